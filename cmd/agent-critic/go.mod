@@ -6,9 +6,13 @@ toolchain go1.24.10
 
 require (
 	github.com/InnoFusionTech/ExplainIQ/internal/adk v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/agentruntime v0.0.0
 	github.com/InnoFusionTech/ExplainIQ/internal/agent v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/agents v0.0.0
 	github.com/InnoFusionTech/ExplainIQ/internal/constants v0.0.0
 	github.com/InnoFusionTech/ExplainIQ/internal/llm v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/tokens v0.0.0 // indirect
+	github.com/InnoFusionTech/ExplainIQ/pkg/explainiqtest v0.0.0
 	github.com/a2aproject/a2a-go v0.3.0
 	github.com/gin-gonic/gin v1.11.0
 	github.com/sirupsen/logrus v1.9.3
@@ -20,6 +24,10 @@ replace github.com/InnoFusionTech/ExplainIQ/internal/adk => ../../internal/adk
 
 replace github.com/InnoFusionTech/ExplainIQ/internal/agent => ../../internal/agent
 
+replace github.com/InnoFusionTech/ExplainIQ/internal/agents => ../../internal/agents
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/agentruntime => ../../internal/agentruntime
+
 replace github.com/InnoFusionTech/ExplainIQ/internal/auth => ../../internal/auth
 
 replace github.com/InnoFusionTech/ExplainIQ/internal/config => ../../internal/config
@@ -28,6 +36,10 @@ replace github.com/InnoFusionTech/ExplainIQ/internal/constants => ../../internal
 
 replace github.com/InnoFusionTech/ExplainIQ/internal/llm => ../../internal/llm
 
+replace github.com/InnoFusionTech/ExplainIQ/internal/tokens => ../../internal/tokens
+
+replace github.com/InnoFusionTech/ExplainIQ/pkg/explainiqtest => ../../pkg/explainiqtest
+
 replace github.com/InnoFusionTech/ExplainIQ/internal/logger => ../../internal/logger
 
 replace github.com/InnoFusionTech/ExplainIQ/internal/server => ../../internal/server
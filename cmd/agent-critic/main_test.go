@@ -11,50 +11,17 @@ import (
 
 	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
 	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/InnoFusionTech/ExplainIQ/pkg/explainiqtest"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
-// MockGeminiClient is a mock implementation of GeminiClientInterface for testing
-type MockGeminiClient struct {
-	critiqueLessonFunc func(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error)
-}
-
-func (m *MockGeminiClient) Summarize(ctx context.Context, topic, context string) (*llm.SummarizeResponse, error) {
-	return nil, errors.New("not implemented")
-}
-
-func (m *MockGeminiClient) ExplainWithOG(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
-	return nil, errors.New("not implemented")
-}
-
-func (m *MockGeminiClient) CritiqueLesson(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
-	if m.critiqueLessonFunc != nil {
-		return m.critiqueLessonFunc(ctx, lessonJSON)
-	}
-	return nil, errors.New("mock error")
-}
-
-func (m *MockGeminiClient) Health(ctx context.Context) error {
-	return nil
-}
-
-func (m *MockGeminiClient) SetAPIKey(apiKey string) {}
-
-func (m *MockGeminiClient) SetModel(model string) {}
-
-func (m *MockGeminiClient) SetBaseURL(baseURL string) {}
-
-func (m *MockGeminiClient) GetModelInfo() map[string]interface{} {
-	return map[string]interface{}{}
-}
-
 // TestCriticService_ProcessTask_Success tests successful task processing
 func TestCriticService_ProcessTask_Success(t *testing.T) {
 	// Create mock client
-	mockClient := &MockGeminiClient{
-		critiqueLessonFunc: func(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		CritiqueFunc: func(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
 			return &llm.CritiqueResponse{
 				Issues: []llm.CritiqueIssue{
 					{
@@ -157,7 +124,7 @@ func TestCriticService_ProcessTask_Success(t *testing.T) {
 // TestCriticService_ProcessTask_MissingLesson tests error handling for missing lesson
 func TestCriticService_ProcessTask_MissingLesson(t *testing.T) {
 	// Create mock client
-	mockClient := &MockGeminiClient{}
+	mockClient := &explainiqtest.MockGeminiClient{}
 
 	// Create service with mock client
 	service := &CriticService{
@@ -187,8 +154,8 @@ func TestCriticService_ProcessTask_MissingLesson(t *testing.T) {
 // TestCriticService_ProcessTask_CritiqueError tests error handling for critique failure
 func TestCriticService_ProcessTask_CritiqueError(t *testing.T) {
 	// Create mock client that returns error
-	mockClient := &MockGeminiClient{
-		critiqueLessonFunc: func(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		CritiqueFunc: func(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
 			return nil, errors.New("critique failed")
 		},
 	}
@@ -221,8 +188,8 @@ func TestCriticService_ProcessTask_CritiqueError(t *testing.T) {
 // TestCriticService_ProcessTask_EmptyCritique tests handling of empty critique
 func TestCriticService_ProcessTask_EmptyCritique(t *testing.T) {
 	// Create mock client
-	mockClient := &MockGeminiClient{
-		critiqueLessonFunc: func(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		CritiqueFunc: func(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
 			return &llm.CritiqueResponse{
 				Issues:    []llm.CritiqueIssue{},
 				PatchPlan: []llm.PatchPlanItem{},
@@ -265,7 +232,7 @@ func TestCriticService_ProcessTask_EmptyCritique(t *testing.T) {
 // TestCriticService_CountIssuesBySeverity tests the severity counting function
 func TestCriticService_CountIssuesBySeverity(t *testing.T) {
 	service := &CriticService{
-		geminiClient: &MockGeminiClient{},
+		geminiClient: &explainiqtest.MockGeminiClient{},
 		logger:       logrus.New(),
 	}
 
@@ -378,8 +345,8 @@ func TestCriticService_HTTPHandlers_ValidRequestWithMock(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	// Create mock client
-	mockClient := &MockGeminiClient{
-		critiqueLessonFunc: func(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		CritiqueFunc: func(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
 			return &llm.CritiqueResponse{
 				Issues: []llm.CritiqueIssue{
 					{
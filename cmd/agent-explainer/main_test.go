@@ -11,46 +11,17 @@ import (
 
 	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
 	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/InnoFusionTech/ExplainIQ/pkg/explainiqtest"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
-// MockGeminiClient is a mock implementation of GeminiClientInterface for testing
-type MockGeminiClient struct {
-	explainWithOGFunc func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error)
-}
-
-func (m *MockGeminiClient) Summarize(ctx context.Context, topic, context string) (*llm.SummarizeResponse, error) {
-	return nil, errors.New("not implemented")
-}
-
-func (m *MockGeminiClient) ExplainWithOG(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
-	if m.explainWithOGFunc != nil {
-		return m.explainWithOGFunc(ctx, topic, outline, misconceptions, context)
-	}
-	return nil, errors.New("mock error")
-}
-
-func (m *MockGeminiClient) Health(ctx context.Context) error {
-	return nil
-}
-
-func (m *MockGeminiClient) SetAPIKey(apiKey string) {}
-
-func (m *MockGeminiClient) SetModel(model string) {}
-
-func (m *MockGeminiClient) SetBaseURL(baseURL string) {}
-
-func (m *MockGeminiClient) GetModelInfo() map[string]interface{} {
-	return map[string]interface{}{}
-}
-
 // TestExplainerService_ProcessTask_Success tests successful task processing
 func TestExplainerService_ProcessTask_Success(t *testing.T) {
 	// Create mock client
-	mockClient := &MockGeminiClient{
-		explainWithOGFunc: func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		ExplainFunc: func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
 			return &llm.OGLesson{
 				BigPicture:     "Machine learning is a subset of AI that enables computers to learn from data.",
 				Metaphor:       "Like teaching a child to recognize animals by showing them pictures.",
@@ -115,7 +86,7 @@ func TestExplainerService_ProcessTask_Success(t *testing.T) {
 // TestExplainerService_ProcessTask_MissingTopic tests error handling for missing topic
 func TestExplainerService_ProcessTask_MissingTopic(t *testing.T) {
 	// Create mock client
-	mockClient := &MockGeminiClient{}
+	mockClient := &explainiqtest.MockGeminiClient{}
 
 	// Create service with mock client
 	service := &ExplainerService{
@@ -145,8 +116,8 @@ func TestExplainerService_ProcessTask_MissingTopic(t *testing.T) {
 // TestExplainerService_ProcessTask_ExplainError tests error handling for explain failure
 func TestExplainerService_ProcessTask_ExplainError(t *testing.T) {
 	// Create mock client that returns error
-	mockClient := &MockGeminiClient{
-		explainWithOGFunc: func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		ExplainFunc: func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
 			return nil, errors.New("explain failed")
 		},
 	}
@@ -179,8 +150,8 @@ func TestExplainerService_ProcessTask_ExplainError(t *testing.T) {
 // TestExplainerService_ProcessTask_OptionalInputs tests handling of optional inputs
 func TestExplainerService_ProcessTask_OptionalInputs(t *testing.T) {
 	// Create mock client
-	mockClient := &MockGeminiClient{
-		explainWithOGFunc: func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		ExplainFunc: func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
 			// Verify that empty strings are passed for missing inputs
 			assert.Equal(t, "machine learning", topic)
 			assert.Equal(t, "", outline)
@@ -227,8 +198,8 @@ func TestExplainerService_ProcessTask_OptionalInputs(t *testing.T) {
 // TestExplainerService_ProcessTask_JSONMarshalError tests error handling for JSON marshaling
 func TestExplainerService_ProcessTask_JSONMarshalError(t *testing.T) {
 	// Create mock client that returns invalid lesson (this shouldn't happen in real usage)
-	mockClient := &MockGeminiClient{
-		explainWithOGFunc: func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		ExplainFunc: func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
 			// Return a lesson with invalid JSON characters (this is a contrived test)
 			return &llm.OGLesson{
 				BigPicture:     "Test big picture",
@@ -384,8 +355,8 @@ func TestExplainerService_HTTPHandlers_ValidRequestWithMock(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	// Create mock client
-	mockClient := &MockGeminiClient{
-		explainWithOGFunc: func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		ExplainFunc: func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
 			return &llm.OGLesson{
 				BigPicture:     "Test big picture",
 				Metaphor:       "Test metaphor",
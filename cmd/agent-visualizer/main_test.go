@@ -11,54 +11,17 @@ import (
 
 	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
 	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/InnoFusionTech/ExplainIQ/pkg/explainiqtest"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
-// MockGeminiClient is a mock implementation of GeminiClientInterface for testing
-type MockGeminiClient struct {
-	visualizeCoreFunc func(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error)
-}
-
-func (m *MockGeminiClient) Summarize(ctx context.Context, topic, context string) (*llm.SummarizeResponse, error) {
-	return nil, errors.New("not implemented")
-}
-
-func (m *MockGeminiClient) ExplainWithOG(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
-	return nil, errors.New("not implemented")
-}
-
-func (m *MockGeminiClient) CritiqueLesson(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
-	return nil, errors.New("not implemented")
-}
-
-func (m *MockGeminiClient) VisualizeCore(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
-	if m.visualizeCoreFunc != nil {
-		return m.visualizeCoreFunc(ctx, lessonJSON, sessionID)
-	}
-	return nil, errors.New("mock error")
-}
-
-func (m *MockGeminiClient) Health(ctx context.Context) error {
-	return nil
-}
-
-func (m *MockGeminiClient) SetAPIKey(apiKey string) {}
-
-func (m *MockGeminiClient) SetModel(model string) {}
-
-func (m *MockGeminiClient) SetBaseURL(baseURL string) {}
-
-func (m *MockGeminiClient) GetModelInfo() map[string]interface{} {
-	return map[string]interface{}{}
-}
-
 // TestVisualizerService_ProcessTask_Success tests successful task processing
 func TestVisualizerService_ProcessTask_Success(t *testing.T) {
 	// Create mock client
-	mockClient := &MockGeminiClient{
-		visualizeCoreFunc: func(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		VisualizeFunc: func(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
 			return &llm.VisualizeResponse{
 				Images: []llm.ImageRef{
 					{
@@ -144,7 +107,7 @@ func TestVisualizerService_ProcessTask_Success(t *testing.T) {
 // TestVisualizerService_ProcessTask_MissingLesson tests error handling for missing lesson
 func TestVisualizerService_ProcessTask_MissingLesson(t *testing.T) {
 	// Create mock client
-	mockClient := &MockGeminiClient{}
+	mockClient := &explainiqtest.MockGeminiClient{}
 
 	// Create service with mock client
 	service := &VisualizerService{
@@ -174,8 +137,8 @@ func TestVisualizerService_ProcessTask_MissingLesson(t *testing.T) {
 // TestVisualizerService_ProcessTask_VisualizationError tests error handling for visualization failure
 func TestVisualizerService_ProcessTask_VisualizationError(t *testing.T) {
 	// Create mock client that returns error
-	mockClient := &MockGeminiClient{
-		visualizeCoreFunc: func(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		VisualizeFunc: func(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
 			return nil, errors.New("visualization failed")
 		},
 	}
@@ -208,8 +171,8 @@ func TestVisualizerService_ProcessTask_VisualizationError(t *testing.T) {
 // TestVisualizerService_ProcessTask_EmptyVisualization tests handling of empty visualization
 func TestVisualizerService_ProcessTask_EmptyVisualization(t *testing.T) {
 	// Create mock client
-	mockClient := &MockGeminiClient{
-		visualizeCoreFunc: func(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		VisualizeFunc: func(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
 			return &llm.VisualizeResponse{
 				Images:   []llm.ImageRef{},
 				Captions: []string{},
@@ -338,8 +301,8 @@ func TestVisualizerService_HTTPHandlers_ValidRequestWithMock(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	// Create mock client
-	mockClient := &MockGeminiClient{
-		visualizeCoreFunc: func(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		VisualizeFunc: func(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
 			return &llm.VisualizeResponse{
 				Images: []llm.ImageRef{
 					{
@@ -421,3 +384,42 @@ func TestVisualizerService_HTTPHandlers_ValidRequestWithMock(t *testing.T) {
 
 
 
+
+// TestVisualizerService_ProcessTask_Accessibility tests that missing alt text is repaired and reported
+func TestVisualizerService_ProcessTask_Accessibility(t *testing.T) {
+	mockClient := &explainiqtest.MockGeminiClient{
+		VisualizeFunc: func(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
+			return &llm.VisualizeResponse{
+				Images: []llm.ImageRef{
+					{URL: "https://example.com/1.png", AltText: "Pipeline diagram", LongDescription: "Three stages connected left to right."},
+					{URL: "https://example.com/2.png", Caption: "Process flowchart"},
+				},
+				Captions: []string{"Pipeline diagram", "Process flowchart"},
+			}, nil
+		},
+	}
+
+	service := &VisualizerService{
+		geminiClient: mockClient,
+		logger:       logrus.New(),
+	}
+
+	response, err := service.ProcessTask(context.Background(), adk.TaskRequest{
+		SessionID: "test-session",
+		Step:      "visualizer",
+		Topic:     "pipelines",
+		Inputs:    map[string]string{"lesson": `{"core_mechanism": "Stages run in order"}`},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, response.Metrics["repaired_alt_text"])
+
+	var images []llm.ImageRef
+	assert.NoError(t, json.Unmarshal([]byte(response.Artifacts["images"]), &images))
+	assert.Equal(t, "Process flowchart", images[1].AltText)
+
+	var info llm.AccessibilityInfo
+	assert.NoError(t, json.Unmarshal([]byte(response.Artifacts["accessibility"]), &info))
+	assert.Len(t, info.Images, 2)
+	assert.Equal(t, 1, info.RepairedAltText)
+	assert.False(t, info.Complete)
+}
@@ -0,0 +1,18 @@
+module github.com/InnoFusionTech/ExplainIQ/cmd/evalrunner
+
+go 1.24.4
+
+toolchain go1.24.10
+
+require (
+	github.com/InnoFusionTech/ExplainIQ/internal/eval v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/llm v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/tokens v0.0.0 // indirect
+	github.com/sirupsen/logrus v1.9.3
+)
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/eval => ../../internal/eval
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/llm => ../../internal/llm
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/tokens => ../../internal/tokens
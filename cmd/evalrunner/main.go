@@ -0,0 +1,138 @@
+// Command evalrunner runs the offline lesson quality evaluation suite and writes
+// JSON and JUnit reports. It exits non-zero when a case fails or a judge score
+// regresses against a baseline report, so it can gate prompt and model changes in CI.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/eval"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	suitePath := flag.String("suite", "", "Path to a suite JSON file (default: built-in suite)")
+	transcripts := flag.String("transcripts", "", "Replay recorded transcripts from this directory instead of running the pipeline")
+	orchestratorURL := flag.String("orchestrator", getEnv("ORCHESTRATOR_URL", "http://localhost:8080"), "Orchestrator base URL used when not replaying transcripts")
+	recordDir := flag.String("record", "", "Record generated outputs as transcripts in this directory")
+	judge := flag.Bool("judge", true, "Score lessons with the rubric-based LLM judge")
+	judgeModel := flag.String("judge-model", getEnv("EVAL_JUDGE_MODEL", ""), "Model used by the judge (default: client default)")
+	rubricPath := flag.String("rubric", "", "Path to a rubrics JSON file; the first rubric is used for judging")
+	minScore := flag.Float64("min-score", eval.DefaultMinScore, "Minimum judge score for a case to pass")
+	baselinePath := flag.String("baseline", "", "Baseline JSON report to compare judge scores against")
+	tolerance := flag.Float64("tolerance", 0.5, "Allowed judge score drop against the baseline")
+	jsonOut := flag.String("json", "eval-report.json", "Write the JSON report to this path (empty to skip)")
+	junitOut := flag.String("junit", "", "Write a JUnit XML report to this path")
+	timeout := flag.Duration("case-timeout", 5*time.Minute, "Timeout for each pipeline run")
+	flag.Parse()
+
+	logger := logrus.New()
+
+	suite := eval.DefaultSuite()
+	if *suitePath != "" {
+		loaded, err := eval.LoadSuite(*suitePath)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load suite")
+		}
+		suite = loaded
+	}
+
+	var source eval.Source
+	if *transcripts != "" {
+		source = eval.NewTranscriptSource(*transcripts)
+	} else {
+		source = eval.NewPipelineSource(*orchestratorURL, *timeout)
+	}
+
+	options := []eval.RunnerOption{
+		eval.WithLogger(logger),
+		eval.WithMinScore(*minScore),
+		eval.WithRecordDir(*recordDir),
+	}
+	if *judge {
+		client := llm.NewGeminiClient("")
+		if *judgeModel != "" {
+			client.SetModel(*judgeModel)
+		}
+		options = append(options, eval.WithJudge(client))
+	}
+	if *rubricPath != "" {
+		rubrics, err := llm.LoadRubricsFile(*rubricPath)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load rubric")
+		}
+		if len(rubrics) == 0 {
+			logger.Fatal("Rubric file contains no rubrics")
+		}
+		options = append(options, eval.WithRubric(rubrics[0]))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := eval.NewRunner(source, options...).Run(ctx, suite)
+	if err != nil {
+		logger.WithError(err).Fatal("Evaluation run failed")
+	}
+
+	if *jsonOut != "" {
+		if err := writeFile(*jsonOut, report.WriteJSON); err != nil {
+			logger.WithError(err).Fatal("Failed to write JSON report")
+		}
+	}
+	if *junitOut != "" {
+		if err := writeFile(*junitOut, report.WriteJUnit); err != nil {
+			logger.WithError(err).Fatal("Failed to write JUnit report")
+		}
+	}
+
+	fmt.Printf("Suite %s: %d passed, %d failed", report.Suite, report.Passed, report.Failed)
+	if report.Judged {
+		fmt.Printf(", mean score %.2f", report.MeanScore)
+	}
+	fmt.Println()
+
+	ok := report.OK()
+	if *baselinePath != "" {
+		baseline, err := eval.LoadReport(*baselinePath)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load baseline report")
+		}
+		for _, regression := range eval.CompareReports(baseline, report, *tolerance) {
+			fmt.Printf("REGRESSION %s: score %.2f -> %.2f\n", regression.CaseID, regression.BaselineScore, regression.Score)
+			ok = false
+		}
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// writeFile creates path and writes to it with write
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// getEnv gets an environment variable with a default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,102 @@
+import { createMocks } from 'node-mocks-http';
+import handler from '../pages/api/status';
+import { collectStatus, resetStatusHistory, serviceChecks } from '../utils/serviceStatus';
+
+jest.mock('@google-cloud/storage', () => ({
+  Storage: jest.fn().mockImplementation(() => ({
+    bucket: jest.fn().mockReturnValue({
+      exists: jest.fn().mockResolvedValue([true]),
+    }),
+  })),
+}));
+
+// Mock fetch
+global.fetch = jest.fn();
+
+// Respond to health checks by URL
+function mockHealth(responses: Record<string, { status: number; body?: unknown } | Error>) {
+  (global.fetch as jest.Mock).mockImplementation(async (url: string) => {
+    const match = Object.keys(responses).find(key => url.includes(key));
+    const response = match ? responses[match] : { status: 404 };
+    if (response instanceof Error) {
+      throw response;
+    }
+    return {
+      ok: response.status >= 200 && response.status < 300,
+      status: response.status,
+      json: async () => response.body ?? {},
+    };
+  });
+}
+
+const env = {
+  ORCHESTRATOR_URL: 'https://orchestrator.example.com',
+  AGENT_SUMMARIZER_URL: 'https://summarizer.example.com',
+  ELASTIC_URL: 'https://search.example.com',
+  GCS_BUCKET: 'explainiq-pdfs',
+} as unknown as NodeJS.ProcessEnv;
+
+describe('service status', () => {
+  const originalEnv = process.env;
+
+  beforeEach(() => {
+    jest.clearAllMocks();
+    resetStatusHistory();
+    process.env = { ...originalEnv, ...env };
+  });
+
+  afterAll(() => {
+    process.env = originalEnv;
+  });
+
+  it('checks only the configured services', () => {
+    expect(serviceChecks(env).map(check => check.name)).toEqual([
+      'orchestrator',
+      'agent-summarizer',
+      'elasticsearch',
+      'storage',
+    ]);
+  });
+
+  it('reports uptime and opens and resolves incidents', async () => {
+    mockHealth({
+      '/readyz': { status: 200, body: { status: 'ready', dependencies: [] } },
+      'summarizer.example.com/health': { status: 200 },
+      '_cluster/health': { status: 200, body: { status: 'green' } },
+    });
+    let report = await collectStatus(serviceChecks(env));
+    expect(report.state).toBe('up');
+
+    mockHealth({
+      '/readyz': { status: 200, body: { status: 'ready', dependencies: [] } },
+      'summarizer.example.com/health': new Error('connect ECONNREFUSED'),
+      '_cluster/health': { status: 200, body: { status: 'yellow' } },
+    });
+    report = await collectStatus(serviceChecks(env));
+    expect(report.state).toBe('degraded');
+    const summarizer = report.services.find(service => service.name === 'agent-summarizer')!;
+    expect(summarizer.state).toBe('down');
+    expect(summarizer.uptime).toBe(50);
+    expect(summarizer.incidents).toHaveLength(1);
+    expect(summarizer.incidents[0].resolved_at).toBeUndefined();
+    expect(report.services.find(service => service.name === 'elasticsearch')!.state).toBe('degraded');
+
+    mockHealth({
+      '/readyz': { status: 200, body: { status: 'ready', dependencies: [] } },
+      'summarizer.example.com/health': { status: 200 },
+      '_cluster/health': { status: 200, body: { status: 'green' } },
+    });
+    report = await collectStatus(serviceChecks(env));
+    expect(report.services.find(service => service.name === 'agent-summarizer')!.incidents[0].resolved_at).toBeDefined();
+  });
+
+  it('returns 503 from the API when the orchestrator is down', async () => {
+    mockHealth({ '/readyz': { status: 503, body: { status: 'unavailable' } } });
+
+    const { req, res } = createMocks({ method: 'GET' });
+    await handler(req as any, res as any);
+
+    expect(res._getStatusCode()).toBe(503);
+    expect(JSON.parse(res._getData()).state).toBe('down');
+  });
+});
@@ -12,6 +12,7 @@ interface BrainPrintData {
   };
   recommendedType: string;
   dominantStyle?: string;
+  recommendationReason?: string;
   tip?: string;
 }
 
@@ -207,6 +208,9 @@ export default function BrainPrintCard({ userID = 'default', className = '', onU
             <p className="text-xs text-gray-500 mt-1">
               {brainPrint.totalSessions} {brainPrint.totalSessions === 1 ? 'session' : 'sessions'} completed
             </p>
+            {brainPrint.recommendationReason && (
+              <p className="text-xs text-gray-600 mt-1">{brainPrint.recommendationReason}</p>
+            )}
           </div>
         </div>
       </div>
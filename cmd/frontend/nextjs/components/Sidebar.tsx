@@ -53,6 +53,7 @@ interface SavedLesson {
   id: string;
   title: string;
   topic: string;
+  summary?: string;
   explanation_type: string;
   created_at: string;
 }
@@ -197,6 +198,11 @@ const Sidebar: React.FC<SidebarProps> = ({ activeType, onTypeChange, disabled =
                         <div className="text-sm font-medium text-gray-900 truncate group-hover:text-blue-600">
                           {lesson.title || lesson.topic}
                         </div>
+                        {lesson.summary && (
+                          <div className="text-xs text-gray-600 mt-1 line-clamp-2">
+                            {lesson.summary}
+                          </div>
+                        )}
                         <div className="text-xs text-gray-500 mt-1">
                           {new Date(lesson.created_at).toLocaleDateString()}
                         </div>
@@ -0,0 +1,55 @@
+import { NextApiRequest, NextApiResponse } from 'next';
+import { getOrchestratorURL } from '../../../../utils/orchestrator';
+import { forwardSSE, writeSSEError, writeSSEHeaders } from '../../../../utils/sseProxy';
+
+/**
+ * Proxy a session's live event stream from the orchestrator without starting a run.
+ * The stream opens with a session_snapshot so viewers joining mid-run catch up.
+ */
+export default async function handler(
+  req: NextApiRequest,
+  res: NextApiResponse
+) {
+  if (req.method !== 'GET') {
+    return res.status(405).json({ error: 'Method not allowed' });
+  }
+
+  const { id: sessionId } = req.query;
+
+  if (!sessionId || typeof sessionId !== 'string') {
+    return res.status(400).json({ error: 'Session ID is required' });
+  }
+
+  // Set up SSE headers
+  writeSSEHeaders(res);
+
+  try {
+    const ORCHESTRATOR_URL = getOrchestratorURL();
+    const orchestratorEndpoint = `${ORCHESTRATOR_URL}/api/sessions/${encodeURIComponent(sessionId)}/events`;
+    console.log(`[SSE Events] Connecting to: ${orchestratorEndpoint}`);
+
+    const response = await fetch(orchestratorEndpoint, {
+      headers: {
+        'Accept': 'text/event-stream',
+        'Cache-Control': 'no-cache',
+      },
+    });
+
+    if (response.status === 404) {
+      throw new Error('Session not found');
+    }
+    if (!response.ok) {
+      throw new Error(`Orchestrator SSE error: ${response.status} ${response.statusText}`);
+    }
+
+    await forwardSSE(req, res, response, sessionId);
+
+  } catch (error) {
+    console.error('SSE events error:', error);
+
+    if (!res.writableEnded) {
+      writeSSEError(res, sessionId, error, 'Unknown error');
+      res.end();
+    }
+  }
+}
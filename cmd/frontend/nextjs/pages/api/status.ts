@@ -0,0 +1,15 @@
+import { NextApiRequest, NextApiResponse } from 'next';
+import { collectStatus, StatusReport } from '../../utils/serviceStatus';
+
+export default async function handler(
+  req: NextApiRequest,
+  res: NextApiResponse<StatusReport | { error: string }>
+) {
+  if (req.method !== 'GET') {
+    return res.status(405).json({ error: 'Method not allowed' });
+  }
+
+  const report = await collectStatus();
+  res.setHeader('Cache-Control', 'no-store');
+  res.status(report.state === 'down' ? 503 : 200).json(report);
+}
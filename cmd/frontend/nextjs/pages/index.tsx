@@ -16,13 +16,7 @@ import { useRetry } from '../hooks/useRetry';
 import { getOrchestratorURL } from '../utils/getOrchestratorURL';
 import { validateTopic } from '../utils/validation';
 import { handleError, isRetryableError } from '../utils/errorHandler';
-
-const STEPS = [
-  { id: 'summarizer', name: 'Summarizer', description: 'Analyzing topic and context' },
-  { id: 'explainer', name: 'Explainer', description: 'Creating structured lesson' },
-  { id: 'visualizer', name: 'Visualizer', description: 'Generating diagrams' },
-  { id: 'critic', name: 'Critic', description: 'Reviewing and improving' },
-];
+import { PIPELINE_STEPS as STEPS } from '../utils/pipelineSteps';
 
 export default function Home() {
   const [topic, setTopic] = useState('');
@@ -0,0 +1,201 @@
+import { useState, useEffect, useRef } from 'react';
+import { useRouter } from 'next/router';
+import Head from 'next/head';
+import Timeline from '../../components/Timeline';
+import LessonCard from '../../components/LessonCard';
+import { StepStatus, SSEEvent, OGLesson, ImageRef } from '../../types';
+import { getOrchestratorURL } from '../../utils/getOrchestratorURL';
+import { PIPELINE_STEPS } from '../../utils/pipelineSteps';
+
+const STEP_STATUSES: StepStatus['status'][] = ['pending', 'running', 'completed', 'failed'];
+
+// Convert a step status reported by the orchestrator into a timeline status
+function toStepStatus(status: string): StepStatus['status'] {
+  return STEP_STATUSES.includes(status as StepStatus['status']) ? (status as StepStatus['status']) : 'pending';
+}
+
+export default function LiveSessionPage() {
+  const router = useRouter();
+  const { id } = router.query;
+  const [steps, setSteps] = useState<StepStatus[]>(
+    PIPELINE_STEPS.map(step => ({
+      step: step.id,
+      status: 'pending',
+      timestamp: new Date().toISOString(),
+    }))
+  );
+  const [sessionStatus, setSessionStatus] = useState<string>('connecting');
+  const [queuePosition, setQueuePosition] = useState<number | null>(null);
+  const [lesson, setLesson] = useState<OGLesson | null>(null);
+  const [images, setImages] = useState<ImageRef[]>([]);
+  const [error, setError] = useState<string | null>(null);
+  const eventSourceRef = useRef<EventSource | null>(null);
+  const runStartedRef = useRef(false);
+
+  useEffect(() => {
+    if (!id || typeof id !== 'string') return;
+
+    const eventSource = new EventSource(`/api/sessions/${id}/events`);
+    eventSourceRef.current = eventSource;
+
+    eventSource.onmessage = (event) => {
+      try {
+        handleSSEEvent(id, JSON.parse(event.data) as SSEEvent);
+      } catch (err) {
+        console.error('Failed to parse SSE event:', err, 'Raw data:', event.data);
+      }
+    };
+
+    eventSource.onerror = () => {
+      if (eventSource.readyState === EventSource.CLOSED) {
+        setError('Connection lost. Refresh the page to reconnect.');
+      }
+    };
+
+    return () => {
+      eventSource.close();
+      eventSourceRef.current = null;
+    };
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [id]);
+
+  // Start the run for a session that was created but never run; progress arrives on the open stream
+  const startRun = async (sessionId: string) => {
+    if (runStartedRef.current) return;
+    runStartedRef.current = true;
+
+    try {
+      const orchestratorURL = getOrchestratorURL();
+      const response = await fetch(`${orchestratorURL}/api/sessions/${sessionId}/run?mode=async`, {
+        method: 'POST',
+      });
+      if (!response.ok) {
+        throw new Error('Failed to start session');
+      }
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to start session');
+    }
+  };
+
+  const updateStepStatus = (
+    stepId: string,
+    status: StepStatus['status'],
+    timestamp: string,
+    duration?: number,
+    error?: string
+  ) => {
+    setSteps(prevSteps =>
+      prevSteps.map(step =>
+        step.step === stepId
+          ? { ...step, status, timestamp, duration, error }
+          : step
+      )
+    );
+  };
+
+  const closeStream = () => {
+    if (eventSourceRef.current) {
+      eventSourceRef.current.close();
+    }
+  };
+
+  const handleSSEEvent = (sessionId: string, event: SSEEvent) => {
+    const { type, data } = event;
+
+    switch (type) {
+      case 'session_snapshot':
+        setSessionStatus(data.status || 'unknown');
+        setQueuePosition(data.queue_position || null);
+        if (data.steps) {
+          const reported = new Map(data.steps.map(step => [step.name, step]));
+          setSteps(prevSteps =>
+            prevSteps.map(step => {
+              const current = reported.get(step.step);
+              return current
+                ? { ...step, status: toStepStatus(current.status), error: current.error }
+                : step;
+            })
+          );
+        }
+        if (data.status === 'created') {
+          startRun(sessionId);
+        }
+        break;
+
+      case 'step_start':
+        setSessionStatus('running');
+        setQueuePosition(null);
+        updateStepStatus(data.step!, 'running', data.timestamp);
+        break;
+
+      case 'step_complete':
+        updateStepStatus(data.step!, 'completed', data.timestamp, data.duration);
+        break;
+
+      case 'step_error':
+        updateStepStatus(data.step!, 'failed', data.timestamp, undefined, data.error);
+        break;
+
+      case 'session_complete': {
+        setSessionStatus('completed');
+        const artifacts = (data.artifacts || {}) as any;
+        if (artifacts.lesson) {
+          setLesson(artifacts.lesson);
+        }
+        setImages(artifacts.images || []);
+        closeStream();
+        break;
+      }
+
+      case 'session_error':
+        setSessionStatus('failed');
+        setError(data.error || 'Session failed');
+        closeStream();
+        break;
+    }
+  };
+
+  return (
+    <>
+      <Head>
+        <title>Live Session - ExplainIQ</title>
+      </Head>
+
+      <div className="min-h-screen bg-gray-50">
+        {/* Header */}
+        <div className="bg-white border-b border-gray-200 shadow-sm">
+          <div className="container mx-auto px-4 py-4 max-w-5xl">
+            <button
+              onClick={() => router.push('/')}
+              className="text-gray-600 hover:text-gray-900 mb-2 flex items-center space-x-2"
+            >
+              <svg className="w-5 h-5" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M10 19l-7-7m0 0l7-7m-7 7h18" />
+              </svg>
+              <span>Back to Home</span>
+            </button>
+            <h1 className="text-2xl font-bold text-gray-900">Session {id}</h1>
+            <p className="text-sm text-gray-500 mt-1">
+              Status: {sessionStatus}
+              {queuePosition ? ` (position ${queuePosition} in queue)` : ''}
+            </p>
+          </div>
+        </div>
+
+        <div className="container mx-auto px-4 py-8 max-w-5xl space-y-8">
+          <Timeline steps={steps} stepInfo={PIPELINE_STEPS} />
+
+          {error && (
+            <div className="bg-red-50 border border-red-200 rounded-lg p-4">
+              <p className="text-red-700">{error}</p>
+            </div>
+          )}
+
+          {lesson && (
+            <LessonCard lesson={lesson} images={images} sessionId={typeof id === 'string' ? id : undefined} />
+          )}
+        </div>
+      </div>
+    </>
+  );
+}
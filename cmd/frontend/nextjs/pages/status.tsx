@@ -0,0 +1,91 @@
+import { GetServerSideProps } from 'next';
+import Head from 'next/head';
+import { collectStatus, ServiceState, StatusReport } from '../utils/serviceStatus';
+
+const STATE_STYLES: Record<ServiceState, { dot: string; text: string; label: string }> = {
+  up: { dot: 'bg-green-500', text: 'text-green-700', label: 'Operational' },
+  degraded: { dot: 'bg-yellow-500', text: 'text-yellow-700', label: 'Degraded' },
+  down: { dot: 'bg-red-500', text: 'text-red-700', label: 'Down' },
+};
+
+const SUMMARIES: Record<ServiceState, string> = {
+  up: 'All systems operational',
+  degraded: 'Some services are degraded',
+  down: 'ExplainIQ is currently unavailable',
+};
+
+interface StatusPageProps {
+  report: StatusReport;
+}
+
+// Render /status as HTML, or as JSON with ?format=json or an Accept: application/json header
+export const getServerSideProps: GetServerSideProps<StatusPageProps> = async ({ req, res, query }) => {
+  const report = await collectStatus();
+  res.setHeader('Cache-Control', 'no-store');
+
+  const wantsJSON = query.format === 'json' || (req.headers.accept || '').startsWith('application/json');
+  if (wantsJSON) {
+    res.statusCode = report.state === 'down' ? 503 : 200;
+    res.setHeader('Content-Type', 'application/json');
+    res.end(JSON.stringify(report));
+  }
+  return { props: { report } };
+};
+
+export default function StatusPage({ report }: StatusPageProps) {
+  const overall = STATE_STYLES[report.state];
+
+  return (
+    <>
+      <Head>
+        <title>Service Status - ExplainIQ</title>
+      </Head>
+      <div className="min-h-screen bg-gray-50 py-10">
+        <div className="max-w-3xl mx-auto px-4">
+          <h1 className="text-2xl font-bold text-gray-900 mb-2">ExplainIQ Status</h1>
+          <div className="bg-white border border-gray-200 rounded-lg p-4 mb-6 flex items-center">
+            <span className={`h-3 w-3 rounded-full mr-3 ${overall.dot}`}></span>
+            <span className={`font-semibold ${overall.text}`}>{SUMMARIES[report.state]}</span>
+          </div>
+
+          <div className="bg-white border border-gray-200 rounded-lg divide-y divide-gray-200">
+            {report.services.map(service => {
+              const style = STATE_STYLES[service.state];
+              return (
+                <div key={service.name} className="p-4">
+                  <div className="flex items-center justify-between">
+                    <div className="flex items-center">
+                      <span className={`h-2.5 w-2.5 rounded-full mr-3 ${style.dot}`}></span>
+                      <span className="font-medium text-gray-900">{service.label}</span>
+                    </div>
+                    <span className={`text-sm ${style.text}`}>{style.label}</span>
+                  </div>
+                  <p className="text-sm text-gray-500 mt-1">
+                    {service.uptime}% uptime since {new Date(service.monitoring_since).toLocaleString()} · {service.latency_ms} ms
+                    {service.error ? ` · ${service.error}` : ''}
+                  </p>
+                  {service.incidents.length > 0 && (
+                    <ul className="mt-2 space-y-1">
+                      {service.incidents.map(incident => (
+                        <li key={incident.started_at} className="text-xs text-gray-600">
+                          <span className={STATE_STYLES[incident.state].text}>{STATE_STYLES[incident.state].label}</span>
+                          {` from ${new Date(incident.started_at).toLocaleString()}`}
+                          {incident.resolved_at ? ` to ${new Date(incident.resolved_at).toLocaleString()}` : ' (ongoing)'}
+                          {incident.error ? ` · ${incident.error}` : ''}
+                        </li>
+                      ))}
+                    </ul>
+                  )}
+                </div>
+              );
+            })}
+          </div>
+
+          <p className="text-xs text-gray-400 mt-4">
+            Checked {new Date(report.generated_at).toLocaleString()}. Also available as <a className="underline" href="/status?format=json">JSON</a>.
+          </p>
+        </div>
+      </div>
+    </>
+  );
+}
@@ -21,7 +21,7 @@ export interface StepStatus {
 }
 
 export interface SSEEvent {
-  type: 'connected' | 'step_start' | 'step_complete' | 'step_error' | 'session_complete' | 'session_error';
+  type: 'connected' | 'session_snapshot' | 'step_start' | 'step_complete' | 'step_error' | 'session_complete' | 'session_error';
   data: {
     session_id: string;
     step?: string;
@@ -30,6 +30,8 @@ export interface SSEEvent {
     duration?: number;
     error?: string;
     artifacts?: Record<string, any>;
+    steps?: Array<{ id?: string; name: string; status: string; duration?: number; error?: string }>;
+    queue_position?: number;
   };
 }
 
@@ -72,5 +74,6 @@ export interface BrainPrint {
     Analogy?: number;
   };
   recommendedType: string;
+  recommendationReason?: string;
   lastUpdated?: string;
 }
@@ -0,0 +1,9 @@
+/**
+ * Pipeline steps in the order the orchestrator runs them
+ */
+export const PIPELINE_STEPS = [
+  { id: 'summarizer', name: 'Summarizer', description: 'Analyzing topic and context' },
+  { id: 'explainer', name: 'Explainer', description: 'Creating structured lesson' },
+  { id: 'visualizer', name: 'Visualizer', description: 'Generating diagrams' },
+  { id: 'critic', name: 'Critic', description: 'Reviewing and improving' },
+];
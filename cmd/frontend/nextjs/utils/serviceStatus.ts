@@ -0,0 +1,218 @@
+import { Storage } from '@google-cloud/storage';
+import { getOrchestratorURL } from './orchestrator';
+
+export type ServiceState = 'up' | 'degraded' | 'down';
+
+export interface StatusIncident {
+  state: ServiceState;
+  started_at: string;
+  resolved_at?: string;
+  error?: string;
+}
+
+export interface ServiceStatus {
+  name: string;
+  label: string;
+  state: ServiceState;
+  latency_ms: number;
+  checked_at: string;
+  error?: string;
+  uptime: number; // Percentage of checks since monitoring_since that were up
+  monitoring_since: string;
+  incidents: StatusIncident[]; // Most recent first
+}
+
+export interface StatusReport {
+  state: ServiceState;
+  services: ServiceStatus[];
+  generated_at: string;
+}
+
+interface CheckResult {
+  state: ServiceState;
+  error?: string;
+}
+
+interface ServiceCheck {
+  name: string;
+  label: string;
+  run: (signal: AbortSignal) => Promise<CheckResult>;
+}
+
+interface ServiceHistory {
+  checks: number;
+  up: number;
+  since: string;
+  incidents: StatusIncident[];
+}
+
+// Timeout for each health check
+const CHECK_TIMEOUT_MS = 5000;
+
+// Number of incidents kept per service
+const MAX_INCIDENTS = 10;
+
+const AGENTS = [
+  { name: 'summarizer', env: 'AGENT_SUMMARIZER_URL' },
+  { name: 'explainer', env: 'AGENT_EXPLAINER_URL' },
+  { name: 'visualizer', env: 'AGENT_VISUALIZER_URL' },
+  { name: 'critic', env: 'AGENT_CRITIC_URL' },
+];
+
+// Check history by service name, kept for the lifetime of the server process
+const history = new Map<string, ServiceHistory>();
+
+// Check an HTTP health endpoint; any 2xx response is up
+function httpCheck(url: string, headers: Record<string, string> = {}) {
+  return async (signal: AbortSignal): Promise<CheckResult> => {
+    const response = await fetch(url, { headers, signal });
+    if (!response.ok) {
+      return { state: 'down', error: `HTTP ${response.status}` };
+    }
+    return { state: 'up' };
+  };
+}
+
+// Check the orchestrator's readiness; a degraded optional dependency degrades the orchestrator
+function orchestratorCheck(baseURL: string) {
+  return async (signal: AbortSignal): Promise<CheckResult> => {
+    const response = await fetch(`${baseURL}/readyz`, { signal });
+    if (!response.ok) {
+      return { state: 'down', error: `HTTP ${response.status}` };
+    }
+    const body = await response.json().catch(() => ({}));
+    if (body.status === 'degraded') {
+      const degraded = (body.dependencies || [])
+        .filter((dependency: { state?: string }) => dependency.state === 'degraded')
+        .map((dependency: { name: string }) => dependency.name);
+      return { state: 'degraded', error: `Degraded: ${degraded.join(', ')}` };
+    }
+    return { state: 'up' };
+  };
+}
+
+// Check Elasticsearch cluster health; a yellow cluster is degraded and a red one down
+function elasticsearchCheck(baseURL: string, apiKey?: string) {
+  return async (signal: AbortSignal): Promise<CheckResult> => {
+    const headers: Record<string, string> = apiKey ? { Authorization: `ApiKey ${apiKey}` } : {};
+    const response = await fetch(`${baseURL.replace(/\/$/, '')}/_cluster/health`, { headers, signal });
+    if (!response.ok) {
+      return { state: 'down', error: `HTTP ${response.status}` };
+    }
+    const body = await response.json();
+    if (body.status === 'red') {
+      return { state: 'down', error: 'Cluster status red' };
+    }
+    if (body.status === 'yellow') {
+      return { state: 'degraded', error: 'Cluster status yellow' };
+    }
+    return { state: 'up' };
+  };
+}
+
+// Check that the storage bucket is reachable
+function storageCheck(bucket: string, projectId?: string) {
+  return async (): Promise<CheckResult> => {
+    const [exists] = await new Storage({ projectId }).bucket(bucket).exists();
+    return exists ? { state: 'up' } : { state: 'down', error: `Bucket ${bucket} not found` };
+  };
+}
+
+// Build the checks for every service configured in the environment
+export function serviceChecks(env: NodeJS.ProcessEnv = process.env): ServiceCheck[] {
+  const checks: ServiceCheck[] = [
+    { name: 'orchestrator', label: 'Orchestrator', run: orchestratorCheck(getOrchestratorURL()) },
+  ];
+  for (const agent of AGENTS) {
+    const url = env[agent.env];
+    if (url) {
+      checks.push({
+        name: `agent-${agent.name}`,
+        label: `${agent.name.charAt(0).toUpperCase()}${agent.name.slice(1)} agent`,
+        run: httpCheck(`${url.replace(/\/$/, '')}/health`),
+      });
+    }
+  }
+  if (env.ELASTIC_URL) {
+    checks.push({ name: 'elasticsearch', label: 'Search (Elasticsearch)', run: elasticsearchCheck(env.ELASTIC_URL, env.ELASTIC_API_KEY) });
+  }
+  if (env.GCS_BUCKET) {
+    checks.push({ name: 'storage', label: 'Storage', run: storageCheck(env.GCS_BUCKET, env.GCS_PROJECT_ID) });
+  }
+  return checks;
+}
+
+// Run one check with a timeout, treating errors and timeouts as down
+async function runCheck(check: ServiceCheck): Promise<CheckResult & { latency_ms: number }> {
+  const controller = new AbortController();
+  const timeout = setTimeout(() => controller.abort(), CHECK_TIMEOUT_MS);
+  const started = Date.now();
+  try {
+    const result = await check.run(controller.signal);
+    return { ...result, latency_ms: Date.now() - started };
+  } catch (error) {
+    const message = controller.signal.aborted ? 'Timed out' : error instanceof Error ? error.message : 'Check failed';
+    return { state: 'down', error: message, latency_ms: Date.now() - started };
+  } finally {
+    clearTimeout(timeout);
+  }
+}
+
+// Record a check result, opening an incident when a service leaves the up state and resolving it on recovery
+function record(name: string, result: CheckResult, checkedAt: string): ServiceHistory {
+  let entry = history.get(name);
+  if (!entry) {
+    entry = { checks: 0, up: 0, since: checkedAt, incidents: [] };
+    history.set(name, entry);
+  }
+  entry.checks++;
+  const open = entry.incidents.find(incident => !incident.resolved_at);
+  if (result.state === 'up') {
+    entry.up++;
+    if (open) {
+      open.resolved_at = checkedAt;
+    }
+  } else if (!open || open.state !== result.state) {
+    if (open) {
+      open.resolved_at = checkedAt;
+    }
+    entry.incidents.unshift({ state: result.state, started_at: checkedAt, error: result.error });
+    entry.incidents = entry.incidents.slice(0, MAX_INCIDENTS);
+  }
+  return entry;
+}
+
+// Overall state: down if the orchestrator is down, degraded if anything else is not up
+function overallState(services: ServiceStatus[]): ServiceState {
+  if (services.some(service => service.name === 'orchestrator' && service.state === 'down')) {
+    return 'down';
+  }
+  return services.every(service => service.state === 'up') ? 'up' : 'degraded';
+}
+
+// Check every configured service and report its state, uptime and recent incidents
+export async function collectStatus(checks: ServiceCheck[] = serviceChecks()): Promise<StatusReport> {
+  const results = await Promise.all(checks.map(runCheck));
+  const checkedAt = new Date().toISOString();
+  const services = checks.map((check, i) => {
+    const result = results[i];
+    const entry = record(check.name, result, checkedAt);
+    return {
+      name: check.name,
+      label: check.label,
+      state: result.state,
+      latency_ms: result.latency_ms,
+      checked_at: checkedAt,
+      ...(result.error ? { error: result.error } : {}),
+      uptime: Math.round((entry.up / entry.checks) * 10000) / 100,
+      monitoring_since: entry.since,
+      incidents: entry.incidents.map(incident => ({ ...incident })),
+    };
+  });
+  return { state: overallState(services), services, generated_at: checkedAt };
+}
+
+// Clear the recorded check history (for tests)
+export function resetStatusHistory() {
+  history.clear();
+}
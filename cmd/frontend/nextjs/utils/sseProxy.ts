@@ -0,0 +1,98 @@
+import { NextApiRequest, NextApiResponse } from 'next';
+import { SSEEvent } from '../types';
+
+/**
+ * Write the response headers for an SSE stream
+ */
+export function writeSSEHeaders(res: NextApiResponse): void {
+  res.writeHead(200, {
+    'Content-Type': 'text/event-stream',
+    'Cache-Control': 'no-cache',
+    'Connection': 'keep-alive',
+    'Access-Control-Allow-Origin': '*',
+    'Access-Control-Allow-Headers': 'Cache-Control',
+  });
+}
+
+/**
+ * Write a session_error event to the client
+ */
+export function writeSSEError(res: NextApiResponse, sessionId: string, error: unknown, fallback: string): void {
+  const errorEvent: SSEEvent = {
+    type: 'session_error',
+    data: {
+      session_id: sessionId,
+      status: 'error',
+      timestamp: new Date().toISOString(),
+      error: error instanceof Error ? error.message : fallback,
+    },
+  };
+  res.write(`data: ${JSON.stringify(errorEvent)}\n\n`);
+}
+
+/**
+ * Forward an orchestrator SSE response to the client message by message,
+ * ending the client response when the orchestrator stream ends
+ */
+export async function forwardSSE(
+  req: NextApiRequest,
+  res: NextApiResponse,
+  response: Response,
+  sessionId: string
+): Promise<void> {
+  if (!response.body) {
+    throw new Error('Response body is null');
+  }
+
+  // Handle client disconnect
+  req.on('close', () => {
+    // Response body will be closed automatically when client disconnects
+    res.end();
+  });
+
+  const reader = response.body.getReader();
+  const decoder = new TextDecoder();
+  let buffer = '';
+
+  try {
+    while (true) {
+      const { done, value } = await reader.read();
+
+      if (done) {
+        break;
+      }
+
+      // Decode the chunk and add to buffer
+      buffer += decoder.decode(value, { stream: true });
+
+      // Process complete SSE messages (ending with \n\n)
+      const lines = buffer.split('\n\n');
+      buffer = lines.pop() || ''; // Keep incomplete message in buffer
+
+      for (const line of lines) {
+        if (line.trim()) {
+          // Forward the SSE event to the client
+          res.write(`${line}\n\n`);
+
+          // Flush to send immediately
+          if (typeof (res as any).flush === 'function') {
+            (res as any).flush();
+          }
+        }
+      }
+    }
+
+    // Send any remaining buffered data
+    if (buffer.trim()) {
+      res.write(`${buffer}\n\n`);
+    }
+  } catch (streamError) {
+    console.error('Error streaming SSE:', streamError);
+    writeSSEError(res, sessionId, streamError, 'Stream error');
+  } finally {
+    reader.releaseLock();
+    if (!res.writableEnded) {
+      res.end();
+    }
+  }
+}
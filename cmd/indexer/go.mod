@@ -0,0 +1,12 @@
+module github.com/InnoFusionTech/ExplainIQ/cmd/indexer
+
+go 1.24.4
+
+toolchain go1.24.10
+
+require (
+	github.com/InnoFusionTech/ExplainIQ/internal/elastic v0.0.0
+	github.com/sirupsen/logrus v1.9.3
+)
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/elastic => ../../internal/elastic
@@ -0,0 +1,102 @@
+// Command indexer manages the Elasticsearch retrieval index: it bootstraps the alias,
+// lifecycle policy and index template, reports what backs the alias, rolls the write
+// index over and reindexes into a fresh backing index after mapping changes.
+//
+// Usage:
+//
+//	indexer [flags] bootstrap|status|rollover|reindex
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/elastic"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	elasticURL := flag.String("url", getEnv("ELASTIC_URL", "http://elasticsearch:9200"), "Elasticsearch address")
+	index := flag.String("index", "lessons", "Alias the pipeline reads and writes")
+	dims := flag.Int("dims", elastic.DefaultDimensions, "Embedding dimensions of the dense_vector field")
+	rolloverSize := flag.String("rollover-size", elastic.DefaultRolloverSize, "Primary shard size that triggers rollover")
+	rolloverAge := flag.String("rollover-age", "", "Also roll over after this age, e.g. 30d")
+	deleteOld := flag.Bool("delete-old", false, "Delete the old indices after a reindex (required to migrate a plain index)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] bootstrap|status|rollover|reindex\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logger := logrus.New()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := elastic.NewClient(ctx, *elasticURL, os.Getenv("ELASTIC_API_KEY"))
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to connect to Elasticsearch")
+	}
+
+	spec := elastic.NewIndexSpec(*index, *dims)
+	spec.RolloverSize = *rolloverSize
+	spec.RolloverMaxAge = *rolloverAge
+
+	switch command := flag.Arg(0); command {
+	case "bootstrap":
+		if err := client.EnsureIndex(ctx, spec); err != nil {
+			logger.WithError(err).Fatal("Bootstrap failed")
+		}
+		printState(ctx, client, spec.Alias, logger)
+	case "status":
+		printState(ctx, client, spec.Alias, logger)
+	case "rollover":
+		newIndex, err := client.Rollover(ctx, spec.Alias)
+		if err != nil {
+			logger.WithError(err).Fatal("Rollover failed")
+		}
+		fmt.Printf("Rolled %s over to %s\n", spec.Alias, newIndex)
+	case "reindex":
+		result, err := client.Reindex(ctx, spec, *deleteOld)
+		if err != nil {
+			logger.WithError(err).Fatal("Reindex failed")
+		}
+		printJSON(result)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// printState prints the indices behind alias
+func printState(ctx context.Context, client *elastic.Client, alias string, logger *logrus.Logger) {
+	state, err := client.IndexState(ctx, alias)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to read index state")
+	}
+	printJSON(state)
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// getEnv gets an environment variable with a default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
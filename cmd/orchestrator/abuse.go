@@ -0,0 +1,497 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// challengeTokenHeader carries a client's answer to a challenge
+	challengeTokenHeader = "X-Challenge-Token"
+	// fingerprintHeader lets clients send a device fingerprint instead of the derived one
+	fingerprintHeader = "X-Client-Fingerprint"
+
+	// abuseSweepSize is the number of tracked clients above which idle ones are swept
+	abuseSweepSize = 10000
+)
+
+// Abuse score weights
+const (
+	abuseVolumeFreeSessions     = 5  // Sessions per window before volume counts
+	abuseVolumePoints           = 10 // Per session over the free allowance
+	abuseDisposableFreeSessions = 3  // Never-run sessions per window before they count
+	abuseDisposablePoints       = 15 // Per never-run session over the free allowance
+	abuseRepeatFreeTopics       = 2  // Times a topic may be requested per window before repeats count
+	abuseRepeatPoints           = 10 // Per repeat of a topic over the free allowance
+	abuseFailedChallengePoints  = 25 // Per failed challenge
+	abuseFreeFingerprints       = 2  // Device fingerprints per window before rotating them counts
+	abuseFingerprintPoints      = 15 // Per fingerprint over the free allowance
+	abuseTopicLinkPoints        = 30
+	abuseTopicLengthPoints      = 20
+	abuseTopicRepeatCharPoints  = 20
+	abuseTopicSymbolPoints      = 20
+	abuseTopicMaxLength         = 300
+)
+
+var (
+	// topicLinkPattern matches URLs and bare domains, which real topics do not need
+	topicLinkPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+|\b[a-z0-9-]+\.(com|net|org|ru|cn|xyz|top|io)\b`)
+)
+
+// AbuseConfig configures scoring of anonymous session creation
+type AbuseConfig struct {
+	Enabled        bool          `json:"enabled"`
+	Window         time.Duration `json:"window"`          // Sliding window the score is computed over
+	ChallengeScore int           `json:"challenge_score"` // Score at which a challenge is required
+	BlockScore     int           `json:"block_score"`     // Score at which requests are rejected outright
+	VerifiedFor    time.Duration `json:"verified_for"`    // How long a passed challenge exempts a client from further challenges
+}
+
+// DefaultAbuseConfig returns the abuse scoring configuration from the environment
+// (ABUSE_DETECTION_ENABLED, ABUSE_WINDOW, ABUSE_CHALLENGE_SCORE, ABUSE_BLOCK_SCORE)
+func DefaultAbuseConfig() AbuseConfig {
+	config := AbuseConfig{
+		Enabled:        os.Getenv("ABUSE_DETECTION_ENABLED") == "true",
+		Window:         10 * time.Minute,
+		ChallengeScore: 50,
+		BlockScore:     150,
+		VerifiedFor:    30 * time.Minute,
+	}
+	if v := os.Getenv("ABUSE_WINDOW"); v != "" {
+		if window, err := time.ParseDuration(v); err == nil && window > 0 {
+			config.Window = window
+		} else {
+			logrus.WithField("value", v).Warn("Invalid ABUSE_WINDOW, using default")
+		}
+	}
+	if v := os.Getenv("ABUSE_CHALLENGE_SCORE"); v != "" {
+		if score, err := strconv.Atoi(v); err == nil && score > 0 {
+			config.ChallengeScore = score
+		} else {
+			logrus.WithField("value", v).Warn("Invalid ABUSE_CHALLENGE_SCORE, using default")
+		}
+	}
+	if v := os.Getenv("ABUSE_BLOCK_SCORE"); v != "" {
+		if score, err := strconv.Atoi(v); err == nil && score > 0 {
+			config.BlockScore = score
+		} else {
+			logrus.WithField("value", v).Warn("Invalid ABUSE_BLOCK_SCORE, using default")
+		}
+	}
+	return config
+}
+
+// ChallengeVerifier issues and checks challenges such as CAPTCHAs for suspicious clients
+type ChallengeVerifier interface {
+	// Challenge describes the challenge a client must solve, e.g. the CAPTCHA provider and site key
+	Challenge() map[string]string
+	// Verify reports whether token is a valid answer from the client at remoteIP
+	Verify(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// siteVerifyURLs are the verification endpoints of CAPTCHA providers sharing the siteverify API
+var siteVerifyURLs = map[string]string{
+	"recaptcha": "https://www.google.com/recaptcha/api/siteverify",
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+	"turnstile": "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// SiteVerifyVerifier checks CAPTCHA tokens against a siteverify endpoint, the API shared by
+// reCAPTCHA, hCaptcha and Cloudflare Turnstile
+type SiteVerifyVerifier struct {
+	provider   string
+	siteKey    string
+	secret     string
+	verifyURL  string
+	httpClient *http.Client
+}
+
+// NewSiteVerifyVerifier creates a verifier for a provider; verifyURL defaults to the provider's endpoint
+func NewSiteVerifyVerifier(provider, siteKey, secret, verifyURL string) (*SiteVerifyVerifier, error) {
+	if verifyURL == "" {
+		verifyURL = siteVerifyURLs[provider]
+	}
+	if verifyURL == "" {
+		return nil, fmt.Errorf("unknown challenge provider %q", provider)
+	}
+	return &SiteVerifyVerifier{
+		provider:   provider,
+		siteKey:    siteKey,
+		secret:     secret,
+		verifyURL:  verifyURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Challenge implements ChallengeVerifier
+func (v *SiteVerifyVerifier) Challenge() map[string]string {
+	return map[string]string{
+		"provider": v.provider,
+		"site_key": v.siteKey,
+		"header":   challengeTokenHeader,
+	}
+}
+
+// Verify implements ChallengeVerifier
+func (v *SiteVerifyVerifier) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("challenge verification returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode challenge verification: %w", err)
+	}
+	return result.Success, nil
+}
+
+// challengeVerifierFromEnv builds the verifier from ABUSE_CHALLENGE_PROVIDER (recaptcha, hcaptcha
+// or turnstile), ABUSE_CHALLENGE_SITE_KEY, ABUSE_CHALLENGE_SECRET and ABUSE_CHALLENGE_VERIFY_URL.
+// It returns nil when no provider is configured.
+func challengeVerifierFromEnv() ChallengeVerifier {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("ABUSE_CHALLENGE_PROVIDER")))
+	if provider == "" {
+		return nil
+	}
+	verifier, err := NewSiteVerifyVerifier(provider, os.Getenv("ABUSE_CHALLENGE_SITE_KEY"), os.Getenv("ABUSE_CHALLENGE_SECRET"), os.Getenv("ABUSE_CHALLENGE_VERIFY_URL"))
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid ABUSE_CHALLENGE_PROVIDER, challenges disabled")
+		return nil
+	}
+	return verifier
+}
+
+// abuseCreation is one session created by a client
+type abuseCreation struct {
+	at          time.Time
+	sessionID   string
+	topic       string // Normalized
+	fingerprint string
+}
+
+// abuseRecord is what is known about one client within the window
+type abuseRecord struct {
+	creations        []abuseCreation
+	failedChallenges []time.Time
+	verifiedUntil    time.Time
+	lastSeen         time.Time
+}
+
+// prune drops events that have left the window
+func (r *abuseRecord) prune(cutoff time.Time) {
+	kept := r.creations[:0]
+	for _, creation := range r.creations {
+		if creation.at.After(cutoff) {
+			kept = append(kept, creation)
+		}
+	}
+	r.creations = kept
+
+	failed := r.failedChallenges[:0]
+	for _, at := range r.failedChallenges {
+		if at.After(cutoff) {
+			failed = append(failed, at)
+		}
+	}
+	r.failedChallenges = failed
+}
+
+// AbuseAssessment is the score of a session creation request and what contributed to it
+type AbuseAssessment struct {
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// add adds points for a reason
+func (a *AbuseAssessment) add(points int, reason string) {
+	if points <= 0 {
+		return
+	}
+	a.Score += points
+	a.Reasons = append(a.Reasons, reason)
+}
+
+// abuseDetector scores anonymous session creation per client over a sliding window
+type abuseDetector struct {
+	config   AbuseConfig
+	verifier ChallengeVerifier // nil disables challenges; suspicious clients are then only logged
+	mu       sync.Mutex
+	records  map[string]*abuseRecord // Client key -> record
+	now      func() time.Time
+}
+
+// newAbuseDetector creates a detector; it returns nil when detection is disabled
+func newAbuseDetector(config AbuseConfig, verifier ChallengeVerifier) *abuseDetector {
+	if !config.Enabled {
+		return nil
+	}
+	return &abuseDetector{
+		config:   config,
+		verifier: verifier,
+		records:  make(map[string]*abuseRecord),
+		now:      time.Now,
+	}
+}
+
+// abuseClient identifies a screened client
+type abuseClient struct {
+	key         string // Proxy-resolved IP the client is scored under
+	fingerprint string // Device fingerprint, an extra signal only
+}
+
+// abuseClientKey identifies a client by its proxy-resolved IP. The fingerprint is client
+// controlled, so keying on it would let a client reset its score by changing headers.
+func abuseClientKey(r *http.Request) string {
+	return clientIP(r)
+}
+
+// abuseFingerprint returns the client's device fingerprint. Without an explicit fingerprint
+// header, the user agent and accepted languages stand in for one.
+func abuseFingerprint(r *http.Request) string {
+	if fingerprint := r.Header.Get(fingerprintHeader); fingerprint != "" {
+		return fingerprint
+	}
+	sum := sha256.Sum256([]byte(r.UserAgent() + "|" + r.Header.Get("Accept-Language")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// normalizeAbuseTopic folds case and whitespace so trivially varied topics compare equal
+func normalizeAbuseTopic(topic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(topic)), " ")
+}
+
+// topicSpamAssessment scores a topic on its own: links, excessive length, long runs of one
+// character and mostly non-letter text are typical of spam rather than learning topics
+func topicSpamAssessment(topic string) AbuseAssessment {
+	var assessment AbuseAssessment
+	if topicLinkPattern.MatchString(topic) {
+		assessment.add(abuseTopicLinkPoints, "topic contains a link")
+	}
+	if len([]rune(topic)) > abuseTopicMaxLength {
+		assessment.add(abuseTopicLengthPoints, "topic is unusually long")
+	}
+
+	letters, others, run, longestRun := 0, 0, 0, 0
+	var previous rune
+	for _, r := range topic {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			letters++
+		case !unicode.IsSpace(r):
+			others++
+		}
+		if r == previous && !unicode.IsSpace(r) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longestRun {
+			longestRun = run
+		}
+		previous = r
+	}
+	if longestRun >= 8 {
+		assessment.add(abuseTopicRepeatCharPoints, "topic repeats a character")
+	}
+	if others > 0 && others >= letters {
+		assessment.add(abuseTopicSymbolPoints, "topic is mostly symbols")
+	}
+	return assessment
+}
+
+// assess scores a client about to create a session for topic from the device with fingerprint.
+// statusOf returns the status of a session the client created earlier, to spot sessions created
+// and never run.
+func (d *abuseDetector) assess(key, fingerprint, topic string, statusOf func(sessionID string) string) AbuseAssessment {
+	now := d.now()
+	normalized := normalizeAbuseTopic(topic)
+
+	d.mu.Lock()
+	var creations []abuseCreation
+	failed := 0
+	if record, ok := d.records[key]; ok {
+		record.prune(now.Add(-d.config.Window))
+		creations = append(creations, record.creations...)
+		failed = len(record.failedChallenges)
+	}
+	d.mu.Unlock()
+
+	assessment := topicSpamAssessment(topic)
+	assessment.add((len(creations)+1-abuseVolumeFreeSessions)*abuseVolumePoints, "many sessions created")
+
+	disposable, repeats := 0, 0
+	fingerprints := map[string]bool{fingerprint: true}
+	for _, creation := range creations {
+		fingerprints[creation.fingerprint] = true
+		if statusOf(creation.sessionID) == "created" {
+			disposable++
+		}
+		if creation.topic == normalized {
+			repeats++
+		}
+	}
+	assessment.add((disposable-abuseDisposableFreeSessions)*abuseDisposablePoints, "sessions created and never run")
+	assessment.add((repeats+1-abuseRepeatFreeTopics)*abuseRepeatPoints, "same topic requested repeatedly")
+	assessment.add((len(fingerprints)-abuseFreeFingerprints)*abuseFingerprintPoints, "many device fingerprints")
+	assessment.add(failed*abuseFailedChallengePoints, "failed challenges")
+	return assessment
+}
+
+// record returns the client's record, creating it and sweeping idle clients as needed. d.mu must be held.
+func (d *abuseDetector) record(key string, now time.Time) *abuseRecord {
+	record, ok := d.records[key]
+	if !ok {
+		if len(d.records) >= abuseSweepSize {
+			for k, r := range d.records {
+				if now.Sub(r.lastSeen) > d.config.Window && now.After(r.verifiedUntil) {
+					delete(d.records, k)
+				}
+			}
+		}
+		record = &abuseRecord{}
+		d.records[key] = record
+	}
+	record.lastSeen = now
+	return record
+}
+
+// recordCreation records a session the client created
+func (d *abuseDetector) recordCreation(key, fingerprint, sessionID, topic string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	record := d.record(key, now)
+	record.creations = append(record.creations, abuseCreation{at: now, sessionID: sessionID, topic: normalizeAbuseTopic(topic), fingerprint: fingerprint})
+}
+
+// verified reports whether the client passed a challenge recently
+func (d *abuseDetector) verified(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	record, ok := d.records[key]
+	return ok && d.now().Before(record.verifiedUntil)
+}
+
+// recordChallenge records the outcome of a challenge answer
+func (d *abuseDetector) recordChallenge(key string, passed bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	record := d.record(key, now)
+	if passed {
+		record.verifiedUntil = now.Add(d.config.VerifiedFor)
+	} else {
+		record.failedChallenges = append(record.failedChallenges, now)
+	}
+}
+
+// screenSessionCreation scores an anonymous session creation request. Clients above the
+// challenge score must answer a challenge in the X-Challenge-Token header; clients above the
+// block score are rejected. It writes the response and returns ok=false when the request must
+// not proceed, and otherwise returns the client to record the created session under.
+// Authenticated requests are not scored.
+func (o *Orchestrator) screenSessionCreation(w http.ResponseWriter, r *http.Request, topic string) (client abuseClient, ok bool) {
+	if o.abuse == nil {
+		return abuseClient{}, true
+	}
+	if principal, _ := auth.PrincipalFromContext(r.Context()); principal != nil {
+		return abuseClient{}, true
+	}
+
+	key := abuseClientKey(r)
+	client = abuseClient{key: key, fingerprint: abuseFingerprint(r)}
+	assessment := o.abuse.assess(key, client.fingerprint, topic, func(sessionID string) string {
+		status, _ := o.sessionStatus(sessionID)
+		return status.Status
+	})
+	fields := logrus.Fields{
+		"ip":      clientIP(r),
+		"score":   assessment.Score,
+		"reasons": assessment.Reasons,
+	}
+
+	if assessment.Score >= o.abuse.config.BlockScore {
+		o.logger.WithFields(fields).Warn("Session creation blocked as abusive")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", strconv.Itoa(int(o.abuse.config.Window.Seconds())))
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":       "Too many suspicious requests",
+			"message":     "Session creation from this client is temporarily blocked. Please try again later.",
+			"retry_after": int(o.abuse.config.Window.Seconds()),
+			"quota_type":  "abuse",
+		})
+		return abuseClient{}, false
+	}
+
+	if assessment.Score < o.abuse.config.ChallengeScore || o.abuse.verified(key) {
+		return client, true
+	}
+	if o.abuse.verifier == nil {
+		o.logger.WithFields(fields).Warn("Suspicious session creation allowed, no challenge verifier is configured")
+		return client, true
+	}
+
+	if token := r.Header.Get(challengeTokenHeader); token != "" {
+		passed, err := o.abuse.verifier.Verify(r.Context(), token, clientIP(r))
+		if err != nil {
+			// A verifier outage must not lock out clients that did solve the challenge
+			o.logger.WithFields(fields).WithError(err).Warn("Failed to verify challenge, allowing request")
+			return client, true
+		}
+		o.abuse.recordChallenge(key, passed)
+		if passed {
+			return client, true
+		}
+		o.logger.WithFields(fields).Warn("Challenge answer rejected")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":     "Challenge required",
+		"message":   "Please complete the challenge and retry with its token in the " + challengeTokenHeader + " header.",
+		"challenge": o.abuse.verifier.Challenge(),
+	})
+	return abuseClient{}, false
+}
+
+// recordSessionCreation counts a created session against the client it was screened for
+func (o *Orchestrator) recordSessionCreation(client abuseClient, sessionID, topic string) {
+	if o.abuse == nil || client.key == "" {
+		return
+	}
+	o.abuse.recordCreation(client.key, client.fingerprint, sessionID, topic)
+}
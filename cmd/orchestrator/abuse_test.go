@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChallengeVerifier accepts a single token
+type fakeChallengeVerifier struct {
+	valid string
+	calls int
+}
+
+// Challenge implements ChallengeVerifier
+func (f *fakeChallengeVerifier) Challenge() map[string]string {
+	return map[string]string{"provider": "fake", "site_key": "site"}
+}
+
+// Verify implements ChallengeVerifier
+func (f *fakeChallengeVerifier) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	f.calls++
+	return token == f.valid, nil
+}
+
+// newAbuseTestOrchestrator creates an orchestrator that scores session creation
+func newAbuseTestOrchestrator(verifier ChallengeVerifier) *Orchestrator {
+	return &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+		abuse: newAbuseDetector(AbuseConfig{
+			Enabled:        true,
+			Window:         time.Minute,
+			ChallengeScore: 50,
+			BlockScore:     150,
+			VerifiedFor:    time.Minute,
+		}, verifier),
+	}
+}
+
+// postCreateSession creates a session for topic from a fixed client, answering a challenge with token if set
+func postCreateSession(o *Orchestrator, topic, token string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(CreateSessionRequest{Topic: topic})
+	req := httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body))
+	req.RemoteAddr = "203.0.113.7:4000"
+	req.Header.Set("User-Agent", "flood-bot/1.0")
+	if token != "" {
+		req.Header.Set(challengeTokenHeader, token)
+	}
+	w := httptest.NewRecorder()
+	o.createSessionHandler(w, req)
+	return w
+}
+
+// TestAbuseChallengeAndBlock tests that a flood of never-run sessions is challenged, then blocked
+func TestAbuseChallengeAndBlock(t *testing.T) {
+	verifier := &fakeChallengeVerifier{valid: "solved"}
+	o := newAbuseTestOrchestrator(verifier)
+
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusCreated, postCreateSession(o, "Caching", "").Code, "request %d", i)
+	}
+
+	w := postCreateSession(o, "Caching", "")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"site_key":"site"`)
+	assert.Zero(t, verifier.calls)
+
+	assert.Equal(t, http.StatusForbidden, postCreateSession(o, "Caching", "wrong").Code)
+	assert.Equal(t, http.StatusCreated, postCreateSession(o, "Caching", "solved").Code)
+	assert.Equal(t, 2, verifier.calls)
+
+	// A passed challenge holds for later requests until the block score is reached
+	assert.Equal(t, http.StatusCreated, postCreateSession(o, "Caching", "").Code)
+	w = postCreateSession(o, "Caching", "solved")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), `"quota_type":"abuse"`)
+	assert.Len(t, o.sessions, 7)
+}
+
+// TestAbuseFingerprintRotation tests that changing device fingerprints does not reset a client's
+// score, and that rotating through many of them adds to it
+func TestAbuseFingerprintRotation(t *testing.T) {
+	o := newAbuseTestOrchestrator(&fakeChallengeVerifier{valid: "solved"})
+	// One device could create these five sessions unchallenged; five devices cannot
+	topics := []string{"Caching", "Queues", "Sharding", "Raft", "Paxos"}
+	for i, topic := range topics {
+		body, _ := json.Marshal(CreateSessionRequest{Topic: topic})
+		req := httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body))
+		req.RemoteAddr = "203.0.113.7:" + strconv.Itoa(4000+i)
+		req.Header.Set(fingerprintHeader, "device-"+strconv.Itoa(i))
+		w := httptest.NewRecorder()
+		o.createSessionHandler(w, req)
+		if i < len(topics)-1 {
+			require.Equal(t, http.StatusCreated, w.Code, topic)
+		} else {
+			assert.Equal(t, http.StatusForbidden, w.Code, topic)
+		}
+	}
+
+	assessment := o.abuse.assess("203.0.113.7", "device-9", "Consensus", func(string) string { return "completed" })
+	assert.Contains(t, assessment.Reasons, "many device fingerprints")
+	assert.Len(t, o.abuse.records, 1)
+}
+
+// TestAbuseSkipsAuthenticatedAndRunSessions tests that authenticated callers are not scored
+// and that sessions which were run do not count as disposable
+func TestAbuseSkipsAuthenticatedAndRunSessions(t *testing.T) {
+	o := newAbuseTestOrchestrator(&fakeChallengeVerifier{})
+	principal := &auth.Principal{UserID: "u1", Method: auth.MethodJWT}
+	for i := 0; i < 10; i++ {
+		body, _ := json.Marshal(CreateSessionRequest{Topic: "Caching"})
+		req := httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body))
+		req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+		w := httptest.NewRecorder()
+		o.createSessionHandler(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	o = newAbuseTestOrchestrator(&fakeChallengeVerifier{})
+	topics := []string{"Caching", "Queues", "Sharding", "Raft", "Paxos", "Gossip"}
+	for _, topic := range topics {
+		require.Equal(t, http.StatusCreated, postCreateSession(o, topic, "").Code, topic)
+		for _, session := range o.sessions {
+			session.Status = "completed"
+		}
+	}
+}
+
+// TestTopicSpamAssessment tests the topic heuristics
+func TestTopicSpamAssessment(t *testing.T) {
+	assert.Zero(t, topicSpamAssessment("How does TCP congestion control work?").Score)
+	assert.Equal(t, abuseTopicLinkPoints, topicSpamAssessment("cheap pills at http://spam.example").Score)
+	assert.Equal(t, abuseTopicLinkPoints, topicSpamAssessment("buy now at cheap-pills.xyz").Score)
+	assert.Equal(t, abuseTopicRepeatCharPoints, topicSpamAssessment("aaaaaaaaaaaa").Score)
+	assert.Equal(t, abuseTopicSymbolPoints, topicSpamAssessment("$$$ !!! ###").Score)
+	assert.Equal(t, abuseTopicLengthPoints, topicSpamAssessment(string(bytes.Repeat([]byte("ab "), 120))).Score)
+}
+
+// TestSiteVerifyVerifier tests token verification against a siteverify endpoint
+func TestSiteVerifyVerifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("secret"))
+		assert.Equal(t, "203.0.113.7", r.PostForm.Get("remoteip"))
+		json.NewEncoder(w).Encode(map[string]bool{"success": r.PostForm.Get("response") == "good"})
+	}))
+	defer server.Close()
+
+	verifier, err := NewSiteVerifyVerifier("turnstile", "site", "secret", server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "turnstile", verifier.Challenge()["provider"])
+
+	ok, err := verifier.Verify(context.Background(), "good", "203.0.113.7")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = verifier.Verify(context.Background(), "bad", "203.0.113.7")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = NewSiteVerifyVerifier("unknown", "", "", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// extractAccessibility builds the accessibility metadata for the visualizer's images,
+// repairing any image that arrived without alt text. Returns nil when there are no images.
+func (p *Pipeline) extractAccessibility(finalResult map[string]interface{}, topic string) *llm.AccessibilityInfo {
+	visualizer, exists := finalResult["visualizer"]
+	if !exists {
+		return nil
+	}
+	visualizerMap, ok := visualizer.(map[string]string)
+	if !ok {
+		return nil
+	}
+	imagesJSON, exists := visualizerMap["images"]
+	if !exists {
+		return nil
+	}
+
+	var images []llm.ImageRef
+	if err := json.Unmarshal([]byte(imagesJSON), &images); err != nil || len(images) == 0 {
+		return nil
+	}
+
+	images, missing := llm.EnsureImageAccessibility(images, topic)
+	if len(missing) > 0 {
+		p.logger.WithFields(logrus.Fields{
+			"topic":   topic,
+			"missing": missing,
+		}).Warn("Visualizer returned images without alt text")
+	}
+
+	return llm.BuildAccessibilityInfo(images, len(missing))
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractAccessibility(t *testing.T) {
+	p := &Pipeline{logger: logrus.New()}
+
+	finalResult := map[string]interface{}{
+		"visualizer": map[string]string{
+			"images": `[
+				{"url": "https://example.com/1.png", "alt_text": "Stage diagram", "long_description": "Three stages in a row."},
+				{"url": "https://example.com/2.png", "alt_text": "", "caption": "Data flow"},
+				{"url": "https://example.com/3.png"}
+			]`,
+		},
+	}
+
+	info := p.extractAccessibility(finalResult, "pipelines")
+	require.NotNil(t, info)
+	require.Len(t, info.Images, 3)
+	assert.Equal(t, "Stage diagram", info.Images[0].AltText)
+	assert.Equal(t, "Data flow", info.Images[1].AltText)
+	assert.Equal(t, "Diagram 3 for pipelines", info.Images[2].AltText)
+	assert.Equal(t, 2, info.RepairedAltText)
+	assert.False(t, info.Complete)
+}
+
+func TestExtractAccessibilityNoImages(t *testing.T) {
+	p := &Pipeline{logger: logrus.New()}
+
+	assert.Nil(t, p.extractAccessibility(map[string]interface{}{}, "topic"))
+	assert.Nil(t, p.extractAccessibility(map[string]interface{}{
+		"visualizer": map[string]string{"images": "[]"},
+	}, "topic"))
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// allSessionsSubscription is the client list key of subscribers to every session's events
+	allSessionsSubscription = "*"
+	// firehoseBuffer is the event buffer of a firehose client, larger than a single session's
+	// since it carries the events of every session
+	firehoseBuffer = 256
+)
+
+// firehoseFilter selects the sessions an admin event stream follows
+type firehoseFilter struct {
+	statuses map[string]bool // Empty matches any status
+	userID   string          // Empty matches any user
+}
+
+// parseFirehoseFilter reads the status (comma-separated or repeated) and user_id query parameters
+func parseFirehoseFilter(r *http.Request) firehoseFilter {
+	filter := firehoseFilter{
+		statuses: make(map[string]bool),
+		userID:   r.URL.Query().Get("user_id"),
+	}
+	for _, value := range r.URL.Query()["status"] {
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				filter.statuses[status] = true
+			}
+		}
+	}
+	return filter
+}
+
+// matchesLocked reports whether a session passes the filter; the caller must hold o.mu
+func (f firehoseFilter) matchesLocked(session *Session) bool {
+	if len(f.statuses) > 0 && !f.statuses[session.Status] {
+		return false
+	}
+	if f.userID != "" {
+		owner, _ := session.Metadata["user_id"].(string)
+		return owner == f.userID
+	}
+	return true
+}
+
+// firehoseEvictedEvent builds the terminal event written to a firehose client that fell behind
+func firehoseEvictedEvent(dropped int) SSEEvent {
+	return SSEEvent{
+		Type: streamEvictedType,
+		Data: map[string]interface{}{
+			"error":          "Client fell too far behind and was disconnected",
+			"dropped_events": dropped,
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// adminEventStreamHandler handles GET /api/admin/events/stream
+// It streams the events of every session, starting with a snapshot of each session in
+// progress. The status and user_id query parameters restrict it to matching sessions; a
+// session that matched once is followed until it finishes, so viewers of running sessions
+// also see them complete.
+func (o *Orchestrator) adminEventStreamHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+	filter := parseFirehoseFilter(r)
+
+	// Subscribe before taking the snapshots so no event falls between them
+	client := make(chan SSEEvent, firehoseBuffer)
+	o.AddClient(allSessionsSubscription, client)
+	defer o.RemoveClient(allSessionsSubscription, client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	following := make(map[string]bool)
+	o.mu.RLock()
+	active := make([]*Session, 0)
+	for _, session := range o.sessions {
+		if sessionInProgress(session) && filter.matchesLocked(session) {
+			active = append(active, session)
+			following[session.ID] = true
+		}
+	}
+	o.mu.RUnlock()
+	sort.Slice(active, func(i, j int) bool { return active[i].CreatedAt.Before(active[j].CreatedAt) })
+
+	for _, session := range active {
+		if status, exists := o.sessionStatus(session.ID); exists {
+			writeSSEEvent(w, flusher, snapshotEvent(status))
+		}
+	}
+	if len(active) == 0 {
+		flusher.Flush()
+	}
+
+	for {
+		select {
+		case event, ok := <-client:
+			if !ok {
+				writeSSEEvent(w, flusher, firehoseEvictedEvent(o.clientDroppedEvents(client)))
+				return
+			}
+			if event.Type != eventsDroppedType && !following[event.SessionID] {
+				o.mu.RLock()
+				session, exists := o.sessions[event.SessionID]
+				matches := exists && filter.matchesLocked(session)
+				o.mu.RUnlock()
+				if !matches {
+					continue
+				}
+				following[event.SessionID] = true
+			}
+			writeSSEEvent(w, flusher, event)
+
+			if event.Type == "session_complete" || event.Type == "session_error" || event.Type == "session_cancelled" {
+				delete(following, event.SessionID)
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
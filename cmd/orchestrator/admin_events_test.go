@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitFirehoseDrained waits until the firehose handler has handled every broadcast event.
+// A filtered-out sentinel is sent last: once the handler takes it, all earlier events are done.
+func waitFirehoseDrained(t *testing.T, o *Orchestrator) {
+	o.BroadcastEvent("b", SSEEvent{Type: "sentinel", SessionID: "b"})
+	require.Eventually(t, func() bool {
+		o.clientsMu.RLock()
+		defer o.clientsMu.RUnlock()
+		return len(o.clients[allSessionsSubscription][0]) == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+// TestAdminEventStream tests that the firehose snapshots active sessions, then follows matching ones live
+func TestAdminEventStream(t *testing.T) {
+	now := time.Now()
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"a": {ID: "a", Status: "running", CreatedAt: now, Metadata: map[string]interface{}{"user_id": "u1"}},
+			"b": {ID: "b", Status: "running", CreatedAt: now.Add(time.Second), Metadata: map[string]interface{}{"user_id": "u2"}},
+			"c": {ID: "c", Status: "created", CreatedAt: now.Add(2 * time.Second), Metadata: map[string]interface{}{"user_id": "u1"}},
+			"d": {ID: "d", Status: "completed", CreatedAt: now.Add(-time.Hour), Metadata: map[string]interface{}{"user_id": "u1"}},
+		},
+		logger:      logrus.New(),
+		clients:     make(map[string][]chan SSEEvent),
+		clientStats: make(map[chan SSEEvent]*sseClientStats),
+	}
+	r := chi.NewRouter()
+	r.Get("/api/admin/events/stream", o.adminEventStreamHandler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/events/stream?status=running&user_id=u1", nil).WithContext(ctx))
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		o.clientsMu.RLock()
+		defer o.clientsMu.RUnlock()
+		return len(o.clients[allSessionsSubscription]) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	o.BroadcastEvent("a", SSEEvent{Type: "step_complete", SessionID: "a"})
+	o.BroadcastEvent("b", SSEEvent{Type: "step_complete", SessionID: "b"})
+	o.BroadcastEvent("c", SSEEvent{Type: "step_complete", SessionID: "c"})
+	waitFirehoseDrained(t, o)
+
+	// c matches once it starts running; a stays followed after it completes
+	o.mu.Lock()
+	o.sessions["c"].Status = "running"
+	o.sessions["a"].Status = "completed"
+	o.mu.Unlock()
+	o.BroadcastEvent("c", SSEEvent{Type: "step_started", SessionID: "c"})
+	o.BroadcastEvent("a", SSEEvent{Type: "session_complete", SessionID: "a"})
+	o.BroadcastEvent("a", SSEEvent{Type: "late_event", SessionID: "a"})
+
+	waitFirehoseDrained(t, o)
+	cancel()
+	<-done
+
+	events := readSSEEvents(t, w.Body.String())
+	require.Len(t, events, 4)
+	assert.Equal(t, "session_snapshot", events[0].Type)
+	assert.Equal(t, "a", events[0].SessionID)
+	assert.Equal(t, "step_complete", events[1].Type)
+	assert.Equal(t, "a", events[1].SessionID)
+	assert.Equal(t, "c", events[2].SessionID)
+	assert.Equal(t, "session_complete", events[3].Type)
+	assert.Empty(t, o.clients[allSessionsSubscription], "the viewer unsubscribes when the stream ends")
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// agentCapabilityTimeout bounds how long startup waits for an agent to report its capabilities
+const agentCapabilityTimeout = 5 * time.Second
+
+// capabilityReporter is implemented by agent clients that can fetch what their agent advertises
+type capabilityReporter interface {
+	Capabilities(ctx context.Context) (*adk.Capabilities, error)
+}
+
+// protocolNegotiator is implemented by agent clients that can settle on an ADK protocol version
+type protocolNegotiator interface {
+	NegotiateProtocolVersion(advertised []string) (string, error)
+}
+
+// negotiateAgentCapabilities fetches the capabilities of each step's agent, checks that the
+// agent advertises the step it is assigned and settles the ADK protocol version each client
+// speaks. Agents that cannot be reached or advertise nothing are not checked. It returns the
+// advertised capabilities by agent name.
+func negotiateAgentCapabilities(ctx context.Context, steps []PipelineStep, clients map[string]AgentClient, logger *logrus.Logger) (map[string]*adk.Capabilities, error) {
+	var (
+		mu           sync.Mutex
+		wg           sync.WaitGroup
+		capabilities = make(map[string]*adk.Capabilities)
+		mismatches   []string
+	)
+	for name, client := range clients {
+		reporter, ok := client.(capabilityReporter)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(name string, reporter capabilityReporter) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, agentCapabilityTimeout)
+			defer cancel()
+
+			advertised, err := reporter.Capabilities(ctx)
+			if err != nil {
+				logger.WithFields(logrus.Fields{
+					"agent": name,
+					"error": err,
+				}).Warn("Could not read agent capabilities, its step assignment is not checked")
+				return
+			}
+			if negotiator, ok := reporter.(protocolNegotiator); ok {
+				var versions []string
+				if advertised != nil {
+					versions = advertised.ProtocolVersions
+				}
+				version, err := negotiator.NegotiateProtocolVersion(versions)
+				if err != nil {
+					mu.Lock()
+					mismatches = append(mismatches, fmt.Sprintf("agent %s: %v", name, err))
+					mu.Unlock()
+					return
+				}
+				logger.WithFields(logrus.Fields{
+					"agent":            name,
+					"protocol_version": version,
+				}).Info("Negotiated ADK protocol version")
+			}
+			if advertised == nil {
+				logger.WithField("agent", name).Info("Agent advertises no capabilities, its step assignment is not checked")
+				return
+			}
+			mu.Lock()
+			capabilities[name] = advertised
+			mu.Unlock()
+		}(name, reporter)
+	}
+	wg.Wait()
+
+	for _, step := range steps {
+		advertised, ok := capabilities[step.Agent]
+		if !ok {
+			continue
+		}
+		if !advertised.SupportsStep(step.Name) {
+			mismatches = append(mismatches, fmt.Sprintf("step %s is assigned to agent %s, which supports %v", step.Name, step.Agent, advertised.Steps))
+		}
+	}
+	if len(mismatches) > 0 {
+		return capabilities, fmt.Errorf("agent capabilities do not match the pipeline: %s", strings.Join(mismatches, "; "))
+	}
+	return capabilities, nil
+}
+
+// supportsExplanationType reports whether the explainer advertises the explanation type.
+// Explainers that advertise nothing are assumed to support every type.
+func (p *Pipeline) supportsExplanationType(explanationType string) bool {
+	if p == nil {
+		return true
+	}
+	advertised, ok := p.agentCapabilities["explainer"]
+	if !ok {
+		return true
+	}
+	return advertised.SupportsExplanationType(explanationType)
+}
+
+// fitContext trims a step's context to the largest context its agent advertises accepting,
+// in characters and in tokens of the step's model
+func (p *Pipeline) fitContext(ctx context.Context, sessionID string, step PipelineStep, contextText string) string {
+	advertised, ok := p.agentCapabilities[step.Agent]
+	if !ok {
+		return contextText
+	}
+	if advertised.MaxContextTokens > 0 {
+		model := step.Inputs["model"]
+		if model == "" {
+			model = llm.DefaultModel
+		}
+		if trimmed := p.tokenCounter.Truncate(ctx, model, contextText, advertised.MaxContextTokens); len(trimmed) < len(contextText) {
+			p.logger.WithFields(logrus.Fields{
+				"session_id":         sessionID,
+				"step":               step.Name,
+				"model":              model,
+				"max_context_tokens": advertised.MaxContextTokens,
+			}).Warn("Context exceeds the agent's token budget, trimming it")
+			contextText = trimmed
+		}
+	}
+	if advertised.MaxContextChars <= 0 || len(contextText) <= advertised.MaxContextChars {
+		return contextText
+	}
+
+	// Cut at a rune boundary
+	end := advertised.MaxContextChars
+	for end > 0 && !utf8.RuneStart(contextText[end]) {
+		end--
+	}
+	trimmed := contextText[:end]
+	p.logger.WithFields(logrus.Fields{
+		"session_id":        sessionID,
+		"step":              step.Name,
+		"context_chars":     len(contextText),
+		"max_context_chars": advertised.MaxContextChars,
+	}).Warn("Context exceeds the agent's advertised maximum, trimming it")
+	return trimmed
+}
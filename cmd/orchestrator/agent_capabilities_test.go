@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/InnoFusionTech/ExplainIQ/internal/tokens"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capableAgentClient is an agent client that advertises fixed capabilities
+type capableAgentClient struct {
+	usageAgentClient
+	capabilities *adk.Capabilities
+	err          error
+}
+
+// Capabilities implements capabilityReporter
+func (c *capableAgentClient) Capabilities(ctx context.Context) (*adk.Capabilities, error) {
+	return c.capabilities, c.err
+}
+
+// TestNegotiateAgentCapabilities tests validating step assignments against advertised capabilities
+func TestNegotiateAgentCapabilities(t *testing.T) {
+	steps := pipelineDefinition("")
+	clients := map[string]AgentClient{
+		"summarizer": &capableAgentClient{capabilities: &adk.Capabilities{Steps: []string{"summarizer"}}},
+		"explainer":  &capableAgentClient{capabilities: &adk.Capabilities{Steps: []string{"explainer"}, MaxContextChars: 10}},
+		"visualizer": &capableAgentClient{err: errors.New("connection refused")}, // Unreachable agents are not checked
+		"critic":     &usageAgentClient{},                                        // Nor are clients that cannot report
+	}
+
+	capabilities, err := negotiateAgentCapabilities(context.Background(), steps, clients, logrus.New())
+	require.NoError(t, err)
+	assert.Len(t, capabilities, 2)
+	assert.Equal(t, 10, capabilities["explainer"].MaxContextChars)
+
+	// An agent deployed in the wrong slot fails startup
+	clients["explainer"] = &capableAgentClient{capabilities: &adk.Capabilities{Steps: []string{"critic"}}}
+	_, err = negotiateAgentCapabilities(context.Background(), steps, clients, logrus.New())
+	assert.ErrorContains(t, err, "step explainer is assigned to agent explainer, which supports [critic]")
+}
+
+// negotiatingAgentClient is a capable agent client that negotiates the ADK protocol version
+type negotiatingAgentClient struct {
+	capableAgentClient
+	negotiated string
+}
+
+// NegotiateProtocolVersion implements protocolNegotiator
+func (c *negotiatingAgentClient) NegotiateProtocolVersion(advertised []string) (string, error) {
+	version, err := adk.NegotiateProtocolVersion(advertised)
+	c.negotiated = version
+	return version, err
+}
+
+// TestNegotiateAgentProtocolVersion tests that agents without a common protocol version fail startup
+func TestNegotiateAgentProtocolVersion(t *testing.T) {
+	steps := pipelineDefinition("")
+	explainer := &negotiatingAgentClient{capableAgentClient: capableAgentClient{capabilities: &adk.Capabilities{
+		Steps: []string{"explainer"}, ProtocolVersions: []string{adk.ProtocolVersion1, "9.0"},
+	}}}
+	legacy := &negotiatingAgentClient{capableAgentClient: capableAgentClient{capabilities: &adk.Capabilities{Steps: []string{"summarizer"}}}}
+	clients := map[string]AgentClient{"summarizer": legacy, "explainer": explainer}
+
+	_, err := negotiateAgentCapabilities(context.Background(), steps, clients, logrus.New())
+	require.NoError(t, err)
+	assert.Equal(t, adk.ProtocolVersion1, explainer.negotiated)
+	assert.Equal(t, adk.ProtocolVersion1, legacy.negotiated, "agents advertising no versions speak 1.0")
+
+	clients["explainer"] = &negotiatingAgentClient{capableAgentClient: capableAgentClient{capabilities: &adk.Capabilities{
+		Steps: []string{"explainer"}, ProtocolVersions: []string{"2.0"},
+	}}}
+	_, err = negotiateAgentCapabilities(context.Background(), steps, clients, logrus.New())
+	assert.ErrorContains(t, err, "agent explainer: incompatible ADK protocol: peer speaks 2.0")
+}
+
+// TestFitContextToAgent tests trimming context to the agent's advertised maximum
+func TestFitContextToAgent(t *testing.T) {
+	p := &Pipeline{
+		logger:            logrus.New(),
+		agentCapabilities: map[string]*adk.Capabilities{"explainer": {Steps: []string{"explainer"}, MaxContextChars: 5}},
+	}
+	explainer := PipelineStep{Name: "explainer", Agent: "explainer"}
+
+	ctx := context.Background()
+	assert.Equal(t, "short", p.fitContext(ctx, "s1", explainer, "short"))
+	assert.Equal(t, "abcd", p.fitContext(ctx, "s1", explainer, "abcdé and more"), "trimmed at a rune boundary")
+	assert.Equal(t, strings.Repeat("x", 50), p.fitContext(ctx, "s1", PipelineStep{Name: "summarizer", Agent: "summarizer"}, strings.Repeat("x", 50)))
+}
+
+// TestFitContextToTokenBudget tests trimming context to the agent's advertised token budget for the step's model
+func TestFitContextToTokenBudget(t *testing.T) {
+	p := &Pipeline{
+		logger:            logrus.New(),
+		agentCapabilities: map[string]*adk.Capabilities{"explainer": {Steps: []string{"explainer"}, MaxContextTokens: 10, MaxContextChars: 1000}},
+	}
+	explainer := PipelineStep{Name: "explainer", Agent: "explainer", Inputs: map[string]string{}}
+
+	contextText := strings.Repeat("Caches keep hot data close. ", 10)
+	trimmed := p.fitContext(context.Background(), "s1", explainer, contextText)
+	assert.True(t, strings.HasPrefix(contextText, trimmed))
+	assert.Equal(t, 10, tokens.Estimate(llm.DefaultModel, trimmed))
+
+	// Digits cost more for Gemini, so the same budget fits fewer of them
+	numbers := strings.Repeat("1024 ", 10)
+	assert.Equal(t, "1024 1024 10", p.fitContext(context.Background(), "s1", explainer, numbers))
+	explainer.Inputs["model"] = "gpt-4o"
+	assert.Equal(t, numbers, p.fitContext(context.Background(), "s1", explainer, numbers))
+}
+
+// TestCreateSessionRejectsUnsupportedExplanationType tests checking explanation types against the explainer
+func TestCreateSessionRejectsUnsupportedExplanationType(t *testing.T) {
+	o := &Orchestrator{
+		sessions:  make(map[string]*Session),
+		logger:    logrus.New(),
+		metaIndex: newMetadataIndex(),
+		pipeline: &Pipeline{agentCapabilities: map[string]*adk.Capabilities{
+			"explainer": {Steps: []string{"explainer"}, ExplanationTypes: []string{"standard", "analogy"}},
+		}},
+	}
+	router := chi.NewRouter()
+	router.Post("/api/sessions", o.createSessionHandler)
+
+	w := serveWithKey(router, "POST", "/api/sessions", "", `{"topic": "Caching", "explanation_type": "interpretive-dance"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "not supported by the explainer")
+
+	assert.Equal(t, http.StatusCreated, serveWithKey(router, "POST", "/api/sessions", "", `{"topic": "Caching", "explanation_type": "analogy"}`).Code)
+}
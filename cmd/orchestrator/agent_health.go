@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultAgentHealthInterval is how often registered agents are health-checked
+	defaultAgentHealthInterval = 30 * time.Second
+	// defaultAgentFailureThreshold is how many consecutive failed checks mark an agent unavailable
+	defaultAgentFailureThreshold = 3
+	// defaultAgentUnavailableWait is how long a step waits for its unavailable agent to recover
+	defaultAgentUnavailableWait = 30 * time.Second
+	// agentHealthCheckTimeout bounds a single agent health check
+	agentHealthCheckTimeout = 5 * time.Second
+)
+
+// agentHealthIntervalFromEnv returns the agent health check interval (AGENT_HEALTH_INTERVAL); 0 disables checks
+func agentHealthIntervalFromEnv() time.Duration {
+	if v := os.Getenv("AGENT_HEALTH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			return d
+		}
+		logrus.WithField("value", v).Warn("Invalid AGENT_HEALTH_INTERVAL, using default")
+	}
+	return defaultAgentHealthInterval
+}
+
+// agentFailureThresholdFromEnv returns the failed checks that mark an agent unavailable (AGENT_HEALTH_FAILURES)
+func agentFailureThresholdFromEnv() int {
+	if v := os.Getenv("AGENT_HEALTH_FAILURES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		logrus.WithField("value", v).Warn("Invalid AGENT_HEALTH_FAILURES, using default")
+	}
+	return defaultAgentFailureThreshold
+}
+
+// agentUnavailableWaitFromEnv returns how long steps wait for an unavailable agent (AGENT_UNAVAILABLE_WAIT)
+func agentUnavailableWaitFromEnv() time.Duration {
+	if v := os.Getenv("AGENT_UNAVAILABLE_WAIT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			return d
+		}
+		logrus.WithField("value", v).Warn("Invalid AGENT_UNAVAILABLE_WAIT, using default")
+	}
+	return defaultAgentUnavailableWait
+}
+
+// AgentHealth is the liveness of one registered agent
+type AgentHealth struct {
+	Agent               string     `json:"agent"`
+	Available           bool       `json:"available"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastCheckedAt       *time.Time `json:"last_checked_at,omitempty"`
+	ChangedAt           *time.Time `json:"changed_at,omitempty"` // When availability last changed
+}
+
+// agentMonitor health-checks agents and tracks which are available. An agent is marked
+// unavailable after failureThreshold consecutive failed checks and re-enabled by the
+// first check that passes.
+type agentMonitor struct {
+	clients          map[string]AgentClient
+	failureThreshold int
+	logger           *logrus.Logger
+
+	mu      sync.RWMutex
+	states  map[string]*AgentHealth
+	changed chan struct{} // Closed and replaced whenever an agent's availability changes
+}
+
+// newAgentMonitor creates a monitor for clients; every agent starts out available
+func newAgentMonitor(clients map[string]AgentClient, failureThreshold int, logger *logrus.Logger) *agentMonitor {
+	if failureThreshold <= 0 {
+		failureThreshold = defaultAgentFailureThreshold
+	}
+	m := &agentMonitor{
+		clients:          clients,
+		failureThreshold: failureThreshold,
+		logger:           logger,
+		states:           make(map[string]*AgentHealth, len(clients)),
+		changed:          make(chan struct{}),
+	}
+	for name := range clients {
+		m.states[name] = &AgentHealth{Agent: name, Available: true}
+	}
+	return m
+}
+
+// checkAll health-checks every agent concurrently
+func (m *agentMonitor) checkAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for name, client := range m.clients {
+		wg.Add(1)
+		go func(name string, client AgentClient) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, agentHealthCheckTimeout)
+			defer cancel()
+			m.record(name, client.Health(checkCtx), time.Now())
+		}(name, client)
+	}
+	wg.Wait()
+}
+
+// record applies one health check result to an agent's state
+func (m *agentMonitor) record(name string, err error, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.states[name]
+	if !ok {
+		return
+	}
+	state.LastCheckedAt = &now
+	wasAvailable := state.Available
+	if err != nil {
+		state.ConsecutiveFailures++
+		state.LastError = err.Error()
+		if state.ConsecutiveFailures >= m.failureThreshold {
+			state.Available = false
+		}
+	} else {
+		state.ConsecutiveFailures = 0
+		state.LastError = ""
+		state.Available = true
+	}
+	if state.Available == wasAvailable {
+		return
+	}
+
+	state.ChangedAt = &now
+	close(m.changed)
+	m.changed = make(chan struct{})
+	if state.Available {
+		m.logger.WithField("agent", name).Info("Agent health checks recovered, re-enabling agent")
+	} else {
+		m.logger.WithFields(logrus.Fields{
+			"agent":    name,
+			"failures": state.ConsecutiveFailures,
+			"error":    state.LastError,
+		}).Warn("Agent failing health checks, marking unavailable")
+	}
+}
+
+// available reports whether an agent may be sent tasks; unmonitored agents are available
+func (m *agentMonitor) available(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	state, ok := m.states[name]
+	return !ok || state.Available
+}
+
+// waitAvailable blocks until an agent is available, the timeout passes or ctx ends
+func (m *agentMonitor) waitAvailable(ctx context.Context, name string, timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		m.mu.RLock()
+		state, ok := m.states[name]
+		if !ok || state.Available {
+			m.mu.RUnlock()
+			return true
+		}
+		changed := m.changed
+		m.mu.RUnlock()
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// snapshot returns every agent's health, sorted by name
+func (m *agentMonitor) snapshot() []AgentHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	agents := make([]AgentHealth, 0, len(m.states))
+	for _, state := range m.states {
+		agents = append(agents, *state)
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Agent < agents[j].Agent })
+	return agents
+}
+
+// start checks the agents every interval until ctx is cancelled
+func (m *agentMonitor) start(ctx context.Context, interval time.Duration) {
+	m.logger.WithFields(logrus.Fields{
+		"interval":          interval,
+		"failure_threshold": m.failureThreshold,
+	}).Info("Agent health monitor started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.checkAll(ctx)
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Agent health monitor stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startAgentMonitor runs the pipeline's agent health checks until ctx is cancelled
+func (p *Pipeline) startAgentMonitor(ctx context.Context) {
+	if p.agentMonitor == nil {
+		return
+	}
+	p.agentMonitor.start(ctx, p.config.AgentHealthInterval)
+}
+
+// awaitAgent holds a step whose agent is unavailable until the agent recovers, telling the
+// session's clients why the step is waiting. It returns an error if the agent stays unavailable.
+func (p *Pipeline) awaitAgent(ctx context.Context, sessionID string, step PipelineStep, stepIndex int, orchestrator *Orchestrator) error {
+	if p.agentMonitor == nil || p.agentMonitor.available(step.Agent) {
+		return nil
+	}
+
+	wait := p.config.AgentUnavailableWait
+	p.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"step":       step.Name,
+		"agent":      step.Agent,
+		"wait":       wait,
+	}).Warn("Step agent is unavailable, waiting for it to recover")
+
+	action := "failing"
+	if wait > 0 {
+		action = "waiting"
+	} else if step.Optional {
+		action = "skipping"
+	}
+	orchestrator.BroadcastEvent(sessionID, SSEEvent{
+		Type:      "agent_unavailable",
+		SessionID: sessionID,
+		StepID:    fmt.Sprintf("step-%d", stepIndex+1),
+		Data: map[string]interface{}{
+			"session_id":   sessionID,
+			"step":         step.Name,
+			"agent":        step.Agent,
+			"action":       action,
+			"wait_seconds": int(wait.Seconds()),
+			"optional":     step.Optional,
+			"message":      fmt.Sprintf("The %s agent is temporarily unavailable.", step.Agent),
+			"timestamp":    time.Now().Format(time.RFC3339),
+		},
+		Timestamp: time.Now(),
+	})
+
+	if wait > 0 && p.agentMonitor.waitAvailable(ctx, step.Agent, wait) {
+		return nil
+	}
+	return fmt.Errorf("agent %s is unavailable", step.Agent)
+}
+
+// agentHealthHandler handles GET /api/agents/health
+func (o *Orchestrator) agentHealthHandler(w http.ResponseWriter, r *http.Request) {
+	agents := []AgentHealth{}
+	if o.pipeline != nil && o.pipeline.agentMonitor != nil {
+		agents = o.pipeline.agentMonitor.snapshot()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"agents":     agents,
+		"monitoring": o.pipeline != nil && o.pipeline.agentMonitor != nil,
+	})
+}
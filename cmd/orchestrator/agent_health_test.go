@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// healthAgentClient is an agent whose health checks fail while down is set
+type healthAgentClient struct {
+	mu    sync.Mutex
+	down  bool
+	tasks int
+}
+
+// setDown sets whether health checks fail
+func (c *healthAgentClient) setDown(down bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = down
+}
+
+// ExecuteTask implements AgentClient
+func (c *healthAgentClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tasks++
+	return &adk.TaskResponse{Artifacts: map[string]string{"outline": "[]"}}, nil
+}
+
+// Health implements AgentClient
+func (c *healthAgentClient) Health(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// TestAgentMonitorAvailability tests marking agents unavailable after repeated failures and re-enabling them
+func TestAgentMonitorAvailability(t *testing.T) {
+	client := &healthAgentClient{down: true}
+	m := newAgentMonitor(map[string]AgentClient{"critic": client}, 2, logrus.New())
+	assert.True(t, m.available("critic"))
+
+	m.checkAll(context.Background())
+	assert.True(t, m.available("critic"), "one failure is not enough")
+	m.checkAll(context.Background())
+	assert.False(t, m.available("critic"))
+
+	health := m.snapshot()
+	require.Len(t, health, 1)
+	assert.Equal(t, 2, health[0].ConsecutiveFailures)
+	assert.Equal(t, "connection refused", health[0].LastError)
+	assert.NotNil(t, health[0].ChangedAt)
+
+	client.setDown(false)
+	m.checkAll(context.Background())
+	assert.True(t, m.available("critic"))
+	assert.Equal(t, 0, m.snapshot()[0].ConsecutiveFailures)
+	assert.True(t, m.available("unknown"))
+}
+
+// TestAgentMonitorWaitAvailable tests that waiting steps resume when their agent recovers
+func TestAgentMonitorWaitAvailable(t *testing.T) {
+	m := newAgentMonitor(map[string]AgentClient{"critic": &healthAgentClient{}}, 1, logrus.New())
+	m.record("critic", errors.New("down"), time.Now())
+
+	assert.False(t, m.waitAvailable(context.Background(), "critic", 10*time.Millisecond))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		m.record("critic", nil, time.Now())
+	}()
+	assert.True(t, m.waitAvailable(context.Background(), "critic", 5*time.Second))
+}
+
+// TestExecuteStepUnavailableAgent tests that optional steps are skipped and required steps fail with a notice
+func TestExecuteStepUnavailableAgent(t *testing.T) {
+	client := &healthAgentClient{}
+	clients := map[string]AgentClient{"summarizer": client, "critic": client}
+	p := &Pipeline{
+		config:       PipelineConfig{AgentUnavailableWait: 0},
+		logger:       logrus.New(),
+		adkClients:   clients,
+		agentMonitor: newAgentMonitor(clients, 1, logrus.New()),
+	}
+	p.agentMonitor.record("summarizer", errors.New("down"), time.Now())
+	p.agentMonitor.record("critic", errors.New("down"), time.Now())
+
+	o := &Orchestrator{logger: logrus.New(), clients: make(map[string][]chan SSEEvent)}
+	events := make(chan SSEEvent, 10)
+	o.AddClient("s1", events)
+
+	critic := PipelineStep{Name: "critic", Agent: "critic", Inputs: map[string]string{"topic": "Caching"}, Optional: true}
+	result := p.executeStep(context.Background(), "s1", critic, o, 3, newRetryBudget(p.config, time.Now()))
+	assert.Equal(t, "skipped", result.Status)
+	assert.Equal(t, "agent critic is unavailable", result.Error)
+
+	summarizer := PipelineStep{Name: "summarizer", Agent: "summarizer", Inputs: map[string]string{"topic": "Caching"}}
+	result = p.executeStep(context.Background(), "s1", summarizer, o, 0, newRetryBudget(p.config, time.Now()))
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, 0, client.tasks)
+
+	var notices []SSEEvent
+	for len(events) > 0 {
+		if event := <-events; event.Type == "agent_unavailable" {
+			notices = append(notices, event)
+		}
+	}
+	require.Len(t, notices, 2)
+	assert.Equal(t, "skipping", notices[0].Data["action"])
+	assert.Equal(t, "failing", notices[1].Data["action"])
+
+	// Once the agent recovers its steps run again
+	p.agentMonitor.record("summarizer", nil, time.Now())
+	result = p.executeStep(context.Background(), "s1", summarizer, o, 0, newRetryBudget(p.config, time.Now()))
+	assert.Equal(t, "completed", result.Status)
+	assert.Equal(t, 1, client.tasks)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// agentPreflightTimeoutFromEnv returns how long a run request waits on agent pings
+// (AGENT_PREFLIGHT_TIMEOUT); 0 disables the pre-flight check
+func agentPreflightTimeoutFromEnv() time.Duration {
+	if v := os.Getenv("AGENT_PREFLIGHT_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			return d
+		}
+		logrus.WithField("value", v).Warn("Invalid AGENT_PREFLIGHT_TIMEOUT, using default")
+	}
+	return agentHealthCheckTimeout
+}
+
+// UnavailableAgent is a mandatory agent that failed its pre-flight ping
+type UnavailableAgent struct {
+	Agent string `json:"agent"`
+	Error string `json:"error"`
+}
+
+// requiredAgents returns the agents a run cannot complete without: those of steps that are
+// neither optional nor seeded, sorted by name
+func requiredAgents(steps []PipelineStep) []string {
+	seen := make(map[string]bool, len(steps))
+	var agents []string
+	for _, step := range steps {
+		if step.Optional || step.Seeded != nil || seen[step.Agent] {
+			continue
+		}
+		seen[step.Agent] = true
+		agents = append(agents, step.Agent)
+	}
+	sort.Strings(agents)
+	return agents
+}
+
+// sessionRequiredAgents returns the mandatory agents of a session's pipeline, after the warm
+// start and organization policy that decide which steps call their agent and which may be skipped
+func (o *Orchestrator) sessionRequiredAgents(session *Session) []string {
+	steps := pipelineDefinition(session.Topic)
+	if saved := o.warmStartLesson(session); saved != nil {
+		applyWarmStart(steps, saved)
+	}
+	applyPolicySteps(session, steps)
+	return requiredAgents(steps)
+}
+
+// preflightAgents pings agents concurrently and returns those that do not answer. Results
+// feed the health monitor, so a ping that fails counts toward marking the agent unavailable.
+func (p *Pipeline) preflightAgents(ctx context.Context, agents []string) []UnavailableAgent {
+	ctx, cancel := context.WithTimeout(ctx, p.config.AgentPreflightTimeout)
+	defer cancel()
+
+	var (
+		mu          sync.Mutex
+		wg          sync.WaitGroup
+		unavailable []UnavailableAgent
+	)
+	for _, name := range agents {
+		client, ok := p.adkClients[name]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(name string, client AgentClient) {
+			defer wg.Done()
+			err := client.Health(ctx)
+			if p.agentMonitor != nil {
+				p.agentMonitor.record(name, err, time.Now())
+			}
+			if err != nil {
+				mu.Lock()
+				unavailable = append(unavailable, UnavailableAgent{Agent: name, Error: err.Error()})
+				mu.Unlock()
+			}
+		}(name, client)
+	}
+	wg.Wait()
+
+	sort.Slice(unavailable, func(i, j int) bool { return unavailable[i].Agent < unavailable[j].Agent })
+	return unavailable
+}
+
+// preflightRetryAfter returns the seconds a client should wait before retrying a run rejected
+// by the pre-flight check: until the health monitor next checks the agents
+func (p *Pipeline) preflightRetryAfter() int {
+	if p.agentMonitor != nil && p.config.AgentHealthInterval > 0 {
+		return int(p.config.AgentHealthInterval.Seconds())
+	}
+	return int(defaultAgentHealthInterval.Seconds())
+}
+
+// agentsReady pings the mandatory agents of a session's pipeline before its run starts. If one
+// is down it writes a 503 telling the client when to retry and returns false, so users are not
+// left watching the first step fail through its retries. Runs served from the result cache and
+// in-process agents skip the check.
+func (o *Orchestrator) agentsReady(w http.ResponseWriter, r *http.Request, session *Session) bool {
+	p := o.pipeline
+	if p == nil || p.config.AgentPreflightTimeout <= 0 || agentsInProcess(p.config.AgentMode) || o.hasCachedResult(session) {
+		return true
+	}
+
+	unavailable := p.preflightAgents(r.Context(), o.sessionRequiredAgents(session))
+	if len(unavailable) == 0 {
+		return true
+	}
+
+	names := make([]string, len(unavailable))
+	for i, agent := range unavailable {
+		names[i] = agent.Agent
+	}
+	subject := fmt.Sprintf("The %s agent is", names[0])
+	if len(names) > 1 {
+		subject = fmt.Sprintf("The %s agents are", strings.Join(names, ", "))
+	}
+	retryAfter := p.preflightRetryAfter()
+	o.logger.WithFields(logrus.Fields{
+		"session_id": session.ID,
+		"agents":     names,
+	}).Warn("Rejected session run, mandatory agents failed pre-flight check")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":       "Service unavailable",
+		"message":     fmt.Sprintf("%s unavailable, retry in %d seconds.", subject, retryAfter),
+		"code":        "agent_unavailable",
+		"agents":      unavailable,
+		"retry_after": retryAfter,
+	})
+	return false
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequiredAgents tests that optional and warm-started steps do not make their agents mandatory
+func TestRequiredAgents(t *testing.T) {
+	steps := pipelineDefinition("Raft")
+	assert.Equal(t, []string{"explainer", "summarizer"}, requiredAgents(steps))
+
+	applyWarmStart(steps, &SavedLesson{Result: &SessionResult{Summary: "Raft elects a leader."}})
+	applyPolicySteps(&Session{Metadata: map[string]interface{}{"require_critic": true}}, steps)
+	assert.Equal(t, []string{"critic", "explainer"}, requiredAgents(steps))
+}
+
+// TestRunSessionAgentPreflight tests that a run is rejected before it starts while a mandatory agent is down
+func TestRunSessionAgentPreflight(t *testing.T) {
+	summarizer := &healthAgentClient{down: true}
+	critic := &healthAgentClient{down: true}
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"s1": {ID: "s1", Topic: "Raft", Status: "created", Metadata: map[string]interface{}{}},
+		},
+		logger:  logrus.New(),
+		clients: make(map[string][]chan SSEEvent),
+	}
+	o.pipeline = &Pipeline{
+		config: PipelineConfig{AgentPreflightTimeout: time.Second},
+		logger: o.logger,
+		adkClients: map[string]AgentClient{
+			"summarizer": summarizer,
+			"explainer":  &healthAgentClient{},
+			"visualizer": &healthAgentClient{},
+			"critic":     critic,
+		},
+	}
+	router := chi.NewRouter()
+	router.Post("/api/sessions/{id}/run", o.runSessionHandler)
+
+	w := serve(router, "POST", "/api/sessions/s1/run")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	var body struct {
+		Code    string             `json:"code"`
+		Message string             `json:"message"`
+		Agents  []UnavailableAgent `json:"agents"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "agent_unavailable", body.Code)
+	assert.Equal(t, "The summarizer agent is unavailable, retry in 30 seconds.", body.Message)
+	require.Len(t, body.Agents, 1, "the critic is optional")
+	assert.Equal(t, "summarizer", body.Agents[0].Agent)
+	assert.Equal(t, "created", o.sessions["s1"].Status)
+
+	summarizer.setDown(false)
+	assert.True(t, o.agentsReady(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/sessions/s1/run", nil), o.sessions["s1"]))
+
+	// Disabled, the check lets runs through whatever the agents' state
+	summarizer.setDown(true)
+	o.pipeline.config.AgentPreflightTimeout = 0
+	assert.True(t, o.agentsReady(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/sessions/s1/run", nil), o.sessions["s1"]))
+}
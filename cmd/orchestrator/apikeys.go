@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	errMissingKeyOwner   = errors.New("user_id or org_id is required")
+	errNegativeKeyExpiry = errors.New("expires_in_days must not be negative")
+)
+
+// newAPIKeyService creates the API key service, loading issued keys if storage is available
+func newAPIKeyService(store auth.APIKeyStore) *auth.APIKeyService {
+	service := auth.NewAPIKeyService(store)
+	if err := service.Load(context.Background()); err != nil {
+		logrus.WithError(err).Warn("Failed to load API keys, continuing with no keys")
+	}
+	return service
+}
+
+// authRequiredFromEnv reports whether API requests must be authenticated (AUTH_REQUIRED).
+// It defaults to false so existing anonymous clients keep working; API keys are still scope-checked.
+func authRequiredFromEnv() bool {
+	if v := os.Getenv("AUTH_REQUIRED"); v != "" {
+		required, err := strconv.ParseBool(v)
+		if err == nil {
+			return required
+		}
+		logrus.WithField("value", v).Warn("Invalid AUTH_REQUIRED, using default")
+	}
+	return false
+}
+
+// adminUsersFromEnv returns the JWT user IDs and emails granted the admin scope (ADMIN_USERS, comma-separated).
+// They bootstrap API key management, since admin keys can only be issued by an admin.
+func adminUsersFromEnv() map[string]bool {
+	admins := make(map[string]bool)
+	for _, id := range strings.Split(os.Getenv("ADMIN_USERS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			admins[strings.ToLower(id)] = true
+		}
+	}
+	return admins
+}
+
+// grantAdmins adds the admin scope to JWT principals listed in ADMIN_USERS
+func (o *Orchestrator) grantAdmins(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.PrincipalFromContext(r.Context())
+		if ok && principal.Method == auth.MethodJWT && !principal.HasScope(auth.ScopeAdmin) &&
+			(o.adminUsers[strings.ToLower(principal.UserID)] || (principal.Email != "" && o.adminUsers[strings.ToLower(principal.Email)])) {
+			admin := *principal
+			admin.Scopes = append(append([]auth.Scope(nil), principal.Scopes...), auth.ScopeAdmin)
+			r = r.WithContext(auth.WithPrincipal(r.Context(), &admin))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireScope rejects authenticated callers lacking a scope, and anonymous callers when auth is required.
+// The admin scope always requires an authenticated caller, whatever AUTH_REQUIRED says.
+func (o *Orchestrator) requireScope(scope auth.Scope) func(http.Handler) http.Handler {
+	return auth.RequireScope(scope, o.authRequired || scope == auth.ScopeAdmin)
+}
+
+// callerIsAdmin reports whether the request was made by a principal with the admin scope
+func callerIsAdmin(r *http.Request) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	return ok && principal.HasScope(auth.ScopeAdmin)
+}
+
+// canManageAPIKeys reports whether the caller may manage keys belonging to a user or organization.
+// Admins manage any key; interactive users manage their own personal keys. Anonymous callers manage none.
+func (o *Orchestrator) canManageAPIKeys(r *http.Request, userID, orgID string) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return false
+	}
+	if principal.HasScope(auth.ScopeAdmin) {
+		return true
+	}
+	return principal.Method == auth.MethodJWT && orgID == "" && userID != "" && userID == principal.UserID
+}
+
+// writeAPIKeyForbidden writes the error returned when the caller may not manage a key
+func writeAPIKeyForbidden(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   "Forbidden",
+		"message": "Not allowed to manage these API keys",
+	})
+}
+
+// CreateAPIKeyRequest represents a request to issue an API key
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	UserID        string   `json:"user_id"`
+	OrgID         string   `json:"org_id"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"` // Zero means the key does not expire
+}
+
+// createAPIKeyHandler handles POST /api/keys. The plaintext key is only returned in this response.
+func (o *Orchestrator) createAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req CreateAPIKeyRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	scopes, err := auth.ParseScopes(req.Scopes)
+	if err == nil && req.UserID == "" && req.OrgID == "" {
+		err = errMissingKeyOwner
+	}
+	if err == nil && req.ExpiresInDays < 0 {
+		err = errNegativeKeyExpiry
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Invalid API key request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if !o.canManageAPIKeys(r, req.UserID, req.OrgID) {
+		writeAPIKeyForbidden(w)
+		return
+	}
+
+	// A key can only carry scopes its issuer holds
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	for _, scope := range scopes {
+		if !principal.HasScope(scope) {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Forbidden",
+				"message": "Cannot grant the " + string(scope) + " scope, which the caller does not hold",
+			})
+			return
+		}
+	}
+
+	key, secret, err := o.apiKeys.Create(r.Context(), auth.CreateAPIKeyRequest{
+		Name:      req.Name,
+		UserID:    req.UserID,
+		OrgID:     req.OrgID,
+		Scopes:    scopes,
+		ExpiresIn: time.Duration(req.ExpiresInDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		o.logger.WithError(err).Error("Failed to create API key")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Failed to create API key",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"key":     key,
+		"api_key": secret,
+		"message": "Store this key now; it will not be shown again",
+	})
+}
+
+// listAPIKeysHandler handles GET /api/keys?user_id=&org_id=
+func (o *Orchestrator) listAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := r.URL.Query().Get("user_id")
+	orgID := r.URL.Query().Get("org_id")
+	if !o.canManageAPIKeys(r, userID, orgID) {
+		writeAPIKeyForbidden(w)
+		return
+	}
+
+	keys := o.apiKeys.List(userID, orgID)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys":  keys,
+		"count": len(keys),
+	})
+}
+
+// revokeAPIKeyHandler handles DELETE /api/keys/{id}
+func (o *Orchestrator) revokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	keyID := chi.URLParam(r, "id")
+	existing, exists := o.apiKeys.Get(keyID)
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "API key not found",
+			"message": "API key not found",
+		})
+		return
+	}
+	if !o.canManageAPIKeys(r, existing.UserID, existing.OrgID) {
+		writeAPIKeyForbidden(w)
+		return
+	}
+
+	key, err := o.apiKeys.Revoke(r.Context(), keyID)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"key_id": keyID,
+			"error":  err,
+		}).Error("Failed to revoke API key")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Failed to revoke API key",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(key)
+}
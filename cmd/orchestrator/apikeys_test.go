@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/flags"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAPIKeyTestOrchestrator creates an orchestrator with in-memory API keys and the full route set
+func newAPIKeyTestOrchestrator(authRequired bool) (*Orchestrator, http.Handler) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		flagService:  flags.NewService(nil),
+		metaIndex:    newMetadataIndex(),
+		apiKeys:      auth.NewAPIKeyService(nil),
+		authRequired: authRequired,
+	}
+	return o, o.setupRoutes()
+}
+
+// newAdminKey issues an admin API key on the orchestrator, creating its key service if needed
+func newAdminKey(t *testing.T, o *Orchestrator) string {
+	t.Helper()
+	if o.apiKeys == nil {
+		o.apiKeys = auth.NewAPIKeyService(nil)
+	}
+	_, secret, err := o.apiKeys.Create(t.Context(), auth.CreateAPIKeyRequest{OrgID: "org-admin", Scopes: []auth.Scope{auth.ScopeAdmin}})
+	require.NoError(t, err)
+	return secret
+}
+
+// serveWithKey serves a request authenticated with an API key
+func serveWithKey(r http.Handler, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(auth.APIKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestAPIKeyLifecycle tests creating, using and revoking a scoped API key
+func TestAPIKeyLifecycle(t *testing.T) {
+	o, router := newAPIKeyTestOrchestrator(false)
+	admin := newAdminKey(t, o)
+
+	w := serveWithKey(router, "POST", "/api/keys", admin, `{"name": "ci", "org_id": "org-1", "scopes": ["sessions:read"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Key    auth.APIKey `json:"key"`
+		APIKey string      `json:"api_key"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.True(t, auth.IsAPIKey(created.APIKey))
+
+	// The key can read sessions but not change flags
+	assert.Equal(t, http.StatusOK, serveWithKey(router, "GET", "/api/sessions", created.APIKey, "").Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(router, "PUT", "/api/flags/beta", created.APIKey, `{"enabled": true}`).Code)
+
+	// A non-admin key cannot manage keys
+	assert.Equal(t, http.StatusForbidden, serveWithKey(router, "GET", "/api/keys?org_id=org-1", created.APIKey, "").Code)
+
+	var listing struct {
+		Keys []auth.APIKey `json:"keys"`
+	}
+	require.NoError(t, json.Unmarshal(serveWithKey(router, "GET", "/api/keys?org_id=org-1", admin, "").Body.Bytes(), &listing))
+	require.Len(t, listing.Keys, 1)
+	assert.Empty(t, listing.Keys[0].SecretHash)
+
+	require.Equal(t, http.StatusOK, serveWithKey(router, "DELETE", "/api/keys/"+created.Key.ID, admin, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, "GET", "/api/sessions", created.APIKey, "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(router, "DELETE", "/api/keys/missing", admin, "").Code)
+}
+
+// TestAPIKeyValidation tests that key requests need an owner and known scopes
+func TestAPIKeyValidation(t *testing.T) {
+	o, router := newAPIKeyTestOrchestrator(false)
+	admin := newAdminKey(t, o)
+
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(router, "POST", "/api/keys", admin, `{"scopes": ["sessions:read"]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(router, "POST", "/api/keys", admin, `{"user_id": "u1", "scopes": ["everything"]}`).Code)
+}
+
+// TestAPIKeyManagementNeedsPrincipal tests that anonymous callers cannot manage keys or reach admin routes,
+// even when AUTH_REQUIRED is off
+func TestAPIKeyManagementNeedsPrincipal(t *testing.T) {
+	_, router := newAPIKeyTestOrchestrator(false)
+
+	assert.Equal(t, http.StatusForbidden, serveWithKey(router, "POST", "/api/keys", "", `{"user_id": "u1", "scopes": ["admin"]}`).Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(router, "GET", "/api/keys?user_id=u1", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, "PUT", "/api/flags/beta", "", `{"enabled": true}`).Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(router, "GET", "/api/sessions", "", "").Code)
+}
+
+// TestAPIKeyScopeEscalation tests that users can only issue keys with scopes they hold
+func TestAPIKeyScopeEscalation(t *testing.T) {
+	o, _ := newAPIKeyTestOrchestrator(false)
+	user := &auth.Principal{UserID: "u1", Method: auth.MethodJWT, Scopes: []auth.Scope{auth.ScopeSessionsRead, auth.ScopeSessionsWrite}}
+
+	create := func(principal *auth.Principal, body string) int {
+		req := httptest.NewRequest("POST", "/api/keys", strings.NewReader(body))
+		req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+		w := httptest.NewRecorder()
+		o.grantAdmins(http.HandlerFunc(o.createAPIKeyHandler)).ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, create(user, `{"user_id": "u1", "scopes": ["admin"]}`))
+	assert.Equal(t, http.StatusCreated, create(user, `{"user_id": "u1", "scopes": ["sessions:write"]}`))
+	assert.Equal(t, http.StatusForbidden, create(user, `{"user_id": "u2", "scopes": ["sessions:read"]}`))
+
+	// Users listed in ADMIN_USERS hold the admin scope
+	o.adminUsers = map[string]bool{"u1": true}
+	assert.Equal(t, http.StatusCreated, create(user, `{"user_id": "u2", "scopes": ["admin"]}`))
+}
+
+// TestAuthRequired tests that anonymous requests are rejected when AUTH_REQUIRED is set
+func TestAuthRequired(t *testing.T) {
+	o, router := newAPIKeyTestOrchestrator(true)
+
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, "GET", "/api/sessions", "", "").Code)
+	assert.Equal(t, http.StatusOK, serveWithKey(router, "GET", "/healthz", "", "").Code)
+
+	_, admin, err := o.apiKeys.Create(t.Context(), auth.CreateAPIKeyRequest{OrgID: "org-1", Scopes: []auth.Scope{auth.ScopeAdmin}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serveWithKey(router, "GET", "/api/sessions", admin, "").Code)
+	assert.Equal(t, http.StatusCreated, serveWithKey(router, "POST", "/api/keys", admin, `{"user_id": "u1", "scopes": ["sessions:write"]}`).Code)
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"google.golang.org/api/googleapi"
+	iamcredentials "google.golang.org/api/iamcredentials/v1"
+	"google.golang.org/api/option"
+	storagev1 "google.golang.org/api/storage/v1"
+)
+
+// gcsArtifactStore implements ArtifactStore and ExportStore with the Cloud Storage JSON API
+type gcsArtifactStore struct {
+	service *storagev1.Service
+	bucket  string
+
+	signerOnce  sync.Once // Sets up URL signing on first use
+	signerEmail string
+	signer      func(ctx context.Context, payload []byte) ([]byte, error)
+	signerErr   error
+}
+
+// newGCSArtifactStore creates a Cloud Storage artifact store using application default credentials
+func newGCSArtifactStore(ctx context.Context, bucket string) (*gcsArtifactStore, error) {
+	service, err := storagev1.NewService(ctx, option.WithScopes(storagev1.DevstorageReadWriteScope))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Storage client: %w", err)
+	}
+	return &gcsArtifactStore{service: service, bucket: bucket}, nil
+}
+
+// Bucket returns the bucket holding the artifacts
+func (s *gcsArtifactStore) Bucket() string {
+	return s.bucket
+}
+
+// EnsureExpiry installs (or replaces) a bucket lifecycle rule deleting objects under prefix after days.
+// Rules for other prefixes are kept.
+func (s *gcsArtifactStore) EnsureExpiry(ctx context.Context, prefix string, days int) error {
+	bucket, err := s.service.Buckets.Get(s.bucket).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get bucket %s: %w", s.bucket, err)
+	}
+
+	rules := make([]*storagev1.BucketLifecycleRule, 0)
+	if bucket.Lifecycle != nil {
+		for _, rule := range bucket.Lifecycle.Rule {
+			if isPrefixExpiryRule(rule, prefix) {
+				continue
+			}
+			rules = append(rules, rule)
+		}
+	}
+	age := int64(days)
+	rules = append(rules, &storagev1.BucketLifecycleRule{
+		Action: &storagev1.BucketLifecycleRuleAction{Type: "Delete"},
+		Condition: &storagev1.BucketLifecycleRuleCondition{
+			Age:           &age,
+			MatchesPrefix: []string{prefix},
+		},
+	})
+
+	patch := &storagev1.Bucket{Lifecycle: &storagev1.BucketLifecycle{Rule: rules}}
+	if _, err := s.service.Buckets.Patch(s.bucket, patch).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to update lifecycle of bucket %s: %w", s.bucket, err)
+	}
+	return nil
+}
+
+// isPrefixExpiryRule reports whether a lifecycle rule is the delete rule for exactly prefix
+func isPrefixExpiryRule(rule *storagev1.BucketLifecycleRule, prefix string) bool {
+	return rule.Action != nil && rule.Action.Type == "Delete" &&
+		rule.Condition != nil && len(rule.Condition.MatchesPrefix) == 1 && rule.Condition.MatchesPrefix[0] == prefix
+}
+
+// Promote copies an object to a content-addressed name under prefix with the given storage class.
+// Objects with identical content share one copy, so promoting a duplicate is a no-op.
+func (s *gcsArtifactStore) Promote(ctx context.Context, object, prefix, storageClass string) (string, error) {
+	source, err := s.service.Objects.Get(s.bucket, object).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get object %s: %w", object, err)
+	}
+
+	digest := source.Md5Hash
+	if digest == "" {
+		// Composite objects have no MD5; fall back to the CRC32C checksum
+		digest = source.Crc32c
+	}
+	raw, err := base64.StdEncoding.DecodeString(digest)
+	if err != nil || len(raw) == 0 {
+		return "", fmt.Errorf("object %s has no usable checksum", object)
+	}
+	destination := prefix + hex.EncodeToString(raw) + path.Ext(object)
+
+	existing, err := s.service.Objects.Get(s.bucket, destination).Context(ctx).Do()
+	if err == nil && existing.StorageClass == storageClass {
+		return destination, nil
+	}
+	if err != nil && !isNotFound(err) {
+		return "", fmt.Errorf("failed to check object %s: %w", destination, err)
+	}
+
+	// Rewrite copies server-side and may take several calls for large objects
+	call := s.service.Objects.Rewrite(s.bucket, object, s.bucket, destination, &storagev1.Object{
+		StorageClass: storageClass,
+		ContentType:  source.ContentType,
+	})
+	for {
+		response, err := call.Context(ctx).Do()
+		if err != nil {
+			return "", fmt.Errorf("failed to copy %s to %s: %w", object, destination, err)
+		}
+		if response.Done {
+			return destination, nil
+		}
+		call = call.RewriteToken(response.RewriteToken)
+	}
+}
+
+// Delete removes an object; deleting a missing object is not an error
+func (s *gcsArtifactStore) Delete(ctx context.Context, object string) error {
+	if err := s.service.Objects.Delete(s.bucket, object).Context(ctx).Do(); err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete object %s: %w", object, err)
+	}
+	return nil
+}
+
+// isNotFound reports whether a Cloud Storage API error is a 404
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
+
+// Upload writes an object, replacing any existing object with the same name
+func (s *gcsArtifactStore) Upload(ctx context.Context, object, contentType string, data io.Reader) error {
+	call := s.service.Objects.Insert(s.bucket, &storagev1.Object{Name: object, ContentType: contentType}).
+		Media(data, googleapi.ContentType(contentType))
+	if _, err := call.Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to upload object %s: %w", object, err)
+	}
+	return nil
+}
+
+// Download reads an object
+func (s *gcsArtifactStore) Download(ctx context.Context, object string) ([]byte, error) {
+	resp, err := s.service.Objects.Get(s.bucket, object).Context(ctx).Download()
+	if err != nil {
+		return nil, fmt.Errorf("failed to download object %s: %w", object, err)
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// SignedURL returns a V4 signed GET URL for an object that is valid for expiry
+func (s *gcsArtifactStore) SignedURL(ctx context.Context, object string, expiry time.Duration) (string, error) {
+	s.signerOnce.Do(func() { s.signerEmail, s.signer, s.signerErr = newIAMSigner(ctx) })
+	if s.signerErr != nil {
+		return "", s.signerErr
+	}
+	return signedGCSURL(ctx, s.bucket, object, time.Now(), expiry, s.signerEmail, s.signer)
+}
+
+// newIAMSigner creates a signer using the IAM Credentials signBlob API for the service account
+// in GCS_SIGNER_EMAIL, or the instance's default service account when unset
+func newIAMSigner(ctx context.Context) (string, func(ctx context.Context, payload []byte) ([]byte, error), error) {
+	email := os.Getenv("GCS_SIGNER_EMAIL")
+	if email == "" {
+		var err error
+		if email, err = metadata.EmailWithContext(ctx, "default"); err != nil {
+			return "", nil, fmt.Errorf("no service account to sign URLs with, set GCS_SIGNER_EMAIL: %w", err)
+		}
+	}
+
+	service, err := iamcredentials.NewService(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create IAM credentials client: %w", err)
+	}
+
+	name := "projects/-/serviceAccounts/" + email
+	sign := func(ctx context.Context, payload []byte) ([]byte, error) {
+		response, err := service.Projects.ServiceAccounts.SignBlob(name, &iamcredentials.SignBlobRequest{
+			Payload: base64.StdEncoding.EncodeToString(payload),
+		}).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign URL: %w", err)
+		}
+		return base64.StdEncoding.DecodeString(response.SignedBlob)
+	}
+	return email, sign, nil
+}
+
+// signedGCSURL builds a V4 signed GET URL (GOOG4-RSA-SHA256) for an object
+func signedGCSURL(ctx context.Context, bucket, object string, now time.Time, expiry time.Duration, email string, sign func(ctx context.Context, payload []byte) ([]byte, error)) (string, error) {
+	now = now.UTC()
+	datestamp := now.Format("20060102")
+	timestamp := now.Format("20060102T150405Z")
+	scope := datestamp + "/auto/storage/goog4_request"
+
+	// Query parameters must be sorted by name in the canonical request
+	query := strings.Join([]string{
+		"X-Goog-Algorithm=GOOG4-RSA-SHA256",
+		"X-Goog-Credential=" + url.QueryEscape(email+"/"+scope),
+		"X-Goog-Date=" + timestamp,
+		fmt.Sprintf("X-Goog-Expires=%d", int(expiry.Seconds())),
+		"X-Goog-SignedHeaders=host",
+	}, "&")
+
+	segments := strings.Split(object, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	resource := "/" + bucket + "/" + strings.Join(segments, "/")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		resource,
+		query,
+		"host:storage.googleapis.com\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	digest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"GOOG4-RSA-SHA256", timestamp, scope, hex.EncodeToString(digest[:])}, "\n")
+
+	signature, err := sign(ctx, []byte(stringToSign))
+	if err != nil {
+		return "", err
+	}
+	return "https://storage.googleapis.com" + resource + "?" + query + "&X-Goog-Signature=" + hex.EncodeToString(signature), nil
+}
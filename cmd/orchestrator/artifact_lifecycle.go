@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// tempArtifactPrefix is where the visualizer writes images for a session
+	tempArtifactPrefix = "sessions/"
+	// savedArtifactPrefix holds content-addressed images referenced by saved lessons
+	savedArtifactPrefix = "lessons/"
+	// defaultTempArtifactTTLDays is how long images of unsaved sessions are kept
+	defaultTempArtifactTTLDays = 7
+	// defaultSavedArtifactStorageClass is the storage class for images of saved lessons
+	defaultSavedArtifactStorageClass = "NEARLINE"
+	// artifactOperationTimeout bounds each background promotion or cleanup
+	artifactOperationTimeout = 2 * time.Minute
+)
+
+// ArtifactStore manages generated artifact objects in a bucket
+type ArtifactStore interface {
+	// Bucket returns the bucket holding the artifacts
+	Bucket() string
+	// EnsureExpiry makes objects under prefix expire after days
+	EnsureExpiry(ctx context.Context, prefix string, days int) error
+	// Promote copies an object to a content-addressed name under prefix and returns the new object name
+	Promote(ctx context.Context, object, prefix, storageClass string) (string, error)
+	// Delete removes an object
+	Delete(ctx context.Context, object string) error
+}
+
+// artifactURLResolver is implemented by artifact stores whose objects are not served from Cloud Storage URLs
+type artifactURLResolver interface {
+	// ObjectURL returns the URL an object is served from
+	ObjectURL(object string) string
+	// ObjectName returns the object name for a URL served from the bucket
+	ObjectName(url string) (string, bool)
+}
+
+// artifactObjectStore is a bucket backing both artifact lifecycle management and library exports
+type artifactObjectStore interface {
+	ArtifactStore
+	ExportStore
+}
+
+// artifactStoreFromEnv opens the object store selected by ARTIFACT_STORE: "gcs" (the default) for
+// GCS_BUCKET or "s3" for S3_BUCKET. It returns nil when the selected store has no bucket configured.
+func artifactStoreFromEnv(ctx context.Context) (artifactObjectStore, error) {
+	switch backend := strings.ToLower(os.Getenv("ARTIFACT_STORE")); backend {
+	case "", "gcs":
+		bucket := os.Getenv("GCS_BUCKET")
+		if bucket == "" {
+			return nil, nil
+		}
+		store, err := newGCSArtifactStore(ctx, bucket)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "s3":
+		bucket := os.Getenv("S3_BUCKET")
+		if bucket == "" {
+			return nil, nil
+		}
+		store, err := newS3ArtifactStore(ctx, bucket)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown ARTIFACT_STORE %q, expected gcs or s3", backend)
+	}
+}
+
+// artifactLifecycle applies lifecycle policies to generated images: images of unsaved
+// sessions expire, images of saved lessons move to long-lived storage, and images no
+// saved lesson references any more are deleted
+type artifactLifecycle struct {
+	store             ArtifactStore
+	tempTTLDays       int
+	savedStorageClass string
+}
+
+// artifactLifecycleFromEnv creates the artifact lifecycle manager for the store selected by ARTIFACT_STORE.
+// It returns nil when lifecycle management is disabled or storage is unavailable.
+func artifactLifecycleFromEnv() *artifactLifecycle {
+	if os.Getenv("ARTIFACT_LIFECYCLE_ENABLED") == "false" {
+		return nil
+	}
+
+	store, err := artifactStoreFromEnv(context.Background())
+	if err != nil {
+		logrus.WithError(err).Warn("Artifact storage not available, continuing without artifact lifecycle management")
+		return nil
+	}
+	if store == nil {
+		return nil
+	}
+
+	lifecycle := &artifactLifecycle{
+		store:             store,
+		tempTTLDays:       defaultTempArtifactTTLDays,
+		savedStorageClass: defaultSavedArtifactStorageClass,
+	}
+	if v := os.Getenv("ARTIFACT_TEMP_TTL_DAYS"); v != "" {
+		if days, err := strconv.Atoi(v); err == nil && days > 0 {
+			lifecycle.tempTTLDays = days
+		} else {
+			logrus.WithField("value", v).Warn("Invalid ARTIFACT_TEMP_TTL_DAYS, using default")
+		}
+	}
+	if v := os.Getenv("ARTIFACT_SAVED_STORAGE_CLASS"); v != "" {
+		lifecycle.savedStorageClass = strings.ToUpper(v)
+	}
+	return lifecycle
+}
+
+// objectName returns the object name for a public URL in the store's bucket
+func (l *artifactLifecycle) objectName(url string) (string, bool) {
+	return bucketObjectName(l.store, url)
+}
+
+// bucketObjectName returns the object name for a URL served from a store's bucket, and false
+// for URLs outside it
+func bucketObjectName(store interface{ Bucket() string }, url string) (string, bool) {
+	if resolver, ok := store.(artifactURLResolver); ok {
+		return resolver.ObjectName(url)
+	}
+	for _, base := range []string{"https://storage.googleapis.com/", "https://storage.cloud.google.com/"} {
+		prefix := base + store.Bucket() + "/"
+		if strings.HasPrefix(url, prefix) {
+			return strings.TrimPrefix(url, prefix), true
+		}
+	}
+	return "", false
+}
+
+// objectURL returns the public URL of an object in the store's bucket
+func (l *artifactLifecycle) objectURL(object string) string {
+	if resolver, ok := l.store.(artifactURLResolver); ok {
+		return resolver.ObjectURL(object)
+	}
+	return "https://storage.googleapis.com/" + l.store.Bucket() + "/" + object
+}
+
+// ensureTempExpiry installs the expiry rule for images of unsaved sessions
+func (o *Orchestrator) ensureTempExpiry(ctx context.Context) {
+	if o.artifacts == nil {
+		return
+	}
+	if err := o.artifacts.store.EnsureExpiry(ctx, tempArtifactPrefix, o.artifacts.tempTTLDays); err != nil {
+		o.logger.WithError(err).Warn("Failed to apply temporary artifact expiry")
+		return
+	}
+	o.logger.WithFields(logrus.Fields{
+		"bucket":   o.artifacts.store.Bucket(),
+		"prefix":   tempArtifactPrefix,
+		"ttl_days": o.artifacts.tempTTLDays,
+	}).Info("Temporary artifact expiry applied")
+}
+
+// promoteSavedLessonImages moves a saved lesson's session images to long-lived,
+// content-addressed objects so they outlive the temporary expiry, and points the
+// lesson at the new URLs
+func (o *Orchestrator) promoteSavedLessonImages(ctx context.Context, savedID string) {
+	if o.artifacts == nil {
+		return
+	}
+
+	o.mu.RLock()
+	lesson, exists := o.savedLessons[savedID]
+	var urls []string
+	if exists && lesson.Result != nil {
+		for url := range lesson.Result.Images {
+			urls = append(urls, url)
+		}
+	}
+	o.mu.RUnlock()
+
+	promoted := make(map[string]string)
+	for _, url := range urls {
+		object, ok := o.artifacts.objectName(url)
+		if !ok || !strings.HasPrefix(object, tempArtifactPrefix) {
+			continue
+		}
+		destination, err := o.artifacts.store.Promote(ctx, object, savedArtifactPrefix, o.artifacts.savedStorageClass)
+		if err != nil {
+			o.logger.WithFields(logrus.Fields{
+				"saved_id": savedID,
+				"object":   object,
+				"error":    err,
+			}).Warn("Failed to promote saved lesson image")
+			continue
+		}
+		promoted[url] = o.artifacts.objectURL(destination)
+	}
+	if len(promoted) == 0 {
+		return
+	}
+
+	o.mu.Lock()
+	if lesson, exists := o.savedLessons[savedID]; exists && lesson.Result != nil {
+		// The images map may be shared with the session result, so replace it rather than edit it
+		images := make(map[string]string, len(lesson.Result.Images))
+		for url, caption := range lesson.Result.Images {
+			if newURL, ok := promoted[url]; ok {
+				url = newURL
+			}
+			images[url] = caption
+		}
+		result := *lesson.Result
+		result.Images = images
+		lesson.Result = &result
+		lesson.UpdatedAt = time.Now()
+	}
+	o.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"saved_id": savedID,
+		"promoted": len(promoted),
+	}).Info("Saved lesson images moved to long-lived storage")
+}
+
+// releaseSavedLessonImages deletes the long-lived images of permanently deleted lessons
+// that no remaining saved lesson (including lessons in the trash) still references.
+// It returns the number of images deleted.
+func (o *Orchestrator) releaseSavedLessonImages(ctx context.Context, lessons ...*SavedLesson) int {
+	if o.artifacts == nil {
+		return 0
+	}
+
+	candidates := make(map[string]struct{})
+	for _, lesson := range lessons {
+		if lesson.Result == nil {
+			continue
+		}
+		for url := range lesson.Result.Images {
+			if object, ok := o.artifacts.objectName(url); ok && strings.HasPrefix(object, savedArtifactPrefix) {
+				candidates[object] = struct{}{}
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return 0
+	}
+
+	// Content-addressed objects may be shared; keep any that are still referenced
+	refs := o.artifactRefCounts()
+	deleted := 0
+	for object := range candidates {
+		if refs[object] > 0 {
+			continue
+		}
+		if err := o.artifacts.store.Delete(ctx, object); err != nil {
+			o.logger.WithFields(logrus.Fields{
+				"object": object,
+				"error":  err,
+			}).Warn("Failed to delete unreferenced lesson image")
+			continue
+		}
+		deleted++
+		o.logger.WithFields(logrus.Fields{
+			"object": object,
+		}).Info("Deleted unreferenced lesson image")
+	}
+	return deleted
+}
+
+// artifactRefCounts counts how many saved lessons reference each long-lived object
+func (o *Orchestrator) artifactRefCounts() map[string]int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	refs := make(map[string]int)
+	for _, lesson := range o.savedLessons {
+		if lesson.Result == nil {
+			continue
+		}
+		for url := range lesson.Result.Images {
+			if object, ok := o.artifacts.objectName(url); ok {
+				refs[object]++
+			}
+		}
+	}
+	return refs
+}
+
+// runArtifactTask runs a lifecycle operation in the background with its own timeout
+func (o *Orchestrator) runArtifactTask(task func(ctx context.Context)) {
+	if o.artifacts == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), artifactOperationTimeout)
+		defer cancel()
+		task(ctx)
+	}()
+}
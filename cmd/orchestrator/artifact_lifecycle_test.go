@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeArtifactStore is an in-memory ArtifactStore; objects promote to a name derived from their content
+type fakeArtifactStore struct {
+	mu       sync.Mutex
+	objects  map[string]string // object name -> content
+	classes  map[string]string // object name -> storage class
+	deleted  []string
+	expiries map[string]int
+}
+
+func newFakeArtifactStore(objects map[string]string) *fakeArtifactStore {
+	return &fakeArtifactStore{objects: objects, classes: make(map[string]string), expiries: make(map[string]int)}
+}
+
+func (f *fakeArtifactStore) Bucket() string { return "test-bucket" }
+
+func (f *fakeArtifactStore) EnsureExpiry(ctx context.Context, prefix string, days int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.expiries[prefix] = days
+	return nil
+}
+
+func (f *fakeArtifactStore) Promote(ctx context.Context, object, prefix, storageClass string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	destination := prefix + f.objects[object] + ".png"
+	f.objects[destination] = f.objects[object]
+	f.classes[destination] = storageClass
+	return destination, nil
+}
+
+func (f *fakeArtifactStore) Delete(ctx context.Context, object string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects, object)
+	f.deleted = append(f.deleted, object)
+	return nil
+}
+
+// newArtifactTestOrchestrator creates an orchestrator using a fake artifact store
+func newArtifactTestOrchestrator(store *fakeArtifactStore) *Orchestrator {
+	return &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		artifacts: &artifactLifecycle{
+			store:             store,
+			tempTTLDays:       7,
+			savedStorageClass: "NEARLINE",
+		},
+	}
+}
+
+const testBucketURL = "https://storage.googleapis.com/test-bucket/"
+
+// TestPromoteSavedLessonImages tests that saved lesson images move to shared long-lived objects
+func TestPromoteSavedLessonImages(t *testing.T) {
+	store := newFakeArtifactStore(map[string]string{
+		"sessions/s1/diagram_1.png": "aaa",
+		"sessions/s2/diagram_1.png": "aaa", // Same content as s1
+	})
+	o := newArtifactTestOrchestrator(store)
+
+	sessionImages := map[string]string{
+		testBucketURL + "sessions/s1/diagram_1.png": "Flow",
+		"https://example.com/external.png":          "External",
+	}
+	o.savedLessons["l1"] = &SavedLesson{ID: "l1", Result: &SessionResult{Images: sessionImages}}
+	o.savedLessons["l2"] = &SavedLesson{ID: "l2", Result: &SessionResult{Images: map[string]string{
+		testBucketURL + "sessions/s2/diagram_1.png": "Flow",
+	}}}
+
+	o.promoteSavedLessonImages(context.Background(), "l1")
+	o.promoteSavedLessonImages(context.Background(), "l2")
+
+	assert.Equal(t, map[string]string{
+		testBucketURL + "lessons/aaa.png":  "Flow",
+		"https://example.com/external.png": "External",
+	}, o.savedLessons["l1"].Result.Images)
+	assert.Contains(t, o.savedLessons["l2"].Result.Images, testBucketURL+"lessons/aaa.png")
+	assert.Equal(t, "NEARLINE", store.classes["lessons/aaa.png"])
+
+	// The session's own image map is left untouched
+	assert.Contains(t, sessionImages, testBucketURL+"sessions/s1/diagram_1.png")
+}
+
+// TestReleaseSavedLessonImagesChecksRefcount tests that shared images survive until the last lesson is deleted
+func TestReleaseSavedLessonImagesChecksRefcount(t *testing.T) {
+	store := newFakeArtifactStore(map[string]string{"lessons/aaa.png": "aaa"})
+	o := newArtifactTestOrchestrator(store)
+
+	shared := map[string]string{testBucketURL + "lessons/aaa.png": "Flow"}
+	l1 := &SavedLesson{ID: "l1", Result: &SessionResult{Images: shared}}
+	deletedAt := time.Now()
+	l2 := &SavedLesson{ID: "l2", Result: &SessionResult{Images: shared}, DeletedAt: &deletedAt}
+	o.savedLessons["l2"] = l2
+
+	// l2 is only in the trash and can still be restored, so the image stays
+	o.releaseSavedLessonImages(context.Background(), l1)
+	assert.Empty(t, store.deleted)
+
+	delete(o.savedLessons, "l2")
+	o.releaseSavedLessonImages(context.Background(), l2)
+	assert.Equal(t, []string{"lessons/aaa.png"}, store.deleted)
+}
+
+// TestEnsureTempExpiry tests that session images get the temporary expiry rule
+func TestEnsureTempExpiry(t *testing.T) {
+	store := newFakeArtifactStore(nil)
+	o := newArtifactTestOrchestrator(store)
+
+	o.ensureTempExpiry(context.Background())
+	assert.Equal(t, 7, store.expiries[tempArtifactPrefix])
+
+	object, ok := o.artifacts.objectName(testBucketURL + "sessions/s1/diagram_1.png")
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(object, tempArtifactPrefix))
+	_, ok = o.artifacts.objectName("https://storage.googleapis.com/other-bucket/sessions/x.png")
+	assert.False(t, ok)
+}
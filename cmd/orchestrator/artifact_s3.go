@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// defaultS3Region is used when neither S3_REGION nor the AWS configuration sets a region
+const defaultS3Region = "us-east-1"
+
+// gcsToS3StorageClass maps the Cloud Storage class names used by the artifact lifecycle settings
+// to the closest S3 storage class that still serves objects immediately
+var gcsToS3StorageClass = map[string]string{
+	"STANDARD": string(types.StorageClassStandard),
+	"NEARLINE": string(types.StorageClassStandardIa),
+	"COLDLINE": string(types.StorageClassGlacierIr),
+	"ARCHIVE":  string(types.StorageClassGlacierIr),
+}
+
+// s3ArtifactStore implements ArtifactStore and ExportStore for Amazon S3 and S3-compatible
+// services such as MinIO
+type s3ArtifactStore struct {
+	client     *s3.Client
+	presigner  *s3.PresignClient
+	bucket     string
+	publicURL  string // Base URL objects are served from, without a trailing slash
+	compatible bool   // A custom endpoint that may only support the STANDARD storage class
+}
+
+// newS3ArtifactStore creates an S3 artifact store. S3_ENDPOINT selects an S3-compatible service
+// (addressed path-style), S3_REGION the region and S3_ACCESS_KEY_ID / S3_SECRET_ACCESS_KEY static
+// credentials; otherwise the default AWS configuration and credential chain are used.
+// S3_PUBLIC_URL overrides the base URL objects are served from.
+func newS3ArtifactStore(ctx context.Context, bucket string) (*s3ArtifactStore, error) {
+	var options []func(*config.LoadOptions) error
+	if region := os.Getenv("S3_REGION"); region != "" {
+		options = append(options, config.WithRegion(region))
+	}
+	if accessKey := os.Getenv("S3_ACCESS_KEY_ID"); accessKey != "" {
+		options = append(options, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			accessKey, os.Getenv("S3_SECRET_ACCESS_KEY"), os.Getenv("S3_SESSION_TOKEN"))))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultS3Region
+	}
+
+	endpoint := strings.TrimSuffix(os.Getenv("S3_ENDPOINT"), "/")
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+
+	publicURL := strings.TrimSuffix(os.Getenv("S3_PUBLIC_URL"), "/")
+	if publicURL == "" {
+		if endpoint != "" {
+			publicURL = endpoint + "/" + bucket
+		} else {
+			publicURL = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, cfg.Region)
+		}
+	}
+
+	return &s3ArtifactStore{
+		client:     client,
+		presigner:  s3.NewPresignClient(client),
+		bucket:     bucket,
+		publicURL:  publicURL,
+		compatible: endpoint != "",
+	}, nil
+}
+
+// Bucket returns the bucket holding the artifacts
+func (s *s3ArtifactStore) Bucket() string {
+	return s.bucket
+}
+
+// ObjectURL returns the URL an object is served from
+func (s *s3ArtifactStore) ObjectURL(object string) string {
+	return s.publicURL + "/" + object
+}
+
+// ObjectName returns the object name for a URL served from the bucket
+func (s *s3ArtifactStore) ObjectName(url string) (string, bool) {
+	object, ok := strings.CutPrefix(url, s.publicURL+"/")
+	return object, ok && object != ""
+}
+
+// EnsureExpiry installs (or replaces) a bucket lifecycle rule expiring objects under prefix after days.
+// Rules for other prefixes are kept.
+func (s *s3ArtifactStore) EnsureExpiry(ctx context.Context, prefix string, days int) error {
+	rules := make([]types.LifecycleRule, 0)
+	existing, err := s.client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(s.bucket),
+	})
+	switch {
+	case err == nil:
+		for _, rule := range existing.Rules {
+			if isS3PrefixExpiryRule(rule, prefix) {
+				continue
+			}
+			rules = append(rules, rule)
+		}
+	case !isS3ErrorCode(err, "NoSuchLifecycleConfiguration"):
+		return fmt.Errorf("failed to get lifecycle of bucket %s: %w", s.bucket, err)
+	}
+
+	rules = append(rules, types.LifecycleRule{
+		ID:         aws.String("expire-" + strings.TrimSuffix(prefix, "/")),
+		Status:     types.ExpirationStatusEnabled,
+		Filter:     &types.LifecycleRuleFilter{Prefix: aws.String(prefix)},
+		Expiration: &types.LifecycleExpiration{Days: aws.Int32(int32(days))},
+	})
+	_, err = s.client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(s.bucket),
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: rules},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update lifecycle of bucket %s: %w", s.bucket, err)
+	}
+	return nil
+}
+
+// isS3PrefixExpiryRule reports whether a lifecycle rule is the expiry rule for exactly prefix
+func isS3PrefixExpiryRule(rule types.LifecycleRule, prefix string) bool {
+	return rule.Expiration != nil && len(rule.Transitions) == 0 &&
+		rule.Filter != nil && rule.Filter.And == nil && rule.Filter.Tag == nil && aws.ToString(rule.Filter.Prefix) == prefix
+}
+
+// Promote copies an object to a name derived from its ETag under prefix with the given storage class.
+// Objects with identical content share one copy, so promoting a duplicate is a no-op.
+func (s *s3ArtifactStore) Promote(ctx context.Context, object, prefix, storageClass string) (string, error) {
+	source, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(object)})
+	if err != nil {
+		return "", fmt.Errorf("failed to get object %s: %w", object, err)
+	}
+
+	// Single-part uploads have the content MD5 as their ETag; multipart ETags still identify the content
+	etag := strings.Trim(aws.ToString(source.ETag), `"`)
+	if etag == "" {
+		return "", fmt.Errorf("object %s has no usable checksum", object)
+	}
+	destination := prefix + etag + path.Ext(object)
+	storageClass = s.storageClass(storageClass)
+
+	existing, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(destination)})
+	if err == nil && s3ObjectStorageClass(existing.StorageClass) == storageClass {
+		return destination, nil
+	}
+	if err != nil && !isS3NotFound(err) {
+		return "", fmt.Errorf("failed to check object %s: %w", destination, err)
+	}
+
+	segments := strings.Split(object, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	_, err = s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:       aws.String(s.bucket),
+		Key:          aws.String(destination),
+		CopySource:   aws.String(s.bucket + "/" + strings.Join(segments, "/")),
+		StorageClass: types.StorageClass(storageClass),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to copy %s to %s: %w", object, destination, err)
+	}
+	return destination, nil
+}
+
+// storageClass returns the S3 storage class for a configured class name. Cloud Storage names are
+// translated; S3-compatible services get STANDARD for them since most support no other class.
+func (s *s3ArtifactStore) storageClass(class string) string {
+	class = strings.ToUpper(class)
+	mapped, ok := gcsToS3StorageClass[class]
+	switch {
+	case !ok:
+		return class
+	case s.compatible:
+		return string(types.StorageClassStandard)
+	default:
+		return mapped
+	}
+}
+
+// s3ObjectStorageClass returns an object's storage class; S3 omits it for STANDARD objects
+func s3ObjectStorageClass(class types.StorageClass) string {
+	if class == "" {
+		return string(types.StorageClassStandard)
+	}
+	return string(class)
+}
+
+// Delete removes an object; deleting a missing object is not an error
+func (s *s3ArtifactStore) Delete(ctx context.Context, object string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(object)})
+	if err != nil && !isS3NotFound(err) {
+		return fmt.Errorf("failed to delete object %s: %w", object, err)
+	}
+	return nil
+}
+
+// Upload writes an object, replacing any existing object with the same name
+func (s *s3ArtifactStore) Upload(ctx context.Context, object, contentType string, data io.Reader) error {
+	// Request signing hashes the body, which needs a seekable reader
+	body, ok := data.(io.ReadSeeker)
+	if !ok {
+		buffered, err := io.ReadAll(data)
+		if err != nil {
+			return fmt.Errorf("failed to read object %s: %w", object, err)
+		}
+		body = bytes.NewReader(buffered)
+	}
+
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(object),
+		ContentType: aws.String(contentType),
+		Body:        body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload object %s: %w", object, err)
+	}
+	return nil
+}
+
+// Download reads an object
+func (s *s3ArtifactStore) Download(ctx context.Context, object string) ([]byte, error) {
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(object)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download object %s: %w", object, err)
+	}
+	defer output.Body.Close()
+	return io.ReadAll(output.Body)
+}
+
+// SignedURL returns a presigned GET URL for an object that is valid for expiry
+func (s *s3ArtifactStore) SignedURL(ctx context.Context, object string, expiry time.Duration) (string, error) {
+	request, err := s.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(object),
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign object %s: %w", object, err)
+	}
+	return request.URL, nil
+}
+
+// isS3NotFound reports whether an S3 API error is a 404
+func isS3NotFound(err error) bool {
+	var responseErr *awshttp.ResponseError
+	return errors.As(err, &responseErr) && responseErr.HTTPStatusCode() == http.StatusNotFound
+}
+
+// isS3ErrorCode reports whether an S3 API error has the given error code
+func isS3ErrorCode(err error, code string) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3Server serves the S3 API calls the artifact store makes against a MinIO-style endpoint
+type fakeS3Server struct {
+	mu        sync.Mutex
+	etags     map[string]string // object key -> ETag
+	copies    map[string]string // destination key -> copy source
+	classes   map[string]string // destination key -> storage class
+	lifecycle string            // Last lifecycle configuration written
+}
+
+// ServeHTTP implements http.Handler
+func (f *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/test-bucket/")
+	switch {
+	case r.URL.Query().Has("lifecycle") && r.Method == http.MethodGet:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`<Error><Code>NoSuchLifecycleConfiguration</Code><Message>none</Message></Error>`))
+	case r.URL.Query().Has("lifecycle") && r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.lifecycle = string(body)
+	case r.Method == http.MethodHead:
+		etag, ok := f.etags[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"`+etag+`"`)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		f.copies[key] = r.Header.Get("X-Amz-Copy-Source")
+		f.classes[key] = r.Header.Get("X-Amz-Storage-Class")
+		f.etags[key] = f.etags[strings.TrimPrefix(f.copies[key], "test-bucket/")]
+		w.Write([]byte(`<CopyObjectResult><ETag>"` + f.etags[key] + `"</ETag></CopyObjectResult>`))
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// newTestS3ArtifactStore creates an S3 store backed by a fake S3-compatible server
+func newTestS3ArtifactStore(t *testing.T, fake *fakeS3Server) (*s3ArtifactStore, string) {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	t.Setenv("S3_ENDPOINT", server.URL)
+	t.Setenv("S3_REGION", "us-east-1")
+	t.Setenv("S3_ACCESS_KEY_ID", "minio")
+	t.Setenv("S3_SECRET_ACCESS_KEY", "minio-secret")
+	store, err := newS3ArtifactStore(context.Background(), "test-bucket")
+	require.NoError(t, err)
+	return store, server.URL
+}
+
+// TestArtifactStoreFromEnv tests selecting the artifact store backend
+func TestArtifactStoreFromEnv(t *testing.T) {
+	t.Setenv("ARTIFACT_STORE", "s3")
+	t.Setenv("S3_BUCKET", "")
+	store, err := artifactStoreFromEnv(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, store)
+
+	t.Setenv("ARTIFACT_STORE", "azure")
+	_, err = artifactStoreFromEnv(context.Background())
+	assert.ErrorContains(t, err, "unknown ARTIFACT_STORE")
+}
+
+// TestS3ArtifactStoreURLs tests public object URLs and presigned download links
+func TestS3ArtifactStoreURLs(t *testing.T) {
+	store, endpoint := newTestS3ArtifactStore(t, &fakeS3Server{})
+
+	url := store.ObjectURL("lessons/abc.png")
+	assert.Equal(t, endpoint+"/test-bucket/lessons/abc.png", url)
+	object, ok := store.ObjectName(url)
+	assert.True(t, ok)
+	assert.Equal(t, "lessons/abc.png", object)
+	_, ok = store.ObjectName("https://storage.googleapis.com/test-bucket/lessons/abc.png")
+	assert.False(t, ok)
+
+	// The artifact lifecycle resolves URLs through the store
+	lifecycle := &artifactLifecycle{store: store}
+	assert.Equal(t, url, lifecycle.objectURL("lessons/abc.png"))
+
+	link, err := store.SignedURL(context.Background(), "exports/library.zip", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, endpoint+"/test-bucket/exports/library.zip?"))
+	assert.Contains(t, link, "X-Amz-Expires=3600")
+	assert.Contains(t, link, "X-Amz-Signature=")
+}
+
+// TestS3ArtifactStorePromote tests copying objects to ETag-derived names
+func TestS3ArtifactStorePromote(t *testing.T) {
+	fake := &fakeS3Server{
+		etags:   map[string]string{"sessions/s1/diagram 1.png": "abc123"},
+		copies:  make(map[string]string),
+		classes: make(map[string]string),
+	}
+	store, _ := newTestS3ArtifactStore(t, fake)
+
+	destination, err := store.Promote(context.Background(), "sessions/s1/diagram 1.png", savedArtifactPrefix, "NEARLINE")
+	require.NoError(t, err)
+	assert.Equal(t, "lessons/abc123.png", destination)
+	assert.Equal(t, "test-bucket/sessions/s1/diagram%201.png", fake.copies[destination])
+	// S3-compatible endpoints get STANDARD in place of Cloud Storage classes
+	assert.Equal(t, "STANDARD", fake.classes[destination])
+
+	// Promoting identical content again reuses the existing copy
+	delete(fake.copies, destination)
+	destination, err = store.Promote(context.Background(), "sessions/s1/diagram 1.png", savedArtifactPrefix, "NEARLINE")
+	require.NoError(t, err)
+	assert.Equal(t, "lessons/abc123.png", destination)
+	assert.Empty(t, fake.copies)
+
+	_, err = store.Promote(context.Background(), "sessions/missing.png", savedArtifactPrefix, "NEARLINE")
+	assert.Error(t, err)
+}
+
+// TestS3ArtifactStoreEnsureExpiry tests installing the prefix expiry rule on a bucket without one
+func TestS3ArtifactStoreEnsureExpiry(t *testing.T) {
+	fake := &fakeS3Server{}
+	store, _ := newTestS3ArtifactStore(t, fake)
+
+	require.NoError(t, store.EnsureExpiry(context.Background(), tempArtifactPrefix, 7))
+	assert.Contains(t, fake.lifecycle, "<Prefix>sessions/</Prefix>")
+	assert.Contains(t, fake.lifecycle, "<Days>7</Days>")
+	assert.Contains(t, fake.lifecycle, "<Status>Enabled</Status>")
+}
+
+// TestS3StorageClass tests translating configured storage classes
+func TestS3StorageClass(t *testing.T) {
+	aws := &s3ArtifactStore{}
+	assert.Equal(t, "STANDARD_IA", aws.storageClass("nearline"))
+	assert.Equal(t, "GLACIER_IR", aws.storageClass("ARCHIVE"))
+	assert.Equal(t, "INTELLIGENT_TIERING", aws.storageClass("INTELLIGENT_TIERING"))
+
+	minio := &s3ArtifactStore{compatible: true}
+	assert.Equal(t, "STANDARD", minio.storageClass("NEARLINE"))
+	assert.Equal(t, "REDUCED_REDUNDANCY", minio.storageClass("REDUCED_REDUNDANCY"))
+}
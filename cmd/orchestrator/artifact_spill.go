@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/sirupsen/logrus"
+)
+
+// spilledArtifactLinkTTL is how long a download link to a spilled artifact is valid
+const spilledArtifactLinkTTL = 15 * time.Minute
+
+// artifactSpillStore is an object store holding artifacts too large to carry inline
+type artifactSpillStore interface {
+	ExportStore
+	// Download reads an object
+	Download(ctx context.Context, object string) ([]byte, error)
+}
+
+// artifactSpill enforces the artifact size caps on step responses, spilling oversized artifacts
+// to the artifact store under the session's temporary prefix so they expire with its images
+type artifactSpill struct {
+	limits adk.ArtifactLimits
+	store  artifactSpillStore // nil without an artifact store; oversized artifacts then fail their step
+}
+
+// artifactSpillFromEnv creates the artifact spill for the store selected by ARTIFACT_STORE, with
+// the caps from ADK_MAX_ARTIFACT_BYTES and ADK_MAX_RESPONSE_BYTES
+func artifactSpillFromEnv() *artifactSpill {
+	spill := &artifactSpill{limits: adk.ArtifactLimitsFromEnv()}
+	store, err := artifactStoreFromEnv(context.Background())
+	if err != nil {
+		logrus.WithError(err).Warn("Artifact storage not available, oversized artifacts will fail their step")
+		return spill
+	}
+	if spillStore, ok := store.(artifactSpillStore); ok {
+		spill.store = spillStore
+	}
+	return spill
+}
+
+// stepArtifactSpiller spills one step's artifacts under the session's prefix
+type stepArtifactSpiller struct {
+	store     artifactSpillStore
+	sessionID string
+	step      string
+}
+
+// SpillArtifact implements adk.ArtifactSpiller
+func (s stepArtifactSpiller) SpillArtifact(ctx context.Context, name, content string) (string, error) {
+	object := fmt.Sprintf("%s%s/artifacts/%s/%s", tempArtifactPrefix, s.sessionID, s.step, name)
+	if err := s.store.Upload(ctx, object, artifactContentType(content), strings.NewReader(content)); err != nil {
+		return "", err
+	}
+	return object, nil
+}
+
+// limitStepArtifacts applies the artifact size caps to a step's response, replacing oversized
+// artifacts with references to their spilled content
+func (o *Orchestrator) limitStepArtifacts(ctx context.Context, sessionID, stepName string, response *adk.TaskResponse) error {
+	if o.artifactSpill == nil {
+		return nil
+	}
+	var spiller adk.ArtifactSpiller
+	if o.artifactSpill.store != nil {
+		spiller = stepArtifactSpiller{store: o.artifactSpill.store, sessionID: sessionID, step: stepName}
+	}
+	spilled, err := response.EnforceArtifactLimits(ctx, o.artifactSpill.limits, spiller)
+	if len(spilled) > 0 {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"step":       stepName,
+			"artifacts":  spilled,
+		}).Info("Spilled oversized artifacts to the artifact store")
+	}
+	return err
+}
+
+// resolveArtifactRefs returns a copy of artifacts with spilled artifacts read back inline, for
+// agents and results that need their content
+func (o *Orchestrator) resolveArtifactRefs(ctx context.Context, artifacts map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(artifacts))
+	for name, content := range artifacts {
+		object, isRef := adk.ParseArtifactRef(content)
+		if !isRef {
+			resolved[name] = content
+			continue
+		}
+		if o.artifactSpill == nil || o.artifactSpill.store == nil {
+			return nil, fmt.Errorf("artifact %s was spilled but no artifact store is configured", name)
+		}
+		data, err := o.artifactSpill.store.Download(ctx, object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spilled artifact %s: %w", name, err)
+		}
+		resolved[name] = string(data)
+	}
+	return resolved, nil
+}
+
+// redirectToSpilledArtifact redirects an artifact request to a short-lived download link for
+// the artifact's spilled content
+func (o *Orchestrator) redirectToSpilledArtifact(w http.ResponseWriter, r *http.Request, object string) {
+	if o.artifactSpill == nil || o.artifactSpill.store == nil {
+		http.Error(w, "Artifact storage is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	url, err := o.artifactSpill.store.SignedURL(r.Context(), object, spilledArtifactLinkTTL)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"object": object,
+			"error":  err,
+		}).Error("Failed to sign spilled artifact URL")
+		http.Error(w, "Failed to create artifact link", http.StatusBadGateway)
+		return
+	}
+	http.Redirect(w, r, url, http.StatusFound)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Download implements artifactSpillStore
+func (s *memoryExportStore) Download(ctx context.Context, object string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[object]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+	return data, nil
+}
+
+// bulkyLessonAgentClient returns a large lesson from the explainer and records every step's inputs
+type bulkyLessonAgentClient struct {
+	mu     sync.Mutex
+	lesson string
+	inputs map[string]map[string]string
+}
+
+// ExecuteTask implements AgentClient
+func (c *bulkyLessonAgentClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inputs[req.Step] = req.Inputs
+	if req.Step == "explainer" {
+		return &adk.TaskResponse{Artifacts: map[string]string{"lesson": c.lesson}}, nil
+	}
+	return &adk.TaskResponse{Artifacts: map[string]string{"notes": "ok"}}, nil
+}
+
+// Health implements AgentClient
+func (c *bulkyLessonAgentClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// runBulkyLesson runs a pipeline whose explainer returns a lesson over the artifact cap
+func runBulkyLesson(t *testing.T, spill *artifactSpill) (*Orchestrator, *Session, *bulkyLessonAgentClient, error) {
+	o := &Orchestrator{
+		sessions:      make(map[string]*Session),
+		savedLessons:  make(map[string]*SavedLesson),
+		logger:        logrus.New(),
+		clients:       make(map[string][]chan SSEEvent),
+		artifactSpill: spill,
+	}
+	session := o.CreateSession("Caching")
+	agent := &bulkyLessonAgentClient{
+		lesson: `{"big_picture": "` + strings.Repeat("Caches keep hot data close. ", 20) + `"}`,
+		inputs: make(map[string]map[string]string),
+	}
+	config := DefaultPipelineConfig()
+	config.RetryDelay = time.Millisecond
+	config.MaxRetries = 0
+	p := &Pipeline{
+		config:     config,
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+	err := p.runPipeline(context.Background(), session.ID, o)
+	session, _ = o.GetSession(session.ID)
+	return o, session, agent, err
+}
+
+// TestArtifactSpillover tests that oversized artifacts are stored out of band and referenced in session outputs
+func TestArtifactSpillover(t *testing.T) {
+	store := &memoryExportStore{objects: make(map[string][]byte)}
+	o, session, agent, err := runBulkyLesson(t, &artifactSpill{limits: adk.ArtifactLimits{MaxArtifactBytes: 200}, store: store})
+	require.NoError(t, err)
+	assert.Equal(t, "completed", session.Status)
+
+	object, spilled := adk.ParseArtifactRef(session.partialOutputs["explainer"]["lesson"])
+	require.True(t, spilled, "expected the lesson to be spilled")
+	assert.Equal(t, "sessions/"+session.ID+"/artifacts/explainer/lesson", object)
+	assert.Equal(t, agent.lesson, string(store.objects[object]))
+
+	// Later agents and the session result see the lesson inline
+	assert.Equal(t, agent.lesson, agent.inputs["critic"]["lesson"])
+	require.NotNil(t, session.Result)
+	assert.Contains(t, session.Result.Lesson, "Caches keep hot data close")
+
+	router := chi.NewRouter()
+	router.Get("/api/sessions/{id}/artifacts/{step}/{name}", o.getSessionArtifactHandler)
+	w := serve(router, "GET", "/api/sessions/"+session.ID+"/artifacts/explainer/lesson")
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Contains(t, w.Header().Get("Location"), object)
+}
+
+// TestArtifactSpilloverWithoutStore tests that an oversized artifact fails its step when there is nowhere to spill it
+func TestArtifactSpilloverWithoutStore(t *testing.T) {
+	_, session, _, err := runBulkyLesson(t, &artifactSpill{limits: adk.ArtifactLimits{MaxArtifactBytes: 200}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), adk.ErrArtifactTooLarge.Error())
+	assert.Equal(t, "failed", session.Status)
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultAsyncRunConcurrency is how many async session runs execute at once
+	defaultAsyncRunConcurrency = 4
+	// statusPollInterval is the Retry-After hint given to clients polling an unfinished session
+	statusPollInterval = 2 * time.Second
+)
+
+// asyncRunConcurrencyFromEnv returns the async run concurrency (ASYNC_RUN_CONCURRENCY)
+func asyncRunConcurrencyFromEnv() int {
+	if v := os.Getenv("ASYNC_RUN_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		logrus.WithField("value", v).Warn("Invalid ASYNC_RUN_CONCURRENCY, using default")
+	}
+	return defaultAsyncRunConcurrency
+}
+
+// runQueue runs queued session runs with bounded concurrency, in FIFO order
+type runQueue struct {
+	concurrency int
+	mu          sync.Mutex
+	running     int
+	waiting     []queuedRun // Runs waiting for a slot, oldest first
+}
+
+// queuedRun is a session run waiting for a slot
+type queuedRun struct {
+	sessionID string
+	run       func()
+}
+
+// newRunQueue creates a run queue executing at most concurrency runs at once
+func newRunQueue(concurrency int) *runQueue {
+	if concurrency <= 0 {
+		concurrency = defaultAsyncRunConcurrency
+	}
+	return &runQueue{concurrency: concurrency}
+}
+
+// enqueue schedules run for a session and returns its 1-based queue position
+func (q *runQueue) enqueue(sessionID string, run func()) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.waiting = append(q.waiting, queuedRun{sessionID: sessionID, run: run})
+	position := len(q.waiting)
+	q.dispatchLocked()
+	return position
+}
+
+// dispatchLocked starts the oldest waiting runs while slots are free; q.mu must be held
+func (q *runQueue) dispatchLocked() {
+	for q.running < q.concurrency && len(q.waiting) > 0 {
+		next := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		q.running++
+
+		go func() {
+			defer func() {
+				q.mu.Lock()
+				q.running--
+				q.dispatchLocked()
+				q.mu.Unlock()
+			}()
+			next.run()
+		}()
+	}
+}
+
+// position returns a session's 1-based position in the queue, or 0 if it is not waiting
+func (q *runQueue) position(sessionID string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, queued := range q.waiting {
+		if queued.sessionID == sessionID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// asyncRunQueue returns the orchestrator's run queue, creating it on first use
+func (o *Orchestrator) asyncRunQueue() *runQueue {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.runQueue == nil {
+		o.runQueue = newRunQueue(asyncRunConcurrencyFromEnv())
+	}
+	return o.runQueue
+}
+
+// initSessionSteps records the pipeline's steps on the session as pending
+func (o *Orchestrator) initSessionSteps(session *Session, steps []PipelineStep) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	session.Steps = make([]SessionStep, len(steps))
+	session.partialOutputs = nil
+	session.Failure = nil
+	for i, step := range steps {
+		session.Steps[i] = SessionStep{
+			ID:     fmt.Sprintf("step-%d", i+1),
+			Name:   step.Name,
+			Status: "pending",
+		}
+	}
+	session.UpdatedAt = time.Now()
+}
+
+// markStepRunning records that a session step has started
+func (o *Orchestrator) markStepRunning(session *Session, index int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if index < 0 || index >= len(session.Steps) {
+		return
+	}
+	now := time.Now()
+	session.Steps[index].Status = "running"
+	session.Steps[index].StartedAt = &now
+	session.UpdatedAt = now
+}
+
+// markStepFinished records a session step's outcome
+func (o *Orchestrator) markStepFinished(session *Session, index int, result PipelineStepResult) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if index < 0 || index >= len(session.Steps) {
+		return
+	}
+	now := time.Now()
+	duration := result.Duration
+	step := &session.Steps[index]
+	step.Status = result.Status
+	step.CompletedAt = &now
+	step.Duration = &duration
+	step.Error = result.Error
+	if result.RetryCount > 0 {
+		step.Metadata = map[string]interface{}{"retry_count": result.RetryCount}
+	}
+	if model, ok := result.Metadata["model"].(string); ok {
+		if step.Metadata == nil {
+			step.Metadata = make(map[string]interface{})
+		}
+		step.Metadata["model"] = model
+		step.Metadata["model_fallbacks"] = result.Metadata["model_fallbacks"]
+	}
+	// Keep the step's usage so per-user usage can be totalled from sessions
+	if metrics, ok := result.Metadata["metrics"].(map[string]interface{}); ok && len(metrics) > 0 {
+		if step.Metadata == nil {
+			step.Metadata = make(map[string]interface{})
+		}
+		step.Metadata["metrics"] = metrics
+	}
+	if result.Status == "completed" && len(result.Output) > 0 {
+		if session.partialOutputs == nil {
+			session.partialOutputs = make(map[string]map[string]string)
+		}
+		session.partialOutputs[result.StepName] = result.Output
+	}
+	session.UpdatedAt = now
+}
+
+// SessionStatusResponse represents the pollable status of a session run
+type SessionStatusResponse struct {
+	SessionID      string          `json:"session_id"`
+	Status         string          `json:"status"`
+	QueuePosition  int             `json:"queue_position,omitempty"`
+	CompletedSteps int             `json:"completed_steps"`
+	TotalSteps     int             `json:"total_steps"`
+	CurrentStep    string          `json:"current_step,omitempty"`
+	Steps          []SessionStep   `json:"steps"`
+	StatusURL      string          `json:"status_url"`
+	ResultURL      string          `json:"result_url,omitempty"`
+	Failure        *SessionFailure `json:"failure,omitempty"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// sessionStatus builds a consistent snapshot of a session's run status
+func (o *Orchestrator) sessionStatus(sessionID string) (SessionStatusResponse, bool) {
+	o.mu.RLock()
+	session, exists := o.sessions[sessionID]
+	if !exists {
+		o.mu.RUnlock()
+		return SessionStatusResponse{}, false
+	}
+
+	status := SessionStatusResponse{
+		SessionID:  session.ID,
+		Status:     session.Status,
+		TotalSteps: len(session.Steps),
+		Steps:      make([]SessionStep, len(session.Steps)),
+		StatusURL:  fmt.Sprintf("/api/sessions/%s/status", session.ID),
+		Failure:    session.Failure,
+		UpdatedAt:  session.UpdatedAt,
+	}
+	copy(status.Steps, session.Steps)
+	queue := o.runQueue
+	o.mu.RUnlock()
+
+	for _, step := range status.Steps {
+		switch step.Status {
+		case "completed":
+			status.CompletedSteps++
+		case "running":
+			status.CurrentStep = step.Name
+		}
+	}
+	if status.Status == "completed" {
+		status.ResultURL = fmt.Sprintf("/api/sessions/%s/result", sessionID)
+	}
+	if status.Status == "queued" && queue != nil {
+		status.QueuePosition = queue.position(sessionID)
+	}
+	if status.Status == "queued" && status.QueuePosition == 0 && o.userRuns != nil {
+		status.QueuePosition = o.userRuns.position(sessionID)
+	}
+	return status, true
+}
+
+// runSessionAsync queues a session run and responds 202 with its status URL
+func (o *Orchestrator) runSessionAsync(w http.ResponseWriter, r *http.Request, session *Session) {
+	o.mu.Lock()
+	if session.Status == "running" || session.Status == "queued" {
+		o.mu.Unlock()
+		http.Error(w, "Session is already running", http.StatusConflict)
+		return
+	}
+	previousStatus := session.Status
+	session.Status = "queued"
+	session.UpdatedAt = time.Now()
+	o.mu.Unlock()
+
+	// The run waits for a slot among the user's runs, then for one in the shared queue
+	queue := o.asyncRunQueue()
+	user := runUser(r)
+	position, err := o.startUserRun(user, session.ID, func(run func()) { queue.enqueue(session.ID, run) })
+	if err != nil {
+		o.restoreSessionStatus(session, previousStatus)
+		o.writeUserRunLimitError(w, user, session.ID)
+		return
+	}
+	if position == 0 {
+		position = queue.position(session.ID)
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id":     session.ID,
+		"queue_position": position,
+	}).Info("Session run queued")
+
+	statusURL := fmt.Sprintf("/api/sessions/%s/status", session.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", statusURL)
+	w.Header().Set("Retry-After", strconv.Itoa(int(statusPollInterval.Seconds())))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session_id":     session.ID,
+		"status":         "queued",
+		"queue_position": position,
+		"status_url":     statusURL,
+		"result_url":     fmt.Sprintf("/api/sessions/%s/result", session.ID),
+	})
+}
+
+// restoreSessionStatus undoes claiming a session for a run that did not start
+func (o *Orchestrator) restoreSessionStatus(session *Session, status string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	session.Status = status
+	session.UpdatedAt = time.Now()
+}
+
+// getSessionStatusHandler handles GET /api/sessions/{id}/status
+func (o *Orchestrator) getSessionStatusHandler(w http.ResponseWriter, r *http.Request) {
+	status, exists := o.sessionStatus(chi.URLParam(r, "id"))
+	if !exists {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if status.Status != "completed" && status.Status != "failed" {
+		w.Header().Set("Retry-After", strconv.Itoa(int(statusPollInterval.Seconds())))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
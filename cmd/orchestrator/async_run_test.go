@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunQueuePositions tests FIFO queue positions with bounded concurrency
+func TestRunQueuePositions(t *testing.T) {
+	q := newRunQueue(1)
+	release := make(chan struct{})
+	started := make(chan string, 3)
+
+	q.enqueue("a", func() { started <- "a"; <-release })
+	require.Equal(t, "a", <-started)
+
+	assert.Equal(t, 1, q.enqueue("b", func() { started <- "b"; <-release }))
+	assert.Equal(t, 2, q.enqueue("c", func() { started <- "c" }))
+	assert.Equal(t, 0, q.position("a"))
+	assert.Equal(t, 1, q.position("b"))
+	assert.Equal(t, 2, q.position("c"))
+
+	release <- struct{}{}
+	require.Equal(t, "b", <-started)
+	assert.Equal(t, 1, q.position("c"))
+
+	release <- struct{}{}
+	require.Equal(t, "c", <-started)
+	assert.Equal(t, 0, q.position("c"))
+}
+
+// TestAsyncRunAndStatus tests that ?mode=async responds 202 and the status endpoint reports progress
+func TestAsyncRunAndStatus(t *testing.T) {
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"s1": {ID: "s1", Topic: "Raft", Status: "created", Metadata: map[string]interface{}{}},
+		},
+		logger:   logrus.New(),
+		clients:  make(map[string][]chan SSEEvent),
+		runQueue: newRunQueue(1),
+	}
+
+	// Occupy the only slot so the queued run stays queued for the rest of the test
+	blocker := make(chan struct{})
+	running := make(chan struct{})
+	o.runQueue.enqueue("other", func() { close(running); <-blocker })
+	<-running
+
+	r := chi.NewRouter()
+	r.Post("/api/sessions/{id}/run", o.runSessionHandler)
+	r.Get("/api/sessions/{id}/status", o.getSessionStatusHandler)
+
+	w := serve(r, "POST", "/api/sessions/s1/run?mode=async")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/api/sessions/s1/status", w.Header().Get("Location"))
+
+	var accepted map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &accepted))
+	assert.Equal(t, "queued", accepted["status"])
+	assert.Equal(t, float64(1), accepted["queue_position"])
+
+	// A queued session cannot be started again
+	assert.Equal(t, http.StatusConflict, serve(r, "POST", "/api/sessions/s1/run?mode=async").Code)
+
+	w = serve(r, "GET", "/api/sessions/s1/status")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	var status SessionStatusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, "queued", status.Status)
+	assert.Equal(t, 1, status.QueuePosition)
+
+	assert.Equal(t, http.StatusNotFound, serve(r, "GET", "/api/sessions/missing/status").Code)
+}
+
+// TestSessionStepProgress tests step-level progress in the status snapshot
+func TestSessionStepProgress(t *testing.T) {
+	session := &Session{ID: "s1", Status: "running", Metadata: map[string]interface{}{}}
+	o := &Orchestrator{
+		sessions: map[string]*Session{"s1": session},
+		logger:   logrus.New(),
+	}
+
+	o.initSessionSteps(session, []PipelineStep{{Name: "summarizer"}, {Name: "explainer"}, {Name: "critic"}})
+	o.markStepRunning(session, 0)
+	o.markStepFinished(session, 0, PipelineStepResult{Status: "completed", Duration: time.Second})
+	o.markStepRunning(session, 1)
+
+	status, ok := o.sessionStatus("s1")
+	require.True(t, ok)
+	assert.Equal(t, 3, status.TotalSteps)
+	assert.Equal(t, 1, status.CompletedSteps)
+	assert.Equal(t, "explainer", status.CurrentStep)
+	assert.Equal(t, "pending", status.Steps[2].Status)
+	assert.Empty(t, status.ResultURL)
+
+	session.Status = "completed"
+	status, _ = o.sessionStatus("s1")
+	assert.Equal(t, "/api/sessions/s1/result", status.ResultURL)
+}
+
+// TestStepModelMetadata tests that the model producing a step's artifacts is recorded on the step
+func TestStepModelMetadata(t *testing.T) {
+	session := &Session{ID: "s1", Status: "running", Metadata: map[string]interface{}{}}
+	o := &Orchestrator{
+		sessions: map[string]*Session{"s1": session},
+		logger:   logrus.New(),
+	}
+
+	o.initSessionSteps(session, []PipelineStep{{Name: "explainer"}, {Name: "critic"}})
+	o.markStepFinished(session, 0, PipelineStepResult{
+		Status:   "completed",
+		Metadata: map[string]interface{}{"model": "gemini-1.5-pro", "model_fallbacks": 1},
+	})
+	o.markStepFinished(session, 1, PipelineStepResult{Status: "completed", Metadata: map[string]interface{}{}})
+
+	assert.Equal(t, "gemini-1.5-pro", session.Steps[0].Metadata["model"])
+	assert.Equal(t, 1, session.Steps[0].Metadata["model_fallbacks"])
+	assert.Nil(t, session.Steps[1].Metadata)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/brainprint"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// brainprintRecommendations returns a profile's ranked explanation styles, ranking them now
+// for profiles that have none yet
+func brainprintRecommendations(profile *brainprint.UserLearningProfile) []brainprint.Recommendation {
+	if len(profile.Recommendations) > 0 {
+		return profile.Recommendations
+	}
+	return brainprint.Recommend(profile, time.Now())
+}
+
+// sessionExplanationType returns the explanation style a session was generated in, or "" if unknown
+func (o *Orchestrator) sessionExplanationType(sessionID string) string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	session, exists := o.sessions[sessionID]
+	if !exists {
+		return ""
+	}
+	explanationType, _ := session.Metadata["explanation_type"].(string)
+	return explanationType
+}
+
+// brainprintFeedbackHandler handles POST /api/brainprint/{userID}/feedback
+// It records a 1-5 rating of a lesson against the lesson's explanation style.
+func (o *Orchestrator) brainprintFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	w.Header().Set("Content-Type", "application/json")
+
+	var req struct {
+		SessionID       string  `json:"session_id,omitempty"`
+		ExplanationType string  `json:"explanation_type,omitempty"` // Defaults to the session's style
+		Rating          float64 `json:"rating"`                     // 1-5
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	explanationType := req.ExplanationType
+	if explanationType == "" && req.SessionID != "" {
+		explanationType = o.sessionExplanationType(req.SessionID)
+	}
+	if explanationType == "" {
+		writeJSONError(w, http.StatusBadRequest, "Invalid explanation type", "An explanation_type or a known session_id is required")
+		return
+	}
+
+	if err := o.brainprintSvc.RecordFeedback(r.Context(), userID, explanationType, req.Rating); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid rating", err.Error())
+		return
+	}
+	if req.SessionID != "" {
+		o.recordExperimentRating(req.SessionID, req.Rating)
+		o.recordSessionRating(req.SessionID, req.Rating)
+	}
+
+	profile, err := o.brainprintSvc.GetBrainPrint(r.Context(), userID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Failed to retrieve BrainPrint", err.Error())
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"userID":          userID,
+		"recommendedType": profile.RecommendedType,
+		"recommendations": brainprintRecommendations(profile),
+	})
+}
+
+// recordBrainPrintQuiz feeds a quiz score, as a fraction of the maximum, into the user's
+// BrainPrint against the style the quizzed session was generated in
+func (o *Orchestrator) recordBrainPrintQuiz(ctx context.Context, userID, sessionID string, score float64) {
+	if o.brainprintSvc == nil || sessionID == "" {
+		return
+	}
+	explanationType := o.sessionExplanationType(sessionID)
+	if explanationType == "" {
+		return
+	}
+	if err := o.brainprintSvc.RecordQuizScore(ctx, userID, explanationType, score); err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"user_id":    userID,
+			"session_id": sessionID,
+			"error":      err,
+		}).Warn("Failed to record quiz score for BrainPrint")
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/brainprint"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBrainPrintFeedbackAndQuizzes tests that ratings and quiz scores feed the recommendation and its reason
+func TestBrainPrintFeedbackAndQuizzes(t *testing.T) {
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"s1": {ID: "s1", Topic: "Recursion", Metadata: map[string]interface{}{"explanation_type": "analogy"}},
+		},
+		brainprintSvc: brainprint.NewService(nil),
+		logger:        logrus.New(),
+	}
+	ctx := context.Background()
+	require.NoError(t, o.brainprintSvc.TrackSession(ctx, "u1", "standard", true))
+	require.NoError(t, o.brainprintSvc.TrackSession(ctx, "u1", "standard", true))
+	require.NoError(t, o.brainprintSvc.TrackSession(ctx, "u1", "analogy", true))
+
+	router := chi.NewRouter()
+	router.Get("/api/brainprint/{userID}", o.getBrainPrintHandler)
+	router.Post("/api/brainprint/{userID}/feedback", o.brainprintFeedbackHandler)
+	router.Post("/api/goals/{userID}/evidence", o.recordGoalEvidenceHandler)
+
+	w := serveWithKey(router, "POST", "/api/brainprint/u1/feedback", "", `{"session_id": "s1", "rating": 5}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	learner := withPrincipal(router, &auth.Principal{UserID: "u1", Method: auth.MethodJWT})
+	require.Equal(t, http.StatusOK, serveWithKey(learner, "POST", "/api/goals/u1/evidence", "", `{"kind": "quiz", "session_id": "s1", "score": 10, "max_score": 10}`).Code)
+
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(router, "POST", "/api/brainprint/u1/feedback", "", `{"session_id": "s1", "rating": 9}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(router, "POST", "/api/brainprint/u1/feedback", "", `{"session_id": "unknown", "rating": 4}`).Code)
+
+	w = serve(router, "GET", "/api/brainprint/u1")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		RecommendedType      string                      `json:"recommendedType"`
+		RecommendationReason string                      `json:"recommendationReason"`
+		Recommendations      []brainprint.Recommendation `json:"recommendations"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Analogy", body.RecommendedType)
+	assert.Contains(t, body.RecommendationReason, "rated them 5.0/5 on average")
+	assert.Contains(t, body.RecommendationReason, "scored 100% on their quizzes")
+	require.Len(t, body.Recommendations, 2)
+	assert.Equal(t, "Standard", body.Recommendations[1].Type)
+
+	// Users without sessions get the default style with a reason
+	w = serve(router, "GET", "/api/brainprint/u2")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Standard", body.RecommendedType)
+	assert.NotEmpty(t, body.RecommendationReason)
+}
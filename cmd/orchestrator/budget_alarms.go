@@ -0,0 +1,479 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// budgetAlarmWindow is how far back observations count towards an alarm
+	budgetAlarmWindow = time.Hour
+	// defaultBudgetAlarmInterval is how often alarm thresholds are evaluated
+	defaultBudgetAlarmInterval = time.Minute
+	// budgetAlarmMinSamples is how many observations an average or percentile needs before it can alarm
+	budgetAlarmMinSamples = 5
+	// budgetAlarmWebhookTimeout bounds delivery of one alarm notification
+	budgetAlarmWebhookTimeout = 10 * time.Second
+)
+
+// Budget alarm names
+const (
+	alarmStepLatencyP95 = "step_latency_p95"
+	alarmSessionTokens  = "session_tokens"
+	alarmHourlyCost     = "hourly_cost"
+)
+
+// BudgetAlarmConfig holds the thresholds that raise budget alarms; a zero threshold is not checked
+type BudgetAlarmConfig struct {
+	StepLatencyP95 time.Duration `json:"step_latency_p95"` // Per step, over the window
+	SessionTokens  int           `json:"session_tokens"`   // Mean input and output tokens per finished session
+	HourlyCostUSD  float64       `json:"hourly_cost_usd"`  // Estimated spend over the window
+	Interval       time.Duration `json:"interval"`
+	WebhookURL     string        `json:"-"` // Receives firing and resolved alarms; Slack and Google Chat compatible
+}
+
+// enabled reports whether any threshold is set
+func (c BudgetAlarmConfig) enabled() bool {
+	return c.StepLatencyP95 > 0 || c.SessionTokens > 0 || c.HourlyCostUSD > 0
+}
+
+// budgetAlarmConfigFromEnv reads ALARM_STEP_LATENCY_P95, ALARM_SESSION_TOKENS, ALARM_HOURLY_COST_USD,
+// ALARM_INTERVAL and ALARM_WEBHOOK_URL
+func budgetAlarmConfigFromEnv() BudgetAlarmConfig {
+	config := BudgetAlarmConfig{
+		Interval:   defaultBudgetAlarmInterval,
+		WebhookURL: os.Getenv("ALARM_WEBHOOK_URL"),
+	}
+	if v := os.Getenv("ALARM_STEP_LATENCY_P95"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			config.StepLatencyP95 = d
+		} else {
+			logrus.WithField("value", v).Warn("Invalid ALARM_STEP_LATENCY_P95, step latency is not alarmed")
+		}
+	}
+	if v := os.Getenv("ALARM_SESSION_TOKENS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			config.SessionTokens = n
+		} else {
+			logrus.WithField("value", v).Warn("Invalid ALARM_SESSION_TOKENS, session tokens are not alarmed")
+		}
+	}
+	if v := os.Getenv("ALARM_HOURLY_COST_USD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			config.HourlyCostUSD = f
+		} else {
+			logrus.WithField("value", v).Warn("Invalid ALARM_HOURLY_COST_USD, hourly cost is not alarmed")
+		}
+	}
+	if v := os.Getenv("ALARM_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			config.Interval = d
+		} else {
+			logrus.WithField("value", v).Warn("Invalid ALARM_INTERVAL, using default")
+		}
+	}
+	return config
+}
+
+// BudgetAlarm is the latest evaluation of one threshold
+type BudgetAlarm struct {
+	Name      string     `json:"name"`
+	Step      string     `json:"step,omitempty"` // Set for per-step alarms
+	Value     float64    `json:"value"`
+	Threshold float64    `json:"threshold"`
+	Unit      string     `json:"unit"` // seconds, tokens or usd
+	Samples   int        `json:"samples"`
+	Firing    bool       `json:"firing"`
+	Since     *time.Time `json:"since,omitempty"` // When the alarm started firing
+}
+
+// key identifies an alarm across evaluations
+func (a BudgetAlarm) key() string {
+	if a.Step != "" {
+		return a.Name + ":" + a.Step
+	}
+	return a.Name
+}
+
+// stepObservation is one finished step
+type stepObservation struct {
+	at       time.Time
+	step     string
+	duration time.Duration
+	costUSD  float64
+}
+
+// sessionObservation is one finished pipeline run
+type sessionObservation struct {
+	at     time.Time
+	tokens int
+}
+
+// budgetMonitor records step and session usage and periodically checks it against the
+// configured thresholds. Alarms are logged, exported for Prometheus and, when a webhook
+// is configured, posted when they start and stop firing.
+type budgetMonitor struct {
+	config     BudgetAlarmConfig
+	logger     *logrus.Logger
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	steps    []stepObservation
+	sessions []sessionObservation
+	alarms   map[string]BudgetAlarm // Latest evaluation by key
+}
+
+// newBudgetMonitor creates a budget monitor, or returns nil if no threshold is set
+func newBudgetMonitor(config BudgetAlarmConfig, logger *logrus.Logger) *budgetMonitor {
+	if !config.enabled() {
+		return nil
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultBudgetAlarmInterval
+	}
+	return &budgetMonitor{
+		config:     config,
+		logger:     logger,
+		httpClient: &http.Client{Timeout: budgetAlarmWebhookTimeout},
+		alarms:     make(map[string]BudgetAlarm),
+	}
+}
+
+// recordStep implements StepCompleteHook
+func (m *budgetMonitor) recordStep(ctx context.Context, session *Session, step PipelineStep, result *PipelineStepResult) error {
+	if result.Status != "completed" {
+		return nil
+	}
+	metrics, _ := result.Metadata["metrics"].(map[string]interface{})
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps = append(m.steps, stepObservation{
+		at:       time.Now(),
+		step:     step.Name,
+		duration: result.Duration,
+		costUSD:  stepCostFromMetrics(metrics).CostUSD,
+	})
+	return nil
+}
+
+// recordSession implements PipelineCompleteHook
+func (m *budgetMonitor) recordSession(ctx context.Context, session *Session, result *PipelineResult) error {
+	tokens := 0
+	for _, step := range result.Steps {
+		metrics, _ := step.Metadata["metrics"].(map[string]interface{})
+		cost := stepCostFromMetrics(metrics)
+		tokens += cost.InputTokens + cost.OutputTokens
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions = append(m.sessions, sessionObservation{at: time.Now(), tokens: tokens})
+	return nil
+}
+
+// pruneLocked drops observations that have left the window
+func (m *budgetMonitor) pruneLocked(now time.Time) {
+	cutoff := now.Add(-budgetAlarmWindow)
+	i := 0
+	for i < len(m.steps) && m.steps[i].at.Before(cutoff) {
+		i++
+	}
+	m.steps = append(m.steps[:0], m.steps[i:]...)
+	i = 0
+	for i < len(m.sessions) && m.sessions[i].at.Before(cutoff) {
+		i++
+	}
+	m.sessions = append(m.sessions[:0], m.sessions[i:]...)
+}
+
+// percentile returns the p-th percentile of sorted values by the nearest-rank method
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// evaluateLocked checks the observations in the window against the thresholds
+func (m *budgetMonitor) evaluateLocked() []BudgetAlarm {
+	var alarms []BudgetAlarm
+
+	if m.config.StepLatencyP95 > 0 {
+		durations := make(map[string][]float64)
+		for _, obs := range m.steps {
+			durations[obs.step] = append(durations[obs.step], obs.duration.Seconds())
+		}
+		for step, values := range durations {
+			sort.Float64s(values)
+			p95 := percentile(values, 95)
+			threshold := m.config.StepLatencyP95.Seconds()
+			alarms = append(alarms, BudgetAlarm{
+				Name:      alarmStepLatencyP95,
+				Step:      step,
+				Value:     p95,
+				Threshold: threshold,
+				Unit:      "seconds",
+				Samples:   len(values),
+				Firing:    len(values) >= budgetAlarmMinSamples && p95 > threshold,
+			})
+		}
+	}
+
+	if m.config.SessionTokens > 0 {
+		total := 0
+		for _, obs := range m.sessions {
+			total += obs.tokens
+		}
+		mean := 0.0
+		if len(m.sessions) > 0 {
+			mean = float64(total) / float64(len(m.sessions))
+		}
+		alarms = append(alarms, BudgetAlarm{
+			Name:      alarmSessionTokens,
+			Value:     mean,
+			Threshold: float64(m.config.SessionTokens),
+			Unit:      "tokens",
+			Samples:   len(m.sessions),
+			Firing:    len(m.sessions) >= budgetAlarmMinSamples && mean > float64(m.config.SessionTokens),
+		})
+	}
+
+	if m.config.HourlyCostUSD > 0 {
+		cost := 0.0
+		for _, obs := range m.steps {
+			cost += obs.costUSD
+		}
+		alarms = append(alarms, BudgetAlarm{
+			Name:      alarmHourlyCost,
+			Value:     cost,
+			Threshold: m.config.HourlyCostUSD,
+			Unit:      "usd",
+			Samples:   len(m.steps),
+			Firing:    cost > m.config.HourlyCostUSD,
+		})
+	}
+
+	sort.Slice(alarms, func(i, j int) bool { return alarms[i].key() < alarms[j].key() })
+	return alarms
+}
+
+// evaluate checks the thresholds, stores the result and returns the alarms that started
+// or stopped firing since the last evaluation
+func (m *budgetMonitor) evaluate(now time.Time) []BudgetAlarm {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneLocked(now)
+	var changed []BudgetAlarm
+	alarms := make(map[string]BudgetAlarm)
+	for _, alarm := range m.evaluateLocked() {
+		previous, seen := m.alarms[alarm.key()]
+		switch {
+		case alarm.Firing && seen && previous.Firing:
+			alarm.Since = previous.Since
+		case alarm.Firing:
+			alarm.Since = &now
+			changed = append(changed, alarm)
+		case seen && previous.Firing:
+			changed = append(changed, alarm)
+		}
+		alarms[alarm.key()] = alarm
+	}
+	// Per-step alarms whose samples have all left the window are resolved too
+	for key, previous := range m.alarms {
+		if _, ok := alarms[key]; !ok && previous.Firing {
+			previous.Firing, previous.Since, previous.Samples = false, nil, 0
+			changed = append(changed, previous)
+		}
+	}
+	m.alarms = alarms
+	return changed
+}
+
+// snapshot returns the latest evaluation of every alarm, sorted by key
+func (m *budgetMonitor) snapshot() []BudgetAlarm {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	alarms := make([]BudgetAlarm, 0, len(m.alarms))
+	for _, alarm := range m.alarms {
+		alarms = append(alarms, alarm)
+	}
+	sort.Slice(alarms, func(i, j int) bool { return alarms[i].key() < alarms[j].key() })
+	return alarms
+}
+
+// check evaluates the thresholds and reports alarms that changed state
+func (m *budgetMonitor) check(ctx context.Context) {
+	for _, alarm := range m.evaluate(time.Now()) {
+		fields := logrus.Fields{
+			"alarm":     alarm.Name,
+			"value":     alarm.Value,
+			"threshold": alarm.Threshold,
+			"unit":      alarm.Unit,
+			"samples":   alarm.Samples,
+		}
+		if alarm.Step != "" {
+			fields["step"] = alarm.Step
+		}
+		if alarm.Firing {
+			m.logger.WithFields(fields).Warn("Budget alarm firing")
+		} else {
+			m.logger.WithFields(fields).Info("Budget alarm resolved")
+		}
+
+		if m.config.WebhookURL != "" {
+			if err := m.notify(ctx, alarm); err != nil {
+				m.logger.WithFields(logrus.Fields{
+					"alarm": alarm.key(),
+					"error": err,
+				}).Warn("Failed to send budget alarm notification")
+			}
+		}
+	}
+}
+
+// alarmText describes an alarm for chat notifications
+func alarmText(alarm BudgetAlarm) string {
+	status := "RESOLVED"
+	if alarm.Firing {
+		status = "FIRING"
+	}
+	subject := alarm.Name
+	if alarm.Step != "" {
+		subject += " (" + alarm.Step + ")"
+	}
+	return fmt.Sprintf("[%s] ExplainIQ budget alarm %s: %.4g %s against a threshold of %.4g over the last %s",
+		status, subject, alarm.Value, alarm.Unit, alarm.Threshold, budgetAlarmWindow)
+}
+
+// notify posts an alarm to the webhook
+func (m *budgetMonitor) notify(ctx context.Context, alarm BudgetAlarm) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"text":  alarmText(alarm),
+		"alarm": alarm,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode alarm: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, budgetAlarmWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.config.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// start evaluates the thresholds every interval until ctx is cancelled
+func (m *budgetMonitor) start(ctx context.Context) {
+	m.logger.WithFields(logrus.Fields{
+		"interval":         m.config.Interval,
+		"step_latency_p95": m.config.StepLatencyP95,
+		"session_tokens":   m.config.SessionTokens,
+		"hourly_cost_usd":  m.config.HourlyCostUSD,
+	}).Info("Budget alarm monitor started")
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Budget alarm monitor stopped")
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+// startBudgetMonitor runs the budget alarm checks until ctx is cancelled
+func (o *Orchestrator) startBudgetMonitor(ctx context.Context) {
+	if o.budgetAlarms == nil {
+		return
+	}
+	o.budgetAlarms.start(ctx)
+}
+
+// budgetAlarmsHandler handles GET /api/alarms
+func (o *Orchestrator) budgetAlarmsHandler(w http.ResponseWriter, r *http.Request) {
+	alarms := []BudgetAlarm{}
+	var config *BudgetAlarmConfig
+	if o.budgetAlarms != nil {
+		alarms = o.budgetAlarms.snapshot()
+		config = &o.budgetAlarms.config
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"alarms":     alarms,
+		"thresholds": config,
+		"monitoring": o.budgetAlarms != nil,
+	})
+}
+
+// budgetAlarmMetricsHandler handles GET /api/alarms/metrics, exporting alarms in the
+// Prometheus text format so Alertmanager can route them
+func (o *Orchestrator) budgetAlarmMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	var alarms []BudgetAlarm
+	if o.budgetAlarms != nil {
+		alarms = o.budgetAlarms.snapshot()
+	}
+
+	var b strings.Builder
+	b.WriteString("# HELP explainiq_budget_alarm_firing Whether a budget alarm is firing.\n")
+	b.WriteString("# TYPE explainiq_budget_alarm_firing gauge\n")
+	for _, alarm := range alarms {
+		firing := 0
+		if alarm.Firing {
+			firing = 1
+		}
+		fmt.Fprintf(&b, "explainiq_budget_alarm_firing{%s} %d\n", alarmLabels(alarm), firing)
+	}
+	b.WriteString("# HELP explainiq_budget_alarm_value Latest value checked by a budget alarm.\n")
+	b.WriteString("# TYPE explainiq_budget_alarm_value gauge\n")
+	for _, alarm := range alarms {
+		fmt.Fprintf(&b, "explainiq_budget_alarm_value{%s} %g\n", alarmLabels(alarm), alarm.Value)
+	}
+	b.WriteString("# HELP explainiq_budget_alarm_threshold Threshold of a budget alarm.\n")
+	b.WriteString("# TYPE explainiq_budget_alarm_threshold gauge\n")
+	for _, alarm := range alarms {
+		fmt.Fprintf(&b, "explainiq_budget_alarm_threshold{%s} %g\n", alarmLabels(alarm), alarm.Threshold)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
+
+// alarmLabels formats an alarm's Prometheus labels
+func alarmLabels(alarm BudgetAlarm) string {
+	labels := fmt.Sprintf("alarm=%q,unit=%q", alarm.Name, alarm.Unit)
+	if alarm.Step != "" {
+		labels += fmt.Sprintf(",step=%q", alarm.Step)
+	}
+	return labels
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBudgetAlarmConfigFromEnv tests reading alarm thresholds from the environment
+func TestBudgetAlarmConfigFromEnv(t *testing.T) {
+	t.Setenv("ALARM_STEP_LATENCY_P95", "20s")
+	t.Setenv("ALARM_SESSION_TOKENS", "50000")
+	t.Setenv("ALARM_HOURLY_COST_USD", "not-a-number")
+
+	config := budgetAlarmConfigFromEnv()
+	assert.Equal(t, 20*time.Second, config.StepLatencyP95)
+	assert.Equal(t, 50000, config.SessionTokens)
+	assert.Zero(t, config.HourlyCostUSD)
+	assert.Equal(t, defaultBudgetAlarmInterval, config.Interval)
+
+	assert.Nil(t, newBudgetMonitor(BudgetAlarmConfig{}, logrus.New()), "no thresholds, no monitor")
+}
+
+// TestBudgetAlarmsFireAndResolve tests alarms firing past their thresholds and resolving as observations age out
+func TestBudgetAlarmsFireAndResolve(t *testing.T) {
+	m := newBudgetMonitor(BudgetAlarmConfig{StepLatencyP95: 10 * time.Second, SessionTokens: 1000, HourlyCostUSD: 0.01}, logrus.New())
+	metrics := map[string]interface{}{"model": "gemini-2.5-flash", "input_tokens": 1000, "output_tokens": 1000}
+
+	for i := 0; i < budgetAlarmMinSamples; i++ {
+		duration := time.Second
+		if i == budgetAlarmMinSamples-1 {
+			duration = time.Minute
+		}
+		result := &PipelineStepResult{Status: "completed", Duration: duration, Metadata: map[string]interface{}{"metrics": metrics}}
+		require.NoError(t, m.recordStep(context.Background(), nil, PipelineStep{Name: "explainer"}, result))
+		require.NoError(t, m.recordSession(context.Background(), nil, &PipelineResult{Steps: []PipelineStepResult{*result}}))
+	}
+	// Failed steps do not count
+	require.NoError(t, m.recordStep(context.Background(), nil, PipelineStep{Name: "critic"}, &PipelineStepResult{Status: "failed", Duration: time.Hour}))
+
+	now := time.Now()
+	changed := m.evaluate(now)
+	require.Len(t, changed, 3)
+	assert.Equal(t, alarmHourlyCost, changed[0].Name)
+	assert.InDelta(t, 5*0.0028, changed[0].Value, 1e-9)
+	assert.Equal(t, alarmSessionTokens, changed[1].Name)
+	assert.Equal(t, 2000.0, changed[1].Value)
+	assert.Equal(t, alarmStepLatencyP95, changed[2].Name)
+	assert.Equal(t, "explainer", changed[2].Step)
+	assert.Equal(t, 60.0, changed[2].Value)
+	for _, alarm := range changed {
+		assert.True(t, alarm.Firing)
+		assert.Equal(t, now, *alarm.Since)
+	}
+
+	// Still firing alarms are not reported again
+	assert.Empty(t, m.evaluate(now.Add(time.Minute)))
+	assert.Equal(t, now, *m.snapshot()[0].Since)
+
+	// Once the observations leave the window every alarm resolves
+	changed = m.evaluate(now.Add(2 * budgetAlarmWindow))
+	require.Len(t, changed, 3)
+	for _, alarm := range changed {
+		assert.False(t, alarm.Firing)
+	}
+	assert.Len(t, m.snapshot(), 2, "step alarms without samples are dropped")
+}
+
+// TestBudgetAlarmWebhook tests posting firing and resolved alarms to the webhook
+func TestBudgetAlarmWebhook(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		received = append(received, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	m := newBudgetMonitor(BudgetAlarmConfig{HourlyCostUSD: 0.001, WebhookURL: server.URL}, logrus.New())
+	result := &PipelineStepResult{Status: "completed", Metadata: map[string]interface{}{
+		"metrics": map[string]interface{}{"model": "gemini-2.5-flash", "input_tokens": 1000, "output_tokens": 1000},
+	}}
+	require.NoError(t, m.recordStep(context.Background(), nil, PipelineStep{Name: "summarizer"}, result))
+
+	m.check(context.Background())
+	m.check(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	assert.Contains(t, received[0]["text"], "[FIRING] ExplainIQ budget alarm hourly_cost")
+}
+
+// TestBudgetAlarmHandlers tests the alarm status and Prometheus endpoints
+func TestBudgetAlarmHandlers(t *testing.T) {
+	m := newBudgetMonitor(BudgetAlarmConfig{StepLatencyP95: time.Second}, logrus.New())
+	for i := 0; i < budgetAlarmMinSamples; i++ {
+		m.recordStep(context.Background(), nil, PipelineStep{Name: "visualizer"}, &PipelineStepResult{Status: "completed", Duration: 3 * time.Second})
+	}
+	m.evaluate(time.Now())
+
+	o := &Orchestrator{logger: logrus.New(), budgetAlarms: m}
+	router := chi.NewRouter()
+	router.Get("/api/alarms", o.budgetAlarmsHandler)
+	router.Get("/api/alarms/metrics", o.budgetAlarmMetricsHandler)
+
+	w := serve(router, "GET", "/api/alarms")
+	require.Equal(t, http.StatusOK, w.Code)
+	var status struct {
+		Alarms     []BudgetAlarm `json:"alarms"`
+		Monitoring bool          `json:"monitoring"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.True(t, status.Monitoring)
+	require.Len(t, status.Alarms, 1)
+	assert.True(t, status.Alarms[0].Firing)
+
+	w = serve(router, "GET", "/api/alarms/metrics")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `explainiq_budget_alarm_firing{alarm="step_latency_p95",unit="seconds",step="visualizer"} 1`)
+	assert.Contains(t, w.Body.String(), `explainiq_budget_alarm_threshold{alarm="step_latency_p95",unit="seconds",step="visualizer"} 1`)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// toyCodeField is the lesson section holding the runnable code example
+const toyCodeField = "toy_example_code"
+
+// toyCodeRemoved replaces a code example that is still broken after a repair attempt
+const toyCodeRemoved = "N/A"
+
+// Outcomes of a toy code repair
+const (
+	CodeRepairRepaired   = "repaired"   // The rewritten code passed the critic
+	CodeRepairUnverified = "unverified" // The code was rewritten but could not be re-checked
+	CodeRepairRemoved    = "removed"    // The code could not be fixed and was dropped from the lesson
+)
+
+// toyCodeRepairFromEnv reports whether code examples the critic finds broken are regenerated (TOY_CODE_REPAIR, on by default)
+func toyCodeRepairFromEnv() bool {
+	return os.Getenv("TOY_CODE_REPAIR") != "false"
+}
+
+// CodeRepairReport records how a lesson's broken code example was handled
+type CodeRepairReport struct {
+	Status    string              `json:"status"`              // repaired, unverified or removed
+	Issues    []llm.CritiqueIssue `json:"issues"`              // Critical issues the critic found in the original code
+	Remaining []llm.CritiqueIssue `json:"remaining,omitempty"` // Critical issues the critic still found after the rewrite
+}
+
+// isCriticalIssue reports whether an issue is of critical severity
+func isCriticalIssue(issue llm.CritiqueIssue) bool {
+	return strings.EqualFold(strings.TrimSpace(issue.Severity), "critical")
+}
+
+// brokenCodeIssues returns the critic's critical issues with the code example
+func brokenCodeIssues(finalResult map[string]interface{}) []llm.CritiqueIssue {
+	critic, _ := finalResult["critic"].(map[string]string)
+	critique := critic["critique"]
+	if critique == "" {
+		return nil
+	}
+	var issues []llm.CritiqueIssue
+	if err := json.Unmarshal([]byte(critique), &issues); err != nil {
+		return nil
+	}
+	var broken []llm.CritiqueIssue
+	for _, issue := range issues {
+		if issue.Section == toyCodeField && isCriticalIssue(issue) {
+			broken = append(broken, issue)
+		}
+	}
+	return broken
+}
+
+// codeRepairGoal is the rewrite goal for a code example with the given problems
+func codeRepairGoal(issues []llm.CritiqueIssue) string {
+	var goal strings.Builder
+	goal.WriteString("fix the code so that it runs correctly, changing only what is needed. A reviewer found these problems")
+	for _, issue := range issues {
+		goal.WriteString(fmt.Sprintf("\n- %s", issue.Problem))
+		if output := strings.TrimSpace(issue.Output); output != "" {
+			goal.WriteString(fmt.Sprintf("\n  Output when run:\n  %s", strings.ReplaceAll(output, "\n", "\n  ")))
+		}
+	}
+	goal.WriteString("\nReturn the complete corrected code")
+	return goal.String()
+}
+
+// repairToyCode regenerates a lesson's code example when the critic flagged it as broken with
+// critical severity, re-prompting the explainer with the critic's findings for that section
+// only. If the rewrite fails or is still critically broken, the example is dropped rather
+// than shipped. It returns the lesson to use and a report, or nil if nothing was repaired.
+func (o *Orchestrator) repairToyCode(ctx context.Context, session *Session, lessonJSON string, finalResult map[string]interface{}) (string, *CodeRepairReport) {
+	if o.pipeline == nil || !o.pipeline.config.RepairToyCode || o.regenClient == nil {
+		return lessonJSON, nil
+	}
+	issues := brokenCodeIssues(finalResult)
+	if len(issues) == 0 {
+		return lessonJSON, nil
+	}
+	lesson := parseLesson(lessonJSON)
+	if lesson == nil {
+		return lessonJSON, nil
+	}
+
+	report := &CodeRepairReport{Issues: issues}
+	repairCtx := llm.WithRewriteGoal(ctx, codeRepairGoal(issues))
+	if persona, ok := session.Metadata["persona"].(string); ok && persona != "" {
+		repairCtx = llm.WithPersona(repairCtx, llm.LookupPersona(persona))
+	}
+	repaired := *lesson
+	rewritten, err := o.regenClient.RegenerateSections(repairCtx, session.Topic, *lesson, []string{toyCodeField})
+	if err != nil || strings.TrimSpace(rewritten[toyCodeField]) == "" {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": session.ID,
+			"error":      err,
+		}).Warn("Failed to repair broken code example, removing it from the lesson")
+		repaired.ToyExampleCode = toyCodeRemoved
+		report.Status = CodeRepairRemoved
+	} else {
+		repaired.ToyExampleCode = rewritten[toyCodeField]
+		report.Status = CodeRepairRepaired
+
+		// Check the rewrite with the critic before shipping it
+		remaining, err := critiqueSections(ctx, o.regenClient, &repaired, []string{toyCodeField})
+		for _, issue := range remaining {
+			if isCriticalIssue(issue) {
+				report.Remaining = append(report.Remaining, issue)
+			}
+		}
+		switch {
+		case err != nil:
+			o.logger.WithFields(logrus.Fields{
+				"session_id": session.ID,
+				"error":      err,
+			}).Warn("Failed to critique repaired code example, keeping it unverified")
+			report.Status = CodeRepairUnverified
+		case len(report.Remaining) > 0:
+			repaired.ToyExampleCode = toyCodeRemoved
+			report.Status = CodeRepairRemoved
+		}
+	}
+
+	repairedJSON, err := json.Marshal(repaired)
+	if err != nil {
+		return lessonJSON, nil
+	}
+	o.logger.WithFields(logrus.Fields{
+		"session_id": session.ID,
+		"issues":     len(issues),
+		"status":     report.Status,
+	}).Info("Broken code example handled")
+	return string(repairedJSON), report
+}
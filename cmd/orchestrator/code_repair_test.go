@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenCodeLessonJSON is a lesson whose code example does not run
+const brokenCodeLessonJSON = `{"big_picture": "Caches keep data close.", "toy_example_code": "print(cache[key)"}`
+
+// criticFinalResult returns a final result whose critic found the given issues
+func criticFinalResult(t *testing.T, issues ...llm.CritiqueIssue) map[string]interface{} {
+	critique, err := json.Marshal(issues)
+	require.NoError(t, err)
+	return map[string]interface{}{
+		"explainer": map[string]string{"lesson": brokenCodeLessonJSON},
+		"critic":    map[string]string{"critique": string(critique)},
+	}
+}
+
+// syntaxErrorIssue is a critical issue with the code example, with the output of running it
+var syntaxErrorIssue = llm.CritiqueIssue{
+	Section:  "toy_example_code",
+	Problem:  "Unbalanced brackets",
+	Severity: "Critical",
+	Output:   "SyntaxError: closing parenthesis ')' does not match opening parenthesis '['",
+}
+
+// TestRepairToyCode tests that a critically broken code example is regenerated from the critic's findings
+func TestRepairToyCode(t *testing.T) {
+	regen := &simplifyingRegenerator{text: "print(cache[key])"}
+	o := newReadabilityTestOrchestrator(regen, false)
+	session := &Session{ID: "s1", Topic: "Caching", Metadata: map[string]interface{}{}}
+	finalResult := criticFinalResult(t, syntaxErrorIssue, llm.CritiqueIssue{Section: "big_picture", Problem: "Vague", Severity: "low"})
+
+	lessonJSON, report := o.repairToyCode(context.Background(), session, brokenCodeLessonJSON, finalResult)
+	require.NotNil(t, report)
+	assert.Equal(t, CodeRepairRepaired, report.Status)
+	assert.Equal(t, []llm.CritiqueIssue{syntaxErrorIssue}, report.Issues)
+	assert.Equal(t, []string{"toy_example_code"}, regen.sections)
+	assert.Contains(t, regen.goal, "Unbalanced brackets")
+	assert.Contains(t, regen.goal, "SyntaxError: closing parenthesis")
+
+	lesson := parseLesson(lessonJSON)
+	require.NotNil(t, lesson)
+	assert.Equal(t, "print(cache[key])", lesson.ToyExampleCode)
+	assert.Equal(t, "Caches keep data close.", lesson.BigPicture)
+}
+
+// TestRepairToyCodeStillBroken tests that code still critically broken after the rewrite is dropped
+func TestRepairToyCodeStillBroken(t *testing.T) {
+	regen := &stubRegenerator{critique: &llm.CritiqueResponse{Issues: []llm.CritiqueIssue{syntaxErrorIssue}}}
+	o := newReadabilityTestOrchestrator(regen, false)
+	o.regenClient = regen
+	session := &Session{ID: "s1", Topic: "Caching", Metadata: map[string]interface{}{}}
+
+	lessonJSON, report := o.repairToyCode(context.Background(), session, brokenCodeLessonJSON, criticFinalResult(t, syntaxErrorIssue))
+	require.NotNil(t, report)
+	assert.Equal(t, CodeRepairRemoved, report.Status)
+	assert.Len(t, report.Remaining, 1)
+	assert.Equal(t, toyCodeRemoved, parseLesson(lessonJSON).ToyExampleCode)
+	assert.Contains(t, regen.critiqued, "new toy_example_code")
+
+	// A rewrite that cannot be re-checked is kept, marked unverified
+	regen = &stubRegenerator{critiqueErr: errors.New("critic unavailable")}
+	o.regenClient = regen
+	lessonJSON, report = o.repairToyCode(context.Background(), session, brokenCodeLessonJSON, criticFinalResult(t, syntaxErrorIssue))
+	require.NotNil(t, report)
+	assert.Equal(t, CodeRepairUnverified, report.Status)
+	assert.Equal(t, "new toy_example_code", parseLesson(lessonJSON).ToyExampleCode)
+}
+
+// TestRepairToyCodeSkipped tests that lessons without critical code issues, or with repair off, are left alone
+func TestRepairToyCodeSkipped(t *testing.T) {
+	regen := &simplifyingRegenerator{text: "unused"}
+	o := newReadabilityTestOrchestrator(regen, false)
+	session := &Session{ID: "s1", Topic: "Caching", Metadata: map[string]interface{}{}}
+
+	highIssue := syntaxErrorIssue
+	highIssue.Severity = "high"
+	lessonJSON, report := o.repairToyCode(context.Background(), session, brokenCodeLessonJSON, criticFinalResult(t, highIssue))
+	assert.Nil(t, report)
+	assert.Equal(t, brokenCodeLessonJSON, lessonJSON)
+
+	o.pipeline.config.RepairToyCode = false
+	lessonJSON, report = o.repairToyCode(context.Background(), session, brokenCodeLessonJSON, criticFinalResult(t, syntaxErrorIssue))
+	assert.Nil(t, report)
+	assert.Equal(t, brokenCodeLessonJSON, lessonJSON)
+	assert.Nil(t, regen.sections)
+}
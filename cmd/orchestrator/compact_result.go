@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+)
+
+const (
+	// compactSectionChars caps each section of a compact lesson
+	compactSectionChars = 600
+	// compactSummaryChars caps the summary of a compact lesson
+	compactSummaryChars = 280
+	// compactOutlineItems caps the outline bullets of a compact lesson
+	compactOutlineItems = 5
+)
+
+// CompactSection is a lesson section as plain text
+type CompactSection struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Text      string `json:"text"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// CompactLesson is a session result trimmed for popovers and browser extensions: plain-text
+// sections of capped length, no inline images and no fields that need the full OGLesson schema
+type CompactLesson struct {
+	SessionID string            `json:"session_id"`
+	Topic     string            `json:"topic"`
+	Status    string            `json:"status"`
+	Partial   bool              `json:"partial,omitempty"` // Built from the steps finished so far
+	Summary   string            `json:"summary,omitempty"`
+	Outline   []string          `json:"outline,omitempty"`
+	Sections  []CompactSection  `json:"sections"`
+	ImageURLs map[string]string `json:"image_urls,omitempty"` // Only images hosted at http(s) URLs
+	Truncated bool              `json:"truncated,omitempty"`  // Some text was cut to fit
+}
+
+// compactText converts text to capped plain text
+func compactText(text string, max int) (string, bool) {
+	return llm.TruncateText(llm.MarkdownToPlainText(text), max)
+}
+
+// compactResult builds the compact form of a session's result
+func compactResult(session *Session, result *SessionResult, partial bool) *CompactLesson {
+	compact := &CompactLesson{
+		SessionID: session.ID,
+		Topic:     session.Topic,
+		Status:    session.Status,
+		Partial:   partial,
+		Sections:  []CompactSection{},
+	}
+
+	var truncated bool
+	compact.Summary, truncated = compactText(result.Summary, compactSummaryChars)
+	compact.Truncated = compact.Truncated || truncated
+
+	for i, bullet := range result.Outline {
+		if i == compactOutlineItems {
+			compact.Truncated = true
+			break
+		}
+		bullet, truncated = compactText(bullet, compactSummaryChars)
+		compact.Outline = append(compact.Outline, bullet)
+		compact.Truncated = compact.Truncated || truncated
+	}
+
+	addSection := func(id, title, text string, code bool) {
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		// Unfenced example code is kept verbatim rather than read as Markdown
+		if !code || strings.HasPrefix(strings.TrimSpace(text), "```") {
+			text = llm.MarkdownToPlainText(text)
+		}
+		plain, truncated := llm.TruncateText(strings.Trim(text, "\n"), compactSectionChars)
+		compact.Sections = append(compact.Sections, CompactSection{ID: id, Title: title, Text: plain, Truncated: truncated})
+		compact.Truncated = compact.Truncated || truncated
+	}
+	if lesson := parseLesson(result.Lesson); lesson != nil {
+		for _, section := range llm.LessonSections {
+			text, _ := lesson.SectionText(section.ID)
+			addSection(section.ID, section.Title, text, section.Field == "toy_example_code")
+		}
+	} else {
+		addSection("lesson", "Lesson", result.Lesson, false)
+	}
+
+	for name, image := range result.Images {
+		if llm.IsWebURL(image) {
+			if compact.ImageURLs == nil {
+				compact.ImageURLs = make(map[string]string)
+			}
+			compact.ImageURLs[name] = image
+		}
+	}
+	return compact
+}
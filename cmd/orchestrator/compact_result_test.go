@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetSessionResultCompact tests the trimmed plain-text result format for extensions
+func TestGetSessionResultCompact(t *testing.T) {
+	lesson, err := json.Marshal(map[string]string{
+		"big_picture":      "A **cache** keeps [hot data](https://example.com) close.",
+		"core_mechanism":   strings.Repeat("Lookups check the cache first. ", 40),
+		"toy_example_code": "x := a*b*c",
+	})
+	require.NoError(t, err)
+	session := &Session{ID: "s1", Topic: "Caching", Status: "completed", Result: &SessionResult{
+		Lesson:  string(lesson),
+		Summary: "## Caching\nCaches trade memory for speed.",
+		Outline: []string{"What", "Why", "How", "When", "Where", "Pitfalls"},
+		Images: map[string]string{
+			"diagram": "data:image/png;base64,iVBORw0KGgo=",
+			"flow":    "https://storage.example.com/flow.png",
+		},
+	}}
+	o := &Orchestrator{sessions: map[string]*Session{"s1": session}, logger: logrus.New()}
+	router := chi.NewRouter()
+	router.Get("/api/sessions/{id}/result", o.getSessionResultHandler)
+
+	w := serve(router, "GET", "/api/sessions/s1/result?format=compact")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "base64")
+	var compact CompactLesson
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &compact))
+
+	assert.Equal(t, "Caching", compact.Topic)
+	assert.Equal(t, "Caching\nCaches trade memory for speed.", compact.Summary)
+	assert.Len(t, compact.Outline, compactOutlineItems)
+	assert.True(t, compact.Truncated)
+	assert.Equal(t, map[string]string{"flow": "https://storage.example.com/flow.png"}, compact.ImageURLs)
+
+	require.Len(t, compact.Sections, 3)
+	assert.Equal(t, CompactSection{ID: "big-picture", Title: "Big Picture", Text: "A cache keeps hot data close."}, compact.Sections[0])
+	assert.True(t, compact.Sections[1].Truncated)
+	assert.LessOrEqual(t, len([]rune(compact.Sections[1].Text)), compactSectionChars)
+	assert.Equal(t, "x := a*b*c", compact.Sections[2].Text, "example code is not read as Markdown")
+
+	assert.Equal(t, http.StatusBadRequest, serve(router, "GET", "/api/sessions/s1/result?format=xml").Code)
+}
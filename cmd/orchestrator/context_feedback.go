@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Context feedback ratings
+const (
+	contextHelpful   = "helpful"
+	contextUnhelpful = "unhelpful"
+)
+
+const (
+	// contextFeedbackMaxBoost is the largest fraction by which feedback raises or lowers a document's score
+	contextFeedbackMaxBoost = 0.5
+	// contextFeedbackPrior is the number of neutral ratings every document starts with, so a few
+	// ratings move its score only a little
+	contextFeedbackPrior = 5
+)
+
+// InjectedContext is a retrieved document that was given to a session's agents
+type InjectedContext struct {
+	DocID    string   `json:"doc_id"`
+	Index    string   `json:"index,omitempty"` // Retrieval index the document came from; empty for seeded context
+	Topic    string   `json:"topic,omitempty"`
+	Section  string   `json:"section,omitempty"`
+	Snippet  string   `json:"snippet,omitempty"`
+	Score    float64  `json:"score"`
+	Steps    []string `json:"steps"`              // Steps the document was injected into
+	Feedback string   `json:"feedback,omitempty"` // helpful or unhelpful, once rated
+}
+
+// ContextFeedbackRequest rates one injected context document
+type ContextFeedbackRequest struct {
+	DocID  string `json:"doc_id"`
+	Rating string `json:"rating"` // helpful or unhelpful
+}
+
+// docFeedback tallies the ratings of one document
+type docFeedback struct {
+	Helpful   int
+	Unhelpful int
+}
+
+// contextFeedbackStore accumulates context ratings by retrieval index and document ID. It boosts
+// helpful documents and demotes unhelpful ones in later hybrid searches.
+type contextFeedbackStore struct {
+	mu   sync.RWMutex
+	docs map[string]map[string]*docFeedback
+}
+
+// newContextFeedbackStore creates an empty feedback store
+func newContextFeedbackStore() *contextFeedbackStore {
+	return &contextFeedbackStore{docs: make(map[string]map[string]*docFeedback)}
+}
+
+// record counts a rating of a document, withdrawing the previous rating of the same session
+func (s *contextFeedbackStore) record(index, docID, previous, rating string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.docs[index] == nil {
+		s.docs[index] = make(map[string]*docFeedback)
+	}
+	tally := s.docs[index][docID]
+	if tally == nil {
+		tally = &docFeedback{}
+		s.docs[index][docID] = tally
+	}
+	switch previous {
+	case contextHelpful:
+		tally.Helpful--
+	case contextUnhelpful:
+		tally.Unhelpful--
+	}
+	switch rating {
+	case contextHelpful:
+		tally.Helpful++
+	case contextUnhelpful:
+		tally.Unhelpful++
+	}
+}
+
+// DocBoost implements elastic.ScoreBooster. Documents without feedback keep their score.
+func (s *contextFeedbackStore) DocBoost(index, docID string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tally := s.docs[index][docID]
+	if tally == nil {
+		return 1
+	}
+	net := float64(tally.Helpful - tally.Unhelpful)
+	return 1 + contextFeedbackMaxBoost*net/float64(tally.Helpful+tally.Unhelpful+contextFeedbackPrior)
+}
+
+// recordInjectedContext adds the documents given to a step to the session's injected context.
+// Documents already injected by another step or an earlier run keep their rating.
+func (o *Orchestrator) recordInjectedContext(sessionID, step string, docs []ContextDoc) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	session, exists := o.sessions[sessionID]
+	if !exists {
+		return
+	}
+	for _, doc := range docs {
+		if doc.Doc.ID == "" {
+			continue
+		}
+		var injected *InjectedContext
+		for _, existing := range session.Context {
+			if existing.DocID == doc.Doc.ID && existing.Index == doc.Index {
+				injected = existing
+				break
+			}
+		}
+		if injected == nil {
+			injected = &InjectedContext{DocID: doc.Doc.ID, Index: doc.Index}
+			session.Context = append(session.Context, injected)
+		}
+		injected.Topic = doc.Doc.Topic
+		injected.Section = doc.Doc.Section
+		injected.Snippet = doc.Snippet
+		injected.Score = doc.Score
+		if !containsString(injected.Steps, step) {
+			injected.Steps = append(injected.Steps, step)
+		}
+	}
+}
+
+// getSessionContextHandler handles GET /api/sessions/{id}/context
+func (o *Orchestrator) getSessionContextHandler(w http.ResponseWriter, r *http.Request) {
+	session, exists := o.GetSession(chi.URLParam(r, "id"))
+	if !exists {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	o.mu.RLock()
+	injected := make([]InjectedContext, 0, len(session.Context))
+	for _, doc := range session.Context {
+		injected = append(injected, *doc)
+	}
+	o.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session_id": session.ID,
+		"context":    injected,
+	})
+}
+
+// contextFeedbackHandler handles POST /api/sessions/{id}/context-feedback. Ratings of retrieved
+// documents boost or demote them in future searches of the same index; rating a document again
+// replaces the session's earlier rating.
+func (o *Orchestrator) contextFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+	session, exists := o.GetSession(chi.URLParam(r, "id"))
+	if !exists {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	var req ContextFeedbackRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Rating != contextHelpful && req.Rating != contextUnhelpful {
+		http.Error(w, "Rating must be helpful or unhelpful", http.StatusBadRequest)
+		return
+	}
+
+	o.mu.Lock()
+	var injected *InjectedContext
+	for _, doc := range session.Context {
+		if doc.DocID == req.DocID {
+			injected = doc
+			break
+		}
+	}
+	if injected == nil {
+		o.mu.Unlock()
+		http.Error(w, "Document was not injected into this session", http.StatusNotFound)
+		return
+	}
+	previous := injected.Feedback
+	injected.Feedback = req.Rating
+	rated := *injected
+	o.mu.Unlock()
+
+	if rated.Index != "" && previous != req.Rating {
+		o.contextFeedback.record(rated.Index, rated.DocID, previous, req.Rating)
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id": session.ID,
+		"doc_id":     rated.DocID,
+		"index":      rated.Index,
+		"rating":     req.Rating,
+	}).Info("Context feedback recorded")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rated)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/elastic"
+	"github.com/InnoFusionTech/ExplainIQ/internal/retrieval"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContextFeedbackStoreBoost tests that ratings raise or lower a document's boost and re-rating replaces a vote
+func TestContextFeedbackStoreBoost(t *testing.T) {
+	store := newContextFeedbackStore()
+	assert.Equal(t, 1.0, store.DocBoost("lessons", "d1"))
+
+	store.record("lessons", "d1", "", contextHelpful)
+	assert.Greater(t, store.DocBoost("lessons", "d1"), 1.0)
+	assert.Equal(t, 1.0, store.DocBoost("lessons-acme", "d1"), "feedback is kept per index")
+
+	store.record("lessons", "d1", contextHelpful, contextUnhelpful)
+	assert.Less(t, store.DocBoost("lessons", "d1"), 1.0)
+	assert.Equal(t, docFeedback{Unhelpful: 1}, *store.docs["lessons"]["d1"])
+
+	for i := 0; i < 100; i++ {
+		store.record("lessons", "d1", "", contextUnhelpful)
+	}
+	assert.GreaterOrEqual(t, store.DocBoost("lessons", "d1"), 1-contextFeedbackMaxBoost)
+}
+
+// TestContextFeedbackHandlers tests listing a session's injected context and rating it
+func TestContextFeedbackHandlers(t *testing.T) {
+	o := &Orchestrator{
+		sessions:        make(map[string]*Session),
+		logger:          logrus.New(),
+		metaIndex:       newMetadataIndex(),
+		contextFeedback: newContextFeedbackStore(),
+	}
+	session := o.CreateSession("Caching")
+	o.recordInjectedContext(session.ID, "summarizer", []ContextDoc{
+		{Doc: elastic.Doc{ID: "d1", Topic: "Caching", Section: "Basics"}, Score: 0.9, Snippet: "A cache keeps hot data close.", Index: "lessons"},
+		{Doc: elastic.Doc{ID: "saved#Lesson", Topic: "Caching"}, Score: 1},
+		{Doc: elastic.Doc{Topic: "No ID"}},
+	})
+	o.recordInjectedContext(session.ID, "explainer", []ContextDoc{
+		{Doc: elastic.Doc{ID: "d1", Topic: "Caching", Section: "Basics"}, Score: 0.8, Snippet: "A cache keeps hot data close.", Index: "lessons"},
+	})
+
+	router := chi.NewRouter()
+	router.Get("/api/sessions/{id}/context", o.getSessionContextHandler)
+	router.Post("/api/sessions/{id}/context-feedback", o.contextFeedbackHandler)
+
+	w := serve(router, http.MethodGet, "/api/sessions/"+session.ID+"/context")
+	require.Equal(t, http.StatusOK, w.Code)
+	var listing struct {
+		Context []InjectedContext `json:"context"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listing))
+	require.Len(t, listing.Context, 2)
+	assert.Equal(t, []string{"summarizer", "explainer"}, listing.Context[0].Steps)
+	assert.Equal(t, "lessons", listing.Context[0].Index)
+
+	path := "/api/sessions/" + session.ID + "/context-feedback"
+	w = serveWithKey(router, http.MethodPost, path, "", `{"doc_id": "d1", "rating": "helpful"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"feedback":"helpful"`)
+	assert.Greater(t, o.contextFeedback.DocBoost("lessons", "d1"), 1.0)
+
+	// Repeating a rating does not count twice, and seeded documents are not tallied
+	serveWithKey(router, http.MethodPost, path, "", `{"doc_id": "d1", "rating": "helpful"}`)
+	assert.Equal(t, docFeedback{Helpful: 1}, *o.contextFeedback.docs["lessons"]["d1"])
+	assert.Equal(t, http.StatusOK, serveWithKey(router, http.MethodPost, path, "", `{"doc_id": "saved#Lesson", "rating": "unhelpful"}`).Code)
+	assert.Len(t, o.contextFeedback.docs, 1)
+
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(router, http.MethodPost, path, "", `{"doc_id": "d1", "rating": "meh"}`).Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(router, http.MethodPost, path, "", `{"doc_id": "d9", "rating": "helpful"}`).Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(router, http.MethodPost, "/api/sessions/missing/context-feedback", "", `{"doc_id": "d1", "rating": "helpful"}`).Code)
+}
+
+// TestContextFeedbackReranksSearch tests that accumulated feedback reorders later hybrid searches
+func TestContextFeedbackReranksSearch(t *testing.T) {
+	searcher := &stubSearcher{hits: []retrieval.Hit{
+		{Doc: elastic.Doc{ID: "d1", Topic: "Caching", Section: "Basics", Text: "A cache keeps hot data close."}, BM25Score: 0.8, VectorScore: 0.8},
+		{Doc: elastic.Doc{ID: "d2", Topic: "Eviction", Section: "Policies", Text: "LRU evicts the least recently used entry."}, BM25Score: 0.7, VectorScore: 0.7},
+	}}
+	retriever := elastic.NewRetrieverWithBackend(searcher, &stubEmbedder{})
+	store := newContextFeedbackStore()
+	retriever.SetBooster(store)
+
+	hits, err := retriever.HybridSearch(context.Background(), "lessons", "cache", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "d1", hits[0].Doc.ID)
+
+	for i := 0; i < 3; i++ {
+		store.record("lessons", "d1", "", contextUnhelpful)
+		store.record("lessons", "d2", "", contextHelpful)
+	}
+	hits, err = retriever.HybridSearch(context.Background(), "lessons", "cache", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "d2", hits[0].Doc.ID)
+
+	hits, err = retriever.HybridSearch(context.Background(), "lessons-acme", "cache", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "d1", hits[0].Doc.ID, "feedback on one index leaves others unchanged")
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// minhashPermutations is the number of hash functions used for MinHash signatures
+	minhashPermutations = 64
+	// shingleSize is the number of words per shingle when comparing snippets without embeddings
+	shingleSize = 3
+)
+
+// PassageReranker scores passages by relevance to a query (e.g. llm.GeminiClient)
+type PassageReranker interface {
+	RerankPassages(ctx context.Context, query string, passages []string) ([]float64, error)
+}
+
+// prepareContext deduplicates, reranks, scrubs and trims retrieved documents before they are formatted into prompts.
+// Instruction-like passages are removed before trimming, so a cut cannot leave part of one behind.
+func (p *Pipeline) prepareContext(ctx context.Context, sessionID, query string, docs []ContextDoc) []ContextDoc {
+	if len(docs) == 0 {
+		return docs
+	}
+
+	prepared := dedupeContextDocs(docs, p.config.ContextDedupThreshold)
+
+	if p.reranker != nil && len(prepared) > 1 {
+		reranked, err := rerankContextDocs(ctx, p.reranker, query, prepared)
+		if err != nil {
+			p.logger.WithFields(logrus.Fields{
+				"session_id": sessionID,
+				"error":      err,
+			}).Warn("Context rerank failed, keeping retrieval order")
+		} else {
+			prepared = reranked
+		}
+	}
+
+	redactions := 0
+	for i := range prepared {
+		snippet, removed := llm.SanitizeUntrustedText(prepared[i].Snippet)
+		redactions += removed
+		prepared[i].Snippet = trimSnippet(snippet, p.config.ContextSnippetMaxChars)
+	}
+	if redactions > 0 {
+		p.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"redactions": redactions,
+		}).Warn("Removed instruction-like text from retrieved context")
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"retrieved":  len(docs),
+		"kept":       len(prepared),
+		"reranked":   p.reranker != nil,
+	}).Info("Prepared context documents")
+
+	return prepared
+}
+
+// dedupeContextDocs drops documents that are near-duplicates of a higher-scoring document.
+// Embeddings are compared by cosine similarity when both documents have them; otherwise
+// snippets are compared by MinHash-estimated Jaccard similarity of word shingles.
+// A threshold <= 0 disables deduplication.
+func dedupeContextDocs(docs []ContextDoc, threshold float64) []ContextDoc {
+	sorted := make([]ContextDoc, len(docs))
+	copy(sorted, docs)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score > sorted[j].Score })
+
+	if threshold <= 0 {
+		return sorted
+	}
+
+	kept := make([]ContextDoc, 0, len(sorted))
+	signatures := make([][]uint64, 0, len(sorted))
+	for _, doc := range sorted {
+		signature := minhashSignature(doc.Snippet)
+
+		duplicate := false
+		for i, existing := range kept {
+			if contextSimilarity(doc, existing, signature, signatures[i]) >= threshold {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		kept = append(kept, doc)
+		signatures = append(signatures, signature)
+	}
+	return kept
+}
+
+// contextSimilarity returns the similarity of two documents in [0, 1]
+func contextSimilarity(a, b ContextDoc, sigA, sigB []uint64) float64 {
+	if len(a.Doc.Embedding) > 0 && len(a.Doc.Embedding) == len(b.Doc.Embedding) {
+		return cosineSimilarity(a.Doc.Embedding, b.Doc.Embedding)
+	}
+	return minhashSimilarity(sigA, sigB)
+}
+
+// cosineSimilarity returns the cosine similarity of two vectors
+func cosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// minhashSignature computes a MinHash signature over the word shingles of a text
+func minhashSignature(text string) []uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return nil
+	}
+
+	shingles := make([]string, 0, len(words))
+	if len(words) < shingleSize {
+		shingles = append(shingles, strings.Join(words, " "))
+	} else {
+		for i := 0; i+shingleSize <= len(words); i++ {
+			shingles = append(shingles, strings.Join(words[i:i+shingleSize], " "))
+		}
+	}
+
+	signature := make([]uint64, minhashPermutations)
+	for i := range signature {
+		signature[i] = math.MaxUint64
+	}
+	for _, shingle := range shingles {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		base := h.Sum64()
+		for i := range signature {
+			// Derive independent hash functions from one base hash
+			v := (base ^ uint64(i+1)*0x9e3779b97f4a7c15) * 0xbf58476d1ce4e5b9
+			v ^= v >> 31
+			if v < signature[i] {
+				signature[i] = v
+			}
+		}
+	}
+	return signature
+}
+
+// minhashSimilarity estimates the Jaccard similarity of two MinHash signatures
+func minhashSimilarity(a, b []uint64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	matches := 0
+	for i := range a {
+		if a[i] == b[i] {
+			matches++
+		}
+	}
+	return float64(matches) / float64(len(a))
+}
+
+// rerankContextDocs reorders documents by reranker score, highest first.
+// The reranker score replaces the retrieval score on the returned documents.
+func rerankContextDocs(ctx context.Context, reranker PassageReranker, query string, docs []ContextDoc) ([]ContextDoc, error) {
+	passages := make([]string, len(docs))
+	for i, doc := range docs {
+		passages[i] = doc.Snippet
+	}
+
+	scores, err := reranker.RerankPassages(ctx, query, passages)
+	if err != nil {
+		return nil, err
+	}
+
+	reranked := make([]ContextDoc, len(docs))
+	copy(reranked, docs)
+	for i := range reranked {
+		reranked[i].Score = scores[i]
+	}
+	sort.SliceStable(reranked, func(i, j int) bool { return reranked[i].Score > reranked[j].Score })
+	return reranked, nil
+}
+
+// trimSnippet shortens a snippet to at most maxChars, cutting at a sentence or word boundary.
+// A maxChars <= 0 leaves the snippet unchanged.
+func trimSnippet(snippet string, maxChars int) string {
+	snippet = strings.TrimSpace(snippet)
+	if maxChars <= 0 || len(snippet) <= maxChars {
+		return snippet
+	}
+
+	cut := snippet[:maxChars]
+	// Avoid splitting a multi-byte character
+	for len(cut) > 0 && !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+
+	if i := strings.LastIndexAny(cut, ".!?"); i >= maxChars/2 {
+		return cut[:i+1]
+	}
+	if i := strings.LastIndexAny(cut, " \n\t"); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
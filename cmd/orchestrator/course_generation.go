@@ -0,0 +1,485 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxSyllabusChapters caps the topics of one generated course
+	maxSyllabusChapters = 50
+	// defaultChapterInterval is the time between the scheduled starts of a course's chapters
+	defaultChapterInterval = 5 * time.Minute
+	// maxChapterInterval caps the time between chapters
+	maxChapterInterval = 7 * 24 * time.Hour
+	// courseSchedulerInterval is how often due chapters are started
+	courseSchedulerInterval = 30 * time.Second
+)
+
+// Chapter statuses before a chapter's session exists; afterwards a chapter has its session's status
+const (
+	chapterScheduled       = "scheduled"
+	chapterWaitingForQuota = "waiting_for_quota"
+	chapterStarting        = "starting"
+)
+
+// Course generation statuses
+const (
+	courseGenerationScheduled  = "scheduled"
+	courseGenerationGenerating = "generating"
+	courseGenerationCompleted  = "completed"
+	courseGenerationPartial    = "partial" // Finished with failed chapters
+)
+
+// syllabusBulletPattern matches list markers like "-", "*" or "3." at the start of a syllabus line
+var syllabusBulletPattern = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+`)
+
+// SyllabusEntry is one topic of a syllabus
+type SyllabusEntry struct {
+	Topic      string `json:"topic"`
+	Difficulty string `json:"difficulty,omitempty"`
+}
+
+// GenerateCourseRequest is the body of POST /api/courses/generate. The syllabus is given either
+// as entries or as the text of a syllabus file with one topic per line, optionally followed by
+// "| difficulty".
+type GenerateCourseRequest struct {
+	Title           string          `json:"title"`
+	CourseID        string          `json:"course_id,omitempty"` // Derived from the title when empty
+	ExplanationType string          `json:"explanation_type,omitempty"`
+	Syllabus        []SyllabusEntry `json:"syllabus,omitempty"`
+	SyllabusText    string          `json:"syllabus_text,omitempty"`
+	IntervalSeconds *int            `json:"interval_seconds,omitempty"` // Between chapter starts; default 300
+	StartAt         *time.Time      `json:"start_at,omitempty"`         // First chapter's earliest start; default now
+}
+
+// CourseChapter is one syllabus topic of a generated course
+type CourseChapter struct {
+	Number      int        `json:"number"`
+	Topic       string     `json:"topic"`
+	Difficulty  string     `json:"difficulty"`
+	Status      string     `json:"status"`
+	SessionID   string     `json:"session_id,omitempty"`
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+}
+
+// CourseGeneration is a batch of sessions generating a course from a syllabus, one chapter at a time
+type CourseGeneration struct {
+	CourseID        string           `json:"course_id"`
+	Title           string           `json:"title"`
+	UserID          string           `json:"user_id,omitempty"`
+	OrgID           string           `json:"org_id,omitempty"`
+	runKey          string           // Who the chapters' runs count against: "user:<id>", or "ip:<address>" for anonymous callers
+	ExplanationType string           `json:"explanation_type"`
+	IntervalSeconds int              `json:"interval_seconds"`
+	Status          string           `json:"status"`
+	Chapters        []*CourseChapter `json:"chapters"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// parseSyllabusText reads a syllabus file: one topic per line, list markers and blank or "#"
+// comment lines ignored, and an optional difficulty after the last "|"
+func parseSyllabusText(text string) []SyllabusEntry {
+	var entries []SyllabusEntry
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = syllabusBulletPattern.ReplaceAllString(line, "")
+		entry := SyllabusEntry{Topic: line}
+		if i := strings.LastIndex(line, "|"); i >= 0 {
+			entry.Topic = strings.TrimSpace(line[:i])
+			entry.Difficulty = strings.TrimSpace(line[i+1:])
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// courseRunKey identifies who a generated course's runs count against: the authenticated
+// user, or the client IP for anonymous callers
+func courseRunKey(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.UserID != "" {
+		return "user:" + principal.UserID
+	}
+	return "ip:" + clientIP(r)
+}
+
+// chapterStatusLocked returns a chapter's status, which is its session's once the session exists; o.mu must be held
+func (o *Orchestrator) chapterStatusLocked(chapter *CourseChapter) string {
+	if chapter.SessionID == "" {
+		return chapter.Status
+	}
+	if session, exists := o.sessions[chapter.SessionID]; exists {
+		return session.Status
+	}
+	return chapter.Status
+}
+
+// courseGenerationView returns a copy of a course generation with current chapter and course statuses
+func (o *Orchestrator) courseGenerationView(courseID string) (*CourseGeneration, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	generation, exists := o.courseGenerations[courseID]
+	if !exists {
+		return nil, false
+	}
+	view := *generation
+	view.Chapters = make([]*CourseChapter, len(generation.Chapters))
+	started, finished, failed := 0, 0, 0
+	for i, chapter := range generation.Chapters {
+		copied := *chapter
+		copied.Status = o.chapterStatusLocked(chapter)
+		view.Chapters[i] = &copied
+		if copied.SessionID != "" {
+			started++
+		}
+		switch copied.Status {
+		case "completed":
+			finished++
+		case "failed", "cancelled":
+			finished++
+			failed++
+		}
+	}
+	switch {
+	case finished == len(view.Chapters) && failed > 0:
+		view.Status = courseGenerationPartial
+	case finished == len(view.Chapters):
+		view.Status = courseGenerationCompleted
+	case started > 0:
+		view.Status = courseGenerationGenerating
+	default:
+		view.Status = courseGenerationScheduled
+	}
+	return &view, true
+}
+
+// courseQuotaExhausted reports whether a user has used up their monthly session or cost allowance
+func (o *Orchestrator) courseQuotaExhausted(userID string, now time.Time) bool {
+	if userID == "" || (o.usageQuota.Sessions <= 0 && o.usageQuota.CostUSD <= 0) {
+		return false
+	}
+	remaining := o.userUsage(userID, now).QuotaRemaining
+	return (remaining.Sessions != nil && *remaining.Sessions <= 0) || (remaining.CostUSD != nil && *remaining.CostUSD <= 0)
+}
+
+// dueChapterLocked returns the chapter of a generation to start at now, if any. Chapters start in
+// order, each once its scheduled time has come and the previous chapter has finished. o.mu must be held.
+func (o *Orchestrator) dueChapterLocked(generation *CourseGeneration, now time.Time) *CourseChapter {
+	for i, chapter := range generation.Chapters {
+		if chapter.SessionID != "" || chapter.Status == chapterStarting {
+			continue
+		}
+		if now.Before(chapter.ScheduledAt) {
+			return nil
+		}
+		if i > 0 {
+			previous := generation.Chapters[i-1]
+			switch o.chapterStatusLocked(previous) {
+			case "completed", "failed", "cancelled":
+			default:
+				return nil
+			}
+		}
+		return chapter
+	}
+	return nil
+}
+
+// advanceCourseGenerations starts the chapters that are due at now. Chapters of users who have
+// used up their monthly allowance wait until it renews.
+func (o *Orchestrator) advanceCourseGenerations(now time.Time) int {
+	type dueChapter struct {
+		generation *CourseGeneration
+		chapter    *CourseChapter
+	}
+
+	o.mu.Lock()
+	var due []dueChapter
+	for _, generation := range o.courseGenerations {
+		if chapter := o.dueChapterLocked(generation, now); chapter != nil {
+			due = append(due, dueChapter{generation: generation, chapter: chapter})
+		}
+	}
+	o.mu.Unlock()
+
+	started := 0
+	for _, next := range due {
+		if o.courseQuotaExhausted(next.generation.UserID, now) {
+			o.mu.Lock()
+			next.chapter.Status = chapterWaitingForQuota
+			o.mu.Unlock()
+			continue
+		}
+
+		// Claim the chapter so a concurrent pass does not start it twice
+		o.mu.Lock()
+		if next.chapter.SessionID != "" || next.chapter.Status == chapterStarting {
+			o.mu.Unlock()
+			continue
+		}
+		next.chapter.Status = chapterStarting
+		o.mu.Unlock()
+
+		if o.startCourseChapter(next.generation, next.chapter, now) {
+			started++
+		}
+	}
+	return started
+}
+
+// startCourseChapter creates a chapter's session and queues its run. A user at their concurrent
+// run limit gets the chapter retried on the next pass.
+func (o *Orchestrator) startCourseChapter(generation *CourseGeneration, chapter *CourseChapter, now time.Time) bool {
+	user := generation.runKey
+	if user == "" {
+		user = "course:" + generation.CourseID
+	}
+	if o.userRuns != nil && !o.userRuns.queue && o.userRuns.limit > 0 && o.userRuns.active(user) >= o.userRuns.limit {
+		o.mu.Lock()
+		chapter.Status = chapterScheduled
+		o.mu.Unlock()
+		return false
+	}
+
+	session := o.CreateSession(chapter.Topic)
+
+	o.mu.Lock()
+	session.Metadata["explanation_type"] = generation.ExplanationType
+	session.Metadata["difficulty"] = chapter.Difficulty
+	session.Metadata["course_chapter"] = chapter.Number
+	if generation.UserID != "" {
+		session.Metadata["user_id"] = generation.UserID
+	}
+	if generation.OrgID != "" {
+		session.Metadata["org_id"] = generation.OrgID
+	}
+	session.Status = "queued"
+	o.mu.Unlock()
+
+	if policy, hasPolicy := o.orgPolicy(generation.OrgID); hasPolicy {
+		applySessionPolicy(session, policy)
+	}
+	o.setSessionGrouping(session, nil, generation.CourseID)
+	o.indexSession(session)
+
+	queue := o.asyncRunQueue()
+	_, err := o.startUserRun(user, session.ID, func(run func()) { queue.enqueue(session.ID, run) })
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err != nil {
+		// The user reached their run limit since the check; drop the session and retry the chapter
+		delete(o.sessions, session.ID)
+		if o.metaIndex != nil {
+			o.metaIndex.remove(session.ID)
+		}
+		chapter.Status = chapterScheduled
+		return false
+	}
+	chapter.SessionID = session.ID
+	startedAt := now
+	chapter.StartedAt = &startedAt
+	generation.UpdatedAt = now
+
+	o.logger.WithFields(logrus.Fields{
+		"course_id":  generation.CourseID,
+		"chapter":    chapter.Number,
+		"session_id": session.ID,
+	}).Info("Course chapter started")
+	return true
+}
+
+// startCourseScheduler starts due course chapters periodically until the context is cancelled
+func (o *Orchestrator) startCourseScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			o.advanceCourseGenerations(now)
+		}
+	}
+}
+
+// generateCourseHandler handles POST /api/courses/generate. It schedules one session per syllabus
+// topic and responds 202 with the course, whose progress is at GET /api/courses/{courseID}.
+func (o *Orchestrator) generateCourseHandler(w http.ResponseWriter, r *http.Request) {
+	var req GenerateCourseRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		http.Error(w, "Title is required", http.StatusBadRequest)
+		return
+	}
+	syllabus := req.Syllabus
+	if len(syllabus) == 0 {
+		syllabus = parseSyllabusText(req.SyllabusText)
+	}
+	if len(syllabus) == 0 {
+		http.Error(w, "Syllabus must list at least one topic", http.StatusBadRequest)
+		return
+	}
+	if len(syllabus) > maxSyllabusChapters {
+		http.Error(w, fmt.Sprintf("Syllabus may list at most %d topics", maxSyllabusChapters), http.StatusBadRequest)
+		return
+	}
+
+	courseID := req.CourseID
+	if courseID == "" {
+		courseID = req.Title
+	}
+	_, courseID, err := normalizeGrouping(nil, courseID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	interval := defaultChapterInterval
+	if req.IntervalSeconds != nil {
+		interval = time.Duration(*req.IntervalSeconds) * time.Second
+		if interval < 0 || interval > maxChapterInterval {
+			http.Error(w, fmt.Sprintf("interval_seconds must be between 0 and %d", int(maxChapterInterval.Seconds())), http.StatusBadRequest)
+			return
+		}
+	}
+	now := time.Now()
+	start := now
+	if req.StartAt != nil && req.StartAt.After(now) {
+		start = *req.StartAt
+	}
+
+	// Every chapter is held to the organization's policy before anything is scheduled
+	orgID := sessionOrg(r)
+	policy, hasPolicy := o.orgPolicy(orgID)
+	generation := &CourseGeneration{
+		CourseID:        courseID,
+		Title:           req.Title,
+		UserID:          sessionOwner(r),
+		runKey:          courseRunKey(r),
+		OrgID:           orgID,
+		IntervalSeconds: int(interval.Seconds()),
+		Status:          courseGenerationScheduled,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	for i, entry := range syllabus {
+		topic := strings.TrimSpace(entry.Topic)
+		if topic == "" {
+			http.Error(w, fmt.Sprintf("Syllabus entry %d has no topic", i+1), http.StatusBadRequest)
+			return
+		}
+		explanationType, requestedDifficulty := req.ExplanationType, entry.Difficulty
+		if hasPolicy {
+			explanationType, requestedDifficulty, err = policy.apply(sessionPolicyRequest{
+				Topic:           topic,
+				ExplanationType: req.ExplanationType,
+				Difficulty:      entry.Difficulty,
+			})
+			if violation, ok := err.(*orgPolicyViolation); ok {
+				o.writeOrgPolicyViolation(w, orgID, violation)
+				return
+			}
+		}
+		if explanationType == "" {
+			explanationType = "standard"
+		}
+		difficulty, err := normalizeDifficulty(requestedDifficulty, explanationType)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Syllabus entry %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+		generation.ExplanationType = explanationType
+		generation.Chapters = append(generation.Chapters, &CourseChapter{
+			Number:      i + 1,
+			Topic:       topic,
+			Difficulty:  difficulty,
+			Status:      chapterScheduled,
+			ScheduledAt: start.Add(time.Duration(i) * interval),
+		})
+	}
+	if o.pipeline != nil && !o.pipeline.supportsExplanationType(generation.ExplanationType) {
+		http.Error(w, fmt.Sprintf("Explanation type %q is not supported by the explainer", generation.ExplanationType), http.StatusBadRequest)
+		return
+	}
+
+	// Courses are generated into an unused course ID so progress and exports cover only its chapters
+	existing := o.courseProgress(courseID, "", false)
+	o.mu.Lock()
+	_, generating := o.courseGenerations[courseID]
+	if generating || existing.TotalSessions > 0 || existing.SavedLessons > 0 {
+		o.mu.Unlock()
+		http.Error(w, fmt.Sprintf("Course %q already exists", courseID), http.StatusConflict)
+		return
+	}
+	if o.courseGenerations == nil {
+		o.courseGenerations = make(map[string]*CourseGeneration)
+	}
+	o.courseGenerations[courseID] = generation
+	o.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"course_id": courseID,
+		"chapters":  len(generation.Chapters),
+		"interval":  interval,
+		"user_id":   generation.UserID,
+	}).Info("Course generation scheduled")
+
+	// The first chapter starts right away when it is due
+	o.advanceCourseGenerations(now)
+
+	view, _ := o.courseGenerationView(courseID)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/courses/"+courseID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(view)
+}
+
+// sessionChapter returns a session's chapter number in a generated course, or 0
+func sessionChapter(session *Session) int {
+	switch number := session.Metadata["course_chapter"].(type) {
+	case int:
+		return number
+	case float64:
+		return int(number)
+	}
+	return 0
+}
+
+// courseContentsMarkdown renders a generated course's title and chapter list for its combined export
+func courseContentsMarkdown(generation *CourseGeneration) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# %s\n\n", generation.Title))
+	for _, chapter := range generation.Chapters {
+		b.WriteString(fmt.Sprintf("%d. %s (%s)", chapter.Number, chapter.Topic, chapter.Difficulty))
+		switch chapter.Status {
+		case "completed":
+		case "failed", "cancelled":
+			b.WriteString(" — failed")
+		default:
+			b.WriteString(" — not generated yet")
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
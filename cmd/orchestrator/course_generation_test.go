@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCourseGenerationTestOrchestrator returns an orchestrator whose run queue is occupied, so
+// chapter sessions stay queued until the test finishes them, and a router serving the course API
+func newCourseGenerationTestOrchestrator() (*Orchestrator, http.Handler) {
+	o := &Orchestrator{
+		sessions:          make(map[string]*Session),
+		savedLessons:      make(map[string]*SavedLesson),
+		orgPolicies:       make(map[string]*OrgPolicy),
+		courseGenerations: make(map[string]*CourseGeneration),
+		logger:            logrus.New(),
+		metaIndex:         newMetadataIndex(),
+		runQueue:          newRunQueue(1),
+	}
+	blocker := make(chan struct{})
+	running := make(chan struct{})
+	o.runQueue.enqueue("other", func() { close(running); <-blocker }) // Never released
+	<-running
+
+	r := chi.NewRouter()
+	r.Post("/api/courses/generate", o.generateCourseHandler)
+	r.Get("/api/courses/{courseID}", o.getCourseHandler)
+	r.Get("/api/courses/{courseID}/export", o.exportCourseHandler)
+	return o, r
+}
+
+// finishChapter completes or fails a chapter's session
+func finishChapter(o *Orchestrator, courseID string, number int, status string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	session := o.sessions[o.courseGenerations[courseID].Chapters[number-1].SessionID]
+	session.Status = status
+	if status == "completed" {
+		session.Result = &SessionResult{Lesson: "Lesson about " + session.Topic}
+	}
+}
+
+// TestParseSyllabusText tests reading topics and difficulties from a syllabus file
+func TestParseSyllabusText(t *testing.T) {
+	entries := parseSyllabusText("# Week 1\n1. Variables | beginner\n- Pointers and references\n\n* Memory models | Advanced\n")
+	assert.Equal(t, []SyllabusEntry{
+		{Topic: "Variables", Difficulty: "beginner"},
+		{Topic: "Pointers and references"},
+		{Topic: "Memory models", Difficulty: "Advanced"},
+	}, entries)
+}
+
+// TestGenerateCourse tests that chapters start in order on schedule and assemble into one course
+func TestGenerateCourse(t *testing.T) {
+	o, router := newCourseGenerationTestOrchestrator()
+
+	body := `{"title": "Systems 101", "interval_seconds": 60,
+		"syllabus_text": "1. Processes | beginner\n2. Threads\n3. Scheduling | advanced"}`
+	w := serveWithKey(router, http.MethodPost, "/api/courses/generate", "", body)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.Equal(t, "/api/courses/systems-101", w.Header().Get("Location"))
+
+	var generation CourseGeneration
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &generation))
+	require.Len(t, generation.Chapters, 3)
+	assert.Equal(t, courseGenerationGenerating, generation.Status)
+	assert.Equal(t, "queued", generation.Chapters[0].Status)
+	assert.Equal(t, chapterScheduled, generation.Chapters[1].Status)
+	assert.Equal(t, DifficultyIntermediate, generation.Chapters[1].Difficulty)
+	assert.Equal(t, DifficultyAdvanced, generation.Chapters[2].Difficulty)
+
+	first, _ := o.GetSession(generation.Chapters[0].SessionID)
+	assert.Equal(t, "systems-101", first.CourseID)
+	assert.Equal(t, 1, sessionChapter(first))
+	assert.Equal(t, DifficultyBeginner, first.Metadata["difficulty"])
+
+	// The next chapter waits for both its scheduled time and the previous chapter
+	start := generation.Chapters[0].ScheduledAt
+	assert.Equal(t, 0, o.advanceCourseGenerations(start.Add(2*time.Minute)))
+	finishChapter(o, "systems-101", 1, "completed")
+	assert.Equal(t, 0, o.advanceCourseGenerations(start.Add(30*time.Second)))
+	assert.Equal(t, 1, o.advanceCourseGenerations(start.Add(61*time.Second)))
+	finishChapter(o, "systems-101", 2, "failed")
+	assert.Equal(t, 1, o.advanceCourseGenerations(start.Add(2*time.Minute)))
+	finishChapter(o, "systems-101", 3, "completed")
+
+	w = serve(router, http.MethodGet, "/api/courses/systems-101")
+	require.Equal(t, http.StatusOK, w.Code)
+	var progress CourseProgress
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &progress))
+	assert.Equal(t, 3, progress.TotalSessions)
+	assert.Equal(t, 2, progress.CompletedSessions)
+	require.NotNil(t, progress.Generation)
+	assert.Equal(t, courseGenerationPartial, progress.Generation.Status)
+
+	w = serve(router, http.MethodGet, "/api/courses/systems-101/export")
+	require.Equal(t, http.StatusOK, w.Code)
+	export := w.Body.String()
+	assert.Contains(t, export, "# Systems 101\n\n1. Processes (beginner)\n2. Threads (intermediate) — failed\n3. Scheduling (advanced)")
+	assert.Less(t, strings.Index(export, "# Processes"), strings.Index(export, "# Scheduling"))
+
+	// The course ID is taken now
+	assert.Equal(t, http.StatusConflict, serveWithKey(router, http.MethodPost, "/api/courses/generate", "", body).Code)
+}
+
+// TestGenerateCourseValidation tests rejecting malformed syllabi
+func TestGenerateCourseValidation(t *testing.T) {
+	_, router := newCourseGenerationTestOrchestrator()
+
+	for _, body := range []string{
+		`{"syllabus": [{"topic": "Processes"}]}`,
+		`{"title": "Empty"}`,
+		`{"title": "Bad difficulty", "syllabus": [{"topic": "Processes", "difficulty": "expert"}]}`,
+		`{"title": "Blank topic", "syllabus": [{"topic": " "}]}`,
+		`{"title": "Slow", "interval_seconds": -1, "syllabus": [{"topic": "Processes"}]}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, serveWithKey(router, http.MethodPost, "/api/courses/generate", "", body).Code, body)
+	}
+}
+
+// TestCourseChaptersWaitForQuota tests that chapters of a user out of monthly sessions are not started
+func TestCourseChaptersWaitForQuota(t *testing.T) {
+	o, router := newCourseGenerationTestOrchestrator()
+	o.usageQuota = usageQuota{Sessions: 1}
+	o.sessions["earlier"] = &Session{ID: "earlier", Status: "completed", CreatedAt: time.Now(), Metadata: map[string]interface{}{"user_id": "u1"}}
+
+	user := withPrincipal(router, &auth.Principal{UserID: "u1", Method: auth.MethodJWT})
+	w := serveWithKey(user, http.MethodPost, "/api/courses/generate", "", `{"title": "Quota", "syllabus": [{"topic": "Processes"}]}`)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var generation CourseGeneration
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &generation))
+	assert.Equal(t, chapterWaitingForQuota, generation.Chapters[0].Status)
+	assert.Empty(t, generation.Chapters[0].SessionID)
+
+	// A new month's allowance lets it start
+	assert.Equal(t, 1, o.advanceCourseGenerations(time.Now().AddDate(0, 1, 0)))
+}
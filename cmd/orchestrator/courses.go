@@ -0,0 +1,396 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxTagsPerItem caps how many tags a session or saved lesson may carry
+	maxTagsPerItem = 20
+	// maxTagLength caps the length of a tag or course ID
+	maxTagLength = 64
+)
+
+// groupingLabelPattern restricts tags and course IDs to URL-safe labels like "cs101" or "week-3"
+var groupingLabelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._:-]*$`)
+
+// normalizeLabel lowercases a tag or course ID and turns spaces into dashes
+func normalizeLabel(label string) string {
+	return strings.Join(strings.Fields(strings.ToLower(label)), "-")
+}
+
+// normalizeGrouping validates tags and a course ID, returning tags sorted and deduplicated.
+// Nil tags stay nil so callers can tell "not given" from "cleared".
+func normalizeGrouping(tags []string, courseID string) ([]string, string, error) {
+	courseID = normalizeLabel(courseID)
+	if courseID != "" && (len(courseID) > maxTagLength || !groupingLabelPattern.MatchString(courseID)) {
+		return nil, "", fmt.Errorf("invalid course_id %q", courseID)
+	}
+	if tags == nil {
+		return nil, courseID, nil
+	}
+
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = normalizeLabel(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxTagLength || !groupingLabelPattern.MatchString(tag) {
+			return nil, "", fmt.Errorf("invalid tag %q", tag)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxTagsPerItem {
+		return nil, "", fmt.Errorf("at most %d tags are allowed", maxTagsPerItem)
+	}
+	sort.Strings(normalized)
+	return normalized, courseID, nil
+}
+
+// setSessionGrouping sets a session's tags and course; nil tags leave the tags unchanged
+func (o *Orchestrator) setSessionGrouping(session *Session, tags []string, courseID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if tags != nil {
+		session.Tags = tags
+	}
+	session.CourseID = courseID
+	session.UpdatedAt = time.Now()
+}
+
+// groupingFilter selects items by tag and course
+type groupingFilter struct {
+	tags     []string // Items must carry every tag
+	courseID string
+}
+
+// groupingFilterFromQuery reads repeated tag= parameters and course_id= from a request
+func groupingFilterFromQuery(r *http.Request) groupingFilter {
+	query := r.URL.Query()
+	filter := groupingFilter{courseID: normalizeLabel(query.Get("course_id"))}
+	for _, tag := range query["tag"] {
+		if tag = normalizeLabel(tag); tag != "" {
+			filter.tags = append(filter.tags, tag)
+		}
+	}
+	return filter
+}
+
+// matches reports whether an item with the given tags and course passes the filter
+func (f groupingFilter) matches(tags []string, courseID string) bool {
+	if f.courseID != "" && courseID != f.courseID {
+		return false
+	}
+	for _, want := range f.tags {
+		found := false
+		for _, tag := range tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// filterSummaries returns the session summaries passing the filter
+func (f groupingFilter) filterSummaries(sessions []SessionSummary) []SessionSummary {
+	if f.courseID == "" && len(f.tags) == 0 {
+		return sessions
+	}
+	filtered := make([]SessionSummary, 0, len(sessions))
+	for _, session := range sessions {
+		if f.matches(session.Tags, session.CourseID) {
+			filtered = append(filtered, session)
+		}
+	}
+	return filtered
+}
+
+// GroupingRequest represents a request to retag or regroup a session or saved lesson
+type GroupingRequest struct {
+	Tags     []string `json:"tags"`
+	CourseID string   `json:"course_id"`
+}
+
+// decodeGroupingRequest decodes and validates a grouping request, writing an error response on failure
+func decodeGroupingRequest(w http.ResponseWriter, r *http.Request) ([]string, string, bool) {
+	var req GroupingRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return nil, "", false
+	}
+	tags, courseID, err := normalizeGrouping(req.Tags, req.CourseID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, "", false
+	}
+	if tags == nil {
+		// A PUT replaces the grouping, so omitted tags clear them
+		tags = []string{}
+	}
+	return tags, courseID, true
+}
+
+// putSessionGroupingHandler handles PUT /api/sessions/{id}/grouping
+func (o *Orchestrator) putSessionGroupingHandler(w http.ResponseWriter, r *http.Request) {
+	session, exists := o.GetSession(chi.URLParam(r, "id"))
+	if !exists {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	tags, courseID, ok := decodeGroupingRequest(w, r)
+	if !ok {
+		return
+	}
+	o.setSessionGrouping(session, tags, courseID)
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id": session.ID,
+		"tags":       tags,
+		"course_id":  courseID,
+	}).Info("Session grouping updated")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session_id": session.ID,
+		"tags":       tags,
+		"course_id":  courseID,
+	})
+}
+
+// putSavedLessonGroupingHandler handles PUT /api/saved/{userID}/{id}/grouping
+func (o *Orchestrator) putSavedLessonGroupingHandler(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	savedID := chi.URLParam(r, "id")
+
+	tags, courseID, ok := decodeGroupingRequest(w, r)
+	if !ok {
+		return
+	}
+
+	o.mu.Lock()
+	savedLesson, exists := o.savedLessons[savedID]
+	if exists && !savedLesson.isDeleted() && savedLesson.UserID == userID {
+		savedLesson.Tags = tags
+		savedLesson.CourseID = courseID
+		savedLesson.UpdatedAt = time.Now()
+	}
+	o.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !exists || savedLesson.isDeleted() || savedLesson.UserID != userID {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Saved lesson not found",
+			"message": "Saved lesson not found",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":        savedID,
+		"tags":      tags,
+		"course_id": courseID,
+	})
+}
+
+// CourseProgress aggregates the sessions and saved lessons in a course
+type CourseProgress struct {
+	CourseID          string            `json:"course_id"`
+	TotalSessions     int               `json:"total_sessions"`
+	CompletedSessions int               `json:"completed_sessions"`
+	FailedSessions    int               `json:"failed_sessions"`
+	ActiveSessions    int               `json:"active_sessions"` // Queued or running
+	PercentComplete   float64           `json:"percent_complete"`
+	SavedLessons      int               `json:"saved_lessons"`
+	Tags              map[string]int    `json:"tags,omitempty"` // Tag -> number of sessions carrying it
+	Sessions          []SessionSummary  `json:"sessions,omitempty"`
+	LastActivity      *time.Time        `json:"last_activity,omitempty"`
+	Generation        *CourseGeneration `json:"generation,omitempty"` // Syllabus chapters of a generated course, in order
+}
+
+// courseProgress aggregates a course's progress. A non-empty userID limits saved lessons to that user.
+func (o *Orchestrator) courseProgress(courseID, userID string, includeSessions bool) CourseProgress {
+	progress := CourseProgress{CourseID: courseID, Tags: make(map[string]int)}
+
+	sessions := groupingFilter{courseID: courseID}.filterSummaries(o.QuerySessions(nil, ""))
+	for _, session := range sessions {
+		progress.TotalSessions++
+		switch session.Status {
+		case "completed":
+			progress.CompletedSessions++
+		case "failed":
+			progress.FailedSessions++
+		case "queued", "running":
+			progress.ActiveSessions++
+		}
+		for _, tag := range session.Tags {
+			progress.Tags[tag]++
+		}
+		if progress.LastActivity == nil || session.UpdatedAt.After(*progress.LastActivity) {
+			updatedAt := session.UpdatedAt
+			progress.LastActivity = &updatedAt
+		}
+	}
+	if progress.TotalSessions > 0 {
+		progress.PercentComplete = float64(progress.CompletedSessions) * 100 / float64(progress.TotalSessions)
+	}
+	if includeSessions {
+		progress.Sessions = sessions
+		progress.Generation, _ = o.courseGenerationView(courseID)
+	}
+
+	o.mu.RLock()
+	for _, lesson := range o.savedLessons {
+		if lesson.CourseID == courseID && !lesson.isDeleted() && (userID == "" || lesson.UserID == userID) {
+			progress.SavedLessons++
+		}
+	}
+	o.mu.RUnlock()
+
+	return progress
+}
+
+// listCoursesHandler handles GET /api/courses
+func (o *Orchestrator) listCoursesHandler(w http.ResponseWriter, r *http.Request) {
+	courseIDs := make(map[string]bool)
+	o.mu.RLock()
+	for _, session := range o.sessions {
+		if session.CourseID != "" {
+			courseIDs[session.CourseID] = true
+		}
+	}
+	for _, lesson := range o.savedLessons {
+		if lesson.CourseID != "" && !lesson.isDeleted() {
+			courseIDs[lesson.CourseID] = true
+		}
+	}
+	o.mu.RUnlock()
+
+	ids := make([]string, 0, len(courseIDs))
+	for id := range courseIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	courses := make([]CourseProgress, 0, len(ids))
+	for _, id := range ids {
+		courses = append(courses, o.courseProgress(id, r.URL.Query().Get("user_id"), false))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"courses": courses,
+		"count":   len(courses),
+	})
+}
+
+// getCourseHandler handles GET /api/courses/{courseID}?user_id=
+func (o *Orchestrator) getCourseHandler(w http.ResponseWriter, r *http.Request) {
+	courseID := normalizeLabel(chi.URLParam(r, "courseID"))
+	progress := o.courseProgress(courseID, r.URL.Query().Get("user_id"), true)
+	if progress.TotalSessions == 0 && progress.SavedLessons == 0 && progress.Generation == nil {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
+// exportCourseHandler handles GET /api/courses/{courseID}/export?format=markdown|json&tag=
+// It bundles the course's completed lessons, oldest first, into one document.
+func (o *Orchestrator) exportCourseHandler(w http.ResponseWriter, r *http.Request) {
+	courseID := normalizeLabel(chi.URLParam(r, "courseID"))
+	filter := groupingFilterFromQuery(r)
+	filter.courseID = courseID
+
+	o.mu.RLock()
+	sessions := make([]*Session, 0)
+	for _, session := range o.sessions {
+		if session.Status == "completed" && session.Result != nil && filter.matches(session.Tags, session.CourseID) {
+			sessions = append(sessions, session)
+		}
+	}
+	o.mu.RUnlock()
+
+	if len(sessions) == 0 {
+		http.Error(w, "No completed lessons in course", http.StatusNotFound)
+		return
+	}
+	// Generated courses are exported in syllabus order, other courses oldest first
+	sort.Slice(sessions, func(i, j int) bool {
+		if a, b := sessionChapter(sessions[i]), sessionChapter(sessions[j]); a != b && a > 0 && b > 0 {
+			return a < b
+		}
+		if sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].ID < sessions[j].ID
+		}
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	generation, generated := o.courseGenerationView(courseID)
+
+	filename := llm.Slugify(courseID)
+	if filename == "" {
+		filename = "course"
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "markdown", "md":
+		parts := make([]string, 0, len(sessions)+1)
+		if generated {
+			parts = append(parts, courseContentsMarkdown(generation))
+		}
+		for _, session := range sessions {
+			parts = append(parts, strings.TrimSpace(renderLessonMarkdown(session)))
+		}
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".md"))
+		w.Write([]byte(strings.Join(parts, "\n\n---\n\n") + "\n"))
+	case "json":
+		lessons := make([]map[string]interface{}, 0, len(sessions))
+		for _, session := range sessions {
+			lessons = append(lessons, map[string]interface{}{
+				"session_id": session.ID,
+				"topic":      session.Topic,
+				"chapter":    sessionChapter(session),
+				"tags":       session.Tags,
+				"result":     session.Result,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".json"))
+		export := map[string]interface{}{
+			"course_id": courseID,
+			"lessons":   lessons,
+			"count":     len(lessons),
+		}
+		if generated {
+			export["title"] = generation.Title
+			export["chapters"] = generation.Chapters
+		}
+		json.NewEncoder(w).Encode(export)
+	default:
+		http.Error(w, fmt.Sprintf("Unsupported export format: %s", format), http.StatusBadRequest)
+	}
+}
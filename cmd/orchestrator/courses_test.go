@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/flags"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCourseTestOrchestrator creates an orchestrator with two CS101 sessions and one unrelated session
+func newCourseTestOrchestrator() (*Orchestrator, http.Handler) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		flagService:  flags.NewService(nil),
+		metaIndex:    newMetadataIndex(),
+	}
+
+	base := time.Now().Add(-time.Hour)
+	add := func(id, topic, status, courseID string, tags []string, offset time.Duration) {
+		session := &Session{
+			ID:        id,
+			Topic:     topic,
+			Status:    status,
+			CreatedAt: base.Add(offset),
+			UpdatedAt: base.Add(offset),
+			Metadata:  make(map[string]interface{}),
+			Tags:      tags,
+			CourseID:  courseID,
+		}
+		if status == "completed" {
+			session.Result = &SessionResult{Lesson: "Lesson about " + topic}
+		}
+		o.sessions[id] = session
+	}
+	add("s1", "Recursion", "completed", "cs101", []string{"week-3"}, 0)
+	add("s2", "Big O", "running", "cs101", []string{"week-3", "review"}, time.Minute)
+	add("s3", "Photosynthesis", "completed", "bio200", nil, 2*time.Minute)
+
+	o.savedLessons["l1"] = &SavedLesson{ID: "l1", SessionID: "s1", UserID: "u1", Tags: []string{"week-3"}, CourseID: "cs101", CreatedAt: base}
+	return o, o.setupRoutes()
+}
+
+// TestNormalizeGrouping tests tag and course ID normalization
+func TestNormalizeGrouping(t *testing.T) {
+	tags, courseID, err := normalizeGrouping([]string{"Week 3", "review", "week-3", " "}, "CS101")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"review", "week-3"}, tags)
+	assert.Equal(t, "cs101", courseID)
+
+	tags, _, err = normalizeGrouping(nil, "")
+	require.NoError(t, err)
+	assert.Nil(t, tags)
+
+	_, _, err = normalizeGrouping([]string{"bad/tag"}, "")
+	assert.Error(t, err)
+	_, _, err = normalizeGrouping(nil, strings.Repeat("x", maxTagLength+1))
+	assert.Error(t, err)
+}
+
+// TestListSessionsByTagAndCourse tests filtering session listings by tag and course
+func TestListSessionsByTagAndCourse(t *testing.T) {
+	_, router := newCourseTestOrchestrator()
+	admin := withPrincipal(router, &auth.Principal{APIKeyID: "key-admin", Scopes: []auth.Scope{auth.ScopeAdmin}})
+
+	var listing struct {
+		Sessions []SessionSummary `json:"sessions"`
+		Total    int              `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(serve(admin, "GET", "/api/sessions?course_id=cs101").Body.Bytes(), &listing))
+	assert.Equal(t, 2, listing.Total)
+
+	require.NoError(t, json.Unmarshal(serve(admin, "GET", "/api/sessions?tag=week-3&tag=review").Body.Bytes(), &listing))
+	require.Equal(t, 1, listing.Total)
+	assert.Equal(t, "s2", listing.Sessions[0].ID)
+
+	var saved struct {
+		Count int `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(serve(router, "GET", "/api/saved/u1?course_id=bio200").Body.Bytes(), &saved))
+	assert.Equal(t, 0, saved.Count)
+	require.NoError(t, json.Unmarshal(serve(router, "GET", "/api/saved/u1?course_id=cs101").Body.Bytes(), &saved))
+	assert.Equal(t, 1, saved.Count)
+}
+
+// TestCourseProgress tests aggregating progress across a course's sessions
+func TestCourseProgress(t *testing.T) {
+	_, router := newCourseTestOrchestrator()
+
+	w := serve(router, "GET", "/api/courses/cs101")
+	require.Equal(t, http.StatusOK, w.Code)
+	var progress CourseProgress
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &progress))
+	assert.Equal(t, 2, progress.TotalSessions)
+	assert.Equal(t, 1, progress.CompletedSessions)
+	assert.Equal(t, 1, progress.ActiveSessions)
+	assert.Equal(t, 50.0, progress.PercentComplete)
+	assert.Equal(t, 1, progress.SavedLessons)
+	assert.Equal(t, 2, progress.Tags["week-3"])
+
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/courses/missing").Code)
+
+	var courses struct {
+		Count int `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(serve(router, "GET", "/api/courses").Body.Bytes(), &courses))
+	assert.Equal(t, 2, courses.Count)
+}
+
+// TestExportCourse tests bundling a course's completed lessons
+func TestExportCourse(t *testing.T) {
+	_, router := newCourseTestOrchestrator()
+
+	w := serve(router, "GET", "/api/courses/cs101/export")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "# Recursion")
+	assert.NotContains(t, w.Body.String(), "Big O", "running sessions are not exported")
+	assert.NotContains(t, w.Body.String(), "Photosynthesis")
+
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/courses/cs101/export?tag=review").Code)
+}
+
+// TestPutSessionGrouping tests regrouping a session
+func TestPutSessionGrouping(t *testing.T) {
+	o, router := newCourseTestOrchestrator()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/api/sessions/s3/grouping", strings.NewReader(`{"tags": ["Week 4"], "course_id": "CS101"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"week-4"}, o.sessions["s3"].Tags)
+	assert.Equal(t, "cs101", o.sessions["s3"].CourseID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/api/saved/u1/l1/grouping", strings.NewReader(`{"course_id": "cs102"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, o.savedLessons["l1"].Tags)
+	assert.Equal(t, "cs102", o.savedLessons["l1"].CourseID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/api/saved/u2/l1/grouping", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
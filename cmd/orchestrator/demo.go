@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// demoUserID owns the demo sessions, saved lessons and BrainPrint profile
+	demoUserID = "demo-user"
+	// defaultDemoDBPath is the SQLite database demo mode uses unless SQLITE_PATH is set
+	defaultDemoDBPath = "explainiq-demo.db"
+)
+
+// demoLesson is one seeded demo session; saved ones also appear in the demo user's library
+type demoLesson struct {
+	Topic           string
+	ExplanationType string
+	Persona         string
+	Saved           bool
+	Favorite        bool
+	Rating          float64 // BrainPrint feedback rating; 0 records none
+}
+
+// demoLessons are the sessions demo mode starts with
+var demoLessons = []demoLesson{
+	{Topic: "Kubernetes", ExplanationType: "standard", Persona: "senior-engineer", Saved: true, Favorite: true, Rating: 5},
+	{Topic: "Raft consensus", ExplanationType: "visualization", Saved: true, Rating: 4},
+	{Topic: "Binary search", ExplanationType: "simple", Persona: "10-year-old", Rating: 3},
+	{Topic: "CRDTs", ExplanationType: "analogy", Saved: true},
+}
+
+// demoModeFromEnv reports whether the orchestrator runs in demo mode (DEMO_MODE, set by --demo)
+func demoModeFromEnv() bool {
+	return os.Getenv("DEMO_MODE") == "true"
+}
+
+// sqlitePathFromEnv returns the SQLite database used for storage instead of Firestore (SQLITE_PATH)
+func sqlitePathFromEnv() string {
+	return strings.TrimSpace(os.Getenv("SQLITE_PATH"))
+}
+
+// seedDemoData fills an empty orchestrator with completed demo sessions, saved lessons and a
+// BrainPrint profile for demoUserID, so every API has something to show without running a
+// pipeline. Lessons come from the lite client; the profile is seeded once per database.
+func (o *Orchestrator) seedDemoData(ctx context.Context) error {
+	lite := llm.NewLiteClient()
+	seededAt := time.Now().Add(-time.Duration(len(demoLessons)) * time.Hour)
+
+	for i, demo := range demoLessons {
+		summary, err := lite.Summarize(ctx, demo.Topic, "")
+		if err != nil {
+			return fmt.Errorf("failed to outline demo lesson %q: %w", demo.Topic, err)
+		}
+		lesson, err := lite.ExplainWithOG(ctx, demo.Topic, strings.Join(summary.Outline, "\n"), "", "")
+		if err != nil {
+			return fmt.Errorf("failed to write demo lesson %q: %w", demo.Topic, err)
+		}
+		lessonJSON, err := json.Marshal(lesson)
+		if err != nil {
+			return fmt.Errorf("failed to encode demo lesson %q: %w", demo.Topic, err)
+		}
+
+		createdAt := seededAt.Add(time.Duration(i) * time.Hour)
+		difficulty, _ := normalizeDifficulty("", demo.ExplanationType)
+		result := &SessionResult{
+			Lesson:      string(lessonJSON),
+			LessonHTML:  lessonHTML(string(lessonJSON)),
+			Summary:     llm.ExtractTLDR(lesson.BigPicture),
+			Outline:     summary.Outline,
+			TOC:         llm.BuildTableOfContents(lesson, summary.Outline),
+			Readability: newReadabilityReport(lesson, difficulty),
+			CompletedAt: createdAt.Add(time.Minute),
+		}
+		session := &Session{
+			ID:        fmt.Sprintf("demo-session-%d", i+1),
+			Topic:     demo.Topic,
+			Status:    "completed",
+			CreatedAt: createdAt,
+			Result:    result,
+			Steps:     make([]SessionStep, 0),
+			Metadata: map[string]interface{}{
+				"explanation_type": demo.ExplanationType,
+				"difficulty":       difficulty,
+				"user_id":          demoUserID,
+				"demo":             true,
+			},
+			Tags: []string{"demo"},
+		}
+		if demo.Persona != "" {
+			session.Metadata["persona"] = demo.Persona
+		}
+		o.UpdateSession(session)
+
+		if demo.Saved {
+			saved := &SavedLesson{
+				ID:              fmt.Sprintf("demo-lesson-%d", i+1),
+				SessionID:       session.ID,
+				UserID:          demoUserID,
+				Topic:           demo.Topic,
+				Title:           demo.Topic,
+				Summary:         result.Summary,
+				ExplanationType: demo.ExplanationType,
+				Result:          result,
+				Tags:            []string{"demo"},
+				CreatedAt:       result.CompletedAt,
+				UpdatedAt:       result.CompletedAt,
+			}
+			if demo.Favorite {
+				favoritedAt := result.CompletedAt
+				saved.Favorite = true
+				saved.FavoritedAt = &favoritedAt
+			}
+			o.mu.Lock()
+			o.savedLessons[saved.ID] = saved
+			o.mu.Unlock()
+		}
+	}
+
+	seededProfile, err := o.seedDemoBrainPrint(ctx)
+	if err != nil {
+		return err
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"user_id":         demoUserID,
+		"sessions":        len(demoLessons),
+		"seeded_profile":  seededProfile,
+		"example_session": "/api/sessions/demo-session-1",
+		"example_library": "/api/saved/" + demoUserID,
+	}).Info("Demo data seeded")
+	return nil
+}
+
+// seedDemoBrainPrint records the demo lessons in the demo user's BrainPrint profile, unless a
+// profile persisted from an earlier demo run already has them. It reports whether it seeded.
+func (o *Orchestrator) seedDemoBrainPrint(ctx context.Context) (bool, error) {
+	if o.brainprintSvc == nil {
+		return false, nil
+	}
+	profile, err := o.brainprintSvc.GetBrainPrint(ctx, demoUserID)
+	if err == nil && profile.TotalSessions > 0 {
+		return false, nil
+	}
+
+	for _, demo := range demoLessons {
+		if err := o.brainprintSvc.TrackSessionWithPersona(ctx, demoUserID, demo.ExplanationType, demo.Persona, true); err != nil {
+			return false, fmt.Errorf("failed to seed demo BrainPrint profile: %w", err)
+		}
+		if demo.Rating > 0 {
+			if err := o.brainprintSvc.RecordFeedback(ctx, demoUserID, demo.ExplanationType, demo.Rating); err != nil {
+				return false, fmt.Errorf("failed to seed demo BrainPrint feedback: %w", err)
+			}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/brainprint"
+	"github.com/InnoFusionTech/ExplainIQ/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDemoTestOrchestrator creates an orchestrator whose BrainPrint profiles persist in store
+func newDemoTestOrchestrator(store storage.Storage) *Orchestrator {
+	return &Orchestrator{
+		sessions:      make(map[string]*Session),
+		savedLessons:  make(map[string]*SavedLesson),
+		logger:        logrus.New(),
+		clients:       make(map[string][]chan SSEEvent),
+		brainprintSvc: brainprint.NewService(store),
+	}
+}
+
+// TestSeedDemoData tests that demo mode starts with completed sessions, a library and a BrainPrint profile
+func TestSeedDemoData(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStorage(ctx, filepath.Join(t.TempDir(), "demo.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	o := newDemoTestOrchestrator(store)
+	require.NoError(t, o.seedDemoData(ctx))
+
+	require.Len(t, o.sessions, len(demoLessons))
+	session := o.sessions["demo-session-1"]
+	require.NotNil(t, session)
+	assert.Equal(t, "completed", session.Status)
+	assert.Equal(t, demoUserID, session.Metadata["user_id"])
+	require.NotNil(t, session.Result)
+	assert.NotNil(t, parseLesson(session.Result.Lesson))
+	assert.NotEmpty(t, session.Result.TOC)
+
+	assert.Len(t, o.savedLessons, 3)
+	assert.True(t, o.savedLessons["demo-lesson-1"].Favorite)
+	assert.Equal(t, demoUserID, o.savedLessons["demo-lesson-2"].UserID)
+
+	profile, err := o.brainprintSvc.GetBrainPrint(ctx, demoUserID)
+	require.NoError(t, err)
+	assert.Equal(t, len(demoLessons), profile.TotalSessions)
+
+	// A restart on the same database reseeds sessions but not the persisted profile
+	restarted := newDemoTestOrchestrator(store)
+	require.NoError(t, restarted.seedDemoData(ctx))
+	assert.Len(t, restarted.sessions, len(demoLessons))
+	profile, err = restarted.brainprintSvc.GetBrainPrint(ctx, demoUserID)
+	require.NoError(t, err)
+	assert.Equal(t, len(demoLessons), profile.TotalSessions)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// dependencyPolicy is how the orchestrator treats an optional dependency that is unavailable
+type dependencyPolicy string
+
+const (
+	dependencyRequired dependencyPolicy = "required" // Refuse to start without it
+	dependencyDegrade  dependencyPolicy = "degrade"  // Start without it and report the degradation
+	dependencyDisabled dependencyPolicy = "disabled" // Never connect to it
+)
+
+// Dependencies with a policy, configured with DEPENDENCY_POLICY_<NAME>
+const (
+	dependencyFirestore    = "firestore"
+	dependencyRetrieval    = "retrieval" // Elasticsearch or another configured retrieval backend
+	dependencyCostTracking = "cost_tracking"
+)
+
+// dependencyNames lists the dependencies in the order they are reported
+var dependencyNames = []string{dependencyFirestore, dependencyRetrieval, dependencyCostTracking}
+
+// Dependency states reported on /readyz
+const (
+	dependencyAvailable   = "available"
+	dependencyDegraded    = "degraded"    // Unavailable, and the orchestrator runs without it
+	dependencyOff         = "disabled"    // Disabled by policy or not configured
+	dependencyUnavailable = "unavailable" // Unavailable although required
+)
+
+// errDependencyNotConfigured reports a dependency the environment does not configure, e.g. no GCP_PROJECT_ID
+var errDependencyNotConfigured = errors.New("not configured")
+
+// DependencyStatus is the policy and current state of one dependency
+type DependencyStatus struct {
+	Name      string           `json:"name"`
+	Policy    dependencyPolicy `json:"policy"`
+	State     string           `json:"state"`
+	Reason    string           `json:"reason,omitempty"` // Why the dependency is not available
+	CheckedAt time.Time        `json:"checked_at,omitempty"`
+}
+
+// dependencyPolicyEnv returns the environment variable configuring a dependency's policy
+func dependencyPolicyEnv(name string) string {
+	return "DEPENDENCY_POLICY_" + strings.ToUpper(name)
+}
+
+// dependencyPoliciesFromEnv reads each dependency's policy. Unset policies degrade, except cost
+// tracking, which is required when ENVIRONMENT is production. Unlike most settings an invalid
+// policy is an error rather than a warning, so a typo cannot silently relax a requirement.
+func dependencyPoliciesFromEnv() (map[string]dependencyPolicy, error) {
+	policies := make(map[string]dependencyPolicy, len(dependencyNames))
+	for _, name := range dependencyNames {
+		policy := dependencyDegrade
+		if name == dependencyCostTracking && os.Getenv("ENVIRONMENT") == "production" {
+			policy = dependencyRequired
+		}
+		if v := strings.ToLower(strings.TrimSpace(os.Getenv(dependencyPolicyEnv(name)))); v != "" {
+			switch dependencyPolicy(v) {
+			case dependencyRequired, dependencyDegrade, dependencyDisabled:
+				policy = dependencyPolicy(v)
+			default:
+				return nil, fmt.Errorf("invalid %s %q (supported: required, degrade, disabled)", dependencyPolicyEnv(name), v)
+			}
+		}
+		policies[name] = policy
+	}
+	if err := validateDependencyPolicies(policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// validateDependencyPolicies rejects policies that cannot be met together
+func validateDependencyPolicies(policies map[string]dependencyPolicy) error {
+	if policies[dependencyCostTracking] == dependencyRequired && policies[dependencyFirestore] == dependencyDisabled {
+		return fmt.Errorf("cost tracking is required but Firestore, which stores it, is disabled")
+	}
+	return nil
+}
+
+// dependencyMonitor tracks the state of the optional dependencies against their policies
+type dependencyMonitor struct {
+	mu       sync.RWMutex
+	policies map[string]dependencyPolicy
+	statuses map[string]*DependencyStatus
+}
+
+// newDependencyMonitor creates a monitor with every dependency not yet reported
+func newDependencyMonitor(policies map[string]dependencyPolicy) *dependencyMonitor {
+	m := &dependencyMonitor{policies: policies, statuses: make(map[string]*DependencyStatus, len(policies))}
+	for name, policy := range policies {
+		m.statuses[name] = &DependencyStatus{Name: name, Policy: policy, State: dependencyOff, Reason: "not checked yet"}
+	}
+	return m
+}
+
+// enabled reports whether the orchestrator should connect to a dependency
+func (m *dependencyMonitor) enabled(name string) bool {
+	if m == nil {
+		return true
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.policies[name] != dependencyDisabled
+}
+
+// report records whether a dependency is available; err is nil when it is. It returns an error
+// when a required dependency is unavailable, which callers treat as fatal at startup.
+func (m *dependencyMonitor) report(name string, err error) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	policy := m.policies[name]
+	status := &DependencyStatus{Name: name, Policy: policy, CheckedAt: time.Now()}
+	m.statuses[name] = status
+	switch {
+	case policy == dependencyDisabled:
+		status.State = dependencyOff
+		status.Reason = "disabled by policy"
+	case err == nil:
+		status.State = dependencyAvailable
+	case policy == dependencyRequired:
+		status.State = dependencyUnavailable
+		status.Reason = err.Error()
+		return fmt.Errorf("required dependency %s is unavailable: %w", name, err)
+	case errors.Is(err, errDependencyNotConfigured):
+		status.State = dependencyOff
+		status.Reason = err.Error()
+	default:
+		status.State = dependencyDegraded
+		status.Reason = err.Error()
+		logrus.WithFields(logrus.Fields{
+			"dependency": name,
+			"error":      err,
+		}).Warn("Dependency unavailable, continuing in degraded mode")
+	}
+	return nil
+}
+
+// snapshot returns the status of each dependency, in report order
+func (m *dependencyMonitor) snapshot() []DependencyStatus {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	statuses := make([]DependencyStatus, 0, len(m.statuses))
+	for _, name := range dependencyNames {
+		if status, ok := m.statuses[name]; ok {
+			statuses = append(statuses, *status)
+		}
+	}
+	return statuses
+}
+
+// readyzHandler handles GET /readyz
+// It fails while a required dependency is unavailable and reports dependencies the orchestrator runs without.
+func (o *Orchestrator) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	dependencies := o.dependencies.snapshot()
+	status, code := "ready", http.StatusOK
+	for _, dependency := range dependencies {
+		switch dependency.State {
+		case dependencyUnavailable:
+			status, code = "unavailable", http.StatusServiceUnavailable
+		case dependencyDegraded:
+			if code == http.StatusOK {
+				status = "degraded"
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       status,
+		"dependencies": dependencies,
+		"timestamp":    time.Now(),
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDependencyPoliciesFromEnv tests policy defaults, production defaults and validation
+func TestDependencyPoliciesFromEnv(t *testing.T) {
+	policies, err := dependencyPoliciesFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, dependencyDegrade, policies[dependencyCostTracking])
+
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("DEPENDENCY_POLICY_RETRIEVAL", "Disabled")
+	policies, err = dependencyPoliciesFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, dependencyRequired, policies[dependencyCostTracking])
+	assert.Equal(t, dependencyDisabled, policies[dependencyRetrieval])
+	assert.Equal(t, dependencyDegrade, policies[dependencyFirestore])
+
+	t.Setenv("DEPENDENCY_POLICY_FIRESTORE", "disabled")
+	_, err = dependencyPoliciesFromEnv()
+	assert.ErrorContains(t, err, "cost tracking is required")
+
+	t.Setenv("DEPENDENCY_POLICY_FIRESTORE", "optional")
+	_, err = dependencyPoliciesFromEnv()
+	assert.ErrorContains(t, err, "DEPENDENCY_POLICY_FIRESTORE")
+}
+
+// TestReadyzReportsDependencyState tests that /readyz reports degradation and fails on a missing required dependency
+func TestReadyzReportsDependencyState(t *testing.T) {
+	monitor := newDependencyMonitor(map[string]dependencyPolicy{
+		dependencyFirestore:    dependencyDegrade,
+		dependencyRetrieval:    dependencyDisabled,
+		dependencyCostTracking: dependencyRequired,
+	})
+	o := &Orchestrator{dependencies: monitor}
+	router := chi.NewRouter()
+	router.Get("/readyz", o.readyzHandler)
+
+	assert.False(t, monitor.enabled(dependencyRetrieval))
+	require.NoError(t, monitor.report(dependencyRetrieval, nil))
+	require.NoError(t, monitor.report(dependencyFirestore, errors.New("connection refused")))
+	require.NoError(t, monitor.report(dependencyCostTracking, nil))
+
+	w := serve(router, "GET", "/readyz")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Status       string             `json:"status"`
+		Dependencies []DependencyStatus `json:"dependencies"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "degraded", body.Status)
+	require.Len(t, body.Dependencies, 3)
+	assert.Equal(t, dependencyDegraded, body.Dependencies[0].State)
+	assert.Equal(t, "connection refused", body.Dependencies[0].Reason)
+	assert.Equal(t, dependencyOff, body.Dependencies[1].State)
+
+	// An unconfigured dependency is off unless it is required
+	assert.NoError(t, monitor.report(dependencyFirestore, errDependencyNotConfigured))
+	assert.Error(t, monitor.report(dependencyCostTracking, errDependencyNotConfigured))
+	w = serve(router, "GET", "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "unavailable", body.Status)
+	assert.Equal(t, dependencyOff, body.Dependencies[0].State)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// agentModeRemote calls each agent over the network (the default)
+	agentModeRemote = "remote"
+	// agentModeEmbedded runs every agent in-process for single-binary deployments
+	agentModeEmbedded = "embedded"
+	// agentModeLite runs every agent in-process with canned responses, for demos without credentials
+	agentModeLite = "lite"
+)
+
+// AgentClient executes pipeline tasks on an agent, either remote or in-process
+type AgentClient interface {
+	ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error)
+	Health(ctx context.Context) error
+}
+
+// agentModeFromEnv returns the agent mode (AGENT_MODE); anything other than "embedded" or "lite" means remote
+func agentModeFromEnv() string {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("AGENT_MODE")))
+	if mode == agentModeEmbedded || mode == agentModeLite {
+		return mode
+	}
+	return agentModeRemote
+}
+
+// agentsInProcess reports whether an agent mode runs the agents inside the orchestrator
+func agentsInProcess(mode string) bool {
+	return mode == agentModeEmbedded || mode == agentModeLite
+}
+
+// newEmbeddedAgentClients creates in-process clients for every agent. Each agent gets
+// its own LLM client, as it would in its own process; in lite mode that client is a stub.
+func newEmbeddedAgentClients(config PipelineConfig, logger *logrus.Logger) map[string]AgentClient {
+	clients := make(map[string]AgentClient, len(agents.Names))
+	for _, name := range agents.Names {
+		var client llm.GeminiClientInterface
+		if config.AgentMode == agentModeLite {
+			client = llm.NewLiteClient()
+		} else {
+			client = llm.NewGeminiClient("")
+		}
+		processor, err := agents.NewProcessor(name, client, logger)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"agent": name,
+				"error": err,
+			}).Error("Failed to create embedded agent")
+			continue
+		}
+		logger.WithFields(logrus.Fields{
+			"agent": name,
+			"mode":  config.AgentMode,
+		}).Info("Initializing embedded agent")
+		clients[name] = agents.NewLocalClient(name, processor, config.StepTimeout)
+	}
+	return clients
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAgentModeFromEnv tests that only AGENT_MODE=embedded or lite selects in-process agents
+func TestAgentModeFromEnv(t *testing.T) {
+	t.Setenv("AGENT_MODE", "")
+	assert.Equal(t, agentModeRemote, agentModeFromEnv())
+
+	t.Setenv("AGENT_MODE", "Embedded")
+	assert.Equal(t, agentModeEmbedded, agentModeFromEnv())
+
+	t.Setenv("AGENT_MODE", "lite")
+	assert.Equal(t, agentModeLite, agentModeFromEnv())
+
+	t.Setenv("AGENT_MODE", "http")
+	assert.Equal(t, agentModeRemote, agentModeFromEnv())
+}
+
+// TestNewEmbeddedAgentClients tests that every pipeline agent gets an in-process client
+func TestNewEmbeddedAgentClients(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "test-key")
+	clients := newEmbeddedAgentClients(PipelineConfig{StepTimeout: time.Minute}, logrus.New())
+
+	assert.Len(t, clients, len(agents.Names))
+	for _, name := range agents.Names {
+		assert.IsType(t, &agents.LocalClient{}, clients[name], name)
+	}
+}
+
+// TestLiteAgentClients tests that lite mode runs a lesson through every agent without credentials
+func TestLiteAgentClients(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "")
+	config := DefaultPipelineConfig()
+	config.AgentMode = agentModeLite
+	config.RetryDelay = time.Millisecond
+	clients := newEmbeddedAgentClients(config, logrus.New())
+	require.Len(t, clients, len(agents.Names))
+
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+	}
+	session := o.CreateSession("Caching")
+	p := &Pipeline{config: config, logger: logrus.New(), adkClients: clients}
+	require.NoError(t, p.runPipeline(context.Background(), session.ID, o))
+
+	session, _ = o.GetSession(session.ID)
+	assert.Equal(t, "completed", session.Status)
+	require.NotNil(t, session.Result)
+	assert.Contains(t, session.Result.Lesson, "Caching")
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/elastic"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/InnoFusionTech/ExplainIQ/internal/retrieval"
+	"github.com/sirupsen/logrus"
+)
+
+// newContextRetrieval creates the configured embedding model and checks that its dimensions match
+// the retrieval index before bootstrapping the index at that size. Retrieval is disabled, and all
+// results are nil, if the embedder cannot be created or the index was built with another size.
+func newContextRetrieval(ctx context.Context, config PipelineConfig, provider retrieval.Provider, logger *logrus.Logger) (*elastic.Retriever, CorpusSearcher, TextEmbedder) {
+	embeddingConfig, err := llm.EmbeddingConfigFromEnv(config.LLMProjectID, config.LLMLocation)
+	if err != nil {
+		logger.WithError(err).Error("Invalid embedding configuration, continuing without context retrieval")
+		return nil, nil, nil
+	}
+	embedder, err := llm.NewEmbedder(ctx, embeddingConfig)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"embedding_provider": embeddingConfig.Provider,
+			"error":              err,
+		}).Error("Embedding model not available, continuing without context retrieval")
+		return nil, nil, nil
+	}
+
+	fields := logrus.Fields{
+		"provider":           provider.Name(),
+		"index":              config.ElasticIndex,
+		"embedding_provider": embeddingConfig.Provider,
+		"embedding_model":    embedder.Model(),
+		"dimensions":         embedder.Dimensions(),
+	}
+	if err := retrieval.CheckDimensions(ctx, provider, config.ElasticIndex, embedder.Dimensions()); err != nil {
+		logger.WithFields(fields).WithError(err).Error("Embedding dimensions do not match the retrieval index, continuing without context retrieval")
+		return nil, nil, nil
+	}
+
+	// Create the index and its lifecycle policy if they do not exist yet
+	if err := provider.EnsureIndex(ctx, config.ElasticIndex, embedder.Dimensions()); err != nil {
+		logger.WithFields(fields).WithError(err).Warn("Failed to bootstrap retrieval index, searches will fail until it exists")
+	}
+
+	logger.WithFields(fields).Info("Context retrieval enabled")
+	return retrieval.NewRetriever(provider, embedder), provider, embedder
+}
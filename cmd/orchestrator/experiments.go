@@ -0,0 +1,353 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// experimentAgents are the agents whose prompts experiments may vary; the visualizer has no text prompt
+var experimentAgents = map[string]bool{"summarizer": true, "explainer": true, "critic": true}
+
+// PromptVariant is one arm of a prompt experiment
+type PromptVariant struct {
+	ID           string  `json:"id"`
+	Weight       float64 `json:"weight"`                 // Relative share of sessions assigned to the variant
+	Instructions string  `json:"instructions,omitempty"` // Appended to the agent's prompt; empty for the control
+}
+
+// PromptExperiment splits sessions between prompt variants of one agent
+type PromptExperiment struct {
+	ID          string          `json:"id"`
+	Agent       string          `json:"agent"` // summarizer, explainer or critic
+	Description string          `json:"description,omitempty"`
+	Variants    []PromptVariant `json:"variants"`
+}
+
+// validate checks an experiment's agent and variants
+func (e *PromptExperiment) validate() error {
+	if e.ID == "" {
+		return fmt.Errorf("experiment id is required")
+	}
+	if !experimentAgents[e.Agent] {
+		return fmt.Errorf("experiment %s: agent must be summarizer, explainer or critic", e.ID)
+	}
+	if len(e.Variants) < 2 {
+		return fmt.Errorf("experiment %s: at least two variants are required", e.ID)
+	}
+	seen := make(map[string]bool, len(e.Variants))
+	for _, variant := range e.Variants {
+		if variant.ID == "" || seen[variant.ID] {
+			return fmt.Errorf("experiment %s: variant ids must be set and unique", e.ID)
+		}
+		if variant.Weight <= 0 {
+			return fmt.Errorf("experiment %s: variant %s needs a positive weight", e.ID, variant.ID)
+		}
+		seen[variant.ID] = true
+	}
+	return nil
+}
+
+// assign picks a session's variant. The choice is a stable hash of the experiment and session,
+// so re-runs of a session keep their variant.
+func (e *PromptExperiment) assign(sessionID string) PromptVariant {
+	total := 0.0
+	for _, variant := range e.Variants {
+		total += variant.Weight
+	}
+	h := fnv.New64a()
+	h.Write([]byte(e.ID + ":" + sessionID))
+	point := float64(h.Sum64()%10000) / 10000 * total
+	for _, variant := range e.Variants {
+		if point < variant.Weight {
+			return variant
+		}
+		point -= variant.Weight
+	}
+	return e.Variants[len(e.Variants)-1]
+}
+
+// experimentOutcome is what a session's run produced, for correlating with its variants
+type experimentOutcome struct {
+	variants     map[string]string // Experiment ID -> variant ID
+	finished     bool
+	failed       bool
+	critiqued    bool
+	criticIssues int
+	severeIssues int // High and critical issues
+	ratings      []float64
+}
+
+// experimentStore holds the configured experiments and the outcomes of the sessions in them
+type experimentStore struct {
+	mu          sync.RWMutex
+	experiments []*PromptExperiment
+	outcomes    map[string]*experimentOutcome // Session ID -> outcome
+}
+
+// newExperimentStore creates a store for validated experiments; invalid ones and second
+// experiments on the same agent are skipped with a warning
+func newExperimentStore(experiments []*PromptExperiment) *experimentStore {
+	store := &experimentStore{outcomes: make(map[string]*experimentOutcome)}
+	agents := make(map[string]string)
+	for _, experiment := range experiments {
+		if err := experiment.validate(); err != nil {
+			logrus.WithError(err).Warn("Skipping invalid prompt experiment")
+			continue
+		}
+		if other, exists := agents[experiment.Agent]; exists {
+			logrus.WithFields(logrus.Fields{
+				"experiment_id": experiment.ID,
+				"running":       other,
+				"agent":         experiment.Agent,
+			}).Warn("Skipping prompt experiment, another experiment already varies this agent")
+			continue
+		}
+		agents[experiment.Agent] = experiment.ID
+		store.experiments = append(store.experiments, experiment)
+	}
+	return store
+}
+
+// experimentStoreFromEnv loads prompt experiments from the JSON array in EXPERIMENTS_FILE
+func experimentStoreFromEnv() *experimentStore {
+	path := os.Getenv("EXPERIMENTS_FILE")
+	if path == "" {
+		return newExperimentStore(nil)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to read EXPERIMENTS_FILE, prompt experiments disabled")
+		return newExperimentStore(nil)
+	}
+	var experiments []*PromptExperiment
+	if err := json.Unmarshal(data, &experiments); err != nil {
+		logrus.WithError(err).Warn("Invalid EXPERIMENTS_FILE, prompt experiments disabled")
+		return newExperimentStore(nil)
+	}
+	return newExperimentStore(experiments)
+}
+
+// get returns an experiment by ID
+func (s *experimentStore) get(id string) (*PromptExperiment, bool) {
+	for _, experiment := range s.experiments {
+		if experiment.ID == id {
+			return experiment, true
+		}
+	}
+	return nil, false
+}
+
+// applyPromptExperiments assigns a session to a variant of each experiment, tags the session
+// with the variant IDs in its "experiments" metadata and passes each variant's instructions
+// to its agent's step
+func (o *Orchestrator) applyPromptExperiments(session *Session, steps []PipelineStep) {
+	if o.experiments == nil || len(o.experiments.experiments) == 0 {
+		return
+	}
+
+	assigned := make(map[string]string, len(o.experiments.experiments))
+	for _, experiment := range o.experiments.experiments {
+		variant := experiment.assign(session.ID)
+		assigned[experiment.ID] = variant.ID
+		if variant.Instructions == "" {
+			continue
+		}
+		for i := range steps {
+			if steps[i].Name == experiment.Agent {
+				steps[i].Inputs["prompt_instructions"] = variant.Instructions
+			}
+		}
+	}
+
+	o.mu.Lock()
+	session.Metadata["experiments"] = assigned
+	o.mu.Unlock()
+
+	o.experiments.mu.Lock()
+	o.experiments.outcomes[session.ID] = &experimentOutcome{variants: assigned}
+	o.experiments.mu.Unlock()
+}
+
+// recordExperimentOutcome records how a session in an experiment finished, including the
+// issues the critic found, and logs it for offline analysis
+func (o *Orchestrator) recordExperimentOutcome(sessionID string, runErr error) {
+	if o.experiments == nil {
+		return
+	}
+
+	var issues []llm.CritiqueIssue
+	o.mu.RLock()
+	if session, exists := o.sessions[sessionID]; exists {
+		if critique := session.partialOutputs["critic"]["critique"]; critique != "" {
+			if err := json.Unmarshal([]byte(critique), &issues); err != nil {
+				issues = nil
+			}
+		}
+	}
+	o.mu.RUnlock()
+
+	o.experiments.mu.Lock()
+	outcome, exists := o.experiments.outcomes[sessionID]
+	if !exists {
+		o.experiments.mu.Unlock()
+		return
+	}
+	outcome.finished = true
+	outcome.failed = runErr != nil
+	outcome.critiqued = issues != nil
+	outcome.criticIssues = len(issues)
+	outcome.severeIssues = 0
+	for _, issue := range issues {
+		if issue.Severity == "high" || issue.Severity == "critical" {
+			outcome.severeIssues++
+		}
+	}
+	fields := logrus.Fields{
+		"session_id":    sessionID,
+		"experiments":   outcome.variants,
+		"failed":        outcome.failed,
+		"critic_issues": outcome.criticIssues,
+		"severe_issues": outcome.severeIssues,
+	}
+	o.experiments.mu.Unlock()
+
+	o.logger.WithFields(fields).Info("Prompt experiment outcome")
+}
+
+// recordExperimentRating records a learner's 1-5 rating of a session in an experiment
+func (o *Orchestrator) recordExperimentRating(sessionID string, rating float64) {
+	if o.experiments == nil {
+		return
+	}
+
+	o.experiments.mu.Lock()
+	outcome, exists := o.experiments.outcomes[sessionID]
+	if !exists {
+		o.experiments.mu.Unlock()
+		return
+	}
+	outcome.ratings = append(outcome.ratings, rating)
+	variants := outcome.variants
+	o.experiments.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id":  sessionID,
+		"experiments": variants,
+		"rating":      rating,
+	}).Info("Prompt experiment rating")
+}
+
+// VariantReport summarizes the sessions assigned to one variant
+type VariantReport struct {
+	VariantID       string  `json:"variant_id"`
+	Weight          float64 `json:"weight"`
+	Sessions        int     `json:"sessions"`  // Assigned sessions, including those still running
+	Completed       int     `json:"completed"` // Runs that produced a lesson
+	Failed          int     `json:"failed"`
+	Critiqued       int     `json:"critiqued"` // Completed runs the critic reviewed
+	AvgCriticIssues float64 `json:"avg_critic_issues"`
+	AvgSevereIssues float64 `json:"avg_severe_issues"` // High and critical issues per critiqued run
+	Ratings         int     `json:"ratings"`
+	AvgRating       float64 `json:"avg_rating"`
+}
+
+// ExperimentReport correlates an experiment's variants with critic findings and learner ratings
+type ExperimentReport struct {
+	Experiment  *PromptExperiment `json:"experiment"`
+	Variants    []VariantReport   `json:"variants"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// report aggregates the outcomes of an experiment's sessions by variant
+func (s *experimentStore) report(experiment *PromptExperiment) *ExperimentReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reports := make([]VariantReport, len(experiment.Variants))
+	index := make(map[string]int, len(experiment.Variants))
+	for i, variant := range experiment.Variants {
+		reports[i] = VariantReport{VariantID: variant.ID, Weight: variant.Weight}
+		index[variant.ID] = i
+	}
+
+	ratingSums := make([]float64, len(reports))
+	for _, outcome := range s.outcomes {
+		i, ok := index[outcome.variants[experiment.ID]]
+		if !ok {
+			continue
+		}
+		report := &reports[i]
+		report.Sessions++
+		if outcome.finished {
+			if outcome.failed {
+				report.Failed++
+			} else {
+				report.Completed++
+			}
+		}
+		if outcome.critiqued {
+			report.Critiqued++
+			report.AvgCriticIssues += float64(outcome.criticIssues)
+			report.AvgSevereIssues += float64(outcome.severeIssues)
+		}
+		for _, rating := range outcome.ratings {
+			report.Ratings++
+			ratingSums[i] += rating
+		}
+	}
+
+	for i := range reports {
+		if reports[i].Critiqued > 0 {
+			reports[i].AvgCriticIssues = roundHundredths(reports[i].AvgCriticIssues / float64(reports[i].Critiqued))
+			reports[i].AvgSevereIssues = roundHundredths(reports[i].AvgSevereIssues / float64(reports[i].Critiqued))
+		}
+		if reports[i].Ratings > 0 {
+			reports[i].AvgRating = roundHundredths(ratingSums[i] / float64(reports[i].Ratings))
+		}
+	}
+	return &ExperimentReport{Experiment: experiment, Variants: reports, GeneratedAt: time.Now()}
+}
+
+// roundHundredths rounds a report average to two decimal places
+func roundHundredths(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// listExperimentsHandler handles GET /api/experiments
+func (o *Orchestrator) listExperimentsHandler(w http.ResponseWriter, r *http.Request) {
+	experiments := make([]*PromptExperiment, 0)
+	if o.experiments != nil {
+		experiments = append(experiments, o.experiments.experiments...)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"experiments": experiments,
+		"count":       len(experiments),
+	})
+}
+
+// experimentReportHandler handles GET /api/experiments/{id}/report
+func (o *Orchestrator) experimentReportHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id := chi.URLParam(r, "id")
+	if o.experiments == nil {
+		writeJSONError(w, http.StatusNotFound, "Experiment not found", fmt.Sprintf("No experiment with ID %s", id))
+		return
+	}
+	experiment, exists := o.experiments.get(id)
+	if !exists {
+		writeJSONError(w, http.StatusNotFound, "Experiment not found", fmt.Sprintf("No experiment with ID %s", id))
+		return
+	}
+	json.NewEncoder(w).Encode(o.experiments.report(experiment))
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestExperiment creates an explainer experiment with a control and a treatment
+func newTestExperiment() *PromptExperiment {
+	return &PromptExperiment{
+		ID:    "hook-first",
+		Agent: "explainer",
+		Variants: []PromptVariant{
+			{ID: "control", Weight: 1},
+			{ID: "question", Weight: 1, Instructions: "Open with a question."},
+		},
+	}
+}
+
+// TestPromptExperimentAssignment tests that assignment is stable per session and follows the weights
+func TestPromptExperimentAssignment(t *testing.T) {
+	experiment := newTestExperiment()
+	experiment.Variants[1].Weight = 3
+
+	counts := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		sessionID := fmt.Sprintf("session-%d", i)
+		variant := experiment.assign(sessionID)
+		assert.Equal(t, variant.ID, experiment.assign(sessionID).ID)
+		counts[variant.ID]++
+	}
+	assert.InDelta(t, 500, counts["control"], 100)
+	assert.InDelta(t, 1500, counts["question"], 100)
+}
+
+// TestNewExperimentStoreValidation tests that invalid experiments and a second experiment on one agent are skipped
+func TestNewExperimentStoreValidation(t *testing.T) {
+	duplicate := newTestExperiment()
+	duplicate.ID = "other"
+	store := newExperimentStore([]*PromptExperiment{
+		newTestExperiment(),
+		duplicate,
+		{ID: "images", Agent: "visualizer", Variants: newTestExperiment().Variants},
+		{ID: "single", Agent: "critic", Variants: []PromptVariant{{ID: "only", Weight: 1}}},
+		{ID: "weightless", Agent: "summarizer", Variants: []PromptVariant{{ID: "a", Weight: 1}, {ID: "b"}}},
+	})
+	require.Len(t, store.experiments, 1)
+	assert.Equal(t, "hook-first", store.experiments[0].ID)
+}
+
+// TestExperimentReport tests that variants are passed to agents and correlated with critic issues and ratings
+func TestExperimentReport(t *testing.T) {
+	o := &Orchestrator{
+		sessions:    make(map[string]*Session),
+		logger:      logrus.New(),
+		experiments: newExperimentStore([]*PromptExperiment{newTestExperiment()}),
+	}
+	experiment := o.experiments.experiments[0]
+
+	// Find one session for each variant
+	sessions := make(map[string]*Session)
+	for i := 0; len(sessions) < 2; i++ {
+		id := fmt.Sprintf("s%d", i)
+		variant := experiment.assign(id)
+		if _, exists := sessions[variant.ID]; !exists {
+			sessions[variant.ID] = &Session{ID: id, Metadata: map[string]interface{}{}}
+			o.sessions[id] = sessions[variant.ID]
+		}
+	}
+
+	for variantID, session := range sessions {
+		steps := pipelineDefinition("Caching")
+		o.applyPromptExperiments(session, steps)
+		assert.Equal(t, map[string]string{"hook-first": variantID}, session.Metadata["experiments"])
+		for _, step := range steps {
+			if step.Name == "explainer" && variantID == "question" {
+				assert.Equal(t, "Open with a question.", step.Inputs["prompt_instructions"])
+			} else {
+				assert.Empty(t, step.Inputs["prompt_instructions"], step.Name)
+			}
+		}
+	}
+
+	sessions["control"].partialOutputs = map[string]map[string]string{
+		"critic": {"critique": `[{"section":"metaphor","severity":"high"},{"section":"real_life","severity":"low"}]`},
+	}
+	o.recordExperimentOutcome(sessions["control"].ID, nil)
+	o.recordExperimentRating(sessions["control"].ID, 3)
+	o.recordExperimentOutcome(sessions["question"].ID, errors.New("explainer failed"))
+	o.recordExperimentRating(sessions["question"].ID, 5)
+	o.recordExperimentRating(sessions["question"].ID, 4)
+	o.recordExperimentRating("unknown", 1)
+
+	r := chi.NewRouter()
+	r.Get("/api/experiments", o.listExperimentsHandler)
+	r.Get("/api/experiments/{id}/report", o.experimentReportHandler)
+
+	w := serve(r, http.MethodGet, "/api/experiments/hook-first/report")
+	require.Equal(t, http.StatusOK, w.Code)
+	var report ExperimentReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Len(t, report.Variants, 2)
+	assert.Equal(t, VariantReport{
+		VariantID: "control", Weight: 1, Sessions: 1, Completed: 1, Critiqued: 1,
+		AvgCriticIssues: 2, AvgSevereIssues: 1, Ratings: 1, AvgRating: 3,
+	}, report.Variants[0])
+	assert.Equal(t, VariantReport{
+		VariantID: "question", Weight: 1, Sessions: 1, Failed: 1, Ratings: 2, AvgRating: 4.5,
+	}, report.Variants[1])
+
+	assert.Contains(t, serve(r, http.MethodGet, "/api/experiments").Body.String(), `"count":1`)
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodGet, "/api/experiments/missing/report").Code)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/pkg/explainiqtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// jsonFields returns the kinds of a struct's JSON fields by name
+func jsonFields(t reflect.Type) map[string]reflect.Kind {
+	fields := make(map[string]reflect.Kind)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type.Kind()
+	}
+	return fields
+}
+
+// TestExplainiqtestMatchesSessionAPI tests that the in-memory orchestrator in pkg/explainiqtest
+// only serves fields the session API has, with the same JSON kinds, so it cannot drift from it
+func TestExplainiqtestMatchesSessionAPI(t *testing.T) {
+	pairs := []struct {
+		fake, real interface{}
+	}{
+		{explainiqtest.Session{}, Session{}},
+		{explainiqtest.SessionStep{}, SessionStep{}},
+		{explainiqtest.SessionResult{}, SessionResult{}},
+		{explainiqtest.SSEEvent{}, SSEEvent{}},
+	}
+	for _, pair := range pairs {
+		fakeType, realType := reflect.TypeOf(pair.fake), reflect.TypeOf(pair.real)
+		real := jsonFields(realType)
+		for name, kind := range jsonFields(fakeType) {
+			realKind, ok := real[name]
+			if assert.True(t, ok, "%s.%s is not in %s", fakeType, name, realType) {
+				assert.Equal(t, realKind, kind, "%s.%s", fakeType, name)
+			}
+		}
+	}
+}
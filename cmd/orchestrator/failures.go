@@ -0,0 +1,142 @@
+package main
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Failure classes of a failed session run
+const (
+	failureAgentUnavailable = "agent_unavailable" // An agent could not be reached
+	failureSafetyBlock      = "safety_block"      // The model refused or blocked its output
+	failureQuota            = "quota_exceeded"    // A model, API or retry quota ran out
+	failureInvalidOutput    = "invalid_output"    // An agent returned output that could not be parsed
+	failureTimeout          = "timeout"           // A step or the session ran past its deadline
+	failureModeration       = "moderation_blocked"
+	failureRejected         = "rejected" // A reviewer rejected a supervised step
+	failureUnknown          = "unknown"
+)
+
+// SessionFailure explains why a session run failed: a class, a message safe to show the
+// learner and a remediation hint for operators. The raw error stays in the step and the logs.
+type SessionFailure struct {
+	Class       string    `json:"class"`
+	Step        string    `json:"step,omitempty"`
+	Message     string    `json:"message"`     // User-facing
+	Remediation string    `json:"remediation"` // Operator-facing
+	Retryable   bool      `json:"retryable"`   // Running the session again may succeed
+	FailedAt    time.Time `json:"failed_at"`
+}
+
+// failureRule maps error text matching a pattern to a failure class
+type failureRule struct {
+	class       string
+	pattern     *regexp.Regexp
+	message     string
+	remediation string
+	retryable   bool
+}
+
+// failureRules are checked in order; the first rule whose pattern matches wins, so more
+// specific causes (a safety block, a quota) come before the transport errors they arrive in
+var failureRules = []failureRule{
+	{
+		class:       failureModeration,
+		pattern:     regexp.MustCompile(`(?i)blocked by content moderation`),
+		message:     "This lesson couldn't be shown because it didn't pass our content checks. Try rephrasing the topic.",
+		remediation: "Review the moderation verdict on the session error event; adjust MODERATION_DENYLIST if the block was a false positive.",
+	},
+	{
+		class:       failureRejected,
+		pattern:     regexp.MustCompile(`(?i)rejected by reviewer|approval timed out`),
+		message:     "This lesson was stopped during review.",
+		remediation: "A supervised step was rejected or not reviewed in time; check the review reason or raise SUPERVISED_APPROVAL_TIMEOUT.",
+	},
+	{
+		class:       failureSafetyBlock,
+		pattern:     regexp.MustCompile(`(?i)blocked|safety|recitation|prohibited content`),
+		message:     "The AI model declined to write part of this lesson. Try rephrasing the topic.",
+		remediation: "The model's safety filters blocked a prompt or response; check the step's prompt inputs and consider a fallback model for the step.",
+	},
+	{
+		class:       failureQuota,
+		pattern:     regexp.MustCompile(`(?i)\b429\b|quota|rate limit|resource.exhausted|retry budget exhausted|too many requests`),
+		message:     "We're handling a lot of lessons right now. Please try again in a few minutes.",
+		remediation: "A model or API quota was exhausted; check the project's Gemini quota, spread load with a quota project or fallback models, or raise PIPELINE_RETRY_BUDGET.",
+		retryable:   true,
+	},
+	{
+		class:       failureTimeout,
+		pattern:     regexp.MustCompile(`(?i)deadline exceeded|timed out|timeout|context canceled`),
+		message:     "Generating this lesson took too long. Please try again.",
+		remediation: "A step exceeded its timeout or the session deadline; check agent latency alarms and SESSION_TIMEOUT.",
+		retryable:   true,
+	},
+	{
+		class:       failureAgentUnavailable,
+		pattern:     regexp.MustCompile(`(?i)agent \S+ (is unavailable|not found)|connection refused|no such host|dial tcp|connection reset|service unavailable|http 50[234]\b|\bEOF\b`),
+		message:     "Part of our lesson service is temporarily unavailable. Please try again shortly.",
+		remediation: "An agent could not be reached; check /api/agents/health, the agent's deployment and its AGENT_*_URL.",
+		retryable:   true,
+	},
+	{
+		class:       failureInvalidOutput,
+		pattern:     regexp.MustCompile(`(?i)unmarshal|invalid character|parse|json|unexpected end|no candidates`),
+		message:     "Something went wrong while putting this lesson together. Please try again.",
+		remediation: "An agent returned malformed output; check the agent logs for the raw model response and the step's response schema.",
+		retryable:   true,
+	},
+}
+
+// classifyFailure classifies the error that failed a session at step
+func classifyFailure(step, errText string) *SessionFailure {
+	failure := &SessionFailure{
+		Class:       failureUnknown,
+		Step:        step,
+		Message:     "Something went wrong while generating this lesson. Please try again.",
+		Remediation: "Unclassified failure; see the step error and orchestrator logs for the session.",
+		Retryable:   true,
+		FailedAt:    time.Now(),
+	}
+	for _, rule := range failureRules {
+		if rule.pattern.MatchString(errText) {
+			failure.Class = rule.class
+			failure.Message = rule.message
+			failure.Remediation = rule.remediation
+			failure.Retryable = rule.retryable
+			return failure
+		}
+	}
+	return failure
+}
+
+// recordFailure classifies a failed run, attaches the result to the session record and logs the
+// remediation hint for operators
+func (o *Orchestrator) recordFailure(session *Session, step, errText string) *SessionFailure {
+	failure := classifyFailure(step, errText)
+	o.mu.Lock()
+	session.Failure = failure
+	o.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id":    session.ID,
+		"step":          step,
+		"failure_class": failure.Class,
+		"remediation":   failure.Remediation,
+		"error":         errText,
+	}).Warn("Session failed")
+	return failure
+}
+
+// failureEventData returns the data of a session_error event: the user-facing message as the
+// error, with the classification attached
+func failureEventData(sessionID string, failure *SessionFailure) map[string]interface{} {
+	return map[string]interface{}{
+		"session_id": sessionID,
+		"error":      failure.Message,
+		"failure":    failure,
+		"timestamp":  time.Now().Format(time.RFC3339),
+	}
+}
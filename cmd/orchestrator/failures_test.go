@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableAgentClient fails every task as if the agent's host refused connections
+type unreachableAgentClient struct{}
+
+// ExecuteTask implements AgentClient
+func (unreachableAgentClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error) {
+	return nil, errors.New(`Post "http://summarizer:8080/task": dial tcp 10.0.0.7:8080: connect: connection refused`)
+}
+
+// Health implements AgentClient
+func (unreachableAgentClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// TestClassifyFailure tests that common error texts map to their failure classes
+func TestClassifyFailure(t *testing.T) {
+	cases := map[string]string{
+		"lesson blocked by content moderation":                                failureModeration,
+		"step critic rejected by reviewer: off topic":                         failureRejected,
+		"step approval timed out":                                             failureRejected,
+		"gemini: response blocked: finish reason SAFETY":                      failureSafetyBlock,
+		"gemini API error 429: RESOURCE_EXHAUSTED":                            failureQuota,
+		"agent unavailable (session retry budget exhausted)":                  failureQuota,
+		"session deadline exceeded":                                           failureTimeout,
+		"dial tcp 10.0.0.7:8080: connect: connection refused":                 failureAgentUnavailable,
+		"agent summarizer not found":                                          failureAgentUnavailable,
+		"failed to parse lesson: invalid character '}' looking for beginning": failureInvalidOutput,
+		"something odd happened":                                              failureUnknown,
+	}
+	for errText, class := range cases {
+		failure := classifyFailure("explainer", errText)
+		assert.Equal(t, class, failure.Class, errText)
+		assert.Equal(t, "explainer", failure.Step)
+		assert.NotEmpty(t, failure.Message, errText)
+		assert.NotEmpty(t, failure.Remediation, errText)
+		assert.NotContains(t, failure.Message, errText, "the raw error is not shown to users")
+	}
+
+	assert.False(t, classifyFailure("", "lesson blocked by content moderation").Retryable)
+	assert.True(t, classifyFailure("", "session deadline exceeded").Retryable)
+}
+
+// TestPipelineFailureClassified tests that a failed run records its classification on the session and the error event
+func TestPipelineFailureClassified(t *testing.T) {
+	o := &Orchestrator{
+		sessions: make(map[string]*Session),
+		logger:   logrus.New(),
+		clients:  make(map[string][]chan SSEEvent),
+	}
+	config := DefaultPipelineConfig()
+	config.MaxRetries = 0
+	agent := unreachableAgentClient{}
+	p := &Pipeline{
+		config:     config,
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+	session := o.CreateSession("Caching")
+	events := make(chan SSEEvent, 64)
+	o.AddClient(session.ID, events)
+
+	require.Error(t, p.runPipeline(context.Background(), session.ID, o))
+
+	session, _ = o.GetSession(session.ID)
+	require.NotNil(t, session.Failure)
+	assert.Equal(t, "failed", session.Status)
+	assert.Equal(t, failureAgentUnavailable, session.Failure.Class)
+	assert.Equal(t, "summarizer", session.Failure.Step)
+	assert.True(t, session.Failure.Retryable)
+
+	var errorEvent *SSEEvent
+	timeout := time.After(time.Second)
+	for errorEvent == nil {
+		select {
+		case event := <-events:
+			if event.Type == "session_error" {
+				errorEvent = &event
+			}
+		case <-timeout:
+			t.Fatal("no session_error event")
+		}
+	}
+	assert.Equal(t, session.Failure.Message, errorEvent.Data["error"])
+	assert.NotContains(t, errorEvent.Data["error"], "connection refused")
+	assert.Equal(t, session.Failure, errorEvent.Data["failure"])
+
+	// Viewers who connect after the failure get the same classified event
+	status, _ := o.sessionStatus(session.ID)
+	assert.Equal(t, session.Failure, status.Failure)
+	replayed := o.finishedSessionEvent(session.ID, status)
+	require.NotNil(t, replayed)
+	assert.Equal(t, session.Failure.Message, replayed.Data["error"])
+}
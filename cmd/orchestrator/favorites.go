@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Orders of the saved lesson list (?sort=)
+const (
+	savedSortCreated   = "created"   // Newest saved first (default)
+	savedSortRecent    = "recent"    // Most recently viewed first; never viewed lessons last
+	savedSortFavorites = "favorites" // Favorites first, most recently favorited first
+	savedSortViews     = "views"     // Most viewed first
+)
+
+// parseSavedSort validates the ?sort= option of the saved lesson list
+func parseSavedSort(r *http.Request) (string, error) {
+	switch mode := r.URL.Query().Get("sort"); mode {
+	case "":
+		return savedSortCreated, nil
+	case savedSortCreated, savedSortRecent, savedSortFavorites, savedSortViews:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid sort %q: use recent, favorites or views", mode)
+	}
+}
+
+// compareTimes returns 1 if a is later than b, -1 if earlier and 0 if equal; unset times are earliest
+func compareTimes(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return a.Compare(*b)
+}
+
+// sortSavedLessons orders saved lessons by a sort mode; ties fall back to newest saved first
+func sortSavedLessons(lessons []*SavedLesson, mode string) {
+	sort.SliceStable(lessons, func(i, j int) bool {
+		a, b := lessons[i], lessons[j]
+		switch mode {
+		case savedSortRecent:
+			if c := compareTimes(a.LastViewedAt, b.LastViewedAt); c != 0 {
+				return c > 0
+			}
+		case savedSortFavorites:
+			if a.Favorite != b.Favorite {
+				return a.Favorite
+			}
+			if c := compareTimes(a.FavoritedAt, b.FavoritedAt); c != 0 {
+				return c > 0
+			}
+		case savedSortViews:
+			if a.ViewCount != b.ViewCount {
+				return a.ViewCount > b.ViewCount
+			}
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+}
+
+// recordView counts a view of a saved lesson; the caller must hold o.mu
+func (l *SavedLesson) recordView(at time.Time) {
+	l.ViewCount++
+	l.LastViewedAt = &at
+}
+
+// favoriteSavedLessonHandler handles PUT and DELETE /api/saved/{userID}/{id}/favorite
+func (o *Orchestrator) favoriteSavedLessonHandler(favorite bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := chi.URLParam(r, "userID")
+		savedID := chi.URLParam(r, "id")
+		w.Header().Set("Content-Type", "application/json")
+
+		o.mu.Lock()
+		savedLesson, exists := o.savedLessons[savedID]
+		if !exists || savedLesson.UserID != userID || savedLesson.isDeleted() {
+			o.mu.Unlock()
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Saved lesson not found",
+				"message": "Saved lesson not found",
+			})
+			return
+		}
+		if savedLesson.Favorite != favorite {
+			savedLesson.Favorite = favorite
+			savedLesson.FavoritedAt = nil
+			if favorite {
+				now := time.Now()
+				savedLesson.FavoritedAt = &now
+			}
+		}
+		favoritedAt := savedLesson.FavoritedAt
+		o.mu.Unlock()
+
+		o.logger.WithFields(logrus.Fields{
+			"saved_id": savedID,
+			"user_id":  userID,
+			"favorite": favorite,
+		}).Info("Saved lesson favorite updated")
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":           savedID,
+			"favorite":     favorite,
+			"favorited_at": favoritedAt,
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFavoritesTestOrchestrator creates an orchestrator with three saved lessons, the newest saved last
+func newFavoritesTestOrchestrator() (*Orchestrator, chi.Router) {
+	saved := time.Now().Add(-time.Hour)
+	o := &Orchestrator{
+		sessions: make(map[string]*Session),
+		savedLessons: map[string]*SavedLesson{
+			"l1": {ID: "l1", UserID: "u1", Topic: "Kubernetes", CreatedAt: saved},
+			"l2": {ID: "l2", UserID: "u1", Topic: "Raft", CreatedAt: saved.Add(time.Minute)},
+			"l3": {ID: "l3", UserID: "u1", Topic: "CRDTs", CreatedAt: saved.Add(2 * time.Minute)},
+		},
+		logger:  logrus.New(),
+		clients: make(map[string][]chan SSEEvent),
+	}
+
+	r := chi.NewRouter()
+	r.Get("/api/saved/{userID}", o.getSavedLessonsHandler)
+	r.Get("/api/saved/{userID}/{id}", o.getSavedLessonHandler)
+	r.Put("/api/saved/{userID}/{id}/favorite", o.favoriteSavedLessonHandler(true))
+	r.Delete("/api/saved/{userID}/{id}/favorite", o.favoriteSavedLessonHandler(false))
+	return o, r
+}
+
+// savedLessonIDs lists the IDs of a user's saved lessons in the order the list API returns them
+func savedLessonIDs(t *testing.T, router http.Handler, query string) []string {
+	w := serve(router, "GET", "/api/saved/u1"+query)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listing struct {
+		Lessons []SavedLesson `json:"lessons"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listing))
+	ids := make([]string, 0, len(listing.Lessons))
+	for _, lesson := range listing.Lessons {
+		ids = append(ids, lesson.ID)
+	}
+	return ids
+}
+
+// TestFavoriteSavedLesson tests favoriting and unfavoriting a saved lesson
+func TestFavoriteSavedLesson(t *testing.T) {
+	o, router := newFavoritesTestOrchestrator()
+
+	w := serve(router, "PUT", "/api/saved/u1/l1/favorite")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, o.savedLessons["l1"].Favorite)
+	require.NotNil(t, o.savedLessons["l1"].FavoritedAt)
+
+	// Favoriting again keeps the original time
+	favoritedAt := *o.savedLessons["l1"].FavoritedAt
+	serve(router, "PUT", "/api/saved/u1/l1/favorite")
+	assert.Equal(t, favoritedAt, *o.savedLessons["l1"].FavoritedAt)
+
+	require.Equal(t, http.StatusOK, serve(router, "DELETE", "/api/saved/u1/l1/favorite").Code)
+	assert.False(t, o.savedLessons["l1"].Favorite)
+	assert.Nil(t, o.savedLessons["l1"].FavoritedAt)
+
+	assert.Equal(t, http.StatusNotFound, serve(router, "PUT", "/api/saved/u2/l1/favorite").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "PUT", "/api/saved/u1/missing/favorite").Code)
+}
+
+// TestSavedLessonViewsAndSorting tests view tracking and the list's sort options
+func TestSavedLessonViewsAndSorting(t *testing.T) {
+	o, router := newFavoritesTestOrchestrator()
+
+	assert.Equal(t, []string{"l3", "l2", "l1"}, savedLessonIDs(t, router, ""))
+
+	// Views are counted for the owner only
+	serve(router, "GET", "/api/saved/u1/l1")
+	serve(router, "GET", "/api/saved/u1/l1")
+	serve(router, "GET", "/api/saved/u2/l1")
+	assert.Equal(t, 2, o.savedLessons["l1"].ViewCount)
+	require.NotNil(t, o.savedLessons["l1"].LastViewedAt)
+	time.Sleep(time.Millisecond)
+	serve(router, "GET", "/api/saved/u1/l2")
+
+	assert.Equal(t, []string{"l2", "l1", "l3"}, savedLessonIDs(t, router, "?sort=recent"))
+	assert.Equal(t, []string{"l1", "l2", "l3"}, savedLessonIDs(t, router, "?sort=views"))
+
+	serve(router, "PUT", "/api/saved/u1/l2/favorite")
+	time.Sleep(time.Millisecond)
+	serve(router, "PUT", "/api/saved/u1/l1/favorite")
+	assert.Equal(t, []string{"l1", "l2", "l3"}, savedLessonIDs(t, router, "?sort=favorites"))
+
+	assert.Equal(t, http.StatusBadRequest, serve(router, "GET", "/api/saved/u1?sort=popular").Code)
+}
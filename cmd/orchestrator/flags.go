@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/flags"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// newFlagService creates the feature flag service, loading the stored flag set if storage is available
+func newFlagService(store flags.Store) *flags.Service {
+	service := flags.NewService(store)
+	if err := service.Load(context.Background()); err != nil {
+		logrus.WithError(err).Warn("Failed to load feature flags, continuing with no flags")
+	}
+	return service
+}
+
+// sessionEvalContext returns the flag evaluation context for a session, preferring the request's identity headers
+func sessionEvalContext(r *http.Request, session *Session) flags.EvalContext {
+	ec := flags.EvalContextFromRequest(r)
+	if ec.UserID == "" {
+		ec.UserID, _ = session.Metadata["user_id"].(string)
+	}
+	if ec.OrgID == "" {
+		ec.OrgID, _ = session.Metadata["org_id"].(string)
+	}
+	return ec
+}
+
+// snapshotSessionFlags records the flags enabled for a session when it is created,
+// so every run of the session sees the same features even if a rollout changes meanwhile
+func (o *Orchestrator) snapshotSessionFlags(r *http.Request, session *Session) {
+	if o.flagService == nil {
+		return
+	}
+
+	var enabled []string
+	for key, on := range o.flagService.EvaluateAll(r.Context(), sessionEvalContext(r, session)) {
+		if on {
+			enabled = append(enabled, key)
+		}
+	}
+	if len(enabled) == 0 {
+		return
+	}
+	sort.Strings(enabled)
+	session.Metadata["feature_flags"] = enabled
+}
+
+// sessionFlags returns the flags enabled for a session
+func sessionFlags(session *Session) []string {
+	switch enabled := session.Metadata["feature_flags"].(type) {
+	case []string:
+		return enabled
+	case []interface{}:
+		// Sessions decoded from JSON carry generic slices
+		keys := make([]string, 0, len(enabled))
+		for _, key := range enabled {
+			if s, ok := key.(string); ok {
+				keys = append(keys, s)
+			}
+		}
+		return keys
+	}
+	return nil
+}
+
+// sessionFlagEnabled reports whether a flag was enabled for a session
+func sessionFlagEnabled(session *Session, key string) bool {
+	for _, flag := range sessionFlags(session) {
+		if flag == key {
+			return true
+		}
+	}
+	return false
+}
+
+// listFlagsHandler handles GET /api/flags
+func (o *Orchestrator) listFlagsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	list := o.flagService.List(r.Context())
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"flags": list,
+		"count": len(list),
+	})
+}
+
+// getFlagHandler handles GET /api/flags/{key}
+func (o *Orchestrator) getFlagHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	flag, exists := o.flagService.Get(r.Context(), chi.URLParam(r, "key"))
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Flag not found",
+			"message": "Flag not found",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(flag)
+}
+
+// putFlagHandler handles PUT /api/flags/{key}
+func (o *Orchestrator) putFlagHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var flag flags.Flag
+	if err := decodeJSONBody(w, r, &flag); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+	flag.Key = chi.URLParam(r, "key")
+
+	saved, err := o.flagService.Put(r.Context(), flag)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if flag.Validate() != nil {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Failed to save flag",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"flag":            saved.Key,
+		"enabled":         saved.Enabled,
+		"rollout_percent": saved.RolloutPercent,
+		"orgs":            len(saved.Orgs),
+		"users":           len(saved.Users),
+	}).Info("Feature flag saved")
+
+	json.NewEncoder(w).Encode(saved)
+}
+
+// deleteFlagHandler handles DELETE /api/flags/{key}
+func (o *Orchestrator) deleteFlagHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	key := chi.URLParam(r, "key")
+	deleted, err := o.flagService.Delete(r.Context(), key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Failed to delete flag",
+			"message": err.Error(),
+		})
+		return
+	}
+	if !deleted {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Flag not found",
+			"message": "Flag not found",
+		})
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"flag": key,
+	}).Info("Feature flag deleted")
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Flag deleted successfully",
+	})
+}
+
+// evaluateFlagsHandler handles GET /api/flags/evaluate?keys=a,b for the authenticated caller.
+// Admins may pass user_id and org_id to check how a rollout lands for someone else.
+func (o *Orchestrator) evaluateFlagsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	ec := flags.EvalContextFromRequest(r)
+	if callerIsAdmin(r) {
+		query := r.URL.Query()
+		if userID := query.Get("user_id"); userID != "" {
+			ec.UserID = userID
+		}
+		if orgID := query.Get("org_id"); orgID != "" {
+			ec.OrgID = orgID
+		}
+	}
+	decisions := make([]flags.Decision, 0)
+	if keys := r.URL.Query().Get("keys"); keys != "" {
+		for _, key := range strings.Split(keys, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				decisions = append(decisions, o.flagService.Evaluate(r.Context(), key, ec))
+			}
+		}
+	} else {
+		for _, flag := range o.flagService.List(r.Context()) {
+			decisions = append(decisions, flag.Evaluate(ec))
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"context":   ec,
+		"decisions": decisions,
+	})
+}
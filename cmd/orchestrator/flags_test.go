@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/flags"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlagsTestOrchestrator creates an orchestrator with an in-memory flag service and the flag routes
+func newFlagsTestOrchestrator() (*Orchestrator, chi.Router) {
+	o := &Orchestrator{
+		sessions:    make(map[string]*Session),
+		logger:      logrus.New(),
+		flagService: flags.NewService(nil),
+	}
+
+	r := chi.NewRouter()
+	r.Route("/api/flags", func(r chi.Router) {
+		r.Get("/", o.listFlagsHandler)
+		r.Get("/evaluate", o.evaluateFlagsHandler)
+		r.Get("/{key}", o.getFlagHandler)
+		r.Put("/{key}", o.putFlagHandler)
+		r.Delete("/{key}", o.deleteFlagHandler)
+	})
+	return o, r
+}
+
+// TestFlagHandlers tests creating, evaluating and deleting a flag through the API
+func TestFlagHandlers(t *testing.T) {
+	_, router := newFlagsTestOrchestrator()
+
+	put := func(key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("PUT", "/api/flags/"+key, strings.NewReader(body)))
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, put("critic.multi_turn", `{"enabled": true, "orgs": ["org-1"]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, put("critic.multi_turn", `{"enabled": true, "rollout_percent": 150}`).Code)
+	assert.Equal(t, http.StatusBadRequest, put("Bad%20Key", `{"enabled": true}`).Code)
+
+	assert.Equal(t, http.StatusOK, serve(router, "GET", "/api/flags/critic.multi_turn").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/flags/missing").Code)
+
+	member := withPrincipal(router, &auth.Principal{UserID: "alice", OrgID: "org-1"})
+	outsider := withPrincipal(router, &auth.Principal{UserID: "bob", OrgID: "org-2"})
+	admin := withPrincipal(router, &auth.Principal{APIKeyID: "key-admin", Scopes: []auth.Scope{auth.ScopeAdmin}})
+
+	var evaluation struct {
+		Decisions []flags.Decision `json:"decisions"`
+	}
+	require.NoError(t, json.Unmarshal(serve(member, "GET", "/api/flags/evaluate").Body.Bytes(), &evaluation))
+	require.Len(t, evaluation.Decisions, 1)
+	assert.True(t, evaluation.Decisions[0].Enabled)
+	assert.Equal(t, flags.ReasonOrgTarget, evaluation.Decisions[0].Reason)
+
+	require.NoError(t, json.Unmarshal(serve(outsider, "GET", "/api/flags/evaluate?org_id=org-1").Body.Bytes(), &evaluation))
+	require.Len(t, evaluation.Decisions, 1)
+	assert.False(t, evaluation.Decisions[0].Enabled, "non-admins cannot pick the org they are evaluated as")
+
+	require.NoError(t, json.Unmarshal(serve(admin, "GET", "/api/flags/evaluate?org_id=org-1").Body.Bytes(), &evaluation))
+	require.Len(t, evaluation.Decisions, 1)
+	assert.True(t, evaluation.Decisions[0].Enabled)
+
+	require.NoError(t, json.Unmarshal(serve(admin, "GET", "/api/flags/evaluate?org_id=org-2&keys=critic.multi_turn,missing").Body.Bytes(), &evaluation))
+	require.Len(t, evaluation.Decisions, 2)
+	assert.False(t, evaluation.Decisions[0].Enabled)
+	assert.Equal(t, flags.ReasonUnknown, evaluation.Decisions[1].Reason)
+
+	assert.Equal(t, http.StatusOK, serve(router, "DELETE", "/api/flags/critic.multi_turn").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "DELETE", "/api/flags/critic.multi_turn").Code)
+}
+
+// TestSnapshotSessionFlags tests that a session records the flags enabled for its org
+func TestSnapshotSessionFlags(t *testing.T) {
+	o, _ := newFlagsTestOrchestrator()
+	ctx := context.Background()
+	_, err := o.flagService.Put(ctx, flags.Flag{Key: "new_agent", Enabled: true, Orgs: []string{"org-1"}})
+	require.NoError(t, err)
+	_, err = o.flagService.Put(ctx, flags.Flag{Key: "everyone", Enabled: true, RolloutPercent: 100})
+	require.NoError(t, err)
+
+	session := &Session{ID: "s1", Metadata: map[string]interface{}{"org_id": "org-1"}}
+	o.snapshotSessionFlags(httptest.NewRequest("POST", "/api/sessions", nil), session)
+	assert.Equal(t, []string{"everyone", "new_agent"}, sessionFlags(session))
+	assert.True(t, sessionFlagEnabled(session, "new_agent"))
+
+	other := &Session{ID: "s2", Metadata: map[string]interface{}{"org_id": "org-2"}}
+	o.snapshotSessionFlags(httptest.NewRequest("POST", "/api/sessions", nil), other)
+	assert.False(t, sessionFlagEnabled(other, "new_agent"))
+	assert.True(t, sessionFlagEnabled(other, "everyone"))
+
+	// Flags survive a JSON round trip of the session metadata
+	data, err := json.Marshal(session.Metadata)
+	require.NoError(t, err)
+	decoded := &Session{}
+	require.NoError(t, json.Unmarshal(data, &decoded.Metadata))
+	assert.True(t, sessionFlagEnabled(decoded, "new_agent"))
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultLinkCheckTimeout bounds each further-reading URL check
+	defaultLinkCheckTimeout = 5 * time.Second
+	// maxFurtherReadingPrompt caps the lesson text sent with a further-reading request
+	maxFurtherReadingPrompt = 4000
+)
+
+// ReadingSuggester proposes further-reading links for a finished lesson
+type ReadingSuggester interface {
+	SuggestFurtherReading(ctx context.Context, topic, lesson string) ([]llm.ReadingLink, error)
+}
+
+// errPrivateAddress rejects link checks that would reach the orchestrator's own network
+var errPrivateAddress = errors.New("link resolves to a non-public address")
+
+// linkVerifier checks that suggested URLs resolve, so lessons only cite working references
+type linkVerifier struct {
+	client  *http.Client
+	timeout time.Duration
+}
+
+// linkVerifierFromEnv reads FURTHER_READING_TIMEOUT (e.g. "5s"); 0 disables further reading
+func linkVerifierFromEnv() *linkVerifier {
+	timeout := defaultLinkCheckTimeout
+	if v := os.Getenv("FURTHER_READING_TIMEOUT"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed < 0 {
+			logrus.WithField("value", v).Warn("Invalid FURTHER_READING_TIMEOUT, using default")
+		} else {
+			timeout = parsed
+		}
+	}
+	if timeout == 0 {
+		return nil
+	}
+	return newLinkVerifier(timeout, false)
+}
+
+// newLinkVerifier creates a verifier; unless allowPrivate is set, links resolving to loopback,
+// private or link-local addresses are treated as dead so model output cannot probe internal services
+func newLinkVerifier(timeout time.Duration, allowPrivate bool) *linkVerifier {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+	}
+	return &linkVerifier{
+		client: &http.Client{
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 5 {
+					return fmt.Errorf("too many redirects")
+				}
+				return nil
+			},
+		},
+		timeout: timeout,
+	}
+}
+
+// alive reports whether url resolves: a HEAD request, retried as a one-byte GET for servers
+// that do not support HEAD, must end in a non-error status
+func (v *linkVerifier) alive(ctx context.Context, url string) bool {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	status, err := v.check(ctx, http.MethodHead, url)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented || status == http.StatusForbidden) {
+		status, err = v.check(ctx, http.MethodGet, url)
+	}
+	return err == nil && status < http.StatusBadRequest
+}
+
+// check requests url with method and returns the final status
+func (v *linkVerifier) check(ctx context.Context, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "ExplainIQ-LinkCheck/1.0")
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// verify checks links concurrently and returns the live ones in their original order
+func (v *linkVerifier) verify(ctx context.Context, links []llm.ReadingLink) []llm.ReadingLink {
+	live := make([]bool, len(links))
+	var wg sync.WaitGroup
+	for i, link := range links {
+		if !llm.IsWebURL(link.URL) {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			live[i] = v.alive(ctx, url)
+		}(i, link.URL)
+	}
+	wg.Wait()
+
+	verified := make([]llm.ReadingLink, 0, len(links))
+	for i, link := range links {
+		if live[i] {
+			verified = append(verified, link)
+		}
+	}
+	return verified
+}
+
+// furtherReading suggests references for a finished lesson and keeps the ones that resolve.
+// It returns nil when further reading is disabled, the model is unavailable or no link survives.
+func (o *Orchestrator) furtherReading(ctx context.Context, sessionID, topic, lesson string) []llm.ReadingLink {
+	if o.readingClient == nil || o.linkVerifier == nil {
+		return nil
+	}
+	if len(lesson) > maxFurtherReadingPrompt {
+		lesson = lesson[:maxFurtherReadingPrompt]
+	}
+	suggested, err := o.readingClient.SuggestFurtherReading(ctx, topic, lesson)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"error":      err,
+		}).Warn("Failed to suggest further reading")
+		return nil
+	}
+
+	verified := o.linkVerifier.verify(ctx, suggested)
+	o.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"suggested":  len(suggested),
+		"verified":   len(verified),
+	}).Info("Verified further reading links")
+	if len(verified) == 0 {
+		return nil
+	}
+	return verified
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// staticReadingSuggester suggests a fixed list of links
+type staticReadingSuggester []llm.ReadingLink
+
+func (s staticReadingSuggester) SuggestFurtherReading(ctx context.Context, topic, lesson string) ([]llm.ReadingLink, error) {
+	return s, nil
+}
+
+// TestFurtherReadingDropsDeadLinks tests that only suggested links that resolve reach the lesson
+func TestFurtherReadingDropsDeadLinks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/spec", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/spec", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	o := &Orchestrator{
+		logger:       logrus.New(),
+		linkVerifier: newLinkVerifier(50*time.Millisecond, true),
+		readingClient: staticReadingSuggester{
+			{Title: "Spec", URL: server.URL + "/spec"},
+			{Title: "Hallucinated", URL: server.URL + "/made-up-paper"},
+			{Title: "Moved", URL: server.URL + "/moved"},
+			{Title: "Slow", URL: server.URL + "/slow"},
+			{Title: "No HEAD", URL: server.URL + "/no-head"},
+			{Title: "Script", URL: "javascript:alert(1)"},
+		},
+	}
+
+	links := o.furtherReading(context.Background(), "s1", "Heaps", "{}")
+	var titles []string
+	for _, link := range links {
+		titles = append(titles, link.Title)
+	}
+	assert.Equal(t, []string{"Spec", "Moved", "No HEAD"}, titles)
+
+	// Without a verifier no unchecked links are returned
+	o.linkVerifier = nil
+	assert.Nil(t, o.furtherReading(context.Background(), "s1", "Heaps", "{}"))
+}
+
+// TestLinkVerifierRejectsPrivateAddresses tests that suggested links cannot reach internal services
+func TestLinkVerifierRejectsPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	assert.False(t, newLinkVerifier(time.Second, false).alive(context.Background(), server.URL))
+	assert.True(t, newLinkVerifier(time.Second, true).alive(context.Background(), server.URL))
+}
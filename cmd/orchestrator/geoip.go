@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// geoIPDatabase looks up countries in a MaxMind DB file (GeoLite2-Country, GeoIP2-Country or
+// GeoIP2-City)
+type geoIPDatabase struct {
+	reader *maxminddb.Reader
+}
+
+// geoIPRecord is the part of a MaxMind DB record country lookups read
+type geoIPRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// openGeoIPDatabase opens a MaxMind DB file
+func openGeoIPDatabase(path string) (*geoIPDatabase, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &geoIPDatabase{reader: reader}, nil
+}
+
+// newGeoIPDatabase reads a MaxMind DB from its contents
+func newGeoIPDatabase(data []byte) (*geoIPDatabase, error) {
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return &geoIPDatabase{reader: reader}, nil
+}
+
+// country returns the ISO 3166-1 alpha-2 code of the country ip is in, falling back to the
+// country it is registered in; empty if unknown
+func (db *geoIPDatabase) country(ip net.IP) (string, error) {
+	var record geoIPRecord
+	if err := db.reader.Lookup(ip, &record); err != nil {
+		return "", err
+	}
+	code := record.Country.ISOCode
+	if code == "" {
+		code = record.RegisteredCountry.ISOCode
+	}
+	return strings.ToUpper(code), nil
+}
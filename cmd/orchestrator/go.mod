@@ -5,21 +5,36 @@ go 1.24.4
 toolchain go1.24.10
 
 require (
+	cloud.google.com/go/compute/metadata v0.9.0
 	github.com/InnoFusionTech/ExplainIQ/internal/adk v0.0.0-00010101000000-000000000000
+	github.com/InnoFusionTech/ExplainIQ/internal/agents v0.0.0-00010101000000-000000000000
 	github.com/InnoFusionTech/ExplainIQ/internal/auth v0.0.0-00010101000000-000000000000
 	github.com/InnoFusionTech/ExplainIQ/internal/brainprint v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/cache v0.0.0-00010101000000-000000000000
 	github.com/InnoFusionTech/ExplainIQ/internal/cost_tracker v0.0.0-00010101000000-000000000000
 	github.com/InnoFusionTech/ExplainIQ/internal/elastic v0.0.0-00010101000000-000000000000
+	github.com/InnoFusionTech/ExplainIQ/internal/flags v0.0.0-00010101000000-000000000000
 	github.com/InnoFusionTech/ExplainIQ/internal/llm v0.0.0-00010101000000-000000000000
+	github.com/InnoFusionTech/ExplainIQ/internal/tokens v0.0.0-00010101000000-000000000000
+	github.com/InnoFusionTech/ExplainIQ/internal/notify v0.0.0-00010101000000-000000000000
 	github.com/InnoFusionTech/ExplainIQ/internal/quota v0.0.0-00010101000000-000000000000
 	github.com/InnoFusionTech/ExplainIQ/internal/rate_limiter v0.0.0-00010101000000-000000000000
+	github.com/InnoFusionTech/ExplainIQ/internal/retrieval v0.0.0-00010101000000-000000000000
 	github.com/InnoFusionTech/ExplainIQ/internal/storage v0.0.0-00010101000000-000000000000
+	github.com/InnoFusionTech/ExplainIQ/pkg/explainiqtest v0.0.0-00010101000000-000000000000
+	github.com/aws/aws-sdk-go-v2 v1.36.5
+	github.com/aws/aws-sdk-go-v2/config v1.29.17
+	github.com/aws/aws-sdk-go-v2/credentials v1.17.70
+	github.com/aws/aws-sdk-go-v2/service/s3 v1.79.3
+	github.com/aws/smithy-go v1.22.4
 	github.com/gin-gonic/gin v1.11.0
 	github.com/go-chi/chi/v5 v5.0.10
 	github.com/go-chi/cors v1.2.1
 	github.com/google/uuid v1.6.0
+	github.com/oschwald/maxminddb-golang v1.13.1
 	github.com/sirupsen/logrus v1.9.3
 	github.com/stretchr/testify v1.11.1
+	google.golang.org/api v0.252.0
 )
 
 require (
@@ -27,9 +42,21 @@ require (
 	cloud.google.com/go/ai v0.7.0 // indirect
 	cloud.google.com/go/auth v0.17.0 // indirect
 	cloud.google.com/go/auth/oauth2adapt v0.2.8 // indirect
-	cloud.google.com/go/compute/metadata v0.9.0 // indirect
 	cloud.google.com/go/firestore v1.19.0 // indirect
 	cloud.google.com/go/longrunning v0.7.0 // indirect
+	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.6.10 // indirect
+	github.com/aws/aws-sdk-go-v2/feature/ec2/imds v1.16.32 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/configsources v1.3.36 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.6.36 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/ini v1.8.3 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/v4a v1.3.34 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/accept-encoding v1.12.4 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/checksum v1.7.1 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/presigned-url v1.12.17 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/s3shared v1.18.15 // indirect
+	github.com/aws/aws-sdk-go-v2/service/sso v1.25.5 // indirect
+	github.com/aws/aws-sdk-go-v2/service/ssooidc v1.30.3 // indirect
+	github.com/aws/aws-sdk-go-v2/service/sts v1.34.0 // indirect
 	github.com/bytedance/sonic v1.14.0 // indirect
 	github.com/bytedance/sonic/loader v0.3.0 // indirect
 	github.com/cloudwego/base64x v0.1.6 // indirect
@@ -56,6 +83,7 @@ require (
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
+	github.com/lib/pq v1.10.9 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
@@ -83,7 +111,6 @@ require (
 	golang.org/x/text v0.30.0 // indirect
 	golang.org/x/time v0.14.0 // indirect
 	golang.org/x/tools v0.37.0 // indirect
-	google.golang.org/api v0.252.0 // indirect
 	google.golang.org/genproto v0.0.0-20251014184007-4626949a642f // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20251014184007-4626949a642f // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20251014184007-4626949a642f // indirect
@@ -96,18 +123,32 @@ replace github.com/InnoFusionTech/ExplainIQ => ../../
 
 replace github.com/InnoFusionTech/ExplainIQ/internal/adk => ../../internal/adk
 
+replace github.com/InnoFusionTech/ExplainIQ/internal/agents => ../../internal/agents
+
 replace github.com/InnoFusionTech/ExplainIQ/internal/auth => ../../internal/auth
 
 replace github.com/InnoFusionTech/ExplainIQ/internal/brainprint => ../../internal/brainprint
 
+replace github.com/InnoFusionTech/ExplainIQ/internal/cache => ../../internal/cache
+
 replace github.com/InnoFusionTech/ExplainIQ/internal/cost_tracker => ../../internal/cost_tracker
 
 replace github.com/InnoFusionTech/ExplainIQ/internal/elastic => ../../internal/elastic
 
+replace github.com/InnoFusionTech/ExplainIQ/internal/flags => ../../internal/flags
+
 replace github.com/InnoFusionTech/ExplainIQ/internal/llm => ../../internal/llm
 
+replace github.com/InnoFusionTech/ExplainIQ/internal/tokens => ../../internal/tokens
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/notify => ../../internal/notify
+
 replace github.com/InnoFusionTech/ExplainIQ/internal/quota => ../../internal/quota
 
 replace github.com/InnoFusionTech/ExplainIQ/internal/rate_limiter => ../../internal/rate_limiter
 
+replace github.com/InnoFusionTech/ExplainIQ/internal/retrieval => ../../internal/retrieval
+
 replace github.com/InnoFusionTech/ExplainIQ/internal/storage => ../../internal/storage
+
+replace github.com/InnoFusionTech/ExplainIQ/pkg/explainiqtest => ../../pkg/explainiqtest
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxGoalsPerUser caps how many topics a user may track at once
+	maxGoalsPerUser = 50
+	// maxGoalTopicLength caps the length of a goal's topic
+	maxGoalTopicLength = 200
+	// maxGoalHistory is how many recent pieces of evidence a goal keeps
+	maxGoalHistory = 20
+	// maxGoalRecommendations is how many next topics GET /api/goals recommends
+	maxGoalRecommendations = 3
+
+	// masteryThreshold is the estimate at which a goal counts as mastered
+	masteryThreshold = 0.8
+	// Prior pseudo-counts of the Beta mastery estimate; a new goal starts at 0.25
+	masteryPriorSuccesses = 1.0
+	masteryPriorFailures  = 3.0
+)
+
+// Kinds of evidence that update a mastery estimate
+const (
+	EvidenceSession = "session" // A completed lesson on the topic
+	EvidenceQuiz    = "quiz"    // A quiz result, scored out of max_score
+	EvidenceReview  = "review"  // A spaced-repetition review grade, 0-5 by default
+)
+
+// evidenceWeights is how many observations one piece of evidence counts as.
+// Finishing a lesson says little about mastery; a quiz says the most.
+var evidenceWeights = map[string]float64{
+	EvidenceSession: 1,
+	EvidenceQuiz:    3,
+	EvidenceReview:  2,
+}
+
+// sessionEvidenceScore is the outcome credited for completing a lesson
+const sessionEvidenceScore = 0.7
+
+// defaultReviewMaxGrade is the top review grade when max_score is not given
+const defaultReviewMaxGrade = 5
+
+// GoalEvidence is one observation that updated a mastery estimate
+type GoalEvidence struct {
+	Kind       string    `json:"kind"`
+	Score      float64   `json:"score"` // Outcome from 0 to 1
+	SessionID  string    `json:"session_id,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// LearningGoal is a topic a user wants to master and the running estimate of their mastery.
+// Mastery is the mean of a Beta distribution whose pseudo-counts grow with weighted evidence.
+type LearningGoal struct {
+	ID         string         `json:"id"`
+	UserID     string         `json:"user_id"`
+	Topic      string         `json:"topic"`
+	Mastery    float64        `json:"mastery"`    // Estimated probability of mastery, 0 to 1
+	Confidence float64        `json:"confidence"` // Grows from 0 towards 1 as evidence accumulates
+	Mastered   bool           `json:"mastered"`
+	Evidence   int            `json:"evidence_count"`
+	History    []GoalEvidence `json:"history,omitempty"` // Most recent evidence, newest last
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	MasteredAt *time.Time     `json:"mastered_at,omitempty"`
+
+	successes float64 // Beta pseudo-counts, including the prior
+	failures  float64
+}
+
+// GoalRecommendation suggests a goal to study next
+type GoalRecommendation struct {
+	GoalID  string  `json:"goal_id"`
+	Topic   string  `json:"topic"`
+	Mastery float64 `json:"mastery"`
+	Reason  string  `json:"reason"`
+}
+
+// newLearningGoal creates a goal with the prior mastery estimate
+func newLearningGoal(userID, topic string, now time.Time) *LearningGoal {
+	goal := &LearningGoal{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Topic:     topic,
+		CreatedAt: now,
+		UpdatedAt: now,
+		successes: masteryPriorSuccesses,
+		failures:  masteryPriorFailures,
+	}
+	goal.estimate()
+	return goal
+}
+
+// estimate recomputes the goal's mastery and confidence from its pseudo-counts
+func (g *LearningGoal) estimate() {
+	total := g.successes + g.failures
+	g.Mastery = g.successes / total
+	observed := total - masteryPriorSuccesses - masteryPriorFailures
+	g.Confidence = observed / (observed + masteryPriorSuccesses + masteryPriorFailures)
+}
+
+// observe folds one piece of evidence into the goal's estimate
+func (g *LearningGoal) observe(evidence GoalEvidence) {
+	weight := evidenceWeights[evidence.Kind]
+	g.successes += weight * evidence.Score
+	g.failures += weight * (1 - evidence.Score)
+	g.estimate()
+
+	g.Evidence++
+	g.History = append(g.History, evidence)
+	if len(g.History) > maxGoalHistory {
+		g.History = g.History[len(g.History)-maxGoalHistory:]
+	}
+	g.UpdatedAt = evidence.RecordedAt
+
+	// Mastery can be lost again after poor results
+	g.Mastered = g.Mastery >= masteryThreshold
+	if g.Mastered && g.MasteredAt == nil {
+		at := evidence.RecordedAt
+		g.MasteredAt = &at
+	} else if !g.Mastered {
+		g.MasteredAt = nil
+	}
+}
+
+// normalizeGoalTopic lowercases a topic and collapses its whitespace for matching
+func normalizeGoalTopic(topic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(topic)), " ")
+}
+
+// goalMatchesTopic reports whether evidence about topic counts towards the goal.
+// The goal's words must appear together in the topic, so "recursion" matches "Recursion in Python".
+func goalMatchesTopic(goal *LearningGoal, topic string) bool {
+	goalTopic := normalizeGoalTopic(goal.Topic)
+	return goalTopic != "" && strings.Contains(" "+normalizeGoalTopic(topic)+" ", " "+goalTopic+" ")
+}
+
+// recordGoalEvidence updates every goal of the user that the topic matches, returning copies of them
+func (o *Orchestrator) recordGoalEvidence(userID, topic string, evidence GoalEvidence) []LearningGoal {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	updated := make([]LearningGoal, 0)
+	for _, goal := range o.goals[userID] {
+		if goalMatchesTopic(goal, topic) {
+			goal.observe(evidence)
+			updated = append(updated, copyGoal(goal))
+		}
+	}
+	return updated
+}
+
+// trackSessionGoals credits a completed session to the goals of the user who ran it
+func (o *Orchestrator) trackSessionGoals(session *Session) {
+	userID, _ := session.Metadata["user_id"].(string)
+	if userID == "" {
+		return
+	}
+	updated := o.recordGoalEvidence(userID, session.Topic, GoalEvidence{
+		Kind:       EvidenceSession,
+		Score:      sessionEvidenceScore,
+		SessionID:  session.ID,
+		RecordedAt: time.Now(),
+	})
+	if len(updated) > 0 {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": session.ID,
+			"user_id":    userID,
+			"goals":      len(updated),
+		}).Debug("Updated learning goals from completed session")
+	}
+}
+
+// copyGoal copies a goal so it can be encoded without holding the lock
+func copyGoal(goal *LearningGoal) LearningGoal {
+	c := *goal
+	c.History = append([]GoalEvidence(nil), goal.History...)
+	return c
+}
+
+// recommendGoals picks the unmastered goals to study next: the weakest first, oldest first on ties
+func recommendGoals(goals []LearningGoal) []GoalRecommendation {
+	candidates := make([]LearningGoal, 0, len(goals))
+	for _, goal := range goals {
+		if !goal.Mastered {
+			candidates = append(candidates, goal)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Mastery != candidates[j].Mastery {
+			return candidates[i].Mastery < candidates[j].Mastery
+		}
+		return candidates[i].CreatedAt.Before(candidates[j].CreatedAt)
+	})
+	if len(candidates) > maxGoalRecommendations {
+		candidates = candidates[:maxGoalRecommendations]
+	}
+
+	recommendations := make([]GoalRecommendation, 0, len(candidates))
+	for _, goal := range candidates {
+		reason := "Keep practicing to reach mastery"
+		switch {
+		case goal.Evidence == 0:
+			reason = "Not started yet"
+		case goal.Mastery >= masteryThreshold-0.15:
+			reason = "Close to mastery; a quiz could confirm it"
+		}
+		recommendations = append(recommendations, GoalRecommendation{
+			GoalID:  goal.ID,
+			Topic:   goal.Topic,
+			Mastery: goal.Mastery,
+			Reason:  reason,
+		})
+	}
+	return recommendations
+}
+
+// canManageGoals reports whether the caller may read or change a user's learning goals.
+// Admins manage any user's goals; interactive users manage their own. Anonymous callers manage none.
+func (o *Orchestrator) canManageGoals(r *http.Request, userID string) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return false
+	}
+	if principal.HasScope(auth.ScopeAdmin) {
+		return true
+	}
+	return principal.Method == auth.MethodJWT && userID == principal.UserID
+}
+
+// goalsUser resolves and authorizes the user a goals request addresses, writing an error if it cannot
+func (o *Orchestrator) goalsUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := chi.URLParam(r, "userID")
+	w.Header().Set("Content-Type", "application/json")
+	if !o.canManageGoals(r, userID) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Forbidden",
+			"message": "Not allowed to access this user's goals",
+		})
+		return "", false
+	}
+	return userID, true
+}
+
+// getGoalsHandler handles GET /api/goals/{userID}
+func (o *Orchestrator) getGoalsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := o.goalsUser(w, r)
+	if !ok {
+		return
+	}
+
+	o.mu.RLock()
+	goals := make([]LearningGoal, 0, len(o.goals[userID]))
+	for _, goal := range o.goals[userID] {
+		goals = append(goals, copyGoal(goal))
+	}
+	o.mu.RUnlock()
+	sort.Slice(goals, func(i, j int) bool {
+		return goals[i].CreatedAt.Before(goals[j].CreatedAt)
+	})
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":         userID,
+		"goals":           goals,
+		"recommendations": recommendGoals(goals),
+	})
+}
+
+// createGoalHandler handles POST /api/goals/{userID}
+// Declaring a topic the user already tracks returns the existing goal.
+func (o *Orchestrator) createGoalHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := o.goalsUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Topic string `json:"topic"`
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	topic := strings.Join(strings.Fields(req.Topic), " ")
+	if topic == "" || len(topic) > maxGoalTopicLength {
+		writeJSONError(w, http.StatusBadRequest, "Invalid topic", "Topic must be 1 to 200 characters")
+		return
+	}
+
+	o.mu.Lock()
+	for _, goal := range o.goals[userID] {
+		if normalizeGoalTopic(goal.Topic) == normalizeGoalTopic(topic) {
+			existing := copyGoal(goal)
+			o.mu.Unlock()
+			json.NewEncoder(w).Encode(existing)
+			return
+		}
+	}
+	if len(o.goals[userID]) >= maxGoalsPerUser {
+		o.mu.Unlock()
+		writeJSONError(w, http.StatusConflict, "Too many goals", "Remove a goal before adding another")
+		return
+	}
+	goal := newLearningGoal(userID, topic, time.Now())
+	if o.goals == nil {
+		o.goals = make(map[string]map[string]*LearningGoal)
+	}
+	if o.goals[userID] == nil {
+		o.goals[userID] = make(map[string]*LearningGoal)
+	}
+	o.goals[userID][goal.ID] = goal
+	created := copyGoal(goal)
+	o.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"goal_id": goal.ID,
+		"topic":   topic,
+	}).Info("Learning goal created")
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// deleteGoalHandler handles DELETE /api/goals/{userID}/{goalID}
+func (o *Orchestrator) deleteGoalHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := o.goalsUser(w, r)
+	if !ok {
+		return
+	}
+
+	goalID := chi.URLParam(r, "goalID")
+	o.mu.Lock()
+	_, exists := o.goals[userID][goalID]
+	delete(o.goals[userID], goalID)
+	o.mu.Unlock()
+
+	if !exists {
+		writeJSONError(w, http.StatusNotFound, "Not found", "Goal not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// recordGoalEvidenceHandler handles POST /api/goals/{userID}/evidence
+// It records a quiz result or review grade against the goals matching the topic,
+// or the topic of session_id when no topic is given.
+func (o *Orchestrator) recordGoalEvidenceHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := o.goalsUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Kind      string  `json:"kind"` // quiz or review
+		Topic     string  `json:"topic,omitempty"`
+		SessionID string  `json:"session_id,omitempty"`
+		Score     float64 `json:"score"`
+		MaxScore  float64 `json:"max_score,omitempty"` // Required for quizzes; reviews default to 5
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	switch req.Kind {
+	case EvidenceQuiz:
+	case EvidenceReview:
+		if req.MaxScore == 0 {
+			req.MaxScore = defaultReviewMaxGrade
+		}
+	default:
+		writeJSONError(w, http.StatusBadRequest, "Invalid kind", "Kind must be quiz or review")
+		return
+	}
+	if req.MaxScore <= 0 || req.Score < 0 || req.Score > req.MaxScore {
+		writeJSONError(w, http.StatusBadRequest, "Invalid score", "Score must be between 0 and a positive max_score")
+		return
+	}
+
+	topic := req.Topic
+	if topic == "" && req.SessionID != "" {
+		o.mu.RLock()
+		if session, exists := o.sessions[req.SessionID]; exists {
+			topic = session.Topic
+		}
+		o.mu.RUnlock()
+	}
+	if strings.TrimSpace(topic) == "" {
+		writeJSONError(w, http.StatusBadRequest, "Invalid topic", "A topic or a known session_id is required")
+		return
+	}
+
+	if req.Kind == EvidenceQuiz {
+		o.recordBrainPrintQuiz(r.Context(), userID, req.SessionID, req.Score/req.MaxScore)
+	}
+
+	updated := o.recordGoalEvidence(userID, topic, GoalEvidence{
+		Kind:       req.Kind,
+		Score:      req.Score / req.MaxScore,
+		SessionID:  req.SessionID,
+		RecordedAt: time.Now(),
+	})
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": userID,
+		"updated": updated,
+	})
+}
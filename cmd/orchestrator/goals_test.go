@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGoalsTestRouter serves the goals API as the given principal
+func newGoalsTestRouter(o *Orchestrator, principal *auth.Principal) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/api/goals/{userID}", func(r chi.Router) {
+		r.Get("/", o.getGoalsHandler)
+		r.Post("/", o.createGoalHandler)
+		r.Post("/evidence", o.recordGoalEvidenceHandler)
+		r.Delete("/{goalID}", o.deleteGoalHandler)
+	})
+	return withPrincipal(r, principal)
+}
+
+// TestLearningGoalMastery tests that weighted evidence moves the estimate and sets mastery
+func TestLearningGoalMastery(t *testing.T) {
+	now := time.Now()
+	goal := newLearningGoal("u1", "Recursion", now)
+	assert.Equal(t, 0.25, goal.Mastery)
+	assert.Zero(t, goal.Confidence)
+
+	goal.observe(GoalEvidence{Kind: EvidenceSession, Score: sessionEvidenceScore, RecordedAt: now})
+	afterSession := goal.Mastery
+	assert.Greater(t, afterSession, 0.25)
+
+	for i := 0; i < 4; i++ {
+		goal.observe(GoalEvidence{Kind: EvidenceQuiz, Score: 1, RecordedAt: now})
+	}
+	assert.True(t, goal.Mastered)
+	assert.NotNil(t, goal.MasteredAt)
+	assert.Greater(t, goal.Confidence, 0.7)
+
+	// Failed reviews can lose mastery again
+	goal.observe(GoalEvidence{Kind: EvidenceReview, Score: 0, RecordedAt: now})
+	goal.observe(GoalEvidence{Kind: EvidenceReview, Score: 0, RecordedAt: now})
+	assert.False(t, goal.Mastered)
+	assert.Nil(t, goal.MasteredAt)
+	assert.Equal(t, 7, goal.Evidence)
+
+	assert.True(t, goalMatchesTopic(goal, "Recursion in  Python"))
+	assert.False(t, goalMatchesTopic(newLearningGoal("u1", "go", now), "Algorithms"))
+}
+
+// TestGoalsAPI tests declaring goals, recording evidence and the recommended next topics
+func TestGoalsAPI(t *testing.T) {
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"s1": {ID: "s1", Topic: "Binary Search Trees", Metadata: map[string]interface{}{"user_id": "u1"}},
+		},
+		logger: logrus.New(),
+	}
+	router := newGoalsTestRouter(o, &auth.Principal{UserID: "u1", Method: auth.MethodJWT})
+
+	for _, topic := range []string{"Binary search trees", "Dynamic programming", "Graphs"} {
+		require.Equal(t, http.StatusCreated, serveWithKey(router, "POST", "/api/goals/u1", "", `{"topic": "`+topic+`"}`).Code)
+	}
+	assert.Equal(t, http.StatusOK, serveWithKey(router, "POST", "/api/goals/u1", "", `{"topic": "graphs"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(router, "POST", "/api/goals/u1", "", `{"topic": " "}`).Code)
+
+	// Completed sessions, quizzes and reviews update the matching goal
+	o.trackSessionGoals(o.sessions["s1"])
+	w := serveWithKey(router, "POST", "/api/goals/u1/evidence", "", `{"kind": "quiz", "session_id": "s1", "score": 9, "max_score": 10}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = serveWithKey(router, "POST", "/api/goals/u1/evidence", "", `{"kind": "review", "topic": "dynamic programming", "score": 1}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(router, "POST", "/api/goals/u1/evidence", "", `{"kind": "quiz", "topic": "graphs", "score": 3}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(router, "POST", "/api/goals/u1/evidence", "", `{"kind": "guess", "topic": "graphs"}`).Code)
+
+	w = serve(router, "GET", "/api/goals/u1")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Goals           []LearningGoal       `json:"goals"`
+		Recommendations []GoalRecommendation `json:"recommendations"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Goals, 3)
+	assert.Equal(t, 2, body.Goals[0].Evidence)
+	assert.Greater(t, body.Goals[0].Mastery, body.Goals[2].Mastery)
+	require.Len(t, body.Recommendations, 3)
+	assert.Equal(t, "Dynamic programming", body.Recommendations[0].Topic)
+	assert.Equal(t, "Graphs", body.Recommendations[1].Topic)
+	assert.Equal(t, "Not started yet", body.Recommendations[1].Reason)
+	assert.Equal(t, "Binary search trees", body.Recommendations[2].Topic)
+
+	assert.Equal(t, http.StatusNoContent, serve(router, "DELETE", "/api/goals/u1/"+body.Goals[2].ID).Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "DELETE", "/api/goals/u1/"+body.Goals[2].ID).Code)
+}
+
+// TestGoalsAPIForbidden tests that users cannot read another user's goals and anonymous callers cannot read any
+func TestGoalsAPIForbidden(t *testing.T) {
+	o := &Orchestrator{logger: logrus.New(), authRequired: true}
+	user := &auth.Principal{UserID: "u1", Method: auth.MethodJWT}
+	assert.Equal(t, http.StatusOK, serve(newGoalsTestRouter(o, user), "GET", "/api/goals/u1").Code)
+	assert.Equal(t, http.StatusForbidden, serve(newGoalsTestRouter(o, user), "GET", "/api/goals/u2").Code)
+	assert.Equal(t, http.StatusForbidden, serve(newGoalsTestRouter(o, nil), "GET", "/api/goals/u1").Code)
+
+	// Anonymous callers are rejected even when authentication is optional
+	o.authRequired = false
+	assert.Equal(t, http.StatusForbidden, serve(newGoalsTestRouter(o, nil), "GET", "/api/goals/u1").Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(newGoalsTestRouter(o, nil), "POST", "/api/goals/u1", "", `{"topic": "graphs"}`).Code)
+}
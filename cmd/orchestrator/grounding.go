@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+)
+
+// groundingResultMetadata returns the result metadata marking a lesson as web-grounded, with the
+// summarizer's Google Search sources, or nil when the summary was not grounded
+func groundingResultMetadata(finalResult map[string]interface{}) map[string]interface{} {
+	summarizer, ok := finalResult["summarizer"].(map[string]string)
+	if !ok || summarizer["web_grounded"] != "true" {
+		return nil
+	}
+
+	citations := []llm.GroundingCitation{}
+	json.Unmarshal([]byte(summarizer["grounding_citations"]), &citations)
+	return map[string]interface{}{
+		"web_grounded":        true,
+		"grounding_citations": citations,
+	}
+}
+
+// sessionGroundingMode returns the grounding mode requested for a session
+func sessionGroundingMode(session *Session) string {
+	mode, _ := session.Metadata["grounding"].(string)
+	if mode == "" {
+		return llm.GroundingOff
+	}
+	return mode
+}
+
+// sessionDeterministic reports whether a session asked for reproducible output
+func sessionDeterministic(session *Session) bool {
+	deterministic, _ := session.Metadata["deterministic"].(bool)
+	return deterministic
+}
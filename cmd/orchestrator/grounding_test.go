@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateSessionWithGrounding tests that the requested grounding mode is validated and stored
+func TestCreateSessionWithGrounding(t *testing.T) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+	}
+	body, _ := json.Marshal(CreateSessionRequest{Topic: "Latest Go release", Grounding: "Auto"})
+	w := httptest.NewRecorder()
+	o.createSessionHandler(w, httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var response CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	session, exists := o.GetSession(response.ID)
+	require.True(t, exists)
+	assert.Equal(t, llm.GroundingAuto, sessionGroundingMode(session))
+
+	body, _ = json.Marshal(CreateSessionRequest{Topic: "Go", Grounding: "always"})
+	w = httptest.NewRecorder()
+	o.createSessionHandler(w, httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestCreateDeterministicSession tests that deterministic sessions ask every agent for reproducible output
+func TestCreateDeterministicSession(t *testing.T) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+	}
+	body, _ := json.Marshal(CreateSessionRequest{Topic: "Caching", Deterministic: true, Grounding: "on"})
+	w := httptest.NewRecorder()
+	o.createSessionHandler(w, httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusBadRequest, w.Code, "web results are not reproducible")
+
+	body, _ = json.Marshal(CreateSessionRequest{Topic: "Caching", Deterministic: true})
+	w = httptest.NewRecorder()
+	o.createSessionHandler(w, httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var response CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	agent := &recordingAgentClient{inputs: make(map[string]map[string]string)}
+	p := &Pipeline{
+		config:     DefaultPipelineConfig(),
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+	require.NoError(t, p.runPipeline(context.Background(), response.ID, o))
+	for _, step := range []string{"summarizer", "explainer", "visualizer", "critic"} {
+		assert.Equal(t, "true", agent.inputs[step]["deterministic"], step)
+	}
+}
+
+// TestGroundingResultMetadata tests marking results grounded by the summarizer as web-grounded
+func TestGroundingResultMetadata(t *testing.T) {
+	assert.Nil(t, groundingResultMetadata(map[string]interface{}{
+		"summarizer": map[string]string{"outline": "[]"},
+	}))
+
+	metadata := groundingResultMetadata(map[string]interface{}{
+		"summarizer": map[string]string{
+			"web_grounded":        "true",
+			"grounding_citations": `[{"uri":"https://go.dev/doc/go1.24","title":"go.dev"}]`,
+		},
+	})
+	require.NotNil(t, metadata)
+	assert.Equal(t, true, metadata["web_grounded"])
+	assert.Equal(t, []llm.GroundingCitation{{URI: "https://go.dev/doc/go1.24", Title: "go.dev"}}, metadata["grounding_citations"])
+
+	artifacts := resultArtifacts(&SessionResult{Metadata: metadata})
+	assert.Equal(t, metadata, artifacts["metadata"])
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+)
+
+// listImageStylesHandler handles GET /api/image-styles
+func (o *Orchestrator) listImageStylesHandler(w http.ResponseWriter, r *http.Request) {
+	styles := llm.ImageStyles()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"styles": styles,
+		"count":  len(styles),
+	})
+}
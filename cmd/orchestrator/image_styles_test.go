@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateSessionWithImageStyle tests that the style preset is validated, stored and passed to the visualizer only
+func TestCreateSessionWithImageStyle(t *testing.T) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+	}
+	body, _ := json.Marshal(CreateSessionRequest{Topic: "Caching", ImageStyle: "watercolor"})
+	w := httptest.NewRecorder()
+	o.createSessionHandler(w, httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	body, _ = json.Marshal(CreateSessionRequest{Topic: "Caching", ImageStyle: "Flat Infographic"})
+	w = httptest.NewRecorder()
+	o.createSessionHandler(w, httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var response CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	session, _ := o.GetSession(response.ID)
+	assert.Equal(t, "flat-infographic", session.Metadata["image_style"])
+
+	agent := &recordingAgentClient{inputs: make(map[string]map[string]string)}
+	p := &Pipeline{
+		config:     DefaultPipelineConfig(),
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+	require.NoError(t, p.runPipeline(context.Background(), response.ID, o))
+	assert.Equal(t, "flat-infographic", agent.inputs["visualizer"]["image_style"])
+	assert.Empty(t, agent.inputs["explainer"]["image_style"])
+}
+
+// TestListImageStyles tests the preset listing
+func TestListImageStyles(t *testing.T) {
+	o := &Orchestrator{logger: logrus.New()}
+	w := httptest.NewRecorder()
+	o.listImageStylesHandler(w, httptest.NewRequest("GET", "/api/image-styles", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"dark-technical"`)
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+// ipAccessRejectionLimit bounds the rejections kept for the admin audit view
+const ipAccessRejectionLimit = 1000
+
+// Reasons a request is rejected by IP access control
+const (
+	ipAccessDenied         = "denylisted"
+	ipAccessNotAllowed     = "not_allowlisted"
+	ipAccessCountryBlocked = "country_blocked"
+)
+
+// IPAccessConfig restricts who may use the public session endpoints, for institutional
+// deployments limited to a campus network or a set of countries
+type IPAccessConfig struct {
+	Allow            []*net.IPNet    `json:"-"`                 // If set, only these networks are served (IP_ALLOWLIST)
+	Deny             []*net.IPNet    `json:"-"`                 // Never served, even when allowlisted (IP_DENYLIST)
+	BlockedCountries map[string]bool `json:"blocked_countries"` // ISO 3166-1 alpha-2 codes (GEO_BLOCKED_COUNTRIES)
+	CountryHeader    string          `json:"country_header"`    // Header a trusted proxy puts the client's country in, e.g. CF-IPCountry (GEO_COUNTRY_HEADER); ignored unless the request came through TRUSTED_PROXIES
+	GeoIPDatabase    string          `json:"geoip_database"`    // MaxMind country or city database used when the header is absent (GEOIP_DB_PATH)
+}
+
+// parseNetworks parses a comma-separated list of IP addresses and CIDR networks
+func parseNetworks(spec string) ([]*net.IPNet, []string) {
+	var networks []*net.IPNet
+	var invalid []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				invalid = append(invalid, entry)
+				continue
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			invalid = append(invalid, entry)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks, invalid
+}
+
+// networksFromEnv reads a list of networks from an environment variable, skipping invalid entries
+func networksFromEnv(name string) []*net.IPNet {
+	networks, invalid := parseNetworks(os.Getenv(name))
+	for _, entry := range invalid {
+		logrus.WithFields(logrus.Fields{
+			"variable": name,
+			"value":    entry,
+		}).Warn("Invalid IP address or network, ignoring it")
+	}
+	return networks
+}
+
+// DefaultIPAccessConfig returns the IP access configuration from the environment
+// (IP_ALLOWLIST, IP_DENYLIST, GEO_BLOCKED_COUNTRIES, GEO_COUNTRY_HEADER, GEOIP_DB_PATH)
+func DefaultIPAccessConfig() IPAccessConfig {
+	config := IPAccessConfig{
+		Allow:            networksFromEnv("IP_ALLOWLIST"),
+		Deny:             networksFromEnv("IP_DENYLIST"),
+		BlockedCountries: make(map[string]bool),
+		CountryHeader:    strings.TrimSpace(os.Getenv("GEO_COUNTRY_HEADER")),
+		GeoIPDatabase:    strings.TrimSpace(os.Getenv("GEOIP_DB_PATH")),
+	}
+	for _, code := range strings.Split(os.Getenv("GEO_BLOCKED_COUNTRIES"), ",") {
+		if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+			config.BlockedCountries[code] = true
+		}
+	}
+	return config
+}
+
+// IPAccessRejection is the audit record of a rejected request
+type IPAccessRejection struct {
+	IP        string    `json:"ip"`
+	Country   string    `json:"country,omitempty"`
+	Reason    string    `json:"reason"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	RequestID string    `json:"request_id,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// ipAccessControl enforces an IPAccessConfig and keeps an audit trail of the requests it rejects
+type ipAccessControl struct {
+	config IPAccessConfig
+	geoip  *geoIPDatabase // Nil unless a database is configured
+
+	mu         sync.Mutex
+	rejections []IPAccessRejection // Oldest first, at most ipAccessRejectionLimit
+	counts     map[string]int      // Rejections by reason since startup
+}
+
+// newIPAccessControl creates access control for a configuration, or returns nil if it restricts nothing
+func newIPAccessControl(config IPAccessConfig) *ipAccessControl {
+	if len(config.Allow) == 0 && len(config.Deny) == 0 && len(config.BlockedCountries) == 0 {
+		return nil
+	}
+	control := &ipAccessControl{
+		config: config,
+		counts: make(map[string]int),
+	}
+	if len(config.BlockedCountries) > 0 {
+		if config.GeoIPDatabase != "" {
+			geoip, err := openGeoIPDatabase(config.GeoIPDatabase)
+			if err != nil {
+				logrus.WithError(err).Warn("Failed to load GEOIP_DB_PATH, countries are only known from GEO_COUNTRY_HEADER")
+			} else {
+				control.geoip = geoip
+			}
+		}
+		if control.geoip == nil && config.CountryHeader == "" {
+			logrus.Warn("GEO_BLOCKED_COUNTRIES is set without GEO_COUNTRY_HEADER or GEOIP_DB_PATH, countries cannot be blocked")
+		}
+	}
+	return control
+}
+
+// requestIP returns the client's IP. realIPMiddleware has already replaced RemoteAddr with
+// the address a trusted proxy reported, so forwarded headers are not read again here.
+func requestIP(r *http.Request) net.IP {
+	return net.ParseIP(remoteHost(r))
+}
+
+// containsIP reports whether any of the networks contains ip
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// country returns the client's country code, preferring the trusted proxy's header; empty if unknown
+func (c *ipAccessControl) country(r *http.Request, ip net.IP) string {
+	if c.config.CountryHeader != "" && viaTrustedProxy(r) {
+		if code := strings.ToUpper(strings.TrimSpace(r.Header.Get(c.config.CountryHeader))); code != "" && code != "XX" {
+			return code
+		}
+	}
+	if c.geoip != nil && ip != nil {
+		code, err := c.geoip.country(ip)
+		if err != nil {
+			logrus.WithError(err).Debug("GeoIP lookup failed")
+		}
+		return code
+	}
+	return ""
+}
+
+// check returns why a request must be rejected, or an empty reason if it may proceed.
+// Denylisted addresses are always rejected, then the allowlist and blocked countries apply.
+// Requests whose country is unknown are not blocked by country.
+func (c *ipAccessControl) check(r *http.Request) (ip net.IP, country, reason string) {
+	ip = requestIP(r)
+	if ip != nil && containsIP(c.config.Deny, ip) {
+		return ip, "", ipAccessDenied
+	}
+	if len(c.config.Allow) > 0 && (ip == nil || !containsIP(c.config.Allow, ip)) {
+		return ip, "", ipAccessNotAllowed
+	}
+	if len(c.config.BlockedCountries) > 0 {
+		country = c.country(r, ip)
+		if c.config.BlockedCountries[country] {
+			return ip, country, ipAccessCountryBlocked
+		}
+	}
+	return ip, country, ""
+}
+
+// reject records a rejected request in the audit trail
+func (c *ipAccessControl) reject(r *http.Request, ip net.IP, country, reason string) IPAccessRejection {
+	rejection := IPAccessRejection{
+		IP:        r.RemoteAddr,
+		Country:   country,
+		Reason:    reason,
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		RequestID: middleware.GetReqID(r.Context()),
+		At:        time.Now(),
+	}
+	if ip != nil {
+		rejection.IP = ip.String()
+	}
+
+	c.mu.Lock()
+	c.rejections = append(c.rejections, rejection)
+	if len(c.rejections) > ipAccessRejectionLimit {
+		c.rejections = c.rejections[len(c.rejections)-ipAccessRejectionLimit:]
+	}
+	c.counts[reason]++
+	c.mu.Unlock()
+	return rejection
+}
+
+// snapshot returns the recorded rejections, newest first, and the counts by reason
+func (c *ipAccessControl) snapshot(limit int) ([]IPAccessRejection, map[string]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rejections := make([]IPAccessRejection, 0)
+	for i := len(c.rejections) - 1; i >= 0 && (limit <= 0 || len(rejections) < limit); i-- {
+		rejections = append(rejections, c.rejections[i])
+	}
+	counts := make(map[string]int, len(c.counts))
+	for reason, n := range c.counts {
+		counts[reason] = n
+	}
+	return rejections, counts
+}
+
+// ipAccessMiddleware rejects requests from denylisted or non-allowlisted addresses and blocked
+// countries before they reach the session endpoints
+func (o *Orchestrator) ipAccessMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if o.ipAccess == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ip, country, reason := o.ipAccess.check(r)
+		if reason != "" {
+			rejection := o.ipAccess.reject(r, ip, country, reason)
+			o.logger.WithFields(logrus.Fields{
+				"audit":      "ip_access",
+				"ip":         rejection.IP,
+				"country":    rejection.Country,
+				"reason":     rejection.Reason,
+				"method":     rejection.Method,
+				"path":       rejection.Path,
+				"request_id": rejection.RequestID,
+			}).Warn("Request rejected by IP access control")
+			http.Error(w, "Access denied", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// networkStrings formats networks for display
+func networkStrings(networks []*net.IPNet) []string {
+	out := make([]string, 0, len(networks))
+	for _, network := range networks {
+		out = append(out, network.String())
+	}
+	return out
+}
+
+// ipAccessHandler handles GET /api/admin/ip-access?limit=
+// It shows the access rules in force and the audit trail of rejected requests, newest first.
+func (o *Orchestrator) ipAccessHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if o.ipAccess == nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"enabled": false})
+		return
+	}
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	countries := make([]string, 0, len(o.ipAccess.config.BlockedCountries))
+	for code := range o.ipAccess.config.BlockedCountries {
+		countries = append(countries, code)
+	}
+	sort.Strings(countries)
+	rejections, counts := o.ipAccess.snapshot(limit)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled":           true,
+		"allow":             networkStrings(o.ipAccess.config.Allow),
+		"deny":              networkStrings(o.ipAccess.config.Deny),
+		"blocked_countries": countries,
+		"country_header":    o.ipAccess.config.CountryHeader,
+		"geoip_loaded":      o.ipAccess.geoip != nil,
+		"rejected":          counts,
+		"rejections":        rejections,
+	})
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MaxMind DB layout constants used to build test databases
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// mmdbDataSeparator is the size of the zero bytes between the search tree and the data section
+const mmdbDataSeparator = 16
+
+// mmdbTestString encodes a short MaxMind DB string
+func mmdbTestString(s string) []byte {
+	return append([]byte{0x40 | byte(len(s))}, s...)
+}
+
+// testMMDB builds a one-node IPv4 MaxMind DB: addresses below 128.0.0.0 are in China, the
+// rest are registered in Germany
+func testMMDB() []byte {
+	// Data section: {"country": {"iso_code": "CN"}} and {"registered_country": {"iso_code": "de"}},
+	// the second reusing the first's "iso_code" key through a pointer
+	china := []byte{0xe1}
+	china = append(china, mmdbTestString("country")...)
+	china = append(china, 0xe1)
+	isoCodeOffset := len(china)
+	china = append(china, mmdbTestString("iso_code")...)
+	china = append(china, mmdbTestString("CN")...)
+	germany := []byte{0xe1}
+	germany = append(germany, mmdbTestString("registered_country")...)
+	germany = append(germany, 0xe1, 0x20, byte(isoCodeOffset))
+	germany = append(germany, mmdbTestString("de")...)
+
+	const nodeCount = 1
+	left := nodeCount + mmdbDataSeparator
+	right := left + len(china)
+	file := []byte{0, 0, byte(left), 0, 0, byte(right)}
+	file = append(file, make([]byte, mmdbDataSeparator)...)
+	file = append(file, china...)
+	file = append(file, germany...)
+
+	file = append(file, mmdbMetadataMarker...)
+	file = append(file, 0xe3)
+	file = append(file, mmdbTestString("node_count")...)
+	file = append(file, 0xc1, nodeCount)
+	file = append(file, mmdbTestString("record_size")...)
+	file = append(file, 0xa1, 24)
+	file = append(file, mmdbTestString("ip_version")...)
+	file = append(file, 0xa1, 4)
+	return file
+}
+
+// TestGeoIPCountry tests country lookups in a MaxMind DB
+func TestGeoIPCountry(t *testing.T) {
+	db, err := newGeoIPDatabase(testMMDB())
+	require.NoError(t, err)
+
+	country, err := db.country(net.ParseIP("10.1.2.3"))
+	require.NoError(t, err)
+	assert.Equal(t, "CN", country)
+
+	country, err = db.country(net.ParseIP("200.1.2.3"))
+	require.NoError(t, err)
+	assert.Equal(t, "DE", country)
+
+	// An IPv4 database knows nothing about IPv6 clients
+	country, _ = db.country(net.ParseIP("2001:db8::1"))
+	assert.Empty(t, country)
+
+	_, err = newGeoIPDatabase([]byte("not a database"))
+	assert.Error(t, err)
+}
+
+// testProxy is the address of the trusted proxy in IP access tests
+const testProxy = "172.16.0.1:443"
+
+// newIPAccessTestRouter serves a session endpoint behind IP access control and the audit view,
+// trusting proxies in 172.16.0.0/12
+func newIPAccessTestRouter(control *ipAccessControl) chi.Router {
+	o := &Orchestrator{logger: logrus.New(), ipAccess: control}
+	proxies, _ := parseNetworks("172.16.0.0/12")
+	r := chi.NewRouter()
+	r.Use(realIPMiddleware(proxies))
+	r.With(o.ipAccessMiddleware).Get("/api/sessions/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Get("/api/admin/ip-access", o.ipAccessHandler)
+	return r
+}
+
+// serveFrom sends a GET request from a client address with optional headers
+func serveFrom(r http.Handler, path, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestIPAccessMiddleware tests allowlists, denylists and header-based country blocking
+func TestIPAccessMiddleware(t *testing.T) {
+	allow, _ := parseNetworks("10.0.0.0/8")
+	deny, _ := parseNetworks("10.0.0.13")
+	router := newIPAccessTestRouter(newIPAccessControl(IPAccessConfig{
+		Allow:            allow,
+		Deny:             deny,
+		BlockedCountries: map[string]bool{"CN": true},
+		CountryHeader:    "CF-IPCountry",
+	}))
+
+	assert.Equal(t, http.StatusOK, serveFrom(router, "/api/sessions/", "10.0.0.5:51234", nil).Code)
+	assert.Equal(t, http.StatusOK, serveFrom(router, "/api/sessions/", testProxy, map[string]string{"X-Forwarded-For": "10.0.0.5", "CF-IPCountry": "FR"}).Code)
+	assert.Equal(t, http.StatusForbidden, serveFrom(router, "/api/sessions/", "10.0.0.13:51234", nil).Code)
+	assert.Equal(t, http.StatusForbidden, serveFrom(router, "/api/sessions/", "192.168.1.1:51234", nil).Code)
+	assert.Equal(t, http.StatusForbidden, serveFrom(router, "/api/sessions/", testProxy, map[string]string{"X-Forwarded-For": "10.0.0.6", "CF-IPCountry": "cn"}).Code)
+
+	// Forwarded and country headers are only honoured from trusted proxies
+	assert.Equal(t, http.StatusOK, serveFrom(router, "/api/sessions/", "10.0.0.7:51234", map[string]string{"CF-IPCountry": "CN"}).Code)
+	assert.Equal(t, http.StatusOK, serveFrom(router, "/api/sessions/", "10.0.0.8:51234", map[string]string{"X-Forwarded-For": "10.0.0.13", "X-Real-IP": "10.0.0.13"}).Code)
+
+	w := serveFrom(router, "/api/admin/ip-access", "192.168.1.1:51234", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var audit struct {
+		Enabled          bool                `json:"enabled"`
+		Allow            []string            `json:"allow"`
+		Deny             []string            `json:"deny"`
+		BlockedCountries []string            `json:"blocked_countries"`
+		Rejected         map[string]int      `json:"rejected"`
+		Rejections       []IPAccessRejection `json:"rejections"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &audit))
+	assert.True(t, audit.Enabled)
+	assert.Equal(t, []string{"10.0.0.0/8"}, audit.Allow)
+	assert.Equal(t, []string{"10.0.0.13/32"}, audit.Deny)
+	assert.Equal(t, []string{"CN"}, audit.BlockedCountries)
+	assert.Equal(t, map[string]int{ipAccessDenied: 1, ipAccessNotAllowed: 1, ipAccessCountryBlocked: 1}, audit.Rejected)
+	require.Len(t, audit.Rejections, 3)
+	assert.Equal(t, "10.0.0.6", audit.Rejections[0].IP)
+	assert.Equal(t, "CN", audit.Rejections[0].Country)
+	assert.Equal(t, ipAccessCountryBlocked, audit.Rejections[0].Reason)
+	assert.Equal(t, "/api/sessions/", audit.Rejections[0].Path)
+}
+
+// TestIPAccessGeoIPDatabase tests country blocking with a MaxMind DB when no country header is sent
+func TestIPAccessGeoIPDatabase(t *testing.T) {
+	db, err := newGeoIPDatabase(testMMDB())
+	require.NoError(t, err)
+	control := newIPAccessControl(IPAccessConfig{BlockedCountries: map[string]bool{"CN": true}})
+	control.geoip = db
+	router := newIPAccessTestRouter(control)
+
+	assert.Equal(t, http.StatusForbidden, serveFrom(router, "/api/sessions/", "10.1.2.3:443", nil).Code)
+	assert.Equal(t, http.StatusOK, serveFrom(router, "/api/sessions/", "200.1.2.3:443", nil).Code)
+	// Clients of unknown country are not blocked
+	assert.Equal(t, http.StatusOK, serveFrom(router, "/api/sessions/", "[2001:db8::1]:443", nil).Code)
+}
+
+// TestDefaultIPAccessConfig tests reading IP access rules from the environment
+func TestDefaultIPAccessConfig(t *testing.T) {
+	assert.Nil(t, newIPAccessControl(DefaultIPAccessConfig()))
+
+	t.Setenv("IP_ALLOWLIST", "10.0.0.0/8, 2001:db8::/32, bogus")
+	t.Setenv("IP_DENYLIST", "10.0.0.13")
+	t.Setenv("GEO_BLOCKED_COUNTRIES", "cn, ru")
+	config := DefaultIPAccessConfig()
+	assert.Len(t, config.Allow, 2)
+	assert.Len(t, config.Deny, 1)
+	assert.Equal(t, map[string]bool{"CN": true, "RU": true}, config.BlockedCountries)
+	assert.NotNil(t, newIPAccessControl(config))
+
+	// Without restrictions the middleware passes everything through
+	router := newIPAccessTestRouter(nil)
+	assert.Equal(t, http.StatusOK, serveFrom(router, "/api/sessions/", "192.168.1.1:51234", nil).Code)
+	w := serveFrom(router, "/api/admin/ip-access", "192.168.1.1:51234", nil)
+	assert.JSONEq(t, `{"enabled": false}`, w.Body.String())
+}
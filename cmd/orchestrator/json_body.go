@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+)
+
+// maxJSONBodyBytes caps API request bodies; saved lessons sent with their results are the largest
+const maxJSONBodyBytes = 4 << 20
+
+// decodeJSONBody streams a request's JSON body into v, failing on bodies larger than
+// maxJSONBodyBytes or nested deeper than adk.DefaultMaxJSONDepth
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	body := http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+	return adk.NewJSONDecoder(body, adk.JSONLimits{MaxDepth: adk.DefaultMaxJSONDepth}).Decode(v)
+}
+
+// writeJSONError writes a JSON error response with a short title and a human-readable message
+func writeJSONError(w http.ResponseWriter, status int, title, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   title,
+		"message": message,
+	})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSaveLessonRejectsUnboundedJSON tests that oversized and deeply nested save requests are rejected before decoding
+func TestSaveLessonRejectsUnboundedJSON(t *testing.T) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+	}
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		o.saveLessonHandler(w, httptest.NewRequest("POST", "/api/save", strings.NewReader(body)))
+		return w
+	}
+
+	// A well-formed request gets as far as the session lookup
+	assert.Equal(t, http.StatusNotFound, post(`{"session_id": "missing"}`).Code)
+
+	deep := `{"session_id": "s1", "tags": ` + strings.Repeat("[", adk.DefaultMaxJSONDepth) + strings.Repeat("]", adk.DefaultMaxJSONDepth) + `}`
+	w := post(deep)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), adk.ErrJSONTooDeep.Error())
+
+	huge := `{"session_id": "s1", "title": "` + strings.Repeat("x", maxJSONBodyBytes) + `"}`
+	assert.Equal(t, http.StatusBadRequest, post(huge).Code)
+}
+
+// FuzzDecodeJSONBody tests that request body decoding never accepts a value nested beyond the limit
+func FuzzDecodeJSONBody(f *testing.F) {
+	for _, seed := range []string{`{"session_id": "s1"}`, `{"tags": ["a", "b"]}`, `{"title": "[[[["}`, `[[[[`, `{"a": {"b": {"c": []}}}`} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, body string) {
+		var v interface{}
+		w := httptest.NewRecorder()
+		if err := decodeJSONBody(w, httptest.NewRequest("POST", "/", strings.NewReader(body)), &v); err != nil {
+			return
+		}
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("Failed to re-encode decoded body: %v", err)
+		}
+		if err := adk.CheckJSONDepth(encoded, adk.DefaultMaxJSONDepth); err != nil {
+			t.Fatalf("Decoded a body nested too deeply: %v", err)
+		}
+	})
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// libraryChatPassages is the number of saved-lesson passages an answer is grounded in
+	libraryChatPassages = 4
+	// libraryChatMaxQuestionChars caps the length of a library question
+	libraryChatMaxQuestionChars = 1000
+)
+
+// LibraryAssistant answers questions from passages of a user's saved lessons (e.g. llm.GeminiClient)
+type LibraryAssistant interface {
+	AnswerFromLibrary(ctx context.Context, question string, passages []llm.LibraryPassage) (string, error)
+}
+
+// LibraryChatRequest represents a question to a user's saved lessons library
+type LibraryChatRequest struct {
+	Question string `json:"question"`
+}
+
+// LibraryChatSource is a saved-lesson passage an answer was grounded in
+type LibraryChatSource struct {
+	LessonID string  `json:"lesson_id"`
+	Title    string  `json:"title"`
+	Section  string  `json:"section"`
+	Score    float64 `json:"score"` // Relevance of the passage to the question
+	Cited    bool    `json:"cited"` // True if the answer cites the passage
+}
+
+// LibraryChatResponse represents an answer from a user's saved lessons library
+type LibraryChatResponse struct {
+	UserID   string              `json:"user_id"`
+	Question string              `json:"question"`
+	Answer   string              `json:"answer"`
+	Sources  []LibraryChatSource `json:"sources"`
+}
+
+// libraryPassage is an indexed section of a saved lesson
+type libraryPassage struct {
+	section   string
+	text      string
+	embedding []float32 // nil when no embedder is configured or embedding failed
+}
+
+// indexedLesson is a saved lesson's passages as of its last update
+type indexedLesson struct {
+	userID    string
+	title     string
+	updatedAt time.Time
+	passages  []libraryPassage
+}
+
+// libraryIndex indexes the passages of saved lessons for retrieval, embedding them when an
+// embedder is configured and falling back to term overlap otherwise.
+// Lessons are indexed lazily when their owner asks a question and re-indexed after they change.
+type libraryIndex struct {
+	mu       sync.Mutex
+	embedder TextEmbedder
+	lessons  map[string]*indexedLesson // By saved lesson ID
+}
+
+// libraryMatch is a passage ranked against a question
+type libraryMatch struct {
+	lessonID string
+	title    string
+	passage  libraryPassage
+	score    float64
+}
+
+// newLibraryIndex creates an empty library index
+func newLibraryIndex(embedder TextEmbedder) *libraryIndex {
+	return &libraryIndex{embedder: embedder, lessons: make(map[string]*indexedLesson)}
+}
+
+// lessonPassages splits a saved lesson into passages: its summary and each non-empty lesson section
+func lessonPassages(lesson *SavedLesson) []libraryPassage {
+	var passages []libraryPassage
+	if lesson.Result == nil {
+		return passages
+	}
+	if summary := strings.TrimSpace(lesson.Result.Summary); summary != "" {
+		passages = append(passages, libraryPassage{section: "Summary", text: summary})
+	}
+	structured := parseLesson(lesson.Result.Lesson)
+	if structured == nil {
+		if text := strings.TrimSpace(lesson.Result.Lesson); text != "" {
+			passages = append(passages, libraryPassage{section: "Lesson", text: text})
+		}
+		return passages
+	}
+	for _, section := range llm.LessonSections {
+		if text, _ := structured.SectionText(section.ID); strings.TrimSpace(text) != "" {
+			passages = append(passages, libraryPassage{section: section.Title, text: text})
+		}
+	}
+	return passages
+}
+
+// sync brings a user's entries up to date with their saved lessons, indexing new or changed
+// lessons and dropping deleted ones
+func (x *libraryIndex) sync(ctx context.Context, userID string, lessons []*SavedLesson) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	current := make(map[string]struct{}, len(lessons))
+	var stale []*SavedLesson
+	for _, lesson := range lessons {
+		current[lesson.ID] = struct{}{}
+		if entry, ok := x.lessons[lesson.ID]; !ok || !entry.updatedAt.Equal(lesson.UpdatedAt) {
+			stale = append(stale, lesson)
+		}
+	}
+	for id, entry := range x.lessons {
+		if _, ok := current[id]; !ok && entry.userID == userID {
+			delete(x.lessons, id)
+		}
+	}
+
+	entries := make([]*indexedLesson, len(stale))
+	var texts []string
+	for i, lesson := range stale {
+		entries[i] = &indexedLesson{userID: lesson.UserID, title: lesson.Title, updatedAt: lesson.UpdatedAt, passages: lessonPassages(lesson)}
+		for _, passage := range entries[i].passages {
+			texts = append(texts, passage.text)
+		}
+	}
+	if x.embedder != nil && len(texts) > 0 {
+		embeddings, err := x.embedder.Embed(ctx, texts)
+		if err != nil || len(embeddings) != len(texts) {
+			logrus.WithFields(logrus.Fields{
+				"user_id": userID,
+				"error":   err,
+			}).Warn("Failed to embed saved lessons, falling back to term matching")
+		} else {
+			next := 0
+			for _, entry := range entries {
+				for i := range entry.passages {
+					entry.passages[i].embedding = embeddings[next]
+					next++
+				}
+			}
+		}
+	}
+	for i, lesson := range stale {
+		x.lessons[lesson.ID] = entries[i]
+	}
+}
+
+// search returns a user's passages most relevant to the question, best first.
+// Passages are compared by embedding when both sides have one and by term overlap otherwise.
+func (x *libraryIndex) search(userID, question string, questionEmbedding []float32, limit int) []libraryMatch {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	terms := libraryTerms(question)
+	var matches []libraryMatch
+	for id, entry := range x.lessons {
+		if entry.userID != userID {
+			continue
+		}
+		for _, passage := range entry.passages {
+			var score float64
+			if questionEmbedding != nil && len(passage.embedding) == len(questionEmbedding) {
+				score = cosineSimilarity(questionEmbedding, passage.embedding)
+			} else {
+				score = termOverlap(terms, libraryTerms(entry.title+" "+passage.text))
+			}
+			if score > 0 {
+				matches = append(matches, libraryMatch{lessonID: id, title: entry.title, passage: passage, score: score})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].lessonID < matches[j].lessonID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// embedQuestion embeds a question, returning nil when no embedder is configured or embedding fails
+func (x *libraryIndex) embedQuestion(ctx context.Context, question string) []float32 {
+	if x.embedder == nil {
+		return nil
+	}
+	embeddings, err := x.embedder.Embed(ctx, []string{question})
+	if err != nil || len(embeddings) != 1 {
+		return nil
+	}
+	return embeddings[0]
+}
+
+// removeUser drops a user's lessons from the index
+func (x *libraryIndex) removeUser(userID string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for id, entry := range x.lessons {
+		if entry.userID == userID {
+			delete(x.lessons, id)
+		}
+	}
+}
+
+// libraryTermPattern matches the words compared by the term-overlap fallback
+var libraryTermPattern = regexp.MustCompile(`[a-z0-9]+`)
+
+// libraryTerms returns the distinct lowercase words of a text, skipping very short ones
+func libraryTerms(text string) map[string]struct{} {
+	terms := make(map[string]struct{})
+	for _, term := range libraryTermPattern.FindAllString(strings.ToLower(text), -1) {
+		if len(term) > 2 {
+			terms[term] = struct{}{}
+		}
+	}
+	return terms
+}
+
+// termOverlap returns the fraction of the question's terms found in a passage
+func termOverlap(question, passage map[string]struct{}) float64 {
+	if len(question) == 0 {
+		return 0
+	}
+	found := 0
+	for term := range question {
+		if _, ok := passage[term]; ok {
+			found++
+		}
+	}
+	return float64(found) / float64(len(question))
+}
+
+// citationPattern matches passage citations such as [2] in an answer
+var citationPattern = regexp.MustCompile(`\[(\d+)\]`)
+
+// citedPassages returns the 1-based passage numbers an answer cites
+func citedPassages(answer string) map[int]bool {
+	cited := make(map[int]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		if n, err := strconv.Atoi(match[1]); err == nil {
+			cited[n] = true
+		}
+	}
+	return cited
+}
+
+// canChatWithLibrary reports whether the caller may ask questions of a user's saved lessons.
+// Admins may use any user's library; interactive users may use their own. Anonymous callers may use none.
+func (o *Orchestrator) canChatWithLibrary(r *http.Request, userID string) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return false
+	}
+	if principal.HasScope(auth.ScopeAdmin) {
+		return true
+	}
+	return principal.Method == auth.MethodJWT && userID == principal.UserID
+}
+
+// libraryChatHandler handles POST /api/library/{userID}/chat
+// It answers a question from the user's own saved lessons, citing the lessons the answer came from.
+func (o *Orchestrator) libraryChatHandler(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	w.Header().Set("Content-Type", "application/json")
+	if !o.canChatWithLibrary(r, userID) {
+		writeJSONError(w, http.StatusForbidden, "Forbidden", "Not allowed to access this user's library")
+		return
+	}
+
+	var req LibraryChatRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	req.Question = strings.TrimSpace(req.Question)
+	if req.Question == "" {
+		writeJSONError(w, http.StatusBadRequest, "Question is required", "Question is required")
+		return
+	}
+	if len(req.Question) > libraryChatMaxQuestionChars {
+		writeJSONError(w, http.StatusBadRequest, "Question too long", fmt.Sprintf("Question must be at most %d characters", libraryChatMaxQuestionChars))
+		return
+	}
+	if o.libraryClient == nil || o.libraryIndex == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Library chat unavailable", "Library chat is not configured")
+		return
+	}
+
+	o.mu.RLock()
+	lessons := make([]*SavedLesson, 0)
+	for _, lesson := range o.savedLessons {
+		if lesson.UserID == userID && !lesson.isDeleted() {
+			copied := *lesson
+			lessons = append(lessons, &copied)
+		}
+	}
+	o.mu.RUnlock()
+	if len(lessons) == 0 {
+		writeJSONError(w, http.StatusNotFound, "No saved lessons", "Save lessons to ask questions about them")
+		return
+	}
+
+	o.libraryIndex.sync(r.Context(), userID, lessons)
+	matches := o.libraryIndex.search(userID, req.Question, o.libraryIndex.embedQuestion(r.Context(), req.Question), libraryChatPassages)
+
+	response := LibraryChatResponse{UserID: userID, Question: req.Question, Sources: make([]LibraryChatSource, 0, len(matches))}
+	if len(matches) == 0 {
+		response.Answer = "None of your saved lessons cover this question yet."
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	passages := make([]llm.LibraryPassage, len(matches))
+	for i, match := range matches {
+		passages[i] = llm.LibraryPassage{Title: match.title, Section: match.passage.section, Text: match.passage.text}
+	}
+	answer, err := o.libraryClient.AnswerFromLibrary(r.Context(), req.Question, passages)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"user_id": userID,
+			"error":   err,
+		}).Error("Failed to answer library question")
+		writeJSONError(w, http.StatusBadGateway, "Failed to answer question", err.Error())
+		return
+	}
+
+	cited := citedPassages(answer)
+	for i, match := range matches {
+		response.Sources = append(response.Sources, LibraryChatSource{
+			LessonID: match.lessonID,
+			Title:    match.title,
+			Section:  match.passage.section,
+			Score:    match.score,
+			Cited:    cited[i+1],
+		})
+	}
+	response.Answer = answer
+
+	o.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"lessons": len(lessons),
+		"sources": len(matches),
+		"cited":   len(cited),
+	}).Info("Answered question from saved lessons")
+	json.NewEncoder(w).Encode(response)
+}
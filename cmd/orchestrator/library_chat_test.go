@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubLibraryAssistant records the passages it was asked to answer from and cites the first one
+type stubLibraryAssistant struct {
+	passages []llm.LibraryPassage
+}
+
+// AnswerFromLibrary implements LibraryAssistant
+func (s *stubLibraryAssistant) AnswerFromLibrary(ctx context.Context, question string, passages []llm.LibraryPassage) (string, error) {
+	s.passages = passages
+	return "Entries expire so stale data is dropped [1].", nil
+}
+
+// newLibraryChatOrchestrator creates an orchestrator with saved lessons on caching and on gardening,
+// returning a router that serves library chat without a principal
+func newLibraryChatOrchestrator(embedder TextEmbedder) (*Orchestrator, *stubLibraryAssistant, chi.Router) {
+	assistant := &stubLibraryAssistant{}
+	o := &Orchestrator{
+		sessions:      make(map[string]*Session),
+		savedLessons:  make(map[string]*SavedLesson),
+		logger:        logrus.New(),
+		libraryClient: assistant,
+		libraryIndex:  newLibraryIndex(embedder),
+	}
+	now := time.Now()
+	o.savedLessons["caching"] = &SavedLesson{ID: "caching", UserID: "u1", Title: "Caching", UpdatedAt: now, Result: &SessionResult{
+		Lesson:  `{"big_picture": "A cache keeps hot data close", "core_mechanism": "Cache entries expire after a TTL so stale data is dropped"}`,
+		Summary: "Caches trade memory for speed",
+	}}
+	o.savedLessons["gardening"] = &SavedLesson{ID: "gardening", UserID: "u1", Title: "Composting", UpdatedAt: now, Result: &SessionResult{
+		Lesson: `{"big_picture": "Compost turns kitchen scraps into soil"}`,
+	}}
+	o.savedLessons["other"] = &SavedLesson{ID: "other", UserID: "u2", Title: "Caching at the edge", UpdatedAt: now, Result: &SessionResult{
+		Lesson: `{"big_picture": "Edge caches expire entries too"}`,
+	}}
+
+	router := chi.NewRouter()
+	router.Post("/api/library/{userID}/chat", o.libraryChatHandler)
+	return o, assistant, router
+}
+
+// TestLibraryChat tests that questions are answered from the user's own lessons with cited sources
+func TestLibraryChat(t *testing.T) {
+	for name, embedder := range map[string]TextEmbedder{"terms": nil, "embeddings": &stubEmbedder{}} {
+		t.Run(name, func(t *testing.T) {
+			o, assistant, anonymous := newLibraryChatOrchestrator(embedder)
+			router := withPrincipal(anonymous, &auth.Principal{UserID: "u1", Method: auth.MethodJWT})
+
+			w := serveWithKey(router, "POST", "/api/library/u1/chat", "", `{"question": "Why do cache entries expire?"}`)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var response LibraryChatResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Contains(t, response.Answer, "[1]")
+			require.NotEmpty(t, response.Sources)
+			assert.Equal(t, "caching", response.Sources[0].LessonID)
+			assert.True(t, response.Sources[0].Cited)
+			for i, source := range response.Sources {
+				assert.NotEqual(t, "other", source.LessonID)
+				assert.Equal(t, i == 0, source.Cited)
+			}
+			assert.Equal(t, "Caching", assistant.passages[0].Title)
+
+			// Deleted lessons drop out of the index
+			deletedAt := time.Now()
+			o.savedLessons["caching"].DeletedAt = &deletedAt
+			o.savedLessons["gardening"].UpdatedAt = time.Now().Add(time.Second)
+			w = serveWithKey(router, "POST", "/api/library/u1/chat", "", `{"question": "Why do cache entries expire?"}`)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			for _, source := range response.Sources {
+				assert.Equal(t, "gardening", source.LessonID)
+			}
+		})
+	}
+}
+
+// TestLibraryChatErrors tests question validation, users without saved lessons and purging a user's index
+func TestLibraryChatErrors(t *testing.T) {
+	o, _, router := newLibraryChatOrchestrator(nil)
+	owner := withPrincipal(router, &auth.Principal{UserID: "u1", Method: auth.MethodJWT})
+	admin := withPrincipal(router, &auth.Principal{APIKeyID: "key-admin", Scopes: []auth.Scope{auth.ScopeAdmin}})
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(owner, "POST", "/api/library/u1/chat", "", `{"question": "  "}`).Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(admin, "POST", "/api/library/u3/chat", "", `{"question": "What is a cache?"}`).Code)
+
+	o.libraryIndex.sync(context.Background(), "u1", []*SavedLesson{o.savedLessons["caching"]})
+	assert.NotEmpty(t, o.libraryIndex.search("u1", "cache", nil, libraryChatPassages))
+	o.libraryIndex.removeUser("u1")
+	assert.Empty(t, o.libraryIndex.search("u1", "cache", nil, libraryChatPassages))
+}
+
+// TestLibraryChatAccess tests that only the library's owner or an admin can ask questions of it
+func TestLibraryChatAccess(t *testing.T) {
+	_, _, router := newLibraryChatOrchestrator(nil)
+	question := `{"question": "Why do cache entries expire?"}`
+
+	assert.Equal(t, http.StatusForbidden, serveWithKey(router, "POST", "/api/library/u1/chat", "", question).Code, "anonymous callers are rejected even when authentication is optional")
+	other := withPrincipal(router, &auth.Principal{UserID: "u2", Method: auth.MethodJWT})
+	assert.Equal(t, http.StatusForbidden, serveWithKey(other, "POST", "/api/library/u1/chat", "", question).Code)
+	key := withPrincipal(router, &auth.Principal{UserID: "u1", Method: auth.MethodAPIKey, APIKeyID: "key-1"})
+	assert.Equal(t, http.StatusForbidden, serveWithKey(key, "POST", "/api/library/u1/chat", "", question).Code)
+
+	admin := withPrincipal(router, &auth.Principal{APIKeyID: "key-admin", Scopes: []auth.Scope{auth.ScopeAdmin}})
+	assert.Equal(t, http.StatusOK, serveWithKey(admin, "POST", "/api/library/u1/chat", "", question).Code)
+}
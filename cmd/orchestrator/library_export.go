@@ -0,0 +1,594 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// libraryExportPrefix is where export archives are written in the bucket
+	libraryExportPrefix = "exports/"
+	// defaultExportLinkTTL is how long a signed download link stays valid
+	defaultExportLinkTTL = 24 * time.Hour
+	// libraryExportTimeout bounds building and uploading one export
+	libraryExportTimeout = 30 * time.Minute
+	// maxExportImageBytes caps the size of each image copied into an export
+	maxExportImageBytes = 20 << 20
+	// maxExportLinkTTL is the longest lifetime V4 signed URLs allow
+	maxExportLinkTTL = 7 * 24 * time.Hour
+)
+
+// Library export statuses
+const (
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+)
+
+// ExportStore stores export archives and issues time-limited download links
+type ExportStore interface {
+	// Upload writes an object
+	Upload(ctx context.Context, object, contentType string, data io.Reader) error
+	// SignedURL returns a download URL for an object that is valid for expiry
+	SignedURL(ctx context.Context, object string, expiry time.Duration) (string, error)
+	// Delete removes an object
+	Delete(ctx context.Context, object string) error
+}
+
+// LibraryExport tracks a bulk export of a user's saved lessons
+type LibraryExport struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Status      string     `json:"status"`
+	Total       int        `json:"total"` // Lessons in the export
+	Done        int        `json:"done"`  // Lessons written so far
+	Object      string     `json:"-"`     // Archive object name once uploaded
+	DownloadURL string     `json:"download_url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // When the download link stops working
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// finished reports whether the export has completed or failed
+func (e *LibraryExport) finished() bool {
+	return e.Status == ExportStatusCompleted || e.Status == ExportStatusFailed
+}
+
+// libraryExporter builds library export archives and tracks their progress
+type libraryExporter struct {
+	store      ExportStore
+	linkTTL    time.Duration
+	fetchImage func(ctx context.Context, url string) ([]byte, string, error)
+
+	mu      sync.Mutex
+	exports map[string]*LibraryExport
+}
+
+// newLibraryExporter creates an exporter writing archives to store
+func newLibraryExporter(store ExportStore, linkTTL time.Duration) *libraryExporter {
+	x := &libraryExporter{
+		store:   store,
+		linkTTL: linkTTL,
+		exports: make(map[string]*LibraryExport),
+	}
+	x.fetchImage = x.fetchStoredImage
+	return x
+}
+
+// libraryExporterFromEnv creates the exporter for the store selected by ARTIFACT_STORE, with the link
+// lifetime from EXPORT_LINK_TTL (e.g. "24h"). It returns nil when storage is unavailable.
+func libraryExporterFromEnv() *libraryExporter {
+	store, err := artifactStoreFromEnv(context.Background())
+	if err != nil {
+		logrus.WithError(err).Warn("Export storage not available, continuing without library exports")
+		return nil
+	}
+	if store == nil {
+		return nil
+	}
+
+	linkTTL := defaultExportLinkTTL
+	if v := os.Getenv("EXPORT_LINK_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil && ttl > 0 && ttl <= maxExportLinkTTL {
+			linkTTL = ttl
+		} else {
+			logrus.WithField("value", v).Warn("Invalid EXPORT_LINK_TTL, using default")
+		}
+	}
+	return newLibraryExporter(store, linkTTL)
+}
+
+// exportImageStore is an export store that also holds the generated lesson images
+type exportImageStore interface {
+	ExportStore
+	// Bucket returns the bucket holding the artifacts
+	Bucket() string
+	// Download reads an object
+	Download(ctx context.Context, object string) ([]byte, error)
+}
+
+// errExportImageOutsideBucket is returned for image URLs that are not objects in the artifact bucket
+var errExportImageOutsideBucket = errors.New("image is not stored in the artifact bucket")
+
+// fetchStoredImage reads an image for inclusion in an export. Only objects in the configured
+// artifact bucket are read, through the store; any other URL is skipped rather than fetched,
+// since image URLs in saved lessons are client-supplied.
+func (x *libraryExporter) fetchStoredImage(ctx context.Context, url string) ([]byte, string, error) {
+	store, ok := x.store.(exportImageStore)
+	if !ok {
+		return nil, "", errExportImageOutsideBucket
+	}
+	object, ok := bucketObjectName(store, url)
+	if !ok {
+		return nil, "", errExportImageOutsideBucket
+	}
+	data, err := store.Download(ctx, object)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxExportImageBytes {
+		return nil, "", fmt.Errorf("image exceeds %d bytes", maxExportImageBytes)
+	}
+	return data, mime.TypeByExtension(path.Ext(object)), nil
+}
+
+// snapshot returns a copy of an export for reading outside the lock
+func (x *libraryExporter) snapshot(exportID string) (LibraryExport, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	export, ok := x.exports[exportID]
+	if !ok {
+		return LibraryExport{}, false
+	}
+	return *export, true
+}
+
+// update applies a change to an export and returns the updated copy
+func (x *libraryExporter) update(exportID string, change func(export *LibraryExport)) LibraryExport {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	export := x.exports[exportID]
+	change(export)
+	return *export
+}
+
+// deleteUserExports deletes a user's export archives and forgets their exports, returning how many archives were deleted
+func (x *libraryExporter) deleteUserExports(ctx context.Context, userID string) (int, error) {
+	x.mu.Lock()
+	var objects []string
+	for id, export := range x.exports {
+		if export.UserID != userID {
+			continue
+		}
+		if export.Object != "" {
+			objects = append(objects, export.Object)
+		}
+		delete(x.exports, id)
+	}
+	x.mu.Unlock()
+
+	deleted := 0
+	for _, object := range objects {
+		if err := x.store.Delete(ctx, object); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// exportManifest describes the contents of an export archive
+type exportManifest struct {
+	UserID      string                 `json:"user_id"`
+	ExportedAt  time.Time              `json:"exported_at"`
+	LessonCount int                    `json:"lesson_count"`
+	Lessons     []exportManifestLesson `json:"lessons"`
+}
+
+// exportManifestLesson describes one lesson in an export archive
+type exportManifestLesson struct {
+	ID              string                `json:"id"`
+	SessionID       string                `json:"session_id"`
+	Title           string                `json:"title"`
+	Topic           string                `json:"topic"`
+	ExplanationType string                `json:"explanation_type,omitempty"`
+	Tags            []string              `json:"tags,omitempty"`
+	CourseID        string                `json:"course_id,omitempty"`
+	File            string                `json:"file"`
+	Images          []exportManifestImage `json:"images,omitempty"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+}
+
+// exportManifestImage describes one lesson image in an export archive
+type exportManifestImage struct {
+	URL     string `json:"url"`
+	Caption string `json:"caption,omitempty"`
+	File    string `json:"file,omitempty"`
+	Error   string `json:"error,omitempty"` // Set when the image could not be copied
+}
+
+// buildLibraryArchive writes a ZIP with one Markdown file per lesson, the lessons' images and
+// a manifest.json. progress is called after each lesson with the number written so far.
+func buildLibraryArchive(ctx context.Context, userID string, lessons []SavedLesson, fetchImage func(ctx context.Context, url string) ([]byte, string, error), progress func(done int)) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	manifest := exportManifest{
+		UserID:      userID,
+		ExportedAt:  time.Now().UTC(),
+		LessonCount: len(lessons),
+		Lessons:     make([]exportManifestLesson, 0, len(lessons)),
+	}
+
+	for i, lesson := range lessons {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		title := lesson.Title
+		if title == "" {
+			title = lesson.Topic
+		}
+		slug := llm.Slugify(title)
+		if slug == "" {
+			slug = "lesson"
+		}
+		base := fmt.Sprintf("%03d-%s", i+1, slug)
+
+		entry := exportManifestLesson{
+			ID:              lesson.ID,
+			SessionID:       lesson.SessionID,
+			Title:           title,
+			Topic:           lesson.Topic,
+			ExplanationType: lesson.ExplanationType,
+			Tags:            lesson.Tags,
+			CourseID:        lesson.CourseID,
+			File:            "lessons/" + base + ".md",
+			CreatedAt:       lesson.CreatedAt,
+			UpdatedAt:       lesson.UpdatedAt,
+		}
+
+		var markdown strings.Builder
+		if lesson.Result != nil {
+			markdown.WriteString(renderLessonMarkdown(&Session{Topic: title, Result: lesson.Result}))
+
+			urls := make([]string, 0, len(lesson.Result.Images))
+			for url := range lesson.Result.Images {
+				urls = append(urls, url)
+			}
+			sort.Strings(urls)
+
+			for n, url := range urls {
+				image := exportManifestImage{URL: url, Caption: lesson.Result.Images[url]}
+				data, contentType, err := fetchImage(ctx, url)
+				if err != nil {
+					image.Error = err.Error()
+					entry.Images = append(entry.Images, image)
+					continue
+				}
+
+				image.File = fmt.Sprintf("images/%s/%d%s", base, n+1, imageExtension(url, contentType))
+				if err := writeArchiveFile(archive, image.File, data); err != nil {
+					return nil, err
+				}
+				entry.Images = append(entry.Images, image)
+			}
+
+			if len(entry.Images) > 0 {
+				markdown.WriteString("## Images\n\n")
+				for _, image := range entry.Images {
+					if image.File != "" {
+						markdown.WriteString(fmt.Sprintf("![%s](../%s)\n\n", image.Caption, image.File))
+					}
+				}
+			}
+		} else {
+			markdown.WriteString(fmt.Sprintf("# %s\n", title))
+		}
+
+		if err := writeArchiveFile(archive, entry.File, []byte(markdown.String())); err != nil {
+			return nil, err
+		}
+		manifest.Lessons = append(manifest.Lessons, entry)
+
+		if progress != nil {
+			progress(i + 1)
+		}
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := writeArchiveFile(archive, "manifest.json", manifestJSON); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeArchiveFile adds one file to a ZIP archive
+func writeArchiveFile(archive *zip.Writer, name string, data []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
+
+// imageExtension picks a file extension from an image URL, falling back to its content type
+func imageExtension(url, contentType string) string {
+	if ext := path.Ext(strings.SplitN(url, "?", 2)[0]); ext != "" && len(ext) <= 5 {
+		return strings.ToLower(ext)
+	}
+	if contentType != "" {
+		if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+			return exts[0]
+		}
+	}
+	return ".bin"
+}
+
+// exportProgressEvent returns the SSE event reporting an export's state
+func exportProgressEvent(export LibraryExport) SSEEvent {
+	eventType := "export_progress"
+	switch export.Status {
+	case ExportStatusCompleted:
+		eventType = "export_complete"
+	case ExportStatusFailed:
+		eventType = "export_error"
+	}
+
+	data := map[string]interface{}{
+		"export_id": export.ID,
+		"status":    export.Status,
+		"done":      export.Done,
+		"total":     export.Total,
+		"timestamp": time.Now().Format(time.RFC3339),
+	}
+	if export.DownloadURL != "" {
+		data["download_url"] = export.DownloadURL
+		data["expires_at"] = export.ExpiresAt
+	}
+	if export.Error != "" {
+		data["error"] = export.Error
+	}
+	return SSEEvent{Type: eventType, Data: data, Timestamp: time.Now()}
+}
+
+// runLibraryExport builds, uploads and links an export archive, broadcasting progress on the export ID
+func (o *Orchestrator) runLibraryExport(exportID, userID string, lessons []SavedLesson) {
+	ctx, cancel := context.WithTimeout(context.Background(), libraryExportTimeout)
+	defer cancel()
+
+	x := o.exporter
+	fail := func(err error) {
+		export := x.update(exportID, func(export *LibraryExport) {
+			now := time.Now()
+			export.Status = ExportStatusFailed
+			export.Error = err.Error()
+			export.CompletedAt = &now
+		})
+		o.logger.WithFields(logrus.Fields{
+			"export_id": exportID,
+			"user_id":   userID,
+			"error":     err,
+		}).Error("Library export failed")
+		o.BroadcastEvent(exportID, exportProgressEvent(export))
+	}
+
+	data, err := buildLibraryArchive(ctx, userID, lessons, x.fetchImage, func(done int) {
+		export := x.update(exportID, func(export *LibraryExport) { export.Done = done })
+		o.BroadcastEvent(exportID, exportProgressEvent(export))
+	})
+	if err != nil {
+		fail(fmt.Errorf("failed to build export: %w", err))
+		return
+	}
+
+	object := fmt.Sprintf("%s%s/%s.zip", libraryExportPrefix, llm.Slugify(userID), exportID)
+	if err := x.store.Upload(ctx, object, "application/zip", bytes.NewReader(data)); err != nil {
+		fail(err)
+		return
+	}
+	link, err := x.store.SignedURL(ctx, object, x.linkTTL)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	export := x.update(exportID, func(export *LibraryExport) {
+		now := time.Now()
+		expiresAt := now.Add(x.linkTTL)
+		export.Status = ExportStatusCompleted
+		export.Object = object
+		export.DownloadURL = link
+		export.ExpiresAt = &expiresAt
+		export.CompletedAt = &now
+	})
+	o.logger.WithFields(logrus.Fields{
+		"export_id": exportID,
+		"user_id":   userID,
+		"lessons":   export.Total,
+		"bytes":     len(data),
+	}).Info("Library export completed")
+	o.BroadcastEvent(exportID, exportProgressEvent(export))
+}
+
+// userLessonsForExport returns copies of a user's saved lessons outside the trash, oldest first
+func (o *Orchestrator) userLessonsForExport(userID string) []SavedLesson {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	lessons := make([]SavedLesson, 0)
+	for _, lesson := range o.savedLessons {
+		if lesson.UserID != userID || lesson.isDeleted() {
+			continue
+		}
+		copied := *lesson
+		if lesson.Result != nil {
+			result := *lesson.Result
+			copied.Result = &result
+		}
+		lessons = append(lessons, copied)
+	}
+	sort.Slice(lessons, func(i, j int) bool {
+		if lessons[i].CreatedAt.Equal(lessons[j].CreatedAt) {
+			return lessons[i].ID < lessons[j].ID
+		}
+		return lessons[i].CreatedAt.Before(lessons[j].CreatedAt)
+	})
+	return lessons
+}
+
+// startLibraryExportHandler handles POST /api/saved/{userID}/export
+// It starts building a ZIP of the user's library in the background and responds 202 with
+// URLs for polling the export and following its progress events.
+func (o *Orchestrator) startLibraryExportHandler(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	w.Header().Set("Content-Type", "application/json")
+
+	if o.exporter == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Exports unavailable",
+			"message": "Export storage is not configured",
+		})
+		return
+	}
+
+	lessons := o.userLessonsForExport(userID)
+	if len(lessons) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Nothing to export",
+			"message": "No saved lessons found for this user",
+		})
+		return
+	}
+
+	export := &LibraryExport{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Status:    ExportStatusRunning,
+		Total:     len(lessons),
+		CreatedAt: time.Now(),
+	}
+	// Respond from a copy; the export is updated by its job as soon as it starts
+	started := *export
+	o.exporter.mu.Lock()
+	o.exporter.exports[export.ID] = export
+	o.exporter.mu.Unlock()
+
+	go o.runLibraryExport(export.ID, userID, lessons)
+
+	o.logger.WithFields(logrus.Fields{
+		"export_id": export.ID,
+		"user_id":   userID,
+		"lessons":   len(lessons),
+	}).Info("Library export started")
+
+	statusURL := fmt.Sprintf("/api/saved/%s/export/%s", userID, export.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"export_id":  started.ID,
+		"status":     started.Status,
+		"total":      started.Total,
+		"status_url": statusURL,
+		"events_url": statusURL + "/events",
+	})
+}
+
+// userExport returns a user's export by ID, writing a 404 if it does not exist
+func (o *Orchestrator) userExport(w http.ResponseWriter, r *http.Request) (LibraryExport, bool) {
+	if o.exporter != nil {
+		export, ok := o.exporter.snapshot(chi.URLParam(r, "exportID"))
+		if ok && export.UserID == chi.URLParam(r, "userID") {
+			return export, true
+		}
+	}
+	http.Error(w, "Export not found", http.StatusNotFound)
+	return LibraryExport{}, false
+}
+
+// getLibraryExportHandler handles GET /api/saved/{userID}/export/{exportID}
+func (o *Orchestrator) getLibraryExportHandler(w http.ResponseWriter, r *http.Request) {
+	export, ok := o.userExport(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(export)
+}
+
+// libraryExportEventsHandler handles GET /api/saved/{userID}/export/{exportID}/events
+// It streams export_progress events until export_complete or export_error.
+func (o *Orchestrator) libraryExportEventsHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := o.userExport(w, r); !ok {
+		return
+	}
+	exportID := chi.URLParam(r, "exportID")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe before taking the snapshot so no event falls between them
+	client := make(chan SSEEvent, 10)
+	o.AddClient(exportID, client)
+	defer o.RemoveClient(exportID, client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	export, _ := o.exporter.snapshot(exportID)
+	writeSSEEvent(w, flusher, exportProgressEvent(export))
+	if export.finished() {
+		return
+	}
+
+	for {
+		select {
+		case event, ok := <-client:
+			if !ok {
+				return
+			}
+			writeSSEEvent(w, flusher, event)
+			if event.Type == "export_complete" || event.Type == "export_error" {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
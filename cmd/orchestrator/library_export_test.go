@@ -0,0 +1,203 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryExportStore keeps uploaded objects in memory
+type memoryExportStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+// Upload implements ExportStore
+func (s *memoryExportStore) Upload(ctx context.Context, object, contentType string, data io.Reader) error {
+	body, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[object] = body
+	return nil
+}
+
+// SignedURL implements ExportStore
+func (s *memoryExportStore) SignedURL(ctx context.Context, object string, expiry time.Duration) (string, error) {
+	return "https://example.com/" + object + "?sig=test", nil
+}
+
+// Delete implements ExportStore
+func (s *memoryExportStore) Delete(ctx context.Context, object string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, object)
+	return nil
+}
+
+// Bucket implements exportImageStore
+func (s *memoryExportStore) Bucket() string {
+	return "test-bucket"
+}
+
+// stubFetchImage serves every image except ones whose URL contains "missing"
+func stubFetchImage(ctx context.Context, url string) ([]byte, string, error) {
+	if strings.Contains(url, "missing") {
+		return nil, "", errors.New("image request returned status 404")
+	}
+	return []byte("png-bytes"), "image/png", nil
+}
+
+// newExportTestOrchestrator creates an orchestrator with two saved lessons, one trashed lesson and the export routes
+func newExportTestOrchestrator(store ExportStore) (*Orchestrator, chi.Router) {
+	deletedAt := time.Now()
+	o := &Orchestrator{
+		savedLessons: map[string]*SavedLesson{
+			"l1": {ID: "l1", UserID: "u1", Topic: "Caching", Title: "Caching Basics", CreatedAt: time.Now().Add(-time.Hour), Result: &SessionResult{
+				Lesson: `{"big_picture":"Keep hot data close."}`,
+				Images: map[string]string{"https://img.example.com/a.png": "Cache diagram", "https://img.example.com/missing.png": "Gone"},
+			}},
+			"l2": {ID: "l2", UserID: "u1", Topic: "Queues", CreatedAt: time.Now()},
+			"l3": {ID: "l3", UserID: "u1", Topic: "Trashed", CreatedAt: time.Now(), DeletedAt: &deletedAt},
+			"l4": {ID: "l4", UserID: "u2", Topic: "Other user", CreatedAt: time.Now()},
+		},
+		logger:  logrus.New(),
+		clients: make(map[string][]chan SSEEvent),
+	}
+	if store != nil {
+		o.exporter = newLibraryExporter(store, time.Hour)
+		o.exporter.fetchImage = stubFetchImage
+	}
+
+	r := chi.NewRouter()
+	r.Post("/api/saved/{userID}/export", o.startLibraryExportHandler)
+	r.Get("/api/saved/{userID}/export/{exportID}", o.getLibraryExportHandler)
+	r.Get("/api/saved/{userID}/export/{exportID}/events", o.libraryExportEventsHandler)
+	return o, r
+}
+
+// readArchive returns the files in a ZIP archive by name
+func readArchive(t *testing.T, data []byte) map[string][]byte {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := make(map[string][]byte)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[file.Name] = body
+	}
+	return files
+}
+
+// TestLibraryExport tests exporting a library to a ZIP with lessons, images and a manifest
+func TestLibraryExport(t *testing.T) {
+	store := &memoryExportStore{objects: make(map[string][]byte)}
+	o, router := newExportTestOrchestrator(store)
+
+	w := serve(router, "POST", "/api/saved/u1/export")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var started map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	exportID := started["export_id"].(string)
+	assert.Equal(t, float64(2), started["total"])
+	assert.Equal(t, "/api/saved/u1/export/"+exportID+"/events", started["events_url"])
+
+	var export LibraryExport
+	require.Eventually(t, func() bool {
+		export, _ = o.exporter.snapshot(exportID)
+		return export.finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, ExportStatusCompleted, export.Status, export.Error)
+	assert.Equal(t, 2, export.Done)
+	assert.Contains(t, export.DownloadURL, "exports/u1/"+exportID+".zip")
+	require.NotNil(t, export.ExpiresAt)
+
+	// Status is only visible to the owner
+	assert.Equal(t, http.StatusOK, serve(router, "GET", "/api/saved/u1/export/"+exportID).Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/saved/u2/export/"+exportID).Code)
+
+	// A finished export's event stream replays the final state and closes
+	events := serve(router, "GET", "/api/saved/u1/export/"+exportID+"/events").Body.String()
+	assert.Contains(t, events, `"type":"export_complete"`)
+
+	files := readArchive(t, store.objects["exports/u1/"+exportID+".zip"])
+	assert.Contains(t, string(files["lessons/001-caching-basics.md"]), "Keep hot data close.")
+	assert.Contains(t, string(files["lessons/001-caching-basics.md"]), "![Cache diagram](../images/001-caching-basics/1.png)")
+	assert.Equal(t, []byte("png-bytes"), files["images/001-caching-basics/1.png"])
+	assert.Contains(t, files, "lessons/002-queues.md")
+
+	var manifest exportManifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	assert.Equal(t, "u1", manifest.UserID)
+	require.Len(t, manifest.Lessons, 2)
+	assert.Equal(t, "l1", manifest.Lessons[0].ID)
+	require.Len(t, manifest.Lessons[0].Images, 2)
+	assert.NotEmpty(t, manifest.Lessons[0].Images[1].Error)
+}
+
+// TestExportImagesOnlyFromBucket tests that exports read images from the artifact bucket and never fetch other URLs
+func TestExportImagesOnlyFromBucket(t *testing.T) {
+	store := &memoryExportStore{objects: map[string][]byte{"lessons/abc.png": []byte("png-bytes")}}
+	x := newLibraryExporter(store, time.Hour)
+
+	data, contentType, err := x.fetchImage(t.Context(), "https://storage.googleapis.com/test-bucket/lessons/abc.png")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("png-bytes"), data)
+	assert.Equal(t, "image/png", contentType)
+
+	for _, url := range []string{
+		"http://169.254.169.254/computeMetadata/v1/instance/service-accounts/default/token",
+		"https://storage.googleapis.com/other-bucket/lessons/abc.png",
+		"https://img.example.com/lessons/abc.png",
+	} {
+		_, _, err := x.fetchImage(t.Context(), url)
+		assert.ErrorIs(t, err, errExportImageOutsideBucket, url)
+	}
+}
+
+// TestLibraryExportUnavailable tests the responses without storage or saved lessons
+func TestLibraryExportUnavailable(t *testing.T) {
+	_, router := newExportTestOrchestrator(nil)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(router, "POST", "/api/saved/u1/export").Code)
+
+	_, router = newExportTestOrchestrator(&memoryExportStore{objects: make(map[string][]byte)})
+	assert.Equal(t, http.StatusNotFound, serve(router, "POST", "/api/saved/nobody/export").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/saved/u1/export/unknown").Code)
+}
+
+// TestSignedGCSURL tests the V4 signed URL layout
+func TestSignedGCSURL(t *testing.T) {
+	var signed string
+	sign := func(ctx context.Context, payload []byte) ([]byte, error) {
+		signed = string(payload)
+		return []byte{0xab, 0xcd}, nil
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	link, err := signedGCSURL(context.Background(), "bucket", "exports/u1/x y.zip", now, time.Hour, "svc@project.iam.gserviceaccount.com", sign)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, "https://storage.googleapis.com/bucket/exports/u1/x%20y.zip?X-Goog-Algorithm=GOOG4-RSA-SHA256"))
+	assert.Contains(t, link, "X-Goog-Credential=svc%40project.iam.gserviceaccount.com%2F20240501%2Fauto%2Fstorage%2Fgoog4_request")
+	assert.Contains(t, link, "X-Goog-Date=20240501T120000Z&X-Goog-Expires=3600")
+	assert.True(t, strings.HasSuffix(link, "&X-Goog-Signature=abcd"))
+	assert.True(t, strings.HasPrefix(signed, "GOOG4-RSA-SHA256\n20240501T120000Z\n20240501/auto/storage/goog4_request\n"))
+}
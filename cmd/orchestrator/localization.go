@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// LessonLocalizer adapts a lesson's examples and code comments to a language
+type LessonLocalizer interface {
+	LocalizeLesson(ctx context.Context, topic string, lesson llm.OGLesson, language string) (map[string]string, error)
+}
+
+// lessonLocalizationFromEnv reports whether lessons in a non-English language get a localization
+// pass (LESSON_LOCALIZATION, on by default)
+func lessonLocalizationFromEnv() bool {
+	return os.Getenv("LESSON_LOCALIZATION") != "false"
+}
+
+// LessonLocalization is a lesson whose code comments, naming guidance and real-life examples were
+// adapted to the session's language. It is kept beside the original lesson, which is unchanged.
+type LessonLocalization struct {
+	Language   string    `json:"language"`
+	Lesson     string    `json:"lesson"`                // Lesson JSON with Sections replaced by their localized text
+	LessonHTML string    `json:"lesson_html,omitempty"` // Sanitized HTML rendering of the localized lesson
+	Sections   []string  `json:"sections"`              // Fields that were localized
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// isEnglish reports whether a language name or code means English
+func isEnglish(language string) bool {
+	language = strings.ToLower(strings.TrimSpace(language))
+	return language == "en" || strings.HasPrefix(language, "en-") || strings.HasPrefix(language, "english")
+}
+
+// sessionLanguage returns the language a session's lessons are written in, or "" for the default
+func sessionLanguage(session *Session) string {
+	language, _ := session.Metadata["language"].(string)
+	return strings.TrimSpace(language)
+}
+
+// localizeLesson runs a localization pass over a finished lesson written in a non-English
+// language, adapting its examples and code comments to that language's readers. It returns nil
+// when the session is in English, localization is off or the pass fails.
+func (o *Orchestrator) localizeLesson(ctx context.Context, session *Session, lessonJSON string) *LessonLocalization {
+	language := sessionLanguage(session)
+	if language == "" || isEnglish(language) || o.localizer == nil || o.pipeline == nil || !o.pipeline.config.LocalizeLessons {
+		return nil
+	}
+	lesson := parseLesson(lessonJSON)
+	if lesson == nil {
+		return nil
+	}
+
+	if persona, ok := session.Metadata["persona"].(string); ok && persona != "" {
+		ctx = llm.WithPersona(ctx, llm.LookupPersona(persona))
+	}
+	localizedSections, err := o.localizer.LocalizeLesson(ctx, session.Topic, *lesson, language)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": session.ID,
+			"language":   language,
+			"error":      err,
+		}).Warn("Failed to localize lesson, keeping only the original")
+		return nil
+	}
+
+	localized := *lesson
+	var sections []string
+	for _, section := range llm.LessonSections {
+		if text, ok := localizedSections[section.Field]; ok && strings.TrimSpace(text) != "" {
+			localized.SetSectionText(section.Field, text)
+			sections = append(sections, section.Field)
+		}
+	}
+	if len(sections) == 0 {
+		return nil
+	}
+	localizedJSON, err := json.Marshal(localized)
+	if err != nil {
+		return nil
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id": session.ID,
+		"language":   language,
+		"sections":   sections,
+	}).Info("Lesson localized")
+	return &LessonLocalization{
+		Language:   language,
+		Lesson:     string(localizedJSON),
+		LessonHTML: lessonHTML(string(localizedJSON)),
+		Sections:   sections,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// localizationArtifact returns a localization as it is sent in session_complete artifacts
+func localizationArtifact(localization *LessonLocalization) map[string]interface{} {
+	artifact := map[string]interface{}{
+		"language": localization.Language,
+		"sections": localization.Sections,
+	}
+	var lesson map[string]interface{}
+	if err := json.Unmarshal([]byte(localization.Lesson), &lesson); err == nil {
+		artifact["lesson"] = lesson
+	}
+	return artifact
+}
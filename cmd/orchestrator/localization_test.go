@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubLocalizer localizes the code example and real-life section, recording the language it was asked for
+type stubLocalizer struct {
+	language string
+	err      error
+}
+
+// LocalizeLesson implements LessonLocalizer
+func (s *stubLocalizer) LocalizeLesson(ctx context.Context, topic string, lesson llm.OGLesson, language string) (map[string]string, error) {
+	s.language = language
+	if s.err != nil {
+		return nil, s.err
+	}
+	return map[string]string{
+		"toy_example_code": "# Clé absente : on lit la base\ncache[key] = value",
+		"real_life":        "La boulangerie garde les baguettes les plus demandées au comptoir.",
+	}, nil
+}
+
+// TestLocalizeLesson tests that a localized lesson is kept beside the unchanged original
+func TestLocalizeLesson(t *testing.T) {
+	localizer := &stubLocalizer{}
+	o := newReadabilityTestOrchestrator(nil, false)
+	o.localizer = localizer
+	session := &Session{ID: "s1", Topic: "Caching", Metadata: map[string]interface{}{"language": "French"}}
+	original := `{"big_picture": "Un cache garde les données proches.", "toy_example_code": "# missing key: read the database\ncache[key] = value"}`
+
+	localization := o.localizeLesson(context.Background(), session, original)
+	require.NotNil(t, localization)
+	assert.Equal(t, "French", localizer.language)
+	assert.Equal(t, "French", localization.Language)
+	assert.Equal(t, []string{"toy_example_code", "real_life"}, localization.Sections)
+
+	lesson := parseLesson(localization.Lesson)
+	require.NotNil(t, lesson)
+	assert.Equal(t, "Un cache garde les données proches.", lesson.BigPicture)
+	assert.Contains(t, lesson.ToyExampleCode, "Clé absente")
+	assert.Contains(t, lesson.RealLife, "boulangerie")
+	assert.Contains(t, localization.LessonHTML, "boulangerie")
+
+	artifacts := resultArtifacts(&SessionResult{Lesson: original, Localization: localization})
+	assert.Contains(t, artifacts, "localization")
+	assert.Contains(t, artifacts["lesson"].(map[string]interface{})["toy_example_code"], "missing key")
+}
+
+// TestLocalizeLessonSkipped tests that English sessions, disabled localization and failed passes add no localization
+func TestLocalizeLessonSkipped(t *testing.T) {
+	localizer := &stubLocalizer{}
+	o := newReadabilityTestOrchestrator(nil, false)
+	o.localizer = localizer
+	lessonJSON := `{"big_picture": "Caches keep data close."}`
+
+	for _, metadata := range []map[string]interface{}{{}, {"language": "English"}, {"language": "en-GB"}} {
+		assert.Nil(t, o.localizeLesson(context.Background(), &Session{ID: "s1", Metadata: metadata}, lessonJSON))
+	}
+	assert.Empty(t, localizer.language, "English lessons are not sent for localization")
+
+	french := &Session{ID: "s1", Metadata: map[string]interface{}{"language": "French"}}
+	localizer.err = errors.New("model unavailable")
+	assert.Nil(t, o.localizeLesson(context.Background(), french, lessonJSON))
+
+	localizer.err = nil
+	o.pipeline.config.LocalizeLessons = false
+	assert.Nil(t, o.localizeLesson(context.Background(), french, lessonJSON))
+}
@@ -1,11 +1,12 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,8 +17,12 @@ import (
 	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
 	"github.com/InnoFusionTech/ExplainIQ/internal/brainprint"
 	"github.com/InnoFusionTech/ExplainIQ/internal/cost_tracker"
+	"github.com/InnoFusionTech/ExplainIQ/internal/flags"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/InnoFusionTech/ExplainIQ/internal/notify"
 	"github.com/InnoFusionTech/ExplainIQ/internal/quota"
 	"github.com/InnoFusionTech/ExplainIQ/internal/rate_limiter"
+	"github.com/InnoFusionTech/ExplainIQ/internal/retrieval"
 	"github.com/InnoFusionTech/ExplainIQ/internal/storage"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -36,15 +41,38 @@ type Session struct {
 	Result    *SessionResult         `json:"result,omitempty"`
 	Steps     []SessionStep          `json:"steps,omitempty"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Tags      []string               `json:"tags,omitempty"`
+	CourseID  string                 `json:"course_id,omitempty"` // Course or collection the session belongs to
+	Revisions []*LessonRevision      `json:"revisions,omitempty"` // Section regenerations, oldest first
+	Failure   *SessionFailure        `json:"failure,omitempty"`   // Why the last run failed
+	Context   []*InjectedContext     `json:"context,omitempty"`   // Retrieved documents given to the session's agents, guarded by mu
+
+	partialOutputs map[string]map[string]string // Outputs of the steps completed so far in a run, by step; guarded by mu
+	forceFresh     bool                         // The next run skips the result cache; guarded by mu
 }
 
 // SessionResult represents the final result of a session
 type SessionResult struct {
-	Lesson      string            `json:"lesson"`
-	Images      map[string]string `json:"images,omitempty"`
-	Summary     string            `json:"summary,omitempty"`
-	Duration    time.Duration     `json:"duration,omitempty"`
-	CompletedAt time.Time         `json:"completed_at,omitempty"`
+	Lesson        string                 `json:"lesson"`
+	LessonHTML    string                 `json:"lesson_html,omitempty"` // Sanitized HTML rendering of the lesson for frontends
+	Images        map[string]string      `json:"images,omitempty"`
+	Summary       string                 `json:"summary,omitempty"`
+	Outline       []string               `json:"outline,omitempty"`
+	TOC           []llm.TOCEntry         `json:"toc,omitempty"`           // Section and outline anchors
+	Accessibility *llm.AccessibilityInfo `json:"accessibility,omitempty"` // Alt text and long descriptions for screen readers
+	Similarity    *SimilarityReport      `json:"similarity,omitempty"`    // Near-duplicates of indexed source material
+	Readability   *ReadabilityReport     `json:"readability,omitempty"`   // Grade level, reading time and code-to-prose ratio
+	CodeRepair    *CodeRepairReport      `json:"code_repair,omitempty"`   // How a code example the critic found broken was fixed or dropped
+	Localization  *LessonLocalization    `json:"localization,omitempty"`  // The lesson with examples and code comments adapted to its language
+	Cached        bool                   `json:"cached,omitempty"`        // Served from the topic result cache
+	CachedFrom    string                 `json:"cached_from,omitempty"`   // Session that generated a cached lesson
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`      // e.g. "web_grounded" and its sources
+	MisconceptionChecks []MisconceptionQuestion `json:"misconception_checks,omitempty"` // True/false checks on common misconceptions
+	FurtherReading []llm.ReadingLink `json:"further_reading,omitempty"` // Suggested references whose URLs were verified to resolve
+	Duration      time.Duration          `json:"duration,omitempty"`
+	CompletedAt   time.Time              `json:"completed_at,omitempty"`
+
+	misconceptionKey []llm.MisconceptionCheck // Answers to MisconceptionChecks, never serialized
 }
 
 // SessionStep represents a step in the session workflow
@@ -73,6 +101,20 @@ type SSEEvent struct {
 type CreateSessionRequest struct {
 	Topic           string `json:"topic"`
 	ExplanationType string `json:"explanation_type,omitempty"` // standard, visualization, simple, analogy
+	OrgID           string `json:"org_id,omitempty"` // Ignored; sessions belong to the authenticated caller's organization
+	Persona         string `json:"persona,omitempty"` // e.g. "10-year-old", "senior engineer", "product manager"
+	Model           string `json:"model,omitempty"`   // Optional model override, must be on the server allowlist
+	Grounding       string `json:"grounding,omitempty"` // Web grounding for the summary: "off" (default), "on" or "auto"
+	Deterministic   bool   `json:"deterministic,omitempty"` // Reproducible output for demos and golden-file tests
+	Difficulty      string `json:"difficulty,omitempty"`    // beginner, intermediate or advanced; sets the readability target
+	Force           bool   `json:"force,omitempty"`         // Generate a fresh lesson even if an identical one is cached
+	ImageStyle      string `json:"image_style,omitempty"`   // Diagram style preset, e.g. whiteboard-sketch (see GET /api/image-styles)
+	Supervised      bool   `json:"supervised,omitempty"`    // Pause after each step until a reviewer approves it
+	TracePrompts    bool   `json:"trace_prompts,omitempty"` // Admin only: record each step's rendered prompts (GET /api/admin/sessions/{id}/prompts)
+
+	Metadata map[string]string `json:"metadata,omitempty"` // Caller-defined tags (e.g. "source": "mobile")
+	Tags     []string          `json:"tags,omitempty"`      // Free-form labels, e.g. "week-3"
+	CourseID string            `json:"course_id,omitempty"` // Groups sessions into a course, e.g. "cs101"
 }
 
 // CreateSessionResponse represents the response for creating a session
@@ -87,34 +129,108 @@ type SavedLesson struct {
 	UserID      string                 `json:"user_id"`
 	Topic       string                 `json:"topic"`
 	Title       string                 `json:"title"`
+	Summary     string                 `json:"summary,omitempty"` // TL;DR for lesson lists
 	ExplanationType string             `json:"explanation_type"`
 	Result      *SessionResult         `json:"result,omitempty"`
+	Revisions   []*LessonRevision      `json:"revisions,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	CourseID    string                 `json:"course_id,omitempty"`
+	DeletedAt   *time.Time             `json:"deleted_at,omitempty"` // Set while the lesson is in the trash
+	Favorite     bool                  `json:"favorite,omitempty"`
+	FavoritedAt  *time.Time            `json:"favorited_at,omitempty"`
+	ViewCount    int                   `json:"view_count"`               // Times the lesson was opened
+	LastViewedAt *time.Time            `json:"last_viewed_at,omitempty"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
 }
 
 // Orchestrator manages learning sessions
 type Orchestrator struct {
-	sessions      map[string]*Session
-	savedLessons  map[string]*SavedLesson // userID -> []SavedLesson (stored by userID)
-	mu            sync.RWMutex
-	logger        *logrus.Logger
-	clients       map[string][]chan SSEEvent
-	clientsMu     sync.RWMutex
-	pipeline      *Pipeline
-	authClient    *auth.Client
-	quotaManager  *quota.QuotaManager
-	brainprintSvc *brainprint.Service
+	sessions       map[string]*Session
+	savedLessons   map[string]*SavedLesson // userID -> []SavedLesson (stored by userID)
+	mu             sync.RWMutex
+	logger         *logrus.Logger
+	clients        map[string][]chan SSEEvent
+	clientsMu      sync.RWMutex
+	clientStats    map[chan SSEEvent]*sseClientStats // Per-client drop tracking, guarded by clientsMu
+	sseEvictAfter  int                               // Consecutive drops before a slow client is evicted
+	sseMaxPerSession int                             // Open event streams allowed per session, 0 for no limit
+	sseMaxPerUser    int                             // Open event streams allowed per user, 0 for no limit
+	userStreams      map[string][]chan SSEEvent      // User -> open streams, oldest first, guarded by clientsMu
+	pipeline       *Pipeline
+	authClient     *auth.Client
+	quotaManager   *quota.QuotaManager
+	routeLimiters  map[routeClass]*rate_limiter.Limiter // Per-IP token buckets by route class
+	brainprintSvc  *brainprint.Service
+	costTracker    *cost_tracker.CostTracker // nil without storage
+	rubricStore    *llm.RubricStore
+	qaClient       QuestionAnswerer
+	quizClient     MisconceptionQuizzer
+	readingClient  ReadingSuggester
+	tldrClient     TLDRWriter
+	linkVerifier   *linkVerifier // Checks further-reading URLs; nil disables further reading
+	regenClient    SectionRegenerator
+	localizer      LessonLocalizer // Adapts examples and code comments of non-English lessons
+	trashTTL       time.Duration
+	modelAllowlist *llm.ModelAllowlist
+	metaIndex      *metadataIndex
+	runQueue       *runQueue
+	userRuns       *userRunLimiter // Per-user cap on concurrently running pipelines; nil disables it
+	usageQuota     usageQuota      // Monthly allowance reported by the usage API
+	tips           *tipRotation    // Per-user tip rotation
+	deploymentVersion string       // Release new sessions are stamped with, for before/after comparisons
+	moderator      llm.Moderator   // Content policy check for finished lessons; nil disables it
+	flagService    *flags.Service
+	artifacts      *artifactLifecycle
+	apiKeys        *auth.APIKeyService
+	authRequired   bool // Reject unauthenticated API requests (AUTH_REQUIRED)
+	adminUsers     map[string]bool // JWT user IDs and emails granted the admin scope (ADMIN_USERS)
+	notifier       *notify.Service
+	exporter       *libraryExporter // nil when export storage is not configured
+	deletionJobs   map[string]*DataDeletionJob // User data deletion audit records, guarded by mu
+	goals          map[string]map[string]*LearningGoal // userID -> goal ID -> learning goal, guarded by mu
+	budgetAlarms   *budgetMonitor                      // Step latency, token and cost alarms; nil when no threshold is set
+	runCancels     map[string]context.CancelCauseFunc  // Cancels the run of each running session, guarded by mu
+	orgLibrary     map[string]*OrgLibraryEntry         // Lessons shared with organizations by entry ID, guarded by mu
+	orgPolicies    map[string]*OrgPolicy               // Session defaults enforced per organization ID, guarded by mu
+	contextFeedback *contextFeedbackStore              // Context document ratings that adjust hybrid search scores
+	courseGenerations map[string]*CourseGeneration     // Courses generated from syllabi by course ID, guarded by mu
+	abuse          *abuseDetector                      // Scores anonymous session creation; nil when disabled
+	ipAccess       *ipAccessControl                    // IP allow/deny lists and country blocking of session endpoints; nil when unrestricted
+	trustedProxies []*net.IPNet                        // Proxies whose forwarded client address and country headers are honoured (TRUSTED_PROXIES)
+	resultCache    *resultCache                        // Completed lessons of anonymous sessions by topic; nil when disabled
+	experiments    *experimentStore                    // Prompt A/B experiments and their outcomes
+	transcripts    *sessionTranscripts                 // Events broadcast for each session, for export
+	dependencies   *dependencyMonitor                  // Policies and state of Firestore, retrieval and cost tracking
+	scratchpads    *scratchpadVault                    // Encrypts explainer scratchpads into transcripts; nil when storage is off
+	approvals      map[string]*pendingApproval         // Supervised runs waiting for a reviewer, by session ID, guarded by mu
+	approvalTimeout time.Duration                      // How long a supervised run waits at each step (SUPERVISED_APPROVAL_TIMEOUT)
+	libraryClient  LibraryAssistant                   // Answers questions from a user's saved lessons
+	libraryIndex   *libraryIndex                      // Saved-lesson passages indexed for library chat
+	artifactSpill  *artifactSpill                     // Artifact size caps and the store oversized artifacts spill to
 }
 
 // NewOrchestrator creates a new orchestrator instance
 func NewOrchestrator() *Orchestrator {
+	// Validate how each optional dependency may fail before connecting to any of them
+	dependencyPolicies, err := dependencyPoliciesFromEnv()
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid dependency policy")
+	}
+	dependencies := newDependencyMonitor(dependencyPolicies)
+
 	// Create pipeline with default config
 	config := DefaultPipelineConfig()
+	if !dependencies.enabled(dependencyRetrieval) {
+		config.Retrieval.Provider = retrieval.ProviderNone
+	}
 	pipeline, err := NewPipeline(config)
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to create pipeline")
 	}
+	if err := dependencies.report(dependencyRetrieval, pipeline.retrievalErr); err != nil {
+		logrus.WithError(err).Fatal("Failed to start orchestrator")
+	}
 
 	// Create auth client
 	serviceURL := os.Getenv("SERVICE_URL")
@@ -127,25 +243,59 @@ func NewOrchestrator() *Orchestrator {
 	var storageClient storage.Storage
 	var costTracker *cost_tracker.CostTracker
 
+	var storageErr error
 	gcpProjectID := os.Getenv("GCP_PROJECT_ID")
-	if gcpProjectID != "" {
+	if sqlitePath := sqlitePathFromEnv(); sqlitePath != "" {
+		// A local SQLite file stands in for Firestore, e.g. in demo mode
+		client, err := storage.NewSQLiteStorage(context.Background(), sqlitePath)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to open SQLite storage")
+		}
+		logrus.WithField("path", sqlitePath).Info("Using SQLite storage instead of Firestore")
+		storageClient = client
+		if dependencies.enabled(dependencyCostTracking) {
+			costTracker = cost_tracker.NewCostTracker(storageClient)
+		}
+		storageErr = fmt.Errorf("replaced by SQLite storage, %w", errDependencyNotConfigured)
+	} else if !dependencies.enabled(dependencyFirestore) {
+		logrus.Info("Firestore disabled by policy, continuing without cost tracking")
+		storageErr = errDependencyNotConfigured
+	} else if gcpProjectID != "" {
 		client, err := storage.NewFirestoreClient(context.Background(), gcpProjectID, "sessions")
 		if err != nil {
 			logrus.WithError(err).Warn("Failed to create Firestore client, continuing without cost tracking")
 			storageClient = nil
 			costTracker = nil
+			storageErr = err
 		} else {
 			storageClient = client
-			costTracker = cost_tracker.NewCostTracker(storageClient)
+			if dependencies.enabled(dependencyCostTracking) {
+				costTracker = cost_tracker.NewCostTracker(storageClient)
+			}
 		}
 	} else {
 		logrus.Warn("GCP_PROJECT_ID not set, continuing without cost tracking")
 		storageClient = nil
 		costTracker = nil
+		storageErr = fmt.Errorf("GCP_PROJECT_ID %w", errDependencyNotConfigured)
+	}
+	if err := dependencies.report(dependencyFirestore, storageErr); err != nil {
+		logrus.WithError(err).Fatal("Failed to start orchestrator")
+	}
+	costTrackingErr := storageErr
+	if costTracker != nil {
+		costTrackingErr = nil
+	}
+	if err := dependencies.report(dependencyCostTracking, costTrackingErr); err != nil {
+		logrus.WithError(err).Fatal("Failed to start orchestrator")
 	}
 
-	// Create rate limiter (10 requests per second, burst of 20)
-	rateLimiter := rate_limiter.NewLimiter(10.0, 20)
+	// Create rate limiters per route class; the expensive class is also the quota manager's limiter
+	rateLimiter := newRouteClassLimiter(routeClassExpensive)
+	routeLimiters := map[routeClass]*rate_limiter.Limiter{
+		routeClassCheap:     newRouteClassLimiter(routeClassCheap),
+		routeClassExpensive: rateLimiter,
+	}
 
 	// Create quota manager
 	var quotaManager *quota.QuotaManager
@@ -164,16 +314,82 @@ func NewOrchestrator() *Orchestrator {
 	}
 	brainprintSvc := brainprint.NewService(brainprintStorage)
 
-	return &Orchestrator{
-		sessions:      make(map[string]*Session),
-		savedLessons:  make(map[string]*SavedLesson),
-		logger:        logrus.New(),
-		clients:       make(map[string][]chan SSEEvent),
-		pipeline:      pipeline,
-		authClient:    authClient,
-		quotaManager:  quotaManager,
-		brainprintSvc: brainprintSvc,
-	}
+	// Feature flags and API keys are shared across replicas through storage when it is available
+	var flagStore flags.Store
+	var keyStore auth.APIKeyStore
+	var notifyStore notify.Store
+	if storageClient != nil {
+		flagStore = storageClient
+		keyStore = storageClient
+		notifyStore = storageClient
+	}
+
+	o := &Orchestrator{
+		sessions:       make(map[string]*Session),
+		savedLessons:   make(map[string]*SavedLesson),
+		logger:         logrus.New(),
+		clients:        make(map[string][]chan SSEEvent),
+		clientStats:    make(map[chan SSEEvent]*sseClientStats),
+		sseEvictAfter:  sseEvictAfterDropsFromEnv(),
+		sseMaxPerSession: sseStreamLimitFromEnv("SSE_MAX_STREAMS_PER_SESSION", defaultSSEMaxPerSession),
+		sseMaxPerUser:    sseStreamLimitFromEnv("SSE_MAX_STREAMS_PER_USER", defaultSSEMaxPerUser),
+		userStreams:      make(map[string][]chan SSEEvent),
+		pipeline:       pipeline,
+		authClient:     authClient,
+		quotaManager:   quotaManager,
+		routeLimiters:  routeLimiters,
+		brainprintSvc:  brainprintSvc,
+		costTracker:    costTracker,
+		rubricStore:    newRubricStore(),
+		qaClient:       llm.NewGeminiClient(""),
+		quizClient:     llm.NewGeminiClient(""),
+		readingClient:  llm.NewGeminiClient(""),
+		tldrClient:     llm.NewGeminiClient(""),
+		linkVerifier:   linkVerifierFromEnv(),
+		regenClient:    llm.NewGeminiClient(""),
+		localizer:      llm.NewGeminiClient(""),
+		trashTTL:       trashRetentionFromEnv(),
+		modelAllowlist: newModelAllowlist(),
+		metaIndex:      newMetadataIndex(),
+		runQueue:       newRunQueue(asyncRunConcurrencyFromEnv()),
+		userRuns:       userRunLimiterFromEnv(),
+		usageQuota:     usageQuotaFromEnv(),
+		tips:           tipRotationFromEnv(),
+		deploymentVersion: deploymentVersionFromEnv(),
+		moderator:      moderatorFromEnv(),
+		flagService:    newFlagService(flagStore),
+		artifacts:      artifactLifecycleFromEnv(),
+		apiKeys:        newAPIKeyService(keyStore),
+		authRequired:   authRequiredFromEnv(),
+		adminUsers:     adminUsersFromEnv(),
+		notifier:       newNotifyService(notifyStore),
+		abuse:          newAbuseDetector(DefaultAbuseConfig(), challengeVerifierFromEnv()),
+		ipAccess:       newIPAccessControl(DefaultIPAccessConfig()),
+		trustedProxies: trustedProxiesFromEnv(),
+		resultCache:    resultCacheFromEnv(),
+		experiments:    experimentStoreFromEnv(),
+		transcripts:    newSessionTranscripts(),
+		dependencies:   dependencies,
+		scratchpads:    scratchpadVaultFromEnv(),
+		approvals:      make(map[string]*pendingApproval),
+		approvalTimeout: approvalTimeoutFromEnv(),
+		libraryClient:  llm.NewGeminiClient(""),
+		libraryIndex:   newLibraryIndex(pipeline.similarityEmbedder),
+		artifactSpill:  artifactSpillFromEnv(),
+		exporter:       libraryExporterFromEnv(),
+		deletionJobs:   make(map[string]*DataDeletionJob),
+		goals:          make(map[string]map[string]*LearningGoal),
+		budgetAlarms:   newBudgetMonitor(budgetAlarmConfigFromEnv(), logrus.New()),
+		orgLibrary:     make(map[string]*OrgLibraryEntry),
+		orgPolicies:    make(map[string]*OrgPolicy),
+		contextFeedback: newContextFeedbackStore(),
+		courseGenerations: make(map[string]*CourseGeneration),
+	}
+	if pipeline.elasticRetriever != nil {
+		pipeline.elasticRetriever.SetBooster(o.contextFeedback)
+	}
+	o.registerPipelineHooks()
+	return o
 }
 
 // CreateSession creates a new learning session
@@ -191,8 +407,12 @@ func (o *Orchestrator) CreateSession(topic string) *Session {
 		Steps:     make([]SessionStep, 0),
 		Metadata:  make(map[string]interface{}),
 	}
+	if o.deploymentVersion != "" {
+		session.Metadata["deployment_version"] = o.deploymentVersion
+	}
 
 	o.sessions[sessionID] = session
+	o.indexSessionLocked(session)
 	o.logger.WithFields(logrus.Fields{
 		"session_id": sessionID,
 		"topic":      topic,
@@ -217,6 +437,7 @@ func (o *Orchestrator) UpdateSession(session *Session) {
 
 	session.UpdatedAt = time.Now()
 	o.sessions[session.ID] = session
+	o.indexSessionLocked(session)
 }
 
 // AddClient adds a client to receive SSE events for a session
@@ -239,29 +460,51 @@ func (o *Orchestrator) RemoveClient(sessionID string, client chan SSEEvent) {
 			break
 		}
 	}
+	o.removeUserStreamLocked(client, o.clientStats[client])
+	delete(o.clientStats, client)
 }
 
-// BroadcastEvent broadcasts an SSE event to all clients for a session
+// BroadcastEvent broadcasts an SSE event to all clients for a session and to the admin firehose.
+// Sends never block; slow clients are told about missed events and evicted if they stay stuck.
 func (o *Orchestrator) BroadcastEvent(sessionID string, event SSEEvent) {
-	o.clientsMu.RLock()
-	clients := o.clients[sessionID]
-	o.clientsMu.RUnlock()
+	o.transcripts.record(sessionID, event)
 
+	o.clientsMu.Lock()
+	defer o.clientsMu.Unlock()
+
+	// Copy the lists since eviction removes clients from them
+	clients := append([]chan SSEEvent(nil), o.clients[sessionID]...)
 	for _, client := range clients {
-		select {
-		case client <- event:
-		default:
-			// Client channel is full, skip
-		}
+		o.deliverLocked(sessionID, client, event)
+	}
+	firehose := append([]chan SSEEvent(nil), o.clients[allSessionsSubscription]...)
+	for _, client := range firehose {
+		o.deliverLocked(allSessionsSubscription, client, event)
 	}
 }
 
 // RunSession executes a session workflow using the pipeline
 func (o *Orchestrator) RunSession(sessionID string) {
-	ctx := context.Background()
+	// Runs get their own deadline instead of the request's, and stop when cancelled
+	ctx, done, ok := o.sessionRunContext(sessionID)
+	if !ok {
+		o.logger.WithField("session_id", sessionID).Info("Session cancelled before its run started")
+		return
+	}
+	defer done()
+
+	// Answer identical anonymous requests from the result cache instead of running the pipeline
+	if o.serveCachedResult(sessionID) {
+		return
+	}
 
 	// Execute the pipeline
-	if err := o.pipeline.runPipeline(ctx, sessionID, o); err != nil {
+	err := o.pipeline.runPipeline(ctx, sessionID, o)
+	if errors.Is(err, errSessionCancelled) {
+		// Cancelled on request; the pipeline has already marked the session
+		return
+	}
+	if err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"session_id": sessionID,
 			"error":      err,
@@ -270,10 +513,17 @@ func (o *Orchestrator) RunSession(sessionID string) {
 		// Update session status to failed
 		session, exists := o.GetSession(sessionID)
 		if exists {
+			if session.Failure == nil {
+				o.recordFailure(session, "", err.Error())
+			}
 			session.Status = "failed"
 			o.UpdateSession(session)
 		}
 	}
+
+	// Tell the user and their organization the run finished
+	o.notifySessionOutcome(sessionID, err)
+	o.recordExperimentOutcome(sessionID, err)
 }
 
 // HTTP Handlers
@@ -281,7 +531,7 @@ func (o *Orchestrator) RunSession(sessionID string) {
 // createSessionHandler handles POST /api/sessions
 func (o *Orchestrator) createSessionHandler(w http.ResponseWriter, r *http.Request) {
 	var req CreateSessionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSONBody(w, r, &req); err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"error": err,
 		}).Error("Failed to decode create session request")
@@ -295,11 +545,82 @@ func (o *Orchestrator) createSessionHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Set default explanation type if not provided
+	// Challenge or block anonymous clients whose recent traffic looks abusive
+	abuseClient, ok := o.screenSessionCreation(w, r, req.Topic)
+	if !ok {
+		return
+	}
+
+	// Validate the model override before creating the session
+	var modelPolicy *llm.ModelPolicy
+	if req.Model != "" {
+		policy, ok := o.resolveModelOverride(w, r, req.Model)
+		if !ok {
+			return
+		}
+		modelPolicy = &policy
+	}
+
+	tags, courseID, err := normalizeGrouping(req.Tags, req.CourseID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	grounding, err := llm.NormalizeGroundingMode(req.Grounding)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	persona, err := llm.ValidatePersona(req.Persona)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.TracePrompts && !o.canTracePrompts(r) {
+		http.Error(w, "Only admins can trace prompts", http.StatusForbidden)
+		return
+	}
+	if req.Deterministic && grounding != llm.GroundingOff {
+		http.Error(w, "Grounding cannot be used with deterministic sessions", http.StatusBadRequest)
+		return
+	}
+
+	// Hold the session to its organization's policy, which may also fill in defaults
 	explanationType := req.ExplanationType
+	requestedDifficulty := req.Difficulty
+	orgID := sessionOrg(r)
+	policy, hasPolicy := o.orgPolicy(orgID)
+	if hasPolicy {
+		explanationType, requestedDifficulty, err = policy.apply(sessionPolicyRequest{
+			Topic:           req.Topic,
+			ExplanationType: req.ExplanationType,
+			Difficulty:      req.Difficulty,
+		})
+		if violation, ok := err.(*orgPolicyViolation); ok {
+			o.writeOrgPolicyViolation(w, orgID, violation)
+			return
+		}
+	}
+
+	// Set default explanation type if not provided
 	if explanationType == "" {
 		explanationType = "standard"
 	}
+	if !o.pipeline.supportsExplanationType(explanationType) {
+		http.Error(w, fmt.Sprintf("Explanation type %q is not supported by the explainer", explanationType), http.StatusBadRequest)
+		return
+	}
+	difficulty, err := normalizeDifficulty(requestedDifficulty, explanationType)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	imageStyle, err := llm.NormalizeImageStyle(req.ImageStyle)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Create session with error handling
 	session := o.CreateSession(req.Topic)
@@ -311,6 +632,51 @@ func (o *Orchestrator) createSessionHandler(w http.ResponseWriter, r *http.Reque
 	
 	// Store explanation type in session metadata
 	session.Metadata["explanation_type"] = explanationType
+	session.Metadata["difficulty"] = difficulty
+	if orgID != "" {
+		session.Metadata["org_id"] = orgID
+	}
+	if owner := sessionOwner(r); owner != "" {
+		session.Metadata["user_id"] = owner
+	}
+	if hasPolicy {
+		applySessionPolicy(session, policy)
+	}
+	if persona != "" {
+		session.Metadata["persona"] = persona
+	}
+	if grounding != llm.GroundingOff {
+		session.Metadata["grounding"] = grounding
+	}
+	if imageStyle != "" {
+		session.Metadata["image_style"] = imageStyle
+	}
+	if req.Deterministic {
+		session.Metadata["deterministic"] = true
+	}
+	if req.Supervised {
+		session.Metadata["supervised"] = true
+	}
+	if req.TracePrompts {
+		session.Metadata["trace_prompts"] = true
+	}
+	if modelPolicy != nil {
+		session.Metadata["model"] = modelPolicy.Name
+		session.Metadata["quota_multiplier"] = modelPolicy.QuotaMultiplier
+	}
+	// Caller-defined tags never override the fields set above or claim ownership
+	for key, value := range req.Metadata {
+		if _, exists := session.Metadata[key]; !exists && key != "" && !policyMetadataKeys[key] && !reservedMetadataKeys[key] {
+			session.Metadata[key] = value
+		}
+	}
+	o.snapshotSessionFlags(r, session)
+	o.setSessionGrouping(session, tags, courseID)
+	o.indexSession(session)
+	o.recordSessionCreation(abuseClient, session.ID, req.Topic)
+	if req.Force {
+		o.bypassResultCache(session)
+	}
 	response := CreateSessionResponse{ID: session.ID}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -324,6 +690,7 @@ func (o *Orchestrator) createSessionHandler(w http.ResponseWriter, r *http.Reque
 }
 
 // runSessionHandler handles POST /api/sessions/{id}/run
+// With ?mode=async the run is queued and the handler responds 202 instead of streaming SSE.
 func (o *Orchestrator) runSessionHandler(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "id")
 
@@ -333,33 +700,60 @@ func (o *Orchestrator) runSessionHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if session.Status == "running" {
+	if r.URL.Query().Get("force") == "true" {
+		o.bypassResultCache(session)
+	}
+
+	// Fail fast, before the run starts, if an agent the run cannot do without is down
+	if !o.agentsReady(w, r, session) {
+		return
+	}
+
+	if r.URL.Query().Get("mode") == "async" {
+		o.runSessionAsync(w, r, session)
+		return
+	}
+
+	// Claim the session so a concurrent run request gets a conflict
+	o.mu.Lock()
+	if session.Status == "running" || session.Status == "queued" {
+		o.mu.Unlock()
 		http.Error(w, "Session is already running", http.StatusConflict)
 		return
 	}
+	previousStatus := session.Status
+	session.Status = "queued"
+	session.UpdatedAt = time.Now()
+	o.mu.Unlock()
 
-	// Set up SSE
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Cache-Control")
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		o.restoreSessionStatus(session, previousStatus)
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
 
 	// Create client channel
+	user := runUser(r)
 	client := make(chan SSEEvent, 10)
-	o.AddClient(sessionID, client)
+	o.AddSessionClient(sessionID, user, client)
 	defer o.RemoveClient(sessionID, client)
 
-	// Start session execution in goroutine
-	go o.RunSession(sessionID)
-
-	// Stream events to client
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+	// Start session execution in goroutine, unless the user is at their concurrent run limit
+	position, err := o.startUserRun(user, sessionID, func(run func()) { go run() })
+	if err != nil {
+		o.restoreSessionStatus(session, previousStatus)
+		o.writeUserRunLimitError(w, user, sessionID)
 		return
 	}
 
+	// Set up SSE
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Cache-Control")
+
 	// Send initial event (formatted to match SSEEvent structure)
 	initialEvent := SSEEvent{
 		Type:      "connected",
@@ -374,10 +768,32 @@ func (o *Orchestrator) runSessionHandler(w http.ResponseWriter, r *http.Request)
 	fmt.Fprintf(w, "data: %s\n\n", string(initialData))
 	flusher.Flush()
 
+	// Tell the client the run is waiting for one of the user's other runs to finish
+	if position > 0 {
+		writeSSEEvent(w, flusher, SSEEvent{
+			Type:      "session_queued",
+			SessionID: sessionID,
+			Data: map[string]interface{}{
+				"session_id":     sessionID,
+				"queue_position": position,
+				"reason":         "user_concurrency_limit",
+				"timestamp":      time.Now().Format(time.RFC3339),
+			},
+			Timestamp: time.Now(),
+		})
+	}
+
 		// Stream events
 		for {
 			select {
-			case event := <-client:
+			case event, ok := <-client:
+				if !ok {
+					// Evicted for falling behind or for a newer stream; end with a terminal event
+					data, _ := json.Marshal(o.closedClientEvent(sessionID, client))
+					fmt.Fprintf(w, "data: %s\n\n", string(data))
+					flusher.Flush()
+					return
+				}
 				data, _ := json.Marshal(event)
 				fmt.Fprintf(w, "data: %s\n\n", string(data))
 				flusher.Flush()
@@ -393,9 +809,16 @@ func (o *Orchestrator) runSessionHandler(w http.ResponseWriter, r *http.Request)
 }
 
 // getSessionResultHandler handles GET /api/sessions/{id}/result
+// ?format=compact returns a CompactLesson for popovers and extensions instead of the full result.
 func (o *Orchestrator) getSessionResultHandler(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "id")
 
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "full" && format != "compact" {
+		http.Error(w, "Unsupported format: use full or compact", http.StatusBadRequest)
+		return
+	}
+
 	session, exists := o.GetSession(sessionID)
 	if !exists {
 		http.Error(w, "Session not found", http.StatusNotFound)
@@ -403,11 +826,28 @@ func (o *Orchestrator) getSessionResultHandler(w http.ResponseWriter, r *http.Re
 	}
 
 	if session.Status != "completed" {
-		http.Error(w, "Session not completed", http.StatusBadRequest)
+		// Serve what the finished steps have produced so far, if anything
+		partial := o.partialSessionResult(session)
+		if partial == nil {
+			http.Error(w, "Session not completed", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if format == "compact" {
+			json.NewEncoder(w).Encode(compactResult(session, partial.SessionResult, true))
+			return
+		}
+		json.NewEncoder(w).Encode(partial)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if format == "compact" {
+		json.NewEncoder(w).Encode(compactResult(session, session.Result, false))
+		return
+	}
 	json.NewEncoder(w).Encode(session.Result)
 }
 
@@ -431,8 +871,8 @@ func (o *Orchestrator) getBrainPrintHandler(w http.ResponseWriter, r *http.Reque
 		profile = brainprint.NewUserLearningProfile(userID)
 	}
 
-	// Get a random tip
-	tip := o.getRandomTip()
+	// Get the user's current tip
+	tip := o.currentTip(userID)
 
 	// Format response with tip
 	response := map[string]interface{}{
@@ -444,7 +884,12 @@ func (o *Orchestrator) getBrainPrintHandler(w http.ResponseWriter, r *http.Reque
 		"usage":          profile.ByType,
 		"tip":            tip,
 		"lastUpdated":    profile.LastUpdated,
+		"byPersona":        profile.ByPersona,
+		"preferredPersona": profile.PreferredPersona,
 	}
+	recommendations := brainprintRecommendations(profile)
+	response["recommendations"] = recommendations
+	response["recommendationReason"] = recommendations[0].Reason
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -461,9 +906,10 @@ func (o *Orchestrator) sessionCompleteHandler(w http.ResponseWriter, r *http.Req
 		SessionID       string `json:"session_id"`
 		UserID          string `json:"user_id"`
 		ExplanationType string `json:"explanation_type"`
+		Persona         string `json:"persona,omitempty"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSONBody(w, r, &req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
@@ -488,7 +934,7 @@ func (o *Orchestrator) sessionCompleteHandler(w http.ResponseWriter, r *http.Req
 	ctx := r.Context()
 
 	// Track session completion
-	if err := o.brainprintSvc.TrackSession(ctx, userID, explanationType, true); err != nil {
+	if err := o.brainprintSvc.TrackSessionWithPersona(ctx, userID, explanationType, llm.NormalizePersona(req.Persona), true); err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"session_id": req.SessionID,
 			"user_id":    userID,
@@ -509,8 +955,8 @@ func (o *Orchestrator) sessionCompleteHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Get a random tip
-	tip := o.getRandomTip()
+	// Get the user's current tip
+	tip := o.currentTip(userID)
 
 	response := map[string]interface{}{
 		"success":       true,
@@ -520,48 +966,14 @@ func (o *Orchestrator) sessionCompleteHandler(w http.ResponseWriter, r *http.Req
 		"totalSessions": profile.TotalSessions,
 		"usage":         profile.ByType,
 		"tip":           tip,
+		"preferredPersona": profile.PreferredPersona,
+		"recommendationReason": brainprintRecommendations(profile)[0].Reason,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// getTipsHandler handles GET /api/tips
-func (o *Orchestrator) getTipsHandler(w http.ResponseWriter, r *http.Request) {
-	tip := o.getRandomTip()
-	
-	response := map[string]interface{}{
-		"tip": tip,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-}
-
-// getRandomTip returns a random learning tip
-func (o *Orchestrator) getRandomTip() string {
-	tips := []string{
-		"Try visualization next to boost retention by 30%!",
-		"Analogy explanations help connect new ideas to familiar concepts.",
-		"Simple explanations are great for complex topics - try them!",
-		"Mix different explanation types to discover your learning style.",
-		"Visual learners benefit most from diagram-based explanations.",
-		"Practice with toy examples to reinforce core mechanisms.",
-		"Real-life applications make abstract concepts concrete.",
-		"Memory hooks help you remember key concepts longer.",
-		"Best practices save time and prevent common mistakes.",
-		"Try different explanation types to find what works best for you!",
-		"Visualization mode creates interactive diagrams for better understanding.",
-		"Standard explanations provide comprehensive coverage of topics.",
-		"Simple explanations break down complex ideas into digestible parts.",
-		"Analogy explanations use familiar concepts to explain new ones.",
-	}
-
-	// Use current time to pseudo-randomly select a tip
-	index := int(time.Now().Unix()) % len(tips)
-	return tips[index]
-}
-
 // saveLessonHandler handles POST /api/saved
 func (o *Orchestrator) saveLessonHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -570,34 +982,16 @@ func (o *Orchestrator) saveLessonHandler(w http.ResponseWriter, r *http.Request)
 		Title           string          `json:"title,omitempty"`
 		ExplanationType string          `json:"explanation_type,omitempty"`
 		Result          *SessionResult  `json:"result,omitempty"`
+		Tags            []string        `json:"tags,omitempty"`      // Defaults to the session's tags
+		CourseID        string          `json:"course_id,omitempty"` // Defaults to the session's course
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 
-	// Read body for logging and decoding
-	bodyBytes, err := io.ReadAll(r.Body)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"error":   "Invalid request body",
-			"message": "Failed to read request body",
-		})
-		return
-	}
-
-	// Log the request body for debugging
-	o.logger.WithFields(logrus.Fields{
-		"body": string(bodyBytes),
-	}).Debug("Save lesson request body")
-
-	// Restore body for decoding
-	r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-
 	// Decode JSON
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeJSONBody(w, r, &req); err != nil {
 		o.logger.WithFields(logrus.Fields{
 			"error": err.Error(),
-			"body":  string(bodyBytes),
 		}).Error("Failed to decode save lesson request")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -633,6 +1027,25 @@ func (o *Orchestrator) saveLessonHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Saved lessons stay grouped with their session unless the request regroups them
+	tags, courseID, err := normalizeGrouping(req.Tags, req.CourseID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Invalid grouping",
+			"message": err.Error(),
+		})
+		return
+	}
+	o.mu.RLock()
+	if tags == nil {
+		tags = append([]string(nil), session.Tags...)
+	}
+	if courseID == "" {
+		courseID = session.CourseID
+	}
+	o.mu.RUnlock()
+
 	// Generate title if not provided
 	title := req.Title
 	if title == "" {
@@ -694,6 +1107,20 @@ func (o *Orchestrator) saveLessonHandler(w http.ResponseWriter, r *http.Request)
 		result.CompletedAt = time.Now()
 	}
 
+	// A result sent by the client has not been through the pipeline's moderation
+	if req.Result != nil {
+		if _, err := o.moderateResult(r.Context(), req.SessionID, result); err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Lesson blocked",
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+	// The HTML is always rendered from the lesson, never taken from the client
+	result.LessonHTML = lessonHTML(result.Lesson)
+
 	// Log what we're saving for debugging
 	o.logger.WithFields(logrus.Fields{
 		"session_id":    req.SessionID,
@@ -711,8 +1138,11 @@ func (o *Orchestrator) saveLessonHandler(w http.ResponseWriter, r *http.Request)
 		UserID:          userID,
 		Topic:           session.Topic,
 		Title:           title,
+		Summary:         result.Summary,
 		ExplanationType: explanationType,
 		Result:          result,
+		Tags:            tags,
+		CourseID:        courseID,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
@@ -722,6 +1152,9 @@ func (o *Orchestrator) saveLessonHandler(w http.ResponseWriter, r *http.Request)
 	o.savedLessons[savedID] = savedLesson
 	o.mu.Unlock()
 
+	// Keep the lesson's images beyond the temporary session expiry
+	o.runArtifactTask(func(ctx context.Context) { o.promoteSavedLessonImages(ctx, savedID) })
+
 	o.logger.WithFields(logrus.Fields{
 		"saved_id":   savedID,
 		"session_id": req.SessionID,
@@ -739,7 +1172,7 @@ func (o *Orchestrator) saveLessonHandler(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
-// getSavedLessonsHandler handles GET /api/saved/{userID}
+// getSavedLessonsHandler handles GET /api/saved/{userID}?tag=&course_id=&sort=recent|favorites|views
 func (o *Orchestrator) getSavedLessonsHandler(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "userID")
 	w.Header().Set("Content-Type", "application/json")
@@ -753,24 +1186,28 @@ func (o *Orchestrator) getSavedLessonsHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	filter := groupingFilterFromQuery(r)
+	sortMode, err := parseSavedSort(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Invalid sort",
+			"message": err.Error(),
+		})
+		return
+	}
+
 	o.mu.RLock()
 	savedLessons := make([]*SavedLesson, 0)
 	for _, lesson := range o.savedLessons {
-		if lesson.UserID == userID {
+		if lesson.UserID == userID && !lesson.isDeleted() && filter.matches(lesson.Tags, lesson.CourseID) {
 			savedLessons = append(savedLessons, lesson)
 		}
 	}
+	// Newest first unless another order was asked for
+	sortSavedLessons(savedLessons, sortMode)
 	o.mu.RUnlock()
 
-	// Sort by created date (newest first)
-	for i := 0; i < len(savedLessons)-1; i++ {
-		for j := i + 1; j < len(savedLessons); j++ {
-			if savedLessons[i].CreatedAt.Before(savedLessons[j].CreatedAt) {
-				savedLessons[i], savedLessons[j] = savedLessons[j], savedLessons[i]
-			}
-		}
-	}
-
 	response := map[string]interface{}{
 		"lessons": savedLessons,
 		"count":   len(savedLessons),
@@ -795,11 +1232,14 @@ func (o *Orchestrator) getSavedLessonHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	o.mu.RLock()
+	o.mu.Lock()
 	savedLesson, exists := o.savedLessons[savedID]
-	o.mu.RUnlock()
+	if exists && !savedLesson.isDeleted() && savedLesson.UserID == userID {
+		savedLesson.recordView(time.Now())
+	}
+	o.mu.Unlock()
 
-	if !exists {
+	if !exists || savedLesson.isDeleted() {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"error":   "Saved lesson not found",
@@ -835,28 +1275,39 @@ func (o *Orchestrator) deleteSavedLessonHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	// Lessons are moved to the trash unless permanent deletion is requested
+	permanent := r.URL.Query().Get("permanent") == "true"
+
 	o.mu.Lock()
 	savedLesson, exists := o.savedLessons[savedID]
-	if exists && savedLesson.UserID == userID {
-		delete(o.savedLessons, savedID)
+	if exists && savedLesson.UserID == userID && (permanent || !savedLesson.isDeleted()) {
+		response := map[string]interface{}{
+			"success": true,
+		}
+		if permanent {
+			delete(o.savedLessons, savedID)
+			o.runArtifactTask(func(ctx context.Context) { o.releaseSavedLessonImages(ctx, savedLesson) })
+			response["message"] = "Saved lesson deleted successfully"
+		} else {
+			now := time.Now()
+			savedLesson.DeletedAt = &now
+			response["message"] = "Saved lesson moved to trash"
+			response["purge_at"] = now.Add(o.trashRetentionPeriod())
+		}
 		o.mu.Unlock()
 
 		o.logger.WithFields(logrus.Fields{
-			"saved_id": savedID,
-			"user_id":  userID,
+			"saved_id":  savedID,
+			"user_id":   userID,
+			"permanent": permanent,
 		}).Info("Saved lesson deleted")
 
-		response := map[string]interface{}{
-			"success": true,
-			"message": "Saved lesson deleted successfully",
-		}
-
 		json.NewEncoder(w).Encode(response)
 		return
 	}
 	o.mu.Unlock()
 
-	if !exists {
+	if !exists || savedLesson.isDeleted() {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"error":   "Saved lesson not found",
@@ -890,14 +1341,14 @@ func (o *Orchestrator) setupRoutes() *chi.Mux {
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(realIPMiddleware(o.trustedProxies))
 	r.Use(middleware.Timeout(60 * time.Second))
 
 	// CORS for development
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", auth.APIKeyHeader, challengeTokenHeader, fingerprintHeader},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
 		MaxAge:           300,
@@ -906,39 +1357,194 @@ func (o *Orchestrator) setupRoutes() *chi.Mux {
 	// Routes
 	r.Get("/health", o.heartbeatHandler)
 	r.Get("/healthz", o.heartbeatHandler) // Cloud Run health check endpoint
+	r.Get("/readyz", o.readyzHandler)     // Dependency policies and degradation state
 	r.Route("/api", func(r chi.Router) {
+		// Authenticate with an API key or a JWT; see AUTH_REQUIRED
+		r.Use(auth.HTTPMiddleware(o.apiKeys, o.authClient, o.authRequired))
+		r.Use(o.grantAdmins)
+
+		// Evaluate feature flags once per request for handlers that gate on them
+		r.Use(flags.Middleware(o.flagService))
+
 		r.Route("/sessions", func(r chi.Router) {
+			// Restrict institutional deployments to allowed networks and countries
+			r.Use(o.ipAccessMiddleware)
+
 			// Public endpoints (no auth required, but quota limited)
 			r.Group(func(r chi.Router) {
 				// Add quota middleware for rate limiting and cost tracking
-				r.Use(o.quotaMiddleware())
+				r.Use(o.quotaMiddleware(routeClassExpensive))
+				r.Use(o.requireScope(auth.ScopeSessionsWrite))
 				r.Post("/", o.createSessionHandler)
+				r.Post("/from-saved/{savedID}", o.createSessionFromSavedHandler)
 				r.Post("/{id}/run", o.runSessionHandler)
+				r.With(o.quotaMiddleware(routeClassCheap)).Post("/{id}/cancel", o.cancelSessionHandler)
+				r.Post("/{id}/questions", o.askQuestionHandler)
+				r.Post("/{id}/misconception-checks", o.answerMisconceptionChecksHandler)
+				r.Post("/{id}/regenerate", o.regenerateSectionsHandler)
+				r.With(o.quotaMiddleware(routeClassCheap)).Post("/{id}/context-feedback", o.contextFeedbackHandler)
+				r.With(o.quotaMiddleware(routeClassCheap)).Post("/{id}/steps/{step}/approve", o.approveStepHandler)
+				r.With(o.quotaMiddleware(routeClassCheap)).Post("/{id}/steps/{step}/reject", o.rejectStepHandler)
+				r.With(o.requireScope(auth.ScopeAdmin)).Post("/import", o.importSessionHandler)
 			})
 
 			// Protected endpoints (auth required)
 			r.Group(func(r chi.Router) {
-				// TODO: Add service authentication middleware for Chi router
-				// r.Use(auth.ServiceAuthMiddleware(o.authClient))
+				r.Use(o.quotaMiddleware(routeClassCheap))
+				r.Use(o.requireScope(auth.ScopeSessionsRead))
+				r.Get("/", o.listSessionsHandler)
 				r.Get("/{id}/result", o.getSessionResultHandler)
+				r.Get("/{id}/artifacts", o.listSessionArtifactsHandler)
+				r.Get("/{id}/artifacts/{step}/{name}", o.getSessionArtifactHandler)
+				r.Get("/{id}/status", o.getSessionStatusHandler)
+				r.Get("/{id}/graph", o.getSessionGraphHandler)
+				r.Get("/{id}/context", o.getSessionContextHandler)
+				r.Get("/{id}/events", o.sessionEventsHandler)
+				r.Get("/{id}/export", o.exportSessionHandler)
+				r.With(o.requireScope(auth.ScopeSessionsWrite)).Put("/{id}/grouping", o.putSessionGroupingHandler)
 			})
 		})
 
 		// BrainPrint endpoints (no quota middleware - read-only, lightweight)
 		r.Get("/brainprint/{userID}", o.getBrainPrintHandler)
+		r.Post("/brainprint/{userID}/feedback", o.brainprintFeedbackHandler)
 		
 		// Session completion endpoint (no quota middleware - called after session completes)
 		r.Post("/session/complete", o.sessionCompleteHandler)
 		
-		// Tips endpoint (no quota middleware - read-only, lightweight)
-		r.Get("/tips", o.getTipsHandler)
+		// Tips endpoint (read-only, lightweight)
+		r.With(o.quotaMiddleware(routeClassCheap)).Get("/tips", o.getTipsHandler)
+		r.With(o.quotaMiddleware(routeClassCheap)).Post("/tips/next", o.nextTipHandler)
+
+		// Models callers may request per session
+		r.Get("/models", o.listModelsHandler)
+
+		// Diagram style presets callers may request per session
+		r.Get("/image-styles", o.listImageStylesHandler)
+
+		// Agent liveness as seen by the health monitor
+		r.With(o.requireScope(auth.ScopeAdmin)).Get("/agents/health", o.agentHealthHandler)
+
+		// Step latency, token and cost budget alarms
+		r.With(o.requireScope(auth.ScopeAdmin)).Get("/alarms", o.budgetAlarmsHandler)
+		r.With(o.requireScope(auth.ScopeAdmin)).Get("/alarms/metrics", o.budgetAlarmMetricsHandler)
+
+		// Live events of all sessions for ops dashboards
+		r.With(o.requireScope(auth.ScopeAdmin)).Get("/admin/events/stream", o.adminEventStreamHandler)
+
+		// Decrypted explainer scratchpads of a session, for debugging
+		r.With(o.requireScope(auth.ScopeAdmin)).Get("/admin/sessions/{id}/scratchpad", o.scratchpadHandler)
+
+		// Rendered prompts, context and generation config of each step of a traced session
+		r.With(o.requireScope(auth.ScopeAdmin)).Get("/admin/sessions/{id}/prompts", o.promptTracesHandler)
+
+		// Shadow agent outputs recorded next to the real agents' for offline comparison
+		r.With(o.requireScope(auth.ScopeAdmin)).Get("/admin/shadow", o.shadowRecordsHandler)
+
+		// IP access rules and the audit trail of requests they rejected
+		r.With(o.requireScope(auth.ScopeAdmin)).Get("/admin/ip-access", o.ipAccessHandler)
+
+		// Before/after quality, latency, retry and cost report of two deployment versions or time ranges
+		r.With(o.requireScope(auth.ScopeAdmin)).Get("/admin/sessions/compare", o.sessionComparisonHandler)
+
+		// Prompt A/B experiments and how their variants perform
+		r.With(o.requireScope(auth.ScopeAdmin)).Get("/experiments", o.listExperimentsHandler)
+		r.With(o.requireScope(auth.ScopeAdmin)).Get("/experiments/{id}/report", o.experimentReportHandler)
+
+		// Critique rubric management endpoints
+		r.Route("/rubrics", func(r chi.Router) {
+			r.Get("/", o.listRubricsHandler)
+			r.With(o.requireScope(auth.ScopeAdmin)).Post("/", o.putRubricHandler)
+			r.Get("/{id}", o.getRubricHandler)
+			r.With(o.requireScope(auth.ScopeAdmin)).Put("/{id}", o.putRubricHandler)
+			r.With(o.requireScope(auth.ScopeAdmin)).Delete("/{id}", o.deleteRubricHandler)
+		})
+
+		// Feature flag management and evaluation endpoints
+		r.Route("/flags", func(r chi.Router) {
+			r.Get("/", o.listFlagsHandler)
+			r.Get("/evaluate", o.evaluateFlagsHandler)
+			r.Get("/{key}", o.getFlagHandler)
+			r.With(o.requireScope(auth.ScopeAdmin)).Put("/{key}", o.putFlagHandler)
+			r.With(o.requireScope(auth.ScopeAdmin)).Delete("/{key}", o.deleteFlagHandler)
+		})
+
+		// Course grouping and progress endpoints
+		r.Route("/courses", func(r chi.Router) {
+			r.Use(o.requireScope(auth.ScopeSessionsRead))
+			r.With(o.requireScope(auth.ScopeSessionsWrite), o.quotaMiddleware(routeClassExpensive)).Post("/generate", o.generateCourseHandler)
+			r.Get("/", o.listCoursesHandler)
+			r.Get("/{courseID}", o.getCourseHandler)
+			r.Get("/{courseID}/export", o.exportCourseHandler)
+		})
+
+		// API key management endpoints; ownership is checked per key
+		r.Route("/keys", func(r chi.Router) {
+			r.Get("/", o.listAPIKeysHandler)
+			r.Post("/", o.createAPIKeyHandler)
+			r.Delete("/{id}", o.revokeAPIKeyHandler)
+		})
+
+		// User data erasure; the caller must be the user or an admin
+		r.Route("/users/{id}/data", func(r chi.Router) {
+			r.Delete("/", o.deleteUserDataHandler)
+			r.Get("/deletions/{jobID}", o.getDataDeletionHandler)
+		})
+
+		// This month's usage for the usage page; the caller must be the user or an admin
+		r.Get("/users/{id}/usage", o.getUserUsageHandler)
+
+		// Learning goals with mastery estimates; the caller must be the user or an admin
+		r.Route("/goals/{userID}", func(r chi.Router) {
+			r.Get("/", o.getGoalsHandler)
+			r.Post("/", o.createGoalHandler)
+			r.Post("/evidence", o.recordGoalEvidenceHandler)
+			r.Delete("/{goalID}", o.deleteGoalHandler)
+		})
+
+		// Study assistant over a user's own saved lessons; the caller must be the user or an admin.
+		// Each question calls the LLM, so it is limited like other expensive routes.
+		r.With(o.quotaMiddleware(routeClassExpensive)).Post("/library/{userID}/chat", o.libraryChatHandler)
+
+		// Organization content library: authors submit, admins review, members browse published lessons
+		r.Route("/orgs/{orgID}/library", func(r chi.Router) {
+			r.Get("/", o.listLibraryHandler)
+			r.Post("/", o.createLibraryEntryHandler)
+			r.Get("/{entryID}", o.getLibraryEntryHandler)
+			r.Post("/{entryID}/submit", o.submitLibraryEntryHandler)
+			r.Post("/{entryID}/review", o.reviewLibraryEntryHandler)
+		})
+
+		// Organization policy: defaults and restrictions enforced on members' new sessions
+		r.Route("/orgs/{orgID}/policy", func(r chi.Router) {
+			r.Get("/", o.getOrgPolicyHandler)
+			r.Put("/", o.putOrgPolicyHandler)
+			r.Delete("/", o.deleteOrgPolicyHandler)
+		})
+
+		// Notification channel preferences per user or organization
+		r.Route("/notifications/{scope}/{id}", func(r chi.Router) {
+			r.Get("/", o.getNotificationPrefsHandler)
+			r.Put("/", o.putNotificationPrefsHandler)
+		})
 
 		// Saved lessons endpoints
 		r.Route("/saved", func(r chi.Router) {
 			r.Post("/", o.saveLessonHandler)
 			r.Get("/{userID}", o.getSavedLessonsHandler)
+			r.Get("/{userID}/trash", o.getTrashHandler)
+			r.Post("/{userID}/export", o.startLibraryExportHandler)
+			r.Get("/{userID}/export/{exportID}", o.getLibraryExportHandler)
+			r.Get("/{userID}/export/{exportID}/events", o.libraryExportEventsHandler)
 			r.Get("/{userID}/{id}", o.getSavedLessonHandler)
+			r.Get("/{userID}/{id}/slides", o.getSavedLessonSlidesHandler)
+			r.Put("/{userID}/{id}/grouping", o.putSavedLessonGroupingHandler)
+			r.Put("/{userID}/{id}/favorite", o.favoriteSavedLessonHandler(true))
+			r.Delete("/{userID}/{id}/favorite", o.favoriteSavedLessonHandler(false))
 			r.Delete("/{userID}/{id}", o.deleteSavedLessonHandler)
+			r.Post("/{userID}/{id}/restore", o.restoreSavedLessonHandler)
+			r.Post("/{userID}/{id}/revisions/{revisionID}/accept", o.reviewRevisionHandler(RevisionStatusAccepted))
+			r.Post("/{userID}/{id}/revisions/{revisionID}/reject", o.reviewRevisionHandler(RevisionStatusRejected))
 		})
 	})
 
@@ -946,20 +1552,25 @@ func (o *Orchestrator) setupRoutes() *chi.Mux {
 }
 
 // quotaMiddleware creates a quota middleware for the orchestrator
-func (o *Orchestrator) quotaMiddleware() func(http.Handler) http.Handler {
+// Each route class draws from its own per-IP token bucket
+func (o *Orchestrator) quotaMiddleware(class routeClass) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Get client IP
-			ip := r.RemoteAddr
-			if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-				ip = xff
+			limiter := o.routeLimiter(class)
+			if limiter == nil {
+				next.ServeHTTP(w, r)
+				return
 			}
 
+			// Get client IP
+			ip := clientIP(r)
+
 			// Check rate limit
-			if !o.quotaManager.RateLimiter.Allow(ip) {
+			if !limiter.Allow(ip) {
 				o.logger.WithFields(logrus.Fields{
-					"ip":   ip,
-					"path": r.URL.Path,
+					"ip":          ip,
+					"path":        r.URL.Path,
+					"route_class": class,
 				}).Warn("Rate limit exceeded")
 
 				w.Header().Set("Content-Type", "application/json")
@@ -969,6 +1580,7 @@ func (o *Orchestrator) quotaMiddleware() func(http.Handler) http.Handler {
 					"message":     "Too many requests from your IP. Please try again later.",
 					"retry_after": 60,
 					"quota_type":  "rate_limit",
+					"route_class": class,
 				})
 				return
 			}
@@ -980,8 +1592,28 @@ func (o *Orchestrator) quotaMiddleware() func(http.Handler) http.Handler {
 }
 
 func main() {
+	// --lite runs the agents in-process with canned responses, so the app can be demoed without keys
+	lite := flag.Bool("lite", false, "run agents in-process with canned responses (same as AGENT_MODE=lite)")
+	// --demo also swaps Firestore for a local SQLite file and seeds data, so every API can be explored without cloud credentials
+	demo := flag.Bool("demo", false, "run lite agents on SQLite storage with seeded demo data (same as DEMO_MODE=true)")
+	flag.Parse()
+	if *demo {
+		os.Setenv("DEMO_MODE", "true")
+	}
+	if *lite || demoModeFromEnv() {
+		os.Setenv("AGENT_MODE", agentModeLite)
+	}
+	if demoModeFromEnv() && sqlitePathFromEnv() == "" {
+		os.Setenv("SQLITE_PATH", defaultDemoDBPath)
+	}
+
 	// Create orchestrator
 	orchestrator := NewOrchestrator()
+	if demoModeFromEnv() {
+		if err := orchestrator.seedDemoData(context.Background()); err != nil {
+			orchestrator.logger.Fatalf("Failed to seed demo data: %v", err)
+		}
+	}
 
 	// Setup routes
 	router := orchestrator.setupRoutes()
@@ -1000,6 +1632,28 @@ func main() {
 		}
 	}()
 
+	// Start nightly refresh of stale saved lessons if enabled
+	refreshCtx, stopRefresh := context.WithCancel(context.Background())
+	defer stopRefresh()
+	if refreshConfig := DefaultLessonRefreshConfig(); refreshConfig.Enabled {
+		go NewLessonRefresher(orchestrator, refreshConfig).Start(refreshCtx)
+	}
+
+	// Health-check the agents and take persistently failing ones out of rotation
+	go orchestrator.pipeline.startAgentMonitor(refreshCtx)
+
+	// Raise alarms when step latency, tokens per session or hourly cost pass their thresholds
+	go orchestrator.startBudgetMonitor(refreshCtx)
+
+	// Purge saved lessons that have been in the trash past the retention period
+	go orchestrator.startTrashPurger(refreshCtx, trashPurgeInterval)
+
+	// Start the due chapters of courses generated from a syllabus
+	go orchestrator.startCourseScheduler(refreshCtx, courseSchedulerInterval)
+
+	// Expire images of sessions that are never saved
+	orchestrator.runArtifactTask(orchestrator.ensureTempExpiry)
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// MisconceptionQuizzer writes true/false checks for a topic's common misconceptions
+type MisconceptionQuizzer interface {
+	GenerateMisconceptionChecks(ctx context.Context, topic string, misconceptions []string) ([]llm.MisconceptionCheck, error)
+}
+
+// MisconceptionQuestion is a misconception check as shown to the learner, without its answer
+type MisconceptionQuestion struct {
+	ID        string `json:"id"`
+	Statement string `json:"statement"`
+}
+
+// MisconceptionAnswersRequest carries a learner's true/false answers by check ID
+type MisconceptionAnswersRequest struct {
+	UserID  string          `json:"user_id,omitempty"` // Defaults to the authenticated user, then the session's user_id
+	Answers map[string]bool `json:"answers"`
+}
+
+// MisconceptionCheckResult is the outcome of one answered check
+type MisconceptionCheckResult struct {
+	ID          string `json:"id"`
+	Statement   string `json:"statement"`
+	Answer      bool   `json:"answer"`
+	Correct     bool   `json:"correct"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// MisconceptionAnswersResponse scores a learner's answers and lists the misconceptions they still hold
+type MisconceptionAnswersResponse struct {
+	SessionID      string                     `json:"session_id"`
+	Results        []MisconceptionCheckResult `json:"results"`
+	Score          float64                    `json:"score"` // Fraction answered correctly, 0-1
+	Misconceptions []string                   `json:"misconceptions,omitempty"`
+}
+
+// summarizerMisconceptions returns the misconceptions listed by the summarizer step
+func summarizerMisconceptions(finalResult map[string]interface{}) []string {
+	summarizer, ok := finalResult["summarizer"].(map[string]string)
+	if !ok || summarizer["misconceptions"] == "" {
+		return nil
+	}
+	var listed []string
+	if err := json.Unmarshal([]byte(summarizer["misconceptions"]), &listed); err != nil {
+		return nil
+	}
+	misconceptions := make([]string, 0, len(listed))
+	for _, misconception := range listed {
+		if misconception = strings.TrimSpace(misconception); misconception != "" {
+			misconceptions = append(misconceptions, misconception)
+		}
+	}
+	return misconceptions
+}
+
+// misconceptionChecks builds the checks appended to a lesson, falling back to restating the
+// misconceptions when the model is unavailable. Lessons with too few misconceptions get none.
+func (o *Orchestrator) misconceptionChecks(ctx context.Context, sessionID, topic string, finalResult map[string]interface{}) []llm.MisconceptionCheck {
+	misconceptions := summarizerMisconceptions(finalResult)
+	if len(misconceptions) < llm.MinMisconceptionChecks {
+		return nil
+	}
+	if o.quizClient != nil {
+		checks, err := o.quizClient.GenerateMisconceptionChecks(ctx, topic, misconceptions)
+		if err == nil {
+			return checks
+		}
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"error":      err,
+		}).Warn("Failed to generate misconception checks, restating misconceptions instead")
+	}
+	return llm.MisconceptionChecksFromList(misconceptions)
+}
+
+// setMisconceptionChecks attaches checks to a result: the statements are public, the answer key is kept server-side
+func (r *SessionResult) setMisconceptionChecks(checks []llm.MisconceptionCheck) {
+	r.misconceptionKey = checks
+	r.MisconceptionChecks = make([]MisconceptionQuestion, len(checks))
+	for i, check := range checks {
+		r.MisconceptionChecks[i] = MisconceptionQuestion{ID: check.ID, Statement: check.Statement}
+	}
+}
+
+// misconceptionUser identifies whose BrainPrint answers are recorded against: the authenticated
+// user, else the request's user_id, else the session's user_id metadata
+func misconceptionUser(r *http.Request, req MisconceptionAnswersRequest, session *Session) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.UserID != "" {
+		return principal.UserID
+	}
+	if req.UserID != "" {
+		return req.UserID
+	}
+	userID, _ := session.Metadata["user_id"].(string)
+	return userID
+}
+
+// answerMisconceptionChecksHandler handles POST /api/sessions/{id}/misconception-checks
+// It scores the learner's answers and records the misconceptions they still hold in their BrainPrint.
+func (o *Orchestrator) answerMisconceptionChecksHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	session, ok := o.completedSession(w, sessionID)
+	if !ok {
+		return
+	}
+	checks := session.Result.misconceptionKey
+	if len(checks) == 0 {
+		http.Error(w, "Session has no misconception checks", http.StatusNotFound)
+		return
+	}
+
+	var req MisconceptionAnswersRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Answers) == 0 {
+		http.Error(w, "At least one answer is required", http.StatusBadRequest)
+		return
+	}
+	known := make(map[string]bool, len(checks))
+	for _, check := range checks {
+		known[check.ID] = true
+	}
+	for id := range req.Answers {
+		if !known[id] {
+			http.Error(w, fmt.Sprintf("Unknown misconception check: %s", id), http.StatusBadRequest)
+			return
+		}
+	}
+
+	response := MisconceptionAnswersResponse{SessionID: sessionID, Results: make([]MisconceptionCheckResult, 0, len(req.Answers))}
+	var corrected []string
+	for _, check := range checks {
+		answer, answered := req.Answers[check.ID]
+		if !answered {
+			continue
+		}
+		correct := answer == check.Answer
+		response.Results = append(response.Results, MisconceptionCheckResult{
+			ID:          check.ID,
+			Statement:   check.Statement,
+			Answer:      check.Answer,
+			Correct:     correct,
+			Explanation: check.Explanation,
+		})
+		if correct {
+			response.Score++
+			corrected = append(corrected, check.Misconception)
+		} else {
+			response.Misconceptions = append(response.Misconceptions, check.Misconception)
+		}
+	}
+	response.Score /= float64(len(response.Results))
+
+	if userID := misconceptionUser(r, req, session); userID != "" {
+		o.recordBrainPrintQuiz(r.Context(), userID, sessionID, response.Score)
+		if o.brainprintSvc != nil {
+			if err := o.brainprintSvc.RecordMisconceptionChecks(r.Context(), userID, session.Topic, sessionID, response.Misconceptions, corrected); err != nil {
+				o.logger.WithFields(logrus.Fields{
+					"user_id":    userID,
+					"session_id": sessionID,
+					"error":      err,
+				}).Warn("Failed to record misconceptions for BrainPrint")
+			}
+		}
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"answered":   len(response.Results),
+		"score":      response.Score,
+	}).Info("Misconception checks answered")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// misconceptionTip returns a follow-up tip for the misconception a user most recently held, if any
+func (o *Orchestrator) misconceptionTip(ctx context.Context, userID string) (string, bool) {
+	if o.brainprintSvc == nil || userID == "" {
+		return "", false
+	}
+	held, ok := o.brainprintSvc.FollowUpMisconception(ctx, userID)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("Revisit %s: your last check suggested you still believe \"%s\", a common misconception.", held.Topic, held.Misconception), true
+}
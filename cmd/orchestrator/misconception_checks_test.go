@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/brainprint"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingQuizzer is a MisconceptionQuizzer whose model calls always fail
+type failingQuizzer struct{}
+
+func (failingQuizzer) GenerateMisconceptionChecks(ctx context.Context, topic string, misconceptions []string) ([]llm.MisconceptionCheck, error) {
+	return nil, errors.New("model unavailable")
+}
+
+// TestMisconceptionChecksFromSummarizer tests check generation from the summarizer's misconceptions
+func TestMisconceptionChecksFromSummarizer(t *testing.T) {
+	o := &Orchestrator{logger: logrus.New(), quizClient: failingQuizzer{}}
+	finalResult := map[string]interface{}{
+		"summarizer": map[string]string{"misconceptions": `["Heaps are sorted", "A heap is a tree of pointers", " "]`},
+	}
+
+	checks := o.misconceptionChecks(context.Background(), "s1", "Heaps", finalResult)
+	require.Len(t, checks, 2)
+	assert.Equal(t, "Heaps are sorted", checks[0].Misconception)
+	assert.False(t, checks[0].Answer)
+
+	// A single misconception is not worth a quiz
+	finalResult["summarizer"] = map[string]string{"misconceptions": `["Heaps are sorted"]`}
+	assert.Empty(t, o.misconceptionChecks(context.Background(), "s1", "Heaps", finalResult))
+}
+
+// TestAnswerMisconceptionChecks tests scoring answers, hiding the answer key and recording held misconceptions
+func TestAnswerMisconceptionChecks(t *testing.T) {
+	result := &SessionResult{Lesson: "{}"}
+	result.setMisconceptionChecks([]llm.MisconceptionCheck{
+		{ID: "check-1", Statement: "Heaps are fully sorted", Answer: false, Explanation: "Only the root is ordered.", Misconception: "Heaps are sorted"},
+		{ID: "check-2", Statement: "A heap can be stored in an array", Answer: true, Misconception: "A heap is a tree of pointers"},
+	})
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"s1": {ID: "s1", Topic: "Heaps", Status: "completed", Result: result, Metadata: map[string]interface{}{"user_id": "u1", "explanation_type": "simple"}},
+		},
+		brainprintSvc: brainprint.NewService(nil),
+		logger:        logrus.New(),
+	}
+
+	public, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(public), "Heaps are fully sorted")
+	assert.NotContains(t, string(public), "Only the root is ordered.")
+
+	router := chi.NewRouter()
+	router.Post("/api/sessions/{id}/misconception-checks", o.answerMisconceptionChecksHandler)
+	router.Get("/api/tips", o.getTipsHandler)
+
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(router, "POST", "/api/sessions/s1/misconception-checks", "", `{"answers": {"check-9": true}}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(router, "POST", "/api/sessions/s1/misconception-checks", "", `{"answers": {}}`).Code)
+
+	w := serveWithKey(router, "POST", "/api/sessions/s1/misconception-checks", "", `{"answers": {"check-1": true, "check-2": true}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response MisconceptionAnswersResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 0.5, response.Score)
+	assert.Equal(t, []string{"Heaps are sorted"}, response.Misconceptions)
+	require.Len(t, response.Results, 2)
+	assert.Equal(t, "Only the root is ordered.", response.Results[0].Explanation)
+
+	profile, err := o.brainprintSvc.GetBrainPrint(context.Background(), "u1")
+	require.NoError(t, err)
+	require.Len(t, profile.Misconceptions, 1)
+	assert.Equal(t, "Heaps", profile.Misconceptions[0].Topic)
+
+	w = serve(router, "GET", "/api/tips?user_id=u1")
+	var tip map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tip))
+	assert.Contains(t, tip["tip"], "Heaps are sorted")
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"os"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// newModelAllowlist creates the per-request model allowlist, loading it from MODEL_ALLOWLIST if set
+// (e.g. "gemini-2.5-flash=1,gemini-1.5-pro=3")
+func newModelAllowlist() *llm.ModelAllowlist {
+	spec := os.Getenv("MODEL_ALLOWLIST")
+	if spec == "" {
+		return llm.DefaultModelAllowlist()
+	}
+
+	allowlist, err := llm.ParseModelAllowlist(spec)
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid MODEL_ALLOWLIST, using default model allowlist")
+		return llm.DefaultModelAllowlist()
+	}
+	return allowlist
+}
+
+// clientIP returns the client IP used for rate limiting, without the port. realIPMiddleware has
+// already resolved it from a trusted proxy's forwarded headers, so they are not read here.
+func clientIP(r *http.Request) string {
+	return remoteHost(r)
+}
+
+// chargeModelQuota charges the extra rate limit tokens a model override costs to the expensive
+// route class bucket. The quota middleware has already charged one token there for the request itself.
+func (o *Orchestrator) chargeModelQuota(r *http.Request, policy llm.ModelPolicy) bool {
+	limiter := o.routeLimiter(routeClassExpensive)
+	if limiter == nil {
+		return true
+	}
+
+	extra := int(math.Ceil(policy.QuotaMultiplier)) - 1
+	if extra <= 0 {
+		return true
+	}
+	return limiter.AllowN(clientIP(r), extra)
+}
+
+// resolveModelOverride validates a requested model against the allowlist and charges its quota.
+// It writes an error response and returns false if the request must be rejected.
+func (o *Orchestrator) resolveModelOverride(w http.ResponseWriter, r *http.Request, model string) (llm.ModelPolicy, bool) {
+	if o.modelAllowlist == nil {
+		writeModelError(w, http.StatusBadRequest, "Model overrides are not enabled", nil)
+		return llm.ModelPolicy{}, false
+	}
+
+	policy, allowed := o.modelAllowlist.Lookup(model)
+	if !allowed {
+		o.logger.WithFields(logrus.Fields{
+			"model": model,
+		}).Warn("Rejected model override not on allowlist")
+		writeModelError(w, http.StatusBadRequest, "Model is not allowed", map[string]interface{}{
+			"allowed_models": o.modelAllowlist.List(),
+		})
+		return llm.ModelPolicy{}, false
+	}
+
+	if !o.chargeModelQuota(r, policy) {
+		o.logger.WithFields(logrus.Fields{
+			"model":            policy.Name,
+			"quota_multiplier": policy.QuotaMultiplier,
+		}).Warn("Rate limit exceeded for model override")
+		writeModelError(w, http.StatusTooManyRequests, "Rate limit exceeded", map[string]interface{}{
+			"message":          "This model costs more quota than you have remaining. Try again later or use the default model.",
+			"retry_after":      60,
+			"quota_type":       "model_multiplier",
+			"quota_multiplier": policy.QuotaMultiplier,
+		})
+		return llm.ModelPolicy{}, false
+	}
+
+	return policy, true
+}
+
+// writeModelError writes a JSON error response for a model override
+func writeModelError(w http.ResponseWriter, status int, message string, extra map[string]interface{}) {
+	body := map[string]interface{}{
+		"error":   message,
+		"message": message,
+	}
+	for k, v := range extra {
+		body[k] = v
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// listModelsHandler handles GET /api/models
+func (o *Orchestrator) listModelsHandler(w http.ResponseWriter, r *http.Request) {
+	models := make([]llm.ModelPolicy, 0)
+	if o.modelAllowlist != nil {
+		models = o.modelAllowlist.List()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"models":  models,
+		"default": llm.DefaultModel,
+	})
+}
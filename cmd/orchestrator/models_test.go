@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/InnoFusionTech/ExplainIQ/internal/quota"
+	"github.com/InnoFusionTech/ExplainIQ/internal/rate_limiter"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newModelsTestOrchestrator creates an orchestrator with a model allowlist and a small rate limit burst
+func newModelsTestOrchestrator(burst int) *Orchestrator {
+	allowlist, _ := llm.ParseModelAllowlist("gemini-2.5-flash=1,gemini-1.5-pro=3")
+	return &Orchestrator{
+		sessions:       make(map[string]*Session),
+		savedLessons:   make(map[string]*SavedLesson),
+		logger:         logrus.New(),
+		clients:        make(map[string][]chan SSEEvent),
+		quotaManager:   quota.NewQuotaManager(rate_limiter.NewLimiter(1000, 1000), nil),
+		routeLimiters:  map[routeClass]*rate_limiter.Limiter{routeClassExpensive: rate_limiter.NewLimiter(0.001, burst)},
+		modelAllowlist: allowlist,
+	}
+}
+
+// createSession posts a create session request to the handler
+func createSession(o *Orchestrator, req CreateSessionRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	o.createSessionHandler(w, httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body)))
+	return w
+}
+
+// TestCreateSessionModelOverride tests that allowed model overrides are stored on the session
+func TestCreateSessionModelOverride(t *testing.T) {
+	o := newModelsTestOrchestrator(10)
+
+	w := createSession(o, CreateSessionRequest{Topic: "Raft", Model: "gemini-1.5-pro"})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var response CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	session, _ := o.GetSession(response.ID)
+	assert.Equal(t, "gemini-1.5-pro", session.Metadata["model"])
+	assert.Equal(t, 3.0, session.Metadata["quota_multiplier"])
+}
+
+// TestCreateSessionModelNotAllowed tests rejection of models outside the allowlist
+func TestCreateSessionModelNotAllowed(t *testing.T) {
+	o := newModelsTestOrchestrator(10)
+
+	w := createSession(o, CreateSessionRequest{Topic: "Raft", Model: "gemini-ultra"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "allowed_models")
+	assert.Empty(t, o.sessions)
+}
+
+// TestCreateSessionModelQuotaMultiplier tests that expensive models consume extra rate limit tokens
+func TestCreateSessionModelQuotaMultiplier(t *testing.T) {
+	o := newModelsTestOrchestrator(3)
+
+	// The 3x model needs two extra tokens on top of the middleware's one
+	require.Equal(t, http.StatusCreated, createSession(o, CreateSessionRequest{Topic: "Raft", Model: "gemini-1.5-pro"}).Code)
+	w := createSession(o, CreateSessionRequest{Topic: "Raft", Model: "gemini-1.5-pro"})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "model_multiplier")
+
+	// The default-cost model is unaffected by the multiplier charge
+	assert.Equal(t, http.StatusCreated, createSession(o, CreateSessionRequest{Topic: "Raft", Model: "gemini-2.5-flash"}).Code)
+}
+
+// TestClientIP tests that rate limits key on the resolved client address, not its port or client-sent headers
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	assert.Equal(t, "203.0.113.7", clientIP(req))
+
+	req.RemoteAddr = "[2001:db8::1]:443"
+	assert.Equal(t, "2001:db8::1", clientIP(req))
+}
+
+// TestCriticModelCrossCheck tests that a configured critic model replaces the session's model for the critic only
+func TestCriticModelCrossCheck(t *testing.T) {
+	o := newModelsTestOrchestrator(10)
+	session := o.CreateSession("Caching")
+	session.Metadata = map[string]interface{}{"model": "gemini-2.5-flash"}
+
+	agent := &recordingAgentClient{inputs: make(map[string]map[string]string)}
+	config := DefaultPipelineConfig()
+	config.RetryDelay = time.Millisecond
+	config.CriticModel = "gemini-2.5-pro"
+	p := &Pipeline{
+		config:     config,
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+	require.NoError(t, p.runPipeline(context.Background(), session.ID, o))
+
+	assert.Equal(t, "gemini-2.5-flash", agent.inputs["explainer"]["model"])
+	assert.Equal(t, "gemini-2.5-pro", agent.inputs["critic"]["model"])
+}
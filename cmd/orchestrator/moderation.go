@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// moderationUnchecked records a lesson released without moderation because the moderator failed
+const moderationUnchecked = "unchecked"
+
+// errLessonBlocked is returned when moderation blocks a finished lesson
+var errLessonBlocked = errors.New("lesson blocked by content moderation")
+
+// moderatorFromEnv builds the lesson moderator from MODERATION_DENYLIST (comma-separated regexes),
+// MODERATION_DENYLIST_ACTION ("redact", the default, or "block") and MODERATION_SAFETY_CLASSIFIER.
+// It returns nil when no moderation is configured.
+func moderatorFromEnv() llm.Moderator {
+	var chain llm.ModerationChain
+
+	if patterns := os.Getenv("MODERATION_DENYLIST"); patterns != "" {
+		block := false
+		switch action := strings.ToLower(os.Getenv("MODERATION_DENYLIST_ACTION")); action {
+		case "", llm.ModerationRedacted, "redact":
+		case llm.ModerationBlocked, "block":
+			block = true
+		default:
+			logrus.WithField("value", action).Warn("Invalid MODERATION_DENYLIST_ACTION, redacting matches")
+		}
+		denylist, err := llm.NewDenylistModerator(strings.Split(patterns, ","), block)
+		if err != nil {
+			logrus.WithError(err).Warn("Invalid MODERATION_DENYLIST, denylist moderation disabled")
+		} else {
+			chain = append(chain, denylist)
+		}
+	}
+
+	if os.Getenv("MODERATION_SAFETY_CLASSIFIER") == "true" {
+		chain = append(chain, llm.NewSafetyModerator(llm.NewGeminiClient("")))
+	}
+
+	if len(chain) == 0 {
+		return nil
+	}
+	return chain
+}
+
+// moderationFields returns the result texts moderation checks: each lesson section, or the raw
+// lesson when it is not structured, and the summary
+func moderationFields(result *SessionResult, lesson *llm.OGLesson) []*string {
+	var fields []*string
+	if lesson != nil {
+		fields = append(fields,
+			&lesson.BigPicture,
+			&lesson.Metaphor,
+			&lesson.CoreMechanism,
+			&lesson.ToyExampleCode,
+			&lesson.MemoryHook,
+			&lesson.RealLife,
+			&lesson.BestPractices,
+		)
+	} else {
+		fields = append(fields, &result.Lesson)
+	}
+	return append(fields, &result.Summary)
+}
+
+// moderateResult runs a finished lesson through the moderator, redacting result in place and
+// recording the outcome in the session's "moderation" metadata. It returns errLessonBlocked if
+// the lesson must not be returned or saved. Moderator failures are logged and the lesson is
+// released unchecked.
+func (o *Orchestrator) moderateResult(ctx context.Context, sessionID string, result *SessionResult) (map[string]interface{}, error) {
+	if o.moderator == nil {
+		return nil, nil
+	}
+
+	lesson := parseLesson(result.Lesson)
+	fields := moderationFields(result, lesson)
+	texts := make([]string, len(fields))
+	for i, field := range fields {
+		texts[i] = *field
+	}
+
+	record := map[string]interface{}{"moderated_at": time.Now().Format(time.RFC3339)}
+	outcome, err := o.moderator.Moderate(ctx, texts)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"error":      err,
+		}).Warn("Lesson moderation failed, releasing lesson unchecked")
+		record["action"] = moderationUnchecked
+		record["error"] = err.Error()
+		o.recordModeration(sessionID, record)
+		return record, nil
+	}
+
+	record["action"] = outcome.Action
+	if len(outcome.Categories) > 0 {
+		record["categories"] = outcome.Categories
+	}
+	if outcome.Redactions > 0 {
+		record["redactions"] = outcome.Redactions
+	}
+	o.recordModeration(sessionID, record)
+
+	switch outcome.Action {
+	case llm.ModerationBlocked:
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"categories": outcome.Categories,
+		}).Warn("Lesson blocked by content moderation")
+		return record, errLessonBlocked
+	case llm.ModerationRedacted:
+		for i, field := range fields {
+			*field = outcome.Texts[i]
+		}
+		if lesson != nil {
+			if data, err := json.Marshal(lesson); err == nil {
+				result.Lesson = string(data)
+			}
+		}
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"redactions": outcome.Redactions,
+			"categories": outcome.Categories,
+		}).Info("Redacted lesson content flagged by moderation")
+	}
+	return record, nil
+}
+
+// recordModeration stores a moderation outcome in the session's metadata
+func (o *Orchestrator) recordModeration(sessionID string, record map[string]interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	session, ok := o.sessions[sessionID]
+	if !ok {
+		return
+	}
+	if session.Metadata == nil {
+		session.Metadata = make(map[string]interface{})
+	}
+	session.Metadata["moderation"] = record
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingModerator is a moderator whose checks always fail
+type failingModerator struct{}
+
+// Moderate implements llm.Moderator
+func (failingModerator) Moderate(ctx context.Context, texts []string) (*llm.ModerationResult, error) {
+	return nil, errors.New("classifier unavailable")
+}
+
+// newModerationOrchestrator creates an orchestrator holding one session, moderated by moderator
+func newModerationOrchestrator(moderator llm.Moderator) *Orchestrator {
+	return &Orchestrator{
+		sessions:  map[string]*Session{"session-1": {ID: "session-1", Metadata: map[string]interface{}{}}},
+		logger:    logrus.New(),
+		moderator: moderator,
+	}
+}
+
+// TestModerateResultRedacts tests that denylisted passages are redacted and the outcome recorded
+func TestModerateResultRedacts(t *testing.T) {
+	denylist, err := llm.NewDenylistModerator([]string{"forbidden"}, false)
+	require.NoError(t, err)
+	o := newModerationOrchestrator(denylist)
+
+	result := &SessionResult{
+		Lesson:  `{"big_picture":"A Forbidden idea","metaphor":"Like a map"}`,
+		Summary: "Nothing forbidden here",
+	}
+	record, err := o.moderateResult(context.Background(), "session-1", result)
+	require.NoError(t, err)
+
+	lesson := parseLesson(result.Lesson)
+	require.NotNil(t, lesson)
+	assert.Equal(t, "A "+llm.ModerationRedaction+" idea", lesson.BigPicture)
+	assert.Equal(t, "Like a map", lesson.Metaphor)
+	assert.Equal(t, "Nothing "+llm.ModerationRedaction+" here", result.Summary)
+
+	assert.Equal(t, llm.ModerationRedacted, record["action"])
+	assert.Equal(t, 2, record["redactions"])
+	assert.Equal(t, []string{"denylist:forbidden"}, record["categories"])
+	session, _ := o.GetSession("session-1")
+	assert.Equal(t, record, session.Metadata["moderation"])
+}
+
+// TestModerateResultBlocks tests that a blocked lesson is reported and left unchanged
+func TestModerateResultBlocks(t *testing.T) {
+	denylist, err := llm.NewDenylistModerator([]string{"forbidden"}, true)
+	require.NoError(t, err)
+	o := newModerationOrchestrator(denylist)
+
+	result := &SessionResult{Lesson: "Plain text with a forbidden word"}
+	record, err := o.moderateResult(context.Background(), "session-1", result)
+	assert.ErrorIs(t, err, errLessonBlocked)
+	assert.Equal(t, llm.ModerationBlocked, record["action"])
+	assert.Equal(t, "Plain text with a forbidden word", result.Lesson)
+}
+
+// TestModerateResultFailsOpen tests that moderator errors release the lesson unchecked
+func TestModerateResultFailsOpen(t *testing.T) {
+	o := newModerationOrchestrator(failingModerator{})
+
+	result := &SessionResult{Lesson: `{"big_picture":"Overview"}`}
+	record, err := o.moderateResult(context.Background(), "session-1", result)
+	require.NoError(t, err)
+	assert.Equal(t, moderationUnchecked, record["action"])
+	assert.Equal(t, "classifier unavailable", record["error"])
+	assert.Equal(t, `{"big_picture":"Overview"}`, result.Lesson)
+
+	// Without a moderator nothing is recorded
+	o = newModerationOrchestrator(nil)
+	record, err = o.moderateResult(context.Background(), "session-1", result)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
+
+// TestSaveLessonModeratesClientResult tests that results sent with a save request are moderated
+// like pipeline results
+func TestSaveLessonModeratesClientResult(t *testing.T) {
+	denylist, err := llm.NewDenylistModerator([]string{"forbidden"}, true)
+	require.NoError(t, err)
+	o := newModerationOrchestrator(denylist)
+	o.sessions["session-1"].Topic = "Caching"
+	o.savedLessons = make(map[string]*SavedLesson)
+
+	save := func(lesson string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{
+			"session_id": "session-1",
+			"result":     SessionResult{Lesson: lesson},
+		})
+		w := httptest.NewRecorder()
+		o.saveLessonHandler(w, httptest.NewRequest("POST", "/api/save", bytes.NewReader(body)))
+		return w
+	}
+
+	w := save("Plain text with a forbidden word")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Empty(t, o.savedLessons)
+
+	assert.Equal(t, http.StatusCreated, save("Plain text").Code)
+	assert.Len(t, o.savedLessons, 1)
+}
+
+// TestModeratorFromEnv tests building the moderator from the environment
+func TestModeratorFromEnv(t *testing.T) {
+	t.Setenv("MODERATION_DENYLIST", "")
+	assert.Nil(t, moderatorFromEnv())
+
+	t.Setenv("MODERATION_DENYLIST", "foo, bar")
+	t.Setenv("MODERATION_DENYLIST_ACTION", "block")
+	moderator := moderatorFromEnv()
+	require.NotNil(t, moderator)
+	outcome, err := moderator.Moderate(context.Background(), []string{"some bar"})
+	require.NoError(t, err)
+	assert.Equal(t, llm.ModerationBlocked, outcome.Action)
+
+	t.Setenv("MODERATION_DENYLIST", "(unclosed")
+	assert.Nil(t, moderatorFromEnv())
+}
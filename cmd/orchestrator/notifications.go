@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/cost_tracker"
+	"github.com/InnoFusionTech/ExplainIQ/internal/notify"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// notificationTimeout bounds delivery of one session's notifications
+const notificationTimeout = 30 * time.Second
+
+// newNotifyService creates the notification service, loading preferences if storage is available
+func newNotifyService(store notify.Store) *notify.Service {
+	service := notify.NewService(store, notify.ConfigFromEnv())
+	if err := service.Load(context.Background()); err != nil {
+		logrus.WithError(err).Warn("Failed to load notification preferences, continuing with none")
+	}
+	return service
+}
+
+// sessionNotifyEvent builds the notification event for a finished session
+func (o *Orchestrator) sessionNotifyEvent(ctx context.Context, session *Session, runErr error) notify.Event {
+	event := notify.Event{
+		Type:      notify.EventSessionCompleted,
+		SessionID: session.ID,
+		Title:     session.Topic,
+		Duration:  time.Since(session.CreatedAt),
+		Timestamp: time.Now().UTC(),
+	}
+	event.UserID, _ = session.Metadata["user_id"].(string)
+	event.OrgID, _ = session.Metadata["org_id"].(string)
+
+	if runErr != nil {
+		event.Type = notify.EventSessionFailed
+		event.Error = runErr.Error()
+	} else if session.Result != nil {
+		event.Summary = session.Result.Summary
+		if session.Result.Duration > 0 {
+			event.Duration = session.Result.Duration
+		}
+	}
+
+	if o.quotaManager != nil {
+		if info, err := o.quotaManager.GetQuotaInfo(ctx, session.ID); err == nil {
+			if costs, ok := info["current_costs"].(*cost_tracker.SessionCosts); ok && costs != nil {
+				total := costs.TotalCost
+				event.CostUSD = &total
+			}
+		}
+	}
+	return event
+}
+
+// notifySessionOutcome sends completion or failure notifications for a session run
+func (o *Orchestrator) notifySessionOutcome(sessionID string, runErr error) {
+	if o.notifier == nil {
+		return
+	}
+	session, exists := o.GetSession(sessionID)
+	if !exists {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+	defer cancel()
+
+	event := o.sessionNotifyEvent(ctx, session, runErr)
+	if event.UserID == "" && event.OrgID == "" {
+		return
+	}
+	if err := o.notifier.Notify(ctx, event); err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"error":      err,
+		}).Warn("Some session notifications were not delivered")
+	}
+}
+
+// notificationScope maps the {scope} URL parameter to a preference scope
+func notificationScope(param string) (notify.Scope, bool) {
+	switch param {
+	case "users":
+		return notify.ScopeUser, true
+	case "orgs":
+		return notify.ScopeOrg, true
+	}
+	return "", false
+}
+
+// canManageNotifications reports whether the caller may manage preferences for a user or organization.
+// Admins manage any preferences; interactive users manage their own. Anonymous callers manage none,
+// since preferences hold webhook URLs and decide where lesson details are sent.
+func (o *Orchestrator) canManageNotifications(r *http.Request, scope notify.Scope, id string) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return false
+	}
+	if principal.HasScope(auth.ScopeAdmin) {
+		return true
+	}
+	return principal.Method == auth.MethodJWT && scope == notify.ScopeUser && id == principal.UserID
+}
+
+// notificationTarget resolves and authorizes the preferences a request addresses, writing an error if it cannot
+func (o *Orchestrator) notificationTarget(w http.ResponseWriter, r *http.Request) (notify.Scope, string, bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	scope, ok := notificationScope(chi.URLParam(r, "scope"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Not found",
+			"message": "Notification preferences exist for users and orgs",
+		})
+		return "", "", false
+	}
+
+	id := chi.URLParam(r, "id")
+	if !o.canManageNotifications(r, scope, id) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Forbidden",
+			"message": "Not allowed to manage these notification preferences",
+		})
+		return "", "", false
+	}
+	return scope, id, true
+}
+
+// getNotificationPrefsHandler handles GET /api/notifications/{scope}/{id}
+func (o *Orchestrator) getNotificationPrefsHandler(w http.ResponseWriter, r *http.Request) {
+	scope, id, ok := o.notificationTarget(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, _ := o.notifier.Preferences(scope, id)
+	if prefs.Channels == nil {
+		prefs.Channels = []notify.ChannelConfig{}
+	}
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// putNotificationPrefsHandler handles PUT /api/notifications/{scope}/{id}
+func (o *Orchestrator) putNotificationPrefsHandler(w http.ResponseWriter, r *http.Request) {
+	scope, id, ok := o.notificationTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var prefs notify.Preferences
+	if err := decodeJSONBody(w, r, &prefs); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	saved, err := o.notifier.SetPreferences(r.Context(), scope, id, prefs)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, notify.ErrInvalidChannel) {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Failed to save notification preferences",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"scope":    scope,
+		"id":       id,
+		"channels": len(saved.Channels),
+	}).Info("Notification preferences updated")
+	json.NewEncoder(w).Encode(saved)
+}
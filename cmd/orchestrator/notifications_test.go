@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNotificationPreferences tests reading and updating notification channels through the API
+func TestNotificationPreferences(t *testing.T) {
+	o, router := newAPIKeyTestOrchestrator(false)
+	o.notifier = notify.NewService(nil, notify.Config{})
+	admin := newAdminKey(t, o)
+
+	body := `{"channels": [{"type": "slack", "target": "https://hooks.slack.com/services/T/B/x", "events": ["session.failed"]}]}`
+	w := serveWithKey(router, "PUT", "/api/notifications/users/u1", admin, body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = serveWithKey(router, "GET", "/api/notifications/users/u1", admin, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var prefs notify.Preferences
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prefs))
+	require.Len(t, prefs.Channels, 1)
+	assert.Equal(t, notify.ChannelSlack, prefs.Channels[0].Type)
+
+	w = serveWithKey(router, "PUT", "/api/notifications/orgs/org-1", admin, `{"channels": [{"type": "email", "target": "nope"}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveWithKey(router, "GET", "/api/notifications/teams/t1", admin, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestNotificationPreferencesForbidden tests that anonymous callers and API keys without admin scope cannot manage preferences
+func TestNotificationPreferencesForbidden(t *testing.T) {
+	o, router := newAPIKeyTestOrchestrator(true)
+	o.notifier = notify.NewService(nil, notify.Config{})
+
+	_, key, err := o.apiKeys.Create(context.Background(), auth.CreateAPIKeyRequest{
+		Name:   "ci",
+		UserID: "u1",
+		Scopes: []auth.Scope{auth.ScopeSessionsWrite},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusForbidden, serveWithKey(router, "GET", "/api/notifications/users/u1", key, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(router, "GET", "/api/notifications/users/u1", "", "").Code)
+
+	// Anonymous callers are rejected even when authentication is optional
+	o, router = newAPIKeyTestOrchestrator(false)
+	o.notifier = notify.NewService(nil, notify.Config{})
+	body := `{"channels": [{"type": "slack", "target": "https://hooks.slack.com/services/T/B/x"}]}`
+	assert.Equal(t, http.StatusForbidden, serveWithKey(router, "GET", "/api/notifications/users/u1", "", "").Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(router, "PUT", "/api/notifications/users/u1", "", body).Code)
+}
+
+// TestSessionNotifyEvent tests building completion and failure events from a session
+func TestSessionNotifyEvent(t *testing.T) {
+	o := &Orchestrator{}
+	session := &Session{
+		ID:        "s1",
+		Topic:     "Raft consensus",
+		CreatedAt: time.Now().Add(-time.Minute),
+		Metadata:  map[string]interface{}{"user_id": "u1", "org_id": "org-1"},
+		Result:    &SessionResult{Duration: 42 * time.Second, Summary: "Raft elects a leader to order the log."},
+	}
+
+	event := o.sessionNotifyEvent(context.Background(), session, nil)
+	assert.Equal(t, notify.EventSessionCompleted, event.Type)
+	assert.Equal(t, "u1", event.UserID)
+	assert.Equal(t, "org-1", event.OrgID)
+	assert.Equal(t, "Raft consensus", event.Title)
+	assert.Equal(t, 42*time.Second, event.Duration)
+	assert.Equal(t, "Raft elects a leader to order the log.", event.Summary)
+	assert.Nil(t, event.CostUSD, "no cost without cost tracking")
+
+	event = o.sessionNotifyEvent(context.Background(), session, errors.New("pipeline failed at step critic"))
+	assert.Equal(t, notify.EventSessionFailed, event.Type)
+	assert.Equal(t, "pipeline failed at step critic", event.Error)
+}
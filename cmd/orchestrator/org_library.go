@@ -0,0 +1,416 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Org library entry statuses, in workflow order
+const (
+	LibraryStatusDraft     = "draft"
+	LibraryStatusReview    = "review"
+	LibraryStatusPublished = "published"
+)
+
+// Review decisions on an org library entry
+const (
+	LibraryDecisionApprove = "approve"
+	LibraryDecisionReject  = "reject"
+)
+
+// maxLibrarySearchResults caps how many entries a library search returns
+const maxLibrarySearchResults = 100
+
+// OrgLibraryEntry is a saved lesson shared with an organization. Entries start as drafts,
+// are submitted for review and become visible to the organization's learners once approved.
+type OrgLibraryEntry struct {
+	ID              string         `json:"id"`
+	OrgID           string         `json:"org_id"`
+	SavedID         string         `json:"saved_id"`  // Saved lesson the entry was published from
+	AuthorID        string         `json:"author_id"` // Owner of the saved lesson
+	Topic           string         `json:"topic"`
+	Title           string         `json:"title"`
+	ExplanationType string         `json:"explanation_type,omitempty"`
+	Tags            []string       `json:"tags,omitempty"`
+	Result          *SessionResult `json:"result,omitempty"` // Copy of the lesson, taken on submission
+	Status          string         `json:"status"`
+	ReviewerID      string         `json:"reviewer_id,omitempty"` // Authenticated caller who reviewed the entry, as in audit records
+	ReviewNote      string         `json:"review_note,omitempty"` // Why the entry was sent back, or approval remarks
+	UsageCount      int            `json:"usage_count"`           // Times learners opened the published entry
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	SubmittedAt     *time.Time     `json:"submitted_at,omitempty"`
+	PublishedAt     *time.Time     `json:"published_at,omitempty"`
+}
+
+// copyLibraryEntry copies an entry so it can be encoded without holding the lock
+func copyLibraryEntry(entry *OrgLibraryEntry) OrgLibraryEntry {
+	c := *entry
+	c.Tags = append([]string(nil), entry.Tags...)
+	return c
+}
+
+// snapshotSavedLesson copies a saved lesson's content into the entry
+func (e *OrgLibraryEntry) snapshotSavedLesson(saved *SavedLesson) {
+	e.Topic = saved.Topic
+	e.Title = saved.Title
+	e.ExplanationType = saved.ExplanationType
+	e.Tags = append([]string(nil), saved.Tags...)
+	if saved.Result != nil {
+		result := *saved.Result
+		e.Result = &result
+	}
+}
+
+// matchesQuery reports whether every word of a search query appears in the entry's topic, title or tags
+func (e *OrgLibraryEntry) matchesQuery(query string) bool {
+	haystack := strings.ToLower(e.Topic + " " + e.Title + " " + strings.Join(e.Tags, " "))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(haystack, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// isLibraryReviewer reports whether the caller may see unpublished entries and review them.
+// Reviews are attributed to the caller, so anonymous callers never review, even when
+// authentication is optional.
+func (o *Orchestrator) isLibraryReviewer(r *http.Request) bool {
+	return callerIsAdmin(r)
+}
+
+// canBrowseOrgLibrary reports whether the caller belongs to the organization.
+// Members browse their own organization's library and admins browse any. Anonymous callers browse none.
+func (o *Orchestrator) canBrowseOrgLibrary(r *http.Request, orgID string) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return false
+	}
+	return principal.HasScope(auth.ScopeAdmin) || (principal.OrgID != "" && principal.OrgID == orgID)
+}
+
+// canContributeToOrgLibrary reports whether the caller may share a user's lesson with an organization.
+// Interactive members share their own lessons; organization keys and admins share on a user's behalf.
+// Anonymous callers and callers outside the organization share nothing.
+func (o *Orchestrator) canContributeToOrgLibrary(r *http.Request, orgID, userID string) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok || !o.canBrowseOrgLibrary(r, orgID) {
+		return false
+	}
+	if principal.HasScope(auth.ScopeAdmin) || principal.Method == auth.MethodAPIKey {
+		return true
+	}
+	return principal.Method == auth.MethodJWT && userID == principal.UserID
+}
+
+// writeLibraryForbidden writes the error returned when the caller may not access an org library
+func writeLibraryForbidden(w http.ResponseWriter) {
+	writeJSONError(w, http.StatusForbidden, "Forbidden", "Not allowed to access this organization's library")
+}
+
+// libraryEntry returns the entry a request addresses if it belongs to the organization
+func (o *Orchestrator) libraryEntry(orgID, entryID string) (*OrgLibraryEntry, bool) {
+	entry, ok := o.orgLibrary[entryID]
+	if !ok || entry.OrgID != orgID {
+		return nil, false
+	}
+	return entry, true
+}
+
+// createLibraryEntryHandler handles POST /api/orgs/{orgID}/library
+// It creates a draft entry from one of the user's saved lessons.
+func (o *Orchestrator) createLibraryEntryHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	w.Header().Set("Content-Type", "application/json")
+
+	var req struct {
+		SavedID string `json:"saved_id"`
+		UserID  string `json:"user_id"`
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.SavedID == "" || req.UserID == "" {
+		writeJSONError(w, http.StatusBadRequest, "Missing fields", "saved_id and user_id are required")
+		return
+	}
+	if !o.canContributeToOrgLibrary(r, orgID, req.UserID) {
+		writeLibraryForbidden(w)
+		return
+	}
+
+	o.mu.Lock()
+	saved, exists := o.savedLessons[req.SavedID]
+	if !exists || saved.isDeleted() {
+		o.mu.Unlock()
+		writeJSONError(w, http.StatusNotFound, "Saved lesson not found", "Saved lesson not found")
+		return
+	}
+	if saved.UserID != req.UserID {
+		o.mu.Unlock()
+		writeJSONError(w, http.StatusForbidden, "Unauthorized", "Only the lesson's owner can share it")
+		return
+	}
+	for _, entry := range o.orgLibrary {
+		if entry.OrgID == orgID && entry.SavedID == saved.ID {
+			o.mu.Unlock()
+			writeJSONError(w, http.StatusConflict, "Already shared", "The lesson is already in this organization's library as "+entry.ID)
+			return
+		}
+	}
+
+	now := time.Now()
+	entry := &OrgLibraryEntry{
+		ID:        uuid.New().String(),
+		OrgID:     orgID,
+		SavedID:   saved.ID,
+		AuthorID:  saved.UserID,
+		Status:    LibraryStatusDraft,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	entry.snapshotSavedLesson(saved)
+	if o.orgLibrary == nil {
+		o.orgLibrary = make(map[string]*OrgLibraryEntry)
+	}
+	o.orgLibrary[entry.ID] = entry
+	created := copyLibraryEntry(entry)
+	o.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"org_id":   orgID,
+		"entry_id": entry.ID,
+		"saved_id": saved.ID,
+		"user_id":  req.UserID,
+	}).Info("Org library draft created")
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// submitLibraryEntryHandler handles POST /api/orgs/{orgID}/library/{entryID}/submit
+// It refreshes the draft from its saved lesson and sends it for review.
+func (o *Orchestrator) submitLibraryEntryHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	entryID := chi.URLParam(r, "entryID")
+	w.Header().Set("Content-Type", "application/json")
+
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if !o.canContributeToOrgLibrary(r, orgID, req.UserID) {
+		writeLibraryForbidden(w)
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entry, ok := o.libraryEntry(orgID, entryID)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "Entry not found", "Library entry not found")
+		return
+	}
+	if entry.AuthorID != req.UserID {
+		writeJSONError(w, http.StatusForbidden, "Unauthorized", "Only the entry's author can submit it")
+		return
+	}
+	if entry.Status != LibraryStatusDraft {
+		writeJSONError(w, http.StatusConflict, "Invalid transition", "Only drafts can be submitted; the entry is "+entry.Status)
+		return
+	}
+	if saved, exists := o.savedLessons[entry.SavedID]; exists && !saved.isDeleted() {
+		entry.snapshotSavedLesson(saved)
+	}
+	if entry.Result == nil || entry.Result.Lesson == "" {
+		writeJSONError(w, http.StatusConflict, "Entry has no content", "The shared lesson has no lesson to review")
+		return
+	}
+
+	now := time.Now()
+	entry.Status = LibraryStatusReview
+	entry.SubmittedAt = &now
+	entry.UpdatedAt = now
+	json.NewEncoder(w).Encode(copyLibraryEntry(entry))
+}
+
+// reviewLibraryEntryHandler handles POST /api/orgs/{orgID}/library/{entryID}/review
+// Approving publishes the entry; rejecting returns it to draft with the reviewer's note.
+func (o *Orchestrator) reviewLibraryEntryHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	entryID := chi.URLParam(r, "entryID")
+	w.Header().Set("Content-Type", "application/json")
+
+	if !o.isLibraryReviewer(r) {
+		writeJSONError(w, http.StatusForbidden, "Forbidden", "Only reviewers can review library entries")
+		return
+	}
+	var req struct {
+		Decision string `json:"decision"` // approve or reject
+		Note     string `json:"note,omitempty"`
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.Decision != LibraryDecisionApprove && req.Decision != LibraryDecisionReject {
+		writeJSONError(w, http.StatusBadRequest, "Invalid decision", "decision must be approve or reject")
+		return
+	}
+	if req.Decision == LibraryDecisionReject && strings.TrimSpace(req.Note) == "" {
+		writeJSONError(w, http.StatusBadRequest, "Note required", "Rejections need a note for the author")
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entry, ok := o.libraryEntry(orgID, entryID)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "Entry not found", "Library entry not found")
+		return
+	}
+	if entry.Status != LibraryStatusReview {
+		writeJSONError(w, http.StatusConflict, "Invalid transition", "Only entries in review can be reviewed; the entry is "+entry.Status)
+		return
+	}
+
+	now := time.Now()
+	entry.ReviewerID = requesterOf(r)
+	entry.ReviewNote = req.Note
+	entry.UpdatedAt = now
+	if req.Decision == LibraryDecisionApprove {
+		entry.Status = LibraryStatusPublished
+		entry.PublishedAt = &now
+	} else {
+		entry.Status = LibraryStatusDraft
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"org_id":   orgID,
+		"entry_id": entry.ID,
+		"decision": req.Decision,
+	}).Info("Org library entry reviewed")
+	json.NewEncoder(w).Encode(copyLibraryEntry(entry))
+}
+
+// LibraryEntrySummary is an org library entry without its lesson, as listed by search
+type LibraryEntrySummary struct {
+	ID              string     `json:"id"`
+	Topic           string     `json:"topic"`
+	Title           string     `json:"title"`
+	ExplanationType string     `json:"explanation_type,omitempty"`
+	Tags            []string   `json:"tags,omitempty"`
+	AuthorID        string     `json:"author_id"`
+	Status          string     `json:"status"`
+	UsageCount      int        `json:"usage_count"`
+	PublishedAt     *time.Time `json:"published_at,omitempty"`
+}
+
+// listLibraryHandler handles GET /api/orgs/{orgID}/library?q=&tag=&status=
+// Learners see published entries, most used first; reviewers may list other statuses.
+func (o *Orchestrator) listLibraryHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	w.Header().Set("Content-Type", "application/json")
+
+	if !o.canBrowseOrgLibrary(r, orgID) {
+		writeLibraryForbidden(w)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = LibraryStatusPublished
+	}
+	switch status {
+	case LibraryStatusPublished:
+	case LibraryStatusDraft, LibraryStatusReview:
+		if !o.isLibraryReviewer(r) {
+			writeJSONError(w, http.StatusForbidden, "Forbidden", "Only reviewers can list unpublished entries")
+			return
+		}
+	default:
+		writeJSONError(w, http.StatusBadRequest, "Invalid status", "status must be draft, review or published")
+		return
+	}
+	query := r.URL.Query().Get("q")
+	filter := groupingFilterFromQuery(r)
+
+	o.mu.RLock()
+	entries := make([]LibraryEntrySummary, 0)
+	for _, entry := range o.orgLibrary {
+		if entry.OrgID != orgID || entry.Status != status || !entry.matchesQuery(query) || !filter.matches(entry.Tags, "") {
+			continue
+		}
+		entries = append(entries, LibraryEntrySummary{
+			ID:              entry.ID,
+			Topic:           entry.Topic,
+			Title:           entry.Title,
+			ExplanationType: entry.ExplanationType,
+			Tags:            append([]string(nil), entry.Tags...),
+			AuthorID:        entry.AuthorID,
+			Status:          entry.Status,
+			UsageCount:      entry.UsageCount,
+			PublishedAt:     entry.PublishedAt,
+		})
+	}
+	o.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].UsageCount != entries[j].UsageCount {
+			return entries[i].UsageCount > entries[j].UsageCount
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	total := len(entries)
+	if len(entries) > maxLibrarySearchResults {
+		entries = entries[:maxLibrarySearchResults]
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"org_id":  orgID,
+		"status":  status,
+		"entries": entries,
+		"total":   total,
+	})
+}
+
+// getLibraryEntryHandler handles GET /api/orgs/{orgID}/library/{entryID}
+// Opening a published entry counts as one use; unpublished entries are visible to reviewers only.
+func (o *Orchestrator) getLibraryEntryHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	entryID := chi.URLParam(r, "entryID")
+	w.Header().Set("Content-Type", "application/json")
+
+	if !o.canBrowseOrgLibrary(r, orgID) {
+		writeLibraryForbidden(w)
+		return
+	}
+	reviewer := o.isLibraryReviewer(r)
+
+	o.mu.Lock()
+	entry, ok := o.libraryEntry(orgID, entryID)
+	if !ok || (entry.Status != LibraryStatusPublished && !reviewer) {
+		o.mu.Unlock()
+		writeJSONError(w, http.StatusNotFound, "Entry not found", "Library entry not found")
+		return
+	}
+	if entry.Status == LibraryStatusPublished {
+		entry.UsageCount++
+	}
+	found := copyLibraryEntry(entry)
+	o.mu.Unlock()
+
+	json.NewEncoder(w).Encode(found)
+}
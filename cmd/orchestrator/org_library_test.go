@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLibraryTestRouter serves the org library API as the given principal
+func newLibraryTestRouter(o *Orchestrator, principal *auth.Principal) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/api/orgs/{orgID}/library", func(r chi.Router) {
+		r.Get("/", o.listLibraryHandler)
+		r.Post("/", o.createLibraryEntryHandler)
+		r.Get("/{entryID}", o.getLibraryEntryHandler)
+		r.Post("/{entryID}/submit", o.submitLibraryEntryHandler)
+		r.Post("/{entryID}/review", o.reviewLibraryEntryHandler)
+	})
+	return withPrincipal(r, principal)
+}
+
+// newLibraryTestOrchestrator returns an orchestrator with saved lessons owned by u1 and u2
+func newLibraryTestOrchestrator() *Orchestrator {
+	now := time.Now()
+	return &Orchestrator{
+		savedLessons: map[string]*SavedLesson{
+			"saved-1": {ID: "saved-1", UserID: "u1", Topic: "Photosynthesis", Title: "How plants eat", Tags: []string{"biology"},
+				Result: &SessionResult{Lesson: "Plants turn light into sugar."}, CreatedAt: now, UpdatedAt: now},
+			"saved-2": {ID: "saved-2", UserID: "u1", Topic: "Mitosis", Title: "Cell division", Tags: []string{"biology"},
+				Result: &SessionResult{Lesson: "Cells split in two."}, CreatedAt: now, UpdatedAt: now},
+			"saved-3": {ID: "saved-3", UserID: "u2", Topic: "Fractions", Result: &SessionResult{Lesson: "Parts of a whole."}},
+		},
+		orgLibrary:   make(map[string]*OrgLibraryEntry),
+		authRequired: true,
+		logger:       logrus.New(),
+	}
+}
+
+// shareLesson creates a library entry as author and returns it
+func shareLesson(t *testing.T, router http.Handler, savedID, userID string) OrgLibraryEntry {
+	t.Helper()
+	w := serveWithKey(router, "POST", "/api/orgs/org-1/library", "", `{"saved_id": "`+savedID+`", "user_id": "`+userID+`"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var entry OrgLibraryEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entry))
+	return entry
+}
+
+// TestOrgLibraryWorkflow tests moving a shared lesson from draft through review to published
+func TestOrgLibraryWorkflow(t *testing.T) {
+	o := newLibraryTestOrchestrator()
+	author := newLibraryTestRouter(o, &auth.Principal{UserID: "u1", OrgID: "org-1", Method: auth.MethodJWT})
+	admin := newLibraryTestRouter(o, &auth.Principal{OrgID: "org-1", APIKeyID: "key-admin", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeAdmin}})
+	member := newLibraryTestRouter(o, &auth.Principal{OrgID: "org-1", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeSessionsRead}})
+
+	entry := shareLesson(t, author, "saved-1", "u1")
+	assert.Equal(t, LibraryStatusDraft, entry.Status)
+	assert.Equal(t, "u1", entry.AuthorID)
+	assert.Equal(t, "How plants eat", entry.Title)
+
+	// The same lesson is shared once per organization
+	assert.Equal(t, http.StatusConflict, serveWithKey(author, "POST", "/api/orgs/org-1/library", "", `{"saved_id": "saved-1", "user_id": "u1"}`).Code)
+	// Only drafts in review can be reviewed, and members do not see drafts
+	assert.Equal(t, http.StatusConflict, serveWithKey(admin, "POST", "/api/orgs/org-1/library/"+entry.ID+"/review", "", `{"decision": "approve"}`).Code)
+	assert.Equal(t, http.StatusNotFound, serve(member, "GET", "/api/orgs/org-1/library/"+entry.ID).Code)
+
+	// Submitting picks up edits made to the saved lesson since the draft was created
+	o.savedLessons["saved-1"].Title = "How plants make food"
+	w := serveWithKey(author, "POST", "/api/orgs/org-1/library/"+entry.ID+"/submit", "", `{"user_id": "u1"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entry))
+	assert.Equal(t, LibraryStatusReview, entry.Status)
+	assert.Equal(t, "How plants make food", entry.Title)
+
+	// Rejection needs a note and returns the entry to draft
+	assert.Equal(t, http.StatusForbidden, serveWithKey(member, "POST", "/api/orgs/org-1/library/"+entry.ID+"/review", "", `{"decision": "approve"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(admin, "POST", "/api/orgs/org-1/library/"+entry.ID+"/review", "", `{"decision": "reject"}`).Code)
+	w = serveWithKey(admin, "POST", "/api/orgs/org-1/library/"+entry.ID+"/review", "", `{"decision": "reject", "note": "Add a diagram"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entry))
+	assert.Equal(t, LibraryStatusDraft, entry.Status)
+	assert.Equal(t, "Add a diagram", entry.ReviewNote)
+
+	require.Equal(t, http.StatusOK, serveWithKey(author, "POST", "/api/orgs/org-1/library/"+entry.ID+"/submit", "", `{"user_id": "u1"}`).Code)
+	w = serveWithKey(admin, "POST", "/api/orgs/org-1/library/"+entry.ID+"/review", "", `{"decision": "approve", "reviewer_id": "teacher-1"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entry))
+	assert.Equal(t, LibraryStatusPublished, entry.Status)
+	assert.Equal(t, "api_key:key-admin", entry.ReviewerID, "the reviewer comes from the principal, not the body")
+	assert.NotNil(t, entry.PublishedAt)
+
+	// Opening a published entry counts a use
+	for i := 0; i < 2; i++ {
+		w = serve(member, "GET", "/api/orgs/org-1/library/"+entry.ID)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entry))
+	assert.Equal(t, 2, entry.UsageCount)
+	assert.Equal(t, "Plants turn light into sugar.", entry.Result.Lesson)
+}
+
+// TestOrgLibraryReviewNeedsAdmin tests that anonymous callers cannot review, browse or share entries
+// even when authentication is optional
+func TestOrgLibraryReviewNeedsAdmin(t *testing.T) {
+	o := newLibraryTestOrchestrator()
+	o.authRequired = false
+	o.orgLibrary["e1"] = &OrgLibraryEntry{ID: "e1", OrgID: "org-1", Topic: "Fractions", Status: LibraryStatusReview}
+	anonymous := newLibraryTestRouter(o, nil)
+
+	w := serveWithKey(anonymous, "POST", "/api/orgs/org-1/library/e1/review", "", `{"decision": "approve", "reviewer_id": "teacher-1"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, LibraryStatusReview, o.orgLibrary["e1"].Status)
+	assert.Equal(t, http.StatusForbidden, serve(anonymous, "GET", "/api/orgs/org-1/library/e1").Code)
+	assert.Equal(t, http.StatusForbidden, serve(anonymous, "GET", "/api/orgs/org-1/library").Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(anonymous, "POST", "/api/orgs/org-1/library", "", `{"saved_id": "saved-1", "user_id": "u1"}`).Code)
+	assert.Len(t, o.orgLibrary, 1)
+}
+
+// TestOrgLibrarySearch tests searching published entries by query and tag, most used first
+func TestOrgLibrarySearch(t *testing.T) {
+	o := newLibraryTestOrchestrator()
+	now := time.Now()
+	o.orgLibrary = map[string]*OrgLibraryEntry{
+		"e1": {ID: "e1", OrgID: "org-1", Topic: "Photosynthesis", Title: "How plants eat", Tags: []string{"biology"}, Status: LibraryStatusPublished, UsageCount: 1, PublishedAt: &now},
+		"e2": {ID: "e2", OrgID: "org-1", Topic: "Mitosis", Title: "Cell division", Tags: []string{"biology"}, Status: LibraryStatusPublished, UsageCount: 5, PublishedAt: &now},
+		"e3": {ID: "e3", OrgID: "org-1", Topic: "Fractions", Tags: []string{"math"}, Status: LibraryStatusReview},
+		"e4": {ID: "e4", OrgID: "org-2", Topic: "Photosynthesis", Tags: []string{"biology"}, Status: LibraryStatusPublished},
+	}
+	member := newLibraryTestRouter(o, &auth.Principal{OrgID: "org-1", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeSessionsRead}})
+	admin := newLibraryTestRouter(o, &auth.Principal{UserID: "root", Method: auth.MethodJWT, Scopes: []auth.Scope{auth.ScopeAdmin}})
+
+	list := func(router http.Handler, path string) []LibraryEntrySummary {
+		t.Helper()
+		w := serve(router, "GET", path)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var body struct {
+			Entries []LibraryEntrySummary `json:"entries"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Entries
+	}
+
+	entries := list(member, "/api/orgs/org-1/library?tag=biology")
+	require.Len(t, entries, 2)
+	assert.Equal(t, "e2", entries[0].ID)
+	assert.Equal(t, "e1", entries[1].ID)
+
+	entries = list(member, "/api/orgs/org-1/library?q=plants")
+	require.Len(t, entries, 1)
+	assert.Equal(t, "e1", entries[0].ID)
+	assert.Empty(t, list(member, "/api/orgs/org-1/library?tag=math"))
+
+	// Entries awaiting review are listed for reviewers only
+	assert.Equal(t, http.StatusForbidden, serve(member, "GET", "/api/orgs/org-1/library?status=review").Code)
+	entries = list(admin, "/api/orgs/org-1/library?status=review")
+	require.Len(t, entries, 1)
+	assert.Equal(t, "e3", entries[0].ID)
+	assert.Equal(t, http.StatusBadRequest, serve(admin, "GET", "/api/orgs/org-1/library?status=archived").Code)
+
+	// Another organization's library and entries are out of reach
+	assert.Equal(t, http.StatusForbidden, serve(member, "GET", "/api/orgs/org-2/library").Code)
+	assert.Equal(t, http.StatusNotFound, serve(member, "GET", "/api/orgs/org-1/library/e4").Code)
+}
+
+// TestOrgLibraryContributors tests that only a lesson's owner within the organization can share and submit it
+func TestOrgLibraryContributors(t *testing.T) {
+	o := newLibraryTestOrchestrator()
+	u2 := newLibraryTestRouter(o, &auth.Principal{UserID: "u2", OrgID: "org-1", Method: auth.MethodJWT})
+	outsider := newLibraryTestRouter(o, &auth.Principal{UserID: "u1", OrgID: "org-2", Method: auth.MethodJWT})
+	orgKey := newLibraryTestRouter(o, &auth.Principal{OrgID: "org-1", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeSessionsWrite}})
+
+	assert.Equal(t, http.StatusForbidden, serveWithKey(u2, "POST", "/api/orgs/org-1/library", "", `{"saved_id": "saved-1", "user_id": "u1"}`).Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(u2, "POST", "/api/orgs/org-1/library", "", `{"saved_id": "saved-1", "user_id": "u2"}`).Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(u2, "POST", "/api/orgs/org-1/library", "", `{"saved_id": "missing", "user_id": "u2"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(u2, "POST", "/api/orgs/org-1/library", "", `{"saved_id": "saved-3"}`).Code)
+	// Owning the lesson is not enough to share it with an organization the caller is not in
+	assert.Equal(t, http.StatusForbidden, serveWithKey(outsider, "POST", "/api/orgs/org-1/library", "", `{"saved_id": "saved-1", "user_id": "u1"}`).Code)
+
+	// An organization key shares on a user's behalf, but the user must still own the lesson
+	entry := shareLesson(t, orgKey, "saved-2", "u1")
+	assert.Equal(t, http.StatusForbidden, serveWithKey(u2, "POST", "/api/orgs/org-1/library/"+entry.ID+"/submit", "", `{"user_id": "u2"}`).Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(orgKey, "POST", "/api/orgs/org-1/library/missing/submit", "", `{"user_id": "u1"}`).Code)
+
+	// Erasing the author's data removes what they shared
+	var report DataDeletionReport
+	o.removeUserRecords("u1", &report)
+	assert.Equal(t, 1, report.LibraryEntriesDeleted)
+	assert.Empty(t, o.orgLibrary)
+}
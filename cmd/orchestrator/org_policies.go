@@ -0,0 +1,340 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxBannedTopics caps the banned topics of one organization's policy
+	maxBannedTopics = 200
+	// maxBannedTopicLength caps the length of one banned topic
+	maxBannedTopicLength = 100
+)
+
+// languagePattern matches a language name or tag such as "French", "pt-BR" or "Simplified Chinese"
+var languagePattern = regexp.MustCompile(`^[\p{L}][\p{L} ()-]{0,39}$`)
+
+// OrgPolicy is the set of defaults an organization enforces on its members' sessions
+type OrgPolicy struct {
+	OrgID                   string    `json:"org_id"`
+	AllowedExplanationTypes []string  `json:"allowed_explanation_types,omitempty"` // Empty allows every type
+	RequireCritic           bool      `json:"require_critic,omitempty"`            // Fail sessions whose critic pass fails instead of skipping it
+	BannedTopics            []string  `json:"banned_topics,omitempty"`             // Phrases a topic may not contain, matched on whole words
+	Difficulty              string    `json:"difficulty,omitempty"`                // Required difficulty
+	Language                string    `json:"language,omitempty"`                  // Language every lesson is written in
+	UpdatedBy               string    `json:"updated_by,omitempty"`
+	UpdatedAt               time.Time `json:"updated_at"`
+}
+
+// orgPolicyViolation is a session request rejected by its organization's policy
+type orgPolicyViolation struct {
+	Rule    string // Policy field that rejected the request
+	Message string
+}
+
+// Error implements error
+func (v *orgPolicyViolation) Error() string {
+	return v.Message
+}
+
+// normalize validates a policy and puts its lists in canonical form
+func (p *OrgPolicy) normalize() error {
+	allowed := make([]string, 0, len(p.AllowedExplanationTypes))
+	seen := make(map[string]bool)
+	for _, explanationType := range p.AllowedExplanationTypes {
+		explanationType = strings.ToLower(strings.TrimSpace(explanationType))
+		if !isExplanationType(explanationType) {
+			return fmt.Errorf("unknown explanation type %q, expected one of %s", explanationType, strings.Join(agents.ExplanationTypes, ", "))
+		}
+		if !seen[explanationType] {
+			seen[explanationType] = true
+			allowed = append(allowed, explanationType)
+		}
+	}
+	p.AllowedExplanationTypes = allowed
+
+	if len(p.BannedTopics) > maxBannedTopics {
+		return fmt.Errorf("at most %d banned topics are allowed", maxBannedTopics)
+	}
+	banned := make([]string, 0, len(p.BannedTopics))
+	seen = make(map[string]bool)
+	for _, topic := range p.BannedTopics {
+		topic = strings.Join(topicWords(topic), " ")
+		if topic == "" {
+			continue
+		}
+		if len(topic) > maxBannedTopicLength {
+			return fmt.Errorf("banned topics must be at most %d characters", maxBannedTopicLength)
+		}
+		if !seen[topic] {
+			seen[topic] = true
+			banned = append(banned, topic)
+		}
+	}
+	p.BannedTopics = banned
+
+	if p.Difficulty != "" {
+		difficulty, err := normalizeDifficulty(p.Difficulty, "")
+		if err != nil {
+			return err
+		}
+		p.Difficulty = difficulty
+	}
+
+	p.Language = strings.TrimSpace(p.Language)
+	if p.Language != "" && !languagePattern.MatchString(p.Language) {
+		return fmt.Errorf("invalid language %q, expected a language name such as \"French\"", p.Language)
+	}
+	return nil
+}
+
+// isExplanationType reports whether explanationType is one the explainer knows
+func isExplanationType(explanationType string) bool {
+	for _, known := range agents.ExplanationTypes {
+		if explanationType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// topicWords lowercases text and splits it into words, dropping punctuation
+func topicWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r == '-' || r == '\'' || ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') || r > 127)
+	})
+}
+
+// bannedTopic returns the banned phrase a topic contains as whole words, if any
+func (p *OrgPolicy) bannedTopic(topic string) (string, bool) {
+	padded := " " + strings.Join(topicWords(topic), " ") + " "
+	for _, banned := range p.BannedTopics {
+		if strings.Contains(padded, " "+banned+" ") {
+			return banned, true
+		}
+	}
+	return "", false
+}
+
+// sessionPolicyRequest is the part of a session request an organization policy governs
+type sessionPolicyRequest struct {
+	Topic           string
+	ExplanationType string // Empty when the caller did not choose one
+	Difficulty      string // Empty when the caller did not choose one
+}
+
+// apply checks a session request against the policy and fills in the policy's defaults.
+// It returns the explanation type and difficulty the session must use.
+func (p *OrgPolicy) apply(req sessionPolicyRequest) (string, string, error) {
+	if banned, ok := p.bannedTopic(req.Topic); ok {
+		return "", "", &orgPolicyViolation{
+			Rule:    "banned_topics",
+			Message: fmt.Sprintf("Your organization does not allow lessons about %q", banned),
+		}
+	}
+
+	explanationType := strings.ToLower(strings.TrimSpace(req.ExplanationType))
+	if len(p.AllowedExplanationTypes) > 0 {
+		switch {
+		case explanationType == "":
+			explanationType = "standard"
+			if !containsString(p.AllowedExplanationTypes, explanationType) {
+				explanationType = p.AllowedExplanationTypes[0]
+			}
+		case !containsString(p.AllowedExplanationTypes, explanationType):
+			return "", "", &orgPolicyViolation{
+				Rule: "allowed_explanation_types",
+				Message: fmt.Sprintf("Your organization does not allow %q explanations; allowed types are %s",
+					explanationType, strings.Join(p.AllowedExplanationTypes, ", ")),
+			}
+		}
+	}
+
+	difficulty := strings.ToLower(strings.TrimSpace(req.Difficulty))
+	if p.Difficulty != "" {
+		if difficulty != "" && difficulty != p.Difficulty {
+			return "", "", &orgPolicyViolation{
+				Rule:    "difficulty",
+				Message: fmt.Sprintf("Your organization requires %s lessons, not %s", p.Difficulty, difficulty),
+			}
+		}
+		difficulty = p.Difficulty
+	}
+	return explanationType, difficulty, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// orgPolicy returns a copy of an organization's policy
+func (o *Orchestrator) orgPolicy(orgID string) (*OrgPolicy, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	policy, ok := o.orgPolicies[orgID]
+	if !ok {
+		return nil, false
+	}
+	c := *policy
+	c.AllowedExplanationTypes = append([]string(nil), policy.AllowedExplanationTypes...)
+	c.BannedTopics = append([]string(nil), policy.BannedTopics...)
+	return &c, true
+}
+
+// sessionOrg returns the organization a new session belongs to, whose policy, rubric and
+// retrieval index it uses: the authenticated caller's organization, or none
+func sessionOrg(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		return principal.OrgID
+	}
+	return ""
+}
+
+// policyMetadataKeys are the session metadata keys only an organization policy may set
+var policyMetadataKeys = map[string]bool{"require_critic": true, "language": true}
+
+// applySessionPolicy stores the policy settings the pipeline enforces on a session
+func applySessionPolicy(session *Session, policy *OrgPolicy) {
+	if policy.RequireCritic {
+		session.Metadata["require_critic"] = true
+	}
+	if policy.Language != "" {
+		session.Metadata["language"] = policy.Language
+	}
+}
+
+// applyPolicySteps makes the critic mandatory and sets the lesson language for sessions whose
+// organization policy requires it
+func applyPolicySteps(session *Session, steps []PipelineStep) {
+	requireCritic, _ := session.Metadata["require_critic"].(bool)
+	language, _ := session.Metadata["language"].(string)
+	for i := range steps {
+		if requireCritic && steps[i].Name == "critic" {
+			steps[i].Optional = false
+		}
+		if language != "" && steps[i].Name != "visualizer" {
+			instruction := fmt.Sprintf("Write all learner-facing text in %s. Keep code, identifiers and JSON keys in English.", language)
+			if existing := steps[i].Inputs["prompt_instructions"]; existing != "" {
+				instruction = existing + "\n" + instruction
+			}
+			steps[i].Inputs["prompt_instructions"] = instruction
+		}
+	}
+}
+
+// writeOrgPolicyViolation writes the rejection of a session request that breaks its organization's policy
+func (o *Orchestrator) writeOrgPolicyViolation(w http.ResponseWriter, orgID string, violation *orgPolicyViolation) {
+	o.logger.WithFields(logrus.Fields{
+		"org_id": orgID,
+		"rule":   violation.Rule,
+	}).Info("Rejected session request by organization policy")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   "Organization policy violation",
+		"message": violation.Message,
+		"code":    "org_policy",
+		"rule":    violation.Rule,
+		"org_id":  orgID,
+	})
+}
+
+// canManageOrgPolicy reports whether the caller may change an organization's policy.
+// Only admins manage policies, and admin keys bound to an organization only manage its own.
+// Anonymous callers manage none.
+func (o *Orchestrator) canManageOrgPolicy(r *http.Request, orgID string) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok || !principal.HasScope(auth.ScopeAdmin) {
+		return false
+	}
+	if principal.Method == auth.MethodAPIKey && principal.OrgID != "" {
+		return principal.OrgID == orgID
+	}
+	return true
+}
+
+// getOrgPolicyHandler handles GET /api/orgs/{orgID}/policy
+// Members see the policy their sessions are held to; an organization without one gets an empty policy.
+func (o *Orchestrator) getOrgPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !o.canBrowseOrgLibrary(r, orgID) {
+		writeJSONError(w, http.StatusForbidden, "Forbidden", "Not allowed to view this organization's policy")
+		return
+	}
+
+	policy, ok := o.orgPolicy(orgID)
+	if !ok {
+		policy = &OrgPolicy{OrgID: orgID}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// putOrgPolicyHandler handles PUT /api/orgs/{orgID}/policy
+func (o *Orchestrator) putOrgPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !o.canManageOrgPolicy(r, orgID) {
+		writeJSONError(w, http.StatusForbidden, "Forbidden", "Only organization admins can change its policy")
+		return
+	}
+
+	var policy OrgPolicy
+	if err := decodeJSONBody(w, r, &policy); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if err := policy.normalize(); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid policy", err.Error())
+		return
+	}
+	policy.OrgID = orgID
+	policy.UpdatedAt = time.Now()
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		policy.UpdatedBy = principal.UserID
+	}
+
+	o.mu.Lock()
+	o.orgPolicies[orgID] = &policy
+	o.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"org_id":        orgID,
+		"banned_topics": len(policy.BannedTopics),
+		"language":      policy.Language,
+	}).Info("Updated organization policy")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// deleteOrgPolicyHandler handles DELETE /api/orgs/{orgID}/policy
+func (o *Orchestrator) deleteOrgPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if !o.canManageOrgPolicy(r, orgID) {
+		writeJSONError(w, http.StatusForbidden, "Forbidden", "Only organization admins can change its policy")
+		return
+	}
+
+	o.mu.Lock()
+	delete(o.orgPolicies, orgID)
+	o.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPolicyTestRouter serves the org policy API and session creation as the given principal
+func newPolicyTestRouter(o *Orchestrator, principal *auth.Principal) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/api/orgs/{orgID}/policy", func(r chi.Router) {
+		r.Get("/", o.getOrgPolicyHandler)
+		r.Put("/", o.putOrgPolicyHandler)
+		r.Delete("/", o.deleteOrgPolicyHandler)
+	})
+	r.Post("/api/sessions", o.createSessionHandler)
+	return withPrincipal(r, principal)
+}
+
+// newPolicyTestOrchestrator returns an orchestrator that requires authentication
+func newPolicyTestOrchestrator() *Orchestrator {
+	return &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		orgPolicies:  make(map[string]*OrgPolicy),
+		clients:      make(map[string][]chan SSEEvent),
+		authRequired: true,
+		logger:       logrus.New(),
+	}
+}
+
+// createPolicySession creates a session through router and returns the response
+func createPolicySession(router http.Handler, req CreateSessionRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewBuffer(body)))
+	return w
+}
+
+// TestOrgPolicyManagement tests that organization admin keys set their own policy and members can only read it
+func TestOrgPolicyManagement(t *testing.T) {
+	o := newPolicyTestOrchestrator()
+	admin := []auth.Scope{auth.ScopeAdmin}
+	orgKey := newPolicyTestRouter(o, &auth.Principal{OrgID: "org-1", Method: auth.MethodAPIKey, APIKeyID: "k1", Scopes: admin})
+	otherAdmin := newPolicyTestRouter(o, &auth.Principal{OrgID: "org-2", Method: auth.MethodAPIKey, Scopes: admin})
+	otherKey := newPolicyTestRouter(o, &auth.Principal{OrgID: "org-2", Method: auth.MethodAPIKey})
+	readKey := newPolicyTestRouter(o, &auth.Principal{OrgID: "org-1", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeSessionsWrite}})
+	member := newPolicyTestRouter(o, &auth.Principal{UserID: "u1", OrgID: "org-1", Method: auth.MethodJWT})
+
+	body := `{"allowed_explanation_types": ["Simple", "analogy", "simple"], "banned_topics": ["  Explosives!", "", "explosives"],
+		"difficulty": "Beginner", "language": "French", "require_critic": true}`
+	w := serveWithKey(orgKey, http.MethodPut, "/api/orgs/org-1/policy", "", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var policy OrgPolicy
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &policy))
+	assert.Equal(t, []string{"simple", "analogy"}, policy.AllowedExplanationTypes)
+	assert.Equal(t, []string{"explosives"}, policy.BannedTopics)
+	assert.Equal(t, DifficultyBeginner, policy.Difficulty)
+	assert.Equal(t, "org-1", policy.OrgID)
+
+	assert.Equal(t, http.StatusForbidden, serveWithKey(otherAdmin, http.MethodPut, "/api/orgs/org-1/policy", "", body).Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(member, http.MethodPut, "/api/orgs/org-1/policy", "", body).Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(readKey, http.MethodPut, "/api/orgs/org-1/policy", "", body).Code)
+
+	// Anonymous callers cannot manage policies even when authentication is optional
+	o.authRequired = false
+	anonymous := newPolicyTestRouter(o, nil)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(anonymous, http.MethodPut, "/api/orgs/org-1/policy", "", body).Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(anonymous, http.MethodDelete, "/api/orgs/org-1/policy", "", "").Code)
+	assert.Equal(t, http.StatusForbidden, serve(anonymous, http.MethodGet, "/api/orgs/org-1/policy").Code)
+	o.authRequired = true
+	assert.Equal(t, http.StatusForbidden, serve(otherKey, http.MethodGet, "/api/orgs/org-1/policy").Code)
+
+	w = serve(member, http.MethodGet, "/api/orgs/org-1/policy")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"language":"French"`)
+
+	for _, invalid := range []string{
+		`{"allowed_explanation_types": ["interpretive-dance"]}`,
+		`{"difficulty": "expert"}`,
+		`{"language": "<script>"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, serveWithKey(orgKey, http.MethodPut, "/api/orgs/org-1/policy", "", invalid).Code, invalid)
+	}
+
+	assert.Equal(t, http.StatusNoContent, serveWithKey(orgKey, http.MethodDelete, "/api/orgs/org-1/policy", "", "").Code)
+	_, exists := o.orgPolicy("org-1")
+	assert.False(t, exists)
+}
+
+// TestCreateSessionEnforcesOrgPolicy tests that members' sessions are rejected or given the policy's defaults
+func TestCreateSessionEnforcesOrgPolicy(t *testing.T) {
+	o := newPolicyTestOrchestrator()
+	o.orgPolicies["org-1"] = &OrgPolicy{
+		OrgID:                   "org-1",
+		AllowedExplanationTypes: []string{"simple", "analogy"},
+		BannedTopics:            []string{"explosives"},
+		Difficulty:              DifficultyBeginner,
+		Language:                "French",
+		RequireCritic:           true,
+	}
+	orgKey := newPolicyTestRouter(o, &auth.Principal{OrgID: "org-1", Method: auth.MethodAPIKey})
+
+	w := createPolicySession(orgKey, CreateSessionRequest{Topic: "Making explosives at home"})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	var rejection map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rejection))
+	assert.Equal(t, "banned_topics", rejection["rule"])
+	assert.Contains(t, rejection["message"], `"explosives"`)
+
+	w = createPolicySession(orgKey, CreateSessionRequest{Topic: "Caching", ExplanationType: "visualization"})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "allowed types are simple, analogy")
+
+	w = createPolicySession(orgKey, CreateSessionRequest{Topic: "Caching", Difficulty: "advanced"})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"rule":"difficulty"`)
+
+	// Allowed requests get the policy's defaults, and caller tags cannot override its settings
+	w = createPolicySession(orgKey, CreateSessionRequest{Topic: "Explosive growth of caches", Metadata: map[string]string{"language": "Pirate"}})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var response CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	session, _ := o.GetSession(response.ID)
+	assert.Equal(t, "simple", session.Metadata["explanation_type"])
+	assert.Equal(t, DifficultyBeginner, session.Metadata["difficulty"])
+	assert.Equal(t, "French", session.Metadata["language"])
+	assert.Equal(t, true, session.Metadata["require_critic"])
+	assert.Equal(t, "org-1", session.Metadata["org_id"])
+
+	// Sessions outside the organization are unaffected
+	open := newPolicyTestRouter(o, &auth.Principal{UserID: "u2", Method: auth.MethodJWT})
+	assert.Equal(t, http.StatusCreated, createPolicySession(open, CreateSessionRequest{Topic: "Making explosives", ExplanationType: "visualization"}).Code)
+}
+
+// TestApplyPolicySteps tests that policy sessions make the critic mandatory and write in the required language
+func TestApplyPolicySteps(t *testing.T) {
+	session := &Session{Metadata: map[string]interface{}{"require_critic": true, "language": "French"}}
+	steps := pipelineDefinition("Caching")
+	steps[1].Inputs["prompt_instructions"] = "Use short paragraphs."
+	applyPolicySteps(session, steps)
+
+	for _, step := range steps {
+		switch step.Name {
+		case "critic":
+			assert.False(t, step.Optional)
+			assert.Contains(t, step.Inputs["prompt_instructions"], "in French")
+		case "explainer":
+			assert.Equal(t, "Use short paragraphs.\nWrite all learner-facing text in French. Keep code, identifiers and JSON keys in English.", step.Inputs["prompt_instructions"])
+		case "visualizer":
+			assert.True(t, step.Optional)
+			assert.Empty(t, step.Inputs["prompt_instructions"])
+		}
+	}
+
+	steps = pipelineDefinition("Caching")
+	applyPolicySteps(&Session{Metadata: map[string]interface{}{}}, steps)
+	assert.True(t, steps[len(steps)-1].Optional, "the critic stays optional without a policy")
+}
@@ -0,0 +1,51 @@
+package main
+
+import "github.com/InnoFusionTech/ExplainIQ/internal/llm"
+
+// PartialSessionResult is the result of a session that has not completed, built from the
+// outputs of the steps that have finished so far
+type PartialSessionResult struct {
+	*SessionResult
+	Partial        bool     `json:"partial"`
+	Status         string   `json:"status"`          // Session status, e.g. running or failed
+	CompletedSteps []string `json:"completed_steps"` // Steps whose outputs are included, in pipeline order
+}
+
+// partialSessionResult builds a session's partial result, or returns nil if no step has
+// completed yet. The lesson is the explainer's, before any critic patch.
+func (o *Orchestrator) partialSessionResult(session *Session) *PartialSessionResult {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if len(session.partialOutputs) == 0 {
+		return nil
+	}
+
+	partial := &PartialSessionResult{
+		SessionResult: &SessionResult{},
+		Partial:       true,
+		Status:        session.Status,
+	}
+	for _, step := range session.Steps {
+		if _, ok := session.partialOutputs[step.Name]; ok {
+			partial.CompletedSteps = append(partial.CompletedSteps, step.Name)
+		}
+	}
+
+	finalResult := make(map[string]interface{}, len(session.partialOutputs))
+	for step, output := range session.partialOutputs {
+		finalResult[step] = output
+	}
+	if summarizer, ok := session.partialOutputs["summarizer"]; ok {
+		partial.Summary = summarizer["summary"]
+		partial.Outline = o.pipeline.extractOutline(finalResult)
+	}
+	if explainer, ok := session.partialOutputs["explainer"]; ok {
+		partial.Lesson = explainer["lesson"]
+	}
+	if _, ok := session.partialOutputs["visualizer"]; ok {
+		partial.Images = o.pipeline.extractImages(finalResult)
+	}
+	partial.TOC = llm.BuildTableOfContents(parseLesson(partial.Lesson), partial.Outline)
+	return partial
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetSessionResultPartial tests serving completed step outputs while a session is still running
+func TestGetSessionResultPartial(t *testing.T) {
+	session := &Session{ID: "s1", Topic: "Caching", Status: "running", Metadata: map[string]interface{}{}}
+	o := &Orchestrator{
+		sessions: map[string]*Session{"s1": session},
+		logger:   logrus.New(),
+	}
+	router := chi.NewRouter()
+	router.Get("/api/sessions/{id}/result", o.getSessionResultHandler)
+
+	o.initSessionSteps(session, pipelineDefinition("Caching"))
+	assert.Equal(t, http.StatusBadRequest, serve(router, "GET", "/api/sessions/s1/result").Code, "nothing to show before a step completes")
+
+	o.markStepFinished(session, 0, PipelineStepResult{
+		StepName: "summarizer",
+		Status:   "completed",
+		Output:   map[string]string{"summary": "Caching in brief", "outline": `["What a cache is"]`},
+	})
+	o.markStepFinished(session, 1, PipelineStepResult{StepName: "explainer", Status: "failed", Error: "timeout"})
+
+	w := serve(router, "GET", "/api/sessions/s1/result")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	var partial PartialSessionResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &partial))
+	assert.True(t, partial.Partial)
+	assert.Equal(t, "running", partial.Status)
+	assert.Equal(t, []string{"summarizer"}, partial.CompletedSteps)
+	assert.Equal(t, "Caching in brief", partial.Summary)
+	assert.Equal(t, []string{"What a cache is"}, partial.Outline)
+	assert.Empty(t, partial.Lesson)
+	require.Len(t, partial.TOC, 1)
+	assert.Equal(t, "#outline-1-what-a-cache-is", partial.TOC[0].Fragment)
+
+	// Completed sessions serve the final result
+	session.Status = "completed"
+	session.Result = &SessionResult{Lesson: `{"title": "Caching"}`}
+	w = serve(router, "GET", "/api/sessions/s1/result")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"partial"`)
+
+	// A new run starts from nothing
+	o.initSessionSteps(session, pipelineDefinition("Caching"))
+	assert.Nil(t, o.partialSessionResult(session))
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
@@ -13,6 +15,9 @@ import (
 	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
 	"github.com/InnoFusionTech/ExplainIQ/internal/elastic"
 	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/InnoFusionTech/ExplainIQ/internal/retrieval"
+	"github.com/InnoFusionTech/ExplainIQ/internal/tokens"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -21,6 +26,7 @@ type ContextDoc struct {
 	Doc     elastic.Doc
 	Score   float64
 	Snippet string
+	Index   string // Retrieval index the document was found in; empty for seeded context
 }
 
 // RetrieverSearchResult is an alias for the retriever's SearchResult type
@@ -34,10 +40,57 @@ type PipelineConfig struct {
 	ContextTopK    int               `json:"context_top_k"`
 	ElasticIndex   string            `json:"elastic_index"`
 	AgentBaseURLs  map[string]string `json:"agent_base_urls"`
+	AgentMode      string            `json:"agent_mode"` // remote (default), embedded or lite
 	ElasticBaseURL string            `json:"elastic_base_url"`
 	ElasticAPIKey  string            `json:"elastic_api_key"`
+	Retrieval      retrieval.Config  `json:"retrieval"` // Context retrieval backend; Elastic settings above apply to elasticsearch
+
+	// Per-organization retrieval so one tenant's course material never reaches another's lessons
+	TenantIndexMode    string `json:"tenant_index_mode"`    // shared (default), index or alias
+	TenantIndexPattern string `json:"tenant_index_pattern"` // Index or alias name with an {org} placeholder, e.g. lessons-{org}
+	TenantIndexOrgs    []string `json:"tenant_index_orgs"`  // Organizations given their own index or alias; others read none
 	LLMProjectID   string            `json:"llm_project_id"`
 	LLMLocation    string            `json:"llm_location"`
+	CriticModel    string            `json:"critic_model"` // Model the critic reviews with instead of the session's (CRITIC_MODEL)
+
+	// Context preparation before prompts
+	ContextDedupThreshold  float64 `json:"context_dedup_threshold"`   // Similarity at or above which snippets are duplicates (0 disables)
+	ContextRerank          bool    `json:"context_rerank"`            // Rerank snippets with the LLM before prompting
+	ContextSnippetMaxChars int     `json:"context_snippet_max_chars"` // Per-snippet length cap (0 disables)
+
+	// Rewrite the hardest sections of lessons above their difficulty's target grade
+	AutoSimplify bool `json:"auto_simplify"`
+
+	// Regenerate code examples the critic finds critically broken (TOY_CODE_REPAIR)
+	RepairToyCode bool `json:"repair_toy_code"`
+
+	// Adapt code comments, naming guidance and real-life examples of non-English lessons (LESSON_LOCALIZATION)
+	LocalizeLessons bool `json:"localize_lessons"`
+
+	// Let the explainer reason in a private scratchpad that is stripped from its output (EXPLAINER_SCRATCHPAD)
+	ExplainerScratchpad bool `json:"explainer_scratchpad"`
+
+	// Near-duplicate check of finished lessons against the indexed corpus
+	SimilarityCheck     bool    `json:"similarity_check"`
+	SimilarityThreshold float64 `json:"similarity_threshold"` // Cosine similarity at or above which a section is flagged
+
+	// Session-wide retry limits on top of the per-step MaxRetries
+	RetryBudget int           `json:"retry_budget"` // Total retries across all steps (0 = no limit)
+	RetryWindow time.Duration `json:"retry_window"` // No retry starts this long after the run began (0 = no limit)
+
+	// Overall deadline of a run, independent of the HTTP request that started it
+	SessionTimeout time.Duration `json:"session_timeout"` // 0 disables the deadline
+
+	// Agent liveness checks; steps wait for, skip or fail on agents marked unavailable
+	AgentHealthInterval   time.Duration `json:"agent_health_interval"`   // How often agents are health-checked (0 disables)
+	AgentFailureThreshold int           `json:"agent_failure_threshold"` // Consecutive failed checks before an agent is unavailable
+	AgentUnavailableWait  time.Duration `json:"agent_unavailable_wait"`  // How long a step waits for its agent to recover
+	AgentPreflightTimeout time.Duration `json:"agent_preflight_timeout"` // How long a run request waits on pings of its mandatory agents (0 disables)
+
+	// Dark launch of new agent versions: a sample of sessions' steps is also sent to these agents
+	ShadowAgentURLs  map[string]string `json:"shadow_agent_urls"`  // Shadow agent URL by agent name (SHADOW_AGENT_URLS)
+	ShadowPercent    int               `json:"shadow_percent"`     // Percentage of sessions mirrored (SHADOW_TRAFFIC_PERCENT)
+	ShadowRecordFile string            `json:"shadow_record_file"` // JSONL file shadow comparisons are appended to (SHADOW_RECORD_FILE)
 }
 
 // DefaultPipelineConfig returns the default pipeline configuration
@@ -96,6 +149,7 @@ func DefaultPipelineConfig() PipelineConfig {
 		StepTimeout:  5 * time.Minute,
 		ContextTopK:  5,
 		ElasticIndex: "lessons",
+		AgentMode:    agentModeFromEnv(),
 		AgentBaseURLs: map[string]string{
 			"summarizer": summarizerURL,
 			"explainer":  explainerURL,
@@ -104,8 +158,43 @@ func DefaultPipelineConfig() PipelineConfig {
 		},
 		ElasticBaseURL: elasticURL,
 		ElasticAPIKey:  "",
+		Retrieval:      retrieval.ConfigFromEnv(),
+
+		TenantIndexMode:    tenantIndexModeFromEnv(),
+		TenantIndexPattern: tenantIndexPatternFromEnv(),
+		TenantIndexOrgs:    tenantIndexOrgsFromEnv(),
 		LLMProjectID:   "explainiq-project",
 		LLMLocation:    "europe-west1",
+		CriticModel:    strings.TrimSpace(os.Getenv("CRITIC_MODEL")),
+
+		ContextDedupThreshold:  0.85,
+		ContextRerank:          os.Getenv("CONTEXT_RERANK_ENABLED") == "true",
+		ContextSnippetMaxChars: 800,
+
+		AutoSimplify: autoSimplifyFromEnv(),
+
+		RepairToyCode: toyCodeRepairFromEnv(),
+
+		LocalizeLessons: lessonLocalizationFromEnv(),
+
+		ExplainerScratchpad: os.Getenv("EXPLAINER_SCRATCHPAD") == "true",
+
+		SimilarityCheck:     os.Getenv("SIMILARITY_CHECK_ENABLED") == "true",
+		SimilarityThreshold: similarityThresholdFromEnv(),
+
+		RetryBudget: sessionRetryBudgetFromEnv(),
+		RetryWindow: sessionRetryWindowFromEnv(),
+
+		SessionTimeout: sessionTimeoutFromEnv(),
+
+		AgentHealthInterval:   agentHealthIntervalFromEnv(),
+		AgentFailureThreshold: agentFailureThresholdFromEnv(),
+		AgentUnavailableWait:  agentUnavailableWaitFromEnv(),
+		AgentPreflightTimeout: agentPreflightTimeoutFromEnv(),
+
+		ShadowAgentURLs:  shadowAgentURLsFromEnv(),
+		ShadowPercent:    shadowPercentFromEnv(),
+		ShadowRecordFile: strings.TrimSpace(os.Getenv("SHADOW_RECORD_FILE")),
 	}
 }
 
@@ -116,6 +205,50 @@ type PipelineStep struct {
 	Inputs          map[string]string `json:"inputs"`
 	RequiresContext bool              `json:"requires_context"`
 	Retryable       bool              `json:"retryable"`
+	DependsOn       []string          `json:"depends_on,omitempty"` // Steps whose outputs feed this step's inputs
+	Optional        bool              `json:"optional,omitempty"`   // Skipped rather than failed when its agent is unavailable
+
+	Seeded      map[string]string `json:"-"` // Outputs used instead of calling the agent, e.g. from a warm start
+	SeedContext []ContextDoc      `json:"-"` // Context documents added to any retrieved ones
+}
+
+// pipelineDefinition returns the pipeline's steps for a topic, in execution order
+func pipelineDefinition(topic string) []PipelineStep {
+	return []PipelineStep{
+		{
+			Name:            "summarizer",
+			Agent:           "summarizer",
+			Inputs:          map[string]string{"topic": topic},
+			RequiresContext: true,
+			Retryable:       true,
+		},
+		{
+			Name:            "explainer",
+			Agent:           "explainer",
+			Inputs:          map[string]string{"topic": topic},
+			RequiresContext: true,
+			Retryable:       true,
+			DependsOn:       []string{"summarizer"},
+		},
+		{
+			Name:            "visualizer",
+			Agent:           "visualizer",
+			Inputs:          map[string]string{"topic": topic},
+			RequiresContext: false,
+			Retryable:       true,
+			DependsOn:       []string{"explainer"},
+			Optional:        true,
+		},
+		{
+			Name:            "critic",
+			Agent:           "critic",
+			Inputs:          map[string]string{"topic": topic},
+			RequiresContext: false,
+			Retryable:       true,
+			DependsOn:       []string{"explainer"},
+			Optional:        true,
+		},
+	}
 }
 
 // PipelineResult represents the result of pipeline execution
@@ -142,62 +275,128 @@ type PipelineStepResult struct {
 
 // Pipeline represents the orchestrator pipeline
 type Pipeline struct {
-	config           PipelineConfig
-	logger           *logrus.Logger
-	elasticClient    *elastic.Client
-	elasticRetriever *elastic.Retriever
-	embeddingClient  *llm.EmbeddingClient
-	adkClients       map[string]*adkgoogle.Client
-	authClient       *auth.Client
+	config            PipelineConfig
+	logger            *logrus.Logger
+	retrievalProvider retrieval.Provider
+	elasticRetriever  *elastic.Retriever
+	embeddingClient   *llm.EmbeddingClient
+	adkClients        map[string]AgentClient
+	authClient        *auth.Client
+	reranker          PassageReranker
+
+	corpusSearcher     CorpusSearcher // Set when retrieval is available
+	similarityEmbedder TextEmbedder
+	tenantIndex        *tenantIndexRouter // Resolves per-organization indices; nil when all sessions share ElasticIndex
+	retrievalErr       error // Why context retrieval is unavailable; nil when it is enabled
+	agentMonitor       *agentMonitor // Tracks remote agent liveness; nil when health checks are off
+	agentCapabilities  map[string]*adk.Capabilities // What each agent advertised at startup, by agent name
+	tokenCounter       *tokens.Service // Counts context tokens against agents' budgets; nil estimates
+	shadow             *shadowMirror // Mirrors sampled steps to shadow agents; nil when none are configured
+
+	hooksMu sync.RWMutex
+	hooks   pipelineHooks // Plugins run around steps and runs
 }
 
 // NewPipeline creates a new pipeline instance
 func NewPipeline(config PipelineConfig) (*Pipeline, error) {
 	logger := logrus.New()
 
-	// Initialize Elasticsearch client (optional)
-	var elasticClient *elastic.Client
+	// Initialize the retrieval provider (optional)
+	retrievalConfig := config.Retrieval
+	if retrievalConfig.ElasticURL == "" {
+		retrievalConfig.ElasticURL = config.ElasticBaseURL
+	}
+	if retrievalConfig.ElasticAPIKey == "" {
+		retrievalConfig.ElasticAPIKey = config.ElasticAPIKey
+	}
+
+	// Try to connect to the retrieval backend, but don't fail if it's not available
 	var elasticRetriever *elastic.Retriever
-	var err error
-	
-	// Try to connect to Elasticsearch, but don't fail if it's not available
-	elasticClient, err = elastic.NewClient(context.Background(), config.ElasticBaseURL, config.ElasticAPIKey)
+	var corpusSearcher CorpusSearcher
+	var similarityEmbedder TextEmbedder
+	var tenantIndex *tenantIndexRouter
+	var retrievalErr error
+	retrievalProvider, err := retrieval.New(context.Background(), retrievalConfig)
 	if err != nil {
-		logger.WithError(err).Warn("Elasticsearch not available, continuing without context retrieval")
-		elasticClient = nil
-		elasticRetriever = nil
+		logger.WithFields(logrus.Fields{
+			"provider": retrievalConfig.Provider,
+			"error":    err,
+		}).Warn("Retrieval provider not available, continuing without context retrieval")
+		retrievalProvider = nil
+		retrievalErr = err
+	} else if retrievalProvider != nil {
+		elasticRetriever, corpusSearcher, similarityEmbedder = newContextRetrieval(context.Background(), config, retrievalProvider, logger)
+		if embedder, ok := similarityEmbedder.(llm.Embedder); ok {
+			tenantIndex = newTenantIndexRouter(config, retrievalProvider, embedder.Dimensions())
+		}
+		if elasticRetriever == nil {
+			retrievalErr = fmt.Errorf("embedding model unavailable or dimensions do not match the retrieval index")
+		}
 	} else {
-		// Initialize embedding client
-		embeddingClient := llm.NewEmbeddingClient(config.LLMProjectID, config.LLMLocation)
-		// Initialize elastic retriever
-		elasticRetriever = elastic.NewRetriever(elasticClient, embeddingClient)
+		retrievalErr = errDependencyNotConfigured
 	}
 
 	// Initialize auth client
 	authClient := auth.NewClient("http://localhost:8080") // Orchestrator's own URL
 
-	// Initialize Google ADK clients for each agent
-	adkClients := make(map[string]*adkgoogle.Client)
-	for agentName, baseURL := range config.AgentBaseURLs {
-		logger.WithFields(logrus.Fields{
-			"agent":  agentName,
-			"url":    baseURL,
-		}).Info("Initializing ADK client for agent")
-		client := adkgoogle.NewClient(baseURL).
-			WithTimeout(config.StepTimeout).
-			WithLogger(logger).
-			WithAuthClient(authClient) // Enable service-to-service authentication for Cloud Run
-		adkClients[agentName] = client
+	// Initialize Google ADK clients for each agent, or run the agents in-process
+	var adkClients map[string]AgentClient
+	if agentsInProcess(config.AgentMode) {
+		adkClients = newEmbeddedAgentClients(config, logger)
+	} else {
+		adkClients = make(map[string]AgentClient)
+		for agentName, baseURL := range config.AgentBaseURLs {
+			logger.WithFields(logrus.Fields{
+				"agent":  agentName,
+				"url":    baseURL,
+			}).Info("Initializing ADK client for agent")
+			client := adkgoogle.NewClient(baseURL).
+				WithTimeout(config.StepTimeout).
+				WithLogger(logger).
+				WithAuthClient(authClient) // Enable service-to-service authentication for Cloud Run
+			adkClients[agentName] = client
+		}
+	}
+
+	// Check step assignments against what the agents advertise
+	capabilities, err := negotiateAgentCapabilities(context.Background(), pipelineDefinition(""), adkClients, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Health-check remote agents so steps do not wait on agents that are down
+	var monitor *agentMonitor
+	if !agentsInProcess(config.AgentMode) && config.AgentHealthInterval > 0 {
+		monitor = newAgentMonitor(adkClients, config.AgentFailureThreshold, logger)
 	}
 
+	// Initialize LLM reranker for retrieved context (optional)
+	var reranker PassageReranker
+	if config.ContextRerank {
+		reranker = llm.NewGeminiClient("")
+	}
+
+	// Count context tokens with the Gemini tokenizer, estimating when it is unreachable
+	tokenCounter := tokens.New(llm.NewGeminiClient(""))
+
 	return &Pipeline{
-		config:           config,
-		logger:           logger,
-		elasticClient:    elasticClient,
-		elasticRetriever: elasticRetriever,
-		embeddingClient:  nil, // Will be set when needed
-		adkClients:       adkClients,
-		authClient:       authClient,
+		config:            config,
+		logger:            logger,
+		retrievalProvider: retrievalProvider,
+		elasticRetriever:  elasticRetriever,
+		embeddingClient:   nil, // Will be set when needed
+		adkClients:        adkClients,
+		authClient:        authClient,
+		reranker:          reranker,
+
+		corpusSearcher:     corpusSearcher,
+		similarityEmbedder: similarityEmbedder,
+		tenantIndex:        tenantIndex,
+		retrievalErr:       retrievalErr,
+		agentMonitor:       monitor,
+		agentCapabilities:  capabilities,
+		tokenCounter:       tokenCounter,
+		shadow:             newShadowMirror(config, authClient, logger),
 	}, nil
 }
 
@@ -218,37 +417,99 @@ func (p *Pipeline) runPipeline(ctx context.Context, sessionID string, orchestrat
 	orchestrator.UpdateSession(session)
 
 	// Define pipeline steps
-	steps := []PipelineStep{
-		{
-			Name:            "summarizer",
-			Agent:           "summarizer",
-			Inputs:          map[string]string{"topic": session.Topic},
-			RequiresContext: true,
-			Retryable:       true,
-		},
-		{
-			Name:            "explainer",
-			Agent:           "explainer",
-			Inputs:          map[string]string{"topic": session.Topic},
-			RequiresContext: true,
-			Retryable:       true,
-		},
-		{
-			Name:            "visualizer",
-			Agent:           "visualizer",
-			Inputs:          map[string]string{"topic": session.Topic},
-			RequiresContext: false,
-			Retryable:       true,
-		},
-		{
-			Name:            "critic",
-			Agent:           "critic",
-			Inputs:          map[string]string{"topic": session.Topic},
-			RequiresContext: false,
-			Retryable:       true,
-		},
+	steps := pipelineDefinition(session.Topic)
+
+	// Pass the requested persona to the agents that write for the learner
+	if persona, ok := session.Metadata["persona"].(string); ok && persona != "" {
+		for i := range steps {
+			if steps[i].Name == "summarizer" || steps[i].Name == "explainer" {
+				steps[i].Inputs["persona"] = persona
+			}
+		}
 	}
 
+	// Ask the explainer to reason in a private scratchpad before writing
+	if p.config.ExplainerScratchpad {
+		for i := range steps {
+			if steps[i].Name == "explainer" {
+				steps[i].Inputs["scratchpad"] = "true"
+			}
+		}
+	}
+
+	// Have agents return the prompts they send when an admin is tracing the session
+	if sessionTracesPrompts(session) {
+		for i := range steps {
+			steps[i].Inputs["trace_prompts"] = "true"
+		}
+	}
+
+	// Draw the session's diagrams in its style preset
+	if style, ok := session.Metadata["image_style"].(string); ok && style != "" {
+		for i := range steps {
+			if steps[i].Name == "visualizer" {
+				steps[i].Inputs["image_style"] = style
+			}
+		}
+	}
+
+	// Let the summarizer ground current-events and factual topics in web search results
+	if grounding := sessionGroundingMode(session); grounding != llm.GroundingOff {
+		steps[0].Inputs["grounding"] = grounding
+	}
+
+	// Pass the session's model override to the agents that call text models
+	if model, ok := session.Metadata["model"].(string); ok && model != "" {
+		for i := range steps {
+			if steps[i].Name != "visualizer" {
+				steps[i].Inputs["model"] = model
+			}
+		}
+	}
+
+	// Cross-check lessons with a different model than the one that wrote them
+	if p.config.CriticModel != "" {
+		for i := range steps {
+			if steps[i].Name == "critic" {
+				steps[i].Inputs["model"] = p.config.CriticModel
+			}
+		}
+	}
+
+	// Ask every agent for reproducible output if the session is deterministic
+	if sessionDeterministic(session) {
+		for i := range steps {
+			steps[i].Inputs["deterministic"] = "true"
+		}
+	}
+
+	// Pass the session's enabled feature flags so agents can gate features being rolled out
+	if enabled := sessionFlags(session); len(enabled) > 0 {
+		for i := range steps {
+			steps[i].Inputs["feature_flags"] = strings.Join(enabled, ",")
+		}
+	}
+
+	// Reuse the saved lesson a session was warm-started from instead of summarizing again
+	if saved := orchestrator.warmStartLesson(session); saved != nil {
+		applyWarmStart(steps, saved)
+	}
+
+	// Pass the deployment's critique rubric to the critic
+	if rubric := orchestrator.rubricForSession(session); rubric != "" {
+		for i := range steps {
+			if steps[i].Name == "critic" {
+				steps[i].Inputs["rubric"] = rubric
+			}
+		}
+	}
+
+	// Assign the session to prompt experiment variants
+	orchestrator.applyPromptExperiments(session, steps)
+
+	// Enforce the organization policy the session was created under
+	applyPolicySteps(session, steps)
+
 	// Execute pipeline steps
 	result := &PipelineResult{
 		SessionID:   sessionID,
@@ -257,22 +518,49 @@ func (p *Pipeline) runPipeline(ctx context.Context, sessionID string, orchestrat
 		FinalResult: make(map[string]interface{}),
 	}
 
+	// Completion hooks run last, once the duration is recorded
+	hooks := p.registeredHooks()
+	defer hooks.runPipelineComplete(ctx, session, result, p.logger)
+
 	startTime := time.Now()
 	defer func() {
 		result.Duration = time.Since(startTime)
 		result.CompletedAt = time.Now()
 	}()
+	budget := newRetryBudget(p.config, startTime)
+	sessionCost := 0.0 // Running estimate for the live cost meter
 
 	// Execute each step
 	// Collect outputs from previous steps to pass to subsequent steps
 	previousOutputs := make(map[string]map[string]string)
 	
+	orchestrator.initSessionSteps(session, steps)
+
 	for i, step := range steps {
 		// Merge previous step outputs into current step inputs if needed
 		step = p.enrichStepInputs(step, previousOutputs)
 		
-		stepResult := p.executeStep(ctx, sessionID, step, orchestrator, i)
+		orchestrator.markStepRunning(session, i)
+		var stepResult PipelineStepResult
+		if err := hooks.runStepStart(ctx, session, &step); err != nil {
+			// A step rejected by a hook is failed without calling its agent or retrying
+			step.Retryable = false
+			stepResult = PipelineStepResult{
+				StepName: step.Name,
+				Status:   "failed",
+				Error:    err.Error(),
+			}
+		} else {
+			stepResult = p.executeStep(ctx, sessionID, step, orchestrator, i, budget)
+		}
+		hooks.runStepComplete(ctx, session, step, &stepResult, p.logger)
+		orchestrator.markStepFinished(session, i, stepResult)
 		result.Steps = append(result.Steps, stepResult)
+
+		// Stop once the session is cancelled or its deadline passes
+		if ctx.Err() != nil {
+			return p.stopRun(ctx, session, result, orchestrator)
+		}
 		
 		// Store outputs from completed steps for use in subsequent steps
 		if stepResult.Status == "completed" {
@@ -305,25 +593,37 @@ func (p *Pipeline) runPipeline(ctx context.Context, sessionID string, orchestrat
 				Timestamp: time.Now(),
 			})
 		} else {
-			// Send step_complete event for successful steps
+			// Send step_complete event for successful steps, with the step's usage for the live cost meter
+			metrics, _ := stepResult.Metadata["metrics"].(map[string]interface{})
+			cost := stepCostFromMetrics(metrics)
+			sessionCost += cost.CostUSD
+			data := map[string]interface{}{
+				"session_id": sessionID,
+				"step":        step.Name,
+				"status":      stepResult.Status,
+				"duration":    stepResult.Duration.Milliseconds(),
+				"input_tokens":     cost.InputTokens,
+				"output_tokens":    cost.OutputTokens,
+				"cost_usd":         cost.CostUSD,
+				"session_cost_usd": sessionCost,
+				"timestamp":   time.Now().Format(time.RFC3339),
+			}
+			// Anchors the frontend can deep-link to as soon as the step lands
+			if sections := stepSectionAnchors(step.Name, stepResult.Output); len(sections) > 0 {
+				data["sections"] = sections
+			}
 			orchestrator.BroadcastEvent(sessionID, SSEEvent{
 				Type:      "step_complete",
 				SessionID: sessionID,
 				StepID:    fmt.Sprintf("step-%d", i+1),
-				Data: map[string]interface{}{
-					"session_id": sessionID,
-					"step":        step.Name,
-					"status":      stepResult.Status,
-					"duration":    stepResult.Duration.Milliseconds(),
-					"timestamp":   time.Now().Format(time.RFC3339),
-				},
+				Data:      data,
 				Timestamp: time.Now(),
 			})
 		}
 
 		// Check if step failed and handle accordingly
 		if stepResult.Status == "failed" {
-			if step.Retryable && stepResult.RetryCount < p.config.MaxRetries {
+			if step.Retryable && stepResult.RetryCount < p.config.MaxRetries && !budget.isExhausted() {
 				p.logger.WithFields(logrus.Fields{
 					"session_id":  sessionID,
 					"step":        step.Name,
@@ -335,19 +635,20 @@ func (p *Pipeline) runPipeline(ctx context.Context, sessionID string, orchestrat
 				result.Status = "failed"
 				result.Error = fmt.Sprintf("step %s failed: %s", step.Name, stepResult.Error)
 
-				// Update session status
+				// Update session status, classifying the failure for users and operators
+				failure := orchestrator.recordFailure(session, step.Name, stepResult.Error)
 				session.Status = "failed"
 				orchestrator.UpdateSession(session)
 
 				// Broadcast final failure event
+				errorData := failureEventData(sessionID, failure)
+				if budget.isExhausted() {
+					errorData["retry_budget"] = budget.info()
+				}
 				orchestrator.BroadcastEvent(sessionID, SSEEvent{
 					Type:      "session_error",
 					SessionID: sessionID,
-					Data: map[string]interface{}{
-						"session_id": sessionID,
-						"error":      result.Error,
-						"timestamp":  time.Now().Format(time.RFC3339),
-					},
+					Data:      errorData,
 					Timestamp: time.Now(),
 				})
 
@@ -355,11 +656,28 @@ func (p *Pipeline) runPipeline(ctx context.Context, sessionID string, orchestrat
 			}
 		}
 
+		// Pause supervised runs until a reviewer approves, edits or rejects the step's output
+		if stepResult.Status == "completed" && sessionSupervised(session) {
+			output, err := orchestrator.awaitStepApproval(ctx, session, i, step.Name, stepResult.Output)
+			if ctx.Err() != nil {
+				return p.stopRun(ctx, session, result, orchestrator)
+			}
+			if err != nil {
+				return p.rejectRun(session, result, orchestrator, err)
+			}
+			stepResult.Output = output
+			previousOutputs[step.Name] = output
+			result.Steps[len(result.Steps)-1].Output = output
+		}
+
 		// Apply critic patch if this is the critic step
 		if step.Name == "critic" && stepResult.Status == "completed" {
 			// Get the lesson from explainer output (stored in previousOutputs)
 			var lessonJSON string
 			if explainerOutput, exists := previousOutputs["explainer"]; exists {
+				if resolved, err := orchestrator.resolveArtifactRefs(ctx, explainerOutput); err == nil {
+					explainerOutput = resolved
+				}
 				if lesson, ok := explainerOutput["lesson"]; ok && lesson != "" {
 					lessonJSON = lesson
 				}
@@ -388,50 +706,82 @@ func (p *Pipeline) runPipeline(ctx context.Context, sessionID string, orchestrat
 	finalResult := make(map[string]interface{})
 	for _, stepResult := range result.Steps {
 		if stepResult.Status == "completed" {
-			finalResult[stepResult.StepName] = stepResult.Output
+			output, err := orchestrator.resolveArtifactRefs(ctx, stepResult.Output)
+			if err != nil {
+				p.logger.WithFields(logrus.Fields{
+					"session_id": sessionID,
+					"step":       stepResult.StepName,
+					"error":      err,
+				}).Warn("Failed to read spilled artifacts for the session result")
+				output = stepResult.Output
+			}
+			finalResult[stepResult.StepName] = output
 		}
 	}
 	result.FinalResult = finalResult
 
 	// Update session with final result
-	session.Status = "completed"
-	session.Result = &SessionResult{
-		Lesson:      p.extractLesson(finalResult),
-		Images:      p.extractImages(finalResult),
-		Summary:     p.extractSummary(finalResult),
-		Duration:    result.Duration,
-		CompletedAt: result.CompletedAt,
+	lessonJSON, codeRepair := orchestrator.repairToyCode(ctx, session, p.extractLesson(finalResult), finalResult)
+	lessonJSON, readability := orchestrator.scoreReadability(ctx, session, lessonJSON)
+	outline := p.extractOutline(finalResult)
+	toc := llm.BuildTableOfContents(parseLesson(lessonJSON), outline)
+	accessibility := p.extractAccessibility(finalResult, session.Topic)
+	similarity := p.checkSimilarity(ctx, sessionID, orchestrator.sessionOrgID(sessionID), lessonJSON)
+
+	sessionResult := &SessionResult{
+		Lesson:        lessonJSON,
+		Images:        p.extractImages(finalResult),
+		Summary:       p.extractSummary(finalResult),
+		Outline:       outline,
+		TOC:           toc,
+		Accessibility: accessibility,
+		Similarity:    similarity,
+		Readability:   readability,
+		CodeRepair:    codeRepair,
+		Metadata:      groundingResultMetadata(finalResult),
+		Duration:      result.Duration,
+		CompletedAt:   result.CompletedAt,
+	}
+	sessionResult.setMisconceptionChecks(orchestrator.misconceptionChecks(ctx, sessionID, session.Topic, finalResult))
+	sessionResult.FurtherReading = orchestrator.furtherReading(ctx, sessionID, session.Topic, lessonJSON)
+	if sessionResult.Summary == "" {
+		sessionResult.Summary = orchestrator.lessonTLDR(ctx, sessionID, session.Topic, lessonJSON)
 	}
-	orchestrator.UpdateSession(session)
 
-	// Track session completion for BrainPrint
-	if orchestrator.brainprintSvc != nil {
-		// Get user ID from session metadata or use session ID as user ID
-		userID := sessionID // Default to session ID
-		if userIDFromMeta, ok := session.Metadata["user_id"].(string); ok && userIDFromMeta != "" {
-			userID = userIDFromMeta
-		}
+	// Moderate the lesson before it is returned or saved
+	moderation, err := orchestrator.moderateResult(ctx, sessionID, sessionResult)
+	if err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+
+		failure := orchestrator.recordFailure(session, "moderation", err.Error())
+		session.Status = "failed"
+		orchestrator.UpdateSession(session)
+
+		errorData := failureEventData(sessionID, failure)
+		errorData["moderation"] = moderation
+		orchestrator.BroadcastEvent(sessionID, SSEEvent{
+			Type:      "session_error",
+			SessionID: sessionID,
+			Data:      errorData,
+			Timestamp: time.Now(),
+		})
+		return err
+	}
 
-		// Get explanation type from session metadata
-		explanationType := "standard"
-		if expType, ok := session.Metadata["explanation_type"].(string); ok && expType != "" {
-			explanationType = expType
-		}
+	sessionResult.LessonHTML = lessonHTML(sessionResult.Lesson)
+	sessionResult.Localization = orchestrator.localizeLesson(ctx, session, sessionResult.Lesson)
 
-		// Track successful session
-		if err := orchestrator.brainprintSvc.TrackSession(ctx, userID, explanationType, true); err != nil {
-			p.logger.WithFields(logrus.Fields{
-				"session_id": sessionID,
-				"user_id":    userID,
-				"error":      err,
-			}).Warn("Failed to track session for BrainPrint")
-		}
-	}
+	session.Status = "completed"
+	session.Result = sessionResult
+	orchestrator.UpdateSession(session)
+	orchestrator.trackSessionGoals(session)
+	orchestrator.cacheSessionResult(session)
 
 	// Broadcast final success event
 	// Prepare artifacts in the format expected by frontend
 	artifacts := make(map[string]interface{})
-	if lesson := p.extractLesson(finalResult); lesson != "" {
+	if lesson := session.Result.Lesson; lesson != "" {
 		var lessonObj map[string]interface{}
 		if err := json.Unmarshal([]byte(lesson), &lessonObj); err == nil {
 			artifacts["lesson"] = lessonObj
@@ -455,11 +805,14 @@ func (p *Pipeline) runPipeline(ctx context.Context, sessionID string, orchestrat
 						} else if alt, ok := img["alt_text"].(string); ok {
 							imageObj["caption"] = alt
 						}
-						if altText, ok := img["alt_text"].(string); ok {
+						if altText, ok := img["alt_text"].(string); ok && strings.TrimSpace(altText) != "" {
 							imageObj["alt_text"] = altText
 						} else if caption, ok := img["caption"].(string); ok {
 							imageObj["alt_text"] = caption
 						}
+						if longDescription, ok := img["long_description"].(string); ok && longDescription != "" {
+							imageObj["long_description"] = longDescription
+						}
 						if len(imageObj) > 0 {
 							imageArray = append(imageArray, imageObj)
 						}
@@ -480,9 +833,36 @@ func (p *Pipeline) runPipeline(ctx context.Context, sessionID string, orchestrat
 			}
 		}
 	}
-	if summary := p.extractSummary(finalResult); summary != "" {
+	if summary := session.Result.Summary; summary != "" {
 		artifacts["summary"] = summary
 	}
+	if len(toc) > 0 {
+		artifacts["toc"] = toc
+	}
+	if similarity != nil {
+		artifacts["similarity"] = similarity
+	}
+	if localization := session.Result.Localization; localization != nil {
+		artifacts["localization"] = localizationArtifact(localization)
+	}
+	if session.Result.Metadata != nil {
+		artifacts["metadata"] = session.Result.Metadata
+	}
+	if accessibility != nil {
+		artifacts["accessibility"] = accessibility
+		// Use the repaired alt text so no rendered image is left without one
+		if imgs, ok := artifacts["images"].([]map[string]string); ok {
+			altByURL := make(map[string]string, len(accessibility.Images))
+			for _, image := range accessibility.Images {
+				altByURL[image.URL] = image.AltText
+			}
+			for _, img := range imgs {
+				if alt, ok := altByURL[img["url"]]; ok {
+					img["alt_text"] = alt
+				}
+			}
+		}
+	}
 
 	// Log artifacts for debugging
 	p.logger.WithFields(logrus.Fields{
@@ -524,8 +904,8 @@ func (p *Pipeline) runPipeline(ctx context.Context, sessionID string, orchestrat
 	return nil
 }
 
-// executeStep executes a single pipeline step
-func (p *Pipeline) executeStep(ctx context.Context, sessionID string, step PipelineStep, orchestrator *Orchestrator, stepIndex int) PipelineStepResult {
+// executeStep executes a single pipeline step, drawing its retries from the session's budget
+func (p *Pipeline) executeStep(ctx context.Context, sessionID string, step PipelineStep, orchestrator *Orchestrator, stepIndex int, budget *retryBudget) PipelineStepResult {
 	stepResult := PipelineStepResult{
 		StepName:   step.Name,
 		Status:     "running",
@@ -552,11 +932,21 @@ func (p *Pipeline) executeStep(ctx context.Context, sessionID string, step Pipel
 		Timestamp: time.Now(),
 	})
 
+	// Seeded steps complete with their seeded outputs without calling the agent
+	if step.Seeded != nil {
+		for k, v := range step.Seeded {
+			stepResult.Output[k] = v
+		}
+		stepResult.Status = "completed"
+		stepResult.Metadata["seeded"] = true
+		return stepResult
+	}
+
 	// Get context if required
 	var contextDocs []ContextDoc
 	if step.RequiresContext {
 		var err error
-		contextDocs, err = p.getContext(ctx, sessionID, step.Inputs["topic"])
+		contextDocs, err = p.getContext(ctx, sessionID, orchestrator.sessionOrgID(sessionID), step.Inputs["topic"])
 		if err != nil {
 			p.logger.WithFields(logrus.Fields{
 				"session_id": sessionID,
@@ -574,9 +964,12 @@ func (p *Pipeline) executeStep(ctx context.Context, sessionID string, step Pipel
 	}
 
 	// Add context to inputs
+	contextDocs = append(append([]ContextDoc(nil), step.SeedContext...), contextDocs...)
 	if len(contextDocs) > 0 {
+		contextDocs = p.prepareContext(ctx, sessionID, step.Inputs["topic"], contextDocs)
+		orchestrator.recordInjectedContext(sessionID, step.Name, contextDocs)
 		contextText := p.formatContext(contextDocs)
-		inputs["context"] = contextText
+		inputs["context"] = p.fitContext(ctx, sessionID, step, contextText)
 	}
 
 	// Execute step with retry logic
@@ -587,6 +980,25 @@ func (p *Pipeline) executeStep(ctx context.Context, sessionID string, step Pipel
 		return stepResult
 	}
 
+	// Wait for an agent marked unavailable by health checks; optional steps are skipped if it stays down
+	if err := p.awaitAgent(ctx, sessionID, step, stepIndex, orchestrator); err != nil {
+		stepResult.Status = "failed"
+		if step.Optional {
+			stepResult.Status = "skipped"
+		}
+		stepResult.Error = err.Error()
+		return stepResult
+	}
+
+	// Agents receive spilled artifacts of earlier steps inline
+	resolvedInputs, err := orchestrator.resolveArtifactRefs(ctx, inputs)
+	if err != nil {
+		stepResult.Status = "failed"
+		stepResult.Error = err.Error()
+		return stepResult
+	}
+	inputs = resolvedInputs
+
 	// Create task request
 	taskReq := adk.TaskRequest{
 		SessionID: sessionID,
@@ -595,10 +1007,25 @@ func (p *Pipeline) executeStep(ctx context.Context, sessionID string, step Pipel
 		Inputs:    inputs,
 	}
 
+	// Retries of this execution share an idempotency key, so an agent that already finished
+	// the task after a timeout returns its result instead of calling the model again
+	taskCtx := adk.WithIdempotencyKey(ctx, fmt.Sprintf("%s:%s:%s", sessionID, step.Name, uuid.New().String()))
+
 	// Execute with retries
 	var lastErr error
 	for attempt := 0; attempt <= p.config.MaxRetries; attempt++ {
 		if attempt > 0 {
+			if !budget.spend(time.Now()) {
+				p.logger.WithFields(logrus.Fields{
+					"session_id": sessionID,
+					"step":       step.Name,
+					"attempt":    attempt + 1,
+				}).Warn("Session retry budget exhausted, not retrying step")
+				stepResult.Status = "failed"
+				stepResult.Error = fmt.Sprintf("step failed after %d attempts, session retry budget exhausted: %v", attempt, lastErr)
+				stepResult.Metadata["retry_budget"] = budget.info()
+				return stepResult
+			}
 			stepResult.RetryCount++
 			p.logger.WithFields(logrus.Fields{
 				"session_id": sessionID,
@@ -638,12 +1065,32 @@ func (p *Pipeline) executeStep(ctx context.Context, sessionID string, step Pipel
 		}
 
 		// Execute the task using Google ADK client
-		response, err := client.ExecuteTask(ctx, &taskReq)
+		callStart := time.Now()
+		response, err := client.ExecuteTask(taskCtx, &taskReq)
 		if err == nil {
+			// Never let the explainer's private reasoning reach artifacts, events or logs
+			orchestrator.redactScratchpad(sessionID, step.Name, response.Artifacts)
+			orchestrator.recordPromptTrace(sessionID, step.Name, attempt+1, response.Artifacts, contextDocs)
+
+			// Compare a new agent version on the same request without affecting the session
+			p.shadow.mirror(ctx, step, taskReq, response, time.Since(callStart))
+
+			// Keep oversized artifacts out of memory, events and later steps' payloads
+			if err := orchestrator.limitStepArtifacts(ctx, sessionID, step.Name, response); err != nil {
+				stepResult.Status = "failed"
+				stepResult.Error = err.Error()
+				return stepResult
+			}
+
 			// Success
 			stepResult.Status = "completed"
 			stepResult.Output = response.Artifacts
 			stepResult.Metadata["metrics"] = response.Metrics
+			// Record which model produced the step's artifacts, including any fallback
+			if model, ok := response.Metrics["model"].(string); ok && model != "" {
+				stepResult.Metadata["model"] = model
+				stepResult.Metadata["model_fallbacks"] = response.Metrics["model_fallbacks"]
+			}
 			if response.Delta != "" {
 				stepResult.Metadata["delta"] = response.Delta
 			}
@@ -698,6 +1145,13 @@ func (p *Pipeline) executeStep(ctx context.Context, sessionID string, step Pipel
 
 		lastErr = err
 
+		// A cancelled or timed-out run is not retried
+		if ctx.Err() != nil {
+			stepResult.Status = "cancelled"
+			stepResult.Error = context.Cause(ctx).Error()
+			return stepResult
+		}
+
 		// Check if error is retryable (for now, all errors are retryable)
 		// In the future, we can add error classification
 		// For now, we'll retry all errors up to MaxRetries
@@ -722,25 +1176,30 @@ func (p *Pipeline) executeStep(ctx context.Context, sessionID string, step Pipel
 	return stepResult
 }
 
-// getContext retrieves relevant context using hybrid search
-func (p *Pipeline) getContext(ctx context.Context, sessionID, topic string) ([]ContextDoc, error) {
+// getContext retrieves relevant context using hybrid search over the index of the session's organization
+func (p *Pipeline) getContext(ctx context.Context, sessionID, orgID, topic string) ([]ContextDoc, error) {
 	// Check if Elasticsearch is available
 	if p.elasticRetriever == nil {
 		p.logger.WithFields(logrus.Fields{
 			"session_id": sessionID,
 			"topic":      topic,
-		}).Info("Elasticsearch not available, skipping context retrieval")
+		}).Info("Retrieval provider not available, skipping context retrieval")
 		return []ContextDoc{}, nil
 	}
 
+	index, err := p.retrievalIndex(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
 	p.logger.WithFields(logrus.Fields{
 		"session_id": sessionID,
 		"topic":      topic,
-		"index":      p.config.ElasticIndex,
+		"index":      index,
 	}).Info("Retrieving context using hybrid search")
 
 	// Perform hybrid search
-	results, err := p.elasticRetriever.HybridSearch(ctx, p.config.ElasticIndex, topic, p.config.ContextTopK)
+	results, err := p.elasticRetriever.HybridSearch(ctx, index, topic, p.config.ContextTopK)
 	if err != nil {
 		return nil, fmt.Errorf("hybrid search failed: %w", err)
 	}
@@ -756,6 +1215,7 @@ func (p *Pipeline) getContext(ctx context.Context, sessionID, topic string) ([]C
 			Doc:     results[i].Doc,
 			Score:   results[i].Score,
 			Snippet: results[i].Snippet,
+			Index:   index,
 		})
 	}
 
@@ -848,7 +1308,8 @@ func getMapKeys(m map[string]map[string]string) []string {
 	return keys
 }
 
-// formatContext formats context documents for agent consumption
+// formatContext formats context documents for agent consumption, each wrapped in
+// delimiters that mark it as untrusted so agents never take it for instructions
 func (p *Pipeline) formatContext(docs []ContextDoc) string {
 	if len(docs) == 0 {
 		return ""
@@ -856,8 +1317,9 @@ func (p *Pipeline) formatContext(docs []ContextDoc) string {
 
 	var contextParts []string
 	for i, doc := range docs {
-		contextPart := fmt.Sprintf("Document %d (Score: %.3f):\nTopic: %s\nSection: %s\nContent: %s\n",
-			i+1, doc.Score, doc.Doc.Topic, doc.Doc.Section, doc.Snippet)
+		document := fmt.Sprintf("Topic: %s\nSection: %s\nContent: %s", doc.Doc.Topic, doc.Doc.Section, doc.Snippet)
+		contextPart := fmt.Sprintf("Document %d (Score: %.3f):\n%s\n",
+			i+1, doc.Score, llm.WrapUntrustedDocument(strconv.Itoa(i+1), document))
 		contextParts = append(contextParts, contextPart)
 	}
 
@@ -905,6 +1367,12 @@ func (p *Pipeline) applyCriticPatch(ctx context.Context, sessionID string, lesso
 	if critiqueJSON, exists := criticOutput["critique"]; exists {
 		lesson["critique"] = critiqueJSON
 	}
+	if reviewerJSON, exists := criticOutput["reviewer"]; exists {
+		var reviewer llm.ReviewerIdentity
+		if err := json.Unmarshal([]byte(reviewerJSON), &reviewer); err == nil {
+			lesson["critique_reviewer"] = reviewer
+		}
+	}
 
 	// Update the lesson in the session
 	session, exists := orchestrator.GetSession(sessionID)
@@ -924,6 +1392,7 @@ func (p *Pipeline) applyCriticPatch(ctx context.Context, sessionID string, lesso
 	}
 
 	session.Result.Lesson = string(updatedLessonJSON)
+	session.Result.LessonHTML = lessonHTML(session.Result.Lesson)
 	orchestrator.UpdateSession(session)
 
 	p.logger.WithField("session_id", sessionID).Info("Critic patch applied successfully")
@@ -987,7 +1456,7 @@ func (p *Pipeline) extractSummary(finalResult map[string]interface{}) string {
 			}
 		}
 	}
-	return "No summary available"
+	return ""
 }
 
 // GetConfig returns the current pipeline configuration
@@ -1002,10 +1471,10 @@ func (p *Pipeline) SetConfig(config PipelineConfig) {
 
 // Health checks the health of all pipeline components
 func (p *Pipeline) Health(ctx context.Context) error {
-	// Check Elasticsearch health (if available)
-	if p.elasticClient != nil {
-		if err := p.elasticClient.Health(ctx); err != nil {
-			return fmt.Errorf("elasticsearch health check failed: %w", err)
+	// Check retrieval backend health (if available)
+	if p.retrievalProvider != nil {
+		if err := p.retrievalProvider.Health(ctx); err != nil {
+			return fmt.Errorf("%s health check failed: %w", p.retrievalProvider.Name(), err)
 		}
 	}
 
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StepStartHook runs before a step's agent is called. It may change the step's inputs.
+// An error fails the step without calling the agent or retrying.
+type StepStartHook func(ctx context.Context, session *Session, step *PipelineStep) error
+
+// StepCompleteHook runs after a step finishes, whether it completed or failed.
+// An error is logged and does not change the step's result.
+type StepCompleteHook func(ctx context.Context, session *Session, step PipelineStep, result *PipelineStepResult) error
+
+// PipelineCompleteHook runs once a pipeline run ends, successfully or not.
+// An error is logged and does not change the run's outcome.
+type PipelineCompleteHook func(ctx context.Context, session *Session, result *PipelineResult) error
+
+// namedStepStartHook is a registered step start hook and the name it is logged under
+type namedStepStartHook struct {
+	name string
+	fn   StepStartHook
+}
+
+// namedStepCompleteHook is a registered step completion hook and the name it is logged under
+type namedStepCompleteHook struct {
+	name string
+	fn   StepCompleteHook
+}
+
+// namedPipelineCompleteHook is a registered pipeline completion hook and the name it is logged under
+type namedPipelineCompleteHook struct {
+	name string
+	fn   PipelineCompleteHook
+}
+
+// pipelineHooks holds the hooks registered on a pipeline, in registration order
+type pipelineHooks struct {
+	stepStart        []namedStepStartHook
+	stepComplete     []namedStepCompleteHook
+	pipelineComplete []namedPipelineCompleteHook
+}
+
+// OnStepStart registers a hook to run before each step. Hooks run in registration order
+// and the first error stops the remaining hooks.
+func (p *Pipeline) OnStepStart(name string, hook StepStartHook) {
+	p.hooksMu.Lock()
+	defer p.hooksMu.Unlock()
+	p.hooks.stepStart = append(p.hooks.stepStart, namedStepStartHook{name: name, fn: hook})
+}
+
+// OnStepComplete registers a hook to run after each step. Hooks run in registration order
+// and every hook runs even if an earlier one fails.
+func (p *Pipeline) OnStepComplete(name string, hook StepCompleteHook) {
+	p.hooksMu.Lock()
+	defer p.hooksMu.Unlock()
+	p.hooks.stepComplete = append(p.hooks.stepComplete, namedStepCompleteHook{name: name, fn: hook})
+}
+
+// OnPipelineComplete registers a hook to run when a pipeline run ends. Hooks run in
+// registration order and every hook runs even if an earlier one fails.
+func (p *Pipeline) OnPipelineComplete(name string, hook PipelineCompleteHook) {
+	p.hooksMu.Lock()
+	defer p.hooksMu.Unlock()
+	p.hooks.pipelineComplete = append(p.hooks.pipelineComplete, namedPipelineCompleteHook{name: name, fn: hook})
+}
+
+// registeredHooks returns a snapshot of the registered hooks, so runs in flight are not
+// affected by later registrations
+func (p *Pipeline) registeredHooks() pipelineHooks {
+	p.hooksMu.RLock()
+	defer p.hooksMu.RUnlock()
+	return pipelineHooks{
+		stepStart:        append([]namedStepStartHook(nil), p.hooks.stepStart...),
+		stepComplete:     append([]namedStepCompleteHook(nil), p.hooks.stepComplete...),
+		pipelineComplete: append([]namedPipelineCompleteHook(nil), p.hooks.pipelineComplete...),
+	}
+}
+
+// runStepStart runs the step start hooks, stopping at the first error
+func (h pipelineHooks) runStepStart(ctx context.Context, session *Session, step *PipelineStep) error {
+	for _, hook := range h.stepStart {
+		if err := hook.fn(ctx, session, step); err != nil {
+			return fmt.Errorf("step start hook %s: %w", hook.name, err)
+		}
+	}
+	return nil
+}
+
+// runStepComplete runs every step completion hook, logging failures
+func (h pipelineHooks) runStepComplete(ctx context.Context, session *Session, step PipelineStep, result *PipelineStepResult, logger *logrus.Logger) {
+	for _, hook := range h.stepComplete {
+		if err := hook.fn(ctx, session, step, result); err != nil {
+			logger.WithFields(logrus.Fields{
+				"session_id": session.ID,
+				"step":       step.Name,
+				"hook":       hook.name,
+				"error":      err,
+			}).Warn("Step completion hook failed")
+		}
+	}
+}
+
+// runPipelineComplete runs every pipeline completion hook, logging failures
+func (h pipelineHooks) runPipelineComplete(ctx context.Context, session *Session, result *PipelineResult, logger *logrus.Logger) {
+	for _, hook := range h.pipelineComplete {
+		if err := hook.fn(ctx, session, result); err != nil {
+			logger.WithFields(logrus.Fields{
+				"session_id": session.ID,
+				"status":     result.Status,
+				"hook":       hook.name,
+				"error":      err,
+			}).Warn("Pipeline completion hook failed")
+		}
+	}
+}
+
+// registerPipelineHooks attaches the orchestrator's cross-cutting concerns to its pipeline
+func (o *Orchestrator) registerPipelineHooks() {
+	o.pipeline.OnPipelineComplete("brainprint", o.trackBrainPrintSession)
+	if o.budgetAlarms != nil {
+		o.pipeline.OnStepComplete("budget_alarms", o.budgetAlarms.recordStep)
+		o.pipeline.OnPipelineComplete("budget_alarms", o.budgetAlarms.recordSession)
+	}
+}
+
+// trackBrainPrintSession records a completed session in the user's BrainPrint
+func (o *Orchestrator) trackBrainPrintSession(ctx context.Context, session *Session, result *PipelineResult) error {
+	if o.brainprintSvc == nil || result.Status != "completed" {
+		return nil
+	}
+
+	o.mu.RLock()
+	userID, _ := session.Metadata["user_id"].(string)
+	explanationType, _ := session.Metadata["explanation_type"].(string)
+	persona, _ := session.Metadata["persona"].(string)
+	o.mu.RUnlock()
+
+	// Sessions without a user are tracked under the session ID
+	if userID == "" {
+		userID = session.ID
+	}
+	if explanationType == "" {
+		explanationType = "standard"
+	}
+	return o.brainprintSvc.TrackSessionWithPersona(ctx, userID, explanationType, persona, true)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHookTestPipeline returns a pipeline whose agents all complete, and a session to run it on
+func newHookTestPipeline() (*Pipeline, *Orchestrator, *recordingAgentClient) {
+	agent := &recordingAgentClient{inputs: make(map[string]map[string]string)}
+	config := DefaultPipelineConfig()
+	config.RetryDelay = time.Millisecond
+	p := &Pipeline{
+		config:     config,
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+	o := &Orchestrator{
+		sessions: map[string]*Session{"s1": {ID: "s1", Topic: "Caching", Status: "created", Metadata: map[string]interface{}{}}},
+		logger:   logrus.New(),
+		clients:  make(map[string][]chan SSEEvent),
+		pipeline: p,
+	}
+	return p, o, agent
+}
+
+// TestPipelineHooksRunInOrder tests that hooks run in registration order around steps and the run
+func TestPipelineHooksRunInOrder(t *testing.T) {
+	p, o, agent := newHookTestPipeline()
+
+	var calls []string
+	p.OnStepStart("first", func(ctx context.Context, session *Session, step *PipelineStep) error {
+		calls = append(calls, "start:first:"+step.Name)
+		step.Inputs["audited"] = "true"
+		return nil
+	})
+	p.OnStepStart("second", func(ctx context.Context, session *Session, step *PipelineStep) error {
+		calls = append(calls, "start:second:"+step.Name)
+		return nil
+	})
+	p.OnStepComplete("failing", func(ctx context.Context, session *Session, step PipelineStep, result *PipelineStepResult) error {
+		calls = append(calls, "complete:failing:"+step.Name)
+		return errors.New("metrics backend down")
+	})
+	p.OnStepComplete("after", func(ctx context.Context, session *Session, step PipelineStep, result *PipelineStepResult) error {
+		calls = append(calls, "complete:after:"+result.Status)
+		return nil
+	})
+	var final *PipelineResult
+	p.OnPipelineComplete("record", func(ctx context.Context, session *Session, result *PipelineResult) error {
+		final = result
+		return nil
+	})
+
+	require.NoError(t, p.runPipeline(context.Background(), "s1", o))
+
+	assert.Equal(t, []string{
+		"start:first:summarizer", "start:second:summarizer", "complete:failing:summarizer", "complete:after:completed",
+	}, calls[:4], "a failing completion hook does not stop later hooks")
+	assert.Len(t, calls, 16)
+	assert.Equal(t, "true", agent.inputs["critic"]["audited"], "start hooks can change step inputs")
+	require.NotNil(t, final)
+	assert.Equal(t, "completed", final.Status)
+	assert.NotZero(t, final.Duration)
+}
+
+// TestStepStartHookFailsStep tests that a step start hook error fails the run without calling the agent
+func TestStepStartHookFailsStep(t *testing.T) {
+	p, o, agent := newHookTestPipeline()
+
+	var skipped bool
+	p.OnStepStart("policy", func(ctx context.Context, session *Session, step *PipelineStep) error {
+		if step.Name == "explainer" {
+			return errors.New("topic not allowed")
+		}
+		return nil
+	})
+	p.OnStepStart("never", func(ctx context.Context, session *Session, step *PipelineStep) error {
+		skipped = skipped || step.Name == "explainer"
+		return nil
+	})
+	var final *PipelineResult
+	p.OnPipelineComplete("record", func(ctx context.Context, session *Session, result *PipelineResult) error {
+		final = result
+		return nil
+	})
+
+	err := p.runPipeline(context.Background(), "s1", o)
+	assert.ErrorContains(t, err, "step start hook policy: topic not allowed")
+	assert.False(t, skipped, "hooks after a failing start hook do not run")
+	_, called := agent.inputs["explainer"]
+	assert.False(t, called)
+	require.NotNil(t, final, "completion hooks run on failed runs")
+	assert.Equal(t, "failed", final.Status)
+}
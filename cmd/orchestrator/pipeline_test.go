@@ -296,7 +296,7 @@ func TestPipelineContextRetrieval(t *testing.T) {
 
 	// Test context retrieval
 	ctx := context.Background()
-	docs, err := pipeline.getContext(ctx, "test-session", "machine learning")
+	docs, err := pipeline.getContext(ctx, "test-session", "", "machine learning")
 
 	// Verify no error occurred
 	assert.NoError(t, err)
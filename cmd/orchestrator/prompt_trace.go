@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// promptTraceEventType is the transcript event holding a step's traced prompts. Like scratchpads,
+// it is recorded in the transcript only, never broadcast to clients.
+const promptTraceEventType = "prompt-trace"
+
+// sessionTracesPrompts reports whether an admin asked to trace a session's prompts
+func sessionTracesPrompts(session *Session) bool {
+	trace, _ := session.Metadata["trace_prompts"].(bool)
+	return trace
+}
+
+// canTracePrompts reports whether the caller may turn on prompt tracing for a session
+func (o *Orchestrator) canTracePrompts(r *http.Request) bool {
+	return callerIsAdmin(r)
+}
+
+// PromptTraceStep is what one step sent to the model: the retrieved context it was given and
+// each rendered prompt with its generation config, fallbacks included
+type PromptTraceStep struct {
+	Step       string                 `json:"step"`
+	Attempt    int                    `json:"attempt"`
+	Context    []PromptTraceSnippet   `json:"context,omitempty"`
+	Prompts    []llm.PromptTraceEntry `json:"prompts"`
+	RecordedAt time.Time              `json:"recorded_at"`
+}
+
+// PromptTraceSnippet is a retrieved context snippet injected into a step
+type PromptTraceSnippet struct {
+	DocID   string  `json:"doc_id,omitempty"`
+	Index   string  `json:"index,omitempty"`
+	Topic   string  `json:"topic,omitempty"`
+	Section string  `json:"section,omitempty"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+// recordPromptTrace removes a step's prompt trace artifact before the step's output is used,
+// storing it with the step's context snippets in the session's transcript
+func (o *Orchestrator) recordPromptTrace(sessionID, stepName string, attempt int, artifacts map[string]string, contextDocs []ContextDoc) {
+	traceJSON, ok := artifacts[agents.PromptTraceArtifact]
+	if !ok {
+		return
+	}
+	delete(artifacts, agents.PromptTraceArtifact)
+
+	var prompts []llm.PromptTraceEntry
+	if err := json.Unmarshal([]byte(traceJSON), &prompts); err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"step":       stepName,
+			"error":      err,
+		}).Warn("Invalid prompt trace, discarding it")
+		return
+	}
+	snippets := make([]PromptTraceSnippet, 0, len(contextDocs))
+	for _, doc := range contextDocs {
+		snippets = append(snippets, PromptTraceSnippet{
+			DocID:   doc.Doc.ID,
+			Index:   doc.Index,
+			Topic:   doc.Doc.Topic,
+			Section: doc.Doc.Section,
+			Snippet: doc.Snippet,
+			Score:   doc.Score,
+		})
+	}
+
+	o.transcripts.record(sessionID, SSEEvent{
+		Type:      promptTraceEventType,
+		SessionID: sessionID,
+		Data: map[string]interface{}{
+			"step":    stepName,
+			"attempt": attempt,
+			"context": snippets,
+			"prompts": prompts,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// promptTracesHandler handles GET /api/admin/sessions/{id}/prompts
+// It lists the prompts traced in the session's transcript, in the order the steps ran.
+func (o *Orchestrator) promptTracesHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	session, exists := o.GetSession(sessionID)
+	if !exists {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	steps := make([]PromptTraceStep, 0)
+	for _, event := range o.transcripts.get(sessionID) {
+		if event.Type != promptTraceEventType {
+			continue
+		}
+		// Round-trip through JSON so live and imported transcripts decode the same way
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			continue
+		}
+		var step PromptTraceStep
+		if err := json.Unmarshal(data, &step); err != nil {
+			continue
+		}
+		step.RecordedAt = event.Timestamp
+		steps = append(steps, step)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session_id": sessionID,
+		"enabled":    sessionTracesPrompts(session),
+		"steps":      steps,
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// promptTraceAgentClient is an AgentClient that returns the prompt it sent when asked to trace prompts
+type promptTraceAgentClient struct{}
+
+// ExecuteTask implements AgentClient
+func (promptTraceAgentClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error) {
+	artifacts := map[string]string{"lesson": `{"big_picture": "Caches keep hot data close"}`}
+	if req.Inputs["trace_prompts"] == "true" {
+		temperature := float32(0.2)
+		trace, _ := json.Marshal([]llm.PromptTraceEntry{{
+			Model:  "gemini-1.5-flash",
+			Prompt: "Explain " + req.Topic,
+			Config: llm.PromptTraceConfig{Temperature: &temperature, ResponseMIMEType: "application/json"},
+		}})
+		artifacts[agents.PromptTraceArtifact] = string(trace)
+	}
+	return &adk.TaskResponse{Artifacts: artifacts}, nil
+}
+
+// Health implements AgentClient
+func (promptTraceAgentClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// TestPromptTrace tests that traced prompts are kept out of step outputs and listed for admins in step order
+func TestPromptTrace(t *testing.T) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+		transcripts:  newSessionTranscripts(),
+	}
+	session := o.CreateSession("Caching")
+	session.Metadata["trace_prompts"] = true
+
+	config := DefaultPipelineConfig()
+	config.RetryDelay = time.Millisecond
+	agent := promptTraceAgentClient{}
+	p := &Pipeline{
+		config:     config,
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+	require.NoError(t, p.runPipeline(context.Background(), session.ID, o))
+
+	session, _ = o.GetSession(session.ID)
+	assert.Equal(t, "completed", session.Status)
+	assert.NotContains(t, session.partialOutputs["explainer"], agents.PromptTraceArtifact)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/sessions/{id}/prompts", o.promptTracesHandler)
+	w := serve(router, http.MethodGet, "/api/admin/sessions/"+session.ID+"/prompts")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Enabled bool              `json:"enabled"`
+		Steps   []PromptTraceStep `json:"steps"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, body.Enabled)
+	require.Len(t, body.Steps, len(agents.Names))
+	assert.Equal(t, "summarizer", body.Steps[0].Step)
+	assert.Equal(t, 1, body.Steps[0].Attempt)
+	require.Len(t, body.Steps[0].Prompts, 1)
+	assert.Equal(t, "Explain Caching", body.Steps[0].Prompts[0].Prompt)
+	assert.Equal(t, float32(0.2), *body.Steps[0].Prompts[0].Config.Temperature)
+
+	// Untraced sessions record nothing
+	untraced := o.CreateSession("Queues")
+	require.NoError(t, p.runPipeline(context.Background(), untraced.ID, o))
+	w = serve(router, http.MethodGet, "/api/admin/sessions/"+untraced.ID+"/prompts")
+	assert.Contains(t, w.Body.String(), `"steps":[]`)
+	assert.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/api/admin/sessions/missing/prompts").Code)
+}
+
+// TestCreateSessionTracePromptsAdminOnly tests that only admins can turn on prompt tracing
+func TestCreateSessionTracePromptsAdminOnly(t *testing.T) {
+	o := newPolicyTestOrchestrator()
+	member := newPolicyTestRouter(o, &auth.Principal{UserID: "u1", Method: auth.MethodJWT, Scopes: []auth.Scope{auth.ScopeSessionsWrite}})
+	admin := newPolicyTestRouter(o, &auth.Principal{UserID: "ops", Method: auth.MethodJWT, Scopes: []auth.Scope{auth.ScopeAdmin}})
+
+	assert.Equal(t, http.StatusForbidden, createPolicySession(member, CreateSessionRequest{Topic: "Caching", TracePrompts: true}).Code)
+
+	w := createPolicySession(admin, CreateSessionRequest{Topic: "Caching", TracePrompts: true})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	session, _ := o.GetSession(created.ID)
+	assert.True(t, sessionTracesPrompts(session))
+
+	// Callers cannot turn it on through free-form metadata
+	w = createPolicySession(member, CreateSessionRequest{Topic: "Caching", Metadata: map[string]string{"trace_prompts": "true"}})
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	session, _ = o.GetSession(created.ID)
+	assert.False(t, sessionTracesPrompts(session))
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// Difficulty levels a session may be written for
+const (
+	DifficultyBeginner     = "beginner"
+	DifficultyIntermediate = "intermediate"
+	DifficultyAdvanced     = "advanced"
+)
+
+// difficultyTargetGrades is the highest Flesch-Kincaid grade acceptable at each difficulty
+var difficultyTargetGrades = map[string]float64{
+	DifficultyBeginner:     8,
+	DifficultyIntermediate: 12,
+	DifficultyAdvanced:     16,
+}
+
+// normalizeDifficulty validates a requested difficulty. Without one, simple explanations are
+// written for beginners and everything else for intermediate readers.
+func normalizeDifficulty(difficulty, explanationType string) (string, error) {
+	difficulty = strings.ToLower(strings.TrimSpace(difficulty))
+	if difficulty == "" {
+		if explanationType == "simple" {
+			return DifficultyBeginner, nil
+		}
+		return DifficultyIntermediate, nil
+	}
+	if _, ok := difficultyTargetGrades[difficulty]; !ok {
+		return "", fmt.Errorf("invalid difficulty %q, expected beginner, intermediate or advanced", difficulty)
+	}
+	return difficulty, nil
+}
+
+// sessionDifficulty returns the difficulty a session was created for
+func sessionDifficulty(session *Session) string {
+	if difficulty, _ := session.Metadata["difficulty"].(string); difficulty != "" {
+		return difficulty
+	}
+	explanationType, _ := session.Metadata["explanation_type"].(string)
+	difficulty, _ := normalizeDifficulty("", explanationType)
+	return difficulty
+}
+
+// ReadabilityReport is a lesson's readability measured against the session's difficulty
+type ReadabilityReport struct {
+	llm.Readability
+	Difficulty         string   `json:"difficulty"`
+	TargetGrade        float64  `json:"target_grade"` // Highest acceptable Flesch-Kincaid grade
+	MeetsTarget        bool     `json:"meets_target"`
+	Simplified         bool     `json:"simplified,omitempty"`          // Sections were rewritten to reach the target
+	SimplifiedSections []string `json:"simplified_sections,omitempty"` // Field names of the rewritten sections
+	OriginalGrade      float64  `json:"original_grade,omitempty"`      // Grade before simplification
+}
+
+// newReadabilityReport scores a lesson for a difficulty
+func newReadabilityReport(lesson *llm.OGLesson, difficulty string) *ReadabilityReport {
+	target := difficultyTargetGrades[difficulty]
+	readability := llm.AnalyzeReadability(lesson)
+	return &ReadabilityReport{
+		Readability: readability,
+		Difficulty:  difficulty,
+		TargetGrade: target,
+		MeetsTarget: readability.FleschKincaidGrade <= target,
+	}
+}
+
+// autoSimplifyFromEnv reports whether lessons above their target grade are rewritten (READABILITY_AUTO_SIMPLIFY)
+func autoSimplifyFromEnv() bool {
+	return os.Getenv("READABILITY_AUTO_SIMPLIFY") == "true"
+}
+
+// simplificationGoal is the rewrite goal for sections above a target grade
+func simplificationGoal(target float64) string {
+	return fmt.Sprintf("be easier to read, using shorter sentences and plainer words, for a reader at US school grade %.0f or below", target)
+}
+
+// scoreReadability measures a finished lesson against the session's difficulty. When the lesson
+// is too hard and auto-simplification is on, its hardest sections are rewritten once; the
+// rewrite is kept only if it lowers the grade. It returns the lesson to use and its report.
+func (o *Orchestrator) scoreReadability(ctx context.Context, session *Session, lessonJSON string) (string, *ReadabilityReport) {
+	lesson := parseLesson(lessonJSON)
+	if lesson == nil {
+		return lessonJSON, nil
+	}
+	report := newReadabilityReport(lesson, sessionDifficulty(session))
+	if report.MeetsTarget || !o.pipeline.config.AutoSimplify || o.regenClient == nil {
+		return lessonJSON, report
+	}
+
+	sections := make([]string, 0, len(report.SectionGrades))
+	for field, grade := range report.SectionGrades {
+		if grade > report.TargetGrade {
+			sections = append(sections, field)
+		}
+	}
+	sort.Strings(sections)
+	if len(sections) == 0 {
+		return lessonJSON, report
+	}
+
+	rewriteCtx := llm.WithRewriteGoal(ctx, simplificationGoal(report.TargetGrade))
+	if persona, ok := session.Metadata["persona"].(string); ok && persona != "" {
+		rewriteCtx = llm.WithPersona(rewriteCtx, llm.LookupPersona(persona))
+	}
+	rewritten, err := o.regenClient.RegenerateSections(rewriteCtx, session.Topic, *lesson, sections)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": session.ID,
+			"sections":   sections,
+			"error":      err,
+		}).Warn("Failed to simplify lesson, keeping it as written")
+		return lessonJSON, report
+	}
+
+	simplified := *lesson
+	for _, field := range sections {
+		simplified.SetSectionText(field, rewritten[field])
+	}
+	simplifiedReport := newReadabilityReport(&simplified, report.Difficulty)
+	if simplifiedReport.FleschKincaidGrade >= report.FleschKincaidGrade {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": session.ID,
+			"grade":      report.FleschKincaidGrade,
+		}).Info("Simplified lesson was not easier to read, keeping the original")
+		return lessonJSON, report
+	}
+	simplifiedJSON, err := json.Marshal(simplified)
+	if err != nil {
+		return lessonJSON, report
+	}
+
+	simplifiedReport.Simplified = true
+	simplifiedReport.SimplifiedSections = sections
+	simplifiedReport.OriginalGrade = report.FleschKincaidGrade
+	o.logger.WithFields(logrus.Fields{
+		"session_id":     session.ID,
+		"sections":       sections,
+		"original_grade": report.FleschKincaidGrade,
+		"grade":          simplifiedReport.FleschKincaidGrade,
+		"target_grade":   report.TargetGrade,
+	}).Info("Lesson simplified to its difficulty target")
+	return string(simplifiedJSON), simplifiedReport
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hardLessonJSON is a lesson whose core mechanism is written well above a beginner's level
+const hardLessonJSON = `{
+	"big_picture": "Caches keep data close.",
+	"core_mechanism": "Consequently, sophisticated cache implementations systematically approximate theoretically optimal replacement policies, prioritizing frequently accessed information according to statistically determined probabilities."
+}`
+
+// simplifyingRegenerator rewrites every section with the same text and records the rewrite goal
+type simplifyingRegenerator struct {
+	text     string
+	goal     string
+	sections []string
+}
+
+// RegenerateSections implements SectionRegenerator
+func (s *simplifyingRegenerator) RegenerateSections(ctx context.Context, topic string, lesson llm.OGLesson, sections []string) (map[string]string, error) {
+	s.goal = llm.RewriteGoalFromContext(ctx)
+	s.sections = sections
+	result := make(map[string]string, len(sections))
+	for _, field := range sections {
+		result[field] = s.text
+	}
+	return result, nil
+}
+
+// CritiqueLesson implements SectionRegenerator
+func (s *simplifyingRegenerator) CritiqueLesson(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
+	return &llm.CritiqueResponse{}, nil
+}
+
+// newReadabilityTestOrchestrator creates an orchestrator that simplifies lessons with regen
+func newReadabilityTestOrchestrator(regen SectionRegenerator, autoSimplify bool) *Orchestrator {
+	config := DefaultPipelineConfig()
+	config.AutoSimplify = autoSimplify
+	return &Orchestrator{
+		logger:      logrus.New(),
+		regenClient: regen,
+		pipeline:    &Pipeline{config: config, logger: logrus.New()},
+	}
+}
+
+// TestNormalizeDifficulty tests difficulty validation and its defaults
+func TestNormalizeDifficulty(t *testing.T) {
+	difficulty, err := normalizeDifficulty("", "simple")
+	require.NoError(t, err)
+	assert.Equal(t, DifficultyBeginner, difficulty)
+
+	difficulty, err = normalizeDifficulty("", "standard")
+	require.NoError(t, err)
+	assert.Equal(t, DifficultyIntermediate, difficulty)
+
+	difficulty, err = normalizeDifficulty(" Advanced ", "simple")
+	require.NoError(t, err)
+	assert.Equal(t, DifficultyAdvanced, difficulty)
+
+	_, err = normalizeDifficulty("expert", "standard")
+	assert.Error(t, err)
+}
+
+// TestScoreReadabilitySimplifies tests that a lesson above its target grade has its hard sections rewritten
+func TestScoreReadabilitySimplifies(t *testing.T) {
+	regen := &simplifyingRegenerator{text: "A cache keeps the data you use most. It drops what you use least."}
+	o := newReadabilityTestOrchestrator(regen, true)
+	session := &Session{ID: "s1", Topic: "Caching", Metadata: map[string]interface{}{"difficulty": DifficultyBeginner}}
+
+	lessonJSON, report := o.scoreReadability(context.Background(), session, hardLessonJSON)
+	require.NotNil(t, report)
+	assert.Equal(t, []string{"core_mechanism"}, regen.sections)
+	assert.Contains(t, regen.goal, "grade 8")
+	assert.True(t, report.Simplified)
+	assert.True(t, report.MeetsTarget)
+	assert.Equal(t, []string{"core_mechanism"}, report.SimplifiedSections)
+	assert.Greater(t, report.OriginalGrade, report.FleschKincaidGrade)
+
+	var lesson llm.OGLesson
+	require.NoError(t, json.Unmarshal([]byte(lessonJSON), &lesson))
+	assert.Equal(t, regen.text, lesson.CoreMechanism)
+	assert.Equal(t, "Caches keep data close.", lesson.BigPicture)
+}
+
+// TestScoreReadabilityKeepsHarderRewrite tests that a rewrite that is no easier to read is discarded
+func TestScoreReadabilityKeepsHarderRewrite(t *testing.T) {
+	regen := &simplifyingRegenerator{text: "Unquestionably, organizational considerations necessitate comprehensive, individualized, institutionally sanctioned documentation procedures."}
+	o := newReadabilityTestOrchestrator(regen, true)
+	session := &Session{ID: "s1", Topic: "Caching", Metadata: map[string]interface{}{"difficulty": DifficultyBeginner}}
+
+	lessonJSON, report := o.scoreReadability(context.Background(), session, hardLessonJSON)
+	require.NotNil(t, report)
+	assert.Equal(t, hardLessonJSON, lessonJSON)
+	assert.False(t, report.Simplified)
+	assert.False(t, report.MeetsTarget)
+}
+
+// TestScoreReadabilityWithoutAutoSimplify tests that lessons are only scored when auto-simplification is off
+func TestScoreReadabilityWithoutAutoSimplify(t *testing.T) {
+	regen := &simplifyingRegenerator{text: "Short."}
+	o := newReadabilityTestOrchestrator(regen, false)
+	session := &Session{ID: "s1", Topic: "Caching", Metadata: map[string]interface{}{"explanation_type": "standard"}}
+
+	lessonJSON, report := o.scoreReadability(context.Background(), session, hardLessonJSON)
+	require.NotNil(t, report)
+	assert.Equal(t, hardLessonJSON, lessonJSON)
+	assert.Nil(t, regen.sections)
+	assert.Equal(t, DifficultyIntermediate, report.Difficulty)
+	assert.Equal(t, float64(12), report.TargetGrade)
+	assert.Positive(t, report.ReadingTimeSeconds)
+
+	_, report = o.scoreReadability(context.Background(), session, "plain text lesson")
+	assert.Nil(t, report)
+}
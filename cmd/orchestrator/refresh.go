@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// LessonRevision represents a regenerated version of a lesson.
+// Saved lesson revisions await user review; session section regenerations apply immediately.
+type LessonRevision struct {
+	ID          string         `json:"id"`
+	SessionID   string         `json:"session_id"`
+	Model       string         `json:"model,omitempty"`
+	Sections    []string       `json:"sections,omitempty"` // Regenerated sections; empty for a full regeneration
+	Result      *SessionResult `json:"result,omitempty"`
+	NeedsReview bool           `json:"needs_review"`
+	Status      string         `json:"status"` // pending, accepted, rejected
+	CreatedAt   time.Time      `json:"created_at"`
+	ReviewedAt  *time.Time     `json:"reviewed_at,omitempty"`
+}
+
+// Revision statuses
+const (
+	RevisionStatusPending  = "pending"
+	RevisionStatusAccepted = "accepted"
+	RevisionStatusRejected = "rejected"
+)
+
+// LessonRefreshConfig represents configuration for the stale lesson refresh job
+type LessonRefreshConfig struct {
+	Enabled    bool          `json:"enabled"`
+	MaxAge     time.Duration `json:"max_age"`
+	RunHour    int           `json:"run_hour"` // Hour of day (UTC) to run the nightly job
+	Model      string        `json:"model"`
+	TopicTerms []string      `json:"topic_terms"`
+	MaxPerRun  int           `json:"max_per_run"`
+}
+
+// defaultRefreshTopicTerms are keywords used to detect technology topics
+var defaultRefreshTopicTerms = []string{
+	"ai", "api", "cloud", "database", "docker", "framework", "go", "golang",
+	"javascript", "kubernetes", "language model", "llm", "machine learning",
+	"neural", "programming", "python", "react", "rust", "security", "software",
+	"sql", "typescript", "web",
+}
+
+// DefaultLessonRefreshConfig returns the refresh configuration from environment variables
+func DefaultLessonRefreshConfig() LessonRefreshConfig {
+	config := LessonRefreshConfig{
+		Enabled:    os.Getenv("LESSON_REFRESH_ENABLED") == "true",
+		MaxAge:     90 * 24 * time.Hour,
+		RunHour:    3,
+		Model:      "gemini-2.5-flash",
+		TopicTerms: defaultRefreshTopicTerms,
+		MaxPerRun:  20,
+	}
+
+	if maxAge := os.Getenv("LESSON_REFRESH_MAX_AGE"); maxAge != "" {
+		if d, err := time.ParseDuration(maxAge); err == nil {
+			config.MaxAge = d
+		}
+	}
+	if runHour := os.Getenv("LESSON_REFRESH_HOUR"); runHour != "" {
+		if h, err := strconv.Atoi(runHour); err == nil && h >= 0 && h < 24 {
+			config.RunHour = h
+		}
+	}
+	if model := os.Getenv("LESSON_REFRESH_MODEL"); model != "" {
+		config.Model = model
+	}
+	if terms := os.Getenv("LESSON_REFRESH_TOPICS"); terms != "" {
+		config.TopicTerms = strings.Split(terms, ",")
+	}
+	if maxPerRun := os.Getenv("LESSON_REFRESH_MAX_PER_RUN"); maxPerRun != "" {
+		if n, err := strconv.Atoi(maxPerRun); err == nil && n > 0 {
+			config.MaxPerRun = n
+		}
+	}
+
+	return config
+}
+
+// LessonRefresher periodically regenerates stale saved lessons
+type LessonRefresher struct {
+	orchestrator *Orchestrator
+	config       LessonRefreshConfig
+	logger       *logrus.Logger
+	now          func() time.Time
+}
+
+// NewLessonRefresher creates a new lesson refresher
+func NewLessonRefresher(orchestrator *Orchestrator, config LessonRefreshConfig) *LessonRefresher {
+	return &LessonRefresher{
+		orchestrator: orchestrator,
+		config:       config,
+		logger:       logrus.New(),
+		now:          time.Now,
+	}
+}
+
+// Start runs the refresh job nightly until the context is cancelled
+func (r *LessonRefresher) Start(ctx context.Context) {
+	r.logger.WithFields(logrus.Fields{
+		"max_age":  r.config.MaxAge,
+		"run_hour": r.config.RunHour,
+		"model":    r.config.Model,
+	}).Info("Starting nightly lesson refresh job")
+
+	for {
+		wait := nextRefreshRun(r.now(), r.config.RunHour).Sub(r.now())
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Lesson refresh job stopped")
+			return
+		case <-time.After(wait):
+			refreshed := r.RunOnce(ctx)
+			r.logger.WithFields(logrus.Fields{
+				"refreshed": refreshed,
+			}).Info("Nightly lesson refresh completed")
+		}
+	}
+}
+
+// RunOnce refreshes all currently stale lessons and returns how many revisions were created
+func (r *LessonRefresher) RunOnce(ctx context.Context) int {
+	refreshed := 0
+	for _, lesson := range r.findStaleLessons() {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := r.refreshLesson(ctx, lesson); err != nil {
+			r.logger.WithFields(logrus.Fields{
+				"saved_id": lesson.ID,
+				"topic":    lesson.Topic,
+				"error":    err,
+			}).Warn("Failed to refresh saved lesson")
+			continue
+		}
+		refreshed++
+	}
+	return refreshed
+}
+
+// findStaleLessons returns saved lessons on technology topics that are older than the max age
+func (r *LessonRefresher) findStaleLessons() []*SavedLesson {
+	cutoff := r.now().Add(-r.config.MaxAge)
+
+	r.orchestrator.mu.RLock()
+	defer r.orchestrator.mu.RUnlock()
+
+	stale := make([]*SavedLesson, 0)
+	for _, lesson := range r.orchestrator.savedLessons {
+		if len(stale) >= r.config.MaxPerRun {
+			break
+		}
+		if lesson.isDeleted() || !lesson.UpdatedAt.Before(cutoff) {
+			continue
+		}
+		if lesson.hasPendingRevision() {
+			continue
+		}
+		if !isTechnologyTopic(lesson.Topic, r.config.TopicTerms) {
+			continue
+		}
+		stale = append(stale, lesson)
+	}
+	return stale
+}
+
+// refreshLesson re-runs the pipeline for a lesson and stores the result as a pending revision
+func (r *LessonRefresher) refreshLesson(ctx context.Context, lesson *SavedLesson) error {
+	session := r.orchestrator.CreateSession(lesson.Topic)
+	session.Metadata["explanation_type"] = lesson.ExplanationType
+	session.Metadata["user_id"] = lesson.UserID
+	session.Metadata["refresh_of"] = lesson.ID
+	session.Metadata["model"] = r.config.Model
+	r.orchestrator.indexSession(session)
+
+	if err := r.orchestrator.pipeline.runPipeline(ctx, session.ID, r.orchestrator); err != nil {
+		return err
+	}
+
+	completed, exists := r.orchestrator.GetSession(session.ID)
+	if !exists || completed.Result == nil {
+		return fmt.Errorf("refresh session %s produced no result", session.ID)
+	}
+
+	revision := &LessonRevision{
+		ID:          uuid.New().String(),
+		SessionID:   session.ID,
+		Model:       r.config.Model,
+		Result:      completed.Result,
+		NeedsReview: true,
+		Status:      RevisionStatusPending,
+		CreatedAt:   r.now(),
+	}
+
+	r.orchestrator.mu.Lock()
+	lesson.Revisions = append(lesson.Revisions, revision)
+	r.orchestrator.mu.Unlock()
+
+	r.logger.WithFields(logrus.Fields{
+		"saved_id":    lesson.ID,
+		"revision_id": revision.ID,
+		"session_id":  session.ID,
+	}).Info("Stored refreshed lesson revision for review")
+
+	return nil
+}
+
+// hasPendingRevision reports whether the lesson already has a revision awaiting review
+func (l *SavedLesson) hasPendingRevision() bool {
+	for _, revision := range l.Revisions {
+		if revision.Status == RevisionStatusPending {
+			return true
+		}
+	}
+	return false
+}
+
+// isTechnologyTopic reports whether a topic matches any of the configured technology terms
+func isTechnologyTopic(topic string, terms []string) bool {
+	words := strings.FieldsFunc(strings.ToLower(topic), func(c rune) bool {
+		return !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9')
+	})
+	normalized := " " + strings.Join(words, " ") + " "
+
+	for _, term := range terms {
+		term = strings.TrimSpace(strings.ToLower(term))
+		if term == "" {
+			continue
+		}
+		if strings.Contains(normalized, " "+term+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// nextRefreshRun returns the next time at the given UTC hour strictly after now
+func nextRefreshRun(now time.Time, hour int) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
+
+// reviewRevisionHandler handles POST /api/saved/{userID}/{id}/revisions/{revisionID}/{accept|reject}
+func (o *Orchestrator) reviewRevisionHandler(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := chi.URLParam(r, "userID")
+		savedID := chi.URLParam(r, "id")
+		revisionID := chi.URLParam(r, "revisionID")
+		w.Header().Set("Content-Type", "application/json")
+
+		o.mu.Lock()
+		savedLesson, exists := o.savedLessons[savedID]
+		if !exists || savedLesson.UserID != userID || savedLesson.isDeleted() {
+			o.mu.Unlock()
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Saved lesson not found",
+				"message": "Saved lesson not found",
+			})
+			return
+		}
+
+		var revision *LessonRevision
+		for _, rev := range savedLesson.Revisions {
+			if rev.ID == revisionID {
+				revision = rev
+				break
+			}
+		}
+		if revision == nil || revision.Status != RevisionStatusPending {
+			o.mu.Unlock()
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Revision not found",
+				"message": "No pending revision with that ID",
+			})
+			return
+		}
+
+		now := time.Now()
+		revision.Status = status
+		revision.NeedsReview = false
+		revision.ReviewedAt = &now
+		if status == RevisionStatusAccepted {
+			savedLesson.Result = revision.Result
+			savedLesson.UpdatedAt = now
+		}
+		o.mu.Unlock()
+
+		o.logger.WithFields(logrus.Fields{
+			"saved_id":    savedID,
+			"revision_id": revisionID,
+			"status":      status,
+		}).Info("Lesson revision reviewed")
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"revision": revision,
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIsTechnologyTopic tests technology topic detection
+func TestIsTechnologyTopic(t *testing.T) {
+	assert.True(t, isTechnologyTopic("Machine Learning basics", defaultRefreshTopicTerms))
+	assert.True(t, isTechnologyTopic("Go concurrency", defaultRefreshTopicTerms))
+	assert.False(t, isTechnologyTopic("Photosynthesis", defaultRefreshTopicTerms))
+	assert.False(t, isTechnologyTopic("Google history", defaultRefreshTopicTerms))
+}
+
+// TestNextRefreshRun tests nightly schedule calculation
+func TestNextRefreshRun(t *testing.T) {
+	before := time.Date(2025, 1, 10, 1, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 1, 10, 3, 0, 0, 0, time.UTC), nextRefreshRun(before, 3))
+
+	after := time.Date(2025, 1, 10, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 1, 11, 3, 0, 0, 0, time.UTC), nextRefreshRun(after, 3))
+}
+
+// TestFindStaleLessons tests selection of lessons to refresh
+func TestFindStaleLessons(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	o := &Orchestrator{
+		savedLessons: map[string]*SavedLesson{
+			"old-tech":    {ID: "old-tech", Topic: "Kubernetes networking", UpdatedAt: now.AddDate(-1, 0, 0)},
+			"old-nontech": {ID: "old-nontech", Topic: "Roman history", UpdatedAt: now.AddDate(-1, 0, 0)},
+			"new-tech":    {ID: "new-tech", Topic: "Python decorators", UpdatedAt: now.AddDate(0, 0, -1)},
+			"pending": {
+				ID:        "pending",
+				Topic:     "SQL joins",
+				UpdatedAt: now.AddDate(-1, 0, 0),
+				Revisions: []*LessonRevision{{ID: "rev", Status: RevisionStatusPending}},
+			},
+		},
+		logger: logrus.New(),
+	}
+
+	refresher := NewLessonRefresher(o, LessonRefreshConfig{
+		MaxAge:     90 * 24 * time.Hour,
+		TopicTerms: defaultRefreshTopicTerms,
+		MaxPerRun:  10,
+	})
+	refresher.now = func() time.Time { return now }
+
+	stale := refresher.findStaleLessons()
+	assert.Len(t, stale, 1)
+	assert.Equal(t, "old-tech", stale[0].ID)
+}
+
+// TestReviewRevisionHandler tests accepting a pending revision
+func TestReviewRevisionHandler(t *testing.T) {
+	o := &Orchestrator{
+		sessions: make(map[string]*Session),
+		savedLessons: map[string]*SavedLesson{
+			"saved-1": {
+				ID:     "saved-1",
+				UserID: "user-1",
+				Result: &SessionResult{Lesson: "old"},
+				Revisions: []*LessonRevision{
+					{ID: "rev-1", Status: RevisionStatusPending, NeedsReview: true, Result: &SessionResult{Lesson: "new"}},
+				},
+			},
+		},
+		logger:  logrus.New(),
+		clients: make(map[string][]chan SSEEvent),
+	}
+	router := o.setupRoutes()
+
+	req := httptest.NewRequest("POST", "/api/saved/user-1/saved-1/revisions/rev-1/accept", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "new", o.savedLessons["saved-1"].Result.Lesson)
+	assert.Equal(t, RevisionStatusAccepted, o.savedLessons["saved-1"].Revisions[0].Status)
+	assert.False(t, o.savedLessons["saved-1"].hasPendingRevision())
+
+	// A reviewed revision cannot be reviewed again
+	req = httptest.NewRequest("POST", "/api/saved/user-1/saved-1/revisions/rev-1/reject", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// SectionRegenerator rewrites individual lesson sections and critiques the result
+type SectionRegenerator interface {
+	RegenerateSections(ctx context.Context, topic string, lesson llm.OGLesson, sections []string) (map[string]string, error)
+	CritiqueLesson(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error)
+}
+
+// RegenerateSectionsRequest represents a request to regenerate lesson sections
+type RegenerateSectionsRequest struct {
+	Sections []string `json:"sections"` // Section field names or anchor IDs, e.g. "metaphor", "toy_example_code"
+}
+
+// RegenerateSectionsResponse represents the revision produced by a section regeneration
+type RegenerateSectionsResponse struct {
+	SessionID string              `json:"session_id"`
+	Revision  *LessonRevision     `json:"revision"`
+	Issues    []llm.CritiqueIssue `json:"issues,omitempty"` // Critic issues found in the regenerated sections
+}
+
+// resolveSections validates requested sections and returns their field names in lesson order
+func resolveSections(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("at least one section is required")
+	}
+
+	wanted := make(map[string]bool, len(requested))
+	for _, id := range requested {
+		section, ok := llm.LookupSection(id)
+		if !ok {
+			return nil, fmt.Errorf("unknown section %q", id)
+		}
+		wanted[section.Field] = true
+	}
+
+	fields := make([]string, 0, len(wanted))
+	for _, section := range llm.LessonSections {
+		if wanted[section.Field] {
+			fields = append(fields, section.Field)
+		}
+	}
+	return fields, nil
+}
+
+// critiqueSections runs the critic over the changed sections only and applies its
+// patches to them. Patches and issues for other sections are discarded.
+func critiqueSections(ctx context.Context, critic SectionRegenerator, lesson *llm.OGLesson, sections []string) ([]llm.CritiqueIssue, error) {
+	changed := make(map[string]string, len(sections))
+	for _, field := range sections {
+		changed[field], _ = lesson.SectionText(field)
+	}
+	changedJSON, err := json.Marshal(changed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode changed sections: %w", err)
+	}
+
+	critique, err := critic.CritiqueLesson(ctx, string(changedJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []llm.CritiqueIssue
+	for _, issue := range critique.Issues {
+		if _, ok := changed[issue.Section]; ok {
+			issues = append(issues, issue)
+		}
+	}
+	for _, patch := range critique.PatchPlan {
+		if _, ok := changed[patch.Section]; ok && patch.ReplacementText != "" {
+			lesson.SetSectionText(patch.Section, patch.ReplacementText)
+		}
+	}
+	return issues, nil
+}
+
+// addSessionRevision records a revision and makes its result the session's current result
+func (o *Orchestrator) addSessionRevision(session *Session, revision *LessonRevision) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	session.Revisions = append(session.Revisions, revision)
+	session.Result = revision.Result
+	session.UpdatedAt = time.Now()
+	o.indexSessionLocked(session)
+}
+
+// regenerateSectionsHandler handles POST /api/sessions/{id}/regenerate
+func (o *Orchestrator) regenerateSectionsHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	session, ok := o.completedSession(w, sessionID)
+	if !ok {
+		return
+	}
+
+	var req RegenerateSectionsRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	sections, err := resolveSections(req.Sections)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lesson := parseLesson(session.Result.Lesson)
+	if lesson == nil {
+		http.Error(w, "Session lesson is not structured and cannot be regenerated by section", http.StatusBadRequest)
+		return
+	}
+
+	if o.regenClient == nil {
+		http.Error(w, "Section regeneration is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	regenerated, err := o.regenClient.RegenerateSections(r.Context(), session.Topic, *lesson, sections)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"sections":   sections,
+			"error":      err,
+		}).Error("Failed to regenerate lesson sections")
+		http.Error(w, "Failed to regenerate sections", http.StatusInternalServerError)
+		return
+	}
+
+	merged := *lesson
+	for _, field := range sections {
+		merged.SetSectionText(field, regenerated[field])
+	}
+
+	// A failed critique keeps the regenerated text rather than failing the request
+	issues, critiqueErr := critiqueSections(r.Context(), o.regenClient, &merged, sections)
+	if critiqueErr != nil {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"error":      critiqueErr,
+		}).Warn("Failed to critique regenerated sections, keeping them unreviewed")
+	}
+
+	lessonJSON, err := json.Marshal(merged)
+	if err != nil {
+		http.Error(w, "Failed to encode lesson", http.StatusInternalServerError)
+		return
+	}
+
+	result := *session.Result
+	result.Lesson = string(lessonJSON)
+	result.LessonHTML = lessonHTML(result.Lesson)
+	result.TOC = llm.BuildTableOfContents(&merged, result.Outline)
+	result.Readability = newReadabilityReport(&merged, sessionDifficulty(session))
+	result.CompletedAt = time.Now()
+
+	revision := &LessonRevision{
+		ID:          uuid.New().String(),
+		SessionID:   sessionID,
+		Sections:    sections,
+		Result:      &result,
+		NeedsReview: critiqueErr != nil,
+		Status:      RevisionStatusAccepted,
+		CreatedAt:   time.Now(),
+	}
+	o.addSessionRevision(session, revision)
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id":  sessionID,
+		"revision_id": revision.ID,
+		"sections":    sections,
+		"issues":      len(issues),
+	}).Info("Lesson sections regenerated")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RegenerateSectionsResponse{
+		SessionID: sessionID,
+		Revision:  revision,
+		Issues:    issues,
+	})
+}
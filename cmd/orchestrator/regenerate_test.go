@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRegenerator returns canned sections and critique, recording what it was asked
+type stubRegenerator struct {
+	sections    []string
+	critiqued   string
+	critique    *llm.CritiqueResponse
+	critiqueErr error
+}
+
+// RegenerateSections implements SectionRegenerator
+func (s *stubRegenerator) RegenerateSections(ctx context.Context, topic string, lesson llm.OGLesson, sections []string) (map[string]string, error) {
+	s.sections = sections
+	result := make(map[string]string, len(sections))
+	for _, field := range sections {
+		result[field] = "new " + field
+	}
+	return result, nil
+}
+
+// CritiqueLesson implements SectionRegenerator
+func (s *stubRegenerator) CritiqueLesson(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
+	s.critiqued = lessonJSON
+	if s.critiqueErr != nil {
+		return nil, s.critiqueErr
+	}
+	if s.critique == nil {
+		return &llm.CritiqueResponse{}, nil
+	}
+	return s.critique, nil
+}
+
+// newRegenerateTestOrchestrator creates an orchestrator with one completed session
+func newRegenerateTestOrchestrator(regen SectionRegenerator) (*Orchestrator, chi.Router) {
+	o, _ := newSectionsTestOrchestrator(nil)
+	o.regenClient = regen
+
+	r := chi.NewRouter()
+	r.Post("/api/sessions/{id}/regenerate", o.regenerateSectionsHandler)
+	return o, r
+}
+
+// postRegenerate posts a regenerate request for the given sections
+func postRegenerate(r chi.Router, sessionID string, sections ...string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(RegenerateSectionsRequest{Sections: sections})
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID+"/regenerate", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestRegenerateSections tests that only the requested sections change and the critic sees only them
+func TestRegenerateSections(t *testing.T) {
+	regen := &stubRegenerator{critique: &llm.CritiqueResponse{
+		Issues: []llm.CritiqueIssue{
+			{Section: "metaphor", Problem: "Too abstract", Severity: "medium"},
+			{Section: "big_picture", Problem: "Out of scope", Severity: "high"},
+		},
+		PatchPlan: []llm.PatchPlanItem{
+			{Section: "metaphor", ReplacementText: "patched metaphor"},
+			{Section: "big_picture", ReplacementText: "must not apply"},
+		},
+	}}
+	o, r := newRegenerateTestOrchestrator(regen)
+
+	rec := postRegenerate(r, "s1", "toy-example", "metaphor")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []string{"metaphor", "toy_example_code"}, regen.sections, "sections are resolved to fields in lesson order")
+	assert.JSONEq(t, `{"metaphor":"new metaphor","toy_example_code":"new toy_example_code"}`, regen.critiqued)
+
+	var resp RegenerateSectionsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Issues, 1)
+	assert.Equal(t, "metaphor", resp.Issues[0].Section)
+	assert.False(t, resp.Revision.NeedsReview)
+
+	session := o.sessions["s1"]
+	require.Len(t, session.Revisions, 1)
+	assert.Equal(t, resp.Revision.ID, session.Revisions[0].ID)
+
+	lesson := parseLesson(session.Result.Lesson)
+	require.NotNil(t, lesson)
+	assert.Equal(t, "Goroutines are cheap threads", lesson.BigPicture)
+	assert.Equal(t, "patched metaphor", lesson.Metaphor)
+	assert.Equal(t, "new toy_example_code", lesson.ToyExampleCode)
+	assert.Equal(t, "The scheduler multiplexes them", lesson.CoreMechanism)
+
+	var hasMetaphor bool
+	for _, entry := range session.Result.TOC {
+		hasMetaphor = hasMetaphor || entry.ID == "metaphor"
+	}
+	assert.True(t, hasMetaphor, "table of contents includes the newly filled section")
+}
+
+// TestRegenerateSectionsCritiqueFailure tests that a failed critique keeps the regenerated text flagged for review
+func TestRegenerateSectionsCritiqueFailure(t *testing.T) {
+	o, r := newRegenerateTestOrchestrator(&stubRegenerator{critiqueErr: errors.New("critic down")})
+
+	rec := postRegenerate(r, "s1", "metaphor")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, o.sessions["s1"].Revisions[0].NeedsReview)
+	assert.Equal(t, "new metaphor", parseLesson(o.sessions["s1"].Result.Lesson).Metaphor)
+}
+
+// TestRegenerateSectionsValidation tests request validation
+func TestRegenerateSectionsValidation(t *testing.T) {
+	_, r := newRegenerateTestOrchestrator(&stubRegenerator{})
+
+	assert.Equal(t, http.StatusBadRequest, postRegenerate(r, "s1").Code)
+	assert.Equal(t, http.StatusBadRequest, postRegenerate(r, "s1", "appendix").Code)
+	assert.Equal(t, http.StatusNotFound, postRegenerate(r, "missing", "metaphor").Code)
+
+	_, r = newRegenerateTestOrchestrator(nil)
+	assert.Equal(t, http.StatusServiceUnavailable, postRegenerate(r, "s1", "metaphor").Code)
+}
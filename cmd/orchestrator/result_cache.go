@@ -0,0 +1,202 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/cache"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultResultCacheSize is how many topics the result cache holds by default
+const defaultResultCacheSize = 500
+
+// uncacheableMetadata are session settings that make a lesson specific to its requester,
+// so sessions with any of them neither use nor fill the result cache
+var uncacheableMetadata = []string{"user_id", "org_id", "persona", "model", "grounding", "deterministic", "warm_start", "supervised"}
+
+// resultCacheEntry is a completed lesson shared by sessions on the same topic
+type resultCacheEntry struct {
+	result    *SessionResult
+	sessionID string // Session that produced the lesson
+	cachedAt  time.Time
+}
+
+// resultCache serves completed lessons of anonymous sessions to later identical requests
+type resultCache struct {
+	entries *cache.LRU
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// resultCacheFromEnv creates the result cache from RESULT_CACHE_TTL and RESULT_CACHE_SIZE.
+// It returns nil, disabling the cache, unless a TTL is set.
+func resultCacheFromEnv() *resultCache {
+	v := os.Getenv("RESULT_CACHE_TTL")
+	if v == "" {
+		return nil
+	}
+	ttl, err := time.ParseDuration(v)
+	if err != nil || ttl <= 0 {
+		logrus.WithField("value", v).Warn("Invalid RESULT_CACHE_TTL, result cache disabled")
+		return nil
+	}
+
+	size := defaultResultCacheSize
+	if v := os.Getenv("RESULT_CACHE_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			size = n
+		} else {
+			logrus.WithField("value", v).Warn("Invalid RESULT_CACHE_SIZE, using default")
+		}
+	}
+	return newResultCache(ttl, size)
+}
+
+// newResultCache creates a result cache whose entries go stale after ttl
+func newResultCache(ttl time.Duration, size int) *resultCache {
+	return &resultCache{entries: cache.NewLRU(size), ttl: ttl, now: time.Now}
+}
+
+// get returns the fresh entry for a key; stale entries are dropped
+func (c *resultCache) get(key string) (*resultCacheEntry, bool) {
+	value, ok := c.entries.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := value.(*resultCacheEntry)
+	if c.now().Sub(entry.cachedAt) > c.ttl {
+		c.entries.Delete(key)
+		return nil, false
+	}
+	return entry, true
+}
+
+// put caches a session's completed result under a key
+func (c *resultCache) put(key, sessionID string, result *SessionResult) {
+	c.entries.Set(key, &resultCacheEntry{result: result, sessionID: sessionID, cachedAt: c.now()})
+}
+
+// resultCacheKey returns the key of a session's lesson: its normalized topic, explanation
+// type, difficulty, image style and enabled feature flags. ok is false for sessions whose lesson is
+// personalized and must not be shared. The caller must hold o.mu.
+func resultCacheKey(session *Session) (key string, ok bool) {
+	for _, name := range uncacheableMetadata {
+		if _, exists := session.Metadata[name]; exists {
+			return "", false
+		}
+	}
+	explanationType, _ := session.Metadata["explanation_type"].(string)
+	if explanationType == "" {
+		explanationType = "standard"
+	}
+	imageStyle, _ := session.Metadata["image_style"].(string)
+	return strings.Join([]string{
+		strings.Join(strings.Fields(strings.ToLower(session.Topic)), " "),
+		explanationType,
+		sessionDifficulty(session),
+		imageStyle,
+		strings.Join(sessionFlags(session), ","),
+	}, "|"), true
+}
+
+// bypassResultCache makes a session's next run generate a fresh lesson (force=true)
+func (o *Orchestrator) bypassResultCache(session *Session) {
+	o.mu.Lock()
+	session.forceFresh = true
+	o.mu.Unlock()
+}
+
+// hasCachedResult reports whether serveCachedResult would complete a session without a pipeline run
+func (o *Orchestrator) hasCachedResult(session *Session) bool {
+	if o.resultCache == nil {
+		return false
+	}
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if session.forceFresh {
+		return false
+	}
+	key, ok := resultCacheKey(session)
+	if !ok {
+		return false
+	}
+	entry, hit := o.resultCache.get(key)
+	return hit && entry.sessionID != session.ID
+}
+
+// serveCachedResult completes a session from the result cache if another session answered an
+// identical request within the TTL, broadcasting the usual completion event. It reports
+// whether the session was served, in which case no pipeline run is needed.
+func (o *Orchestrator) serveCachedResult(sessionID string) bool {
+	if o.resultCache == nil {
+		return false
+	}
+
+	o.mu.Lock()
+	session, exists := o.sessions[sessionID]
+	if !exists || session.forceFresh {
+		o.mu.Unlock()
+		return false
+	}
+	key, ok := resultCacheKey(session)
+	if !ok {
+		o.mu.Unlock()
+		return false
+	}
+	entry, hit := o.resultCache.get(key)
+	if !hit || entry.sessionID == sessionID {
+		o.mu.Unlock()
+		return false
+	}
+
+	result := *entry.result
+	result.Cached = true
+	result.CachedFrom = entry.sessionID
+	session.Result = &result
+	session.Status = "completed"
+	session.UpdatedAt = time.Now()
+	o.indexSessionLocked(session)
+	artifacts := resultArtifacts(&result)
+	o.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id":  sessionID,
+		"cached_from": entry.sessionID,
+		"age":         time.Since(entry.cachedAt).Round(time.Second).String(),
+	}).Info("Session served from result cache")
+
+	o.BroadcastEvent(sessionID, SSEEvent{
+		Type:      "session_complete",
+		SessionID: sessionID,
+		Data: map[string]interface{}{
+			"session_id": sessionID,
+			"status":     "completed",
+			"artifacts":  artifacts,
+			"cached":     true,
+			"timestamp":  time.Now().Format(time.RFC3339),
+		},
+		Timestamp: time.Now(),
+	})
+	return true
+}
+
+// cacheSessionResult offers a freshly completed session's lesson to the result cache
+func (o *Orchestrator) cacheSessionResult(session *Session) {
+	if o.resultCache == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	session.forceFresh = false
+	if session.Result == nil || session.Result.Cached {
+		return
+	}
+	if key, ok := resultCacheKey(session); ok {
+		o.resultCache.put(key, session.ID, session.Result)
+	}
+}
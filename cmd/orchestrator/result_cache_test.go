@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResultCache tests that identical anonymous sessions are completed from the cache without running agents
+func TestResultCache(t *testing.T) {
+	o, agent := newDeadlineTestOrchestrator(0)
+	o.resultCache = newResultCache(time.Hour, 10)
+	now := time.Now()
+	o.resultCache.now = func() time.Time { return now }
+
+	source := o.CreateSession("Consistent Hashing")
+	source.Metadata["explanation_type"] = "standard"
+	source.Status = "completed"
+	source.Result = &SessionResult{Lesson: `{"big_picture":"Keys map to a ring"}`, Summary: "Rings"}
+	o.cacheSessionResult(source)
+
+	session := o.CreateSession("  consistent   hashing ")
+	session.Metadata["explanation_type"] = "standard"
+	client := make(chan SSEEvent, 10)
+	o.AddClient(session.ID, client)
+
+	o.RunSession(session.ID)
+	assert.Zero(t, agent.calls.Load())
+	assert.Equal(t, "completed", session.Status)
+	require.NotNil(t, session.Result)
+	assert.True(t, session.Result.Cached)
+	assert.Equal(t, source.ID, session.Result.CachedFrom)
+	assert.Equal(t, "Rings", session.Result.Summary)
+	assert.False(t, source.Result.Cached, "the source result is not modified")
+
+	event := <-client
+	assert.Equal(t, "session_complete", event.Type)
+	assert.Equal(t, true, event.Data["cached"])
+
+	// A different difficulty, a forced run, the source itself and stale entries all miss
+	harder := o.CreateSession("Consistent Hashing")
+	harder.Metadata["difficulty"] = DifficultyAdvanced
+	assert.False(t, o.serveCachedResult(harder.ID))
+
+	forced := o.CreateSession("Consistent Hashing")
+	o.bypassResultCache(forced)
+	assert.False(t, o.serveCachedResult(forced.ID))
+	assert.False(t, o.serveCachedResult(source.ID))
+
+	later := o.CreateSession("Consistent Hashing")
+	now = now.Add(2 * time.Hour)
+	assert.False(t, o.serveCachedResult(later.ID))
+}
+
+// TestResultCacheKey tests that personalized sessions are never cached
+func TestResultCacheKey(t *testing.T) {
+	session := &Session{Topic: "Raft", Metadata: map[string]interface{}{"explanation_type": "simple"}}
+	key, ok := resultCacheKey(session)
+	require.True(t, ok)
+	assert.Equal(t, "raft|simple|beginner||", key)
+
+	session.Metadata["image_style"] = "whiteboard-sketch"
+	styled, _ := resultCacheKey(session)
+	assert.NotEqual(t, key, styled)
+
+	for _, name := range uncacheableMetadata {
+		personalized := &Session{Topic: "Raft", Metadata: map[string]interface{}{name: "x"}}
+		_, ok := resultCacheKey(personalized)
+		assert.False(t, ok, name)
+	}
+}
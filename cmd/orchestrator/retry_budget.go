@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultSessionRetryBudget is the number of retries a session may spend across all steps
+const defaultSessionRetryBudget = 4
+
+// sessionRetryBudgetFromEnv reads PIPELINE_RETRY_BUDGET, the total retries a session may
+// spend across all steps; 0 leaves only the per-step MaxRetries limit
+func sessionRetryBudgetFromEnv() int {
+	v := os.Getenv("PIPELINE_RETRY_BUDGET")
+	if v == "" {
+		return defaultSessionRetryBudget
+	}
+	budget, err := strconv.Atoi(v)
+	if err != nil || budget < 0 {
+		logrus.WithField("value", v).Warn("Invalid PIPELINE_RETRY_BUDGET, using default")
+		return defaultSessionRetryBudget
+	}
+	return budget
+}
+
+// sessionRetryWindowFromEnv reads PIPELINE_RETRY_WINDOW (e.g. "10m"), the time from the
+// start of a pipeline run after which no further retries begin; unset disables the cap
+func sessionRetryWindowFromEnv() time.Duration {
+	v := os.Getenv("PIPELINE_RETRY_WINDOW")
+	if v == "" {
+		return 0
+	}
+	window, err := time.ParseDuration(v)
+	if err != nil || window < 0 {
+		logrus.WithField("value", v).Warn("Invalid PIPELINE_RETRY_WINDOW, retry window disabled")
+		return 0
+	}
+	return window
+}
+
+// retryBudget limits the retries of one pipeline run across all of its steps,
+// by count and by wall-clock time since the run started
+type retryBudget struct {
+	mu         sync.Mutex
+	maxRetries int           // 0 means no session-wide limit
+	window     time.Duration // 0 means no wall-clock limit
+	startedAt  time.Time
+	used       int
+	exhausted  string // Why the budget ran out, once it has
+}
+
+// newRetryBudget creates the retry budget for a pipeline run starting at startedAt
+func newRetryBudget(config PipelineConfig, startedAt time.Time) *retryBudget {
+	return &retryBudget{
+		maxRetries: config.RetryBudget,
+		window:     config.RetryWindow,
+		startedAt:  startedAt,
+	}
+}
+
+// spend takes one retry from the budget, reporting false and recording why once it is exhausted
+func (b *retryBudget) spend(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.exhausted != "":
+		return false
+	case b.maxRetries > 0 && b.used >= b.maxRetries:
+		b.exhausted = fmt.Sprintf("all %d session retries used", b.maxRetries)
+		return false
+	case b.window > 0 && now.Sub(b.startedAt) >= b.window:
+		b.exhausted = fmt.Sprintf("retry window of %s elapsed", b.window)
+		return false
+	}
+	b.used++
+	return true
+}
+
+// isExhausted reports whether a retry was refused because the budget ran out
+func (b *retryBudget) isExhausted() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.exhausted != ""
+}
+
+// info describes the budget for SSE events
+func (b *retryBudget) info() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	info := map[string]interface{}{
+		"retries_used": b.used,
+		"max_retries":  b.maxRetries,
+	}
+	if b.window > 0 {
+		info["window_seconds"] = b.window.Seconds()
+		info["elapsed_seconds"] = time.Since(b.startedAt).Seconds()
+	}
+	if b.exhausted != "" {
+		info["exhausted"] = b.exhausted
+	}
+	return info
+}
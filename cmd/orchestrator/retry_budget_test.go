@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingAgentClient fails every task and counts the calls
+type failingAgentClient struct {
+	calls int
+}
+
+// ExecuteTask implements AgentClient
+func (c *failingAgentClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error) {
+	c.calls++
+	return nil, errors.New("agent unavailable")
+}
+
+// Health implements AgentClient
+func (c *failingAgentClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// TestRetryBudget tests the retry count and wall-clock limits
+func TestRetryBudget(t *testing.T) {
+	start := time.Now()
+	budget := newRetryBudget(PipelineConfig{RetryBudget: 2}, start)
+	assert.True(t, budget.spend(start))
+	assert.True(t, budget.spend(start))
+	assert.False(t, budget.isExhausted())
+	assert.False(t, budget.spend(start))
+	assert.True(t, budget.isExhausted())
+	assert.Equal(t, "all 2 session retries used", budget.info()["exhausted"])
+
+	budget = newRetryBudget(PipelineConfig{RetryWindow: time.Minute}, start)
+	assert.True(t, budget.spend(start.Add(30*time.Second)))
+	assert.False(t, budget.spend(start.Add(time.Minute)))
+	assert.Contains(t, budget.info(), "window_seconds")
+
+	// Without limits, and without a budget at all, retries are always allowed
+	unlimited := newRetryBudget(PipelineConfig{}, start)
+	for i := 0; i < 100; i++ {
+		require.True(t, unlimited.spend(start.Add(time.Hour)))
+	}
+	var none *retryBudget
+	assert.True(t, none.spend(start))
+	assert.False(t, none.isExhausted())
+}
+
+// TestExecuteStepRetryBudget tests that steps stop retrying once the session budget is spent
+func TestExecuteStepRetryBudget(t *testing.T) {
+	client := &failingAgentClient{}
+	p := &Pipeline{
+		config:     PipelineConfig{MaxRetries: 3, RetryBudget: 4},
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": client},
+	}
+	o := &Orchestrator{logger: logrus.New(), clients: make(map[string][]chan SSEEvent)}
+	step := PipelineStep{Name: "summarizer", Agent: "summarizer", Inputs: map[string]string{"topic": "Caching"}}
+	budget := newRetryBudget(p.config, time.Now())
+
+	// The first step may use all of its per-step retries
+	result := p.executeStep(context.Background(), "s1", step, o, 0, budget)
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, 3, result.RetryCount)
+	assert.Equal(t, 4, client.calls)
+	assert.False(t, budget.isExhausted())
+
+	// The second step only gets what is left of the session budget
+	client.calls = 0
+	result = p.executeStep(context.Background(), "s1", step, o, 1, budget)
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, 1, result.RetryCount)
+	assert.Equal(t, 2, client.calls)
+	assert.True(t, budget.isExhausted())
+	assert.Contains(t, result.Error, "session retry budget exhausted")
+	assert.Contains(t, result.Metadata, "retry_budget")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/rate_limiter"
+	"github.com/sirupsen/logrus"
+)
+
+// routeClass groups routes that share a per-IP token bucket
+type routeClass string
+
+const (
+	routeClassCheap     routeClass = "cheap"     // Reads such as results, status and tips
+	routeClassExpensive routeClass = "expensive" // Session creation, runs and other LLM-backed calls
+)
+
+// routeClassLimit is the token bucket configuration for a route class
+type routeClassLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// defaultRouteClassLimits are used when no environment override is set
+var defaultRouteClassLimits = map[routeClass]routeClassLimit{
+	routeClassCheap:     {RequestsPerSecond: 50, Burst: 100},
+	routeClassExpensive: {RequestsPerSecond: 10, Burst: 20},
+}
+
+// routeClassLimitFromEnv reads RATE_LIMIT_<CLASS>_RPS and RATE_LIMIT_<CLASS>_BURST for a route class
+func routeClassLimitFromEnv(class routeClass) routeClassLimit {
+	limit := defaultRouteClassLimits[class]
+	prefix := fmt.Sprintf("RATE_LIMIT_%s_", strings.ToUpper(string(class)))
+
+	if v := os.Getenv(prefix + "RPS"); v != "" {
+		if rps, err := strconv.ParseFloat(v, 64); err == nil && rps > 0 {
+			limit.RequestsPerSecond = rps
+		} else {
+			logrus.WithField("value", v).Warnf("Invalid %sRPS, using default", prefix)
+		}
+	}
+	if v := os.Getenv(prefix + "BURST"); v != "" {
+		if burst, err := strconv.Atoi(v); err == nil && burst > 0 {
+			limit.Burst = burst
+		} else {
+			logrus.WithField("value", v).Warnf("Invalid %sBURST, using default", prefix)
+		}
+	}
+	return limit
+}
+
+// newRouteClassLimiter creates the limiter for a route class from the environment
+func newRouteClassLimiter(class routeClass) *rate_limiter.Limiter {
+	limit := routeClassLimitFromEnv(class)
+	return rate_limiter.NewLimiter(limit.RequestsPerSecond, limit.Burst)
+}
+
+// routeLimiter returns the limiter for a route class. Classes without their own
+// limiter fall back to the quota manager's limiter; nil means no limit applies.
+func (o *Orchestrator) routeLimiter(class routeClass) *rate_limiter.Limiter {
+	if limiter, ok := o.routeLimiters[class]; ok && limiter != nil {
+		return limiter
+	}
+	if o.quotaManager != nil {
+		return o.quotaManager.RateLimiter
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/quota"
+	"github.com/InnoFusionTech/ExplainIQ/internal/rate_limiter"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRouteClassLimitFromEnv tests per-class overrides and fallback to defaults
+func TestRouteClassLimitFromEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT_CHEAP_RPS", "5")
+	t.Setenv("RATE_LIMIT_CHEAP_BURST", "invalid")
+
+	limit := routeClassLimitFromEnv(routeClassCheap)
+	assert.Equal(t, 5.0, limit.RequestsPerSecond)
+	assert.Equal(t, defaultRouteClassLimits[routeClassCheap].Burst, limit.Burst)
+	assert.Equal(t, defaultRouteClassLimits[routeClassExpensive], routeClassLimitFromEnv(routeClassExpensive))
+}
+
+// TestQuotaMiddlewareRouteClasses tests that cheap and expensive routes draw from separate buckets
+func TestQuotaMiddlewareRouteClasses(t *testing.T) {
+	expensive := rate_limiter.NewLimiter(0.001, 1)
+	o := &Orchestrator{
+		logger:       logrus.New(),
+		quotaManager: quota.NewQuotaManager(expensive, nil),
+		routeLimiters: map[routeClass]*rate_limiter.Limiter{
+			routeClassCheap:     rate_limiter.NewLimiter(0.001, 3),
+			routeClassExpensive: expensive,
+		},
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	r := chi.NewRouter()
+	r.With(o.quotaMiddleware(routeClassExpensive)).Post("/run", ok)
+	r.With(o.quotaMiddleware(routeClassCheap)).Get("/result", ok)
+
+	assert.Equal(t, http.StatusOK, serve(r, http.MethodPost, "/run").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(r, http.MethodPost, "/run").Code)
+
+	// Reads keep working after the expensive bucket is empty
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, serve(r, http.MethodGet, "/result").Code)
+	}
+	w := serve(r, http.MethodGet, "/result")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), `"route_class":"cheap"`)
+}
+
+// TestQuotaMiddlewareWithoutLimiter tests that routes pass through when no limiter is configured
+func TestQuotaMiddlewareWithoutLimiter(t *testing.T) {
+	o := &Orchestrator{logger: logrus.New()}
+
+	r := chi.NewRouter()
+	r.With(o.quotaMiddleware(routeClassCheap)).Get("/tips", func(w http.ResponseWriter, r *http.Request) {})
+	assert.Equal(t, http.StatusOK, serve(r, http.MethodGet, "/tips").Code)
+}
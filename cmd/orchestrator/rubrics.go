@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// newRubricStore creates the critique rubric store, loading rubrics from CRITIC_RUBRICS_FILE if set
+func newRubricStore() *llm.RubricStore {
+	store := llm.NewRubricStore()
+
+	path := os.Getenv("CRITIC_RUBRICS_FILE")
+	if path == "" {
+		return store
+	}
+
+	rubrics, err := llm.LoadRubricsFile(path)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to load critique rubrics, continuing with default rubric")
+		return store
+	}
+
+	for _, rubric := range rubrics {
+		if err := store.Put(rubric); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"rubric_id": rubric.ID,
+				"error":     err,
+			}).Warn("Skipping invalid critique rubric")
+		}
+	}
+	return store
+}
+
+// rubricForSession returns the encoded critique rubric for a session's org and explanation type
+func (o *Orchestrator) rubricForSession(session *Session) string {
+	if o.rubricStore == nil {
+		return ""
+	}
+
+	orgID, _ := session.Metadata["org_id"].(string)
+	explanationType, _ := session.Metadata["explanation_type"].(string)
+
+	rubric := o.rubricStore.Resolve(orgID, explanationType)
+	data, err := json.Marshal(rubric)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": session.ID,
+			"rubric_id":  rubric.ID,
+			"error":      err,
+		}).Warn("Failed to encode critique rubric")
+		return ""
+	}
+	return string(data)
+}
+
+// listRubricsHandler handles GET /api/rubrics
+func (o *Orchestrator) listRubricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	rubrics := o.rubricStore.List()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rubrics": rubrics,
+		"count":   len(rubrics),
+		"default": llm.DefaultRubric(),
+	})
+}
+
+// getRubricHandler handles GET /api/rubrics/{id}
+func (o *Orchestrator) getRubricHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rubric, exists := o.rubricStore.Get(chi.URLParam(r, "id"))
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Rubric not found",
+			"message": "Rubric not found",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(rubric)
+}
+
+// putRubricHandler handles POST /api/rubrics and PUT /api/rubrics/{id}
+func (o *Orchestrator) putRubricHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var rubric llm.Rubric
+	if err := decodeJSONBody(w, r, &rubric); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if id := chi.URLParam(r, "id"); id != "" {
+		rubric.ID = id
+	}
+
+	if err := o.rubricStore.Put(&rubric); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Invalid rubric",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"rubric_id":        rubric.ID,
+		"org_id":           rubric.OrgID,
+		"explanation_type": rubric.ExplanationType,
+	}).Info("Critique rubric saved")
+
+	json.NewEncoder(w).Encode(rubric)
+}
+
+// deleteRubricHandler handles DELETE /api/rubrics/{id}
+func (o *Orchestrator) deleteRubricHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := chi.URLParam(r, "id")
+	if !o.rubricStore.Delete(id) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Rubric not found",
+			"message": "Rubric not found",
+		})
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"rubric_id": id,
+	}).Info("Critique rubric deleted")
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Rubric deleted successfully",
+	})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRubricManagementAPI tests creating, resolving and deleting critique rubrics
+func TestRubricManagementAPI(t *testing.T) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+		rubricStore:  llm.NewRubricStore(),
+	}
+	admin := newAdminKey(t, o)
+	router := o.setupRoutes()
+
+	body, _ := json.Marshal(llm.Rubric{
+		OrgID:    "med-school",
+		Criteria: []llm.RubricCriterion{{Name: "Clinical Accuracy", Description: "Matches current guidelines"}},
+	})
+	req := httptest.NewRequest("PUT", "/api/rubrics/medical", bytes.NewBuffer(body))
+	req.Header.Set(auth.APIKeyHeader, admin)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Sessions for the org receive the custom rubric
+	session := &Session{ID: "s1", Metadata: map[string]interface{}{"org_id": "med-school"}}
+	var rubric llm.Rubric
+	require.NoError(t, json.Unmarshal([]byte(o.rubricForSession(session)), &rubric))
+	assert.Equal(t, "medical", rubric.ID)
+
+	// Other orgs fall back to the default rubric
+	other := &Session{ID: "s2", Metadata: map[string]interface{}{"org_id": "bootcamp"}}
+	require.NoError(t, json.Unmarshal([]byte(o.rubricForSession(other)), &rubric))
+	assert.Equal(t, "default", rubric.ID)
+
+	// Invalid rubrics are rejected
+	req = httptest.NewRequest("POST", "/api/rubrics", bytes.NewBufferString(`{"id":"empty"}`))
+	req.Header.Set(auth.APIKeyHeader, admin)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest("DELETE", "/api/rubrics/medical", nil)
+	req.Header.Set(auth.APIKeyHeader, admin)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("GET", "/api/rubrics/medical", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestSessionRubricFollowsPrincipalOrg tests that only the critic receives a rubric, chosen by the caller's org rather than the request body
+func TestSessionRubricFollowsPrincipalOrg(t *testing.T) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+		rubricStore:  llm.NewRubricStore(),
+	}
+	require.NoError(t, o.rubricStore.Put(&llm.Rubric{
+		ID:       "medical",
+		OrgID:    "med-school",
+		Criteria: []llm.RubricCriterion{{Name: "Clinical Accuracy", Description: "Matches current guidelines"}},
+	}))
+
+	criticRubric := func(principal *auth.Principal) string {
+		body, _ := json.Marshal(CreateSessionRequest{Topic: "Sepsis", OrgID: "med-school"})
+		w := httptest.NewRecorder()
+		withPrincipal(http.HandlerFunc(o.createSessionHandler), principal).ServeHTTP(w, httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body)))
+		require.Equal(t, http.StatusCreated, w.Code)
+		var response CreateSessionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		agent := &recordingAgentClient{inputs: make(map[string]map[string]string)}
+		p := &Pipeline{
+			config:     DefaultPipelineConfig(),
+			logger:     logrus.New(),
+			adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+		}
+		require.NoError(t, p.runPipeline(context.Background(), response.ID, o))
+		for _, step := range []string{"summarizer", "explainer", "visualizer"} {
+			assert.NotContains(t, agent.inputs[step], "rubric", step)
+		}
+
+		var rubric llm.Rubric
+		require.NoError(t, json.Unmarshal([]byte(agent.inputs["critic"]["rubric"]), &rubric))
+		return rubric.ID
+	}
+
+	assert.Equal(t, "default", criticRubric(nil), "the body org_id is ignored")
+	assert.Equal(t, "default", criticRubric(&auth.Principal{UserID: "bob", OrgID: "bootcamp"}))
+	assert.Equal(t, "medical", criticRubric(&auth.Principal{UserID: "alice", OrgID: "med-school"}))
+}
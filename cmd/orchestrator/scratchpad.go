@@ -0,0 +1,187 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// scratchpadEventType is the transcript event holding an encrypted scratchpad. It is recorded in
+// the transcript only, never broadcast to clients.
+const scratchpadEventType = "scratchpad"
+
+// scratchpadVault encrypts explainer scratchpads with AES-GCM
+type scratchpadVault struct {
+	aead cipher.AEAD
+}
+
+// newScratchpadVault creates a vault from a 32-byte key
+func newScratchpadVault(key []byte) (*scratchpadVault, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("scratchpad key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &scratchpadVault{aead: aead}, nil
+}
+
+// scratchpadVaultFromEnv creates the vault from SCRATCHPAD_ENCRYPTION_KEY (base64, 32 bytes).
+// Regulated deployments set SCRATCHPAD_STORAGE=off to discard scratchpads entirely; without a
+// valid key they are discarded too, since they are never stored in the clear.
+func scratchpadVaultFromEnv() *scratchpadVault {
+	if storage := strings.ToLower(os.Getenv("SCRATCHPAD_STORAGE")); storage == "off" || storage == "false" {
+		logrus.Info("Scratchpad storage disabled, explainer scratchpads are discarded")
+		return nil
+	}
+	encoded := os.Getenv("SCRATCHPAD_ENCRYPTION_KEY")
+	if encoded == "" {
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid SCRATCHPAD_ENCRYPTION_KEY, explainer scratchpads are discarded")
+		return nil
+	}
+	vault, err := newScratchpadVault(key)
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid SCRATCHPAD_ENCRYPTION_KEY, explainer scratchpads are discarded")
+		return nil
+	}
+	return vault
+}
+
+// scratchpadAAD binds a sealed scratchpad to the session and step it was written for, so its
+// ciphertext cannot be replayed into another session's transcript
+func scratchpadAAD(sessionID, step string) []byte {
+	return []byte(sessionID + "\x00" + step)
+}
+
+// seal encrypts a session step's scratchpad, returning the nonce and ciphertext base64-encoded
+func (v *scratchpadVault) seal(sessionID, step, plaintext string) (string, error) {
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(v.aead.Seal(nonce, nonce, []byte(plaintext), scratchpadAAD(sessionID, step))), nil
+}
+
+// open decrypts a scratchpad sealed for a session step
+func (v *scratchpadVault) open(sessionID, step, sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < v.aead.NonceSize() {
+		return "", fmt.Errorf("sealed scratchpad is too short")
+	}
+	nonce, ciphertext := data[:v.aead.NonceSize()], data[v.aead.NonceSize():]
+	plaintext, err := v.aead.Open(nil, nonce, ciphertext, scratchpadAAD(sessionID, step))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// redactScratchpad removes a step's scratchpad artifact before the step's output is used,
+// storing it encrypted in the session's transcript when storage is enabled
+func (o *Orchestrator) redactScratchpad(sessionID, stepName string, artifacts map[string]string) {
+	scratchpad, ok := artifacts[agents.ScratchpadArtifact]
+	if !ok {
+		return
+	}
+	delete(artifacts, agents.ScratchpadArtifact)
+	if o.scratchpads == nil || scratchpad == "" {
+		return
+	}
+
+	sealed, err := o.scratchpads.seal(sessionID, stepName, scratchpad)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"step":       stepName,
+			"error":      err,
+		}).Warn("Failed to encrypt scratchpad, discarding it")
+		return
+	}
+	o.transcripts.record(sessionID, SSEEvent{
+		Type:      scratchpadEventType,
+		SessionID: sessionID,
+		Data: map[string]interface{}{
+			"step":       stepName,
+			"ciphertext": sealed,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// ScratchpadEntry is a decrypted scratchpad from a session's transcript
+type ScratchpadEntry struct {
+	Step       string    `json:"step"`
+	Scratchpad string    `json:"scratchpad"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// scratchpadHandler handles GET /api/admin/sessions/{id}/scratchpad
+// It decrypts the scratchpads recorded in the session's transcript, including imported ones
+// sealed with the same key, which stay bound to the ID of the session they were exported from.
+func (o *Orchestrator) scratchpadHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	if o.scratchpads == nil {
+		http.Error(w, "Scratchpad storage is disabled", http.StatusNotFound)
+		return
+	}
+	o.mu.RLock()
+	session, exists := o.sessions[sessionID]
+	sealedFor := sessionID
+	if exists {
+		if original, ok := session.Metadata["imported_from"].(string); ok && original != "" {
+			sealedFor = original
+		}
+	}
+	o.mu.RUnlock()
+	if !exists {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	entries := make([]ScratchpadEntry, 0)
+	for _, event := range o.transcripts.get(sessionID) {
+		if event.Type != scratchpadEventType {
+			continue
+		}
+		sealed, _ := event.Data["ciphertext"].(string)
+		step, _ := event.Data["step"].(string)
+		scratchpad, err := o.scratchpads.open(sealedFor, step, sealed)
+		if err != nil {
+			o.logger.WithFields(logrus.Fields{
+				"session_id": sessionID,
+				"error":      err,
+			}).Warn("Failed to decrypt scratchpad")
+			continue
+		}
+		entries = append(entries, ScratchpadEntry{Step: step, Scratchpad: scratchpad, RecordedAt: event.Timestamp})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session_id":  sessionID,
+		"scratchpads": entries,
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scratchpadAgentClient is an AgentClient that returns a scratchpad with its lesson when asked for one
+type scratchpadAgentClient struct{}
+
+// ExecuteTask implements AgentClient
+func (scratchpadAgentClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error) {
+	artifacts := map[string]string{"lesson": `{"big_picture": "Caches keep hot data close"}`}
+	if req.Inputs["scratchpad"] == "true" {
+		artifacts[agents.ScratchpadArtifact] = "secret reasoning"
+	}
+	return &adk.TaskResponse{Artifacts: artifacts}, nil
+}
+
+// Health implements AgentClient
+func (scratchpadAgentClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// TestScratchpadRedaction tests that scratchpads never reach session outputs or clients and are readable only encrypted
+func TestScratchpadRedaction(t *testing.T) {
+	vault, err := newScratchpadVault(bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+		transcripts:  newSessionTranscripts(),
+		scratchpads:  vault,
+	}
+	session := o.CreateSession("Caching")
+
+	config := DefaultPipelineConfig()
+	config.RetryDelay = time.Millisecond
+	config.ExplainerScratchpad = true
+	agent := scratchpadAgentClient{}
+	p := &Pipeline{
+		config:     config,
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+	require.NoError(t, p.runPipeline(context.Background(), session.ID, o))
+
+	session, _ = o.GetSession(session.ID)
+	assert.Equal(t, "completed", session.Status)
+	assert.NotContains(t, session.partialOutputs["explainer"], agents.ScratchpadArtifact)
+	transcript, err := json.Marshal(o.transcripts.get(session.ID))
+	require.NoError(t, err)
+	assert.NotContains(t, string(transcript), "secret reasoning")
+	assert.Contains(t, string(transcript), scratchpadEventType)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/sessions/{id}/scratchpad", o.scratchpadHandler)
+	w := serve(router, "GET", "/api/admin/sessions/"+session.ID+"/scratchpad")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Scratchpads []ScratchpadEntry `json:"scratchpads"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Scratchpads, 1)
+	assert.Equal(t, "explainer", body.Scratchpads[0].Step)
+	assert.Equal(t, "secret reasoning", body.Scratchpads[0].Scratchpad)
+
+	// A scratchpad copied into another session's transcript does not decrypt there
+	other := o.CreateSession("Queues")
+	for _, event := range o.transcripts.get(session.ID) {
+		if event.Type == scratchpadEventType {
+			o.transcripts.record(other.ID, event)
+		}
+	}
+	w = serve(router, "GET", "/api/admin/sessions/"+other.ID+"/scratchpad")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Empty(t, body.Scratchpads)
+
+	// With storage off the scratchpad is still stripped, but not kept
+	o.scratchpads = nil
+	artifacts := map[string]string{"lesson": "{}", agents.ScratchpadArtifact: "more reasoning"}
+	o.redactScratchpad("s2", "explainer", artifacts)
+	assert.NotContains(t, artifacts, agents.ScratchpadArtifact)
+	assert.Empty(t, o.transcripts.get("s2"))
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/admin/sessions/"+session.ID+"/scratchpad").Code)
+}
+
+// TestScratchpadVaultFromEnv tests the storage switch and key validation
+func TestScratchpadVaultFromEnv(t *testing.T) {
+	t.Setenv("SCRATCHPAD_ENCRYPTION_KEY", "c2hvcnQ=")
+	assert.Nil(t, scratchpadVaultFromEnv())
+
+	t.Setenv("SCRATCHPAD_ENCRYPTION_KEY", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	vault := scratchpadVaultFromEnv()
+	require.NotNil(t, vault)
+	sealed, err := vault.seal("s1", "explainer", "reasoning")
+	require.NoError(t, err)
+	opened, err := vault.open("s1", "explainer", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "reasoning", opened)
+	_, err = vault.open("s2", "explainer", sealed)
+	assert.Error(t, err)
+	_, err = vault.open("s1", "critic", sealed)
+	assert.Error(t, err)
+
+	t.Setenv("SCRATCHPAD_STORAGE", "off")
+	assert.Nil(t, scratchpadVaultFromEnv())
+}
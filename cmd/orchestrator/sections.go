@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// QuestionAnswerer answers follow-up questions about a lesson
+type QuestionAnswerer interface {
+	AnswerQuestion(ctx context.Context, topic, lessonContext, question string) (string, error)
+}
+
+// AskQuestionRequest represents a follow-up question about a session's lesson
+type AskQuestionRequest struct {
+	Question string `json:"question"`
+}
+
+// AskQuestionResponse represents the answer to a follow-up question
+type AskQuestionResponse struct {
+	SessionID string `json:"session_id"`
+	Section   string `json:"section,omitempty"`
+	Question  string `json:"question"`
+	Answer    string `json:"answer"`
+}
+
+// parseLesson decodes a lesson JSON string, returning nil if it is not a structured lesson
+func parseLesson(lessonJSON string) *llm.OGLesson {
+	var lesson llm.OGLesson
+	if err := json.Unmarshal([]byte(lessonJSON), &lesson); err != nil {
+		return nil
+	}
+	return &lesson
+}
+
+// extractOutline extracts the summarizer outline from final result
+func (p *Pipeline) extractOutline(finalResult map[string]interface{}) []string {
+	if summarizer, exists := finalResult["summarizer"]; exists {
+		if summarizerMap, ok := summarizer.(map[string]string); ok {
+			var outline []string
+			if err := json.Unmarshal([]byte(summarizerMap["outline"]), &outline); err == nil {
+				return outline
+			}
+		}
+	}
+	return nil
+}
+
+// stepSectionAnchors returns the anchors a completed step's output makes linkable: the outline
+// bullets once the summarizer lands and the lesson sections once the explainer does. They match
+// the anchors of the final result's TOC.
+func stepSectionAnchors(stepName string, output map[string]string) []llm.TOCEntry {
+	switch stepName {
+	case "summarizer":
+		var outline []string
+		if err := json.Unmarshal([]byte(output["outline"]), &outline); err != nil {
+			return nil
+		}
+		return llm.BuildTableOfContents(nil, outline)
+	case "explainer":
+		lesson := parseLesson(output["lesson"])
+		if lesson == nil {
+			return nil
+		}
+		return llm.BuildTableOfContents(lesson, nil)
+	}
+	return nil
+}
+
+// scopedLessonContext returns the lesson content a question should be answered from.
+// An empty section returns the whole lesson; an unknown section returns an error.
+func scopedLessonContext(result *SessionResult, section string) (string, error) {
+	lesson := parseLesson(result.Lesson)
+
+	if section == "" {
+		if lesson == nil {
+			return result.Lesson, nil
+		}
+		var b strings.Builder
+		for _, s := range llm.LessonSections {
+			if text, _ := lesson.SectionText(s.ID); text != "" {
+				b.WriteString(fmt.Sprintf("%s:\n%s\n\n", s.Title, text))
+			}
+		}
+		return b.String(), nil
+	}
+
+	if lesson != nil {
+		if text, ok := lesson.SectionText(section); ok {
+			if text == "" {
+				return "", fmt.Errorf("section %q is empty", section)
+			}
+			return text, nil
+		}
+	}
+
+	for i, bullet := range result.Outline {
+		if llm.OutlineAnchor(i, bullet) == section {
+			return bullet, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown section %q", section)
+}
+
+// renderLessonMarkdown renders a session's lesson as Markdown with a table of contents and section anchors
+func renderLessonMarkdown(session *Session) string {
+	var b strings.Builder
+	lesson := parseLesson(session.Result.Lesson)
+
+	b.WriteString(fmt.Sprintf("# %s\n\n", session.Topic))
+
+	if len(session.Result.TOC) > 0 {
+		b.WriteString("## Contents\n\n")
+		for _, entry := range session.Result.TOC {
+			if entry.Kind == "section" {
+				b.WriteString(fmt.Sprintf("- [%s](#%s)\n", entry.Title, entry.ID))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(session.Result.Outline) > 0 {
+		b.WriteString("## Outline\n\n")
+		for i, bullet := range session.Result.Outline {
+			b.WriteString(fmt.Sprintf("- <a id=\"%s\"></a>%s\n", llm.OutlineAnchor(i, bullet), bullet))
+		}
+		b.WriteString("\n")
+	}
+
+	if lesson == nil {
+		b.WriteString(session.Result.Lesson)
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	for _, section := range llm.LessonSections {
+		text, _ := lesson.SectionText(section.ID)
+		if text == "" {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n## %s\n\n", section.ID, section.Title))
+		if section.Field == "toy_example_code" {
+			b.WriteString(fmt.Sprintf("```\n%s\n```\n\n", strings.TrimSpace(text)))
+		} else {
+			b.WriteString(text)
+			b.WriteString("\n\n")
+		}
+	}
+
+	return b.String()
+}
+
+// renderLessonHTML renders a session's lesson as a standalone HTML document with section anchors
+func renderLessonHTML(session *Session) string {
+	var b strings.Builder
+	lesson := parseLesson(session.Result.Lesson)
+	title := html.EscapeString(session.Topic)
+
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString(fmt.Sprintf("<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n", title, title))
+
+	if len(session.Result.TOC) > 0 {
+		b.WriteString("<nav id=\"contents\">\n<h2>Contents</h2>\n<ul>\n")
+		for _, entry := range session.Result.TOC {
+			if entry.Kind == "section" {
+				b.WriteString(fmt.Sprintf("<li><a href=\"#%s\">%s</a></li>\n", entry.ID, html.EscapeString(entry.Title)))
+			}
+		}
+		b.WriteString("</ul>\n</nav>\n")
+	}
+
+	if len(session.Result.Outline) > 0 {
+		b.WriteString("<section id=\"outline\">\n<h2>Outline</h2>\n<ul>\n")
+		for i, bullet := range session.Result.Outline {
+			b.WriteString(fmt.Sprintf("<li id=\"%s\">%s</li>\n", llm.OutlineAnchor(i, bullet), html.EscapeString(bullet)))
+		}
+		b.WriteString("</ul>\n</section>\n")
+	}
+
+	if lesson == nil {
+		b.WriteString(fmt.Sprintf("<pre>%s</pre>\n", html.EscapeString(session.Result.Lesson)))
+	} else {
+		b.WriteString(llm.RenderLessonHTML(lesson))
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// lessonHTML renders a lesson as sanitized HTML for the result's lesson_html. A lesson that is
+// not OGLesson JSON is rendered as Markdown.
+func lessonHTML(lessonJSON string) string {
+	if lesson := parseLesson(lessonJSON); lesson != nil {
+		return llm.RenderLessonHTML(lesson)
+	}
+	return llm.RenderMarkdown(lessonJSON)
+}
+
+// completedSession returns a completed session by ID, writing an error response if unavailable
+func (o *Orchestrator) completedSession(w http.ResponseWriter, sessionID string) (*Session, bool) {
+	session, exists := o.GetSession(sessionID)
+	if !exists {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	if session.Status != "completed" || session.Result == nil {
+		http.Error(w, "Session not completed", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return session, true
+}
+
+// exportSessionHandler handles GET /api/sessions/{id}/export?format=markdown|html|bundle
+// The bundle format exports a session in any status for POST /api/sessions/import.
+func (o *Orchestrator) exportSessionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("format") == "bundle" {
+		o.writeSessionBundle(w, r, chi.URLParam(r, "id"))
+		return
+	}
+
+	session, ok := o.completedSession(w, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "markdown", "md":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write([]byte(renderLessonMarkdown(session)))
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(renderLessonHTML(session)))
+	default:
+		http.Error(w, fmt.Sprintf("Unsupported export format: %s", format), http.StatusBadRequest)
+	}
+}
+
+// askQuestionHandler handles POST /api/sessions/{id}/questions?section=
+func (o *Orchestrator) askQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	session, ok := o.completedSession(w, sessionID)
+	if !ok {
+		return
+	}
+
+	var req AskQuestionRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Question) == "" {
+		http.Error(w, "Question is required", http.StatusBadRequest)
+		return
+	}
+
+	section := strings.TrimSpace(r.URL.Query().Get("section"))
+	lessonContext, err := scopedLessonContext(session.Result, section)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if o.qaClient == nil {
+		http.Error(w, "Follow-up questions are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	answer, err := o.qaClient.AnswerQuestion(r.Context(), session.Topic, lessonContext, req.Question)
+	if err != nil {
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"section":    section,
+			"error":      err,
+		}).Error("Failed to answer follow-up question")
+		http.Error(w, "Failed to answer question", http.StatusInternalServerError)
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"section":    section,
+	}).Info("Answered follow-up question")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AskQuestionResponse{
+		SessionID: sessionID,
+		Section:   section,
+		Question:  req.Question,
+		Answer:    answer,
+	})
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubQuestionAnswerer records the context it was asked to answer from
+type stubQuestionAnswerer struct {
+	lessonContext string
+}
+
+// AnswerQuestion implements QuestionAnswerer
+func (s *stubQuestionAnswerer) AnswerQuestion(ctx context.Context, topic, lessonContext, question string) (string, error) {
+	s.lessonContext = lessonContext
+	return "answer", nil
+}
+
+// newSectionsTestOrchestrator creates an orchestrator with one completed session
+func newSectionsTestOrchestrator(qa QuestionAnswerer) (*Orchestrator, chi.Router) {
+	lesson := `{"big_picture":"Goroutines are cheap threads","core_mechanism":"The scheduler multiplexes them","toy_example_code":"go f()"}`
+	outline := []string{"What is a goroutine?"}
+
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"s1": {
+				ID:     "s1",
+				Topic:  "Goroutines",
+				Status: "completed",
+				Result: &SessionResult{
+					Lesson:  lesson,
+					Outline: outline,
+					TOC:     llm.BuildTableOfContents(parseLesson(lesson), outline),
+				},
+			},
+		},
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+		qaClient:     qa,
+	}
+
+	r := chi.NewRouter()
+	r.Get("/api/sessions/{id}/export", o.exportSessionHandler)
+	r.Post("/api/sessions/{id}/questions", o.askQuestionHandler)
+	return o, r
+}
+
+// TestExportSessionAnchors tests that exported lessons carry section anchors
+func TestExportSessionAnchors(t *testing.T) {
+	_, router := newSectionsTestOrchestrator(nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/sessions/s1/export?format=markdown", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "[Core Mechanism](#core-mechanism)")
+	assert.Contains(t, w.Body.String(), `<a id="outline-1-what-is-a-goroutine"></a>`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/sessions/s1/export?format=html", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<section id="big-picture">`)
+	assert.Contains(t, w.Body.String(), `<a href="#toy-example">`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/sessions/s1/export?format=pdf", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestAskQuestionScopedToSection tests that ?section= limits the question context
+func TestAskQuestionScopedToSection(t *testing.T) {
+	qa := &stubQuestionAnswerer{}
+	_, router := newSectionsTestOrchestrator(qa)
+	body, _ := json.Marshal(AskQuestionRequest{Question: "Why is it cheap?"})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/sessions/s1/questions?section=core-mechanism", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "The scheduler multiplexes them", qa.lessonContext)
+
+	var response AskQuestionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "answer", response.Answer)
+	assert.Equal(t, "core-mechanism", response.Section)
+
+	// Without a section the whole lesson is used
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/sessions/s1/questions", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, qa.lessonContext, "Goroutines are cheap threads")
+	assert.Contains(t, qa.lessonContext, "The scheduler multiplexes them")
+
+	// Outline anchors are accepted too
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/sessions/s1/questions?section=outline-1-what-is-a-goroutine", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "What is a goroutine?", qa.lessonContext)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/sessions/s1/questions?section=appendix", bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestLessonHTML tests rendering lesson JSON and plain Markdown lessons as sanitized HTML
+func TestLessonHTML(t *testing.T) {
+	out := lessonHTML(`{"big_picture":"Caches are <script>alert(1)</script> **fast**"}`)
+	assert.Contains(t, out, `<section id="big-picture">`)
+	assert.Contains(t, out, "<strong>fast</strong>")
+	assert.NotContains(t, out, "<script>")
+
+	assert.Equal(t, "<p>Plain <em>lesson</em></p>\n", lessonHTML("Plain *lesson*"))
+}
+
+// TestSaveLessonRendersHTML tests that saved lessons carry HTML rendered from the lesson, not the client's
+func TestSaveLessonRendersHTML(t *testing.T) {
+	o := &Orchestrator{
+		sessions:     map[string]*Session{"s1": {ID: "s1", Topic: "Caching", Metadata: map[string]interface{}{}}},
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"session_id": "s1",
+		"result":     SessionResult{Lesson: "Plain *lesson*", LessonHTML: "<script>alert(1)</script>"},
+	})
+	w := httptest.NewRecorder()
+	o.saveLessonHandler(w, httptest.NewRequest("POST", "/api/save", bytes.NewBuffer(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	require.Len(t, o.savedLessons, 1)
+	for _, saved := range o.savedLessons {
+		assert.Equal(t, "<p>Plain <em>lesson</em></p>\n", saved.Result.LessonHTML)
+	}
+}
+
+// sectionsAgentClient answers each step with an outline or a structured lesson
+type sectionsAgentClient struct{}
+
+// ExecuteTask implements AgentClient
+func (sectionsAgentClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error) {
+	switch req.Step {
+	case "summarizer":
+		return &adk.TaskResponse{Artifacts: map[string]string{"outline": `["What a cache is", "Eviction"]`}}, nil
+	case "explainer":
+		return &adk.TaskResponse{Artifacts: map[string]string{"lesson": `{"big_picture": "Keep hot data close", "core_mechanism": "Look up, then fall back"}`}}, nil
+	}
+	return &adk.TaskResponse{Artifacts: map[string]string{}}, nil
+}
+
+// Health implements AgentClient
+func (sectionsAgentClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// TestStepCompleteEventSections tests that step_complete events carry the anchors of the sections the step produced
+func TestStepCompleteEventSections(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.RetryDelay = time.Millisecond
+	agent := sectionsAgentClient{}
+	p := &Pipeline{
+		config:     config,
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+	session := &Session{ID: "s1", Topic: "Caching", Status: "created", Metadata: map[string]interface{}{}}
+	o := &Orchestrator{
+		sessions: map[string]*Session{"s1": session},
+		logger:   logrus.New(),
+		clients:  make(map[string][]chan SSEEvent),
+	}
+	events := make(chan SSEEvent, 100)
+	o.AddClient("s1", events)
+	require.NoError(t, p.runPipeline(context.Background(), "s1", o))
+
+	sections := make(map[string][]llm.TOCEntry)
+	for len(events) > 0 {
+		event := <-events
+		if event.Type == "step_complete" {
+			entries, _ := event.Data["sections"].([]llm.TOCEntry)
+			sections[event.Data["step"].(string)] = entries
+		}
+	}
+	require.Len(t, sections["summarizer"], 2)
+	assert.Equal(t, "#outline-2-eviction", sections["summarizer"][1].Fragment)
+	require.Len(t, sections["explainer"], 2)
+	assert.Equal(t, "#core-mechanism", sections["explainer"][1].Fragment)
+	assert.Empty(t, sections["visualizer"])
+
+	// The final result links to the same anchors
+	session, _ = o.GetSession("s1")
+	require.NotNil(t, session.Result)
+	assert.Equal(t, append(sections["explainer"], sections["summarizer"]...), session.Result.TOC)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/go-chi/chi/v5"
+)
+
+// StepArtifact describes one output of a pipeline step, such as the summarizer's outline or the critic's patch plan
+type StepArtifact struct {
+	Step        string `json:"step"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"` // Bytes
+	URL         string `json:"url"`  // Where the artifact's content is served
+}
+
+// artifactContentType returns application/json for JSON artifacts and plain text otherwise
+func artifactContentType(content string) string {
+	if json.Valid([]byte(content)) {
+		return "application/json"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// artifactCacheControl returns the Cache-Control header for a session's artifacts.
+// Artifacts of a session still running may be replaced by a retry, so they are not stored.
+func artifactCacheControl(session *Session) string {
+	if session.Status == "completed" {
+		return "private, no-cache"
+	}
+	return "no-store"
+}
+
+// listSessionArtifactsHandler handles GET /api/sessions/{id}/artifacts
+// Artifacts are listed in pipeline order, then by name.
+func (o *Orchestrator) listSessionArtifactsHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+
+	o.mu.RLock()
+	session, exists := o.sessions[sessionID]
+	if !exists {
+		o.mu.RUnlock()
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	artifacts := make([]StepArtifact, 0)
+	for _, step := range session.Steps {
+		outputs := session.partialOutputs[step.Name]
+		names := make([]string, 0, len(outputs))
+		for name := range outputs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			artifacts = append(artifacts, StepArtifact{
+				Step:        step.Name,
+				Name:        name,
+				ContentType: artifactContentType(outputs[name]),
+				Size:        len(outputs[name]),
+				URL:         fmt.Sprintf("/api/sessions/%s/artifacts/%s/%s", sessionID, step.Name, name),
+			})
+		}
+	}
+	status := session.Status
+	cacheControl := artifactCacheControl(session)
+	o.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", cacheControl)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session_id": sessionID,
+		"status":     status,
+		"artifacts":  artifacts,
+	})
+}
+
+// getSessionArtifactHandler handles GET /api/sessions/{id}/artifacts/{step}/{name}
+// It serves one step output as stored, e.g. critic/patch_plan as JSON; spilled outputs redirect to the artifact store.
+func (o *Orchestrator) getSessionArtifactHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	stepName := chi.URLParam(r, "step")
+	name := chi.URLParam(r, "name")
+
+	o.mu.RLock()
+	session, exists := o.sessions[sessionID]
+	if !exists {
+		o.mu.RUnlock()
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	content, found := session.partialOutputs[stepName][name]
+	cacheControl := artifactCacheControl(session)
+	o.mu.RUnlock()
+
+	if !found {
+		http.Error(w, fmt.Sprintf("Artifact %s/%s not found", stepName, name), http.StatusNotFound)
+		return
+	}
+	if object, spilled := adk.ParseArtifactRef(content); spilled {
+		o.redirectToSpilledArtifact(w, r, object)
+		return
+	}
+
+	w.Header().Set("Content-Type", artifactContentType(content))
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Write([]byte(content))
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSessionArtifacts tests listing a session's step outputs and fetching them one at a time
+func TestSessionArtifacts(t *testing.T) {
+	session := &Session{ID: "s1", Topic: "Caching", Status: "running", Metadata: map[string]interface{}{}}
+	o := &Orchestrator{
+		sessions: map[string]*Session{"s1": session},
+		logger:   logrus.New(),
+	}
+	router := chi.NewRouter()
+	router.Get("/api/sessions/{id}/artifacts", o.listSessionArtifactsHandler)
+	router.Get("/api/sessions/{id}/artifacts/{step}/{name}", o.getSessionArtifactHandler)
+
+	o.initSessionSteps(session, pipelineDefinition("Caching"))
+	o.markStepFinished(session, 0, PipelineStepResult{
+		StepName: "summarizer",
+		Status:   "completed",
+		Output:   map[string]string{"summary": "Caching in brief", "outline": `["What a cache is"]`},
+	})
+	o.markStepFinished(session, 3, PipelineStepResult{
+		StepName: "critic",
+		Status:   "completed",
+		Output:   map[string]string{"critique": `[]`, "patch_plan": `[{"section": "metaphor"}]`},
+	})
+
+	w := serve(router, "GET", "/api/sessions/s1/artifacts")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var body struct {
+		Status    string         `json:"status"`
+		Artifacts []StepArtifact `json:"artifacts"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "running", body.Status)
+	require.Len(t, body.Artifacts, 4)
+	assert.Equal(t, StepArtifact{
+		Step:        "summarizer",
+		Name:        "outline",
+		ContentType: "application/json",
+		Size:        len(`["What a cache is"]`),
+		URL:         "/api/sessions/s1/artifacts/summarizer/outline",
+	}, body.Artifacts[0])
+	assert.Equal(t, "summary", body.Artifacts[1].Name)
+	assert.Equal(t, "critic", body.Artifacts[2].Step)
+	assert.Equal(t, "patch_plan", body.Artifacts[3].Name)
+
+	w = serve(router, "GET", "/api/sessions/s1/artifacts/critic/patch_plan")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `[{"section": "metaphor"}]`, w.Body.String())
+
+	session.Status = "completed"
+	w = serve(router, "GET", "/api/sessions/s1/artifacts/summarizer/summary")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "Caching in brief", w.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/sessions/s1/artifacts/explainer/lesson").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/sessions/s2/artifacts").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/sessions/s2/artifacts/critic/critique").Code)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// sessionBundleVersion is the format version of exported session bundles
+	sessionBundleVersion = 1
+	// maxTranscriptEvents caps the events kept per session; older events are dropped first
+	maxTranscriptEvents = 1000
+	// maxBundleBytes bounds the size of an imported bundle
+	maxBundleBytes = 32 << 20
+)
+
+// sessionTranscripts keeps the events broadcast for each session, so a session's run can be
+// reviewed after its clients disconnected
+type sessionTranscripts struct {
+	mu     sync.Mutex
+	events map[string][]SSEEvent
+}
+
+// newSessionTranscripts creates an empty transcript store
+func newSessionTranscripts() *sessionTranscripts {
+	return &sessionTranscripts{events: make(map[string][]SSEEvent)}
+}
+
+// record appends an event to a session's transcript
+func (t *sessionTranscripts) record(sessionID string, event SSEEvent) {
+	if t == nil || sessionID == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := append(t.events[sessionID], event)
+	if len(events) > maxTranscriptEvents {
+		events = append([]SSEEvent(nil), events[len(events)-maxTranscriptEvents:]...)
+	}
+	t.events[sessionID] = events
+}
+
+// get returns a copy of a session's transcript, oldest event first
+func (t *sessionTranscripts) get(sessionID string) []SSEEvent {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]SSEEvent(nil), t.events[sessionID]...)
+}
+
+// set replaces a session's transcript, keeping its latest events
+func (t *sessionTranscripts) set(sessionID string, events []SSEEvent) {
+	if t == nil {
+		return
+	}
+	if len(events) > maxTranscriptEvents {
+		events = events[len(events)-maxTranscriptEvents:]
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events[sessionID] = events
+}
+
+// remove drops a session's transcript
+func (t *sessionTranscripts) remove(sessionID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.events, sessionID)
+}
+
+// SessionBundle is a self-contained copy of a session for moving it between environments,
+// e.g. importing a problematic production session into staging to debug it
+type SessionBundle struct {
+	Version    int                          `json:"version"`
+	ExportedAt time.Time                    `json:"exported_at"`
+	Source     string                       `json:"source,omitempty"`     // Environment the session was exported from (ENVIRONMENT)
+	Session    *Session                     `json:"session"`              // Including its steps, result and revisions
+	Artifacts  map[string]map[string]string `json:"artifacts,omitempty"`  // Step outputs by step and name
+	Transcript []SSEEvent                   `json:"transcript,omitempty"` // Events broadcast for the session, oldest first
+}
+
+// adminTranscriptEvents are the transcript events only admins may export or import: prompt traces
+// and encrypted scratchpads, which hold the prompts and reasoning hidden from learners
+var adminTranscriptEvents = map[string]bool{
+	promptTraceEventType: true,
+	scratchpadEventType:  true,
+}
+
+// publicTranscript returns the events of a transcript that are not admin-only
+func publicTranscript(events []SSEEvent) []SSEEvent {
+	public := make([]SSEEvent, 0, len(events))
+	for _, event := range events {
+		if !adminTranscriptEvents[event.Type] {
+			public = append(public, event)
+		}
+	}
+	return public
+}
+
+// sessionBundle builds the export bundle of a session. Admin-only transcript events are
+// included only when includeAdminEvents is set.
+func (o *Orchestrator) sessionBundle(sessionID string, includeAdminEvents bool) (*SessionBundle, bool) {
+	o.mu.RLock()
+	session, exists := o.sessions[sessionID]
+	if !exists {
+		o.mu.RUnlock()
+		return nil, false
+	}
+	// Round-trip through JSON so the bundle does not share maps with the live session
+	data, err := json.Marshal(session)
+	artifacts := make(map[string]map[string]string, len(session.partialOutputs))
+	for step, outputs := range session.partialOutputs {
+		artifacts[step] = make(map[string]string, len(outputs))
+		for name, content := range outputs {
+			artifacts[step][name] = content
+		}
+	}
+	o.mu.RUnlock()
+
+	var snapshot Session
+	if err != nil || json.Unmarshal(data, &snapshot) != nil {
+		return nil, false
+	}
+	transcript := o.transcripts.get(sessionID)
+	if !includeAdminEvents {
+		transcript = publicTranscript(transcript)
+	}
+	return &SessionBundle{
+		Version:    sessionBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Source:     os.Getenv("ENVIRONMENT"),
+		Session:    &snapshot,
+		Artifacts:  artifacts,
+		Transcript: transcript,
+	}, true
+}
+
+// writeSessionBundle writes a session's bundle as a JSON attachment, with admin-only
+// transcript events for admins only
+func (o *Orchestrator) writeSessionBundle(w http.ResponseWriter, r *http.Request, sessionID string) {
+	bundle, ok := o.sessionBundle(sessionID, callerIsAdmin(r))
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"session-%s.json\"", sessionID))
+	json.NewEncoder(w).Encode(bundle)
+}
+
+// validate checks that a bundle can be imported
+func (b *SessionBundle) validate() error {
+	if b.Version != sessionBundleVersion {
+		return fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+	if b.Session == nil || b.Session.ID == "" {
+		return fmt.Errorf("bundle has no session")
+	}
+	if b.Session.Topic == "" {
+		return fmt.Errorf("bundled session has no topic")
+	}
+	return nil
+}
+
+// importSessionHandler handles POST /api/sessions/import
+// The bundled session is stored under a new ID, tagged with its original ID and environment.
+// Sessions exported mid-run are imported as failed, since their run does not exist here;
+// any imported session can be run again.
+func (o *Orchestrator) importSessionHandler(w http.ResponseWriter, r *http.Request) {
+	var bundle SessionBundle
+	if err := adk.NewJSONDecoder(http.MaxBytesReader(w, r.Body, maxBundleBytes), adk.JSONLimits{MaxDepth: adk.DefaultMaxJSONDepth}).Decode(&bundle); err != nil {
+		http.Error(w, "Invalid session bundle", http.StatusBadRequest)
+		return
+	}
+	if err := bundle.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session := bundle.Session
+	originalID := session.ID
+	session.ID = uuid.New().String()
+	if session.Metadata == nil {
+		session.Metadata = make(map[string]interface{})
+	}
+	session.Metadata["imported_from"] = originalID
+	if bundle.Source != "" {
+		session.Metadata["imported_source"] = bundle.Source
+	}
+	if sessionInProgress(session) {
+		session.Status = "failed"
+	}
+	if session.Steps == nil {
+		session.Steps = make([]SessionStep, 0)
+	}
+	session.partialOutputs = bundle.Artifacts
+	session.UpdatedAt = time.Now()
+
+	// Only admins may bring in prompt traces and scratchpads, which admins would otherwise trust
+	if !callerIsAdmin(r) {
+		bundle.Transcript = publicTranscript(bundle.Transcript)
+	}
+	for i := range bundle.Transcript {
+		bundle.Transcript[i].SessionID = session.ID
+	}
+	o.transcripts.set(session.ID, bundle.Transcript)
+
+	o.mu.Lock()
+	o.sessions[session.ID] = session
+	o.indexSessionLocked(session)
+	o.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id":    session.ID,
+		"imported_from": originalID,
+		"source":        bundle.Source,
+		"status":        session.Status,
+	}).Info("Session imported")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":            session.ID,
+		"imported_from": originalID,
+		"status":        session.Status,
+	})
+}
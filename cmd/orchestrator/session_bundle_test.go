@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBundleTestOrchestrator creates an orchestrator that records transcripts
+func newBundleTestOrchestrator() (*Orchestrator, chi.Router) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+		transcripts:  newSessionTranscripts(),
+	}
+	r := chi.NewRouter()
+	r.Get("/api/sessions/{id}/export", o.exportSessionHandler)
+	r.Post("/api/sessions/import", o.importSessionHandler)
+	return o, r
+}
+
+// TestSessionBundleRoundTrip tests that a session exported mid-run imports with its steps, artifacts and transcript
+func TestSessionBundleRoundTrip(t *testing.T) {
+	source, sourceRouter := newBundleTestOrchestrator()
+	t.Setenv("ENVIRONMENT", "production")
+	session := source.CreateSession("Consensus")
+	session.Status = "running"
+	session.Metadata["user_id"] = "u1"
+	session.Steps = []SessionStep{{ID: "step-1", Name: "summarizer", Status: "completed", Output: "summary"}}
+	session.partialOutputs = map[string]map[string]string{"summarizer": {"summary": "Nodes agree on a value."}}
+	source.BroadcastEvent(session.ID, SSEEvent{Type: "step_start", SessionID: session.ID, Timestamp: time.Now()})
+	source.BroadcastEvent(session.ID, SSEEvent{Type: "step_complete", SessionID: session.ID, Timestamp: time.Now()})
+
+	// Markdown export still requires a completed session
+	assert.Equal(t, http.StatusBadRequest, serve(sourceRouter, "GET", "/api/sessions/"+session.ID+"/export").Code)
+
+	w := serve(sourceRouter, "GET", "/api/sessions/"+session.ID+"/export?format=bundle")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), session.ID)
+	var bundle SessionBundle
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bundle))
+	assert.Equal(t, "production", bundle.Source)
+	assert.Len(t, bundle.Transcript, 2)
+
+	target, targetRouter := newBundleTestOrchestrator()
+	body, _ := json.Marshal(bundle)
+	w = httptest.NewRecorder()
+	targetRouter.ServeHTTP(w, httptest.NewRequest("POST", "/api/sessions/import", bytes.NewReader(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var response struct {
+		ID           string `json:"id"`
+		ImportedFrom string `json:"imported_from"`
+		Status       string `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEqual(t, session.ID, response.ID)
+	assert.Equal(t, session.ID, response.ImportedFrom)
+	assert.Equal(t, "failed", response.Status, "the run that was in progress does not exist here")
+
+	imported, exists := target.GetSession(response.ID)
+	require.True(t, exists)
+	assert.Equal(t, "Consensus", imported.Topic)
+	assert.Equal(t, "u1", imported.Metadata["user_id"])
+	assert.Equal(t, "production", imported.Metadata["imported_source"])
+	assert.Len(t, imported.Steps, 1)
+	assert.Equal(t, "Nodes agree on a value.", imported.partialOutputs["summarizer"]["summary"])
+	transcript := target.transcripts.get(response.ID)
+	require.Len(t, transcript, 2)
+	assert.Equal(t, response.ID, transcript[0].SessionID)
+}
+
+// TestSessionBundleAdminEvents tests that prompt traces and scratchpads are exported and imported by admins only
+func TestSessionBundleAdminEvents(t *testing.T) {
+	o, router := newBundleTestOrchestrator()
+	session := o.CreateSession("Consensus")
+	o.BroadcastEvent(session.ID, SSEEvent{Type: "step_start", SessionID: session.ID, Timestamp: time.Now()})
+	o.transcripts.record(session.ID, SSEEvent{Type: promptTraceEventType, SessionID: session.ID, Timestamp: time.Now()})
+	o.transcripts.record(session.ID, SSEEvent{Type: scratchpadEventType, SessionID: session.ID, Timestamp: time.Now()})
+
+	export := func(principal *auth.Principal) SessionBundle {
+		w := serve(withPrincipal(router, principal), "GET", "/api/sessions/"+session.ID+"/export?format=bundle")
+		require.Equal(t, http.StatusOK, w.Code)
+		var bundle SessionBundle
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bundle))
+		return bundle
+	}
+	admin := &auth.Principal{APIKeyID: "k1", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeAdmin}}
+	member := &auth.Principal{UserID: "u1", Method: auth.MethodJWT}
+	assert.Len(t, export(admin).Transcript, 3)
+	assert.Len(t, export(nil).Transcript, 1)
+	memberBundle := export(member)
+	require.Len(t, memberBundle.Transcript, 1)
+	assert.Equal(t, "step_start", memberBundle.Transcript[0].Type)
+
+	// A non-admin cannot plant admin-only events by importing them
+	body, _ := json.Marshal(export(admin))
+	w := httptest.NewRecorder()
+	withPrincipal(router, member).ServeHTTP(w, httptest.NewRequest("POST", "/api/sessions/import", bytes.NewReader(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var response struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, o.transcripts.get(response.ID), 1)
+}
+
+// TestImportSessionRejectsInvalidBundles tests bundle validation
+func TestImportSessionRejectsInvalidBundles(t *testing.T) {
+	o, router := newBundleTestOrchestrator()
+	for _, body := range []string{
+		`not json`,
+		`{"version":2,"session":{"id":"s1","topic":"Raft"}}`,
+		`{"version":1}`,
+		`{"version":1,"session":{"id":"s1"}}`,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/api/sessions/import", bytes.NewBufferString(body)))
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	assert.Empty(t, o.sessions)
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/sessions/missing/export?format=bundle").Code)
+}
+
+// TestSessionTranscriptCap tests that transcripts keep only the latest events
+func TestSessionTranscriptCap(t *testing.T) {
+	transcripts := newSessionTranscripts()
+	for i := 0; i < maxTranscriptEvents+5; i++ {
+		transcripts.record("s1", SSEEvent{Type: "step-delta", Data: map[string]interface{}{"i": i}})
+	}
+	events := transcripts.get("s1")
+	require.Len(t, events, maxTranscriptEvents)
+	assert.Equal(t, 5, events[0].Data["i"])
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+)
+
+// deploymentVersionFromEnv reads the release sessions are stamped with: DEPLOYMENT_VERSION, else
+// the Cloud Run revision
+func deploymentVersionFromEnv() string {
+	if version := os.Getenv("DEPLOYMENT_VERSION"); version != "" {
+		return version
+	}
+	return os.Getenv("K_REVISION")
+}
+
+// sessionCohort selects the sessions on one side of a comparison, by deployment version or by
+// creation time in [From, To)
+type sessionCohort struct {
+	Version string     `json:"version,omitempty"`
+	From    *time.Time `json:"from,omitempty"`
+	To      *time.Time `json:"to,omitempty"`
+}
+
+// matches reports whether a session belongs to the cohort
+func (c sessionCohort) matches(session *Session) bool {
+	if c.Version != "" {
+		version, _ := session.Metadata["deployment_version"].(string)
+		return version == c.Version
+	}
+	return !session.CreatedAt.Before(*c.From) && session.CreatedAt.Before(*c.To)
+}
+
+// CohortStats aggregates the finished sessions of a cohort
+type CohortStats struct {
+	sessionCohort
+	Sessions            int     `json:"sessions"` // Finished runs; cached results and running sessions are excluded
+	Completed           int     `json:"completed"`
+	Failed              int     `json:"failed"`
+	FailureRate         float64 `json:"failure_rate"`
+	AvgDurationSeconds  float64 `json:"avg_duration_seconds"` // Completed runs only
+	P50DurationSeconds  float64 `json:"p50_duration_seconds"`
+	P95DurationSeconds  float64 `json:"p95_duration_seconds"`
+	Steps               int     `json:"steps"`
+	Retries             int     `json:"retries"`
+	RetryRate           float64 `json:"retry_rate"` // Retries per step
+	TotalCostUSD        float64 `json:"total_cost_usd"`
+	AvgCostUSD          float64 `json:"avg_cost_usd"`
+	AvgTokens           float64 `json:"avg_tokens"`
+	Critiqued           int     `json:"critiqued"` // Runs the critic reviewed
+	AvgCriticIssues     float64 `json:"avg_critic_issues"`
+	AvgSevereIssues     float64 `json:"avg_severe_issues"` // High and critical issues per critiqued run
+	AvgReadabilityGrade float64 `json:"avg_readability_grade"`
+	Rated               int     `json:"rated"`
+	AvgRating           float64 `json:"avg_rating"` // Learner ratings, 1-5
+}
+
+// SessionComparisonReport compares a baseline cohort with a candidate one, e.g. before and after
+// a model or prompt upgrade. Delta holds candidate minus baseline for each average and rate that
+// both cohorts have data for.
+type SessionComparisonReport struct {
+	Baseline    CohortStats        `json:"baseline"`
+	Candidate   CohortStats        `json:"candidate"`
+	Delta       map[string]float64 `json:"delta"`
+	GeneratedAt time.Time          `json:"generated_at"`
+}
+
+// cohortFromQuery reads the version or time range of one side of a comparison from the
+// <prefix>_version, <prefix>_from and <prefix>_to query parameters
+func cohortFromQuery(r *http.Request, prefix string) (sessionCohort, error) {
+	query := r.URL.Query()
+	version := query.Get(prefix + "_version")
+	from, to := query.Get(prefix+"_from"), query.Get(prefix+"_to")
+	if version != "" {
+		if from != "" || to != "" {
+			return sessionCohort{}, fmt.Errorf("%s: give either a version or a time range, not both", prefix)
+		}
+		return sessionCohort{Version: version}, nil
+	}
+	if from == "" || to == "" {
+		return sessionCohort{}, fmt.Errorf("%s: %s_version or both %s_from and %s_to are required", prefix, prefix, prefix, prefix)
+	}
+	fromTime, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return sessionCohort{}, fmt.Errorf("%s_from must be an RFC 3339 time", prefix)
+	}
+	toTime, err := time.Parse(time.RFC3339, to)
+	if err != nil {
+		return sessionCohort{}, fmt.Errorf("%s_to must be an RFC 3339 time", prefix)
+	}
+	if !fromTime.Before(toTime) {
+		return sessionCohort{}, fmt.Errorf("%s_from must be before %s_to", prefix, prefix)
+	}
+	return sessionCohort{From: &fromTime, To: &toTime}, nil
+}
+
+// cohortStats aggregates the finished, uncached sessions matching a cohort
+func (o *Orchestrator) cohortStats(cohort sessionCohort) CohortStats {
+	stats := CohortStats{sessionCohort: cohort}
+	var durations []float64
+	var tokens, ratingSum, gradeSum float64
+	graded := 0
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	for _, session := range o.sessions {
+		if session.Status != "completed" && session.Status != "failed" {
+			continue
+		}
+		if session.Result != nil && session.Result.Cached {
+			continue
+		}
+		if !cohort.matches(session) {
+			continue
+		}
+
+		stats.Sessions++
+		if session.Status == "failed" {
+			stats.Failed++
+		} else {
+			stats.Completed++
+			if session.Result != nil && session.Result.Duration > 0 {
+				durations = append(durations, session.Result.Duration.Seconds())
+			}
+			if session.Result != nil && session.Result.Readability != nil {
+				gradeSum += session.Result.Readability.FleschKincaidGrade
+				graded++
+			}
+		}
+
+		for _, step := range session.Steps {
+			if step.Status == "pending" || step.Status == "skipped" {
+				continue
+			}
+			stats.Steps++
+			stats.Retries += metricInt(step.Metadata, "retry_count")
+		}
+
+		usage := sessionUsage(session)
+		stats.TotalCostUSD += usage.CostUSD
+		tokens += float64(usage.InputTokens + usage.OutputTokens)
+
+		if critique := session.partialOutputs["critic"]["critique"]; critique != "" {
+			var issues []llm.CritiqueIssue
+			if err := json.Unmarshal([]byte(critique), &issues); err == nil {
+				stats.Critiqued++
+				stats.AvgCriticIssues += float64(len(issues))
+				for _, issue := range issues {
+					if issue.Severity == "high" || issue.Severity == "critical" {
+						stats.AvgSevereIssues++
+					}
+				}
+			}
+		}
+
+		if rating, ok := session.Metadata["rating"].(float64); ok {
+			stats.Rated++
+			ratingSum += rating
+		}
+	}
+
+	if stats.Sessions > 0 {
+		stats.FailureRate = roundHundredths(float64(stats.Failed) / float64(stats.Sessions))
+		stats.AvgCostUSD = roundCost(stats.TotalCostUSD / float64(stats.Sessions))
+		stats.AvgTokens = roundHundredths(tokens / float64(stats.Sessions))
+	}
+	stats.TotalCostUSD = roundCost(stats.TotalCostUSD)
+	if len(durations) > 0 {
+		sort.Float64s(durations)
+		sum := 0.0
+		for _, d := range durations {
+			sum += d
+		}
+		stats.AvgDurationSeconds = roundHundredths(sum / float64(len(durations)))
+		stats.P50DurationSeconds = roundHundredths(percentile(durations, 50))
+		stats.P95DurationSeconds = roundHundredths(percentile(durations, 95))
+	}
+	if stats.Steps > 0 {
+		stats.RetryRate = roundHundredths(float64(stats.Retries) / float64(stats.Steps))
+	}
+	if stats.Critiqued > 0 {
+		stats.AvgCriticIssues = roundHundredths(stats.AvgCriticIssues / float64(stats.Critiqued))
+		stats.AvgSevereIssues = roundHundredths(stats.AvgSevereIssues / float64(stats.Critiqued))
+	}
+	if graded > 0 {
+		stats.AvgReadabilityGrade = roundHundredths(gradeSum / float64(graded))
+	}
+	if stats.Rated > 0 {
+		stats.AvgRating = roundHundredths(ratingSum / float64(stats.Rated))
+	}
+	return stats
+}
+
+// roundCost rounds a dollar amount to a millionth, the precision model prices are quoted in
+func roundCost(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+// compareCohorts builds the before/after report of two cohorts
+func compareCohorts(baseline, candidate CohortStats) *SessionComparisonReport {
+	delta := make(map[string]float64)
+	diff := func(name string, before, after float64, ok bool) {
+		if ok {
+			delta[name] = roundHundredths(after - before)
+		}
+	}
+	bothRan := baseline.Sessions > 0 && candidate.Sessions > 0
+	diff("failure_rate", baseline.FailureRate, candidate.FailureRate, bothRan)
+	diff("retry_rate", baseline.RetryRate, candidate.RetryRate, baseline.Steps > 0 && candidate.Steps > 0)
+	diff("avg_tokens", baseline.AvgTokens, candidate.AvgTokens, bothRan)
+	if bothRan {
+		delta["avg_cost_usd"] = roundCost(candidate.AvgCostUSD - baseline.AvgCostUSD)
+	}
+	timed := baseline.AvgDurationSeconds > 0 && candidate.AvgDurationSeconds > 0
+	diff("avg_duration_seconds", baseline.AvgDurationSeconds, candidate.AvgDurationSeconds, timed)
+	diff("p95_duration_seconds", baseline.P95DurationSeconds, candidate.P95DurationSeconds, timed)
+	critiqued := baseline.Critiqued > 0 && candidate.Critiqued > 0
+	diff("avg_critic_issues", baseline.AvgCriticIssues, candidate.AvgCriticIssues, critiqued)
+	diff("avg_severe_issues", baseline.AvgSevereIssues, candidate.AvgSevereIssues, critiqued)
+	diff("avg_readability_grade", baseline.AvgReadabilityGrade, candidate.AvgReadabilityGrade, baseline.AvgReadabilityGrade > 0 && candidate.AvgReadabilityGrade > 0)
+	diff("avg_rating", baseline.AvgRating, candidate.AvgRating, baseline.Rated > 0 && candidate.Rated > 0)
+
+	return &SessionComparisonReport{Baseline: baseline, Candidate: candidate, Delta: delta, GeneratedAt: time.Now()}
+}
+
+// recordSessionRating keeps a learner's rating on the session for comparison reports
+func (o *Orchestrator) recordSessionRating(sessionID string, rating float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if session, exists := o.sessions[sessionID]; exists {
+		if session.Metadata == nil {
+			session.Metadata = make(map[string]interface{})
+		}
+		session.Metadata["rating"] = rating
+	}
+}
+
+// sessionComparisonHandler handles GET /api/admin/sessions/compare
+// The baseline and candidate cohorts are each selected by deployment version
+// (baseline_version, candidate_version) or creation time (baseline_from/baseline_to, candidate_from/candidate_to).
+func (o *Orchestrator) sessionComparisonHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	baseline, err := cohortFromQuery(r, "baseline")
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid baseline", err.Error())
+		return
+	}
+	candidate, err := cohortFromQuery(r, "candidate")
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid candidate", err.Error())
+		return
+	}
+	json.NewEncoder(w).Encode(compareCohorts(o.cohortStats(baseline), o.cohortStats(candidate)))
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// comparisonSession creates a finished session of a deployment version with one explainer step
+func comparisonSession(version, status string, createdAt time.Time, duration time.Duration, retries, tokens int, critique string) *Session {
+	session := &Session{
+		Status:    status,
+		CreatedAt: createdAt,
+		Metadata:  map[string]interface{}{"deployment_version": version},
+		Steps: []SessionStep{{
+			Name:   "explainer",
+			Status: status,
+			Metadata: map[string]interface{}{
+				"retry_count": retries,
+				"metrics":     map[string]interface{}{"model": "gemini-2.5-flash", "input_tokens": tokens, "output_tokens": tokens},
+			},
+		}},
+	}
+	if status == "completed" {
+		session.Result = &SessionResult{Duration: duration}
+	}
+	if critique != "" {
+		session.partialOutputs = map[string]map[string]string{"critic": {"critique": critique}}
+	}
+	return session
+}
+
+// TestSessionComparison tests the before/after report of two deployment versions
+func TestSessionComparison(t *testing.T) {
+	now := time.Now()
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"a1": comparisonSession("v1", "completed", now, 10*time.Second, 1, 1000, `[{"severity":"high"},{"severity":"low"}]`),
+			"a2": comparisonSession("v1", "failed", now, 0, 2, 500, ""),
+			"b1": comparisonSession("v2", "completed", now, 6*time.Second, 0, 400, `[{"severity":"low"}]`),
+			"b2": comparisonSession("v2", "completed", now, 8*time.Second, 0, 400, `[]`),
+			"b3": comparisonSession("v2", "running", now, 0, 0, 0, ""),
+		},
+		logger: logrus.New(),
+	}
+	cached := comparisonSession("v2", "completed", now, time.Second, 0, 0, "")
+	cached.Result.Cached = true
+	o.sessions["b4"] = cached
+	o.recordSessionRating("a1", 3)
+	o.recordSessionRating("b1", 5)
+
+	r := chi.NewRouter()
+	r.Get("/api/admin/sessions/compare", o.sessionComparisonHandler)
+	w := serve(r, "GET", "/api/admin/sessions/compare?baseline_version=v1&candidate_version=v2")
+	require.Equal(t, http.StatusOK, w.Code)
+	var report SessionComparisonReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+
+	assert.Equal(t, 2, report.Baseline.Sessions)
+	assert.Equal(t, 0.5, report.Baseline.FailureRate)
+	assert.Equal(t, 1.5, report.Baseline.RetryRate)
+	assert.Equal(t, 1.0, report.Baseline.AvgSevereIssues)
+	assert.Equal(t, 2, report.Candidate.Sessions, "running and cached sessions are excluded")
+	assert.Equal(t, 7.0, report.Candidate.AvgDurationSeconds)
+	assert.Equal(t, 8.0, report.Candidate.P95DurationSeconds)
+	assert.Less(t, report.Candidate.AvgCostUSD, report.Baseline.AvgCostUSD)
+
+	assert.Equal(t, -0.5, report.Delta["failure_rate"])
+	assert.Equal(t, -1.5, report.Delta["retry_rate"])
+	assert.Equal(t, -3.0, report.Delta["avg_duration_seconds"])
+	assert.Equal(t, -1.5, report.Delta["avg_critic_issues"])
+	assert.Equal(t, 2.0, report.Delta["avg_rating"])
+	assert.NotContains(t, report.Delta, "avg_readability_grade", "neither cohort was scored")
+}
+
+// TestSessionComparisonTimeRanges tests selecting cohorts by creation time and rejecting bad ranges
+func TestSessionComparisonTimeRanges(t *testing.T) {
+	before := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	after := before.AddDate(0, 0, 7)
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"a1": comparisonSession("", "completed", before, time.Second, 0, 10, ""),
+			"b1": comparisonSession("", "completed", after, time.Second, 0, 10, ""),
+			"b2": comparisonSession("", "failed", after, 0, 0, 10, ""),
+		},
+		logger: logrus.New(),
+	}
+	r := chi.NewRouter()
+	r.Get("/api/admin/sessions/compare", o.sessionComparisonHandler)
+
+	w := serve(r, "GET", "/api/admin/sessions/compare?baseline_from=2026-03-01T00:00:00Z&baseline_to=2026-03-02T00:00:00Z&candidate_from=2026-03-08T00:00:00Z&candidate_to=2026-03-09T00:00:00Z")
+	require.Equal(t, http.StatusOK, w.Code)
+	var report SessionComparisonReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 1, report.Baseline.Sessions)
+	assert.Equal(t, 2, report.Candidate.Sessions)
+	assert.Equal(t, 0.5, report.Delta["failure_rate"])
+
+	for _, query := range []string{
+		"candidate_version=v2",
+		"baseline_version=v1&baseline_from=2026-03-01T00:00:00Z&candidate_version=v2",
+		"baseline_from=2026-03-02T00:00:00Z&baseline_to=2026-03-01T00:00:00Z&candidate_version=v2",
+		"baseline_from=yesterday&baseline_to=today&candidate_version=v2",
+	} {
+		assert.Equal(t, http.StatusBadRequest, serve(r, "GET", "/api/admin/sessions/compare?"+query).Code, query)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultSessionTimeout bounds a whole pipeline run, across all steps and retries
+const defaultSessionTimeout = 20 * time.Minute
+
+var (
+	// errSessionCancelled is the cause of a run stopped through the cancel endpoint
+	errSessionCancelled = errors.New("session cancelled")
+	// errSessionTimeout is the cause of a run stopped by the pipeline's session timeout
+	errSessionTimeout = errors.New("session deadline exceeded")
+)
+
+// sessionTimeoutFromEnv reads SESSION_TIMEOUT (e.g. "15m"), the longest a pipeline run may
+// take; "0" disables the deadline
+func sessionTimeoutFromEnv() time.Duration {
+	v := os.Getenv("SESSION_TIMEOUT")
+	if v == "" {
+		return defaultSessionTimeout
+	}
+	timeout, err := time.ParseDuration(v)
+	if err != nil || timeout < 0 {
+		logrus.WithField("value", v).Warn("Invalid SESSION_TIMEOUT, using default")
+		return defaultSessionTimeout
+	}
+	return timeout
+}
+
+// sessionRunContext returns the context a session run executes under. It is derived from the
+// pipeline's session timeout rather than the HTTP request, so runs outlive the request that
+// started them, and it is cancelled by the cancel endpoint. done must be called when the run ends.
+// ok is false if the session was cancelled before its run started.
+func (o *Orchestrator) sessionRunContext(sessionID string) (ctx context.Context, done func(), ok bool) {
+	o.mu.Lock()
+	session, exists := o.sessions[sessionID]
+	if exists && session.Status == "cancelled" {
+		o.mu.Unlock()
+		return nil, nil, false
+	}
+	// Supervised runs wait on reviewers, so each approval is bounded instead of the whole run
+	supervised := exists && sessionSupervised(session)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	if o.runCancels == nil {
+		o.runCancels = make(map[string]context.CancelCauseFunc)
+	}
+	o.runCancels[sessionID] = cancel
+	o.mu.Unlock()
+
+	stop := func() {}
+	if timeout := o.pipeline.config.SessionTimeout; timeout > 0 && !supervised {
+		ctx, stop = context.WithTimeoutCause(ctx, timeout, errSessionTimeout)
+	}
+	return ctx, func() {
+		o.mu.Lock()
+		delete(o.runCancels, sessionID)
+		o.mu.Unlock()
+		stop()
+		cancel(nil)
+	}, true
+}
+
+// stopRun ends a run whose context was cancelled or timed out between steps
+func (p *Pipeline) stopRun(ctx context.Context, session *Session, result *PipelineResult, orchestrator *Orchestrator) error {
+	cause := context.Cause(ctx)
+	status := "failed"
+	eventType := "session_error"
+	if errors.Is(cause, errSessionCancelled) {
+		status = "cancelled"
+		eventType = "session_cancelled"
+	}
+
+	result.Status = status
+	result.Error = cause.Error()
+	data := map[string]interface{}{
+		"session_id": session.ID,
+		"error":      result.Error,
+		"timestamp":  time.Now().Format(time.RFC3339),
+	}
+	if status == "failed" {
+		data = failureEventData(session.ID, orchestrator.recordFailure(session, "", result.Error))
+	}
+	session.Status = status
+	orchestrator.UpdateSession(session)
+
+	p.logger.WithFields(logrus.Fields{
+		"session_id": session.ID,
+		"status":     status,
+		"cause":      cause,
+	}).Warn("Pipeline run stopped")
+
+	orchestrator.BroadcastEvent(session.ID, SSEEvent{
+		Type:      eventType,
+		SessionID: session.ID,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+	return fmt.Errorf("pipeline stopped: %w", cause)
+}
+
+// cancelSessionHandler handles POST /api/sessions/{id}/cancel
+// A running session stops after its current agent call is abandoned; a queued one never starts.
+func (o *Orchestrator) cancelSessionHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	w.Header().Set("Content-Type", "application/json")
+
+	o.mu.Lock()
+	session, exists := o.sessions[sessionID]
+	if !exists {
+		o.mu.Unlock()
+		writeJSONError(w, http.StatusNotFound, "Session not found", "Session not found")
+		return
+	}
+
+	status := http.StatusAccepted
+	if cancel, running := o.runCancels[sessionID]; running {
+		cancel(errSessionCancelled)
+	} else if session.Status == "queued" || session.Status == "pending" {
+		session.Status = "cancelled"
+		session.UpdatedAt = time.Now()
+		status = http.StatusOK
+	} else {
+		current := session.Status
+		o.mu.Unlock()
+		writeJSONError(w, http.StatusConflict, "Session not running", "Only queued or running sessions can be cancelled; the session is "+current)
+		return
+	}
+	current := session.Status
+	o.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"status":     current,
+	}).Info("Session cancellation requested")
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session_id": sessionID,
+		"status":     current,
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingAgentClient blocks every task until its context ends
+type blockingAgentClient struct {
+	calls atomic.Int32
+}
+
+// ExecuteTask implements AgentClient
+func (c *blockingAgentClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error) {
+	c.calls.Add(1)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// Health implements AgentClient
+func (c *blockingAgentClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// newDeadlineTestOrchestrator creates an orchestrator whose agents all block
+func newDeadlineTestOrchestrator(timeout time.Duration) (*Orchestrator, *blockingAgentClient) {
+	agent := &blockingAgentClient{}
+	config := DefaultPipelineConfig()
+	config.RetryDelay = time.Millisecond
+	config.SessionTimeout = timeout
+	o := &Orchestrator{
+		sessions: make(map[string]*Session),
+		logger:   logrus.New(),
+		clients:  make(map[string][]chan SSEEvent),
+		pipeline: &Pipeline{
+			config:     config,
+			logger:     logrus.New(),
+			adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+		},
+	}
+	return o, agent
+}
+
+// newCancelTestRouter serves the cancel endpoint
+func newCancelTestRouter(o *Orchestrator) http.Handler {
+	r := chi.NewRouter()
+	r.Post("/api/sessions/{id}/cancel", o.cancelSessionHandler)
+	return r
+}
+
+// TestCancelRunningSession tests that cancelling a running session stops its agent call without retrying
+func TestCancelRunningSession(t *testing.T) {
+	o, agent := newDeadlineTestOrchestrator(0)
+	session := o.CreateSession("Caching")
+
+	finished := make(chan struct{})
+	go func() {
+		o.RunSession(session.ID)
+		close(finished)
+	}()
+	require.Eventually(t, func() bool { return agent.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	router := newCancelTestRouter(o)
+	assert.Equal(t, http.StatusAccepted, serve(router, "POST", "/api/sessions/"+session.ID+"/cancel").Code)
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("run did not stop after cancellation")
+	}
+
+	session, _ = o.GetSession(session.ID)
+	assert.Equal(t, "cancelled", session.Status)
+	assert.Equal(t, int32(1), agent.calls.Load())
+	assert.Empty(t, o.runCancels)
+	assert.Equal(t, http.StatusConflict, serve(router, "POST", "/api/sessions/"+session.ID+"/cancel").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "POST", "/api/sessions/missing/cancel").Code)
+}
+
+// TestSessionTimeout tests that a run past the session deadline fails instead of waiting on its agents
+func TestSessionTimeout(t *testing.T) {
+	o, agent := newDeadlineTestOrchestrator(20 * time.Millisecond)
+	session := o.CreateSession("Caching")
+
+	o.RunSession(session.ID)
+
+	session, _ = o.GetSession(session.ID)
+	assert.Equal(t, "failed", session.Status)
+	assert.Equal(t, int32(1), agent.calls.Load())
+	assert.Equal(t, errSessionTimeout.Error(), session.Steps[0].Error)
+}
+
+// TestCancelQueuedSession tests that a session cancelled while queued never runs
+func TestCancelQueuedSession(t *testing.T) {
+	o, agent := newDeadlineTestOrchestrator(0)
+	session := o.CreateSession("Caching")
+	session.Status = "queued"
+
+	w := serve(newCancelTestRouter(o), "POST", "/api/sessions/"+session.ID+"/cancel")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"cancelled"`)
+
+	o.RunSession(session.ID)
+	assert.Equal(t, "cancelled", session.Status)
+	assert.Zero(t, agent.calls.Load())
+}
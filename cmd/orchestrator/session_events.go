@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// snapshotEvent returns the event describing a session's current status and steps
+func snapshotEvent(status SessionStatusResponse) SSEEvent {
+	return SSEEvent{
+		Type:      "session_snapshot",
+		SessionID: status.SessionID,
+		Data: map[string]interface{}{
+			"session_id":     status.SessionID,
+			"status":         status.Status,
+			"steps":          status.Steps,
+			"queue_position": status.QueuePosition,
+			"timestamp":      time.Now().Format(time.RFC3339),
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// finishedSessionEvent returns the final event for a completed or failed session,
+// or nil if the session is still in progress
+func (o *Orchestrator) finishedSessionEvent(sessionID string, status SessionStatusResponse) *SSEEvent {
+	switch status.Status {
+	case "completed":
+		session, exists := o.GetSession(sessionID)
+		if !exists || session.Result == nil {
+			return nil
+		}
+
+		o.mu.RLock()
+		artifacts := resultArtifacts(session.Result)
+		o.mu.RUnlock()
+
+		return &SSEEvent{
+			Type:      "session_complete",
+			SessionID: sessionID,
+			Data: map[string]interface{}{
+				"session_id": sessionID,
+				"status":     "completed",
+				"artifacts":  artifacts,
+				"timestamp":  time.Now().Format(time.RFC3339),
+			},
+			Timestamp: time.Now(),
+		}
+	case "failed":
+		if status.Failure != nil {
+			return &SSEEvent{
+				Type:      "session_error",
+				SessionID: sessionID,
+				Data:      failureEventData(sessionID, status.Failure),
+				Timestamp: time.Now(),
+			}
+		}
+		message := "Session failed"
+		for _, step := range status.Steps {
+			if step.Status == "failed" && step.Error != "" {
+				message = fmt.Sprintf("step %s failed: %s", step.Name, step.Error)
+				break
+			}
+		}
+		return &SSEEvent{
+			Type:      "session_error",
+			SessionID: sessionID,
+			Data: map[string]interface{}{
+				"session_id": sessionID,
+				"error":      message,
+				"timestamp":  time.Now().Format(time.RFC3339),
+			},
+			Timestamp: time.Now(),
+		}
+	}
+	return nil
+}
+
+// resultArtifacts converts a stored result into the artifacts sent with session_complete
+func resultArtifacts(result *SessionResult) map[string]interface{} {
+	artifacts := make(map[string]interface{})
+
+	var lesson map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Lesson), &lesson); err == nil {
+		artifacts["lesson"] = lesson
+	}
+
+	urls := make([]string, 0, len(result.Images))
+	for url := range result.Images {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	images := make([]map[string]string, 0, len(urls))
+	captions := make([]string, 0, len(urls))
+	for _, url := range urls {
+		caption := result.Images[url]
+		images = append(images, map[string]string{"url": url, "caption": caption, "alt_text": caption})
+		if caption != "" {
+			captions = append(captions, caption)
+		}
+	}
+	artifacts["images"] = images
+	artifacts["captions"] = captions
+	if result.Metadata != nil {
+		artifacts["metadata"] = result.Metadata
+	}
+	if result.Localization != nil {
+		artifacts["localization"] = localizationArtifact(result.Localization)
+	}
+	return artifacts
+}
+
+// writeSSEEvent writes one event to an SSE stream
+func writeSSEEvent(w http.ResponseWriter, flusher http.Flusher, event SSEEvent) {
+	data, _ := json.Marshal(event)
+	fmt.Fprintf(w, "data: %s\n\n", string(data))
+	flusher.Flush()
+}
+
+// sessionEventsHandler handles GET /api/sessions/{id}/events
+// It streams a session's events without starting a run, beginning with a snapshot of its
+// steps so viewers joining mid-run catch up. Finished sessions get their final event at once.
+func (o *Orchestrator) sessionEventsHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	if _, exists := o.GetSession(sessionID); !exists {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe before taking the snapshot so no event falls between them
+	client := make(chan SSEEvent, 10)
+	o.AddSessionClient(sessionID, runUser(r), client)
+	defer o.RemoveClient(sessionID, client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	status, _ := o.sessionStatus(sessionID)
+	writeSSEEvent(w, flusher, snapshotEvent(status))
+	if final := o.finishedSessionEvent(sessionID, status); final != nil {
+		writeSSEEvent(w, flusher, *final)
+		return
+	}
+
+	for {
+		select {
+		case event, ok := <-client:
+			if !ok {
+				// Evicted for falling behind or for a newer stream; end with a terminal event
+				writeSSEEvent(w, flusher, o.closedClientEvent(sessionID, client))
+				return
+			}
+			writeSSEEvent(w, flusher, event)
+
+			if event.Type == "final" || event.Type == "session_complete" || event.Type == "session_error" {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEventsTestOrchestrator creates an orchestrator with a running and a completed session
+func newEventsTestOrchestrator() (*Orchestrator, chi.Router) {
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"running": {
+				ID:     "running",
+				Status: "running",
+				Steps:  []SessionStep{{ID: "step-1", Name: "summarizer", Status: "completed"}, {ID: "step-2", Name: "explainer", Status: "running"}},
+			},
+			"done": {
+				ID:     "done",
+				Status: "completed",
+				Result: &SessionResult{
+					Lesson: `{"big_picture":"Consensus"}`,
+					Images: map[string]string{"https://img/1.png": "Leader election"},
+				},
+			},
+		},
+		logger:      logrus.New(),
+		clients:     make(map[string][]chan SSEEvent),
+		clientStats: make(map[chan SSEEvent]*sseClientStats),
+	}
+
+	r := chi.NewRouter()
+	r.Get("/api/sessions/{id}/events", o.sessionEventsHandler)
+	return o, r
+}
+
+// readSSEEvents decodes the data lines of an SSE body
+func readSSEEvents(t *testing.T, body string) []SSEEvent {
+	var events []SSEEvent
+	for _, line := range strings.Split(body, "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var event SSEEvent
+			require.NoError(t, json.Unmarshal([]byte(data), &event))
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// TestSessionEventsFinishedSession tests that a completed session replays its result and ends the stream
+func TestSessionEventsFinishedSession(t *testing.T) {
+	_, r := newEventsTestOrchestrator()
+
+	w := serve(r, http.MethodGet, "/api/sessions/done/events")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	events := readSSEEvents(t, w.Body.String())
+	require.Len(t, events, 2)
+	assert.Equal(t, "session_snapshot", events[0].Type)
+	assert.Equal(t, "session_complete", events[1].Type)
+
+	artifacts := events[1].Data["artifacts"].(map[string]interface{})
+	assert.Equal(t, "Consensus", artifacts["lesson"].(map[string]interface{})["big_picture"])
+	assert.Equal(t, []interface{}{"Leader election"}, artifacts["captions"])
+}
+
+// TestSessionEventsLiveSession tests that viewers of a running session get a snapshot and then live events
+func TestSessionEventsLiveSession(t *testing.T) {
+	o, r := newEventsTestOrchestrator()
+
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/sessions/running/events", nil))
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		o.clientsMu.RLock()
+		defer o.clientsMu.RUnlock()
+		return len(o.clients["running"]) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	o.BroadcastEvent("running", SSEEvent{Type: "step_complete", SessionID: "running", Data: map[string]interface{}{"step": "explainer"}})
+	o.BroadcastEvent("running", SSEEvent{Type: "session_complete", SessionID: "running"})
+	<-done
+
+	events := readSSEEvents(t, w.Body.String())
+	require.Len(t, events, 3)
+	assert.Equal(t, "session_snapshot", events[0].Type)
+	assert.Equal(t, "running", events[0].Data["status"])
+	assert.Len(t, events[0].Data["steps"], 2)
+	assert.Equal(t, "step_complete", events[1].Type)
+	assert.Equal(t, "session_complete", events[2].Type)
+	assert.Empty(t, o.clients["running"], "the viewer unsubscribes when the stream ends")
+}
+
+// TestSessionEventsNotFound tests that unknown sessions are rejected
+func TestSessionEventsNotFound(t *testing.T) {
+	_, r := newEventsTestOrchestrator()
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodGet, "/api/sessions/missing/events").Code)
+}
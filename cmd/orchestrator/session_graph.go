@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GraphNode is a pipeline step in a session's status graph
+type GraphNode struct {
+	ID          string     `json:"id"` // Step name
+	StepID      string     `json:"step_id,omitempty"`
+	Agent       string     `json:"agent"`
+	Status      string     `json:"status"` // pending, running, completed, skipped, failed or cancelled
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DurationMs  *int64     `json:"duration_ms,omitempty"`
+	Retries     int        `json:"retries"`
+	Model       string     `json:"model,omitempty"` // Model that served the step, when reported
+	Error       string     `json:"error,omitempty"`
+}
+
+// GraphEdge is a data dependency between two pipeline steps
+type GraphEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Status string `json:"status"` // pending until From completes, then satisfied
+}
+
+// PipelineGraph is the node/edge view of a session's pipeline for rendering a DAG
+type PipelineGraph struct {
+	SessionID   string      `json:"session_id"`
+	Status      string      `json:"status"`
+	CurrentStep string      `json:"current_step,omitempty"`
+	Nodes       []GraphNode `json:"nodes"`
+	Edges       []GraphEdge `json:"edges"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// buildPipelineGraph lays the session's live step results over the pipeline definition.
+// Steps that have not started yet are pending.
+func buildPipelineGraph(sessionID, status, topic string, steps []SessionStep, updatedAt time.Time) PipelineGraph {
+	results := make(map[string]SessionStep, len(steps))
+	for _, step := range steps {
+		results[step.Name] = step
+	}
+
+	graph := PipelineGraph{
+		SessionID: sessionID,
+		Status:    status,
+		Nodes:     []GraphNode{},
+		Edges:     []GraphEdge{},
+		UpdatedAt: updatedAt,
+	}
+	nodeStatus := make(map[string]string)
+	for _, step := range pipelineDefinition(topic) {
+		node := GraphNode{ID: step.Name, Agent: step.Agent, Status: "pending"}
+		if result, ok := results[step.Name]; ok {
+			node.StepID = result.ID
+			node.Status = result.Status
+			node.StartedAt = result.StartedAt
+			node.CompletedAt = result.CompletedAt
+			node.Error = result.Error
+			if result.Duration != nil {
+				ms := result.Duration.Milliseconds()
+				node.DurationMs = &ms
+			}
+			node.Retries = metricInt(result.Metadata, "retry_count")
+			node.Model, _ = result.Metadata["model"].(string)
+		}
+		if node.Status == "running" {
+			graph.CurrentStep = node.ID
+		}
+		nodeStatus[node.ID] = node.Status
+		graph.Nodes = append(graph.Nodes, node)
+
+		for _, dependency := range step.DependsOn {
+			edge := GraphEdge{From: dependency, To: step.Name, Status: "pending"}
+			if nodeStatus[dependency] == "completed" {
+				edge.Status = "satisfied"
+			}
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+	return graph
+}
+
+// getSessionGraphHandler handles GET /api/sessions/{id}/graph
+func (o *Orchestrator) getSessionGraphHandler(w http.ResponseWriter, r *http.Request) {
+	o.mu.RLock()
+	session, exists := o.sessions[chi.URLParam(r, "id")]
+	if !exists {
+		o.mu.RUnlock()
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	graph := buildPipelineGraph(session.ID, session.Status, session.Topic, session.Steps, session.UpdatedAt)
+	o.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(graph)
+}
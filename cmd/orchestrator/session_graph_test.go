@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSessionGraph tests the graph of a session part way through its pipeline
+func TestSessionGraph(t *testing.T) {
+	session := &Session{ID: "s1", Topic: "Caching", Status: "running"}
+	o := &Orchestrator{
+		sessions: map[string]*Session{"s1": session},
+		logger:   logrus.New(),
+	}
+	o.initSessionSteps(session, pipelineDefinition(session.Topic))
+	o.markStepRunning(session, 0)
+	o.markStepFinished(session, 0, PipelineStepResult{
+		Status:     "completed",
+		Duration:   1500 * time.Millisecond,
+		RetryCount: 2,
+		Metadata:   map[string]interface{}{"model": "gemini-1.5-flash"},
+	})
+	o.markStepRunning(session, 1)
+
+	r := chi.NewRouter()
+	r.Get("/api/sessions/{id}/graph", o.getSessionGraphHandler)
+
+	w := serve(r, "GET", "/api/sessions/s1/graph")
+	require.Equal(t, http.StatusOK, w.Code)
+	var graph PipelineGraph
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &graph))
+
+	assert.Equal(t, "explainer", graph.CurrentStep)
+	require.Len(t, graph.Nodes, 4)
+	summarizer := graph.Nodes[0]
+	assert.Equal(t, "summarizer", summarizer.ID)
+	assert.Equal(t, "completed", summarizer.Status)
+	assert.Equal(t, 2, summarizer.Retries)
+	assert.Equal(t, "gemini-1.5-flash", summarizer.Model)
+	require.NotNil(t, summarizer.DurationMs)
+	assert.Equal(t, int64(1500), *summarizer.DurationMs)
+	assert.Equal(t, "running", graph.Nodes[1].Status)
+	assert.Equal(t, "pending", graph.Nodes[3].Status)
+
+	assert.Equal(t, []GraphEdge{
+		{From: "summarizer", To: "explainer", Status: "satisfied"},
+		{From: "explainer", To: "visualizer", Status: "pending"},
+		{From: "explainer", To: "critic", Status: "pending"},
+	}, graph.Edges)
+
+	assert.Equal(t, http.StatusNotFound, serve(r, "GET", "/api/sessions/missing/graph").Code)
+}
+
+// TestSessionGraphStoredRetries tests that retry counts decoded from storage as JSON numbers are kept
+func TestSessionGraphStoredRetries(t *testing.T) {
+	steps := []SessionStep{{Name: "summarizer", Status: "completed", Metadata: map[string]interface{}{"retry_count": float64(3)}}}
+	graph := buildPipelineGraph("s1", "running", "Caching", steps, time.Now())
+	require.NotEmpty(t, graph.Nodes)
+	assert.Equal(t, 3, graph.Nodes[0].Retries)
+}
+
+// TestSessionGraphNotStarted tests that a session that has not run shows every step pending
+func TestSessionGraphNotStarted(t *testing.T) {
+	graph := buildPipelineGraph("s1", "created", "Caching", nil, time.Now())
+	require.Len(t, graph.Nodes, 4)
+	for _, node := range graph.Nodes {
+		assert.Equal(t, "pending", node.Status)
+	}
+	assert.Empty(t, graph.CurrentStep)
+	assert.Len(t, graph.Edges, 3)
+
+	graph = buildPipelineGraph("s1", "failed", "Caching", []SessionStep{{Name: "summarizer", Status: "failed", Error: "boom"}}, time.Now())
+	assert.Equal(t, "boom", graph.Nodes[0].Error)
+	assert.Equal(t, "pending", graph.Edges[0].Status)
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+)
+
+const (
+	// metadataQueryPrefix prefixes query parameters that filter on session metadata
+	metadataQueryPrefix = "meta."
+	// defaultSessionQueryLimit is the page size when no limit is given
+	defaultSessionQueryLimit = 50
+	// maxSessionQueryLimit caps the page size
+	maxSessionQueryLimit = 500
+)
+
+// reservedMetadataKeys are the session metadata keys callers cannot set as tags: ownership is
+// taken from the authenticated principal only
+var reservedMetadataKeys = map[string]bool{"user_id": true, "org_id": true}
+
+// sessionOwner returns the user a new session belongs to: the authenticated principal's user,
+// or no one for anonymous callers
+func sessionOwner(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		return principal.UserID
+	}
+	return ""
+}
+
+// metadataIndex indexes session IDs by metadata key and value.
+// It is guarded by the orchestrator's mu.
+type metadataIndex struct {
+	entries   map[string]map[string]map[string]struct{} // key -> value -> session IDs
+	bySession map[string]map[string]string              // session ID -> key -> indexed value
+}
+
+// newMetadataIndex creates an empty metadata index
+func newMetadataIndex() *metadataIndex {
+	return &metadataIndex{
+		entries:   make(map[string]map[string]map[string]struct{}),
+		bySession: make(map[string]map[string]string),
+	}
+}
+
+// metadataValue converts a metadata value to its indexed string form
+func metadataValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// update re-indexes a session's current metadata
+func (idx *metadataIndex) update(session *Session) {
+	idx.remove(session.ID)
+
+	indexed := make(map[string]string, len(session.Metadata))
+	for key, v := range session.Metadata {
+		value := metadataValue(v)
+		if idx.entries[key] == nil {
+			idx.entries[key] = make(map[string]map[string]struct{})
+		}
+		if idx.entries[key][value] == nil {
+			idx.entries[key][value] = make(map[string]struct{})
+		}
+		idx.entries[key][value][session.ID] = struct{}{}
+		indexed[key] = value
+	}
+	idx.bySession[session.ID] = indexed
+}
+
+// remove drops a session from the index
+func (idx *metadataIndex) remove(sessionID string) {
+	for key, value := range idx.bySession[sessionID] {
+		delete(idx.entries[key][value], sessionID)
+		if len(idx.entries[key][value]) == 0 {
+			delete(idx.entries[key], value)
+		}
+		if len(idx.entries[key]) == 0 {
+			delete(idx.entries, key)
+		}
+	}
+	delete(idx.bySession, sessionID)
+}
+
+// match returns the IDs of sessions matching every key filter.
+// A key with several values matches sessions having any of them.
+func (idx *metadataIndex) match(filters map[string][]string) map[string]struct{} {
+	var result map[string]struct{}
+	for key, values := range filters {
+		matched := make(map[string]struct{})
+		for _, value := range values {
+			for id := range idx.entries[key][value] {
+				if result == nil {
+					matched[id] = struct{}{}
+				} else if _, ok := result[id]; ok {
+					matched[id] = struct{}{}
+				}
+			}
+		}
+		result = matched
+		if len(result) == 0 {
+			break
+		}
+	}
+	return result
+}
+
+// indexSession indexes a session's metadata; call it after changing metadata outside UpdateSession
+func (o *Orchestrator) indexSession(session *Session) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.indexSessionLocked(session)
+}
+
+// indexSessionLocked indexes a session's metadata; o.mu must be held
+func (o *Orchestrator) indexSessionLocked(session *Session) {
+	if o.metaIndex == nil {
+		o.metaIndex = newMetadataIndex()
+	}
+	o.metaIndex.update(session)
+}
+
+// SessionSummary represents a session in query results
+type SessionSummary struct {
+	ID        string                 `json:"id"`
+	Topic     string                 `json:"topic"`
+	Status    string                 `json:"status"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Tags      []string               `json:"tags,omitempty"`
+	CourseID  string                 `json:"course_id,omitempty"`
+}
+
+// QuerySessions returns sessions whose metadata matches the filters, newest first.
+// An empty status matches every status.
+func (o *Orchestrator) QuerySessions(filters map[string][]string, status string) []SessionSummary {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	var candidates map[string]struct{}
+	if len(filters) > 0 {
+		if o.metaIndex == nil {
+			return []SessionSummary{}
+		}
+		candidates = o.metaIndex.match(filters)
+	}
+
+	results := make([]SessionSummary, 0)
+	for id, session := range o.sessions {
+		if candidates != nil {
+			if _, ok := candidates[id]; !ok {
+				continue
+			}
+		}
+		if status != "" && session.Status != status {
+			continue
+		}
+
+		metadata := make(map[string]interface{}, len(session.Metadata))
+		for k, v := range session.Metadata {
+			metadata[k] = v
+		}
+		results = append(results, SessionSummary{
+			ID:        session.ID,
+			Topic:     session.Topic,
+			Status:    session.Status,
+			CreatedAt: session.CreatedAt,
+			UpdatedAt: session.UpdatedAt,
+			Metadata:  metadata,
+			Tags:      append([]string(nil), session.Tags...),
+			CourseID:  session.CourseID,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].CreatedAt.Equal(results[j].CreatedAt) {
+			return results[i].ID < results[j].ID
+		}
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+	return results
+}
+
+// parseMetadataFilters extracts meta.<key>=<value> filters from query parameters
+func parseMetadataFilters(query map[string][]string) map[string][]string {
+	filters := make(map[string][]string)
+	for param, values := range query {
+		if !strings.HasPrefix(param, metadataQueryPrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(param, metadataQueryPrefix)
+		if key == "" {
+			continue
+		}
+		filters[key] = append(filters[key], values...)
+	}
+	return filters
+}
+
+// scopeSessionFilters limits a session listing to what the caller may see: admins see every session,
+// users their own and organization API keys their organization's. It returns false for anonymous callers.
+func scopeSessionFilters(r *http.Request, filters map[string][]string) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	switch {
+	case !ok:
+		return false
+	case principal.HasScope(auth.ScopeAdmin):
+		return true
+	case principal.UserID != "":
+		filters["user_id"] = []string{principal.UserID}
+		return true
+	case principal.OrgID != "":
+		filters["org_id"] = []string{principal.OrgID}
+		return true
+	}
+	return false
+}
+
+// listSessionsHandler handles GET /api/sessions?meta.<key>=<value>&tag=&course_id=&status=&limit=&offset=
+// Non-admin callers only see their own sessions; see scopeSessionFilters.
+func (o *Orchestrator) listSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+
+	limit := defaultSessionQueryLimit
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Invalid limit",
+				"message": "limit must be a positive integer",
+			})
+			return
+		}
+		limit = parsed
+	}
+	if limit > maxSessionQueryLimit {
+		limit = maxSessionQueryLimit
+	}
+
+	offset := 0
+	if v := query.Get("offset"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Invalid offset",
+				"message": "offset must be a non-negative integer",
+			})
+			return
+		}
+		offset = parsed
+	}
+
+	filters := parseMetadataFilters(query)
+	if !scopeSessionFilters(r, filters) {
+		writeJSONError(w, http.StatusForbidden, "Forbidden", "Listing sessions requires an authenticated caller")
+		return
+	}
+	sessions := groupingFilterFromQuery(r).filterSummaries(o.QuerySessions(filters, query.Get("status")))
+	total := len(sessions)
+
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sessions": sessions[offset:end],
+		"count":    end - offset,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+		"filters":  filters,
+	})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newQueryTestOrchestrator creates an orchestrator with sessions tagged by explanation type and source
+func newQueryTestOrchestrator(t *testing.T) *Orchestrator {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+	}
+
+	requests := []CreateSessionRequest{
+		{Topic: "TCP", ExplanationType: "analogy", Metadata: map[string]string{"source": "mobile"}},
+		{Topic: "UDP", ExplanationType: "analogy", Metadata: map[string]string{"source": "web"}},
+		{Topic: "DNS", ExplanationType: "simple", Metadata: map[string]string{"source": "mobile", "explanation_type": "ignored"}},
+	}
+	for _, req := range requests {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		o.createSessionHandler(w, httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body)))
+		require.Equal(t, http.StatusCreated, w.Code)
+		time.Sleep(time.Millisecond) // keep creation order distinct
+	}
+	return o
+}
+
+// queryAdmin is an admin principal that may list every session
+var queryAdmin = &auth.Principal{APIKeyID: "key-admin", Scopes: []auth.Scope{auth.ScopeAdmin}}
+
+// querySessions calls the list sessions handler as an admin and decodes the response
+func querySessions(t *testing.T, o *Orchestrator, rawQuery string) ([]SessionSummary, int) {
+	return querySessionsAs(t, o, queryAdmin, rawQuery)
+}
+
+// querySessionsAs calls the list sessions handler as principal and decodes the response
+func querySessionsAs(t *testing.T, o *Orchestrator, principal *auth.Principal, rawQuery string) ([]SessionSummary, int) {
+	w := httptest.NewRecorder()
+	withPrincipal(http.HandlerFunc(o.listSessionsHandler), principal).ServeHTTP(w, httptest.NewRequest("GET", "/api/sessions?"+rawQuery, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Sessions []SessionSummary `json:"sessions"`
+		Total    int              `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Sessions, response.Total
+}
+
+// TestCreateSessionOwnership tests that a session's owner and organization come only from the principal, never from the body
+func TestCreateSessionOwnership(t *testing.T) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+	}
+	create := func(principal *auth.Principal) *Session {
+		body, _ := json.Marshal(CreateSessionRequest{Topic: "TCP", OrgID: "acme", Metadata: map[string]string{"user_id": "victim", "org_id": "acme", "source": "web"}})
+		req := httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body))
+		if principal != nil {
+			req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+		}
+		w := httptest.NewRecorder()
+		o.createSessionHandler(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var response CreateSessionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		session, _ := o.GetSession(response.ID)
+		return session
+	}
+
+	anonymous := create(nil)
+	assert.NotContains(t, anonymous.Metadata, "user_id")
+	assert.NotContains(t, anonymous.Metadata, "org_id")
+	assert.Equal(t, "web", anonymous.Metadata["source"])
+
+	owned := create(&auth.Principal{UserID: "u1", Method: auth.MethodJWT})
+	assert.Equal(t, "u1", owned.Metadata["user_id"])
+	assert.NotContains(t, owned.Metadata, "org_id")
+
+	member := create(&auth.Principal{UserID: "u2", OrgID: "globex", Method: auth.MethodJWT})
+	assert.Equal(t, "globex", member.Metadata["org_id"])
+}
+
+// TestQuerySessionsByMetadata tests filtering sessions by metadata keys
+func TestQuerySessionsByMetadata(t *testing.T) {
+	o := newQueryTestOrchestrator(t)
+
+	sessions, total := querySessions(t, o, "meta.explanation_type=analogy&meta.source=mobile")
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "TCP", sessions[0].Topic)
+
+	// Several values for one key match any of them, newest first
+	sessions, _ = querySessions(t, o, "meta.source=mobile&meta.source=web")
+	require.Len(t, sessions, 3)
+	assert.Equal(t, "DNS", sessions[0].Topic)
+
+	// Caller tags never override server-set metadata
+	_, total = querySessions(t, o, "meta.explanation_type=ignored")
+	assert.Equal(t, 0, total)
+
+	_, total = querySessions(t, o, "meta.source=desktop")
+	assert.Equal(t, 0, total)
+}
+
+// TestQuerySessionsReindexesOnUpdate tests that the index follows metadata changes
+func TestQuerySessionsReindexesOnUpdate(t *testing.T) {
+	o := newQueryTestOrchestrator(t)
+
+	sessions, _ := querySessions(t, o, "meta.source=web")
+	require.Len(t, sessions, 1)
+
+	session, _ := o.GetSession(sessions[0].ID)
+	session.Metadata["source"] = "mobile"
+	session.Status = "completed"
+	o.UpdateSession(session)
+
+	_, total := querySessions(t, o, "meta.source=web")
+	assert.Equal(t, 0, total)
+	_, total = querySessions(t, o, "meta.source=mobile&status=completed")
+	assert.Equal(t, 1, total)
+}
+
+// TestQuerySessionsPagination tests limit and offset handling
+func TestQuerySessionsPagination(t *testing.T) {
+	o := newQueryTestOrchestrator(t)
+
+	sessions, total := querySessions(t, o, "limit=2&offset=1")
+	assert.Equal(t, 3, total)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "UDP", sessions[0].Topic)
+
+	w := httptest.NewRecorder()
+	o.listSessionsHandler(w, httptest.NewRequest("GET", "/api/sessions?limit=abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestListSessionsScopedToCaller tests that non-admins only list their own sessions and anonymous callers none
+func TestListSessionsScopedToCaller(t *testing.T) {
+	o := newQueryTestOrchestrator(t)
+	for _, principal := range []*auth.Principal{
+		{UserID: "alice", OrgID: "acme", Method: auth.MethodJWT},
+		{UserID: "bob", OrgID: "acme", Method: auth.MethodJWT},
+	} {
+		body, _ := json.Marshal(CreateSessionRequest{Topic: "Owned by " + principal.UserID})
+		w := httptest.NewRecorder()
+		withPrincipal(http.HandlerFunc(o.createSessionHandler), principal).ServeHTTP(w, httptest.NewRequest("POST", "/api/sessions", bytes.NewBuffer(body)))
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	sessions, total := querySessionsAs(t, o, &auth.Principal{UserID: "alice", Method: auth.MethodJWT}, "meta.user_id=bob")
+	require.Equal(t, 1, total, "a user_id filter cannot widen the listing")
+	assert.Equal(t, "Owned by alice", sessions[0].Topic)
+
+	_, total = querySessionsAs(t, o, &auth.Principal{OrgID: "acme", APIKeyID: "key-org"}, "")
+	assert.Equal(t, 2, total)
+
+	_, total = querySessions(t, o, "")
+	assert.Equal(t, 5, total)
+
+	w := httptest.NewRecorder()
+	o.listSessionsHandler(w, httptest.NewRequest("GET", "/api/sessions", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	adkgoogle "github.com/InnoFusionTech/ExplainIQ/internal/adk/google"
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/flags"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultShadowPercent is the share of sessions mirrored when SHADOW_TRAFFIC_PERCENT is unset
+	defaultShadowPercent = 10
+	// shadowRecordLimit bounds the comparisons kept in memory
+	shadowRecordLimit = 500
+	// shadowMaxInFlight bounds concurrent shadow calls; steps beyond it are not mirrored
+	shadowMaxInFlight = 4
+)
+
+// shadowAgentURLsFromEnv reads the shadow agents from SHADOW_AGENT_URLS, a comma-separated
+// list of agent=url pairs such as "explainer=https://agent-explainer-v2.run.app"
+func shadowAgentURLsFromEnv() map[string]string {
+	urls := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv("SHADOW_AGENT_URLS"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		agent, url, ok := strings.Cut(pair, "=")
+		agent, url = strings.TrimSpace(agent), strings.TrimSpace(url)
+		if !ok || agent == "" || url == "" {
+			logrus.WithField("value", pair).Warn("Invalid SHADOW_AGENT_URLS entry, expected agent=url")
+			continue
+		}
+		urls[agent] = url
+	}
+	return urls
+}
+
+// shadowPercentFromEnv returns the percentage of sessions mirrored to shadow agents (SHADOW_TRAFFIC_PERCENT)
+func shadowPercentFromEnv() int {
+	if v := os.Getenv("SHADOW_TRAFFIC_PERCENT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n <= 100 {
+			return n
+		}
+		logrus.WithField("value", v).Warn("Invalid SHADOW_TRAFFIC_PERCENT, using default")
+	}
+	return defaultShadowPercent
+}
+
+// ShadowOutput is what one agent returned for a mirrored step
+type ShadowOutput struct {
+	Artifacts  map[string]string      `json:"artifacts,omitempty"`
+	Metrics    map[string]interface{} `json:"metrics,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// ShadowRecord pairs the user-facing output of a step with the shadow agent's output for the
+// same task request
+type ShadowRecord struct {
+	ID         string       `json:"id"`
+	SessionID  string       `json:"session_id"`
+	Step       string       `json:"step"`
+	Agent      string       `json:"agent"`
+	ShadowURL  string       `json:"shadow_url"`
+	Primary    ShadowOutput `json:"primary"`
+	Shadow     ShadowOutput `json:"shadow"`
+	RecordedAt time.Time    `json:"recorded_at"`
+}
+
+// shadowMirror dark-launches new agent versions: it sends a sample of sessions' step requests
+// to shadow agents after the real agent answered and records both outputs. Shadow responses
+// never reach sessions, and a slow or failing shadow agent never delays or fails a step.
+type shadowMirror struct {
+	clients map[string]AgentClient // Shadow agent by agent name
+	urls    map[string]string
+	percent int
+	timeout time.Duration
+	logger  *logrus.Logger
+	slots   chan struct{}
+	pending sync.WaitGroup
+
+	mu      sync.RWMutex
+	records []*ShadowRecord // Oldest first
+	file    *os.File        // Records are appended as JSON lines when SHADOW_RECORD_FILE is set
+}
+
+// newShadowMirror creates a mirror for the configured shadow agents, or returns nil if there are none
+func newShadowMirror(config PipelineConfig, authClient *auth.Client, logger *logrus.Logger) *shadowMirror {
+	if len(config.ShadowAgentURLs) == 0 || config.ShadowPercent <= 0 {
+		return nil
+	}
+	clients := make(map[string]AgentClient, len(config.ShadowAgentURLs))
+	for agentName, baseURL := range config.ShadowAgentURLs {
+		logger.WithFields(logrus.Fields{
+			"agent":   agentName,
+			"url":     baseURL,
+			"percent": config.ShadowPercent,
+		}).Info("Mirroring agent traffic to shadow agent")
+		clients[agentName] = adkgoogle.NewClient(baseURL).
+			WithTimeout(config.StepTimeout).
+			WithLogger(logger).
+			WithAuthClient(authClient)
+	}
+
+	mirror := newShadowMirrorWithClients(clients, config.ShadowAgentURLs, config.ShadowPercent, config.StepTimeout, logger)
+	if path := config.ShadowRecordFile; path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to open SHADOW_RECORD_FILE, shadow records are kept in memory only")
+		} else {
+			mirror.file = file
+		}
+	}
+	return mirror
+}
+
+// newShadowMirrorWithClients creates a mirror sending to the given shadow agent clients
+func newShadowMirrorWithClients(clients map[string]AgentClient, urls map[string]string, percent int, timeout time.Duration, logger *logrus.Logger) *shadowMirror {
+	return &shadowMirror{
+		clients: clients,
+		urls:    urls,
+		percent: percent,
+		timeout: timeout,
+		logger:  logger,
+		slots:   make(chan struct{}, shadowMaxInFlight),
+	}
+}
+
+// sampled reports whether a session's steps are mirrored. Sampling is by session, so a
+// mirrored session has every shadowed step compared.
+func (m *shadowMirror) sampled(sessionID string) bool {
+	return flags.Bucket("shadow", sessionID) < m.percent
+}
+
+// mirror sends a step's task request to the step's shadow agent in the background and records
+// its output next to the real agent's
+func (m *shadowMirror) mirror(ctx context.Context, step PipelineStep, req adk.TaskRequest, primary *adk.TaskResponse, primaryDuration time.Duration) {
+	if m == nil {
+		return
+	}
+	client, ok := m.clients[step.Agent]
+	if !ok || !m.sampled(req.SessionID) {
+		return
+	}
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		m.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"step":       step.Name,
+		}).Debug("Shadow agent busy, not mirroring step")
+		return
+	}
+
+	record := &ShadowRecord{
+		ID:        uuid.New().String(),
+		SessionID: req.SessionID,
+		Step:      step.Name,
+		Agent:     step.Agent,
+		ShadowURL: m.urls[step.Agent],
+		Primary: ShadowOutput{
+			Artifacts:  copyStringMap(primary.Artifacts),
+			Metrics:    primary.Metrics,
+			DurationMs: primaryDuration.Milliseconds(),
+		},
+	}
+	shadowReq := req
+	shadowReq.Inputs = copyStringMap(req.Inputs)
+	delete(shadowReq.Inputs, "trace_prompts")
+
+	// The shadow call outlives the step, but not the shadow timeout
+	shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.timeout)
+	m.pending.Add(1)
+	go func() {
+		defer m.pending.Done()
+		defer func() { <-m.slots }()
+		defer cancel()
+
+		start := time.Now()
+		response, err := client.ExecuteTask(shadowCtx, &shadowReq)
+		record.Shadow.DurationMs = time.Since(start).Milliseconds()
+		if err != nil {
+			record.Shadow.Error = err.Error()
+		} else {
+			record.Shadow.Artifacts = copyStringMap(response.Artifacts)
+			record.Shadow.Metrics = response.Metrics
+			// Shadow agents follow the same redaction rules as real ones
+			delete(record.Shadow.Artifacts, agents.ScratchpadArtifact)
+			delete(record.Shadow.Artifacts, agents.PromptTraceArtifact)
+		}
+		record.RecordedAt = time.Now()
+		m.add(record)
+	}()
+}
+
+// add stores a finished comparison, dropping the oldest beyond shadowRecordLimit
+func (m *shadowMirror) add(record *ShadowRecord) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, record)
+	if len(m.records) > shadowRecordLimit {
+		m.records = m.records[len(m.records)-shadowRecordLimit:]
+	}
+	if m.file != nil {
+		line, err := json.Marshal(record)
+		if err == nil {
+			_, err = m.file.Write(append(line, '\n'))
+		}
+		if err != nil {
+			m.logger.WithError(err).Warn("Failed to write shadow record")
+		}
+	}
+}
+
+// wait blocks until in-flight shadow calls finish
+func (m *shadowMirror) wait() {
+	m.pending.Wait()
+}
+
+// list returns the recorded comparisons, newest first, optionally for one step or session
+func (m *shadowMirror) list(step, sessionID string, limit int) []*ShadowRecord {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	records := make([]*ShadowRecord, 0)
+	for i := len(m.records) - 1; i >= 0 && (limit <= 0 || len(records) < limit); i-- {
+		record := m.records[i]
+		if (step != "" && record.Step != step) || (sessionID != "" && record.SessionID != sessionID) {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// ShadowSummary compares a shadowed agent with its real counterpart
+type ShadowSummary struct {
+	Agent           string  `json:"agent"`
+	ShadowURL       string  `json:"shadow_url"`
+	Mirrored        int     `json:"mirrored"`
+	ShadowErrors    int     `json:"shadow_errors"`
+	AvgPrimaryMs    float64 `json:"avg_primary_ms"`
+	AvgShadowMs     float64 `json:"avg_shadow_ms"`
+	ArtifactsDiffer int     `json:"artifacts_differ"` // Successful mirrors whose artifact names differ from the real agent's
+}
+
+// summary aggregates the recorded comparisons by agent
+func (m *shadowMirror) summary() []ShadowSummary {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	summaries := make([]ShadowSummary, 0, len(m.urls))
+	for agentName, url := range m.urls {
+		summary := ShadowSummary{Agent: agentName, ShadowURL: url}
+		var primaryMs, shadowMs int64
+		for _, record := range m.records {
+			if record.Agent != agentName {
+				continue
+			}
+			summary.Mirrored++
+			primaryMs += record.Primary.DurationMs
+			shadowMs += record.Shadow.DurationMs
+			if record.Shadow.Error != "" {
+				summary.ShadowErrors++
+			} else if !sameKeys(record.Primary.Artifacts, record.Shadow.Artifacts) {
+				summary.ArtifactsDiffer++
+			}
+		}
+		if summary.Mirrored > 0 {
+			summary.AvgPrimaryMs = roundHundredths(float64(primaryMs) / float64(summary.Mirrored))
+			summary.AvgShadowMs = roundHundredths(float64(shadowMs) / float64(summary.Mirrored))
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// sameKeys reports whether two artifact maps have the same artifact names
+func sameKeys(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// copyStringMap returns a shallow copy of m
+func copyStringMap(m map[string]string) map[string]string {
+	copied := make(map[string]string, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
+
+// shadowRecordsHandler handles GET /api/admin/shadow?step=&session_id=&limit=&format=jsonl
+// It lists shadow comparisons newest first with a per-agent summary; format=jsonl downloads
+// the records one per line for offline comparison.
+func (o *Orchestrator) shadowRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	if o.pipeline == nil || o.pipeline.shadow == nil {
+		http.Error(w, "Shadow mode is not configured", http.StatusNotFound)
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	records := o.pipeline.shadow.list(r.URL.Query().Get("step"), r.URL.Query().Get("session_id"), limit)
+
+	if r.URL.Query().Get("format") == "jsonl" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="shadow-records.jsonl"`)
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			encoder.Encode(record)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"percent": o.pipeline.shadow.percent,
+		"summary": o.pipeline.shadow.summary(),
+		"records": records,
+	})
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shadowExplainerClient is a new explainer version answering with its own lesson
+type shadowExplainerClient struct {
+	calls atomic.Int32
+}
+
+// ExecuteTask implements AgentClient
+func (c *shadowExplainerClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error) {
+	c.calls.Add(1)
+	return &adk.TaskResponse{
+		Artifacts: map[string]string{
+			"lesson":                  `{"big_picture": "Shadow lesson"}`,
+			agents.ScratchpadArtifact: "private reasoning",
+		},
+		Metrics: map[string]interface{}{"model": "gemini-2.5-pro"},
+	}, nil
+}
+
+// Health implements AgentClient
+func (c *shadowExplainerClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// runShadowedSession runs a session with a primary explainer and the given shadow explainer
+func runShadowedSession(t *testing.T, shadow AgentClient) (*Pipeline, *Session) {
+	config := DefaultPipelineConfig()
+	config.RetryDelay = time.Millisecond
+	agent := sectionsAgentClient{}
+	p := &Pipeline{
+		config:     config,
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+		shadow: newShadowMirrorWithClients(
+			map[string]AgentClient{"explainer": shadow},
+			map[string]string{"explainer": "http://agent-explainer-v2:8082"},
+			100, time.Second, logrus.New()),
+	}
+	session := &Session{ID: "s1", Topic: "Caching", Status: "created", Metadata: map[string]interface{}{}}
+	o := &Orchestrator{
+		sessions: map[string]*Session{"s1": session},
+		logger:   logrus.New(),
+		clients:  make(map[string][]chan SSEEvent),
+	}
+	require.NoError(t, p.runPipeline(context.Background(), "s1", o))
+	p.shadow.wait()
+	session, _ = o.GetSession("s1")
+	return p, session
+}
+
+// TestShadowMirrorRecordsBothOutputs tests that shadowed steps record both agents' outputs
+// while the session keeps the real agent's
+func TestShadowMirrorRecordsBothOutputs(t *testing.T) {
+	shadow := &shadowExplainerClient{}
+	p, session := runShadowedSession(t, shadow)
+
+	require.NotNil(t, session.Result)
+	assert.Contains(t, session.Result.Lesson, "Keep hot data close")
+	assert.NotContains(t, session.Result.Lesson, "Shadow lesson")
+	assert.Equal(t, int32(1), shadow.calls.Load())
+
+	records := p.shadow.list("", "", 0)
+	require.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "s1", record.SessionID)
+	assert.Equal(t, "explainer", record.Step)
+	assert.Equal(t, "http://agent-explainer-v2:8082", record.ShadowURL)
+	assert.Contains(t, record.Primary.Artifacts["lesson"], "Keep hot data close")
+	assert.Contains(t, record.Shadow.Artifacts["lesson"], "Shadow lesson")
+	assert.NotContains(t, record.Shadow.Artifacts, agents.ScratchpadArtifact)
+	assert.Equal(t, "gemini-2.5-pro", record.Shadow.Metrics["model"])
+	assert.Empty(t, record.Shadow.Error)
+}
+
+// TestShadowMirrorFailureDoesNotAffectSession tests that a failing shadow agent is recorded
+// without failing the session
+func TestShadowMirrorFailureDoesNotAffectSession(t *testing.T) {
+	p, session := runShadowedSession(t, unreachableAgentClient{})
+
+	assert.Equal(t, "completed", session.Status)
+	require.NotNil(t, session.Result)
+	assert.Contains(t, session.Result.Lesson, "Keep hot data close")
+
+	records := p.shadow.list("explainer", "", 0)
+	require.Len(t, records, 1)
+	assert.Contains(t, records[0].Shadow.Error, "connection refused")
+
+	summary := p.shadow.summary()
+	require.Len(t, summary, 1)
+	assert.Equal(t, 1, summary[0].Mirrored)
+	assert.Equal(t, 1, summary[0].ShadowErrors)
+}
+
+// TestShadowMirrorSamplesSessions tests that only the configured share of sessions is mirrored,
+// and always the same ones
+func TestShadowMirrorSamplesSessions(t *testing.T) {
+	shadow := &shadowExplainerClient{}
+	m := newShadowMirrorWithClients(map[string]AgentClient{"explainer": shadow}, nil, 30, time.Second, logrus.New())
+	step := PipelineStep{Name: "explainer", Agent: "explainer"}
+	primary := &adk.TaskResponse{Artifacts: map[string]string{"lesson": "{}"}}
+
+	sampled := 0
+	for i := 0; i < 200; i++ {
+		sessionID := fmt.Sprintf("session-%d", i)
+		if m.sampled(sessionID) {
+			sampled++
+		}
+		m.mirror(context.Background(), step, adk.TaskRequest{SessionID: sessionID, Step: "explainer"}, primary, time.Millisecond)
+		m.wait()
+	}
+	assert.InDelta(t, 60, sampled, 25)
+	assert.Equal(t, int32(sampled), shadow.calls.Load())
+
+	// Steps of agents without a shadow version are never mirrored
+	for i := 0; i < 20; i++ {
+		m.mirror(context.Background(), PipelineStep{Name: "critic", Agent: "critic"}, adk.TaskRequest{SessionID: fmt.Sprintf("session-%d", i)}, primary, 0)
+	}
+	m.wait()
+	assert.Equal(t, int32(sampled), shadow.calls.Load())
+
+	// A nil mirror is a no-op
+	var none *shadowMirror
+	none.mirror(context.Background(), step, adk.TaskRequest{SessionID: "s1"}, primary, 0)
+}
+
+// TestShadowRecordsHandler tests listing and downloading shadow comparisons
+func TestShadowRecordsHandler(t *testing.T) {
+	o := &Orchestrator{logger: logrus.New(), pipeline: &Pipeline{}}
+	r := chi.NewRouter()
+	r.Get("/api/admin/shadow", o.shadowRecordsHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/shadow", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	o.pipeline.shadow = newShadowMirrorWithClients(nil, map[string]string{"explainer": "http://v2"}, 100, time.Second, logrus.New())
+	for _, sessionID := range []string{"s1", "s2", "s3"} {
+		o.pipeline.shadow.add(&ShadowRecord{
+			ID:        sessionID + "-explainer",
+			SessionID: sessionID,
+			Step:      "explainer",
+			Agent:     "explainer",
+			Primary:   ShadowOutput{Artifacts: map[string]string{"lesson": "a"}, DurationMs: 100},
+			Shadow:    ShadowOutput{Artifacts: map[string]string{"lesson": "b", "diagram": "c"}, DurationMs: 300},
+		})
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/shadow?limit=2", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Percent int             `json:"percent"`
+		Summary []ShadowSummary `json:"summary"`
+		Records []ShadowRecord  `json:"records"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 100, response.Percent)
+	require.Len(t, response.Records, 2)
+	assert.Equal(t, "s3", response.Records[0].SessionID)
+	require.Len(t, response.Summary, 1)
+	assert.Equal(t, 3, response.Summary[0].Mirrored)
+	assert.Equal(t, 3, response.Summary[0].ArtifactsDiffer)
+	assert.Equal(t, 100.0, response.Summary[0].AvgPrimaryMs)
+	assert.Equal(t, 300.0, response.Summary[0].AvgShadowMs)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/shadow?format=jsonl&session_id=s2", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	scanner := bufio.NewScanner(strings.NewReader(w.Body.String()))
+	lines := 0
+	for scanner.Scan() {
+		var record ShadowRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		assert.Equal(t, "s2", record.SessionID)
+		lines++
+	}
+	assert.Equal(t, 1, lines)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/shadow?limit=x", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestShadowConfigFromEnv tests parsing SHADOW_AGENT_URLS and SHADOW_TRAFFIC_PERCENT
+func TestShadowConfigFromEnv(t *testing.T) {
+	t.Setenv("SHADOW_AGENT_URLS", "explainer=https://explainer-v2.run.app, bogus ,critic=")
+	assert.Equal(t, map[string]string{"explainer": "https://explainer-v2.run.app"}, shadowAgentURLsFromEnv())
+
+	t.Setenv("SHADOW_TRAFFIC_PERCENT", "25")
+	assert.Equal(t, 25, shadowPercentFromEnv())
+	t.Setenv("SHADOW_TRAFFIC_PERCENT", "150")
+	assert.Equal(t, defaultShadowPercent, shadowPercentFromEnv())
+
+	config := DefaultPipelineConfig()
+	config.ShadowAgentURLs = nil
+	assert.Nil(t, newShadowMirror(config, nil, logrus.New()))
+}
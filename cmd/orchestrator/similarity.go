@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/InnoFusionTech/ExplainIQ/internal/retrieval"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultSimilarityThreshold is the cosine similarity at or above which a section is flagged
+	defaultSimilarityThreshold = 0.92
+	// similarityCandidates is the number of corpus documents compared per lesson section
+	similarityCandidates = 3
+	// similarityMinWords skips sections too short to meaningfully reproduce a source
+	similarityMinWords = 12
+	// similaritySnippetChars caps the corpus excerpt stored with a match
+	similaritySnippetChars = 200
+)
+
+// TextEmbedder embeds texts for similarity comparison (e.g. llm.EmbeddingClient)
+type TextEmbedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// CorpusSearcher finds indexed documents close to a text (e.g. a retrieval.Provider)
+type CorpusSearcher interface {
+	HybridSearch(ctx context.Context, index, query string, embedding []float32, size int) ([]retrieval.Hit, error)
+}
+
+// SimilarityMatch is a lesson section that nearly duplicates an indexed corpus document
+type SimilarityMatch struct {
+	Section    string  `json:"section"` // Lesson section anchor ID
+	DocID      string  `json:"doc_id"`
+	DocTopic   string  `json:"doc_topic,omitempty"`
+	DocSection string  `json:"doc_section,omitempty"`
+	Similarity float64 `json:"similarity"`        // Cosine similarity of the embeddings
+	Snippet    string  `json:"snippet,omitempty"` // Start of the matching corpus document
+}
+
+// SimilarityReport records the near-duplicate check run before a lesson is finalized
+type SimilarityReport struct {
+	Threshold       float64           `json:"threshold"`
+	Flagged         bool              `json:"flagged"` // True if any section matched at or above the threshold
+	SectionsChecked int               `json:"sections_checked"`
+	Matches         []SimilarityMatch `json:"matches"`
+	CheckedAt       time.Time         `json:"checked_at"`
+}
+
+// similarityThresholdFromEnv reads SIMILARITY_THRESHOLD, a cosine similarity in (0, 1]
+func similarityThresholdFromEnv() float64 {
+	v := os.Getenv("SIMILARITY_THRESHOLD")
+	if v == "" {
+		return defaultSimilarityThreshold
+	}
+	threshold, err := strconv.ParseFloat(v, 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		logrus.WithField("value", v).Warn("Invalid SIMILARITY_THRESHOLD, using default")
+		return defaultSimilarityThreshold
+	}
+	return threshold
+}
+
+// checkSimilarity compares each lesson section's embedding against the corpus indexed for the
+// session's organization and reports sections whose closest documents are at or above the
+// similarity threshold.
+// It returns nil when the check is disabled or cannot run; failures never block a lesson.
+func (p *Pipeline) checkSimilarity(ctx context.Context, sessionID, orgID, lessonJSON string) *SimilarityReport {
+	if !p.config.SimilarityCheck || p.corpusSearcher == nil || p.similarityEmbedder == nil {
+		return nil
+	}
+	lesson := parseLesson(lessonJSON)
+	if lesson == nil {
+		return nil
+	}
+
+	index, err := p.retrievalIndex(ctx, orgID)
+	if err != nil {
+		p.logTenantIndexError(sessionID, orgID, err)
+		return nil
+	}
+	report, err := findSimilarSections(ctx, p.corpusSearcher, p.similarityEmbedder, index, lesson, p.config.SimilarityThreshold)
+	if err != nil {
+		p.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"error":      err,
+		}).Warn("Similarity check failed, finalizing lesson without it")
+		return nil
+	}
+
+	fields := logrus.Fields{
+		"session_id": sessionID,
+		"checked":    report.SectionsChecked,
+		"matches":    len(report.Matches),
+	}
+	if report.Flagged {
+		p.logger.WithFields(fields).Warn("Lesson sections closely match indexed source material")
+	} else {
+		p.logger.WithFields(fields).Info("Similarity check passed")
+	}
+	return report
+}
+
+// findSimilarSections runs the near-duplicate check for a lesson.
+// Candidate documents are found by hybrid search and scored by embedding cosine similarity.
+func findSimilarSections(ctx context.Context, searcher CorpusSearcher, embedder TextEmbedder, index string, lesson *llm.OGLesson, threshold float64) (*SimilarityReport, error) {
+	report := &SimilarityReport{Threshold: threshold, Matches: []SimilarityMatch{}, CheckedAt: time.Now().UTC()}
+
+	var sections []llm.LessonSection
+	var texts []string
+	for _, section := range llm.LessonSections {
+		text, _ := lesson.SectionText(section.ID)
+		if len(strings.Fields(text)) < similarityMinWords {
+			continue
+		}
+		sections = append(sections, section)
+		texts = append(texts, text)
+	}
+	if len(texts) == 0 {
+		return report, nil
+	}
+
+	embeddings, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed lesson sections: %w", err)
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d section embeddings, got %d", len(texts), len(embeddings))
+	}
+
+	for i, section := range sections {
+		hits, err := searcher.HybridSearch(ctx, index, texts[i], embeddings[i], similarityCandidates)
+		if err != nil {
+			return nil, fmt.Errorf("corpus search failed for section %s: %w", section.ID, err)
+		}
+		if err := embedMissingDocs(ctx, embedder, hits); err != nil {
+			return nil, err
+		}
+
+		for _, hit := range hits {
+			if len(hit.Doc.Embedding) != len(embeddings[i]) {
+				continue
+			}
+			similarity := cosineSimilarity(embeddings[i], hit.Doc.Embedding)
+			if similarity < threshold {
+				continue
+			}
+			report.Matches = append(report.Matches, SimilarityMatch{
+				Section:    section.ID,
+				DocID:      hit.Doc.ID,
+				DocTopic:   hit.Doc.Topic,
+				DocSection: hit.Doc.Section,
+				Similarity: similarity,
+				Snippet:    trimSnippet(hit.Doc.Text, similaritySnippetChars),
+			})
+		}
+		report.SectionsChecked++
+	}
+
+	sort.SliceStable(report.Matches, func(i, j int) bool {
+		return report.Matches[i].Similarity > report.Matches[j].Similarity
+	})
+	report.Flagged = len(report.Matches) > 0
+	return report, nil
+}
+
+// embedMissingDocs embeds, in one batch, the hits whose backend did not return a stored embedding
+func embedMissingDocs(ctx context.Context, embedder TextEmbedder, hits []retrieval.Hit) error {
+	var missing []int
+	var texts []string
+	for i, hit := range hits {
+		if len(hit.Doc.Embedding) == 0 && strings.TrimSpace(hit.Doc.Text) != "" {
+			missing = append(missing, i)
+			texts = append(texts, hit.Doc.Text)
+		}
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	embeddings, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed corpus documents: %w", err)
+	}
+	for j, i := range missing {
+		if j < len(embeddings) {
+			hits[i].Doc.Embedding = embeddings[j]
+		}
+	}
+	return nil
+}
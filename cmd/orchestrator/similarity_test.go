@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/elastic"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/InnoFusionTech/ExplainIQ/internal/retrieval"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubEmbedder embeds texts by keyword: "cache" texts point one way, everything else another
+type stubEmbedder struct {
+	calls int
+	err   error
+}
+
+// Embed implements TextEmbedder
+func (s *stubEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		if strings.Contains(strings.ToLower(text), "cache") {
+			embeddings[i] = []float32{1, 0.01}
+		} else {
+			embeddings[i] = []float32{0, 1}
+		}
+	}
+	return embeddings, nil
+}
+
+// stubSearcher returns fixed hits for every query
+type stubSearcher struct {
+	hits []retrieval.Hit
+}
+
+// HybridSearch implements CorpusSearcher
+func (s *stubSearcher) HybridSearch(ctx context.Context, index, query string, embedding []float32, size int) ([]retrieval.Hit, error) {
+	hits := make([]retrieval.Hit, len(s.hits))
+	copy(hits, s.hits)
+	return hits, nil
+}
+
+// similarityTestLesson has one long section about caches, one long unrelated section and one short section
+func similarityTestLesson() *llm.OGLesson {
+	return &llm.OGLesson{
+		BigPicture:    "A cache keeps frequently used data close to the code that needs it so repeated reads are fast.",
+		CoreMechanism: "Lookups check a fast store first and fall back to the slower origin on a miss, then fill the store.",
+		MemoryHook:    "Keep hot data close.",
+	}
+}
+
+// TestFindSimilarSections tests flagging sections that match corpus documents
+func TestFindSimilarSections(t *testing.T) {
+	searcher := &stubSearcher{hits: []retrieval.Hit{
+		{Doc: elastic.Doc{ID: "doc-1", Topic: "Caching", Text: "A cache keeps frequently used data close."}},
+		{Doc: elastic.Doc{ID: "doc-2", Topic: "Queues", Text: "Queues buffer work.", Embedding: []float32{0, 1}}},
+	}}
+	embedder := &stubEmbedder{}
+
+	report, err := findSimilarSections(context.Background(), searcher, embedder, "lessons", similarityTestLesson(), 0.9)
+	require.NoError(t, err)
+	assert.True(t, report.Flagged)
+	assert.Equal(t, 2, report.SectionsChecked) // the short memory hook is skipped
+	require.Len(t, report.Matches, 2)
+
+	sections := []string{report.Matches[0].Section, report.Matches[1].Section}
+	assert.ElementsMatch(t, []string{"big-picture", "core-mechanism"}, sections)
+	for _, match := range report.Matches {
+		if match.Section == "big-picture" {
+			assert.Equal(t, "doc-1", match.DocID)
+		} else {
+			assert.Equal(t, "doc-2", match.DocID)
+		}
+		assert.GreaterOrEqual(t, match.Similarity, 0.9)
+	}
+
+	report, err = findSimilarSections(context.Background(), searcher, embedder, "lessons", &llm.OGLesson{MemoryHook: "Short."}, 0.9)
+	require.NoError(t, err)
+	assert.False(t, report.Flagged)
+	assert.Empty(t, report.Matches)
+}
+
+// TestCheckSimilarity tests that the check is skipped when disabled and never fails the lesson
+func TestCheckSimilarity(t *testing.T) {
+	lessonJSON := `{"big_picture":"A cache keeps frequently used data close to the code that needs it so repeated reads are fast."}`
+	searcher := &stubSearcher{hits: []retrieval.Hit{{Doc: elastic.Doc{ID: "doc-1", Text: "cache", Embedding: []float32{1, 0}}}}}
+	p := &Pipeline{
+		config:             PipelineConfig{SimilarityThreshold: 0.9},
+		logger:             logrus.New(),
+		corpusSearcher:     searcher,
+		similarityEmbedder: &stubEmbedder{},
+	}
+	assert.Nil(t, p.checkSimilarity(context.Background(), "s1", "", lessonJSON))
+
+	p.config.SimilarityCheck = true
+	report := p.checkSimilarity(context.Background(), "s1", "", lessonJSON)
+	require.NotNil(t, report)
+	assert.True(t, report.Flagged)
+
+	p.similarityEmbedder = &stubEmbedder{err: errors.New("embedding unavailable")}
+	assert.Nil(t, p.checkSimilarity(context.Background(), "s1", "", lessonJSON))
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// revealJSVersion is the reveal.js release the exported HTML deck loads from the CDN
+	revealJSVersion = "5.1.0"
+	// pptxContentType is the MIME type of PowerPoint decks
+	pptxContentType = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+)
+
+// Slide represents one slide in an exported deck
+type Slide struct {
+	Title    string
+	Bullets  []string // Rendered as a bulleted list
+	Body     string   // Rendered as paragraphs
+	Code     string   // Rendered in a monospace block
+	ImageURL string
+	Caption  string
+}
+
+// buildSlideDeck converts a saved lesson into slides: a title slide, the outline,
+// one slide per lesson section, and one slide per generated image
+func buildSlideDeck(saved *SavedLesson) []Slide {
+	title := saved.Title
+	if title == "" {
+		title = saved.Topic
+	}
+
+	slides := []Slide{{Title: title, Body: saved.Topic}}
+	if saved.Result == nil {
+		return slides
+	}
+
+	if len(saved.Result.Outline) > 0 {
+		slides = append(slides, Slide{Title: "Outline", Bullets: saved.Result.Outline})
+	}
+
+	if lesson := parseLesson(saved.Result.Lesson); lesson != nil {
+		for _, section := range llm.LessonSections {
+			text, _ := lesson.SectionText(section.ID)
+			text = strings.TrimSpace(text)
+			if text == "" {
+				continue
+			}
+			if section.Field == "toy_example_code" {
+				if strings.EqualFold(text, "N/A") {
+					continue
+				}
+				slides = append(slides, Slide{Title: section.Title, Code: text})
+			} else {
+				slides = append(slides, Slide{Title: section.Title, Body: text})
+			}
+		}
+	} else if saved.Result.Lesson != "" {
+		slides = append(slides, Slide{Title: "Lesson", Body: saved.Result.Lesson})
+	}
+
+	// Images are keyed by URL; sort for a stable slide order
+	urls := make([]string, 0, len(saved.Result.Images))
+	for url := range saved.Result.Images {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for i, url := range urls {
+		caption := saved.Result.Images[url]
+		slides = append(slides, Slide{
+			Title:    fmt.Sprintf("Visual %d", i+1),
+			ImageURL: url,
+			Caption:  caption,
+		})
+	}
+
+	return slides
+}
+
+// paragraphs splits text into paragraphs on blank lines
+func paragraphs(text string) []string {
+	var result []string
+	for _, p := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// renderRevealJS renders slides as a standalone reveal.js HTML document
+func renderRevealJS(title string, slides []Slide) string {
+	var b strings.Builder
+	cdn := "https://cdn.jsdelivr.net/npm/reveal.js@" + revealJSVersion
+
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\">\n")
+	b.WriteString(fmt.Sprintf("<title>%s</title>\n", html.EscapeString(title)))
+	b.WriteString(fmt.Sprintf("<link rel=\"stylesheet\" href=\"%s/dist/reveal.css\">\n", cdn))
+	b.WriteString(fmt.Sprintf("<link rel=\"stylesheet\" href=\"%s/dist/theme/white.css\">\n", cdn))
+	b.WriteString(fmt.Sprintf("<link rel=\"stylesheet\" href=\"%s/plugin/highlight/monokai.css\">\n", cdn))
+	b.WriteString("</head>\n<body>\n<div class=\"reveal\">\n<div class=\"slides\">\n")
+
+	for _, slide := range slides {
+		b.WriteString("<section>\n")
+		b.WriteString(fmt.Sprintf("<h2>%s</h2>\n", html.EscapeString(slide.Title)))
+		if len(slide.Bullets) > 0 {
+			b.WriteString("<ul>\n")
+			for _, bullet := range slide.Bullets {
+				b.WriteString(fmt.Sprintf("<li>%s</li>\n", html.EscapeString(bullet)))
+			}
+			b.WriteString("</ul>\n")
+		}
+		for _, p := range paragraphs(slide.Body) {
+			b.WriteString(fmt.Sprintf("<p>%s</p>\n", html.EscapeString(p)))
+		}
+		if slide.Code != "" {
+			b.WriteString(fmt.Sprintf("<pre><code data-trim>%s</code></pre>\n", html.EscapeString(slide.Code)))
+		}
+		if slide.ImageURL != "" {
+			b.WriteString(fmt.Sprintf("<img src=\"%s\" alt=\"%s\">\n", html.EscapeString(slide.ImageURL), html.EscapeString(slide.Caption)))
+			if slide.Caption != "" {
+				b.WriteString(fmt.Sprintf("<p><small>%s</small></p>\n", html.EscapeString(slide.Caption)))
+			}
+		}
+		b.WriteString("</section>\n")
+	}
+
+	b.WriteString("</div>\n</div>\n")
+	b.WriteString(fmt.Sprintf("<script src=\"%s/dist/reveal.js\"></script>\n", cdn))
+	b.WriteString(fmt.Sprintf("<script src=\"%s/plugin/highlight/highlight.js\"></script>\n", cdn))
+	b.WriteString("<script>Reveal.initialize({hash: true, plugins: [RevealHighlight]});</script>\n")
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// slidesFilename returns the download filename for a deck
+func slidesFilename(saved *SavedLesson, ext string) string {
+	name := llm.Slugify(saved.Title)
+	if name == "" {
+		name = llm.Slugify(saved.Topic)
+	}
+	if name == "" {
+		name = "lesson"
+	}
+	return name + "-slides." + ext
+}
+
+// getSavedLessonSlidesHandler handles GET /api/saved/{userID}/{id}/slides?format=revealjs|pptx
+func (o *Orchestrator) getSavedLessonSlidesHandler(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	savedID := chi.URLParam(r, "id")
+
+	o.mu.RLock()
+	savedLesson, exists := o.savedLessons[savedID]
+	o.mu.RUnlock()
+
+	if !exists || savedLesson.isDeleted() || savedLesson.UserID != userID {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Saved lesson not found",
+			"message": "Saved lesson not found",
+		})
+		return
+	}
+
+	slides := buildSlideDeck(savedLesson)
+	title := slides[0].Title
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "revealjs", "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", slidesFilename(savedLesson, "html")))
+		w.Write([]byte(renderRevealJS(title, slides)))
+	case "pptx":
+		deck, err := renderPPTX(title, slides)
+		if err != nil {
+			o.logger.WithFields(logrus.Fields{
+				"saved_id": savedID,
+				"error":    err,
+			}).Error("Failed to render PPTX deck")
+			http.Error(w, "Failed to render slides", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", pptxContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", slidesFilename(savedLesson, "pptx")))
+		w.Write(deck)
+	default:
+		http.Error(w, fmt.Sprintf("Unsupported slides format: %s", format), http.StatusBadRequest)
+	}
+}
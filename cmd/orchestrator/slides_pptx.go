@@ -0,0 +1,283 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// OOXML namespaces used by the PPTX writer
+const (
+	nsDrawingML     = "http://schemas.openxmlformats.org/drawingml/2006/main"
+	nsPresentation  = "http://schemas.openxmlformats.org/presentationml/2006/main"
+	nsRelationships = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsPackageRels   = "http://schemas.openxmlformats.org/package/2006/relationships"
+	relTypeBase     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/"
+)
+
+// Slide geometry in EMUs (16:9, 13.333in x 7.5in)
+const (
+	pptxSlideWidth  = 12192000
+	pptxSlideHeight = 6858000
+	pptxMargin      = 457200
+	pptxTitleHeight = 1143000
+)
+
+// renderPPTX renders slides as a PowerPoint (PPTX) package.
+// Images are inserted as linked pictures so the deck does not need to download them.
+func renderPPTX(title string, slides []Slide) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", pptxContentTypes(len(slides))},
+		{"_rels/.rels", pptxRels([]pptxRel{{ID: "rId1", Type: "officeDocument", Target: "ppt/presentation.xml"}})},
+		{"docProps/core.xml", pptxCoreProps(title)},
+		{"ppt/presentation.xml", pptxPresentation(len(slides))},
+		{"ppt/_rels/presentation.xml.rels", pptxPresentationRels(len(slides))},
+		{"ppt/slideMasters/slideMaster1.xml", pptxSlideMaster},
+		{"ppt/slideMasters/_rels/slideMaster1.xml.rels", pptxRels([]pptxRel{
+			{ID: "rId1", Type: "slideLayout", Target: "../slideLayouts/slideLayout1.xml"},
+			{ID: "rId2", Type: "theme", Target: "../theme/theme1.xml"},
+		})},
+		{"ppt/slideLayouts/slideLayout1.xml", pptxSlideLayout},
+		{"ppt/slideLayouts/_rels/slideLayout1.xml.rels", pptxRels([]pptxRel{
+			{ID: "rId1", Type: "slideMaster", Target: "../slideMasters/slideMaster1.xml"},
+		})},
+		{"ppt/theme/theme1.xml", pptxTheme},
+	}
+
+	for i, slide := range slides {
+		rels := []pptxRel{{ID: "rId1", Type: "slideLayout", Target: "../slideLayouts/slideLayout1.xml"}}
+		if slide.ImageURL != "" {
+			rels = append(rels, pptxRel{ID: "rId2", Type: "image", Target: slide.ImageURL, External: true})
+		}
+		files = append(files,
+			struct{ name, content string }{fmt.Sprintf("ppt/slides/slide%d.xml", i+1), pptxSlide(slide)},
+			struct{ name, content string }{fmt.Sprintf("ppt/slides/_rels/slide%d.xml.rels", i+1), pptxRels(rels)},
+		)
+	}
+
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", f.name, err)
+		}
+		if _, err := w.Write([]byte(f.content)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize pptx: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// pptxRel represents a package relationship
+type pptxRel struct {
+	ID       string
+	Type     string // Relationship type suffix, e.g. "slide"
+	Target   string
+	External bool
+}
+
+// xmlEscape escapes text for use in XML content and attributes
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// pptxRels renders a relationships part
+func pptxRels(rels []pptxRel) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(fmt.Sprintf("<Relationships xmlns=\"%s\">", nsPackageRels))
+	for _, rel := range rels {
+		mode := ""
+		if rel.External {
+			mode = ` TargetMode="External"`
+		}
+		b.WriteString(fmt.Sprintf("<Relationship Id=\"%s\" Type=\"%s%s\" Target=\"%s\"%s/>", rel.ID, relTypeBase, rel.Type, xmlEscape(rel.Target), mode))
+	}
+	b.WriteString("</Relationships>")
+	return b.String()
+}
+
+// pptxContentTypes renders [Content_Types].xml
+func pptxContentTypes(slideCount int) string {
+	const ct = "application/vnd.openxmlformats-officedocument."
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>`)
+	b.WriteString(`<Override PartName="/ppt/presentation.xml" ContentType="` + ct + `presentationml.presentation.main+xml"/>`)
+	b.WriteString(`<Override PartName="/ppt/slideMasters/slideMaster1.xml" ContentType="` + ct + `presentationml.slideMaster+xml"/>`)
+	b.WriteString(`<Override PartName="/ppt/slideLayouts/slideLayout1.xml" ContentType="` + ct + `presentationml.slideLayout+xml"/>`)
+	b.WriteString(`<Override PartName="/ppt/theme/theme1.xml" ContentType="` + ct + `theme+xml"/>`)
+	for i := 1; i <= slideCount; i++ {
+		b.WriteString(fmt.Sprintf(`<Override PartName="/ppt/slides/slide%d.xml" ContentType="%spresentationml.slide+xml"/>`, i, ct))
+	}
+	b.WriteString("</Types>")
+	return b.String()
+}
+
+// pptxCoreProps renders the document core properties
+func pptxCoreProps(title string) string {
+	return xml.Header +
+		`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+		"<dc:title>" + xmlEscape(title) + "</dc:title><dc:creator>ExplainIQ</dc:creator></cp:coreProperties>"
+}
+
+// pptxPresentation renders ppt/presentation.xml
+func pptxPresentation(slideCount int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(fmt.Sprintf(`<p:presentation xmlns:a="%s" xmlns:r="%s" xmlns:p="%s">`, nsDrawingML, nsRelationships, nsPresentation))
+	b.WriteString(`<p:sldMasterIdLst><p:sldMasterId id="2147483648" r:id="rId1"/></p:sldMasterIdLst>`)
+	b.WriteString("<p:sldIdLst>")
+	for i := 0; i < slideCount; i++ {
+		b.WriteString(fmt.Sprintf(`<p:sldId id="%d" r:id="rId%d"/>`, 256+i, i+3))
+	}
+	b.WriteString("</p:sldIdLst>")
+	b.WriteString(fmt.Sprintf(`<p:sldSz cx="%d" cy="%d"/><p:notesSz cx="6858000" cy="9144000"/>`, pptxSlideWidth, pptxSlideHeight))
+	b.WriteString("</p:presentation>")
+	return b.String()
+}
+
+// pptxPresentationRels renders the presentation relationships; slides start at rId3
+func pptxPresentationRels(slideCount int) string {
+	rels := []pptxRel{
+		{ID: "rId1", Type: "slideMaster", Target: "slideMasters/slideMaster1.xml"},
+		{ID: "rId2", Type: "theme", Target: "theme/theme1.xml"},
+	}
+	for i := 1; i <= slideCount; i++ {
+		rels = append(rels, pptxRel{ID: fmt.Sprintf("rId%d", i+2), Type: "slide", Target: fmt.Sprintf("slides/slide%d.xml", i)})
+	}
+	return pptxRels(rels)
+}
+
+// pptxParagraph is one paragraph in a text box
+type pptxParagraph struct {
+	Text   string
+	Size   int // Hundredths of a point
+	Bold   bool
+	Bullet bool
+	Mono   bool
+}
+
+// pptxTextBox renders a text box shape
+func pptxTextBox(id int, name string, x, y, cx, cy int, paragraphs []pptxParagraph) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(`<p:sp><p:nvSpPr><p:cNvPr id="%d" name="%s"/><p:cNvSpPr txBox="1"/><p:nvPr/></p:nvSpPr>`, id, name))
+	b.WriteString(fmt.Sprintf(`<p:spPr><a:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr>`, x, y, cx, cy))
+	b.WriteString(`<p:txBody><a:bodyPr wrap="square"><a:normAutofit/></a:bodyPr><a:lstStyle/>`)
+	for _, p := range paragraphs {
+		b.WriteString("<a:p>")
+		if p.Bullet {
+			b.WriteString(`<a:pPr marL="342900" indent="-342900"><a:buChar char="&#8226;"/></a:pPr>`)
+		}
+		bold := ""
+		if p.Bold {
+			bold = ` b="1"`
+		}
+		b.WriteString(fmt.Sprintf(`<a:r><a:rPr lang="en-US" sz="%d"%s dirty="0">`, p.Size, bold))
+		if p.Mono {
+			b.WriteString(`<a:latin typeface="Courier New"/>`)
+		}
+		b.WriteString("</a:rPr><a:t>" + xmlEscape(p.Text) + "</a:t></a:r></a:p>")
+	}
+	b.WriteString("</p:txBody></p:sp>")
+	return b.String()
+}
+
+// pptxSlide renders a slide part
+func pptxSlide(slide Slide) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(fmt.Sprintf(`<p:sld xmlns:a="%s" xmlns:r="%s" xmlns:p="%s"><p:cSld><p:spTree>`, nsDrawingML, nsRelationships, nsPresentation))
+	b.WriteString(`<p:nvGrpSpPr><p:cNvPr id="1" name=""/><p:cNvGrpSpPr/><p:nvPr/></p:nvGrpSpPr><p:grpSpPr/>`)
+
+	contentWidth := pptxSlideWidth - 2*pptxMargin
+	bodyTop := pptxMargin + pptxTitleHeight
+	bodyHeight := pptxSlideHeight - bodyTop - pptxMargin
+
+	b.WriteString(pptxTextBox(2, "Title", pptxMargin, pptxMargin, contentWidth, pptxTitleHeight,
+		[]pptxParagraph{{Text: slide.Title, Size: 3600, Bold: true}}))
+
+	var body []pptxParagraph
+	for _, bullet := range slide.Bullets {
+		body = append(body, pptxParagraph{Text: bullet, Size: 2000, Bullet: true})
+	}
+	for _, p := range paragraphs(slide.Body) {
+		body = append(body, pptxParagraph{Text: p, Size: 1800})
+	}
+	if slide.Code != "" {
+		for _, line := range strings.Split(slide.Code, "\n") {
+			body = append(body, pptxParagraph{Text: line, Size: 1400, Mono: true})
+		}
+	}
+
+	if slide.ImageURL != "" {
+		captionHeight := 457200
+		b.WriteString(`<p:pic><p:nvPicPr><p:cNvPr id="3" name="Image"`)
+		if slide.Caption != "" {
+			b.WriteString(` descr="` + xmlEscape(slide.Caption) + `"`)
+		}
+		b.WriteString(`/><p:cNvPicPr><a:picLocks noChangeAspect="1"/></p:cNvPicPr><p:nvPr/></p:nvPicPr>`)
+		b.WriteString(`<p:blipFill><a:blip r:link="rId2"/><a:stretch><a:fillRect/></a:stretch></p:blipFill>`)
+		b.WriteString(fmt.Sprintf(`<p:spPr><a:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr></p:pic>`,
+			pptxMargin, bodyTop, contentWidth, bodyHeight-captionHeight))
+		if slide.Caption != "" {
+			b.WriteString(pptxTextBox(4, "Caption", pptxMargin, pptxSlideHeight-pptxMargin-captionHeight, contentWidth, captionHeight,
+				[]pptxParagraph{{Text: slide.Caption, Size: 1400}}))
+		}
+	} else if len(body) > 0 {
+		b.WriteString(pptxTextBox(3, "Body", pptxMargin, bodyTop, contentWidth, bodyHeight, body))
+	}
+
+	b.WriteString(`</p:spTree></p:cSld><p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr></p:sld>`)
+	return b.String()
+}
+
+// pptxSlideMaster is a minimal slide master with no placeholders
+var pptxSlideMaster = xml.Header + `<p:sldMaster xmlns:a="` + nsDrawingML + `" xmlns:r="` + nsRelationships + `" xmlns:p="` + nsPresentation + `">` +
+	`<p:cSld><p:spTree><p:nvGrpSpPr><p:cNvPr id="1" name=""/><p:cNvGrpSpPr/><p:nvPr/></p:nvGrpSpPr><p:grpSpPr/></p:spTree></p:cSld>` +
+	`<p:clrMap bg1="lt1" tx1="dk1" bg2="lt2" tx2="dk2" accent1="accent1" accent2="accent2" accent3="accent3" accent4="accent4" accent5="accent5" accent6="accent6" hlink="hlink" folHlink="folHlink"/>` +
+	`<p:sldLayoutIdLst><p:sldLayoutId id="2147483649" r:id="rId1"/></p:sldLayoutIdLst>` +
+	`</p:sldMaster>`
+
+// pptxSlideLayout is a blank slide layout
+var pptxSlideLayout = xml.Header + `<p:sldLayout xmlns:a="` + nsDrawingML + `" xmlns:r="` + nsRelationships + `" xmlns:p="` + nsPresentation + `" type="blank" preserve="1">` +
+	`<p:cSld name="Blank"><p:spTree><p:nvGrpSpPr><p:cNvPr id="1" name=""/><p:cNvGrpSpPr/><p:nvPr/></p:nvGrpSpPr><p:grpSpPr/></p:spTree></p:cSld>` +
+	`<p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr></p:sldLayout>`
+
+// pptxTheme is a minimal Office theme
+var pptxTheme = xml.Header + `<a:theme xmlns:a="` + nsDrawingML + `" name="ExplainIQ"><a:themeElements>` +
+	`<a:clrScheme name="ExplainIQ">` +
+	`<a:dk1><a:srgbClr val="1F2937"/></a:dk1><a:lt1><a:srgbClr val="FFFFFF"/></a:lt1>` +
+	`<a:dk2><a:srgbClr val="111827"/></a:dk2><a:lt2><a:srgbClr val="F3F4F6"/></a:lt2>` +
+	`<a:accent1><a:srgbClr val="2563EB"/></a:accent1><a:accent2><a:srgbClr val="7C3AED"/></a:accent2>` +
+	`<a:accent3><a:srgbClr val="059669"/></a:accent3><a:accent4><a:srgbClr val="D97706"/></a:accent4>` +
+	`<a:accent5><a:srgbClr val="DC2626"/></a:accent5><a:accent6><a:srgbClr val="0891B2"/></a:accent6>` +
+	`<a:hlink><a:srgbClr val="2563EB"/></a:hlink><a:folHlink><a:srgbClr val="7C3AED"/></a:folHlink>` +
+	`</a:clrScheme>` +
+	`<a:fontScheme name="ExplainIQ">` +
+	`<a:majorFont><a:latin typeface="Calibri"/><a:ea typeface=""/><a:cs typeface=""/></a:majorFont>` +
+	`<a:minorFont><a:latin typeface="Calibri"/><a:ea typeface=""/><a:cs typeface=""/></a:minorFont>` +
+	`</a:fontScheme>` +
+	`<a:fmtScheme name="ExplainIQ">` +
+	`<a:fillStyleLst><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:fillStyleLst>` +
+	`<a:lnStyleLst><a:ln w="6350"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln><a:ln w="12700"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln><a:ln w="19050"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln></a:lnStyleLst>` +
+	`<a:effectStyleLst><a:effectStyle><a:effectLst/></a:effectStyle><a:effectStyle><a:effectLst/></a:effectStyle><a:effectStyle><a:effectLst/></a:effectStyle></a:effectStyleLst>` +
+	`<a:bgFillStyleLst><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:bgFillStyleLst>` +
+	`</a:fmtScheme>` +
+	`</a:themeElements></a:theme>`
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSlidesTestOrchestrator creates an orchestrator with one saved lesson and the slides route
+func newSlidesTestOrchestrator(t *testing.T) (*Orchestrator, chi.Router) {
+	lessonJSON, err := json.Marshal(llm.OGLesson{
+		BigPicture:     "Goroutines are lightweight threads.\n\nThey are cheap to start.",
+		Metaphor:       "Like waiters in a restaurant <busy> & quick",
+		ToyExampleCode: "go func() {\n\tfmt.Println(\"hi\")\n}()",
+		BestPractices:  "Always know how a goroutine will stop.",
+	})
+	require.NoError(t, err)
+
+	o := &Orchestrator{
+		sessions: make(map[string]*Session),
+		savedLessons: map[string]*SavedLesson{
+			"l1": {
+				ID:     "l1",
+				UserID: "u1",
+				Topic:  "Goroutines",
+				Title:  "Intro to Goroutines",
+				Result: &SessionResult{
+					Lesson:  string(lessonJSON),
+					Outline: []string{"What", "Why"},
+					Images:  map[string]string{"https://example.com/a.png": "Scheduler diagram"},
+				},
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			},
+		},
+		logger:  logrus.New(),
+		clients: make(map[string][]chan SSEEvent),
+	}
+
+	r := chi.NewRouter()
+	r.Get("/api/saved/{userID}/{id}/slides", o.getSavedLessonSlidesHandler)
+	return o, r
+}
+
+// TestBuildSlideDeck tests the slide order: title, outline, sections, images
+func TestBuildSlideDeck(t *testing.T) {
+	o, _ := newSlidesTestOrchestrator(t)
+	slides := buildSlideDeck(o.savedLessons["l1"])
+
+	titles := make([]string, len(slides))
+	for i, s := range slides {
+		titles[i] = s.Title
+	}
+	assert.Equal(t, []string{"Intro to Goroutines", "Outline", "Big Picture", "Metaphor", "Toy Example", "Best Practices", "Visual 1"}, titles)
+	assert.Equal(t, "https://example.com/a.png", slides[6].ImageURL)
+	assert.NotEmpty(t, slides[4].Code)
+}
+
+// TestRevealJSSlidesExport tests the reveal.js HTML export
+func TestRevealJSSlidesExport(t *testing.T) {
+	_, router := newSlidesTestOrchestrator(t)
+
+	w := serve(router, "GET", "/api/saved/u1/l1/slides")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "intro-to-goroutines-slides.html")
+
+	body := w.Body.String()
+	assert.Equal(t, 7, strings.Count(body, "<section>"))
+	assert.Contains(t, body, "Reveal.initialize")
+	assert.Contains(t, body, "&lt;busy&gt; &amp; quick")
+	assert.Contains(t, body, "<p>They are cheap to start.</p>")
+}
+
+// TestPPTXSlidesExport tests that the PPTX export is a well-formed package
+func TestPPTXSlidesExport(t *testing.T) {
+	_, router := newSlidesTestOrchestrator(t)
+
+	w := serve(router, "GET", "/api/saved/u1/l1/slides?format=pptx")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, pptxContentType, w.Header().Get("Content-Type"))
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		parts[f.Name] = string(data)
+
+		// Every part must be well-formed XML
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else {
+				require.NoError(t, err, f.Name)
+			}
+		}
+	}
+
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts, "ppt/presentation.xml")
+	assert.Contains(t, parts, "ppt/slides/slide7.xml")
+	assert.NotContains(t, parts, "ppt/slides/slide8.xml")
+	assert.Equal(t, 7, strings.Count(parts["ppt/presentation.xml"], "<p:sldId "))
+	assert.Contains(t, parts["ppt/slides/_rels/slide7.xml.rels"], `TargetMode="External"`)
+	assert.Contains(t, parts["ppt/slides/slide5.xml"], "Courier New")
+}
+
+// TestSlidesExportErrors tests access control and unsupported formats
+func TestSlidesExportErrors(t *testing.T) {
+	_, router := newSlidesTestOrchestrator(t)
+
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/saved/u2/l1/slides").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/saved/u1/missing/slides").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(router, "GET", "/api/saved/u1/l1/slides?format=keynote").Code)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultSSEEvictAfterDrops is how many consecutive events a client may miss before it is evicted
+	defaultSSEEvictAfterDrops = 64
+	// eventsDroppedType marks a gap in a client's event stream
+	eventsDroppedType = "events-dropped"
+	// streamEvictedType is the terminal event sent to an evicted client
+	streamEvictedType = "stream-evicted"
+)
+
+// sseEvictAfterDropsFromEnv returns the slow-client eviction threshold (SSE_EVICT_AFTER_DROPS)
+func sseEvictAfterDropsFromEnv() int {
+	if v := os.Getenv("SSE_EVICT_AFTER_DROPS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		logrus.WithField("value", v).Warn("Invalid SSE_EVICT_AFTER_DROPS, using default")
+	}
+	return defaultSSEEvictAfterDrops
+}
+
+// sseClientStats tracks the events a client has missed because its channel was full
+type sseClientStats struct {
+	dropped     int // Events dropped over the client's lifetime
+	pending     int // Events dropped since the last events-dropped marker
+	consecutive int // Events dropped since the client last accepted one
+	evicted     bool
+
+	sessionID string // Set for streams subject to the connection limits
+	user      string
+	limited   string // Why the stream was closed for a newer one, if it was
+}
+
+// clientStatsLocked returns the stats for a client, creating them on first use; the caller must hold clientsMu
+func (o *Orchestrator) clientStatsLocked(client chan SSEEvent) *sseClientStats {
+	if o.clientStats == nil {
+		o.clientStats = make(map[chan SSEEvent]*sseClientStats)
+	}
+	stats, exists := o.clientStats[client]
+	if !exists {
+		stats = &sseClientStats{}
+		o.clientStats[client] = stats
+	}
+	return stats
+}
+
+// deliverLocked sends an event to one client without blocking. A client that fell
+// behind first receives an events-dropped marker so it knows its timeline has a gap;
+// a client that keeps missing events is evicted. The caller must hold clientsMu.
+func (o *Orchestrator) deliverLocked(sessionID string, client chan SSEEvent, event SSEEvent) {
+	stats := o.clientStatsLocked(client)
+	if stats.evicted {
+		return
+	}
+
+	if stats.pending > 0 {
+		marker := SSEEvent{
+			Type:      eventsDroppedType,
+			SessionID: sessionID,
+			Data: map[string]interface{}{
+				"dropped":       stats.pending,
+				"total_dropped": stats.dropped,
+			},
+			Timestamp: time.Now(),
+		}
+		select {
+		case client <- marker:
+			stats.pending = 0
+			stats.consecutive = 0
+		default:
+			o.dropLocked(sessionID, client, stats)
+			return
+		}
+	}
+
+	select {
+	case client <- event:
+		stats.consecutive = 0
+	default:
+		o.dropLocked(sessionID, client, stats)
+	}
+}
+
+// dropLocked records a dropped event and evicts the client once it is stuck beyond
+// the threshold. Eviction closes the client's channel, which tells its handler to
+// send a terminal error event. The caller must hold clientsMu.
+func (o *Orchestrator) dropLocked(sessionID string, client chan SSEEvent, stats *sseClientStats) {
+	stats.dropped++
+	stats.pending++
+	stats.consecutive++
+
+	limit := o.sseEvictAfter
+	if limit <= 0 {
+		limit = defaultSSEEvictAfterDrops
+	}
+	if stats.consecutive < limit {
+		return
+	}
+
+	o.evictClientLocked(sessionID, client, stats)
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id":     sessionID,
+		"dropped_events": stats.dropped,
+	}).Warn("Evicted slow SSE client")
+}
+
+// evictClientLocked unsubscribes a client and closes its channel, which tells its handler to
+// send a terminal event. The caller must hold clientsMu.
+func (o *Orchestrator) evictClientLocked(sessionID string, client chan SSEEvent, stats *sseClientStats) {
+	stats.evicted = true
+	clients := o.clients[sessionID]
+	for i, c := range clients {
+		if c == client {
+			o.clients[sessionID] = append(clients[:i], clients[i+1:]...)
+			break
+		}
+	}
+	o.removeUserStreamLocked(client, stats)
+	close(client)
+}
+
+// clientDroppedEvents returns how many events a client has missed
+func (o *Orchestrator) clientDroppedEvents(client chan SSEEvent) int {
+	o.clientsMu.RLock()
+	defer o.clientsMu.RUnlock()
+
+	if stats, exists := o.clientStats[client]; exists {
+		return stats.dropped
+	}
+	return 0
+}
+
+// evictedEvent builds the terminal event written to a client that was evicted for falling behind
+func evictedEvent(sessionID string, dropped int) SSEEvent {
+	return SSEEvent{
+		Type:      streamEvictedType,
+		SessionID: sessionID,
+		Data: map[string]interface{}{
+			"error":          "Client fell too far behind and was disconnected",
+			"dropped_events": dropped,
+			"result_url":     "/api/sessions/" + sessionID + "/result",
+		},
+		Timestamp: time.Now(),
+	}
+}
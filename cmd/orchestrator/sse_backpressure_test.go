@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBroadcastTestOrchestrator creates an orchestrator for broadcast tests
+func newBroadcastTestOrchestrator(evictAfter int) *Orchestrator {
+	return &Orchestrator{
+		sessions:      make(map[string]*Session),
+		logger:        logrus.New(),
+		clients:       make(map[string][]chan SSEEvent),
+		sseEvictAfter: evictAfter,
+	}
+}
+
+// TestBroadcastEventMarksDroppedEvents tests that a slow client learns how many events it missed
+func TestBroadcastEventMarksDroppedEvents(t *testing.T) {
+	o := newBroadcastTestOrchestrator(10)
+	client := make(chan SSEEvent, 2)
+	o.AddClient("s1", client)
+
+	for _, eventType := range []string{"step-start", "step-delta", "step-delta", "step-delta"} {
+		o.BroadcastEvent("s1", SSEEvent{Type: eventType, SessionID: "s1"})
+	}
+	assert.Equal(t, 2, o.clientDroppedEvents(client))
+
+	// The client catches up; the next event is preceded by a gap marker
+	<-client
+	<-client
+	o.BroadcastEvent("s1", SSEEvent{Type: "step-complete", SessionID: "s1"})
+
+	marker := <-client
+	assert.Equal(t, eventsDroppedType, marker.Type)
+	assert.Equal(t, 2, marker.Data["dropped"])
+	assert.Equal(t, "step-complete", (<-client).Type)
+
+	o.RemoveClient("s1", client)
+	assert.Empty(t, o.clientStats)
+}
+
+// TestBroadcastEventEvictsStuckClient tests that a client stuck beyond the threshold is evicted
+func TestBroadcastEventEvictsStuckClient(t *testing.T) {
+	o := newBroadcastTestOrchestrator(3)
+	stuck := make(chan SSEEvent, 1)
+	healthy := make(chan SSEEvent, 10)
+	o.AddClient("s1", stuck)
+	o.AddClient("s1", healthy)
+
+	for i := 0; i < 5; i++ {
+		o.BroadcastEvent("s1", SSEEvent{Type: "step-delta", SessionID: "s1"})
+	}
+
+	// The stuck client was removed and its channel closed after the buffered event
+	o.clientsMu.RLock()
+	remaining := o.clients["s1"]
+	o.clientsMu.RUnlock()
+	require.Len(t, remaining, 1)
+	assert.Equal(t, healthy, remaining[0])
+
+	_, ok := <-stuck
+	assert.True(t, ok)
+	_, ok = <-stuck
+	assert.False(t, ok)
+	assert.Equal(t, 3, o.clientDroppedEvents(stuck))
+	assert.Len(t, healthy, 5)
+
+	event := evictedEvent("s1", o.clientDroppedEvents(stuck))
+	assert.Equal(t, streamEvictedType, event.Type)
+	assert.Equal(t, 3, event.Data["dropped_events"])
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultSSEMaxPerSession is how many event streams may be open for one session
+	defaultSSEMaxPerSession = 5
+	// defaultSSEMaxPerUser is how many event streams one user may have open across sessions
+	defaultSSEMaxPerUser = 20
+	// streamLimitType is the terminal event sent to a stream closed to make room for a newer one
+	streamLimitType = "stream-limit"
+)
+
+// Reasons a stream was closed to make room for a newer one
+const (
+	streamLimitSession = "session_limit"
+	streamLimitUser    = "user_limit"
+)
+
+// sseStreamLimitFromEnv reads a stream limit from the environment; 0 disables the limit
+func sseStreamLimitFromEnv(name string, fallback int) int {
+	if v := os.Getenv(name); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+		logrus.WithField("value", v).Warn("Invalid " + name + ", using default")
+	}
+	return fallback
+}
+
+// AddSessionClient adds a user's client to receive SSE events for a session. When the session
+// or the user is at their stream limit, their oldest stream is closed to make room.
+func (o *Orchestrator) AddSessionClient(sessionID, user string, client chan SSEEvent) {
+	o.clientsMu.Lock()
+	defer o.clientsMu.Unlock()
+
+	if limit := o.sseMaxPerSession; limit > 0 {
+		for len(o.clients[sessionID]) >= limit {
+			o.limitClientLocked(sessionID, o.clients[sessionID][0], streamLimitSession)
+		}
+	}
+	if limit := o.sseMaxPerUser; limit > 0 && user != "" {
+		for len(o.userStreams[user]) >= limit {
+			oldest := o.userStreams[user][0]
+			o.limitClientLocked(o.clientStatsLocked(oldest).sessionID, oldest, streamLimitUser)
+		}
+	}
+
+	stats := o.clientStatsLocked(client)
+	stats.sessionID = sessionID
+	stats.user = user
+	o.clients[sessionID] = append(o.clients[sessionID], client)
+	if user != "" {
+		if o.userStreams == nil {
+			o.userStreams = make(map[string][]chan SSEEvent)
+		}
+		o.userStreams[user] = append(o.userStreams[user], client)
+	}
+}
+
+// limitClientLocked closes a stream to keep its session or user within the stream limit;
+// the caller must hold clientsMu
+func (o *Orchestrator) limitClientLocked(sessionID string, client chan SSEEvent, reason string) {
+	stats := o.clientStatsLocked(client)
+	stats.limited = reason
+	o.evictClientLocked(sessionID, client, stats)
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"user":       stats.user,
+		"reason":     reason,
+	}).Info("Closed oldest SSE stream over the connection limit")
+}
+
+// removeUserStreamLocked forgets a client in its user's stream list; the caller must hold clientsMu
+func (o *Orchestrator) removeUserStreamLocked(client chan SSEEvent, stats *sseClientStats) {
+	if stats == nil || stats.user == "" {
+		return
+	}
+	streams := o.userStreams[stats.user]
+	for i, c := range streams {
+		if c == client {
+			streams = append(streams[:i], streams[i+1:]...)
+			break
+		}
+	}
+	if len(streams) == 0 {
+		delete(o.userStreams, stats.user)
+	} else {
+		o.userStreams[stats.user] = streams
+	}
+}
+
+// closedClientEvent builds the terminal event for a client whose channel the orchestrator closed:
+// either it fell too far behind or a newer stream took its place
+func (o *Orchestrator) closedClientEvent(sessionID string, client chan SSEEvent) SSEEvent {
+	o.clientsMu.RLock()
+	var reason string
+	if stats, exists := o.clientStats[client]; exists {
+		reason = stats.limited
+	}
+	o.clientsMu.RUnlock()
+
+	if reason == "" {
+		return evictedEvent(sessionID, o.clientDroppedEvents(client))
+	}
+	limit := o.sseMaxPerSession
+	message := "Too many open streams for this session; this older stream was closed"
+	if reason == streamLimitUser {
+		limit = o.sseMaxPerUser
+		message = "Too many open streams for this user; this older stream was closed"
+	}
+	return SSEEvent{
+		Type:      streamLimitType,
+		SessionID: sessionID,
+		Data: map[string]interface{}{
+			"error":      message,
+			"reason":     reason,
+			"limit":      limit,
+			"status_url": "/api/sessions/" + sessionID + "/status",
+		},
+		Timestamp: time.Now(),
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertClosed asserts that the orchestrator closed a client's channel
+func assertClosed(t *testing.T, client chan SSEEvent) {
+	t.Helper()
+	select {
+	case _, ok := <-client:
+		assert.False(t, ok, "the client's channel is closed")
+	default:
+		t.Fatal("the client's channel is still open")
+	}
+}
+
+// TestSessionStreamLimit tests that a session over its stream limit closes its oldest stream with a limit event
+func TestSessionStreamLimit(t *testing.T) {
+	o := newBroadcastTestOrchestrator(10)
+	o.sseMaxPerSession = 2
+
+	oldest := make(chan SSEEvent, 4)
+	middle := make(chan SSEEvent, 4)
+	newest := make(chan SSEEvent, 4)
+	o.AddSessionClient("s1", "user:alice", oldest)
+	o.AddSessionClient("s1", "user:bob", middle)
+	o.AddSessionClient("s1", "user:carol", newest)
+
+	assertClosed(t, oldest)
+	require.Len(t, o.clients["s1"], 2)
+	assert.Empty(t, o.userStreams["user:alice"])
+
+	event := o.closedClientEvent("s1", oldest)
+	assert.Equal(t, streamLimitType, event.Type)
+	assert.Equal(t, streamLimitSession, event.Data["reason"])
+	assert.Equal(t, 2, event.Data["limit"])
+
+	// Broadcasts skip the closed stream and reach the others
+	o.BroadcastEvent("s1", SSEEvent{Type: "step-start", SessionID: "s1"})
+	assert.Equal(t, "step-start", (<-middle).Type)
+	assert.Equal(t, "step-start", (<-newest).Type)
+	o.RemoveClient("s1", oldest)
+}
+
+// TestUserStreamLimit tests that a user over their stream limit loses their oldest stream on any session
+func TestUserStreamLimit(t *testing.T) {
+	o := newBroadcastTestOrchestrator(10)
+	o.sseMaxPerUser = 2
+
+	first := make(chan SSEEvent, 4)
+	second := make(chan SSEEvent, 4)
+	third := make(chan SSEEvent, 4)
+	other := make(chan SSEEvent, 4)
+	o.AddSessionClient("s1", "user:alice", first)
+	o.AddSessionClient("s2", "user:alice", second)
+	o.AddSessionClient("s1", "user:bob", other)
+	o.AddSessionClient("s3", "user:alice", third)
+
+	assertClosed(t, first)
+	assert.Equal(t, []chan SSEEvent{other}, o.clients["s1"])
+	assert.Equal(t, []chan SSEEvent{second, third}, o.userStreams["user:alice"])
+	assert.Equal(t, streamLimitUser, o.closedClientEvent("s1", first).Data["reason"])
+
+	// Closing a stream frees its place
+	o.RemoveClient("s2", second)
+	o.AddSessionClient("s2", "user:alice", make(chan SSEEvent, 4))
+	assert.Len(t, o.userStreams["user:alice"], 2)
+	assert.Len(t, o.clients["s3"], 1)
+}
+
+// TestSessionEventsCountCaller tests that event streams count against the viewer's principal or
+// IP, not against the user who owns the session
+func TestSessionEventsCountCaller(t *testing.T) {
+	o := newBroadcastTestOrchestrator(10)
+	o.sseMaxPerUser = 1
+	o.sessions["s1"] = &Session{ID: "s1", Topic: "Raft", Status: "running", Metadata: map[string]interface{}{"user_id": "alice"}}
+	router := chi.NewRouter()
+	router.Get("/api/sessions/{id}/events", o.sessionEventsHandler)
+
+	stream := func(principal *auth.Principal) context.CancelFunc {
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest("GET", "/api/sessions/s1/events", nil).WithContext(ctx)
+		req.RemoteAddr = "203.0.113.7:4000"
+		go withPrincipal(router, principal).ServeHTTP(httptest.NewRecorder(), req)
+		return cancel
+	}
+	streams := func(user string) int {
+		o.clientsMu.Lock()
+		defer o.clientsMu.Unlock()
+		return len(o.userStreams[user])
+	}
+
+	defer stream(&auth.Principal{UserID: "alice", Method: auth.MethodJWT})()
+	require.Eventually(t, func() bool { return streams("user:alice") == 1 }, time.Second, time.Millisecond)
+
+	// An anonymous viewer of alice's session neither uses nor displaces her stream
+	defer stream(nil)()
+	require.Eventually(t, func() bool { return streams("ip:203.0.113.7") == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, 1, streams("user:alice"))
+}
+
+// TestStreamLimitsDisabled tests that zero limits allow any number of streams
+func TestStreamLimitsDisabled(t *testing.T) {
+	o := newBroadcastTestOrchestrator(10)
+	for i := 0; i < 50; i++ {
+		o.AddSessionClient("s1", "user:alice", make(chan SSEEvent, 1))
+	}
+	assert.Len(t, o.clients["s1"], 50)
+
+	// Slow clients still get the eviction event rather than a limit event
+	assert.Equal(t, streamEvictedType, o.closedClientEvent("s1", o.clients["s1"][0]).Type)
+}
+
+// TestSSEStreamLimitFromEnv tests reading stream limits from the environment
+func TestSSEStreamLimitFromEnv(t *testing.T) {
+	t.Setenv("SSE_MAX_STREAMS_PER_SESSION", "3")
+	assert.Equal(t, 3, sseStreamLimitFromEnv("SSE_MAX_STREAMS_PER_SESSION", defaultSSEMaxPerSession))
+	t.Setenv("SSE_MAX_STREAMS_PER_SESSION", "0")
+	assert.Equal(t, 0, sseStreamLimitFromEnv("SSE_MAX_STREAMS_PER_SESSION", defaultSSEMaxPerSession))
+	t.Setenv("SSE_MAX_STREAMS_PER_SESSION", "-1")
+	assert.Equal(t, defaultSSEMaxPerSession, sseStreamLimitFromEnv("SSE_MAX_STREAMS_PER_SESSION", defaultSSEMaxPerSession))
+}
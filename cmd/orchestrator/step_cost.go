@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/InnoFusionTech/ExplainIQ/internal/cost_tracker"
+)
+
+// stepCost is the token usage and estimated dollar cost of one pipeline step
+type stepCost struct {
+	InputTokens  int
+	OutputTokens int
+	Images       int
+	CostUSD      float64
+}
+
+// metricInt reads an integer metric; metrics from remote agents arrive as JSON numbers
+func metricInt(metrics map[string]interface{}, key string) int {
+	switch v := metrics[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// stepCostFromMetrics estimates a step's cost from the usage its agent reported.
+// Tokens are priced for the model that served the step.
+func stepCostFromMetrics(metrics map[string]interface{}) stepCost {
+	model, _ := metrics["model"].(string)
+	cost := stepCost{
+		InputTokens:  metricInt(metrics, "input_tokens"),
+		OutputTokens: metricInt(metrics, "output_tokens"),
+		Images:       metricInt(metrics, "images_count"),
+	}
+	if cost.InputTokens > 0 || cost.OutputTokens > 0 {
+		cost.CostUSD += cost_tracker.EstimateLLMCost(model, cost.InputTokens, cost.OutputTokens)
+	}
+	if cost.Images > 0 {
+		cost.CostUSD += cost_tracker.EstimateImageCost(cost.Images)
+	}
+	return cost
+}
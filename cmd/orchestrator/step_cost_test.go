@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usageAgentClient completes every task and reports fixed usage metrics
+type usageAgentClient struct {
+	metrics map[string]interface{}
+}
+
+// ExecuteTask implements AgentClient
+func (c *usageAgentClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error) {
+	return &adk.TaskResponse{
+		Artifacts: map[string]string{"summary": "Summary", "lesson": `{"title": "Caching"}`},
+		Metrics:   c.metrics,
+	}, nil
+}
+
+// Health implements AgentClient
+func (c *usageAgentClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// TestStepCostFromMetrics tests pricing embedded and JSON-decoded usage metrics
+func TestStepCostFromMetrics(t *testing.T) {
+	cost := stepCostFromMetrics(map[string]interface{}{"model": "gemini-2.5-flash", "input_tokens": 1000, "output_tokens": 1000})
+	assert.Equal(t, 1000, cost.InputTokens)
+	assert.InDelta(t, 0.0028, cost.CostUSD, 1e-9)
+
+	// Remote agents report metrics as JSON numbers
+	cost = stepCostFromMetrics(map[string]interface{}{"input_tokens": float64(500), "output_tokens": float64(0), "images_count": float64(2)})
+	assert.Equal(t, 500, cost.InputTokens)
+	assert.Equal(t, 2, cost.Images)
+	assert.InDelta(t, 0.0405, cost.CostUSD, 1e-9)
+
+	assert.Zero(t, stepCostFromMetrics(nil).CostUSD)
+}
+
+// TestStepCompleteEventCost tests that step_complete events carry step and running session cost
+func TestStepCompleteEventCost(t *testing.T) {
+	agent := &usageAgentClient{metrics: map[string]interface{}{"model": "gemini-2.5-flash", "input_tokens": 1000, "output_tokens": 1000}}
+	config := DefaultPipelineConfig()
+	config.RetryDelay = time.Millisecond
+	p := &Pipeline{
+		config:     config,
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+	session := &Session{ID: "s1", Topic: "Caching", Status: "created", Metadata: map[string]interface{}{}}
+	o := &Orchestrator{
+		sessions: map[string]*Session{"s1": session},
+		logger:   logrus.New(),
+		clients:  make(map[string][]chan SSEEvent),
+	}
+	events := make(chan SSEEvent, 100)
+	o.AddClient("s1", events)
+
+	require.NoError(t, p.runPipeline(context.Background(), "s1", o))
+
+	var costs []map[string]interface{}
+	for len(events) > 0 {
+		event := <-events
+		if event.Type == "step_complete" {
+			costs = append(costs, event.Data)
+		}
+	}
+	require.Len(t, costs, 4)
+	assert.Equal(t, 1000, costs[0]["input_tokens"])
+	assert.InDelta(t, 0.0028, costs[0]["cost_usd"], 1e-9)
+	assert.InDelta(t, 0.0028, costs[0]["session_cost_usd"], 1e-9)
+	assert.InDelta(t, 4*0.0028, costs[3]["session_cost_usd"], 1e-9)
+}
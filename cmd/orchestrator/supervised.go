@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultApprovalTimeout bounds how long a supervised run waits for a reviewer at each step
+const defaultApprovalTimeout = 24 * time.Hour
+
+// errApprovalTimeout is returned when no reviewer decided on a step in time
+var errApprovalTimeout = errors.New("step approval timed out")
+
+// approvalTimeoutFromEnv reads SUPERVISED_APPROVAL_TIMEOUT
+func approvalTimeoutFromEnv() time.Duration {
+	v := os.Getenv("SUPERVISED_APPROVAL_TIMEOUT")
+	if v == "" {
+		return defaultApprovalTimeout
+	}
+	timeout, err := time.ParseDuration(v)
+	if err != nil || timeout <= 0 {
+		logrus.WithField("value", v).Warn("Invalid SUPERVISED_APPROVAL_TIMEOUT, using default")
+		return defaultApprovalTimeout
+	}
+	return timeout
+}
+
+// sessionSupervised reports whether a session's run pauses for approval after each step
+func sessionSupervised(session *Session) bool {
+	supervised, _ := session.Metadata["supervised"].(bool)
+	return supervised
+}
+
+// stepReview is a reviewer's decision on a step's output
+type stepReview struct {
+	approved bool
+	reason   string            // Why the step was rejected
+	edits    map[string]string // Artifacts replaced by the reviewer, by name
+}
+
+// pendingApproval is a supervised run waiting for a decision on one step
+type pendingApproval struct {
+	step     string
+	stepID   string
+	decision chan stepReview
+}
+
+// StepReviewRequest is the body of a step approval or rejection
+type StepReviewRequest struct {
+	Reason string            `json:"reason,omitempty"`
+	Edits  map[string]string `json:"edits,omitempty"` // Replacement artifacts, e.g. {"lesson": "..."}
+}
+
+// awaitStepApproval pauses a supervised run after a completed step until a reviewer approves or
+// rejects it, broadcasting an awaiting-approval event with the step's artifacts. It returns the
+// output the run continues with: the step's own, or the reviewer's edits merged into it.
+func (o *Orchestrator) awaitStepApproval(ctx context.Context, session *Session, index int, stepName string, output map[string]string) (map[string]string, error) {
+	stepID := fmt.Sprintf("step-%d", index+1)
+	pending := &pendingApproval{step: stepName, stepID: stepID, decision: make(chan stepReview, 1)}
+
+	o.mu.Lock()
+	if o.approvals == nil {
+		o.approvals = make(map[string]*pendingApproval)
+	}
+	o.approvals[session.ID] = pending
+	if index < len(session.Steps) {
+		session.Steps[index].Status = "awaiting_approval"
+	}
+	session.UpdatedAt = time.Now()
+	o.mu.Unlock()
+
+	o.BroadcastEvent(session.ID, SSEEvent{
+		Type:      "awaiting-approval",
+		SessionID: session.ID,
+		StepID:    stepID,
+		Data: map[string]interface{}{
+			"session_id": session.ID,
+			"step":       stepName,
+			"artifacts":  output,
+			"approve":    fmt.Sprintf("/api/sessions/%s/steps/%s/approve", session.ID, stepName),
+			"reject":     fmt.Sprintf("/api/sessions/%s/steps/%s/reject", session.ID, stepName),
+			"timestamp":  time.Now().Format(time.RFC3339),
+		},
+		Timestamp: time.Now(),
+	})
+
+	timeout := o.approvalTimeout
+	if timeout <= 0 {
+		timeout = defaultApprovalTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var review stepReview
+	var err error
+	select {
+	case review = <-pending.decision:
+	case <-ctx.Done():
+		err = context.Cause(ctx)
+	case <-timer.C:
+		err = errApprovalTimeout
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.approvals[session.ID] == pending {
+		delete(o.approvals, session.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !review.approved && len(review.edits) == 0 {
+		reason := review.reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		return nil, fmt.Errorf("step %s rejected by reviewer: %s", stepName, reason)
+	}
+
+	decision := "approved"
+	if len(review.edits) > 0 {
+		decision = "edited"
+		edited := make(map[string]string, len(output)+len(review.edits))
+		for name, content := range output {
+			edited[name] = content
+		}
+		for name, content := range review.edits {
+			edited[name] = content
+		}
+		output = edited
+		if session.partialOutputs != nil {
+			session.partialOutputs[stepName] = output
+		}
+	}
+	if index < len(session.Steps) {
+		step := &session.Steps[index]
+		step.Status = "completed"
+		if step.Metadata == nil {
+			step.Metadata = make(map[string]interface{})
+		}
+		step.Metadata["review"] = decision
+		if review.reason != "" {
+			step.Metadata["review_reason"] = review.reason
+		}
+	}
+	session.UpdatedAt = time.Now()
+	return output, nil
+}
+
+// rejectRun fails a supervised run whose step was rejected or never reviewed
+func (p *Pipeline) rejectRun(session *Session, result *PipelineResult, orchestrator *Orchestrator, err error) error {
+	result.Status = "failed"
+	result.Error = err.Error()
+	failure := orchestrator.recordFailure(session, "", result.Error)
+	session.Status = "failed"
+	orchestrator.UpdateSession(session)
+
+	orchestrator.BroadcastEvent(session.ID, SSEEvent{
+		Type:      "session_error",
+		SessionID: session.ID,
+		Data:      failureEventData(session.ID, failure),
+		Timestamp: time.Now(),
+	})
+	return fmt.Errorf("pipeline stopped: %w", err)
+}
+
+// approveStepHandler handles POST /api/sessions/{id}/steps/{step}/approve
+// Edits in the body replace the step's artifacts before the run continues.
+func (o *Orchestrator) approveStepHandler(w http.ResponseWriter, r *http.Request) {
+	o.reviewStep(w, r, true)
+}
+
+// rejectStepHandler handles POST /api/sessions/{id}/steps/{step}/reject
+// A rejection with edits continues the run with the edited artifacts; without edits the run fails.
+func (o *Orchestrator) rejectStepHandler(w http.ResponseWriter, r *http.Request) {
+	o.reviewStep(w, r, false)
+}
+
+// canReviewSession reports whether the caller may review the steps of a session owned by owner.
+// Admins review any session; users review their own. Anonymous callers and unowned sessions are refused.
+func canReviewSession(r *http.Request, owner string) bool {
+	if callerIsAdmin(r) {
+		return true
+	}
+	caller := sessionOwner(r)
+	return caller != "" && caller == owner
+}
+
+// reviewStep delivers a reviewer's decision to the run waiting on the step, named or by ID (e.g. step-2)
+func (o *Orchestrator) reviewStep(w http.ResponseWriter, r *http.Request, approved bool) {
+	sessionID := chi.URLParam(r, "id")
+	stepName := chi.URLParam(r, "step")
+
+	var req StepReviewRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	o.mu.Lock()
+	session, exists := o.sessions[sessionID]
+	var owner string
+	if exists {
+		owner, _ = session.Metadata["user_id"].(string)
+	}
+	allowed := canReviewSession(r, owner)
+	pending := o.approvals[sessionID]
+	if exists && allowed && pending != nil && (pending.step == stepName || pending.stepID == stepName) {
+		delete(o.approvals, sessionID)
+	} else {
+		pending = nil
+	}
+	o.mu.Unlock()
+
+	if !exists {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if !allowed {
+		http.Error(w, "Only the session's owner can review its steps", http.StatusForbidden)
+		return
+	}
+	if pending == nil {
+		http.Error(w, "Step is not awaiting approval", http.StatusConflict)
+		return
+	}
+	pending.decision <- stepReview{approved: approved, reason: req.Reason, edits: req.Edits}
+
+	decision := "approved"
+	if !approved {
+		decision = "rejected"
+	}
+	o.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"step":       pending.step,
+		"decision":   decision,
+		"edited":     len(req.Edits) > 0,
+	}).Info("Supervised step reviewed")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session_id": sessionID,
+		"step":       pending.step,
+		"decision":   decision,
+		"edited":     len(req.Edits) > 0,
+	})
+}
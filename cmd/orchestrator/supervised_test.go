@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// supervisedOwner owns the sessions started by startSupervisedRun
+var supervisedOwner = &auth.Principal{UserID: "u1", Method: auth.MethodJWT}
+
+// startSupervisedRun starts a supervised session owned by supervisedOwner in the background,
+// returning a router serving its owner and the run's result
+func startSupervisedRun(t *testing.T) (*Orchestrator, *Session, http.Handler, chan error) {
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: make(map[string]*SavedLesson),
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+		approvals:    make(map[string]*pendingApproval),
+	}
+	session := o.CreateSession("Caching")
+	session.Metadata["supervised"] = true
+	session.Metadata["user_id"] = supervisedOwner.UserID
+
+	agent := &recordingAgentClient{inputs: make(map[string]map[string]string)}
+	config := DefaultPipelineConfig()
+	config.RetryDelay = time.Millisecond
+	p := &Pipeline{
+		config:     config,
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+
+	router := chi.NewRouter()
+	router.Post("/api/sessions/{id}/steps/{step}/approve", o.approveStepHandler)
+	router.Post("/api/sessions/{id}/steps/{step}/reject", o.rejectStepHandler)
+
+	done := make(chan error, 1)
+	go func() { done <- p.runPipeline(context.Background(), session.ID, o) }()
+	return o, session, withPrincipal(router, supervisedOwner), done
+}
+
+// waitForApproval waits until the session's run is paused at a step
+func waitForApproval(t *testing.T, o *Orchestrator, sessionID, step string) {
+	require.Eventually(t, func() bool {
+		o.mu.RLock()
+		defer o.mu.RUnlock()
+		pending := o.approvals[sessionID]
+		return pending != nil && pending.step == step
+	}, 5*time.Second, time.Millisecond)
+}
+
+// TestSupervisedRunApprovals tests that a supervised run pauses at each step and continues with the reviewer's edits
+func TestSupervisedRunApprovals(t *testing.T) {
+	o, session, router, done := startSupervisedRun(t)
+	base := "/api/sessions/" + session.ID + "/steps/"
+
+	waitForApproval(t, o, session.ID, "summarizer")
+	assert.Equal(t, "awaiting_approval", session.Steps[0].Status)
+	assert.Equal(t, http.StatusConflict, serveWithKey(router, "POST", base+"explainer/approve", "", "").Code)
+	assert.Equal(t, http.StatusAccepted, serveWithKey(router, "POST", base+"step-1/approve", "", "").Code)
+
+	waitForApproval(t, o, session.ID, "explainer")
+	edited := `{"big_picture": "Reviewed: caches keep hot data close"}`
+	w := serveWithKey(router, "POST", base+"explainer/reject", "", `{"reason": "Too vague", "edits": {"lesson": "{\"big_picture\": \"Reviewed: caches keep hot data close\"}"}}`)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	for _, step := range []string{"visualizer", "critic"} {
+		waitForApproval(t, o, session.ID, step)
+		require.Equal(t, http.StatusAccepted, serveWithKey(router, "POST", base+step+"/approve", "", "").Code)
+	}
+	require.NoError(t, <-done)
+
+	session, _ = o.GetSession(session.ID)
+	assert.Equal(t, "completed", session.Status)
+	assert.Equal(t, edited, session.partialOutputs["explainer"]["lesson"])
+	assert.Equal(t, "edited", session.Steps[1].Metadata["review"])
+	assert.Equal(t, "Too vague", session.Steps[1].Metadata["review_reason"])
+	assert.Equal(t, "approved", session.Steps[0].Metadata["review"])
+}
+
+// TestSupervisedReviewNeedsOwner tests that only the session's owner or an admin can review its steps
+func TestSupervisedReviewNeedsOwner(t *testing.T) {
+	o, session, _, done := startSupervisedRun(t)
+	router := chi.NewRouter()
+	router.Post("/api/sessions/{id}/steps/{step}/approve", o.approveStepHandler)
+	router.Post("/api/sessions/{id}/steps/{step}/reject", o.rejectStepHandler)
+	base := "/api/sessions/" + session.ID + "/steps/"
+	edits := `{"edits": {"outline": "[\"Injected\"]"}}`
+
+	waitForApproval(t, o, session.ID, "summarizer")
+	other := withPrincipal(router, &auth.Principal{UserID: "u2", Method: auth.MethodJWT})
+	assert.Equal(t, http.StatusForbidden, serveWithKey(other, "POST", base+"summarizer/reject", "", edits).Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(router, "POST", base+"summarizer/approve", "", edits).Code)
+	waitForApproval(t, o, session.ID, "summarizer")
+
+	admin := withPrincipal(router, &auth.Principal{APIKeyID: "key-admin", Scopes: []auth.Scope{auth.ScopeAdmin}})
+	require.Equal(t, http.StatusAccepted, serveWithKey(admin, "POST", base+"summarizer/reject", "", `{"reason": "Stop"}`).Code)
+	require.Error(t, <-done)
+}
+
+// TestSupervisedRunRejected tests that rejecting a step without edits fails the run
+func TestSupervisedRunRejected(t *testing.T) {
+	o, session, router, done := startSupervisedRun(t)
+
+	waitForApproval(t, o, session.ID, "summarizer")
+	require.Equal(t, http.StatusAccepted, serveWithKey(router, "POST", "/api/sessions/"+session.ID+"/steps/summarizer/reject", "", `{"reason": "Wrong outline"}`).Code)
+	err := <-done
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Wrong outline")
+
+	session, _ = o.GetSession(session.ID)
+	assert.Equal(t, "failed", session.Status)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(router, "POST", "/api/sessions/missing/steps/summarizer/approve", "", "").Code)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/retrieval"
+	"github.com/sirupsen/logrus"
+)
+
+// Tenant index modes accepted in PipelineConfig.TenantIndexMode
+const (
+	TenantIndexShared = "shared" // Every session reads ElasticIndex (default)
+	TenantIndexPerOrg = "index"  // Each organization has its own index named by TenantIndexPattern
+	TenantIndexAlias  = "alias"  // Organizations read filtered aliases of ElasticIndex named by TenantIndexPattern
+)
+
+const (
+	// tenantIndexOrgPlaceholder is replaced by the organization in TenantIndexPattern
+	tenantIndexOrgPlaceholder = "{org}"
+	// untenantedAliasOrg names the alias that sessions without an organization read in alias
+	// mode; organization names are encoded so none can produce it
+	untenantedAliasOrg = "_shared"
+	// tenantAliasRefresh is how often a filtered alias is re-ensured, so backing indices
+	// created by a rollover are covered
+	tenantAliasRefresh = 10 * time.Minute
+)
+
+// tenantIndexModeFromEnv reads TENANT_INDEX_MODE, defaulting to a shared index
+func tenantIndexModeFromEnv() string {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv("TENANT_INDEX_MODE")))
+	switch v {
+	case "":
+		return TenantIndexShared
+	case TenantIndexShared, TenantIndexPerOrg, TenantIndexAlias:
+		return v
+	default:
+		logrus.WithField("value", v).Warn("Invalid TENANT_INDEX_MODE, using a shared index")
+		return TenantIndexShared
+	}
+}
+
+// tenantIndexPatternFromEnv reads TENANT_INDEX_PATTERN, e.g. lessons-{org}; empty uses "<index>-{org}"
+func tenantIndexPatternFromEnv() string {
+	v := strings.TrimSpace(os.Getenv("TENANT_INDEX_PATTERN"))
+	if v != "" && !strings.Contains(v, tenantIndexOrgPlaceholder) {
+		logrus.WithField("value", v).Warn("TENANT_INDEX_PATTERN has no {org} placeholder, using default")
+		return ""
+	}
+	return v
+}
+
+// tenantIndexOrgsFromEnv reads TENANT_INDEX_ORGS, the comma-separated organizations that get
+// their own index or alias
+func tenantIndexOrgsFromEnv() []string {
+	var orgs []string
+	for _, org := range strings.Split(os.Getenv("TENANT_INDEX_ORGS"), ",") {
+		if org = strings.TrimSpace(org); org != "" {
+			orgs = append(orgs, org)
+		}
+	}
+	return orgs
+}
+
+// encodeIndexOrg encodes an organization ID for use in index names. Lowercase letters and
+// digits are kept and every other character becomes "_<hex code point>_", so the encoding is
+// injective: "Acme-Inc" and "acme_inc" get different indices.
+func encodeIndexOrg(orgID string) string {
+	var b strings.Builder
+	for _, r := range orgID {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			fmt.Fprintf(&b, "_%x_", r)
+		}
+	}
+	return b.String()
+}
+
+// tenantIndexRouter resolves the retrieval index a session reads, so one organization's
+// course material never reaches another organization's lessons
+type tenantIndexRouter struct {
+	mode       string
+	pattern    string
+	base       string // Shared index; also the index filtered aliases select from
+	provider   retrieval.Provider
+	dimensions int
+	orgs       map[string]bool // Organizations given their own index or alias
+	now        func() time.Time
+
+	mu      sync.Mutex
+	ensured map[string]time.Time // Index or alias -> when it was last bootstrapped
+}
+
+// newTenantIndexRouter creates the router for a pipeline configuration. It returns nil, meaning
+// every session reads ElasticIndex, in shared mode.
+func newTenantIndexRouter(config PipelineConfig, provider retrieval.Provider, dimensions int) *tenantIndexRouter {
+	if config.TenantIndexMode == "" || config.TenantIndexMode == TenantIndexShared {
+		return nil
+	}
+	pattern := config.TenantIndexPattern
+	if pattern == "" {
+		pattern = config.ElasticIndex + "-" + tenantIndexOrgPlaceholder
+	}
+	orgs := make(map[string]bool, len(config.TenantIndexOrgs))
+	for _, org := range config.TenantIndexOrgs {
+		orgs[org] = true
+	}
+	if len(orgs) == 0 {
+		logrus.WithField("mode", config.TenantIndexMode).Warn("TENANT_INDEX_ORGS is empty, sessions of organizations will retrieve no context")
+	}
+	return &tenantIndexRouter{
+		mode:       config.TenantIndexMode,
+		pattern:    pattern,
+		base:       config.ElasticIndex,
+		provider:   provider,
+		dimensions: dimensions,
+		orgs:       orgs,
+		now:        time.Now,
+		ensured:    make(map[string]time.Time),
+	}
+}
+
+// indexFor returns the index a session of orgID reads, bootstrapping it on first use. Only the
+// configured organizations have one, so callers cannot create indices at will. An error means
+// the tenant's index is unavailable; callers must not fall back to the shared index.
+func (t *tenantIndexRouter) indexFor(ctx context.Context, orgID string) (string, error) {
+	if orgID != "" && !t.orgs[orgID] {
+		return "", fmt.Errorf("organization %q has no tenant index", orgID)
+	}
+	org := encodeIndexOrg(orgID)
+
+	switch t.mode {
+	case TenantIndexPerOrg:
+		if org == "" {
+			return t.base, nil
+		}
+		index := strings.ReplaceAll(t.pattern, tenantIndexOrgPlaceholder, org)
+		err := t.ensure(index, 0, func() error {
+			return t.provider.EnsureIndex(ctx, index, t.dimensions)
+		})
+		return index, err
+	case TenantIndexAlias:
+		aliaser, ok := t.provider.(retrieval.TenantAliaser)
+		if !ok {
+			return "", fmt.Errorf("retrieval provider %s does not support filtered aliases", t.provider.Name())
+		}
+		aliasOrg := org
+		if aliasOrg == "" {
+			aliasOrg = untenantedAliasOrg
+		}
+		alias := strings.ReplaceAll(t.pattern, tenantIndexOrgPlaceholder, aliasOrg)
+		err := t.ensure(alias, tenantAliasRefresh, func() error {
+			return aliaser.EnsureTenantAlias(ctx, t.base, alias, orgID)
+		})
+		return alias, err
+	default:
+		return t.base, nil
+	}
+}
+
+// ensure runs bootstrap for name unless it succeeded within refresh (0 = ever). The lock is not
+// held during bootstrap, so a slow provider does not stall other tenants; concurrent first uses
+// of one name may both bootstrap it, which providers treat as a no-op.
+func (t *tenantIndexRouter) ensure(name string, refresh time.Duration, bootstrap func() error) error {
+	t.mu.Lock()
+	at, ok := t.ensured[name]
+	fresh := ok && (refresh == 0 || t.now().Sub(at) < refresh)
+	t.mu.Unlock()
+	if fresh {
+		return nil
+	}
+
+	if err := bootstrap(); err != nil {
+		return fmt.Errorf("failed to bootstrap tenant index %s: %w", name, err)
+	}
+	t.mu.Lock()
+	t.ensured[name] = t.now()
+	t.mu.Unlock()
+	return nil
+}
+
+// retrievalIndex returns the index retrieval and similarity checks use for a session of orgID
+func (p *Pipeline) retrievalIndex(ctx context.Context, orgID string) (string, error) {
+	if p.tenantIndex == nil {
+		return p.config.ElasticIndex, nil
+	}
+	return p.tenantIndex.indexFor(ctx, orgID)
+}
+
+// sessionOrgID returns the organization a session belongs to, or "" if it has none
+func (o *Orchestrator) sessionOrgID(sessionID string) string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	session, exists := o.sessions[sessionID]
+	if !exists {
+		return ""
+	}
+	orgID, _ := session.Metadata["org_id"].(string)
+	return orgID
+}
+
+// logTenantIndexError records that a tenant's index could not be resolved
+func (p *Pipeline) logTenantIndexError(sessionID, orgID string, err error) {
+	p.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"org_id":     orgID,
+		"mode":       p.config.TenantIndexMode,
+		"error":      err,
+	}).Warn("Tenant retrieval index unavailable, continuing without it")
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/elastic"
+	"github.com/InnoFusionTech/ExplainIQ/internal/retrieval"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingProvider records the indices and aliases the router bootstraps
+type recordingProvider struct {
+	ensured []string
+	aliases map[string]string // Alias -> organization
+	search  []string          // Indices searched
+}
+
+// Name implements retrieval.Provider
+func (p *recordingProvider) Name() string { return "recording" }
+
+// EnsureIndex implements retrieval.Provider
+func (p *recordingProvider) EnsureIndex(ctx context.Context, index string, dimensions int) error {
+	p.ensured = append(p.ensured, index)
+	return nil
+}
+
+// Index implements retrieval.Provider
+func (p *recordingProvider) Index(ctx context.Context, index string, docs []retrieval.Doc) error {
+	return nil
+}
+
+// HybridSearch implements retrieval.Provider
+func (p *recordingProvider) HybridSearch(ctx context.Context, index, query string, embedding []float32, size int) ([]retrieval.Hit, error) {
+	p.search = append(p.search, index)
+	return nil, nil
+}
+
+// Delete implements retrieval.Provider
+func (p *recordingProvider) Delete(ctx context.Context, index string, ids []string) error { return nil }
+
+// Health implements retrieval.Provider
+func (p *recordingProvider) Health(ctx context.Context) error { return nil }
+
+// aliasingProvider also supports filtered aliases
+type aliasingProvider struct {
+	recordingProvider
+}
+
+// EnsureTenantAlias implements retrieval.TenantAliaser
+func (p *aliasingProvider) EnsureTenantAlias(ctx context.Context, index, alias, orgID string) error {
+	if p.aliases == nil {
+		p.aliases = make(map[string]string)
+	}
+	p.aliases[alias] = orgID
+	p.ensured = append(p.ensured, alias)
+	return nil
+}
+
+// TestTenantIndexPerOrg tests that each configured organization reads its own index, bootstrapped once
+func TestTenantIndexPerOrg(t *testing.T) {
+	provider := &recordingProvider{}
+	config := PipelineConfig{ElasticIndex: "lessons", TenantIndexMode: TenantIndexPerOrg, TenantIndexOrgs: []string{"Acme Corp", "globex"}}
+	router := newTenantIndexRouter(config, provider, 768)
+	require.NotNil(t, router)
+
+	index, err := router.indexFor(context.Background(), "Acme Corp")
+	require.NoError(t, err)
+	assert.Equal(t, "lessons-_41_cme_20__43_orp", index)
+	index, err = router.indexFor(context.Background(), "globex")
+	require.NoError(t, err)
+	assert.Equal(t, "lessons-globex", index)
+	_, err = router.indexFor(context.Background(), "Acme Corp")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lessons-_41_cme_20__43_orp", "lessons-globex"}, provider.ensured)
+
+	index, err = router.indexFor(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "lessons", index)
+
+	// Unknown organizations get no index, and none is created for them
+	_, err = router.indexFor(context.Background(), "initech")
+	assert.Error(t, err)
+	assert.Len(t, provider.ensured, 2)
+
+	assert.Nil(t, newTenantIndexRouter(PipelineConfig{ElasticIndex: "lessons", TenantIndexMode: TenantIndexShared}, provider, 768))
+}
+
+// TestEncodeIndexOrg tests that distinct organizations never share an index name
+func TestEncodeIndexOrg(t *testing.T) {
+	assert.Equal(t, "acme", encodeIndexOrg("acme"))
+	assert.NotEqual(t, encodeIndexOrg("Acme-Inc"), encodeIndexOrg("acme_inc"))
+	assert.NotEqual(t, encodeIndexOrg("acme inc"), encodeIndexOrg("acme_inc"))
+	assert.NotEqual(t, encodeIndexOrg("a_41_"), encodeIndexOrg("aA"))
+	assert.NotEqual(t, untenantedAliasOrg, encodeIndexOrg("_shared"))
+}
+
+// TestTenantIndexAlias tests filtered aliases per organization and for sessions without one
+func TestTenantIndexAlias(t *testing.T) {
+	provider := &aliasingProvider{}
+	config := PipelineConfig{ElasticIndex: "lessons", TenantIndexMode: TenantIndexAlias, TenantIndexPattern: "tenant_{org}", TenantIndexOrgs: []string{"acme"}}
+	router := newTenantIndexRouter(config, provider, 768)
+	now := time.Now()
+	router.now = func() time.Time { return now }
+
+	index, err := router.indexFor(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, "tenant_acme", index)
+	assert.Equal(t, "acme", provider.aliases["tenant_acme"])
+
+	index, err = router.indexFor(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "tenant__shared", index)
+	assert.Equal(t, "", provider.aliases["tenant__shared"])
+
+	// Aliases are refreshed so indices created by a rollover are covered
+	router.indexFor(context.Background(), "acme")
+	assert.Len(t, provider.ensured, 2)
+	now = now.Add(tenantAliasRefresh)
+	router.indexFor(context.Background(), "acme")
+	assert.Len(t, provider.ensured, 3)
+
+	// A provider without filtered aliases cannot isolate tenants in a shared index
+	router = newTenantIndexRouter(config, &recordingProvider{}, 768)
+	_, err = router.indexFor(context.Background(), "acme")
+	assert.Error(t, err)
+}
+
+// TestGetContextUsesTenantIndex tests that retrieval searches the session organization's index
+// and returns no context when that index is unavailable
+func TestGetContextUsesTenantIndex(t *testing.T) {
+	provider := &aliasingProvider{}
+	p := &Pipeline{
+		config:           PipelineConfig{ElasticIndex: "lessons", ContextTopK: 3, TenantIndexMode: TenantIndexAlias, TenantIndexOrgs: []string{"acme"}},
+		logger:           logrus.New(),
+		elasticRetriever: elastic.NewRetrieverWithBackend(provider, &stubEmbedder{}),
+	}
+	p.tenantIndex = newTenantIndexRouter(p.config, provider, 768)
+
+	_, err := p.getContext(context.Background(), "s1", "acme", "caching")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lessons-acme"}, provider.search)
+
+	p.tenantIndex = newTenantIndexRouter(p.config, &recordingProvider{}, 768)
+	docs, err := p.getContext(context.Background(), "s2", "acme", "caching")
+	assert.Error(t, err)
+	assert.Empty(t, docs)
+	assert.Len(t, provider.search, 1)
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultTipInterval is how long a user keeps seeing the same tip
+	defaultTipInterval = time.Hour
+	// tipHistory is how many recent tips a user is not shown again
+	tipHistory = 5
+	// maxTipViewers bounds the users whose rotation state is kept
+	maxTipViewers = 10000
+)
+
+// learningTips are the tips rotated through on the tips endpoint and BrainPrint responses
+var learningTips = []string{
+	"Try visualization next to boost retention by 30%!",
+	"Analogy explanations help connect new ideas to familiar concepts.",
+	"Simple explanations are great for complex topics - try them!",
+	"Mix different explanation types to discover your learning style.",
+	"Visual learners benefit most from diagram-based explanations.",
+	"Practice with toy examples to reinforce core mechanisms.",
+	"Real-life applications make abstract concepts concrete.",
+	"Memory hooks help you remember key concepts longer.",
+	"Best practices save time and prevent common mistakes.",
+	"Try different explanation types to find what works best for you!",
+	"Visualization mode creates interactive diagrams for better understanding.",
+	"Standard explanations provide comprehensive coverage of topics.",
+	"Simple explanations break down complex ideas into digestible parts.",
+	"Analogy explanations use familiar concepts to explain new ones.",
+}
+
+// fallbackTips serves orchestrators created without a tip rotation
+var fallbackTips = newTipRotation(learningTips, defaultTipInterval)
+
+// tipState is the tip a viewer is currently shown and the ones they saw before it
+type tipState struct {
+	current   int
+	recent    []int // Most recent last, including current
+	rotatesAt time.Time
+	seenAt    time.Time
+}
+
+// tipRotation tracks per-viewer tips, so clients see their own tip that changes on a schedule or
+// on request instead of all clients sharing one tip derived from the clock
+type tipRotation struct {
+	interval time.Duration
+	tips     []string
+
+	mu      sync.Mutex
+	viewers map[string]*tipState
+	rand    *rand.Rand
+}
+
+// newTipRotation creates a rotation over tips, advancing each viewer's tip every interval
+func newTipRotation(tips []string, interval time.Duration) *tipRotation {
+	return &tipRotation{
+		interval: interval,
+		tips:     tips,
+		viewers:  make(map[string]*tipState),
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// tipRotationFromEnv reads TIP_ROTATION_INTERVAL (e.g. "1h")
+func tipRotationFromEnv() *tipRotation {
+	interval := defaultTipInterval
+	if v := os.Getenv("TIP_ROTATION_INTERVAL"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed <= 0 {
+			logrus.WithField("value", v).Warn("Invalid TIP_ROTATION_INTERVAL, using default")
+		} else {
+			interval = parsed
+		}
+	}
+	return newTipRotation(learningTips, interval)
+}
+
+// current returns a viewer's tip and when it rotates, advancing it once its interval has passed
+func (t *tipRotation) current(viewer string, now time.Time) (string, time.Time) {
+	if t == nil {
+		t = fallbackTips
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.stateLocked(viewer, now)
+	if !now.Before(state.rotatesAt) {
+		t.advanceLocked(state, now)
+	}
+	return t.tips[state.current], state.rotatesAt
+}
+
+// next moves a viewer to a tip they have not seen recently
+func (t *tipRotation) next(viewer string, now time.Time) (string, time.Time) {
+	if t == nil {
+		t = fallbackTips
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.stateLocked(viewer, now)
+	t.advanceLocked(state, now)
+	return t.tips[state.current], state.rotatesAt
+}
+
+// stateLocked returns a viewer's state, starting new viewers on a random tip
+func (t *tipRotation) stateLocked(viewer string, now time.Time) *tipState {
+	state, exists := t.viewers[viewer]
+	if !exists {
+		if len(t.viewers) >= maxTipViewers {
+			t.evictLocked()
+		}
+		first := t.rand.Intn(len(t.tips))
+		state = &tipState{current: first, recent: []int{first}, rotatesAt: now.Add(t.interval)}
+		t.viewers[viewer] = state
+	}
+	state.seenAt = now
+	return state
+}
+
+// advanceLocked picks a random tip outside the viewer's recent history
+func (t *tipRotation) advanceLocked(state *tipState, now time.Time) {
+	window := tipHistory
+	if window > len(t.tips)-1 {
+		window = len(t.tips) - 1
+	}
+	if len(state.recent) > window {
+		state.recent = state.recent[len(state.recent)-window:]
+	}
+	recent := make(map[int]bool, len(state.recent))
+	for _, i := range state.recent {
+		recent[i] = true
+	}
+	candidates := make([]int, 0, len(t.tips))
+	for i := range t.tips {
+		if !recent[i] {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	state.current = candidates[t.rand.Intn(len(candidates))]
+	state.recent = append(state.recent, state.current)
+	state.rotatesAt = now.Add(t.interval)
+}
+
+// evictLocked drops viewers idle for a rotation interval, then arbitrary ones until a tenth of
+// the capacity is free
+func (t *tipRotation) evictLocked() {
+	cutoff := time.Now().Add(-t.interval)
+	for viewer, state := range t.viewers {
+		if state.seenAt.Before(cutoff) {
+			delete(t.viewers, viewer)
+		}
+	}
+	for viewer := range t.viewers {
+		if len(t.viewers) < maxTipViewers*9/10 {
+			break
+		}
+		delete(t.viewers, viewer)
+	}
+}
+
+// tipViewer identifies whose rotation a tips request reads: the authenticated user, else the
+// user_id query parameter, else the client address
+func tipViewer(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.UserID != "" {
+		return "user:" + principal.UserID
+	}
+	if userID := r.URL.Query().Get("user_id"); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + clientIP(r)
+}
+
+// currentTip returns a user's current tip for responses that include one
+func (o *Orchestrator) currentTip(userID string) string {
+	tip, _ := o.tips.current("user:"+userID, time.Now())
+	return tip
+}
+
+// tipETag derives a strong validator from the tip shown to the viewer
+func tipETag(tip string) string {
+	sum := sha256.Sum256([]byte(tip))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// writeTip writes a tip response. Clients may cache it until the tip rotates and poll with
+// If-None-Match, which is answered with 304 while the tip is unchanged.
+func writeTip(w http.ResponseWriter, r *http.Request, tip string, rotatesAt time.Time, now time.Time) {
+	etag := tipETag(tip)
+	maxAge := int(rotatesAt.Sub(now).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", maxAge))
+	w.Header().Set("Vary", "Authorization")
+	if r.Method == http.MethodGet && r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tip":        tip,
+		"rotates_at": rotatesAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// getTipsHandler handles GET /api/tips?user_id=
+// Users holding a misconception from an earlier lesson get a follow-up tip about it.
+func (o *Orchestrator) getTipsHandler(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	tip, rotatesAt := o.tips.current(tipViewer(r), now)
+	if followUp, ok := o.misconceptionTip(r.Context(), r.URL.Query().Get("user_id")); ok {
+		tip = followUp
+	}
+	writeTip(w, r, tip, rotatesAt, now)
+}
+
+// nextTipHandler handles POST /api/tips/next
+// It moves the caller to a tip they have not seen among their last few.
+func (o *Orchestrator) nextTipHandler(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	tip, rotatesAt := o.tips.next(tipViewer(r), now)
+	writeTip(w, r, tip, rotatesAt, now)
+}
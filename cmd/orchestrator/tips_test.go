@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTipRotation tests that tips hold for the interval and never repeat a recent tip
+func TestTipRotation(t *testing.T) {
+	rotation := newTipRotation(learningTips, time.Hour)
+	now := time.Now()
+
+	first, rotatesAt := rotation.current("user:u1", now)
+	assert.Equal(t, now.Add(time.Hour), rotatesAt)
+	again, _ := rotation.current("user:u1", now.Add(59*time.Minute))
+	assert.Equal(t, first, again, "the tip holds until it rotates")
+
+	seen := []string{first}
+	for i := 0; i < 50; i++ {
+		tip, _ := rotation.next("user:u1", now)
+		window := seen
+		if len(window) > tipHistory {
+			window = window[len(window)-tipHistory:]
+		}
+		assert.NotContains(t, window, tip)
+		seen = append(seen, tip)
+	}
+
+	rotated, _ := rotation.current("user:u1", now.Add(2*time.Hour))
+	assert.NotEqual(t, seen[len(seen)-1], rotated, "the tip rotates once its interval passes")
+
+	// A rotation with two tips alternates rather than getting stuck
+	pair := newTipRotation([]string{"a", "b"}, time.Hour)
+	tip, _ := pair.current("v", now)
+	next, _ := pair.next("v", now)
+	assert.NotEqual(t, tip, next)
+	back, _ := pair.next("v", now)
+	assert.Equal(t, tip, back)
+}
+
+// TestTipsETag tests conditional polling of the tips endpoint and the next tip action
+func TestTipsETag(t *testing.T) {
+	o := &Orchestrator{tips: newTipRotation(learningTips, time.Hour)}
+	router := chi.NewRouter()
+	router.Get("/api/tips", o.getTipsHandler)
+	router.Post("/api/tips/next", o.nextTipHandler)
+
+	w := serve(router, "GET", "/api/tips?user_id=u1")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Contains(t, w.Header().Get("Cache-Control"), "private, max-age=")
+	var tip map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tip))
+
+	poll := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/tips?user_id=u1", nil)
+		req.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	assert.Equal(t, http.StatusNotModified, poll().Code)
+
+	w = serve(router, "POST", "/api/tips/next?user_id=u1")
+	require.Equal(t, http.StatusOK, w.Code)
+	var next map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &next))
+	assert.NotEqual(t, tip["tip"], next["tip"])
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	// The changed tip is served in full to a client polling with the old ETag
+	w = poll()
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), next["tip"])
+}
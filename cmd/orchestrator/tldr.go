@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// maxTLDRPrompt caps the lesson text sent with a TL;DR request
+const maxTLDRPrompt = 4000
+
+// TLDRWriter writes a short summary of a finished lesson
+type TLDRWriter interface {
+	WriteTLDR(ctx context.Context, topic, lesson string) (string, error)
+}
+
+// lessonTLDR returns a one- or two-sentence summary of a finished lesson for previews. It asks the
+// model and falls back to the opening of the lesson's big picture when the model is unavailable.
+func (o *Orchestrator) lessonTLDR(ctx context.Context, sessionID, topic, lesson string) string {
+	if o.tldrClient != nil {
+		prompt := lesson
+		if len(prompt) > maxTLDRPrompt {
+			prompt = prompt[:maxTLDRPrompt]
+		}
+		tldr, err := o.tldrClient.WriteTLDR(ctx, topic, prompt)
+		if err == nil {
+			return tldr
+		}
+		o.logger.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"error":      err,
+		}).Warn("Failed to write lesson TL;DR, using the lesson opening")
+	}
+
+	if parsed := parseLesson(lesson); parsed != nil {
+		for _, section := range llm.LessonSections {
+			if text, _ := parsed.SectionText(section.ID); text != "" {
+				return llm.ExtractTLDR(text)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTLDRWriter returns a canned TL;DR or error
+type fakeTLDRWriter struct {
+	tldr   string
+	err    error
+	lesson string
+}
+
+// WriteTLDR implements TLDRWriter
+func (f *fakeTLDRWriter) WriteTLDR(ctx context.Context, topic, lesson string) (string, error) {
+	f.lesson = lesson
+	return f.tldr, f.err
+}
+
+// TestLessonTLDR tests that lessons get a model TL;DR, falling back to the lesson's opening sentences
+func TestLessonTLDR(t *testing.T) {
+	lesson := `{"big_picture": "Caches keep hot data close to the CPU. They trade memory for speed. Eviction decides what stays."}`
+	writer := &fakeTLDRWriter{tldr: "Caches make repeated reads fast."}
+	o := &Orchestrator{logger: logrus.New(), tldrClient: writer}
+
+	assert.Equal(t, "Caches make repeated reads fast.", o.lessonTLDR(context.Background(), "s1", "Caching", lesson))
+	assert.Equal(t, lesson, writer.lesson)
+
+	writer.err = errors.New("quota exceeded")
+	assert.Equal(t, "Caches keep hot data close to the CPU. They trade memory for speed.",
+		o.lessonTLDR(context.Background(), "s1", "Caching", lesson))
+
+	o.tldrClient = nil
+	assert.Equal(t, "Caches keep hot data close to the CPU. They trade memory for speed.",
+		o.lessonTLDR(context.Background(), "s1", "Caching", lesson))
+	assert.Empty(t, o.lessonTLDR(context.Background(), "s1", "Caching", "not a lesson"))
+}
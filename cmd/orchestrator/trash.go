@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultTrashRetention is how long soft-deleted lessons stay in the trash before being purged
+	DefaultTrashRetention = 30 * 24 * time.Hour
+	// trashPurgeInterval is how often the background purge job runs
+	trashPurgeInterval = time.Hour
+)
+
+// trashRetentionFromEnv returns the trash retention period from SAVED_LESSON_TRASH_RETENTION, or the default
+func trashRetentionFromEnv() time.Duration {
+	if v := os.Getenv("SAVED_LESSON_TRASH_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		logrus.WithField("value", v).Warn("Invalid SAVED_LESSON_TRASH_RETENTION, using default")
+	}
+	return DefaultTrashRetention
+}
+
+// isDeleted reports whether the lesson is in the trash
+func (l *SavedLesson) isDeleted() bool {
+	return l.DeletedAt != nil
+}
+
+// trashRetentionPeriod returns the configured trash retention period
+func (o *Orchestrator) trashRetentionPeriod() time.Duration {
+	if o.trashTTL > 0 {
+		return o.trashTTL
+	}
+	return DefaultTrashRetention
+}
+
+// purgeTrash permanently deletes lessons that have been in the trash longer than the retention period
+func (o *Orchestrator) purgeTrash(now time.Time) int {
+	cutoff := now.Add(-o.trashRetentionPeriod())
+
+	o.mu.Lock()
+	purged := make([]*SavedLesson, 0)
+	for id, lesson := range o.savedLessons {
+		if lesson.isDeleted() && lesson.DeletedAt.Before(cutoff) {
+			delete(o.savedLessons, id)
+			purged = append(purged, lesson)
+		}
+	}
+	o.mu.Unlock()
+
+	for _, lesson := range purged {
+		o.logger.WithFields(logrus.Fields{
+			"saved_id":   lesson.ID,
+			"user_id":    lesson.UserID,
+			"deleted_at": lesson.DeletedAt,
+		}).Info("Purged saved lesson from trash")
+	}
+	if len(purged) > 0 {
+		o.runArtifactTask(func(ctx context.Context) { o.releaseSavedLessonImages(ctx, purged...) })
+	}
+	return len(purged)
+}
+
+// startTrashPurger runs purgeTrash periodically until the context is cancelled
+func (o *Orchestrator) startTrashPurger(ctx context.Context, interval time.Duration) {
+	o.logger.WithFields(logrus.Fields{
+		"interval":  interval,
+		"retention": o.trashRetentionPeriod(),
+	}).Info("Saved lesson trash purger started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			o.logger.Info("Saved lesson trash purger stopped")
+			return
+		case now := <-ticker.C:
+			if purged := o.purgeTrash(now); purged > 0 {
+				o.logger.WithField("purged", purged).Info("Saved lesson trash purge completed")
+			}
+		}
+	}
+}
+
+// getTrashHandler handles GET /api/saved/{userID}/trash
+func (o *Orchestrator) getTrashHandler(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	w.Header().Set("Content-Type", "application/json")
+
+	if userID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "User ID is required",
+			"message": "User ID is required",
+		})
+		return
+	}
+
+	retention := o.trashRetentionPeriod()
+
+	o.mu.RLock()
+	lessons := make([]*SavedLesson, 0)
+	for _, lesson := range o.savedLessons {
+		if lesson.UserID == userID && lesson.isDeleted() {
+			lessons = append(lessons, lesson)
+		}
+	}
+	o.mu.RUnlock()
+
+	// Most recently deleted first
+	sort.Slice(lessons, func(i, j int) bool {
+		return lessons[i].DeletedAt.After(*lessons[j].DeletedAt)
+	})
+
+	trashed := make([]map[string]interface{}, 0, len(lessons))
+	for _, lesson := range lessons {
+		trashed = append(trashed, map[string]interface{}{
+			"lesson":   lesson,
+			"purge_at": lesson.DeletedAt.Add(retention),
+		})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"lessons":        trashed,
+		"count":          len(trashed),
+		"retention_days": int(retention.Hours() / 24),
+	})
+}
+
+// restoreSavedLessonHandler handles POST /api/saved/{userID}/{id}/restore
+func (o *Orchestrator) restoreSavedLessonHandler(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	savedID := chi.URLParam(r, "id")
+	w.Header().Set("Content-Type", "application/json")
+
+	o.mu.Lock()
+	savedLesson, exists := o.savedLessons[savedID]
+	if !exists || savedLesson.UserID != userID || !savedLesson.isDeleted() {
+		o.mu.Unlock()
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Saved lesson not found in trash",
+			"message": "Saved lesson not found in trash",
+		})
+		return
+	}
+	savedLesson.DeletedAt = nil
+	o.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"saved_id": savedID,
+		"user_id":  userID,
+	}).Info("Saved lesson restored from trash")
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Saved lesson restored successfully",
+		"lesson":  savedLesson,
+	})
+}
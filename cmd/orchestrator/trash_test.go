@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTrashTestOrchestrator creates an orchestrator with one saved lesson and the saved lesson routes
+func newTrashTestOrchestrator() (*Orchestrator, chi.Router) {
+	o := &Orchestrator{
+		sessions: make(map[string]*Session),
+		savedLessons: map[string]*SavedLesson{
+			"l1": {ID: "l1", UserID: "u1", Topic: "Kubernetes", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		},
+		logger:  logrus.New(),
+		clients: make(map[string][]chan SSEEvent),
+	}
+
+	r := chi.NewRouter()
+	r.Get("/api/saved/{userID}", o.getSavedLessonsHandler)
+	r.Get("/api/saved/{userID}/trash", o.getTrashHandler)
+	r.Get("/api/saved/{userID}/{id}", o.getSavedLessonHandler)
+	r.Delete("/api/saved/{userID}/{id}", o.deleteSavedLessonHandler)
+	r.Post("/api/saved/{userID}/{id}/restore", o.restoreSavedLessonHandler)
+	return o, r
+}
+
+// serve sends a request to the router and returns the recorder
+func serve(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+// TestSoftDeleteAndRestore tests that deleted lessons move to the trash and can be restored
+func TestSoftDeleteAndRestore(t *testing.T) {
+	o, router := newTrashTestOrchestrator()
+
+	require.Equal(t, http.StatusOK, serve(router, "DELETE", "/api/saved/u1/l1").Code)
+	assert.Contains(t, o.savedLessons, "l1")
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/api/saved/u1/l1").Code)
+
+	var listing map[string]interface{}
+	require.NoError(t, json.Unmarshal(serve(router, "GET", "/api/saved/u1").Body.Bytes(), &listing))
+	assert.Equal(t, float64(0), listing["count"])
+
+	var trash map[string]interface{}
+	require.NoError(t, json.Unmarshal(serve(router, "GET", "/api/saved/u1/trash").Body.Bytes(), &trash))
+	assert.Equal(t, float64(1), trash["count"])
+	assert.Equal(t, float64(30), trash["retention_days"])
+
+	// Other users cannot restore the lesson
+	assert.Equal(t, http.StatusNotFound, serve(router, "POST", "/api/saved/u2/l1/restore").Code)
+
+	require.Equal(t, http.StatusOK, serve(router, "POST", "/api/saved/u1/l1/restore").Code)
+	assert.Equal(t, http.StatusOK, serve(router, "GET", "/api/saved/u1/l1").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "POST", "/api/saved/u1/l1/restore").Code)
+}
+
+// TestPermanentDelete tests immediate deletion with ?permanent=true
+func TestPermanentDelete(t *testing.T) {
+	o, router := newTrashTestOrchestrator()
+
+	require.Equal(t, http.StatusOK, serve(router, "DELETE", "/api/saved/u1/l1?permanent=true").Code)
+	assert.NotContains(t, o.savedLessons, "l1")
+}
+
+// TestPurgeTrash tests that only lessons past the retention period are purged
+func TestPurgeTrash(t *testing.T) {
+	o, _ := newTrashTestOrchestrator()
+	now := time.Now()
+
+	old := now.Add(-31 * 24 * time.Hour)
+	recent := now.Add(-time.Hour)
+	o.savedLessons["l1"].DeletedAt = &old
+	o.savedLessons["l2"] = &SavedLesson{ID: "l2", UserID: "u1", DeletedAt: &recent}
+	o.savedLessons["l3"] = &SavedLesson{ID: "l3", UserID: "u1"}
+
+	assert.Equal(t, 1, o.purgeTrash(now))
+	assert.NotContains(t, o.savedLessons, "l1")
+	assert.Contains(t, o.savedLessons, "l2")
+	assert.Contains(t, o.savedLessons, "l3")
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// trustedProxyKey marks requests that arrived through a trusted proxy
+type trustedProxyKey struct{}
+
+// trustedProxiesFromEnv reads TRUSTED_PROXIES, the comma-separated addresses and networks of the
+// load balancers and proxies in front of the orchestrator
+func trustedProxiesFromEnv() []*net.IPNet {
+	return networksFromEnv("TRUSTED_PROXIES")
+}
+
+// remoteHost returns the host part of a request's RemoteAddr, which may or may not carry a port
+func remoteHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// forwardedClient returns the client a trusted proxy reported: the rightmost X-Forwarded-For
+// entry that is not itself a trusted proxy, else X-Real-IP. It returns nil if neither names one.
+func forwardedClient(r *http.Request, proxies []*net.IPNet) net.IP {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	var leftmost net.IP
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			// Entries left of a malformed one were not added by a proxy we trust
+			break
+		}
+		if !containsIP(proxies, ip) {
+			return ip
+		}
+		leftmost = ip
+	}
+	if leftmost != nil {
+		return leftmost
+	}
+	return net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+}
+
+// realIPMiddleware replaces RemoteAddr with the client address reported in X-Forwarded-For or
+// X-Real-IP, but only for requests whose peer is one of the trusted proxies; from anyone else
+// those headers are ignored, so clients cannot choose the address they are rate limited,
+// access controlled and audited under. Requests from trusted proxies are marked, so headers
+// only a proxy may set, like the country header, are honoured for them alone.
+func realIPMiddleware(proxies []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer := net.ParseIP(remoteHost(r))
+			if peer == nil || !containsIP(proxies, peer) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if client := forwardedClient(r, proxies); client != nil {
+				r.RemoteAddr = client.String()
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), trustedProxyKey{}, true)))
+		})
+	}
+}
+
+// viaTrustedProxy reports whether a request arrived through a trusted proxy
+func viaTrustedProxy(r *http.Request) bool {
+	trusted, _ := r.Context().Value(trustedProxyKey{}).(bool)
+	return trusted
+}
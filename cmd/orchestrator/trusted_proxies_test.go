@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRealIPMiddleware tests that forwarded client addresses are taken only from trusted proxies
+func TestRealIPMiddleware(t *testing.T) {
+	proxies, _ := parseNetworks("10.0.0.0/8, 2001:db8::/32")
+	resolve := func(remoteAddr string, headers map[string]string) (string, bool) {
+		var addr string
+		var trusted bool
+		handler := realIPMiddleware(proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			addr, trusted = r.RemoteAddr, viaTrustedProxy(r)
+		}))
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return addr, trusted
+	}
+
+	// Untrusted peers keep their own address whatever they claim
+	addr, trusted := resolve("203.0.113.9:5000", map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.1"})
+	assert.Equal(t, "203.0.113.9:5000", addr)
+	assert.False(t, trusted)
+
+	// The rightmost untrusted hop is the client; entries a client prepended are skipped
+	addr, trusted = resolve("10.0.0.2:443", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.7, 10.0.0.3"})
+	assert.Equal(t, "198.51.100.7", addr)
+	assert.True(t, trusted)
+
+	addr, _ = resolve("[2001:db8::1]:443", map[string]string{"X-Real-IP": "198.51.100.8"})
+	assert.Equal(t, "198.51.100.8", addr)
+
+	// A trusted peer that forwards nothing is the client
+	addr, trusted = resolve("10.0.0.2:443", nil)
+	assert.Equal(t, "10.0.0.2:443", addr)
+	assert.True(t, trusted)
+
+	require.Equal(t, "10.0.0.2", remoteHost(&http.Request{RemoteAddr: "10.0.0.2:443"}))
+	assert.Equal(t, "10.0.0.2", remoteHost(&http.Request{RemoteAddr: "10.0.0.2"}))
+}
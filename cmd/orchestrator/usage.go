@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// usageQuota is the monthly allowance shown on a user's usage page
+type usageQuota struct {
+	Sessions int     // Sessions per calendar month; 0 is unlimited
+	CostUSD  float64 // Estimated spend per calendar month; 0 is unlimited
+}
+
+// usageQuotaFromEnv reads the monthly allowance from USER_MONTHLY_SESSION_QUOTA and
+// USER_MONTHLY_COST_QUOTA_USD; both default to unlimited
+func usageQuotaFromEnv() usageQuota {
+	var quota usageQuota
+	if v := os.Getenv("USER_MONTHLY_SESSION_QUOTA"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			quota.Sessions = n
+		} else {
+			logrus.WithField("value", v).Warn("Invalid USER_MONTHLY_SESSION_QUOTA, sessions are unlimited")
+		}
+	}
+	if v := os.Getenv("USER_MONTHLY_COST_QUOTA_USD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			quota.CostUSD = f
+		} else {
+			logrus.WithField("value", v).Warn("Invalid USER_MONTHLY_COST_QUOTA_USD, spend is unlimited")
+		}
+	}
+	return quota
+}
+
+// UsageTotals is the usage of a set of sessions
+type UsageTotals struct {
+	Sessions     int     `json:"sessions"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	TotalTokens  int     `json:"total_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// UsageQuotaRemaining is what is left of a user's monthly allowance; nil fields are unlimited
+type UsageQuotaRemaining struct {
+	Sessions *int     `json:"sessions"`
+	CostUSD  *float64 `json:"cost_usd"`
+}
+
+// UserUsageResponse is a user's usage for the current calendar month
+type UserUsageResponse struct {
+	UserID         string                 `json:"user_id"`
+	PeriodStart    time.Time              `json:"period_start"`
+	PeriodEnd      time.Time              `json:"period_end"`
+	UsageTotals                           // Totals across all explanation types
+	QuotaRemaining UsageQuotaRemaining    `json:"quota_remaining"`
+	ByType         map[string]UsageTotals `json:"by_explanation_type"`
+}
+
+// add adds one session's usage to the totals
+func (t *UsageTotals) add(cost stepCost) {
+	t.InputTokens += cost.InputTokens
+	t.OutputTokens += cost.OutputTokens
+	t.TotalTokens += cost.InputTokens + cost.OutputTokens
+	t.CostUSD += cost.CostUSD
+}
+
+// monthStart returns the start of now's calendar month in UTC
+func monthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// sessionUsage totals the usage its agents reported across a session's steps
+func sessionUsage(session *Session) stepCost {
+	var total stepCost
+	for _, step := range session.Steps {
+		metrics, _ := step.Metadata["metrics"].(map[string]interface{})
+		cost := stepCostFromMetrics(metrics)
+		total.InputTokens += cost.InputTokens
+		total.OutputTokens += cost.OutputTokens
+		total.Images += cost.Images
+		total.CostUSD += cost.CostUSD
+	}
+	return total
+}
+
+// userUsage totals a user's sessions created in the calendar month containing now
+func (o *Orchestrator) userUsage(userID string, now time.Time) UserUsageResponse {
+	start := monthStart(now)
+	usage := UserUsageResponse{
+		UserID:      userID,
+		PeriodStart: start,
+		PeriodEnd:   start.AddDate(0, 1, 0),
+		ByType:      make(map[string]UsageTotals),
+	}
+
+	o.mu.RLock()
+	for _, session := range o.sessions {
+		if owner, _ := session.Metadata["user_id"].(string); owner != userID {
+			continue
+		}
+		if session.CreatedAt.Before(usage.PeriodStart) || !session.CreatedAt.Before(usage.PeriodEnd) {
+			continue
+		}
+		explanationType, _ := session.Metadata["explanation_type"].(string)
+		if explanationType == "" {
+			explanationType = "standard"
+		}
+		cost := sessionUsage(session)
+
+		usage.Sessions++
+		usage.add(cost)
+		byType := usage.ByType[explanationType]
+		byType.Sessions++
+		byType.add(cost)
+		usage.ByType[explanationType] = byType
+	}
+	o.mu.RUnlock()
+
+	if o.usageQuota.Sessions > 0 {
+		remaining := max(o.usageQuota.Sessions-usage.Sessions, 0)
+		usage.QuotaRemaining.Sessions = &remaining
+	}
+	if o.usageQuota.CostUSD > 0 {
+		remaining := max(o.usageQuota.CostUSD-usage.CostUSD, 0)
+		usage.QuotaRemaining.CostUSD = &remaining
+	}
+	return usage
+}
+
+// canViewUsage reports whether the caller may read a user's usage.
+// Admins read any user's usage; interactive users read their own. Anonymous callers read none.
+func (o *Orchestrator) canViewUsage(r *http.Request, userID string) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return false
+	}
+	if principal.HasScope(auth.ScopeAdmin) {
+		return true
+	}
+	return principal.Method == auth.MethodJWT && userID == principal.UserID
+}
+
+// getUserUsageHandler handles GET /api/users/{id}/usage
+func (o *Orchestrator) getUserUsageHandler(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	w.Header().Set("Content-Type", "application/json")
+	if !o.canViewUsage(r, userID) {
+		writeJSONError(w, http.StatusForbidden, "Forbidden", "Not allowed to view this user's usage")
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	json.NewEncoder(w).Encode(o.userUsage(userID, time.Now()))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usageSession creates a session for userID with one step that used tokens tokens in and out
+func usageSession(userID, explanationType string, createdAt time.Time, tokens int) *Session {
+	return &Session{
+		CreatedAt: createdAt,
+		Metadata:  map[string]interface{}{"user_id": userID, "explanation_type": explanationType},
+		Steps: []SessionStep{{
+			Name: "explainer",
+			Metadata: map[string]interface{}{"metrics": map[string]interface{}{
+				"model": "gemini-2.5-flash", "input_tokens": tokens, "output_tokens": tokens,
+			}},
+		}},
+	}
+}
+
+// newUsageTestRouter creates an orchestrator with sessions for u1 and u2 and the usage route
+func newUsageTestRouter() (*Orchestrator, chi.Router) {
+	now := time.Now()
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"s1": usageSession("u1", "standard", now, 1000),
+			"s2": usageSession("u1", "visual", now, 1000),
+			"s3": usageSession("u1", "visual", now, 500),
+			"s4": usageSession("u1", "standard", monthStart(now).Add(-time.Hour), 1000),
+			"s5": usageSession("u2", "standard", now, 1000),
+		},
+		logger:     logrus.New(),
+		usageQuota: usageQuota{Sessions: 2},
+	}
+	r := chi.NewRouter()
+	r.Get("/api/users/{id}/usage", o.getUserUsageHandler)
+	return o, r
+}
+
+// TestUserUsage tests that a user's usage covers only their sessions from this month
+func TestUserUsage(t *testing.T) {
+	_, router := newUsageTestRouter()
+
+	w := serve(withPrincipal(router, &auth.Principal{UserID: "u1", Method: auth.MethodJWT}), "GET", "/api/users/u1/usage")
+	require.Equal(t, http.StatusOK, w.Code)
+	var usage UserUsageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &usage))
+
+	assert.Equal(t, 3, usage.Sessions)
+	assert.Equal(t, 2500, usage.InputTokens)
+	assert.Equal(t, 5000, usage.TotalTokens)
+	assert.InDelta(t, 0.0028+0.0028+0.0014, usage.CostUSD, 1e-9)
+	assert.Equal(t, 1, usage.ByType["standard"].Sessions)
+	assert.Equal(t, 2, usage.ByType["visual"].Sessions)
+	assert.Equal(t, 3000, usage.ByType["visual"].TotalTokens)
+
+	// Remaining quota bottoms out at zero, and an unset quota is unlimited
+	require.NotNil(t, usage.QuotaRemaining.Sessions)
+	assert.Equal(t, 0, *usage.QuotaRemaining.Sessions)
+	assert.Nil(t, usage.QuotaRemaining.CostUSD)
+}
+
+// TestUserUsageAuthorization tests that users may only read their own usage and anonymous callers may read none
+func TestUserUsageAuthorization(t *testing.T) {
+	o, router := newUsageTestRouter()
+
+	request := func(principal *auth.Principal) int {
+		return serve(withPrincipal(router, principal), "GET", "/api/users/u1/usage").Code
+	}
+	assert.Equal(t, http.StatusForbidden, request(nil), "anonymous callers are rejected even when authentication is optional")
+	o.authRequired = true
+	assert.Equal(t, http.StatusForbidden, request(nil))
+	assert.Equal(t, http.StatusForbidden, request(&auth.Principal{UserID: "u2", Method: auth.MethodJWT}))
+	assert.Equal(t, http.StatusOK, request(&auth.Principal{UserID: "u1", Method: auth.MethodJWT}))
+	assert.Equal(t, http.StatusOK, request(&auth.Principal{APIKeyID: "k1", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeAdmin}}))
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/notify"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// dataDeletionTimeout bounds one user data deletion job
+const dataDeletionTimeout = 10 * time.Minute
+
+// User data deletion job statuses
+const (
+	DeletionStatusRunning             = "running"
+	DeletionStatusCompleted           = "completed"
+	DeletionStatusCompletedWithErrors = "completed_with_errors"
+)
+
+// DataDeletionReport records what a user data deletion erased
+type DataDeletionReport struct {
+	SessionsDeleted          int      `json:"sessions_deleted"`
+	SessionsSkipped          []string `json:"sessions_skipped,omitempty"` // Still in progress; request deletion again once they finish
+	SavedLessonsDeleted      int      `json:"saved_lessons_deleted"`
+	BrainPrintDeleted        bool     `json:"brainprint_deleted"`
+	CostRecordsAnonymized    int      `json:"cost_records_anonymized"`
+	NotificationPrefsDeleted bool     `json:"notification_prefs_deleted"` // Email and webhook channels
+	ArtifactsDeleted         int      `json:"artifacts_deleted"`          // Generated images
+	ExportsDeleted           int      `json:"exports_deleted"`            // Library export archives
+	GoalsDeleted             int      `json:"goals_deleted"`              // Learning goals and their mastery estimates
+	LibraryEntriesDeleted    int      `json:"library_entries_deleted"`    // Lessons the user shared with an organization
+	APIKeysRevoked           int      `json:"api_keys_revoked"`           // Keys the user issued, which stop authenticating
+}
+
+// DataDeletionJob is the audit record of erasing a user's data
+type DataDeletionJob struct {
+	ID          string             `json:"id"`
+	UserID      string             `json:"user_id"`
+	RequestedBy string             `json:"requested_by"` // Caller that requested the deletion
+	Status      string             `json:"status"`
+	Report      DataDeletionReport `json:"report"`
+	Errors      []string           `json:"errors,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	CompletedAt *time.Time         `json:"completed_at,omitempty"`
+}
+
+// sessionInProgress reports whether a session may still be written by a pipeline run
+func sessionInProgress(session *Session) bool {
+	switch session.Status {
+	case "pending", "queued", "running":
+		return true
+	}
+	return false
+}
+
+// canEraseUserData reports whether the caller may erase a user's data.
+// Admins erase any user's data; interactive users erase their own. Anonymous callers erase nothing.
+func (o *Orchestrator) canEraseUserData(r *http.Request, userID string) bool {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return false
+	}
+	if principal.HasScope(auth.ScopeAdmin) {
+		return true
+	}
+	return principal.Method == auth.MethodJWT && userID == principal.UserID
+}
+
+// requesterOf describes the caller of a request for the audit record
+func requesterOf(r *http.Request) string {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return "anonymous"
+	}
+	if principal.Method == auth.MethodAPIKey {
+		return "api_key:" + principal.APIKeyID
+	}
+	return "user:" + principal.UserID
+}
+
+// removeUserRecords removes a user's saved lessons (including the trash), shared library entries, learning goals, library chat index and sessions from memory.
+// Sessions are the user's when their user_id metadata matches or one of the user's lessons saved
+// them; sessions another user's lesson still references are kept, and in-progress sessions are skipped.
+func (o *Orchestrator) removeUserRecords(userID string, report *DataDeletionReport) ([]*SavedLesson, []*Session) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var lessons []*SavedLesson
+	candidates := make(map[string]struct{})
+	for id, lesson := range o.savedLessons {
+		if lesson.UserID != userID {
+			continue
+		}
+		lessons = append(lessons, lesson)
+		candidates[lesson.SessionID] = struct{}{}
+		delete(o.savedLessons, id)
+	}
+	for id, session := range o.sessions {
+		if owner, _ := session.Metadata["user_id"].(string); owner == userID {
+			candidates[id] = struct{}{}
+		}
+	}
+
+	referenced := make(map[string]struct{})
+	for _, lesson := range o.savedLessons {
+		referenced[lesson.SessionID] = struct{}{}
+	}
+
+	var sessions []*Session
+	for id := range candidates {
+		session, exists := o.sessions[id]
+		if !exists {
+			continue
+		}
+		if _, shared := referenced[id]; shared {
+			continue
+		}
+		if sessionInProgress(session) {
+			report.SessionsSkipped = append(report.SessionsSkipped, id)
+			continue
+		}
+		sessions = append(sessions, session)
+		delete(o.sessions, id)
+		o.transcripts.remove(id)
+		if o.metaIndex != nil {
+			o.metaIndex.remove(id)
+		}
+	}
+	sort.Strings(report.SessionsSkipped)
+
+	if o.libraryIndex != nil {
+		o.libraryIndex.removeUser(userID)
+	}
+
+	report.GoalsDeleted = len(o.goals[userID])
+	delete(o.goals, userID)
+
+	for id, entry := range o.orgLibrary {
+		if entry.AuthorID == userID {
+			delete(o.orgLibrary, id)
+			report.LibraryEntriesDeleted++
+		}
+	}
+
+	report.SavedLessonsDeleted = len(lessons)
+	report.SessionsDeleted = len(sessions)
+	return lessons, sessions
+}
+
+// deleteSessionImages deletes the temporary images generated for sessions, returning how many were deleted
+func (o *Orchestrator) deleteSessionImages(ctx context.Context, sessions []*Session) (int, error) {
+	if o.artifacts == nil {
+		return 0, nil
+	}
+
+	deleted := 0
+	for _, session := range sessions {
+		if session.Result == nil {
+			continue
+		}
+		for url := range session.Result.Images {
+			object, ok := o.artifacts.objectName(url)
+			if !ok || !strings.HasPrefix(object, tempArtifactPrefix) {
+				continue
+			}
+			if err := o.artifacts.store.Delete(ctx, object); err != nil {
+				return deleted, fmt.Errorf("failed to delete image %s: %w", object, err)
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// runUserDataDeletion erases a user's sessions, saved lessons, BrainPrint profile, notification
+// channels, images and export archives, and anonymizes the cost records of the erased sessions.
+// Every step runs even if an earlier one fails; failures are recorded on the job.
+func (o *Orchestrator) runUserDataDeletion(jobID, userID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), dataDeletionTimeout)
+	defer cancel()
+
+	var report DataDeletionReport
+	var errs []string
+	record := func(step string, err error) {
+		errs = append(errs, fmt.Sprintf("%s: %v", step, err))
+	}
+
+	lessons, sessions := o.removeUserRecords(userID, &report)
+
+	report.ArtifactsDeleted = o.releaseSavedLessonImages(ctx, lessons...)
+	deleted, err := o.deleteSessionImages(ctx, sessions)
+	report.ArtifactsDeleted += deleted
+	if err != nil {
+		record("artifacts", err)
+	}
+
+	if o.apiKeys != nil {
+		for _, key := range o.apiKeys.List(userID, "") {
+			if key.Revoked() {
+				continue
+			}
+			if _, err := o.apiKeys.Revoke(ctx, key.ID); err != nil {
+				record("api keys", err)
+				continue
+			}
+			report.APIKeysRevoked++
+		}
+	}
+
+	if o.exporter != nil {
+		if report.ExportsDeleted, err = o.exporter.deleteUserExports(ctx, userID); err != nil {
+			record("exports", err)
+		}
+	}
+
+	if o.brainprintSvc != nil {
+		if report.BrainPrintDeleted, err = o.brainprintSvc.DeleteProfile(ctx, userID); err != nil {
+			record("brainprint", err)
+		}
+	}
+
+	if o.costTracker != nil {
+		for _, session := range sessions {
+			anonymized, err := o.costTracker.AnonymizeSessionCosts(ctx, session.ID)
+			if err != nil {
+				record("cost records", err)
+				continue
+			}
+			if anonymized {
+				report.CostRecordsAnonymized++
+			}
+		}
+	}
+
+	if o.notifier != nil {
+		if _, exists := o.notifier.Preferences(notify.ScopeUser, userID); exists {
+			if _, err := o.notifier.SetPreferences(ctx, notify.ScopeUser, userID, notify.Preferences{}); err != nil {
+				record("notifications", err)
+			} else {
+				report.NotificationPrefsDeleted = true
+			}
+		}
+	}
+
+	now := time.Now()
+	o.mu.Lock()
+	job := o.deletionJobs[jobID]
+	job.Report = report
+	job.Errors = errs
+	job.Status = DeletionStatusCompleted
+	if len(errs) > 0 {
+		job.Status = DeletionStatusCompletedWithErrors
+	}
+	job.CompletedAt = &now
+	status := job.Status
+	o.mu.Unlock()
+
+	fields := logrus.Fields{
+		"job_id":        jobID,
+		"user_id":       userID,
+		"status":        status,
+		"sessions":      report.SessionsDeleted,
+		"saved_lessons": report.SavedLessonsDeleted,
+		"artifacts":     report.ArtifactsDeleted,
+		"exports":       report.ExportsDeleted,
+		"api_keys":      report.APIKeysRevoked,
+	}
+	if len(errs) > 0 {
+		o.logger.WithFields(fields).WithField("errors", errs).Error("User data deletion finished with errors")
+	} else {
+		o.logger.WithFields(fields).Info("User data deletion completed")
+	}
+}
+
+// deleteUserDataHandler handles DELETE /api/users/{id}/data
+// It starts erasing the user's data in the background and responds 202 with the job's status URL.
+func (o *Orchestrator) deleteUserDataHandler(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	w.Header().Set("Content-Type", "application/json")
+
+	if !o.canEraseUserData(r, userID) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Forbidden",
+			"message": "Not allowed to delete this user's data",
+		})
+		return
+	}
+
+	job := &DataDeletionJob{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		RequestedBy: requesterOf(r),
+		Status:      DeletionStatusRunning,
+		CreatedAt:   time.Now(),
+	}
+	o.mu.Lock()
+	if o.deletionJobs == nil {
+		o.deletionJobs = make(map[string]*DataDeletionJob)
+	}
+	o.deletionJobs[job.ID] = job
+	o.mu.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"job_id":       job.ID,
+		"user_id":      userID,
+		"requested_by": job.RequestedBy,
+	}).Info("User data deletion requested")
+
+	go o.runUserDataDeletion(job.ID, userID)
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"job_id":     job.ID,
+		"status":     DeletionStatusRunning,
+		"status_url": fmt.Sprintf("/api/users/%s/data/deletions/%s", userID, job.ID),
+	})
+}
+
+// getDataDeletionHandler handles GET /api/users/{id}/data/deletions/{jobID}
+func (o *Orchestrator) getDataDeletionHandler(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	if !o.canEraseUserData(r, userID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	o.mu.RLock()
+	var job DataDeletionJob
+	stored, exists := o.deletionJobs[chi.URLParam(r, "jobID")]
+	if exists {
+		job = *stored
+	}
+	o.mu.RUnlock()
+
+	if !exists || job.UserID != userID {
+		http.Error(w, "Deletion job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
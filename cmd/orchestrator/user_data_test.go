@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/InnoFusionTech/ExplainIQ/internal/brainprint"
+	"github.com/InnoFusionTech/ExplainIQ/internal/notify"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUserDataTestOrchestrator creates an orchestrator holding data for users u1 and u2 and the user data routes
+func newUserDataTestOrchestrator(t *testing.T) (*Orchestrator, chi.Router) {
+	ctx := context.Background()
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"s1": {ID: "s1", Status: "completed", Metadata: map[string]interface{}{"user_id": "u1"}},
+			"s2": {ID: "s2", Status: "completed"},
+			"s3": {ID: "s3", Status: "running", Metadata: map[string]interface{}{"user_id": "u1"}},
+			"s4": {ID: "s4", Status: "completed", Metadata: map[string]interface{}{"user_id": "u2"}},
+		},
+		savedLessons: map[string]*SavedLesson{
+			"l1": {ID: "l1", UserID: "u1", SessionID: "s2"},
+			"l2": {ID: "l2", UserID: "u2", SessionID: "s4"},
+		},
+		logger:        logrus.New(),
+		clients:       make(map[string][]chan SSEEvent),
+		metaIndex:     newMetadataIndex(),
+		brainprintSvc: brainprint.NewService(nil),
+		notifier:      notify.NewService(nil, notify.Config{}),
+		deletionJobs:  make(map[string]*DataDeletionJob),
+	}
+	for _, session := range o.sessions {
+		o.indexSessionLocked(session)
+	}
+	require.NoError(t, o.brainprintSvc.TrackSession(ctx, "u1", "standard", true))
+	_, err := o.notifier.SetPreferences(ctx, notify.ScopeUser, "u1", notify.Preferences{
+		Channels: []notify.ChannelConfig{{Type: notify.ChannelSlack, Target: "https://hooks.slack.com/services/T/B/X"}},
+	})
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	r.Delete("/api/users/{id}/data", o.deleteUserDataHandler)
+	r.Get("/api/users/{id}/data/deletions/{jobID}", o.getDataDeletionHandler)
+	return o, r
+}
+
+// TestDeleteUserData tests erasing a user's data and reading the completion report
+func TestDeleteUserData(t *testing.T) {
+	o, routes := newUserDataTestOrchestrator(t)
+	store := &memoryExportStore{objects: map[string][]byte{"exports/u1/e1.zip": []byte("zip")}}
+	o.exporter = newLibraryExporter(store, time.Hour)
+	o.exporter.exports["e1"] = &LibraryExport{ID: "e1", UserID: "u1", Status: ExportStatusCompleted, Object: "exports/u1/e1.zip"}
+	o.apiKeys = auth.NewAPIKeyService(nil)
+	_, u1Key, err := o.apiKeys.Create(t.Context(), auth.CreateAPIKeyRequest{UserID: "u1", Scopes: []auth.Scope{auth.ScopeSessionsRead}})
+	require.NoError(t, err)
+	_, u2Key, err := o.apiKeys.Create(t.Context(), auth.CreateAPIKeyRequest{UserID: "u2", Scopes: []auth.Scope{auth.ScopeSessionsRead}})
+	require.NoError(t, err)
+	router := withPrincipal(routes, &auth.Principal{UserID: "u1", Method: auth.MethodJWT})
+
+	w := serve(router, "DELETE", "/api/users/u1/data")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var started map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	jobID := started["job_id"].(string)
+
+	var job DataDeletionJob
+	require.Eventually(t, func() bool {
+		w := serve(router, "GET", "/api/users/u1/data/deletions/"+jobID)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		return job.Status != DeletionStatusRunning
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, DeletionStatusCompleted, job.Status, job.Errors)
+	assert.Equal(t, "user:u1", job.RequestedBy)
+	assert.Equal(t, 2, job.Report.SessionsDeleted)
+	assert.Equal(t, []string{"s3"}, job.Report.SessionsSkipped)
+	assert.Equal(t, 1, job.Report.SavedLessonsDeleted)
+	assert.True(t, job.Report.BrainPrintDeleted)
+	assert.True(t, job.Report.NotificationPrefsDeleted)
+	assert.Equal(t, 1, job.Report.ExportsDeleted)
+	assert.Equal(t, 1, job.Report.APIKeysRevoked)
+
+	// Only u1's finished data is gone
+	assert.NotContains(t, o.sessions, "s1")
+	assert.NotContains(t, o.sessions, "s2")
+	assert.Contains(t, o.sessions, "s3")
+	assert.Contains(t, o.sessions, "s4")
+	assert.NotContains(t, o.savedLessons, "l1")
+	assert.Contains(t, o.savedLessons, "l2")
+	assert.Empty(t, store.objects)
+	assert.Empty(t, o.QuerySessions(map[string][]string{"user_id": {"u1"}}, "completed"))
+	_, exists := o.notifier.Preferences(notify.ScopeUser, "u1")
+	assert.False(t, exists)
+	_, err = o.apiKeys.Authenticate(u1Key)
+	assert.Error(t, err)
+	_, err = o.apiKeys.Authenticate(u2Key)
+	assert.NoError(t, err)
+
+	// The report is only visible for the user it belongs to
+	admin := withPrincipal(routes, &auth.Principal{APIKeyID: "k1", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeAdmin}})
+	assert.Equal(t, http.StatusNotFound, serve(admin, "GET", "/api/users/u2/data/deletions/"+jobID).Code)
+}
+
+// TestDeleteUserDataAuthorization tests that users may only erase their own data, even when authentication is optional
+func TestDeleteUserDataAuthorization(t *testing.T) {
+	_, router := newUserDataTestOrchestrator(t)
+
+	request := func(principal *auth.Principal) int {
+		w := serve(withPrincipal(router, principal), "DELETE", "/api/users/u1/data")
+		return w.Code
+	}
+	assert.Equal(t, http.StatusForbidden, request(nil))
+	assert.Equal(t, http.StatusForbidden, request(&auth.Principal{UserID: "u2", Method: auth.MethodJWT}))
+	assert.Equal(t, http.StatusForbidden, request(&auth.Principal{UserID: "u1", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeSessionsWrite}}))
+	assert.Equal(t, http.StatusAccepted, request(&auth.Principal{UserID: "u1", Method: auth.MethodJWT}))
+	assert.Equal(t, http.StatusAccepted, request(&auth.Principal{APIKeyID: "k1", Method: auth.MethodAPIKey, Scopes: []auth.Scope{auth.ScopeAdmin}}))
+}
+
+// withPrincipal wraps a handler so every request carries principal, if not nil
+func withPrincipal(next http.Handler, principal *auth.Principal) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal != nil {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
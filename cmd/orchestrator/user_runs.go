@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultUserMaxRuns is how many pipelines one user may run at once
+	defaultUserMaxRuns = 2
+	// userRunRetryAfter is the Retry-After hint, in seconds, when a user is at their run limit
+	userRunRetryAfter = 30
+)
+
+// errUserRunLimit is returned when a user is at their concurrent run limit and excess runs are rejected
+var errUserRunLimit = errors.New("too many concurrent sessions")
+
+// userRunLimiter caps how many pipelines each user runs at once. Runs over the cap wait
+// in a per-user FIFO queue or are rejected, depending on policy.
+type userRunLimiter struct {
+	limit int  // Runs per user; 0 disables the cap
+	queue bool // Queue excess runs instead of rejecting them
+
+	mu      sync.Mutex
+	running map[string]int         // user -> runs holding a slot
+	waiting map[string][]queuedRun // user -> runs waiting for a slot, oldest first
+}
+
+// newUserRunLimiter creates a limiter allowing limit runs per user
+func newUserRunLimiter(limit int, queue bool) *userRunLimiter {
+	return &userRunLimiter{
+		limit:   limit,
+		queue:   queue,
+		running: make(map[string]int),
+		waiting: make(map[string][]queuedRun),
+	}
+}
+
+// userRunLimiterFromEnv creates the limiter from USER_MAX_CONCURRENT_RUNS (0 disables the cap)
+// and USER_RUN_LIMIT_POLICY ("reject", the default, or "queue")
+func userRunLimiterFromEnv() *userRunLimiter {
+	limit := defaultUserMaxRuns
+	if v := os.Getenv("USER_MAX_CONCURRENT_RUNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			limit = n
+		} else {
+			logrus.WithField("value", v).Warn("Invalid USER_MAX_CONCURRENT_RUNS, using default")
+		}
+	}
+
+	queue := false
+	switch policy := strings.ToLower(os.Getenv("USER_RUN_LIMIT_POLICY")); policy {
+	case "", "reject":
+	case "queue":
+		queue = true
+	default:
+		logrus.WithField("value", policy).Warn("Invalid USER_RUN_LIMIT_POLICY, rejecting excess runs")
+	}
+	return newUserRunLimiter(limit, queue)
+}
+
+// acquire starts a user's run now if they are under the limit, or queues it when the policy allows.
+// It returns the run's 1-based position in the user's queue, 0 if it started. start must not block
+// and must call release for the user when the run finishes.
+func (l *userRunLimiter) acquire(user, sessionID string, start func()) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit <= 0 || l.running[user] < l.limit {
+		l.running[user]++
+		start()
+		return 0, nil
+	}
+	if !l.queue {
+		return 0, errUserRunLimit
+	}
+	l.waiting[user] = append(l.waiting[user], queuedRun{sessionID: sessionID, run: start})
+	return len(l.waiting[user]), nil
+}
+
+// release frees a user's slot, handing it to their oldest waiting run
+func (l *userRunLimiter) release(user string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if waiting := l.waiting[user]; len(waiting) > 0 {
+		next := waiting[0]
+		if len(waiting) == 1 {
+			delete(l.waiting, user)
+		} else {
+			l.waiting[user] = waiting[1:]
+		}
+		go next.run()
+		return
+	}
+
+	l.running[user]--
+	if l.running[user] <= 0 {
+		delete(l.running, user)
+	}
+}
+
+// active returns how many runs a user holds slots for
+func (l *userRunLimiter) active(user string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.running[user]
+}
+
+// position returns a session's 1-based position in its user's queue, or 0 if it is not waiting
+func (l *userRunLimiter) position(sessionID string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, waiting := range l.waiting {
+		for i, queued := range waiting {
+			if queued.sessionID == sessionID {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// runUser identifies who a session run counts against: the authenticated user, else the
+// client IP. Session metadata is not consulted, so anonymous callers cannot spend another
+// user's allowance or escape their own by running sessions created for someone else.
+func runUser(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.UserID != "" {
+		return "user:" + principal.UserID
+	}
+	return "ip:" + clientIP(r)
+}
+
+// startUserRun starts a session run against the user's run limit. The run either starts now,
+// waits in the user's queue (returning its position) or is rejected with errUserRunLimit.
+// start is given the run to execute and must not block; callers launch it or route it through another queue.
+func (o *Orchestrator) startUserRun(user, sessionID string, start func(run func())) (int, error) {
+	run := func() { o.RunSession(sessionID) }
+	if o.userRuns == nil {
+		start(run)
+		return 0, nil
+	}
+	return o.userRuns.acquire(user, sessionID, func() {
+		start(func() {
+			defer o.userRuns.release(user)
+			run()
+		})
+	})
+}
+
+// writeUserRunLimitError writes the structured 429 for a user at their run limit
+func (o *Orchestrator) writeUserRunLimitError(w http.ResponseWriter, user, sessionID string) {
+	o.logger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"user":       user,
+		"limit":      o.userRuns.limit,
+	}).Warn("Rejected session run over the user's concurrent run limit")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(userRunRetryAfter))
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":       "Too many concurrent sessions",
+		"message":     "Wait for one of your running sessions to finish before starting another.",
+		"code":        "user_concurrency_limit",
+		"limit":       o.userRuns.limit,
+		"running":     o.userRuns.active(user),
+		"retry_after": userRunRetryAfter,
+	})
+}
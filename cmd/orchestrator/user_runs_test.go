@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUserRunLimiterReject tests that runs over a user's limit are rejected while other users are unaffected
+func TestUserRunLimiterReject(t *testing.T) {
+	l := newUserRunLimiter(1, false)
+
+	position, err := l.acquire("alice", "s1", func() {})
+	require.NoError(t, err)
+	assert.Equal(t, 0, position)
+
+	_, err = l.acquire("alice", "s2", func() {})
+	assert.ErrorIs(t, err, errUserRunLimit)
+
+	_, err = l.acquire("bob", "s3", func() {})
+	assert.NoError(t, err)
+
+	l.release("alice")
+	assert.Equal(t, 0, l.active("alice"))
+	_, err = l.acquire("alice", "s2", func() {})
+	assert.NoError(t, err)
+}
+
+// TestUserRunLimiterQueue tests that queued runs start in order as the user's runs finish
+func TestUserRunLimiterQueue(t *testing.T) {
+	l := newUserRunLimiter(1, true)
+	started := make(chan string, 3)
+
+	_, err := l.acquire("alice", "s1", func() { started <- "s1" })
+	require.NoError(t, err)
+	require.Equal(t, "s1", <-started)
+
+	position, err := l.acquire("alice", "s2", func() { started <- "s2" })
+	require.NoError(t, err)
+	assert.Equal(t, 1, position)
+	position, err = l.acquire("alice", "s3", func() { started <- "s3" })
+	require.NoError(t, err)
+	assert.Equal(t, 2, position)
+	assert.Equal(t, 2, l.position("s3"))
+
+	// Finishing a run hands its slot to the next waiting run
+	l.release("alice")
+	require.Equal(t, "s2", <-started)
+	assert.Equal(t, 1, l.active("alice"))
+	assert.Equal(t, 1, l.position("s3"))
+
+	l.release("alice")
+	require.Equal(t, "s3", <-started)
+	l.release("alice")
+	assert.Equal(t, 0, l.active("alice"))
+}
+
+// TestRunSessionUserLimit tests the structured 429 for a user at their concurrent run limit
+func TestRunSessionUserLimit(t *testing.T) {
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"s1": {ID: "s1", Topic: "Raft", Status: "created", Metadata: map[string]interface{}{"user_id": "alice"}},
+		},
+		logger:   logrus.New(),
+		clients:  make(map[string][]chan SSEEvent),
+		runQueue: newRunQueue(1),
+		userRuns: newUserRunLimiter(1, false),
+	}
+	_, err := o.userRuns.acquire("user:alice", "other", func() {})
+	require.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Post("/api/sessions/{id}/run", o.runSessionHandler)
+	r := withPrincipal(router, &auth.Principal{UserID: "alice", Method: auth.MethodJWT})
+
+	for _, path := range []string{"/api/sessions/s1/run?mode=async", "/api/sessions/s1/run"} {
+		w := serve(r, "POST", path)
+		require.Equal(t, http.StatusTooManyRequests, w.Code, path)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "user_concurrency_limit", body["code"])
+		assert.Equal(t, float64(1), body["limit"])
+		assert.Equal(t, float64(1), body["running"])
+	}
+
+	// A rejected run leaves the session as it was
+	session, _ := o.GetSession("s1")
+	assert.Equal(t, "created", session.Status)
+}
+
+// TestRunUser tests that runs count against the authenticated user, else the client IP, never
+// the user a session's metadata names
+func TestRunUser(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/sessions/s1/run", nil)
+	req.RemoteAddr = "203.0.113.7:4000"
+	assert.Equal(t, "ip:203.0.113.7", runUser(req))
+
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{UserID: "alice", Method: auth.MethodJWT}))
+	assert.Equal(t, "user:alice", runUser(req))
+}
+
+// TestAsyncRunUserQueue tests that a queued run reports its place in the user's queue
+func TestAsyncRunUserQueue(t *testing.T) {
+	o := &Orchestrator{
+		sessions: map[string]*Session{
+			"s1": {ID: "s1", Topic: "Raft", Status: "created", Metadata: map[string]interface{}{"user_id": "alice"}},
+		},
+		logger:   logrus.New(),
+		clients:  make(map[string][]chan SSEEvent),
+		runQueue: newRunQueue(1),
+		userRuns: newUserRunLimiter(1, true),
+	}
+	_, err := o.userRuns.acquire("user:alice", "other", func() {})
+	require.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Post("/api/sessions/{id}/run", o.runSessionHandler)
+	r := withPrincipal(router, &auth.Principal{UserID: "alice", Method: auth.MethodJWT})
+
+	w := serve(r, "POST", "/api/sessions/s1/run?mode=async")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var accepted map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &accepted))
+	assert.Equal(t, float64(1), accepted["queue_position"])
+
+	status, ok := o.sessionStatus("s1")
+	require.True(t, ok)
+	assert.Equal(t, "queued", status.Status)
+	assert.Equal(t, 1, status.QueuePosition)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/elastic"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// warmStartContextScore ranks saved lesson sections above retrieved documents before reranking
+const warmStartContextScore = 1.0
+
+// WarmStartRequest represents the request for POST /api/sessions/from-saved/{savedID}.
+// Unset fields default to the saved lesson's.
+type WarmStartRequest struct {
+	ExplanationType string   `json:"explanation_type,omitempty"`
+	Persona         string   `json:"persona,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	CourseID        string   `json:"course_id,omitempty"`
+}
+
+// createSessionFromSavedHandler handles POST /api/sessions/from-saved/{savedID}
+// It creates a session on the saved lesson's topic that, when run, reuses the lesson's outline
+// instead of summarizing again and gives the explainer the lesson as retrieval context.
+// Only the lesson's owner may warm-start from it, and the new session belongs to them.
+func (o *Orchestrator) createSessionFromSavedHandler(w http.ResponseWriter, r *http.Request) {
+	savedID := chi.URLParam(r, "savedID")
+	w.Header().Set("Content-Type", "application/json")
+
+	var req WarmStartRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	owner := sessionOwner(r)
+	if owner == "" {
+		writeJSONError(w, http.StatusForbidden, "Forbidden", "Warm-starting a session requires an authenticated user")
+		return
+	}
+
+	o.mu.RLock()
+	saved, exists := o.savedLessons[savedID]
+	o.mu.RUnlock()
+	if !exists || saved.isDeleted() {
+		writeJSONError(w, http.StatusNotFound, "Saved lesson not found", "Saved lesson not found")
+		return
+	}
+	if saved.UserID != owner {
+		writeJSONError(w, http.StatusForbidden, "Unauthorized", "Unauthorized")
+		return
+	}
+	if saved.Result == nil || saved.Result.Lesson == "" {
+		writeJSONError(w, http.StatusConflict, "Saved lesson has no content", "The saved lesson has no lesson to start from")
+		return
+	}
+
+	tags, courseID := saved.Tags, saved.CourseID
+	if req.Tags != nil || req.CourseID != "" {
+		var err error
+		tags, courseID, err = normalizeGrouping(req.Tags, req.CourseID)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "Invalid grouping", err.Error())
+			return
+		}
+	}
+
+	explanationType := req.ExplanationType
+	if explanationType == "" {
+		explanationType = saved.ExplanationType
+	}
+
+	// Hold the session to its organization's policy like any other new session
+	var requestedDifficulty string
+	orgID := sessionOrg(r)
+	policy, hasPolicy := o.orgPolicy(orgID)
+	if hasPolicy {
+		var err error
+		explanationType, requestedDifficulty, err = policy.apply(sessionPolicyRequest{
+			Topic:           saved.Topic,
+			ExplanationType: explanationType,
+		})
+		if violation, ok := err.(*orgPolicyViolation); ok {
+			o.writeOrgPolicyViolation(w, orgID, violation)
+			return
+		}
+	}
+
+	if explanationType == "" {
+		explanationType = "standard"
+	}
+	if !o.pipeline.supportsExplanationType(explanationType) {
+		writeJSONError(w, http.StatusBadRequest, "Unsupported explanation type", "The explainer does not support explanation type "+explanationType)
+		return
+	}
+	difficulty, err := normalizeDifficulty(requestedDifficulty, explanationType)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid difficulty", err.Error())
+		return
+	}
+
+	persona, err := llm.ValidatePersona(req.Persona)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid persona", err.Error())
+		return
+	}
+
+	session := o.CreateSession(saved.Topic)
+	o.mu.Lock()
+	session.Metadata["user_id"] = owner
+	session.Metadata["explanation_type"] = explanationType
+	session.Metadata["difficulty"] = difficulty
+	if orgID != "" {
+		session.Metadata["org_id"] = orgID
+	}
+	if hasPolicy {
+		applySessionPolicy(session, policy)
+	}
+	if persona != "" {
+		session.Metadata["persona"] = persona
+	}
+	session.Metadata["warm_start"] = map[string]interface{}{
+		"saved_id":   saved.ID,
+		"session_id": saved.SessionID,
+	}
+	o.mu.Unlock()
+	o.snapshotSessionFlags(r, session)
+	o.setSessionGrouping(session, tags, courseID)
+	o.indexSession(session)
+
+	o.logger.WithFields(logrus.Fields{
+		"session_id": session.ID,
+		"saved_id":   saved.ID,
+		"user_id":    owner,
+	}).Info("Session warm-started from saved lesson")
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CreateSessionResponse{ID: session.ID})
+}
+
+// warmStartLesson returns the saved lesson a session was warm-started from, or nil if it was
+// not warm-started or the lesson has since been deleted
+func (o *Orchestrator) warmStartLesson(session *Session) *SavedLesson {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	warmStart, ok := session.Metadata["warm_start"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	savedID, _ := warmStart["saved_id"].(string)
+	saved, exists := o.savedLessons[savedID]
+	if !exists || saved.isDeleted() || saved.Result == nil {
+		return nil
+	}
+	return saved
+}
+
+// applyWarmStart seeds pipeline steps from a saved lesson: the summarizer's outputs come from
+// the lesson instead of the agent, and the explainer sees the lesson's sections as context
+func applyWarmStart(steps []PipelineStep, saved *SavedLesson) {
+	seeded := map[string]string{}
+	if len(saved.Result.Outline) > 0 {
+		if outline, err := json.Marshal(saved.Result.Outline); err == nil {
+			seeded["outline"] = string(outline)
+		}
+	}
+	if saved.Result.Summary != "" {
+		seeded["summary"] = saved.Result.Summary
+	}
+
+	for i := range steps {
+		switch steps[i].Name {
+		case "summarizer":
+			steps[i].Seeded = seeded
+		case "explainer":
+			steps[i].SeedContext = warmStartContext(saved)
+		}
+	}
+}
+
+// warmStartContext turns a saved lesson into context documents, one per section
+func warmStartContext(saved *SavedLesson) []ContextDoc {
+	doc := func(section, text string) ContextDoc {
+		return ContextDoc{
+			Doc: elastic.Doc{
+				ID:       saved.ID + "#" + section,
+				Topic:    saved.Topic,
+				Section:  section,
+				Text:     text,
+				Metadata: map[string]string{"source": "saved_lesson", "saved_id": saved.ID},
+			},
+			Score:   warmStartContextScore,
+			Snippet: text,
+		}
+	}
+
+	lesson := parseLesson(saved.Result.Lesson)
+	if lesson == nil {
+		return []ContextDoc{doc("Lesson", saved.Result.Lesson)}
+	}
+	var docs []ContextDoc
+	for _, section := range llm.LessonSections {
+		if text, _ := lesson.SectionText(section.ID); strings.TrimSpace(text) != "" {
+			docs = append(docs, doc(section.Title, text))
+		}
+	}
+	return docs
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAgentClient records the inputs of each task it receives, by step
+type recordingAgentClient struct {
+	mu     sync.Mutex
+	inputs map[string]map[string]string
+}
+
+// ExecuteTask implements AgentClient
+func (c *recordingAgentClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (*adk.TaskResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inputs[req.Step] = req.Inputs
+	return &adk.TaskResponse{Artifacts: map[string]string{"lesson": `{"big_picture": "Deeper caching"}`}}, nil
+}
+
+// Health implements AgentClient
+func (c *recordingAgentClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// TestWarmStartFromSavedLesson tests creating a session from a saved lesson and running it without summarizing
+func TestWarmStartFromSavedLesson(t *testing.T) {
+	saved := &SavedLesson{
+		ID:              "saved-1",
+		SessionID:       "old-session",
+		UserID:          "u1",
+		Topic:           "Caching",
+		ExplanationType: "analogy",
+		Tags:            []string{"week-3"},
+		Result: &SessionResult{
+			Lesson:  `{"big_picture": "Caches keep hot data close", "metaphor": "A desk drawer"}`,
+			Summary: "Caching in brief",
+			Outline: []string{"What a cache is", "Eviction"},
+		},
+	}
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: map[string]*SavedLesson{"saved-1": saved},
+		logger:       logrus.New(),
+		clients:      make(map[string][]chan SSEEvent),
+		metaIndex:    newMetadataIndex(),
+	}
+	router := chi.NewRouter()
+	router.Post("/api/sessions/from-saved/{savedID}", o.createSessionFromSavedHandler)
+	owner := withPrincipal(router, &auth.Principal{UserID: "u1", Method: auth.MethodJWT})
+	other := withPrincipal(router, &auth.Principal{UserID: "u2", Method: auth.MethodJWT})
+
+	assert.Equal(t, http.StatusNotFound, serveWithKey(owner, "POST", "/api/sessions/from-saved/missing", "", `{}`).Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(other, "POST", "/api/sessions/from-saved/saved-1", "", `{"user_id": "u1"}`).Code, "the body cannot claim ownership")
+	assert.Equal(t, http.StatusForbidden, serveWithKey(router, "POST", "/api/sessions/from-saved/saved-1", "", `{"user_id": "u1"}`).Code)
+
+	w := serveWithKey(owner, "POST", "/api/sessions/from-saved/saved-1", "", `{}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	session, ok := o.GetSession(created.ID)
+	require.True(t, ok)
+	assert.Equal(t, "u1", session.Metadata["user_id"])
+	assert.Equal(t, "Caching", session.Topic)
+	assert.Equal(t, "analogy", session.Metadata["explanation_type"])
+	assert.Equal(t, []string{"week-3"}, session.Tags)
+
+	agent := &recordingAgentClient{inputs: make(map[string]map[string]string)}
+	config := DefaultPipelineConfig()
+	config.RetryDelay = time.Millisecond
+	p := &Pipeline{
+		config:     config,
+		logger:     logrus.New(),
+		adkClients: map[string]AgentClient{"summarizer": agent, "explainer": agent, "visualizer": agent, "critic": agent},
+	}
+	require.NoError(t, p.runPipeline(context.Background(), created.ID, o))
+
+	_, summarized := agent.inputs["summarizer"]
+	assert.False(t, summarized, "the summarizer agent is skipped")
+	explainer := agent.inputs["explainer"]
+	assert.Equal(t, `["What a cache is","Eviction"]`, explainer["outline"])
+	assert.Contains(t, explainer["context"], "Section: Big Picture\nContent: Caches keep hot data close")
+	assert.Contains(t, explainer["context"], "Section: Metaphor\nContent: A desk drawer")
+
+	session, _ = o.GetSession(created.ID)
+	assert.Equal(t, "completed", session.Status)
+	assert.Equal(t, "Caching in brief", session.Result.Summary)
+	assert.Equal(t, []string{"What a cache is", "Eviction"}, session.Result.Outline)
+}
+
+// TestWarmStartEnforcesOrgPolicy tests that warm-started sessions are held to the caller's organization policy
+func TestWarmStartEnforcesOrgPolicy(t *testing.T) {
+	saved := &SavedLesson{
+		ID:              "saved-1",
+		UserID:          "u1",
+		Topic:           "Caching",
+		ExplanationType: "analogy",
+		Result:          &SessionResult{Lesson: `{"big_picture": "Caches keep hot data close"}`},
+	}
+	o := &Orchestrator{
+		sessions:     make(map[string]*Session),
+		savedLessons: map[string]*SavedLesson{"saved-1": saved},
+		orgPolicies: map[string]*OrgPolicy{"org-1": {
+			OrgID:                   "org-1",
+			AllowedExplanationTypes: []string{"simple"},
+			Difficulty:              DifficultyBeginner,
+			Language:                "French",
+		}},
+		logger:    logrus.New(),
+		clients:   make(map[string][]chan SSEEvent),
+		metaIndex: newMetadataIndex(),
+	}
+	router := chi.NewRouter()
+	router.Post("/api/sessions/from-saved/{savedID}", o.createSessionFromSavedHandler)
+	member := withPrincipal(router, &auth.Principal{UserID: "u1", OrgID: "org-1", Method: auth.MethodJWT})
+
+	w := serveWithKey(member, "POST", "/api/sessions/from-saved/saved-1", "", `{}`)
+	require.Equal(t, http.StatusForbidden, w.Code, "the saved lesson's explanation type is not allowed")
+	assert.Contains(t, w.Body.String(), `"rule":"allowed_explanation_types"`)
+
+	w = serveWithKey(member, "POST", "/api/sessions/from-saved/saved-1", "", `{"explanation_type": "simple"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	session, ok := o.GetSession(created.ID)
+	require.True(t, ok)
+	assert.Equal(t, "org-1", session.Metadata["org_id"])
+	assert.Equal(t, DifficultyBeginner, session.Metadata["difficulty"])
+	assert.Equal(t, "French", session.Metadata["language"])
+
+	o.orgPolicies["org-1"].BannedTopics = []string{"caching"}
+	w = serveWithKey(member, "POST", "/api/sessions/from-saved/saved-1", "", `{"explanation_type": "simple"}`)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"rule":"banned_topics"`)
+}
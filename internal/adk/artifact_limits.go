@@ -0,0 +1,112 @@
+package adk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default artifact size caps. Lessons are tens of kilobytes; anything near these sizes is
+// almost always inlined image data.
+const (
+	defaultMaxArtifactBytes = 1 << 20 // 1 MiB
+	defaultMaxResponseBytes = 4 << 20 // 4 MiB
+)
+
+// artifactRefPrefix marks an artifact whose content was spilled to an artifact store
+const artifactRefPrefix = "artifact-ref:"
+
+// ErrArtifactTooLarge is returned when an artifact exceeds the size caps and cannot be spilled
+var ErrArtifactTooLarge = errors.New("artifact exceeds size limit")
+
+// ArtifactLimits caps the size of artifacts carried inline in a TaskResponse
+type ArtifactLimits struct {
+	MaxArtifactBytes int // Largest single artifact kept inline; 0 disables the cap
+	MaxResponseBytes int // Largest total of inline artifacts; 0 disables the cap
+}
+
+// DefaultArtifactLimits returns the default artifact size caps
+func DefaultArtifactLimits() ArtifactLimits {
+	return ArtifactLimits{MaxArtifactBytes: defaultMaxArtifactBytes, MaxResponseBytes: defaultMaxResponseBytes}
+}
+
+// ArtifactLimitsFromEnv reads ADK_MAX_ARTIFACT_BYTES and ADK_MAX_RESPONSE_BYTES, falling back to
+// the defaults; 0 disables a cap
+func ArtifactLimitsFromEnv() ArtifactLimits {
+	limits := DefaultArtifactLimits()
+	if n, err := strconv.Atoi(os.Getenv("ADK_MAX_ARTIFACT_BYTES")); err == nil && n >= 0 {
+		limits.MaxArtifactBytes = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("ADK_MAX_RESPONSE_BYTES")); err == nil && n >= 0 {
+		limits.MaxResponseBytes = n
+	}
+	return limits
+}
+
+// ArtifactSpiller stores oversized artifact content out of band, returning the object it was stored as
+type ArtifactSpiller interface {
+	SpillArtifact(ctx context.Context, name, content string) (string, error)
+}
+
+// ArtifactRef returns the inline reference to an artifact spilled as object
+func ArtifactRef(object string) string {
+	return artifactRefPrefix + object
+}
+
+// ParseArtifactRef returns the object an artifact reference points to
+func ParseArtifactRef(content string) (string, bool) {
+	if !strings.HasPrefix(content, artifactRefPrefix) {
+		return "", false
+	}
+	object := strings.TrimPrefix(content, artifactRefPrefix)
+	return object, object != ""
+}
+
+// EnforceArtifactLimits spills artifacts over MaxArtifactBytes, then the largest remaining
+// artifacts until the inline total fits MaxResponseBytes, replacing each with a reference.
+// It returns the names of the spilled artifacts. Without a spiller, an artifact over the caps
+// fails with ErrArtifactTooLarge.
+func (r *TaskResponse) EnforceArtifactLimits(ctx context.Context, limits ArtifactLimits, spiller ArtifactSpiller) ([]string, error) {
+	names := make([]string, 0, len(r.Artifacts))
+	total := 0
+	for name, content := range r.Artifacts {
+		names = append(names, name)
+		total += len(content)
+	}
+	// Largest first, so the fewest artifacts are spilled to fit the response cap
+	sort.Slice(names, func(i, j int) bool {
+		if len(r.Artifacts[names[i]]) != len(r.Artifacts[names[j]]) {
+			return len(r.Artifacts[names[i]]) > len(r.Artifacts[names[j]])
+		}
+		return names[i] < names[j]
+	})
+
+	var spilled []string
+	for _, name := range names {
+		content := r.Artifacts[name]
+		overArtifact := limits.MaxArtifactBytes > 0 && len(content) > limits.MaxArtifactBytes
+		overResponse := limits.MaxResponseBytes > 0 && total > limits.MaxResponseBytes
+		if !overArtifact && !overResponse {
+			continue
+		}
+		if _, isRef := ParseArtifactRef(content); isRef {
+			continue
+		}
+		if spiller == nil {
+			return spilled, fmt.Errorf("%w: %s is %d bytes and no artifact store is configured", ErrArtifactTooLarge, name, len(content))
+		}
+		object, err := spiller.SpillArtifact(ctx, name, content)
+		if err != nil {
+			return spilled, fmt.Errorf("failed to spill artifact %s: %w", name, err)
+		}
+		ref := ArtifactRef(object)
+		r.Artifacts[name] = ref
+		total += len(ref) - len(content)
+		spilled = append(spilled, name)
+	}
+	return spilled, nil
+}
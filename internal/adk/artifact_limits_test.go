@@ -0,0 +1,71 @@
+package adk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// memorySpiller keeps spilled artifacts in memory
+type memorySpiller map[string]string
+
+// SpillArtifact implements ArtifactSpiller
+func (m memorySpiller) SpillArtifact(ctx context.Context, name, content string) (string, error) {
+	object := "spilled/" + name
+	m[object] = content
+	return object, nil
+}
+
+// TestEnforceArtifactLimits tests that oversized artifacts are replaced by references to spilled content
+func TestEnforceArtifactLimits(t *testing.T) {
+	images := strings.Repeat("i", 300)
+	response := &TaskResponse{Artifacts: map[string]string{
+		"images":  images,
+		"lesson":  strings.Repeat("l", 150),
+		"outline": "short",
+	}}
+	spiller := memorySpiller{}
+
+	spilled, err := response.EnforceArtifactLimits(context.Background(), ArtifactLimits{MaxArtifactBytes: 200, MaxResponseBytes: 250}, spiller)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(spilled) != 1 || spilled[0] != "images" {
+		t.Fatalf("Expected only images to be spilled, got %v", spilled)
+	}
+	object, ok := ParseArtifactRef(response.Artifacts["images"])
+	if !ok || spiller[object] != images {
+		t.Errorf("Expected images to reference the spilled content, got %q", response.Artifacts["images"])
+	}
+	if response.Artifacts["outline"] != "short" {
+		t.Error("Expected small artifacts to stay inline")
+	}
+
+	// Over the response cap, the largest artifacts are spilled until the rest fit
+	spilled, err = response.EnforceArtifactLimits(context.Background(), ArtifactLimits{MaxResponseBytes: 100}, spiller)
+	if err != nil || len(spilled) != 1 || spilled[0] != "lesson" {
+		t.Errorf("Expected the lesson to be spilled to fit the response cap, got %v (%v)", spilled, err)
+	}
+}
+
+// TestEnforceArtifactLimitsWithoutSpiller tests that oversized artifacts fail without an artifact store
+func TestEnforceArtifactLimitsWithoutSpiller(t *testing.T) {
+	response := &TaskResponse{Artifacts: map[string]string{"images": strings.Repeat("i", 300)}}
+	if _, err := response.EnforceArtifactLimits(context.Background(), ArtifactLimits{MaxArtifactBytes: 200}, nil); !errors.Is(err, ErrArtifactTooLarge) {
+		t.Errorf("Expected ErrArtifactTooLarge, got %v", err)
+	}
+	if _, err := response.EnforceArtifactLimits(context.Background(), ArtifactLimits{}, nil); err != nil {
+		t.Errorf("Expected no caps to accept any size, got %v", err)
+	}
+}
+
+// TestArtifactLimitsFromEnv tests the size cap overrides
+func TestArtifactLimitsFromEnv(t *testing.T) {
+	t.Setenv("ADK_MAX_ARTIFACT_BYTES", "1024")
+	t.Setenv("ADK_MAX_RESPONSE_BYTES", "nope")
+	limits := ArtifactLimitsFromEnv()
+	if limits.MaxArtifactBytes != 1024 || limits.MaxResponseBytes != defaultMaxResponseBytes {
+		t.Errorf("Unexpected limits %+v", limits)
+	}
+}
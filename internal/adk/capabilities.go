@@ -0,0 +1,72 @@
+package adk
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CapabilitiesExtensionURI identifies the ExplainIQ capabilities extension in an A2A AgentCard
+const CapabilitiesExtensionURI = "https://explainiq.dev/a2a/extensions/capabilities/v1"
+
+// EndpointCapabilities serves an agent's capabilities as JSON over the plain HTTP contract
+const EndpointCapabilities = "/capabilities"
+
+// Capabilities describes what an agent can do, so callers can check step assignments
+// before sending it work
+type Capabilities struct {
+	Steps            []string `json:"steps"`                        // Pipeline steps the agent processes
+	ExplanationTypes []string `json:"explanation_types,omitempty"`  // Empty means the agent does not vary by explanation type
+	MaxContextChars  int      `json:"max_context_chars,omitempty"`  // Largest "context" input accepted; 0 means no limit
+	MaxContextTokens int      `json:"max_context_tokens,omitempty"` // Token budget of the "context" input; 0 means no limit
+	Streaming        bool     `json:"streaming"`                    // Whether the transport streams responses
+	ProtocolVersions []string `json:"protocol_versions,omitempty"`  // ADK protocol versions spoken; empty means ProtocolVersion1 only
+}
+
+// CapabilityReporter is implemented by task processors that advertise their capabilities
+type CapabilityReporter interface {
+	Capabilities() Capabilities
+}
+
+// SupportsStep reports whether the agent processes the given step
+func (c Capabilities) SupportsStep(step string) bool {
+	for _, s := range c.Steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsExplanationType reports whether the agent writes in the given explanation type
+func (c Capabilities) SupportsExplanationType(explanationType string) bool {
+	if len(c.ExplanationTypes) == 0 {
+		return true
+	}
+	for _, t := range c.ExplanationTypes {
+		if t == explanationType {
+			return true
+		}
+	}
+	return false
+}
+
+// Params returns the capabilities as AgentCard extension parameters
+func (c Capabilities) Params() map[string]any {
+	data, _ := json.Marshal(c)
+	var params map[string]any
+	json.Unmarshal(data, &params)
+	return params
+}
+
+// CapabilitiesFromParams parses capabilities from AgentCard extension parameters
+func CapabilitiesFromParams(params map[string]any) (*Capabilities, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode capability params: %w", err)
+	}
+	var capabilities Capabilities
+	if err := json.Unmarshal(data, &capabilities); err != nil {
+		return nil, fmt.Errorf("invalid capability params: %w", err)
+	}
+	return &capabilities, nil
+}
@@ -0,0 +1,39 @@
+package adk
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCapabilitiesParamsRoundTrip tests encoding capabilities as AgentCard extension params
+func TestCapabilitiesParamsRoundTrip(t *testing.T) {
+	capabilities := Capabilities{
+		Steps:            []string{"explainer"},
+		ExplanationTypes: []string{"standard", "analogy"},
+		MaxContextChars:  32000,
+		Streaming:        true,
+	}
+
+	parsed, err := CapabilitiesFromParams(capabilities.Params())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(capabilities, *parsed) {
+		t.Errorf("Expected %+v, got %+v", capabilities, *parsed)
+	}
+	if !parsed.SupportsStep("explainer") || parsed.SupportsStep("critic") {
+		t.Error("Expected only the explainer step to be supported")
+	}
+	if !parsed.SupportsExplanationType("analogy") || parsed.SupportsExplanationType("visualization") {
+		t.Error("Expected only the listed explanation types to be supported")
+	}
+
+	// Agents that do not vary by explanation type accept any
+	if !(Capabilities{Steps: []string{"critic"}}).SupportsExplanationType("analogy") {
+		t.Error("Expected an empty explanation type list to accept any type")
+	}
+
+	if _, err := CapabilitiesFromParams(map[string]any{"steps": "explainer"}); err == nil {
+		t.Error("Expected an error for malformed params")
+	}
+}
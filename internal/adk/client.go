@@ -5,11 +5,14 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
+	mrand "math/rand"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,7 +34,8 @@ type Client struct {
 func NewClient(baseURL string, options ...ClientOption) *Client {
 	client := &Client{
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: SharedTransport(),
 		},
 		logger:        logrus.New(),
 		config:        DefaultTaskConfig(),
@@ -132,12 +136,15 @@ func (c *Client) DoTask(ctx context.Context, url string, req TaskRequest) (TaskR
 
 	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
 		if attempt > 0 {
-			// Calculate backoff delay
-			delay := c.calculateBackoffDelay(attempt)
+			// Calculate backoff delay, honoring the server's Retry-After if it sent one
+			retryAfter := retryAfterFromError(err)
+			delay := c.retryDelay(attempt, retryAfter)
+			metadata.RecordRetryDelay(delay, retryAfter)
 			c.logger.WithFields(logrus.Fields{
-				"task_id": metadata.TaskID,
-				"attempt": attempt,
-				"delay":   delay,
+				"task_id":     metadata.TaskID,
+				"attempt":     attempt,
+				"delay":       delay,
+				"retry_after": retryAfter,
 			}).Warn("Retrying task execution")
 
 			// Wait for backoff delay
@@ -157,13 +164,22 @@ func (c *Client) DoTask(ctx context.Context, url string, req TaskRequest) (TaskR
 		if err == nil {
 			// Success
 			metadata.UpdateStatus(TaskStatusCompleted)
+			if len(metadata.RetryDelays) > 0 {
+				// Report time spent backing off so callers can tell slow agents from throttled ones
+				response.AddMetric("retry_count", len(metadata.RetryDelays))
+				response.AddMetric("retry_delay_ms", metadata.TotalRetryDelay.Milliseconds())
+				if metadata.LastRetryAfter > 0 {
+					response.AddMetric("retry_after_ms", metadata.LastRetryAfter.Milliseconds())
+				}
+			}
 			metadata.Outputs = response.Artifacts
 			metadata.Metrics = response.Metrics
 
 			c.logger.WithFields(logrus.Fields{
-				"task_id":  metadata.TaskID,
-				"attempt":  attempt + 1,
-				"duration": metadata.Duration,
+				"task_id":           metadata.TaskID,
+				"attempt":           attempt + 1,
+				"duration":          metadata.Duration,
+				"total_retry_delay": metadata.TotalRetryDelay,
 			}).Info("Task completed successfully")
 
 			return response, nil
@@ -238,6 +254,7 @@ func (c *Client) executeTask(ctx context.Context, url string, req TaskRequest, i
 	httpReq.Header.Set("X-Session-ID", req.SessionID)
 	httpReq.Header.Set("X-Step", req.Step)
 	httpReq.Header.Set("X-Topic", req.Topic)
+	httpReq.Header.Set(VersionHeader, ProtocolVersion)
 
 	if c.apiKey != "" {
 		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
@@ -275,12 +292,14 @@ func (c *Client) executeTask(ctx context.Context, url string, req TaskRequest, i
 
 	// Check for HTTP errors
 	if resp.StatusCode >= 400 {
-		return TaskResponse{}, c.handleHTTPError(resp.StatusCode, responseBody, metadata)
+		taskErr := c.handleHTTPError(resp.StatusCode, responseBody, metadata)
+		taskErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return TaskResponse{}, taskErr
 	}
 
-	// Parse response
-	var taskResponse TaskResponse
-	if err := json.Unmarshal(responseBody, &taskResponse); err != nil {
+	// Parse response, failing on fields outside the protocol version rather than dropping them
+	taskResponse, err := DecodeTaskResponse(responseBody, ProtocolVersion)
+	if err != nil {
 		return TaskResponse{}, &TaskError{
 			Code:    "DESERIALIZATION_ERROR",
 			Message: "Failed to unmarshal response",
@@ -308,13 +327,40 @@ func (c *Client) generateIdempotencyKey(req TaskRequest) string {
 	return fmt.Sprintf("%x-%s", randomBytes, keyData)
 }
 
-// calculateBackoffDelay calculates the backoff delay for retries
+// retryDelay returns how long to wait before a retry. A server Retry-After takes precedence
+// over the computed backoff; either way the delay is capped at MaxRetryDelay.
+func (c *Client) retryDelay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return c.capRetryDelay(retryAfter)
+	}
+	return c.calculateBackoffDelay(attempt)
+}
+
+// capRetryDelay limits a delay to MaxRetryDelay when a cap is configured
+func (c *Client) capRetryDelay(delay time.Duration) time.Duration {
+	if c.config.MaxRetryDelay > 0 && delay > c.config.MaxRetryDelay {
+		return c.config.MaxRetryDelay
+	}
+	return delay
+}
+
+// calculateBackoffDelay calculates the backoff delay for retries, capped at MaxRetryDelay.
+// With Jitter enabled it returns a random delay in [0, backoff] ("full jitter") so that
+// clients failing together do not retry in lockstep.
 func (c *Client) calculateBackoffDelay(attempt int) time.Duration {
+	delay := c.capRetryDelay(c.baseBackoffDelay(attempt))
+	if c.config.Jitter && delay > 0 {
+		delay = time.Duration(mrand.Int63n(int64(delay) + 1))
+	}
+	return delay
+}
+
+// baseBackoffDelay calculates the un-jittered backoff delay for the configured strategy
+func (c *Client) baseBackoffDelay(attempt int) time.Duration {
 	switch c.config.BackoffType {
 	case "exponential":
 		// Exponential backoff: delay * 2^attempt
-		delay := float64(c.config.RetryDelay) * math.Pow(2, float64(attempt-1))
-		return time.Duration(delay)
+		return exponentialDelay(c.config.RetryDelay, attempt)
 	case "linear":
 		// Linear backoff: delay * attempt
 		return c.config.RetryDelay * time.Duration(attempt)
@@ -323,9 +369,49 @@ func (c *Client) calculateBackoffDelay(attempt int) time.Duration {
 		return c.config.RetryDelay
 	default:
 		// Default to exponential
-		delay := float64(c.config.RetryDelay) * math.Pow(2, float64(attempt-1))
-		return time.Duration(delay)
+		return exponentialDelay(c.config.RetryDelay, attempt)
+	}
+}
+
+// exponentialDelay returns base * 2^(attempt-1), saturating instead of overflowing
+func exponentialDelay(base time.Duration, attempt int) time.Duration {
+	delay := float64(base) * math.Pow(2, float64(attempt-1))
+	if delay >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// retryAfterFromError returns the Retry-After carried by a (possibly wrapped) TaskError
+func retryAfterFromError(err error) time.Duration {
+	var taskErr *TaskError
+	if errors.As(err, &taskErr) {
+		return taskErr.RetryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter parses a Retry-After header given as delay-seconds or an HTTP date.
+// It returns 0 if the header is absent, invalid or already in the past.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(header); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay
+		}
 	}
+	return 0
 }
 
 // handleHTTPError handles HTTP error responses
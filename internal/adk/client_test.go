@@ -504,6 +504,148 @@ func TestBackoffDelayCalculation(t *testing.T) {
 	}
 }
 
+// TestBackoffDelayCapAndJitter tests the max delay cap and full jitter
+func TestBackoffDelayCapAndJitter(t *testing.T) {
+	client := NewClient("https://api.example.com")
+	client.SetConfig(TaskConfig{
+		RetryDelay:    1 * time.Second,
+		BackoffType:   "exponential",
+		MaxRetryDelay: 5 * time.Second,
+	})
+
+	if delay := client.calculateBackoffDelay(10); delay != 5*time.Second {
+		t.Errorf("Expected delay capped at 5s, got %v", delay)
+	}
+
+	client.SetConfig(TaskConfig{
+		RetryDelay:    1 * time.Second,
+		BackoffType:   "exponential",
+		MaxRetryDelay: 5 * time.Second,
+		Jitter:        true,
+	})
+
+	distinct := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		delay := client.calculateBackoffDelay(3)
+		if delay < 0 || delay > 4*time.Second {
+			t.Fatalf("Expected jittered delay in [0, 4s], got %v", delay)
+		}
+		distinct[delay] = true
+	}
+	if len(distinct) < 2 {
+		t.Error("Expected jittered delays to vary")
+	}
+}
+
+// TestParseRetryAfter tests Retry-After header parsing
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header   string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{" 10 ", 10 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(7 * time.Second).Format(http.TimeFormat), 7 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header, now); got != tt.expected {
+			t.Errorf("parseRetryAfter(%q) = %v, expected %v", tt.header, got, tt.expected)
+		}
+	}
+}
+
+// TestRetryDelayHonorsRetryAfter tests that Retry-After overrides backoff and is capped
+func TestRetryDelayHonorsRetryAfter(t *testing.T) {
+	client := NewClient("https://api.example.com")
+	client.SetConfig(TaskConfig{
+		RetryDelay:    1 * time.Second,
+		BackoffType:   "fixed",
+		MaxRetryDelay: 10 * time.Second,
+		Jitter:        true,
+	})
+
+	if delay := client.retryDelay(1, 4*time.Second); delay != 4*time.Second {
+		t.Errorf("Expected Retry-After delay 4s, got %v", delay)
+	}
+	if delay := client.retryDelay(1, time.Minute); delay != 10*time.Second {
+		t.Errorf("Expected Retry-After capped at 10s, got %v", delay)
+	}
+}
+
+// TestDoTaskRetryAfter tests that a 503 Retry-After is honored and recorded
+func TestDoTaskRetryAfter(t *testing.T) {
+	attemptCount := 0
+	var firstAttempt, secondAttempt time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptCount++
+		if attemptCount == 1 {
+			firstAttempt = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		secondAttempt = time.Now()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(TaskResponse{Delta: "ok"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithConfig(TaskConfig{
+		Timeout:     5 * time.Second,
+		MaxRetries:  2,
+		RetryDelay:  10 * time.Millisecond,
+		BackoffType: "fixed",
+		Jitter:      true,
+	}))
+
+	req := TaskRequest{SessionID: "s", Step: "retry-after", Topic: "t"}
+	response, err := client.DoTask(context.Background(), server.URL+"/task", req)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if attemptCount != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", attemptCount)
+	}
+	if waited := secondAttempt.Sub(firstAttempt); waited < 900*time.Millisecond {
+		t.Errorf("Expected to wait about 1s for Retry-After, waited %v", waited)
+	}
+	if response.Metrics["retry_count"] != 1 {
+		t.Errorf("Expected retry_count metric 1, got %v", response.Metrics["retry_count"])
+	}
+	if delay, _ := response.Metrics["retry_delay_ms"].(int64); delay < 900 {
+		t.Errorf("Expected retry_delay_ms metric of about 1000, got %v", response.Metrics["retry_delay_ms"])
+	}
+	if response.Metrics["retry_after_ms"] != int64(1000) {
+		t.Errorf("Expected retry_after_ms metric 1000, got %v", response.Metrics["retry_after_ms"])
+	}
+}
+
+// TestRecordRetryDelay tests retry timing in task metadata
+func TestRecordRetryDelay(t *testing.T) {
+	metadata := &TaskMetadata{}
+	metadata.RecordRetryDelay(100*time.Millisecond, 0)
+	metadata.RecordRetryDelay(2*time.Second, 2*time.Second)
+
+	if len(metadata.RetryDelays) != 2 {
+		t.Fatalf("Expected 2 recorded delays, got %d", len(metadata.RetryDelays))
+	}
+	if metadata.TotalRetryDelay != 2100*time.Millisecond {
+		t.Errorf("Expected total retry delay 2.1s, got %v", metadata.TotalRetryDelay)
+	}
+	if metadata.LastRetryAfter != 2*time.Second {
+		t.Errorf("Expected last Retry-After 2s, got %v", metadata.LastRetryAfter)
+	}
+}
+
 // TestTaskRequestValidation tests TaskRequest validation
 func TestTaskRequestValidation(t *testing.T) {
 	// Test valid request
@@ -42,9 +42,10 @@ const (
 
 // TaskError represents an error that occurred during task execution
 type TaskError struct {
-	Code    string `json:"code"`    // Error code
-	Message string `json:"message"` // Error message
-	Details string `json:"details"` // Additional error details
+	Code       string        `json:"code"`                  // Error code
+	Message    string        `json:"message"`               // Error message
+	Details    string        `json:"details"`               // Additional error details
+	RetryAfter time.Duration `json:"retry_after,omitempty"` // Server-requested delay before retrying (Retry-After)
 }
 
 // TaskMetadata represents metadata about a task execution
@@ -67,23 +68,31 @@ type TaskMetadata struct {
 	Metrics        map[string]interface{} `json:"metrics"`         // Execution metrics
 	CorrelationID  string                 `json:"correlation_id"`  // Request correlation ID
 	IdempotencyKey string                 `json:"idempotency_key"` // Idempotency key
+
+	RetryDelays     []time.Duration `json:"retry_delays,omitempty"`     // Delay waited before each retry
+	TotalRetryDelay time.Duration   `json:"total_retry_delay"`          // Total time spent waiting between attempts
+	LastRetryAfter  time.Duration   `json:"last_retry_after,omitempty"` // Most recent server Retry-After honored
 }
 
 // TaskConfig represents configuration for task execution
 type TaskConfig struct {
-	Timeout     time.Duration `json:"timeout"`      // Task timeout
-	MaxRetries  int           `json:"max_retries"`  // Maximum retries
-	RetryDelay  time.Duration `json:"retry_delay"`  // Initial retry delay
-	BackoffType string        `json:"backoff_type"` // Backoff strategy (exponential, linear, fixed)
+	Timeout       time.Duration `json:"timeout"`         // Task timeout
+	MaxRetries    int           `json:"max_retries"`     // Maximum retries
+	RetryDelay    time.Duration `json:"retry_delay"`     // Initial retry delay
+	BackoffType   string        `json:"backoff_type"`    // Backoff strategy (exponential, linear, fixed)
+	MaxRetryDelay time.Duration `json:"max_retry_delay"` // Cap on any single retry delay (0 = no cap)
+	Jitter        bool          `json:"jitter"`          // Apply full jitter to computed backoff delays
 }
 
 // DefaultTaskConfig returns the default task configuration
 func DefaultTaskConfig() TaskConfig {
 	return TaskConfig{
-		Timeout:     30 * time.Second,
-		MaxRetries:  3,
-		RetryDelay:  1 * time.Second,
-		BackoffType: "exponential",
+		Timeout:       30 * time.Second,
+		MaxRetries:    3,
+		RetryDelay:    1 * time.Second,
+		BackoffType:   "exponential",
+		MaxRetryDelay: 30 * time.Second,
+		Jitter:        true,
 	}
 }
 
@@ -194,6 +203,15 @@ func (tm *TaskMetadata) IncrementRetry() {
 	tm.Status = TaskStatusRetrying
 }
 
+// RecordRetryDelay records the delay waited before a retry and the Retry-After that produced it, if any
+func (tm *TaskMetadata) RecordRetryDelay(delay, retryAfter time.Duration) {
+	tm.RetryDelays = append(tm.RetryDelays, delay)
+	tm.TotalRetryDelay += delay
+	if retryAfter > 0 {
+		tm.LastRetryAfter = retryAfter
+	}
+}
+
 // CanRetry checks if the task can be retried
 func (tm *TaskMetadata) CanRetry() bool {
 	return tm.RetryCount < tm.MaxRetries &&
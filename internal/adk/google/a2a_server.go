@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2asrv"
 	"google.golang.org/adk/agent"
@@ -23,6 +24,9 @@ type A2AServer struct {
 	baseURL   *url.URL
 	listener  net.Listener
 	server    *http.Server
+	handlers  map[string]http.Handler
+	capabilities *adk.Capabilities // Advertised in the AgentCard when set
+	idempotency *adk.IdempotencyCache // Replays responses to duplicate task deliveries when set
 	logger    interface {
 		Infof(format string, args ...interface{})
 		Errorf(format string, args ...interface{})
@@ -67,13 +71,29 @@ func NewA2AServer(agent agent.Agent, port string, logger interface {
 		port:     port,
 		baseURL:  baseURL,
 		listener: listener,
+		handlers: make(map[string]http.Handler),
 		logger:   logger,
 	}, nil
 }
 
-// Start starts the A2A server
-func (s *A2AServer) Start() error {
-	// Create AgentCard
+// Handle registers an additional HTTP handler to be served alongside the A2A endpoints
+func (s *A2AServer) Handle(pattern string, handler http.Handler) {
+	s.handlers[pattern] = handler
+}
+
+// SetCapabilities sets the steps, explanation types and context size advertised in the AgentCard
+func (s *A2AServer) SetCapabilities(capabilities adk.Capabilities) {
+	s.capabilities = &capabilities
+}
+
+// SetIdempotencyCache serves /invoke through cache, so duplicate task deliveries return the
+// original response without re-running the agent
+func (s *A2AServer) SetIdempotencyCache(cache *adk.IdempotencyCache) {
+	s.idempotency = cache
+}
+
+// agentCard builds the AgentCard, advertising the agent's capabilities as an extension
+func (s *A2AServer) agentCard() *a2a.AgentCard {
 	agentCard := &a2a.AgentCard{
 		Name:               s.agent.Name(),
 		Skills:             adka2a.BuildAgentSkills(s.agent),
@@ -81,6 +101,24 @@ func (s *A2AServer) Start() error {
 		URL:                s.baseURL.JoinPath("/invoke").String(),
 		Capabilities:      a2a.AgentCapabilities{Streaming: true},
 	}
+	if s.capabilities != nil {
+		capabilities := *s.capabilities
+		capabilities.Streaming = agentCard.Capabilities.Streaming
+		if len(capabilities.ProtocolVersions) == 0 {
+			capabilities.ProtocolVersions = adk.SupportedProtocolVersions
+		}
+		agentCard.Capabilities.Extensions = []a2a.AgentExtension{{
+			URI:         adk.CapabilitiesExtensionURI,
+			Description: "Pipeline steps, explanation types, context size and ADK protocol versions this agent supports",
+			Params:      capabilities.Params(),
+		}}
+	}
+	return agentCard
+}
+
+// Start starts the A2A server
+func (s *A2AServer) Start() error {
+	agentCard := s.agentCard()
 
 	// Create executor
 	executor := adka2a.NewExecutor(adka2a.ExecutorConfig{
@@ -96,7 +134,7 @@ func (s *A2AServer) Start() error {
 	mux.Handle(a2asrv.WellKnownAgentCardPath, a2asrv.NewStaticAgentCardHandler(agentCard))
 
 	requestHandler := a2asrv.NewHandler(executor)
-	mux.Handle("/invoke", a2asrv.NewJSONRPCHandler(requestHandler))
+	mux.Handle("/invoke", adk.VersionMiddleware(s.idempotency.Wrap(a2asrv.NewJSONRPCHandler(requestHandler))))
 
 	// Health check endpoint
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
@@ -104,6 +142,11 @@ func (s *A2AServer) Start() error {
 		w.Write([]byte("OK"))
 	})
 
+	// Additional handlers registered by the caller (metrics, etc.)
+	for pattern, handler := range s.handlers {
+		mux.Handle(pattern, handler)
+	}
+
 	s.logger.Infof("A2A server started on %s", s.baseURL.String())
 	s.logger.Infof("AgentCard available at %s", s.baseURL.JoinPath(a2asrv.WellKnownAgentCardPath).String())
 
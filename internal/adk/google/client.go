@@ -5,29 +5,40 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
 	authclient "github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/remoteagent"
 	"google.golang.org/adk/session"
 )
 
+// metadataLimits bounds the capabilities and AgentCard documents agents serve
+var metadataLimits = adk.JSONLimits{MaxBytes: adk.MaxAgentMetadataBytes, MaxDepth: adk.DefaultMaxJSONDepth}
+
+// unmarshalText decodes JSON an agent returned inside message text, which the outer
+// response's depth check cannot see into
+func unmarshalText(text string, v interface{}) error {
+	return adk.UnmarshalJSON([]byte(text), v, adk.JSONLimits{MaxDepth: adk.DefaultMaxJSONDepth})
+}
+
 // Client represents a Google ADK-compatible client for communicating with agents
 // This follows Google ADK patterns for agent-to-agent communication
 // It can use either HTTP REST (legacy) or A2A protocol (Google ADK)
 type Client struct {
-	baseURL     string
-	httpClient  *http.Client
-	logger      *logrus.Logger
-	authClient  *authclient.Client
-	useA2A      bool
-	remoteAgent agent.Agent
+	baseURL         string
+	httpClient      *http.Client
+	logger          *logrus.Logger
+	authClient      *authclient.Client
+	useA2A          bool
+	remoteAgent     agent.Agent
+	protocolVersion string // ADK protocol version sent with tasks, settled by NegotiateProtocolVersion
 }
 
 // NewClient creates a new Google ADK-compatible client
@@ -40,10 +51,12 @@ func NewClient(baseURL string) *Client {
 	client := &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: adk.SharedTransport(),
 		},
-		logger: logrus.New(),
-		useA2A: useA2A,
+		logger:          logrus.New(),
+		useA2A:          useA2A,
+		protocolVersion: adk.ProtocolVersion,
 	}
 
 	// If using A2A, create remote agent
@@ -158,7 +171,10 @@ func (c *Client) executeTaskJSONRPC(ctx context.Context, req *adk.TaskRequest) (
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("User-Agent", "ExplainIQ-Google-ADK-Client/1.0")
-	httpReq.Header.Set("X-ADK-Version", "1.0")
+	httpReq.Header.Set(adk.VersionHeader, c.protocolVersion)
+	if key := adk.IdempotencyKeyFromContext(ctx); key != "" {
+		httpReq.Header.Set(adk.IdempotencyHeader, key)
+	}
 
 	// Add authentication if available (for Cloud Run service-to-service auth)
 	if c.authClient != nil && strings.HasPrefix(c.baseURL, "https://") {
@@ -183,8 +199,8 @@ func (c *Client) executeTaskJSONRPC(ctx context.Context, req *adk.TaskRequest) (
 	}
 	defer resp.Body.Close()
 
-	// Read response
-	responseBody, err := io.ReadAll(resp.Body)
+	// Read response, bounded so a misbehaving agent cannot exhaust memory
+	responseBody, err := adk.ReadLimited(resp.Body, adk.MaxAgentResponseBytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
@@ -213,10 +229,14 @@ func (c *Client) executeTaskJSONRPC(ctx context.Context, req *adk.TaskRequest) (
 		} `json:"error,omitempty"`
 	}
 
-	if err := json.Unmarshal(responseBody, &jsonRPCResponse); err != nil {
+	if err := adk.UnmarshalJSON(responseBody, &jsonRPCResponse, adk.JSONLimits{MaxDepth: adk.DefaultMaxJSONDepth}); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal JSON-RPC response: %w", err)
 	}
 
+	if err := c.checkResponseVersion(resp); err != nil {
+		return nil, err
+	}
+
 	if jsonRPCResponse.Error != nil {
 		errorMsg := jsonRPCResponse.Error.Message
 		if jsonRPCResponse.Error.Data != nil {
@@ -330,7 +350,7 @@ func (c *Client) executeTaskJSONRPC(ctx context.Context, req *adk.TaskRequest) (
 
 						// Try to parse as TaskResponse JSON (artifacts are sent as JSON)
 						var tempResp adk.TaskResponse
-						if err := json.Unmarshal([]byte(text), &tempResp); err == nil {
+						if err := unmarshalText(text, &tempResp); err == nil {
 							c.logger.WithFields(logrus.Fields{
 								"session_id":     req.SessionID,
 								"part_index":     partIdx,
@@ -361,7 +381,7 @@ func (c *Client) executeTaskJSONRPC(ctx context.Context, req *adk.TaskRequest) (
 						} else {
 							// If not TaskResponse, might be just artifacts JSON
 							var artifacts map[string]string
-							if err2 := json.Unmarshal([]byte(text), &artifacts); err2 == nil {
+							if err2 := unmarshalText(text, &artifacts); err2 == nil {
 								c.logger.WithFields(logrus.Fields{
 									"session_id":     req.SessionID,
 									"part_index":     partIdx,
@@ -402,7 +422,7 @@ func (c *Client) executeTaskJSONRPC(ctx context.Context, req *adk.TaskRequest) (
 								if text, ok := partMap["text"].(string); ok {
 									// Try to parse as TaskResponse JSON
 									var tempResp adk.TaskResponse
-									if err := json.Unmarshal([]byte(text), &tempResp); err == nil {
+									if err := unmarshalText(text, &tempResp); err == nil {
 										for k, v := range tempResp.Artifacts {
 											taskResponse.Artifacts[k] = v
 										}
@@ -417,7 +437,7 @@ func (c *Client) executeTaskJSONRPC(ctx context.Context, req *adk.TaskRequest) (
 										}
 									} else {
 										var artifacts map[string]string
-										if err2 := json.Unmarshal([]byte(text), &artifacts); err2 == nil {
+										if err2 := unmarshalText(text, &artifacts); err2 == nil {
 											for k, v := range artifacts {
 												taskResponse.Artifacts[k] = v
 											}
@@ -439,7 +459,7 @@ func (c *Client) executeTaskJSONRPC(ctx context.Context, req *adk.TaskRequest) (
 						if partMap, ok := part.(map[string]interface{}); ok {
 							if text, ok := partMap["text"].(string); ok {
 								var tempResp adk.TaskResponse
-								if err := json.Unmarshal([]byte(text), &tempResp); err == nil {
+								if err := unmarshalText(text, &tempResp); err == nil {
 									for k, v := range tempResp.Artifacts {
 										taskResponse.Artifacts[k] = v
 									}
@@ -454,7 +474,7 @@ func (c *Client) executeTaskJSONRPC(ctx context.Context, req *adk.TaskRequest) (
 									}
 								} else {
 									var artifacts map[string]string
-									if err2 := json.Unmarshal([]byte(text), &artifacts); err2 == nil {
+									if err2 := unmarshalText(text, &artifacts); err2 == nil {
 										for k, v := range artifacts {
 											taskResponse.Artifacts[k] = v
 										}
@@ -508,7 +528,10 @@ func (c *Client) executeTaskHTTP(ctx context.Context, req *adk.TaskRequest) (*ad
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("User-Agent", "ExplainIQ-Google-ADK-Client/1.0")
-	httpReq.Header.Set("X-ADK-Version", "1.0")
+	httpReq.Header.Set(adk.VersionHeader, c.protocolVersion)
+	if key := adk.IdempotencyKeyFromContext(ctx); key != "" {
+		httpReq.Header.Set(adk.IdempotencyHeader, key)
+	}
 
 	// Add authentication if available (for Cloud Run service-to-service auth)
 	if c.authClient != nil && strings.HasPrefix(c.baseURL, "https://") {
@@ -533,8 +556,8 @@ func (c *Client) executeTaskHTTP(ctx context.Context, req *adk.TaskRequest) (*ad
 	}
 	defer resp.Body.Close()
 
-	// Read response
-	responseBody, err := io.ReadAll(resp.Body)
+	// Read response, bounded so a misbehaving agent cannot exhaust memory
+	responseBody, err := adk.ReadLimited(resp.Body, adk.MaxAgentResponseBytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
@@ -551,9 +574,12 @@ func (c *Client) executeTaskHTTP(ctx context.Context, req *adk.TaskRequest) (*ad
 		return nil, fmt.Errorf("agent error: HTTP %d - %s", resp.StatusCode, string(responseBody))
 	}
 
-	// Parse response
-	var taskResponse adk.TaskResponse
-	if err := json.Unmarshal(responseBody, &taskResponse); err != nil {
+	// Parse response in the negotiated protocol version, so fields this build does not know fail loudly
+	if err := c.checkResponseVersion(resp); err != nil {
+		return nil, err
+	}
+	taskResponse, err := adk.DecodeTaskResponse(responseBody, c.protocolVersion)
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
@@ -566,6 +592,31 @@ func (c *Client) executeTaskHTTP(ctx context.Context, req *adk.TaskRequest) (*ad
 	return &taskResponse, nil
 }
 
+// ProtocolVersion returns the ADK protocol version the client sends tasks in
+func (c *Client) ProtocolVersion() string {
+	return c.protocolVersion
+}
+
+// NegotiateProtocolVersion settles on the newest protocol version both the client and the agent
+// speak, given the versions the agent advertises. It fails with an *adk.VersionError if they share none.
+func (c *Client) NegotiateProtocolVersion(advertised []string) (string, error) {
+	version, err := adk.NegotiateProtocolVersion(advertised)
+	if err != nil {
+		return "", err
+	}
+	c.protocolVersion = version
+	return version, nil
+}
+
+// checkResponseVersion fails if the agent answered in a different protocol version than it was asked
+func (c *Client) checkResponseVersion(resp *http.Response) error {
+	if version := resp.Header.Get(adk.VersionHeader); version != "" && version != c.protocolVersion {
+		return fmt.Errorf("agent answered in ADK protocol %s, expected %s: %w", version, c.protocolVersion,
+			&adk.VersionError{Offered: []string{version}, Supported: []string{c.protocolVersion}})
+	}
+	return nil
+}
+
 // Health checks the health of the remote agent
 func (c *Client) Health(ctx context.Context) error {
 	// For A2A, we can check the AgentCard endpoint
@@ -624,6 +675,60 @@ func (c *Client) Health(ctx context.Context) error {
 	return nil
 }
 
+// Capabilities fetches the capabilities the agent advertises: the capabilities extension of its
+// AgentCard over A2A, or the capabilities endpoint over HTTP REST. It returns nil if the agent
+// advertises none.
+func (c *Client) Capabilities(ctx context.Context) (*adk.Capabilities, error) {
+	path := adk.EndpointCapabilities
+	if c.useA2A {
+		path = a2asrv.WellKnownAgentCardPath
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(c.baseURL, "/")+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create capabilities request: %w", err)
+	}
+	if c.authClient != nil && strings.HasPrefix(c.baseURL, "https://") {
+		token, err := c.authClient.GetIDToken(ctx, c.baseURL)
+		if err != nil {
+			c.logger.WithError(err).Warn("Failed to get ID token for capabilities request, proceeding without authentication")
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("capabilities request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("capabilities request failed with status %d", resp.StatusCode)
+	}
+
+	if !c.useA2A {
+		var capabilities adk.Capabilities
+		if err := adk.NewJSONDecoder(resp.Body, metadataLimits).Decode(&capabilities); err != nil {
+			return nil, fmt.Errorf("failed to decode capabilities: %w", err)
+		}
+		return &capabilities, nil
+	}
+
+	var card a2a.AgentCard
+	if err := adk.NewJSONDecoder(resp.Body, metadataLimits).Decode(&card); err != nil {
+		return nil, fmt.Errorf("failed to decode AgentCard: %w", err)
+	}
+	for _, extension := range card.Capabilities.Extensions {
+		if extension.URI == adk.CapabilitiesExtensionURI {
+			return adk.CapabilitiesFromParams(extension.Params)
+		}
+	}
+	return nil, nil
+}
+
 // invocationContext implements agent.InvocationContext
 type invocationContext struct {
 	ctx     context.Context
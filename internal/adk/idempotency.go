@@ -0,0 +1,240 @@
+package adk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyHeader carries the key identifying one logical task delivery across retries
+	IdempotencyHeader = "X-Idempotency-Key"
+	// IdempotentReplayHeader marks a response replayed from the idempotency cache
+	IdempotentReplayHeader = "X-Idempotent-Replay"
+
+	// defaultIdempotencyTTL is how long agents keep task responses for duplicate deliveries
+	defaultIdempotencyTTL = 10 * time.Minute
+	// maxIdempotencyEntries bounds the responses an agent keeps
+	maxIdempotencyEntries = 1000
+)
+
+// idempotencyKeyContextKey is the context key for a task's idempotency key
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey returns a context whose task deliveries carry key, so retries of the same
+// task can be recognised by the agent
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key set by WithIdempotencyKey
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key
+}
+
+// IdempotencyTTLFromEnv reads ADK_IDEMPOTENCY_TTL (e.g. "10m"), falling back to the default;
+// 0 disables the cache
+func IdempotencyTTLFromEnv() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("ADK_IDEMPOTENCY_TTL")); err == nil && d >= 0 {
+		return d
+	}
+	return defaultIdempotencyTTL
+}
+
+// cachedResponse is a recorded task response, or one still being produced
+type cachedResponse struct {
+	done      chan struct{} // Closed once the response is recorded
+	ok        bool          // The task succeeded and the response may be replayed
+	status    int
+	header    http.Header
+	body      []byte
+	expiresAt time.Time
+}
+
+// IdempotencyCache replays successful task responses to duplicate deliveries carrying the same
+// X-Idempotency-Key, so an orchestrator retrying after a timeout does not pay for a second model
+// call. A keyed task keeps running after its caller disconnects, and a duplicate arriving while it
+// runs waits for its result. Failed tasks are not cached, so their retries run again.
+type IdempotencyCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cachedResponse // path and key -> response
+}
+
+// NewIdempotencyCache creates a cache keeping responses for ttl; a zero ttl disables it
+func NewIdempotencyCache(ttl time.Duration) *IdempotencyCache {
+	return &IdempotencyCache{ttl: ttl, entries: make(map[string]*cachedResponse)}
+}
+
+// Wrap returns a handler serving keyed task deliveries through the cache. Unkeyed, non-POST and
+// streaming requests pass straight through.
+func (c *IdempotencyCache) Wrap(next http.Handler) http.Handler {
+	if c == nil || c.ttl <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyHeader)
+		if key == "" || r.Method != http.MethodPost || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key = r.URL.Path + " " + key
+
+		for {
+			entry, owner := c.claim(key)
+			if owner {
+				c.run(entry, key, next, r)
+			} else {
+				select {
+				case <-entry.done:
+				case <-r.Context().Done():
+					return
+				}
+			}
+			if owner || entry.ok {
+				if !owner {
+					w.Header().Set(IdempotentReplayHeader, "true")
+				}
+				entry.write(w)
+				return
+			}
+			// The delivery being waited on failed; run this one
+		}
+	})
+}
+
+// claim returns the live entry for key, or creates one the caller must fill
+func (c *IdempotencyCache) claim(key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := c.entries[key]; ok {
+		select {
+		case <-entry.done:
+			if entry.ok && now.Before(entry.expiresAt) {
+				return entry, false
+			}
+		default:
+			return entry, false
+		}
+	}
+	c.pruneLocked(now)
+	entry := &cachedResponse{done: make(chan struct{})}
+	c.entries[key] = entry
+	return entry, true
+}
+
+// run serves the task into entry, detached from the caller so a disconnect cannot cancel it
+func (c *IdempotencyCache) run(entry *cachedResponse, key string, next http.Handler, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), c.ttl)
+	defer cancel()
+
+	recorder := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+	next.ServeHTTP(recorder, r.WithContext(ctx))
+
+	c.mu.Lock()
+	entry.status = recorder.status
+	entry.header = recorder.header
+	entry.body = recorder.body.Bytes()
+	entry.ok = succeeded(recorder.status, entry.body)
+	entry.expiresAt = time.Now().Add(c.ttl)
+	if !entry.ok && c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	close(entry.done)
+}
+
+// succeeded reports whether a task response may be replayed: an HTTP success that is not a
+// JSON-RPC error or a failed A2A task
+func succeeded(status int, body []byte) bool {
+	if status >= http.StatusBadRequest {
+		return false
+	}
+	var payload struct {
+		Error  json.RawMessage `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return true
+	}
+	if len(payload.Error) > 0 && string(payload.Error) != "null" {
+		return false
+	}
+	var task struct {
+		Status struct {
+			State string `json:"state"`
+		} `json:"status"`
+	}
+	if json.Unmarshal(payload.Result, &task) == nil && task.Status.State == "failed" {
+		return false
+	}
+	return true
+}
+
+// pruneLocked drops expired responses, and the oldest ones while the cache is full
+func (c *IdempotencyCache) pruneLocked(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		select {
+		case <-entry.done:
+		default:
+			continue
+		}
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+	if len(c.entries) >= maxIdempotencyEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// write replays the recorded response
+func (e *cachedResponse) write(w http.ResponseWriter) {
+	for name, values := range e.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(e.status)
+	w.Write(e.body)
+}
+
+// responseRecorder buffers a handler's response
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+// Header implements http.ResponseWriter
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+}
+
+// Write implements http.ResponseWriter
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(data)
+}
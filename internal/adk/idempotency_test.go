@@ -0,0 +1,131 @@
+package adk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingTaskHandler counts task executions, failing while fail is set
+type countingTaskHandler struct {
+	calls   atomic.Int32
+	fail    atomic.Bool
+	release chan struct{} // When set, executions block until it is closed
+}
+
+// ServeHTTP implements http.Handler
+func (h *countingTaskHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := h.calls.Add(1)
+	if h.release != nil {
+		<-h.release
+	}
+	if h.fail.Load() {
+		http.Error(w, `{"error":"boom"}`, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"artifacts":{"call":"` + string(rune('0'+n)) + `"}}`))
+}
+
+// postTask delivers a task with an idempotency key
+func postTask(handler http.Handler, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/task", strings.NewReader(`{}`))
+	if key != "" {
+		req.Header.Set(IdempotencyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// TestIdempotencyCacheReplays tests that duplicate deliveries replay the first response
+func TestIdempotencyCacheReplays(t *testing.T) {
+	next := &countingTaskHandler{}
+	handler := NewIdempotencyCache(time.Minute).Wrap(next)
+
+	first := postTask(handler, "k1")
+	second := postTask(handler, "k1")
+	if next.calls.Load() != 1 {
+		t.Fatalf("Expected one execution, got %d", next.calls.Load())
+	}
+	if second.Body.String() != first.Body.String() || second.Header().Get(IdempotentReplayHeader) != "true" {
+		t.Errorf("Expected the first response replayed, got %q", second.Body.String())
+	}
+
+	// Other keys and unkeyed deliveries execute
+	postTask(handler, "k2")
+	postTask(handler, "")
+	postTask(handler, "")
+	if next.calls.Load() != 4 {
+		t.Errorf("Expected four executions, got %d", next.calls.Load())
+	}
+}
+
+// TestIdempotencyCacheSkipsFailures tests that failed deliveries are executed again on retry
+func TestIdempotencyCacheSkipsFailures(t *testing.T) {
+	next := &countingTaskHandler{}
+	next.fail.Store(true)
+	handler := NewIdempotencyCache(time.Minute).Wrap(next)
+
+	if w := postTask(handler, "k1"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected the failure to be returned, got %d", w.Code)
+	}
+	next.fail.Store(false)
+	if w := postTask(handler, "k1"); w.Code != http.StatusOK || next.calls.Load() != 2 {
+		t.Errorf("Expected the retry to execute, got %d after %d calls", w.Code, next.calls.Load())
+	}
+
+	if succeeded(http.StatusOK, []byte(`{"jsonrpc":"2.0","error":{"code":-32000}}`)) {
+		t.Error("Expected a JSON-RPC error not to be replayable")
+	}
+	if succeeded(http.StatusOK, []byte(`{"result":{"kind":"task","status":{"state":"failed"}}}`)) {
+		t.Error("Expected a failed A2A task not to be replayable")
+	}
+}
+
+// TestIdempotencyCacheInFlight tests that a duplicate arriving mid-execution waits for the first,
+// and that the execution survives its caller going away
+func TestIdempotencyCacheInFlight(t *testing.T) {
+	next := &countingTaskHandler{release: make(chan struct{})}
+	handler := NewIdempotencyCache(time.Minute).Wrap(next)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		req := httptest.NewRequest(http.MethodPost, "/task", strings.NewReader(`{}`)).WithContext(ctx)
+		req.Header.Set(IdempotencyHeader, "k1")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	for next.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel() // The orchestrator timed out
+
+	duplicate := make(chan *httptest.ResponseRecorder)
+	go func() { duplicate <- postTask(handler, "k1") }()
+	time.Sleep(10 * time.Millisecond)
+	close(next.release)
+
+	w := <-duplicate
+	wg.Wait()
+	if next.calls.Load() != 1 || w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"call":"1"`) {
+		t.Errorf("Expected the duplicate to receive the first execution's result, got %d %q after %d calls", w.Code, w.Body.String(), next.calls.Load())
+	}
+}
+
+// TestIdempotencyKeyContext tests carrying a task's idempotency key in its context
+func TestIdempotencyKeyContext(t *testing.T) {
+	if key := IdempotencyKeyFromContext(context.Background()); key != "" {
+		t.Errorf("Expected no key, got %q", key)
+	}
+	if key := IdempotencyKeyFromContext(WithIdempotencyKey(context.Background(), "k1")); key != "k1" {
+		t.Errorf("Expected k1, got %q", key)
+	}
+}
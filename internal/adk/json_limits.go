@@ -0,0 +1,149 @@
+package adk
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Limits on untrusted JSON. Task payloads carry lessons and context documents, a few megabytes
+// at most, and no legitimate payload nests anywhere near DefaultMaxJSONDepth levels.
+const (
+	DefaultMaxJSONDepth   = 64
+	MaxTaskRequestBytes   = 16 << 20 // 16 MiB
+	MaxAgentResponseBytes = 32 << 20 // 32 MiB; A2A responses escape artifacts inside message text
+	MaxAgentMetadataBytes = 1 << 20  // 1 MiB; capabilities and agent cards
+)
+
+var (
+	// ErrJSONTooLarge is returned when a payload exceeds its size limit
+	ErrJSONTooLarge = errors.New("JSON payload exceeds size limit")
+	// ErrJSONTooDeep is returned when objects and arrays nest deeper than allowed
+	ErrJSONTooDeep = errors.New("JSON payload nested too deeply")
+)
+
+// JSONLimits bounds the JSON a decoder accepts
+type JSONLimits struct {
+	MaxBytes int64 // Largest payload read; 0 disables the cap
+	MaxDepth int   // Deepest nesting of objects and arrays; 0 disables the cap
+}
+
+// depthScanner tracks the nesting depth of a JSON stream without parsing it
+type depthScanner struct {
+	maxDepth int
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+// scan consumes data, returning how many bytes fit within the depth limit and ErrJSONTooDeep
+// if the limit was exceeded
+func (s *depthScanner) scan(data []byte) (int, error) {
+	for i, c := range data {
+		switch {
+		case s.inString:
+			switch {
+			case s.escaped:
+				s.escaped = false
+			case c == '\\':
+				s.escaped = true
+			case c == '"':
+				s.inString = false
+			}
+		case c == '"':
+			s.inString = true
+		case c == '{' || c == '[':
+			s.depth++
+			if s.maxDepth > 0 && s.depth > s.maxDepth {
+				return i, ErrJSONTooDeep
+			}
+		case c == '}' || c == ']':
+			if s.depth > 0 {
+				s.depth--
+			}
+		}
+	}
+	return len(data), nil
+}
+
+// CheckJSONDepth fails with ErrJSONTooDeep if objects and arrays in data nest deeper than maxDepth
+func CheckJSONDepth(data []byte, maxDepth int) error {
+	_, err := (&depthScanner{maxDepth: maxDepth}).scan(data)
+	return err
+}
+
+// limitedJSONReader streams JSON through size and depth checks
+type limitedJSONReader struct {
+	r         io.Reader
+	remaining int64 // Bytes still allowed; negative when the size is not capped
+	depth     depthScanner
+	err       error
+}
+
+// Read implements io.Reader. Bytes past a limit are never handed to the decoder, so a value
+// cut short by a limit cannot decode successfully.
+func (l *limitedJSONReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if l.remaining == 0 {
+		// At the limit: the payload is fine if it ends here
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			l.err = ErrJSONTooLarge
+			return 0, l.err
+		}
+		return 0, err
+	}
+	if l.remaining > 0 && int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	if l.remaining > 0 {
+		l.remaining -= int64(n)
+	}
+	if kept, depthErr := l.depth.scan(p[:n]); depthErr != nil {
+		l.err = depthErr
+		return kept, depthErr
+	}
+	return n, err
+}
+
+// NewLimitedReader returns a reader that passes r through, failing with ErrJSONTooLarge past
+// limits.MaxBytes and with ErrJSONTooDeep past limits.MaxDepth
+func NewLimitedReader(r io.Reader, limits JSONLimits) io.Reader {
+	remaining := limits.MaxBytes
+	if remaining <= 0 {
+		remaining = -1
+	}
+	return &limitedJSONReader{r: r, remaining: remaining, depth: depthScanner{maxDepth: limits.MaxDepth}}
+}
+
+// NewJSONDecoder returns a decoder that streams from r within limits
+func NewJSONDecoder(r io.Reader, limits JSONLimits) *json.Decoder {
+	return json.NewDecoder(NewLimitedReader(r, limits))
+}
+
+// ReadLimited reads all of r, failing with ErrJSONTooLarge if it holds more than maxBytes
+func ReadLimited(r io.Reader, maxBytes int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, ErrJSONTooLarge
+	}
+	return data, nil
+}
+
+// UnmarshalJSON is json.Unmarshal failing on payloads beyond limits
+func UnmarshalJSON(data []byte, v interface{}, limits JSONLimits) error {
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return ErrJSONTooLarge
+	}
+	if err := CheckJSONDepth(data, limits.MaxDepth); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,144 @@
+package adk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// nestedJSON returns depth arrays nested inside each other
+func nestedJSON(depth int) string {
+	return strings.Repeat("[", depth) + strings.Repeat("]", depth)
+}
+
+// TestCheckJSONDepth tests depth checking, including brackets inside strings
+func TestCheckJSONDepth(t *testing.T) {
+	if err := CheckJSONDepth([]byte(nestedJSON(DefaultMaxJSONDepth)), DefaultMaxJSONDepth); err != nil {
+		t.Errorf("Expected nesting at the limit to pass, got %v", err)
+	}
+	if err := CheckJSONDepth([]byte(nestedJSON(DefaultMaxJSONDepth+1)), DefaultMaxJSONDepth); !errors.Is(err, ErrJSONTooDeep) {
+		t.Errorf("Expected ErrJSONTooDeep, got %v", err)
+	}
+
+	quoted := `{"text": "` + strings.Repeat(`[{\"`, 100) + `"}`
+	if err := CheckJSONDepth([]byte(quoted), 2); err != nil {
+		t.Errorf("Expected brackets inside strings to be ignored, got %v", err)
+	}
+	if err := CheckJSONDepth([]byte(nestedJSON(1000)), 0); err != nil {
+		t.Errorf("Expected a zero limit to disable the check, got %v", err)
+	}
+}
+
+// TestNewJSONDecoder tests that the streaming decoder enforces both limits
+func TestNewJSONDecoder(t *testing.T) {
+	limits := JSONLimits{MaxBytes: 32, MaxDepth: 3}
+	var v interface{}
+
+	// One byte at a time exercises limits that fall between reads
+	err := NewJSONDecoder(iotest.OneByteReader(strings.NewReader(`{"a": [1, 2, {"b": true}]}`)), limits).Decode(&v)
+	if err != nil {
+		t.Fatalf("Expected a payload within limits to decode, got %v", err)
+	}
+	if !reflect.DeepEqual(v, map[string]interface{}{"a": []interface{}{1.0, 2.0, map[string]interface{}{"b": true}}}) {
+		t.Errorf("Unexpected decoded value %v", v)
+	}
+
+	exact := `"` + strings.Repeat("x", 30) + `"`
+	if err := NewJSONDecoder(strings.NewReader(exact), limits).Decode(&v); err != nil {
+		t.Errorf("Expected a payload of exactly MaxBytes to decode, got %v", err)
+	}
+
+	err = NewJSONDecoder(strings.NewReader(`"`+strings.Repeat("x", 31)+`"`), limits).Decode(&v)
+	if !errors.Is(err, ErrJSONTooLarge) {
+		t.Errorf("Expected ErrJSONTooLarge, got %v", err)
+	}
+
+	err = NewJSONDecoder(iotest.OneByteReader(strings.NewReader(nestedJSON(4))), limits).Decode(&v)
+	if !errors.Is(err, ErrJSONTooDeep) {
+		t.Errorf("Expected ErrJSONTooDeep, got %v", err)
+	}
+}
+
+// TestReadLimited tests reading a body up to a size limit
+func TestReadLimited(t *testing.T) {
+	data, err := ReadLimited(strings.NewReader("12345"), 5)
+	if err != nil || string(data) != "12345" {
+		t.Errorf("Expected the whole body, got %q, %v", data, err)
+	}
+	if _, err := ReadLimited(strings.NewReader("123456"), 5); !errors.Is(err, ErrJSONTooLarge) {
+		t.Errorf("Expected ErrJSONTooLarge, got %v", err)
+	}
+}
+
+// TestDecodeTaskResponseTooDeep tests that task responses are depth checked before decoding
+func TestDecodeTaskResponseTooDeep(t *testing.T) {
+	data := []byte(`{"success": true, "result": {"lesson": ` + nestedJSON(DefaultMaxJSONDepth) + `}}`)
+	if _, err := DecodeTaskResponse(data, ProtocolVersion1); !errors.Is(err, ErrJSONTooDeep) {
+		t.Errorf("Expected ErrJSONTooDeep, got %v", err)
+	}
+}
+
+// FuzzUnmarshalJSON tests that limited unmarshaling agrees with encoding/json on everything it accepts
+func FuzzUnmarshalJSON(f *testing.F) {
+	for _, seed := range []string{`{}`, `[1, "two", {"three": [3]}]`, `"[{"`, `"\"[["`, nestedJSON(10), `{"a":`, `]]]`} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var limited, plain interface{}
+		err := UnmarshalJSON(data, &limited, JSONLimits{MaxBytes: 1 << 10, MaxDepth: 5})
+		if errors.Is(err, ErrJSONTooLarge) || errors.Is(err, ErrJSONTooDeep) {
+			return
+		}
+		plainErr := json.Unmarshal(data, &plain)
+		if (err == nil) != (plainErr == nil) {
+			t.Fatalf("Limited and plain unmarshaling disagree: %v vs %v", err, plainErr)
+		}
+		if err == nil && !reflect.DeepEqual(limited, plain) {
+			t.Fatalf("Limited unmarshaling decoded %v, plain decoded %v", limited, plain)
+		}
+	})
+}
+
+// FuzzNewJSONDecoder tests that the streaming decoder never accepts a value beyond its limits
+// and otherwise decodes what encoding/json decodes
+func FuzzNewJSONDecoder(f *testing.F) {
+	for _, seed := range []string{`{}`, `{"a": [1, 2, {"b": null}]} trailing`, `"\\"`, nestedJSON(6), `[` + strings.Repeat(`"x",`, 20) + `"x"]`} {
+		f.Add([]byte(seed))
+	}
+	limits := JSONLimits{MaxBytes: 64, MaxDepth: 5}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var limited interface{}
+		dec := NewJSONDecoder(iotest.OneByteReader(bytes.NewReader(data)), limits)
+		err := dec.Decode(&limited)
+		if errors.Is(err, ErrJSONTooLarge) || errors.Is(err, ErrJSONTooDeep) {
+			return
+		}
+
+		var plain interface{}
+		plainErr := json.NewDecoder(bytes.NewReader(data)).Decode(&plain)
+		if err != nil {
+			if plainErr == nil && len(data) <= int(limits.MaxBytes) {
+				t.Fatalf("Limited decoder failed with %v on input plain decoding accepts", err)
+			}
+			return
+		}
+		if plainErr != nil {
+			t.Fatalf("Limited decoder accepted input plain decoding rejects: %v", plainErr)
+		}
+		if !reflect.DeepEqual(limited, plain) {
+			t.Fatalf("Limited decoder decoded %v, plain decoded %v", limited, plain)
+		}
+
+		encoded, _ := json.Marshal(limited)
+		if CheckJSONDepth(encoded, limits.MaxDepth) != nil {
+			t.Fatalf("Limited decoder accepted a value nested deeper than %d", limits.MaxDepth)
+		}
+		if dec.InputOffset() > limits.MaxBytes {
+			t.Fatalf("Limited decoder consumed %d bytes", dec.InputOffset())
+		}
+	})
+}
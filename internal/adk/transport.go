@@ -0,0 +1,71 @@
+package adk
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Transport defaults for agent connections. Agents are few and called often, so each
+// keeps a deep pool of idle connections instead of the standard library's two per host.
+const (
+	defaultMaxIdleConnsPerHost = 32
+	defaultMaxIdleConns        = 128
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultDialTimeout         = 10 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	tlsSessionCacheSize        = 64
+)
+
+var (
+	sharedTransport     *http.Transport
+	sharedTransportOnce sync.Once
+)
+
+// SharedTransport returns the HTTP transport shared by all agent clients, so connections
+// and TLS sessions are reused across clients and retries.
+// ADK_MAX_IDLE_CONNS_PER_HOST overrides the idle connections kept per agent.
+func SharedTransport() *http.Transport {
+	sharedTransportOnce.Do(func() {
+		sharedTransport = newTransport(maxIdleConnsPerHostFromEnv())
+	})
+	return sharedTransport
+}
+
+// newTransport creates an HTTP transport tuned for agent traffic
+func newTransport(maxIdleConnsPerHost int) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: defaultKeepAlive,
+	}
+	maxIdleConns := defaultMaxIdleConns
+	if maxIdleConnsPerHost > maxIdleConns {
+		maxIdleConns = maxIdleConnsPerHost
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdleConns,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		TLSHandshakeTimeout:   defaultTLSHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+		TLSClientConfig: &tls.Config{
+			ClientSessionCache: tls.NewLRUClientSessionCache(tlsSessionCacheSize),
+		},
+	}
+}
+
+// maxIdleConnsPerHostFromEnv reads ADK_MAX_IDLE_CONNS_PER_HOST, falling back to the default
+func maxIdleConnsPerHostFromEnv() int {
+	if n, err := strconv.Atoi(os.Getenv("ADK_MAX_IDLE_CONNS_PER_HOST")); err == nil && n > 0 {
+		return n
+	}
+	return defaultMaxIdleConnsPerHost
+}
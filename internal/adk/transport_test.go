@@ -0,0 +1,45 @@
+package adk
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSharedTransport tests that clients share one tuned transport
+func TestSharedTransport(t *testing.T) {
+	transport := SharedTransport()
+	if transport != SharedTransport() {
+		t.Fatal("Expected the same transport on every call")
+	}
+	if transport.MaxIdleConnsPerHost < defaultMaxIdleConnsPerHost {
+		t.Errorf("Expected at least %d idle connections per host, got %d", defaultMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	}
+	if transport.TLSClientConfig == nil || transport.TLSClientConfig.ClientSessionCache == nil {
+		t.Error("Expected a TLS session cache")
+	}
+
+	first := NewClient("http://summarizer:8080", WithTimeout(time.Minute))
+	second := NewClient("http://critic:8080")
+	if first.httpClient.Transport != transport || second.httpClient.Transport != transport {
+		t.Error("Expected clients to use the shared transport")
+	}
+	if second.httpClient.Timeout != 30*time.Second {
+		t.Errorf("Expected per-client timeouts to stay independent, got %v", second.httpClient.Timeout)
+	}
+}
+
+// TestMaxIdleConnsPerHostFromEnv tests overriding the idle connection pool size
+func TestMaxIdleConnsPerHostFromEnv(t *testing.T) {
+	t.Setenv("ADK_MAX_IDLE_CONNS_PER_HOST", "64")
+	if got := maxIdleConnsPerHostFromEnv(); got != 64 {
+		t.Errorf("Expected 64, got %d", got)
+	}
+	if got := newTransport(256).MaxIdleConns; got != 256 {
+		t.Errorf("Expected the total pool to grow with the per-host pool, got %d", got)
+	}
+
+	t.Setenv("ADK_MAX_IDLE_CONNS_PER_HOST", "lots")
+	if got := maxIdleConnsPerHostFromEnv(); got != defaultMaxIdleConnsPerHost {
+		t.Errorf("Expected the default for invalid values, got %d", got)
+	}
+}
@@ -0,0 +1,183 @@
+package adk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// VersionHeader carries the ADK protocol version of a task request and its response
+	VersionHeader = "X-ADK-Version"
+
+	// ProtocolVersion1 is the original task contract: TaskRequest and TaskResponse as defined in contract.go
+	ProtocolVersion1 = "1.0"
+	// ProtocolVersion is the version this build prefers to speak
+	ProtocolVersion = ProtocolVersion1
+)
+
+// SupportedProtocolVersions lists every protocol version this build can speak, oldest first
+var SupportedProtocolVersions = []string{ProtocolVersion1}
+
+// ProtocolSchema lists the JSON fields of the task request and response in one protocol version.
+// Payloads with fields outside their version's schema are rejected rather than silently dropped.
+type ProtocolSchema struct {
+	Version        string
+	RequestFields  []string
+	ResponseFields []string
+}
+
+// protocolSchemas are the task schemas by protocol version
+var protocolSchemas = map[string]ProtocolSchema{
+	ProtocolVersion1: {
+		Version:        ProtocolVersion1,
+		RequestFields:  []string{"session_id", "step", "topic", "inputs"},
+		ResponseFields: []string{"delta", "artifacts", "next", "metrics"},
+	},
+}
+
+// VersionError reports that a client and an agent share no protocol version
+type VersionError struct {
+	Offered   []string // Versions the other side speaks
+	Supported []string // Versions this build speaks
+}
+
+// Error implements error
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("incompatible ADK protocol: peer speaks %s, this build speaks %s",
+		strings.Join(e.Offered, ", "), strings.Join(e.Supported, ", "))
+}
+
+// SupportsProtocolVersion reports whether this build speaks a protocol version
+func SupportsProtocolVersion(version string) bool {
+	_, ok := protocolSchemas[version]
+	return ok
+}
+
+// NegotiateProtocolVersion picks the newest version both this build and an agent speak. Agents
+// that advertise no versions predate negotiation and speak ProtocolVersion1.
+func NegotiateProtocolVersion(advertised []string) (string, error) {
+	if len(advertised) == 0 {
+		advertised = []string{ProtocolVersion1}
+	}
+	var common []string
+	for _, version := range advertised {
+		if SupportsProtocolVersion(version) {
+			common = append(common, version)
+		}
+	}
+	if len(common) == 0 {
+		return "", &VersionError{Offered: advertised, Supported: SupportedProtocolVersions}
+	}
+	sort.Slice(common, func(i, j int) bool { return versionLess(common[i], common[j]) })
+	return common[len(common)-1], nil
+}
+
+// versionLess orders "major.minor" versions numerically
+func versionLess(a, b string) bool {
+	aMajor, aMinor := splitVersion(a)
+	bMajor, bMinor := splitVersion(b)
+	if aMajor != bMajor {
+		return aMajor < bMajor
+	}
+	return aMinor < bMinor
+}
+
+// splitVersion parses a "major.minor" version, treating unparsable parts as 0
+func splitVersion(version string) (int, int) {
+	majorText, minorText, _ := strings.Cut(version, ".")
+	major, _ := strconv.Atoi(majorText)
+	minor, _ := strconv.Atoi(minorText)
+	return major, minor
+}
+
+// RequestProtocolVersion returns the protocol version of a task request; requests without a
+// version header come from clients predating negotiation
+func RequestProtocolVersion(r *http.Request) string {
+	if version := r.Header.Get(VersionHeader); version != "" {
+		return version
+	}
+	return ProtocolVersion1
+}
+
+// DecodeTaskRequest decodes a task request in the given protocol version, failing on fields the
+// version does not define or nesting deeper than DefaultMaxJSONDepth
+func DecodeTaskRequest(data []byte, version string) (TaskRequest, error) {
+	var req TaskRequest
+	schema, ok := protocolSchemas[version]
+	if !ok {
+		return req, &VersionError{Offered: []string{version}, Supported: SupportedProtocolVersions}
+	}
+	if err := CheckJSONDepth(data, DefaultMaxJSONDepth); err != nil {
+		return req, err
+	}
+	if err := checkSchemaFields(data, schema.RequestFields, version); err != nil {
+		return req, err
+	}
+	err := json.Unmarshal(data, &req)
+	return req, err
+}
+
+// DecodeTaskResponse decodes a task response in the given protocol version, failing on fields
+// the version does not define or nesting deeper than DefaultMaxJSONDepth
+func DecodeTaskResponse(data []byte, version string) (TaskResponse, error) {
+	var resp TaskResponse
+	schema, ok := protocolSchemas[version]
+	if !ok {
+		return resp, &VersionError{Offered: []string{version}, Supported: SupportedProtocolVersions}
+	}
+	if err := CheckJSONDepth(data, DefaultMaxJSONDepth); err != nil {
+		return resp, err
+	}
+	if err := checkSchemaFields(data, schema.ResponseFields, version); err != nil {
+		return resp, err
+	}
+	err := json.Unmarshal(data, &resp)
+	return resp, err
+}
+
+// checkSchemaFields fails if a JSON object has fields outside the schema's
+func checkSchemaFields(data []byte, fields []string, version string) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field] = true
+	}
+	var unknown []string
+	for field := range object {
+		if !known[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("fields not in ADK protocol %s: %s", version, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// VersionMiddleware rejects task requests in a protocol version this build does not speak and
+// labels responses with the version they were served in
+func VersionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := RequestProtocolVersion(r)
+		if !SupportsProtocolVersion(version) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":              "Unsupported ADK protocol version",
+				"details":            (&VersionError{Offered: []string{version}, Supported: SupportedProtocolVersions}).Error(),
+				"supported_versions": SupportedProtocolVersions,
+			})
+			return
+		}
+		w.Header().Set(VersionHeader, version)
+		next.ServeHTTP(w, r)
+	})
+}
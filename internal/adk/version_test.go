@@ -0,0 +1,74 @@
+package adk
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestNegotiateProtocolVersion tests picking the newest common protocol version
+func TestNegotiateProtocolVersion(t *testing.T) {
+	version, err := NegotiateProtocolVersion(nil)
+	if err != nil || version != ProtocolVersion1 {
+		t.Errorf("Expected agents advertising nothing to speak %s, got %q, %v", ProtocolVersion1, version, err)
+	}
+
+	version, err = NegotiateProtocolVersion([]string{"3.0", ProtocolVersion1, "0.9"})
+	if err != nil || version != ProtocolVersion1 {
+		t.Errorf("Expected %s, got %q, %v", ProtocolVersion1, version, err)
+	}
+
+	_, err = NegotiateProtocolVersion([]string{"2.0"})
+	var versionErr *VersionError
+	if !errors.As(err, &versionErr) {
+		t.Fatalf("Expected a VersionError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "peer speaks 2.0") {
+		t.Errorf("Expected the error to name the offered versions, got %q", err.Error())
+	}
+}
+
+// TestDecodeTaskRejectsUnknownFields tests that fields outside the protocol schema fail decoding
+func TestDecodeTaskRejectsUnknownFields(t *testing.T) {
+	req, err := DecodeTaskRequest([]byte(`{"session_id": "s1", "step": "explainer", "topic": "Go"}`), ProtocolVersion1)
+	if err != nil || req.Topic != "Go" {
+		t.Fatalf("Expected the request to decode, got %+v, %v", req, err)
+	}
+
+	_, err = DecodeTaskRequest([]byte(`{"session_id": "s1", "topic": "Go", "priority": 1, "deadline": "soon"}`), ProtocolVersion1)
+	if err == nil || !strings.Contains(err.Error(), "deadline, priority") {
+		t.Errorf("Expected unknown request fields to be reported, got %v", err)
+	}
+
+	_, err = DecodeTaskResponse([]byte(`{"artifacts": {}, "citations": []}`), ProtocolVersion1)
+	if err == nil || !strings.Contains(err.Error(), "citations") {
+		t.Errorf("Expected unknown response fields to be reported, got %v", err)
+	}
+
+	if _, err = DecodeTaskResponse([]byte(`{}`), "2.0"); err == nil {
+		t.Error("Expected an unsupported version to fail decoding")
+	}
+}
+
+// TestVersionMiddleware tests rejecting unsupported versions and labelling responses
+func TestVersionMiddleware(t *testing.T) {
+	handler := VersionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/invoke", nil))
+	if w.Code != http.StatusOK || w.Header().Get(VersionHeader) != ProtocolVersion1 {
+		t.Errorf("Expected unversioned requests to be served as %s, got %d %q", ProtocolVersion1, w.Code, w.Header().Get(VersionHeader))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/invoke", nil)
+	req.Header.Set(VersionHeader, "2.0")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "supported_versions") {
+		t.Errorf("Expected 400 listing supported versions, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,25 @@
+module github.com/InnoFusionTech/ExplainIQ/internal/agentruntime
+
+go 1.24.4
+
+toolchain go1.24.10
+
+require (
+	github.com/InnoFusionTech/ExplainIQ/internal/adk v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/config v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/constants v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/logger v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/server v0.0.0
+	github.com/sirupsen/logrus v1.9.3
+	github.com/stretchr/testify v1.11.1
+)
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/adk => ../adk
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/config => ../config
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/constants => ../constants
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/logger => ../logger
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/server => ../server
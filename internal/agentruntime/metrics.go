@@ -0,0 +1,106 @@
+package agentruntime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+)
+
+// Metrics tracks task processing counters for an agent
+type Metrics struct {
+	mu            sync.Mutex
+	startedAt     time.Time
+	tasksTotal    int64
+	tasksFailed   int64
+	inFlight      int64
+	totalDuration time.Duration
+}
+
+// MetricsSnapshot represents a point-in-time view of agent metrics
+type MetricsSnapshot struct {
+	Service       string  `json:"service"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	TasksTotal    int64   `json:"tasks_total"`
+	TasksFailed   int64   `json:"tasks_failed"`
+	InFlight      int64   `json:"in_flight"`
+	AvgDurationMs float64 `json:"avg_duration_ms"`
+}
+
+// NewMetrics creates a new metrics tracker
+func NewMetrics() *Metrics {
+	return &Metrics{
+		startedAt: time.Now(),
+	}
+}
+
+// Wrap returns a TaskProcessor that records metrics for every task
+func (m *Metrics) Wrap(p adk.TaskProcessor) adk.TaskProcessor {
+	return &instrumentedProcessor{next: p, metrics: m}
+}
+
+// Snapshot returns the current metrics
+func (m *Metrics) Snapshot(service string) MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := MetricsSnapshot{
+		Service:       service,
+		UptimeSeconds: time.Since(m.startedAt).Seconds(),
+		TasksTotal:    m.tasksTotal,
+		TasksFailed:   m.tasksFailed,
+		InFlight:      m.inFlight,
+	}
+	if completed := m.tasksTotal - m.inFlight; completed > 0 {
+		snapshot.AvgDurationMs = float64(m.totalDuration.Milliseconds()) / float64(completed)
+	}
+	return snapshot
+}
+
+// Handler returns an HTTP handler serving the metrics as JSON
+func (m *Metrics) Handler(service string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.Snapshot(service))
+	})
+}
+
+// begin records the start of a task
+func (m *Metrics) begin() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tasksTotal++
+	m.inFlight++
+}
+
+// end records the completion of a task
+func (m *Metrics) end(duration time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight--
+	m.totalDuration += duration
+	if failed {
+		m.tasksFailed++
+	}
+}
+
+// instrumentedProcessor wraps a TaskProcessor with metrics collection
+type instrumentedProcessor struct {
+	next    adk.TaskProcessor
+	metrics *Metrics
+}
+
+// ProcessTask processes a task and records its outcome
+func (p *instrumentedProcessor) ProcessTask(ctx context.Context, req adk.TaskRequest) (adk.TaskResponse, error) {
+	p.metrics.begin()
+	start := time.Now()
+
+	response, err := p.next.ProcessTask(ctx, req)
+	p.metrics.end(time.Since(start), err != nil)
+	return response, err
+}
@@ -0,0 +1,278 @@
+package agentruntime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	adkgoogle "github.com/InnoFusionTech/ExplainIQ/internal/adk/google"
+	"github.com/InnoFusionTech/ExplainIQ/internal/config"
+	"github.com/InnoFusionTech/ExplainIQ/internal/constants"
+	"github.com/InnoFusionTech/ExplainIQ/internal/logger"
+	"github.com/InnoFusionTech/ExplainIQ/internal/server"
+	"github.com/sirupsen/logrus"
+)
+
+// Serving modes
+const (
+	ModeA2A  = "a2a"
+	ModeHTTP = "http"
+)
+
+// Metrics endpoint path
+const EndpointMetrics = "/metrics"
+
+// defaultPorts maps agent service names to their default ports
+var defaultPorts = map[string]string{
+	constants.ServiceSummarizer: constants.DefaultPortSummarizer,
+	constants.ServiceExplainer:  constants.DefaultPortExplainer,
+	constants.ServiceCritic:     constants.DefaultPortCritic,
+	constants.ServiceVisualizer: constants.DefaultPortVisualizer,
+}
+
+// Config holds configuration for running an agent
+type Config struct {
+	Name            string
+	Description     string
+	Port            string
+	Mode            string // a2a or http
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	ShutdownTimeout time.Duration
+	Logger          *logrus.Logger
+	Capabilities    *adk.Capabilities // Advertised to callers; defaults to the processor's own
+	IdempotencyTTL  time.Duration     // How long responses are kept for duplicate task deliveries; 0 disables
+}
+
+// ConfigFromEnv builds an agent configuration from environment variables
+func ConfigFromEnv(name, description string) Config {
+	appConfig := config.LoadForService(defaultPorts[name], "")
+
+	mode := os.Getenv("AGENT_MODE")
+	if mode == "" {
+		mode = ModeA2A
+	}
+
+	return Config{
+		Name:            name,
+		Description:     description,
+		Port:            appConfig.Port,
+		Mode:            mode,
+		ReadTimeout:     appConfig.ReadTimeout,
+		WriteTimeout:    appConfig.WriteTimeout,
+		ShutdownTimeout: appConfig.ShutdownTimeout,
+		Logger:          logger.New(logger.Config{Level: appConfig.LogLevel}),
+		IdempotencyTTL:  adk.IdempotencyTTLFromEnv(),
+	}
+}
+
+// Run starts an agent with configuration from the environment and blocks until shutdown
+func Run(name, description string, p adk.TaskProcessor) error {
+	return RunWithConfig(ConfigFromEnv(name, description), p)
+}
+
+// RunWithConfig starts an agent with the given configuration and blocks until shutdown
+func RunWithConfig(cfg Config, p adk.TaskProcessor) error {
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.New()
+	}
+
+	if reporter, ok := p.(adk.CapabilityReporter); ok && cfg.Capabilities == nil {
+		capabilities := reporter.Capabilities()
+		cfg.Capabilities = &capabilities
+	}
+
+	metrics := NewMetrics()
+	processor := metrics.Wrap(p)
+
+	cfg.Logger.WithFields(logrus.Fields{
+		"service": cfg.Name,
+		"port":    cfg.Port,
+		"mode":    cfg.Mode,
+	}).Info("Starting agent")
+
+	switch cfg.Mode {
+	case ModeA2A:
+		return runA2A(cfg, processor, metrics)
+	case ModeHTTP:
+		return runHTTP(cfg, processor, metrics)
+	default:
+		return fmt.Errorf("unknown agent mode %q", cfg.Mode)
+	}
+}
+
+// runA2A serves the agent over the Google ADK A2A protocol
+func runA2A(cfg Config, processor adk.TaskProcessor, metrics *Metrics) error {
+	adkAgent, err := adkgoogle.CreateAgent(cfg.Name, cfg.Description, processor, adkgoogle.NewLoggerAdapter(cfg.Logger))
+	if err != nil {
+		return fmt.Errorf("failed to create Google ADK agent: %w", err)
+	}
+
+	a2aServer, err := adkgoogle.NewA2AServer(adkAgent, cfg.Port, cfg.Logger)
+	if err != nil {
+		return fmt.Errorf("failed to create A2A server: %w", err)
+	}
+	a2aServer.Handle(EndpointMetrics, metrics.Handler(cfg.Name))
+	a2aServer.SetIdempotencyCache(adk.NewIdempotencyCache(cfg.IdempotencyTTL))
+	if cfg.Capabilities != nil {
+		a2aServer.SetCapabilities(*cfg.Capabilities)
+	}
+
+	cfg.Logger.Infof("AgentCard available at: %s", a2aServer.GetAgentCardURL())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- a2aServer.Start()
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("A2A server failed: %w", err)
+		}
+		return nil
+	case <-quit:
+	}
+
+	cfg.Logger.Info("Shutting down agent...")
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := a2aServer.Stop(ctx); err != nil {
+		return fmt.Errorf("agent forced to shutdown: %w", err)
+	}
+
+	cfg.Logger.Info("Agent exited")
+	return nil
+}
+
+// runHTTP serves the agent over the plain HTTP /task contract
+func runHTTP(cfg Config, processor adk.TaskProcessor, metrics *Metrics) error {
+	handler := adk.NewIdempotencyCache(cfg.IdempotencyTTL).Wrap(NewHTTPHandler(cfg.Name, processor, metrics, cfg.Logger))
+	if cfg.Capabilities != nil {
+		handler = WithCapabilities(handler, *cfg.Capabilities)
+	}
+
+	srv := server.New(server.Config{
+		Addr:            ":" + cfg.Port,
+		Handler:         handler,
+		ReadTimeout:     cfg.ReadTimeout,
+		WriteTimeout:    cfg.WriteTimeout,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: cfg.ShutdownTimeout,
+		Logger:          cfg.Logger,
+	})
+
+	return srv.StartAndWait()
+}
+
+// NewHTTPHandler returns the HTTP handler exposing the task, health and metrics endpoints
+func NewHTTPHandler(name string, processor adk.TaskProcessor, metrics *Metrics, log *logrus.Logger) http.Handler {
+	mux := http.NewServeMux()
+
+	healthHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":            "healthy",
+			"service":           name,
+			"protocol_versions": adk.SupportedProtocolVersions,
+			"timestamp":         time.Now().UTC(),
+		})
+	}
+	mux.HandleFunc(constants.EndpointHealth, healthHandler)
+	mux.HandleFunc(constants.EndpointHealthz, healthHandler)
+	mux.Handle(EndpointMetrics, metrics.Handler(name))
+
+	mux.Handle(constants.EndpointTask, adk.VersionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": "Method not allowed",
+			})
+			return
+		}
+
+		body, err := adk.ReadLimited(r.Body, adk.MaxTaskRequestBytes)
+		if errors.Is(err, adk.ErrJSONTooLarge) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Request too large",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Invalid request format",
+				"details": err.Error(),
+			})
+			return
+		}
+		req, err := adk.DecodeTaskRequest(body, adk.RequestProtocolVersion(r))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Invalid request format",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Invalid request",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		response, err := processor.ProcessTask(r.Context(), req)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"session_id": req.SessionID,
+				"error":      err,
+			}).Error("Task processing failed")
+
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Task processing failed",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		json.NewEncoder(w).Encode(response)
+	})))
+
+	return mux
+}
+
+// WithCapabilities serves an agent's capabilities at adk.EndpointCapabilities in front of handler.
+// Plain HTTP responses are not streamed, so Streaming is always false.
+func WithCapabilities(handler http.Handler, capabilities adk.Capabilities) http.Handler {
+	capabilities.Streaming = false
+	if len(capabilities.ProtocolVersions) == 0 {
+		capabilities.ProtocolVersions = adk.SupportedProtocolVersions
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(adk.EndpointCapabilities, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(capabilities)
+	})
+	mux.Handle("/", handler)
+	return mux
+}
@@ -0,0 +1,128 @@
+package agentruntime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubProcessor is a TaskProcessor returning a fixed response or error
+type stubProcessor struct {
+	err error
+}
+
+// ProcessTask implements adk.TaskProcessor
+func (s *stubProcessor) ProcessTask(ctx context.Context, req adk.TaskRequest) (adk.TaskResponse, error) {
+	if s.err != nil {
+		return adk.TaskResponse{}, s.err
+	}
+	return adk.TaskResponse{Artifacts: map[string]string{"topic": req.Topic}}, nil
+}
+
+// taskBody returns an encoded task request body
+func taskBody(t *testing.T) *bytes.Buffer {
+	body, err := json.Marshal(adk.TaskRequest{SessionID: "s1", Step: "explain", Topic: "go"})
+	require.NoError(t, err)
+	return bytes.NewBuffer(body)
+}
+
+// TestHTTPHandlerTask tests task processing over HTTP mode
+func TestHTTPHandlerTask(t *testing.T) {
+	metrics := NewMetrics()
+	handler := NewHTTPHandler("agent-test", metrics.Wrap(&stubProcessor{}), metrics, logrus.New())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/task", taskBody(t)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response adk.TaskResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "go", response.Artifacts["topic"])
+
+	snapshot := metrics.Snapshot("agent-test")
+	assert.Equal(t, int64(1), snapshot.TasksTotal)
+	assert.Equal(t, int64(0), snapshot.TasksFailed)
+	assert.Equal(t, int64(0), snapshot.InFlight)
+}
+
+// TestHTTPHandlerTaskFailure tests that processor errors are reported and counted
+func TestHTTPHandlerTaskFailure(t *testing.T) {
+	metrics := NewMetrics()
+	handler := NewHTTPHandler("agent-test", metrics.Wrap(&stubProcessor{err: errors.New("boom")}), metrics, logrus.New())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/task", taskBody(t)))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, int64(1), metrics.Snapshot("agent-test").TasksFailed)
+}
+
+// TestHTTPHandlerTaskProtocolVersion tests that task requests outside the agent's protocol are rejected
+func TestHTTPHandlerTaskProtocolVersion(t *testing.T) {
+	metrics := NewMetrics()
+	handler := NewHTTPHandler("agent-test", metrics.Wrap(&stubProcessor{}), metrics, logrus.New())
+
+	req := httptest.NewRequest(http.MethodPost, "/task", taskBody(t))
+	req.Header.Set(adk.VersionHeader, "2.0")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/task", bytes.NewBufferString(`{"topic": "go", "priority": 1}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "priority")
+	assert.Equal(t, int64(0), metrics.Snapshot("agent-test").TasksTotal)
+}
+
+// TestHTTPHandlerHealthAndMetrics tests the health and metrics endpoints
+func TestHTTPHandlerHealthAndMetrics(t *testing.T) {
+	metrics := NewMetrics()
+	handler := NewHTTPHandler("agent-test", metrics.Wrap(&stubProcessor{}), metrics, logrus.New())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, EndpointMetrics, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var snapshot MetricsSnapshot
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &snapshot))
+	assert.Equal(t, "agent-test", snapshot.Service)
+}
+
+// TestRunWithConfigUnknownMode tests rejection of unsupported modes
+func TestRunWithConfigUnknownMode(t *testing.T) {
+	err := RunWithConfig(Config{Name: "agent-test", Mode: "grpc"}, &stubProcessor{})
+	assert.Error(t, err)
+}
+
+// TestWithCapabilities tests serving capabilities alongside the task endpoints
+func TestWithCapabilities(t *testing.T) {
+	metrics := NewMetrics()
+	handler := WithCapabilities(NewHTTPHandler("agent-test", metrics.Wrap(&stubProcessor{}), metrics, logrus.New()),
+		adk.Capabilities{Steps: []string{"explainer"}, MaxContextChars: 100, Streaming: true})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, adk.EndpointCapabilities, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var capabilities adk.Capabilities
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &capabilities))
+	assert.Equal(t, []string{"explainer"}, capabilities.Steps)
+	assert.Equal(t, 100, capabilities.MaxContextChars)
+	assert.False(t, capabilities.Streaming)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/task", taskBody(t)))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,141 @@
+// Package agents implements the summarizer, explainer, visualizer and critic task
+// processors. The agent binaries serve them over A2A or HTTP; the orchestrator can
+// also run them in-process (AGENT_MODE=embedded) for single-binary deployments.
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// Agent names as used by pipeline steps
+const (
+	Summarizer = "summarizer"
+	Explainer  = "explainer"
+	Visualizer = "visualizer"
+	Critic     = "critic"
+)
+
+// Names lists every agent in pipeline order
+var Names = []string{Summarizer, Explainer, Visualizer, Critic}
+
+// ExplanationTypes lists the explanation styles the explainer writes in
+var ExplanationTypes = []string{"standard", "visualization", "simple", "analogy"}
+
+// maxContextChars is the largest retrieval context the summarizer and explainer accept
+const maxContextChars = 32000
+
+// maxContextTokens is the token budget for the retrieval context of the summarizer and explainer
+const maxContextTokens = 8000
+
+// ScratchpadArtifact names the explainer artifact holding its private reasoning, which is never shown to users
+const ScratchpadArtifact = "scratchpad"
+
+// PromptTraceArtifact names the artifact holding the step's rendered prompts and generation configs,
+// produced only when an admin asked to trace the session
+const PromptTraceArtifact = "prompt_trace"
+
+// CostTracker records the cost of LLM calls made while processing tasks
+type CostTracker interface {
+	TrackLLMCall(ctx context.Context, sessionID, userID, ipAddress, model string, inputTokens, outputTokens int) error
+}
+
+// NewProcessor creates the task processor for an agent name
+func NewProcessor(name string, client llm.GeminiClientInterface, logger *logrus.Logger) (adk.TaskProcessor, error) {
+	switch name {
+	case Summarizer:
+		return NewSummarizerProcessor(client, nil, logger), nil
+	case Explainer:
+		return NewExplainerProcessor(client, logger), nil
+	case Visualizer:
+		return NewVisualizerProcessor(client, logger), nil
+	case Critic:
+		config := llm.CriticConfigFromEnv()
+		reviewer, err := llm.NewCritiquer(config, client)
+		if err != nil {
+			return nil, err
+		}
+		return NewCriticProcessor(client, logger).UseReviewer(config, reviewer), nil
+	default:
+		return nil, fmt.Errorf("unknown agent %q", name)
+	}
+}
+
+// LocalClient executes tasks against an in-process TaskProcessor, matching the
+// ExecuteTask/Health shape of the remote ADK clients so callers can use either
+type LocalClient struct {
+	name      string
+	processor adk.TaskProcessor
+	timeout   time.Duration
+}
+
+// NewLocalClient creates a client for an in-process processor. A zero timeout disables the per-task deadline.
+func NewLocalClient(name string, processor adk.TaskProcessor, timeout time.Duration) *LocalClient {
+	return &LocalClient{
+		name:      name,
+		processor: processor,
+		timeout:   timeout,
+	}
+}
+
+// ExecuteTask runs a task in-process. A panicking processor is reported as an
+// error instead of taking down the host process.
+func (c *LocalClient) ExecuteTask(ctx context.Context, req *adk.TaskRequest) (resp *adk.TaskResponse, err error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			resp = nil
+			err = fmt.Errorf("agent %s panicked: %v", c.name, r)
+		}
+	}()
+
+	response, err := c.processor.ProcessTask(ctx, *req)
+	if err != nil {
+		return nil, fmt.Errorf("agent %s failed: %w", c.name, err)
+	}
+	return &response, nil
+}
+
+// Capabilities returns the capabilities the in-process processor advertises, or nil if it advertises none
+func (c *LocalClient) Capabilities(ctx context.Context) (*adk.Capabilities, error) {
+	reporter, ok := c.processor.(adk.CapabilityReporter)
+	if !ok {
+		return nil, nil
+	}
+	capabilities := reporter.Capabilities()
+	return &capabilities, nil
+}
+
+// Health reports the in-process agent as healthy; it shares the host's lifecycle
+func (c *LocalClient) Health(ctx context.Context) error {
+	return nil
+}
+
+// withPromptTrace records the task's model requests if the orchestrator asked for a prompt trace
+func withPromptTrace(ctx context.Context, req adk.TaskRequest) (context.Context, *llm.PromptTrace) {
+	if req.Inputs["trace_prompts"] != "true" {
+		return ctx, nil
+	}
+	return llm.WithPromptTrace(ctx)
+}
+
+// attachPromptTrace adds the recorded model requests to a task response
+func attachPromptTrace(response *adk.TaskResponse, trace *llm.PromptTrace) {
+	if trace == nil {
+		return
+	}
+	if traceJSON, err := json.Marshal(trace.Entries()); err == nil {
+		response.Artifacts[PromptTraceArtifact] = string(traceJSON)
+	}
+}
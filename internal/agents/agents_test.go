@@ -0,0 +1,262 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is a GeminiClientInterface returning canned responses
+type fakeClient struct {
+	summarizeErr         error
+	summarizeContext     string // Context passed to the last Summarize call
+	explainDeterministic bool   // Whether the last ExplainWithOG call was deterministic
+}
+
+func (f *fakeClient) Summarize(ctx context.Context, topic, context string) (*llm.SummarizeResponse, error) {
+	f.summarizeContext = context
+	if f.summarizeErr != nil {
+		return nil, f.summarizeErr
+	}
+	return &llm.SummarizeResponse{Outline: []string{"Intro", "Details"}}, nil
+}
+
+func (f *fakeClient) ExplainWithOG(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
+	f.explainDeterministic = llm.DeterministicFromContext(ctx)
+	lesson := &llm.OGLesson{BigPicture: "Big picture of " + topic}
+	if llm.ScratchpadFromContext(ctx) {
+		lesson.Scratchpad = "Start from the learner's intuition"
+	}
+	return lesson, nil
+}
+
+func (f *fakeClient) CritiqueLesson(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
+	return &llm.CritiqueResponse{Issues: []llm.CritiqueIssue{{Severity: "high"}, {Severity: "low"}}}, nil
+}
+
+func (f *fakeClient) VisualizeCore(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
+	return &llm.VisualizeResponse{Images: []llm.ImageRef{{URL: "https://example.com/1.png", Caption: "Flow"}}}, nil
+}
+
+func (f *fakeClient) Health(ctx context.Context) error     { return nil }
+func (f *fakeClient) SetAPIKey(apiKey string)              {}
+func (f *fakeClient) SetModel(model string)                {}
+func (f *fakeClient) SetBaseURL(baseURL string)            {}
+func (f *fakeClient) GetModelInfo() map[string]interface{} { return nil }
+
+func TestNewProcessor(t *testing.T) {
+	inputs := map[string]map[string]string{
+		Summarizer: {"topic": "queues"},
+		Explainer:  {"topic": "queues"},
+		Visualizer: {"lesson": `{"core_mechanism": "FIFO"}`},
+		Critic:     {"lesson": `{"core_mechanism": "FIFO"}`},
+	}
+	expected := map[string]string{
+		Summarizer: "outline",
+		Explainer:  "lesson",
+		Visualizer: "images",
+		Critic:     "critique",
+	}
+
+	for _, name := range Names {
+		processor, err := NewProcessor(name, &fakeClient{}, logrus.New())
+		require.NoError(t, err, name)
+
+		response, err := processor.ProcessTask(context.Background(), adk.TaskRequest{
+			SessionID: "s1",
+			Step:      name,
+			Inputs:    inputs[name],
+		})
+		require.NoError(t, err, name)
+		assert.Contains(t, response.Artifacts, expected[name], name)
+	}
+
+	_, err := NewProcessor("translator", &fakeClient{}, nil)
+	assert.Error(t, err)
+}
+
+func TestProcessorCapabilities(t *testing.T) {
+	for _, name := range Names {
+		processor, err := NewProcessor(name, &fakeClient{}, logrus.New())
+		require.NoError(t, err, name)
+
+		capabilities, err := NewLocalClient(name, processor, 0).Capabilities(context.Background())
+		require.NoError(t, err, name)
+		require.NotNil(t, capabilities, name)
+		assert.Equal(t, []string{name}, capabilities.Steps, name)
+	}
+
+	explainer := NewExplainerProcessor(&fakeClient{}, logrus.New()).Capabilities()
+	assert.True(t, explainer.SupportsExplanationType("analogy"))
+	assert.False(t, explainer.SupportsExplanationType("interpretive-dance"))
+	assert.Equal(t, maxContextChars, explainer.MaxContextChars)
+}
+
+func TestCountIssuesBySeverity(t *testing.T) {
+	issues := []llm.CritiqueIssue{{Severity: "high"}, {Severity: "high"}, {Severity: "low"}}
+	assert.Equal(t, 2, CountIssuesBySeverity(issues, "high"))
+	assert.Equal(t, 0, CountIssuesBySeverity(issues, "critical"))
+}
+
+// processorFunc adapts a function to adk.TaskProcessor
+type processorFunc func(ctx context.Context, req adk.TaskRequest) (adk.TaskResponse, error)
+
+func (f processorFunc) ProcessTask(ctx context.Context, req adk.TaskRequest) (adk.TaskResponse, error) {
+	return f(ctx, req)
+}
+
+func TestLocalClient(t *testing.T) {
+	client := NewLocalClient(Summarizer, NewSummarizerProcessor(&fakeClient{}, nil, nil), time.Second)
+	response, err := client.ExecuteTask(context.Background(), &adk.TaskRequest{Inputs: map[string]string{"topic": "queues"}})
+	require.NoError(t, err)
+	assert.Contains(t, response.Artifacts, "outline")
+	assert.NoError(t, client.Health(context.Background()))
+
+	failing := NewLocalClient(Summarizer, NewSummarizerProcessor(&fakeClient{summarizeErr: errors.New("quota")}, nil, nil), 0)
+	_, err = failing.ExecuteTask(context.Background(), &adk.TaskRequest{Inputs: map[string]string{"topic": "queues"}})
+	assert.ErrorContains(t, err, "quota")
+
+	panicking := NewLocalClient("broken", processorFunc(func(ctx context.Context, req adk.TaskRequest) (adk.TaskResponse, error) {
+		panic("nil map")
+	}), 0)
+	_, err = panicking.ExecuteTask(context.Background(), &adk.TaskRequest{})
+	assert.ErrorContains(t, err, "panicked")
+
+	slow := NewLocalClient("slow", processorFunc(func(ctx context.Context, req adk.TaskRequest) (adk.TaskResponse, error) {
+		<-ctx.Done()
+		return adk.TaskResponse{}, ctx.Err()
+	}), 10*time.Millisecond)
+	_, err = slow.ExecuteTask(context.Background(), &adk.TaskRequest{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// fakeGrounder is a Grounder returning a canned result or error
+type fakeGrounder struct {
+	err   error
+	calls int
+}
+
+func (g *fakeGrounder) Ground(ctx context.Context, topic string) (*llm.GroundingResult, error) {
+	g.calls++
+	if g.err != nil {
+		return nil, g.err
+	}
+	return &llm.GroundingResult{
+		Text:      topic + " changed last week.",
+		Citations: []llm.GroundingCitation{{URI: "https://example.com/news", Title: "News"}},
+	}, nil
+}
+
+// TestSummarizerGrounding tests that grounded summaries carry web research and citations
+func TestSummarizerGrounding(t *testing.T) {
+	client := &fakeClient{}
+	grounder := &fakeGrounder{}
+	processor := NewSummarizerProcessor(client, nil, nil)
+	processor.SetGrounder(grounder)
+
+	response, err := processor.ProcessTask(context.Background(), adk.TaskRequest{Inputs: map[string]string{"topic": "Latest Go release", "grounding": "auto"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, grounder.calls)
+	assert.Contains(t, client.summarizeContext, "Latest Go release changed last week.")
+	assert.Equal(t, "true", response.Artifacts["web_grounded"])
+	assert.JSONEq(t, `[{"uri":"https://example.com/news","title":"News"}]`, response.Artifacts["grounding_citations"])
+	assert.Equal(t, true, response.Metrics["web_grounded"])
+
+	// Auto mode leaves timeless topics alone
+	response, err = processor.ProcessTask(context.Background(), adk.TaskRequest{Inputs: map[string]string{"topic": "Recursion", "grounding": "auto"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, grounder.calls)
+	assert.NotContains(t, response.Artifacts, "web_grounded")
+
+	// A grounding failure falls back to an ungrounded summary
+	grounder.err = errors.New("search unavailable")
+	response, err = processor.ProcessTask(context.Background(), adk.TaskRequest{Inputs: map[string]string{"topic": "Recursion", "grounding": "on"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, grounder.calls)
+	assert.NotContains(t, response.Artifacts, "grounding_citations")
+}
+
+// TestExplainerDeterministic tests that the deterministic input switches the request to deterministic generation
+func TestExplainerDeterministic(t *testing.T) {
+	client := &fakeClient{}
+	processor := NewExplainerProcessor(client, nil)
+
+	_, err := processor.ProcessTask(context.Background(), adk.TaskRequest{Inputs: map[string]string{"topic": "Caching", "deterministic": "true"}})
+	require.NoError(t, err)
+	assert.True(t, client.explainDeterministic)
+
+	_, err = processor.ProcessTask(context.Background(), adk.TaskRequest{Inputs: map[string]string{"topic": "Caching"}})
+	require.NoError(t, err)
+	assert.False(t, client.explainDeterministic)
+}
+
+// TestExplainerScratchpad tests that the scratchpad is requested only on demand and returned as its own artifact
+func TestExplainerScratchpad(t *testing.T) {
+	processor := NewExplainerProcessor(&fakeClient{}, nil)
+
+	response, err := processor.ProcessTask(context.Background(), adk.TaskRequest{Inputs: map[string]string{"topic": "Caching"}})
+	require.NoError(t, err)
+	assert.NotContains(t, response.Artifacts, ScratchpadArtifact)
+
+	response, err = processor.ProcessTask(context.Background(), adk.TaskRequest{Inputs: map[string]string{"topic": "Caching", "scratchpad": "true"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Start from the learner's intuition", response.Artifacts[ScratchpadArtifact])
+	assert.NotContains(t, response.Artifacts["lesson"], "intuition")
+}
+
+// fakeCritiquer is a second-provider reviewer recording the model it was asked to use
+type fakeCritiquer struct {
+	model string // Model override carried by the last request's context
+}
+
+func (f *fakeCritiquer) CritiqueLesson(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
+	f.model = llm.ModelFromContext(ctx)
+	return &llm.CritiqueResponse{Issues: []llm.CritiqueIssue{{Severity: "critical"}}}, nil
+}
+
+// TestCriticReviewer tests that a configured reviewer critiques the lesson and is named in the artifacts
+func TestCriticReviewer(t *testing.T) {
+	req := adk.TaskRequest{Inputs: map[string]string{"lesson": `{"core_mechanism": "FIFO"}`, "model": "gemini-2.5-flash"}}
+
+	// By default the session's Gemini model reviews the lesson
+	response, err := NewCriticProcessor(&fakeClient{}, nil).ProcessTask(context.Background(), req)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"provider": "gemini", "model": "gemini-2.5-flash"}`, response.Artifacts["reviewer"])
+
+	// A pinned Gemini model replaces the session's model
+	reviewer := &fakeCritiquer{}
+	processor := NewCriticProcessor(&fakeClient{}, nil).UseReviewer(llm.CriticConfig{Model: "gemini-2.5-pro"}, reviewer)
+	response, err = processor.ProcessTask(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "gemini-2.5-pro", reviewer.model)
+	assert.Equal(t, 1, response.Metrics["critical_issues"])
+	assert.JSONEq(t, `{"provider": "gemini", "model": "gemini-2.5-pro"}`, response.Artifacts["reviewer"])
+
+	// Another provider reviews instead of the Gemini client
+	processor = NewCriticProcessor(&fakeClient{}, nil).UseReviewer(llm.CriticConfig{Provider: llm.CriticProviderOpenAI, Model: "gpt-4o"}, reviewer)
+	response, err = processor.ProcessTask(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Metrics["critical_issues"])
+	assert.JSONEq(t, `{"provider": "openai", "model": "gpt-4o"}`, response.Artifacts["reviewer"])
+}
+
+// TestPromptTraceArtifact tests that agents return their model requests only when asked to trace prompts
+func TestPromptTraceArtifact(t *testing.T) {
+	processor, err := NewProcessor(Explainer, &fakeClient{}, logrus.New())
+	require.NoError(t, err)
+
+	response, err := processor.ProcessTask(context.Background(), adk.TaskRequest{Inputs: map[string]string{"topic": "queues"}})
+	require.NoError(t, err)
+	assert.NotContains(t, response.Artifacts, PromptTraceArtifact)
+
+	response, err = processor.ProcessTask(context.Background(), adk.TaskRequest{Inputs: map[string]string{"topic": "queues", "trace_prompts": "true"}})
+	require.NoError(t, err)
+	assert.Contains(t, response.Artifacts, PromptTraceArtifact)
+}
@@ -0,0 +1,182 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// CriticProcessor critiques lessons and proposes patch plans
+type CriticProcessor struct {
+	geminiClient llm.GeminiClientInterface
+	reviewer     llm.Critiquer    // Reviews lessons; the Gemini client unless another provider is configured
+	config       llm.CriticConfig // Reviewer provider and pinned model
+	logger       *logrus.Logger
+}
+
+// NewCriticProcessor creates a critic processor
+func NewCriticProcessor(client llm.GeminiClientInterface, logger *logrus.Logger) *CriticProcessor {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &CriticProcessor{
+		geminiClient: client,
+		reviewer:     client,
+		config:       llm.CriticConfig{Provider: llm.CriticProviderGemini},
+		logger:       logger,
+	}
+}
+
+// UseReviewer makes the critic review lessons with the configured provider, so lessons can be
+// checked by a different model family than the one that wrote them. A configured Gemini model
+// takes precedence over the session's model.
+func (s *CriticProcessor) UseReviewer(config llm.CriticConfig, reviewer llm.Critiquer) *CriticProcessor {
+	if config.Provider == "" {
+		config.Provider = llm.CriticProviderGemini
+	}
+	s.config = config
+	s.reviewer = reviewer
+	return s
+}
+
+// Capabilities implements adk.CapabilityReporter
+func (s *CriticProcessor) Capabilities() adk.Capabilities {
+	return adk.Capabilities{Steps: []string{Critic}}
+}
+
+// ProcessTask processes a critique task
+func (s *CriticProcessor) ProcessTask(ctx context.Context, req adk.TaskRequest) (adk.TaskResponse, error) {
+	s.logger.WithFields(logrus.Fields{
+		"session_id": req.SessionID,
+		"step":       req.Step,
+		"topic":      req.Topic,
+	}).Info("Processing critique task")
+
+	// Extract lesson JSON from inputs
+	lessonJSON, exists := req.Inputs["lesson"]
+	if !exists || lessonJSON == "" {
+		return adk.TaskResponse{}, fmt.Errorf("lesson JSON is required in inputs")
+	}
+
+	// Use the pinned reviewer model, else the model requested for this session
+	if s.config.Provider == llm.CriticProviderGemini && s.config.Model != "" {
+		ctx = llm.WithModel(ctx, s.config.Model)
+	} else if model := req.Inputs["model"]; model != "" {
+		ctx = llm.WithModel(ctx, model)
+	}
+
+	// Generate reproducibly if the session asked for deterministic output
+	if req.Inputs["deterministic"] == "true" {
+		ctx = llm.WithDeterministic(ctx)
+	}
+
+	// Follow the prompt experiment variant the session was assigned to, if any
+	if instructions := req.Inputs["prompt_instructions"]; instructions != "" {
+		ctx = llm.WithPromptInstructions(ctx, instructions)
+	}
+
+	// Apply a custom rubric if the orchestrator supplied one
+	if rubricJSON := req.Inputs["rubric"]; rubricJSON != "" {
+		var rubric llm.Rubric
+		if err := json.Unmarshal([]byte(rubricJSON), &rubric); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"session_id": req.SessionID,
+				"error":      err,
+			}).Warn("Invalid rubric in inputs, using default rubric")
+		} else {
+			ctx = llm.WithRubric(ctx, &rubric)
+			s.logger.WithFields(logrus.Fields{
+				"session_id": req.SessionID,
+				"rubric_id":  rubric.ID,
+			}).Info("Using custom critique rubric")
+		}
+	}
+
+	// Record which model, after any fallbacks, produces the artifacts
+	ctx, usage := llm.WithModelUsage(ctx)
+	ctx, trace := withPromptTrace(ctx, req)
+
+	// Perform critique
+	critiqueResponse, err := s.reviewer.CritiqueLesson(ctx, lessonJSON)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"error":      err,
+		}).Error("Lesson critique failed")
+		return adk.TaskResponse{}, fmt.Errorf("lesson critique failed: %w", err)
+	}
+
+	// Convert critique to JSON strings
+	critiqueJSON, err := json.Marshal(critiqueResponse.Issues)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"error":      err,
+		}).Error("Failed to marshal critique issues")
+		return adk.TaskResponse{}, fmt.Errorf("failed to marshal critique issues: %w", err)
+	}
+
+	patchPlanJSON, err := json.Marshal(critiqueResponse.PatchPlan)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"error":      err,
+		}).Error("Failed to marshal patch plan")
+		return adk.TaskResponse{}, fmt.Errorf("failed to marshal patch plan: %w", err)
+	}
+
+	// Record who reviewed the lesson alongside the critique
+	reviewer := llm.ReviewerIdentity{Provider: s.config.Provider, Model: usage.Model()}
+	if reviewer.Model == "" && s.config.Provider == llm.CriticProviderGemini {
+		reviewer.Model = llm.ModelFromContext(ctx)
+	} else if reviewer.Model == "" {
+		reviewer.Model = s.config.Model
+	}
+	reviewerJSON, err := json.Marshal(reviewer)
+	if err != nil {
+		return adk.TaskResponse{}, fmt.Errorf("failed to marshal reviewer: %w", err)
+	}
+
+	// Create response
+	response := adk.TaskResponse{
+		Artifacts: map[string]string{
+			"critique":   string(critiqueJSON),
+			"patch_plan": string(patchPlanJSON),
+			"reviewer":   string(reviewerJSON),
+		},
+		Metrics: map[string]interface{}{
+			"issues_count":     len(critiqueResponse.Issues),
+			"patch_plan_count": len(critiqueResponse.PatchPlan),
+			"critical_issues":  CountIssuesBySeverity(critiqueResponse.Issues, "critical"),
+			"high_issues":      CountIssuesBySeverity(critiqueResponse.Issues, "high"),
+			"medium_issues":    CountIssuesBySeverity(critiqueResponse.Issues, "medium"),
+			"low_issues":       CountIssuesBySeverity(critiqueResponse.Issues, "low"),
+		},
+	}
+	usage.Metrics(response.Metrics)
+	attachPromptTrace(&response, trace)
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":       req.SessionID,
+		"issues_count":     len(critiqueResponse.Issues),
+		"patch_plan_count": len(critiqueResponse.PatchPlan),
+		"reviewer":         reviewer.Provider + "/" + reviewer.Model,
+	}).Info("Critique task completed successfully")
+
+	return response, nil
+}
+
+// CountIssuesBySeverity counts issues by severity level
+func CountIssuesBySeverity(issues []llm.CritiqueIssue, severity string) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,147 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// ExplainerProcessor explains topics as OG lessons
+type ExplainerProcessor struct {
+	geminiClient llm.GeminiClientInterface
+	logger       *logrus.Logger
+}
+
+// NewExplainerProcessor creates a explainer processor
+func NewExplainerProcessor(client llm.GeminiClientInterface, logger *logrus.Logger) *ExplainerProcessor {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &ExplainerProcessor{
+		geminiClient: client,
+		logger:       logger,
+	}
+}
+
+// Capabilities implements adk.CapabilityReporter
+func (s *ExplainerProcessor) Capabilities() adk.Capabilities {
+	return adk.Capabilities{
+		Steps:            []string{Explainer},
+		ExplanationTypes: ExplanationTypes,
+		MaxContextChars:  maxContextChars,
+		MaxContextTokens: maxContextTokens,
+	}
+}
+
+// ProcessTask processes an explanation task
+func (s *ExplainerProcessor) ProcessTask(ctx context.Context, req adk.TaskRequest) (adk.TaskResponse, error) {
+	s.logger.WithFields(logrus.Fields{
+		"session_id": req.SessionID,
+		"step":       req.Step,
+		"topic":      req.Topic,
+	}).Info("Processing explanation task")
+
+	// Extract required inputs
+	topic, exists := req.Inputs["topic"]
+	if !exists || topic == "" {
+		return adk.TaskResponse{}, fmt.Errorf("topic is required in inputs")
+	}
+
+	outline, exists := req.Inputs["outline"]
+	if !exists {
+		outline = "" // Outline is optional
+	}
+
+	misconceptions, exists := req.Inputs["misconceptions"]
+	if !exists {
+		misconceptions = "" // Misconceptions are optional
+	}
+
+	// Use the model requested for this session if the orchestrator supplied one
+	if model := req.Inputs["model"]; model != "" {
+		ctx = llm.WithModel(ctx, model)
+	}
+
+	// Generate reproducibly if the session asked for deterministic output
+	if req.Inputs["deterministic"] == "true" {
+		ctx = llm.WithDeterministic(ctx)
+	}
+
+	// Follow the prompt experiment variant the session was assigned to, if any
+	if instructions := req.Inputs["prompt_instructions"]; instructions != "" {
+		ctx = llm.WithPromptInstructions(ctx, instructions)
+	}
+
+	// Write for the requested persona if the orchestrator supplied one
+	if persona := llm.LookupPersona(req.Inputs["persona"]); persona != nil {
+		ctx = llm.WithPersona(ctx, persona)
+	}
+
+	// Reason in a private scratchpad first if the orchestrator asked for one
+	if req.Inputs["scratchpad"] == "true" {
+		ctx = llm.WithScratchpad(ctx)
+	}
+
+	context, exists := req.Inputs["context"]
+	if !exists {
+		context = "" // Context is optional
+	}
+
+	// Record which model, after any fallbacks, produces the artifacts
+	ctx, usage := llm.WithModelUsage(ctx)
+	ctx, trace := withPromptTrace(ctx, req)
+
+	// Generate OG lesson
+	ogLesson, err := s.geminiClient.ExplainWithOG(ctx, topic, outline, misconceptions, context)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"error":      err,
+		}).Error("OG lesson generation failed")
+		return adk.TaskResponse{}, fmt.Errorf("OG lesson generation failed: %w", err)
+	}
+
+	// Convert OGLesson to JSON string
+	lessonJSON, err := json.Marshal(ogLesson)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"error":      err,
+		}).Error("Failed to marshal OG lesson")
+		return adk.TaskResponse{}, fmt.Errorf("failed to marshal OG lesson: %w", err)
+	}
+
+	// Create response
+	response := adk.TaskResponse{
+		Artifacts: map[string]string{
+			"lesson": string(lessonJSON),
+		},
+		Metrics: map[string]interface{}{
+			"big_picture_length":      len(ogLesson.BigPicture),
+			"metaphor_length":         len(ogLesson.Metaphor),
+			"core_mechanism_length":   len(ogLesson.CoreMechanism),
+			"toy_example_code_length": len(ogLesson.ToyExampleCode),
+			"memory_hook_length":      len(ogLesson.MemoryHook),
+			"real_life_length":        len(ogLesson.RealLife),
+			"best_practices_length":   len(ogLesson.BestPractices),
+		},
+	}
+	// The orchestrator strips the scratchpad before the lesson reaches users
+	if ogLesson.Scratchpad != "" {
+		response.Artifacts[ScratchpadArtifact] = ogLesson.Scratchpad
+		response.Metrics["scratchpad_length"] = len(ogLesson.Scratchpad)
+	}
+	usage.Metrics(response.Metrics)
+	attachPromptTrace(&response, trace)
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id": req.SessionID,
+		"topic":      topic,
+	}).Info("Explanation task completed successfully")
+
+	return response, nil
+}
@@ -0,0 +1,19 @@
+module github.com/InnoFusionTech/ExplainIQ/internal/agents
+
+go 1.24.4
+
+toolchain go1.24.10
+
+require (
+	github.com/InnoFusionTech/ExplainIQ/internal/adk v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/llm v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/tokens v0.0.0
+	github.com/sirupsen/logrus v1.9.3
+	github.com/stretchr/testify v1.11.1
+)
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/adk => ../adk
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/llm => ../llm
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/tokens => ../tokens
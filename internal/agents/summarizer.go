@@ -0,0 +1,210 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/InnoFusionTech/ExplainIQ/internal/tokens"
+	"github.com/sirupsen/logrus"
+)
+
+// SummarizerProcessor summarizes topics into an outline, prerequisites, misconceptions and citations
+type SummarizerProcessor struct {
+	geminiClient llm.GeminiClientInterface
+	costTracker  CostTracker
+	grounder     llm.Grounder // Researches topics with Google Search; nil disables grounding
+	logger       *logrus.Logger
+}
+
+// NewSummarizerProcessor creates a summarizer processor. costTracker may be nil.
+// Grounding is available when a Vertex AI project is configured in the environment.
+func NewSummarizerProcessor(client llm.GeminiClientInterface, costTracker CostTracker, logger *logrus.Logger) *SummarizerProcessor {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	s := &SummarizerProcessor{
+		geminiClient: client,
+		costTracker:  costTracker,
+		logger:       logger,
+	}
+	if grounder := llm.GroundingClientFromEnv(); grounder != nil {
+		s.grounder = grounder
+	}
+	return s
+}
+
+// SetGrounder replaces the grounder used for web-grounded summaries; nil disables grounding
+func (s *SummarizerProcessor) SetGrounder(grounder llm.Grounder) {
+	s.grounder = grounder
+}
+
+// ground researches topic on the web when the task's grounding mode calls for it.
+// Grounding failures are logged and the summary proceeds without web results.
+func (s *SummarizerProcessor) ground(ctx context.Context, req adk.TaskRequest, topic string) *llm.GroundingResult {
+	mode := req.Inputs["grounding"]
+	if !llm.ShouldGround(mode, topic) {
+		return nil
+	}
+	if s.grounder == nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"mode":       mode,
+		}).Warn("Grounding requested but no grounding project is configured")
+		return nil
+	}
+
+	result, err := s.grounder.Ground(ctx, topic)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"error":      err,
+		}).Warn("Grounding failed, summarizing without web results")
+		return nil
+	}
+	return result
+}
+
+// Capabilities implements adk.CapabilityReporter
+func (s *SummarizerProcessor) Capabilities() adk.Capabilities {
+	return adk.Capabilities{Steps: []string{Summarizer}, MaxContextChars: maxContextChars, MaxContextTokens: maxContextTokens}
+}
+
+// ProcessTask processes a summarization task
+func (s *SummarizerProcessor) ProcessTask(ctx context.Context, req adk.TaskRequest) (adk.TaskResponse, error) {
+	s.logger.WithFields(logrus.Fields{
+		"session_id": req.SessionID,
+		"step":       req.Step,
+		"topic":      req.Topic,
+	}).Info("Processing summarization task")
+
+	// Extract topic and context from inputs
+	topic, exists := req.Inputs["topic"]
+	if !exists || topic == "" {
+		return adk.TaskResponse{}, fmt.Errorf("topic is required in inputs")
+	}
+
+	// Use the model requested for this session if the orchestrator supplied one
+	if model := req.Inputs["model"]; model != "" {
+		ctx = llm.WithModel(ctx, model)
+	}
+
+	// Generate reproducibly if the session asked for deterministic output
+	if req.Inputs["deterministic"] == "true" {
+		ctx = llm.WithDeterministic(ctx)
+	}
+
+	// Follow the prompt experiment variant the session was assigned to, if any
+	if instructions := req.Inputs["prompt_instructions"]; instructions != "" {
+		ctx = llm.WithPromptInstructions(ctx, instructions)
+	}
+
+	// Write for the requested persona if the orchestrator supplied one
+	if persona := llm.LookupPersona(req.Inputs["persona"]); persona != nil {
+		ctx = llm.WithPersona(ctx, persona)
+	}
+
+	context, exists := req.Inputs["context"]
+	if !exists {
+		context = "" // Context is optional
+	}
+
+	// Add web research for current-events and factual topics when the session asked for it
+	grounding := s.ground(ctx, req, topic)
+	if grounding != nil {
+		context = strings.TrimSpace(llm.GroundedContext(grounding) + "\n\n" + context)
+	}
+
+	// Record which model, after any fallbacks, produces the artifacts
+	ctx, usage := llm.WithModelUsage(ctx)
+	ctx, trace := withPromptTrace(ctx, req)
+
+	// Perform summarization
+	result, err := s.geminiClient.Summarize(ctx, topic, context)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"error":      err,
+		}).Error("Summarization failed")
+		return adk.TaskResponse{}, fmt.Errorf("summarization failed: %w", err)
+	}
+
+	// Track LLM call cost from the reported usage, estimating it when the model reported none
+	if s.costTracker != nil {
+		model := usage.Model()
+		if model == "" {
+			model = "gemini-pro"
+		}
+		inputTokens, outputTokens := usage.Tokens()
+		if inputTokens == 0 && outputTokens == 0 {
+			inputTokens = tokens.Estimate(model, topic+"\n"+context)
+			outputTokens = tokens.Estimate(model, strings.Join(result.Outline, "\n")+"\n"+
+				strings.Join(result.Prerequisites, "\n")+"\n"+strings.Join(result.Misconceptions, "\n"))
+		}
+
+		// Track the cost
+		if err := s.costTracker.TrackLLMCall(ctx, req.SessionID, "", "", model, inputTokens, outputTokens); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"session_id": req.SessionID,
+				"error":      err,
+			}).Warn("Failed to track LLM call cost")
+		}
+	}
+
+	// Convert result to artifacts
+	artifacts := make(map[string]string)
+
+	// Convert arrays to JSON strings
+	if outlineJSON, err := json.Marshal(result.Outline); err == nil {
+		artifacts["outline"] = string(outlineJSON)
+	}
+
+	if prereqJSON, err := json.Marshal(result.Prerequisites); err == nil {
+		artifacts["prerequisites"] = string(prereqJSON)
+	}
+
+	if misconJSON, err := json.Marshal(result.Misconceptions); err == nil {
+		artifacts["misconceptions"] = string(misconJSON)
+	}
+
+	if citationsJSON, err := json.Marshal(result.Citations); err == nil {
+		artifacts["citations"] = string(citationsJSON)
+	}
+
+	if grounding != nil {
+		if groundingJSON, err := json.Marshal(grounding.Citations); err == nil {
+			artifacts["grounding_citations"] = string(groundingJSON)
+		}
+		artifacts["web_grounded"] = "true"
+	}
+
+	// Create response
+	response := adk.TaskResponse{
+		Artifacts: artifacts,
+		Metrics: map[string]interface{}{
+			"outline_count":        len(result.Outline),
+			"prerequisites_count":  len(result.Prerequisites),
+			"misconceptions_count": len(result.Misconceptions),
+			"citations_count":      len(result.Citations),
+		},
+	}
+	usage.Metrics(response.Metrics)
+	attachPromptTrace(&response, trace)
+	if grounding != nil {
+		response.Metrics["web_grounded"] = true
+		response.Metrics["grounding_citations_count"] = len(grounding.Citations)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":     req.SessionID,
+		"outline_count":  len(result.Outline),
+		"prereq_count":   len(result.Prerequisites),
+		"miscon_count":   len(result.Misconceptions),
+		"citation_count": len(result.Citations),
+	}).Info("Summarization task completed successfully")
+
+	return response, nil
+}
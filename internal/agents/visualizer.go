@@ -0,0 +1,139 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// VisualizerProcessor generates diagrams and captions for lessons
+type VisualizerProcessor struct {
+	geminiClient llm.GeminiClientInterface
+	logger       *logrus.Logger
+}
+
+// NewVisualizerProcessor creates a visualizer processor
+func NewVisualizerProcessor(client llm.GeminiClientInterface, logger *logrus.Logger) *VisualizerProcessor {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &VisualizerProcessor{
+		geminiClient: client,
+		logger:       logger,
+	}
+}
+
+// Capabilities implements adk.CapabilityReporter
+func (s *VisualizerProcessor) Capabilities() adk.Capabilities {
+	return adk.Capabilities{Steps: []string{Visualizer}}
+}
+
+// ProcessTask processes a visualization task
+func (s *VisualizerProcessor) ProcessTask(ctx context.Context, req adk.TaskRequest) (adk.TaskResponse, error) {
+	s.logger.WithFields(logrus.Fields{
+		"session_id": req.SessionID,
+		"step":       req.Step,
+		"topic":      req.Topic,
+	}).Info("Processing visualization task")
+
+	// Extract lesson JSON from inputs
+	lessonJSON, exists := req.Inputs["lesson"]
+	if !exists || lessonJSON == "" {
+		return adk.TaskResponse{}, fmt.Errorf("lesson JSON is required in inputs")
+	}
+
+	// Generate reproducibly if the session asked for deterministic output
+	if req.Inputs["deterministic"] == "true" {
+		ctx = llm.WithDeterministic(ctx)
+	}
+
+	// Draw in the session's style preset, if it chose one
+	imageStyle := ""
+	if style, ok := llm.LookupImageStyle(req.Inputs["image_style"]); ok {
+		ctx = llm.WithImageStyle(ctx, style)
+		imageStyle = style.ID
+	}
+
+	// Record which model, after any fallbacks, produces the artifacts
+	ctx, usage := llm.WithModelUsage(ctx)
+	ctx, trace := withPromptTrace(ctx, req)
+
+	// Generate visualizations
+	visualizeResponse, err := s.geminiClient.VisualizeCore(ctx, lessonJSON, req.SessionID)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"error":      err,
+		}).Error("Visualization generation failed")
+		return adk.TaskResponse{}, fmt.Errorf("visualization generation failed: %w", err)
+	}
+
+	// Every image needs a text alternative; repair any the model left empty
+	images, missingAltText := llm.EnsureImageAccessibility(visualizeResponse.Images, req.Topic)
+	if len(missingAltText) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"repaired":   len(missingAltText),
+		}).Warn("Generated missing alt text for images")
+	}
+	visualizeResponse.Images = images
+
+	accessibilityJSON, err := json.Marshal(llm.BuildAccessibilityInfo(images, len(missingAltText)))
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"error":      err,
+		}).Error("Failed to marshal accessibility metadata")
+		return adk.TaskResponse{}, fmt.Errorf("failed to marshal accessibility metadata: %w", err)
+	}
+
+	// Convert images and captions to JSON strings
+	imagesJSON, err := json.Marshal(visualizeResponse.Images)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"error":      err,
+		}).Error("Failed to marshal images")
+		return adk.TaskResponse{}, fmt.Errorf("failed to marshal images: %w", err)
+	}
+
+	captionsJSON, err := json.Marshal(visualizeResponse.Captions)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"session_id": req.SessionID,
+			"error":      err,
+		}).Error("Failed to marshal captions")
+		return adk.TaskResponse{}, fmt.Errorf("failed to marshal captions: %w", err)
+	}
+
+	// Create response
+	response := adk.TaskResponse{
+		Artifacts: map[string]string{
+			"images":        string(imagesJSON),
+			"captions":      string(captionsJSON),
+			"accessibility": string(accessibilityJSON),
+		},
+		Metrics: map[string]interface{}{
+			"images_count":      len(visualizeResponse.Images),
+			"captions_count":    len(visualizeResponse.Captions),
+			"repaired_alt_text": len(missingAltText),
+		},
+	}
+	if imageStyle != "" {
+		response.Metrics["image_style"] = imageStyle
+	}
+	usage.Metrics(response.Metrics)
+	attachPromptTrace(&response, trace)
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":     req.SessionID,
+		"images_count":   len(visualizeResponse.Images),
+		"captions_count": len(visualizeResponse.Captions),
+	}).Info("Visualization task completed successfully")
+
+	return response, nil
+}
@@ -0,0 +1,377 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// APIKeyPrefix marks a bearer credential as an API key rather than a JWT
+	APIKeyPrefix = "eiq_"
+	// apiKeyStorageKey is the storage key holding the deployment's API keys
+	apiKeyStorageKey = "api_keys"
+)
+
+// Scope is a permission granted to an API key
+type Scope string
+
+const (
+	// ScopeSessionsRead allows listing sessions and reading their results
+	ScopeSessionsRead Scope = "sessions:read"
+	// ScopeSessionsWrite allows creating and running sessions; it implies sessions:read
+	ScopeSessionsWrite Scope = "sessions:write"
+	// ScopeAdmin allows managing flags, rubrics and API keys; it implies every other scope
+	ScopeAdmin Scope = "admin"
+)
+
+// ValidScopes lists the scopes an API key may be granted
+var ValidScopes = []Scope{ScopeSessionsRead, ScopeSessionsWrite, ScopeAdmin}
+
+var (
+	// ErrInvalidAPIKey is returned when a presented key is unknown or malformed
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrAPIKeyRevoked is returned when a presented key has been revoked
+	ErrAPIKeyRevoked = errors.New("API key has been revoked")
+	// ErrAPIKeyExpired is returned when a presented key is past its expiry
+	ErrAPIKeyExpired = errors.New("API key has expired")
+	// ErrAPIKeyNotFound is returned when revoking a key that does not exist
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+// APIKeyStore defines the storage operations needed to persist API keys
+type APIKeyStore interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// APIKey represents an API key issued to a user or organization. Only a hash of
+// the secret is kept; the plaintext key is returned once, when the key is created.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	UserID     string     `json:"user_id,omitempty"`
+	OrgID      string     `json:"org_id,omitempty"`
+	Scopes     []Scope    `json:"scopes"`
+	SecretHash string     `json:"secret_hash,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key grants a scope
+func (k *APIKey) HasScope(scope Scope) bool {
+	return ScopesAllow(k.Scopes, scope)
+}
+
+// Revoked reports whether the key has been revoked
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// public returns a copy of the key that is safe to return to callers
+func (k *APIKey) public() APIKey {
+	key := *k
+	key.SecretHash = ""
+	key.Scopes = append([]Scope(nil), k.Scopes...)
+	return key
+}
+
+// ScopesAllow reports whether a set of granted scopes satisfies a required scope
+func ScopesAllow(granted []Scope, required Scope) bool {
+	for _, scope := range granted {
+		switch {
+		case scope == required, scope == ScopeAdmin:
+			return true
+		case scope == ScopeSessionsWrite && required == ScopeSessionsRead:
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScopes validates and deduplicates scope names
+func ParseScopes(names []string) ([]Scope, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+
+	seen := make(map[Scope]bool, len(names))
+	scopes := make([]Scope, 0, len(names))
+	for _, name := range names {
+		scope := Scope(strings.TrimSpace(name))
+		valid := false
+		for _, s := range ValidScopes {
+			if scope == s {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown scope %q", name)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// CreateAPIKeyRequest describes a key to issue
+type CreateAPIKeyRequest struct {
+	Name      string
+	UserID    string
+	OrgID     string
+	Scopes    []Scope
+	ExpiresIn time.Duration // Zero means the key does not expire
+}
+
+// APIKeyService issues, revokes and authenticates API keys
+type APIKeyService struct {
+	store  APIKeyStore
+	logger *logrus.Logger
+	mu     sync.RWMutex
+	keys   map[string]*APIKey
+	now    func() time.Time
+}
+
+// NewAPIKeyService creates an API key service. A nil store keeps keys in memory only.
+func NewAPIKeyService(store APIKeyStore) *APIKeyService {
+	return &APIKeyService{
+		store:  store,
+		logger: logrus.New(),
+		keys:   make(map[string]*APIKey),
+		now:    time.Now,
+	}
+}
+
+// Load reloads the issued keys from storage
+func (s *APIKeyService) Load(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+
+	data, err := s.store.Get(ctx, apiKeyStorageKey)
+	if err != nil {
+		return fmt.Errorf("failed to load API keys: %w", err)
+	}
+
+	var stored []*APIKey
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return fmt.Errorf("failed to decode API keys: %w", err)
+		}
+	}
+
+	keys := make(map[string]*APIKey, len(stored))
+	for _, key := range stored {
+		keys[key.ID] = key
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	return nil
+}
+
+// Create issues a new key and returns it along with the plaintext secret, which is not stored
+func (s *APIKeyService) Create(ctx context.Context, req CreateAPIKeyRequest) (APIKey, string, error) {
+	if req.UserID == "" && req.OrgID == "" {
+		return APIKey{}, "", fmt.Errorf("an API key must belong to a user or an organization")
+	}
+	if len(req.Scopes) == 0 {
+		return APIKey{}, "", fmt.Errorf("at least one scope is required")
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	now := s.now()
+	key := &APIKey{
+		ID:         id,
+		Name:       req.Name,
+		UserID:     req.UserID,
+		OrgID:      req.OrgID,
+		Scopes:     append([]Scope(nil), req.Scopes...),
+		SecretHash: hashSecret(secret),
+		CreatedAt:  now,
+	}
+	if req.ExpiresIn > 0 {
+		expiresAt := now.Add(req.ExpiresIn)
+		key.ExpiresAt = &expiresAt
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[id] = key
+	if err := s.persistLocked(ctx); err != nil {
+		delete(s.keys, id)
+		return APIKey{}, "", err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"key_id":  id,
+		"user_id": req.UserID,
+		"org_id":  req.OrgID,
+		"scopes":  req.Scopes,
+	}).Info("API key created")
+
+	return key.public(), APIKeyPrefix + id + "_" + secret, nil
+}
+
+// List returns the keys belonging to a user or an organization, newest first.
+// Empty filters match every key.
+func (s *APIKeyService) List(userID, orgID string) []APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]APIKey, 0)
+	for _, key := range s.keys {
+		if userID != "" && key.UserID != userID {
+			continue
+		}
+		if orgID != "" && key.OrgID != orgID {
+			continue
+		}
+		keys = append(keys, key.public())
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys
+}
+
+// Get returns a key by ID
+func (s *APIKeyService) Get(id string) (APIKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return APIKey{}, false
+	}
+	return key.public(), true
+}
+
+// Revoke revokes a key so it can no longer authenticate. Revoking a revoked key is a no-op.
+func (s *APIKeyService) Revoke(ctx context.Context, id string) (APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+	if key.Revoked() {
+		return key.public(), nil
+	}
+
+	now := s.now()
+	key.RevokedAt = &now
+	if err := s.persistLocked(ctx); err != nil {
+		key.RevokedAt = nil
+		return APIKey{}, err
+	}
+
+	s.logger.WithField("key_id", id).Info("API key revoked")
+	return key.public(), nil
+}
+
+// Authenticate validates a plaintext key and returns the key it belongs to.
+// Last-used times are tracked in memory only, to keep authentication free of storage writes.
+func (s *APIKeyService) Authenticate(raw string) (APIKey, error) {
+	id, secret, ok := parseAPIKey(raw)
+	if !ok {
+		return APIKey{}, ErrInvalidAPIKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists || subtle.ConstantTimeCompare([]byte(key.SecretHash), []byte(hashSecret(secret))) != 1 {
+		return APIKey{}, ErrInvalidAPIKey
+	}
+	if key.Revoked() {
+		return APIKey{}, ErrAPIKeyRevoked
+	}
+	now := s.now()
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return APIKey{}, ErrAPIKeyExpired
+	}
+
+	key.LastUsedAt = &now
+	return key.public(), nil
+}
+
+// persistLocked writes the keys to storage; the caller must hold s.mu
+func (s *APIKeyService) persistLocked(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+
+	keys := make([]*APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to encode API keys: %w", err)
+	}
+	if err := s.store.Set(ctx, apiKeyStorageKey, data); err != nil {
+		return fmt.Errorf("failed to persist API keys: %w", err)
+	}
+	return nil
+}
+
+// IsAPIKey reports whether a credential looks like an API key
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// parseAPIKey splits a plaintext key into its ID and secret
+func parseAPIKey(raw string) (id, secret string, ok bool) {
+	if !IsAPIKey(raw) {
+		return "", "", false
+	}
+	id, secret, ok = strings.Cut(strings.TrimPrefix(raw, APIKeyPrefix), "_")
+	if !ok || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+// hashSecret returns the stored form of a key secret
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryKeyStore is an in-memory APIKeyStore
+type memoryKeyStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	err  error
+}
+
+func newMemoryKeyStore() *memoryKeyStore {
+	return &memoryKeyStore{data: make(map[string][]byte)}
+}
+
+func (m *memoryKeyStore) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[key], nil
+}
+
+func (m *memoryKeyStore) Set(ctx context.Context, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.data[key] = value
+	return nil
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	store := newMemoryKeyStore()
+	service := NewAPIKeyService(store)
+
+	key, secret, err := service.Create(context.Background(), CreateAPIKeyRequest{
+		Name:   "ci",
+		UserID: "user-1",
+		Scopes: []Scope{ScopeSessionsRead},
+	})
+	require.NoError(t, err)
+	assert.True(t, IsAPIKey(secret))
+	assert.Empty(t, key.SecretHash, "secret hash must not be returned")
+	assert.NotContains(t, string(store.data[apiKeyStorageKey]), secret[len(APIKeyPrefix)+len(key.ID)+1:])
+
+	authenticated, err := service.Authenticate(secret)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, authenticated.ID)
+	assert.NotNil(t, authenticated.LastUsedAt)
+
+	// Keys survive a reload from storage
+	reloaded := NewAPIKeyService(store)
+	require.NoError(t, reloaded.Load(context.Background()))
+	_, err = reloaded.Authenticate(secret)
+	assert.NoError(t, err)
+
+	_, err = service.Authenticate(secret + "x")
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	_, err = service.Authenticate("not-a-key")
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+}
+
+func TestAPIKeyService_RevokeAndExpiry(t *testing.T) {
+	service := NewAPIKeyService(nil)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	key, secret, err := service.Create(context.Background(), CreateAPIKeyRequest{
+		OrgID:     "org-1",
+		Scopes:    []Scope{ScopeAdmin},
+		ExpiresIn: time.Hour,
+	})
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Hour)
+	_, err = service.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrAPIKeyExpired)
+
+	revoked, err := service.Revoke(context.Background(), key.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked.Revoked())
+	_, err = service.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrAPIKeyRevoked)
+
+	_, err = service.Revoke(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyService_CreateRollsBackOnStoreFailure(t *testing.T) {
+	store := newMemoryKeyStore()
+	store.err = errors.New("unavailable")
+	service := NewAPIKeyService(store)
+
+	_, _, err := service.Create(context.Background(), CreateAPIKeyRequest{UserID: "user-1", Scopes: []Scope{ScopeSessionsRead}})
+	assert.Error(t, err)
+	assert.Empty(t, service.List("", ""))
+}
+
+func TestAPIKeyService_ListFilters(t *testing.T) {
+	service := NewAPIKeyService(nil)
+	ctx := context.Background()
+	_, _, err := service.Create(ctx, CreateAPIKeyRequest{UserID: "user-1", Scopes: []Scope{ScopeSessionsRead}})
+	require.NoError(t, err)
+	_, _, err = service.Create(ctx, CreateAPIKeyRequest{UserID: "user-2", OrgID: "org-1", Scopes: []Scope{ScopeSessionsRead}})
+	require.NoError(t, err)
+
+	assert.Len(t, service.List("user-1", ""), 1)
+	assert.Len(t, service.List("", "org-1"), 1)
+	assert.Len(t, service.List("", ""), 2)
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes([]string{"sessions:read", "admin", "sessions:read"})
+	require.NoError(t, err)
+	assert.Equal(t, []Scope{ScopeSessionsRead, ScopeAdmin}, scopes)
+
+	_, err = ParseScopes([]string{"sessions:delete"})
+	assert.Error(t, err)
+	_, err = ParseScopes(nil)
+	assert.Error(t, err)
+}
+
+func TestScopesAllow(t *testing.T) {
+	assert.True(t, ScopesAllow([]Scope{ScopeSessionsWrite}, ScopeSessionsRead))
+	assert.False(t, ScopesAllow([]Scope{ScopeSessionsRead}, ScopeSessionsWrite))
+	assert.True(t, ScopesAllow([]Scope{ScopeAdmin}, ScopeSessionsWrite))
+	assert.False(t, ScopesAllow([]Scope{ScopeSessionsWrite}, ScopeAdmin))
+}
+
+func TestHTTPMiddleware_APIKeyScopes(t *testing.T) {
+	service := NewAPIKeyService(nil)
+	_, readKey, err := service.Create(context.Background(), CreateAPIKeyRequest{UserID: "user-1", Scopes: []Scope{ScopeSessionsRead}})
+	require.NoError(t, err)
+
+	var seen *Principal
+	handler := HTTPMiddleware(service, nil, false)(RequireScope(ScopeSessionsWrite, false)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen, _ = PrincipalFromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})))
+
+	serve := func(header, value string) int {
+		req := httptest.NewRequest("POST", "/api/sessions", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Anonymous requests pass while auth is optional
+	assert.Equal(t, http.StatusOK, serve("", ""))
+	assert.Nil(t, seen)
+
+	// A read-only key cannot write
+	assert.Equal(t, http.StatusForbidden, serve(APIKeyHeader, readKey))
+	assert.Equal(t, http.StatusForbidden, serve("Authorization", "Bearer "+readKey))
+
+	// Invalid keys are rejected even when auth is optional
+	assert.Equal(t, http.StatusUnauthorized, serve(APIKeyHeader, APIKeyPrefix+"bogus_key"))
+
+	_, writeKey, err := service.Create(context.Background(), CreateAPIKeyRequest{OrgID: "org-1", Scopes: []Scope{ScopeSessionsWrite}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serve(APIKeyHeader, writeKey))
+	require.NotNil(t, seen)
+	assert.Equal(t, MethodAPIKey, seen.Method)
+	assert.Equal(t, "org-1", seen.OrgID)
+}
+
+func TestHTTPMiddleware_Required(t *testing.T) {
+	handler := HTTPMiddleware(NewAPIKeyService(nil), nil, true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/api/sessions", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Health checks stay public
+	req = httptest.NewRequest("GET", "/healthz", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestHTTPMiddleware_InvalidJWT tests that an invalid JWT is rejected even when authentication is optional
+func TestHTTPMiddleware_InvalidJWT(t *testing.T) {
+	handler := HTTPMiddleware(nil, NewClient("http://localhost:8080"), false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/api/sessions", nil)
+	req.Header.Set("Authorization", "Bearer invalid-token")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
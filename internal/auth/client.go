@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -23,6 +24,18 @@ type Client struct {
 	GoogleCertURL string
 	logger        *logrus.Logger
 	httpClient    *http.Client
+
+	tokenMu  sync.Mutex
+	idTokens map[string]cachedIDToken // By audience
+}
+
+// idTokenRefreshMargin is how long before expiry a cached ID token is replaced
+const idTokenRefreshMargin = 5 * time.Minute
+
+// cachedIDToken is an ID token held until shortly before it expires
+type cachedIDToken struct {
+	token     string
+	expiresAt time.Time
 }
 
 // GoogleJWKS represents Google's JSON Web Key Set
@@ -49,11 +62,39 @@ func NewClient(serviceURL string) *Client {
 		GoogleCertURL: "https://www.googleapis.com/oauth2/v3/certs",
 		logger:        logrus.New(),
 		httpClient:    &http.Client{Timeout: 30 * time.Second},
+		idTokens:      make(map[string]cachedIDToken),
 	}
 }
 
-// GetIDToken obtains an ID token from the metadata server for service-to-service authentication
+// GetIDToken obtains an ID token from the metadata server for service-to-service authentication.
+// Tokens are cached per audience and reused until shortly before they expire.
 func (c *Client) GetIDToken(ctx context.Context, targetAudience string) (string, error) {
+	c.tokenMu.Lock()
+	cached, ok := c.idTokens[targetAudience]
+	c.tokenMu.Unlock()
+	if ok && time.Until(cached.expiresAt) > idTokenRefreshMargin {
+		return cached.token, nil
+	}
+
+	token, err := c.fetchIDToken(ctx, targetAudience)
+	if err != nil {
+		return "", err
+	}
+
+	// Tokens without a readable expiry are not cached
+	if expiresAt, ok := idTokenExpiry(token); ok {
+		c.tokenMu.Lock()
+		if c.idTokens == nil {
+			c.idTokens = make(map[string]cachedIDToken)
+		}
+		c.idTokens[targetAudience] = cachedIDToken{token: token, expiresAt: expiresAt}
+		c.tokenMu.Unlock()
+	}
+	return token, nil
+}
+
+// fetchIDToken requests a new ID token from the metadata server
+func (c *Client) fetchIDToken(ctx context.Context, targetAudience string) (string, error) {
 	c.logger.WithField("target_audience", targetAudience).Info("Obtaining ID token from metadata server")
 
 	req, err := http.NewRequestWithContext(ctx, "GET", c.MetadataURL, nil)
@@ -90,6 +131,20 @@ func (c *Client) GetIDToken(ctx context.Context, targetAudience string) (string,
 	return token, nil
 }
 
+// idTokenExpiry reads the expiry of an ID token without verifying it; the token comes
+// straight from the metadata server and is verified by its audience
+func idTokenExpiry(token string) (time.Time, bool) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return time.Time{}, false
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return time.Time{}, false
+	}
+	return exp.Time, true
+}
+
 // ValidateGoogleJWT validates a Google-signed JWT token
 func (c *Client) ValidateGoogleJWT(ctx context.Context, tokenString string) (*Claims, error) {
 	c.logger.Debug("Validating Google JWT token")
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetIDTokenCachesByAudience tests reusing ID tokens per audience until they near expiry
+func TestGetIDTokenCachesByAudience(t *testing.T) {
+	var requests atomic.Int32
+	expiresIn := time.Hour
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"aud": r.URL.Query().Get("audience"),
+			"exp": time.Now().Add(expiresIn).Unix(),
+		}).SignedString([]byte("test"))
+		require.NoError(t, err)
+		w.Write([]byte(token))
+	}))
+	defer server.Close()
+
+	client := NewClient("http://localhost:8080")
+	client.MetadataURL = server.URL
+
+	first, err := client.GetIDToken(context.Background(), "https://summarizer.example.com")
+	require.NoError(t, err)
+	second, err := client.GetIDToken(context.Background(), "https://summarizer.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Each audience gets its own token
+	_, err = client.GetIDToken(context.Background(), "https://critic.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+
+	// Tokens about to expire are refreshed
+	expiresIn = time.Minute
+	_, err = client.GetIDToken(context.Background(), "https://explainer.example.com")
+	require.NoError(t, err)
+	_, err = client.GetIDToken(context.Background(), "https://explainer.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), requests.Load())
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// APIKeyHeader carries an API key for clients that cannot set an Authorization header
+const APIKeyHeader = "X-API-Key"
+
+// Authentication methods recorded on a Principal
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+)
+
+// userScopes are the scopes granted to interactively authenticated (JWT) users
+var userScopes = []Scope{ScopeSessionsRead, ScopeSessionsWrite}
+
+// Principal identifies the caller of an authenticated net/http request
+type Principal struct {
+	UserID   string
+	OrgID    string
+	Email    string
+	Method   string // MethodJWT or MethodAPIKey
+	APIKeyID string
+	Scopes   []Scope
+}
+
+// HasScope reports whether the principal was granted a scope
+func (p *Principal) HasScope(scope Scope) bool {
+	return ScopesAllow(p.Scopes, scope)
+}
+
+// principalKey is the context key for the request's Principal
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying a principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored in ctx
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// HTTPMiddleware creates a net/http middleware authenticating requests with either an
+// API key (X-API-Key header, or a Bearer token starting with APIKeyPrefix) or a Google JWT.
+// Either keys or authClient may be nil to disable that method. When required is false,
+// requests without credentials pass through unauthenticated, but invalid credentials are still rejected.
+func HTTPMiddleware(keys *APIKeyService, authClient *Client, required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isPublicEndpoint(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			credential := r.Header.Get(APIKeyHeader)
+			if credential == "" {
+				if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+					parts := strings.SplitN(authHeader, " ", 2)
+					if len(parts) != 2 || parts[0] != "Bearer" {
+						writeAuthError(w, http.StatusUnauthorized, "Invalid authorization format", "Authorization header must be 'Bearer <token>'")
+						return
+					}
+					credential = parts[1]
+				}
+			}
+
+			if credential == "" {
+				if required {
+					writeAuthError(w, http.StatusUnauthorized, "Authentication required", "Provide a Bearer token or an X-API-Key header")
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var principal *Principal
+			if IsAPIKey(credential) {
+				if keys == nil {
+					writeAuthError(w, http.StatusUnauthorized, "Invalid API key", "API keys are not enabled")
+					return
+				}
+				key, err := keys.Authenticate(credential)
+				if err != nil {
+					keys.logger.WithFields(logrus.Fields{
+						"error": err.Error(),
+						"path":  r.URL.Path,
+					}).Warn("API key authentication failed")
+					writeAuthError(w, http.StatusUnauthorized, "Invalid API key", err.Error())
+					return
+				}
+				principal = &Principal{
+					UserID:   key.UserID,
+					OrgID:    key.OrgID,
+					Method:   MethodAPIKey,
+					APIKeyID: key.ID,
+					Scopes:   key.Scopes,
+				}
+			} else {
+				if authClient == nil {
+					writeAuthError(w, http.StatusUnauthorized, "Invalid token", "JWT authentication is not enabled")
+					return
+				}
+				claims, err := authClient.ValidateGoogleJWT(r.Context(), credential)
+				if err != nil {
+					authClient.logger.WithFields(logrus.Fields{
+						"error": err.Error(),
+						"path":  r.URL.Path,
+					}).Warn("JWT validation failed")
+					writeAuthError(w, http.StatusUnauthorized, "Invalid token", "JWT token validation failed")
+					return
+				}
+				principal = &Principal{
+					UserID: claims.UserID,
+					Email:  claims.Email,
+					Method: MethodJWT,
+					Scopes: userScopes,
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireScope creates a net/http middleware rejecting principals that lack a scope.
+// Unauthenticated requests are rejected only when required is true, so routes can be
+// scope-checked for API keys while anonymous access is still being phased out.
+func RequireScope(scope Scope, required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				if required {
+					writeAuthError(w, http.StatusUnauthorized, "Authentication required", "Provide a Bearer token or an X-API-Key header")
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !principal.HasScope(scope) {
+				writeAuthError(w, http.StatusForbidden, "Insufficient scope", "This request requires the "+string(scope)+" scope")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeAuthError writes a JSON authentication error
+func writeAuthError(w http.ResponseWriter, status int, errMsg, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   errMsg,
+		"message": message,
+	})
+}
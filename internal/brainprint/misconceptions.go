@@ -0,0 +1,78 @@
+package brainprint
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// maxHeldMisconceptions caps the misconceptions kept per profile; the oldest are dropped first
+const maxHeldMisconceptions = 20
+
+// HeldMisconception is a misconception a user answered a lesson's check about incorrectly
+type HeldMisconception struct {
+	Topic         string    `json:"topic"`
+	Misconception string    `json:"misconception"`
+	SessionID     string    `json:"sessionID,omitempty"`
+	RecordedAt    time.Time `json:"recordedAt"`
+}
+
+// RecordMisconceptionChecks updates the misconceptions a user holds from their answers to a
+// lesson's misconception checks: held ones are added, or refreshed if already known, and
+// corrected ones are cleared
+func (s *Service) RecordMisconceptionChecks(ctx context.Context, userID, topic, sessionID string, held, corrected []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profile, err := s.getProfile(ctx, userID)
+	if err != nil {
+		profile = NewUserLearningProfile(userID)
+	}
+
+	drop := make(map[string]bool, len(held)+len(corrected))
+	for _, misconception := range held {
+		drop[misconception] = true
+	}
+	for _, misconception := range corrected {
+		drop[misconception] = true
+	}
+	kept := make([]HeldMisconception, 0, len(profile.Misconceptions)+len(held))
+	for _, existing := range profile.Misconceptions {
+		if !drop[existing.Misconception] {
+			kept = append(kept, existing)
+		}
+	}
+
+	now := s.now()
+	for _, misconception := range held {
+		kept = append(kept, HeldMisconception{
+			Topic:         topic,
+			Misconception: misconception,
+			SessionID:     sessionID,
+			RecordedAt:    now,
+		})
+	}
+	if len(kept) > maxHeldMisconceptions {
+		kept = kept[len(kept)-maxHeldMisconceptions:]
+	}
+	profile.Misconceptions = kept
+	profile.LastUpdated = now
+
+	if err := s.saveProfile(ctx, profile); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	return nil
+}
+
+// FollowUpMisconception returns the misconception the user most recently showed they hold
+func (s *Service) FollowUpMisconception(ctx context.Context, userID string) (*HeldMisconception, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profile, err := s.getProfile(ctx, userID)
+	if err != nil || len(profile.Misconceptions) == 0 {
+		return nil, false
+	}
+	latest := profile.Misconceptions[len(profile.Misconceptions)-1]
+	return &latest, true
+}
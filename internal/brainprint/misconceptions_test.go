@@ -0,0 +1,39 @@
+package brainprint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecordMisconceptionChecks tests that held misconceptions are recorded, refreshed, cleared and capped
+func TestRecordMisconceptionChecks(t *testing.T) {
+	service := NewService(nil)
+	ctx := context.Background()
+
+	_, ok := service.FollowUpMisconception(ctx, "user123")
+	assert.False(t, ok)
+
+	require.NoError(t, service.RecordMisconceptionChecks(ctx, "user123", "Go", "s1",
+		[]string{"Goroutines are OS threads", "Channels are always buffered"}, nil))
+	followUp, ok := service.FollowUpMisconception(ctx, "user123")
+	require.True(t, ok)
+	assert.Equal(t, "Channels are always buffered", followUp.Misconception)
+	assert.Equal(t, "Go", followUp.Topic)
+
+	// A correct answer later clears the misconception; holding one again refreshes it
+	require.NoError(t, service.RecordMisconceptionChecks(ctx, "user123", "Go", "s2",
+		[]string{"Goroutines are OS threads"}, []string{"Channels are always buffered"}))
+	profile, err := service.GetBrainPrint(ctx, "user123")
+	require.NoError(t, err)
+	require.Len(t, profile.Misconceptions, 1)
+	assert.Equal(t, "s2", profile.Misconceptions[0].SessionID)
+
+	for i := 0; i < maxHeldMisconceptions+3; i++ {
+		require.NoError(t, service.RecordMisconceptionChecks(ctx, "user123", "Go", "s3", []string{string(rune('a' + i))}, nil))
+	}
+	profile, _ = service.GetBrainPrint(ctx, "user123")
+	assert.Len(t, profile.Misconceptions, maxHeldMisconceptions)
+}
@@ -23,6 +23,11 @@ type UserLearningProfile struct {
 	RecommendedType string                `json:"recommendedType"`
 	SuccessRate     map[string]float64    `json:"successRate,omitempty"` // Success rate per type
 	Engagement      map[string]float64    `json:"engagement,omitempty"` // Engagement metrics per type
+	ByPersona        map[string]int        `json:"byPersona,omitempty"` // {"senior-engineer": 3}
+	PreferredPersona string                `json:"preferredPersona,omitempty"`
+	Stats            map[string]*TypeStats `json:"stats,omitempty"`           // Completion, feedback and quiz signals per type
+	Recommendations  []Recommendation      `json:"recommendations,omitempty"` // Types ranked best first, with reasons
+	Misconceptions   []HeldMisconception   `json:"misconceptions,omitempty"`  // Misconceptions the user still holds, oldest first
 }
 
 // NewUserLearningProfile creates a new user learning profile
@@ -35,6 +40,8 @@ func NewUserLearningProfile(userID string) *UserLearningProfile {
 		RecommendedType: string(ExplanationTypeStandard), // Default recommendation
 		SuccessRate:     make(map[string]float64),
 		Engagement:      make(map[string]float64),
+		ByPersona:       make(map[string]int),
+		Stats:           make(map[string]*TypeStats),
 	}
 }
 
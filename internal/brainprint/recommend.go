@@ -0,0 +1,153 @@
+package brainprint
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Weights of each signal in a style's recommendation score; they sum to 1
+const (
+	completionWeight = 0.35
+	feedbackWeight   = 0.25
+	quizWeight       = 0.25
+	recencyWeight    = 0.15
+)
+
+const (
+	// recencyHalfLife is how long it takes a style's recency signal to halve
+	recencyHalfLife = 14 * 24 * time.Hour
+	// recentlyUsedWindow is how recently a style must have been used for the reason to mention it
+	recentlyUsedWindow = 7 * 24 * time.Hour
+	// priorStrength is how many neutral observations each signal starts with, so a single
+	// session or rating cannot swing a recommendation on its own
+	priorStrength = 2.0
+	// maxFeedbackRating is the top of the feedback rating scale
+	maxFeedbackRating = 5.0
+)
+
+// TypeStats accumulates the signals recorded for one explanation style
+type TypeStats struct {
+	Completed int       `json:"completed"`
+	Failed    int       `json:"failed,omitempty"`
+	RatingSum float64   `json:"ratingSum,omitempty"` // Sum of 1-5 feedback ratings
+	Ratings   int       `json:"ratings,omitempty"`
+	QuizSum   float64   `json:"quizSum,omitempty"` // Sum of quiz scores as fractions of the maximum
+	Quizzes   int       `json:"quizzes,omitempty"`
+	LastUsed  time.Time `json:"lastUsed"`
+}
+
+// completionRate returns the share of sessions in the style that completed
+func (t *TypeStats) completionRate() float64 {
+	if attempts := t.Completed + t.Failed; attempts > 0 {
+		return float64(t.Completed) / float64(attempts)
+	}
+	return 0
+}
+
+// Recommendation is a ranked explanation style with the reason it is suggested
+type Recommendation struct {
+	Type   string  `json:"type"`
+	Score  float64 `json:"score"` // 0-1, higher is better
+	Reason string  `json:"reason"`
+}
+
+// smoothed blends an average of observations in [0,1] with a neutral prior of 0.5
+func smoothed(sum float64, count int) float64 {
+	return (sum + 0.5*priorStrength) / (float64(count) + priorStrength)
+}
+
+// scoreType weighs a style's completion rate, feedback, quiz scores and recency into a 0-1 score
+func scoreType(stats *TypeStats, now time.Time) float64 {
+	completion := smoothed(float64(stats.Completed), stats.Completed+stats.Failed)
+	feedback := smoothed((stats.RatingSum-float64(stats.Ratings))/(maxFeedbackRating-1), stats.Ratings)
+	quiz := smoothed(stats.QuizSum, stats.Quizzes)
+
+	recency := 0.0
+	if !stats.LastUsed.IsZero() {
+		age := math.Max(0, now.Sub(stats.LastUsed).Hours())
+		recency = math.Exp2(-age / recencyHalfLife.Hours())
+	}
+
+	return completionWeight*completion + feedbackWeight*feedback + quizWeight*quiz + recencyWeight*recency
+}
+
+// Recommend ranks the styles a user has tried, best first, each with a human-readable reason.
+// A user with no sessions gets the standard style.
+func Recommend(profile *UserLearningProfile, now time.Time) []Recommendation {
+	if len(profile.Stats) == 0 {
+		return []Recommendation{{
+			Type:   string(ExplanationTypeStandard),
+			Score:  0,
+			Reason: "Standard explanations are suggested until you have completed a few sessions.",
+		}}
+	}
+
+	recommendations := make([]Recommendation, 0, len(profile.Stats))
+	for explanationType, stats := range profile.Stats {
+		recommendations = append(recommendations, Recommendation{
+			Type:  explanationType,
+			Score: math.Round(scoreType(stats, now)*1000) / 1000,
+		})
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+		return recommendations[i].Type < recommendations[j].Type
+	})
+
+	for i := range recommendations {
+		recommendations[i].Reason = recommendationReason(recommendations, i, profile.Stats, now)
+	}
+	return recommendations
+}
+
+// recommendationReason explains a ranked style from the signals behind its score
+func recommendationReason(ranked []Recommendation, i int, all map[string]*TypeStats, now time.Time) string {
+	explanationType := ranked[i].Type
+	stats := all[explanationType]
+
+	var evidence []string
+	attempts := stats.Completed + stats.Failed
+	if attempts > 0 {
+		evidence = append(evidence, fmt.Sprintf("you finished %d of %d %s sessions", stats.Completed, attempts, explanationType))
+	}
+	if stats.Ratings > 0 {
+		evidence = append(evidence, fmt.Sprintf("rated them %.1f/5 on average", stats.RatingSum/float64(stats.Ratings)))
+	}
+	if stats.Quizzes > 0 {
+		evidence = append(evidence, fmt.Sprintf("scored %.0f%% on their quizzes", 100*stats.QuizSum/float64(stats.Quizzes)))
+	}
+	if !stats.LastUsed.IsZero() && now.Sub(stats.LastUsed) < recentlyUsedWindow {
+		evidence = append(evidence, "used this style recently")
+	}
+
+	var reason string
+	if i == 0 {
+		reason = fmt.Sprintf("%s is suggested because %s.", explanationType, joinEvidence(evidence))
+	} else {
+		reason = fmt.Sprintf("%s ranks below %s: %s.", explanationType, ranked[0].Type, joinEvidence(evidence))
+	}
+	if i == 0 && len(ranked) > 1 {
+		runnerUp := all[ranked[1].Type]
+		if stats.completionRate() > runnerUp.completionRate() && attempts > 0 {
+			reason += fmt.Sprintf(" You complete it more often than %s.", ranked[1].Type)
+		}
+	}
+	return reason
+}
+
+// joinEvidence joins evidence phrases into one clause: "a, b and c"
+func joinEvidence(evidence []string) string {
+	switch len(evidence) {
+	case 0:
+		return "there is little evidence yet"
+	case 1:
+		return evidence[0]
+	default:
+		return strings.Join(evidence[:len(evidence)-1], ", ") + " and " + evidence[len(evidence)-1]
+	}
+}
@@ -0,0 +1,82 @@
+package brainprint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendWeighsOutcomesOverUsage(t *testing.T) {
+	service := NewService(nil)
+	ctx := context.Background()
+
+	// Visualization is used most but goes badly; Analogy is used less but goes well
+	for i := 0; i < 4; i++ {
+		require.NoError(t, service.TrackSession(ctx, "user1", "visualization", i%2 == 0))
+		require.NoError(t, service.RecordQuizScore(ctx, "user1", "visualization", 0.3))
+	}
+	require.NoError(t, service.RecordFeedback(ctx, "user1", "visualization", 2))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, service.TrackSession(ctx, "user1", "analogy", true))
+		require.NoError(t, service.RecordQuizScore(ctx, "user1", "analogy", 0.9))
+		require.NoError(t, service.RecordFeedback(ctx, "user1", "analogy", 5))
+	}
+
+	profile, err := service.GetBrainPrint(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "Analogy", profile.RecommendedType)
+	require.Len(t, profile.Recommendations, 2)
+	assert.Equal(t, "Analogy", profile.Recommendations[0].Type)
+	assert.Greater(t, profile.Recommendations[0].Score, profile.Recommendations[1].Score)
+	assert.Equal(t, "Analogy is suggested because you finished 2 of 2 Analogy sessions, rated them 5.0/5 on average, "+
+		"scored 90% on their quizzes and used this style recently. You complete it more often than Visualization.",
+		profile.Recommendations[0].Reason)
+	assert.Contains(t, profile.Recommendations[1].Reason, "Visualization ranks below Analogy")
+	assert.Equal(t, 0.5, profile.SuccessRate["Visualization"])
+}
+
+func TestRecommendPrefersRecentStyles(t *testing.T) {
+	service := NewService(nil)
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	service.now = func() time.Time { return now.Add(-60 * 24 * time.Hour) }
+	require.NoError(t, service.TrackSession(ctx, "user1", "simple", true))
+	service.now = func() time.Time { return now }
+	require.NoError(t, service.TrackSession(ctx, "user1", "standard", true))
+
+	profile, err := service.GetBrainPrint(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "Standard", profile.RecommendedType)
+	assert.NotContains(t, profile.Recommendations[1].Reason, "recently")
+}
+
+func TestRecommendWithoutSessions(t *testing.T) {
+	recommendations := Recommend(NewUserLearningProfile("user1"), time.Now())
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, "Standard", recommendations[0].Type)
+	assert.NotEmpty(t, recommendations[0].Reason)
+}
+
+func TestRecordSignalValidation(t *testing.T) {
+	service := NewService(nil)
+	ctx := context.Background()
+
+	assert.Error(t, service.RecordFeedback(ctx, "user1", "standard", 6))
+	assert.Error(t, service.RecordQuizScore(ctx, "user1", "standard", 1.5))
+}
+
+func TestTypeStatsSeedsSavedProfiles(t *testing.T) {
+	// Profiles saved before stats were tracked only have session counts
+	profile := NewUserLearningProfile("user1")
+	profile.Stats = nil
+	profile.ByType = map[string]int{"Simple": 3}
+
+	stats := typeStats(profile, "Analogy")
+	assert.Equal(t, 0, stats.Completed)
+	assert.Equal(t, 3, profile.Stats["Simple"].Completed)
+	assert.Equal(t, profile.LastUpdated, profile.Stats["Simple"].LastUsed)
+}
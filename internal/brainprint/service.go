@@ -22,6 +22,7 @@ type Service struct {
 	logger  *logrus.Logger
 	mu      sync.RWMutex
 	profiles map[string]*UserLearningProfile // In-memory cache
+	now      func() time.Time
 }
 
 // NewService creates a new BrainPrint service
@@ -30,11 +31,17 @@ func NewService(storageClient StorageInterface) *Service {
 		storage:  storageClient,
 		logger:   logrus.New(),
 		profiles: make(map[string]*UserLearningProfile),
+		now:      time.Now,
 	}
 }
 
 // TrackSession tracks a completed session for a user
 func (s *Service) TrackSession(ctx context.Context, userID string, explanationType string, success bool) error {
+	return s.TrackSessionWithPersona(ctx, userID, explanationType, "", success)
+}
+
+// TrackSessionWithPersona tracks a completed session for a user along with the persona it was written for
+func (s *Service) TrackSessionWithPersona(ctx context.Context, userID string, explanationType string, persona string, success bool) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -48,24 +55,35 @@ func (s *Service) TrackSession(ctx context.Context, userID string, explanationTy
 	}
 
 	// Update statistics
+	now := s.now()
+	stats := typeStats(profile, normalizedType)
 	profile.TotalSessions++
 	profile.ByType[normalizedType]++
-	profile.LastUpdated = time.Now()
+	profile.LastUpdated = now
 
-	// Update success rate (simple calculation: track successful sessions per type)
+	// Update the completion signal and success rate
 	if success {
-		// Initialize success count if not exists
-		if profile.SuccessRate == nil {
-			profile.SuccessRate = make(map[string]float64)
+		stats.Completed++
+	} else {
+		stats.Failed++
+	}
+	stats.LastUsed = now
+	if profile.SuccessRate == nil {
+		profile.SuccessRate = make(map[string]float64)
+	}
+	profile.SuccessRate[normalizedType] = stats.completionRate()
+
+	// Update persona usage
+	if persona != "" {
+		if profile.ByPersona == nil {
+			profile.ByPersona = make(map[string]int)
 		}
-		// Simple success rate: increment by 1 for each successful session
-		// In a real implementation, you'd track success/failure counts separately
-		currentSuccess := profile.SuccessRate[normalizedType]
-		profile.SuccessRate[normalizedType] = currentSuccess + 1.0
+		profile.ByPersona[persona]++
+		profile.PreferredPersona = preferredPersona(profile.ByPersona)
 	}
 
 	// Calculate recommended type
-	profile.RecommendedType = s.calculateRecommendedType(profile)
+	s.updateRecommendations(profile)
 
 	// Save profile
 	if err := s.saveProfile(ctx, profile); err != nil {
@@ -77,11 +95,78 @@ func (s *Service) TrackSession(ctx context.Context, userID string, explanationTy
 		"explanationType": normalizedType,
 		"totalSessions":   profile.TotalSessions,
 		"recommendedType": profile.RecommendedType,
+		"persona":         persona,
 	}).Info("Session tracked for BrainPrint")
 
 	return nil
 }
 
+// RecordFeedback records a user's 1-5 rating of a lesson in the given explanation style
+func (s *Service) RecordFeedback(ctx context.Context, userID string, explanationType string, rating float64) error {
+	if rating < 1 || rating > maxFeedbackRating {
+		return fmt.Errorf("rating must be between 1 and %g", maxFeedbackRating)
+	}
+	return s.recordSignal(ctx, userID, explanationType, func(stats *TypeStats) {
+		stats.RatingSum += rating
+		stats.Ratings++
+	})
+}
+
+// RecordQuizScore records a quiz result, as a fraction of the maximum score, for a lesson in the given style
+func (s *Service) RecordQuizScore(ctx context.Context, userID string, explanationType string, score float64) error {
+	if score < 0 || score > 1 {
+		return fmt.Errorf("quiz score must be between 0 and 1")
+	}
+	return s.recordSignal(ctx, userID, explanationType, func(stats *TypeStats) {
+		stats.QuizSum += score
+		stats.Quizzes++
+	})
+}
+
+// recordSignal applies one feedback or quiz signal to a user's stats and refreshes their recommendations
+func (s *Service) recordSignal(ctx context.Context, userID string, explanationType string, apply func(stats *TypeStats)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	normalizedType := NormalizeExplanationType(explanationType)
+	profile, err := s.getProfile(ctx, userID)
+	if err != nil {
+		profile = NewUserLearningProfile(userID)
+	}
+
+	apply(typeStats(profile, normalizedType))
+	profile.LastUpdated = s.now()
+	s.updateRecommendations(profile)
+
+	if err := s.saveProfile(ctx, profile); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	return nil
+}
+
+// typeStats returns a profile's stats for a type, seeding stats for profiles saved before
+// stats were tracked from their session counts
+func typeStats(profile *UserLearningProfile, explanationType string) *TypeStats {
+	if profile.Stats == nil {
+		profile.Stats = make(map[string]*TypeStats)
+		for seededType, count := range profile.ByType {
+			profile.Stats[seededType] = &TypeStats{Completed: count, LastUsed: profile.LastUpdated}
+		}
+	}
+	stats, exists := profile.Stats[explanationType]
+	if !exists {
+		stats = &TypeStats{}
+		profile.Stats[explanationType] = stats
+	}
+	return stats
+}
+
+// updateRecommendations re-ranks a profile's explanation types from its current stats
+func (s *Service) updateRecommendations(profile *UserLearningProfile) {
+	profile.Recommendations = Recommend(profile, s.now())
+	profile.RecommendedType = profile.Recommendations[0].Type
+}
+
 // GetBrainPrint retrieves the BrainPrint for a user
 func (s *Service) GetBrainPrint(ctx context.Context, userID string) (*UserLearningProfile, error) {
 	s.mu.RLock()
@@ -143,70 +228,50 @@ func (s *Service) saveProfile(ctx context.Context, profile *UserLearningProfile)
 	return nil
 }
 
-// calculateRecommendedType determines the recommended explanation type based on usage and success rate
-func (s *Service) calculateRecommendedType(profile *UserLearningProfile) string {
-	if profile.TotalSessions == 0 {
-		return string(ExplanationTypeStandard)
-	}
-
-	// Calculate weighted score: usage count + success rate
-	type Score struct {
-		Type  string
-		Score float64
-	}
-
-	scores := make([]Score, 0, len(profile.ByType))
+// keyDeleter is implemented by profile storage that can remove keys
+type keyDeleter interface {
+	Delete(ctx context.Context, key string) error
+}
 
-	for explanationType, count := range profile.ByType {
-		// Base score from usage count
-		score := float64(count)
+// DeleteProfile erases a user's profile from the cache and storage, reporting whether one existed
+func (s *Service) DeleteProfile(ctx context.Context, userID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		// Add success rate bonus (if available)
-		if profile.SuccessRate != nil {
-			if successRate, exists := profile.SuccessRate[explanationType]; exists {
-				// Weight success rate: higher success rate = higher score
-				score += successRate * 0.5
-			}
-		}
+	_, existed := s.profiles[userID]
+	delete(s.profiles, userID)
 
-		scores = append(scores, Score{
-			Type:  explanationType,
-			Score: score,
-		})
+	if s.storage == nil {
+		return existed, nil
 	}
 
-	// Find the type with highest score
-	if len(scores) == 0 {
-		return string(ExplanationTypeStandard)
+	key := fmt.Sprintf("brainprint:%s", userID)
+	if data, err := s.storage.Get(ctx, key); err == nil && len(data) > 0 {
+		existed = true
 	}
-
-	maxScore := scores[0]
-	for _, score := range scores[1:] {
-		if score.Score > maxScore.Score {
-			maxScore = score
-		}
+	deleter, ok := s.storage.(keyDeleter)
+	if !ok {
+		return existed, fmt.Errorf("profile storage does not support deletion")
+	}
+	if err := deleter.Delete(ctx, key); err != nil {
+		return existed, fmt.Errorf("failed to delete profile from storage: %w", err)
 	}
 
-	// If user has tried multiple types, suggest the least used one to encourage exploration
-	// But only if they have enough sessions (at least 5)
-	if profile.TotalSessions >= 5 {
-		// Find least used type
-		minCount := profile.TotalSessions
-		leastUsedType := ""
-		for explanationType, count := range profile.ByType {
-			if count < minCount {
-				minCount = count
-				leastUsedType = explanationType
-			}
-		}
+	s.logger.WithField("userID", userID).Info("BrainPrint profile deleted")
+	return existed, nil
+}
 
-		// If least used type exists and is significantly less used, suggest it
-		if leastUsedType != "" && float64(minCount) < maxScore.Score*0.5 {
-			return leastUsedType
+// preferredPersona returns the most used persona, breaking ties alphabetically
+func preferredPersona(byPersona map[string]int) string {
+	preferred := ""
+	maxCount := 0
+	for persona, count := range byPersona {
+		if count > maxCount || (count == maxCount && persona < preferred) {
+			preferred = persona
+			maxCount = count
 		}
 	}
-
-	return maxScore.Type
+	return preferred
 }
 
 // GetStats returns statistics about BrainPrint usage
@@ -218,6 +283,7 @@ func (s *Service) GetStats(ctx context.Context) (map[string]interface{}, error)
 		"total_profiles": len(s.profiles),
 		"total_sessions": 0,
 		"by_type":        make(map[string]int),
+		"by_persona":     make(map[string]int),
 	}
 
 	for _, profile := range s.profiles {
@@ -226,6 +292,10 @@ func (s *Service) GetStats(ctx context.Context) (map[string]interface{}, error)
 			byType := stats["by_type"].(map[string]int)
 			byType[explanationType] += count
 		}
+		for persona, count := range profile.ByPersona {
+			byPersona := stats["by_persona"].(map[string]int)
+			byPersona[persona] += count
+		}
 	}
 
 	return stats, nil
@@ -41,6 +41,28 @@ func TestTrackSession(t *testing.T) {
 	assert.Equal(t, 1, profile.ByType["Standard"])
 }
 
+func TestTrackSessionWithPersona(t *testing.T) {
+	service := NewService(nil)
+	ctx := context.Background()
+
+	require.NoError(t, service.TrackSessionWithPersona(ctx, "user123", "standard", "senior-engineer", true))
+	require.NoError(t, service.TrackSessionWithPersona(ctx, "user123", "simple", "product-manager", true))
+	require.NoError(t, service.TrackSessionWithPersona(ctx, "user123", "standard", "senior-engineer", true))
+	require.NoError(t, service.TrackSession(ctx, "user123", "standard", true))
+
+	profile, err := service.GetBrainPrint(ctx, "user123")
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, profile.TotalSessions)
+	assert.Equal(t, 2, profile.ByPersona["senior-engineer"])
+	assert.Equal(t, 1, profile.ByPersona["product-manager"])
+	assert.Equal(t, "senior-engineer", profile.PreferredPersona)
+
+	stats, err := service.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats["by_persona"].(map[string]int)["senior-engineer"])
+}
+
 func TestCalculateRecommendedType(t *testing.T) {
 	service := NewService(nil)
 	ctx := context.Background()
@@ -107,3 +129,23 @@ func TestGetBrainPrintEmptyProfile(t *testing.T) {
 	assert.Equal(t, "Standard", profile.RecommendedType)
 }
 
+
+func TestDeleteProfile(t *testing.T) {
+	service := NewService(nil)
+	ctx := context.Background()
+
+	require.NoError(t, service.TrackSession(ctx, "user123", "visualization", true))
+
+	existed, err := service.DeleteProfile(ctx, "user123")
+	require.NoError(t, err)
+	assert.True(t, existed)
+
+	// The user starts over with an empty profile
+	profile, err := service.GetBrainPrint(ctx, "user123")
+	require.NoError(t, err)
+	assert.Equal(t, 0, profile.TotalSessions)
+
+	existed, err = service.DeleteProfile(ctx, "nobody")
+	require.NoError(t, err)
+	assert.False(t, existed)
+}
@@ -0,0 +1,31 @@
+package cache
+
+import "testing"
+
+// TestLRUEviction tests that the least recently used entry is evicted
+func TestLRUEviction(t *testing.T) {
+	c := NewLRU(2)
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	// Touch "a" so that "b" becomes the oldest entry
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("Expected a to be cached")
+	}
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+	if v, ok := c.Get("c"); !ok || v.(int) != 3 {
+		t.Errorf("Expected c=3, got %v", v)
+	}
+
+	stats := c.Stats()
+	if stats.Evictions != 1 {
+		t.Errorf("Expected 1 eviction, got %d", stats.Evictions)
+	}
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("Expected 2 hits and 1 miss, got %d/%d", stats.Hits, stats.Misses)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,6 +18,7 @@ type Config struct {
 	ElasticURL       string
 	ElasticAPIKey    string
 	GeminiAPIKey     string
+	GeminiBackend    string // "aistudio" (API key) or "vertex" (service account)
 	ShutdownTimeout  time.Duration
 	ReadTimeout      time.Duration
 	WriteTimeout     time.Duration
@@ -33,6 +35,7 @@ func Load() *Config {
 		ElasticURL:      getEnv("ELASTIC_URL", "http://elasticsearch:9200"),
 		ElasticAPIKey:   getEnv("ELASTIC_API_KEY", ""),
 		GeminiAPIKey:    getEnv("GEMINI_API_KEY", ""),
+		GeminiBackend:   getEnv("GEMINI_BACKEND", "aistudio"),
 		ShutdownTimeout: getDurationEnv("SHUTDOWN_TIMEOUT", 30*time.Second),
 		ReadTimeout:     getDurationEnv("READ_TIMEOUT", 15*time.Second),
 		WriteTimeout:    getDurationEnv("WRITE_TIMEOUT", 5*time.Minute), // Increased for long-running tasks like critic
@@ -86,17 +89,23 @@ func getIntEnv(key string, defaultValue int) int {
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	if c.GeminiAPIKey == "" {
+	if c.GeminiAPIKey == "" && !c.usesVertex() {
 		return fmt.Errorf("GEMINI_API_KEY is required")
 	}
 	return nil
 }
 
+// usesVertex reports whether Gemini is reached through Vertex AI, which authenticates with
+// application default credentials instead of an API key
+func (c *Config) usesVertex() bool {
+	return strings.EqualFold(c.GeminiBackend, "vertex")
+}
+
 // ValidateOptional validates optional configuration (doesn't fail on missing keys)
 func (c *Config) ValidateOptional() []string {
 	var warnings []string
 	
-	if c.GeminiAPIKey == "" {
+	if c.GeminiAPIKey == "" && !c.usesVertex() {
 		warnings = append(warnings, "GEMINI_API_KEY not set - AI features may not work")
 	}
 	
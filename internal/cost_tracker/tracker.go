@@ -76,7 +76,7 @@ func NewCostTracker(storage storage.Storage) *CostTracker {
 // TrackLLMCall tracks an LLM call cost
 func (ct *CostTracker) TrackLLMCall(ctx context.Context, sessionID, userID, ipAddress, model string, inputTokens, outputTokens int) error {
 	// Estimate cost based on model and tokens
-	cost := ct.estimateLLMCost(model, inputTokens, outputTokens)
+	cost := EstimateLLMCost(model, inputTokens, outputTokens)
 
 	entry := CostEntry{
 		SessionID:     sessionID,
@@ -100,7 +100,7 @@ func (ct *CostTracker) TrackLLMCall(ctx context.Context, sessionID, userID, ipAd
 // TrackImageCall tracks an image generation call cost
 func (ct *CostTracker) TrackImageCall(ctx context.Context, sessionID, userID, ipAddress string, imageCount int) error {
 	// Estimate cost based on image count
-	cost := ct.estimateImageCost(imageCount)
+	cost := EstimateImageCost(imageCount)
 
 	entry := CostEntry{
 		SessionID:     sessionID,
@@ -145,6 +145,36 @@ func (ct *CostTracker) GetSessionCosts(ctx context.Context, sessionID string) (*
 	return &costs, nil
 }
 
+// AnonymizeSessionCosts removes the user ID and IP address from a session's cost totals while
+// keeping the amounts for billing aggregates. It reports whether a record was changed.
+func (ct *CostTracker) AnonymizeSessionCosts(ctx context.Context, sessionID string) (bool, error) {
+	key := fmt.Sprintf("session_costs:%s", sessionID)
+
+	data, err := ct.storage.Get(ctx, key)
+	if err != nil || len(data) == 0 {
+		return false, nil
+	}
+
+	var costs SessionCosts
+	if err := json.Unmarshal(data, &costs); err != nil {
+		return false, fmt.Errorf("failed to unmarshal session costs: %w", err)
+	}
+	if costs.UserID == "" && costs.IPAddress == "" {
+		return false, nil
+	}
+	costs.UserID = ""
+	costs.IPAddress = ""
+
+	costsData, err := json.Marshal(costs)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal session costs: %w", err)
+	}
+	if err := ct.storage.Set(ctx, key, costsData); err != nil {
+		return false, fmt.Errorf("failed to store anonymized session costs: %w", err)
+	}
+	return true, nil
+}
+
 // CheckCostLimits checks if the session has exceeded cost limits
 func (ct *CostTracker) CheckCostLimits(ctx context.Context, sessionID string, limits CostLimits) (*SessionCosts, bool, error) {
 	costs, err := ct.GetSessionCosts(ctx, sessionID)
@@ -220,8 +250,8 @@ func (ct *CostTracker) updateSessionCosts(ctx context.Context, entry CostEntry)
 	return ct.storage.Set(ctx, key, costsData)
 }
 
-// estimateLLMCost estimates the cost of an LLM call
-func (ct *CostTracker) estimateLLMCost(model string, inputTokens, outputTokens int) float64 {
+// EstimateLLMCost estimates the cost of an LLM call
+func EstimateLLMCost(model string, inputTokens, outputTokens int) float64 {
 	// Simplified cost estimation (in USD)
 	// In production, you'd use actual pricing from the LLM provider
 
@@ -234,6 +264,18 @@ func (ct *CostTracker) estimateLLMCost(model string, inputTokens, outputTokens i
 	case "gemini-pro-vision":
 		inputCostPer1K = 0.0005
 		outputCostPer1K = 0.0015
+	case "gemini-2.5-flash":
+		inputCostPer1K = 0.0003  // $0.30 per 1M tokens
+		outputCostPer1K = 0.0025 // $2.50 per 1M tokens
+	case "gemini-2.5-pro":
+		inputCostPer1K = 0.00125 // $1.25 per 1M tokens
+		outputCostPer1K = 0.01   // $10 per 1M tokens
+	case "gemini-1.5-flash":
+		inputCostPer1K = 0.000075 // $0.075 per 1M tokens
+		outputCostPer1K = 0.0003  // $0.30 per 1M tokens
+	case "gemini-1.5-pro":
+		inputCostPer1K = 0.00125 // $1.25 per 1M tokens
+		outputCostPer1K = 0.005  // $5 per 1M tokens
 	default:
 		inputCostPer1K = 0.001 // Default pricing
 		outputCostPer1K = 0.002
@@ -245,8 +287,8 @@ func (ct *CostTracker) estimateLLMCost(model string, inputTokens, outputTokens i
 	return inputCost + outputCost
 }
 
-// estimateImageCost estimates the cost of image generation
-func (ct *CostTracker) estimateImageCost(imageCount int) float64 {
+// EstimateImageCost estimates the cost of image generation
+func EstimateImageCost(imageCount int) float64 {
 	// Simplified cost estimation for Imagen (in USD)
 	// In production, you'd use actual pricing from the image generation service
 
@@ -12,6 +12,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// DefaultDimensions is the embedding size of text-embedding-004, which the pipeline indexes with
+const DefaultDimensions = 768
+
 // Client represents an Elasticsearch client with hybrid search capabilities
 type Client struct {
 	es     *elasticsearch.Client
@@ -155,8 +158,59 @@ func (c *Client) UpsertDocs(ctx context.Context, index string, docs []Doc) error
 	return nil
 }
 
+// DeleteDocs deletes documents by ID using the bulk API
+func (c *Client) DeleteDocs(ctx context.Context, index string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, id := range ids {
+		action := map[string]interface{}{
+			"delete": map[string]interface{}{
+				"_index": index,
+				"_id":    id,
+			},
+		}
+		actionJSON, err := json.Marshal(action)
+		if err != nil {
+			return fmt.Errorf("failed to marshal action: %w", err)
+		}
+		buf.Write(actionJSON)
+		buf.WriteString("\n")
+	}
+
+	req := esapi.BulkRequest{
+		Body:    &buf,
+		Refresh: "true",
+	}
+
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return fmt.Errorf("failed to execute bulk delete: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("bulk delete failed: %s", string(body))
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"index": index,
+		"count": len(ids),
+	}).Info("Documents deleted successfully")
+	return nil
+}
+
 // getHybridSearchMapping returns the mapping for hybrid search (BM25 + dense vectors)
 func (c *Client) getHybridSearchMapping() map[string]interface{} {
+	return HybridSearchMapping(DefaultDimensions)
+}
+
+// HybridSearchMapping returns the index settings and mappings for hybrid search with
+// embeddings of the given size
+func HybridSearchMapping(dimensions int) map[string]interface{} {
 	return map[string]interface{}{
 		"settings": map[string]interface{}{
 			"number_of_shards":   1,
@@ -172,6 +226,17 @@ func (c *Client) getHybridSearchMapping() map[string]interface{} {
 			},
 		},
 		"mappings": map[string]interface{}{
+			// Metadata values are exact-match labels
+			"dynamic_templates": []interface{}{
+				map[string]interface{}{
+					"metadata_keywords": map[string]interface{}{
+						"path_match": "metadata.*",
+						"mapping": map[string]interface{}{
+							"type": "keyword",
+						},
+					},
+				},
+			},
 			"properties": map[string]interface{}{
 				"id": map[string]interface{}{
 					"type": "keyword",
@@ -205,17 +270,12 @@ func (c *Client) getHybridSearchMapping() map[string]interface{} {
 				},
 				"embedding": map[string]interface{}{
 					"type":       "dense_vector",
-					"dims":       1536, // OpenAI ada-002 dimensions
+					"dims":       dimensions,
 					"index":      true,
 					"similarity": "cosine",
 				},
 				"metadata": map[string]interface{}{
 					"type": "object",
-					"properties": map[string]interface{}{
-						"*": map[string]interface{}{
-							"type": "keyword",
-						},
-					},
 				},
 				"created_at": map[string]interface{}{
 					"type": "date",
@@ -443,3 +503,52 @@ func (c *Client) IndexExists(ctx context.Context, name string) (bool, error) {
 
 	return res.StatusCode == 200, nil
 }
+
+// IndexDimensions returns the dims of the embedding field in an index's mapping, or 0 if
+// the index does not exist or has no embedding field. An alias resolves to its indices,
+// which must all agree.
+func (c *Client) IndexDimensions(ctx context.Context, name string) (int, error) {
+	req := esapi.IndicesGetMappingRequest{
+		Index: []string{name},
+	}
+
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get index mapping: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return 0, nil
+	}
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return 0, fmt.Errorf("get mapping failed: %s", string(body))
+	}
+
+	var mappings map[string]struct {
+		Mappings struct {
+			Properties struct {
+				Embedding struct {
+					Dims int `json:"dims"`
+				} `json:"embedding"`
+			} `json:"properties"`
+		} `json:"mappings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&mappings); err != nil {
+		return 0, fmt.Errorf("failed to parse index mapping: %w", err)
+	}
+
+	dimensions := 0
+	for index, mapping := range mappings {
+		dims := mapping.Mappings.Properties.Embedding.Dims
+		if dims == 0 {
+			continue
+		}
+		if dimensions != 0 && dims != dimensions {
+			return 0, fmt.Errorf("indices behind %s disagree on embedding dims: %s has %d, another has %d", name, index, dims, dimensions)
+		}
+		dimensions = dims
+	}
+	return dimensions, nil
+}
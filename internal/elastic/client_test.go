@@ -107,8 +107,8 @@ func TestHybridSearchMapping(t *testing.T) {
 		t.Errorf("Expected embedding type to be 'dense_vector', got '%v'", embedding["type"])
 	}
 
-	if embedding["dims"] != 1536 {
-		t.Errorf("Expected embedding dims to be 1536, got %v", embedding["dims"])
+	if embedding["dims"] != DefaultDimensions {
+		t.Errorf("Expected embedding dims to be %d, got %v", DefaultDimensions, embedding["dims"])
 	}
 
 	// Check text field
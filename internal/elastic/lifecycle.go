@@ -0,0 +1,427 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultRolloverSize is the primary shard size at which the write index rolls over
+	DefaultRolloverSize = "50gb"
+	// generationDigits is the width of the generation suffix of backing indices, as in lessons-000001
+	generationDigits = 6
+)
+
+// IndexSpec describes an alias-fronted index managed by an ILM policy. Clients read and
+// write through Alias; documents live in backing indices named Alias-000001, Alias-000002
+// and so on, created from an index template that carries the current mapping.
+type IndexSpec struct {
+	Alias          string // Name clients use, e.g. "lessons"
+	Dimensions     int    // dense_vector size of the embedding field
+	RolloverSize   string // Roll over when a primary shard reaches this size
+	RolloverMaxAge string // Also roll over after this age (e.g. "30d"); empty for size only
+}
+
+// NewIndexSpec returns the spec for an alias with the default rollover policy
+func NewIndexSpec(alias string, dimensions int) IndexSpec {
+	if dimensions <= 0 {
+		dimensions = DefaultDimensions
+	}
+	return IndexSpec{
+		Alias:        alias,
+		Dimensions:   dimensions,
+		RolloverSize: DefaultRolloverSize,
+	}
+}
+
+// PolicyName returns the name of the alias's ILM policy
+func (s IndexSpec) PolicyName() string {
+	return s.Alias + "-policy"
+}
+
+// TemplateName returns the name of the alias's index template
+func (s IndexSpec) TemplateName() string {
+	return s.Alias + "-template"
+}
+
+// BackingIndex returns the name of the backing index of a generation
+func (s IndexSpec) BackingIndex(generation int) string {
+	return fmt.Sprintf("%s-%0*d", s.Alias, generationDigits, generation)
+}
+
+// generation parses the generation of one of the alias's backing indices, or returns 0
+func (s IndexSpec) generation(index string) int {
+	suffix, ok := strings.CutPrefix(index, s.Alias+"-")
+	if !ok {
+		return 0
+	}
+	generation, err := strconv.Atoi(suffix)
+	if err != nil {
+		return 0
+	}
+	return generation
+}
+
+// lifecyclePolicy returns the ILM policy body. Lesson documents are reference material,
+// so the policy only rolls the write index over and never deletes.
+func (s IndexSpec) lifecyclePolicy() map[string]interface{} {
+	rollover := map[string]interface{}{
+		"max_primary_shard_size": s.RolloverSize,
+	}
+	if s.RolloverMaxAge != "" {
+		rollover["max_age"] = s.RolloverMaxAge
+	}
+	return map[string]interface{}{
+		"policy": map[string]interface{}{
+			"phases": map[string]interface{}{
+				"hot": map[string]interface{}{
+					"actions": map[string]interface{}{
+						"rollover": rollover,
+					},
+				},
+			},
+		},
+	}
+}
+
+// indexTemplate returns the composable index template body applied to new backing indices
+func (s IndexSpec) indexTemplate() map[string]interface{} {
+	mapping := HybridSearchMapping(s.Dimensions)
+	settings := mapping["settings"].(map[string]interface{})
+	settings["index.lifecycle.name"] = s.PolicyName()
+	settings["index.lifecycle.rollover_alias"] = s.Alias
+
+	// Longer aliases take precedence, so a per-tenant alias such as lessons-acme keeps its own
+	// template although lessons-* also matches its backing indices
+	return map[string]interface{}{
+		"index_patterns": []string{s.Alias + "-*"},
+		"priority":       200 + len(s.Alias),
+		"template": map[string]interface{}{
+			"settings": settings,
+			"mappings": mapping["mappings"],
+		},
+	}
+}
+
+// IndexState describes what currently backs an alias
+type IndexState struct {
+	Alias       string   `json:"alias"`
+	Indices     []string `json:"indices"`                // Backing indices, oldest first
+	WriteIndex  string   `json:"write_index,omitempty"`  // Backing index that receives writes
+	LegacyIndex bool     `json:"legacy_index,omitempty"` // Alias is a plain index created before lifecycle management
+}
+
+// Exists reports whether the alias resolves to any index
+func (s IndexState) Exists() bool {
+	return s.LegacyIndex || len(s.Indices) > 0
+}
+
+// perform executes a request, returning the response body or an error for failed requests
+func (c *Client) perform(ctx context.Context, req esapi.Request, action string) ([]byte, error) {
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", action, err)
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("%s failed: %s", action, string(body))
+	}
+	return body, nil
+}
+
+// marshalBody encodes a request body
+func marshalBody(body interface{}) (io.Reader, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	return bytes.NewReader(data), nil
+}
+
+// IndexState looks up the indices behind an alias
+func (c *Client) IndexState(ctx context.Context, alias string) (IndexState, error) {
+	state := IndexState{Alias: alias, Indices: []string{}}
+
+	res, err := esapi.IndicesGetAliasRequest{Name: []string{alias}}.Do(ctx, c.es)
+	if err != nil {
+		return state, fmt.Errorf("failed to get alias: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		// No alias; a plain index may still carry the name
+		exists, err := c.IndexExists(ctx, alias)
+		if err != nil {
+			return state, err
+		}
+		state.LegacyIndex = exists
+		if exists {
+			state.Indices = []string{alias}
+			state.WriteIndex = alias
+		}
+		return state, nil
+	}
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return state, fmt.Errorf("get alias failed: %s", string(body))
+	}
+
+	var indices map[string]struct {
+		Aliases map[string]struct {
+			IsWriteIndex *bool `json:"is_write_index"`
+		} `json:"aliases"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return state, fmt.Errorf("failed to decode alias response: %w", err)
+	}
+	for index, entry := range indices {
+		state.Indices = append(state.Indices, index)
+		if write := entry.Aliases[alias].IsWriteIndex; write != nil && *write {
+			state.WriteIndex = index
+		}
+	}
+	sort.Strings(state.Indices)
+	if state.WriteIndex == "" && len(state.Indices) == 1 {
+		state.WriteIndex = state.Indices[0]
+	}
+	return state, nil
+}
+
+// PutLifecycle creates or updates the alias's ILM policy and index template. Updating the
+// template does not change existing backing indices; their mapping changes at the next
+// rollover or reindex.
+func (c *Client) PutLifecycle(ctx context.Context, spec IndexSpec) error {
+	policy, err := marshalBody(spec.lifecyclePolicy())
+	if err != nil {
+		return err
+	}
+	if _, err := c.perform(ctx, esapi.ILMPutLifecycleRequest{Policy: spec.PolicyName(), Body: policy}, "put lifecycle policy"); err != nil {
+		return err
+	}
+
+	template, err := marshalBody(spec.indexTemplate())
+	if err != nil {
+		return err
+	}
+	if _, err := c.perform(ctx, esapi.IndicesPutIndexTemplateRequest{Name: spec.TemplateName(), Body: template}, "put index template"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// EnsureIndex bootstraps the alias: it puts the lifecycle policy and template and, when
+// nothing answers to the alias yet, creates the first backing index as its write index.
+// A legacy plain index with the alias's name is left in place; migrate it with Reindex.
+func (c *Client) EnsureIndex(ctx context.Context, spec IndexSpec) error {
+	if err := c.PutLifecycle(ctx, spec); err != nil {
+		return err
+	}
+
+	state, err := c.IndexState(ctx, spec.Alias)
+	if err != nil {
+		return err
+	}
+	if state.LegacyIndex {
+		c.logger.WithField("index", spec.Alias).Warn("Index predates lifecycle management; reindex it to enable rollover")
+		return nil
+	}
+	if state.Exists() {
+		return nil
+	}
+
+	index := spec.BackingIndex(1)
+	body, err := marshalBody(map[string]interface{}{
+		"aliases": map[string]interface{}{
+			spec.Alias: map[string]interface{}{"is_write_index": true},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := c.perform(ctx, esapi.IndicesCreateRequest{Index: index, Body: body}, "create index"); err != nil {
+		return err
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"alias": spec.Alias,
+		"index": index,
+		"dims":  spec.Dimensions,
+	}).Info("Bootstrapped index")
+	return nil
+}
+
+// Rollover moves the alias's writes to a new backing index now, without waiting for ILM
+func (c *Client) Rollover(ctx context.Context, alias string) (string, error) {
+	body, err := c.perform(ctx, esapi.IndicesRolloverRequest{Alias: alias}, "roll over index")
+	if err != nil {
+		return "", err
+	}
+	var response struct {
+		NewIndex string `json:"new_index"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to decode rollover response: %w", err)
+	}
+	return response.NewIndex, nil
+}
+
+// ReindexResult reports a completed reindex
+type ReindexResult struct {
+	NewIndex   string   `json:"new_index"`
+	OldIndices []string `json:"old_indices"`
+	Documents  int      `json:"documents"`
+	Deleted    bool     `json:"deleted"` // Old indices were deleted
+}
+
+// Reindex copies every document behind the alias into a new backing index created from the
+// current template, then atomically points the alias at it. Stored embeddings are copied as
+// they are, so changing Dimensions requires re-ingesting the corpus instead.
+// A legacy plain index can only be migrated with deleteOld, since the alias takes its name.
+func (c *Client) Reindex(ctx context.Context, spec IndexSpec, deleteOld bool) (*ReindexResult, error) {
+	state, err := c.IndexState(ctx, spec.Alias)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Exists() {
+		return nil, fmt.Errorf("index %s does not exist", spec.Alias)
+	}
+	if state.LegacyIndex && !deleteOld {
+		return nil, fmt.Errorf("index %s is a plain index; migrating it replaces the index with an alias and requires deleting it", spec.Alias)
+	}
+
+	if err := c.PutLifecycle(ctx, spec); err != nil {
+		return nil, err
+	}
+
+	generation := 1
+	for _, index := range state.Indices {
+		if g := spec.generation(index); g >= generation {
+			generation = g + 1
+		}
+	}
+	newIndex := spec.BackingIndex(generation)
+	if _, err := c.perform(ctx, esapi.IndicesCreateRequest{Index: newIndex}, "create index"); err != nil {
+		return nil, err
+	}
+
+	body, err := marshalBody(map[string]interface{}{
+		"source": map[string]interface{}{"index": spec.Alias},
+		"dest":   map[string]interface{}{"index": newIndex},
+	})
+	if err != nil {
+		return nil, err
+	}
+	wait, refresh := true, true
+	response, err := c.perform(ctx, esapi.ReindexRequest{Body: body, WaitForCompletion: &wait, Refresh: &refresh}, "reindex")
+	if err != nil {
+		return nil, err
+	}
+	var reindexed struct {
+		Total    int           `json:"total"`
+		Failures []interface{} `json:"failures"`
+	}
+	if err := json.Unmarshal(response, &reindexed); err != nil {
+		return nil, fmt.Errorf("failed to decode reindex response: %w", err)
+	}
+	if len(reindexed.Failures) > 0 {
+		return nil, fmt.Errorf("reindex into %s had %d failures; alias left unchanged", newIndex, len(reindexed.Failures))
+	}
+
+	// Swap the alias in one step so readers never see an empty index
+	actions := []interface{}{
+		map[string]interface{}{"add": map[string]interface{}{"index": newIndex, "alias": spec.Alias, "is_write_index": true}},
+	}
+	for _, index := range state.Indices {
+		if state.LegacyIndex {
+			actions = append(actions, map[string]interface{}{"remove_index": map[string]interface{}{"index": index}})
+		} else {
+			actions = append(actions, map[string]interface{}{"remove": map[string]interface{}{"index": index, "alias": spec.Alias}})
+		}
+	}
+	aliases, err := marshalBody(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.perform(ctx, esapi.IndicesUpdateAliasesRequest{Body: aliases}, "update aliases"); err != nil {
+		return nil, err
+	}
+
+	result := &ReindexResult{
+		NewIndex:   newIndex,
+		OldIndices: state.Indices,
+		Documents:  reindexed.Total,
+		Deleted:    state.LegacyIndex,
+	}
+	if deleteOld && !state.LegacyIndex {
+		if _, err := c.perform(ctx, esapi.IndicesDeleteRequest{Index: state.Indices}, "delete old indices"); err != nil {
+			return result, err
+		}
+		result.Deleted = true
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"alias":       spec.Alias,
+		"new_index":   newIndex,
+		"old_indices": state.Indices,
+		"documents":   reindexed.Total,
+	}).Info("Reindex completed")
+	return result, nil
+}
+
+// EnsureFilteredAlias points alias at every backing index of target, showing only documents
+// whose field equals value, or documents without the field when value is empty. Adding an
+// existing alias updates its filter. Backing indices created by a later rollover or reindex
+// are not covered until the alias is ensured again.
+func (c *Client) EnsureFilteredAlias(ctx context.Context, target, alias, field, value string) error {
+	state, err := c.IndexState(ctx, target)
+	if err != nil {
+		return err
+	}
+	if !state.Exists() {
+		return fmt.Errorf("index %s does not exist", target)
+	}
+
+	filter := map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must_not": map[string]interface{}{"exists": map[string]interface{}{"field": field}},
+		},
+	}
+	if value != "" {
+		filter = map[string]interface{}{"term": map[string]interface{}{field: value}}
+	}
+	actions := make([]interface{}, 0, len(state.Indices))
+	for _, index := range state.Indices {
+		actions = append(actions, map[string]interface{}{
+			"add": map[string]interface{}{"index": index, "alias": alias, "filter": filter},
+		})
+	}
+	body, err := marshalBody(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return err
+	}
+	if _, err := c.perform(ctx, esapi.IndicesUpdateAliasesRequest{Body: body}, "update aliases"); err != nil {
+		return err
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"alias":   alias,
+		"target":  target,
+		"indices": state.Indices,
+		"field":   field,
+	}).Info("Ensured filtered alias")
+	return nil
+}
@@ -0,0 +1,210 @@
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCluster records requests and answers the index lifecycle APIs
+type fakeCluster struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   map[string]string
+	aliases  map[string][]string // alias -> backing indices
+	indices  map[string]bool
+}
+
+func newFakeCluster(t *testing.T) (*fakeCluster, *Client) {
+	cluster := &fakeCluster{
+		bodies:  make(map[string]string),
+		aliases: make(map[string][]string),
+		indices: make(map[string]bool),
+	}
+	server := httptest.NewServer(cluster)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(context.Background(), server.URL, "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return cluster, client
+}
+
+func (f *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+	body, _ := io.ReadAll(r.Body)
+	key := r.Method + " " + r.URL.Path
+	f.requests = append(f.requests, key)
+	f.bodies[key] = string(body)
+
+	switch {
+	case r.URL.Path == "/_cluster/health":
+		io.WriteString(w, `{"status":"green"}`)
+	case strings.HasPrefix(r.URL.Path, "/_alias/"):
+		alias := strings.TrimPrefix(r.URL.Path, "/_alias/")
+		indices, ok := f.aliases[alias]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{}`)
+			return
+		}
+		response := map[string]interface{}{}
+		for i, index := range indices {
+			response[index] = map[string]interface{}{
+				"aliases": map[string]interface{}{alias: map[string]interface{}{"is_write_index": i == len(indices)-1}},
+			}
+		}
+		json.NewEncoder(w).Encode(response)
+	case r.Method == http.MethodHead:
+		if !f.indices[strings.TrimPrefix(r.URL.Path, "/")] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.URL.Path == "/_reindex":
+		io.WriteString(w, `{"total":42,"failures":[]}`)
+	case r.URL.Path == "/_aliases":
+		var update struct {
+			Actions []map[string]map[string]interface{} `json:"actions"`
+		}
+		json.Unmarshal(body, &update)
+		for _, action := range update.Actions {
+			if add, ok := action["add"]; ok {
+				alias := add["alias"].(string)
+				f.aliases[alias] = []string{add["index"].(string)}
+			}
+			if remove, ok := action["remove_index"]; ok {
+				delete(f.indices, remove["index"].(string))
+			}
+		}
+		io.WriteString(w, `{"acknowledged":true}`)
+	case r.Method == http.MethodPut && !strings.HasPrefix(r.URL.Path, "/_"):
+		index := strings.TrimPrefix(r.URL.Path, "/")
+		f.indices[index] = true
+		var create struct {
+			Aliases map[string]interface{} `json:"aliases"`
+		}
+		json.Unmarshal(body, &create)
+		for alias := range create.Aliases {
+			f.aliases[alias] = append(f.aliases[alias], index)
+		}
+		io.WriteString(w, `{"acknowledged":true}`)
+	default:
+		io.WriteString(w, `{"acknowledged":true}`)
+	}
+}
+
+func TestEnsureIndexBootstrap(t *testing.T) {
+	cluster, client := newFakeCluster(t)
+	spec := NewIndexSpec("lessons", 0)
+
+	if err := client.EnsureIndex(context.Background(), spec); err != nil {
+		t.Fatalf("EnsureIndex failed: %v", err)
+	}
+
+	if _, ok := cluster.bodies["PUT /_ilm/policy/lessons-policy"]; !ok {
+		t.Error("Expected the ILM policy to be put")
+	}
+	template := cluster.bodies["PUT /_index_template/lessons-template"]
+	if !strings.Contains(template, `"dims":768`) || !strings.Contains(template, `"index.lifecycle.rollover_alias":"lessons"`) {
+		t.Errorf("Expected template with 768 dims and the rollover alias, got %s", template)
+	}
+	if !strings.Contains(cluster.bodies["PUT /lessons-000001"], `"is_write_index":true`) {
+		t.Errorf("Expected lessons-000001 to be created as the write index, got %v", cluster.requests)
+	}
+
+	// A second bootstrap leaves the existing index alone
+	cluster.requests = nil
+	if err := client.EnsureIndex(context.Background(), spec); err != nil {
+		t.Fatalf("EnsureIndex failed: %v", err)
+	}
+	for _, request := range cluster.requests {
+		if request == "PUT /lessons-000002" || request == "PUT /lessons-000001" {
+			t.Errorf("Expected no index to be created, got %s", request)
+		}
+	}
+
+	state, err := client.IndexState(context.Background(), "lessons")
+	if err != nil {
+		t.Fatalf("IndexState failed: %v", err)
+	}
+	if state.WriteIndex != "lessons-000001" || state.LegacyIndex {
+		t.Errorf("Unexpected state %+v", state)
+	}
+}
+
+func TestReindex(t *testing.T) {
+	cluster, client := newFakeCluster(t)
+	cluster.aliases["lessons"] = []string{"lessons-000001", "lessons-000002"}
+
+	result, err := client.Reindex(context.Background(), NewIndexSpec("lessons", 768), false)
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if result.NewIndex != "lessons-000003" || result.Documents != 42 || result.Deleted {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if !strings.Contains(cluster.bodies["POST /_reindex"], `"dest":{"index":"lessons-000003"}`) {
+		t.Errorf("Expected reindex into lessons-000003, got %s", cluster.bodies["POST /_reindex"])
+	}
+	if got := cluster.aliases["lessons"]; len(got) != 1 || got[0] != "lessons-000003" {
+		t.Errorf("Expected alias to point at lessons-000003, got %v", got)
+	}
+}
+
+func TestReindexLegacyIndex(t *testing.T) {
+	cluster, client := newFakeCluster(t)
+	cluster.indices["lessons"] = true
+
+	if _, err := client.Reindex(context.Background(), NewIndexSpec("lessons", 768), false); err == nil {
+		t.Fatal("Expected migrating a plain index without deleteOld to fail")
+	}
+
+	result, err := client.Reindex(context.Background(), NewIndexSpec("lessons", 768), true)
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if result.NewIndex != "lessons-000001" || !result.Deleted {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if cluster.indices["lessons"] {
+		t.Error("Expected the plain index to be removed in the alias swap")
+	}
+}
+
+func TestEnsureFilteredAlias(t *testing.T) {
+	cluster, client := newFakeCluster(t)
+	cluster.aliases["lessons"] = []string{"lessons-000001", "lessons-000002"}
+
+	if err := client.EnsureFilteredAlias(context.Background(), "lessons", "lessons-acme", "metadata.org_id", "acme"); err != nil {
+		t.Fatalf("EnsureFilteredAlias failed: %v", err)
+	}
+	body := cluster.bodies["POST /_aliases"]
+	for _, index := range []string{"lessons-000001", "lessons-000002"} {
+		if !strings.Contains(body, `"index":"`+index+`"`) {
+			t.Errorf("Expected the alias on %s, got %s", index, body)
+		}
+	}
+	if !strings.Contains(body, `"term":{"metadata.org_id":"acme"}`) {
+		t.Errorf("Expected a term filter on the org, got %s", body)
+	}
+
+	if err := client.EnsureFilteredAlias(context.Background(), "lessons", "lessons-_shared", "metadata.org_id", ""); err != nil {
+		t.Fatalf("EnsureFilteredAlias failed: %v", err)
+	}
+	if body := cluster.bodies["POST /_aliases"]; !strings.Contains(body, `"must_not":{"exists":{"field":"metadata.org_id"}}`) {
+		t.Errorf("Expected untenanted documents to be selected, got %s", body)
+	}
+
+	if err := client.EnsureFilteredAlias(context.Background(), "missing", "missing-acme", "metadata.org_id", "acme"); err == nil {
+		t.Error("Expected an error for a missing target")
+	}
+}
@@ -6,14 +6,32 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
 	"github.com/sirupsen/logrus"
 )
 
+// Backend runs the first-stage hybrid query for a Retriever. Hits should carry
+// separate BM25 and vector scores; the Retriever combines and diversifies them.
+type Backend interface {
+	HybridSearch(ctx context.Context, index, query string, embedding []float32, size int) ([]SearchHit, error)
+}
+
+// QueryEmbedder embeds search queries; llm.Embedder implementations satisfy it
+type QueryEmbedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// ScoreBooster adjusts the combined scores of documents, e.g. from reader feedback
+type ScoreBooster interface {
+	// DocBoost returns the factor the combined score of a document in index is multiplied by
+	DocBoost(index, docID string) float64
+}
+
 // Retriever represents a hybrid search retriever combining BM25 and vector search
 type Retriever struct {
 	client          *Client
-	embeddingClient *llm.EmbeddingClient
+	backend         Backend      // Used instead of client when set
+	booster         ScoreBooster // Adjusts combined scores before diversification; nil for none
+	embeddingClient QueryEmbedder
 	logger          *logrus.Logger
 	bm25Weight      float64
 	vectorWeight    float64
@@ -38,7 +56,7 @@ type HybridSearchConfig struct {
 }
 
 // NewRetriever creates a new hybrid search retriever
-func NewRetriever(esClient *Client, embeddingClient *llm.EmbeddingClient) *Retriever {
+func NewRetriever(esClient *Client, embeddingClient QueryEmbedder) *Retriever {
 	return &Retriever{
 		client:          esClient,
 		embeddingClient: embeddingClient,
@@ -49,6 +67,13 @@ func NewRetriever(esClient *Client, embeddingClient *llm.EmbeddingClient) *Retri
 	}
 }
 
+// NewRetrieverWithBackend creates a hybrid search retriever over a non-Elasticsearch backend
+func NewRetrieverWithBackend(backend Backend, embeddingClient QueryEmbedder) *Retriever {
+	r := NewRetriever(nil, embeddingClient)
+	r.backend = backend
+	return r
+}
+
 // SetConfig sets the hybrid search configuration
 func (r *Retriever) SetConfig(config HybridSearchConfig) {
 	if config.BM25Weight > 0 {
@@ -88,6 +113,7 @@ func (r *Retriever) HybridSearch(ctx context.Context, index, query string, k int
 
 	// Step 3: Combine scores with weighted sum
 	combinedResults := r.combineScores(esResults, query)
+	r.applyBoosts(index, combinedResults)
 
 	// Step 4: Apply MMR diversification
 	diversifiedResults := r.applyMMR(combinedResults, k)
@@ -117,6 +143,10 @@ func (r *Retriever) embedQuery(ctx context.Context, query string) ([]float32, er
 
 // executeHybridQuery executes the Elasticsearch query with bool_should
 func (r *Retriever) executeHybridQuery(ctx context.Context, index, query string, embedding []float32, size int) ([]SearchHit, error) {
+	if r.backend != nil {
+		return r.backend.HybridSearch(ctx, index, query, embedding, size)
+	}
+
 	// Execute the search using the existing client
 	results, err := r.client.HybridSearch(ctx, index, query, embedding, size)
 	if err != nil {
@@ -153,6 +183,22 @@ func (r *Retriever) combineScores(results []SearchHit, query string) []SearchHit
 	return results
 }
 
+// applyBoosts scales combined scores by the booster's per-document factors and restores
+// descending score order for MMR
+func (r *Retriever) applyBoosts(index string, results []SearchHit) {
+	if r.booster == nil {
+		return
+	}
+	for i := range results {
+		if results[i].Doc.ID != "" {
+			results[i].Score *= r.booster.DocBoost(index, results[i].Doc.ID)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+}
+
 // applyMMR applies Maximal Marginal Relevance diversification
 func (r *Retriever) applyMMR(results []SearchHit, k int) []SearchHit {
 	if len(results) <= k {
@@ -364,6 +410,11 @@ func (r *Retriever) extractSnippet(text, query string, maxLen int) string {
 
 // extractDocFromSource extracts a Doc from Elasticsearch source
 func (r *Retriever) extractDocFromSource(source map[string]interface{}) Doc {
+	return DocFromSource(source)
+}
+
+// DocFromSource extracts a Doc from a stored document source
+func DocFromSource(source map[string]interface{}) Doc {
 	doc := Doc{}
 
 	if id, ok := source["id"].(string); ok {
@@ -418,6 +469,11 @@ func (r *Retriever) SetWeights(bm25Weight, vectorWeight float64) {
 	}
 }
 
+// SetBooster sets the per-document score adjustments applied to future searches
+func (r *Retriever) SetBooster(booster ScoreBooster) {
+	r.booster = booster
+}
+
 // SetMMRLambda sets the MMR diversification factor
 func (r *Retriever) SetMMRLambda(lambda float64) {
 	if lambda >= 0 && lambda <= 1 {
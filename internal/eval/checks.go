@@ -0,0 +1,126 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+)
+
+const (
+	// minSectionChars is the shortest a lesson section may be before it is flagged
+	minSectionChars = 40
+	// maxSectionChars is the longest a lesson section may be before it is flagged
+	maxSectionChars = 4000
+)
+
+// CheckResult represents the outcome of one structural check
+type CheckResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// RunChecks runs the structural checks for a case's output
+func RunChecks(c Case, output *Output) []CheckResult {
+	if output == nil || output.Lesson == nil {
+		return []CheckResult{{Name: "lesson_present", Passed: false, Message: "no lesson was produced"}}
+	}
+
+	checks := []CheckResult{
+		checkSectionsPresent(output.Lesson),
+		checkSectionLengths(output.Lesson),
+		checkToyExample(output.Lesson),
+	}
+	if c.MinOutlineItems > 0 {
+		checks = append(checks, checkOutline(output.Outline, c.MinOutlineItems))
+	}
+	if len(c.RequiredKeywords) > 0 {
+		checks = append(checks, checkKeywords(output.Lesson, c.RequiredKeywords))
+	}
+	return checks
+}
+
+// sectionText returns a lesson section's content by section ID
+func sectionText(lesson *llm.OGLesson, id string) string {
+	text, _ := lesson.SectionText(id)
+	return text
+}
+
+// checkSectionsPresent verifies that every lesson section has content
+func checkSectionsPresent(lesson *llm.OGLesson) CheckResult {
+	var missing []string
+	for _, section := range llm.LessonSections {
+		if strings.TrimSpace(sectionText(lesson, section.ID)) == "" {
+			missing = append(missing, section.ID)
+		}
+	}
+	if len(missing) > 0 {
+		return CheckResult{Name: "sections_present", Message: "missing sections: " + strings.Join(missing, ", ")}
+	}
+	return CheckResult{Name: "sections_present", Passed: true}
+}
+
+// checkSectionLengths verifies that prose sections are neither truncated nor runaway
+func checkSectionLengths(lesson *llm.OGLesson) CheckResult {
+	var problems []string
+	for _, section := range llm.LessonSections {
+		if section.ID == "toy-example" {
+			continue
+		}
+		length := len(strings.TrimSpace(sectionText(lesson, section.ID)))
+		if length == 0 {
+			continue // Reported by sections_present
+		}
+		if length < minSectionChars {
+			problems = append(problems, fmt.Sprintf("%s too short (%d chars)", section.ID, length))
+		} else if length > maxSectionChars {
+			problems = append(problems, fmt.Sprintf("%s too long (%d chars)", section.ID, length))
+		}
+	}
+	if len(problems) > 0 {
+		return CheckResult{Name: "section_lengths", Message: strings.Join(problems, "; ")}
+	}
+	return CheckResult{Name: "section_lengths", Passed: true}
+}
+
+// checkToyExample verifies that the toy example is either code or an explicit N/A
+func checkToyExample(lesson *llm.OGLesson) CheckResult {
+	code := strings.TrimSpace(lesson.ToyExampleCode)
+	if code == "" {
+		return CheckResult{Name: "toy_example", Message: "toy example is empty"}
+	}
+	if strings.EqualFold(code, "N/A") || strings.Contains(code, "\n") {
+		return CheckResult{Name: "toy_example", Passed: true}
+	}
+	return CheckResult{Name: "toy_example", Message: "toy example is a single line and not N/A"}
+}
+
+// checkOutline verifies the summarizer outline length
+func checkOutline(outline []string, minItems int) CheckResult {
+	if len(outline) < minItems {
+		return CheckResult{Name: "outline_length", Message: fmt.Sprintf("outline has %d items, want at least %d", len(outline), minItems)}
+	}
+	return CheckResult{Name: "outline_length", Passed: true}
+}
+
+// checkKeywords verifies that the lesson mentions every required keyword
+func checkKeywords(lesson *llm.OGLesson, keywords []string) CheckResult {
+	var text strings.Builder
+	for _, section := range llm.LessonSections {
+		text.WriteString(strings.ToLower(sectionText(lesson, section.ID)))
+		text.WriteString("\n")
+	}
+	body := text.String()
+
+	var missing []string
+	for _, keyword := range keywords {
+		if !strings.Contains(body, strings.ToLower(keyword)) {
+			missing = append(missing, keyword)
+		}
+	}
+	if len(missing) > 0 {
+		return CheckResult{Name: "required_keywords", Message: "missing keywords: " + strings.Join(missing, ", ")}
+	}
+	return CheckResult{Name: "required_keywords", Passed: true}
+}
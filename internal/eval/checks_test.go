@@ -0,0 +1,67 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+// goodLesson returns a lesson that passes every structural check
+func goodLesson() *llm.OGLesson {
+	prose := strings.Repeat("Binary search halves a sorted list by checking the middle element. ", 2)
+	return &llm.OGLesson{
+		BigPicture:     prose,
+		Metaphor:       prose,
+		CoreMechanism:  prose,
+		ToyExampleCode: "lo, hi := 0, len(a)-1\nfor lo <= hi {}",
+		MemoryHook:     prose,
+		RealLife:       prose,
+		BestPractices:  prose,
+	}
+}
+
+// checksByName indexes check results by name
+func checksByName(results []CheckResult) map[string]CheckResult {
+	byName := make(map[string]CheckResult, len(results))
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+	return byName
+}
+
+// TestRunChecksPass tests that a complete lesson passes every check
+func TestRunChecksPass(t *testing.T) {
+	c := Case{ID: "bs", Topic: "Binary search", RequiredKeywords: []string{"Sorted", "middle"}, MinOutlineItems: 2}
+	results := RunChecks(c, &Output{Lesson: goodLesson(), Outline: []string{"a", "b"}})
+
+	assert.Len(t, results, 5)
+	for _, r := range results {
+		assert.True(t, r.Passed, "%s: %s", r.Name, r.Message)
+	}
+}
+
+// TestRunChecksFailures tests that structural problems are reported
+func TestRunChecksFailures(t *testing.T) {
+	lesson := goodLesson()
+	lesson.Metaphor = ""
+	lesson.MemoryHook = "short"
+	lesson.ToyExampleCode = "see above"
+
+	c := Case{ID: "bs", Topic: "Binary search", RequiredKeywords: []string{"logarithmic"}, MinOutlineItems: 3}
+	results := checksByName(RunChecks(c, &Output{Lesson: lesson, Outline: []string{"a"}}))
+
+	assert.False(t, results["sections_present"].Passed)
+	assert.Contains(t, results["sections_present"].Message, "metaphor")
+	assert.False(t, results["section_lengths"].Passed)
+	assert.Contains(t, results["section_lengths"].Message, "memory-hook")
+	assert.False(t, results["toy_example"].Passed)
+	assert.False(t, results["outline_length"].Passed)
+	assert.False(t, results["required_keywords"].Passed)
+	assert.Contains(t, results["required_keywords"].Message, "logarithmic")
+
+	missing := RunChecks(c, &Output{})
+	assert.Len(t, missing, 1)
+	assert.False(t, missing[0].Passed)
+}
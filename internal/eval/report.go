@@ -0,0 +1,192 @@
+package eval
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+)
+
+// CaseResult represents the evaluation result for one case
+type CaseResult struct {
+	CaseID    string               `json:"case_id"`
+	Topic     string               `json:"topic"`
+	Passed    bool                 `json:"passed"`
+	Score     float64              `json:"score"` // Judge overall score, 0-10; 0 when not judged
+	MinScore  float64              `json:"min_score,omitempty"`
+	Checks    []CheckResult        `json:"checks"`
+	Judgement *llm.LessonJudgement `json:"judgement,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	Duration  time.Duration        `json:"duration"`
+}
+
+// Report represents the results of an evaluation run
+type Report struct {
+	Suite     string        `json:"suite"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Judged    bool          `json:"judged"`
+	Passed    int           `json:"passed"`
+	Failed    int           `json:"failed"`
+	MeanScore float64       `json:"mean_score"`
+	Cases     []CaseResult  `json:"cases"`
+}
+
+// Regression represents a case whose score dropped against a baseline report
+type Regression struct {
+	CaseID        string  `json:"case_id"`
+	BaselineScore float64 `json:"baseline_score"`
+	Score         float64 `json:"score"`
+}
+
+// OK reports whether every case passed
+func (r *Report) OK() bool {
+	return r.Failed == 0
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// LoadReport reads a JSON report, e.g. a baseline from a previous run
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+	return &report, nil
+}
+
+// CompareReports returns the cases whose judge score dropped by more than tolerance against the baseline.
+// Cases missing from either report are ignored.
+func CompareReports(baseline, current *Report, tolerance float64) []Regression {
+	baselineScores := make(map[string]float64, len(baseline.Cases))
+	for _, c := range baseline.Cases {
+		baselineScores[c.CaseID] = c.Score
+	}
+
+	regressions := make([]Regression, 0)
+	for _, c := range current.Cases {
+		previous, ok := baselineScores[c.CaseID]
+		if !ok {
+			continue
+		}
+		if previous-c.Score > tolerance {
+			regressions = append(regressions, Regression{
+				CaseID:        c.CaseID,
+				BaselineScore: previous,
+				Score:         c.Score,
+			})
+		}
+	}
+	return regressions
+}
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite is a JUnit test suite
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a JUnit test case
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitMessage is a JUnit failure or error
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML, one test case per evaluation case
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name: "eval." + r.Suite,
+		Time: formatSeconds(r.Duration),
+	}
+
+	for _, c := range r.Cases {
+		testCase := junitTestCase{
+			Name:      c.CaseID,
+			ClassName: "eval." + r.Suite,
+			Time:      formatSeconds(c.Duration),
+		}
+		if r.Judged {
+			testCase.SystemOut = fmt.Sprintf("score=%.2f", c.Score)
+		}
+
+		switch {
+		case c.Error != "":
+			testCase.Error = &junitMessage{Message: c.Error, Body: c.Error}
+			suite.Errors++
+		case !c.Passed:
+			reasons := failureReasons(c)
+			testCase.Failure = &junitMessage{Message: reasons[0], Body: strings.Join(reasons, "\n")}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// failureReasons lists why a case failed
+func failureReasons(c CaseResult) []string {
+	var reasons []string
+	for _, check := range c.Checks {
+		if !check.Passed {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", check.Name, check.Message))
+		}
+	}
+	if c.Judgement != nil && c.Score < c.MinScore {
+		reasons = append(reasons, fmt.Sprintf("judge score %.2f below minimum %.2f", c.Score, c.MinScore))
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "case failed")
+	}
+	return reasons
+}
+
+// formatSeconds formats a duration as seconds for JUnit time attributes
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,58 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteJUnit tests that passing, failing and errored cases are reported
+func TestWriteJUnit(t *testing.T) {
+	report := &Report{
+		Suite:  "default",
+		Judged: true,
+		Cases: []CaseResult{
+			{CaseID: "ok", Passed: true, Score: 8},
+			{CaseID: "low", Score: 4, MinScore: 6, Judgement: &llm.LessonJudgement{Overall: 4}},
+			{CaseID: "broken", Error: "failed to create session"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&buf))
+
+	var parsed junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &parsed))
+	require.Len(t, parsed.Suites, 1)
+
+	suite := parsed.Suites[0]
+	assert.Equal(t, 3, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Equal(t, 1, suite.Errors)
+	assert.Nil(t, suite.Cases[0].Failure)
+	require.NotNil(t, suite.Cases[1].Failure)
+	assert.Contains(t, suite.Cases[1].Failure.Message, "below minimum")
+	require.NotNil(t, suite.Cases[2].Error)
+}
+
+// TestCompareReports tests regression detection against a baseline
+func TestCompareReports(t *testing.T) {
+	baseline := &Report{Cases: []CaseResult{
+		{CaseID: "a", Score: 8},
+		{CaseID: "b", Score: 8},
+		{CaseID: "gone", Score: 9},
+	}}
+	current := &Report{Cases: []CaseResult{
+		{CaseID: "a", Score: 7.8},
+		{CaseID: "b", Score: 6},
+		{CaseID: "new", Score: 2},
+	}}
+
+	regressions := CompareReports(baseline, current, 0.5)
+	require.Len(t, regressions, 1)
+	assert.Equal(t, Regression{CaseID: "b", BaselineScore: 8, Score: 6}, regressions[0])
+}
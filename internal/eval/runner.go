@@ -0,0 +1,180 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultMinScore is the minimum judge score a case needs to pass
+const DefaultMinScore = 6.0
+
+// Judge scores a lesson against a rubric (e.g. llm.GeminiClient)
+type Judge interface {
+	JudgeLesson(ctx context.Context, rubric *llm.Rubric, topic, lessonJSON string) (*llm.LessonJudgement, error)
+}
+
+// Runner runs evaluation suites
+type Runner struct {
+	source    Source
+	judge     Judge
+	rubric    *llm.Rubric
+	minScore  float64
+	recordDir string
+	logger    *logrus.Logger
+}
+
+// RunnerOption represents a runner configuration option
+type RunnerOption func(*Runner)
+
+// WithJudge enables rubric-based LLM judging
+func WithJudge(judge Judge) RunnerOption {
+	return func(r *Runner) {
+		r.judge = judge
+	}
+}
+
+// WithRubric sets the rubric the judge scores against
+func WithRubric(rubric *llm.Rubric) RunnerOption {
+	return func(r *Runner) {
+		r.rubric = rubric
+	}
+}
+
+// WithMinScore sets the default minimum judge score for a case to pass
+func WithMinScore(minScore float64) RunnerOption {
+	return func(r *Runner) {
+		r.minScore = minScore
+	}
+}
+
+// WithRecordDir records every generated output as a transcript in dir
+func WithRecordDir(dir string) RunnerOption {
+	return func(r *Runner) {
+		r.recordDir = dir
+	}
+}
+
+// WithLogger sets a custom logger
+func WithLogger(logger *logrus.Logger) RunnerOption {
+	return func(r *Runner) {
+		r.logger = logger
+	}
+}
+
+// NewRunner creates a runner that evaluates outputs from source
+func NewRunner(source Source, options ...RunnerOption) *Runner {
+	runner := &Runner{
+		source:   source,
+		rubric:   llm.DefaultRubric(),
+		minScore: DefaultMinScore,
+		logger:   logrus.New(),
+	}
+
+	for _, option := range options {
+		option(runner)
+	}
+
+	return runner
+}
+
+// Run evaluates every case in the suite sequentially
+func (r *Runner) Run(ctx context.Context, suite *Suite) (*Report, error) {
+	if err := suite.Validate(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Suite:     suite.Name,
+		StartedAt: time.Now(),
+		Judged:    r.judge != nil,
+		Cases:     make([]CaseResult, 0, len(suite.Cases)),
+	}
+
+	var totalScore float64
+	for _, c := range suite.Cases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result := r.runCase(ctx, c)
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		totalScore += result.Score
+		report.Cases = append(report.Cases, result)
+
+		r.logger.WithFields(logrus.Fields{
+			"case_id": c.ID,
+			"passed":  result.Passed,
+			"score":   result.Score,
+			"error":   result.Error,
+		}).Info("Evaluated case")
+	}
+
+	if report.Judged && len(report.Cases) > 0 {
+		report.MeanScore = totalScore / float64(len(report.Cases))
+	}
+	report.Duration = time.Since(report.StartedAt)
+	return report, nil
+}
+
+// runCase generates, checks and judges a single case
+func (r *Runner) runCase(ctx context.Context, c Case) CaseResult {
+	start := time.Now()
+	result := CaseResult{CaseID: c.ID, Topic: c.Topic}
+
+	output, err := r.source.Generate(ctx, c)
+	if err != nil {
+		result.Error = err.Error()
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	if r.recordDir != "" {
+		if err := SaveTranscript(r.recordDir, output); err != nil {
+			r.logger.WithFields(logrus.Fields{
+				"case_id": c.ID,
+				"error":   err,
+			}).Warn("Failed to record transcript")
+		}
+	}
+
+	result.Checks = RunChecks(c, output)
+	result.Passed = true
+	for _, check := range result.Checks {
+		if !check.Passed {
+			result.Passed = false
+		}
+	}
+
+	if r.judge != nil && output.Lesson != nil {
+		result.MinScore = r.minScore
+		if c.MinScore > 0 {
+			result.MinScore = c.MinScore
+		}
+
+		lessonJSON, err := json.Marshal(output.Lesson)
+		if err != nil {
+			result.Error = err.Error()
+			result.Passed = false
+		} else if judgement, err := r.judge.JudgeLesson(ctx, r.rubric, c.Topic, string(lessonJSON)); err != nil {
+			result.Error = "judge failed: " + err.Error()
+			result.Passed = false
+		} else {
+			result.Judgement = judgement
+			result.Score = judgement.Overall
+			if result.Score < result.MinScore {
+				result.Passed = false
+			}
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
@@ -0,0 +1,97 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJudge returns a fixed score per topic
+type fakeJudge struct {
+	scores map[string]float64
+}
+
+// JudgeLesson returns the configured score for the topic
+func (j *fakeJudge) JudgeLesson(ctx context.Context, rubric *llm.Rubric, topic, lessonJSON string) (*llm.LessonJudgement, error) {
+	score, ok := j.scores[topic]
+	if !ok {
+		return nil, errors.New("no score configured")
+	}
+	return &llm.LessonJudgement{Overall: score}, nil
+}
+
+// TestRunnerWithTranscripts tests replaying recorded transcripts with judging
+func TestRunnerWithTranscripts(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, SaveTranscript(dir, &Output{CaseID: "good", Topic: "Binary search", Lesson: goodLesson()}))
+	require.NoError(t, SaveTranscript(dir, &Output{CaseID: "weak", Topic: "Binary search", Lesson: goodLesson()}))
+
+	suite := &Suite{Name: "test", Cases: []Case{
+		{ID: "good", Topic: "Binary search"},
+		{ID: "weak", Topic: "Weak topic"},
+		{ID: "missing", Topic: "Missing"},
+	}}
+
+	judge := &fakeJudge{scores: map[string]float64{"Binary search": 8, "Weak topic": 5}}
+	report, err := NewRunner(NewTranscriptSource(dir), WithJudge(judge)).Run(context.Background(), suite)
+	require.NoError(t, err)
+
+	assert.True(t, report.Judged)
+	assert.Equal(t, 1, report.Passed)
+	assert.Equal(t, 2, report.Failed)
+	assert.False(t, report.OK())
+	assert.InDelta(t, 13.0/3, report.MeanScore, 0.001)
+
+	assert.True(t, report.Cases[0].Passed)
+	assert.False(t, report.Cases[1].Passed)
+	assert.Equal(t, DefaultMinScore, report.Cases[1].MinScore)
+	assert.NotEmpty(t, report.Cases[2].Error)
+}
+
+// TestPipelineSource tests running a case through the orchestrator HTTP API
+func TestPipelineSource(t *testing.T) {
+	lessonJSON, err := json.Marshal(goodLesson())
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/sessions", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "Binary search", req["topic"])
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"id": "s1"})
+	})
+	mux.HandleFunc("/api/sessions/s1/run", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"type\":\"session_complete\"}\n\n"))
+	})
+	mux.HandleFunc("/api/sessions/s1/result", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"lesson":  string(lessonJSON),
+			"outline": []string{"one", "two"},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	output, err := NewPipelineSource(server.URL, 0).Generate(context.Background(), Case{ID: "bs", Topic: "Binary search"})
+	require.NoError(t, err)
+	assert.Equal(t, "s1", output.SessionID)
+	assert.Equal(t, goodLesson().BigPicture, output.Lesson.BigPicture)
+	assert.Equal(t, []string{"one", "two"}, output.Outline)
+}
+
+// TestLoadSuiteValidation tests that invalid suites are rejected
+func TestLoadSuiteValidation(t *testing.T) {
+	assert.NoError(t, DefaultSuite().Validate())
+	assert.Error(t, (&Suite{Name: "empty"}).Validate())
+	assert.Error(t, (&Suite{Cases: []Case{{ID: "a", Topic: "x"}, {ID: "a", Topic: "y"}}}).Validate())
+	assert.Error(t, (&Suite{Cases: []Case{{ID: "a"}}}).Validate())
+}
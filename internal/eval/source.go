@@ -0,0 +1,180 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+)
+
+// Output represents the pipeline output for one case
+type Output struct {
+	CaseID    string        `json:"case_id"`
+	Topic     string        `json:"topic"`
+	SessionID string        `json:"session_id,omitempty"`
+	Lesson    *llm.OGLesson `json:"lesson"`
+	Outline   []string      `json:"outline,omitempty"`
+	Duration  time.Duration `json:"duration,omitempty"`
+}
+
+// Source produces the output to evaluate for a case
+type Source interface {
+	Generate(ctx context.Context, c Case) (*Output, error)
+}
+
+// TranscriptSource replays recorded outputs stored as <case-id>.json files in a directory
+type TranscriptSource struct {
+	dir string
+}
+
+// NewTranscriptSource creates a source that reads recorded transcripts from dir
+func NewTranscriptSource(dir string) *TranscriptSource {
+	return &TranscriptSource{dir: dir}
+}
+
+// Generate loads the recorded output for a case
+func (s *TranscriptSource) Generate(ctx context.Context, c Case) (*Output, error) {
+	data, err := os.ReadFile(transcriptPath(s.dir, c.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	var output Output
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse transcript: %w", err)
+	}
+	output.CaseID = c.ID
+	if output.Topic == "" {
+		output.Topic = c.Topic
+	}
+	return &output, nil
+}
+
+// SaveTranscript records an output so it can be replayed with a TranscriptSource
+func SaveTranscript(dir string, output *Output) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcript: %w", err)
+	}
+	if err := os.WriteFile(transcriptPath(dir, output.CaseID), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// transcriptPath returns the transcript file for a case
+func transcriptPath(dir, caseID string) string {
+	return filepath.Join(dir, filepath.Base(caseID)+".json")
+}
+
+// PipelineSource runs cases through a live orchestrator over its HTTP API
+type PipelineSource struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewPipelineSource creates a source backed by the orchestrator at baseURL
+func NewPipelineSource(baseURL string, timeout time.Duration) *PipelineSource {
+	return &PipelineSource{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// sessionResult mirrors the orchestrator's session result payload
+type sessionResult struct {
+	Lesson  string   `json:"lesson"`
+	Outline []string `json:"outline"`
+}
+
+// Generate creates a session, runs it to completion and fetches its result
+func (s *PipelineSource) Generate(ctx context.Context, c Case) (*Output, error) {
+	start := time.Now()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"topic":            c.Topic,
+		"explanation_type": c.ExplanationType,
+		"persona":          c.Persona,
+		"metadata":         map[string]string{"source": "eval", "eval_case": c.ID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal session request: %w", err)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := s.do(ctx, http.MethodPost, "/api/sessions", body, http.StatusCreated, &created); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	// The run endpoint streams SSE events and closes the stream when the session finishes
+	if err := s.do(ctx, http.MethodPost, "/api/sessions/"+created.ID+"/run", nil, http.StatusOK, nil); err != nil {
+		return nil, fmt.Errorf("failed to run session %s: %w", created.ID, err)
+	}
+
+	var result sessionResult
+	if err := s.do(ctx, http.MethodGet, "/api/sessions/"+created.ID+"/result", nil, http.StatusOK, &result); err != nil {
+		return nil, fmt.Errorf("failed to fetch result for session %s: %w", created.ID, err)
+	}
+
+	var lesson llm.OGLesson
+	if err := json.Unmarshal([]byte(result.Lesson), &lesson); err != nil {
+		return nil, fmt.Errorf("session %s returned an unparseable lesson: %w", created.ID, err)
+	}
+
+	return &Output{
+		CaseID:    c.ID,
+		Topic:     c.Topic,
+		SessionID: created.ID,
+		Lesson:    &lesson,
+		Outline:   result.Outline,
+		Duration:  time.Since(start),
+	}, nil
+}
+
+// do sends a request to the orchestrator and decodes the response into out, if non-nil
+func (s *PipelineSource) do(ctx context.Context, method, path string, body []byte, wantStatus int, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != wantStatus {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,80 @@
+// Package eval runs a fixed suite of topics through the lesson pipeline (or recorded
+// transcripts), scores the outputs with structural checks and rubric-based LLM judging,
+// and reports the results so prompt and model changes can be gated on quality regressions.
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Case represents one topic in an evaluation suite
+type Case struct {
+	ID               string   `json:"id"`
+	Topic            string   `json:"topic"`
+	ExplanationType  string   `json:"explanation_type,omitempty"`
+	Persona          string   `json:"persona,omitempty"`
+	RequiredKeywords []string `json:"required_keywords,omitempty"` // Terms the lesson must mention (case-insensitive)
+	MinOutlineItems  int      `json:"min_outline_items,omitempty"` // Minimum outline length; 0 skips the check
+	MinScore         float64  `json:"min_score,omitempty"`         // Overrides the runner's minimum judge score
+}
+
+// Suite represents a named, fixed set of evaluation cases
+type Suite struct {
+	Name  string `json:"name"`
+	Cases []Case `json:"cases"`
+}
+
+// DefaultSuite returns the built-in topic suite
+func DefaultSuite() *Suite {
+	return &Suite{
+		Name: "default",
+		Cases: []Case{
+			{ID: "binary-search", Topic: "Binary search", RequiredKeywords: []string{"sorted", "middle"}, MinOutlineItems: 3},
+			{ID: "goroutines", Topic: "Goroutines in Go", RequiredKeywords: []string{"concurrent", "channel"}, MinOutlineItems: 3},
+			{ID: "tcp-handshake", Topic: "TCP three-way handshake", RequiredKeywords: []string{"SYN", "ACK"}, MinOutlineItems: 3},
+			{ID: "photosynthesis", Topic: "Photosynthesis", ExplanationType: "simple", Persona: "10-year-old", RequiredKeywords: []string{"light", "sugar"}},
+			{ID: "hash-tables", Topic: "Hash tables", RequiredKeywords: []string{"hash", "collision"}, MinOutlineItems: 3},
+		},
+	}
+}
+
+// LoadSuite reads a suite from a JSON file
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite file: %w", err)
+	}
+
+	var suite Suite
+	if err := json.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse suite file: %w", err)
+	}
+	if err := suite.Validate(); err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
+
+// Validate checks that the suite has cases with unique IDs and topics
+func (s *Suite) Validate() error {
+	if len(s.Cases) == 0 {
+		return fmt.Errorf("suite %q has no cases", s.Name)
+	}
+
+	seen := make(map[string]bool, len(s.Cases))
+	for i, c := range s.Cases {
+		if c.ID == "" {
+			return fmt.Errorf("case %d is missing an id", i)
+		}
+		if c.Topic == "" {
+			return fmt.Errorf("case %q is missing a topic", c.ID)
+		}
+		if seen[c.ID] {
+			return fmt.Errorf("duplicate case id %q", c.ID)
+		}
+		seen[c.ID] = true
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package flags
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagValidate(t *testing.T) {
+	assert.NoError(t, (&Flag{Key: "critic.multi_turn", RolloutPercent: 50}).Validate())
+	assert.Error(t, (&Flag{Key: ""}).Validate())
+	assert.Error(t, (&Flag{Key: "Bad Key"}).Validate())
+	assert.Error(t, (&Flag{Key: "ok", RolloutPercent: 101}).Validate())
+	assert.Error(t, (&Flag{Key: "ok", RolloutPercent: -1}).Validate())
+}
+
+func TestFlagEvaluateTargeting(t *testing.T) {
+	flag := &Flag{
+		Key:     "new_agent",
+		Enabled: true,
+		Orgs:    []string{"org-1"},
+		Users:   []string{"user-1"},
+	}
+
+	assert.Equal(t, Decision{Key: "new_agent", Enabled: true, Reason: ReasonUserTarget}, flag.Evaluate(EvalContext{UserID: "user-1"}))
+	assert.Equal(t, Decision{Key: "new_agent", Enabled: true, Reason: ReasonOrgTarget}, flag.Evaluate(EvalContext{UserID: "user-2", OrgID: "org-1"}))
+	assert.Equal(t, Decision{Key: "new_agent", Enabled: false, Reason: ReasonRolloutExcluded}, flag.Evaluate(EvalContext{UserID: "user-2"}))
+
+	flag.Enabled = false
+	assert.Equal(t, Decision{Key: "new_agent", Enabled: false, Reason: ReasonDisabled}, flag.Evaluate(EvalContext{UserID: "user-1"}))
+}
+
+func TestFlagEvaluateRollout(t *testing.T) {
+	flag := &Flag{Key: "critic.multi_turn", Enabled: true, RolloutPercent: 30}
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		ec := EvalContext{UserID: fmt.Sprintf("user-%d", i)}
+		decision := flag.Evaluate(ec)
+		// Bucketing is deterministic
+		assert.Equal(t, decision, flag.Evaluate(ec))
+		if decision.Enabled {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 300, enabled, 60)
+
+	// Growing the rollout never removes a user who already had the flag
+	wider := &Flag{Key: "critic.multi_turn", Enabled: true, RolloutPercent: 60}
+	for i := 0; i < 1000; i++ {
+		ec := EvalContext{UserID: fmt.Sprintf("user-%d", i)}
+		if flag.Evaluate(ec).Enabled {
+			assert.True(t, wider.Evaluate(ec).Enabled)
+		}
+	}
+
+	// Anonymous callers only get fully rolled out flags
+	assert.False(t, flag.Evaluate(EvalContext{}).Enabled)
+	flag.RolloutPercent = 100
+	assert.True(t, flag.Evaluate(EvalContext{}).Enabled)
+}
@@ -0,0 +1,68 @@
+package flags
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+)
+
+// contextKey is the type for flag values stored in a request context
+type contextKey struct{}
+
+// requestFlags holds the flags evaluated for a request
+type requestFlags struct {
+	evalContext EvalContext
+	enabled     map[string]bool
+}
+
+// EvalContextFromRequest returns the evaluation context of the request's authenticated
+// principal. Anonymous requests get an empty context, so callers cannot opt themselves into
+// user or organization targeting by naming another identity.
+func EvalContextFromRequest(r *http.Request) EvalContext {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return EvalContext{}
+	}
+	return EvalContext{UserID: principal.UserID, OrgID: principal.OrgID}
+}
+
+// Middleware evaluates all flags for the caller once per request and stores the
+// results in the request context, where handlers read them with Enabled.
+// A nil service passes requests through unchanged.
+func Middleware(s *Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if s == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ec := EvalContextFromRequest(r)
+			ctx := WithFlags(r.Context(), ec, s.EvaluateAll(r.Context(), ec))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WithFlags returns a context carrying evaluated flags
+func WithFlags(ctx context.Context, ec EvalContext, enabled map[string]bool) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestFlags{evalContext: ec, enabled: enabled})
+}
+
+// Enabled reports whether a flag was evaluated as on for the context's request.
+// It returns false when the context carries no flags.
+func Enabled(ctx context.Context, key string) bool {
+	if rf, ok := ctx.Value(contextKey{}).(*requestFlags); ok {
+		return rf.enabled[key]
+	}
+	return false
+}
+
+// FromContext returns the evaluation context and evaluated flags stored in ctx
+func FromContext(ctx context.Context) (EvalContext, map[string]bool, bool) {
+	rf, ok := ctx.Value(contextKey{}).(*requestFlags)
+	if !ok {
+		return EvalContext{}, nil, false
+	}
+	return rf.evalContext, rf.enabled, true
+}
@@ -0,0 +1,241 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// storageKey is the storage key holding the deployment's flag set
+	storageKey = "feature_flags"
+	// DefaultRefreshInterval is how long flags are served from memory before being reloaded from storage
+	DefaultRefreshInterval = 30 * time.Second
+)
+
+// Store defines the storage operations needed to persist flags
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// Service stores feature flags and evaluates them. Flags are kept in memory and
+// reloaded from storage periodically so changes made through another replica
+// take effect without a restart.
+type Service struct {
+	store           Store
+	refreshInterval time.Duration
+	logger          *logrus.Logger
+	mu              sync.RWMutex
+	flags           map[string]*Flag
+	loadedAt        time.Time
+	now             func() time.Time
+}
+
+// NewService creates a flag service. A nil store keeps flags in memory only.
+func NewService(store Store) *Service {
+	return &Service{
+		store:           store,
+		refreshInterval: DefaultRefreshInterval,
+		logger:          logrus.New(),
+		flags:           make(map[string]*Flag),
+		now:             time.Now,
+	}
+}
+
+// SetRefreshInterval sets how often flags are reloaded from storage
+func (s *Service) SetRefreshInterval(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshInterval = interval
+}
+
+// Load reloads the flag set from storage
+func (s *Service) Load(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+
+	data, err := s.store.Get(ctx, storageKey)
+	if err != nil {
+		return fmt.Errorf("failed to load feature flags: %w", err)
+	}
+
+	var stored []*Flag
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return fmt.Errorf("failed to decode feature flags: %w", err)
+		}
+	}
+
+	flags := make(map[string]*Flag, len(stored))
+	for _, flag := range stored {
+		if err := flag.Validate(); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"flag":  flag.Key,
+				"error": err,
+			}).Warn("Skipping invalid stored feature flag")
+			continue
+		}
+		flags[flag.Key] = flag
+	}
+
+	s.mu.Lock()
+	s.flags = flags
+	s.loadedAt = s.now()
+	s.mu.Unlock()
+	return nil
+}
+
+// refreshIfStale reloads flags when the in-memory copy is older than the refresh interval.
+// On failure the last known flags keep being served.
+func (s *Service) refreshIfStale(ctx context.Context) {
+	if s.store == nil {
+		return
+	}
+
+	s.mu.RLock()
+	stale := s.now().Sub(s.loadedAt) >= s.refreshInterval
+	s.mu.RUnlock()
+	if !stale {
+		return
+	}
+
+	if err := s.Load(ctx); err != nil {
+		s.logger.WithError(err).Warn("Failed to refresh feature flags, using cached flags")
+		// Back off until the next interval rather than retrying on every evaluation
+		s.mu.Lock()
+		s.loadedAt = s.now()
+		s.mu.Unlock()
+	}
+}
+
+// List returns all flags sorted by key
+func (s *Service) List(ctx context.Context) []Flag {
+	s.refreshIfStale(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	flags := make([]Flag, 0, len(s.flags))
+	for _, flag := range s.flags {
+		flags = append(flags, *flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+	return flags
+}
+
+// Get returns a flag by key
+func (s *Service) Get(ctx context.Context, key string) (Flag, bool) {
+	s.refreshIfStale(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	flag, exists := s.flags[key]
+	if !exists {
+		return Flag{}, false
+	}
+	return *flag, true
+}
+
+// Put creates or replaces a flag and persists the flag set
+func (s *Service) Put(ctx context.Context, flag Flag) (Flag, error) {
+	if err := flag.Validate(); err != nil {
+		return Flag{}, err
+	}
+	flag.UpdatedAt = s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.flags[flag.Key]
+	s.flags[flag.Key] = &flag
+	if err := s.persistLocked(ctx); err != nil {
+		if existed {
+			s.flags[flag.Key] = previous
+		} else {
+			delete(s.flags, flag.Key)
+		}
+		return Flag{}, err
+	}
+	return flag, nil
+}
+
+// Delete removes a flag and persists the flag set. It returns false if the flag did not exist.
+func (s *Service) Delete(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, exists := s.flags[key]
+	if !exists {
+		return false, nil
+	}
+	delete(s.flags, key)
+	if err := s.persistLocked(ctx); err != nil {
+		s.flags[key] = previous
+		return false, err
+	}
+	return true, nil
+}
+
+// persistLocked writes the flag set to storage; the caller must hold s.mu
+func (s *Service) persistLocked(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+
+	flags := make([]*Flag, 0, len(s.flags))
+	for _, flag := range s.flags {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+
+	data, err := json.Marshal(flags)
+	if err != nil {
+		return fmt.Errorf("failed to encode feature flags: %w", err)
+	}
+	if err := s.store.Set(ctx, storageKey, data); err != nil {
+		return fmt.Errorf("failed to save feature flags: %w", err)
+	}
+	s.loadedAt = s.now()
+	return nil
+}
+
+// Evaluate decides whether a flag is on for an evaluation context. Unknown flags are off.
+func (s *Service) Evaluate(ctx context.Context, key string, ec EvalContext) Decision {
+	s.refreshIfStale(ctx)
+
+	s.mu.RLock()
+	flag, exists := s.flags[key]
+	s.mu.RUnlock()
+
+	if !exists {
+		return Decision{Key: key, Reason: ReasonUnknown}
+	}
+	return flag.Evaluate(ec)
+}
+
+// IsEnabled reports whether a flag is on for an evaluation context
+func (s *Service) IsEnabled(ctx context.Context, key string, ec EvalContext) bool {
+	return s.Evaluate(ctx, key, ec).Enabled
+}
+
+// EvaluateAll evaluates every flag for an evaluation context
+func (s *Service) EvaluateAll(ctx context.Context, ec EvalContext) map[string]bool {
+	s.refreshIfStale(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[string]bool, len(s.flags))
+	for key, flag := range s.flags {
+		result[key] = flag.Evaluate(ec).Enabled
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory Store for tests
+type memoryStore struct {
+	data    map[string][]byte
+	failSet bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{data: make(map[string][]byte)}
+}
+
+func (m *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return m.data[key], nil
+}
+
+func (m *memoryStore) Set(ctx context.Context, key string, value []byte) error {
+	if m.failSet {
+		return errors.New("storage unavailable")
+	}
+	m.data[key] = value
+	return nil
+}
+
+func TestServicePersistsFlags(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	service := NewService(store)
+
+	_, err := service.Put(ctx, Flag{Key: "new_agent", Enabled: true, RolloutPercent: 100})
+	require.NoError(t, err)
+	_, err = service.Put(ctx, Flag{Key: "Invalid"})
+	assert.Error(t, err)
+
+	// Another replica sees the stored flags
+	other := NewService(store)
+	require.NoError(t, other.Load(ctx))
+	flag, exists := other.Get(ctx, "new_agent")
+	require.True(t, exists)
+	assert.Equal(t, 100, flag.RolloutPercent)
+	assert.True(t, other.IsEnabled(ctx, "new_agent", EvalContext{}))
+	assert.Equal(t, ReasonUnknown, other.Evaluate(ctx, "missing", EvalContext{}).Reason)
+
+	deleted, err := service.Delete(ctx, "new_agent")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Empty(t, service.List(ctx))
+}
+
+func TestServiceRollsBackOnStorageFailure(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	service := NewService(store)
+
+	_, err := service.Put(ctx, Flag{Key: "new_agent", Enabled: true})
+	require.NoError(t, err)
+
+	store.failSet = true
+	_, err = service.Put(ctx, Flag{Key: "new_agent", Enabled: false})
+	assert.Error(t, err)
+	flag, _ := service.Get(ctx, "new_agent")
+	assert.True(t, flag.Enabled)
+
+	_, err = service.Delete(ctx, "new_agent")
+	assert.Error(t, err)
+	_, exists := service.Get(ctx, "new_agent")
+	assert.True(t, exists)
+}
+
+func TestServiceRefreshesFromStorage(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	now := time.Now()
+
+	reader := NewService(store)
+	reader.now = func() time.Time { return now }
+	require.NoError(t, reader.Load(ctx))
+
+	writer := NewService(store)
+	_, err := writer.Put(ctx, Flag{Key: "new_agent", Enabled: true, RolloutPercent: 100})
+	require.NoError(t, err)
+
+	// Served from memory until the refresh interval passes
+	assert.False(t, reader.IsEnabled(ctx, "new_agent", EvalContext{}))
+	now = now.Add(DefaultRefreshInterval)
+	assert.True(t, reader.IsEnabled(ctx, "new_agent", EvalContext{}))
+}
+
+func TestMiddleware(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(nil)
+	_, err := service.Put(ctx, Flag{Key: "new_agent", Enabled: true, Orgs: []string{"org-1"}})
+	require.NoError(t, err)
+
+	var enabled bool
+	handler := Middleware(service)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enabled = Enabled(r.Context(), "new_agent")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{OrgID: "org-1", Method: auth.MethodAPIKey}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.True(t, enabled)
+
+	// Identity headers and query parameters are not trusted
+	req = httptest.NewRequest(http.MethodGet, "/?org_id=org-1", nil)
+	req.Header.Set("X-Org-ID", "org-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.False(t, enabled)
+
+	assert.False(t, Enabled(ctx, "new_agent"))
+}
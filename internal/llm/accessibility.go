@@ -0,0 +1,199 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxAltTextLength keeps alt text short enough for screen readers; longer content belongs in the long description
+const maxAltTextLength = 125
+
+// ImageAccessibility represents the accessible text for one lesson image
+type ImageAccessibility struct {
+	URL             string `json:"url"`
+	AltText         string `json:"alt_text"`
+	LongDescription string `json:"long_description,omitempty"`
+	Caption         string `json:"caption,omitempty"`
+}
+
+// AccessibilityInfo summarizes how screen-reader complete a lesson's images are
+type AccessibilityInfo struct {
+	Images          []ImageAccessibility `json:"images"`
+	RepairedAltText int                  `json:"repaired_alt_text"` // Images whose alt text was missing and had to be generated
+	Complete        bool                 `json:"complete"`          // Every image has alt text and a long description
+}
+
+// EnsureImageAccessibility fills in missing or overlong alt text from the caption or long
+// description so no image is left without a text alternative. It returns the repaired images
+// and the indexes of images whose alt text was missing.
+func EnsureImageAccessibility(images []ImageRef, topic string) ([]ImageRef, []int) {
+	repaired := make([]ImageRef, len(images))
+	var missing []int
+
+	for i, image := range images {
+		image.AltText = strings.TrimSpace(image.AltText)
+		image.LongDescription = strings.TrimSpace(image.LongDescription)
+
+		if image.AltText == "" {
+			missing = append(missing, i)
+			switch {
+			case strings.TrimSpace(image.Caption) != "":
+				image.AltText = strings.TrimSpace(image.Caption)
+			case image.LongDescription != "":
+				image.AltText = firstSentence(image.LongDescription)
+			default:
+				image.AltText = fmt.Sprintf("Diagram %d for %s", i+1, topic)
+			}
+		}
+
+		if len(image.AltText) > maxAltTextLength {
+			// Keep the full text available in the long description
+			if image.LongDescription == "" {
+				image.LongDescription = image.AltText
+			}
+			image.AltText = truncateWords(image.AltText, maxAltTextLength)
+		}
+
+		repaired[i] = image
+	}
+
+	return repaired, missing
+}
+
+// BuildAccessibilityInfo builds the accessibility summary for a set of images
+func BuildAccessibilityInfo(images []ImageRef, repairedAltText int) *AccessibilityInfo {
+	info := &AccessibilityInfo{
+		Images:          make([]ImageAccessibility, 0, len(images)),
+		RepairedAltText: repairedAltText,
+		Complete:        true,
+	}
+
+	for _, image := range images {
+		info.Images = append(info.Images, ImageAccessibility{
+			URL:             image.URL,
+			AltText:         image.AltText,
+			LongDescription: image.LongDescription,
+			Caption:         image.Caption,
+		})
+		if image.AltText == "" || image.LongDescription == "" {
+			info.Complete = false
+		}
+	}
+
+	return info
+}
+
+// addLongDescriptions generates a long description for each image that lacks one.
+// If the request fails, descriptions are derived from the visualization prompts instead.
+func (c *GeminiClient) addLongDescriptions(ctx context.Context, lesson OGLesson, prompts []VisualizationPrompt, images []ImageRef) []ImageRef {
+	descriptions, err := c.generateLongDescriptions(ctx, lesson, prompts)
+	if err != nil {
+		c.logger.WithFields(logrus.Fields{
+			"error": err,
+		}).Warn("Long description generation failed, using prompt-based descriptions")
+		descriptions = nil
+	}
+
+	result := make([]ImageRef, len(images))
+	for i, image := range images {
+		if image.LongDescription == "" {
+			if i < len(descriptions) && strings.TrimSpace(descriptions[i]) != "" {
+				image.LongDescription = strings.TrimSpace(descriptions[i])
+			} else if i < len(prompts) {
+				image.LongDescription = fallbackLongDescription(prompts[i], lesson)
+			}
+		}
+		result[i] = image
+	}
+	return result
+}
+
+// generateLongDescriptions asks the model for one WCAG-style long description per diagram
+func (c *GeminiClient) generateLongDescriptions(ctx context.Context, lesson OGLesson, prompts []VisualizationPrompt) ([]string, error) {
+	response, err := c.executeRequest(ctx, buildLongDescriptionPrompt(lesson, prompts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute long description request: %w", err)
+	}
+
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+
+	return parseLongDescriptions(text.String(), len(prompts))
+}
+
+// buildLongDescriptionPrompt creates the prompt for image long descriptions
+func buildLongDescriptionPrompt(lesson OGLesson, prompts []VisualizationPrompt) string {
+	var promptBuilder strings.Builder
+
+	promptBuilder.WriteString("You are writing long descriptions for diagrams in a lesson so that learners using screen readers get the same information as sighted learners.\n\n")
+	promptBuilder.WriteString(fmt.Sprintf("Concept shown: %s\n\n", lesson.CoreMechanism))
+	promptBuilder.WriteString("Diagrams:\n")
+	for i, prompt := range prompts {
+		promptBuilder.WriteString(fmt.Sprintf("[%d] %s\n", i+1, prompt.Caption))
+	}
+	promptBuilder.WriteString("\nFor each diagram write 2-4 plain sentences describing its parts, their relationships, and the order of any flow, " +
+		"without starting with \"Image of\" or \"Diagram of\".\n")
+	promptBuilder.WriteString(fmt.Sprintf("Respond with a JSON array of exactly %d strings in diagram order and nothing else.\n", len(prompts)))
+
+	return promptBuilder.String()
+}
+
+// parseLongDescriptions extracts the JSON description array from a response
+func parseLongDescriptions(responseText string, expected int) ([]string, error) {
+	start := strings.Index(responseText, "[")
+	end := strings.LastIndex(responseText, "]")
+	if start == -1 || end <= start {
+		return nil, fmt.Errorf("no description array found in response")
+	}
+
+	var descriptions []string
+	if err := json.Unmarshal([]byte(responseText[start:end+1]), &descriptions); err != nil {
+		return nil, fmt.Errorf("failed to parse long descriptions: %w", err)
+	}
+	if len(descriptions) != expected {
+		return nil, fmt.Errorf("expected %d long descriptions, got %d", expected, len(descriptions))
+	}
+	return descriptions, nil
+}
+
+// fallbackLongDescription derives a long description from the visualization prompt
+func fallbackLongDescription(prompt VisualizationPrompt, lesson OGLesson) string {
+	description := strings.TrimSpace(prompt.Caption)
+	if description != "" && !strings.HasSuffix(description, ".") {
+		description += "."
+	}
+	if mechanism := strings.TrimSpace(lesson.CoreMechanism); mechanism != "" {
+		description += " The diagram illustrates how it works: " + mechanism
+	}
+	return strings.TrimSpace(description)
+}
+
+// firstSentence returns the first sentence of a text
+func firstSentence(text string) string {
+	if i := strings.IndexAny(text, ".!?"); i > 0 {
+		return text[:i+1]
+	}
+	return text
+}
+
+// truncateWords shortens text to at most max bytes at a word boundary, adding an ellipsis
+func truncateWords(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	cut := text[:max-1]
+	for len(cut) > 0 && !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
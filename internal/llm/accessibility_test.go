@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEnsureImageAccessibility tests that missing and overlong alt text is repaired
+func TestEnsureImageAccessibility(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	images, missing := EnsureImageAccessibility([]ImageRef{
+		{URL: "a", AltText: "Flowchart of TCP handshake"},
+		{URL: "b", Caption: "Process flowchart"},
+		{URL: "c", LongDescription: "Three boxes in a row. Arrows connect them."},
+		{URL: "d"},
+		{URL: "e", AltText: long},
+	}, "TCP")
+
+	assert.Equal(t, []int{1, 2, 3}, missing)
+	assert.Equal(t, "Flowchart of TCP handshake", images[0].AltText)
+	assert.Equal(t, "Process flowchart", images[1].AltText)
+	assert.Equal(t, "Three boxes in a row.", images[2].AltText)
+	assert.Equal(t, "Diagram 4 for TCP", images[3].AltText)
+	assert.LessOrEqual(t, len(images[4].AltText), maxAltTextLength+len("…"))
+	assert.Equal(t, strings.TrimSpace(long), images[4].LongDescription)
+}
+
+// TestBuildAccessibilityInfo tests completeness reporting
+func TestBuildAccessibilityInfo(t *testing.T) {
+	info := BuildAccessibilityInfo([]ImageRef{{URL: "a", AltText: "alt", LongDescription: "long"}}, 0)
+	assert.True(t, info.Complete)
+
+	info = BuildAccessibilityInfo([]ImageRef{{URL: "a", AltText: "alt"}}, 1)
+	assert.False(t, info.Complete)
+	assert.Equal(t, 1, info.RepairedAltText)
+
+	assert.True(t, BuildAccessibilityInfo(nil, 0).Complete)
+}
+
+// TestParseLongDescriptions tests extracting long descriptions from model output
+func TestParseLongDescriptions(t *testing.T) {
+	descriptions, err := parseLongDescriptions("```json\n[\"first\", \"second\"]\n```", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, descriptions)
+
+	_, err = parseLongDescriptions(`["only one"]`, 2)
+	assert.Error(t, err)
+}
+
+// TestFallbackLongDescription tests prompt-derived long descriptions
+func TestFallbackLongDescription(t *testing.T) {
+	description := fallbackLongDescription(VisualizationPrompt{Caption: "Process flowchart"}, OGLesson{CoreMechanism: "Packets are acknowledged."})
+	assert.Equal(t, "Process flowchart. The diagram illustrates how it works: Packets are acknowledged.", description)
+}
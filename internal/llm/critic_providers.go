@@ -0,0 +1,186 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// Critic providers selectable with CRITIC_PROVIDER
+const (
+	CriticProviderGemini = "gemini" // The agent's Gemini client (default)
+	CriticProviderOpenAI = "openai" // OpenAI or an OpenAI-compatible /chat/completions API
+)
+
+const (
+	defaultOpenAICriticModel = "gpt-4o"
+	defaultOpenAICriticURL   = "https://api.openai.com/v1"
+)
+
+// Critiquer reviews a lesson and proposes a patch plan
+type Critiquer interface {
+	CritiqueLesson(ctx context.Context, lessonJSON string) (*CritiqueResponse, error)
+}
+
+// ReviewerIdentity names the provider and model that critiqued a lesson
+type ReviewerIdentity struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+}
+
+// CriticConfig selects the model family the critic reviews lessons with. Reviewing with a
+// different model than the one that wrote the lesson reduces correlated blind spots.
+type CriticConfig struct {
+	Provider string
+	Model    string // "" keeps the session's model for gemini and uses gpt-4o for openai
+	URL      string // Base URL for the openai provider
+	APIKey   string
+}
+
+// CriticConfigFromEnv reads the critic provider from CRITIC_PROVIDER, CRITIC_MODEL, CRITIC_URL
+// and CRITIC_API_KEY (or OPENAI_API_KEY)
+func CriticConfigFromEnv() CriticConfig {
+	config := CriticConfig{
+		Provider: strings.ToLower(strings.TrimSpace(os.Getenv("CRITIC_PROVIDER"))),
+		Model:    strings.TrimSpace(os.Getenv("CRITIC_MODEL")),
+		URL:      os.Getenv("CRITIC_URL"),
+		APIKey:   os.Getenv("CRITIC_API_KEY"),
+	}
+	if config.Provider == "" {
+		config.Provider = CriticProviderGemini
+	}
+	if config.APIKey == "" && config.Provider == CriticProviderOpenAI {
+		config.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	return config
+}
+
+// NewCritiquer creates the reviewer for a critic configuration. The gemini provider reviews
+// with the given Gemini client.
+func NewCritiquer(config CriticConfig, gemini GeminiClientInterface) (Critiquer, error) {
+	switch config.Provider {
+	case "", CriticProviderGemini:
+		return gemini, nil
+	case CriticProviderOpenAI:
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("the openai critic provider needs CRITIC_API_KEY or OPENAI_API_KEY")
+		}
+		return NewOpenAICritic(config.URL, config.APIKey, config.Model), nil
+	default:
+		return nil, fmt.Errorf("unknown critic provider %q", config.Provider)
+	}
+}
+
+// OpenAICritic critiques lessons with OpenAI's /chat/completions API
+type OpenAICritic struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAICritic creates an OpenAI critic; empty values use the public API and gpt-4o
+func NewOpenAICritic(baseURL, apiKey, model string) *OpenAICritic {
+	if baseURL == "" {
+		baseURL = defaultOpenAICriticURL
+	}
+	if model == "" {
+		model = defaultOpenAICriticModel
+	}
+	return &OpenAICritic{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Model returns the model the critic reviews with
+func (c *OpenAICritic) Model() string {
+	return c.model
+}
+
+// CritiqueLesson implements Critiquer using the request-scoped rubric, if any. The model and
+// token counts are recorded on the context's ModelUsage like Gemini requests.
+func (c *OpenAICritic) CritiqueLesson(ctx context.Context, lessonJSON string) (*CritiqueResponse, error) {
+	request := map[string]interface{}{
+		"model": c.model,
+		"messages": []map[string]string{
+			{"role": "user", "content": appendPromptInstructions(ctx, critiquePrompt(lessonJSON, RubricFromContext(ctx)))},
+		},
+		"response_format": map[string]string{"type": "json_object"},
+	}
+	if DeterministicFromContext(ctx) {
+		request["temperature"] = deterministicTemperature
+		request["top_p"] = deterministicTopP
+	}
+
+	var response struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int32 `json:"prompt_tokens"`
+			CompletionTokens int32 `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := c.post(ctx, request, &response); err != nil {
+		return nil, err
+	}
+	recordTokens(ctx, &genai.UsageMetadata{
+		PromptTokenCount:     response.Usage.PromptTokens,
+		CandidatesTokenCount: response.Usage.CompletionTokens,
+	})
+	if len(response.Choices) == 0 {
+		return nil, ErrEmptyCandidates
+	}
+
+	critique, err := parseCritiqueText(response.Choices[0].Message.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse critique response: %w", err)
+	}
+	recordModel(ctx, c.model, false)
+	return critique, nil
+}
+
+// post sends a chat completion request and decodes the response
+func (c *OpenAICritic) post(ctx context.Context, request, out interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("chat completions API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse chat completions response: %w", err)
+	}
+	return nil
+}
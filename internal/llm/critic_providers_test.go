@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCriticConfigFromEnv tests reading the critic provider configuration
+func TestCriticConfigFromEnv(t *testing.T) {
+	t.Setenv("CRITIC_PROVIDER", "")
+	t.Setenv("CRITIC_MODEL", "")
+	config := CriticConfigFromEnv()
+	assert.Equal(t, CriticProviderGemini, config.Provider)
+
+	gemini := NewGeminiClient("test-key")
+	critiquer, err := NewCritiquer(config, gemini)
+	require.NoError(t, err)
+	assert.Same(t, gemini, critiquer)
+
+	t.Setenv("CRITIC_PROVIDER", "OpenAI")
+	t.Setenv("CRITIC_MODEL", "gpt-4.1")
+	t.Setenv("CRITIC_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	config = CriticConfigFromEnv()
+	assert.Equal(t, CriticProviderOpenAI, config.Provider)
+	assert.Equal(t, "sk-test", config.APIKey)
+	critiquer, err = NewCritiquer(config, gemini)
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4.1", critiquer.(*OpenAICritic).Model())
+
+	_, err = NewCritiquer(CriticConfig{Provider: CriticProviderOpenAI}, gemini)
+	assert.ErrorContains(t, err, "CRITIC_API_KEY")
+	_, err = NewCritiquer(CriticConfig{Provider: "claude"}, gemini)
+	assert.ErrorContains(t, err, "unknown critic provider")
+}
+
+// TestOpenAICritic tests critiquing with an OpenAI-compatible API and recording its usage
+func TestOpenAICritic(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Write([]byte(`{
+			"choices": [{"message": {"content": "{\"issues\": [{\"section\": \" metaphor \", \"problem\": \"Misleading\", \"severity\": \"high\", \"confidence\": 2}], \"patch_plan\": []}"}}],
+			"usage": {"prompt_tokens": 120, "completion_tokens": 30}
+		}`))
+	}))
+	defer server.Close()
+
+	critic := NewOpenAICritic(server.URL+"/v1/", "sk-test", "")
+	ctx, usage := WithModelUsage(WithDeterministic(context.Background()))
+	critique, err := critic.CritiqueLesson(ctx, `{"big_picture": "x"}`)
+	require.NoError(t, err)
+
+	require.Len(t, critique.Issues, 1)
+	assert.Equal(t, "metaphor", critique.Issues[0].Section)
+	assert.Equal(t, 1.0, critique.Issues[0].Confidence)
+	assert.Equal(t, "gpt-4o", request["model"])
+	assert.Equal(t, float64(0), request["temperature"])
+
+	assert.Equal(t, "gpt-4o", usage.Model())
+	input, output := usage.Tokens()
+	assert.Equal(t, 120, input)
+	assert.Equal(t, 30, output)
+}
+
+// TestOpenAICriticError tests that API errors are reported with the response body
+func TestOpenAICriticError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewOpenAICritic(server.URL, "sk-test", "gpt-4o-mini").CritiqueLesson(context.Background(), "{}")
+	assert.ErrorContains(t, err, "status 429: rate limited")
+}
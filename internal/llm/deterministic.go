@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/sirupsen/logrus"
+)
+
+// Sampling parameters used in deterministic mode
+const (
+	deterministicTemperature = 0
+	deterministicTopP        = 1
+	deterministicTopK        = 1
+)
+
+// deterministicContextKey is the context key for deterministic generation
+type deterministicContextKey struct{}
+
+// WithDeterministic returns a context whose Gemini requests use fixed sampling parameters and
+// are answered from recorded responses when the same request has been made before
+func WithDeterministic(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deterministicContextKey{}, true)
+}
+
+// DeterministicFromContext reports whether the context asks for deterministic generation
+func DeterministicFromContext(ctx context.Context) bool {
+	deterministic, _ := ctx.Value(deterministicContextKey{}).(bool)
+	return deterministic
+}
+
+// deterministicConfig returns a copy of config with temperature 0 and fixed topP and topK
+func deterministicConfig(config *genai.GenerationConfig) *genai.GenerationConfig {
+	fixed := &genai.GenerationConfig{}
+	if config != nil {
+		copied := *config
+		fixed = &copied
+	}
+	fixed.SetCandidateCount(1)
+	fixed.SetTemperature(deterministicTemperature)
+	fixed.SetTopP(deterministicTopP)
+	fixed.SetTopK(deterministicTopK)
+	return fixed
+}
+
+// ResponseRecorder stores model responses by request so deterministic requests are replayed
+// byte for byte. Recordings are kept in memory and, when a directory is set, on disk so they
+// survive restarts and can be checked in as test fixtures.
+type ResponseRecorder struct {
+	dir string
+
+	mu        sync.RWMutex
+	responses map[string]*GeminiResponse
+}
+
+// NewResponseRecorder creates a recorder; an empty dir keeps recordings in memory only
+func NewResponseRecorder(dir string) *ResponseRecorder {
+	return &ResponseRecorder{dir: dir, responses: make(map[string]*GeminiResponse)}
+}
+
+// defaultRecorder serves deterministic requests; LLM_RECORDINGS_DIR persists its recordings
+var defaultRecorder = NewResponseRecorder(os.Getenv("LLM_RECORDINGS_DIR"))
+
+// recordingKey identifies a request by its model, prompt and generation config
+func recordingKey(model, prompt string, config *genai.GenerationConfig) string {
+	configJSON, _ := json.Marshal(config)
+	sum := sha256.Sum256([]byte(model + "\x00" + prompt + "\x00" + string(configJSON)))
+	return hex.EncodeToString(sum[:])
+}
+
+// path returns the file holding a recording
+func (r *ResponseRecorder) path(key string) string {
+	return filepath.Join(r.dir, key+".json")
+}
+
+// Lookup returns the recorded response to a request, if any
+func (r *ResponseRecorder) Lookup(key string) (*GeminiResponse, bool) {
+	r.mu.RLock()
+	response, ok := r.responses[key]
+	r.mu.RUnlock()
+	if ok || r.dir == "" {
+		return response, ok
+	}
+
+	data, err := os.ReadFile(r.path(key))
+	if err != nil {
+		return nil, false
+	}
+	response = &GeminiResponse{}
+	if err := json.Unmarshal(data, response); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"key":   key,
+			"error": err,
+		}).Warn("Ignoring unreadable response recording")
+		return nil, false
+	}
+	r.mu.Lock()
+	r.responses[key] = response
+	r.mu.Unlock()
+	return response, true
+}
+
+// Record stores the response to a request
+func (r *ResponseRecorder) Record(key string, response *GeminiResponse) error {
+	r.mu.Lock()
+	r.responses[key] = response
+	r.mu.Unlock()
+	if r.dir == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+	if err := os.WriteFile(r.path(key), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
+
+// ArtifactPrefix returns the storage prefix for a session's generated artifacts. Deterministic
+// requests name artifacts after their content, so repeated runs produce the same names.
+func ArtifactPrefix(ctx context.Context, sessionID, content string) string {
+	if !DeterministicFromContext(ctx) {
+		return "sessions/" + sessionID
+	}
+	sum := sha256.Sum256([]byte(content))
+	return "deterministic/" + hex.EncodeToString(sum[:8])
+}
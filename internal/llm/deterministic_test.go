@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeterministicRequests tests fixed sampling and replay of recorded responses
+func TestDeterministicRequests(t *testing.T) {
+	dir := t.TempDir()
+	var configs []*genai.GenerationConfig
+	calls := 0
+	newClient := func() *GeminiClient {
+		client := &GeminiClient{model: DefaultModel, logger: logrus.New(), recorder: NewResponseRecorder(dir)}
+		client.generate = func(ctx context.Context, model string, prompt genai.Part, config *genai.GenerationConfig) (*genai.GenerateContentResponse, error) {
+			calls++
+			configs = append(configs, config)
+			return &genai.GenerateContentResponse{
+				Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text("answer " + string(rune('0'+calls)))}}}},
+			}, nil
+		}
+		return client
+	}
+	client := newClient()
+	ctx := WithDeterministic(context.Background())
+
+	first, err := client.executeRequest(ctx, "Explain caching")
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, float32(0), *configs[0].Temperature)
+	assert.Equal(t, float32(1), *configs[0].TopP)
+	assert.Equal(t, int32(1), *configs[0].TopK)
+
+	second, err := client.executeRequest(ctx, "Explain caching")
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, calls, "the recorded response is replayed")
+
+	// Recordings on disk survive a new client
+	third, err := newClient().executeRequest(ctx, "Explain caching")
+	require.NoError(t, err)
+	assert.Equal(t, first, third)
+	assert.Equal(t, 1, calls)
+
+	// Other prompts and non-deterministic requests go to the model
+	_, err = client.executeRequest(ctx, "Explain queues")
+	require.NoError(t, err)
+	_, err = client.executeRequest(context.Background(), "Explain caching")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Nil(t, configs[2], "sampling is left to the model outside deterministic mode")
+}
+
+// TestArtifactPrefix tests that deterministic requests name artifacts after their content
+func TestArtifactPrefix(t *testing.T) {
+	assert.Equal(t, "sessions/s1", ArtifactPrefix(context.Background(), "s1", "lesson"))
+
+	ctx := WithDeterministic(context.Background())
+	assert.Equal(t, ArtifactPrefix(ctx, "s1", "lesson"), ArtifactPrefix(ctx, "s2", "lesson"))
+	assert.NotEqual(t, ArtifactPrefix(ctx, "s1", "lesson"), ArtifactPrefix(ctx, "s1", "other lesson"))
+}
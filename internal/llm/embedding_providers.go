@@ -0,0 +1,316 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Embedding providers selectable with EMBEDDING_PROVIDER
+const (
+	EmbeddingProviderVertex = "vertex" // Vertex AI text embeddings (default)
+	EmbeddingProviderOpenAI = "openai" // OpenAI or an OpenAI-compatible /embeddings API
+	EmbeddingProviderHTTP   = "http"   // A local sentence-transformers or TEI server
+)
+
+const (
+	defaultVertexEmbeddingDimensions = 768
+	defaultOpenAIEmbeddingModel      = "text-embedding-3-small"
+	defaultOpenAIEmbeddingURL        = "https://api.openai.com/v1"
+)
+
+// openAIEmbeddingDimensions are the native sizes of OpenAI's embedding models
+var openAIEmbeddingDimensions = map[string]int{
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+	"text-embedding-ada-002": 1536,
+}
+
+// Embedder generates fixed-size embeddings for texts
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Dimensions returns the size of every embedding the model produces
+	Dimensions() int
+	// Model returns the embedding model name
+	Model() string
+}
+
+// EmbeddingConfig selects and configures an embedding provider
+type EmbeddingConfig struct {
+	Provider   string
+	Model      string
+	Dimensions int    // 0 uses the model's native size
+	URL        string // Base URL for the openai and http providers
+	APIKey     string
+	ProjectID  string // Vertex AI project
+	Location   string // Vertex AI location
+}
+
+// EmbeddingConfigFromEnv reads the embedding provider from EMBEDDING_PROVIDER, EMBEDDING_MODEL,
+// EMBEDDING_DIMENSIONS, EMBEDDING_URL and EMBEDDING_API_KEY (or OPENAI_API_KEY). Vertex AI
+// uses the given project and location.
+func EmbeddingConfigFromEnv(projectID, location string) (EmbeddingConfig, error) {
+	config := EmbeddingConfig{
+		Provider:  strings.ToLower(strings.TrimSpace(os.Getenv("EMBEDDING_PROVIDER"))),
+		Model:     os.Getenv("EMBEDDING_MODEL"),
+		URL:       os.Getenv("EMBEDDING_URL"),
+		APIKey:    os.Getenv("EMBEDDING_API_KEY"),
+		ProjectID: projectID,
+		Location:  location,
+	}
+	if config.Provider == "" {
+		config.Provider = EmbeddingProviderVertex
+	}
+	if config.APIKey == "" && config.Provider == EmbeddingProviderOpenAI {
+		config.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if raw := os.Getenv("EMBEDDING_DIMENSIONS"); raw != "" {
+		dimensions, err := strconv.Atoi(raw)
+		if err != nil || dimensions <= 0 {
+			return config, fmt.Errorf("invalid EMBEDDING_DIMENSIONS %q", raw)
+		}
+		config.Dimensions = dimensions
+	}
+	return config, nil
+}
+
+// NewEmbedder creates the embedder for a provider configuration. Servers that do not report
+// their embedding size are probed once so the size is known before any index is created.
+func NewEmbedder(ctx context.Context, config EmbeddingConfig) (Embedder, error) {
+	switch config.Provider {
+	case "", EmbeddingProviderVertex:
+		client := NewEmbeddingClient(config.ProjectID, config.Location)
+		client.SetModel(config.Model)
+		client.SetDimensions(config.Dimensions)
+		return client, nil
+	case EmbeddingProviderOpenAI:
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("the openai embedding provider needs EMBEDDING_API_KEY or OPENAI_API_KEY")
+		}
+		return NewOpenAIEmbedder(config.URL, config.APIKey, config.Model, config.Dimensions)
+	case EmbeddingProviderHTTP:
+		if config.URL == "" {
+			return nil, fmt.Errorf("the http embedding provider needs EMBEDDING_URL")
+		}
+		embedder := NewHTTPEmbedder(config.URL, config.Model, config.Dimensions)
+		embedder.apiKey = config.APIKey
+		if embedder.dimensions == 0 {
+			if err := embedder.probeDimensions(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return embedder, nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", config.Provider)
+	}
+}
+
+// checkEmbeddingDimensions returns an error if any embedding is not the expected size
+func checkEmbeddingDimensions(embeddings [][]float32, dimensions int) error {
+	for i, embedding := range embeddings {
+		if len(embedding) != dimensions {
+			return fmt.Errorf("embedding %d has %d dimensions, expected %d", i, len(embedding), dimensions)
+		}
+	}
+	return nil
+}
+
+// OpenAIEmbedder generates embeddings with OpenAI's /embeddings API
+type OpenAIEmbedder struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	dimensions int
+	reduced    bool // Whether to ask the API to shorten embeddings to dimensions
+	httpClient *http.Client
+}
+
+// NewOpenAIEmbedder creates an OpenAI embedder. Dimensions of 0 use the model's native size,
+// which must then be known; text-embedding-3 models can be shortened to a smaller size.
+func NewOpenAIEmbedder(baseURL, apiKey, model string, dimensions int) (*OpenAIEmbedder, error) {
+	if baseURL == "" {
+		baseURL = defaultOpenAIEmbeddingURL
+	}
+	if model == "" {
+		model = defaultOpenAIEmbeddingModel
+	}
+	native, known := openAIEmbeddingDimensions[model]
+	if dimensions == 0 {
+		if !known {
+			return nil, fmt.Errorf("unknown dimensions for embedding model %q, set EMBEDDING_DIMENSIONS", model)
+		}
+		dimensions = native
+	}
+	return &OpenAIEmbedder{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		dimensions: dimensions,
+		reduced:    dimensions != native,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Embed implements Embedder
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts provided")
+	}
+
+	request := map[string]interface{}{"model": e.model, "input": texts}
+	if e.reduced {
+		request["dimensions"] = e.dimensions
+	}
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := postEmbeddingJSON(ctx, e.httpClient, e.baseURL+"/embeddings", e.apiKey, request, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(response.Data))
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		embeddings[item.Index] = item.Embedding
+	}
+	if err := checkEmbeddingDimensions(embeddings, e.dimensions); err != nil {
+		return nil, err
+	}
+	return embeddings, nil
+}
+
+// Dimensions implements Embedder
+func (e *OpenAIEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// Model implements Embedder
+func (e *OpenAIEmbedder) Model() string {
+	return e.model
+}
+
+// HTTPEmbedder generates embeddings with a self-hosted server such as Hugging Face
+// text-embeddings-inference or a sentence-transformers wrapper. It POSTs {"inputs": [...]}
+// and accepts either a bare list of vectors or {"embeddings": [...]}.
+type HTTPEmbedder struct {
+	url        string
+	apiKey     string
+	model      string
+	dimensions int
+	httpClient *http.Client
+}
+
+// NewHTTPEmbedder creates an embedder for the server at url. Dimensions of 0 are learned
+// from the first response.
+func NewHTTPEmbedder(url, model string, dimensions int) *HTTPEmbedder {
+	return &HTTPEmbedder{
+		url:        url,
+		model:      model,
+		dimensions: dimensions,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Embed implements Embedder
+func (e *HTTPEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts provided")
+	}
+
+	request := map[string]interface{}{"inputs": texts}
+	if e.model != "" {
+		request["model"] = e.model
+	}
+	var raw json.RawMessage
+	if err := postEmbeddingJSON(ctx, e.httpClient, e.url, e.apiKey, request, &raw); err != nil {
+		return nil, err
+	}
+
+	var embeddings [][]float32
+	if err := json.Unmarshal(raw, &embeddings); err != nil {
+		var wrapped struct {
+			Embeddings [][]float32 `json:"embeddings"`
+		}
+		if err := json.Unmarshal(raw, &wrapped); err != nil {
+			return nil, fmt.Errorf("failed to parse embedding response: %w", err)
+		}
+		embeddings = wrapped.Embeddings
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embeddings))
+	}
+	if e.dimensions == 0 {
+		e.dimensions = len(embeddings[0])
+	}
+	if err := checkEmbeddingDimensions(embeddings, e.dimensions); err != nil {
+		return nil, err
+	}
+	return embeddings, nil
+}
+
+// probeDimensions embeds a short text to learn the server's embedding size
+func (e *HTTPEmbedder) probeDimensions(ctx context.Context) error {
+	if _, err := e.Embed(ctx, []string{"dimension probe"}); err != nil {
+		return fmt.Errorf("failed to probe embedding dimensions from %s: %w", e.url, err)
+	}
+	return nil
+}
+
+// Dimensions implements Embedder
+func (e *HTTPEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// Model implements Embedder
+func (e *HTTPEmbedder) Model() string {
+	return e.model
+}
+
+// postEmbeddingJSON POSTs a JSON request and decodes a successful JSON response into out
+func postEmbeddingJSON(ctx context.Context, client *http.Client, url, apiKey string, request, out interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("embedding API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse embedding response: %w", err)
+	}
+	return nil
+}
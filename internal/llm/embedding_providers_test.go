@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEmbeddingConfigFromEnv tests reading the embedding provider configuration
+func TestEmbeddingConfigFromEnv(t *testing.T) {
+	t.Setenv("EMBEDDING_PROVIDER", "")
+	t.Setenv("EMBEDDING_DIMENSIONS", "")
+	config, err := EmbeddingConfigFromEnv("project", "europe-west1")
+	require.NoError(t, err)
+	assert.Equal(t, EmbeddingProviderVertex, config.Provider)
+
+	embedder, err := NewEmbedder(context.Background(), config)
+	require.NoError(t, err)
+	assert.Equal(t, 768, embedder.Dimensions())
+	assert.Equal(t, "text-embedding-004", embedder.Model())
+
+	t.Setenv("EMBEDDING_PROVIDER", "OpenAI")
+	t.Setenv("EMBEDDING_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	t.Setenv("EMBEDDING_DIMENSIONS", "256")
+	config, err = EmbeddingConfigFromEnv("", "")
+	require.NoError(t, err)
+	assert.Equal(t, EmbeddingProviderOpenAI, config.Provider)
+	assert.Equal(t, "sk-test", config.APIKey)
+	assert.Equal(t, 256, config.Dimensions)
+
+	t.Setenv("EMBEDDING_DIMENSIONS", "many")
+	_, err = EmbeddingConfigFromEnv("", "")
+	assert.Error(t, err)
+
+	_, err = NewEmbedder(context.Background(), EmbeddingConfig{Provider: "cohere"})
+	assert.ErrorContains(t, err, "unknown embedding provider")
+	_, err = NewEmbedder(context.Background(), EmbeddingConfig{Provider: EmbeddingProviderHTTP})
+	assert.ErrorContains(t, err, "EMBEDDING_URL")
+}
+
+// TestOpenAIEmbedder tests embedding with an OpenAI-compatible API
+func TestOpenAIEmbedder(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		// Results may arrive out of order; the index says which input they belong to
+		w.Write([]byte(`{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer server.Close()
+
+	embedder, err := NewOpenAIEmbedder(server.URL+"/v1", "sk-test", "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "text-embedding-3-small", embedder.Model())
+
+	embeddings, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, embeddings)
+	assert.Equal(t, float64(2), request["dimensions"])
+
+	// Embeddings that do not match the configured size are rejected
+	embedder.dimensions = 3
+	_, err = embedder.Embed(context.Background(), []string{"a", "b"})
+	assert.ErrorContains(t, err, "has 2 dimensions, expected 3")
+
+	_, err = NewOpenAIEmbedder("", "sk-test", "custom-model", 0)
+	assert.ErrorContains(t, err, "EMBEDDING_DIMENSIONS")
+}
+
+// TestHTTPEmbedderProbesDimensions tests learning the size of a local server's embeddings
+func TestHTTPEmbedderProbesDimensions(t *testing.T) {
+	wrapped := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Inputs []string `json:"inputs"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		vectors := make([][]float32, len(request.Inputs))
+		for i := range vectors {
+			vectors[i] = []float32{0.1, 0.2, 0.3}
+		}
+		if wrapped {
+			json.NewEncoder(w).Encode(map[string]interface{}{"embeddings": vectors})
+			return
+		}
+		json.NewEncoder(w).Encode(vectors)
+	}))
+	defer server.Close()
+
+	embedder, err := NewEmbedder(context.Background(), EmbeddingConfig{Provider: EmbeddingProviderHTTP, URL: server.URL, Model: "all-MiniLM-L6-v2"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, embedder.Dimensions())
+
+	wrapped = true
+	embeddings, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Len(t, embeddings, 2)
+
+	// Embeddings that disagree with the configured size are rejected
+	mismatched := NewHTTPEmbedder(server.URL, "", 384)
+	_, err = mismatched.Embed(context.Background(), []string{"a"})
+	assert.ErrorContains(t, err, "expected 384")
+}
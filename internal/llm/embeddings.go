@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/InnoFusionTech/ExplainIQ/internal/tokens"
 	"github.com/sirupsen/logrus"
 )
 
@@ -26,6 +27,7 @@ type EmbeddingClient struct {
 	retryDelay   time.Duration
 	batchSize    int
 	maxTokens    int
+	dimensions   int
 }
 
 // EmbeddingRequest represents a request to the Vertex AI embeddings API
@@ -98,6 +100,7 @@ func NewEmbeddingClient(projectID, location string) *EmbeddingClient {
 		retryDelay: 1 * time.Second,
 		batchSize:  5, // Vertex AI text-embedding-004 supports up to 5 texts per request
 		maxTokens:  3072, // Maximum tokens per text
+		dimensions: defaultVertexEmbeddingDimensions,
 	}
 }
 
@@ -159,7 +162,7 @@ func (c *EmbeddingClient) embedBatch(ctx context.Context, texts []string) ([][]f
 	request := EmbeddingRequest{
 		Instances: instances,
 		Parameters: EmbeddingParameters{
-			OutputDimensionality: 768, // text-embedding-004 default output dimension
+			OutputDimensionality: c.dimensions,
 		},
 	}
 
@@ -207,6 +210,9 @@ func (c *EmbeddingClient) embedBatch(ctx context.Context, texts []string) ([][]f
 	for i, prediction := range response.Predictions {
 		embeddings[i] = prediction.Embeddings.Values
 	}
+	if err := checkEmbeddingDimensions(embeddings, c.dimensions); err != nil {
+		return nil, err
+	}
 
 	return embeddings, nil
 }
@@ -296,8 +302,8 @@ func (c *EmbeddingClient) validateTexts(texts []string) error {
 			return fmt.Errorf("text at index %d is empty", i)
 		}
 		
-		// Estimate token count (rough approximation: 1 token ≈ 4 characters)
-		estimatedTokens := len(text) / 4
+		// Estimate token count for the embedding model's tokenizer
+		estimatedTokens := tokens.Estimate(c.model, text)
 		if estimatedTokens > c.maxTokens {
 			return fmt.Errorf("text at index %d exceeds maximum token limit (%d tokens estimated, max %d)",
 				i, estimatedTokens, c.maxTokens)
@@ -361,6 +367,30 @@ func (c *EmbeddingClient) SetMaxTokens(tokens int) {
 	}
 }
 
+// SetModel sets the Vertex AI embedding model
+func (c *EmbeddingClient) SetModel(model string) {
+	if model != "" {
+		c.model = model
+	}
+}
+
+// SetDimensions sets the output dimensionality requested from the model
+func (c *EmbeddingClient) SetDimensions(dimensions int) {
+	if dimensions > 0 {
+		c.dimensions = dimensions
+	}
+}
+
+// Dimensions returns the size of each embedding
+func (c *EmbeddingClient) Dimensions() int {
+	return c.dimensions
+}
+
+// Model returns the embedding model name
+func (c *EmbeddingClient) Model() string {
+	return c.model
+}
+
 // GetModelInfo returns information about the embedding model
 func (c *EmbeddingClient) GetModelInfo() map[string]interface{} {
 	return map[string]interface{}{
@@ -369,7 +399,7 @@ func (c *EmbeddingClient) GetModelInfo() map[string]interface{} {
 		"location":            c.location,
 		"max_batch_size":      c.batchSize,
 		"max_tokens_per_text": c.maxTokens,
-		"output_dimensions":   768,
+		"output_dimensions":   c.dimensions,
 		"max_retries":         c.maxRetries,
 		"retry_delay":         c.retryDelay,
 	}
@@ -0,0 +1,158 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrEmptyCandidates is returned when a model responds without any text candidates
+var ErrEmptyCandidates = errors.New("no candidates in response")
+
+// fallbackModelsFromEnv reads the fallback chain from GEMINI_FALLBACK_MODELS,
+// a comma-separated list such as "gemini-1.5-flash,gemini-1.5-pro". Unset means no fallback.
+func fallbackModelsFromEnv() []string {
+	return parseModelList(os.Getenv("GEMINI_FALLBACK_MODELS"))
+}
+
+// parseModelList splits a comma-separated model list, dropping blanks
+func parseModelList(spec string) []string {
+	var models []string
+	for _, model := range strings.Split(spec, ",") {
+		if model = strings.TrimSpace(model); model != "" {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// SetFallbackModels sets the models retried, in order, when the primary model's
+// response is blocked for safety, fails with a server error or is empty
+func (c *GeminiClient) SetFallbackModels(models []string) {
+	c.fallbackModels = append([]string(nil), models...)
+}
+
+// FallbackModels returns the configured fallback chain
+func (c *GeminiClient) FallbackModels() []string {
+	return append([]string(nil), c.fallbackModels...)
+}
+
+// modelChain returns the primary model followed by the fallback models, without duplicates
+func (c *GeminiClient) modelChain(primary string) []string {
+	chain := []string{primary}
+	seen := map[string]bool{primary: true}
+	for _, model := range c.fallbackModels {
+		if !seen[model] {
+			seen[model] = true
+			chain = append(chain, model)
+		}
+	}
+	return chain
+}
+
+// isFallbackError reports whether a failed request should be retried on the next model:
+// safety blocks, server errors and empty candidates. Client errors such as invalid
+// requests or quota exhaustion would fail the same way on every model.
+func isFallbackError(err error) bool {
+	var blocked *genai.BlockedError
+	if errors.As(err, &blocked) || errors.Is(err, ErrEmptyCandidates) {
+		return true
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= 500
+	}
+
+	if st, ok := status.FromError(errors.Unwrap(err)); ok {
+		switch st.Code() {
+		case codes.Internal, codes.Unavailable, codes.Unknown, codes.DataLoss:
+			return true
+		}
+	}
+	return false
+}
+
+// ModelUsage records which models served the requests made with a context and the tokens they used
+type ModelUsage struct {
+	mu           sync.Mutex
+	model        string
+	fallbacks    int
+	inputTokens  int
+	outputTokens int
+}
+
+// Model returns the model that served the most recent successful request, or ""
+func (u *ModelUsage) Model() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.model
+}
+
+// Fallbacks returns how many successful requests were served by a fallback model
+func (u *ModelUsage) Fallbacks() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.fallbacks
+}
+
+// Tokens returns the prompt and output tokens reported across all requests, including failed ones
+func (u *ModelUsage) Tokens() (input, output int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.inputTokens, u.outputTokens
+}
+
+// Metrics adds the recorded model, fallback count and token counts to task metrics
+func (u *ModelUsage) Metrics(metrics map[string]interface{}) {
+	if model := u.Model(); model != "" {
+		metrics["model"] = model
+		metrics["model_fallbacks"] = u.Fallbacks()
+	}
+	if input, output := u.Tokens(); input > 0 || output > 0 {
+		metrics["input_tokens"] = input
+		metrics["output_tokens"] = output
+	}
+}
+
+// modelUsageContextKey is the context key for a ModelUsage recorder
+type modelUsageContextKey struct{}
+
+// WithModelUsage returns a context that records the models serving its requests
+func WithModelUsage(ctx context.Context) (context.Context, *ModelUsage) {
+	usage := &ModelUsage{}
+	return context.WithValue(ctx, modelUsageContextKey{}, usage), usage
+}
+
+// recordTokens adds a response's token counts to the context's ModelUsage, if any
+func recordTokens(ctx context.Context, metadata *genai.UsageMetadata) {
+	usage, ok := ctx.Value(modelUsageContextKey{}).(*ModelUsage)
+	if !ok || metadata == nil {
+		return
+	}
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	usage.inputTokens += int(metadata.PromptTokenCount)
+	usage.outputTokens += int(metadata.CandidatesTokenCount)
+}
+
+// recordModel records a successful request on the context's ModelUsage, if any
+func recordModel(ctx context.Context, model string, fallback bool) {
+	usage, ok := ctx.Value(modelUsageContextKey{}).(*ModelUsage)
+	if !ok {
+		return
+	}
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	usage.model = model
+	if fallback {
+		usage.fallbacks++
+	}
+}
@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/tokens"
+	"github.com/google/generative-ai-go/genai"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+)
+
+// newFallbackTestClient creates a client whose requests are answered per model by responses
+func newFallbackTestClient(fallbacks []string, responses map[string]error, calls *[]string) *GeminiClient {
+	client := &GeminiClient{model: DefaultModel, logger: logrus.New(), fallbackModels: fallbacks}
+	client.generate = func(ctx context.Context, model string, prompt genai.Part, config *genai.GenerationConfig) (*genai.GenerateContentResponse, error) {
+		*calls = append(*calls, model)
+		if err, ok := responses[model]; ok {
+			if errors.Is(err, ErrEmptyCandidates) {
+				return &genai.GenerateContentResponse{}, nil
+			}
+			return nil, err
+		}
+		return &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text("ok from " + model)}}}},
+		}, nil
+	}
+	return client
+}
+
+// TestModelFallbackChain tests that blocked, failing and empty responses move down the chain
+func TestModelFallbackChain(t *testing.T) {
+	var calls []string
+	client := newFallbackTestClient(
+		[]string{"gemini-1.5-flash", "gemini-1.5-pro"},
+		map[string]error{
+			DefaultModel:       &genai.BlockedError{Candidate: &genai.Candidate{FinishReason: genai.FinishReasonSafety}},
+			"gemini-1.5-flash": ErrEmptyCandidates,
+		},
+		&calls,
+	)
+
+	ctx, usage := WithModelUsage(context.Background())
+	response, err := client.executeRequest(ctx, "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, "ok from gemini-1.5-pro", response.Candidates[0].Content.Parts[0].Text)
+	assert.Equal(t, []string{DefaultModel, "gemini-1.5-flash", "gemini-1.5-pro"}, calls)
+	assert.Equal(t, "gemini-1.5-pro", usage.Model())
+	assert.Equal(t, 1, usage.Fallbacks())
+
+	metrics := map[string]interface{}{}
+	usage.Metrics(metrics)
+	assert.Equal(t, "gemini-1.5-pro", metrics["model"])
+	assert.Equal(t, 1, metrics["model_fallbacks"])
+}
+
+// TestModelFallbackServerError tests that 5xx errors fall back and client errors do not
+func TestModelFallbackServerError(t *testing.T) {
+	var calls []string
+	client := newFallbackTestClient(
+		[]string{"gemini-1.5-flash"},
+		map[string]error{DefaultModel: &googleapi.Error{Code: 503}},
+		&calls,
+	)
+	ctx, usage := WithModelUsage(context.Background())
+	_, err := client.executeRequest(ctx, "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, "gemini-1.5-flash", usage.Model())
+
+	calls = nil
+	client = newFallbackTestClient(
+		[]string{"gemini-1.5-flash"},
+		map[string]error{DefaultModel: &googleapi.Error{Code: 400}},
+		&calls,
+	)
+	_, err = client.executeRequest(context.Background(), "prompt")
+	assert.Error(t, err)
+	assert.Equal(t, []string{DefaultModel}, calls)
+}
+
+// TestModelFallbackExhausted tests that the last model's error is returned when every model fails
+func TestModelFallbackExhausted(t *testing.T) {
+	var calls []string
+	client := newFallbackTestClient(
+		[]string{"gemini-1.5-flash"},
+		map[string]error{DefaultModel: ErrEmptyCandidates, "gemini-1.5-flash": ErrEmptyCandidates},
+		&calls,
+	)
+	ctx, usage := WithModelUsage(context.Background())
+	_, err := client.executeRequest(ctx, "prompt")
+	assert.ErrorIs(t, err, ErrEmptyCandidates)
+	assert.Len(t, calls, 2)
+	assert.Empty(t, usage.Model())
+}
+
+// TestModelChain tests the override model leads the chain without duplicates
+func TestModelChain(t *testing.T) {
+	client := &GeminiClient{fallbackModels: parseModelList(" gemini-1.5-flash, ,gemini-1.5-pro,gemini-1.5-flash")}
+	assert.Equal(t, []string{"gemini-1.5-pro", "gemini-1.5-flash"}, client.modelChain("gemini-1.5-pro"))
+	assert.Equal(t, []string{DefaultModel, "gemini-1.5-flash", "gemini-1.5-pro"}, client.modelChain(DefaultModel))
+}
+
+// TestModelUsageTokens tests that token counts from every response are reported in task metrics
+func TestModelUsageTokens(t *testing.T) {
+	client := &GeminiClient{model: DefaultModel, logger: logrus.New()}
+	client.generate = func(ctx context.Context, model string, prompt genai.Part, config *genai.GenerationConfig) (*genai.GenerateContentResponse, error) {
+		return &genai.GenerateContentResponse{
+			Candidates:    []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text("ok")}}}},
+			UsageMetadata: &genai.UsageMetadata{PromptTokenCount: 120, CandidatesTokenCount: 30, TotalTokenCount: 150},
+		}, nil
+	}
+
+	ctx, usage := WithModelUsage(context.Background())
+	for i := 0; i < 2; i++ {
+		_, err := client.executeRequest(ctx, "prompt")
+		require.NoError(t, err)
+	}
+	input, output := usage.Tokens()
+	assert.Equal(t, 240, input)
+	assert.Equal(t, 60, output)
+
+	metrics := map[string]interface{}{}
+	usage.Metrics(metrics)
+	assert.Equal(t, 240, metrics["input_tokens"])
+	assert.Equal(t, 60, metrics["output_tokens"])
+}
+
+// TestModelUsageTokensEstimated tests that responses without usage metadata are counted by estimate
+func TestModelUsageTokensEstimated(t *testing.T) {
+	client := &GeminiClient{model: DefaultModel, logger: logrus.New()}
+	client.generate = func(ctx context.Context, model string, prompt genai.Part, config *genai.GenerationConfig) (*genai.GenerateContentResponse, error) {
+		return &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text("Caches keep hot data close")}}}},
+		}, nil
+	}
+
+	ctx, usage := WithModelUsage(context.Background())
+	_, err := client.executeRequest(ctx, "Explain caching to a beginner")
+	require.NoError(t, err)
+	input, output := usage.Tokens()
+	assert.Equal(t, tokens.Estimate(DefaultModel, "Explain caching to a beginner"), input)
+	assert.Equal(t, tokens.Estimate(DefaultModel, "Caches keep hot data close"), output)
+	assert.Positive(t, output)
+}
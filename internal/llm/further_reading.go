@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MinFurtherReading is the fewest further-reading links requested for a lesson
+	MinFurtherReading = 3
+	// MaxFurtherReading is the most further-reading links kept for a lesson
+	MaxFurtherReading = 5
+)
+
+// ReadingLink is a further-reading reference suggested for a lesson
+type ReadingLink struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"` // Why the reference is worth reading
+}
+
+// SuggestFurtherReading asks the model for MinFurtherReading to MaxFurtherReading references that
+// extend a lesson. The links are unverified: models invent plausible URLs, so callers must check
+// that each one resolves before showing it.
+func (c *GeminiClient) SuggestFurtherReading(ctx context.Context, topic, lesson string) ([]ReadingLink, error) {
+	c.logger.WithFields(logrus.Fields{
+		"topic": topic,
+		"model": c.model,
+	}).Info("Suggesting further reading with Gemini")
+
+	response, err := c.executeRequest(ctx, buildFurtherReadingPrompt(topic, lesson))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute further reading request: %w", err)
+	}
+	if len(response.Candidates) == 0 {
+		return nil, fmt.Errorf("no candidates in response")
+	}
+
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return parseFurtherReading(text.String())
+}
+
+// buildFurtherReadingPrompt creates the prompt asking for references that extend the lesson
+func buildFurtherReadingPrompt(topic, lesson string) string {
+	var promptBuilder strings.Builder
+	promptBuilder.WriteString("You are an expert educator recommending further reading after a lesson.\n\n")
+	promptBuilder.WriteString(fmt.Sprintf("Topic: %s\n\n", topic))
+	if lesson = strings.TrimSpace(lesson); lesson != "" {
+		promptBuilder.WriteString("Lesson:\n")
+		promptBuilder.WriteString(lesson)
+		promptBuilder.WriteString("\n\n")
+	}
+	promptBuilder.WriteString(fmt.Sprintf("Recommend %d to %d references a learner should read next. ", MinFurtherReading, MaxFurtherReading))
+	promptBuilder.WriteString("Prefer official documentation, specifications, well-known textbooks' companion sites and long-lived articles. ")
+	promptBuilder.WriteString("Only give URLs you are confident exist; do not guess paths.\n\n")
+	promptBuilder.WriteString(`Respond with a JSON array of objects with the fields "title", "url" and "description" (one sentence).`)
+	promptBuilder.WriteString("\n\nYour JSON response:\n")
+	return promptBuilder.String()
+}
+
+// parseFurtherReading extracts the links from the response text, dropping entries without a
+// title, non-web URLs and duplicates
+func parseFurtherReading(responseText string) ([]ReadingLink, error) {
+	jsonStart := strings.Index(responseText, "[")
+	jsonEnd := strings.LastIndex(responseText, "]")
+	if jsonStart == -1 || jsonEnd <= jsonStart {
+		return nil, fmt.Errorf("no JSON array found in response")
+	}
+
+	var raw []ReadingLink
+	if err := json.Unmarshal([]byte(responseText[jsonStart:jsonEnd+1]), &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal further reading: %w", err)
+	}
+
+	links := make([]ReadingLink, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, item := range raw {
+		link := ReadingLink{
+			Title:       strings.TrimSpace(item.Title),
+			URL:         strings.TrimSpace(item.URL),
+			Description: strings.TrimSpace(item.Description),
+		}
+		if link.Title == "" || !IsWebURL(link.URL) || seen[link.URL] {
+			continue
+		}
+		seen[link.URL] = true
+		links = append(links, link)
+		if len(links) == MaxFurtherReading {
+			break
+		}
+	}
+	if len(links) == 0 {
+		return nil, fmt.Errorf("no usable further reading links in response")
+	}
+	return links, nil
+}
+
+// IsWebURL reports whether raw is an absolute http or https URL with a host
+func IsWebURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.User == nil
+}
@@ -0,0 +1,32 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseFurtherReading tests that only titled, distinct web links are kept
+func TestParseFurtherReading(t *testing.T) {
+	response := "Sure:\n```json\n[" +
+		`{"title":" Effective Go ","url":"https://go.dev/doc/effective_go","description":"Idiomatic Go."},` +
+		`{"title":"Duplicate","url":"https://go.dev/doc/effective_go"},` +
+		`{"title":"Script","url":"javascript:alert(1)"},` +
+		`{"title":"Relative","url":"/doc/faq"},` +
+		`{"title":"","url":"https://go.dev/ref/spec"},` +
+		`{"title":"Go Memory Model","url":"https://go.dev/ref/mem"}` +
+		"]\n```"
+
+	links, err := parseFurtherReading(response)
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	assert.Equal(t, ReadingLink{Title: "Effective Go", URL: "https://go.dev/doc/effective_go", Description: "Idiomatic Go."}, links[0])
+	assert.Equal(t, "https://go.dev/ref/mem", links[1].URL)
+
+	_, err = parseFurtherReading(`[{"title":"Mail","url":"mailto:a@b.c"}]`)
+	assert.Error(t, err, "a response with no usable links is an error")
+	_, err = parseFurtherReading("no json")
+	assert.Error(t, err)
+	assert.Contains(t, buildFurtherReadingPrompt("Go", ""), "3 to 5 references")
+}
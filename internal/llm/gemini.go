@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"strings"
 
@@ -28,11 +29,15 @@ type GeminiClientInterface interface {
 // ModelsWrapper wraps the genai client to provide Models.GenerateContent interface
 type ModelsWrapper struct {
 	client *genai.Client
+	vertex *vertexBackend // Serves requests through Vertex AI instead of client when set
 }
 
 // GenerateContent wraps the SDK call to match the requested format:
 // client.Models.GenerateContent(ctx, "gemini-2.5-flash", genai.Text(prompt), nil)
 func (m *ModelsWrapper) GenerateContent(ctx context.Context, modelName string, prompt genai.Part, config *genai.GenerationConfig) (*genai.GenerateContentResponse, error) {
+	if m.vertex != nil {
+		return m.vertex.generateContent(ctx, modelName, prompt, config, nil, nil)
+	}
 	model := m.client.GenerativeModel(modelName)
 	if config != nil {
 		model.GenerationConfig = *config
@@ -50,6 +55,16 @@ type GeminiClient struct {
 	logger  *logrus.Logger
 	baseURL string // For testing only - not used with official SDK
 	apiKey  string // For testing only - tracks the API key used
+	backend string // BackendAIStudio or BackendVertex
+
+	freeTextOnly   bool     // Disables responseSchema structured output
+	fallbackModels []string // Models retried in order when a request fails with a fallback error
+	recorder       *ResponseRecorder // Replays deterministic requests; nil uses the process-wide recorder
+
+	// generate overrides Models.GenerateContent; used by tests
+	generate func(ctx context.Context, model string, prompt genai.Part, config *genai.GenerationConfig) (*genai.GenerateContentResponse, error)
+	// generateToolCall overrides Models.GenerateToolCall; used by tests
+	generateToolCall func(ctx context.Context, model string, prompt genai.Part, tools []*genai.Tool, toolConfig *genai.ToolConfig) (*genai.GenerateContentResponse, error)
 }
 
 // GeminiRequest represents a request to the Gemini API
@@ -130,6 +145,8 @@ type OGLesson struct {
 	MemoryHook     string `json:"memory_hook"`      // Mnemonic device or memorable phrase
 	RealLife       string `json:"real_life"`        // Real-world applications and examples
 	BestPractices  string `json:"best_practices"`   // Key do's and don'ts
+
+	Scratchpad string `json:"-"` // Private reasoning written before the lesson when requested; never serialized
 }
 
 // CritiqueIssue represents an issue found in a lesson
@@ -137,6 +154,9 @@ type CritiqueIssue struct {
 	Section  string `json:"section"`  // The section of the lesson (e.g., "big_picture", "metaphor")
 	Problem  string `json:"problem"`  // Description of the problem
 	Severity string `json:"severity"` // Severity level: "low", "medium", "high", "critical"
+
+	Confidence float64 `json:"confidence,omitempty"` // How sure the critic is that the issue is real, from 0 to 1
+	Output     string  `json:"output,omitempty"`     // What running the code printed, e.g. a compiler or sandbox error, when it was run
 }
 
 // PatchPlanItem represents a specific change to be made to a lesson
@@ -154,9 +174,10 @@ type CritiqueResponse struct {
 
 // ImageRef represents a reference to a generated image
 type ImageRef struct {
-	URL     string `json:"url"`      // Signed URL to the image
-	AltText string `json:"alt_text"` // Alt text for accessibility
-	Caption string `json:"caption"`  // Caption describing the image
+	URL             string `json:"url"`                        // Signed URL to the image
+	AltText         string `json:"alt_text"`                   // Alt text for accessibility
+	Caption         string `json:"caption"`                    // Caption describing the image
+	LongDescription string `json:"long_description,omitempty"` // Extended description for screen readers (WCAG 1.1.1)
 }
 
 // VisualizeResponse represents the response from visualization
@@ -168,20 +189,61 @@ type VisualizeResponse struct {
 // NewGeminiClient creates a new Gemini client using the official SDK
 // Supports both API key authentication and Application Default Credentials (ADC)
 // If apiKey is empty, it will try to use GEMINI_API_KEY env var, or fall back to ADC (nil)
+// The backend and its settings are read from the environment (see GeminiConfigFromEnv);
+// an explicit apiKey always selects AI Studio
 func NewGeminiClient(apiKey string) *GeminiClient {
+	cfg := GeminiConfigFromEnv()
+	if apiKey != "" {
+		cfg.Backend = BackendAIStudio
+		cfg.APIKey = apiKey
+	}
+	return NewGeminiClientWithConfig(cfg)
+}
+
+// NewGeminiClientWithConfig creates a Gemini client for the configured backend: AI Studio with
+// an API key or ADC, or Vertex AI in a project and region with a service account
+func NewGeminiClientWithConfig(cfg GeminiConfig) *GeminiClient {
 	var client *genai.Client
 	var err error
 	ctx := context.Background()
 
-	// Try to get API key from environment if not provided
-	if apiKey == "" {
-		apiKey = os.Getenv("GEMINI_API_KEY")
+	if cfg.Backend == BackendVertex {
+		vertex, err := newVertexBackend(ctx, cfg)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to create Gemini client for Vertex AI")
+			return &GeminiClient{
+				client:  nil,
+				Models:  nil,
+				model:   "gemini-2.5-flash",
+				logger:  logrus.New(),
+				backend: BackendVertex,
+			}
+		}
+		logrus.WithFields(logrus.Fields{
+			"project":   vertex.project,
+			"locations": vertex.locations,
+		}).Debug("Gemini client initialized for Vertex AI")
+		return &GeminiClient{
+			Models:  &ModelsWrapper{vertex: vertex},
+			model:   "gemini-2.5-flash",
+			logger:  logrus.New(),
+			backend: BackendVertex,
+
+			freeTextOnly:   !structuredOutputFromEnv(),
+			fallbackModels: fallbackModelsFromEnv(),
+		}
+	}
+
+	apiKey := cfg.APIKey
+	var opts []option.ClientOption
+	if cfg.QuotaProject != "" {
+		opts = append(opts, option.WithQuotaProject(cfg.QuotaProject))
 	}
 
 	// Initialize client with API key if available, otherwise use ADC (nil)
 	if apiKey != "" {
 		// Use API key authentication
-		client, err = genai.NewClient(ctx, option.WithAPIKey(apiKey))
+		client, err = genai.NewClient(ctx, append(opts, option.WithAPIKey(apiKey))...)
 		if err != nil {
 			logrus.WithError(err).Error("Failed to create Gemini client with API key")
 			return &GeminiClient{
@@ -194,7 +256,7 @@ func NewGeminiClient(apiKey string) *GeminiClient {
 		logrus.Debug("Gemini client initialized with API key")
 	} else {
 		// Use Application Default Credentials (ADC) - like genai.NewClient(ctx, nil)
-		client, err = genai.NewClient(ctx, nil)
+		client, err = genai.NewClient(ctx, opts...)
 		if err != nil {
 			logrus.WithError(err).Error("Failed to create Gemini client with ADC")
 			return &GeminiClient{
@@ -208,11 +270,15 @@ func NewGeminiClient(apiKey string) *GeminiClient {
 	}
 
 	return &GeminiClient{
-		client: client,
-		Models: &ModelsWrapper{client: client},
-		model:  "gemini-2.5-flash",
-		logger: logrus.New(),
-		apiKey: apiKey, // Store for testing purposes
+		client:  client,
+		Models:  &ModelsWrapper{client: client},
+		model:   "gemini-2.5-flash",
+		logger:  logrus.New(),
+		apiKey:  apiKey, // Store for testing purposes
+		backend: BackendAIStudio,
+
+		freeTextOnly:   !structuredOutputFromEnv(),
+		fallbackModels: fallbackModelsFromEnv(),
 	}
 }
 
@@ -225,18 +291,24 @@ func (c *GeminiClient) Summarize(ctx context.Context, topic, context string) (*S
 	}).Info("Starting summarization")
 
 	// Create the prompt
-	prompt := c.createSummarizePrompt(topic, context)
+	prompt := appendPromptInstructions(ctx, c.createSummarizePrompt(topic, context, PersonaFromContext(ctx)))
 
-	// Execute the request using the SDK
-	response, err := c.executeRequest(ctx, prompt)
+	// Execute the request using the SDK, constraining output to the summary schema when supported
+	response, structured, err := c.executeJSONRequest(ctx, prompt, summarizeResponseSchema)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute summarization request: %w", err)
 	}
 
-	// Parse the response
-	result, err := c.parseSummarizeResponse(response)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse summarization response: %w", err)
+	// Parse the response, falling back to extracting JSON from free text
+	var result *SummarizeResponse
+	if structured {
+		result = c.decodeStructuredSummarizeResponse(response)
+	}
+	if result == nil {
+		result, err = c.parseSummarizeResponse(response)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse summarization response: %w", err)
+		}
 	}
 
 	c.logger.WithFields(logrus.Fields{
@@ -251,7 +323,14 @@ func (c *GeminiClient) Summarize(ctx context.Context, topic, context string) (*S
 }
 
 // createSummarizePrompt creates the prompt for summarization
-func (c *GeminiClient) createSummarizePrompt(topic, context string) string {
+// A nil persona produces a prompt for a general audience
+func (c *GeminiClient) createSummarizePrompt(topic, context string, persona *Persona) string {
+	var audience strings.Builder
+	writePersona(&audience, persona)
+	if persona != nil {
+		audience.WriteString("Choose outline points, prerequisites and misconceptions that fit this audience.\n\n")
+	}
+
 	return fmt.Sprintf(`You are an expert educational content summarizer. Analyze the provided context about "%s" and create a comprehensive summary.
 
 Context:
@@ -275,26 +354,96 @@ Requirements:
 - Keep each bullet point under 100 characters
 - Maximum 10 items per array
 
-Topic: %s`, topic, context, topic)
+%sTopic: %s`, topic, UntrustedContextBlock(context), audience.String(), topic)
 }
 
 // executeRequest executes a request to the Gemini API using the official SDK
 // Uses the requested format: client.Models.GenerateContent(ctx, "gemini-2.5-flash", genai.Text(prompt), nil)
 func (c *GeminiClient) executeRequest(ctx context.Context, prompt string) (*GeminiResponse, error) {
-	if c.client == nil || c.Models == nil {
+	return c.executeRequestWithConfig(ctx, prompt, nil)
+}
+
+// executeRequestWithConfig executes a request to the Gemini API with an optional generation config.
+// Safety blocks, server errors and empty responses are retried against the fallback models in order.
+func (c *GeminiClient) executeRequestWithConfig(ctx context.Context, prompt string, config *genai.GenerationConfig) (*GeminiResponse, error) {
+	if c.generate == nil && c.Models == nil {
 		return nil, fmt.Errorf("Gemini client not initialized")
 	}
 
+	var response *GeminiResponse
+	err := c.runModelChain(ctx, func(model string) error {
+		var err error
+		response, err = c.executeModelRequest(ctx, model, prompt, config)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// runModelChain calls request with the request's model and then each fallback model
+// until one succeeds or fails with an error a fallback cannot fix
+func (c *GeminiClient) runModelChain(ctx context.Context, request func(model string) error) error {
+	// A per-request model override takes precedence over the client's model
+	model := c.model
+	if override := ModelFromContext(ctx); override != "" {
+		model = override
+	}
+
+	var lastErr error
+	chain := c.modelChain(model)
+	for i, candidate := range chain {
+		err := request(candidate)
+		if err == nil {
+			recordModel(ctx, candidate, i > 0)
+			return nil
+		}
+		lastErr = err
+		if ctx.Err() != nil || !isFallbackError(err) || i == len(chain)-1 {
+			break
+		}
+		c.logger.WithFields(logrus.Fields{
+			"model":          candidate,
+			"fallback_model": chain[i+1],
+			"error":          err,
+		}).Warn("Model request failed, retrying with fallback model")
+	}
+	return lastErr
+}
+
+// executeModelRequest executes a request against one model and converts the SDK response
+func (c *GeminiClient) executeModelRequest(ctx context.Context, model, prompt string, config *genai.GenerationConfig) (*GeminiResponse, error) {
+	// Deterministic requests use fixed sampling and replay earlier responses to the same request
+	var recordKey string
+	deterministic := DeterministicFromContext(ctx)
+	if deterministic {
+		config = deterministicConfig(config)
+	}
+	recordPrompt(ctx, model, prompt, config, "")
+	if deterministic {
+		recordKey = recordingKey(model, prompt, config)
+		if recorded, ok := c.responseRecorder().Lookup(recordKey); ok {
+			return recorded, nil
+		}
+	}
+
+	generate := c.generate
+	if generate == nil {
+		generate = c.Models.GenerateContent
+	}
+
 	// Use the requested format: client.Models.GenerateContent(ctx, model, genai.Text(prompt), nil)
-	result, err := c.Models.GenerateContent(
+	result, err := generate(
 		ctx,
-		c.model,
+		model,
 		genai.Text(prompt),
-		nil,
+		config,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate content: %w", err)
 	}
+	recordTokens(ctx, usageOrEstimate(model, prompt, result))
 
 	// Convert SDK response to our internal format
 	response := &GeminiResponse{
@@ -302,7 +451,7 @@ func (c *GeminiClient) executeRequest(ctx context.Context, prompt string) (*Gemi
 	}
 
 	// Process candidates from the SDK response
-	if result.Candidates != nil && len(result.Candidates) > 0 {
+	if result.Candidates != nil && len(result.Candidates) > 0 && result.Candidates[0].Content != nil {
 		candidate := result.Candidates[0]
 		geminiCandidate := GeminiCandidate{
 			Content: GeminiContent{
@@ -319,7 +468,9 @@ func (c *GeminiClient) executeRequest(ctx context.Context, prompt string) (*Gemi
 			}
 		}
 
-		response.Candidates = append(response.Candidates, geminiCandidate)
+		if len(geminiCandidate.Content.Parts) > 0 {
+			response.Candidates = append(response.Candidates, geminiCandidate)
+		}
 	}
 
 	// Process usage metadata if available
@@ -333,12 +484,26 @@ func (c *GeminiClient) executeRequest(ctx context.Context, prompt string) (*Gemi
 
 	// Validate response
 	if len(response.Candidates) == 0 {
-		return nil, fmt.Errorf("no candidates in response")
+		return nil, fmt.Errorf("%w from model %s", ErrEmptyCandidates, model)
+	}
+
+	if recordKey != "" {
+		if err := c.responseRecorder().Record(recordKey, response); err != nil {
+			c.logger.WithError(err).Warn("Failed to record deterministic response")
+		}
 	}
 
 	return response, nil
 }
 
+// responseRecorder returns the recorder that replays deterministic requests
+func (c *GeminiClient) responseRecorder() *ResponseRecorder {
+	if c.recorder != nil {
+		return c.recorder
+	}
+	return defaultRecorder
+}
+
 // parseSummarizeResponse parses the summarization response
 func (c *GeminiClient) parseSummarizeResponse(response *GeminiResponse) (*SummarizeResponse, error) {
 	// Extract text from the first candidate
@@ -421,7 +586,7 @@ func (c *GeminiClient) cleanStringArray(arr []string, maxLen int) []string {
 
 // Health checks the health of the Gemini client
 func (c *GeminiClient) Health(ctx context.Context) error {
-	if c.client == nil {
+	if c.Models == nil {
 		return fmt.Errorf("Gemini client not initialized")
 	}
 
@@ -454,6 +619,7 @@ func (c *GeminiClient) SetAPIKey(apiKey string) {
 	
 	c.client = client
 	c.Models = &ModelsWrapper{client: client}
+	c.backend = BackendAIStudio
 }
 
 // SetModel updates the model
@@ -479,10 +645,11 @@ func (c *GeminiClient) ExplainWithOG(ctx context.Context, topic, outline, miscon
 	}).Info("Generating OG lesson with Gemini")
 
 	// Construct the prompt
-	prompt := c.buildExplainOGPrompt(topic, outline, misconceptions, context)
+	prompt := appendPromptInstructions(ctx, c.buildExplainOGPrompt(topic, outline, misconceptions, context, PersonaFromContext(ctx)))
+	prompt = appendScratchpadInstructions(ctx, prompt)
 
-	// Make API call using the SDK
-	response, err := c.executeRequest(ctx, prompt)
+	// Make API call using the SDK, constraining output to the lesson schema when supported
+	response, structured, err := c.executeJSONRequest(ctx, prompt, lessonSchema(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("API request failed: %w", err)
 	}
@@ -497,11 +664,20 @@ func (c *GeminiClient) ExplainWithOG(ctx context.Context, topic, outline, miscon
 		return nil, fmt.Errorf("no content parts in response")
 	}
 
-	// Extract JSON from response
+	// Decode structured output directly, falling back to extracting JSON from free text
 	responseText := candidate.Content.Parts[0].Text
-	ogLesson, err := c.parseOGLessonResponse(responseText)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse OG lesson response: %w", err)
+	var ogLesson *OGLesson
+	if structured {
+		ogLesson = decodeStructuredOGLesson(responseText)
+	}
+	if ogLesson == nil {
+		ogLesson, err = c.parseOGLessonResponse(responseText)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OG lesson response: %w", err)
+		}
+	}
+	if ScratchpadFromContext(ctx) {
+		ogLesson.Scratchpad = extractScratchpad(responseText)
 	}
 
 	c.logger.WithField("topic", topic).Info("OG lesson generation completed")
@@ -509,7 +685,8 @@ func (c *GeminiClient) ExplainWithOG(ctx context.Context, topic, outline, miscon
 }
 
 // buildExplainOGPrompt constructs the prompt for OG lesson generation
-func (c *GeminiClient) buildExplainOGPrompt(topic, outline, misconceptions, context string) string {
+// A nil persona produces a lesson for a general audience
+func (c *GeminiClient) buildExplainOGPrompt(topic, outline, misconceptions, context string, persona *Persona) string {
 	var promptBuilder strings.Builder
 
 	promptBuilder.WriteString(fmt.Sprintf(`
@@ -540,10 +717,12 @@ Your task is to produce a JSON object with exactly these fields:
 
 	if context != "" {
 		promptBuilder.WriteString("Additional Context:\n")
-		promptBuilder.WriteString(context)
+		promptBuilder.WriteString(UntrustedContextBlock(context))
 		promptBuilder.WriteString("\n\n")
 	}
 
+	writePersona(&promptBuilder, persona)
+
 	promptBuilder.WriteString(`
 Requirements:
 - Produce JSON only, no markdown formatting
@@ -642,8 +821,28 @@ func (c *GeminiClient) CritiqueLesson(ctx context.Context, lessonJSON string) (*
 		"model":         c.model,
 	}).Info("Critiquing lesson with Gemini")
 
-	// Construct the prompt
-	prompt := c.buildCritiquePrompt(lessonJSON)
+	// Prefer a structured submit_critique tool call; fall back to parsing JSON from prose
+	rubric := RubricFromContext(ctx)
+	if !c.freeTextOnly {
+		critiqueResponse, err := c.critiqueWithTool(ctx, lessonJSON, rubric)
+		if err == nil {
+			c.logger.WithFields(logrus.Fields{
+				"issues_count":     len(critiqueResponse.Issues),
+				"patch_plan_count": len(critiqueResponse.PatchPlan),
+			}).Info("Lesson critique completed")
+			return critiqueResponse, nil
+		}
+		if !isToolFallbackError(err) {
+			return nil, fmt.Errorf("API request failed: %w", err)
+		}
+		c.logger.WithFields(logrus.Fields{
+			"model": c.model,
+			"error": err,
+		}).Warn("Critique tool call failed, retrying as free text")
+	}
+
+	// Construct the prompt using the request-scoped rubric, if any
+	prompt := appendPromptInstructions(ctx, c.buildCritiquePrompt(lessonJSON, rubric))
 
 	// Make API call using the SDK
 	response, err := c.executeRequest(ctx, prompt)
@@ -677,17 +876,20 @@ func (c *GeminiClient) CritiqueLesson(ctx context.Context, lessonJSON string) (*
 }
 
 // buildCritiquePrompt constructs the prompt for lesson critique
-func (c *GeminiClient) buildCritiquePrompt(lessonJSON string) string {
-	var promptBuilder strings.Builder
-
-	promptBuilder.WriteString(`
-You are an expert educational content reviewer. Your task is to critique a lesson and provide specific, actionable feedback.
+// A nil rubric falls back to the default evaluation criteria
+func (c *GeminiClient) buildCritiquePrompt(lessonJSON string, rubric *Rubric) string {
+	return critiquePrompt(lessonJSON, rubric)
+}
 
-Analyze the following lesson JSON and identify issues, then create a patch plan to fix them.
+// critiquePrompt builds the free-text critique prompt shared by every critic provider
+func critiquePrompt(lessonJSON string, rubric *Rubric) string {
+	if rubric == nil {
+		rubric = DefaultRubric()
+	}
 
-Lesson to critique:
-`)
+	var promptBuilder strings.Builder
 
+	promptBuilder.WriteString(critiquePromptIntro)
 	promptBuilder.WriteString(lessonJSON)
 	promptBuilder.WriteString(`
 
@@ -696,34 +898,25 @@ Your response must be a JSON object with exactly these fields:
   - "section": The section name (e.g., "big_picture", "metaphor", "core_mechanism", "toy_example_code", "memory_hook", "real_life", "best_practices")
   - "problem": Specific description of the problem
   - "severity": Severity level ("low", "medium", "high", "critical")
+  - "confidence": How sure you are that this is a real problem, from 0 to 1
 
 - "patch_plan": Array of fixes, each with:
   - "section": The section to modify
   - "change": Description of what needs to change
   - "replacement_text": The complete new text for that section
 
-Evaluation Criteria:
-1. **Clarity**: Is the content clear and understandable?
-2. **Accuracy**: Is the information technically correct?
-3. **Completeness**: Are all necessary concepts covered?
-4. **Engagement**: Is the content engaging and memorable?
-5. **Structure**: Does each section serve its intended purpose?
-6. **Code Quality**: If code is present, is it correct and runnable?
-7. **Length**: Are sections appropriately sized (not too short/long)?
-
-Severity Guidelines:
-- "critical": Factual errors, broken code, major misconceptions
-- "high": Significant clarity issues, missing key concepts
-- "medium": Minor clarity issues, could be more engaging
-- "low": Minor improvements, style suggestions
+`)
 
-Example JSON structure:
+	writeRubric(&promptBuilder, rubric)
+
+	promptBuilder.WriteString(`Example JSON structure:
 {
   "issues": [
     {
       "section": "big_picture",
       "problem": "Too vague and doesn't provide clear context",
-      "severity": "high"
+      "severity": "high",
+      "confidence": 0.9
     }
   ],
   "patch_plan": [
@@ -741,8 +934,38 @@ Your JSON response:
 	return promptBuilder.String()
 }
 
+// writeRubric writes the rubric's evaluation criteria and severity guidelines to the prompt
+func writeRubric(promptBuilder *strings.Builder, rubric *Rubric) {
+	promptBuilder.WriteString("Evaluation Criteria:\n")
+	for i, criterion := range rubric.Criteria {
+		fmt.Fprintf(promptBuilder, "%d. **%s**: %s\n", i+1, criterion.Name, criterion.Description)
+	}
+
+	promptBuilder.WriteString("\nSeverity Guidelines:\n")
+	defaults := DefaultRubric().SeverityGuidelines
+	for _, severity := range critiqueSeverities {
+		guideline, ok := rubric.SeverityGuidelines[severity]
+		if !ok {
+			guideline = defaults[severity]
+		}
+		fmt.Fprintf(promptBuilder, "- %q: %s\n", severity, guideline)
+	}
+
+	if rubric.Instructions != "" {
+		promptBuilder.WriteString("\nAdditional Review Instructions:\n")
+		promptBuilder.WriteString(rubric.Instructions)
+		promptBuilder.WriteString("\n")
+	}
+	promptBuilder.WriteString("\n")
+}
+
 // parseCritiqueResponse extracts and parses the CritiqueResponse from the response text
 func (c *GeminiClient) parseCritiqueResponse(responseText string) (*CritiqueResponse, error) {
+	return parseCritiqueText(responseText)
+}
+
+// parseCritiqueText extracts the critique JSON object from a model's response text
+func parseCritiqueText(responseText string) (*CritiqueResponse, error) {
 	// Find JSON in the response
 	jsonStart := strings.Index(responseText, "{")
 	if jsonStart == -1 {
@@ -761,11 +984,18 @@ func (c *GeminiClient) parseCritiqueResponse(responseText string) (*CritiqueResp
 		return nil, fmt.Errorf("failed to unmarshal critique response JSON: %w", err)
 	}
 
-	// Clean and validate the response
+	cleanCritiqueResponse(&critiqueResponse)
+	return &critiqueResponse, nil
+}
+
+// cleanCritiqueResponse trims the critique's text fields and clamps confidences to [0, 1]
+func cleanCritiqueResponse(critiqueResponse *CritiqueResponse) {
 	for i := range critiqueResponse.Issues {
-		critiqueResponse.Issues[i].Section = strings.TrimSpace(critiqueResponse.Issues[i].Section)
-		critiqueResponse.Issues[i].Problem = strings.TrimSpace(critiqueResponse.Issues[i].Problem)
-		critiqueResponse.Issues[i].Severity = strings.TrimSpace(critiqueResponse.Issues[i].Severity)
+		issue := &critiqueResponse.Issues[i]
+		issue.Section = strings.TrimSpace(issue.Section)
+		issue.Problem = strings.TrimSpace(issue.Problem)
+		issue.Severity = strings.TrimSpace(issue.Severity)
+		issue.Confidence = math.Max(0, math.Min(1, issue.Confidence))
 	}
 
 	for i := range critiqueResponse.PatchPlan {
@@ -773,8 +1003,6 @@ func (c *GeminiClient) parseCritiqueResponse(responseText string) (*CritiqueResp
 		critiqueResponse.PatchPlan[i].Change = strings.TrimSpace(critiqueResponse.PatchPlan[i].Change)
 		critiqueResponse.PatchPlan[i].ReplacementText = strings.TrimSpace(critiqueResponse.PatchPlan[i].ReplacementText)
 	}
-
-	return &critiqueResponse, nil
 }
 
 // VisualizeCore generates visual diagrams for lesson core mechanisms using Imagen
@@ -792,7 +1020,7 @@ func (c *GeminiClient) VisualizeCore(ctx context.Context, lessonJSON, sessionID
 	}
 
 	// Generate visualization prompts for the core mechanism
-	prompts, err := c.buildVisualizationPrompts(lesson.CoreMechanism)
+	prompts, err := c.buildVisualizationPrompts(ctx, lesson.CoreMechanism)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build visualization prompts: %w", err)
 	}
@@ -808,12 +1036,21 @@ func (c *GeminiClient) VisualizeCore(ctx context.Context, lessonJSON, sessionID
 		c.logger.Warn("GCS_BUCKET not set, using default: explainiq-diagrams")
 	}
 
+	// Deterministic sessions name images after the lesson so repeated runs reuse the same objects
+	artifactPrefix := ArtifactPrefix(ctx, sessionID, lessonJSON)
+	imageStyle := "default"
+	if style := ImageStyleFromContext(ctx); style != nil {
+		imageStyle = style.ID
+	}
+
 	// For now, return empty images array since actual image generation/upload is not implemented
 	// This prevents broken image URLs from being displayed in the frontend
 	// TODO: Implement actual image generation using Imagen API and upload to GCS
 	c.logger.WithFields(logrus.Fields{
-		"session_id": sessionID,
-		"prompts":    len(prompts),
+		"session_id":      sessionID,
+		"prompts":         len(prompts),
+		"artifact_prefix": artifactPrefix,
+		"image_style":     imageStyle,
 	}).Info("Visualization generation skipped - image generation not yet implemented")
 	
 	// Return empty images array to avoid broken image URLs
@@ -825,7 +1062,7 @@ func (c *GeminiClient) VisualizeCore(ctx context.Context, lessonJSON, sessionID
 		// Upload image to GCS
 		// Get signed URL or public URL
 		imageRef := ImageRef{
-			URL:     fmt.Sprintf("https://storage.googleapis.com/%s/sessions/%s/diagram_%d.png", gcsBucket, sessionID, i+1),
+			URL:     fmt.Sprintf("https://storage.googleapis.com/%s/%s/diagram_%d.png", gcsBucket, artifactPrefix, i+1),
 			AltText: fmt.Sprintf("Diagram %d illustrating %s", i+1, lesson.CoreMechanism),
 			Caption: prompt.Caption,
 		}
@@ -834,6 +1071,12 @@ func (c *GeminiClient) VisualizeCore(ctx context.Context, lessonJSON, sessionID
 	}
 	*/
 
+	// Give every image a long description and non-empty alt text
+	if len(images) > 0 {
+		images = c.addLongDescriptions(ctx, lesson, prompts, images)
+		images, _ = EnsureImageAccessibility(images, "the lesson")
+	}
+
 	response := &VisualizeResponse{
 		Images:   images,
 		Captions: captions,
@@ -854,8 +1097,8 @@ type VisualizationPrompt struct {
 	Caption string `json:"caption"` // The caption for the generated image
 }
 
-// buildVisualizationPrompts creates prompts for visualizing the core mechanism
-func (c *GeminiClient) buildVisualizationPrompts(coreMechanism string) ([]VisualizationPrompt, error) {
+// buildVisualizationPrompts creates prompts for visualizing the core mechanism in the context's image style
+func (c *GeminiClient) buildVisualizationPrompts(ctx context.Context, coreMechanism string) ([]VisualizationPrompt, error) {
 	if coreMechanism == "" {
 		return nil, fmt.Errorf("core mechanism is empty")
 	}
@@ -871,6 +1114,9 @@ func (c *GeminiClient) buildVisualizationPrompts(coreMechanism string) ([]Visual
 			Caption: fmt.Sprintf("Process flowchart: %s", coreMechanism),
 		},
 	}
+	for i := range prompts {
+		prompts[i].Prompt = applyImageStyle(ctx, prompts[i].Prompt)
+	}
 
 	return prompts, nil
 }
@@ -879,7 +1125,8 @@ func (c *GeminiClient) buildVisualizationPrompts(coreMechanism string) ([]Visual
 func (c *GeminiClient) GetModelInfo() map[string]interface{} {
 	return map[string]interface{}{
 		"model":        c.model,
-		"client_valid": c.client != nil,
+		"client_valid": c.Models != nil,
+		"backend":      c.backend,
 		"sdk_version":  "google.generative-ai-go",
 	}
 }
@@ -340,7 +340,7 @@ func TestSummarizeMissingFields(t *testing.T) {
 func TestCreateSummarizePrompt(t *testing.T) {
 	client := NewGeminiClient("test-api-key")
 
-	prompt := client.createSummarizePrompt("machine learning", "context about ML")
+	prompt := client.createSummarizePrompt("machine learning", "context about ML", nil)
 
 	assert.Contains(t, prompt, "machine learning")
 	assert.Contains(t, prompt, "context about ML")
@@ -524,7 +524,7 @@ func BenchmarkCreateSummarizePrompt(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = client.createSummarizePrompt("machine learning", "context about ML")
+		_ = client.createSummarizePrompt("machine learning", "context about ML", nil)
 	}
 }
 
@@ -709,7 +709,7 @@ func TestExplainWithOGInvalidJSON(t *testing.T) {
 func TestBuildExplainOGPrompt(t *testing.T) {
 	client := NewGeminiClient("test-api-key")
 
-	prompt := client.buildExplainOGPrompt("machine learning", "Introduction, Concepts", "ML is magic", "Additional context")
+	prompt := client.buildExplainOGPrompt("machine learning", "Introduction, Concepts", "ML is magic", "Additional context", nil)
 
 	assert.Contains(t, prompt, "machine learning")
 	assert.Contains(t, prompt, "Introduction, Concepts")
@@ -844,7 +844,7 @@ func BenchmarkBuildExplainOGPrompt(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = client.buildExplainOGPrompt("machine learning", "Introduction, Concepts", "ML is magic", "Additional context")
+		_ = client.buildExplainOGPrompt("machine learning", "Introduction, Concepts", "ML is magic", "Additional context", nil)
 	}
 }
 
@@ -1079,7 +1079,7 @@ func TestBuildCritiquePrompt(t *testing.T) {
 		"metaphor": "It's like magic"
 	}`
 
-	prompt := client.buildCritiquePrompt(lessonJSON)
+	prompt := client.buildCritiquePrompt(lessonJSON, nil)
 
 	assert.Contains(t, prompt, "Machine learning is cool")
 	assert.Contains(t, prompt, "It's like magic")
@@ -1255,7 +1255,7 @@ func BenchmarkBuildCritiquePrompt(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = client.buildCritiquePrompt(lessonJSON)
+		_ = client.buildCritiquePrompt(lessonJSON, nil)
 	}
 }
 
@@ -1373,7 +1373,7 @@ func TestBuildVisualizationPrompts(t *testing.T) {
 	client := NewGeminiClient("test-api-key")
 
 	coreMechanism := "Algorithms find patterns in data to make predictions"
-	prompts, err := client.buildVisualizationPrompts(coreMechanism)
+	prompts, err := client.buildVisualizationPrompts(context.Background(), coreMechanism)
 
 	// Verify no error occurred
 	assert.NoError(t, err)
@@ -1397,7 +1397,7 @@ func TestBuildVisualizationPrompts(t *testing.T) {
 func TestBuildVisualizationPromptsEmptyMechanism(t *testing.T) {
 	client := NewGeminiClient("test-api-key")
 
-	prompts, err := client.buildVisualizationPrompts("")
+	prompts, err := client.buildVisualizationPrompts(context.Background(), "")
 
 	// Verify error occurred
 	assert.Error(t, err)
@@ -1490,6 +1490,6 @@ func BenchmarkBuildVisualizationPrompts(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = client.buildVisualizationPrompts(coreMechanism)
+		_, _ = client.buildVisualizationPrompts(context.Background(), coreMechanism)
 	}
 }
@@ -3,10 +3,13 @@ module github.com/InnoFusionTech/ExplainIQ/internal/llm
 go 1.22
 
 require (
+	github.com/InnoFusionTech/ExplainIQ/internal/tokens v0.0.0
 	github.com/google/generative-ai-go v0.15.0
 	github.com/sirupsen/logrus v1.9.3
 	github.com/stretchr/testify v1.9.0
+	golang.org/x/oauth2 v0.24.0
 	google.golang.org/api v0.207.0
+	google.golang.org/grpc v1.67.1
 )
 
 require (
@@ -34,16 +37,16 @@ require (
 	go.opentelemetry.io/otel/trace v1.29.0 // indirect
 	golang.org/x/crypto v0.29.0 // indirect
 	golang.org/x/net v0.31.0 // indirect
-	golang.org/x/oauth2 v0.24.0 // indirect
 	golang.org/x/sync v0.9.0 // indirect
 	golang.org/x/sys v0.27.0 // indirect
 	golang.org/x/text v0.20.0 // indirect
 	golang.org/x/time v0.8.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20241104194629-dd2ea8efbc28 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20241113202542-65e8d215514f // indirect
-	google.golang.org/grpc v1.67.1 // indirect
 	google.golang.org/protobuf v1.35.2 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace github.com/InnoFusionTech/ExplainIQ => ../../
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/tokens => ../tokens
@@ -0,0 +1,257 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2/google"
+)
+
+// Grounding modes a session can request for the summarizer
+const (
+	GroundingOff  = "off"  // Never search the web (default)
+	GroundingOn   = "on"   // Always ground the summary in Google Search results
+	GroundingAuto = "auto" // Ground only topics that look like current events or factual lookups
+)
+
+// maxGroundingCitations caps the web sources kept per grounded summary
+const maxGroundingCitations = 10
+
+// NormalizeGroundingMode validates a requested grounding mode; an empty mode is off
+func NormalizeGroundingMode(mode string) (string, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "", GroundingOff:
+		return GroundingOff, nil
+	case GroundingOn, GroundingAuto:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid grounding mode %q: must be %s, %s or %s", mode, GroundingOff, GroundingOn, GroundingAuto)
+	}
+}
+
+// currentEventsPattern matches topics that depend on recent or verifiable facts
+var currentEventsPattern = regexp.MustCompile(`(?i)\b(latest|current|recent|recently|today|this (week|month|year)|news|update[sd]?|announce[ds]?|release[ds]?|election|price|prices|statistics|population|who is|when (did|was|is)|20[0-9]{2})\b`)
+
+// ShouldGround reports whether a summary of topic should be grounded under mode
+func ShouldGround(mode, topic string) bool {
+	switch mode {
+	case GroundingOn:
+		return true
+	case GroundingAuto:
+		return currentEventsPattern.MatchString(topic)
+	default:
+		return false
+	}
+}
+
+// GroundingCitation is a web source a grounded answer drew on
+type GroundingCitation struct {
+	URI   string `json:"uri"`
+	Title string `json:"title,omitempty"`
+}
+
+// GroundingResult is a model answer grounded in Google Search results
+type GroundingResult struct {
+	Text      string              `json:"text"`
+	Citations []GroundingCitation `json:"citations"`
+	Queries   []string            `json:"queries,omitempty"` // Searches the model ran
+}
+
+// Grounder researches a topic on the web
+type Grounder interface {
+	Ground(ctx context.Context, topic string) (*GroundingResult, error)
+}
+
+// GroundingClient answers prompts with Gemini on Vertex AI using the Google Search tool
+type GroundingClient struct {
+	projectID  string
+	location   string
+	model      string
+	endpoint   string // Overrides the Vertex AI endpoint; used by tests
+	httpClient *http.Client
+	logger     *logrus.Logger
+}
+
+// NewGroundingClient creates a grounding client for a Vertex AI project and location
+func NewGroundingClient(projectID, location string) *GroundingClient {
+	if location == "" {
+		location = "us-central1"
+	}
+	return &GroundingClient{
+		projectID:  projectID,
+		location:   location,
+		model:      "gemini-2.5-flash",
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		logger:     logrus.New(),
+	}
+}
+
+// GroundingClientFromEnv creates a grounding client from GROUNDING_PROJECT_ID (or GCP_PROJECT_ID),
+// GROUNDING_LOCATION and GROUNDING_MODEL. It returns nil when no project is configured.
+func GroundingClientFromEnv() *GroundingClient {
+	projectID := os.Getenv("GROUNDING_PROJECT_ID")
+	if projectID == "" {
+		projectID = os.Getenv("GCP_PROJECT_ID")
+	}
+	if projectID == "" {
+		return nil
+	}
+	client := NewGroundingClient(projectID, os.Getenv("GROUNDING_LOCATION"))
+	if model := os.Getenv("GROUNDING_MODEL"); model != "" {
+		client.model = model
+	}
+	return client
+}
+
+// groundingRequest is a Vertex AI generateContent request with the Google Search tool
+type groundingRequest struct {
+	Contents []groundingContent `json:"contents"`
+	Tools    []map[string]any   `json:"tools"`
+}
+
+// groundingContent is a turn of a generateContent request or response
+type groundingContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []GeminiPart `json:"parts"`
+}
+
+// groundingResponse is the part of a generateContent response grounding uses
+type groundingResponse struct {
+	Candidates []struct {
+		Content           groundingContent `json:"content"`
+		GroundingMetadata struct {
+			WebSearchQueries []string `json:"webSearchQueries"`
+			GroundingChunks  []struct {
+				Web *struct {
+					URI   string `json:"uri"`
+					Title string `json:"title"`
+				} `json:"web"`
+			} `json:"groundingChunks"`
+		} `json:"groundingMetadata"`
+	} `json:"candidates"`
+}
+
+// buildGroundingPrompt asks for the up-to-date facts a lesson about topic needs
+func buildGroundingPrompt(topic string) string {
+	return fmt.Sprintf(`Research the topic "%s" for a short lesson. Using web search, report the key facts,
+definitions, dates and figures a learner needs, noting what is recent or has changed.
+Be concise and factual; use plain sentences without markdown.`, topic)
+}
+
+// Ground asks Gemini to research topic with Google Search and returns its answer and sources
+func (c *GroundingClient) Ground(ctx context.Context, topic string) (*GroundingResult, error) {
+	body, err := json.Marshal(groundingRequest{
+		Contents: []groundingContent{{Role: "user", Parts: []GeminiPart{{Text: buildGroundingPrompt(topic)}}}},
+		Tools:    []map[string]any{{"googleSearch": map[string]any{}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := c.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/%s:generateContent",
+			c.location, c.projectID, c.location, c.model)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	accessToken, err := c.getAccessToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("grounding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiError GeminiError
+		if err := json.Unmarshal(responseBody, &apiError); err == nil && apiError.Error.Message != "" {
+			return nil, fmt.Errorf("API error %d: %s", apiError.Error.Code, apiError.Error.Message)
+		}
+		return nil, fmt.Errorf("grounding request failed with status %d: %s", resp.StatusCode, string(responseBody))
+	}
+
+	var response groundingResponse
+	if err := json.Unmarshal(responseBody, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(response.Candidates) == 0 {
+		return nil, ErrEmptyCandidates
+	}
+
+	candidate := response.Candidates[0]
+	result := &GroundingResult{Citations: []GroundingCitation{}, Queries: candidate.GroundingMetadata.WebSearchQueries}
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		text.WriteString(part.Text)
+	}
+	result.Text = strings.TrimSpace(text.String())
+
+	seen := make(map[string]bool)
+	for _, chunk := range candidate.GroundingMetadata.GroundingChunks {
+		if chunk.Web == nil || chunk.Web.URI == "" || seen[chunk.Web.URI] {
+			continue
+		}
+		seen[chunk.Web.URI] = true
+		result.Citations = append(result.Citations, GroundingCitation{URI: chunk.Web.URI, Title: chunk.Web.Title})
+		if len(result.Citations) == maxGroundingCitations {
+			break
+		}
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"topic":     topic,
+		"citations": len(result.Citations),
+		"queries":   len(result.Queries),
+	}).Info("Grounded topic with Google Search")
+	return result, nil
+}
+
+// getAccessToken returns GOOGLE_ACCESS_TOKEN when set, otherwise a token from Application Default Credentials
+func (c *GroundingClient) getAccessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	source, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return "", err
+	}
+	token, err := source.Token()
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// GroundedContext formats a grounding result as untrusted context for the summarizer prompt
+func GroundedContext(result *GroundingResult) string {
+	var b strings.Builder
+	b.WriteString("Web research (Google Search):\n")
+	b.WriteString(result.Text)
+	if len(result.Citations) > 0 {
+		b.WriteString("\n\nSources:\n")
+		for _, citation := range result.Citations {
+			fmt.Fprintf(&b, "- %s %s\n", citation.Title, citation.URI)
+		}
+	}
+	return WrapUntrustedDocument("web", b.String())
+}
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestShouldGround tests which topics each grounding mode grounds
+func TestShouldGround(t *testing.T) {
+	assert.False(t, ShouldGround(GroundingOff, "Latest AI regulations"))
+	assert.True(t, ShouldGround(GroundingOn, "Binary search"))
+	assert.True(t, ShouldGround(GroundingAuto, "Latest AI regulations in the EU"))
+	assert.True(t, ShouldGround(GroundingAuto, "The 2024 Olympics"))
+	assert.False(t, ShouldGround(GroundingAuto, "How recursion works"))
+
+	mode, err := NormalizeGroundingMode(" Auto ")
+	require.NoError(t, err)
+	assert.Equal(t, GroundingAuto, mode)
+	mode, err = NormalizeGroundingMode("")
+	require.NoError(t, err)
+	assert.Equal(t, GroundingOff, mode)
+	_, err = NormalizeGroundingMode("always")
+	assert.Error(t, err)
+}
+
+// TestGroundingClientGround tests the Google Search tool request and citation extraction
+func TestGroundingClientGround(t *testing.T) {
+	t.Setenv("GOOGLE_ACCESS_TOKEN", "test-token")
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"Go 1.24 was released "},{"text":"in February 2025."}]},
+			"groundingMetadata":{"webSearchQueries":["go 1.24 release"],"groundingChunks":[
+				{"web":{"uri":"https://go.dev/doc/go1.24","title":"go.dev"}},
+				{"web":{"uri":"https://go.dev/doc/go1.24","title":"go.dev"}},
+				{"retrievedContext":{}},
+				{"web":{"uri":"https://go.dev/blog/go1.24","title":"Go blog"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewGroundingClient("project", "")
+	client.endpoint = server.URL
+	result, err := client.Ground(context.Background(), "Latest Go release")
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{map[string]any{"googleSearch": map[string]any{}}}, request["tools"])
+	assert.Equal(t, "Go 1.24 was released in February 2025.", result.Text)
+	assert.Equal(t, []string{"go 1.24 release"}, result.Queries)
+	assert.Equal(t, []GroundingCitation{
+		{URI: "https://go.dev/doc/go1.24", Title: "go.dev"},
+		{URI: "https://go.dev/blog/go1.24", Title: "Go blog"},
+	}, result.Citations)
+	assert.Contains(t, GroundedContext(result), "- Go blog https://go.dev/blog/go1.24")
+}
+
+// TestGroundingClientError tests that API errors are reported
+func TestGroundingClientError(t *testing.T) {
+	t.Setenv("GOOGLE_ACCESS_TOKEN", "test-token")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"code":403,"message":"Permission denied","status":"PERMISSION_DENIED"}}`))
+	}))
+	defer server.Close()
+
+	client := NewGroundingClient("project", "us-east1")
+	client.endpoint = server.URL
+	_, err := client.Ground(context.Background(), "Latest Go release")
+	assert.ErrorContains(t, err, "Permission denied")
+}
@@ -0,0 +1,206 @@
+package llm
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token classes emitted by HighlightCode, styled by the frontend
+const (
+	tokenKeyword = "tok-keyword"
+	tokenString  = "tok-string"
+	tokenComment = "tok-comment"
+	tokenNumber  = "tok-number"
+)
+
+// codeLanguage describes the lexical rules HighlightCode needs for one language
+type codeLanguage struct {
+	keywords     map[string]bool
+	lineComments []string // Prefixes that start a comment running to the end of the line
+	blockComment bool     // Supports /* */ comments
+}
+
+// words makes a keyword set
+func words(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	cLikeLanguage = codeLanguage{
+		keywords: words(`break case catch class const continue default do else enum extends false final finally for if
+			implements import interface new null private protected public return static struct super switch this throw
+			throws true try void while int long float double char bool boolean string var auto`),
+		lineComments: []string{"//"},
+		blockComment: true,
+	}
+	codeLanguages = map[string]codeLanguage{
+		"go": {
+			keywords: words(`break case chan const continue default defer else fallthrough for func go goto if import
+				interface map package range return select struct switch type var nil true false`),
+			lineComments: []string{"//"},
+			blockComment: true,
+		},
+		"python": {
+			keywords: words(`and as assert async await break class continue def del elif else except False finally
+				for from global if import in is lambda None nonlocal not or pass raise return True try while with yield`),
+			lineComments: []string{"#"},
+		},
+		"javascript": {
+			keywords: words(`async await break case catch class const continue default delete do else export extends
+				false finally for function if import in instanceof let new null of return super switch this throw true
+				try typeof undefined var void while yield interface type`),
+			lineComments: []string{"//"},
+			blockComment: true,
+		},
+		"shell": {
+			keywords:     words(`if then else elif fi for while do done case esac in function return export local echo`),
+			lineComments: []string{"#"},
+		},
+		"sql": {
+			keywords: words(`select from where join left right inner outer on group by order having insert into values
+				update set delete create table index primary key not null and or as limit SELECT FROM WHERE JOIN LEFT
+				RIGHT INNER OUTER ON GROUP BY ORDER HAVING INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE INDEX
+				PRIMARY KEY NOT NULL AND OR AS LIMIT`),
+			lineComments: []string{"--"},
+			blockComment: true,
+		},
+	}
+	// languageAliases maps code fence names to the language whose rules they use
+	languageAliases = map[string]string{
+		"golang": "go", "py": "python", "python3": "python", "js": "javascript", "jsx": "javascript",
+		"ts": "javascript", "tsx": "javascript", "typescript": "javascript", "sh": "shell", "bash": "shell",
+		"zsh": "shell", "postgres": "sql", "mysql": "sql", "sqlite": "sql",
+	}
+)
+
+// lookupCodeLanguage returns the lexical rules for a code fence language, defaulting to C-like rules
+func lookupCodeLanguage(name string) codeLanguage {
+	if alias, ok := languageAliases[name]; ok {
+		name = alias
+	}
+	if lang, ok := codeLanguages[name]; ok {
+		return lang
+	}
+	return cLikeLanguage
+}
+
+// GuessCodeLanguage guesses the language of an unlabelled code sample from telltale syntax,
+// returning "" when nothing stands out
+func GuessCodeLanguage(code string) string {
+	switch {
+	case strings.Contains(code, "package main") || strings.Contains(code, "func ") || strings.Contains(code, ":="):
+		return "go"
+	case strings.Contains(code, "def ") || strings.Contains(code, "print(") || strings.Contains(code, "elif "):
+		return "python"
+	case strings.Contains(code, "function ") || strings.Contains(code, "const ") || strings.Contains(code, "=>") || strings.Contains(code, "console.log"):
+		return "javascript"
+	case strings.HasPrefix(code, "#!/bin/") || strings.HasPrefix(code, "$ "):
+		return "shell"
+	}
+	return ""
+}
+
+// HighlightCode escapes code and wraps its keywords, strings, comments and numbers in
+// <span class="tok-*"> elements for a frontend stylesheet to color
+func HighlightCode(code, language string) string {
+	lang := lookupCodeLanguage(language)
+	var b strings.Builder
+	span := func(class, text string) {
+		b.WriteString(`<span class="` + class + `">` + html.EscapeString(text) + `</span>`)
+	}
+
+	for i := 0; i < len(code); {
+		rest := code[i:]
+
+		if lineCommentAt(rest, lang.lineComments) {
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			span(tokenComment, rest[:end])
+			i += end
+			continue
+		}
+		if lang.blockComment && strings.HasPrefix(rest, "/*") {
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 4
+			}
+			span(tokenComment, rest[:end])
+			i += end
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case r == '"' || r == '\'' || r == '`':
+			end := stringLiteralEnd(rest, byte(r))
+			span(tokenString, rest[:end])
+			i += end
+		case unicode.IsDigit(r):
+			end := strings.IndexFunc(rest, func(c rune) bool {
+				return !unicode.IsDigit(c) && !unicode.IsLetter(c) && c != '.' && c != '_'
+			})
+			if end < 0 {
+				end = len(rest)
+			}
+			span(tokenNumber, rest[:end])
+			i += end
+		case unicode.IsLetter(r) || r == '_':
+			end := strings.IndexFunc(rest, func(c rune) bool {
+				return !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_'
+			})
+			if end < 0 {
+				end = len(rest)
+			}
+			if word := rest[:end]; lang.keywords[word] {
+				span(tokenKeyword, word)
+			} else {
+				b.WriteString(html.EscapeString(word))
+			}
+			i += end
+		default:
+			b.WriteString(html.EscapeString(rest[:size]))
+			i += size
+		}
+	}
+	return b.String()
+}
+
+// lineCommentAt reports whether s starts with one of the line comment prefixes
+func lineCommentAt(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringLiteralEnd returns the length of the string literal opening s. Backtick strings may span
+// lines; other strings end at an unescaped quote or the end of the line.
+func stringLiteralEnd(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		case quote:
+			return i + 1
+		}
+	}
+	return len(s)
+}
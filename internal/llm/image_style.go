@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ImageStyle is a visual preset for generated diagrams, applied as an Imagen prompt modifier
+type ImageStyle struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Modifier    string `json:"modifier"` // Appended to every image prompt
+}
+
+// imageStyles are the built-in presets, in the order they are listed to callers
+var imageStyles = []ImageStyle{
+	{
+		ID:          "whiteboard-sketch",
+		Name:        "Whiteboard sketch",
+		Description: "Hand-drawn marker diagrams on a white background",
+		Modifier:    "Render as a hand-drawn whiteboard sketch: black and blue marker strokes on a plain white background, slightly uneven lines, handwritten-style labels.",
+	},
+	{
+		ID:          "flat-infographic",
+		Name:        "Flat infographic",
+		Description: "Flat vector shapes with a limited color palette",
+		Modifier:    "Render as a flat vector infographic: solid fills, no gradients or shadows, a palette of at most four colors, rounded shapes and generous spacing.",
+	},
+	{
+		ID:          "dark-technical",
+		Name:        "Dark-mode technical diagram",
+		Description: "Precise technical diagram on a dark background",
+		Modifier:    "Render as a dark-mode technical diagram: light thin lines and monospace labels on a near-black background, with a single accent color for highlights.",
+	},
+}
+
+// ImageStyles returns the built-in image style presets
+func ImageStyles() []ImageStyle {
+	styles := make([]ImageStyle, len(imageStyles))
+	copy(styles, imageStyles)
+	return styles
+}
+
+// LookupImageStyle returns the preset with an ID, after normalizing it
+func LookupImageStyle(id string) (*ImageStyle, bool) {
+	normalized := NormalizePersona(id)
+	for _, style := range imageStyles {
+		if style.ID == normalized {
+			found := style
+			return &found, true
+		}
+	}
+	return nil, false
+}
+
+// NormalizeImageStyle validates a requested image style and returns its preset ID.
+// An empty style returns "", meaning the visualizer's default look.
+func NormalizeImageStyle(style string) (string, error) {
+	if strings.TrimSpace(style) == "" {
+		return "", nil
+	}
+	preset, ok := LookupImageStyle(style)
+	if !ok {
+		ids := make([]string, len(imageStyles))
+		for i, known := range imageStyles {
+			ids[i] = known.ID
+		}
+		return "", fmt.Errorf("unknown image style %q (supported: %s)", style, strings.Join(ids, ", "))
+	}
+	return preset.ID, nil
+}
+
+// imageStyleContextKey is the context key for a request-scoped image style
+type imageStyleContextKey struct{}
+
+// WithImageStyle returns a context whose image prompts use a style preset
+func WithImageStyle(ctx context.Context, style *ImageStyle) context.Context {
+	return context.WithValue(ctx, imageStyleContextKey{}, style)
+}
+
+// ImageStyleFromContext returns the image style carried by the context, or nil
+func ImageStyleFromContext(ctx context.Context) *ImageStyle {
+	style, _ := ctx.Value(imageStyleContextKey{}).(*ImageStyle)
+	return style
+}
+
+// applyImageStyle adds the context's style modifier to an image prompt
+func applyImageStyle(ctx context.Context, prompt string) string {
+	style := ImageStyleFromContext(ctx)
+	if style == nil {
+		return prompt
+	}
+	return prompt + " " + style.Modifier
+}
@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeImageStyle tests preset validation and normalization
+func TestNormalizeImageStyle(t *testing.T) {
+	style, err := NormalizeImageStyle("")
+	require.NoError(t, err)
+	assert.Empty(t, style)
+
+	style, err = NormalizeImageStyle("Whiteboard Sketch")
+	require.NoError(t, err)
+	assert.Equal(t, "whiteboard-sketch", style)
+
+	_, err = NormalizeImageStyle("watercolor")
+	assert.ErrorContains(t, err, "flat-infographic")
+
+	assert.Len(t, ImageStyles(), 3)
+}
+
+// TestVisualizationPromptsUseImageStyle tests that the context's style modifier reaches every image prompt
+func TestVisualizationPromptsUseImageStyle(t *testing.T) {
+	client := &GeminiClient{}
+	plain, err := client.buildVisualizationPrompts(context.Background(), "Requests pass through a load balancer")
+	require.NoError(t, err)
+
+	style, _ := LookupImageStyle("dark-technical")
+	styled, err := client.buildVisualizationPrompts(WithImageStyle(context.Background(), style), "Requests pass through a load balancer")
+	require.NoError(t, err)
+	require.Len(t, styled, len(plain))
+	for i := range styled {
+		assert.Equal(t, plain[i].Prompt+" "+style.Modifier, styled[i].Prompt)
+		assert.Equal(t, plain[i].Caption, styled[i].Caption)
+	}
+}
@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LessonJudgement represents rubric-based scores assigned to a lesson by an LLM judge
+type LessonJudgement struct {
+	Scores    map[string]float64 `json:"scores"`    // Score per rubric criterion, 0-10
+	Overall   float64            `json:"overall"`   // Overall score, 0-10
+	Rationale string             `json:"rationale"` // Short justification for the scores
+}
+
+// JudgeLesson scores a lesson against each criterion of the rubric.
+// A nil rubric uses DefaultRubric.
+func (c *GeminiClient) JudgeLesson(ctx context.Context, rubric *Rubric, topic, lessonJSON string) (*LessonJudgement, error) {
+	if rubric == nil {
+		rubric = DefaultRubric()
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"topic":     topic,
+		"rubric_id": rubric.ID,
+		"model":     c.model,
+	}).Info("Judging lesson with Gemini")
+
+	response, err := c.executeRequest(ctx, c.buildJudgePrompt(rubric, topic, lessonJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute judge request: %w", err)
+	}
+
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+
+	return parseLessonJudgement(text.String(), rubric)
+}
+
+// buildJudgePrompt creates the prompt for rubric-based lesson judging
+func (c *GeminiClient) buildJudgePrompt(rubric *Rubric, topic, lessonJSON string) string {
+	var promptBuilder strings.Builder
+
+	promptBuilder.WriteString("You are an impartial reviewer grading an educational lesson.\n\n")
+	promptBuilder.WriteString(fmt.Sprintf("Topic: %s\n\n", topic))
+	promptBuilder.WriteString("Lesson (JSON):\n")
+	promptBuilder.WriteString(lessonJSON)
+	promptBuilder.WriteString("\n\nScore the lesson from 0 (unacceptable) to 10 (excellent) on each criterion:\n")
+	for _, criterion := range rubric.Criteria {
+		promptBuilder.WriteString(fmt.Sprintf("- %s: %s\n", criterion.Name, criterion.Description))
+	}
+	if rubric.Instructions != "" {
+		promptBuilder.WriteString(fmt.Sprintf("\nAdditional instructions: %s\n", rubric.Instructions))
+	}
+	promptBuilder.WriteString("\nRespond with a single JSON object and nothing else, in this format:\n")
+	promptBuilder.WriteString(`{"scores": {"<criterion name>": <score>, ...}, "overall": <score>, "rationale": "<one or two sentences>"}`)
+	promptBuilder.WriteString("\n")
+
+	return promptBuilder.String()
+}
+
+// parseLessonJudgement extracts the judgement JSON object from a judge response.
+// Scores are clamped to [0, 10]; a missing overall score is the mean of the criterion scores.
+func parseLessonJudgement(responseText string, rubric *Rubric) (*LessonJudgement, error) {
+	start := strings.Index(responseText, "{")
+	end := strings.LastIndex(responseText, "}")
+	if start == -1 || end <= start {
+		return nil, fmt.Errorf("no judgement object found in judge response")
+	}
+
+	var judgement LessonJudgement
+	if err := json.Unmarshal([]byte(responseText[start:end+1]), &judgement); err != nil {
+		return nil, fmt.Errorf("failed to parse judgement: %w", err)
+	}
+
+	scores := make(map[string]float64, len(rubric.Criteria))
+	for _, criterion := range rubric.Criteria {
+		score, ok := judgement.Scores[criterion.Name]
+		if !ok {
+			return nil, fmt.Errorf("judgement is missing a score for criterion %q", criterion.Name)
+		}
+		scores[criterion.Name] = clampScore(score)
+	}
+	judgement.Scores = scores
+
+	if judgement.Overall <= 0 && len(scores) > 0 {
+		var total float64
+		for _, score := range scores {
+			total += score
+		}
+		judgement.Overall = total / float64(len(scores))
+	}
+	judgement.Overall = clampScore(judgement.Overall)
+	judgement.Rationale = strings.TrimSpace(judgement.Rationale)
+
+	return &judgement, nil
+}
+
+// clampScore limits a judge score to [0, 10]
+func clampScore(score float64) float64 {
+	if score < 0 {
+		return 0
+	}
+	if score > 10 {
+		return 10
+	}
+	return score
+}
@@ -0,0 +1,39 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseLessonJudgement tests extracting and clamping judge scores
+func TestParseLessonJudgement(t *testing.T) {
+	rubric := &Rubric{
+		ID:       "test",
+		Criteria: []RubricCriterion{{Name: "Clarity"}, {Name: "Accuracy"}},
+	}
+
+	judgement, err := parseLessonJudgement("Result:\n{\"scores\": {\"Clarity\": 8, \"Accuracy\": 12}, \"rationale\": \" good \"}", rubric)
+	require.NoError(t, err)
+	assert.Equal(t, 8.0, judgement.Scores["Clarity"])
+	assert.Equal(t, 10.0, judgement.Scores["Accuracy"])
+	assert.Equal(t, 9.0, judgement.Overall)
+	assert.Equal(t, "good", judgement.Rationale)
+
+	_, err = parseLessonJudgement(`{"scores": {"Clarity": 8}, "overall": 8}`, rubric)
+	assert.Error(t, err)
+
+	_, err = parseLessonJudgement("no json here", rubric)
+	assert.Error(t, err)
+}
+
+// TestBuildJudgePrompt tests that every rubric criterion is listed in the prompt
+func TestBuildJudgePrompt(t *testing.T) {
+	client := NewGeminiClient("test-api-key")
+	prompt := client.buildJudgePrompt(DefaultRubric(), "goroutines", `{"big_picture":"x"}`)
+	for _, criterion := range DefaultRubric().Criteria {
+		assert.Contains(t, prompt, criterion.Name)
+	}
+	assert.Contains(t, prompt, "Topic: goroutines")
+}
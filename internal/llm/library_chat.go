@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LibraryPassage is an excerpt of a saved lesson used to answer a study question
+type LibraryPassage struct {
+	Title   string // Title of the saved lesson
+	Section string // Section heading, e.g. "Core Mechanism"
+	Text    string
+}
+
+// AnswerFromLibrary answers a question from passages of the learner's saved lessons,
+// citing the passages it used by number, e.g. [2]
+func (c *GeminiClient) AnswerFromLibrary(ctx context.Context, question string, passages []LibraryPassage) (string, error) {
+	c.logger.WithFields(logrus.Fields{
+		"passages": len(passages),
+		"model":    c.model,
+	}).Info("Answering question from saved lessons")
+
+	response, err := c.executeRequest(ctx, buildLibraryChatPrompt(question, passages))
+	if err != nil {
+		return "", fmt.Errorf("failed to execute library question request: %w", err)
+	}
+	if len(response.Candidates) == 0 {
+		return "", fmt.Errorf("no candidates in response")
+	}
+
+	var answer strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		answer.WriteString(part.Text)
+	}
+	if strings.TrimSpace(answer.String()) == "" {
+		return "", fmt.Errorf("empty answer in response")
+	}
+	return strings.TrimSpace(answer.String()), nil
+}
+
+// buildLibraryChatPrompt creates the prompt for a question over numbered saved-lesson passages
+func buildLibraryChatPrompt(question string, passages []LibraryPassage) string {
+	var promptBuilder strings.Builder
+	promptBuilder.WriteString("You are a study assistant answering a learner's question from lessons they saved earlier.\n\n")
+	promptBuilder.WriteString("Passages from the learner's saved lessons:\n")
+	for i, passage := range passages {
+		promptBuilder.WriteString(fmt.Sprintf("[%d] %s - %s\n%s\n\n", i+1, passage.Title, passage.Section, passage.Text))
+	}
+	promptBuilder.WriteString(fmt.Sprintf("Question: %s\n\n", question))
+	promptBuilder.WriteString("Answer using only the passages above and cite each passage you use by its number in square brackets, e.g. [2]. ")
+	promptBuilder.WriteString("If the passages do not answer the question, say so briefly and suggest a topic to study. ")
+	promptBuilder.WriteString("Keep the answer under 200 words and respond in plain text.")
+	return promptBuilder.String()
+}
@@ -0,0 +1,18 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuildLibraryChatPrompt tests that passages are numbered for citation
+func TestBuildLibraryChatPrompt(t *testing.T) {
+	prompt := buildLibraryChatPrompt("Why do caches expire entries?", []LibraryPassage{
+		{Title: "Caching", Section: "Best Practices", Text: "Set an expiry so stale data is dropped."},
+		{Title: "CDNs", Section: "Core Mechanism", Text: "Edge servers keep copies near users."},
+	})
+	assert.Contains(t, prompt, "[1] Caching - Best Practices\nSet an expiry")
+	assert.Contains(t, prompt, "[2] CDNs - Core Mechanism")
+	assert.Contains(t, prompt, "Question: Why do caches expire entries?")
+}
@@ -0,0 +1,38 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLiteClient tests that the lite client produces a complete lesson flow without a model
+func TestLiteClient(t *testing.T) {
+	var client GeminiClientInterface = NewLiteClient()
+	ctx := context.Background()
+
+	summary, err := client.Summarize(ctx, "Caching", "")
+	require.NoError(t, err)
+	assert.Len(t, summary.Outline, 3)
+
+	lesson, err := client.ExplainWithOG(ctx, "Caching", "", "", "")
+	require.NoError(t, err)
+	assert.Contains(t, lesson.BigPicture, "Caching")
+	lessonJSON, err := json.Marshal(lesson)
+	require.NoError(t, err)
+
+	critique, err := client.CritiqueLesson(ctx, string(lessonJSON))
+	require.NoError(t, err)
+	assert.Empty(t, critique.Issues)
+
+	visuals, err := client.VisualizeCore(ctx, string(lessonJSON), "s1")
+	require.NoError(t, err)
+	require.Len(t, visuals.Images, 1)
+	assert.NotEmpty(t, visuals.Images[0].AltText)
+
+	_, err = client.CritiqueLesson(ctx, "not json")
+	assert.Error(t, err)
+}
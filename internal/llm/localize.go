@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LocalizedSections are the lesson sections a localization pass adapts: the metaphor and real-life
+// examples get culturally relevant references, the code example its comments, and the best
+// practices their naming guidance
+var LocalizedSections = []string{"metaphor", "toy_example_code", "real_life", "best_practices"}
+
+// LocalizeLesson adapts a lesson's examples and code comments for learners reading it in language.
+// It returns the localized text of each LocalizedSections field the lesson has; sections the
+// lesson leaves empty are not returned.
+func (c *GeminiClient) LocalizeLesson(ctx context.Context, topic string, lesson OGLesson, language string) (map[string]string, error) {
+	sections := localizableSections(lesson)
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("no sections to localize")
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"topic":    topic,
+		"language": language,
+		"sections": sections,
+		"model":    c.model,
+	}).Info("Localizing lesson with Gemini")
+
+	response, err := c.executeRequest(ctx, buildLocalizePrompt(topic, lesson, sections, language, PersonaFromContext(ctx)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute localize request: %w", err)
+	}
+	if len(response.Candidates) == 0 {
+		return nil, fmt.Errorf("no candidates in response")
+	}
+
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return parseRegeneratedSections(text.String(), sections)
+}
+
+// localizableSections returns the LocalizedSections fields the lesson has text for
+func localizableSections(lesson OGLesson) []string {
+	var sections []string
+	for _, field := range LocalizedSections {
+		if text, _ := lesson.SectionText(field); strings.TrimSpace(text) != "" {
+			sections = append(sections, field)
+		}
+	}
+	return sections
+}
+
+// buildLocalizePrompt creates the prompt for localizing lesson sections into a language.
+// Sections that are not localized are included as context so the examples stay consistent.
+func buildLocalizePrompt(topic string, lesson OGLesson, sections []string, language string, persona *Persona) string {
+	localize := make(map[string]bool, len(sections))
+	for _, field := range sections {
+		localize[field] = true
+	}
+
+	var promptBuilder strings.Builder
+	promptBuilder.WriteString("You are an expert educator localizing a lesson for learners who read it in " + language + ".\n\n")
+	promptBuilder.WriteString(fmt.Sprintf("Topic: %s\n\n", topic))
+	writePersona(&promptBuilder, persona)
+
+	promptBuilder.WriteString("The rest of the lesson, for context only:\n\n")
+	for _, section := range LessonSections {
+		if localize[section.Field] {
+			continue
+		}
+		if text, _ := lesson.SectionText(section.Field); text != "" {
+			promptBuilder.WriteString(fmt.Sprintf("%s (%s):\n%s\n\n", section.Title, section.Field, text))
+		}
+	}
+
+	promptBuilder.WriteString("Localize these sections:\n")
+	promptBuilder.WriteString(fmt.Sprintf("- Write all prose in %s.\n", language))
+	promptBuilder.WriteString("- Replace metaphors and real-life examples with references familiar to people in regions where " + language + " is spoken: local places, institutions, foods, sports and everyday situations. Keep the point each example makes.\n")
+	promptBuilder.WriteString(fmt.Sprintf("- Translate code comments and string literals shown to users into %s. Keep keywords, library names, API calls and the code's behavior unchanged.\n", language))
+	promptBuilder.WriteString("- Keep identifiers in ASCII. Where the lesson gives naming guidance, explain how teams writing in " + language + " usually name variables, for example English identifiers with comments in " + language + ".\n\n")
+
+	fields := make([]string, 0, len(sections))
+	for _, section := range LessonSections {
+		if !localize[section.Field] {
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%q", section.Field))
+		current, _ := lesson.SectionText(section.Field)
+		promptBuilder.WriteString(fmt.Sprintf("%s (%s), current version:\n%s\n\n", section.Title, section.Field, current))
+	}
+
+	promptBuilder.WriteString(fmt.Sprintf("Respond with a JSON object containing exactly these fields: %s. ", strings.Join(fields, ", ")))
+	promptBuilder.WriteString("Each value is the complete localized text for that section. Do not include any other fields.\n\nYour JSON response:\n")
+	return promptBuilder.String()
+}
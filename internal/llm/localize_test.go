@@ -0,0 +1,27 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuildLocalizePrompt tests that only sections with examples or code are localized, the rest given as context
+func TestBuildLocalizePrompt(t *testing.T) {
+	lesson := OGLesson{
+		BigPicture:     "Caches keep hot data close",
+		Metaphor:       "A desk drawer",
+		ToyExampleCode: "# look up the key first\ncache[key] = value",
+	}
+
+	sections := localizableSections(lesson)
+	assert.Equal(t, []string{"metaphor", "toy_example_code"}, sections)
+
+	prompt := buildLocalizePrompt("Caching", lesson, sections, "Japanese", nil)
+	assert.Contains(t, prompt, "learners who read it in Japanese")
+	assert.Contains(t, prompt, "Big Picture (big_picture):\nCaches keep hot data close")
+	assert.Contains(t, prompt, "Toy Example (toy_example_code), current version:\n# look up the key first")
+	assert.Contains(t, prompt, "Translate code comments")
+	assert.Contains(t, prompt, `exactly these fields: "metaphor", "toy_example_code"`)
+	assert.NotContains(t, prompt, "Real Life (real_life)")
+}
@@ -0,0 +1,209 @@
+package llm
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	// codeSpanPattern matches inline code
+	codeSpanPattern = regexp.MustCompile("`([^`\n]+)`")
+	// linkPattern matches an inline Markdown link
+	linkPattern = regexp.MustCompile(`\[([^\]\n]+)\]\(([^()\s]+)\)`)
+	// boldPattern and italicPattern match emphasis in escaped text
+	boldPattern   = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	italicPattern = regexp.MustCompile(`\*([^*\s][^*\n]*)\*`)
+	// headingPattern, bulletPattern and numberedPattern match block-level Markdown lines
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	bulletPattern   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	numberedPattern = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	// fenceLanguagePattern keeps only characters safe in a class name from a code fence's info string
+	fenceLanguagePattern = regexp.MustCompile(`[^a-z0-9+#-]`)
+	// lineBreakTagPattern matches a <br> tag in escaped text
+	lineBreakTagPattern = regexp.MustCompile(`&lt;br\s*/?&gt;`)
+)
+
+// allowedInlineTags are the attribute-free HTML tags kept from model output; all other markup is escaped
+var allowedInlineTags = []string{"b", "strong", "i", "em", "code", "sub", "sup"}
+
+// allowedTagPatterns match a balanced pair of each allowed tag in escaped text
+var allowedTagPatterns = func() map[string]*regexp.Regexp {
+	patterns := make(map[string]*regexp.Regexp, len(allowedInlineTags))
+	for _, tag := range allowedInlineTags {
+		patterns[tag] = regexp.MustCompile(fmt.Sprintf(`(?i)&lt;%s&gt;(.*?)&lt;/%s&gt;`, tag, tag))
+	}
+	return patterns
+}()
+
+// RenderMarkdown renders model-written Markdown as sanitized HTML. All text is escaped before
+// any markup is added, so raw HTML in the input can never reach the output; only links with
+// http, https or mailto URLs and a few attribute-free inline tags survive. Headings start at
+// <h3> so rendered sections nest under their <h2> section titles, and fenced code blocks are
+// syntax highlighted.
+func RenderMarkdown(text string) string {
+	var b strings.Builder
+	var paragraph []string
+	listTag := ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + renderInline(strings.Join(paragraph, "\n")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			b.WriteString("</" + listTag + ">\n")
+			listTag = ""
+		}
+	}
+	openList := func(tag string) {
+		flushParagraph()
+		if listTag != tag {
+			closeList()
+			b.WriteString("<" + tag + ">\n")
+			listTag = tag
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			flushParagraph()
+			closeList()
+			language := fenceLanguagePattern.ReplaceAllString(strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))), "")
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			b.WriteString(renderCodeBlock(strings.Join(code, "\n"), language))
+			continue
+		}
+
+		if trimmed == "" {
+			flushParagraph()
+			closeList()
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(trimmed); m != nil {
+			flushParagraph()
+			closeList()
+			level := min(len(m[1])+2, 6)
+			b.WriteString(fmt.Sprintf("<h%d>%s</h%d>\n", level, renderInline(m[2]), level))
+			continue
+		}
+		if m := bulletPattern.FindStringSubmatch(line); m != nil {
+			openList("ul")
+			b.WriteString("<li>" + renderInline(m[1]) + "</li>\n")
+			continue
+		}
+		if m := numberedPattern.FindStringSubmatch(line); m != nil {
+			openList("ol")
+			b.WriteString("<li>" + renderInline(m[1]) + "</li>\n")
+			continue
+		}
+		closeList()
+		paragraph = append(paragraph, trimmed)
+	}
+	flushParagraph()
+	closeList()
+	return b.String()
+}
+
+// RenderLessonHTML renders a lesson's sections as sanitized HTML, one <section> per non-empty
+// section with the same anchors as the table of contents
+func RenderLessonHTML(lesson *OGLesson) string {
+	var b strings.Builder
+	for _, section := range LessonSections {
+		text, _ := lesson.SectionText(section.ID)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("<section id=\"%s\">\n<h2>%s</h2>\n", section.ID, html.EscapeString(section.Title)))
+		if section.Field == "toy_example_code" && !strings.HasPrefix(strings.TrimSpace(text), "```") {
+			code := strings.Trim(text, "\n")
+			b.WriteString(renderCodeBlock(code, GuessCodeLanguage(code)))
+		} else {
+			b.WriteString(RenderMarkdown(text))
+		}
+		b.WriteString("</section>\n")
+	}
+	return b.String()
+}
+
+// renderCodeBlock renders a highlighted code block, labelled with its language when known
+func renderCodeBlock(code, language string) string {
+	if language == "" {
+		return "<pre><code>" + HighlightCode(code, "") + "</code></pre>\n"
+	}
+	return fmt.Sprintf("<pre><code class=\"language-%s\">%s</code></pre>\n", language, HighlightCode(code, language))
+}
+
+// renderInline renders inline Markdown: code spans, links and emphasis
+func renderInline(text string) string {
+	return replaceSpans(text, codeSpanPattern, func(m []string) string {
+		return "<code>" + html.EscapeString(m[1]) + "</code>"
+	}, renderLinks)
+}
+
+// renderLinks renders links with safe URLs, keeping only the text of any others
+func renderLinks(text string) string {
+	return replaceSpans(text, linkPattern, func(m []string) string {
+		label := renderEmphasis(m[1])
+		if !safeLinkURL(m[2]) {
+			return label
+		}
+		return fmt.Sprintf("<a href=\"%s\" rel=\"nofollow noopener noreferrer\">%s</a>", html.EscapeString(m[2]), label)
+	}, renderEmphasis)
+}
+
+// renderEmphasis escapes text, then restores the allowed inline tags and renders bold and italics
+func renderEmphasis(text string) string {
+	escaped := html.EscapeString(text)
+	escaped = lineBreakTagPattern.ReplaceAllString(escaped, "<br>")
+	for _, tag := range allowedInlineTags {
+		escaped = allowedTagPatterns[tag].ReplaceAllString(escaped, "<"+tag+">$1</"+tag+">")
+	}
+	escaped = boldPattern.ReplaceAllString(escaped, "<strong>$1</strong>")
+	return italicPattern.ReplaceAllString(escaped, "<em>$1</em>")
+}
+
+// replaceSpans renders the matches of pattern with match and the text between them with other
+func replaceSpans(text string, pattern *regexp.Regexp, match func([]string) string, other func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(other(text[last:loc[0]]))
+		groups := make([]string, len(loc)/2)
+		for i := range groups {
+			if loc[2*i] >= 0 {
+				groups[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		b.WriteString(match(groups))
+		last = loc[1]
+	}
+	b.WriteString(other(text[last:]))
+	return b.String()
+}
+
+// safeLinkURL reports whether a link URL uses a scheme that cannot run script
+func safeLinkURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRenderMarkdown tests rendering the Markdown constructs lessons use
+func TestRenderMarkdown(t *testing.T) {
+	out := RenderMarkdown("## Why caches\n\nA **cache** keeps *hot* data close, see [the docs](https://example.com/a?b=1&c=2).\n\n- Fast\n- Small\n\n1. Check\n2. Fetch\n\nUse `get(key)` first.")
+
+	assert.Contains(t, out, "<h4>Why caches</h4>")
+	assert.Contains(t, out, "<strong>cache</strong>")
+	assert.Contains(t, out, "<em>hot</em>")
+	assert.Contains(t, out, `<a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer">the docs</a>`)
+	assert.Contains(t, out, "<ul>\n<li>Fast</li>\n<li>Small</li>\n</ul>")
+	assert.Contains(t, out, "<ol>\n<li>Check</li>\n<li>Fetch</li>\n</ol>")
+	assert.Contains(t, out, "<p>Use <code>get(key)</code> first.</p>")
+}
+
+// TestRenderMarkdownSanitizes tests that markup in model output cannot inject script
+func TestRenderMarkdownSanitizes(t *testing.T) {
+	for _, input := range []string{
+		`<script>alert(1)</script>`,
+		`<img src=x onerror="alert(1)">`,
+		`[click](javascript:alert(1))`,
+		`[click](data:text/html,<script>alert(1)</script>)`,
+		"`<script>` and **<b onclick=alert(1)>x</b>**",
+		"```\n</code></pre><script>alert(1)</script>\n```",
+		`<b>bold <script>alert(1)</script></b>`,
+	} {
+		out := RenderMarkdown(input)
+		for _, unsafe := range []string{"<script", "<img", "onerror=\"", "href=\"javascript", "href=\"data", "<b onclick"} {
+			assert.NotContains(t, out, unsafe, input)
+		}
+	}
+
+	// Attribute-free inline tags survive, unbalanced ones stay escaped
+	assert.Contains(t, RenderMarkdown("A <b>bold</b> claim<br>next"), "A <b>bold</b> claim<br>next")
+	assert.Contains(t, RenderMarkdown("An <em>open tag"), "An &lt;em&gt;open tag")
+}
+
+// TestHighlightCode tests tokenizing code for syntax highlighting
+func TestHighlightCode(t *testing.T) {
+	out := HighlightCode("def add(a, b):\n    # sum \"<b>\"\n    return a + 1 if a < b else 'x'", "py")
+	assert.Contains(t, out, `<span class="tok-keyword">def</span> add`)
+	assert.Contains(t, out, `<span class="tok-comment"># sum &#34;&lt;b&gt;&#34;</span>`)
+	assert.Contains(t, out, `<span class="tok-number">1</span>`)
+	assert.Contains(t, out, `<span class="tok-string">&#39;x&#39;</span>`)
+	assert.Contains(t, out, "a &lt; b")
+
+	out = HighlightCode("x := \"a\\\"b\" /* note */", "go")
+	assert.Contains(t, out, `<span class="tok-string">&#34;a\&#34;b&#34;</span>`)
+	assert.Contains(t, out, `<span class="tok-comment">/* note */</span>`)
+}
+
+// TestRenderLessonHTML tests rendering a lesson's sections with highlighted example code
+func TestRenderLessonHTML(t *testing.T) {
+	out := RenderLessonHTML(&OGLesson{
+		BigPicture:     "Caches are **fast**.",
+		ToyExampleCode: "func get(k string) string {\n\treturn cache[k]\n}",
+	})
+	assert.Contains(t, out, "<section id=\"big-picture\">\n<h2>Big Picture</h2>\n<p>Caches are <strong>fast</strong>.</p>")
+	assert.Contains(t, out, `<pre><code class="language-go"><span class="tok-keyword">func</span> get`)
+	assert.NotContains(t, out, `id="metaphor"`)
+	assert.Equal(t, 2, strings.Count(out, "</section>"))
+}
+
+// TestMarkdownToPlainText tests stripping Markdown and HTML for plain-text surfaces
+func TestMarkdownToPlainText(t *testing.T) {
+	out := MarkdownToPlainText("## Why caches\n\nA **cache** keeps *hot* data <b>close</b>, see [the docs](https://example.com).\n\n\n\n- Fast\n- Small ![diagram](data:image/png;base64,iVBORw0KGgo=)\n\n```go\nv := cache[`k`]\n```")
+	assert.Equal(t, "Why caches\n\nA cache keeps hot data close, see the docs.\n\n• Fast\n• Small diagram\n\nv := cache[`k`]", out)
+	assert.NotContains(t, MarkdownToPlainText("inline data:image/png;base64,AAAA== here"), "base64")
+}
+
+// TestTruncateText tests shortening text at a word boundary
+func TestTruncateText(t *testing.T) {
+	out, truncated := TruncateText("Caches keep frequently used data close to the processor.", 30)
+	assert.True(t, truncated)
+	assert.Equal(t, "Caches keep frequently used…", out)
+	assert.LessOrEqual(t, len([]rune(out)), 30)
+
+	out, truncated = TruncateText("Short", 30)
+	assert.False(t, truncated)
+	assert.Equal(t, "Short", out)
+
+	out, _ = TruncateText("ééééééééééé", 5)
+	assert.Equal(t, "éééé…", out)
+}
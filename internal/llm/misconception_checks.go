@@ -0,0 +1,130 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MinMisconceptionChecks is the fewest misconceptions worth quizzing a learner on
+	MinMisconceptionChecks = 2
+	// MaxMisconceptionChecks is the most true/false checks appended to a lesson
+	MaxMisconceptionChecks = 3
+)
+
+// MisconceptionCheck is a true/false statement testing whether a learner holds a misconception
+type MisconceptionCheck struct {
+	ID            string `json:"id"`
+	Statement     string `json:"statement"`
+	Answer        bool   `json:"answer"`        // Whether the statement is true
+	Explanation   string `json:"explanation"`   // Why, shown after the learner answers
+	Misconception string `json:"misconception"` // The misconception the check targets
+}
+
+// GenerateMisconceptionChecks writes one true/false check for each of the first
+// MaxMisconceptionChecks misconceptions, mixing true corrections with false restatements
+func (c *GeminiClient) GenerateMisconceptionChecks(ctx context.Context, topic string, misconceptions []string) ([]MisconceptionCheck, error) {
+	if len(misconceptions) > MaxMisconceptionChecks {
+		misconceptions = misconceptions[:MaxMisconceptionChecks]
+	}
+	if len(misconceptions) == 0 {
+		return nil, fmt.Errorf("no misconceptions to check")
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"topic":          topic,
+		"misconceptions": len(misconceptions),
+		"model":          c.model,
+	}).Info("Generating misconception checks with Gemini")
+
+	response, err := c.executeRequest(ctx, buildMisconceptionChecksPrompt(topic, misconceptions))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute misconception check request: %w", err)
+	}
+	if len(response.Candidates) == 0 {
+		return nil, fmt.Errorf("no candidates in response")
+	}
+
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return parseMisconceptionChecks(text.String(), misconceptions)
+}
+
+// buildMisconceptionChecksPrompt creates the prompt asking for one check per misconception
+func buildMisconceptionChecksPrompt(topic string, misconceptions []string) string {
+	var promptBuilder strings.Builder
+	promptBuilder.WriteString("You are an expert educator writing a short true/false check after a lesson.\n\n")
+	promptBuilder.WriteString(fmt.Sprintf("Topic: %s\n\n", topic))
+	promptBuilder.WriteString("Common misconceptions about this topic:\n")
+	for i, misconception := range misconceptions {
+		promptBuilder.WriteString(fmt.Sprintf("%d. %s\n", i+1, misconception))
+	}
+	promptBuilder.WriteString("\nWrite exactly one true/false statement per misconception, in the same order. ")
+	promptBuilder.WriteString("Mix the answers: some statements restate the misconception (false), others state the correct idea (true). ")
+	promptBuilder.WriteString("Keep each statement to one sentence and each explanation to at most two.\n\n")
+	promptBuilder.WriteString(`Respond with a JSON array of objects with the fields "statement", "answer" (true or false) and "explanation".`)
+	promptBuilder.WriteString("\n\nYour JSON response:\n")
+	return promptBuilder.String()
+}
+
+// parseMisconceptionChecks extracts the checks from the response text, pairing each with its misconception
+func parseMisconceptionChecks(responseText string, misconceptions []string) ([]MisconceptionCheck, error) {
+	jsonStart := strings.Index(responseText, "[")
+	jsonEnd := strings.LastIndex(responseText, "]")
+	if jsonStart == -1 || jsonEnd <= jsonStart {
+		return nil, fmt.Errorf("no JSON array found in response")
+	}
+
+	var raw []struct {
+		Statement   string `json:"statement"`
+		Answer      bool   `json:"answer"`
+		Explanation string `json:"explanation"`
+	}
+	if err := json.Unmarshal([]byte(responseText[jsonStart:jsonEnd+1]), &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal misconception checks: %w", err)
+	}
+	if len(raw) != len(misconceptions) {
+		return nil, fmt.Errorf("expected %d misconception checks, got %d", len(misconceptions), len(raw))
+	}
+
+	checks := make([]MisconceptionCheck, len(raw))
+	for i, item := range raw {
+		statement := strings.TrimSpace(item.Statement)
+		if statement == "" {
+			return nil, fmt.Errorf("misconception check %d has no statement", i+1)
+		}
+		checks[i] = MisconceptionCheck{
+			ID:            fmt.Sprintf("check-%d", i+1),
+			Statement:     statement,
+			Answer:        item.Answer,
+			Explanation:   strings.TrimSpace(item.Explanation),
+			Misconception: misconceptions[i],
+		}
+	}
+	return checks, nil
+}
+
+// MisconceptionChecksFromList builds checks without a model call: each misconception is
+// stated as it is commonly believed, so every answer is false
+func MisconceptionChecksFromList(misconceptions []string) []MisconceptionCheck {
+	if len(misconceptions) > MaxMisconceptionChecks {
+		misconceptions = misconceptions[:MaxMisconceptionChecks]
+	}
+	checks := make([]MisconceptionCheck, 0, len(misconceptions))
+	for i, misconception := range misconceptions {
+		checks = append(checks, MisconceptionCheck{
+			ID:            fmt.Sprintf("check-%d", i+1),
+			Statement:     misconception,
+			Answer:        false,
+			Explanation:   "This is a common misconception; the lesson explains why it does not hold.",
+			Misconception: misconception,
+		})
+	}
+	return checks
+}
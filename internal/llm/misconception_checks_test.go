@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseMisconceptionChecks tests that checks are paired with their misconceptions in order
+func TestParseMisconceptionChecks(t *testing.T) {
+	misconceptions := []string{"Goroutines are OS threads", "Channels are always buffered"}
+	response := "Here you go:\n```json\n[" +
+		`{"statement":"Goroutines are scheduled onto OS threads by the Go runtime.","answer":true,"explanation":"Many goroutines share few threads."},` +
+		`{"statement":"Every channel has a buffer.","answer":false,"explanation":"Unbuffered channels synchronize sender and receiver."}` +
+		"]\n```"
+
+	checks, err := parseMisconceptionChecks(response, misconceptions)
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+	assert.Equal(t, "check-1", checks[0].ID)
+	assert.True(t, checks[0].Answer)
+	assert.Equal(t, "Goroutines are OS threads", checks[0].Misconception)
+	assert.False(t, checks[1].Answer)
+
+	_, err = parseMisconceptionChecks(`[{"statement":"Only one","answer":true}]`, misconceptions)
+	assert.Error(t, err, "one check per misconception is required")
+	_, err = parseMisconceptionChecks("no json", misconceptions)
+	assert.Error(t, err)
+}
+
+// TestMisconceptionChecksFromList tests the fallback checks
+func TestMisconceptionChecksFromList(t *testing.T) {
+	checks := MisconceptionChecksFromList([]string{"a", "b", "c", "d"})
+	require.Len(t, checks, MaxMisconceptionChecks)
+	for _, check := range checks {
+		assert.False(t, check.Answer)
+		assert.Equal(t, check.Misconception, check.Statement)
+	}
+	assert.Contains(t, buildMisconceptionChecksPrompt("Go", []string{"a", "b"}), "2. b")
+}
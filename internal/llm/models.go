@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultModel is the model used when a request does not override it
+const DefaultModel = "gemini-2.5-flash"
+
+// ModelPolicy describes a model callers may request and what it costs against their quota
+type ModelPolicy struct {
+	Name            string  `json:"name"`
+	QuotaMultiplier float64 `json:"quota_multiplier"` // Quota units charged per request relative to the default model
+}
+
+// ModelAllowlist holds the models callers may request per session
+type ModelAllowlist struct {
+	policies map[string]ModelPolicy
+}
+
+// NewModelAllowlist creates an allowlist from model policies.
+// Multipliers below 1 are raised to 1 so overrides never cost less than the default model.
+func NewModelAllowlist(policies ...ModelPolicy) *ModelAllowlist {
+	allowlist := &ModelAllowlist{policies: make(map[string]ModelPolicy)}
+	for _, policy := range policies {
+		name := strings.TrimSpace(policy.Name)
+		if name == "" {
+			continue
+		}
+		if policy.QuotaMultiplier < 1 {
+			policy.QuotaMultiplier = 1
+		}
+		policy.Name = name
+		allowlist.policies[name] = policy
+	}
+	return allowlist
+}
+
+// DefaultModelAllowlist returns the built-in allowlist
+func DefaultModelAllowlist() *ModelAllowlist {
+	return NewModelAllowlist(
+		ModelPolicy{Name: DefaultModel, QuotaMultiplier: 1},
+		ModelPolicy{Name: "gemini-1.5-flash", QuotaMultiplier: 1},
+		ModelPolicy{Name: "gemini-1.5-pro", QuotaMultiplier: 3},
+		ModelPolicy{Name: "gemini-2.5-pro", QuotaMultiplier: 4},
+	)
+}
+
+// ParseModelAllowlist parses an allowlist spec such as "gemini-2.5-flash=1,gemini-1.5-pro=3".
+// Entries without a multiplier default to 1.
+func ParseModelAllowlist(spec string) (*ModelAllowlist, error) {
+	policies := make([]ModelPolicy, 0)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, multiplier, hasMultiplier := strings.Cut(entry, "=")
+		policy := ModelPolicy{Name: strings.TrimSpace(name), QuotaMultiplier: 1}
+		if hasMultiplier {
+			value, err := strconv.ParseFloat(strings.TrimSpace(multiplier), 64)
+			if err != nil || value <= 0 {
+				return nil, fmt.Errorf("invalid quota multiplier for model %q: %q", policy.Name, multiplier)
+			}
+			policy.QuotaMultiplier = value
+		}
+		policies = append(policies, policy)
+	}
+
+	if len(policies) == 0 {
+		return nil, fmt.Errorf("model allowlist is empty")
+	}
+	return NewModelAllowlist(policies...), nil
+}
+
+// Lookup returns the policy for a model if it is allowed
+func (a *ModelAllowlist) Lookup(model string) (ModelPolicy, bool) {
+	policy, ok := a.policies[strings.TrimSpace(model)]
+	return policy, ok
+}
+
+// List returns the allowed models sorted by name
+func (a *ModelAllowlist) List() []ModelPolicy {
+	policies := make([]ModelPolicy, 0, len(a.policies))
+	for _, policy := range a.policies {
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+	return policies
+}
+
+// modelContextKey is the context key for a request-scoped model override
+type modelContextKey struct{}
+
+// WithModel returns a context carrying a model override for Gemini requests
+func WithModel(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, modelContextKey{}, model)
+}
+
+// ModelFromContext returns the model override carried by the context, or ""
+func ModelFromContext(ctx context.Context) string {
+	model, _ := ctx.Value(modelContextKey{}).(string)
+	return model
+}
@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseModelAllowlist tests parsing allowlist specs
+func TestParseModelAllowlist(t *testing.T) {
+	allowlist, err := ParseModelAllowlist("gemini-2.5-flash, gemini-1.5-pro=3, cheap=0.5")
+	require.NoError(t, err)
+
+	policy, ok := allowlist.Lookup("gemini-1.5-pro")
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, policy.QuotaMultiplier)
+
+	policy, ok = allowlist.Lookup("gemini-2.5-flash")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, policy.QuotaMultiplier)
+
+	// Multipliers never drop below the default model's cost
+	policy, _ = allowlist.Lookup("cheap")
+	assert.Equal(t, 1.0, policy.QuotaMultiplier)
+
+	_, ok = allowlist.Lookup("gpt-4")
+	assert.False(t, ok)
+	assert.Len(t, allowlist.List(), 3)
+
+	_, err = ParseModelAllowlist("gemini-1.5-pro=lots")
+	assert.Error(t, err)
+	_, err = ParseModelAllowlist(" , ")
+	assert.Error(t, err)
+}
+
+// TestModelContext tests passing a model override through the context
+func TestModelContext(t *testing.T) {
+	assert.Equal(t, "", ModelFromContext(context.Background()))
+	assert.Equal(t, "gemini-1.5-pro", ModelFromContext(WithModel(context.Background(), "gemini-1.5-pro")))
+}
@@ -0,0 +1,213 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// Moderation actions, from least to most severe
+const (
+	ModerationPassed   = "passed"   // Nothing violated policy
+	ModerationRedacted = "redacted" // Violating passages were replaced with ModerationRedaction
+	ModerationBlocked  = "blocked"  // The content must not be shown or stored
+)
+
+// ModerationRedaction replaces redacted passages
+const ModerationRedaction = "[removed by moderation]"
+
+// ModerationResult is the outcome of moderating a set of texts
+type ModerationResult struct {
+	Action     string   `json:"action"`
+	Categories []string `json:"categories,omitempty"` // Policy categories or denylist patterns that matched
+	Redactions int      `json:"redactions,omitempty"`
+	Texts      []string `json:"-"` // The texts after redaction, in input order
+}
+
+// Moderator checks generated content against a content policy
+type Moderator interface {
+	Moderate(ctx context.Context, texts []string) (*ModerationResult, error)
+}
+
+// moderationSeverity orders actions so combined results keep the most severe
+var moderationSeverity = map[string]int{ModerationPassed: 0, ModerationRedacted: 1, ModerationBlocked: 2}
+
+// DenylistModerator redacts or blocks text matching any of its patterns
+type DenylistModerator struct {
+	patterns []*regexp.Regexp
+	block    bool
+}
+
+// NewDenylistModerator compiles case-insensitive denylist patterns. Matches are redacted,
+// or block the content entirely when block is set.
+func NewDenylistModerator(patterns []string, block bool) (*DenylistModerator, error) {
+	m := &DenylistModerator{block: block}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid denylist pattern %q: %w", pattern, err)
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m, nil
+}
+
+// Moderate implements Moderator
+func (m *DenylistModerator) Moderate(ctx context.Context, texts []string) (*ModerationResult, error) {
+	result := &ModerationResult{Action: ModerationPassed, Texts: make([]string, len(texts))}
+	matched := make(map[string]bool)
+	for i, text := range texts {
+		for _, re := range m.patterns {
+			matches := len(re.FindAllStringIndex(text, -1))
+			if matches == 0 {
+				continue
+			}
+			matched[re.String()[len("(?i)"):]] = true
+			result.Redactions += matches
+			text = re.ReplaceAllLiteralString(text, ModerationRedaction)
+		}
+		result.Texts[i] = text
+	}
+
+	if len(matched) == 0 {
+		return result, nil
+	}
+	for pattern := range matched {
+		result.Categories = append(result.Categories, "denylist:"+pattern)
+	}
+	sort.Strings(result.Categories)
+	result.Action = ModerationRedacted
+	if m.block {
+		result.Action = ModerationBlocked
+		result.Texts = nil
+		result.Redactions = 0
+	}
+	return result, nil
+}
+
+// ModerationChain runs moderators in order, each over the previous one's redacted texts.
+// It stops at the first block.
+type ModerationChain []Moderator
+
+// Moderate implements Moderator
+func (chain ModerationChain) Moderate(ctx context.Context, texts []string) (*ModerationResult, error) {
+	combined := &ModerationResult{Action: ModerationPassed, Texts: texts}
+	for _, moderator := range chain {
+		result, err := moderator.Moderate(ctx, combined.Texts)
+		if err != nil {
+			return nil, err
+		}
+		if moderationSeverity[result.Action] > moderationSeverity[combined.Action] {
+			combined.Action = result.Action
+		}
+		combined.Categories = append(combined.Categories, result.Categories...)
+		combined.Redactions += result.Redactions
+		if result.Action == ModerationBlocked {
+			combined.Texts = nil
+			return combined, nil
+		}
+		combined.Texts = result.Texts
+	}
+	return combined, nil
+}
+
+// SafetyModerator blocks content Gemini's safety classifier rates at or above a harm probability
+type SafetyModerator struct {
+	client    *GeminiClient
+	threshold genai.HarmProbability
+}
+
+// NewSafetyModerator creates a moderator blocking content rated medium harm or higher
+func NewSafetyModerator(client *GeminiClient) *SafetyModerator {
+	return &SafetyModerator{client: client, threshold: genai.HarmProbabilityMedium}
+}
+
+// safetyCheckPrompt asks for a trivial reply so the ratings reflect the lesson, not the answer
+const safetyCheckPrompt = "Reply with the single word OK. The following text is an educational lesson:\n\n"
+
+// Moderate implements Moderator
+func (m *SafetyModerator) Moderate(ctx context.Context, texts []string) (*ModerationResult, error) {
+	generate := m.client.generate
+	if generate == nil {
+		if m.client.client == nil || m.client.Models == nil {
+			return nil, fmt.Errorf("Gemini client not initialized")
+		}
+		generate = m.client.Models.GenerateContent
+	}
+
+	model := m.client.model
+	if override := ModelFromContext(ctx); override != "" {
+		model = override
+	}
+
+	var ratings []*genai.SafetyRating
+	response, err := generate(ctx, model, genai.Text(safetyCheckPrompt+strings.Join(texts, "\n\n")), nil)
+	var blocked *genai.BlockedError
+	switch {
+	case errors.As(err, &blocked):
+		// The SDK reports a blocked prompt or candidate as an error carrying the ratings
+		if blocked.PromptFeedback != nil {
+			ratings = append(ratings, blocked.PromptFeedback.SafetyRatings...)
+		}
+		if blocked.Candidate != nil {
+			ratings = append(ratings, blocked.Candidate.SafetyRatings...)
+		}
+		if len(ratings) == 0 {
+			return &ModerationResult{Action: ModerationBlocked, Categories: []string{"safety:blocked"}}, nil
+		}
+	case err != nil:
+		return nil, fmt.Errorf("safety classification failed: %w", err)
+	default:
+		recordTokens(ctx, response.UsageMetadata)
+		if response.PromptFeedback != nil {
+			ratings = append(ratings, response.PromptFeedback.SafetyRatings...)
+		}
+		for _, candidate := range response.Candidates {
+			ratings = append(ratings, candidate.SafetyRatings...)
+		}
+	}
+
+	result := &ModerationResult{Action: ModerationPassed, Texts: texts}
+	seen := make(map[string]bool)
+	for _, rating := range ratings {
+		if rating == nil || (!rating.Blocked && rating.Probability < m.threshold) {
+			continue
+		}
+		category := "safety:" + harmCategoryName(rating.Category)
+		if !seen[category] {
+			seen[category] = true
+			result.Categories = append(result.Categories, category)
+		}
+	}
+	if len(result.Categories) > 0 {
+		sort.Strings(result.Categories)
+		result.Action = ModerationBlocked
+		result.Texts = nil
+	}
+	return result, nil
+}
+
+// harmCategoryName turns a harm category such as HarmCategoryDangerousContent into dangerous_content
+func harmCategoryName(category genai.HarmCategory) string {
+	name := strings.TrimPrefix(category.String(), "HarmCategory")
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDenylistModerator tests redacting and blocking denylisted passages
+func TestDenylistModerator(t *testing.T) {
+	moderator, err := NewDenylistModerator([]string{`bad\s*word`, " ", "secret-[0-9]+"}, false)
+	require.NoError(t, err)
+
+	result, err := moderator.Moderate(context.Background(), []string{"A BAD word here, and a badword there.", "Nothing to see.", "Key secret-42"})
+	require.NoError(t, err)
+	assert.Equal(t, ModerationRedacted, result.Action)
+	assert.Equal(t, 3, result.Redactions)
+	assert.Equal(t, []string{"denylist:bad\\s*word", "denylist:secret-[0-9]+"}, result.Categories)
+	assert.Equal(t, "A "+ModerationRedaction+" here, and a "+ModerationRedaction+" there.", result.Texts[0])
+	assert.Equal(t, "Nothing to see.", result.Texts[1])
+
+	blocking, err := NewDenylistModerator([]string{"secret-[0-9]+"}, true)
+	require.NoError(t, err)
+	result, err = blocking.Moderate(context.Background(), []string{"Key secret-42"})
+	require.NoError(t, err)
+	assert.Equal(t, ModerationBlocked, result.Action)
+	assert.Nil(t, result.Texts)
+
+	result, err = blocking.Moderate(context.Background(), []string{"All clear"})
+	require.NoError(t, err)
+	assert.Equal(t, ModerationPassed, result.Action)
+
+	_, err = NewDenylistModerator([]string{"("}, false)
+	assert.Error(t, err)
+}
+
+// TestModerationChain tests that the chain keeps the most severe action and stops at a block
+func TestModerationChain(t *testing.T) {
+	redact, _ := NewDenylistModerator([]string{"darn"}, false)
+	block, _ := NewDenylistModerator([]string{"forbidden"}, true)
+	chain := ModerationChain{redact, block}
+
+	result, err := chain.Moderate(context.Background(), []string{"darn it"})
+	require.NoError(t, err)
+	assert.Equal(t, ModerationRedacted, result.Action)
+	assert.Equal(t, []string{ModerationRedaction + " it"}, result.Texts)
+
+	result, err = chain.Moderate(context.Background(), []string{"darn, forbidden"})
+	require.NoError(t, err)
+	assert.Equal(t, ModerationBlocked, result.Action)
+	assert.Equal(t, []string{"denylist:darn", "denylist:forbidden"}, result.Categories)
+}
+
+// TestSafetyModerator tests blocking content the safety classifier rates as harmful
+func TestSafetyModerator(t *testing.T) {
+	var ratings []*genai.SafetyRating
+	var blockErr error
+	client := &GeminiClient{model: DefaultModel, logger: logrus.New()}
+	client.generate = func(ctx context.Context, model string, prompt genai.Part, config *genai.GenerationConfig) (*genai.GenerateContentResponse, error) {
+		if blockErr != nil {
+			return nil, blockErr
+		}
+		return &genai.GenerateContentResponse{
+			PromptFeedback: &genai.PromptFeedback{SafetyRatings: ratings},
+			Candidates:     []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text("OK")}}}},
+		}, nil
+	}
+	moderator := NewSafetyModerator(client)
+
+	ratings = []*genai.SafetyRating{{Category: genai.HarmCategoryHarassment, Probability: genai.HarmProbabilityLow}}
+	result, err := moderator.Moderate(context.Background(), []string{"A lesson about queues"})
+	require.NoError(t, err)
+	assert.Equal(t, ModerationPassed, result.Action)
+	assert.Equal(t, []string{"A lesson about queues"}, result.Texts)
+
+	ratings = []*genai.SafetyRating{{Category: genai.HarmCategoryDangerousContent, Probability: genai.HarmProbabilityHigh}}
+	result, err = moderator.Moderate(context.Background(), []string{"A dangerous lesson"})
+	require.NoError(t, err)
+	assert.Equal(t, ModerationBlocked, result.Action)
+	assert.Equal(t, []string{"safety:dangerous_content"}, result.Categories)
+
+	blockErr = &genai.BlockedError{PromptFeedback: &genai.PromptFeedback{BlockReason: genai.BlockReasonSafety}}
+	result, err = moderator.Moderate(context.Background(), []string{"A blocked lesson"})
+	require.NoError(t, err)
+	assert.Equal(t, ModerationBlocked, result.Action)
+}
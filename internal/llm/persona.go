@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxPersonaLength is the longest free-form persona accepted, in characters
+const MaxPersonaLength = 40
+
+// customPersonaPattern limits free-form personas to words joined by spaces, hyphens and apostrophes,
+// so an audience description cannot carry instructions into the prompt
+var customPersonaPattern = regexp.MustCompile(`^[\p{L}\p{N}]+(?:[ '-]+[\p{L}\p{N}]+)*$`)
+
+// Persona describes the audience a lesson should be written for ("explain like I'm X")
+type Persona struct {
+	Name       string `json:"name"`
+	Audience   string `json:"audience"`
+	Vocabulary string `json:"vocabulary"`
+	Depth      string `json:"depth"`
+}
+
+// knownPersonas maps normalized persona names to their prompt settings
+var knownPersonas = map[string]Persona{
+	"10-year-old": {
+		Name:       "10-year-old",
+		Audience:   "a curious 10-year-old child",
+		Vocabulary: "everyday words only, no jargon; define anything unusual",
+		Depth:      "intuition first, skip formal details and math",
+	},
+	"high-school-student": {
+		Name:       "high-school-student",
+		Audience:   "a high school student",
+		Vocabulary: "plain language with key terms introduced and defined",
+		Depth:      "core ideas with simple worked examples",
+	},
+	"college-student": {
+		Name:       "college-student",
+		Audience:   "an undergraduate student",
+		Vocabulary: "standard technical terminology",
+		Depth:      "conceptual understanding plus the underlying theory",
+	},
+	"senior-engineer": {
+		Name:       "senior-engineer",
+		Audience:   "a senior software engineer",
+		Vocabulary: "precise technical language, assume strong fundamentals",
+		Depth:      "internals, trade-offs, edge cases and production concerns",
+	},
+	"product-manager": {
+		Name:       "product-manager",
+		Audience:   "a product manager",
+		Vocabulary: "business-friendly language, minimal code",
+		Depth:      "capabilities, limitations, costs and user impact",
+	},
+	"executive": {
+		Name:       "executive",
+		Audience:   "a busy executive",
+		Vocabulary: "non-technical, outcome-focused language",
+		Depth:      "strategic implications in as few words as possible",
+	},
+}
+
+// NormalizePersona normalizes a persona name (e.g. "Senior Engineer" -> "senior-engineer")
+func NormalizePersona(persona string) string {
+	fields := strings.Fields(strings.ToLower(strings.TrimSpace(persona)))
+	return strings.Join(fields, "-")
+}
+
+// ValidatePersona checks a requested persona and returns its normalized name.
+// Built-in personas are always accepted; free-form ones must be short plain words.
+func ValidatePersona(name string) (string, error) {
+	normalized := NormalizePersona(name)
+	if normalized == "" {
+		return "", nil
+	}
+	if _, exists := knownPersonas[normalized]; exists {
+		return normalized, nil
+	}
+
+	trimmed := strings.TrimSpace(name)
+	if utf8.RuneCountInString(trimmed) > MaxPersonaLength {
+		return "", fmt.Errorf("persona must be at most %d characters", MaxPersonaLength)
+	}
+	if !customPersonaPattern.MatchString(trimmed) {
+		return "", fmt.Errorf("persona %q may only contain letters, numbers, spaces, hyphens and apostrophes", trimmed)
+	}
+	return normalized, nil
+}
+
+// LookupPersona returns the settings for a persona name.
+// Unknown names are treated as a free-form audience description; an empty or invalid name returns nil.
+func LookupPersona(name string) *Persona {
+	normalized, err := ValidatePersona(name)
+	if err != nil || normalized == "" {
+		return nil
+	}
+
+	if persona, exists := knownPersonas[normalized]; exists {
+		return &persona
+	}
+
+	return &Persona{
+		Name:       normalized,
+		Audience:   strings.TrimSpace(name),
+		Vocabulary: "language appropriate for this audience",
+		Depth:      "the level of detail this audience needs",
+	}
+}
+
+// KnownPersonas returns the names of the built-in personas
+func KnownPersonas() []string {
+	names := make([]string, 0, len(knownPersonas))
+	for name := range knownPersonas {
+		names = append(names, name)
+	}
+	return names
+}
+
+// PromptVariables returns the persona as prompt template variables
+func (p *Persona) PromptVariables() map[string]string {
+	return map[string]string{
+		"persona":    p.Name,
+		"audience":   p.Audience,
+		"vocabulary": p.Vocabulary,
+		"depth":      p.Depth,
+	}
+}
+
+// writePersona writes the audience instructions for a persona to the prompt
+func writePersona(promptBuilder *strings.Builder, persona *Persona) {
+	if persona == nil {
+		return
+	}
+
+	vars := persona.PromptVariables()
+	promptBuilder.WriteString("Target Audience:\n")
+	promptBuilder.WriteString("- Explain as if to " + vars["audience"] + "\n")
+	promptBuilder.WriteString("- Vocabulary: " + vars["vocabulary"] + "\n")
+	promptBuilder.WriteString("- Depth: " + vars["depth"] + "\n\n")
+}
+
+// personaContextKey is the context key for a request-scoped persona
+type personaContextKey struct{}
+
+// WithPersona returns a context carrying the persona to write for
+func WithPersona(ctx context.Context, persona *Persona) context.Context {
+	return context.WithValue(ctx, personaContextKey{}, persona)
+}
+
+// PersonaFromContext returns the persona carried by the context, or nil
+func PersonaFromContext(ctx context.Context) *Persona {
+	persona, _ := ctx.Value(personaContextKey{}).(*Persona)
+	return persona
+}
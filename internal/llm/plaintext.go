@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// imagePattern matches an inline Markdown image
+	imagePattern = regexp.MustCompile(`!\[([^\]\n]*)\]\([^()\s]*\)`)
+	// htmlTagPattern matches an HTML tag
+	htmlTagPattern = regexp.MustCompile(`</?[a-zA-Z][^<>]*>`)
+	// dataURIPattern matches an inline base64 data URI
+	dataURIPattern = regexp.MustCompile(`data:[a-zA-Z0-9.+/-]+;base64,[A-Za-z0-9+/=]+`)
+	// blankLinesPattern matches runs of blank lines
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// MarkdownToPlainText strips Markdown and HTML from model-written text for surfaces that
+// cannot render it: headings and emphasis lose their markers, links and images keep only their
+// text, bullets become "•" and base64 data URIs are dropped. Code block contents are kept verbatim.
+func MarkdownToPlainText(text string) string {
+	var out []string
+	inCode := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, dataURIPattern.ReplaceAllString(line, ""))
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(trimmed); m != nil {
+			trimmed = m[2]
+		} else if m := bulletPattern.FindStringSubmatch(line); m != nil {
+			trimmed = "• " + m[1]
+		}
+		out = append(out, plainInline(trimmed))
+	}
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(out, "\n"), "\n\n"))
+}
+
+// plainInline removes inline Markdown and HTML markup from a line
+func plainInline(text string) string {
+	text = dataURIPattern.ReplaceAllString(text, "")
+	text = imagePattern.ReplaceAllString(text, "$1")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = codeSpanPattern.ReplaceAllString(text, "$1")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = boldPattern.ReplaceAllString(text, "$1")
+	return italicPattern.ReplaceAllString(text, "$1")
+}
+
+// TruncateText shortens text to at most max characters, cutting at a word boundary where one
+// is near and appending an ellipsis. It reports whether the text was shortened.
+func TruncateText(text string, max int) (string, bool) {
+	if max <= 0 || utf8.RuneCountInString(text) <= max {
+		return text, false
+	}
+	runes := []rune(text)
+	cut := string(runes[:max-1])
+	if i := strings.LastIndexAny(cut, " \n\t"); i > len(cut)*3/4 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " \n\t.,;:") + "…", true
+}
@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// PromptTraceConfig is the generation config a prompt was sent with
+type PromptTraceConfig struct {
+	Temperature      *float32 `json:"temperature,omitempty"`
+	TopP             *float32 `json:"top_p,omitempty"`
+	TopK             *int32   `json:"top_k,omitempty"`
+	MaxOutputTokens  *int32   `json:"max_output_tokens,omitempty"`
+	CandidateCount   *int32   `json:"candidate_count,omitempty"`
+	StopSequences    []string `json:"stop_sequences,omitempty"`
+	ResponseMIMEType string   `json:"response_mime_type,omitempty"`
+	ResponseSchema   bool     `json:"response_schema,omitempty"` // Output was constrained to a JSON schema
+	Tool             string   `json:"tool,omitempty"`            // Function the model was required to call
+}
+
+// PromptTraceEntry is one model request exactly as sent
+type PromptTraceEntry struct {
+	Model  string            `json:"model"`
+	Prompt string            `json:"prompt"`
+	Config PromptTraceConfig `json:"config"`
+}
+
+// PromptTrace records the model requests made under a context, in order, fallbacks included
+type PromptTrace struct {
+	mu      sync.Mutex
+	entries []PromptTraceEntry
+}
+
+// Entries returns the recorded requests
+func (t *PromptTrace) Entries() []PromptTraceEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]PromptTraceEntry(nil), t.entries...)
+}
+
+// promptTraceContextKey is the context key for a PromptTrace recorder
+type promptTraceContextKey struct{}
+
+// WithPromptTrace returns a context that records the rendered prompts and generation configs of its requests
+func WithPromptTrace(ctx context.Context) (context.Context, *PromptTrace) {
+	trace := &PromptTrace{}
+	return context.WithValue(ctx, promptTraceContextKey{}, trace), trace
+}
+
+// recordPrompt adds a request to the context's PromptTrace, if any
+func recordPrompt(ctx context.Context, model, prompt string, config *genai.GenerationConfig, tool string) {
+	trace, ok := ctx.Value(promptTraceContextKey{}).(*PromptTrace)
+	if !ok {
+		return
+	}
+	entry := PromptTraceEntry{Model: model, Prompt: prompt, Config: PromptTraceConfig{Tool: tool}}
+	if config != nil {
+		entry.Config.Temperature = config.Temperature
+		entry.Config.TopP = config.TopP
+		entry.Config.TopK = config.TopK
+		entry.Config.MaxOutputTokens = config.MaxOutputTokens
+		entry.Config.CandidateCount = config.CandidateCount
+		entry.Config.StopSequences = config.StopSequences
+		entry.Config.ResponseMIMEType = config.ResponseMIMEType
+		entry.Config.ResponseSchema = config.ResponseSchema != nil
+	}
+
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+	trace.entries = append(trace.entries, entry)
+}
@@ -0,0 +1,44 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+)
+
+// TestPromptTrace tests recording each model request's rendered prompt and config, fallbacks included
+func TestPromptTrace(t *testing.T) {
+	client := &GeminiClient{model: DefaultModel, fallbackModels: []string{"fallback-model"}, logger: logrus.New()}
+	client.generate = func(ctx context.Context, model string, prompt genai.Part, config *genai.GenerationConfig) (*genai.GenerateContentResponse, error) {
+		if model == DefaultModel {
+			return nil, &googleapi.Error{Code: 503}
+		}
+		return &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text("answer")}}}},
+		}, nil
+	}
+
+	ctx, trace := WithPromptTrace(WithDeterministic(context.Background()))
+	client.recorder = NewResponseRecorder(t.TempDir())
+	_, err := client.executeRequestWithConfig(ctx, "Explain caching", jsonGenerationConfig(ogLessonResponseSchema))
+	require.NoError(t, err)
+
+	entries := trace.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, DefaultModel, entries[0].Model)
+	assert.Equal(t, "fallback-model", entries[1].Model)
+	assert.Equal(t, "Explain caching", entries[1].Prompt)
+	assert.Equal(t, float32(0), *entries[1].Config.Temperature, "the config is recorded as sent")
+	assert.True(t, entries[1].Config.ResponseSchema)
+	assert.Equal(t, jsonMIMEType, entries[1].Config.ResponseMIMEType)
+
+	// Requests outside a traced context are not recorded
+	_, err = client.executeRequest(context.Background(), "Explain queues")
+	require.NoError(t, err)
+	assert.Len(t, trace.Entries(), 2)
+}
@@ -0,0 +1,27 @@
+package llm
+
+import "context"
+
+// promptInstructionsContextKey is the context key for a prompt experiment's instructions
+type promptInstructionsContextKey struct{}
+
+// WithPromptInstructions returns a context whose prompts end with extra instructions,
+// e.g. those of the prompt experiment variant a session was assigned to
+func WithPromptInstructions(ctx context.Context, instructions string) context.Context {
+	return context.WithValue(ctx, promptInstructionsContextKey{}, instructions)
+}
+
+// PromptInstructionsFromContext returns the extra prompt instructions carried by the context, or ""
+func PromptInstructionsFromContext(ctx context.Context) string {
+	instructions, _ := ctx.Value(promptInstructionsContextKey{}).(string)
+	return instructions
+}
+
+// appendPromptInstructions adds the context's extra instructions to the end of a prompt
+func appendPromptInstructions(ctx context.Context, prompt string) string {
+	instructions := PromptInstructionsFromContext(ctx)
+	if instructions == "" {
+		return prompt
+	}
+	return prompt + "\n\nAdditional Instructions:\n" + instructions + "\n"
+}
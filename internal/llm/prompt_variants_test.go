@@ -0,0 +1,18 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAppendPromptInstructions tests that experiment instructions end the prompt only when set
+func TestAppendPromptInstructions(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "Explain caching.", appendPromptInstructions(ctx, "Explain caching."))
+
+	ctx = WithPromptInstructions(ctx, "Open with a question.")
+	assert.Equal(t, "Open with a question.", PromptInstructionsFromContext(ctx))
+	assert.Equal(t, "Explain caching.\n\nAdditional Instructions:\nOpen with a question.\n", appendPromptInstructions(ctx, "Explain caching."))
+}
@@ -0,0 +1,172 @@
+package llm
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// proseWordsPerMinute is the reading speed assumed for a lesson's prose
+	proseWordsPerMinute = 200
+	// codeWordsPerMinute is the slower speed assumed for reading code
+	codeWordsPerMinute = 80
+)
+
+var (
+	// fencedCodePattern matches Markdown code blocks embedded in prose sections
+	fencedCodePattern = regexp.MustCompile("(?s)```.*?```")
+	// sentenceEndPattern matches the punctuation that ends a sentence
+	sentenceEndPattern = regexp.MustCompile(`[.!?]+(\s|$)`)
+)
+
+// Readability describes how hard a lesson is to read and how long it takes
+type Readability struct {
+	FleschKincaidGrade float64            `json:"flesch_kincaid_grade"` // US school grade needed to follow the prose
+	FleschReadingEase  float64            `json:"flesch_reading_ease"`  // 0-100, higher is easier
+	Words              int                `json:"words"`                // Prose words
+	Sentences          int                `json:"sentences"`
+	ReadingTimeSeconds int                `json:"reading_time_seconds"`
+	CodeToProseRatio   float64            `json:"code_to_prose_ratio"`      // Code characters per prose character
+	SectionGrades      map[string]float64 `json:"section_grades,omitempty"` // Grade of each prose section, by field name
+}
+
+// textStats counts the words, sentences and syllables of a piece of prose
+type textStats struct {
+	words     int
+	sentences int
+	syllables int
+}
+
+// add accumulates another text's counts
+func (s *textStats) add(other textStats) {
+	s.words += other.words
+	s.sentences += other.sentences
+	s.syllables += other.syllables
+}
+
+// grade returns the Flesch-Kincaid grade level, or 0 for empty text
+func (s textStats) grade() float64 {
+	if s.words == 0 {
+		return 0
+	}
+	return roundTenth(0.39*float64(s.words)/float64(s.sentences) + 11.8*float64(s.syllables)/float64(s.words) - 15.59)
+}
+
+// ease returns the Flesch reading ease score clamped to 0-100, or 100 for empty text
+func (s textStats) ease() float64 {
+	if s.words == 0 {
+		return 100
+	}
+	score := 206.835 - 1.015*float64(s.words)/float64(s.sentences) - 84.6*float64(s.syllables)/float64(s.words)
+	return roundTenth(math.Max(0, math.Min(100, score)))
+}
+
+// AnalyzeReadability scores a lesson's prose sections. The toy example and any fenced code
+// blocks in the prose count as code: they add to the reading time but not to the grade.
+func AnalyzeReadability(lesson *OGLesson) Readability {
+	readability := Readability{SectionGrades: make(map[string]float64)}
+	var total textStats
+	code := lesson.ToyExampleCode
+	proseChars := 0
+
+	for _, section := range LessonSections {
+		if section.Field == "toy_example_code" {
+			continue
+		}
+		text, _ := lesson.SectionText(section.Field)
+		for _, block := range fencedCodePattern.FindAllString(text, -1) {
+			code += "\n" + block
+		}
+		prose := fencedCodePattern.ReplaceAllString(text, " ")
+		stats := countText(prose)
+		if stats.words == 0 {
+			continue
+		}
+		readability.SectionGrades[section.Field] = stats.grade()
+		total.add(stats)
+		proseChars += len(strings.Join(strings.Fields(prose), ""))
+	}
+
+	codeWords := len(strings.Fields(code))
+	readability.FleschKincaidGrade = total.grade()
+	readability.FleschReadingEase = total.ease()
+	readability.Words = total.words
+	readability.Sentences = total.sentences
+	minutes := float64(total.words)/proseWordsPerMinute + float64(codeWords)/codeWordsPerMinute
+	readability.ReadingTimeSeconds = int(math.Ceil(minutes * 60))
+	if proseChars > 0 {
+		readability.CodeToProseRatio = roundHundredth(float64(len(strings.Join(strings.Fields(code), ""))) / float64(proseChars))
+	}
+	return readability
+}
+
+// countText counts a text's words, sentences and syllables. Text without sentence-ending
+// punctuation counts as one sentence.
+func countText(text string) textStats {
+	var stats textStats
+	for _, word := range strings.Fields(text) {
+		word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if word == "" {
+			continue
+		}
+		stats.words++
+		stats.syllables += countSyllables(word)
+	}
+	if stats.words > 0 {
+		stats.sentences = len(sentenceEndPattern.FindAllString(text, -1))
+		if stats.sentences == 0 {
+			stats.sentences = 1
+		}
+	}
+	return stats
+}
+
+// countSyllables estimates an English word's syllables from its vowel groups
+func countSyllables(word string) int {
+	word = strings.ToLower(word)
+	count := 0
+	previousVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !previousVowel {
+			count++
+		}
+		previousVowel = vowel
+	}
+	// A trailing silent e does not make a syllable, except in endings like "-le"
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// roundTenth rounds to one decimal place
+func roundTenth(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// roundHundredth rounds to two decimal places
+func roundHundredth(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// rewriteGoalContextKey is the context key for a section rewrite goal
+type rewriteGoalContextKey struct{}
+
+// WithRewriteGoal returns a context whose section regenerations pursue a specific goal,
+// e.g. simpler wording, instead of a fresh approach
+func WithRewriteGoal(ctx context.Context, goal string) context.Context {
+	return context.WithValue(ctx, rewriteGoalContextKey{}, goal)
+}
+
+// RewriteGoalFromContext returns the rewrite goal carried by the context, or ""
+func RewriteGoalFromContext(ctx context.Context) string {
+	goal, _ := ctx.Value(rewriteGoalContextKey{}).(string)
+	return goal
+}
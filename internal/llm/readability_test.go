@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCountSyllables tests the vowel-group syllable estimate
+func TestCountSyllables(t *testing.T) {
+	for word, want := range map[string]int{"cat": 1, "cache": 1, "table": 2, "memory": 3, "recursion": 3, "the": 1, "rhythm": 1} {
+		assert.Equal(t, want, countSyllables(word), word)
+	}
+}
+
+// TestAnalyzeReadability tests grading prose and keeping code out of the grade
+func TestAnalyzeReadability(t *testing.T) {
+	simple := AnalyzeReadability(&OGLesson{
+		BigPicture: "A cache is a small box. It keeps things close. You get them fast.",
+		Metaphor:   "It is like a desk drawer.",
+	})
+	hard := AnalyzeReadability(&OGLesson{
+		BigPicture: "Hierarchical memory architectures exploit temporal and spatial locality, amortizing expensive retrieval operations across subsequent computational accesses.",
+	})
+	assert.Less(t, simple.FleschKincaidGrade, 3.0)
+	assert.Greater(t, hard.FleschKincaidGrade, 16.0)
+	assert.Greater(t, simple.FleschReadingEase, hard.FleschReadingEase)
+	assert.Equal(t, 4, simple.Sentences)
+	assert.Contains(t, simple.SectionGrades, "big_picture")
+	assert.NotContains(t, simple.SectionGrades, "core_mechanism")
+	assert.Zero(t, simple.CodeToProseRatio)
+
+	// Code in the toy example and fenced blocks adds reading time but not grade
+	withCode := AnalyzeReadability(&OGLesson{
+		BigPicture:     "A cache is a small box. It keeps things close. You get them fast.",
+		Metaphor:       "It is like a desk drawer.\n```\ndrawer.get(key)\n```",
+		ToyExampleCode: "cache = {}\ncache[key] = fetch(key)",
+	})
+	assert.Equal(t, simple.FleschKincaidGrade, withCode.FleschKincaidGrade)
+	assert.Equal(t, simple.Words, withCode.Words)
+	assert.Greater(t, withCode.ReadingTimeSeconds, simple.ReadingTimeSeconds)
+	assert.Greater(t, withCode.CodeToProseRatio, 0.5)
+
+	empty := AnalyzeReadability(&OGLesson{})
+	assert.Zero(t, empty.Words)
+	assert.Zero(t, empty.ReadingTimeSeconds)
+}
+
+// TestRewriteGoalContext tests carrying a rewrite goal on the context
+func TestRewriteGoalContext(t *testing.T) {
+	assert.Empty(t, RewriteGoalFromContext(context.Background()))
+	assert.Equal(t, "be simpler", RewriteGoalFromContext(WithRewriteGoal(context.Background(), "be simpler")))
+}
@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RegenerateSections rewrites the given lesson sections, keeping the rest of the lesson fixed.
+// sections are field names (e.g. "metaphor"); the result maps each field to its new text.
+func (c *GeminiClient) RegenerateSections(ctx context.Context, topic string, lesson OGLesson, sections []string) (map[string]string, error) {
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("no sections to regenerate")
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"topic":    topic,
+		"sections": sections,
+		"model":    c.model,
+	}).Info("Regenerating lesson sections with Gemini")
+
+	prompt, err := c.buildRegeneratePrompt(topic, lesson, sections, PersonaFromContext(ctx), RewriteGoalFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.executeRequest(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute regenerate request: %w", err)
+	}
+
+	if len(response.Candidates) == 0 {
+		return nil, fmt.Errorf("no candidates in response")
+	}
+
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+
+	return parseRegeneratedSections(text.String(), sections)
+}
+
+// buildRegeneratePrompt creates the prompt for regenerating lesson sections.
+// Sections that are not regenerated are included as fixed context.
+// A nil persona keeps the lesson aimed at a general audience; an empty goal asks for a fresh approach.
+func (c *GeminiClient) buildRegeneratePrompt(topic string, lesson OGLesson, sections []string, persona *Persona, goal string) (string, error) {
+	regenerate := make(map[string]bool, len(sections))
+	for _, id := range sections {
+		section, ok := LookupSection(id)
+		if !ok {
+			return "", fmt.Errorf("unknown section %q", id)
+		}
+		regenerate[section.Field] = true
+	}
+
+	var promptBuilder strings.Builder
+
+	promptBuilder.WriteString("You are an expert educator revising part of an existing lesson.\n\n")
+	promptBuilder.WriteString(fmt.Sprintf("Topic: %s\n\n", topic))
+	writePersona(&promptBuilder, persona)
+
+	promptBuilder.WriteString("These sections are final and must not be changed. Keep the rewritten sections consistent with them:\n\n")
+	for _, section := range LessonSections {
+		if regenerate[section.Field] {
+			continue
+		}
+		if text, _ := lesson.SectionText(section.Field); text != "" {
+			promptBuilder.WriteString(fmt.Sprintf("%s (%s):\n%s\n\n", section.Title, section.Field, text))
+		}
+	}
+
+	if goal != "" {
+		promptBuilder.WriteString(fmt.Sprintf("Rewrite these sections to %s, keeping their meaning:\n\n", goal))
+	} else {
+		promptBuilder.WriteString("Rewrite these sections with a fresh approach, improving on the current version:\n\n")
+	}
+	fields := make([]string, 0, len(regenerate))
+	for _, section := range LessonSections {
+		if !regenerate[section.Field] {
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%q", section.Field))
+		current, _ := lesson.SectionText(section.Field)
+		promptBuilder.WriteString(fmt.Sprintf("%s (%s), current version:\n%s\n\n", section.Title, section.Field, current))
+	}
+
+	promptBuilder.WriteString(fmt.Sprintf("Respond with a JSON object containing exactly these fields: %s. ", strings.Join(fields, ", ")))
+	promptBuilder.WriteString("Each value is the complete new text for that section. Do not include any other fields.\n\nYour JSON response:\n")
+
+	return promptBuilder.String(), nil
+}
+
+// parseRegeneratedSections extracts the regenerated sections from the response text.
+// Every requested section must be present and non-empty.
+func parseRegeneratedSections(responseText string, sections []string) (map[string]string, error) {
+	jsonStart := strings.Index(responseText, "{")
+	jsonEnd := strings.LastIndex(responseText, "}")
+	if jsonStart == -1 || jsonEnd <= jsonStart {
+		return nil, fmt.Errorf("no JSON found in response")
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal([]byte(responseText[jsonStart:jsonEnd+1]), &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal regenerated sections: %w", err)
+	}
+
+	result := make(map[string]string, len(sections))
+	for _, id := range sections {
+		section, ok := LookupSection(id)
+		if !ok {
+			return nil, fmt.Errorf("unknown section %q", id)
+		}
+		text := strings.TrimSpace(raw[section.Field])
+		if text == "" {
+			return nil, fmt.Errorf("section %s missing from response", section.Field)
+		}
+		result[section.Field] = text
+	}
+
+	return result, nil
+}
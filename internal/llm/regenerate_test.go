@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildRegeneratePrompt tests that only the requested sections are rewritten
+func TestBuildRegeneratePrompt(t *testing.T) {
+	client := &GeminiClient{}
+	lesson := OGLesson{
+		BigPicture:     "Caches keep hot data close",
+		Metaphor:       "A desk drawer",
+		ToyExampleCode: "cache[key] = value",
+	}
+
+	prompt, err := client.buildRegeneratePrompt("Caching", lesson, []string{"metaphor", "toy-example"}, nil, "")
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "Big Picture (big_picture):\nCaches keep hot data close")
+	assert.Contains(t, prompt, "Metaphor (metaphor), current version:\nA desk drawer")
+	assert.Contains(t, prompt, `exactly these fields: "metaphor", "toy_example_code"`)
+	assert.NotContains(t, prompt, "Target Audience")
+	assert.Contains(t, prompt, "with a fresh approach")
+
+	prompt, err = client.buildRegeneratePrompt("Caching", lesson, []string{"metaphor"}, nil, "use shorter sentences")
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "Rewrite these sections to use shorter sentences, keeping their meaning")
+
+	_, err = client.buildRegeneratePrompt("Caching", lesson, []string{"appendix"}, nil, "")
+	assert.Error(t, err)
+}
+
+// TestParseRegeneratedSections tests extracting and validating regenerated sections
+func TestParseRegeneratedSections(t *testing.T) {
+	sections, err := parseRegeneratedSections("Here you go:\n{\"metaphor\": \" A library \", \"big_picture\": \"ignored\"}", []string{"metaphor"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"metaphor": "A library"}, sections)
+
+	_, err = parseRegeneratedSections(`{"metaphor": ""}`, []string{"metaphor"})
+	assert.Error(t, err)
+
+	_, err = parseRegeneratedSections("no json", []string{"metaphor"})
+	assert.Error(t, err)
+}
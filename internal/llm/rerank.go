@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RerankPassages scores how relevant each passage is to the query, from 0 (irrelevant) to 10 (essential).
+// The returned slice has one score per passage, in the same order.
+func (c *GeminiClient) RerankPassages(ctx context.Context, query string, passages []string) ([]float64, error) {
+	if len(passages) == 0 {
+		return []float64{}, nil
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"query":    query,
+		"passages": len(passages),
+		"model":    c.model,
+	}).Info("Reranking context passages")
+
+	response, err := c.executeRequest(ctx, c.buildRerankPrompt(query, passages))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute rerank request: %w", err)
+	}
+
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+
+	return parseRerankScores(text.String(), len(passages))
+}
+
+// buildRerankPrompt creates the prompt for passage reranking
+func (c *GeminiClient) buildRerankPrompt(query string, passages []string) string {
+	var promptBuilder strings.Builder
+
+	promptBuilder.WriteString("You are ranking reference passages for a lesson about the topic below.\n\n")
+	promptBuilder.WriteString(fmt.Sprintf("Topic: %s\n\n", query))
+	promptBuilder.WriteString("Passages:\n")
+	for i, passage := range passages {
+		promptBuilder.WriteString(fmt.Sprintf("[%d] %s\n\n", i+1, passage))
+	}
+	promptBuilder.WriteString(fmt.Sprintf("Rate how useful each passage is for teaching the topic on a scale from 0 (irrelevant) to 10 (essential).\n"+
+		"Respond with a JSON array of exactly %d numbers in passage order and nothing else, e.g. [7, 2, 9].\n", len(passages)))
+
+	return promptBuilder.String()
+}
+
+// parseRerankScores extracts the JSON score array from a rerank response
+func parseRerankScores(responseText string, expected int) ([]float64, error) {
+	start := strings.Index(responseText, "[")
+	end := strings.LastIndex(responseText, "]")
+	if start == -1 || end <= start {
+		return nil, fmt.Errorf("no score array found in rerank response")
+	}
+
+	var scores []float64
+	if err := json.Unmarshal([]byte(responseText[start:end+1]), &scores); err != nil {
+		return nil, fmt.Errorf("failed to parse rerank scores: %w", err)
+	}
+	if len(scores) != expected {
+		return nil, fmt.Errorf("expected %d rerank scores, got %d", expected, len(scores))
+	}
+	return scores, nil
+}
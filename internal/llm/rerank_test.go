@@ -0,0 +1,30 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseRerankScores tests extracting rerank scores from model output
+func TestParseRerankScores(t *testing.T) {
+	scores, err := parseRerankScores("Here you go:\n[7, 2.5, 9]", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{7, 2.5, 9}, scores)
+
+	_, err = parseRerankScores("[1, 2]", 3)
+	assert.Error(t, err)
+
+	_, err = parseRerankScores("no scores", 1)
+	assert.Error(t, err)
+}
+
+// TestBuildRerankPrompt tests that every passage is numbered in the prompt
+func TestBuildRerankPrompt(t *testing.T) {
+	client := NewGeminiClient("test-api-key")
+	prompt := client.buildRerankPrompt("goroutines", []string{"first passage", "second passage"})
+	assert.Contains(t, prompt, "[1] first passage")
+	assert.Contains(t, prompt, "[2] second passage")
+	assert.Contains(t, prompt, "exactly 2 numbers")
+}
@@ -0,0 +1,209 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Severity levels used by the critic
+var critiqueSeverities = []string{"critical", "high", "medium", "low"}
+
+// RubricCriterion represents a single evaluation criterion used by the critic
+type RubricCriterion struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Rubric represents the evaluation criteria and severity guidelines for lesson critique
+type Rubric struct {
+	ID                 string            `json:"id"`
+	Name               string            `json:"name"`
+	OrgID              string            `json:"org_id,omitempty"`           // Empty matches every org
+	ExplanationType    string            `json:"explanation_type,omitempty"` // Empty matches every explanation type
+	Criteria           []RubricCriterion `json:"criteria"`
+	SeverityGuidelines map[string]string `json:"severity_guidelines"`
+	Instructions       string            `json:"instructions,omitempty"` // Additional reviewer instructions
+	UpdatedAt          time.Time         `json:"updated_at"`
+}
+
+// DefaultRubric returns the built-in rubric used when no custom rubric matches
+func DefaultRubric() *Rubric {
+	return &Rubric{
+		ID:   "default",
+		Name: "Default",
+		Criteria: []RubricCriterion{
+			{Name: "Clarity", Description: "Is the content clear and understandable?"},
+			{Name: "Accuracy", Description: "Is the information technically correct?"},
+			{Name: "Completeness", Description: "Are all necessary concepts covered?"},
+			{Name: "Engagement", Description: "Is the content engaging and memorable?"},
+			{Name: "Structure", Description: "Does each section serve its intended purpose?"},
+			{Name: "Code Quality", Description: "If code is present, is it correct and runnable?"},
+			{Name: "Length", Description: "Are sections appropriately sized (not too short/long)?"},
+		},
+		SeverityGuidelines: map[string]string{
+			"critical": "Factual errors, broken code, major misconceptions",
+			"high":     "Significant clarity issues, missing key concepts",
+			"medium":   "Minor clarity issues, could be more engaging",
+			"low":      "Minor improvements, style suggestions",
+		},
+	}
+}
+
+// Validate checks that the rubric is usable in a critique prompt
+func (r *Rubric) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("rubric id is required")
+	}
+	if len(r.Criteria) == 0 {
+		return fmt.Errorf("rubric must define at least one criterion")
+	}
+	for i, criterion := range r.Criteria {
+		if strings.TrimSpace(criterion.Name) == "" {
+			return fmt.Errorf("criterion %d is missing a name", i+1)
+		}
+	}
+	for severity := range r.SeverityGuidelines {
+		if !isCritiqueSeverity(severity) {
+			return fmt.Errorf("unknown severity %q (expected one of %s)", severity, strings.Join(critiqueSeverities, ", "))
+		}
+	}
+	return nil
+}
+
+// isCritiqueSeverity reports whether a severity level is supported
+func isCritiqueSeverity(severity string) bool {
+	for _, s := range critiqueSeverities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// RubricStore holds custom critique rubrics keyed by ID
+type RubricStore struct {
+	mu      sync.RWMutex
+	rubrics map[string]*Rubric
+}
+
+// NewRubricStore creates a new empty rubric store
+func NewRubricStore() *RubricStore {
+	return &RubricStore{
+		rubrics: make(map[string]*Rubric),
+	}
+}
+
+// Put validates and stores a rubric, replacing any rubric with the same ID
+func (s *RubricStore) Put(rubric *Rubric) error {
+	if err := rubric.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rubric.UpdatedAt = time.Now()
+	s.rubrics[rubric.ID] = rubric
+	return nil
+}
+
+// Get retrieves a rubric by ID
+func (s *RubricStore) Get(id string) (*Rubric, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rubric, exists := s.rubrics[id]
+	return rubric, exists
+}
+
+// Delete removes a rubric by ID and reports whether it existed
+func (s *RubricStore) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, exists := s.rubrics[id]
+	delete(s.rubrics, id)
+	return exists
+}
+
+// List returns all rubrics sorted by ID
+func (s *RubricStore) List() []*Rubric {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rubrics := make([]*Rubric, 0, len(s.rubrics))
+	for _, rubric := range s.rubrics {
+		rubrics = append(rubrics, rubric)
+	}
+	sort.Slice(rubrics, func(i, j int) bool { return rubrics[i].ID < rubrics[j].ID })
+	return rubrics
+}
+
+// Resolve returns the most specific rubric for an org and explanation type.
+// An exact org and type match wins over an org-only match, which wins over a
+// type-only match. The default rubric is returned when nothing matches.
+func (s *RubricStore) Resolve(orgID, explanationType string) *Rubric {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var best *Rubric
+	bestScore := 0
+	for _, rubric := range s.rubrics {
+		score := 0
+		switch {
+		case rubric.OrgID != "" && rubric.OrgID != orgID:
+			continue
+		case rubric.ExplanationType != "" && rubric.ExplanationType != explanationType:
+			continue
+		}
+		if rubric.OrgID != "" {
+			score += 2
+		}
+		if rubric.ExplanationType != "" {
+			score++
+		}
+		if best == nil || score > bestScore || (score == bestScore && rubric.ID < best.ID) {
+			best = rubric
+			bestScore = score
+		}
+	}
+
+	if best == nil {
+		return DefaultRubric()
+	}
+	return best
+}
+
+// LoadRubricsFile loads rubric documents from a JSON file containing an array of rubrics
+func LoadRubricsFile(path string) ([]*Rubric, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rubrics file: %w", err)
+	}
+
+	var rubrics []*Rubric
+	if err := json.Unmarshal(data, &rubrics); err != nil {
+		return nil, fmt.Errorf("failed to parse rubrics file: %w", err)
+	}
+	return rubrics, nil
+}
+
+// rubricContextKey is the context key for a request-scoped rubric
+type rubricContextKey struct{}
+
+// WithRubric returns a context carrying the rubric to use for critique
+func WithRubric(ctx context.Context, rubric *Rubric) context.Context {
+	return context.WithValue(ctx, rubricContextKey{}, rubric)
+}
+
+// RubricFromContext returns the rubric carried by the context, or nil
+func RubricFromContext(ctx context.Context) *Rubric {
+	rubric, _ := ctx.Value(rubricContextKey{}).(*Rubric)
+	return rubric
+}
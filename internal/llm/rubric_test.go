@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRubricStoreResolve tests rubric resolution precedence
+func TestRubricStoreResolve(t *testing.T) {
+	store := NewRubricStore()
+	criteria := []RubricCriterion{{Name: "Accuracy", Description: "Clinically correct"}}
+
+	require.NoError(t, store.Put(&Rubric{ID: "simple", ExplanationType: "simple", Criteria: criteria}))
+	require.NoError(t, store.Put(&Rubric{ID: "med", OrgID: "med-school", Criteria: criteria}))
+	require.NoError(t, store.Put(&Rubric{ID: "med-simple", OrgID: "med-school", ExplanationType: "simple", Criteria: criteria}))
+
+	assert.Equal(t, "med-simple", store.Resolve("med-school", "simple").ID)
+	assert.Equal(t, "med", store.Resolve("med-school", "standard").ID)
+	assert.Equal(t, "simple", store.Resolve("bootcamp", "simple").ID)
+	assert.Equal(t, "default", store.Resolve("bootcamp", "standard").ID)
+}
+
+// TestRubricValidate tests rubric validation
+func TestRubricValidate(t *testing.T) {
+	assert.NoError(t, DefaultRubric().Validate())
+	assert.Error(t, (&Rubric{ID: "empty"}).Validate())
+	assert.Error(t, (&Rubric{
+		ID:                 "bad-severity",
+		Criteria:           []RubricCriterion{{Name: "Clarity"}},
+		SeverityGuidelines: map[string]string{"blocker": "nope"},
+	}).Validate())
+}
+
+// TestBuildCritiquePromptWithRubric tests that custom rubrics are injected into the prompt
+func TestBuildCritiquePromptWithRubric(t *testing.T) {
+	client := NewGeminiClient("test-api-key")
+	rubric := &Rubric{
+		ID:                 "med",
+		Criteria:           []RubricCriterion{{Name: "Clinical Accuracy", Description: "Dosages and guidelines must match current standards"}},
+		SeverityGuidelines: map[string]string{"critical": "Any unsafe clinical advice"},
+		Instructions:       "Cite the guideline a correction is based on.",
+	}
+
+	prompt := client.buildCritiquePrompt(`{"big_picture": "x"}`, rubric)
+	assert.Contains(t, prompt, "Clinical Accuracy")
+	assert.Contains(t, prompt, "Any unsafe clinical advice")
+	assert.Contains(t, prompt, "Cite the guideline")
+	// Missing severities fall back to the default guidelines
+	assert.Contains(t, prompt, "Minor improvements, style suggestions")
+	assert.NotContains(t, prompt, "Code Quality")
+
+	defaultPrompt := client.buildCritiquePrompt(`{"big_picture": "x"}`, nil)
+	assert.Contains(t, defaultPrompt, "Code Quality")
+}
+
+// TestRubricContext tests passing a rubric through the context
+func TestRubricContext(t *testing.T) {
+	assert.Nil(t, RubricFromContext(context.Background()))
+
+	ctx := WithRubric(context.Background(), DefaultRubric())
+	assert.Equal(t, "default", RubricFromContext(ctx).ID)
+}
+
+// TestLoadRubricsFile tests loading rubric documents from disk
+func TestLoadRubricsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rubrics.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"id":"bootcamp","org_id":"bootcamp","criteria":[{"name":"Runnable Code"}]}]`), 0o600))
+
+	rubrics, err := LoadRubricsFile(path)
+	require.NoError(t, err)
+	require.Len(t, rubrics, 1)
+	assert.Equal(t, "bootcamp", rubrics[0].OrgID)
+	assert.Equal(t, "Runnable Code", rubrics[0].Criteria[0].Name)
+}
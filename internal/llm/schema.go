@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// jsonMIMEType is the response MIME type that enables structured output
+const jsonMIMEType = "application/json"
+
+// stringArraySchema is the schema for a list of strings
+var stringArraySchema = &genai.Schema{
+	Type:  genai.TypeArray,
+	Items: &genai.Schema{Type: genai.TypeString},
+}
+
+// summarizeResponseSchema constrains summarization output to SummarizeResponse
+var summarizeResponseSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"outline":        stringArraySchema,
+		"prerequisites":  stringArraySchema,
+		"misconceptions": stringArraySchema,
+		"citations":      stringArraySchema,
+	},
+	Required: []string{"outline", "prerequisites", "misconceptions", "citations"},
+}
+
+// ogLessonResponseSchema constrains lesson output to OGLesson
+var ogLessonResponseSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"big_picture":      {Type: genai.TypeString, Description: "High-level overview and context"},
+		"metaphor":         {Type: genai.TypeString, Description: "Analogical explanation to aid understanding"},
+		"core_mechanism":   {Type: genai.TypeString, Description: "The fundamental how/why it works"},
+		"toy_example_code": {Type: genai.TypeString, Description: "Simple, runnable code example or N/A"},
+		"memory_hook":      {Type: genai.TypeString, Description: "Mnemonic device or memorable phrase"},
+		"real_life":        {Type: genai.TypeString, Description: "Real-world applications and examples"},
+		"best_practices":   {Type: genai.TypeString, Description: "Key do's and don'ts"},
+	},
+	Required: []string{"big_picture", "metaphor", "core_mechanism", "toy_example_code", "memory_hook", "real_life", "best_practices"},
+}
+
+// structuredOutputFromEnv reports whether structured output is enabled (GEMINI_STRUCTURED_OUTPUT, default true)
+func structuredOutputFromEnv() bool {
+	return !strings.EqualFold(os.Getenv("GEMINI_STRUCTURED_OUTPUT"), "false")
+}
+
+// SetStructuredOutput enables or disables schema-constrained JSON output
+func (c *GeminiClient) SetStructuredOutput(enabled bool) {
+	c.freeTextOnly = !enabled
+}
+
+// jsonGenerationConfig returns a generation config requesting JSON that matches the schema
+func jsonGenerationConfig(schema *genai.Schema) *genai.GenerationConfig {
+	return &genai.GenerationConfig{
+		ResponseMIMEType: jsonMIMEType,
+		ResponseSchema:   schema,
+	}
+}
+
+// executeJSONRequest requests schema-constrained JSON output. If structured output is disabled
+// or the model rejects the request as invalid (e.g. it does not support responseSchema), it falls
+// back to a free-text request; other errors are returned as is. The returned flag reports whether the response is schema-constrained.
+func (c *GeminiClient) executeJSONRequest(ctx context.Context, prompt string, schema *genai.Schema) (*GeminiResponse, bool, error) {
+	if !c.freeTextOnly {
+		response, err := c.executeRequestWithConfig(ctx, prompt, jsonGenerationConfig(schema))
+		if err == nil {
+			return response, true, nil
+		}
+		if !isSchemaUnsupportedError(err) {
+			return nil, false, err
+		}
+		c.logger.WithFields(logrus.Fields{
+			"model": c.model,
+			"error": err,
+		}).Warn("Model rejected structured output, retrying as free text")
+	}
+
+	response, err := c.executeRequest(ctx, prompt)
+	return response, false, err
+}
+
+// isSchemaUnsupportedError reports whether a structured output request was rejected as invalid,
+// as models without response schema support do. Other failures would recur as free text.
+func isSchemaUnsupportedError(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusBadRequest
+	}
+
+	if st, ok := status.FromError(errors.Unwrap(err)); ok {
+		return st.Code() == codes.InvalidArgument
+	}
+	return false
+}
+
+// decodeStructuredSummarizeResponse decodes a schema-constrained summarization response.
+// It returns nil if the text is not valid JSON so the caller can fall back to the free-text parser.
+func (c *GeminiClient) decodeStructuredSummarizeResponse(response *GeminiResponse) *SummarizeResponse {
+	if len(response.Candidates) == 0 || len(response.Candidates[0].Content.Parts) == 0 {
+		return nil
+	}
+
+	var result SummarizeResponse
+	if err := json.Unmarshal([]byte(strings.TrimSpace(response.Candidates[0].Content.Parts[0].Text)), &result); err != nil {
+		return nil
+	}
+
+	result = c.validateAndCleanResponse(result)
+	return &result
+}
+
+// decodeStructuredOGLesson decodes a schema-constrained lesson response.
+// It returns nil if the text is not valid JSON so the caller can fall back to the free-text parser.
+func decodeStructuredOGLesson(responseText string) *OGLesson {
+	var lesson OGLesson
+	if err := json.Unmarshal([]byte(strings.TrimSpace(responseText)), &lesson); err != nil {
+		return nil
+	}
+
+	lesson.BigPicture = strings.TrimSpace(lesson.BigPicture)
+	lesson.Metaphor = strings.TrimSpace(lesson.Metaphor)
+	lesson.CoreMechanism = strings.TrimSpace(lesson.CoreMechanism)
+	lesson.ToyExampleCode = strings.TrimSpace(lesson.ToyExampleCode)
+	lesson.MemoryHook = strings.TrimSpace(lesson.MemoryHook)
+	lesson.RealLife = strings.TrimSpace(lesson.RealLife)
+	lesson.BestPractices = strings.TrimSpace(lesson.BestPractices)
+	return &lesson
+}
@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// jsonFieldNames returns the JSON field names of a struct type, skipping fields that are never serialized
+func jsonFieldNames(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// TestResponseSchemasMatchStructs tests that the response schemas cover every struct field
+func TestResponseSchemasMatchStructs(t *testing.T) {
+	assert.ElementsMatch(t, jsonFieldNames(OGLesson{}), ogLessonResponseSchema.Required)
+	assert.ElementsMatch(t, jsonFieldNames(SummarizeResponse{}), summarizeResponseSchema.Required)
+}
+
+// TestJSONGenerationConfig tests the structured output generation config
+func TestJSONGenerationConfig(t *testing.T) {
+	config := jsonGenerationConfig(summarizeResponseSchema)
+	assert.Equal(t, "application/json", config.ResponseMIMEType)
+	assert.Same(t, summarizeResponseSchema, config.ResponseSchema)
+
+	client := NewGeminiClient("test-api-key")
+	assert.False(t, client.freeTextOnly)
+	client.SetStructuredOutput(false)
+	assert.True(t, client.freeTextOnly)
+}
+
+// TestExecuteJSONRequestFallback tests that only rejected schemas are retried as free text
+func TestExecuteJSONRequestFallback(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		err       error
+		fallback  bool
+		wantCalls int
+	}{
+		{name: "bad request", err: &googleapi.Error{Code: 400}, fallback: true, wantCalls: 2},
+		{name: "invalid argument", err: fmt.Errorf("failed to generate content: %w", status.Error(codes.InvalidArgument, "response_schema")), fallback: true, wantCalls: 2},
+		{name: "quota", err: &googleapi.Error{Code: 429}, wantCalls: 1},
+		{name: "server error", err: &googleapi.Error{Code: 503}, wantCalls: 1},
+	} {
+		calls := 0
+		client := &GeminiClient{model: DefaultModel, logger: logrus.New()}
+		client.generate = func(ctx context.Context, model string, prompt genai.Part, config *genai.GenerationConfig) (*genai.GenerateContentResponse, error) {
+			calls++
+			if config != nil && config.ResponseSchema != nil {
+				return nil, tc.err
+			}
+			return &genai.GenerateContentResponse{
+				Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text("free text")}}}},
+			}, nil
+		}
+
+		_, structured, err := client.executeJSONRequest(context.Background(), "prompt", summarizeResponseSchema)
+		assert.Equal(t, tc.wantCalls, calls, tc.name)
+		assert.False(t, structured, tc.name)
+		if tc.fallback {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.Error(t, err, tc.name)
+		}
+	}
+}
+
+// TestDecodeStructuredOGLesson tests decoding schema-constrained lesson output
+func TestDecodeStructuredOGLesson(t *testing.T) {
+	lesson := decodeStructuredOGLesson(`{"big_picture": "  Overview ", "metaphor": "Like a post office"}`)
+	require.NotNil(t, lesson)
+	assert.Equal(t, "Overview", lesson.BigPicture)
+	assert.Equal(t, "Like a post office", lesson.Metaphor)
+
+	// Free text is left to the fallback parser
+	assert.Nil(t, decodeStructuredOGLesson("Here is the lesson: {\"big_picture\": \"x\"}"))
+}
+
+// TestDecodeStructuredSummarizeResponse tests decoding schema-constrained summary output
+func TestDecodeStructuredSummarizeResponse(t *testing.T) {
+	client := NewGeminiClient("test-api-key")
+	response := &GeminiResponse{Candidates: []GeminiCandidate{{Content: GeminiContent{Parts: []GeminiPart{
+		{Text: `{"outline": ["Intro", "Details"], "prerequisites": [], "misconceptions": ["It is magic"], "citations": []}`},
+	}}}}}
+
+	result := client.decodeStructuredSummarizeResponse(response)
+	require.NotNil(t, result)
+	assert.Equal(t, []string{"Intro", "Details"}, result.Outline)
+	assert.Equal(t, []string{"It is magic"}, result.Misconceptions)
+
+	response.Candidates[0].Content.Parts[0].Text = "not json"
+	assert.Nil(t, client.decodeStructuredSummarizeResponse(response))
+}
@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// scratchpadField is the response field holding the model's private reasoning
+const scratchpadField = "scratchpad"
+
+// scratchpadContextKey is the context key for requesting a reasoning scratchpad
+type scratchpadContextKey struct{}
+
+// WithScratchpad returns a context whose lesson requests ask the model to reason in a private
+// scratchpad before writing the lesson
+func WithScratchpad(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scratchpadContextKey{}, true)
+}
+
+// ScratchpadFromContext reports whether the context asks for a reasoning scratchpad
+func ScratchpadFromContext(ctx context.Context) bool {
+	enabled, _ := ctx.Value(scratchpadContextKey{}).(bool)
+	return enabled
+}
+
+// ogLessonScratchpadSchema is ogLessonResponseSchema with the scratchpad field
+var ogLessonScratchpadSchema = func() *genai.Schema {
+	properties := make(map[string]*genai.Schema, len(ogLessonResponseSchema.Properties)+1)
+	for name, property := range ogLessonResponseSchema.Properties {
+		properties[name] = property
+	}
+	properties[scratchpadField] = &genai.Schema{Type: genai.TypeString, Description: "Private step-by-step reasoning, never shown to the learner"}
+	return &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: properties,
+		Required:   append([]string{scratchpadField}, ogLessonResponseSchema.Required...),
+	}
+}()
+
+// lessonSchema returns the lesson response schema, including the scratchpad when the context asks for one
+func lessonSchema(ctx context.Context) *genai.Schema {
+	if ScratchpadFromContext(ctx) {
+		return ogLessonScratchpadSchema
+	}
+	return ogLessonResponseSchema
+}
+
+// appendScratchpadInstructions asks for the scratchpad field when the context requests one
+func appendScratchpadInstructions(ctx context.Context, prompt string) string {
+	if !ScratchpadFromContext(ctx) {
+		return prompt
+	}
+	return prompt + "\n\nScratchpad:\n" +
+		`Add a "scratchpad" field to the JSON object, written before the lesson fields. Use it to reason step by step: ` +
+		"identify what the learner most needs, check the mechanism for errors and plan how the metaphor and example fit together. " +
+		"The scratchpad is private and never shown to the learner, so the lesson fields must stand on their own.\n"
+}
+
+// extractScratchpad returns the scratchpad field of a lesson response, or "" if it has none
+func extractScratchpad(responseText string) string {
+	jsonStart := strings.Index(responseText, "{")
+	jsonEnd := strings.LastIndex(responseText, "}")
+	if jsonStart == -1 || jsonEnd <= jsonStart {
+		return ""
+	}
+	var response struct {
+		Scratchpad string `json:"scratchpad"`
+	}
+	if err := json.Unmarshal([]byte(responseText[jsonStart:jsonEnd+1]), &response); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(response.Scratchpad)
+}
@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scratchpadLesson is a lesson response with a scratchpad
+const scratchpadLesson = `{"scratchpad": " Learner needs the intuition first. ", "big_picture": "Caches keep hot data close.", "metaphor": "A desk drawer.", "core_mechanism": "Hits skip the slow store.", "toy_example_code": "N/A", "memory_hook": "Close is fast.", "real_life": "CPUs and CDNs.", "best_practices": "Set expiry."}`
+
+// TestExplainWithOGScratchpad tests that a requested scratchpad is parsed but never serialized with the lesson
+func TestExplainWithOGScratchpad(t *testing.T) {
+	var prompt string
+	var schema *genai.Schema
+	client := &GeminiClient{model: DefaultModel, logger: logrus.New()}
+	client.generate = func(ctx context.Context, model string, part genai.Part, config *genai.GenerationConfig) (*genai.GenerateContentResponse, error) {
+		prompt = string(part.(genai.Text))
+		schema = config.ResponseSchema
+		return &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text(scratchpadLesson)}}}},
+		}, nil
+	}
+
+	lesson, err := client.ExplainWithOG(context.Background(), "caching", "", "", "")
+	require.NoError(t, err)
+	assert.Empty(t, lesson.Scratchpad, "scratchpads are only kept when requested")
+	assert.NotContains(t, prompt, "Scratchpad:")
+	assert.NotContains(t, schema.Properties, scratchpadField)
+
+	lesson, err = client.ExplainWithOG(WithScratchpad(context.Background()), "caching", "", "", "")
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "Scratchpad:")
+	assert.Contains(t, schema.Required, scratchpadField)
+	assert.Equal(t, "Learner needs the intuition first.", lesson.Scratchpad)
+	assert.Equal(t, "Caches keep hot data close.", lesson.BigPicture)
+
+	data, err := json.Marshal(lesson)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "intuition")
+}
@@ -0,0 +1,217 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxAnchorSlugLen limits the length of the text part of an outline anchor
+const maxAnchorSlugLen = 48
+
+// LessonSection describes one OGLesson field and its stable anchor
+type LessonSection struct {
+	ID    string `json:"id"`    // Stable anchor ID (e.g. "core-mechanism")
+	Field string `json:"field"` // JSON field name in OGLesson (e.g. "core_mechanism")
+	Title string `json:"title"` // Human-readable heading
+}
+
+// LessonSections lists the OGLesson sections in display order
+var LessonSections = []LessonSection{
+	{ID: "big-picture", Field: "big_picture", Title: "Big Picture"},
+	{ID: "metaphor", Field: "metaphor", Title: "Metaphor"},
+	{ID: "core-mechanism", Field: "core_mechanism", Title: "Core Mechanism"},
+	{ID: "toy-example", Field: "toy_example_code", Title: "Toy Example"},
+	{ID: "memory-hook", Field: "memory_hook", Title: "Memory Hook"},
+	{ID: "real-life", Field: "real_life", Title: "Real Life"},
+	{ID: "best-practices", Field: "best_practices", Title: "Best Practices"},
+}
+
+// TOCEntry represents one entry in a lesson's table of contents
+type TOCEntry struct {
+	ID       string `json:"id"`              // Anchor ID, usable as a URL fragment or ?section= value
+	Fragment string `json:"fragment"`        // URL fragment linking to the anchor, e.g. "#core-mechanism"
+	Title    string `json:"title"`           // Heading or outline bullet text
+	Kind     string `json:"kind"`            // "section" or "outline"
+	Field    string `json:"field,omitempty"` // OGLesson field name for sections
+}
+
+// LookupSection returns the section matching an anchor ID or OGLesson field name
+func LookupSection(id string) (LessonSection, bool) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	for _, section := range LessonSections {
+		if section.ID == id || section.Field == id {
+			return section, true
+		}
+	}
+	return LessonSection{}, false
+}
+
+// SectionText returns the lesson content for a section anchor ID or field name
+func (l *OGLesson) SectionText(id string) (string, bool) {
+	section, ok := LookupSection(id)
+	if !ok {
+		return "", false
+	}
+
+	switch section.Field {
+	case "big_picture":
+		return l.BigPicture, true
+	case "metaphor":
+		return l.Metaphor, true
+	case "core_mechanism":
+		return l.CoreMechanism, true
+	case "toy_example_code":
+		return l.ToyExampleCode, true
+	case "memory_hook":
+		return l.MemoryHook, true
+	case "real_life":
+		return l.RealLife, true
+	case "best_practices":
+		return l.BestPractices, true
+	}
+	return "", false
+}
+
+// SetSectionText replaces the lesson content for a section anchor ID or field name.
+// It returns false if the section is unknown.
+func (l *OGLesson) SetSectionText(id, text string) bool {
+	section, ok := LookupSection(id)
+	if !ok {
+		return false
+	}
+
+	switch section.Field {
+	case "big_picture":
+		l.BigPicture = text
+	case "metaphor":
+		l.Metaphor = text
+	case "core_mechanism":
+		l.CoreMechanism = text
+	case "toy_example_code":
+		l.ToyExampleCode = text
+	case "memory_hook":
+		l.MemoryHook = text
+	case "real_life":
+		l.RealLife = text
+	case "best_practices":
+		l.BestPractices = text
+	default:
+		return false
+	}
+	return true
+}
+
+// OutlineAnchor returns the stable anchor ID for an outline bullet.
+// The ID combines the 1-based position with a slug of the bullet text.
+func OutlineAnchor(index int, bullet string) string {
+	slug := Slugify(bullet)
+	if len(slug) > maxAnchorSlugLen {
+		slug = strings.TrimRight(slug[:maxAnchorSlugLen], "-")
+	}
+	if slug == "" {
+		return fmt.Sprintf("outline-%d", index+1)
+	}
+	return fmt.Sprintf("outline-%d-%s", index+1, slug)
+}
+
+// Slugify converts text to a lowercase, hyphen-separated anchor slug
+func Slugify(text string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			pendingHyphen = false
+		default:
+			pendingHyphen = true
+		}
+	}
+	return b.String()
+}
+
+// BuildTableOfContents returns the table of contents for a lesson and its outline.
+// Empty lesson sections are skipped; a nil lesson yields outline entries only.
+func BuildTableOfContents(lesson *OGLesson, outline []string) []TOCEntry {
+	toc := make([]TOCEntry, 0, len(LessonSections)+len(outline))
+
+	if lesson != nil {
+		for _, section := range LessonSections {
+			if text, _ := lesson.SectionText(section.ID); strings.TrimSpace(text) == "" {
+				continue
+			}
+			toc = append(toc, TOCEntry{
+				ID:       section.ID,
+				Fragment: "#" + section.ID,
+				Title:    section.Title,
+				Kind:     "section",
+				Field:    section.Field,
+			})
+		}
+	}
+
+	for i, bullet := range outline {
+		if strings.TrimSpace(bullet) == "" {
+			continue
+		}
+		anchor := OutlineAnchor(i, bullet)
+		toc = append(toc, TOCEntry{
+			ID:       anchor,
+			Fragment: "#" + anchor,
+			Title:    bullet,
+			Kind:     "outline",
+		})
+	}
+
+	return toc
+}
+
+// AnswerQuestion answers a follow-up question about a lesson.
+// lessonContext holds the lesson content the answer should be grounded in,
+// either the full lesson or a single section when the question is scoped.
+func (c *GeminiClient) AnswerQuestion(ctx context.Context, topic, lessonContext, question string) (string, error) {
+	c.logger.WithFields(logrus.Fields{
+		"topic":       topic,
+		"context_len": len(lessonContext),
+		"model":       c.model,
+	}).Info("Answering follow-up question")
+
+	prompt := c.buildQuestionPrompt(topic, lessonContext, question)
+
+	response, err := c.executeRequest(ctx, prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute question request: %w", err)
+	}
+
+	var answer strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		answer.WriteString(part.Text)
+	}
+	if strings.TrimSpace(answer.String()) == "" {
+		return "", fmt.Errorf("empty answer in response")
+	}
+
+	return strings.TrimSpace(answer.String()), nil
+}
+
+// buildQuestionPrompt creates the prompt for a follow-up question
+func (c *GeminiClient) buildQuestionPrompt(topic, lessonContext, question string) string {
+	var promptBuilder strings.Builder
+
+	promptBuilder.WriteString("You are a patient tutor answering a learner's follow-up question about a lesson.\n\n")
+	promptBuilder.WriteString(fmt.Sprintf("Topic: %s\n\n", topic))
+	promptBuilder.WriteString("Lesson content:\n")
+	promptBuilder.WriteString(lessonContext)
+	promptBuilder.WriteString("\n\n")
+	promptBuilder.WriteString(fmt.Sprintf("Question: %s\n\n", question))
+	promptBuilder.WriteString("Answer using the lesson content above. If the question goes beyond it, say so briefly before answering. ")
+	promptBuilder.WriteString("Keep the answer under 200 words and respond in plain text.")
+
+	return promptBuilder.String()
+}
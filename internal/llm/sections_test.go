@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuildTableOfContents tests section and outline anchors in the table of contents
+func TestBuildTableOfContents(t *testing.T) {
+	lesson := &OGLesson{
+		BigPicture:    "Overview",
+		CoreMechanism: "How it works",
+		BestPractices: "Do this",
+	}
+	outline := []string{"What is a goroutine?", "", "Channels & select"}
+
+	toc := BuildTableOfContents(lesson, outline)
+	assert.Len(t, toc, 5)
+	assert.Equal(t, "big-picture", toc[0].ID)
+	assert.Equal(t, "core_mechanism", toc[1].Field)
+	assert.Equal(t, "best-practices", toc[2].ID)
+	assert.Equal(t, "outline-1-what-is-a-goroutine", toc[3].ID)
+	assert.Equal(t, "outline-3-channels-select", toc[4].ID)
+	assert.Equal(t, "outline", toc[4].Kind)
+	assert.Equal(t, "#core-mechanism", toc[1].Fragment)
+	assert.Equal(t, "#outline-3-channels-select", toc[4].Fragment)
+
+	// Anchors are stable across calls
+	assert.Equal(t, toc, BuildTableOfContents(lesson, outline))
+}
+
+// TestSectionText tests looking up lesson content by anchor ID or field name
+func TestSectionText(t *testing.T) {
+	lesson := &OGLesson{ToyExampleCode: "fmt.Println(1)"}
+
+	text, ok := lesson.SectionText("toy-example")
+	assert.True(t, ok)
+	assert.Equal(t, "fmt.Println(1)", text)
+
+	text, ok = lesson.SectionText("toy_example_code")
+	assert.True(t, ok)
+	assert.Equal(t, "fmt.Println(1)", text)
+
+	_, ok = lesson.SectionText("appendix")
+	assert.False(t, ok)
+}
+
+// TestOutlineAnchor tests anchor generation for outline bullets
+func TestOutlineAnchor(t *testing.T) {
+	assert.Equal(t, "outline-2", OutlineAnchor(1, "!!!"))
+	long := OutlineAnchor(0, "a very long outline bullet that keeps going well past the slug length limit")
+	assert.LessOrEqual(t, len(long), len("outline-1-")+maxAnchorSlugLen)
+	assert.NotContains(t, long[len(long)-1:], "-")
+}
+
+// TestSetSectionText tests replacing lesson content by anchor ID or field name
+func TestSetSectionText(t *testing.T) {
+	lesson := &OGLesson{Metaphor: "old"}
+
+	assert.True(t, lesson.SetSectionText("metaphor", "new"))
+	assert.Equal(t, "new", lesson.Metaphor)
+	assert.True(t, lesson.SetSectionText("toy-example", "fmt.Println(2)"))
+	assert.Equal(t, "fmt.Println(2)", lesson.ToyExampleCode)
+	assert.False(t, lesson.SetSectionText("appendix", "x"))
+}
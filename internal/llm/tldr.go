@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxTLDRChars caps a lesson TL;DR so it fits notification previews and list rows
+	MaxTLDRChars = 280
+	// tldrSentences is how many sentences a TL;DR keeps
+	tldrSentences = 2
+)
+
+// tldrLabelPattern matches a label the model may put before the TL;DR
+var tldrLabelPattern = regexp.MustCompile(`(?i)^(tl;?dr|summary)\s*[:\-–—]\s*`)
+
+// WriteTLDR asks the model for a one- or two-sentence summary of a finished lesson, for
+// notifications, saved-lesson lists and other previews
+func (c *GeminiClient) WriteTLDR(ctx context.Context, topic, lesson string) (string, error) {
+	c.logger.WithFields(logrus.Fields{
+		"topic": topic,
+		"model": c.model,
+	}).Info("Writing lesson TL;DR with Gemini")
+
+	response, err := c.executeRequest(ctx, buildTLDRPrompt(topic, lesson))
+	if err != nil {
+		return "", fmt.Errorf("failed to execute TL;DR request: %w", err)
+	}
+	if len(response.Candidates) == 0 {
+		return "", fmt.Errorf("no candidates in response")
+	}
+
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return parseTLDR(text.String())
+}
+
+// buildTLDRPrompt creates the prompt asking for a short summary of the lesson
+func buildTLDRPrompt(topic, lesson string) string {
+	var promptBuilder strings.Builder
+	promptBuilder.WriteString("You are an expert educator writing a preview of a lesson.\n\n")
+	promptBuilder.WriteString(fmt.Sprintf("Topic: %s\n\n", topic))
+	if lesson = strings.TrimSpace(lesson); lesson != "" {
+		promptBuilder.WriteString("Lesson:\n")
+		promptBuilder.WriteString(lesson)
+		promptBuilder.WriteString("\n\n")
+	}
+	promptBuilder.WriteString("Write a TL;DR of the lesson in one or two plain sentences a learner could read in a notification. ")
+	promptBuilder.WriteString(fmt.Sprintf("Use at most %d characters, no Markdown, no label and no quotes.\n\n", MaxTLDRChars))
+	promptBuilder.WriteString("Your TL;DR:\n")
+	return promptBuilder.String()
+}
+
+// parseTLDR cleans the model's TL;DR, failing when nothing usable is left
+func parseTLDR(responseText string) (string, error) {
+	tldr := ExtractTLDR(responseText)
+	if tldr == "" {
+		return "", fmt.Errorf("empty TL;DR in response")
+	}
+	return tldr, nil
+}
+
+// ExtractTLDR reduces text to a TL;DR: plain text on one line, its first two sentences, capped at
+// MaxTLDRChars. It serves both model output and the extractive fallback from a lesson's opening.
+func ExtractTLDR(text string) string {
+	text = strings.Join(strings.Fields(MarkdownToPlainText(text)), " ")
+	text = strings.Trim(tldrLabelPattern.ReplaceAllString(text, ""), `"“” `)
+	text = firstSentences(text, tldrSentences)
+	text, _ = TruncateText(text, MaxTLDRChars)
+	return text
+}
+
+// firstSentences returns the first n sentences of single-line text
+func firstSentences(text string, n int) string {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '.', '!', '?':
+			if i+1 == len(text) || text[i+1] == ' ' {
+				n--
+				if n == 0 {
+					return text[:i+1]
+				}
+			}
+		}
+	}
+	return text
+}
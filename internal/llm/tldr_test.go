@@ -0,0 +1,32 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTLDR tests that model TL;DRs lose labels and Markdown and keep at most two sentences
+func TestParseTLDR(t *testing.T) {
+	tldr, err := parseTLDR("TL;DR: **Caches** keep hot data close.  They trade memory for speed! Eviction decides what stays.\n")
+	require.NoError(t, err)
+	assert.Equal(t, "Caches keep hot data close. They trade memory for speed!", tldr)
+
+	tldr, err = parseTLDR(`"Raft elects a leader to order the log, version 3.1 explains it"`)
+	require.NoError(t, err)
+	assert.Equal(t, "Raft elects a leader to order the log, version 3.1 explains it", tldr)
+
+	_, err = parseTLDR("TL;DR:  \n")
+	assert.Error(t, err)
+	assert.Contains(t, buildTLDRPrompt("Raft", "Leader election"), "Topic: Raft")
+}
+
+// TestExtractTLDRCapsLength tests that a TL;DR without sentence breaks is cut to fit previews
+func TestExtractTLDRCapsLength(t *testing.T) {
+	tldr := ExtractTLDR(strings.Repeat("word ", 200))
+	assert.LessOrEqual(t, utf8.RuneCountInString(tldr), MaxTLDRChars)
+	assert.True(t, strings.HasSuffix(tldr, "…"))
+}
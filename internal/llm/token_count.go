@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/tokens"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// ErrTokenCountUnavailable is returned by CountTokens when the client cannot reach the countTokens API
+var ErrTokenCountUnavailable = errors.New("token counting is not available for this client")
+
+// CountTokens implements tokens.Counter with the Gemini countTokens API. An empty model counts
+// for the request's model override or the client's model.
+func (c *GeminiClient) CountTokens(ctx context.Context, model, text string) (int, error) {
+	if c.client == nil {
+		return 0, ErrTokenCountUnavailable
+	}
+	if model == "" {
+		model = c.model
+		if override := ModelFromContext(ctx); override != "" {
+			model = override
+		}
+	}
+	response, err := c.client.GenerativeModel(model).CountTokens(ctx, genai.Text(text))
+	if err != nil {
+		return 0, err
+	}
+	return int(response.TotalTokens), nil
+}
+
+// usageOrEstimate returns a response's reported token usage, estimating it from the prompt and
+// output when the model reported none, so cost tracking never sees a free request
+func usageOrEstimate(model, prompt string, result *genai.GenerateContentResponse) *genai.UsageMetadata {
+	if result.UsageMetadata != nil && (result.UsageMetadata.PromptTokenCount > 0 || result.UsageMetadata.CandidatesTokenCount > 0) {
+		return result.UsageMetadata
+	}
+	var output strings.Builder
+	for _, candidate := range result.Candidates {
+		if candidate.Content == nil {
+			continue
+		}
+		for _, part := range candidate.Content.Parts {
+			switch part := part.(type) {
+			case genai.Text:
+				output.WriteString(string(part))
+			case genai.FunctionCall:
+				args, _ := json.Marshal(part.Args)
+				output.Write(args)
+			}
+		}
+	}
+	return &genai.UsageMetadata{
+		PromptTokenCount:     int32(tokens.Estimate(model, prompt)),
+		CandidatesTokenCount: int32(tokens.Estimate(model, output.String())),
+	}
+}
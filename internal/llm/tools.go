@@ -0,0 +1,169 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// critiqueToolName is the function the critic calls to submit its findings
+const critiqueToolName = "submit_critique"
+
+// critiquePromptIntro opens every critique prompt; the lesson JSON follows it
+const critiquePromptIntro = `
+You are an expert educational content reviewer. Your task is to critique a lesson and provide specific, actionable feedback.
+
+Analyze the following lesson JSON and identify issues, then create a patch plan to fix them.
+
+Lesson to critique:
+`
+
+// errInvalidToolArguments is returned when a tool call's arguments do not decode into the expected type
+var errInvalidToolArguments = errors.New("invalid tool arguments")
+
+// isToolFallbackError reports whether a failed tool call should be retried as free text: the model
+// rejected the tool declaration, answered without calling it or called it with unusable arguments
+func isToolFallbackError(err error) bool {
+	return isSchemaUnsupportedError(err) || errors.Is(err, ErrEmptyCandidates) || errors.Is(err, errInvalidToolArguments)
+}
+
+// critiqueTool declares submit_critique, whose arguments mirror CritiqueResponse
+var critiqueTool = &genai.Tool{
+	FunctionDeclarations: []*genai.FunctionDeclaration{{
+		Name:        critiqueToolName,
+		Description: "Submit the issues found in the lesson and the patch plan that fixes them",
+		Parameters: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"issues": {
+					Type: genai.TypeArray,
+					Items: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"section":    {Type: genai.TypeString, Enum: ogLessonResponseSchema.Required, Description: "Lesson field the issue is in"},
+							"problem":    {Type: genai.TypeString, Description: "Specific description of the problem"},
+							"severity":   {Type: genai.TypeString, Enum: critiqueSeverities},
+							"confidence": {Type: genai.TypeNumber, Description: "How sure you are that this is a real problem, from 0 to 1"},
+						},
+						Required: []string{"section", "problem", "severity", "confidence"},
+					},
+				},
+				"patch_plan": {
+					Type: genai.TypeArray,
+					Items: &genai.Schema{
+						Type: genai.TypeObject,
+						Properties: map[string]*genai.Schema{
+							"section":          {Type: genai.TypeString, Enum: ogLessonResponseSchema.Required, Description: "Lesson field to modify"},
+							"change":           {Type: genai.TypeString, Description: "Description of what needs to change"},
+							"replacement_text": {Type: genai.TypeString, Description: "The complete new text for the section"},
+						},
+						Required: []string{"section", "change", "replacement_text"},
+					},
+				},
+			},
+			Required: []string{"issues", "patch_plan"},
+		},
+	}},
+}
+
+// toolCallConfig requires the model to answer by calling the named function
+func toolCallConfig(name string) *genai.ToolConfig {
+	return &genai.ToolConfig{
+		FunctionCallingConfig: &genai.FunctionCallingConfig{
+			Mode:                 genai.FunctionCallingAny,
+			AllowedFunctionNames: []string{name},
+		},
+	}
+}
+
+// GenerateToolCall generates content with function declarations available to the model
+func (m *ModelsWrapper) GenerateToolCall(ctx context.Context, modelName string, prompt genai.Part, tools []*genai.Tool, toolConfig *genai.ToolConfig) (*genai.GenerateContentResponse, error) {
+	if m.vertex != nil {
+		return m.vertex.generateContent(ctx, modelName, prompt, nil, tools, toolConfig)
+	}
+	model := m.client.GenerativeModel(modelName)
+	model.Tools = tools
+	model.ToolConfig = toolConfig
+	return model.GenerateContent(ctx, prompt)
+}
+
+// executeToolCall asks the model to call the tool's function and returns the call's arguments.
+// A response without the call is treated like an empty response and moves down the fallback chain.
+func (c *GeminiClient) executeToolCall(ctx context.Context, prompt string, tool *genai.Tool) (map[string]any, error) {
+	generate := c.generateToolCall
+	if generate == nil {
+		if c.Models == nil {
+			return nil, fmt.Errorf("Gemini client not initialized")
+		}
+		generate = c.Models.GenerateToolCall
+	}
+	name := tool.FunctionDeclarations[0].Name
+
+	var args map[string]any
+	err := c.runModelChain(ctx, func(model string) error {
+		recordPrompt(ctx, model, prompt, nil, name)
+		result, err := generate(ctx, model, genai.Text(prompt), []*genai.Tool{tool}, toolCallConfig(name))
+		if err != nil {
+			return fmt.Errorf("failed to generate content: %w", err)
+		}
+		recordTokens(ctx, usageOrEstimate(model, prompt, result))
+		for _, candidate := range result.Candidates {
+			for _, call := range candidate.FunctionCalls() {
+				if call.Name == name {
+					args = call.Args
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("%w: model %s did not call %s", ErrEmptyCandidates, model, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// buildCritiqueToolPrompt constructs the prompt asking the model to report its critique through submit_critique
+func buildCritiqueToolPrompt(lessonJSON string, rubric *Rubric) string {
+	if rubric == nil {
+		rubric = DefaultRubric()
+	}
+
+	var promptBuilder strings.Builder
+	promptBuilder.WriteString(critiquePromptIntro)
+	promptBuilder.WriteString(lessonJSON)
+	promptBuilder.WriteString(`
+
+Report your findings by calling ` + critiqueToolName + `. Give every issue a confidence from 0 to 1 that it
+is a real problem, and add a patch plan item with the complete replacement text for each section
+that needs to change. Call it with empty lists if the lesson has no issues.
+
+`)
+	writeRubric(&promptBuilder, rubric)
+	return promptBuilder.String()
+}
+
+// critiqueWithTool critiques a lesson through a submit_critique function call
+func (c *GeminiClient) critiqueWithTool(ctx context.Context, lessonJSON string, rubric *Rubric) (*CritiqueResponse, error) {
+	args, err := c.executeToolCall(ctx, appendPromptInstructions(ctx, buildCritiqueToolPrompt(lessonJSON, rubric)), critiqueTool)
+	if err != nil {
+		return nil, err
+	}
+
+	// Arguments arrive as generic JSON values; round-trip them into the typed response
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s arguments: %w", critiqueToolName, err)
+	}
+	var critiqueResponse CritiqueResponse
+	if err := json.Unmarshal(data, &critiqueResponse); err != nil {
+		return nil, fmt.Errorf("%w for %s: %v", errInvalidToolArguments, critiqueToolName, err)
+	}
+
+	cleanCritiqueResponse(&critiqueResponse)
+	return &critiqueResponse, nil
+}
@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+)
+
+// proseCritique is a free-text critique with its JSON embedded in prose
+const proseCritique = `Here is my review: {"issues": [{"section": "analogy", "problem": "Too abstract", "severity": "low", "confidence": 0.4}], "patch_plan": []}`
+
+// newToolTestClient creates a client whose tool calls are answered by toolCall and free-text requests with proseCritique
+func newToolTestClient(toolCall func(tools []*genai.Tool, config *genai.ToolConfig) (*genai.GenerateContentResponse, error), proseCalls *int) *GeminiClient {
+	client := &GeminiClient{model: DefaultModel, logger: logrus.New()}
+	client.generateToolCall = func(ctx context.Context, model string, prompt genai.Part, tools []*genai.Tool, config *genai.ToolConfig) (*genai.GenerateContentResponse, error) {
+		return toolCall(tools, config)
+	}
+	client.generate = func(ctx context.Context, model string, prompt genai.Part, config *genai.GenerationConfig) (*genai.GenerateContentResponse, error) {
+		*proseCalls++
+		return &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text(proseCritique)}}}},
+		}, nil
+	}
+	return client
+}
+
+// TestCritiqueLessonToolCall tests that the critic's findings are read from the submit_critique call
+func TestCritiqueLessonToolCall(t *testing.T) {
+	proseCalls := 0
+	client := newToolTestClient(func(tools []*genai.Tool, config *genai.ToolConfig) (*genai.GenerateContentResponse, error) {
+		require.Len(t, tools, 1)
+		assert.Equal(t, critiqueToolName, tools[0].FunctionDeclarations[0].Name)
+		assert.Equal(t, []string{critiqueToolName}, config.FunctionCallingConfig.AllowedFunctionNames)
+		call := genai.FunctionCall{
+			Name: critiqueToolName,
+			Args: map[string]any{
+				"issues": []any{
+					map[string]any{"section": "title", "problem": " Vague title ", "severity": "medium", "confidence": 1.7},
+				},
+				"patch_plan": []any{
+					map[string]any{"section": "title", "change": "Be specific", "replacement_text": "Caching in Web Servers"},
+				},
+			},
+		}
+		return &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{call}}}},
+		}, nil
+	}, &proseCalls)
+
+	critique, err := client.CritiqueLesson(context.Background(), `{"title": "Caching"}`)
+	require.NoError(t, err)
+	assert.Zero(t, proseCalls)
+	require.Len(t, critique.Issues, 1)
+	assert.Equal(t, "Vague title", critique.Issues[0].Problem)
+	assert.Equal(t, 1.0, critique.Issues[0].Confidence)
+	require.Len(t, critique.PatchPlan, 1)
+	assert.Equal(t, "Caching in Web Servers", critique.PatchPlan[0].ReplacementText)
+}
+
+// TestCritiqueLessonToolFallback tests that a failed tool call is retried as a free-text critique
+func TestCritiqueLessonToolFallback(t *testing.T) {
+	proseCalls := 0
+	client := newToolTestClient(func(tools []*genai.Tool, config *genai.ToolConfig) (*genai.GenerateContentResponse, error) {
+		return nil, &googleapi.Error{Code: 400}
+	}, &proseCalls)
+
+	critique, err := client.CritiqueLesson(context.Background(), `{"title": "Caching"}`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, proseCalls)
+	require.Len(t, critique.Issues, 1)
+	assert.Equal(t, 0.4, critique.Issues[0].Confidence)
+
+	// A model that answers in text instead of calling the tool also falls back
+	proseCalls = 0
+	client = newToolTestClient(func(tools []*genai.Tool, config *genai.ToolConfig) (*genai.GenerateContentResponse, error) {
+		return &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text("no tool")}}}},
+		}, nil
+	}, &proseCalls)
+	_, err = client.CritiqueLesson(context.Background(), `{"title": "Caching"}`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, proseCalls)
+}
+
+// TestCritiqueLessonToolErrorNotRetried tests that quota and server errors are returned instead of retried as free text
+func TestCritiqueLessonToolErrorNotRetried(t *testing.T) {
+	for _, code := range []int{429, 503} {
+		proseCalls := 0
+		client := newToolTestClient(func(tools []*genai.Tool, config *genai.ToolConfig) (*genai.GenerateContentResponse, error) {
+			return nil, &googleapi.Error{Code: code}
+		}, &proseCalls)
+
+		_, err := client.CritiqueLesson(context.Background(), `{"title": "Caching"}`)
+		assert.Error(t, err, code)
+		assert.Zero(t, proseCalls, code)
+	}
+
+	// Arguments that do not match the critique shape still fall back
+	proseCalls := 0
+	client := newToolTestClient(func(tools []*genai.Tool, config *genai.ToolConfig) (*genai.GenerateContentResponse, error) {
+		return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{
+			genai.FunctionCall{Name: critiqueToolName, Args: map[string]any{"issues": "none"}},
+		}}}}}, nil
+	}, &proseCalls)
+	_, err := client.CritiqueLesson(context.Background(), `{"title": "Caching"}`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, proseCalls)
+}
+
+// TestCritiqueLessonFreeTextOnly tests that free-text mode never attempts the tool call
+func TestCritiqueLessonFreeTextOnly(t *testing.T) {
+	proseCalls := 0
+	client := newToolTestClient(func(tools []*genai.Tool, config *genai.ToolConfig) (*genai.GenerateContentResponse, error) {
+		t.Fatal("tool call attempted in free-text mode")
+		return nil, nil
+	}, &proseCalls)
+	client.freeTextOnly = true
+
+	_, err := client.CritiqueLesson(context.Background(), `{"title": "Caching"}`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, proseCalls)
+}
@@ -0,0 +1,413 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
+)
+
+// Gemini backends
+const (
+	BackendAIStudio = "aistudio" // Google AI Studio, authenticated with an API key or ADC
+	BackendVertex   = "vertex"   // Vertex AI, authenticated with a service account in a project and region
+)
+
+const (
+	// defaultVertexLocation is the Vertex AI region used when none is configured
+	defaultVertexLocation = "us-central1"
+	// vertexScope is the OAuth scope Vertex AI requests are authorized with
+	vertexScope = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// GeminiConfig selects the backend a GeminiClient calls and how it authenticates
+type GeminiConfig struct {
+	Backend      string   // BackendAIStudio (default) or BackendVertex
+	APIKey       string   // AI Studio API key; empty uses Application Default Credentials
+	Project      string   // Vertex AI project the models are called in
+	Locations    []string // Vertex AI regions, tried in order while a region is overloaded or unavailable
+	QuotaProject string   // Project billed and quota-checked for requests, when not the credentials' own
+}
+
+// GeminiConfigFromEnv reads the backend from GEMINI_BACKEND ("aistudio" or "vertex"), the API key
+// from GEMINI_API_KEY, the Vertex AI project from VERTEX_PROJECT (or GCP_PROJECT_ID), its regions
+// from the comma-separated VERTEX_LOCATION and the quota project from GEMINI_QUOTA_PROJECT
+func GeminiConfigFromEnv() GeminiConfig {
+	cfg := GeminiConfig{
+		Backend:      BackendAIStudio,
+		APIKey:       os.Getenv("GEMINI_API_KEY"),
+		Project:      os.Getenv("VERTEX_PROJECT"),
+		QuotaProject: os.Getenv("GEMINI_QUOTA_PROJECT"),
+	}
+	switch backend := strings.ToLower(strings.TrimSpace(os.Getenv("GEMINI_BACKEND"))); backend {
+	case "", BackendAIStudio:
+	case BackendVertex:
+		cfg.Backend = BackendVertex
+	default:
+		logrus.WithField("value", backend).Warn("Invalid GEMINI_BACKEND, using AI Studio")
+	}
+	if cfg.Project == "" {
+		cfg.Project = os.Getenv("GCP_PROJECT_ID")
+	}
+	for _, location := range strings.Split(os.Getenv("VERTEX_LOCATION"), ",") {
+		if location = strings.TrimSpace(location); location != "" {
+			cfg.Locations = append(cfg.Locations, location)
+		}
+	}
+	return cfg
+}
+
+// vertexBackend calls Gemini models through the Vertex AI REST API
+type vertexBackend struct {
+	project      string
+	locations    []string
+	quotaProject string
+	httpClient   *http.Client // Adds the service account's OAuth token to requests
+	logger       *logrus.Logger
+
+	// baseURL overrides the regional endpoint; used by tests
+	baseURL func(location string) string
+}
+
+// newVertexBackend creates a Vertex AI backend authenticated with Application Default Credentials
+func newVertexBackend(ctx context.Context, cfg GeminiConfig) (*vertexBackend, error) {
+	if cfg.Project == "" {
+		return nil, fmt.Errorf("Vertex AI requires a project (VERTEX_PROJECT or GCP_PROJECT_ID)")
+	}
+	httpClient, err := google.DefaultClient(ctx, vertexScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Vertex AI credentials: %w", err)
+	}
+	locations := cfg.Locations
+	if len(locations) == 0 {
+		locations = []string{defaultVertexLocation}
+	}
+	return &vertexBackend{
+		project:      cfg.Project,
+		locations:    locations,
+		quotaProject: cfg.QuotaProject,
+		httpClient:   httpClient,
+		logger:       logrus.New(),
+	}, nil
+}
+
+// endpoint returns the generateContent URL for a model in a region. The "global" location is
+// served from the non-regional host.
+func (v *vertexBackend) endpoint(location, model string) string {
+	base := "https://" + location + "-aiplatform.googleapis.com"
+	if location == "global" {
+		base = "https://aiplatform.googleapis.com"
+	}
+	if v.baseURL != nil {
+		base = v.baseURL(location)
+	}
+	return fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/google/models/%s:generateContent", base, v.project, location, model)
+}
+
+// generateContent calls a model in the first region that is not overloaded or unavailable
+func (v *vertexBackend) generateContent(ctx context.Context, model string, prompt genai.Part, config *genai.GenerationConfig, tools []*genai.Tool, toolConfig *genai.ToolConfig) (*genai.GenerateContentResponse, error) {
+	text, ok := prompt.(genai.Text)
+	if !ok {
+		return nil, fmt.Errorf("Vertex AI backend does not support %T prompts", prompt)
+	}
+	body, err := json.Marshal(newVertexRequest(string(text), config, tools, toolConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Vertex AI request: %w", err)
+	}
+
+	var lastErr error
+	for i, location := range v.locations {
+		result, err := v.post(ctx, v.endpoint(location, model), body)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil || !regionalRetryable(err) || i == len(v.locations)-1 {
+			break
+		}
+		v.logger.WithFields(logrus.Fields{
+			"model":         model,
+			"location":      location,
+			"next_location": v.locations[i+1],
+			"error":         err,
+		}).Warn("Vertex AI region unavailable, retrying in the next region")
+	}
+	return nil, lastErr
+}
+
+// post sends one generateContent request and converts the response to the SDK's types
+func (v *vertexBackend) post(ctx context.Context, url string, body []byte) (*genai.GenerateContentResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if v.quotaProject != "" {
+		req.Header.Set("X-Goog-User-Project", v.quotaProject)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := &googleapi.Error{Code: resp.StatusCode, Body: string(data), Header: resp.Header}
+		var payload struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &payload) == nil {
+			apiErr.Message = payload.Error.Message
+		}
+		return nil, apiErr
+	}
+
+	var result vertexResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode Vertex AI response: %w", err)
+	}
+	return result.toSDK()
+}
+
+// regionalRetryable reports whether another region may succeed where this one failed
+func regionalRetryable(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+	}
+	// Transport errors, such as a regional endpoint that cannot be reached
+	return true
+}
+
+// vertexPart is one part of a Vertex AI content
+type vertexPart struct {
+	Text         string              `json:"text,omitempty"`
+	FunctionCall *vertexFunctionCall `json:"functionCall,omitempty"`
+}
+
+// vertexFunctionCall is a function call predicted by the model
+type vertexFunctionCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// vertexContent is a message in a Vertex AI conversation
+type vertexContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []vertexPart `json:"parts"`
+}
+
+// vertexSchema is the Vertex AI form of genai.Schema
+type vertexSchema struct {
+	Type        string                   `json:"type,omitempty"`
+	Format      string                   `json:"format,omitempty"`
+	Description string                   `json:"description,omitempty"`
+	Nullable    bool                     `json:"nullable,omitempty"`
+	Enum        []string                 `json:"enum,omitempty"`
+	Items       *vertexSchema            `json:"items,omitempty"`
+	Properties  map[string]*vertexSchema `json:"properties,omitempty"`
+	Required    []string                 `json:"required,omitempty"`
+}
+
+// vertexGenerationConfig is the Vertex AI form of genai.GenerationConfig
+type vertexGenerationConfig struct {
+	CandidateCount   *int32        `json:"candidateCount,omitempty"`
+	StopSequences    []string      `json:"stopSequences,omitempty"`
+	MaxOutputTokens  *int32        `json:"maxOutputTokens,omitempty"`
+	Temperature      *float32      `json:"temperature,omitempty"`
+	TopP             *float32      `json:"topP,omitempty"`
+	TopK             *int32        `json:"topK,omitempty"`
+	ResponseMIMEType string        `json:"responseMimeType,omitempty"`
+	ResponseSchema   *vertexSchema `json:"responseSchema,omitempty"`
+}
+
+// vertexFunctionDeclaration is the Vertex AI form of genai.FunctionDeclaration
+type vertexFunctionDeclaration struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Parameters  *vertexSchema `json:"parameters,omitempty"`
+}
+
+// vertexTool is the Vertex AI form of genai.Tool
+type vertexTool struct {
+	FunctionDeclarations []vertexFunctionDeclaration `json:"functionDeclarations"`
+}
+
+// vertexToolConfig is the Vertex AI form of genai.ToolConfig
+type vertexToolConfig struct {
+	FunctionCallingConfig struct {
+		Mode                 string   `json:"mode,omitempty"`
+		AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+	} `json:"functionCallingConfig"`
+}
+
+// vertexRequest is a Vertex AI generateContent request
+type vertexRequest struct {
+	Contents         []vertexContent         `json:"contents"`
+	GenerationConfig *vertexGenerationConfig `json:"generationConfig,omitempty"`
+	Tools            []vertexTool            `json:"tools,omitempty"`
+	ToolConfig       *vertexToolConfig       `json:"toolConfig,omitempty"`
+}
+
+// vertexResponse is a Vertex AI generateContent response
+type vertexResponse struct {
+	Candidates []struct {
+		Index        int32          `json:"index"`
+		Content      *vertexContent `json:"content"`
+		FinishReason string         `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata *struct {
+		PromptTokenCount        int32 `json:"promptTokenCount"`
+		CachedContentTokenCount int32 `json:"cachedContentTokenCount"`
+		CandidatesTokenCount    int32 `json:"candidatesTokenCount"`
+		TotalTokenCount         int32 `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// Vertex AI enum names, indexed by the SDK's enum values
+var (
+	vertexTypes         = []string{"TYPE_UNSPECIFIED", "STRING", "NUMBER", "INTEGER", "BOOLEAN", "ARRAY", "OBJECT"}
+	vertexCallingModes  = []string{"MODE_UNSPECIFIED", "AUTO", "ANY", "NONE"}
+	vertexFinishReasons = []string{"FINISH_REASON_UNSPECIFIED", "STOP", "MAX_TOKENS", "SAFETY", "RECITATION", "OTHER"}
+	vertexBlockReasons  = []string{"BLOCK_REASON_UNSPECIFIED", "SAFETY", "OTHER"}
+)
+
+// enumName returns the Vertex AI name of an SDK enum value
+func enumName(names []string, value int) string {
+	if value < 0 || value >= len(names) {
+		return ""
+	}
+	return names[value]
+}
+
+// enumValue returns the SDK enum value of a Vertex AI name; unknown names map to the last, "other" value
+func enumValue(names []string, name string) int {
+	if name == "" {
+		return 0
+	}
+	for i, candidate := range names {
+		if candidate == name {
+			return i
+		}
+	}
+	return len(names) - 1
+}
+
+// newVertexRequest converts an SDK request to a Vertex AI request
+func newVertexRequest(prompt string, config *genai.GenerationConfig, tools []*genai.Tool, toolConfig *genai.ToolConfig) vertexRequest {
+	req := vertexRequest{Contents: []vertexContent{{Role: "user", Parts: []vertexPart{{Text: prompt}}}}}
+	if config != nil {
+		req.GenerationConfig = &vertexGenerationConfig{
+			CandidateCount:   config.CandidateCount,
+			StopSequences:    config.StopSequences,
+			MaxOutputTokens:  config.MaxOutputTokens,
+			Temperature:      config.Temperature,
+			TopP:             config.TopP,
+			TopK:             config.TopK,
+			ResponseMIMEType: config.ResponseMIMEType,
+			ResponseSchema:   newVertexSchema(config.ResponseSchema),
+		}
+	}
+	for _, tool := range tools {
+		var converted vertexTool
+		for _, decl := range tool.FunctionDeclarations {
+			converted.FunctionDeclarations = append(converted.FunctionDeclarations, vertexFunctionDeclaration{
+				Name:        decl.Name,
+				Description: decl.Description,
+				Parameters:  newVertexSchema(decl.Parameters),
+			})
+		}
+		req.Tools = append(req.Tools, converted)
+	}
+	if toolConfig != nil && toolConfig.FunctionCallingConfig != nil {
+		req.ToolConfig = &vertexToolConfig{}
+		req.ToolConfig.FunctionCallingConfig.Mode = enumName(vertexCallingModes, int(toolConfig.FunctionCallingConfig.Mode))
+		req.ToolConfig.FunctionCallingConfig.AllowedFunctionNames = toolConfig.FunctionCallingConfig.AllowedFunctionNames
+	}
+	return req
+}
+
+// newVertexSchema converts an SDK schema to a Vertex AI schema
+func newVertexSchema(schema *genai.Schema) *vertexSchema {
+	if schema == nil {
+		return nil
+	}
+	converted := &vertexSchema{
+		Type:        enumName(vertexTypes, int(schema.Type)),
+		Format:      schema.Format,
+		Description: schema.Description,
+		Nullable:    schema.Nullable,
+		Enum:        schema.Enum,
+		Items:       newVertexSchema(schema.Items),
+		Required:    schema.Required,
+	}
+	if len(schema.Properties) > 0 {
+		converted.Properties = make(map[string]*vertexSchema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			converted.Properties[name] = newVertexSchema(property)
+		}
+	}
+	return converted
+}
+
+// toSDK converts a Vertex AI response to the SDK's response, returning a *genai.BlockedError for
+// blocked prompts and candidates like the SDK does
+func (r vertexResponse) toSDK() (*genai.GenerateContentResponse, error) {
+	result := &genai.GenerateContentResponse{}
+	if r.UsageMetadata != nil {
+		result.UsageMetadata = &genai.UsageMetadata{
+			PromptTokenCount:        r.UsageMetadata.PromptTokenCount,
+			CachedContentTokenCount: r.UsageMetadata.CachedContentTokenCount,
+			CandidatesTokenCount:    r.UsageMetadata.CandidatesTokenCount,
+			TotalTokenCount:         r.UsageMetadata.TotalTokenCount,
+		}
+	}
+	if r.PromptFeedback != nil && r.PromptFeedback.BlockReason != "" {
+		result.PromptFeedback = &genai.PromptFeedback{BlockReason: genai.BlockReason(enumValue(vertexBlockReasons, r.PromptFeedback.BlockReason))}
+		if result.PromptFeedback.BlockReason != genai.BlockReasonUnspecified {
+			return nil, &genai.BlockedError{PromptFeedback: result.PromptFeedback}
+		}
+	}
+
+	for _, c := range r.Candidates {
+		candidate := &genai.Candidate{
+			Index:        c.Index,
+			FinishReason: genai.FinishReason(enumValue(vertexFinishReasons, c.FinishReason)),
+		}
+		if c.Content != nil {
+			candidate.Content = &genai.Content{Role: c.Content.Role}
+			for _, part := range c.Content.Parts {
+				switch {
+				case part.FunctionCall != nil:
+					candidate.Content.Parts = append(candidate.Content.Parts, genai.FunctionCall{Name: part.FunctionCall.Name, Args: part.FunctionCall.Args})
+				case part.Text != "":
+					candidate.Content.Parts = append(candidate.Content.Parts, genai.Text(part.Text))
+				}
+			}
+		}
+		if candidate.FinishReason == genai.FinishReasonSafety || candidate.FinishReason == genai.FinishReasonRecitation {
+			return nil, &genai.BlockedError{Candidate: candidate}
+		}
+		result.Candidates = append(result.Candidates, candidate)
+	}
+	return result, nil
+}
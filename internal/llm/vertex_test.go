@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestVertexBackend creates a Vertex AI backend whose regions are served by handler under /<location>
+func newTestVertexBackend(t *testing.T, handler http.HandlerFunc, locations ...string) *vertexBackend {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &vertexBackend{
+		project:      "acme-prod",
+		locations:    locations,
+		quotaProject: "acme-billing",
+		httpClient:   server.Client(),
+		logger:       NewGeminiClient("test-api-key").logger,
+		baseURL:      func(location string) string { return server.URL + "/" + location },
+	}
+}
+
+// TestGeminiConfigFromEnv tests selecting the Vertex AI backend and its regions
+func TestGeminiConfigFromEnv(t *testing.T) {
+	t.Setenv("GEMINI_BACKEND", "Vertex")
+	t.Setenv("VERTEX_PROJECT", "")
+	t.Setenv("GCP_PROJECT_ID", "acme-prod")
+	t.Setenv("VERTEX_LOCATION", "europe-west4, europe-west1")
+	t.Setenv("GEMINI_QUOTA_PROJECT", "acme-billing")
+
+	cfg := GeminiConfigFromEnv()
+	assert.Equal(t, BackendVertex, cfg.Backend)
+	assert.Equal(t, "acme-prod", cfg.Project)
+	assert.Equal(t, []string{"europe-west4", "europe-west1"}, cfg.Locations)
+	assert.Equal(t, "acme-billing", cfg.QuotaProject)
+
+	t.Setenv("GEMINI_BACKEND", "bogus")
+	assert.Equal(t, BackendAIStudio, GeminiConfigFromEnv().Backend)
+}
+
+// TestVertexGenerateContent tests calling a model through Vertex AI with the client's request path
+func TestVertexGenerateContent(t *testing.T) {
+	var body vertexRequest
+	vertex := newTestVertexBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/europe-west4/v1/projects/acme-prod/locations/europe-west4/publishers/google/models/gemini-2.5-flash:generateContent", r.URL.Path)
+		assert.Equal(t, "acme-billing", r.Header.Get("X-Goog-User-Project"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"{\"big_picture\":\"Caches\"}"}]},"finishReason":"STOP"}],
+			"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":34,"totalTokenCount":46}}`))
+	}, "europe-west4")
+
+	client := &GeminiClient{Models: &ModelsWrapper{vertex: vertex}, model: "gemini-2.5-flash", logger: vertex.logger}
+	response, err := client.executeRequestWithConfig(context.Background(), "Explain caches", &genai.GenerationConfig{
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   &genai.Schema{Type: genai.TypeObject, Properties: map[string]*genai.Schema{"big_picture": {Type: genai.TypeString}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"big_picture":"Caches"}`, response.Candidates[0].Content.Parts[0].Text)
+	assert.Equal(t, 46, response.UsageMetadata.TotalTokenCount)
+
+	assert.Equal(t, "Explain caches", body.Contents[0].Parts[0].Text)
+	assert.Equal(t, "OBJECT", body.GenerationConfig.ResponseSchema.Type)
+	assert.Equal(t, "STRING", body.GenerationConfig.ResponseSchema.Properties["big_picture"].Type)
+}
+
+// TestVertexToolCall tests function calling through Vertex AI
+func TestVertexToolCall(t *testing.T) {
+	vertex := newTestVertexBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		var body vertexRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "ANY", body.ToolConfig.FunctionCallingConfig.Mode)
+		assert.Equal(t, "submit", body.Tools[0].FunctionDeclarations[0].Name)
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"functionCall":{"name":"submit","args":{"score":0.9}}}]},"finishReason":"STOP"}]}`))
+	}, "us-central1")
+
+	client := &GeminiClient{Models: &ModelsWrapper{vertex: vertex}, model: "gemini-2.5-flash", logger: vertex.logger}
+	args, err := client.executeToolCall(context.Background(), "Score it", &genai.Tool{
+		FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "submit", Parameters: &genai.Schema{Type: genai.TypeObject}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, args["score"])
+}
+
+// TestVertexRegionalFailover tests that an overloaded region hands the request to the next region
+func TestVertexRegionalFailover(t *testing.T) {
+	var regions []string
+	vertex := newTestVertexBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		region := strings.Split(r.URL.Path, "/")[1]
+		regions = append(regions, region)
+		if region == "us-central1" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"code":429,"message":"Resource exhausted"}}`))
+			return
+		}
+		if region == "us-east4" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":400,"message":"Invalid argument"}}`))
+			return
+		}
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"ok"}]}}]}`))
+	}, "us-central1", "us-east4", "us-west1")
+
+	_, err := vertex.generateContent(context.Background(), "gemini-2.5-flash", genai.Text("hi"), nil, nil, nil)
+	// A bad request fails the same way everywhere, so us-west1 is never tried
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid argument")
+	assert.Equal(t, []string{"us-central1", "us-east4"}, regions)
+
+	vertex.locations = []string{"us-central1", "us-west1"}
+	result, err := vertex.generateContent(context.Background(), "gemini-2.5-flash", genai.Text("hi"), nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, genai.Text("ok"), result.Candidates[0].Content.Parts[0])
+}
+
+// TestVertexBlockedResponse tests that blocked responses become fallback-eligible BlockedErrors
+func TestVertexBlockedResponse(t *testing.T) {
+	for _, body := range []string{
+		`{"promptFeedback":{"blockReason":"SAFETY"}}`,
+		`{"candidates":[{"content":{"parts":[]},"finishReason":"SAFETY"}]}`,
+	} {
+		vertex := newTestVertexBackend(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}, "us-central1")
+		_, err := vertex.generateContent(context.Background(), "gemini-2.5-flash", genai.Text("hi"), nil, nil, nil)
+		var blocked *genai.BlockedError
+		assert.True(t, errors.As(err, &blocked), body)
+		assert.True(t, isFallbackError(err), body)
+	}
+}
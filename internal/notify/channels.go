@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig configures outgoing email
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// EmailChannel sends notifications by email over SMTP
+type EmailChannel struct {
+	config   SMTPConfig
+	from     *mail.Address
+	to       *mail.Address
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailChannel creates an email channel; it fails if SMTP is not configured or either
+// address does not parse
+func NewEmailChannel(config SMTPConfig, to string) (*EmailChannel, error) {
+	if config.Host == "" || config.From == "" {
+		return nil, fmt.Errorf("%w: SMTP is not configured", ErrInvalidChannel)
+	}
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid sender address: %v", ErrInvalidChannel, err)
+	}
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid email address: %v", ErrInvalidChannel, err)
+	}
+	return &EmailChannel{config: config, from: from, to: recipient, sendMail: smtp.SendMail}, nil
+}
+
+// Type returns the channel type
+func (c *EmailChannel) Type() string {
+	return ChannelEmail
+}
+
+// Send emails the event to the recipient. net/smtp does not take a context,
+// so ctx is only checked before sending.
+func (c *EmailChannel) Send(ctx context.Context, event Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	subject, body := FormatMessage(event)
+	// Titles come from users, so line breaks must not end the header and start new ones
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+
+	var msg strings.Builder
+	msg.WriteString("From: " + c.from.String() + "\r\n")
+	msg.WriteString("To: " + c.to.String() + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if c.config.Username != "" {
+		auth = smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+	}
+	addr := c.config.Host + ":" + strconv.Itoa(c.config.Port)
+	if err := c.sendMail(addr, auth, c.from.Address, []string{c.to.Address}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// WebhookChannel posts notifications to a chat incoming webhook.
+// Slack and Google Chat both accept a JSON body with a text field.
+type WebhookChannel struct {
+	channelType string
+	url         string
+	httpClient  *http.Client
+}
+
+// NewSlackChannel creates a channel posting to a Slack incoming webhook
+func NewSlackChannel(webhookURL string) *WebhookChannel {
+	return &WebhookChannel{channelType: ChannelSlack, url: webhookURL, httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// NewGoogleChatChannel creates a channel posting to a Google Chat space webhook
+func NewGoogleChatChannel(webhookURL string) *WebhookChannel {
+	return &WebhookChannel{channelType: ChannelGoogleChat, url: webhookURL, httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Type returns the channel type
+func (c *WebhookChannel) Type() string {
+	return c.channelType
+}
+
+// Send posts the event to the webhook
+func (c *WebhookChannel) Send(ctx context.Context, event Event) error {
+	subject, body := FormatMessage(event)
+	text := subject + "\n" + body
+	if c.channelType == ChannelSlack {
+		text = "*" + subject + "*\n" + body
+	}
+
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
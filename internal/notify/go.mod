@@ -0,0 +1,10 @@
+module github.com/InnoFusionTech/ExplainIQ/internal/notify
+
+go 1.24.4
+
+toolchain go1.24.10
+
+require (
+	github.com/sirupsen/logrus v1.9.3
+	github.com/stretchr/testify v1.11.1
+)
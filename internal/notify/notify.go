@@ -0,0 +1,387 @@
+// Package notify delivers session completion and failure notifications to
+// users and organizations over email, Slack and Google Chat.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event types
+const (
+	EventSessionCompleted = "session.completed"
+	EventSessionFailed    = "session.failed"
+)
+
+// Channel types
+const (
+	ChannelEmail      = "email"
+	ChannelSlack      = "slack"
+	ChannelGoogleChat = "google_chat"
+)
+
+// webhookHosts are the only hosts each webhook channel may post to, so preferences
+// cannot send lesson details to an arbitrary server
+var webhookHosts = map[string]string{
+	ChannelSlack:      "hooks.slack.com",
+	ChannelGoogleChat: "chat.googleapis.com",
+}
+
+// Scope is the owner of a set of notification preferences
+type Scope string
+
+// Preference scopes
+const (
+	ScopeUser Scope = "user"
+	ScopeOrg  Scope = "org"
+)
+
+// storageKey is the storage key holding all notification preferences
+const storageKey = "notify_preferences"
+
+// ErrInvalidChannel is returned for channel configurations that cannot be delivered to
+var ErrInvalidChannel = errors.New("invalid notification channel")
+
+// Event describes a finished session
+type Event struct {
+	Type      string        `json:"type"` // EventSessionCompleted or EventSessionFailed
+	SessionID string        `json:"session_id"`
+	UserID    string        `json:"user_id,omitempty"`
+	OrgID     string        `json:"org_id,omitempty"`
+	Title     string        `json:"title"`
+	Summary   string        `json:"summary,omitempty"` // TL;DR of a completed lesson
+	Duration  time.Duration `json:"duration"`
+	CostUSD   *float64      `json:"cost_usd,omitempty"` // Nil when cost tracking is unavailable
+	Error     string        `json:"error,omitempty"`
+	Link      string        `json:"link,omitempty"` // Deep link to the result
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// Channel delivers events to one destination
+type Channel interface {
+	Type() string
+	Send(ctx context.Context, event Event) error
+}
+
+// ChannelConfig configures a notification channel
+type ChannelConfig struct {
+	Type   string   `json:"type"`             // email, slack or google_chat
+	Target string   `json:"target"`           // Email address or webhook URL
+	Events []string `json:"events,omitempty"` // Event types to deliver; empty means all
+}
+
+// Validate checks that the channel type is known and the target fits it
+func (c ChannelConfig) Validate() error {
+	switch c.Type {
+	case ChannelEmail:
+		if _, err := mail.ParseAddress(c.Target); err != nil {
+			return fmt.Errorf("%w: invalid email address %q", ErrInvalidChannel, c.Target)
+		}
+	case ChannelSlack, ChannelGoogleChat:
+		u, err := url.Parse(c.Target)
+		if err != nil || u.Scheme != "https" || u.Host != webhookHosts[c.Type] || u.User != nil {
+			return fmt.Errorf("%w: %s webhook must be an https URL on %s", ErrInvalidChannel, c.Type, webhookHosts[c.Type])
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidChannel, c.Type)
+	}
+
+	for _, event := range c.Events {
+		if event != EventSessionCompleted && event != EventSessionFailed {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidChannel, event)
+		}
+	}
+	return nil
+}
+
+// wants reports whether the channel subscribes to an event type
+func (c ChannelConfig) wants(eventType string) bool {
+	if len(c.Events) == 0 {
+		return true
+	}
+	for _, event := range c.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Preferences are the channels configured for a user or organization
+type Preferences struct {
+	Channels  []ChannelConfig `json:"channels"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Store persists notification preferences
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// Config configures channel delivery
+type Config struct {
+	SMTP    SMTPConfig
+	BaseURL string // Public URL of the web app, used to build result deep links
+}
+
+// ConfigFromEnv reads the SMTP settings from SMTP_HOST, SMTP_PORT, SMTP_USERNAME,
+// SMTP_PASSWORD and NOTIFY_EMAIL_FROM, and the deep link base from APP_BASE_URL
+func ConfigFromEnv() Config {
+	config := Config{
+		SMTP: SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     587,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("NOTIFY_EMAIL_FROM"),
+		},
+		BaseURL: strings.TrimRight(os.Getenv("APP_BASE_URL"), "/"),
+	}
+	if v := os.Getenv("SMTP_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err == nil && port > 0 {
+			config.SMTP.Port = port
+		} else {
+			logrus.WithField("value", v).Warn("Invalid SMTP_PORT, using default")
+		}
+	}
+	return config
+}
+
+// Service stores notification preferences and delivers events to the
+// channels configured for the event's user and organization
+type Service struct {
+	store      Store
+	config     Config
+	mu         sync.RWMutex
+	prefs      map[string]Preferences // Keyed by "<scope>:<id>"
+	newChannel func(ChannelConfig) (Channel, error)
+	logger     *logrus.Logger
+}
+
+// NewService creates a notification service; a nil store keeps preferences in memory only
+func NewService(store Store, config Config) *Service {
+	s := &Service{
+		store:  store,
+		config: config,
+		prefs:  make(map[string]Preferences),
+		logger: logrus.New(),
+	}
+	s.newChannel = s.channelFor
+	return s
+}
+
+// prefsKey returns the preferences map key for a scope and owner ID
+func prefsKey(scope Scope, id string) string {
+	return string(scope) + ":" + id
+}
+
+// Load reads preferences from storage
+func (s *Service) Load(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+
+	data, err := s.store.Get(ctx, storageKey)
+	if err != nil {
+		return fmt.Errorf("failed to load notification preferences: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	prefs := make(map[string]Preferences)
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		return fmt.Errorf("failed to decode notification preferences: %w", err)
+	}
+
+	s.mu.Lock()
+	s.prefs = prefs
+	s.mu.Unlock()
+	return nil
+}
+
+// Preferences returns the preferences for a user or organization
+func (s *Service) Preferences(scope Scope, id string) (Preferences, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefs, ok := s.prefs[prefsKey(scope, id)]
+	return prefs, ok
+}
+
+// SetPreferences validates and stores the preferences for a user or organization.
+// Empty preferences remove the entry.
+func (s *Service) SetPreferences(ctx context.Context, scope Scope, id string, prefs Preferences) (Preferences, error) {
+	if scope != ScopeUser && scope != ScopeOrg {
+		return Preferences{}, fmt.Errorf("unknown preference scope %q", scope)
+	}
+	if id == "" {
+		return Preferences{}, fmt.Errorf("%s ID is required", scope)
+	}
+	for _, channel := range prefs.Channels {
+		if err := channel.Validate(); err != nil {
+			return Preferences{}, err
+		}
+	}
+	prefs.UpdatedAt = time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.prefs[prefsKey(scope, id)]
+	if len(prefs.Channels) == 0 {
+		delete(s.prefs, prefsKey(scope, id))
+	} else {
+		s.prefs[prefsKey(scope, id)] = prefs
+	}
+
+	if err := s.saveLocked(ctx); err != nil {
+		if existed {
+			s.prefs[prefsKey(scope, id)] = previous
+		} else {
+			delete(s.prefs, prefsKey(scope, id))
+		}
+		return Preferences{}, err
+	}
+	return prefs, nil
+}
+
+// saveLocked writes all preferences to storage; s.mu must be held
+func (s *Service) saveLocked(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+	data, err := json.Marshal(s.prefs)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification preferences: %w", err)
+	}
+	if err := s.store.Set(ctx, storageKey, data); err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+// ResultLink returns the deep link to a session's result, or "" without a base URL
+func (s *Service) ResultLink(sessionID string) string {
+	if s.config.BaseURL == "" {
+		return ""
+	}
+	return s.config.BaseURL + "/sessions/" + url.PathEscape(sessionID)
+}
+
+// channelsFor returns the channels subscribed to an event from the user's and
+// organization's preferences, without duplicates
+func (s *Service) channelsFor(event Event) []ChannelConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var configs []ChannelConfig
+	seen := make(map[string]bool)
+	for _, key := range []string{prefsKey(ScopeUser, event.UserID), prefsKey(ScopeOrg, event.OrgID)} {
+		if strings.HasSuffix(key, ":") {
+			continue
+		}
+		for _, channel := range s.prefs[key].Channels {
+			id := channel.Type + "|" + channel.Target
+			if channel.wants(event.Type) && !seen[id] {
+				seen[id] = true
+				configs = append(configs, channel)
+			}
+		}
+	}
+	return configs
+}
+
+// channelFor creates the channel for a configuration
+func (s *Service) channelFor(config ChannelConfig) (Channel, error) {
+	switch config.Type {
+	case ChannelEmail:
+		return NewEmailChannel(s.config.SMTP, config.Target)
+	case ChannelSlack:
+		return NewSlackChannel(config.Target), nil
+	case ChannelGoogleChat:
+		return NewGoogleChatChannel(config.Target), nil
+	}
+	return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidChannel, config.Type)
+}
+
+// Notify sends an event to every subscribed channel. Delivery continues past
+// failing channels; their errors are joined in the result.
+func (s *Service) Notify(ctx context.Context, event Event) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if event.Link == "" {
+		event.Link = s.ResultLink(event.SessionID)
+	}
+
+	var errs []error
+	for _, config := range s.channelsFor(event) {
+		channel, err := s.newChannel(config)
+		if err == nil {
+			err = channel.Send(ctx, event)
+		}
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"session_id": event.SessionID,
+				"channel":    config.Type,
+				"error":      err,
+			}).Warn("Failed to deliver notification")
+			errs = append(errs, fmt.Errorf("%s: %w", config.Type, err))
+			continue
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"session_id": event.SessionID,
+			"channel":    config.Type,
+			"event":      event.Type,
+		}).Info("Notification delivered")
+	}
+	return errors.Join(errs...)
+}
+
+// FormatMessage renders an event as a subject line and a plain text body
+func FormatMessage(event Event) (subject, body string) {
+	title := event.Title
+	if title == "" {
+		title = "Your lesson"
+	}
+
+	var b strings.Builder
+	if event.Type == EventSessionFailed {
+		subject = fmt.Sprintf("Lesson failed: %s", title)
+		b.WriteString(fmt.Sprintf("Generating \"%s\" failed.\n", title))
+		if event.Error != "" {
+			b.WriteString(fmt.Sprintf("Error: %s\n", event.Error))
+		}
+	} else {
+		subject = fmt.Sprintf("Lesson ready: %s", title)
+		b.WriteString(fmt.Sprintf("\"%s\" is ready.\n", title))
+		if event.Summary != "" {
+			b.WriteString(event.Summary + "\n")
+		}
+	}
+
+	if event.Duration > 0 {
+		b.WriteString(fmt.Sprintf("Duration: %s\n", event.Duration.Round(time.Second)))
+	}
+	if event.CostUSD != nil {
+		b.WriteString(fmt.Sprintf("Cost: $%.4f\n", *event.CostUSD))
+	}
+	if event.Link != "" {
+		b.WriteString(fmt.Sprintf("View the result: %s\n", event.Link))
+	}
+	return subject, b.String()
+}
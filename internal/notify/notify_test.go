@@ -0,0 +1,207 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory Store
+type memoryStore struct {
+	data map[string][]byte
+}
+
+// Get implements Store
+func (m *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return m.data[key], nil
+}
+
+// Set implements Store
+func (m *memoryStore) Set(ctx context.Context, key string, value []byte) error {
+	m.data[key] = value
+	return nil
+}
+
+// recordingChannel records the events it was sent
+type recordingChannel struct {
+	config ChannelConfig
+	sent   *[]string
+	err    error
+}
+
+// Type implements Channel
+func (c *recordingChannel) Type() string { return c.config.Type }
+
+// Send implements Channel
+func (c *recordingChannel) Send(ctx context.Context, event Event) error {
+	*c.sent = append(*c.sent, c.config.Type+"|"+c.config.Target+"|"+event.Link)
+	return c.err
+}
+
+// TestChannelConfigValidate tests channel type, target and event validation
+func TestChannelConfigValidate(t *testing.T) {
+	assert.NoError(t, ChannelConfig{Type: ChannelEmail, Target: "ada@example.com"}.Validate())
+	assert.NoError(t, ChannelConfig{Type: ChannelSlack, Target: "https://hooks.slack.com/services/x"}.Validate())
+	assert.NoError(t, ChannelConfig{Type: ChannelGoogleChat, Target: "https://chat.googleapis.com/v1/spaces/x"}.Validate())
+
+	for _, config := range []ChannelConfig{
+		{Type: ChannelEmail, Target: "not an address"},
+		{Type: ChannelGoogleChat, Target: "http://chat.googleapis.com/v1/spaces/x"},
+		{Type: ChannelSlack, Target: "https://attacker.example.com/services/x"},
+		{Type: ChannelSlack, Target: "https://hooks.slack.com.attacker.example.com/x"},
+		{Type: ChannelSlack, Target: "https://chat.googleapis.com/v1/spaces/x"},
+		{Type: ChannelGoogleChat, Target: "https://chat.googleapis.com:8443/v1/spaces/x"},
+		{Type: "pager", Target: "x"},
+		{Type: ChannelSlack, Target: "https://hooks.slack.com/x", Events: []string{"session.started"}},
+	} {
+		assert.ErrorIs(t, config.Validate(), ErrInvalidChannel, config)
+	}
+}
+
+// TestSetPreferencesPersists tests that preferences are validated, saved and reloaded
+func TestSetPreferencesPersists(t *testing.T) {
+	store := &memoryStore{data: make(map[string][]byte)}
+	service := NewService(store, Config{})
+
+	_, err := service.SetPreferences(context.Background(), ScopeUser, "u1", Preferences{
+		Channels: []ChannelConfig{{Type: ChannelEmail, Target: "ada@example.com"}},
+	})
+	require.NoError(t, err)
+
+	_, err = service.SetPreferences(context.Background(), ScopeUser, "u1", Preferences{
+		Channels: []ChannelConfig{{Type: ChannelEmail, Target: "bad"}},
+	})
+	assert.Error(t, err)
+
+	reloaded := NewService(store, Config{})
+	require.NoError(t, reloaded.Load(context.Background()))
+	prefs, ok := reloaded.Preferences(ScopeUser, "u1")
+	require.True(t, ok)
+	assert.Equal(t, "ada@example.com", prefs.Channels[0].Target)
+
+	_, err = reloaded.SetPreferences(context.Background(), ScopeUser, "u1", Preferences{})
+	require.NoError(t, err)
+	_, ok = reloaded.Preferences(ScopeUser, "u1")
+	assert.False(t, ok, "empty preferences remove the entry")
+}
+
+// TestNotifyUserAndOrgChannels tests delivery to user and organization channels with event filtering
+func TestNotifyUserAndOrgChannels(t *testing.T) {
+	service := NewService(nil, Config{BaseURL: "https://app.example.com"})
+	var sent []string
+	service.newChannel = func(config ChannelConfig) (Channel, error) {
+		var err error
+		if config.Type == ChannelGoogleChat {
+			err = errors.New("webhook down")
+		}
+		return &recordingChannel{config: config, sent: &sent, err: err}, nil
+	}
+
+	ctx := context.Background()
+	slack := ChannelConfig{Type: ChannelSlack, Target: "https://hooks.slack.com/team"}
+	_, err := service.SetPreferences(ctx, ScopeUser, "u1", Preferences{Channels: []ChannelConfig{
+		{Type: ChannelEmail, Target: "ada@example.com", Events: []string{EventSessionFailed}},
+		slack,
+	}})
+	require.NoError(t, err)
+	_, err = service.SetPreferences(ctx, ScopeOrg, "org-1", Preferences{Channels: []ChannelConfig{
+		slack,
+		{Type: ChannelGoogleChat, Target: "https://chat.googleapis.com/v1/spaces/x"},
+	}})
+	require.NoError(t, err)
+
+	err = service.Notify(ctx, Event{Type: EventSessionCompleted, SessionID: "s1", UserID: "u1", OrgID: "org-1"})
+	assert.ErrorContains(t, err, "webhook down")
+	assert.Equal(t, []string{
+		"slack|https://hooks.slack.com/team|https://app.example.com/sessions/s1",
+		"google_chat|https://chat.googleapis.com/v1/spaces/x|https://app.example.com/sessions/s1",
+	}, sent, "email is skipped for completions and the shared Slack webhook is notified once")
+}
+
+// TestFormatMessage tests the completion and failure message text
+func TestFormatMessage(t *testing.T) {
+	cost := 0.0123
+	subject, body := FormatMessage(Event{
+		Type:     EventSessionCompleted,
+		Title:    "Raft consensus",
+		Summary:  "Raft keeps replicas in agreement by electing a leader.",
+		Duration: 42*time.Second + 300*time.Millisecond,
+		CostUSD:  &cost,
+		Link:     "https://app.example.com/sessions/s1",
+	})
+	assert.Equal(t, "Lesson ready: Raft consensus", subject)
+	assert.Contains(t, body, "is ready.\nRaft keeps replicas in agreement by electing a leader.\n")
+	assert.Contains(t, body, "Duration: 42s")
+	assert.Contains(t, body, "Cost: $0.0123")
+	assert.Contains(t, body, "https://app.example.com/sessions/s1")
+
+	subject, body = FormatMessage(Event{Type: EventSessionFailed, Title: "Raft", Error: "critic timed out"})
+	assert.Equal(t, "Lesson failed: Raft", subject)
+	assert.Contains(t, body, "Error: critic timed out")
+	assert.NotContains(t, body, "Cost")
+}
+
+// TestWebhookChannelSend tests posting to chat webhooks
+func TestWebhookChannelSend(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	require.NoError(t, NewSlackChannel(server.URL).Send(context.Background(), Event{Type: EventSessionCompleted, Title: "Raft"}))
+	assert.Contains(t, payload["text"], "*Lesson ready: Raft*")
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no_service", http.StatusNotFound)
+	}))
+	defer failing.Close()
+	assert.ErrorContains(t, NewGoogleChatChannel(failing.URL).Send(context.Background(), Event{}), "status 404")
+}
+
+// TestEmailChannelSend tests composing and sending email
+func TestEmailChannelSend(t *testing.T) {
+	_, err := NewEmailChannel(SMTPConfig{}, "ada@example.com")
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+
+	channel, err := NewEmailChannel(SMTPConfig{Host: "smtp.example.com", Port: 587, From: "lessons@example.com"}, "ada@example.com")
+	require.NoError(t, err)
+
+	var addr string
+	var msg []byte
+	channel.sendMail = func(a string, auth smtp.Auth, from string, to []string, m []byte) error {
+		addr, msg = a, m
+		return nil
+	}
+	require.NoError(t, channel.Send(context.Background(), Event{Type: EventSessionCompleted, Title: "Raft"}))
+	assert.Equal(t, "smtp.example.com:587", addr)
+	assert.Contains(t, string(msg), "Subject: Lesson ready: Raft\r\n")
+
+	// Titles cannot inject headers, and the envelope carries bare addresses
+	channel, err = NewEmailChannel(SMTPConfig{Host: "smtp.example.com", Port: 587, From: "Lessons <lessons@example.com>"}, "Ada Lovelace <ada@example.com>")
+	require.NoError(t, err)
+	var from string
+	var to []string
+	channel.sendMail = func(a string, auth smtp.Auth, f string, recipients []string, m []byte) error {
+		from, to, msg = f, recipients, m
+		return nil
+	}
+	require.NoError(t, channel.Send(context.Background(), Event{Type: EventSessionCompleted, Title: "Raft\r\nBcc: victim@example.com"}))
+	assert.Equal(t, "lessons@example.com", from)
+	assert.Equal(t, []string{"ada@example.com"}, to)
+	headers := strings.SplitN(string(msg), "\r\n\r\n", 2)[0]
+	assert.NotContains(t, headers, "\r\nBcc:")
+	assert.Contains(t, headers, "To: \"Ada Lovelace\" <ada@example.com>\r\n")
+
+	_, err = NewEmailChannel(SMTPConfig{Host: "smtp.example.com", From: "lessons@example.com"}, "ada@example.com\r\nBcc: victim@example.com")
+	assert.ErrorIs(t, err, ErrInvalidChannel)
+}
@@ -63,6 +63,21 @@ func (l *Limiter) Allow(ip string) bool {
 	return allowed
 }
 
+// AllowN checks if n requests' worth of tokens are available for the given IP and consumes them
+func (l *Limiter) AllowN(ip string, n int) bool {
+	limiter := l.GetLimiter(ip)
+	allowed := limiter.AllowN(time.Now(), n)
+
+	l.logger.WithFields(logrus.Fields{
+		"ip":      ip,
+		"n":       n,
+		"allowed": allowed,
+		"tokens":  limiter.Tokens(),
+	}).Debug("Rate limit check")
+
+	return allowed
+}
+
 // Wait waits for the limiter to allow the request
 func (l *Limiter) Wait(ctx context.Context, ip string) error {
 	limiter := l.GetLimiter(ip)
@@ -0,0 +1,75 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/elastic"
+)
+
+// ElasticsearchProvider serves retrieval from an Elasticsearch cluster
+type ElasticsearchProvider struct {
+	client *elastic.Client
+}
+
+// NewElasticsearchProvider creates a provider over an Elasticsearch client
+func NewElasticsearchProvider(client *elastic.Client) *ElasticsearchProvider {
+	return &ElasticsearchProvider{client: client}
+}
+
+// Name returns the provider name
+func (p *ElasticsearchProvider) Name() string {
+	return ProviderElasticsearch
+}
+
+// EnsureIndex bootstraps index as an alias over lifecycle-managed backing indices with the
+// hybrid search mapping, if nothing answers to the name yet
+func (p *ElasticsearchProvider) EnsureIndex(ctx context.Context, index string, dimensions int) error {
+	return p.client.EnsureIndex(ctx, elastic.NewIndexSpec(index, dimensions))
+}
+
+// IndexDimensions returns the embedding dims in the index mapping, or 0 if there is no index
+func (p *ElasticsearchProvider) IndexDimensions(ctx context.Context, index string) (int, error) {
+	return p.client.IndexDimensions(ctx, index)
+}
+
+// EnsureTenantAlias creates or refreshes a filtered alias over index's backing indices that
+// shows one organization's documents. Refresh it after a rollover so new indices are covered.
+func (p *ElasticsearchProvider) EnsureTenantAlias(ctx context.Context, index, alias, orgID string) error {
+	return p.client.EnsureFilteredAlias(ctx, index, alias, "metadata."+TenantMetadataKey, orgID)
+}
+
+// Index upserts documents
+func (p *ElasticsearchProvider) Index(ctx context.Context, index string, docs []Doc) error {
+	return p.client.UpsertDocs(ctx, index, docs)
+}
+
+// HybridSearch runs a combined BM25 and kNN query. Elasticsearch returns one blended
+// score, so it is split evenly between the BM25 and vector components.
+func (p *ElasticsearchProvider) HybridSearch(ctx context.Context, index, query string, embedding []float32, size int) ([]Hit, error) {
+	result, err := p.client.HybridSearch(ctx, index, query, embedding, size)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch query failed: %w", err)
+	}
+
+	hits := make([]Hit, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		hits = append(hits, Hit{
+			Doc:         elastic.DocFromSource(hit.Source),
+			Score:       hit.Score,
+			BM25Score:   hit.Score * 0.5,
+			VectorScore: hit.Score * 0.5,
+		})
+	}
+	return hits, nil
+}
+
+// Delete removes documents by ID
+func (p *ElasticsearchProvider) Delete(ctx context.Context, index string, ids []string) error {
+	return p.client.DeleteDocs(ctx, index, ids)
+}
+
+// Health checks the cluster
+func (p *ElasticsearchProvider) Health(ctx context.Context) error {
+	return p.client.Health(ctx)
+}
@@ -0,0 +1,21 @@
+module github.com/InnoFusionTech/ExplainIQ/internal/retrieval
+
+go 1.24.4
+
+toolchain go1.24.10
+
+require (
+	github.com/InnoFusionTech/ExplainIQ/internal/elastic v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/llm v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/tokens v0.0.0 // indirect
+	github.com/google/uuid v1.6.0
+	github.com/lib/pq v1.10.9
+	github.com/sirupsen/logrus v1.9.3
+	github.com/stretchr/testify v1.11.1
+)
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/elastic => ../elastic
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/llm => ../llm
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/tokens => ../tokens
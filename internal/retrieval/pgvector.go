@@ -0,0 +1,260 @@
+package retrieval
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// pgIdentifierPattern restricts index names, which become table names, to safe identifiers
+var pgIdentifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// PGVectorProvider serves retrieval from Postgres with the pgvector extension.
+// Each index is a table holding the documents, a generated tsvector for keyword
+// ranking and a vector column for cosine similarity.
+type PGVectorProvider struct {
+	db     *sql.DB
+	logger *logrus.Logger
+}
+
+// NewPGVectorProvider creates a provider over an open database
+func NewPGVectorProvider(db *sql.DB) *PGVectorProvider {
+	return &PGVectorProvider{db: db, logger: logrus.New()}
+}
+
+// OpenPGVectorProvider connects to Postgres and verifies the connection
+func OpenPGVectorProvider(ctx context.Context, dsn string) (*PGVectorProvider, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Postgres: %w", err)
+	}
+	provider := NewPGVectorProvider(db)
+	if err := provider.Health(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to Postgres: %w", err)
+	}
+	return provider, nil
+}
+
+// Name returns the provider name
+func (p *PGVectorProvider) Name() string {
+	return ProviderPGVector
+}
+
+// tableName validates an index name and returns it quoted as a table name
+func tableName(index string) (string, error) {
+	if !pgIdentifierPattern.MatchString(index) {
+		return "", fmt.Errorf("invalid pgvector index name %q", index)
+	}
+	return pq.QuoteIdentifier(index), nil
+}
+
+// EnsureIndex creates the extension, table and indexes if they do not exist
+func (p *PGVectorProvider) EnsureIndex(ctx context.Context, index string, dimensions int) error {
+	table, err := tableName(index)
+	if err != nil {
+		return err
+	}
+	if dimensions <= 0 {
+		dimensions = DefaultDimensions
+	}
+
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			topic TEXT NOT NULL DEFAULT '',
+			section TEXT NOT NULL DEFAULT '',
+			body TEXT NOT NULL DEFAULT '',
+			metadata JSONB NOT NULL DEFAULT '{}',
+			embedding vector(%d),
+			created_at TEXT NOT NULL DEFAULT '',
+			updated_at TEXT NOT NULL DEFAULT '',
+			search_text tsvector GENERATED ALWAYS AS (
+				setweight(to_tsvector('english', topic), 'A') ||
+				setweight(to_tsvector('english', section), 'B') ||
+				to_tsvector('english', body)
+			) STORED
+		)`, table, dimensions),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s USING GIN (search_text)`, pq.QuoteIdentifier(index+"_search_idx"), table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s USING hnsw (embedding vector_cosine_ops)`, pq.QuoteIdentifier(index+"_embedding_idx"), table),
+	}
+	for _, statement := range statements {
+		if _, err := p.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create pgvector index %s: %w", index, err)
+		}
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"index":      index,
+		"dimensions": dimensions,
+	}).Info("pgvector index ready")
+	return nil
+}
+
+// IndexDimensions returns the size of the table's embedding column, or 0 if there is no table.
+// pgvector stores a vector column's dimensions as its type modifier.
+func (p *PGVectorProvider) IndexDimensions(ctx context.Context, index string) (int, error) {
+	if _, err := tableName(index); err != nil {
+		return 0, err
+	}
+	var dimensions int
+	err := p.db.QueryRowContext(ctx, `SELECT atttypmod FROM pg_attribute
+		WHERE attrelid = to_regclass($1) AND attname = 'embedding' AND NOT attisdropped`, index).Scan(&dimensions)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read pgvector dimensions of %s: %w", index, err)
+	}
+	if dimensions < 0 {
+		return 0, nil
+	}
+	return dimensions, nil
+}
+
+// Index upserts documents in one transaction
+func (p *PGVectorProvider) Index(ctx context.Context, index string, docs []Doc) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	table, err := tableName(index)
+	if err != nil {
+		return err
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	statement := fmt.Sprintf(`INSERT INTO %s (id, topic, section, body, metadata, embedding, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6::vector, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			topic = EXCLUDED.topic, section = EXCLUDED.section, body = EXCLUDED.body,
+			metadata = EXCLUDED.metadata, embedding = EXCLUDED.embedding, updated_at = EXCLUDED.updated_at`, table)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, doc := range docs {
+		metadata, err := json.Marshal(doc.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata for %s: %w", doc.ID, err)
+		}
+		createdAt, updatedAt := doc.CreatedAt, doc.UpdatedAt
+		if createdAt == "" {
+			createdAt = now
+		}
+		if updatedAt == "" {
+			updatedAt = now
+		}
+		if _, err := tx.ExecContext(ctx, statement, doc.ID, doc.Topic, doc.Section, doc.Text,
+			string(metadata), vectorLiteral(doc.Embedding), createdAt, updatedAt); err != nil {
+			return fmt.Errorf("failed to upsert document %s: %w", doc.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit documents: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"index": index,
+		"count": len(docs),
+	}).Info("Documents upserted successfully")
+	return nil
+}
+
+// HybridSearch ranks documents by full-text rank and cosine similarity, returning both scores
+func (p *PGVectorProvider) HybridSearch(ctx context.Context, index, query string, embedding []float32, size int) ([]Hit, error) {
+	table, err := tableName(index)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		size = 10
+	}
+
+	// Candidates are the union of the nearest vectors and the keyword matches
+	statement := fmt.Sprintf(`SELECT id, topic, section, body, metadata, created_at, updated_at,
+			ts_rank_cd(search_text, plainto_tsquery('english', $1), 32) AS keyword_score,
+			1 - (embedding <=> $2::vector) AS vector_score
+		FROM %s
+		WHERE id IN (
+			(SELECT id FROM %s ORDER BY embedding <=> $2::vector LIMIT $3)
+			UNION
+			(SELECT id FROM %s WHERE search_text @@ plainto_tsquery('english', $1) LIMIT $3)
+		)
+		ORDER BY keyword_score + vector_score DESC
+		LIMIT $3`, table, table, table)
+
+	rows, err := p.db.QueryContext(ctx, statement, query, vectorLiteral(embedding), size)
+	if err != nil {
+		return nil, fmt.Errorf("pgvector query failed: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]Hit, 0, size)
+	for rows.Next() {
+		var doc Doc
+		var metadata []byte
+		var keyword, vector sql.NullFloat64
+		if err := rows.Scan(&doc.ID, &doc.Topic, &doc.Section, &doc.Text, &metadata,
+			&doc.CreatedAt, &doc.UpdatedAt, &keyword, &vector); err != nil {
+			return nil, fmt.Errorf("failed to read pgvector row: %w", err)
+		}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &doc.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to decode metadata for %s: %w", doc.ID, err)
+			}
+		}
+		hits = append(hits, Hit{
+			Doc:         doc,
+			Score:       keyword.Float64 + vector.Float64,
+			BM25Score:   keyword.Float64,
+			VectorScore: vector.Float64,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgvector query failed: %w", err)
+	}
+	return hits, nil
+}
+
+// Delete removes documents by ID
+func (p *PGVectorProvider) Delete(ctx context.Context, index string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	table, err := tableName(index)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ANY($1)`, table), pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+	return nil
+}
+
+// Health pings the database
+func (p *PGVectorProvider) Health(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
+// vectorLiteral formats an embedding in pgvector's text format, e.g. [0.1,0.2]
+func vectorLiteral(embedding []float32) string {
+	parts := make([]string, len(embedding))
+	for i, v := range embedding {
+		parts[i] = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
@@ -0,0 +1,264 @@
+package retrieval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// qdrantIDNamespace derives Qdrant point IDs, which must be UUIDs, from document IDs
+var qdrantIDNamespace = uuid.MustParse("5d3b0a2e-8c4f-4f7e-9a61-1f0c2b7e6d90")
+
+// QdrantProvider serves retrieval from a Qdrant collection over its REST API.
+// Qdrant ranks by vector similarity; the keyword component is scored locally
+// over the returned payloads.
+type QdrantProvider struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	logger     *logrus.Logger
+}
+
+// NewQdrantProvider creates a provider for the Qdrant server at baseURL
+func NewQdrantProvider(baseURL, apiKey string) *QdrantProvider {
+	return &QdrantProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		logger:     logrus.New(),
+	}
+}
+
+// Name returns the provider name
+func (p *QdrantProvider) Name() string {
+	return ProviderQdrant
+}
+
+// qdrantPointID returns the point ID for a document ID
+func qdrantPointID(docID string) string {
+	return uuid.NewSHA1(qdrantIDNamespace, []byte(docID)).String()
+}
+
+// do sends a request and decodes the response's result field into out if non-nil.
+// It returns the HTTP status code.
+func (p *QdrantProvider) do(ctx context.Context, method, path string, body, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode Qdrant request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create Qdrant request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.apiKey != "" {
+		req.Header.Set("api-key", p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("Qdrant request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, fmt.Errorf("Qdrant returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out != nil {
+		envelope := struct {
+			Result interface{} `json:"result"`
+		}{Result: out}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode Qdrant response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// collectionPath returns the API path of a collection
+func collectionPath(index string) string {
+	return "/collections/" + url.PathEscape(index)
+}
+
+// EnsureIndex creates the collection with cosine distance if it does not exist
+func (p *QdrantProvider) EnsureIndex(ctx context.Context, index string, dimensions int) error {
+	if dimensions <= 0 {
+		dimensions = DefaultDimensions
+	}
+
+	status, err := p.do(ctx, http.MethodGet, collectionPath(index), nil, nil)
+	if err == nil {
+		return nil
+	}
+	if status != http.StatusNotFound {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"vectors": map[string]interface{}{
+			"size":     dimensions,
+			"distance": "Cosine",
+		},
+	}
+	if _, err := p.do(ctx, http.MethodPut, collectionPath(index), body, nil); err != nil {
+		return fmt.Errorf("failed to create Qdrant collection %s: %w", index, err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"index":      index,
+		"dimensions": dimensions,
+	}).Info("Qdrant collection created")
+	return nil
+}
+
+// IndexDimensions returns the collection's vector size, or 0 if there is no collection
+func (p *QdrantProvider) IndexDimensions(ctx context.Context, index string) (int, error) {
+	var collection struct {
+		Config struct {
+			Params struct {
+				Vectors struct {
+					Size int `json:"size"`
+				} `json:"vectors"`
+			} `json:"params"`
+		} `json:"config"`
+	}
+	status, err := p.do(ctx, http.MethodGet, collectionPath(index), nil, &collection)
+	if status == http.StatusNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return collection.Config.Params.Vectors.Size, nil
+}
+
+// Index upserts documents as points; the document is stored as the point payload
+func (p *QdrantProvider) Index(ctx context.Context, index string, docs []Doc) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	points := make([]map[string]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		points = append(points, map[string]interface{}{
+			"id":     qdrantPointID(doc.ID),
+			"vector": doc.Embedding,
+			"payload": map[string]interface{}{
+				"id":         doc.ID,
+				"topic":      doc.Topic,
+				"section":    doc.Section,
+				"text":       doc.Text,
+				"metadata":   doc.Metadata,
+				"created_at": doc.CreatedAt,
+				"updated_at": doc.UpdatedAt,
+			},
+		})
+	}
+
+	if _, err := p.do(ctx, http.MethodPut, collectionPath(index)+"/points?wait=true", map[string]interface{}{"points": points}, nil); err != nil {
+		return fmt.Errorf("failed to upsert Qdrant points: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"index": index,
+		"count": len(docs),
+	}).Info("Documents upserted successfully")
+	return nil
+}
+
+// qdrantScoredPoint is a point returned by a Qdrant search
+type qdrantScoredPoint struct {
+	Score   float64 `json:"score"`
+	Payload struct {
+		ID        string            `json:"id"`
+		Topic     string            `json:"topic"`
+		Section   string            `json:"section"`
+		Text      string            `json:"text"`
+		Metadata  map[string]string `json:"metadata"`
+		CreatedAt string            `json:"created_at"`
+		UpdatedAt string            `json:"updated_at"`
+	} `json:"payload"`
+}
+
+// HybridSearch fetches the nearest points and scores query terms against their payloads
+func (p *QdrantProvider) HybridSearch(ctx context.Context, index, query string, embedding []float32, size int) ([]Hit, error) {
+	if size <= 0 {
+		size = 10
+	}
+
+	body := map[string]interface{}{
+		"vector":       embedding,
+		"limit":        size * 2, // Extra candidates so keyword matches can move up
+		"with_payload": true,
+	}
+	var points []qdrantScoredPoint
+	if _, err := p.do(ctx, http.MethodPost, collectionPath(index)+"/points/search", body, &points); err != nil {
+		return nil, fmt.Errorf("qdrant query failed: %w", err)
+	}
+
+	hits := make([]Hit, 0, len(points))
+	for _, point := range points {
+		doc := Doc{
+			ID:        point.Payload.ID,
+			Topic:     point.Payload.Topic,
+			Section:   point.Payload.Section,
+			Text:      point.Payload.Text,
+			Metadata:  point.Payload.Metadata,
+			CreatedAt: point.Payload.CreatedAt,
+			UpdatedAt: point.Payload.UpdatedAt,
+		}
+		keyword := keywordScore(query, doc)
+		hits = append(hits, Hit{
+			Doc:         doc,
+			Score:       keyword + point.Score,
+			BM25Score:   keyword,
+			VectorScore: point.Score,
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > size {
+		hits = hits[:size]
+	}
+	return hits, nil
+}
+
+// Delete removes documents by ID
+func (p *QdrantProvider) Delete(ctx context.Context, index string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pointIDs := make([]string, len(ids))
+	for i, id := range ids {
+		pointIDs[i] = qdrantPointID(id)
+	}
+	if _, err := p.do(ctx, http.MethodPost, collectionPath(index)+"/points/delete?wait=true", map[string]interface{}{"points": pointIDs}, nil); err != nil {
+		return fmt.Errorf("failed to delete Qdrant points: %w", err)
+	}
+	return nil
+}
+
+// Health checks the server
+func (p *QdrantProvider) Health(ctx context.Context) error {
+	_, err := p.do(ctx, http.MethodGet, "/healthz", nil, nil)
+	return err
+}
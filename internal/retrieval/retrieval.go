@@ -0,0 +1,177 @@
+// Package retrieval abstracts the vector store used for context retrieval so the
+// orchestrator can run against Elasticsearch, Postgres/pgvector or Qdrant.
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/elastic"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+)
+
+// Doc is a document stored for retrieval
+type Doc = elastic.Doc
+
+// Hit is a scored search result
+type Hit = elastic.SearchHit
+
+// Provider names accepted in Config
+const (
+	ProviderElasticsearch = "elasticsearch"
+	ProviderPGVector      = "pgvector"
+	ProviderQdrant        = "qdrant"
+	ProviderNone          = "none"
+)
+
+const (
+	// DefaultDimensions is the embedding size of text-embedding-004, used when a caller
+	// does not know its embedder's size
+	DefaultDimensions = 768
+	// DefaultElasticURL is the Elasticsearch address used when none is configured
+	DefaultElasticURL = "http://elasticsearch:9200"
+)
+
+// Provider is a retrieval backend supporting indexing, hybrid search and deletion.
+// HybridSearch returns hits with separate BM25 (keyword) and vector scores; callers
+// combine and diversify them, typically through a Retriever.
+type Provider interface {
+	Name() string
+	EnsureIndex(ctx context.Context, index string, dimensions int) error
+	Index(ctx context.Context, index string, docs []Doc) error
+	HybridSearch(ctx context.Context, index, query string, embedding []float32, size int) ([]Hit, error)
+	Delete(ctx context.Context, index string, ids []string) error
+	Health(ctx context.Context) error
+}
+
+// Config selects and configures a retrieval provider
+type Config struct {
+	Provider      string `json:"provider"` // elasticsearch (default), pgvector, qdrant or none
+	ElasticURL    string `json:"elastic_url"`
+	ElasticAPIKey string `json:"elastic_api_key"`
+	PostgresDSN   string `json:"postgres_dsn"`
+	QdrantURL     string `json:"qdrant_url"`
+	QdrantAPIKey  string `json:"qdrant_api_key"`
+}
+
+// ConfigFromEnv reads the provider configuration from RETRIEVAL_PROVIDER, ELASTIC_URL,
+// ELASTIC_API_KEY, PGVECTOR_DSN, QDRANT_URL and QDRANT_API_KEY
+func ConfigFromEnv() Config {
+	config := Config{
+		Provider:      strings.ToLower(strings.TrimSpace(os.Getenv("RETRIEVAL_PROVIDER"))),
+		ElasticURL:    os.Getenv("ELASTIC_URL"),
+		ElasticAPIKey: os.Getenv("ELASTIC_API_KEY"),
+		PostgresDSN:   os.Getenv("PGVECTOR_DSN"),
+		QdrantURL:     os.Getenv("QDRANT_URL"),
+		QdrantAPIKey:  os.Getenv("QDRANT_API_KEY"),
+	}
+	if config.Provider == "" {
+		config.Provider = ProviderElasticsearch
+	}
+	return config
+}
+
+// New connects to the configured provider. It returns a nil provider for ProviderNone.
+func New(ctx context.Context, config Config) (Provider, error) {
+	switch config.Provider {
+	case "", ProviderElasticsearch:
+		elasticURL := config.ElasticURL
+		if elasticURL == "" {
+			elasticURL = DefaultElasticURL
+		}
+		client, err := elastic.NewClient(ctx, elasticURL, config.ElasticAPIKey)
+		if err != nil {
+			return nil, err
+		}
+		return NewElasticsearchProvider(client), nil
+	case ProviderPGVector:
+		if config.PostgresDSN == "" {
+			return nil, fmt.Errorf("pgvector provider requires a Postgres DSN")
+		}
+		provider, err := OpenPGVectorProvider(ctx, config.PostgresDSN)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	case ProviderQdrant:
+		if config.QdrantURL == "" {
+			return nil, fmt.Errorf("qdrant provider requires a URL")
+		}
+		provider := NewQdrantProvider(config.QdrantURL, config.QdrantAPIKey)
+		if err := provider.Health(ctx); err != nil {
+			return nil, fmt.Errorf("failed to connect to Qdrant: %w", err)
+		}
+		return provider, nil
+	case ProviderNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown retrieval provider %q", config.Provider)
+	}
+}
+
+// DimensionReporter is implemented by providers that can report the embedding size an
+// existing index was created with
+type DimensionReporter interface {
+	IndexDimensions(ctx context.Context, index string) (int, error)
+}
+
+// TenantMetadataKey is the document metadata key naming the organization a document belongs to
+const TenantMetadataKey = "org_id"
+
+// TenantAliaser is implemented by providers that can expose one tenant's documents of a shared
+// index under their own name, so reads through that name never return another tenant's documents
+type TenantAliaser interface {
+	// EnsureTenantAlias points alias at the documents of index whose TenantMetadataKey is orgID,
+	// or at the documents without an organization when orgID is empty
+	EnsureTenantAlias(ctx context.Context, index, alias, orgID string) error
+}
+
+// CheckDimensions verifies that an existing index stores embeddings of the given size, so a
+// change of embedding model fails at startup instead of silently degrading search. Providers
+// that cannot report their dimensions, and indices that do not exist yet, pass.
+func CheckDimensions(ctx context.Context, provider Provider, index string, dimensions int) error {
+	reporter, ok := provider.(DimensionReporter)
+	if !ok {
+		return nil
+	}
+	indexed, err := reporter.IndexDimensions(ctx, index)
+	if err != nil {
+		return fmt.Errorf("failed to read dimensions of index %s: %w", index, err)
+	}
+	if indexed != 0 && indexed != dimensions {
+		return fmt.Errorf("index %s stores %d-dimensional embeddings but the embedding model produces %d; "+
+			"reindex or set EMBEDDING_DIMENSIONS to match", index, indexed, dimensions)
+	}
+	return nil
+}
+
+// NewRetriever creates a hybrid search retriever over a provider, adding query
+// embedding, score combination, MMR diversification and snippets
+func NewRetriever(provider Provider, embedder llm.Embedder) *elastic.Retriever {
+	return elastic.NewRetrieverWithBackend(provider, embedder)
+}
+
+// keywordScore scores how many distinct query terms appear in a document, in [0, 1].
+// Providers without a native full-text index use it as their BM25 component.
+func keywordScore(query string, doc Doc) float64 {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return 0
+	}
+
+	text := strings.ToLower(doc.Topic + " " + doc.Section + " " + doc.Text)
+	seen := make(map[string]bool, len(terms))
+	matched := 0
+	for _, term := range terms {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		if strings.Contains(text, term) {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(seen))
+}
@@ -0,0 +1,183 @@
+package retrieval
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQdrant is a minimal in-memory Qdrant REST server
+type fakeQdrant struct {
+	mu          sync.Mutex
+	collections map[string]int
+	points      map[string]map[string]interface{} // point ID -> payload
+	deleted     []string
+}
+
+func newFakeQdrant() *fakeQdrant {
+	return &fakeQdrant{collections: make(map[string]int), points: make(map[string]map[string]interface{})}
+}
+
+func (f *fakeQdrant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	switch {
+	case r.URL.Path == "/healthz":
+		w.Write([]byte("ok"))
+	case r.Method == http.MethodGet && r.URL.Path == "/collections/lessons":
+		if _, ok := f.collections["lessons"]; !ok {
+			http.Error(w, `{"status":{"error":"Not found"}}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{
+			"config": map[string]interface{}{"params": map[string]interface{}{
+				"vectors": map[string]interface{}{"size": f.collections["lessons"], "distance": "Cosine"},
+			}},
+		}})
+	case r.Method == http.MethodPut && r.URL.Path == "/collections/lessons":
+		vectors := body["vectors"].(map[string]interface{})
+		f.collections["lessons"] = int(vectors["size"].(float64))
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": true})
+	case r.Method == http.MethodPut && r.URL.Path == "/collections/lessons/points":
+		for _, p := range body["points"].([]interface{}) {
+			point := p.(map[string]interface{})
+			f.points[point["id"].(string)] = point["payload"].(map[string]interface{})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"status": "completed"}})
+	case r.Method == http.MethodPost && r.URL.Path == "/collections/lessons/points/search":
+		// Every point is returned with a fixed similarity; "Caching" ranks first by vector
+		results := make([]map[string]interface{}, 0)
+		for _, payload := range f.points {
+			score := 0.5
+			if payload["topic"] == "Caching" {
+				score = 0.6
+			}
+			results = append(results, map[string]interface{}{"score": score, "payload": payload})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": results})
+	case r.Method == http.MethodPost && r.URL.Path == "/collections/lessons/points/delete":
+		for _, id := range body["points"].([]interface{}) {
+			f.deleted = append(f.deleted, id.(string))
+			delete(f.points, id.(string))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"status": "completed"}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestQdrantProvider(t *testing.T) {
+	server := newFakeQdrant()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	ctx := context.Background()
+	provider := NewQdrantProvider(ts.URL+"/", "")
+	require.NoError(t, provider.Health(ctx))
+
+	require.NoError(t, provider.EnsureIndex(ctx, "lessons", 0))
+	assert.Equal(t, DefaultDimensions, server.collections["lessons"])
+	require.NoError(t, provider.EnsureIndex(ctx, "lessons", 0), "existing collections are left alone")
+
+	require.NoError(t, provider.Index(ctx, "lessons", []Doc{
+		{ID: "doc-1", Topic: "Caching", Text: "Caches keep hot data close", Embedding: []float32{0.1, 0.2}},
+		{ID: "doc-2", Topic: "Recursion", Text: "Recursion is a function calling itself", Embedding: []float32{0.3, 0.4}},
+	}))
+	assert.Len(t, server.points, 2)
+
+	hits, err := provider.HybridSearch(ctx, "lessons", "recursion function", []float32{0.3, 0.4}, 1)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "doc-2", hits[0].Doc.ID, "keyword matches outrank a slightly closer vector")
+	assert.Equal(t, 1.0, hits[0].BM25Score)
+	assert.Equal(t, 0.5, hits[0].VectorScore)
+
+	require.NoError(t, provider.Delete(ctx, "lessons", []string{"doc-1"}))
+	assert.Equal(t, []string{qdrantPointID("doc-1")}, server.deleted)
+	assert.Len(t, server.points, 1)
+}
+
+func TestCheckDimensions(t *testing.T) {
+	server := newFakeQdrant()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	ctx := context.Background()
+	provider := NewQdrantProvider(ts.URL, "")
+
+	dimensions, err := provider.IndexDimensions(ctx, "lessons")
+	require.NoError(t, err)
+	assert.Equal(t, 0, dimensions)
+	require.NoError(t, CheckDimensions(ctx, provider, "lessons", 1536), "a missing index takes any size")
+
+	require.NoError(t, provider.EnsureIndex(ctx, "lessons", 768))
+	dimensions, err = provider.IndexDimensions(ctx, "lessons")
+	require.NoError(t, err)
+	assert.Equal(t, 768, dimensions)
+
+	require.NoError(t, CheckDimensions(ctx, provider, "lessons", 768))
+	err = CheckDimensions(ctx, provider, "lessons", 1536)
+	assert.ErrorContains(t, err, "stores 768-dimensional embeddings but the embedding model produces 1536")
+}
+
+func TestQdrantPointIDIsStable(t *testing.T) {
+	assert.Equal(t, qdrantPointID("doc-1"), qdrantPointID("doc-1"))
+	assert.NotEqual(t, qdrantPointID("doc-1"), qdrantPointID("doc-2"))
+}
+
+func TestVectorLiteral(t *testing.T) {
+	assert.Equal(t, "[0.5,-1,0.25]", vectorLiteral([]float32{0.5, -1, 0.25}))
+	assert.Equal(t, "[]", vectorLiteral(nil))
+}
+
+func TestTableName(t *testing.T) {
+	name, err := tableName("lessons")
+	require.NoError(t, err)
+	assert.Equal(t, `"lessons"`, name)
+
+	for _, bad := range []string{"", "Lessons", "lessons; DROP TABLE x", "1lessons"} {
+		_, err := tableName(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestKeywordScore(t *testing.T) {
+	doc := Doc{Topic: "Binary search", Text: "Halve the sorted array each step"}
+	assert.Equal(t, 1.0, keywordScore("binary search", doc))
+	assert.Equal(t, 0.5, keywordScore("sorted heap", doc))
+	assert.Equal(t, 0.0, keywordScore("", doc))
+}
+
+func TestNew(t *testing.T) {
+	provider, err := New(context.Background(), Config{Provider: ProviderNone})
+	assert.NoError(t, err)
+	assert.Nil(t, provider)
+
+	_, err = New(context.Background(), Config{Provider: "solr"})
+	assert.Error(t, err)
+	_, err = New(context.Background(), Config{Provider: ProviderPGVector})
+	assert.Error(t, err)
+	_, err = New(context.Background(), Config{Provider: ProviderQdrant})
+	assert.Error(t, err)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("RETRIEVAL_PROVIDER", " Qdrant ")
+	t.Setenv("QDRANT_URL", "http://qdrant:6333")
+	config := ConfigFromEnv()
+	assert.Equal(t, ProviderQdrant, config.Provider)
+	assert.Equal(t, "http://qdrant:6333", config.QdrantURL)
+
+	t.Setenv("RETRIEVAL_PROVIDER", "")
+	assert.Equal(t, ProviderElasticsearch, ConfigFromEnv().Provider)
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // Registers the pure-Go "sqlite" driver, so no cgo or system library is needed
+)
+
+// SQLiteStorage is a key-value Storage in a local SQLite file, for development without cloud credentials
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+// NewSQLiteStorage opens or creates the SQLite database at path; ":memory:" keeps it in memory
+func NewSQLiteStorage(ctx context.Context, path string) (*SQLiteStorage, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database %s: %w", path, err)
+	}
+	// SQLite allows one writer at a time; one connection also keeps an in-memory database alive
+	db.SetMaxOpenConns(1)
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS kv_storage (
+		key        TEXT PRIMARY KEY,
+		value      BLOB NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create SQLite schema: %w", err)
+	}
+	return &SQLiteStorage{db: db}, nil
+}
+
+// Get retrieves a value by key from SQLite
+func (s *SQLiteStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM kv_storage WHERE key = ?`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("key %s not found", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// Set stores a value by key in SQLite
+func (s *SQLiteStorage) Set(ctx context.Context, key string, value []byte) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO kv_storage (key, value, updated_at) VALUES (?, ?, ?)
+		 ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
+		key, value, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to set key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes a value by key from SQLite
+func (s *SQLiteStorage) Delete(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM kv_storage WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("failed to delete key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Close closes the SQLite database
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSQLiteStorage tests key-value storage in a SQLite file, including reopening it
+func TestSQLiteStorage(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "explainiq.db")
+
+	store, err := NewSQLiteStorage(ctx, path)
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "missing")
+	assert.ErrorContains(t, err, "not found")
+
+	require.NoError(t, store.Set(ctx, "flags", []byte(`{"a": 1}`)))
+	require.NoError(t, store.Set(ctx, "flags", []byte(`{"a": 2}`)))
+	require.NoError(t, store.Set(ctx, "keys", []byte(`[]`)))
+	value, err := store.Get(ctx, "flags")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 2}`, string(value))
+
+	require.NoError(t, store.Delete(ctx, "keys"))
+	require.NoError(t, store.Close())
+
+	store, err = NewSQLiteStorage(ctx, path)
+	require.NoError(t, err)
+	defer store.Close()
+	value, err = store.Get(ctx, "flags")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 2}`, string(value))
+	_, err = store.Get(ctx, "keys")
+	assert.Error(t, err)
+}
@@ -0,0 +1,5 @@
+module github.com/InnoFusionTech/ExplainIQ/internal/tokens
+
+go 1.22
+
+require github.com/stretchr/testify v1.9.0
@@ -0,0 +1,205 @@
+// Package tokens counts the tokens of text for a model, with the provider's tokenizer API
+// where available and a model-aware heuristic otherwise. Context budgets, quotas and cost
+// estimates use it instead of treating string length as a token count.
+package tokens
+
+import (
+	"context"
+	"crypto/sha256"
+	"math"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// defaultTimeout bounds a tokenizer API call before falling back to the estimate
+	defaultTimeout = 2 * time.Second
+	// apiBackoff is how long the API is skipped after it fails
+	apiBackoff = time.Minute
+	// maxCacheEntries bounds the count cache; it is cleared when full
+	maxCacheEntries = 4096
+)
+
+// profile describes how a model family's tokenizer splits text
+type profile struct {
+	charsPerToken float64 // Latin-script characters packed into a token
+	splitDigits   bool    // Every digit is its own token
+}
+
+// profiles are matched by model name prefix, first match wins
+var profiles = []struct {
+	prefix string
+	profile
+}{
+	{"gemini", profile{charsPerToken: 4, splitDigits: true}},
+	{"gemma", profile{charsPerToken: 4, splitDigits: true}},
+	{"gpt", profile{charsPerToken: 4}},
+	{"claude", profile{charsPerToken: 3.5}},
+}
+
+// defaultProfile is used for unknown models
+var defaultProfile = profile{charsPerToken: 4}
+
+// profileFor returns the tokenizer profile of a model, ignoring any "models/" prefix
+func profileFor(model string) profile {
+	model = strings.TrimPrefix(strings.ToLower(model), "models/")
+	for _, p := range profiles {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.profile
+		}
+	}
+	return defaultProfile
+}
+
+// isLogographic reports whether r belongs to a script tokenizers split about one token per character
+func isLogographic(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Thai)
+}
+
+// Estimate approximates the number of tokens a model's tokenizer produces for text. Words take
+// about one token per few characters, punctuation and logographic characters about one each.
+// It errs towards overcounting, so budgets built on it hold.
+func Estimate(model, text string) int {
+	p := profileFor(model)
+	tokens := 0.0
+	word := 0.0 // Weighted characters of the word being read
+	flush := func() {
+		if word > 0 {
+			tokens += math.Max(1, math.Ceil(word/p.charsPerToken))
+			word = 0
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case isLogographic(r):
+			flush()
+			tokens++
+		case unicode.IsDigit(r) && p.splitDigits:
+			flush()
+			tokens++
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			if r < utf8.RuneSelf {
+				word++
+			} else {
+				word += 2 // Non-ASCII letters are rarer in tokenizer vocabularies
+			}
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return int(tokens)
+}
+
+// Truncate returns the longest prefix of text, cut at a rune boundary, estimated to fit in
+// maxTokens tokens
+func Truncate(model, text string, maxTokens int) string {
+	if Estimate(model, text) <= maxTokens {
+		return text
+	}
+	if maxTokens <= 0 {
+		return ""
+	}
+
+	// Estimates only grow as the prefix does, so search for the longest one that fits
+	offsets := make([]int, 0, len(text))
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	lo, hi := 0, len(offsets)-1 // offsets[lo] always fits
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if Estimate(model, text[:offsets[mid]]) <= maxTokens {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return text[:offsets[lo]]
+}
+
+// Counter counts tokens with a provider's tokenizer
+type Counter interface {
+	CountTokens(ctx context.Context, model, text string) (int, error)
+}
+
+// cacheKey identifies a counted text
+type cacheKey struct {
+	model string
+	text  [sha256.Size]byte
+}
+
+// Service counts tokens with a provider's tokenizer API, falling back to Estimate when there is
+// no API or it fails or is slow. A nil Service always estimates.
+type Service struct {
+	api     Counter
+	timeout time.Duration
+
+	mu          sync.Mutex
+	cache       map[cacheKey]int
+	failedUntil time.Time // The API is skipped until then after a failure
+}
+
+// New creates a Service counting with api; a nil api always estimates
+func New(api Counter) *Service {
+	return &Service{
+		api:     api,
+		timeout: defaultTimeout,
+		cache:   make(map[cacheKey]int),
+	}
+}
+
+// Count returns the number of tokens in text for a model
+func (s *Service) Count(ctx context.Context, model, text string) int {
+	if s == nil || s.api == nil || text == "" {
+		return Estimate(model, text)
+	}
+
+	key := cacheKey{model: model, text: sha256.Sum256([]byte(text))}
+	s.mu.Lock()
+	if count, ok := s.cache[key]; ok {
+		s.mu.Unlock()
+		return count
+	}
+	skip := time.Now().Before(s.failedUntil)
+	s.mu.Unlock()
+	if skip {
+		return Estimate(model, text)
+	}
+
+	apiCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	count, err := s.api.CountTokens(apiCtx, model, text)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failedUntil = time.Now().Add(apiBackoff)
+		return Estimate(model, text)
+	}
+	if len(s.cache) >= maxCacheEntries {
+		s.cache = make(map[cacheKey]int)
+	}
+	s.cache[key] = count
+	return count
+}
+
+// Truncate returns the longest prefix of text, cut at a rune boundary, that fits in maxTokens
+// tokens for a model. Counted text over the budget is cut by the estimate, scaled to the count.
+func (s *Service) Truncate(ctx context.Context, model, text string, maxTokens int) string {
+	count := s.Count(ctx, model, text)
+	if count <= maxTokens {
+		return text
+	}
+	target := maxTokens
+	if estimate := Estimate(model, text); estimate > 0 {
+		target = int(float64(maxTokens) * float64(estimate) / float64(count))
+	}
+	return Truncate(model, text, target)
+}
@@ -0,0 +1,82 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCounter is a Counter returning a fixed count or error
+type fakeCounter struct {
+	count int
+	err   error
+	calls int
+}
+
+func (f *fakeCounter) CountTokens(ctx context.Context, model, text string) (int, error) {
+	f.calls++
+	return f.count, f.err
+}
+
+// TestEstimate tests the heuristic across scripts, digits and model families
+func TestEstimate(t *testing.T) {
+	assert.Equal(t, 0, Estimate("gemini-2.5-flash", ""))
+	assert.Equal(t, 2, Estimate("gemini-2.5-flash", "the cat"))
+	assert.Equal(t, 3, Estimate("gemini-2.5-flash", "the, cat"))
+	assert.Equal(t, 5, Estimate("gemini-2.5-flash", "internationalization"), "long words take several tokens")
+	assert.Equal(t, 4, Estimate("gemini-2.5-flash", "2024"), "Gemini splits digits")
+	assert.Equal(t, 1, Estimate("gpt-4o", "2024"))
+	assert.Equal(t, 4, Estimate("gemini-2.5-flash", "缓存命中"), "one token per Han character")
+	assert.Equal(t, Estimate("gemini-2.5-flash", "cache"), Estimate("models/gemini-2.5-flash", "cache"))
+	assert.Greater(t, Estimate("claude-3-haiku", strings.Repeat("tokenization ", 10)), Estimate("gemini-2.5-flash", strings.Repeat("tokenization ", 10)))
+
+	// Text is never estimated at fewer tokens than a plain length/4 proxy would give for prose
+	text := strings.Repeat("A cache keeps hot data close to the processor. ", 20)
+	assert.GreaterOrEqual(t, Estimate("", text), len(text)/4)
+}
+
+// TestTruncate tests cutting text to a token budget at rune boundaries
+func TestTruncate(t *testing.T) {
+	text := "Caches keep hot data close; évictions drop cold data."
+	assert.Equal(t, text, Truncate("gemini-2.5-flash", text, 100))
+	assert.Equal(t, "", Truncate("gemini-2.5-flash", text, 0))
+
+	trimmed := Truncate("gemini-2.5-flash", text, 5)
+	assert.True(t, strings.HasPrefix(text, trimmed))
+	assert.LessOrEqual(t, Estimate("gemini-2.5-flash", trimmed), 5)
+	assert.Greater(t, Estimate("gemini-2.5-flash", text[:len(trimmed)+1]), 5, "the longest fitting prefix is kept")
+
+	assert.Equal(t, "缓存", Truncate("gemini-2.5-flash", "缓存命中", 2))
+}
+
+// TestServiceCount tests counting with the API, caching counts and falling back to the estimate
+func TestServiceCount(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeCounter{count: 42}
+	service := New(api)
+	assert.Equal(t, 42, service.Count(ctx, "gemini-2.5-flash", "the cat"))
+	assert.Equal(t, 42, service.Count(ctx, "gemini-2.5-flash", "the cat"))
+	assert.Equal(t, 1, api.calls, "counts are cached")
+
+	failing := &fakeCounter{err: errors.New("unavailable")}
+	service = New(failing)
+	assert.Equal(t, 2, service.Count(ctx, "gemini-2.5-flash", "the cat"))
+	assert.Equal(t, 3, service.Count(ctx, "gemini-2.5-flash", "the, cat"))
+	assert.Equal(t, 1, failing.calls, "a failing API is skipped for a while")
+
+	var unset *Service
+	assert.Equal(t, 2, unset.Count(ctx, "gemini-2.5-flash", "the cat"))
+	assert.Equal(t, 2, New(nil).Count(ctx, "gemini-2.5-flash", "the cat"))
+}
+
+// TestServiceTruncate tests that counted text is cut in proportion to its counted size
+func TestServiceTruncate(t *testing.T) {
+	text := strings.Repeat("word ", 100)
+	service := New(&fakeCounter{count: 200}) // Twice the estimate
+	trimmed := service.Truncate(context.Background(), "gemini-2.5-flash", text, 100)
+	assert.Equal(t, 50, Estimate("gemini-2.5-flash", trimmed))
+	assert.Equal(t, text, New(&fakeCounter{count: 80}).Truncate(context.Background(), "gemini-2.5-flash", text, 100))
+}
@@ -0,0 +1,66 @@
+package explainiq
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	adkgoogle "github.com/InnoFusionTech/ExplainIQ/internal/adk/google"
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/sirupsen/logrus"
+)
+
+// TaskRequest is the request an agent receives for one pipeline step
+type TaskRequest = adk.TaskRequest
+
+// TaskResponse is an agent's reply to a TaskRequest
+type TaskResponse = adk.TaskResponse
+
+// Agent names, in pipeline order
+const (
+	Summarizer = agents.Summarizer
+	Explainer  = agents.Explainer
+	Visualizer = agents.Visualizer
+	Critic     = agents.Critic
+)
+
+// AgentNames lists every agent in pipeline order
+var AgentNames = []string{Summarizer, Explainer, Visualizer, Critic}
+
+// Agent executes one pipeline step
+type Agent interface {
+	ExecuteTask(ctx context.Context, req *TaskRequest) (*TaskResponse, error)
+	Health(ctx context.Context) error
+}
+
+// NewLocalAgent creates the named agent in-process, generating with client. A zero
+// timeout disables the per-task deadline; a nil logger discards the agent's logs.
+func NewLocalAgent(name string, client Client, timeout time.Duration, logger *logrus.Logger) (Agent, error) {
+	if logger == nil {
+		logger = quietLogger()
+	}
+	processor, err := agents.NewProcessor(name, client, logger)
+	if err != nil {
+		return nil, err
+	}
+	return agents.NewLocalClient(name, processor, timeout), nil
+}
+
+// NewRemoteAgent creates a client for an agent service at baseURL, speaking A2A to
+// https and Cloud Run URLs and the HTTP task protocol otherwise. A zero timeout keeps
+// the client's default.
+func NewRemoteAgent(baseURL string, timeout time.Duration) Agent {
+	client := adkgoogle.NewClient(baseURL)
+	if timeout > 0 {
+		client = client.WithTimeout(timeout)
+	}
+	return client
+}
+
+// quietLogger returns a logger that discards output
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
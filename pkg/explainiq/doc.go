@@ -0,0 +1,28 @@
+// Package explainiq embeds ExplainIQ lesson generation in a Go application, without
+// running the HTTP orchestrator.
+//
+// An Engine runs the lesson pipeline: the summarizer outlines a topic, the explainer
+// writes the lesson, the visualizer draws it and the critic reviews it, after which the
+// critic's patch plan is applied to the lesson. Each step is an Agent, either in-process
+// (NewLocalAgent, the default) or a deployed agent service (NewRemoteAgent):
+//
+//	engine, err := explainiq.New(explainiq.Config{Client: explainiq.NewGeminiClient("")})
+//	if err != nil {
+//		return err
+//	}
+//	result, err := engine.Generate(ctx, explainiq.Request{Topic: "Raft consensus"})
+//
+// Engine is a plain sequential runner over the same agents, not the orchestrator's
+// pipeline: it does not retry steps, moderate lessons or render them as HTML, and it fails
+// on a patch plan the orchestrator would skip. Sessions, streaming, storage, caching and the
+// other orchestrator features are not part of this package; use the orchestrator's HTTP
+// API for those.
+//
+// # Status
+//
+// This package is experimental and has no stability guarantee. It builds on the
+// repository's internal modules, which are not published as versioned modules, so it can
+// only be used from within this repository's Go workspace or through a replace directive
+// pointing at a checkout. Lesson, TaskRequest and the other aliased types are the internal
+// types themselves and change whenever those do.
+package explainiq
@@ -0,0 +1,198 @@
+package explainiq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config configures an Engine
+type Config struct {
+	Client      Client           // Model the in-process agents generate with; nil uses NewGeminiClient("")
+	Agents      map[string]Agent // Agents by name, replacing the in-process ones, e.g. from NewRemoteAgent
+	StepTimeout time.Duration    // Deadline for each in-process step; zero disables it
+	Visualize   bool             // Runs the visualizer step
+	SkipCritic  bool             // Skips the critic step, so the lesson is not reviewed or patched
+	Logger      *logrus.Logger   // Logger for the engine and in-process agents; nil discards logs
+}
+
+// Request asks for a lesson on one topic
+type Request struct {
+	Topic         string // Topic to teach; required
+	Context       string // Reference material the summarizer and explainer should draw on
+	Persona       string // Persona to write for, e.g. "10-year-old" or "senior-engineer"
+	Model         string // Model override for the summarizer, explainer and critic
+	Deterministic bool   // Generates reproducibly for the same request
+	SessionID     string // Identifies the run to the agents; one is generated when empty
+}
+
+// Result is a generated lesson with what the pipeline produced along the way
+type Result struct {
+	SessionID      string            // Session ID the agents saw
+	Lesson         Lesson            // Final lesson, with the critic's patches applied
+	Outline        []string          // Summarizer outline the lesson follows
+	Prerequisites  []string          // What a learner should know first
+	Misconceptions []string          // Common misconceptions the lesson addresses
+	Images         []ImageRef        // Visualizer images; empty unless Config.Visualize is set
+	Critique       []CritiqueIssue   // Issues the critic found before patching
+	Patches        []PatchPlanItem   // Patches applied to the lesson
+	Artifacts      map[string]string // Raw artifacts of every step, keyed by artifact name; "lesson" is unpatched
+}
+
+// ErrTopicRequired is returned by Generate for a request without a topic
+var ErrTopicRequired = errors.New("explainiq: topic is required")
+
+// Engine generates lessons by running the pipeline's agents in order. It is safe for
+// concurrent use.
+type Engine struct {
+	agents map[string]Agent
+	steps  []string
+	logger *logrus.Logger
+}
+
+// New creates an Engine, creating in-process agents for every step Config.Agents does not supply
+func New(config Config) (*Engine, error) {
+	logger := config.Logger
+	if logger == nil {
+		logger = quietLogger()
+	}
+
+	steps := []string{Summarizer, Explainer}
+	if config.Visualize {
+		steps = append(steps, Visualizer)
+	}
+	if !config.SkipCritic {
+		steps = append(steps, Critic)
+	}
+
+	engine := &Engine{agents: make(map[string]Agent, len(steps)), steps: steps, logger: logger}
+	for _, name := range steps {
+		if agent := config.Agents[name]; agent != nil {
+			engine.agents[name] = agent
+			continue
+		}
+		if config.Client == nil {
+			config.Client = NewGeminiClient("")
+		}
+		agent, err := NewLocalAgent(name, config.Client, config.StepTimeout, logger)
+		if err != nil {
+			return nil, fmt.Errorf("explainiq: failed to create %s agent: %w", name, err)
+		}
+		engine.agents[name] = agent
+	}
+	return engine, nil
+}
+
+// Steps returns the agents the engine runs, in order
+func (e *Engine) Steps() []string {
+	return append([]string(nil), e.steps...)
+}
+
+// Health checks every agent the engine runs
+func (e *Engine) Health(ctx context.Context) error {
+	for _, name := range e.steps {
+		if err := e.agents[name].Health(ctx); err != nil {
+			return fmt.Errorf("explainiq: %s agent is unavailable: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Generate runs the pipeline for a request and returns the finished lesson. Each step
+// receives the artifacts of the steps before it; the first failing step ends the run.
+func (e *Engine) Generate(ctx context.Context, req Request) (*Result, error) {
+	topic := strings.TrimSpace(req.Topic)
+	if topic == "" {
+		return nil, ErrTopicRequired
+	}
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = fmt.Sprintf("explainiq-%d", time.Now().UnixNano())
+	}
+
+	inputs := map[string]string{"topic": topic}
+	if req.Context != "" {
+		inputs["context"] = req.Context
+	}
+	if req.Persona != "" {
+		inputs["persona"] = req.Persona
+	}
+	if req.Model != "" {
+		inputs["model"] = req.Model
+	}
+	if req.Deterministic {
+		inputs["deterministic"] = "true"
+	}
+
+	artifacts := make(map[string]string)
+	for _, name := range e.steps {
+		stepInputs := make(map[string]string, len(inputs)+len(artifacts))
+		for key, value := range artifacts {
+			stepInputs[key] = value
+		}
+		for key, value := range inputs {
+			stepInputs[key] = value
+		}
+
+		started := time.Now()
+		response, err := e.agents[name].ExecuteTask(ctx, &TaskRequest{
+			SessionID: sessionID,
+			Step:      name,
+			Topic:     topic,
+			Inputs:    stepInputs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("explainiq: %s step failed: %w", name, err)
+		}
+		if response == nil {
+			return nil, fmt.Errorf("explainiq: %s step returned no response", name)
+		}
+		for key, value := range response.Artifacts {
+			artifacts[key] = value
+		}
+
+		e.logger.WithFields(logrus.Fields{
+			"session_id":  sessionID,
+			"step":        name,
+			"duration_ms": time.Since(started).Milliseconds(),
+		}).Debug("Pipeline step completed")
+	}
+
+	return buildResult(sessionID, artifacts)
+}
+
+// buildResult decodes the pipeline's artifacts and applies the critic's patch plan to the lesson
+func buildResult(sessionID string, artifacts map[string]string) (*Result, error) {
+	result := &Result{SessionID: sessionID, Artifacts: artifacts}
+	if err := json.Unmarshal([]byte(artifacts["lesson"]), &result.Lesson); err != nil {
+		return nil, fmt.Errorf("explainiq: explainer returned an invalid lesson: %w", err)
+	}
+
+	optional := map[string]interface{}{
+		"outline":        &result.Outline,
+		"prerequisites":  &result.Prerequisites,
+		"misconceptions": &result.Misconceptions,
+		"images":         &result.Images,
+		"critique":       &result.Critique,
+		"patch_plan":     &result.Patches,
+	}
+	for name, target := range optional {
+		if value := artifacts[name]; value != "" {
+			if err := json.Unmarshal([]byte(value), target); err != nil {
+				return nil, fmt.Errorf("explainiq: invalid %s artifact: %w", name, err)
+			}
+		}
+	}
+
+	for _, patch := range result.Patches {
+		if !result.Lesson.SetSectionText(patch.Section, patch.ReplacementText) {
+			return nil, fmt.Errorf("explainiq: patch plan names unknown section %q", patch.Section)
+		}
+	}
+	return result, nil
+}
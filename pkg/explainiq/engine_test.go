@@ -0,0 +1,130 @@
+package explainiq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// agentFunc is an Agent answering tasks with a function
+type agentFunc func(ctx context.Context, req *TaskRequest) (*TaskResponse, error)
+
+// ExecuteTask implements Agent
+func (f agentFunc) ExecuteTask(ctx context.Context, req *TaskRequest) (*TaskResponse, error) {
+	return f(ctx, req)
+}
+
+// Health implements Agent
+func (f agentFunc) Health(ctx context.Context) error {
+	return nil
+}
+
+// TestGenerate tests a full in-process run with the lite client
+func TestGenerate(t *testing.T) {
+	engine, err := New(Config{Client: NewLiteClient(), Visualize: true})
+	require.NoError(t, err)
+	assert.Equal(t, AgentNames, engine.Steps())
+	require.NoError(t, engine.Health(context.Background()))
+
+	result, err := engine.Generate(context.Background(), Request{Topic: "Raft consensus"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.SessionID)
+	assert.Contains(t, result.Lesson.BigPicture, "Raft consensus")
+	assert.Len(t, result.Outline, 3)
+	assert.NotEmpty(t, result.Misconceptions)
+	require.Len(t, result.Images, 1)
+	assert.NotEmpty(t, result.Images[0].AltText)
+	assert.Empty(t, result.Critique)
+	assert.Contains(t, result.Artifacts, "critique")
+}
+
+// TestGenerateAppliesPatchPlan tests that the critic sees earlier artifacts and its patches reach the lesson
+func TestGenerateAppliesPatchPlan(t *testing.T) {
+	var criticInputs map[string]string
+	critic := agentFunc(func(ctx context.Context, req *TaskRequest) (*TaskResponse, error) {
+		criticInputs = req.Inputs
+		return &TaskResponse{Artifacts: map[string]string{
+			"critique":   `[{"section": "metaphor", "problem": "Too vague", "severity": "medium"}]`,
+			"patch_plan": `[{"section": "metaphor", "change": "Sharpen", "replacement_text": "Raft is a committee electing a chair."}]`,
+		}}, nil
+	})
+
+	engine, err := New(Config{Client: NewLiteClient(), Agents: map[string]Agent{Critic: critic}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{Summarizer, Explainer, Critic}, engine.Steps())
+
+	result, err := engine.Generate(context.Background(), Request{Topic: "Raft", Persona: "10-year-old", SessionID: "s1"})
+	require.NoError(t, err)
+	assert.Equal(t, "s1", result.SessionID)
+	assert.Equal(t, "Raft is a committee electing a chair.", result.Lesson.Metaphor)
+	require.Len(t, result.Critique, 1)
+	assert.Equal(t, "medium", result.Critique[0].Severity)
+
+	assert.Equal(t, "10-year-old", criticInputs["persona"])
+	assert.NotEmpty(t, criticInputs["outline"])
+	var original Lesson
+	require.NoError(t, json.Unmarshal([]byte(criticInputs["lesson"]), &original))
+	assert.NotEqual(t, result.Lesson.Metaphor, original.Metaphor)
+	assert.Equal(t, criticInputs["lesson"], result.Artifacts["lesson"], "artifacts keep the unpatched lesson")
+}
+
+// TestGenerateRemoteAgent tests a step served by an agent over the HTTP task protocol
+func TestGenerateRemoteAgent(t *testing.T) {
+	var received TaskRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/task", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(TaskResponse{Artifacts: map[string]string{
+			"outline": `["Terms", "Leader election", "Log replication"]`,
+		}})
+	}))
+	defer server.Close()
+
+	engine, err := New(Config{
+		Client:     NewLiteClient(),
+		Agents:     map[string]Agent{Summarizer: NewRemoteAgent(server.URL, 0)},
+		SkipCritic: true,
+	})
+	require.NoError(t, err)
+
+	result, err := engine.Generate(context.Background(), Request{Topic: "Raft", Deterministic: true})
+	require.NoError(t, err)
+	assert.Equal(t, Summarizer, received.Step)
+	assert.Equal(t, "true", received.Inputs["deterministic"])
+	assert.Equal(t, []string{"Terms", "Leader election", "Log replication"}, result.Outline)
+	assert.NotEmpty(t, result.Lesson.CoreMechanism)
+	assert.NotContains(t, result.Artifacts, "critique")
+}
+
+// TestGenerateErrors tests that a missing topic, a failing step and a bad patch plan end the run
+func TestGenerateErrors(t *testing.T) {
+	engine, err := New(Config{Client: NewLiteClient()})
+	require.NoError(t, err)
+	_, err = engine.Generate(context.Background(), Request{Topic: "  "})
+	assert.ErrorIs(t, err, ErrTopicRequired)
+
+	failing := agentFunc(func(ctx context.Context, req *TaskRequest) (*TaskResponse, error) {
+		return nil, errors.New("model unavailable")
+	})
+	engine, err = New(Config{Client: NewLiteClient(), Agents: map[string]Agent{Explainer: failing}})
+	require.NoError(t, err)
+	_, err = engine.Generate(context.Background(), Request{Topic: "Raft"})
+	assert.ErrorContains(t, err, "explainer step failed: model unavailable")
+
+	badPatch := agentFunc(func(ctx context.Context, req *TaskRequest) (*TaskResponse, error) {
+		return &TaskResponse{Artifacts: map[string]string{
+			"patch_plan": `[{"section": "epilogue", "replacement_text": "The end."}]`,
+		}}, nil
+	})
+	engine, err = New(Config{Client: NewLiteClient(), Agents: map[string]Agent{Critic: badPatch}})
+	require.NoError(t, err)
+	_, err = engine.Generate(context.Background(), Request{Topic: "Raft"})
+	assert.ErrorContains(t, err, `unknown section "epilogue"`)
+}
@@ -0,0 +1,22 @@
+module github.com/InnoFusionTech/ExplainIQ/pkg/explainiq
+
+go 1.24.4
+
+toolchain go1.24.10
+
+require (
+	github.com/InnoFusionTech/ExplainIQ/internal/adk v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/agents v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/llm v0.0.0
+	github.com/InnoFusionTech/ExplainIQ/internal/tokens v0.0.0 // indirect
+	github.com/sirupsen/logrus v1.9.3
+	github.com/stretchr/testify v1.11.1
+)
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/adk => ../../internal/adk
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/agents => ../../internal/agents
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/llm => ../../internal/llm
+
+replace github.com/InnoFusionTech/ExplainIQ/internal/tokens => ../../internal/tokens
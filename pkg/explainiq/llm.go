@@ -0,0 +1,43 @@
+package explainiq
+
+import (
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+)
+
+// Client is the language model the in-process agents generate with. Implement it to
+// plug in another model provider.
+type Client = llm.GeminiClientInterface
+
+// Lesson is a generated lesson, one field per section
+type Lesson = llm.OGLesson
+
+// SummarizeResponse is a topic's outline, prerequisites, misconceptions and citations
+type SummarizeResponse = llm.SummarizeResponse
+
+// CritiqueResponse is a review of a lesson with the patches that fix its issues
+type CritiqueResponse = llm.CritiqueResponse
+
+// CritiqueIssue is one problem the critic found in a lesson
+type CritiqueIssue = llm.CritiqueIssue
+
+// PatchPlanItem replaces the text of one lesson section
+type PatchPlanItem = llm.PatchPlanItem
+
+// VisualizeResponse holds the images drawn for a lesson
+type VisualizeResponse = llm.VisualizeResponse
+
+// ImageRef is one generated image
+type ImageRef = llm.ImageRef
+
+// NewGeminiClient creates a Gemini client. An empty apiKey uses GEMINI_API_KEY, or
+// Application Default Credentials when that is unset; the backend and model settings
+// are read from the environment as in the agent services.
+func NewGeminiClient(apiKey string) Client {
+	return llm.NewGeminiClient(apiKey)
+}
+
+// NewLiteClient creates a client that returns canned lessons instantly and makes no
+// model calls, for demos and tests
+func NewLiteClient() Client {
+	return llm.NewLiteClient()
+}
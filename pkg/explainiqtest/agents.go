@@ -0,0 +1,130 @@
+package explainiqtest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/sirupsen/logrus"
+)
+
+// TaskHandler answers one agent task
+type TaskHandler func(ctx context.Context, req TaskRequest) (TaskResponse, error)
+
+// ProcessTask lets a TaskHandler stand in for an agent's task processor
+func (h TaskHandler) ProcessTask(ctx context.Context, req TaskRequest) (TaskResponse, error) {
+	return h(ctx, req)
+}
+
+// quietLogger returns a logger that discards output
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// AgentHandler returns the real task processor for an agent backed by client, so its
+// artifacts have exactly the shape the orchestrator expects
+func AgentHandler(name string, client *MockGeminiClient) (TaskHandler, error) {
+	processor, err := agents.NewProcessor(name, client, quietLogger())
+	if err != nil {
+		return nil, err
+	}
+	return processor.ProcessTask, nil
+}
+
+// AgentServer is a fake agent serving the task protocol over HTTP
+type AgentServer struct {
+	*httptest.Server
+	Name string
+
+	mu       sync.Mutex
+	handler  TaskHandler
+	requests []TaskRequest
+}
+
+// NewAgentServer starts a fake agent that answers tasks with handler, or with the real
+// processor over a MockGeminiClient when handler is nil. It is closed when the test ends.
+func NewAgentServer(t testing.TB, name string, handler TaskHandler) *AgentServer {
+	t.Helper()
+	if handler == nil {
+		var err error
+		if handler, err = AgentHandler(name, &MockGeminiClient{}); err != nil {
+			t.Fatalf("explainiqtest: %v", err)
+		}
+	}
+
+	agent := &AgentServer{Name: name, handler: handler}
+	mux := http.NewServeMux()
+	health := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "healthy", "service": name})
+	}
+	mux.HandleFunc("/health", health)
+	mux.HandleFunc("/healthz", health)
+	mux.HandleFunc("POST /task", agent.serveTask)
+
+	agent.Server = httptest.NewServer(mux)
+	t.Cleanup(agent.Close)
+	return agent
+}
+
+// NewAgentServers starts a fake server for every agent with the real processors
+func NewAgentServers(t testing.TB) map[string]*AgentServer {
+	t.Helper()
+	servers := make(map[string]*AgentServer, len(AgentNames))
+	for _, name := range AgentNames {
+		servers[name] = NewAgentServer(t, name, nil)
+	}
+	return servers
+}
+
+// SetHandler replaces how the agent answers tasks
+func (a *AgentServer) SetHandler(handler TaskHandler) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.handler = handler
+}
+
+// Requests returns the tasks the agent has received, in order
+func (a *AgentServer) Requests() []TaskRequest {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]TaskRequest(nil), a.requests...)
+}
+
+// serveTask handles POST /task like the agent runtime, including its error bodies
+func (a *AgentServer) serveTask(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req TaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	a.mu.Lock()
+	a.requests = append(a.requests, req)
+	handler := a.handler
+	a.mu.Unlock()
+
+	response, err := handler(r.Context(), req)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Task processing failed",
+			"details": err.Error(),
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,127 @@
+package explainiqtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOrchestratorRunsPipeline tests a full session run against the in-memory orchestrator
+func TestOrchestratorRunsPipeline(t *testing.T) {
+	orchestrator := NewOrchestrator(t)
+
+	id, events := orchestrator.RunSession(t, "Binary Search")
+	complete, err := events.WaitFor("session_complete", 5*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, id, complete.SessionID)
+	require.NoError(t, events.Wait(5*time.Second))
+
+	assert.Equal(t, []string{
+		"connected",
+		"step_start", "step_complete",
+		"step_start", "step_complete",
+		"step_start", "step_complete",
+		"step_start", "step_complete",
+		"session_complete",
+	}, events.Types())
+
+	res, err := http.Get(orchestrator.URL + "/api/sessions/" + id + "/result")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var result SessionResult
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+	assert.Contains(t, result.Lesson, "Binary Search solves a common problem")
+	assert.Equal(t, FakeSummary("Binary Search").Outline, result.Outline)
+	assert.Len(t, result.Images, 1)
+}
+
+// TestOrchestratorStepFailure tests that a failing agent ends the stream with a session error
+func TestOrchestratorStepFailure(t *testing.T) {
+	orchestrator := NewOrchestrator(t, WithAgent(Visualizer, func(ctx context.Context, req TaskRequest) (TaskResponse, error) {
+		return TaskResponse{}, errors.New("image quota exceeded")
+	}))
+
+	id, events := orchestrator.RunSession(t, "Recursion")
+	failure, err := events.WaitFor("session_error", 5*time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, failure.Data["error"], "image quota exceeded")
+
+	session, ok := orchestrator.Session(id)
+	require.True(t, ok)
+	assert.Equal(t, "failed", session.Status)
+	assert.Equal(t, "completed", session.Steps[1].Status)
+	assert.Equal(t, "failed", session.Steps[2].Status)
+
+	res, err := http.Get(orchestrator.URL + "/api/sessions/" + id + "/result")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+// TestOrchestratorMockGemini tests that the orchestrator's agents call the given Gemini client
+func TestOrchestratorMockGemini(t *testing.T) {
+	client := &MockGeminiClient{}
+	orchestrator := NewOrchestrator(t, WithGeminiClient(client))
+
+	_, events := orchestrator.RunSession(t, "Hash Maps")
+	_, err := events.WaitFor("session_complete", 5*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Summarize", "ExplainWithOG", "VisualizeCore", "CritiqueLesson"}, client.Calls())
+}
+
+// TestOrchestratorCreateSessionValidation tests that a topic is required
+func TestOrchestratorCreateSessionValidation(t *testing.T) {
+	orchestrator := NewOrchestrator(t)
+
+	res, err := http.Post(orchestrator.URL+"/api/sessions", "application/json", bytes.NewReader([]byte(`{}`)))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, err = http.Post(orchestrator.URL+"/api/sessions/missing/run", "application/json", nil)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+// TestAgentServer tests the fake agent's task endpoint with the default and scripted handlers
+func TestAgentServer(t *testing.T) {
+	agent := NewAgentServer(t, Summarizer, nil)
+
+	body, _ := json.Marshal(TaskRequest{SessionID: "s1", Step: Summarizer, Topic: "Graphs", Inputs: map[string]string{"topic": "Graphs"}})
+	res, err := http.Post(agent.URL+"/task", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	var response TaskResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+	res.Body.Close()
+	assert.Equal(t, AgentResponse(Summarizer, "Graphs").Artifacts["outline"], response.Artifacts["outline"])
+	require.Len(t, agent.Requests(), 1)
+	assert.Equal(t, "s1", agent.Requests()[0].SessionID)
+
+	agent.SetHandler(func(ctx context.Context, req TaskRequest) (TaskResponse, error) {
+		return NewTaskResponse().Artifact("outline", `["custom"]`).Usage("gemini-2.5-flash", 10, 20).Build(), nil
+	})
+	res, err = http.Post(agent.URL+"/task", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+	res.Body.Close()
+	assert.Equal(t, `["custom"]`, response.Artifacts["outline"])
+	assert.EqualValues(t, 20, response.Metrics["output_tokens"])
+
+	agent.SetHandler(func(ctx context.Context, req TaskRequest) (TaskResponse, error) {
+		return TaskResponse{}, errors.New("boom")
+	})
+	res, err = http.Post(agent.URL+"/task", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+}
@@ -0,0 +1,102 @@
+package explainiqtest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+)
+
+// MockGeminiClient is a scripted llm.GeminiClientInterface. Each call uses its Func field when
+// set and otherwise returns the matching Fake fixture, so a zero value runs a whole pipeline.
+type MockGeminiClient struct {
+	SummarizeFunc func(ctx context.Context, topic, context string) (*llm.SummarizeResponse, error)
+	ExplainFunc   func(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error)
+	CritiqueFunc  func(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error)
+	VisualizeFunc func(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error)
+	HealthFunc    func(ctx context.Context) error
+
+	mu    sync.Mutex
+	calls []string
+	model string
+}
+
+// record notes a call by method name
+func (m *MockGeminiClient) record(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, method)
+}
+
+// Calls returns the names of the methods called so far, in order
+func (m *MockGeminiClient) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+// Summarize implements llm.GeminiClientInterface
+func (m *MockGeminiClient) Summarize(ctx context.Context, topic, context string) (*llm.SummarizeResponse, error) {
+	m.record("Summarize")
+	if m.SummarizeFunc != nil {
+		return m.SummarizeFunc(ctx, topic, context)
+	}
+	return FakeSummary(topic), nil
+}
+
+// ExplainWithOG implements llm.GeminiClientInterface
+func (m *MockGeminiClient) ExplainWithOG(ctx context.Context, topic, outline, misconceptions, context string) (*llm.OGLesson, error) {
+	m.record("ExplainWithOG")
+	if m.ExplainFunc != nil {
+		return m.ExplainFunc(ctx, topic, outline, misconceptions, context)
+	}
+	return FakeLesson(topic), nil
+}
+
+// CritiqueLesson implements llm.GeminiClientInterface
+func (m *MockGeminiClient) CritiqueLesson(ctx context.Context, lessonJSON string) (*llm.CritiqueResponse, error) {
+	m.record("CritiqueLesson")
+	if m.CritiqueFunc != nil {
+		return m.CritiqueFunc(ctx, lessonJSON)
+	}
+	return FakeCritique(), nil
+}
+
+// VisualizeCore implements llm.GeminiClientInterface
+func (m *MockGeminiClient) VisualizeCore(ctx context.Context, lessonJSON, sessionID string) (*llm.VisualizeResponse, error) {
+	m.record("VisualizeCore")
+	if m.VisualizeFunc != nil {
+		return m.VisualizeFunc(ctx, lessonJSON, sessionID)
+	}
+	return FakeVisuals(sessionID), nil
+}
+
+// Health implements llm.GeminiClientInterface
+func (m *MockGeminiClient) Health(ctx context.Context) error {
+	if m.HealthFunc != nil {
+		return m.HealthFunc(ctx)
+	}
+	return nil
+}
+
+// SetAPIKey implements llm.GeminiClientInterface
+func (m *MockGeminiClient) SetAPIKey(apiKey string) {}
+
+// SetModel implements llm.GeminiClientInterface
+func (m *MockGeminiClient) SetModel(model string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.model = model
+}
+
+// SetBaseURL implements llm.GeminiClientInterface
+func (m *MockGeminiClient) SetBaseURL(baseURL string) {}
+
+// GetModelInfo implements llm.GeminiClientInterface
+func (m *MockGeminiClient) GetModelInfo() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return map[string]interface{}{"model": m.model, "mock": true}
+}
+
+var _ llm.GeminiClientInterface = (*MockGeminiClient)(nil)
@@ -0,0 +1,324 @@
+package explainiqtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// SessionResult is the result of a completed session, as served by GET /api/sessions/{id}/result
+type SessionResult struct {
+	Lesson  string            `json:"lesson"`           // Lesson JSON from the explainer
+	Images  map[string]string `json:"images,omitempty"` // Image URL -> caption
+	Outline []string          `json:"outline,omitempty"`
+}
+
+// SessionStep is the status of one pipeline step
+type SessionStep struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // pending, running, completed or failed
+	Error  string `json:"error,omitempty"`
+}
+
+// Session is a session held by the in-memory orchestrator
+type Session struct {
+	ID     string         `json:"id"`
+	Topic  string         `json:"topic"`
+	Status string         `json:"status"` // created, running, completed or failed
+	Steps  []SessionStep  `json:"steps"`
+	Result *SessionResult `json:"result,omitempty"`
+}
+
+// Orchestrator is an in-memory stand-in for the ExplainIQ orchestrator. It serves session
+// creation, runs with an SSE event stream, status and results, running the agents in
+// process in pipeline order. It does not authenticate, rate limit or persist anything.
+// Its Session, SessionStep and SessionResult carry a subset of the real API's JSON fields;
+// the orchestrator's tests check that every field they serve exists there with the same type.
+type Orchestrator struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	agents   map[string]TaskHandler
+	sessions map[string]*Session
+	nextID   int
+}
+
+// OrchestratorOption configures an Orchestrator
+type OrchestratorOption func(o *Orchestrator) error
+
+// WithAgent answers one agent's tasks with handler
+func WithAgent(name string, handler TaskHandler) OrchestratorOption {
+	return func(o *Orchestrator) error {
+		o.agents[name] = handler
+		return nil
+	}
+}
+
+// WithGeminiClient runs the real agent processors over client
+func WithGeminiClient(client *MockGeminiClient) OrchestratorOption {
+	return func(o *Orchestrator) error {
+		for _, name := range AgentNames {
+			handler, err := AgentHandler(name, client)
+			if err != nil {
+				return err
+			}
+			o.agents[name] = handler
+		}
+		return nil
+	}
+}
+
+// NewOrchestrator starts an in-memory orchestrator whose agents are the real processors
+// over a MockGeminiClient unless options replace them. It is closed when the test ends.
+func NewOrchestrator(t testing.TB, options ...OrchestratorOption) *Orchestrator {
+	t.Helper()
+	o := &Orchestrator{
+		agents:   make(map[string]TaskHandler),
+		sessions: make(map[string]*Session),
+	}
+	options = append([]OrchestratorOption{WithGeminiClient(&MockGeminiClient{})}, options...)
+	for _, option := range options {
+		if err := option(o); err != nil {
+			t.Fatalf("explainiqtest: %v", err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/sessions", o.createSession)
+	mux.HandleFunc("POST /api/sessions/{id}/run", o.runSession)
+	mux.HandleFunc("GET /api/sessions/{id}/status", o.sessionStatus)
+	mux.HandleFunc("GET /api/sessions/{id}/result", o.sessionResult)
+
+	o.Server = httptest.NewServer(mux)
+	t.Cleanup(o.Close)
+	return o
+}
+
+// Session returns a copy of a session
+func (o *Orchestrator) Session(id string) (Session, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	session, ok := o.sessions[id]
+	if !ok {
+		return Session{}, false
+	}
+	c := *session
+	c.Steps = append([]SessionStep(nil), session.Steps...)
+	return c, true
+}
+
+// RunSession creates a session for topic over HTTP, runs it and returns its ID and recorded events
+func (o *Orchestrator) RunSession(t testing.TB, topic string) (string, *SSERecorder) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"topic": topic})
+	res, err := http.Post(o.URL+"/api/sessions", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("explainiqtest: create session: %v", err)
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&created)
+	res.Body.Close()
+	if err != nil || res.StatusCode != http.StatusCreated {
+		t.Fatalf("explainiqtest: create session: status %d, %v", res.StatusCode, err)
+	}
+
+	res, err = http.Post(o.URL+"/api/sessions/"+created.ID+"/run", "application/json", nil)
+	if err != nil {
+		t.Fatalf("explainiqtest: run session: %v", err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return created.ID, RecordSSE(res.Body)
+}
+
+// createSession handles POST /api/sessions
+func (o *Orchestrator) createSession(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Topic string `json:"topic"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Topic == "" {
+		http.Error(w, "Topic is required", http.StatusBadRequest)
+		return
+	}
+
+	o.mu.Lock()
+	o.nextID++
+	session := &Session{ID: fmt.Sprintf("session-%d", o.nextID), Topic: req.Topic, Status: "created"}
+	for _, name := range AgentNames {
+		session.Steps = append(session.Steps, SessionStep{Name: name, Status: "pending"})
+	}
+	o.sessions[session.ID] = session
+	o.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": session.ID})
+}
+
+// runSession handles POST /api/sessions/{id}/run, streaming the run's events as it goes
+func (o *Orchestrator) runSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	o.mu.Lock()
+	session, ok := o.sessions[id]
+	if ok && session.Status == "running" {
+		o.mu.Unlock()
+		http.Error(w, "Session is already running", http.StatusConflict)
+		return
+	}
+	if ok {
+		session.Status = "running"
+	}
+	o.mu.Unlock()
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher, _ := w.(http.Flusher)
+	send := func(eventType string, stepIndex int, data map[string]interface{}) {
+		event := SSEEvent{Type: eventType, SessionID: id, Data: data, Timestamp: time.Now()}
+		if stepIndex >= 0 {
+			event.StepID = fmt.Sprintf("step-%d", stepIndex+1)
+		}
+		data["session_id"] = id
+		encoded, _ := json.Marshal(event)
+		fmt.Fprintf(w, "data: %s\n\n", encoded)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	send("connected", -1, map[string]interface{}{})
+
+	inputs := map[string]string{"topic": session.Topic}
+	for i, name := range AgentNames {
+		o.setStep(id, i, "running", "")
+		send("step_start", i, map[string]interface{}{"step": name})
+
+		started := time.Now()
+		response, err := o.agents[name](r.Context(), TaskRequest{SessionID: id, Step: name, Topic: session.Topic, Inputs: copyInputs(inputs)})
+		if err != nil {
+			o.setStep(id, i, "failed", err.Error())
+			o.setStatus(id, "failed", nil)
+			send("step_error", i, map[string]interface{}{"step": name, "error": err.Error()})
+			send("session_error", -1, map[string]interface{}{"error": fmt.Sprintf("step %s failed: %s", name, err)})
+			return
+		}
+		for key, value := range response.Artifacts {
+			inputs[key] = value
+		}
+		o.setStep(id, i, "completed", "")
+		send("step_complete", i, map[string]interface{}{
+			"step":     name,
+			"status":   "completed",
+			"duration": time.Since(started).Milliseconds(),
+		})
+	}
+
+	result := buildResult(inputs)
+	o.setStatus(id, "completed", result)
+	var lesson map[string]interface{}
+	json.Unmarshal([]byte(result.Lesson), &lesson)
+	send("session_complete", -1, map[string]interface{}{
+		"artifacts": map[string]interface{}{"lesson": lesson, "images": result.Images},
+	})
+}
+
+// copyInputs copies step inputs so handlers cannot change later steps' inputs
+func copyInputs(inputs map[string]string) map[string]string {
+	c := make(map[string]string, len(inputs))
+	for k, v := range inputs {
+		c[k] = v
+	}
+	return c
+}
+
+// buildResult assembles a session result from the artifacts of every step
+func buildResult(artifacts map[string]string) *SessionResult {
+	result := &SessionResult{Lesson: artifacts["lesson"]}
+	json.Unmarshal([]byte(artifacts["outline"]), &result.Outline)
+
+	var images []struct {
+		URL     string `json:"url"`
+		Caption string `json:"caption"`
+	}
+	if json.Unmarshal([]byte(artifacts["images"]), &images) == nil && len(images) > 0 {
+		result.Images = make(map[string]string, len(images))
+		for _, image := range images {
+			result.Images[image.URL] = image.Caption
+		}
+	}
+	return result
+}
+
+// setStep records a step's status
+func (o *Orchestrator) setStep(id string, index int, status, errMessage string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	step := &o.sessions[id].Steps[index]
+	step.Status = status
+	step.Error = errMessage
+}
+
+// setStatus records a session's status and result
+func (o *Orchestrator) setStatus(id, status string, result *SessionResult) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sessions[id].Status = status
+	o.sessions[id].Result = result
+}
+
+// sessionStatus handles GET /api/sessions/{id}/status
+func (o *Orchestrator) sessionStatus(w http.ResponseWriter, r *http.Request) {
+	session, ok := o.Session(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	completed := 0
+	current := ""
+	for _, step := range session.Steps {
+		switch step.Status {
+		case "completed":
+			completed++
+		case "running":
+			current = step.Name
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session_id":      session.ID,
+		"status":          session.Status,
+		"completed_steps": completed,
+		"total_steps":     len(session.Steps),
+		"current_step":    current,
+		"steps":           session.Steps,
+	})
+}
+
+// sessionResult handles GET /api/sessions/{id}/result
+func (o *Orchestrator) sessionResult(w http.ResponseWriter, r *http.Request) {
+	session, ok := o.Session(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if session.Status != "completed" {
+		http.Error(w, "Session not completed", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session.Result)
+}
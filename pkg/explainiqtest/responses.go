@@ -0,0 +1,170 @@
+// Package explainiqtest provides test doubles for services that integrate with ExplainIQ:
+// a scripted Gemini client, builders for agent task responses, fake agent servers that
+// speak the agent task protocol, a recorder for session SSE streams and an in-memory
+// orchestrator serving the session API.
+//
+// Like pkg/explainiq, the package is experimental and builds on the repository's
+// unpublished internal modules, so it is only usable from this repository's Go workspace
+// or through a replace directive pointing at a checkout.
+package explainiqtest
+
+import (
+	"encoding/json"
+
+	"github.com/InnoFusionTech/ExplainIQ/internal/adk"
+	"github.com/InnoFusionTech/ExplainIQ/internal/agents"
+	"github.com/InnoFusionTech/ExplainIQ/internal/llm"
+)
+
+// TaskRequest is the request an agent receives for one pipeline step
+type TaskRequest = adk.TaskRequest
+
+// TaskResponse is an agent's reply to a TaskRequest
+type TaskResponse = adk.TaskResponse
+
+// Agent names, in pipeline order
+const (
+	Summarizer = agents.Summarizer
+	Explainer  = agents.Explainer
+	Visualizer = agents.Visualizer
+	Critic     = agents.Critic
+)
+
+// AgentNames lists every agent in pipeline order
+var AgentNames = agents.Names
+
+// TaskResponseBuilder builds a TaskResponse for a fake agent
+type TaskResponseBuilder struct {
+	response TaskResponse
+}
+
+// NewTaskResponse starts an empty TaskResponse
+func NewTaskResponse() *TaskResponseBuilder {
+	return &TaskResponseBuilder{response: TaskResponse{
+		Artifacts: make(map[string]string),
+		Metrics:   make(map[string]interface{}),
+	}}
+}
+
+// Artifact sets a string artifact
+func (b *TaskResponseBuilder) Artifact(key, value string) *TaskResponseBuilder {
+	b.response.Artifacts[key] = value
+	return b
+}
+
+// JSONArtifact sets an artifact to the JSON encoding of value, as agents do for structured artifacts
+func (b *TaskResponseBuilder) JSONArtifact(key string, value interface{}) *TaskResponseBuilder {
+	data, err := json.Marshal(value)
+	if err != nil {
+		panic("explainiqtest: cannot encode artifact " + key + ": " + err.Error())
+	}
+	return b.Artifact(key, string(data))
+}
+
+// Metric sets an execution metric
+func (b *TaskResponseBuilder) Metric(key string, value interface{}) *TaskResponseBuilder {
+	b.response.Metrics[key] = value
+	return b
+}
+
+// Usage sets the model and token metrics the orchestrator prices steps from
+func (b *TaskResponseBuilder) Usage(model string, inputTokens, outputTokens int) *TaskResponseBuilder {
+	return b.Metric("model", model).
+		Metric("model_fallbacks", 0).
+		Metric("input_tokens", inputTokens).
+		Metric("output_tokens", outputTokens)
+}
+
+// Delta sets the incremental output
+func (b *TaskResponseBuilder) Delta(delta string) *TaskResponseBuilder {
+	b.response.Delta = delta
+	return b
+}
+
+// Next sets the suggested next step
+func (b *TaskResponseBuilder) Next(next string) *TaskResponseBuilder {
+	b.response.Next = next
+	return b
+}
+
+// Build returns the response; the builder may be reused afterwards
+func (b *TaskResponseBuilder) Build() TaskResponse {
+	response := b.response
+	response.Artifacts = make(map[string]string, len(b.response.Artifacts))
+	for k, v := range b.response.Artifacts {
+		response.Artifacts[k] = v
+	}
+	response.Metrics = make(map[string]interface{}, len(b.response.Metrics))
+	for k, v := range b.response.Metrics {
+		response.Metrics[k] = v
+	}
+	return response
+}
+
+// FakeSummary returns a summarizer result for topic
+func FakeSummary(topic string) *llm.SummarizeResponse {
+	return &llm.SummarizeResponse{
+		Outline:        []string{"What " + topic + " is", "How " + topic + " works", "Where " + topic + " is used"},
+		Prerequisites:  []string{"Basic programming"},
+		Misconceptions: []string{topic + " is only for experts"},
+		Citations:      []string{},
+	}
+}
+
+// FakeLesson returns a complete lesson about topic
+func FakeLesson(topic string) *llm.OGLesson {
+	return &llm.OGLesson{
+		BigPicture:     topic + " solves a common problem in a simple way.",
+		Metaphor:       topic + " is like a well-organized toolbox.",
+		CoreMechanism:  topic + " works by breaking the problem into small steps.",
+		ToyExampleCode: "print(\"" + topic + "\")",
+		MemoryHook:     "Think small steps.",
+		RealLife:       topic + " is used in everyday software.",
+		BestPractices:  "Start simple and measure.",
+	}
+}
+
+// FakeVisuals returns a visualizer result with one image
+func FakeVisuals(sessionID string) *llm.VisualizeResponse {
+	return &llm.VisualizeResponse{
+		Images: []llm.ImageRef{{
+			URL:     "https://example.com/" + sessionID + "/diagram.png",
+			AltText: "Diagram of the lesson's core mechanism",
+			Caption: "How it works",
+		}},
+		Captions: []string{"How it works"},
+	}
+}
+
+// FakeCritique returns a critique that finds nothing to fix
+func FakeCritique() *llm.CritiqueResponse {
+	return &llm.CritiqueResponse{
+		Issues:    []llm.CritiqueIssue{},
+		PatchPlan: []llm.PatchPlanItem{},
+	}
+}
+
+// AgentResponse returns the response an agent gives for topic with the fake Gemini results.
+// Unknown agents get an empty response.
+func AgentResponse(agent, topic string) TaskResponse {
+	builder := NewTaskResponse()
+	switch agent {
+	case Summarizer:
+		summary := FakeSummary(topic)
+		builder.JSONArtifact("outline", summary.Outline).
+			JSONArtifact("prerequisites", summary.Prerequisites).
+			JSONArtifact("misconceptions", summary.Misconceptions).
+			JSONArtifact("citations", summary.Citations)
+	case Explainer:
+		builder.JSONArtifact("lesson", FakeLesson(topic))
+	case Visualizer:
+		visuals := FakeVisuals("session")
+		builder.JSONArtifact("images", visuals.Images).
+			JSONArtifact("captions", visuals.Captions)
+	case Critic:
+		critique := FakeCritique()
+		builder.JSONArtifact("critique", critique.Issues).
+			JSONArtifact("patch_plan", critique.PatchPlan)
+	}
+	return builder.Build()
+}
@@ -0,0 +1,126 @@
+package explainiqtest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSEEvent is one event of a session's event stream
+type SSEEvent struct {
+	Type      string                 `json:"type"`
+	SessionID string                 `json:"session_id"`
+	StepID    string                 `json:"step_id,omitempty"`
+	Data      map[string]interface{} `json:"data"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// SSERecorder collects the events of an SSE stream as they arrive
+type SSERecorder struct {
+	mu     sync.Mutex
+	events []SSEEvent
+	err    error
+	added  chan struct{} // Closed and replaced whenever an event arrives
+	done   chan struct{} // Closed when the stream ends
+}
+
+// RecordSSE reads data frames from stream in the background until it ends. Frames that
+// are not JSON events are skipped.
+func RecordSSE(stream io.Reader) *SSERecorder {
+	recorder := &SSERecorder{
+		added: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go recorder.read(stream)
+	return recorder
+}
+
+// read scans the stream, one event per data line
+func (r *SSERecorder) read(stream io.Reader) {
+	defer close(r.done)
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		var event SSEEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			continue
+		}
+		r.mu.Lock()
+		r.events = append(r.events, event)
+		close(r.added)
+		r.added = make(chan struct{})
+		r.mu.Unlock()
+	}
+
+	r.mu.Lock()
+	r.err = scanner.Err()
+	r.mu.Unlock()
+}
+
+// Events returns the events received so far
+func (r *SSERecorder) Events() []SSEEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]SSEEvent(nil), r.events...)
+}
+
+// Types returns the types of the events received so far, in order
+func (r *SSERecorder) Types() []string {
+	events := r.Events()
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+// Wait blocks until the stream ends or timeout passes, returning any read error
+func (r *SSERecorder) Wait(timeout time.Duration) error {
+	select {
+	case <-r.done:
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.err
+	case <-time.After(timeout):
+		return fmt.Errorf("SSE stream still open after %s", timeout)
+	}
+}
+
+// WaitFor blocks until an event of the given type arrives and returns the first one
+func (r *SSERecorder) WaitFor(eventType string, timeout time.Duration) (SSEEvent, error) {
+	deadline := time.After(timeout)
+	for {
+		r.mu.Lock()
+		for _, event := range r.events {
+			if event.Type == eventType {
+				r.mu.Unlock()
+				return event, nil
+			}
+		}
+		added := r.added
+		r.mu.Unlock()
+
+		select {
+		case <-added:
+		case <-r.done:
+			// Check the events once more; the last one may have arrived with the close
+			for _, event := range r.Events() {
+				if event.Type == eventType {
+					return event, nil
+				}
+			}
+			return SSEEvent{}, fmt.Errorf("SSE stream ended without a %s event", eventType)
+		case <-deadline:
+			return SSEEvent{}, fmt.Errorf("no %s event within %s", eventType, timeout)
+		}
+	}
+}